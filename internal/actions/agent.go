@@ -0,0 +1,67 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// AgentRegisterIdempotent replaces agentName's capability set. Resume and task
+// claim only route an agent tasks whose required capabilities it has all
+// registered; tasks without requirements go to any agent.
+func AgentRegisterIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, capabilities []string) (*store.AgentRegistration, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	return store.RegisterAgentIdempotent(ctx, db, agentName, requestID, capabilities)
+}
+
+// AgentCapabilities returns agentName's registered capabilities.
+func AgentCapabilities(ctx context.Context, db *sql.DB, agentName string) ([]string, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	return store.GetAgentCapabilities(ctx, db, agentName)
+}
+
+// AgentList returns the fleet overview: every agent with state, its last
+// activity, focus, held tasks, and liveness (see store.ListAgentPresence).
+func AgentList(ctx context.Context, db *sql.DB, staleAfter time.Duration) ([]store.AgentPresence, error) {
+	return store.ListAgentPresence(ctx, db, time.Now(), staleAfter)
+}
+
+// AgentEvictIdempotent releases target's claims on behalf of agentName: its
+// in_progress focus tasks return to pending and its focus is cleared.
+func AgentEvictIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, target string) (*store.AgentEviction, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if target == "" {
+		return nil, errors.New("agent to evict is required")
+	}
+	return store.EvictAgentIdempotent(ctx, db, agentName, requestID, target)
+}
+
+// AgentLeaseSetIdempotent sets the claim lease agentName's claims take when
+// neither the claim nor the task sets one; ttl 0 clears it.
+func AgentLeaseSetIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, ttl time.Duration) (*store.AgentLeaseSetting, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	return store.SetAgentDefaultLeaseIdempotent(ctx, db, agentName, requestID, ttl)
+}
+
+// AgentLease returns agentName's default claim lease.
+func AgentLease(ctx context.Context, db *sql.DB, agentName string) (*store.AgentLeaseSetting, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	ttl, err := store.GetAgentDefaultLease(ctx, db, agentName)
+	if err != nil {
+		return nil, err
+	}
+	return &store.AgentLeaseSetting{AgentName: agentName, Seconds: int64(ttl / time.Second)}, nil
+}
@@ -0,0 +1,101 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// analyticsBarWidth is the length of the longest bar in the text report.
+const analyticsBarWidth = 30
+
+// Analytics aggregates throughput, time to complete, tool failure rates,
+// project activity, and event volume for projectID (all projects when empty)
+// over the last sinceDays days.
+func Analytics(ctx context.Context, db *sql.DB, projectID string, sinceDays int) (*store.Analytics, error) {
+	a, err := store.BuildAnalytics(ctx, db, projectID, sinceDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build analytics: %w", err)
+	}
+	return a, nil
+}
+
+// RenderAnalyticsText renders a as a plain-text report with ASCII bar charts.
+func RenderAnalyticsText(a *store.Analytics) string {
+	var b strings.Builder
+	scope := "all projects"
+	if a.ProjectID != "" {
+		scope = a.ProjectID
+	}
+	fmt.Fprintf(&b, "vybe analytics: %s, last %d days\n\n", scope, a.SinceDays)
+
+	fmt.Fprintf(&b, "Throughput: %d tasks completed (%.2f/day)\n", a.Throughput.Completed, a.Throughput.PerDay)
+	peak := 0
+	for _, d := range a.Throughput.ByDay {
+		peak = max(peak, d.Completed)
+	}
+	for _, d := range a.Throughput.ByDay {
+		fmt.Fprintf(&b, "  %s  %s %d\n", d.Day, analyticsBar(d.Completed, peak), d.Completed)
+	}
+
+	if t := a.TimeToComplete; t.Tasks > 0 {
+		fmt.Fprintf(&b, "\nTime to complete: mean %s, median %s (%d tasks)\n",
+			analyticsDuration(t.MeanSeconds), analyticsDuration(t.MedianSeconds), t.Tasks)
+	} else {
+		b.WriteString("\nTime to complete: no tasks completed\n")
+	}
+
+	b.WriteString("\nFailure rate by tool:\n")
+	if len(a.ToolFailures) == 0 {
+		b.WriteString("  (no tool events)\n")
+	}
+	for _, r := range a.ToolFailures {
+		tool := r.Tool
+		if tool == "" {
+			tool = "(unknown)"
+		}
+		fmt.Fprintf(&b, "  %-14s %s %5.1f%%  %d/%d\n", tool,
+			analyticsBar(int(r.FailureRate*100+0.5), 100), r.FailureRate*100, r.Failures, r.Calls)
+	}
+
+	b.WriteString("\nBusiest projects:\n")
+	peak = 0
+	for _, p := range a.Projects {
+		peak = max(peak, p.Events)
+	}
+	for _, p := range a.Projects {
+		project := p.ProjectID
+		if project == "" {
+			project = "(none)"
+		}
+		fmt.Fprintf(&b, "  %s %d events, %d completed  %s\n", analyticsBar(p.Events, peak), p.Events, p.Completions, project)
+	}
+
+	fmt.Fprintf(&b, "\nEvents by kind (%d total):\n", a.TotalEvents)
+	peak = 0
+	for _, k := range a.EventsByKind {
+		peak = max(peak, k.Count)
+	}
+	for _, k := range a.EventsByKind {
+		fmt.Fprintf(&b, "  %-24s %s %d\n", k.Kind, analyticsBar(k.Count, peak), k.Count)
+	}
+	return b.String()
+}
+
+// analyticsBar draws v relative to peak, padded to analyticsBarWidth. Any
+// non-zero value gets at least one mark.
+func analyticsBar(v, peak int) string {
+	n := 0
+	if v > 0 && peak > 0 {
+		n = max(1, v*analyticsBarWidth/peak)
+	}
+	return strings.Repeat("#", n) + strings.Repeat(".", analyticsBarWidth-n)
+}
+
+func analyticsDuration(secs float64) string {
+	return (time.Duration(secs) * time.Second).String()
+}
@@ -0,0 +1,29 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// TokenCreateIdempotent creates an API token for a token-protected daemon.
+// The returned token carries its secret only on the first call.
+func TokenCreateIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, name, role string) (*store.APIToken, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	return store.CreateAPITokenIdempotent(ctx, db, agentName, requestID, name, role)
+}
+
+// TokenRevokeIdempotent revokes an API token.
+func TokenRevokeIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, tokenID string) (*store.APIToken, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if tokenID == "" {
+		return nil, errors.New("token id is required")
+	}
+	return store.RevokeAPITokenIdempotent(ctx, db, agentName, requestID, tokenID)
+}
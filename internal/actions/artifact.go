@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 
@@ -8,14 +9,23 @@ import (
 	"github.com/dotcommander/vybe/internal/store"
 )
 
-func ArtifactAddIdempotent(db *sql.DB, agentName, requestID, taskID, filePath, contentType string) (*models.Artifact, int64, error) { //nolint:revive // argument-limit: all artifact params are required and distinct
+func ArtifactAddIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, filePath, contentType string) (*models.Artifact, int64, error) { //nolint:revive // argument-limit: all artifact params are required and distinct
+	return ArtifactAddWithContentIdempotent(ctx, db, agentName, requestID, taskID, filePath, contentType, nil)
+}
+
+// ArtifactAddWithContentIdempotent links an artifact and, when content is
+// non-nil, stores its body so it can be diffed after the workspace is gone.
+func ArtifactAddWithContentIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, filePath, contentType string, content []byte) (*models.Artifact, int64, error) { //nolint:revive // argument-limit: mirrors ArtifactAddIdempotent plus the captured body
 	if agentName == "" {
 		return nil, 0, errors.New("agent name is required")
 	}
 	if requestID == "" {
 		return nil, 0, errors.New("request id is required")
 	}
-	artifact, eventID, err := store.AddArtifactIdempotent(db, agentName, requestID, taskID, filePath, contentType)
+	if err := CheckConfinedTask(ctx, db, agentName, taskID); err != nil {
+		return nil, 0, err
+	}
+	artifact, eventID, err := store.AddArtifactWithContentIdempotent(ctx, db, agentName, requestID, taskID, filePath, contentType, content)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -23,11 +33,11 @@ func ArtifactAddIdempotent(db *sql.DB, agentName, requestID, taskID, filePath, c
 }
 
 // ArtifactGet retrieves a single artifact by ID.
-func ArtifactGet(db *sql.DB, id string) (*models.Artifact, error) {
-	return store.GetArtifact(db, id)
+func ArtifactGet(ctx context.Context, db *sql.DB, id string) (*models.Artifact, error) {
+	return store.GetArtifact(ctx, db, id)
 }
 
 // ArtifactListByTask returns artifacts linked to a task, newest first.
-func ArtifactListByTask(db *sql.DB, taskID string, limit int) ([]*models.Artifact, error) {
-	return store.ListArtifactsByTask(db, taskID, limit)
+func ArtifactListByTask(ctx context.Context, db *sql.DB, taskID string, limit int) ([]*models.Artifact, error) {
+	return store.ListArtifactsByTask(ctx, db, taskID, limit)
 }
@@ -0,0 +1,224 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+const (
+	// diffContextLines matches `diff -u`.
+	diffContextLines = 3
+	// maxDiffCells bounds the LCS table; beyond it the changed middle of the
+	// files is reported as one replaced block instead of a minimal diff.
+	maxDiffCells = 4_000_000
+)
+
+// ArtifactDiffResult compares the stored content of two artifacts.
+type ArtifactDiffResult struct {
+	A         *models.Artifact `json:"a"`
+	B         *models.Artifact `json:"b"`
+	Identical bool             `json:"identical"`
+	Binary    bool             `json:"binary"`
+	Added     int              `json:"added"`
+	Removed   int              `json:"removed"`
+	Diff      string           `json:"diff,omitempty"`
+}
+
+// ArtifactDiff returns a unified diff from artifact idA to artifact idB. Both
+// artifacts must have been added with content capture.
+func ArtifactDiff(ctx context.Context, db *sql.DB, idA, idB string) (_ *ArtifactDiffResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	a, err := store.GetArtifact(ctx, db, idA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := store.GetArtifact(ctx, db, idB)
+	if err != nil {
+		return nil, err
+	}
+	contentA, err := store.GetArtifactContent(ctx, db, idA)
+	if err != nil {
+		return nil, err
+	}
+	contentB, err := store.GetArtifactContent(ctx, db, idB)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ArtifactDiffResult{A: a, B: b, Identical: a.ContentHash == b.ContentHash}
+	if res.Identical {
+		return res, nil
+	}
+	if isBinary(contentA) || isBinary(contentB) {
+		res.Binary = true
+		return res, nil
+	}
+
+	res.Diff, res.Added, res.Removed = unifiedDiff(
+		fmt.Sprintf("%s (%s)", a.FilePath, a.ID),
+		fmt.Sprintf("%s (%s)", b.FilePath, b.ID),
+		splitLines(string(contentA)), splitLines(string(contentB)))
+	return res, nil
+}
+
+func isBinary(content []byte) bool {
+	return !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0
+}
+
+// splitLines splits on newlines; a trailing newline does not add an empty line.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+type diffOp struct {
+	kind   byte // ' ', '-', '+'
+	text   string
+	ai, bi int // 0-based positions in a and b before this op
+}
+
+// diffOps returns the edit script from a to b. Common prefix and suffix are
+// trimmed first so typical edits keep the LCS table small.
+func diffOps(a, b []string) []diffOp {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for i := range pre {
+		ops = append(ops, diffOp{kind: ' ', text: a[i], ai: i, bi: i})
+	}
+
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	ai, bi := pre, pre
+	emit := func(kind byte, text string) {
+		ops = append(ops, diffOp{kind: kind, text: text, ai: ai, bi: bi})
+		if kind != '+' {
+			ai++
+		}
+		if kind != '-' {
+			bi++
+		}
+	}
+
+	if len(ma)*len(mb) > maxDiffCells {
+		for _, l := range ma {
+			emit('-', l)
+		}
+		for _, l := range mb {
+			emit('+', l)
+		}
+	} else {
+		// lcs[i][j] is the LCS length of ma[i:] and mb[j:].
+		lcs := make([][]int, len(ma)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(mb)+1)
+		}
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(ma) || j < len(mb) {
+			switch {
+			case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+				emit(' ', ma[i])
+				i++
+				j++
+			case j < len(mb) && (i == len(ma) || lcs[i][j+1] > lcs[i+1][j]):
+				emit('+', mb[j])
+				j++
+			default:
+				emit('-', ma[i])
+				i++
+			}
+		}
+	}
+
+	for k := len(a) - suf; k < len(a); k++ {
+		emit(' ', a[k])
+	}
+	return ops
+}
+
+// unifiedDiff renders a `diff -u` style patch and counts changed lines.
+func unifiedDiff(nameA, nameB string, a, b []string) (diff string, added, removed int) {
+	ops := diffOps(a, b)
+
+	var changes []int
+	for i, op := range ops {
+		switch op.kind {
+		case '+':
+			added++
+			changes = append(changes, i)
+		case '-':
+			removed++
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return "", 0, 0
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+	for k := 0; k < len(changes); {
+		last := k
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*diffContextLines {
+			last++
+		}
+		start := max(0, changes[k]-diffContextLines)
+		end := min(len(ops), changes[last]+diffContextLines+1)
+		writeHunk(&sb, ops[start:end])
+		k = last + 1
+	}
+	return sb.String(), added, removed
+}
+
+func writeHunk(sb *strings.Builder, hunk []diffOp) {
+	countA, countB := 0, 0
+	for _, op := range hunk {
+		if op.kind != '+' {
+			countA++
+		}
+		if op.kind != '-' {
+			countB++
+		}
+	}
+	// An empty side is addressed by the line before it, per the unified format.
+	startA, startB := hunk[0].ai, hunk[0].bi
+	if countA > 0 {
+		startA++
+	}
+	if countB > 0 {
+		startB++
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", startA, countA, startB, countB)
+	for _, op := range hunk {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.text)
+		sb.WriteByte('\n')
+	}
+}
@@ -0,0 +1,85 @@
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestUnifiedDiff_Hunks(t *testing.T) {
+	a := splitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+	b := splitLines("1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n")
+
+	diff, added, removed := unifiedDiff("a", "b", a, b)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`, diff)
+
+	diff, added, removed = unifiedDiff("a", "b", nil, []string{"x"})
+	assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+x\n", diff)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 0, removed)
+
+	diff, _, _ = unifiedDiff("a", "b", a, a)
+	assert.Empty(t, diff)
+}
+
+func TestArtifactDiff_StoredContent(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := store.CreateTask(context.Background(), db, "Build", "", "", 0)
+	require.NoError(t, err)
+
+	v1, _, err := ArtifactAddWithContentIdempotent(context.Background(), db, "agent1", "art-1", task.ID, "out.txt", "text/plain", []byte("hello\nworld\n"))
+	require.NoError(t, err)
+	v2, _, err := ArtifactAddWithContentIdempotent(context.Background(), db, "agent1", "art-2", task.ID, "out.txt", "text/plain", []byte("hello\nthere\n"))
+	require.NoError(t, err)
+	v3, _, err := ArtifactAddWithContentIdempotent(context.Background(), db, "agent1", "art-3", task.ID, "out.txt", "text/plain", []byte("hello\nworld\n"))
+	require.NoError(t, err)
+	assert.Equal(t, v1.ContentHash, v3.ContentHash, "identical content shares a blob")
+	assert.Equal(t, int64(12), v1.ContentSize)
+
+	res, err := ArtifactDiff(context.Background(), db, v1.ID, v2.ID)
+	require.NoError(t, err)
+	assert.False(t, res.Identical)
+	assert.Equal(t, 1, res.Added)
+	assert.Equal(t, 1, res.Removed)
+	assert.Contains(t, res.Diff, "-world\n+there\n")
+
+	res, err = ArtifactDiff(context.Background(), db, v1.ID, v3.ID)
+	require.NoError(t, err)
+	assert.True(t, res.Identical)
+	assert.Empty(t, res.Diff)
+
+	bin, _, err := ArtifactAddWithContentIdempotent(context.Background(), db, "agent1", "art-4", task.ID, "out.bin", "", []byte{0, 1, 2})
+	require.NoError(t, err)
+	res, err = ArtifactDiff(context.Background(), db, v1.ID, bin.ID)
+	require.NoError(t, err)
+	assert.True(t, res.Binary)
+
+	linkOnly, _, err := ArtifactAddIdempotent(context.Background(), db, "agent1", "art-5", task.ID, "out.txt", "")
+	require.NoError(t, err)
+	assert.Empty(t, linkOnly.ContentHash)
+	_, err = ArtifactDiff(context.Background(), db, v1.ID, linkOnly.ID)
+	require.ErrorContains(t, err, "no stored content")
+}
@@ -0,0 +1,276 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// BatchOp is one line of a `vybe batch` stream. Args holds the operation's
+// fields; RequestID makes the line idempotent on its own.
+type BatchOp struct {
+	Op        string          `json:"op"`
+	RequestID string          `json:"request_id"`
+	Args      json.RawMessage `json:"args"`
+}
+
+// batchHandler applies one decoded operation in its own transaction.
+type batchHandler func(ctx context.Context, db *sql.DB, agentName, requestID string, args json.RawMessage) (any, error)
+
+// batchHandlers maps batch op names to the idempotent actions they call.
+// Names follow the CLI: "<group>.<subcommand>" with dashes as underscores.
+var batchHandlers = map[string]batchHandler{
+	"task.create":       batchTaskCreate,
+	"task.set_status":   batchTaskSetStatus,
+	"task.begin":        batchTaskBegin,
+	"task.close":        batchTaskClose,
+	"task.add_dep":      batchTaskAddDep,
+	"task.set_meta":     batchTaskSetMeta,
+	"task.criteria_add": batchTaskCriteriaAdd,
+	"memory.set":        batchMemorySet,
+	"events.add":        batchEventsAdd,
+	"artifacts.add":     batchArtifactsAdd,
+	"msg.send":          batchMsgSend,
+	"push":              batchPush,
+}
+
+// BatchOpNames lists the supported batch operations in sorted order.
+func BatchOpNames() []string {
+	names := make([]string, 0, len(batchHandlers))
+	for name := range batchHandlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyBatchOp runs one batch operation once per (agent_name, request_id):
+// replaying a line with the same request id returns the original result.
+func ApplyBatchOp(ctx context.Context, db *sql.DB, agentName string, op BatchOp) (_ any, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	span.Set("vybe.batch.op", op.Op)
+	defer func() { span.End(err) }()
+
+	handler, ok := batchHandlers[op.Op]
+	if !ok {
+		return nil, fmt.Errorf("unknown batch op %q (valid: %s)", op.Op, strings.Join(BatchOpNames(), ", "))
+	}
+	if err := validateAgentRequest(agentName, op.RequestID); err != nil {
+		return nil, err
+	}
+	args := op.Args
+	if len(bytes.TrimSpace(args)) == 0 {
+		args = json.RawMessage("{}")
+	}
+	return handler(ctx, db, agentName, op.RequestID, args)
+}
+
+func decodeBatchArgs(args json.RawMessage, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid args: %w", err)
+	}
+	return nil
+}
+
+func batchTaskCreate(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		ProjectID   string     `json:"project_id"`
+		Priority    int        `json:"priority"`
+		DueAt       *time.Time `json:"due_at"`
+		Size        string     `json:"size"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	task, eventID, err := TaskCreateWithOptionsIdempotent(ctx, db, agentName, requestID, a.Title, a.Description, a.ProjectID, a.Priority,
+		TaskCreateOptions{DueAt: a.DueAt, Size: models.TaskSize(a.Size)})
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Task    *models.Task `json:"task"`
+		EventID int64        `json:"event_id"`
+	}{task, eventID}, nil
+}
+
+func batchTaskSetStatus(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		TaskID        string `json:"task_id"`
+		Status        string `json:"status"`
+		BlockedReason string `json:"blocked_reason"`
+		Strict        bool   `json:"strict"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	task, eventID, err := TaskSetStatusWithOptionsIdempotent(ctx, db, agentName, requestID, a.TaskID, a.Status,
+		TaskStatusOptions{BlockedReason: a.BlockedReason, Strict: a.Strict})
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Task    *models.Task `json:"task"`
+		EventID int64        `json:"event_id"`
+	}{task, eventID}, nil
+}
+
+func batchTaskBegin(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		TaskID string `json:"task_id"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	return TaskStartIdempotent(ctx, db, agentName, requestID, a.TaskID)
+}
+
+func batchTaskClose(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		TaskID        string `json:"task_id"`
+		Outcome       string `json:"outcome"`
+		Summary       string `json:"summary"`
+		Label         string `json:"label"`
+		BlockedReason string `json:"blocked_reason"`
+		SupersededBy  string `json:"superseded_by"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	return TaskCloseWithOptionsIdempotent(ctx, db, agentName, requestID, a.TaskID, a.Outcome, a.Summary, TaskCloseOptions{
+		Label:         a.Label,
+		BlockedReason: a.BlockedReason,
+		SupersededBy:  a.SupersededBy,
+	})
+}
+
+func batchTaskAddDep(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		TaskID    string `json:"task_id"`
+		DependsOn string `json:"depends_on"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	return TaskAddDepIdempotent(ctx, db, agentName, requestID, a.TaskID, a.DependsOn)
+}
+
+func batchTaskSetMeta(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		TaskID string `json:"task_id"`
+		Key    string `json:"key"`
+		Value  string `json:"value"`
+		Type   string `json:"type"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	meta, eventID, err := TaskMetaSetIdempotent(ctx, db, agentName, requestID, a.TaskID, a.Key, a.Value, a.Type)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Meta    *models.TaskMeta `json:"meta"`
+		EventID int64            `json:"event_id"`
+	}{meta, eventID}, nil
+}
+
+func batchTaskCriteriaAdd(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		TaskID string `json:"task_id"`
+		Text   string `json:"text"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	criterion, eventID, err := TaskCriteriaAddIdempotent(ctx, db, agentName, requestID, a.TaskID, a.Text)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Criterion *models.TaskCriterion `json:"criterion"`
+		EventID   int64                 `json:"event_id"`
+	}{criterion, eventID}, nil
+}
+
+func batchMemorySet(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var m PushMemoryInput
+	if err := decodeBatchArgs(raw, &m); err != nil {
+		return nil, err
+	}
+	eventID, err := MemorySetIdempotent(ctx, db, agentName, requestID, m.Key, m.Value, m.ValueType, m.Scope, m.ScopeID,
+		m.ExpiresAt, m.Pinned, m.Kind, m.HalfLifeDays, m.SourceTaskID)
+	if err != nil {
+		return nil, err
+	}
+	return PushMemoryResult{Key: m.Key, EventID: eventID}, nil
+}
+
+func batchEventsAdd(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		TaskID string `json:"task_id"`
+		PushEventInput
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	return PushIdempotent(ctx, db, agentName, requestID, PushInput{TaskID: a.TaskID, Event: &a.PushEventInput})
+}
+
+func batchArtifactsAdd(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		TaskID      string `json:"task_id"`
+		FilePath    string `json:"file_path"`
+		ContentType string `json:"content_type"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	artifact, eventID, err := ArtifactAddIdempotent(ctx, db, agentName, requestID, a.TaskID, a.FilePath, a.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Artifact *models.Artifact `json:"artifact"`
+		EventID  int64            `json:"event_id"`
+	}{artifact, eventID}, nil
+}
+
+func batchMsgSend(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var a struct {
+		To      string `json:"to"`
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+		TaskID  string `json:"task_id"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	msg, eventID, err := MessageSendIdempotent(ctx, db, agentName, requestID, a.To, a.Subject, a.Body, a.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Message *models.Message `json:"message"`
+		EventID int64           `json:"event_id"`
+	}{msg, eventID}, nil
+}
+
+func batchPush(ctx context.Context, db *sql.DB, agentName, requestID string, raw json.RawMessage) (any, error) {
+	var in PushInput
+	if err := decodeBatchArgs(raw, &in); err != nil {
+		return nil, err
+	}
+	return PushIdempotent(ctx, db, agentName, requestID, in)
+}
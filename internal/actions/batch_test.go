@@ -0,0 +1,59 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestApplyBatchOp_ReplaysPerRequestID(t *testing.T) {
+	db, _ := setupTestDBWithCleanup(t)
+
+	op := BatchOp{Op: "task.create", RequestID: "b1", Args: json.RawMessage(`{"title": "From batch", "priority": 3}`)}
+	first, err := ApplyBatchOp(context.Background(), db, "agent1", op)
+	require.NoError(t, err)
+	again, err := ApplyBatchOp(context.Background(), db, "agent1", op)
+	require.NoError(t, err)
+
+	firstJSON, err := json.Marshal(first)
+	require.NoError(t, err)
+	againJSON, err := json.Marshal(again)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(firstJSON), string(againJSON), "same request id returns the original task")
+
+	var created struct {
+		Task struct {
+			ID string `json:"id"`
+		} `json:"task"`
+	}
+	require.NoError(t, json.Unmarshal(firstJSON, &created))
+	tasks, err := store.ListTasks(context.Background(), db, "", "", -1)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, created.Task.ID, tasks[0].ID)
+
+	_, err = ApplyBatchOp(context.Background(), db, "agent1", BatchOp{Op: "task.begin", RequestID: "b2",
+		Args: json.RawMessage(`{"task_id": "` + created.Task.ID + `"}`)})
+	require.NoError(t, err)
+	task, err := store.GetTask(context.Background(), db, created.Task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "in_progress", string(task.Status))
+}
+
+func TestApplyBatchOp_RejectsBadLines(t *testing.T) {
+	db, _ := setupTestDBWithCleanup(t)
+
+	_, err := ApplyBatchOp(context.Background(), db, "agent1", BatchOp{Op: "task.explode", RequestID: "x1"})
+	assert.ErrorContains(t, err, "unknown batch op")
+
+	_, err = ApplyBatchOp(context.Background(), db, "agent1", BatchOp{Op: "task.create", RequestID: "x2", Args: json.RawMessage(`{"titel": "typo"}`)})
+	assert.ErrorContains(t, err, "invalid args")
+
+	_, err = ApplyBatchOp(context.Background(), db, "agent1", BatchOp{Op: "memory.set", Args: json.RawMessage(`{"key": "k", "value": "v", "scope": "global"}`)})
+	assert.ErrorContains(t, err, "request id is required")
+}
@@ -0,0 +1,170 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// BriefFieldDiff compares a single-valued brief field across two agents.
+type BriefFieldDiff struct {
+	A    string `json:"a"`
+	B    string `json:"b"`
+	Same bool   `json:"same"`
+}
+
+// BriefSetDiff compares a list-valued brief section by entry identity.
+type BriefSetDiff struct {
+	OnlyA  []string `json:"only_a"`
+	OnlyB  []string `json:"only_b"`
+	Shared int      `json:"shared"`
+}
+
+// BriefDiffResult explains how two agents' injected contexts differ.
+// Memory entries are identified as "scope:scope_id:key"; events by ID; artifacts by path.
+type BriefDiffResult struct {
+	AgentA         string         `json:"agent_a"`
+	AgentB         string         `json:"agent_b"`
+	Identical      bool           `json:"identical"`
+	FocusTask      BriefFieldDiff `json:"focus_task"`
+	Project        BriefFieldDiff `json:"project"`
+	Memory         BriefSetDiff   `json:"memory"`
+	MemoryValues   []string       `json:"memory_value_differs,omitempty"`
+	RecentEvents   BriefSetDiff   `json:"recent_events"`
+	PriorReasoning BriefSetDiff   `json:"prior_reasoning"`
+	Artifacts      BriefSetDiff   `json:"artifacts"`
+	ApproxTokens   [2]int         `json:"approx_tokens"`
+}
+
+// BriefDiff builds read-only briefs for two agents with the same options and compares them.
+func BriefDiff(ctx context.Context, db *sql.DB, agentA, agentB string, opts BriefOptions) (*BriefDiffResult, error) {
+	if agentA == "" || agentB == "" {
+		return nil, errors.New("both agent names are required")
+	}
+	a, err := BriefWithOptions(ctx, db, agentA, opts)
+	if err != nil {
+		return nil, fmt.Errorf("brief for %s: %w", agentA, err)
+	}
+	b, err := BriefWithOptions(ctx, db, agentB, opts)
+	if err != nil {
+		return nil, fmt.Errorf("brief for %s: %w", agentB, err)
+	}
+	result := DiffBriefs(a, b)
+	result.AgentA, result.AgentB = agentA, agentB
+	return result, nil
+}
+
+// DiffBriefs compares two brief packets section by section.
+func DiffBriefs(a, b *store.BriefPacket) *BriefDiffResult {
+	r := &BriefDiffResult{
+		FocusTask:    fieldDiff(briefTaskLabel(a), briefTaskLabel(b)),
+		Project:      fieldDiff(briefProjectID(a), briefProjectID(b)),
+		ApproxTokens: [2]int{a.ApproxTokens, b.ApproxTokens},
+	}
+
+	memB := memoryIndex(b.RelevantMemory)
+	r.Memory = setDiff(memoryIDs(a.RelevantMemory), memoryIDs(b.RelevantMemory))
+	for _, m := range a.RelevantMemory {
+		id := memoryID(m)
+		if other, ok := memB[id]; ok && other.Value != m.Value {
+			r.MemoryValues = append(r.MemoryValues, id)
+		}
+	}
+
+	r.RecentEvents = setDiff(eventIDs(a.RecentEvents), eventIDs(b.RecentEvents))
+	r.PriorReasoning = setDiff(eventIDs(a.PriorReasoning), eventIDs(b.PriorReasoning))
+	r.Artifacts = setDiff(artifactPaths(a.Artifacts), artifactPaths(b.Artifacts))
+
+	r.Identical = r.FocusTask.Same && r.Project.Same && len(r.MemoryValues) == 0 &&
+		r.Memory.empty() && r.RecentEvents.empty() && r.PriorReasoning.empty() && r.Artifacts.empty()
+	return r
+}
+
+func (d BriefSetDiff) empty() bool {
+	return len(d.OnlyA) == 0 && len(d.OnlyB) == 0
+}
+
+func fieldDiff(a, b string) BriefFieldDiff {
+	return BriefFieldDiff{A: a, B: b, Same: a == b}
+}
+
+func briefTaskLabel(b *store.BriefPacket) string {
+	if b.Task == nil {
+		return ""
+	}
+	return b.Task.ID + " " + b.Task.Title
+}
+
+func briefProjectID(b *store.BriefPacket) string {
+	if b.Project == nil {
+		return ""
+	}
+	return b.Project.ID
+}
+
+// setDiff returns entries unique to each side, preserving each side's order.
+func setDiff(a, b []string) BriefSetDiff {
+	inA := make(map[string]bool, len(a))
+	for _, id := range a {
+		inA[id] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, id := range b {
+		inB[id] = true
+	}
+	d := BriefSetDiff{OnlyA: []string{}, OnlyB: []string{}}
+	for _, id := range a {
+		if inB[id] {
+			d.Shared++
+		} else {
+			d.OnlyA = append(d.OnlyA, id)
+		}
+	}
+	for _, id := range b {
+		if !inA[id] {
+			d.OnlyB = append(d.OnlyB, id)
+		}
+	}
+	return d
+}
+
+func memoryID(m *models.Memory) string {
+	return string(m.Scope) + ":" + m.ScopeID + ":" + m.Key
+}
+
+func memoryIDs(mems []*models.Memory) []string {
+	ids := make([]string, 0, len(mems))
+	for _, m := range mems {
+		ids = append(ids, memoryID(m))
+	}
+	return ids
+}
+
+func memoryIndex(mems []*models.Memory) map[string]*models.Memory {
+	idx := make(map[string]*models.Memory, len(mems))
+	for _, m := range mems {
+		idx[memoryID(m)] = m
+	}
+	return idx
+}
+
+func eventIDs(events []*models.Event) []string {
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, strconv.FormatInt(e.ID, 10))
+	}
+	return ids
+}
+
+func artifactPaths(artifacts []*models.Artifact) []string {
+	paths := make([]string, 0, len(artifacts))
+	for _, a := range artifacts {
+		paths = append(paths, a.FilePath)
+	}
+	return paths
+}
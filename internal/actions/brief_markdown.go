@@ -0,0 +1,196 @@
+package actions
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// RenderBriefMarkdown renders a brief packet as a self-contained markdown
+// document: task, acceptance criteria, dependencies, sub-agent delegations,
+// memory, recent events, prior reasoning, and artifacts. It is meant for
+// pasting into agents that have no vybe hook integration, so it carries no
+// budget trimming of its own; shape the packet with --max-tokens first.
+func RenderBriefMarkdown(agentName string, brief *store.BriefPacket) string {
+	var b strings.Builder
+	task := getBriefTask(brief)
+	if task == nil {
+		b.WriteString("# Brief: no focus task\n\n")
+	} else {
+		fmt.Fprintf(&b, "# Brief: %s\n\n", task.Title)
+	}
+	if agentName != "" {
+		fmt.Fprintf(&b, "- Agent: %s\n", agentName)
+	}
+	if brief != nil && brief.Project != nil {
+		fmt.Fprintf(&b, "- Project: %s (%s)\n", brief.Project.Name, brief.Project.ID)
+	}
+	if brief != nil && brief.Counts != nil {
+		fmt.Fprintf(&b, "- Project tasks: %d pending, %d in progress, %d blocked, %d completed\n",
+			brief.Counts.Pending, brief.Counts.InProgress, brief.Counts.Blocked, brief.Counts.Completed)
+	}
+	if brief == nil {
+		return b.String()
+	}
+
+	if task != nil {
+		b.WriteString("\n## Task\n\n")
+		fmt.Fprintf(&b, "- ID: `%s`\n", task.ID)
+		fmt.Fprintf(&b, "- Status: %s\n", task.Status)
+		fmt.Fprintf(&b, "- Priority: %d\n", task.Priority)
+		if task.BlockedReason != "" {
+			fmt.Fprintf(&b, "- Blocked: %s\n", task.BlockedReason)
+		}
+		if task.DueAt != nil {
+			fmt.Fprintf(&b, "- Due: %s\n", task.DueAt.UTC().Format("2006-01-02 15:04 UTC"))
+		}
+		if task.Attempts > 0 {
+			fmt.Fprintf(&b, "- Previous failed attempts: %d\n", task.Attempts)
+		}
+		if len(task.Tags) > 0 {
+			fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(task.Tags, ", "))
+		}
+		if task.Description != "" {
+			fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(task.Description))
+		}
+	}
+
+	if len(brief.Criteria) > 0 {
+		b.WriteString("\n## Acceptance criteria\n\n")
+		for _, c := range brief.Criteria {
+			mark := " "
+			if c.Done {
+				mark = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s (#%d)\n", mark, c.Text, c.ID)
+		}
+	}
+
+	if len(brief.Dependencies) > 0 {
+		b.WriteString("\n## Waiting on\n\n")
+		for _, d := range brief.Dependencies {
+			fmt.Fprintf(&b, "- %s (`%s`, %s)\n", d.Title, d.ID, d.Status)
+		}
+	}
+
+	if len(brief.Delegations) > 0 {
+		b.WriteString("\n## Delegated to sub-agents\n\n")
+		for _, d := range brief.Delegations {
+			fmt.Fprintf(&b, "- %s (`%s`) to %s: %s, task %s\n", d.TaskTitle, d.TaskID, d.Subagent, d.Status, d.TaskStatus)
+		}
+	}
+
+	if len(brief.MemoryConflicts) > 0 {
+		b.WriteString("\n## Memory conflicts\n\n")
+		for _, c := range brief.MemoryConflicts {
+			fmt.Fprintf(&b, "- #%d `%s`: a = %s (%s), b = %s (%s, current)\n", c.ID, c.Key,
+				markdownLine(c.ValueA), c.AgentA, markdownLine(c.ValueB), c.AgentB)
+		}
+	}
+
+	if len(brief.Decisions) > 0 {
+		b.WriteString("\n## Decisions\n\n")
+		for _, d := range brief.Decisions {
+			fmt.Fprintf(&b, "- `%s`: %s\n", d.Key, markdownLine(d.Decision))
+			if d.Context != "" {
+				fmt.Fprintf(&b, "  - Context: %s\n", markdownLine(d.Context))
+			}
+			if len(d.Alternatives) > 0 {
+				fmt.Fprintf(&b, "  - Alternatives: %s\n", markdownLine(strings.Join(d.Alternatives, "; ")))
+			}
+			if d.Consequences != "" {
+				fmt.Fprintf(&b, "  - Consequences: %s\n", markdownLine(d.Consequences))
+			}
+		}
+	}
+
+	appendMarkdownMemory(&b, brief.RelevantMemory)
+
+	if len(brief.Lessons) > 0 {
+		b.WriteString("\n## Lessons\n\n")
+		for _, l := range brief.Lessons {
+			fmt.Fprintf(&b, "- %s\n", l.Text)
+		}
+	}
+
+	if len(brief.RecentEvents) > 0 {
+		b.WriteString("\n## Recent events\n\n")
+		for _, e := range brief.RecentEvents {
+			fmt.Fprintf(&b, "- %s `%s` %s\n", e.CreatedAt.UTC().Format("2006-01-02 15:04"), e.Kind, markdownLine(store.BriefEventLabel(e)))
+		}
+	}
+
+	if len(brief.PriorReasoning) > 0 {
+		b.WriteString("\n## Prior reasoning\n\n")
+		for _, e := range brief.PriorReasoning {
+			intent, approach := extractReasoningFields(e.Metadata)
+			switch {
+			case intent != "" && approach != "":
+				fmt.Fprintf(&b, "- Intent: %s; approach: %s\n", markdownLine(intent), markdownLine(approach))
+			case intent != "":
+				fmt.Fprintf(&b, "- Intent: %s\n", markdownLine(intent))
+			case approach != "":
+				fmt.Fprintf(&b, "- Approach: %s\n", markdownLine(approach))
+			default:
+				fmt.Fprintf(&b, "- %s\n", markdownLine(e.Message))
+			}
+		}
+	}
+
+	if len(brief.Artifacts) > 0 {
+		b.WriteString("\n## Artifacts\n\n")
+		for _, a := range brief.Artifacts {
+			if a.ContentType != "" {
+				fmt.Fprintf(&b, "- `%s` (%s)\n", a.FilePath, a.ContentType)
+			} else {
+				fmt.Fprintf(&b, "- `%s`\n", a.FilePath)
+			}
+		}
+	}
+
+	if brief.Budget != nil && len(brief.Budget.Elided) > 0 {
+		fmt.Fprintf(&b, "\n_Trimmed to ~%d tokens; elided entries:", brief.Budget.MaxTokens)
+		for _, section := range slices.Sorted(maps.Keys(brief.Budget.Elided)) {
+			fmt.Fprintf(&b, " %s %d", section, brief.Budget.Elided[section])
+		}
+		b.WriteString("._\n")
+	}
+	return b.String()
+}
+
+// appendMarkdownMemory writes directives and facts as separate sections,
+// narrowest scope first, as the hook prompt does.
+func appendMarkdownMemory(b *strings.Builder, memory []*models.Memory) {
+	var directives, facts []*models.Memory
+	for _, m := range memory {
+		if m.Kind == string(models.MemoryKindDirective) {
+			directives = append(directives, m)
+		} else {
+			facts = append(facts, m)
+		}
+	}
+	sortMemoryByScope(directives)
+	sortMemoryByScope(facts)
+
+	if len(directives) > 0 {
+		b.WriteString("\n## Directives\n\n")
+		for _, m := range directives {
+			fmt.Fprintf(b, "- %s\n", markdownLine(m.Value))
+		}
+	}
+	if len(facts) > 0 {
+		b.WriteString("\n## Memory\n\n")
+		for _, m := range facts {
+			fmt.Fprintf(b, "- **%s** (%s): %s\n", m.Key, m.Scope, markdownLine(m.Value))
+		}
+	}
+}
+
+// markdownLine folds a multi-line value onto one list-item line.
+func markdownLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
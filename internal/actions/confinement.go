@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// ConfineAgentIdempotent confines agentName to projectID (empty releases it).
+func ConfineAgentIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) (*store.AgentConfinement, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	return store.ConfineAgentIdempotent(ctx, db, agentName, requestID, projectID)
+}
+
+// ConfineProjectFilter returns the project filter a read by agentName must
+// use: the filter itself for an unconfined agent, and the agent's project for
+// a confined one. A confined agent asking for another project gets a
+// *store.ConfinementError.
+func ConfineProjectFilter(ctx context.Context, db *sql.DB, agentName, projectFilter string) (string, error) {
+	confined, err := store.GetAgentConfinement(ctx, db, agentName)
+	if err != nil || confined == "" {
+		return projectFilter, err
+	}
+	if projectFilter != "" && projectFilter != confined {
+		return "", &store.ConfinementError{AgentName: agentName, ProjectID: confined, Target: "project " + projectFilter}
+	}
+	return confined, nil
+}
+
+// CheckConfinedTask rejects taskID when agentName is confined to another project.
+func CheckConfinedTask(ctx context.Context, db *sql.DB, agentName, taskID string) error {
+	return store.CheckConfinedTask(ctx, db, agentName, taskID)
+}
+
+// CheckConfinedMemoryRead rejects reading memory of another project, task, or
+// agent when agentName is confined. Global memory stays readable.
+func CheckConfinedMemoryRead(ctx context.Context, db *sql.DB, agentName, scope, scopeID string) error {
+	return store.CheckConfinedMemory(ctx, db, agentName, scope, scopeID, false)
+}
+
+// checkConfinedMemoryWrite is CheckConfinedMemoryRead for writes, which also
+// rejects global memory.
+func checkConfinedMemoryWrite(ctx context.Context, db *sql.DB, agentName, scope, scopeID string) error {
+	return store.CheckConfinedMemory(ctx, db, agentName, scope, scopeID, true)
+}
+
+// confineProjectID returns the project a write by agentName lands in: the
+// agent's project when it is confined and projectID is empty.
+func confineProjectID(ctx context.Context, db *sql.DB, agentName, projectID string) (string, error) {
+	return ConfineProjectFilter(ctx, db, agentName, projectID)
+}
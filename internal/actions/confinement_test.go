@@ -0,0 +1,66 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestConfinedAgent_StaysInsideItsProject(t *testing.T) {
+	db, _ := setupTestDBWithCleanup(t)
+
+	home, err := store.CreateProject(context.Background(), db, "home", "")
+	require.NoError(t, err)
+	other, err := store.CreateProject(context.Background(), db, "other", "")
+	require.NoError(t, err)
+	foreign, _, err := TaskCreateIdempotent(context.Background(), db, "admin", "req_foreign", "foreign", "", other.ID, 0)
+	require.NoError(t, err)
+
+	_, err = ConfineAgentIdempotent(context.Background(), db, "worker", "req_confine", home.ID)
+	require.NoError(t, err)
+
+	var confErr *store.ConfinementError
+	isConfined := func(err error) bool { return errors.As(err, &confErr) }
+
+	// Writes default to the confined project and cannot leave it.
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "worker", "req_create", "local", "", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, home.ID, task.ProjectID)
+	_, _, err = TaskCreateIdempotent(context.Background(), db, "worker", "req_create_other", "escape", "", other.ID, 0)
+	assert.True(t, isConfined(err), "create in another project: %v", err)
+	_, _, err = TaskSetStatusIdempotent(context.Background(), db, "worker", "req_status", foreign.ID, "in_progress", "")
+	assert.True(t, isConfined(err), "status change on a foreign task: %v", err)
+
+	_, err = MemorySetIdempotent(context.Background(), db, "worker", "req_mem_global", "k", "v", "", "global", "", nil, false, "", nil, "")
+	assert.True(t, isConfined(err), "global memory write: %v", err)
+	_, err = MemorySetIdempotent(context.Background(), db, "worker", "req_mem_other", "k", "v", "", "project", other.ID, nil, false, "", nil, "")
+	assert.True(t, isConfined(err), "other project memory write: %v", err)
+	_, err = MemorySetIdempotent(context.Background(), db, "worker", "req_mem_home", "k", "v", "", "project", home.ID, nil, false, "", nil, "")
+	require.NoError(t, err)
+
+	// Reads of global memory stay open; other projects do not.
+	require.NoError(t, CheckConfinedMemoryRead(context.Background(), db, "worker", "global", ""))
+	assert.True(t, isConfined(CheckConfinedMemoryRead(context.Background(), db, "worker", "project", other.ID)))
+	filter, err := ConfineProjectFilter(context.Background(), db, "worker", "")
+	require.NoError(t, err)
+	assert.Equal(t, home.ID, filter)
+	_, err = ConfineProjectFilter(context.Background(), db, "worker", other.ID)
+	assert.True(t, isConfined(err))
+
+	_, err = ProjectFocusIdempotent(context.Background(), db, "worker", "req_focus", other.ID)
+	assert.True(t, isConfined(err), "focus on another project: %v", err)
+
+	// Release lifts every check.
+	_, err = ConfineAgentIdempotent(context.Background(), db, "worker", "req_release", "")
+	require.NoError(t, err)
+	_, err = MemorySetIdempotent(context.Background(), db, "worker", "req_mem_global_2", "k", "v", "", "global", "", nil, false, "", nil, "")
+	require.NoError(t, err)
+	filter, err = ConfineProjectFilter(context.Background(), db, "worker", other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, filter)
+}
@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// contextLessonLimit maps a profile's lesson cap onto
+// store.BriefBuildOptions.LessonLimit, where 0 defers to config and a negative
+// value means none.
+func contextLessonLimit(p app.ContextProfile) int {
+	switch {
+	case p.Lessons < 0:
+		return 0
+	case p.Lessons == 0:
+		return -1
+	default:
+		return p.Lessons
+	}
+}
+
+// profileLimit returns the first n items, or all of them when n is negative.
+func profileLimit[T any](items []T, n int) []T {
+	if n < 0 || n >= len(items) {
+		return items
+	}
+	return items[:n]
+}
+
+// profileBrief returns a shallow copy of brief cut to the profile's section
+// limits for rendering; the brief returned to callers keeps every section.
+func profileBrief(brief *store.BriefPacket, p app.ContextProfile) *store.BriefPacket {
+	if brief == nil {
+		return nil
+	}
+	shaped := *brief
+	if brief.Task != nil && p.TaskDescriptionRunes >= 0 {
+		task := *brief.Task
+		task.Description, _ = truncatePromptRunes(task.Description, p.TaskDescriptionRunes)
+		shaped.Task = &task
+	}
+	if !p.Dependencies {
+		shaped.Dependencies = nil
+	}
+	shaped.RelevantMemory = profileLimit(brief.RelevantMemory, p.Memory)
+	shaped.Lessons = profileLimit(brief.Lessons, p.Lessons)
+	shaped.PriorReasoning = profileLimit(brief.PriorReasoning, p.History)
+	shaped.RecentEvents = profileEvents(brief.RecentEvents, p.RecentFailures, p.History)
+	return &shaped
+}
+
+// profileEvents keeps up to failures failure events and up to history other
+// events, in their original order.
+func profileEvents(events []*models.Event, failures, history int) []*models.Event {
+	if failures < 0 && history < 0 {
+		return events
+	}
+	kept := make([]*models.Event, 0, len(events))
+	for _, e := range events {
+		limit := &history
+		if store.IsBriefFailureKind(e.Kind) {
+			limit = &failures
+		}
+		if *limit == 0 {
+			continue
+		}
+		if *limit > 0 {
+			*limit--
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
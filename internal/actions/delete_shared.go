@@ -21,7 +21,7 @@ type deleteParams struct {
 	DeleteFn     func(tx *sql.Tx) error
 }
 
-func runDeleteIdempotent(db *sql.DB, p deleteParams) (int64, error) {
+func runDeleteIdempotent(ctx context.Context, db *sql.DB, p deleteParams) (int64, error) {
 	if err := validateAgentRequest(p.AgentName, p.RequestID); err != nil {
 		return 0, err
 	}
@@ -29,7 +29,7 @@ func runDeleteIdempotent(db *sql.DB, p deleteParams) (int64, error) {
 		return 0, fmt.Errorf("%s ID is required", p.ResourceName)
 	}
 
-	r, err := store.RunIdempotent(context.Background(), db, p.AgentName, p.RequestID, p.Command, func(tx *sql.Tx) (eventResult, error) {
+	r, err := store.RunIdempotent(ctx, db, p.AgentName, p.RequestID, p.Command, func(tx *sql.Tx) (eventResult, error) {
 		if err := p.DeleteFn(tx); err != nil {
 			return eventResult{}, err
 		}
@@ -0,0 +1,27 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// EnableEventChainIdempotent starts the tamper-evident event chain once per
+// (agent_name, request_id). signed chains HMAC every event with the audit key.
+func EnableEventChainIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, signed bool) (*store.EventChain, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	return store.EnableEventChainIdempotent(ctx, db, agentName, requestID, signed)
+}
+
+// VerifyEventChain recomputes the event chain and reports where it breaks.
+func VerifyEventChain(ctx context.Context, db *sql.DB) (*store.EventChainReport, error) {
+	report, err := store.VerifyEventChain(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("verify event chain: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,28 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// EventKindRegisterIdempotent registers a custom event kind.
+func EventKindRegisterIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, kind, description string) (*store.EventKindInfo, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	return store.RegisterEventKindIdempotent(ctx, db, agentName, requestID, kind, description)
+}
+
+// EventKindRemoveIdempotent unregisters a custom event kind.
+func EventKindRemoveIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, kind string) (*store.EventKindInfo, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if kind == "" {
+		return nil, errors.New("kind is required")
+	}
+	return store.RemoveEventKindIdempotent(ctx, db, agentName, requestID, kind)
+}
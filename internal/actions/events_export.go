@@ -0,0 +1,28 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseSince parses an events export --since value into the earliest
+// created_at to include: RFC3339, YYYY-MM-DD (midnight UTC), or a window back
+// from now such as 12h, 30d, or 2w. Windows are not capped.
+func ParseSince(raw string, now time.Time) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, errors.New("since value is empty")
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	if d, err := time.Parse(time.DateOnly, raw); err == nil {
+		return d, nil
+	}
+	if d, err := parseDurationExtended(raw); err == nil && d > 0 {
+		return now.Add(-d).UTC().Truncate(time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q: use RFC3339, YYYY-MM-DD, or a window like 12h, 30d, or 2w", raw)
+}
@@ -0,0 +1,38 @@
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	got, err := ParseSince("30d", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -30), got)
+
+	got, err = ParseSince("12h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-12*time.Hour), got)
+
+	got, err = ParseSince("5000d", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -5000), got, "export windows are not capped like retention")
+
+	got, err = ParseSince("2026-01-31", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = ParseSince("2026-02-01T09:30:00+02:00", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 2, 1, 7, 30, 0, 0, time.UTC), got)
+
+	for _, bad := range []string{"", "0d", "-3d", "soon"} {
+		_, err := ParseSince(bad, now)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// FederatedSource is one database in a federated query. Name is the file's
+// base name without extension, or the full path when two base names collide.
+type FederatedSource struct {
+	Name  string `json:"source"`
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+	Error string `json:"error,omitempty"`
+}
+
+// FederatedTask is a task tagged with the database it came from.
+type FederatedTask struct {
+	Source string `json:"source"`
+	*models.Task
+}
+
+// FederatedMemory is a memory entry tagged with the database it came from.
+type FederatedMemory struct {
+	Source string `json:"source"`
+	*models.Memory
+}
+
+// ParseFederatedSources names each database path. Paths may not repeat.
+func ParseFederatedSources(paths []string) ([]FederatedSource, error) {
+	var sources []FederatedSource
+	seen := map[string]bool{}
+	names := map[string]int{}
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		if seen[abs] {
+			return nil, errors.New("database listed twice: " + p)
+		}
+		seen[abs] = true
+		name := strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+		names[name]++
+		sources = append(sources, FederatedSource{Name: name, Path: p})
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("at least one database is required")
+	}
+	for i, s := range sources {
+		if names[s.Name] > 1 {
+			sources[i].Name = s.Path
+		}
+	}
+	return sources, nil
+}
+
+// federate runs query against each source opened read-only. A source that
+// fails to open or query records its error and is skipped, so one stale or
+// missing database does not hide the rest of the fleet.
+func federate[T any](ctx context.Context, sources []FederatedSource, query func(db *sql.DB, source string) ([]T, error)) []T {
+	var out []T
+	for i := range sources {
+		rows, err := federateOne(ctx, sources[i], query)
+		if err != nil {
+			sources[i].Error = err.Error()
+			continue
+		}
+		sources[i].Count = len(rows)
+		out = append(out, rows...)
+	}
+	return out
+}
+
+func federateOne[T any](ctx context.Context, src FederatedSource, query func(db *sql.DB, source string) ([]T, error)) ([]T, error) {
+	db, err := store.OpenDBReadOnly(ctx, src.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+	return query(db, src.Name)
+}
+
+// FederatedTaskList runs a task list across sources and merges the results,
+// highest priority first, then oldest first. Per-source errors are recorded on
+// sources.
+func FederatedTaskList(ctx context.Context, sources []FederatedSource, statusFilter string, priorityFilter int, metaFilters []string) ([]FederatedTask, error) {
+	if _, err := store.ParseTaskMetaFilters(metaFilters); err != nil {
+		return nil, err
+	}
+	tasks := federate(ctx, sources, func(db *sql.DB, source string) ([]FederatedTask, error) {
+		list, err := TaskListByMeta(ctx, db, statusFilter, "", priorityFilter, metaFilters)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]FederatedTask, len(list))
+		for i, t := range list {
+			out[i] = FederatedTask{Source: source, Task: t}
+		}
+		return out, nil
+	})
+	slices.SortStableFunc(tasks, func(a, b FederatedTask) int {
+		if a.Priority != b.Priority {
+			return b.Priority - a.Priority
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return tasks, nil
+}
+
+// FederatedMemoryList lists one memory scope (optionally under a key prefix)
+// across sources, in source order.
+func FederatedMemoryList(ctx context.Context, sources []FederatedSource, scope, scopeID, prefix string) []FederatedMemory {
+	return federate(ctx, sources, func(db *sql.DB, source string) ([]FederatedMemory, error) {
+		list, err := store.ListMemoryWithPrefix(ctx, db, scope, scopeID, prefix)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]FederatedMemory, len(list))
+		for i, m := range list {
+			out[i] = FederatedMemory{Source: source, Memory: m}
+		}
+		return out, nil
+	})
+}
@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestFederatedTaskList_MergesSourcesAndReportsFailures(t *testing.T) {
+	dir := t.TempDir()
+	apiPath := filepath.Join(dir, "api.db")
+	webPath := filepath.Join(dir, "web.db")
+	for path, prio := range map[string]int{apiPath: 1, webPath: 7} {
+		db, err := store.InitDBWithPath(path)
+		require.NoError(t, err)
+		task, _, err := TaskCreateIdempotent(context.Background(), db, "agent1", "create-1", "Blocked in "+filepath.Base(path), "", "", prio)
+		require.NoError(t, err)
+		_, _, err = TaskSetStatusIdempotent(context.Background(), db, "agent1", "block-1", task.ID, "blocked", "")
+		require.NoError(t, err)
+		_, err = store.CreateTask(context.Background(), db, "Pending elsewhere", "", "", 9)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+	}
+
+	sources, err := ParseFederatedSources([]string{apiPath, webPath, filepath.Join(dir, "gone.db")})
+	require.NoError(t, err)
+	tasks, err := FederatedTaskList(context.Background(), sources, "blocked", -1, nil)
+	require.NoError(t, err)
+
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "web", tasks[0].Source, "higher priority first across databases")
+	assert.Equal(t, "api", tasks[1].Source)
+	assert.Equal(t, 1, sources[0].Count)
+	assert.Equal(t, 1, sources[1].Count)
+	assert.Contains(t, sources[2].Error, "failed to open database")
+
+	_, err = ParseFederatedSources([]string{apiPath, apiPath})
+	assert.ErrorContains(t, err, "listed twice")
+}
+
+func TestParseFederatedSources_DisambiguatesNames(t *testing.T) {
+	sources, err := ParseFederatedSources([]string{"a/vybe.db", "b/vybe.db", "c/other.db"})
+	require.NoError(t, err)
+	assert.Equal(t, "a/vybe.db", sources[0].Name)
+	assert.Equal(t, "b/vybe.db", sources[1].Name)
+	assert.Equal(t, "other", sources[2].Name)
+}
+
+func TestOpenDBReadOnly_RejectsWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ro.db")
+	db, err := store.InitDBWithPath(path)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	ro, err := store.OpenDBReadOnly(context.Background(), path)
+	require.NoError(t, err)
+	defer func() { _ = ro.Close() }()
+	_, err = store.CreateTask(context.Background(), ro, "Nope", "", "", 0)
+	assert.Error(t, err)
+}
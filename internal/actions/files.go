@@ -0,0 +1,41 @@
+package actions
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// FilesTouched returns the files changed by the tool_success events p selects,
+// most recently changed first, capped at limit (0 = no cap).
+func FilesTouched(ctx context.Context, db *sql.DB, p store.FileJournalParams, limit int) ([]store.FileChange, error) {
+	files, err := store.ListFileChanges(ctx, db, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list touched files: %w", err)
+	}
+	return capFileChanges(files, limit), nil
+}
+
+// FilesHot returns the files changed most often in the last sinceDays days
+// (all projects when projectID is empty), most edits first, capped at limit.
+func FilesHot(ctx context.Context, db *sql.DB, projectID string, sinceDays, limit int) ([]store.FileChange, error) {
+	files, err := store.ListFileChanges(ctx, db, store.FileJournalParams{ProjectID: projectID, SinceDays: sinceDays})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hot files: %w", err)
+	}
+	slices.SortStableFunc(files, func(a, b store.FileChange) int {
+		return cmp.Compare(b.Edits, a.Edits)
+	})
+	return capFileChanges(files, limit), nil
+}
+
+func capFileChanges(files []store.FileChange, limit int) []store.FileChange {
+	if limit > 0 && len(files) > limit {
+		return files[:limit]
+	}
+	return files
+}
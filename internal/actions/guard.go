@@ -0,0 +1,131 @@
+package actions
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+// guardEditTools are the Claude Code tools that write files; the
+// edit_outside_project and require_focus_task rules apply to them by default.
+var guardEditTools = []string{"Write", "Edit", "MultiEdit", "NotebookEdit"} //nolint:gochecknoglobals // fixed list of file-writing tools
+
+// GuardCall describes one tool call for EvaluateGuard.
+type GuardCall struct {
+	Tool string
+	// Command is the Bash command, if any.
+	Command string
+	// Path is the file an edit tool writes, absolute or relative to ProjectDir.
+	Path string
+	// ProjectDir bounds edit_outside_project.
+	ProjectDir string
+	// HasFocusTask reports whether the agent has a focus task. It is only
+	// called when a require_focus_task policy applies, since it needs the DB.
+	HasFocusTask func() bool
+}
+
+// GuardVerdict is the decision of the policy that matched a tool call.
+type GuardVerdict struct {
+	Policy   string `json:"policy"`
+	Rule     string `json:"rule"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+// EvaluateGuard applies policies to call and returns the verdict of the first
+// matching deny policy, else the first matching ask policy, or nil when the
+// call is allowed.
+func EvaluateGuard(policies []app.GuardPolicy, call GuardCall) *GuardVerdict {
+	var ask *GuardVerdict
+	for _, p := range policies {
+		rule := p.Rule
+		if rule == "" {
+			rule = app.GuardRuleCommand
+		}
+		if !guardPolicyApplies(p, rule, call.Tool) || !guardRuleMatches(p, rule, call) {
+			continue
+		}
+		v := &GuardVerdict{Policy: p.Name, Rule: rule, Decision: p.Decision, Reason: guardReason(p, rule)}
+		if p.Decision == app.GuardDeny {
+			return v
+		}
+		if ask == nil {
+			ask = v
+		}
+	}
+	return ask
+}
+
+func guardPolicyApplies(p app.GuardPolicy, rule, tool string) bool {
+	if len(p.Tools) > 0 {
+		return slices.Contains(p.Tools, tool)
+	}
+	if rule == app.GuardRuleCommand {
+		return tool == "Bash"
+	}
+	return slices.Contains(guardEditTools, tool)
+}
+
+func guardRuleMatches(p app.GuardPolicy, rule string, call GuardCall) bool {
+	switch rule {
+	case app.GuardRuleCommand:
+		re, err := regexp.Compile(p.Pattern)
+		return err == nil && call.Command != "" && re.MatchString(call.Command)
+	case app.GuardRuleEditOutsideProject:
+		return call.Path != "" && call.ProjectDir != "" && !pathWithin(call.ProjectDir, call.Path)
+	case app.GuardRuleRequireFocusTask:
+		return call.HasFocusTask != nil && !call.HasFocusTask()
+	}
+	return false
+}
+
+func guardReason(p app.GuardPolicy, rule string) string {
+	reason := p.Reason
+	if reason == "" {
+		switch rule {
+		case app.GuardRuleEditOutsideProject:
+			reason = "file is outside the project directory"
+		case app.GuardRuleRequireFocusTask:
+			reason = "no focus task; claim one with 'vybe resume' before editing"
+		default:
+			reason = "command matches a blocked pattern"
+		}
+	}
+	if p.Name != "" {
+		reason += " (vybe guard policy " + p.Name + ")"
+	}
+	return reason
+}
+
+// pathWithin reports whether path is dir or below it, after resolving
+// symlinks where the paths exist. A relative path is taken relative to dir.
+func pathWithin(dir, path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	rel, err := filepath.Rel(resolveExisting(dir), resolveExisting(path))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolveExisting evaluates symlinks in the longest existing prefix of path.
+func resolveExisting(path string) string {
+	path = filepath.Clean(path)
+	var rest []string
+	for cur := path; ; {
+		if resolved, err := filepath.EvalSymlinks(cur); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...)
+		}
+		parent := filepath.Dir(cur)
+		if parent == cur {
+			return path
+		}
+		rest = append([]string{filepath.Base(cur)}, rest...)
+		cur = parent
+	}
+}
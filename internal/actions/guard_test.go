@@ -0,0 +1,56 @@
+package actions
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+func TestEvaluateGuard_DefaultPolicies(t *testing.T) {
+	project := t.TempDir()
+	policies := app.DefaultGuardPolicies()
+
+	for _, cmd := range []string{"rm -rf build", "sudo rm -fr /", "rm -Rf ~/x", "rm -r -f dist"} {
+		v := EvaluateGuard(policies, GuardCall{Tool: "Bash", Command: cmd})
+		require.NotNil(t, v, cmd)
+		require.Equal(t, app.GuardDeny, v.Decision, cmd)
+		require.Equal(t, "no-rm-rf", v.Policy)
+	}
+	for _, cmd := range []string{"rm -r build", "rm file.txt", "git rm -r --cached x", "echo firm -rf"} {
+		require.Nil(t, EvaluateGuard(policies, GuardCall{Tool: "Bash", Command: cmd}), cmd)
+	}
+
+	require.Nil(t, EvaluateGuard(policies, GuardCall{Tool: "Edit", Path: filepath.Join(project, "a", "b.go"), ProjectDir: project}))
+	require.Nil(t, EvaluateGuard(policies, GuardCall{Tool: "Edit", Path: "rel/b.go", ProjectDir: project}))
+	v := EvaluateGuard(policies, GuardCall{Tool: "Write", Path: filepath.Join(project, "..", "elsewhere.go"), ProjectDir: project})
+	require.NotNil(t, v)
+	require.Equal(t, app.GuardAsk, v.Decision)
+	require.Contains(t, v.Reason, "edits-in-project")
+
+	// Rules only apply to their tools: Read never counts as an edit.
+	require.Nil(t, EvaluateGuard(policies, GuardCall{Tool: "Read", Path: "/etc/passwd", ProjectDir: project}))
+}
+
+func TestEvaluateGuard_FocusTaskAndPrecedence(t *testing.T) {
+	calls := 0
+	noFocus := func() bool { calls++; return false }
+	policies := []app.GuardPolicy{
+		{Name: "ask-git", Rule: app.GuardRuleCommand, Pattern: `^git push`, Decision: app.GuardAsk},
+		{Name: "focus", Rule: app.GuardRuleRequireFocusTask, Decision: app.GuardDeny},
+		{Name: "no-force", Pattern: `--force`, Tools: []string{"Bash"}, Decision: app.GuardDeny},
+	}
+
+	// Deny wins over an earlier ask.
+	v := EvaluateGuard(policies, GuardCall{Tool: "Bash", Command: "git push --force", HasFocusTask: noFocus})
+	require.Equal(t, "no-force", v.Policy)
+	require.Zero(t, calls, "focus is only looked up for edit tools")
+
+	v = EvaluateGuard(policies, GuardCall{Tool: "Edit", Path: "x.go", HasFocusTask: noFocus})
+	require.Equal(t, "focus", v.Policy)
+	require.Equal(t, 1, calls)
+
+	require.Nil(t, EvaluateGuard(policies, GuardCall{Tool: "Edit", Path: "x.go", HasFocusTask: func() bool { return true }}))
+}
@@ -17,9 +17,9 @@ func TestTaskCreateIdempotent_Replay(t *testing.T) {
 	agent := "agent1"
 	req := "req_task_create"
 
-	task1, eid1, err := TaskCreateIdempotent(db, agent, req, "t1", "d1", "", 0)
+	task1, eid1, err := TaskCreateIdempotent(context.Background(), db, agent, req, "t1", "d1", "", 0)
 	require.NoError(t, err)
-	task2, eid2, err := TaskCreateIdempotent(db, agent, req, "t1", "d1", "", 0)
+	task2, eid2, err := TaskCreateIdempotent(context.Background(), db, agent, req, "t1", "d1", "", 0)
 	require.NoError(t, err)
 
 	require.Equal(t, task1.ID, task2.ID)
@@ -41,9 +41,9 @@ func TestMemorySetIdempotent_Replay(t *testing.T) {
 	agent := "agent1"
 	req := "req_mem_set"
 
-	eid1, err := MemorySetIdempotent(db, agent, req, "k", "v", "", "global", "", nil, false, "", nil, "")
+	eid1, err := MemorySetIdempotent(context.Background(), db, agent, req, "k", "v", "", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
-	eid2, err := MemorySetIdempotent(db, agent, req, "k", "v", "", "global", "", nil, false, "", nil, "")
+	eid2, err := MemorySetIdempotent(context.Background(), db, agent, req, "k", "v", "", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 	require.Equal(t, eid1, eid2)
 
@@ -63,17 +63,17 @@ func TestResumeIdempotent_Replay(t *testing.T) {
 	agent := "agent1"
 
 	// Seed: agent state + one task + one event so resume has deltas.
-	_, err := store.LoadOrCreateAgentState(db, agent)
+	_, err := store.LoadOrCreateAgentState(context.Background(), db, agent)
 	require.NoError(t, err)
-	task, _, err := TaskCreateIdempotent(db, agent, "req_seed_task", "t1", "d1", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, agent, "req_seed_task", "t1", "d1", "", 0)
 	require.NoError(t, err)
-	_, err = store.AppendEventIdempotent(db, agent, "req_seed_event", "progress", task.ID, "p1")
+	_, err = store.AppendEventIdempotent(context.Background(), db, agent, "req_seed_event", "progress", task.ID, "p1")
 	require.NoError(t, err)
 
 	req := "req_resume"
-	r1, err := ResumeWithOptionsIdempotent(db, agent, req, ResumeOptions{EventLimit: 1000})
+	r1, err := ResumeWithOptionsIdempotent(context.Background(), db, agent, req, ResumeOptions{EventLimit: 1000})
 	require.NoError(t, err)
-	r2, err := ResumeWithOptionsIdempotent(db, agent, req, ResumeOptions{EventLimit: 1000})
+	r2, err := ResumeWithOptionsIdempotent(context.Background(), db, agent, req, ResumeOptions{EventLimit: 1000})
 	require.NoError(t, err)
 
 	require.Equal(t, r1.NewCursor, r2.NewCursor)
@@ -86,13 +86,13 @@ func TestTaskStartIdempotent_Replay(t *testing.T) {
 	defer cleanup()
 
 	agent := "agent1"
-	task, _, err := TaskCreateIdempotent(db, agent, "req_seed_taskstart", "t1", "d1", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, agent, "req_seed_taskstart", "t1", "d1", "", 0)
 	require.NoError(t, err)
 
 	req := "req_task_start"
-	r1, err := TaskStartIdempotent(db, agent, req, task.ID)
+	r1, err := TaskStartIdempotent(context.Background(), db, agent, req, task.ID)
 	require.NoError(t, err)
-	r2, err := TaskStartIdempotent(db, agent, req, task.ID)
+	r2, err := TaskStartIdempotent(context.Background(), db, agent, req, task.ID)
 	require.NoError(t, err)
 
 	require.Equal(t, r1.Task.ID, r2.Task.ID)
@@ -110,13 +110,13 @@ func TestTaskSetStatusIdempotent_Replay(t *testing.T) {
 	defer cleanup()
 
 	agent := "agent1"
-	task, _, err := TaskCreateIdempotent(db, agent, "req_seed_taskstatus", "t1", "d1", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, agent, "req_seed_taskstatus", "t1", "d1", "", 0)
 	require.NoError(t, err)
 
 	req := "req_task_set_status"
-	t1, eid1, err := TaskSetStatusIdempotent(db, agent, req, task.ID, "blocked", "")
+	t1, eid1, err := TaskSetStatusIdempotent(context.Background(), db, agent, req, task.ID, "blocked", "")
 	require.NoError(t, err)
-	t2, eid2, err := TaskSetStatusIdempotent(db, agent, req, task.ID, "blocked", "")
+	t2, eid2, err := TaskSetStatusIdempotent(context.Background(), db, agent, req, task.ID, "blocked", "")
 	require.NoError(t, err)
 
 	require.Equal(t, t1.ID, t2.ID)
@@ -137,7 +137,7 @@ func TestMemoryDeleteIdempotent_Replay(t *testing.T) {
 	defer cleanup()
 
 	agent := "agent1"
-	_, err := MemorySetIdempotent(db, agent, "req_seed_memdel", "k", "v", "", "global", "", nil, false, "", nil, "")
+	_, err := MemorySetIdempotent(context.Background(), db, agent, "req_seed_memdel", "k", "v", "", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 
 	req := "req_mem_delete"
@@ -161,13 +161,13 @@ func TestArtifactAddIdempotent_Replay(t *testing.T) {
 	defer cleanup()
 
 	agent := "agent1"
-	task, _, err := TaskCreateIdempotent(db, agent, "req_seed_artifact", "t1", "d1", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, agent, "req_seed_artifact", "t1", "d1", "", 0)
 	require.NoError(t, err)
 
 	req := "req_artifact_add"
-	a1, eid1, err := ArtifactAddIdempotent(db, agent, req, task.ID, "/tmp/out.txt", "text/plain")
+	a1, eid1, err := ArtifactAddIdempotent(context.Background(), db, agent, req, task.ID, "/tmp/out.txt", "text/plain")
 	require.NoError(t, err)
-	a2, eid2, err := ArtifactAddIdempotent(db, agent, req, task.ID, "/tmp/out.txt", "text/plain")
+	a2, eid2, err := ArtifactAddIdempotent(context.Background(), db, agent, req, task.ID, "/tmp/out.txt", "text/plain")
 	require.NoError(t, err)
 
 	require.Equal(t, a1.ID, a2.ID)
@@ -187,13 +187,13 @@ func TestTaskDeleteIdempotent_Replay(t *testing.T) {
 	defer cleanup()
 
 	agent := "agent1"
-	task, _, err := TaskCreateIdempotent(db, agent, "req_seed_task_delete", "t1", "d1", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, agent, "req_seed_task_delete", "t1", "d1", "", 0)
 	require.NoError(t, err)
 
 	req := "req_task_delete"
-	eid1, err := TaskDeleteIdempotent(db, agent, req, task.ID)
+	eid1, err := TaskDeleteIdempotent(context.Background(), db, agent, req, task.ID)
 	require.NoError(t, err)
-	eid2, err := TaskDeleteIdempotent(db, agent, req, task.ID)
+	eid2, err := TaskDeleteIdempotent(context.Background(), db, agent, req, task.ID)
 	require.NoError(t, err)
 	require.Equal(t, eid1, eid2)
 
@@ -211,13 +211,13 @@ func TestProjectDeleteIdempotent_Replay(t *testing.T) {
 	defer cleanup()
 
 	agent := "agent1"
-	project, _, err := ProjectCreateIdempotent(db, agent, "req_seed_project_delete", "proj1", "")
+	project, _, err := ProjectCreateIdempotent(context.Background(), db, agent, "req_seed_project_delete", "proj1", "")
 	require.NoError(t, err)
 
 	req := "req_project_delete"
-	eid1, err := ProjectDeleteIdempotent(db, agent, req, project.ID)
+	eid1, err := ProjectDeleteIdempotent(context.Background(), db, agent, req, project.ID)
 	require.NoError(t, err)
-	eid2, err := ProjectDeleteIdempotent(db, agent, req, project.ID)
+	eid2, err := ProjectDeleteIdempotent(context.Background(), db, agent, req, project.ID)
 	require.NoError(t, err)
 	require.Equal(t, eid1, eid2)
 
@@ -237,7 +237,7 @@ func TestTaskCreateIdempotent_ReplayAfterDeletion(t *testing.T) {
 	agent := "agent1"
 	req := "req_task_create_del"
 
-	task1, eid1, err := TaskCreateIdempotent(db, agent, req, "t1", "d1", "", 0)
+	task1, eid1, err := TaskCreateIdempotent(context.Background(), db, agent, req, "t1", "d1", "", 0)
 	require.NoError(t, err)
 	require.NotNil(t, task1)
 
@@ -246,7 +246,7 @@ func TestTaskCreateIdempotent_ReplayAfterDeletion(t *testing.T) {
 	require.NoError(t, err)
 
 	// Replay with the same request-id: must return the original snapshot, not an error.
-	task2, eid2, err := TaskCreateIdempotent(db, agent, req, "t1", "d1", "", 0)
+	task2, eid2, err := TaskCreateIdempotent(context.Background(), db, agent, req, "t1", "d1", "", 0)
 	require.NoError(t, err)
 	require.NotNil(t, task2)
 
@@ -264,7 +264,7 @@ func TestProjectCreateIdempotent_ReplayAfterDeletion(t *testing.T) {
 	agent := "agent1"
 	req := "req_project_create_del"
 
-	proj1, eid1, err := ProjectCreateIdempotent(db, agent, req, "MyProject", "")
+	proj1, eid1, err := ProjectCreateIdempotent(context.Background(), db, agent, req, "MyProject", "")
 	require.NoError(t, err)
 	require.NotNil(t, proj1)
 
@@ -273,7 +273,7 @@ func TestProjectCreateIdempotent_ReplayAfterDeletion(t *testing.T) {
 	require.NoError(t, err)
 
 	// Replay with the same request-id: must return the original snapshot, not an error.
-	proj2, eid2, err := ProjectCreateIdempotent(db, agent, req, "MyProject", "")
+	proj2, eid2, err := ProjectCreateIdempotent(context.Background(), db, agent, req, "MyProject", "")
 	require.NoError(t, err)
 	require.NotNil(t, proj2)
 
@@ -36,7 +36,19 @@ func validateTaskID(taskID string) error {
 	return nil
 }
 
-func runCreateWithEvent[T any](
+// validateTaskMutation checks a request to change taskID, including that a
+// confined agent stays inside its project.
+func validateTaskMutation(ctx context.Context, db *sql.DB, agentName, requestID, taskID string) error {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return err
+	}
+	if err := validateTaskID(taskID); err != nil {
+		return err
+	}
+	return CheckConfinedTask(ctx, db, agentName, taskID)
+}
+
+func runCreateWithEvent[T any](ctx context.Context,
 	db *sql.DB,
 	agentName, requestID, command, action string,
 	operation func(tx *sql.Tx) (T, int64, error),
@@ -45,7 +57,7 @@ func runCreateWithEvent[T any](
 		return nil, 0, err
 	}
 
-	r, err := store.RunIdempotent(context.Background(), db, agentName, requestID, command, func(tx *sql.Tx) (createWithEventResult[T], error) {
+	r, err := store.RunIdempotent(ctx, db, agentName, requestID, command, func(tx *sql.Tx) (createWithEventResult[T], error) {
 		value, eventID, err := operation(tx)
 		if err != nil {
 			return createWithEventResult[T]{}, err
@@ -70,22 +82,28 @@ func retryOnResumeConflict(err error) bool {
 		store.IsVersionConflict(err)
 }
 
-func runTaskMutationWithRetry[T any](
+// checkIfVersion enforces a --if-version compare-and-set precondition inside a
+// task mutation; a nil ifVersion skips the check.
+func checkIfVersion(tx *sql.Tx, taskID string, ifVersion *int) error {
+	if ifVersion == nil {
+		return nil
+	}
+	return store.CheckTaskVersionTx(tx, taskID, *ifVersion)
+}
+
+func runTaskMutationWithRetry[T any](ctx context.Context,
 	db *sql.DB,
 	agentName, requestID, taskID, command, taskState string,
 	operation func(tx *sql.Tx) (T, error),
 ) (*models.Task, T, error) {
 	var zero T
 
-	if err := validateAgentRequest(agentName, requestID); err != nil {
-		return nil, zero, err
-	}
-	if err := validateTaskID(taskID); err != nil {
+	if err := validateTaskMutation(ctx, db, agentName, requestID, taskID); err != nil {
 		return nil, zero, err
 	}
 
 	result, _, err := store.RunIdempotentWithRetry(
-		context.Background(),
+		ctx,
 		db,
 		agentName,
 		requestID,
@@ -98,7 +116,7 @@ func runTaskMutationWithRetry[T any](
 		return nil, zero, err
 	}
 
-	task, err := store.GetTask(db, taskID)
+	task, err := store.GetTask(ctx, db, taskID)
 	if err != nil {
 		return nil, zero, fmt.Errorf("failed to fetch %s task: %w", taskState, err)
 	}
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -10,7 +11,7 @@ func TestResumeWithOptionsIdempotent_WrapperPath(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	resp, err := ResumeWithOptionsIdempotent(db, "agent-a", "req-resume-1", ResumeOptions{EventLimit: 1000})
+	resp, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent-a", "req-resume-1", ResumeOptions{EventLimit: 1000})
 	require.NoError(t, err)
 	require.NotNil(t, resp)
 }
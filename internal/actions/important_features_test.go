@@ -13,7 +13,7 @@ func TestMemoryDeleteIdempotent_ReplayWrapper(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	_, err := MemorySetIdempotent(db, "agent-a", "req-mem-setup-1", "k2", "v2", "", "global", "", nil, false, "", nil, "")
+	_, err := MemorySetIdempotent(context.Background(), db, "agent-a", "req-mem-setup-1", "k2", "v2", "", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 
 	first, err := MemoryDeleteIdempotent(context.Background(), db, "agent-a", "req-del-1", "k2", "global", "")
@@ -37,9 +37,9 @@ func TestProjectCreateIdempotent_Replay(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	p1, e1, err := ProjectCreateIdempotent(db, "agent-a", "req-proj-1", "proj-x", "")
+	p1, e1, err := ProjectCreateIdempotent(context.Background(), db, "agent-a", "req-proj-1", "proj-x", "")
 	require.NoError(t, err)
-	p2, e2, err := ProjectCreateIdempotent(db, "agent-a", "req-proj-1", "proj-x", "")
+	p2, e2, err := ProjectCreateIdempotent(context.Background(), db, "agent-a", "req-proj-1", "proj-x", "")
 	require.NoError(t, err)
 	require.Equal(t, p1.ID, p2.ID)
 	require.Equal(t, e1, e2)
@@ -49,12 +49,12 @@ func TestProjectFocusIdempotent_Replay(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	project, _, err := ProjectCreateIdempotent(db, "agent-a", "req-proj-focus-setup", "proj-focus", "")
+	project, _, err := ProjectCreateIdempotent(context.Background(), db, "agent-a", "req-proj-focus-setup", "proj-focus", "")
 	require.NoError(t, err)
 
-	e1, err := ProjectFocusIdempotent(db, "agent-a", "req-focus-1", project.ID)
+	e1, err := ProjectFocusIdempotent(context.Background(), db, "agent-a", "req-focus-1", project.ID)
 	require.NoError(t, err)
-	e2, err := ProjectFocusIdempotent(db, "agent-a", "req-focus-1", project.ID)
+	e2, err := ProjectFocusIdempotent(context.Background(), db, "agent-a", "req-focus-1", project.ID)
 	require.NoError(t, err)
 	require.Equal(t, e1, e2)
 }
@@ -63,10 +63,10 @@ func TestTaskStartIdempotent_Works(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	a, err := store.CreateTask(db, "a", "", "", 0)
+	a, err := store.CreateTask(context.Background(), db, "a", "", "", 0)
 	require.NoError(t, err)
 
-	startResult, err := TaskStartIdempotent(db, "agent-a", "req-start-1", a.ID)
+	startResult, err := TaskStartIdempotent(context.Background(), db, "agent-a", "req-start-1", a.ID)
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, startResult.StatusEventID, int64(0))
 	require.Greater(t, startResult.FocusEventID, int64(0))
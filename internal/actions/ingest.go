@@ -0,0 +1,356 @@
+package actions
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// Supported ingest sources.
+const (
+	IngestSourceMem0 = "mem0"
+	IngestSourceZep  = "zep"
+)
+
+// IngestOptions controls how foreign memory exports are mapped into vybe memory.
+type IngestOptions struct {
+	// Scope/ScopeID override the per-record scope mapping when Scope is set.
+	Scope   string
+	ScopeID string
+	// MinConfidence drops records whose source confidence is known and below this value.
+	MinConfidence float64
+	// Now is the reference time for expiry checks; zero means time.Now().
+	Now time.Time
+}
+
+// IngestRecord is one foreign memory mapped onto vybe memory fields.
+// Confidence is the source product's confidence/rating when it exported one;
+// vybe memory has no confidence column, so it is carried in the ingest event metadata.
+type IngestRecord struct {
+	Key        string     `json:"key"`
+	Value      string     `json:"value"`
+	Scope      string     `json:"scope"`
+	ScopeID    string     `json:"scope_id,omitempty"`
+	Kind       string     `json:"kind"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	Confidence *float64   `json:"confidence,omitempty"`
+	SourceID   string     `json:"source_id,omitempty"`
+}
+
+// IngestSkip explains why a foreign record was not imported.
+type IngestSkip struct {
+	SourceID string `json:"source_id,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// IngestPlan is the parsed, mapped form of an export file.
+type IngestPlan struct {
+	Source  string         `json:"source"`
+	Records []IngestRecord `json:"records"`
+	Skipped []IngestSkip   `json:"skipped,omitempty"`
+}
+
+// IngestResult is the outcome of writing an IngestPlan.
+type IngestResult struct {
+	Source   string `json:"source"`
+	EventID  int64  `json:"event_id"`
+	Imported int    `json:"imported"`
+	Skipped  int    `json:"skipped"`
+}
+
+// PlanIngest parses an export from source ("mem0" or "zep") and maps it to memory records.
+func PlanIngest(source string, data []byte, opts IngestOptions) (*IngestPlan, error) {
+	if opts.Scope != "" {
+		if err := validateIngestScope(opts.Scope, opts.ScopeID); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	var plan *IngestPlan
+	var err error
+	switch source {
+	case IngestSourceMem0:
+		plan, err = planMem0(data, opts)
+	case IngestSourceZep:
+		plan, err = planZep(data, opts)
+	default:
+		return nil, fmt.Errorf("unsupported ingest source: %q (must be one of: %s, %s)", source, IngestSourceMem0, IngestSourceZep)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// IngestMemoryIdempotent writes a plan in one transaction. A memory_ingested event
+// summarizing the import is written first and linked as source_event_id on every record.
+func IngestMemoryIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, plan *IngestPlan) (_ *IngestResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, errors.New("ingest plan is required")
+	}
+
+	confidence := make(map[string]float64)
+	for _, r := range plan.Records {
+		if r.Confidence != nil {
+			confidence[r.Key] = *r.Confidence
+		}
+	}
+	meta, err := json.Marshal(struct {
+		Source     string             `json:"source"`
+		Imported   int                `json:"imported"`
+		Skipped    int                `json:"skipped"`
+		Confidence map[string]float64 `json:"confidence,omitempty"`
+	}{Source: plan.Source, Imported: len(plan.Records), Skipped: len(plan.Skipped), Confidence: confidence})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ingest metadata: %w", err)
+	}
+
+	r, err := store.RunIdempotent(ctx, db, agentName, requestID, "memory.ingest", func(tx *sql.Tx) (IngestResult, error) {
+		eventID, err := store.InsertEventTx(tx, models.EventKindMemoryIngested, agentName, "",
+			fmt.Sprintf("Ingested %d memories from %s", len(plan.Records), plan.Source), string(meta))
+		if err != nil {
+			return IngestResult{}, fmt.Errorf("failed to insert ingest event: %w", err)
+		}
+		for _, rec := range plan.Records {
+			sourceEventID := eventID
+			if _, err := store.UpsertMemoryTx(tx, agentName, rec.Key, rec.Value, "", rec.Scope, rec.ScopeID,
+				rec.ExpiresAt, false, rec.Kind, nil, &sourceEventID, ""); err != nil {
+				return IngestResult{}, fmt.Errorf("failed to upsert memory %q: %w", rec.Key, err)
+			}
+		}
+		return IngestResult{Source: plan.Source, EventID: eventID, Imported: len(plan.Records), Skipped: len(plan.Skipped)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func validateIngestScope(scope, scopeID string) error {
+	switch models.MemoryScope(scope) {
+	case models.MemoryScopeGlobal:
+		if scopeID != "" {
+			return errors.New("global scope cannot have a scope_id")
+		}
+	case models.MemoryScopeProject, models.MemoryScopeTask, models.MemoryScopeAgent:
+		if scopeID == "" {
+			return fmt.Errorf("%s scope requires a scope_id", scope)
+		}
+	default:
+		return fmt.Errorf("invalid scope: %s (must be one of: global, project, task, agent)", scope)
+	}
+	return nil
+}
+
+// unwrapExportList accepts a bare JSON array or an object wrapping one under any of keys.
+func unwrapExportList(data []byte, keys ...string) ([]json.RawMessage, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return nil, errors.New("export file is empty")
+	}
+	var list []json.RawMessage
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("invalid export JSON: %w", err)
+		}
+		return list, nil
+	}
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, fmt.Errorf("invalid export JSON: %w", err)
+	}
+	for _, k := range keys {
+		raw, ok := wrapper[k]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, fmt.Errorf("invalid export JSON: %q must be an array: %w", k, err)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("export JSON has no record list (expected an array or one of: %s)", strings.Join(keys, ", "))
+}
+
+// ingestKey builds a stable memory key from a source id, falling back to a content hash.
+func ingestKey(source, id, value string) string {
+	if id == "" {
+		sum := sha256.Sum256([]byte(value))
+		id = hex.EncodeToString(sum[:8])
+	}
+	return source + "." + id
+}
+
+// clampConfidence normalizes a 0..1 or 0..100 score; nil means unknown.
+func clampConfidence(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	if c > 1 && c <= 100 {
+		c /= 100
+	}
+	c = max(0, min(1, c))
+	return &c
+}
+
+// floatFromAny reads a JSON number or numeric string.
+func floatFromAny(v any) *float64 {
+	switch n := v.(type) {
+	case float64:
+		return &n
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(n), 64); err == nil {
+			return &f
+		}
+	}
+	return nil
+}
+
+// parseExportTime accepts RFC3339 timestamps with or without fractional seconds.
+func parseExportTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// admitRecord applies scope override, confidence floor, and expiry filtering.
+func admitRecord(plan *IngestPlan, rec IngestRecord, opts IngestOptions) {
+	if rec.Value == "" {
+		plan.Skipped = append(plan.Skipped, IngestSkip{SourceID: rec.SourceID, Reason: "empty value"})
+		return
+	}
+	if rec.Confidence != nil && *rec.Confidence < opts.MinConfidence {
+		plan.Skipped = append(plan.Skipped, IngestSkip{SourceID: rec.SourceID, Reason: fmt.Sprintf("confidence %.2f below minimum", *rec.Confidence)})
+		return
+	}
+	if rec.ExpiresAt != nil && !rec.ExpiresAt.After(opts.Now) {
+		plan.Skipped = append(plan.Skipped, IngestSkip{SourceID: rec.SourceID, Reason: "already expired or invalidated"})
+		return
+	}
+	if opts.Scope != "" {
+		rec.Scope, rec.ScopeID = opts.Scope, opts.ScopeID
+	}
+	if rec.Kind == "" || !models.MemoryKind(rec.Kind).IsValid() {
+		rec.Kind = string(models.MemoryKindFact)
+	}
+	plan.Records = append(plan.Records, rec)
+}
+
+// mem0Memory is one entry from a mem0 get_all/export payload.
+type mem0Memory struct {
+	ID             string         `json:"id"`
+	Memory         string         `json:"memory"`
+	Text           string         `json:"text"`
+	AgentID        string         `json:"agent_id"`
+	Score          any            `json:"score"`
+	Metadata       map[string]any `json:"metadata"`
+	ExpirationDate string         `json:"expiration_date"`
+}
+
+// planMem0 maps mem0 memories: agent_id becomes agent scope, everything else is
+// global (vybe has no user or run scope). metadata.confidence, or score, is the confidence.
+func planMem0(data []byte, opts IngestOptions) (*IngestPlan, error) {
+	list, err := unwrapExportList(data, "results", "memories")
+	if err != nil {
+		return nil, err
+	}
+	plan := &IngestPlan{Source: IngestSourceMem0, Records: make([]IngestRecord, 0, len(list))}
+	for i, raw := range list {
+		var m mem0Memory
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, fmt.Errorf("mem0 record %d: %w", i, err)
+		}
+		value := m.Memory
+		if value == "" {
+			value = m.Text
+		}
+		rec := IngestRecord{
+			Key:       ingestKey(IngestSourceMem0, m.ID, value),
+			Value:     value,
+			Scope:     string(models.MemoryScopeGlobal),
+			ExpiresAt: parseExportTime(m.ExpirationDate),
+			SourceID:  m.ID,
+		}
+		if m.AgentID != "" {
+			rec.Scope, rec.ScopeID = string(models.MemoryScopeAgent), m.AgentID
+		}
+		confidence := floatFromAny(m.Score)
+		if m.Metadata != nil {
+			if c := floatFromAny(m.Metadata["confidence"]); c != nil {
+				confidence = c
+			}
+			if k, ok := m.Metadata["kind"].(string); ok {
+				rec.Kind = k
+			}
+		}
+		rec.Confidence = clampConfidence(confidence)
+		admitRecord(plan, rec, opts)
+	}
+	return plan, nil
+}
+
+// zepFact is one fact or graph edge from a Zep export.
+type zepFact struct {
+	UUID      string `json:"uuid"`
+	Fact      string `json:"fact"`
+	Rating    any    `json:"rating"`
+	InvalidAt string `json:"invalid_at"`
+	ExpiredAt string `json:"expired_at"`
+}
+
+// planZep maps Zep facts/edges into global memory. rating is the confidence; the
+// earlier of invalid_at/expired_at becomes the expiry, and already-invalid facts are skipped.
+func planZep(data []byte, opts IngestOptions) (*IngestPlan, error) {
+	list, err := unwrapExportList(data, "facts", "edges")
+	if err != nil {
+		return nil, err
+	}
+	plan := &IngestPlan{Source: IngestSourceZep, Records: make([]IngestRecord, 0, len(list))}
+	for i, raw := range list {
+		var f zepFact
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, fmt.Errorf("zep record %d: %w", i, err)
+		}
+		expiresAt := parseExportTime(f.InvalidAt)
+		if e := parseExportTime(f.ExpiredAt); e != nil && (expiresAt == nil || e.Before(*expiresAt)) {
+			expiresAt = e
+		}
+		rec := IngestRecord{
+			Key:        ingestKey(IngestSourceZep, f.UUID, f.Fact),
+			Value:      f.Fact,
+			Scope:      string(models.MemoryScopeGlobal),
+			ExpiresAt:  expiresAt,
+			Confidence: clampConfidence(floatFromAny(f.Rating)),
+			SourceID:   f.UUID,
+		}
+		admitRecord(plan, rec, opts)
+	}
+	return plan, nil
+}
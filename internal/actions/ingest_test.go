@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestPlanIngest_Mem0MapsScopeAndConfidence(t *testing.T) {
+	data := []byte(`{"results": [
+		{"id": "m1", "memory": "Prefers tabs", "user_id": "alice", "metadata": {"confidence": 0.9}},
+		{"id": "m2", "memory": "Reviewer checks tests", "agent_id": "reviewer", "score": 40},
+		{"id": "m3", "memory": "Low signal", "score": 0.1},
+		{"id": "m4", "memory": ""}
+	]}`)
+
+	plan, err := PlanIngest(IngestSourceMem0, data, IngestOptions{MinConfidence: 0.2})
+	require.NoError(t, err)
+	require.Len(t, plan.Records, 2)
+	require.Len(t, plan.Skipped, 2)
+
+	assert.Equal(t, "mem0.m1", plan.Records[0].Key)
+	assert.Equal(t, "global", plan.Records[0].Scope)
+	require.NotNil(t, plan.Records[0].Confidence)
+	assert.InDelta(t, 0.9, *plan.Records[0].Confidence, 1e-9)
+
+	assert.Equal(t, "agent", plan.Records[1].Scope)
+	assert.Equal(t, "reviewer", plan.Records[1].ScopeID)
+	require.NotNil(t, plan.Records[1].Confidence)
+	assert.InDelta(t, 0.4, *plan.Records[1].Confidence, 1e-9, "0-100 scores are normalized")
+}
+
+func TestPlanIngest_ZepSkipsInvalidatedFacts(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := []byte(`[
+		{"uuid": "f1", "fact": "Service runs on port 8080", "rating": 0.7},
+		{"uuid": "f2", "fact": "Old deploy target", "invalid_at": "2025-06-01T00:00:00Z"},
+		{"uuid": "f3", "fact": "Sprint ends soon", "expired_at": "2026-02-01T00:00:00Z"}
+	]`)
+
+	plan, err := PlanIngest(IngestSourceZep, data, IngestOptions{Now: now, Scope: "project", ScopeID: "/repo"})
+	require.NoError(t, err)
+	require.Len(t, plan.Records, 2)
+	require.Len(t, plan.Skipped, 1)
+	assert.Equal(t, "f2", plan.Skipped[0].SourceID)
+
+	for _, r := range plan.Records {
+		assert.Equal(t, "project", r.Scope)
+		assert.Equal(t, "/repo", r.ScopeID)
+	}
+	require.NotNil(t, plan.Records[1].ExpiresAt)
+	assert.True(t, plan.Records[1].ExpiresAt.After(now))
+}
+
+func TestPlanIngest_RejectsUnknownSourceAndShape(t *testing.T) {
+	_, err := PlanIngest("letta", []byte(`[]`), IngestOptions{})
+	require.Error(t, err)
+
+	_, err = PlanIngest(IngestSourceMem0, []byte(`{"items": []}`), IngestOptions{})
+	require.Error(t, err)
+
+	_, err = PlanIngest(IngestSourceMem0, []byte(`[]`), IngestOptions{Scope: "project"})
+	require.Error(t, err, "non-global scope override needs a scope id")
+}
+
+func TestIngestMemoryIdempotent_WritesMemoriesWithProvenance(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	plan, err := PlanIngest(IngestSourceMem0, []byte(`[{"id": "m1", "memory": "Uses goose migrations", "metadata": {"confidence": 0.8}}]`), IngestOptions{})
+	require.NoError(t, err)
+
+	result, err := IngestMemoryIdempotent(context.Background(), db, "agent1", "ingest_1", plan)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	assert.Greater(t, result.EventID, int64(0))
+
+	mem, err := store.GetMemory(context.Background(), db, "mem0.m1", "global", "")
+	require.NoError(t, err)
+	require.NotNil(t, mem)
+	assert.Equal(t, "Uses goose migrations", mem.Value)
+	require.NotNil(t, mem.SourceEventID)
+	assert.Equal(t, result.EventID, *mem.SourceEventID)
+
+	replay, err := IngestMemoryIdempotent(context.Background(), db, "agent1", "ingest_1", plan)
+	require.NoError(t, err)
+	assert.Equal(t, result.EventID, replay.EventID)
+}
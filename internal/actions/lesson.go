@@ -0,0 +1,64 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// LessonAddIdempotent records a lesson for projectID ("" for global). Adding
+// text the project already has returns the existing lesson unchanged.
+func LessonAddIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID, text, source string, confidence *float64) (*store.LessonChange, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return nil, errors.New("lesson text is required")
+	}
+	return store.AddLessonIdempotent(ctx, db, agentName, requestID, projectID, text, source, confidence)
+}
+
+// LessonPromoteIdempotent raises a lesson's confidence by one step; global
+// also makes a project lesson global.
+func LessonPromoteIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, lessonID int64, global bool) (*store.LessonChange, error) {
+	if err := validateLessonRank(agentName, requestID, lessonID); err != nil {
+		return nil, err
+	}
+	return store.RankLessonIdempotent(ctx, db, agentName, requestID, "lesson.promote", lessonID, store.LessonConfidenceStep, global)
+}
+
+// LessonDemoteIdempotent lowers a lesson's confidence by one step. Lessons
+// below store.LessonBriefMinConfidence stay listed but leave briefs.
+func LessonDemoteIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, lessonID int64) (*store.LessonChange, error) {
+	if err := validateLessonRank(agentName, requestID, lessonID); err != nil {
+		return nil, err
+	}
+	return store.RankLessonIdempotent(ctx, db, agentName, requestID, "lesson.demote", lessonID, -store.LessonConfidenceStep, false)
+}
+
+// LessonFeedbackIdempotent records whether a lesson helped. Wrong verdicts
+// lower confidence faster than helpful ones raise it, so lessons reported
+// wrong repeatedly fall out of briefs.
+func LessonFeedbackIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, lessonID int64, helpful bool, note string) (*store.LessonChange, error) {
+	if err := validateLessonRank(agentName, requestID, lessonID); err != nil {
+		return nil, err
+	}
+	return store.FeedbackLessonIdempotent(ctx, db, agentName, requestID, lessonID, helpful, note)
+}
+
+// LessonList returns lessons best ranked first (see store.ListLessons).
+func LessonList(ctx context.Context, db *sql.DB, q store.LessonQuery) ([]*store.Lesson, error) {
+	return store.ListLessons(ctx, db, q)
+}
+
+func validateLessonRank(agentName, requestID string, lessonID int64) error {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return err
+	}
+	if lessonID <= 0 {
+		return errors.New("lesson id is required")
+	}
+	return nil
+}
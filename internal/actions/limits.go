@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// LimitStatus is one activity limit with its current usage. Max 0 means
+// unlimited. Window is the UTC day for daily limits, or the busiest recent
+// session for events_per_session.
+type LimitStatus struct {
+	Limit     string `json:"limit"`
+	Max       int    `json:"max"`
+	Used      int    `json:"used"`
+	Remaining *int   `json:"remaining"`
+	Window    string `json:"window,omitempty"`
+	Reached   bool   `json:"reached"`
+}
+
+// LimitsStatusResult reports every activity limit for the active database.
+// Workspace names the workspace whose limits apply, if any.
+type LimitsStatusResult struct {
+	Workspace string            `json:"workspace,omitempty"`
+	Settings  app.LimitSettings `json:"settings"`
+	Limits    []LimitStatus     `json:"limits"`
+}
+
+func limitMax(limits app.LimitSettings, limit string) int {
+	switch limit {
+	case store.LimitTasksPerDay:
+		return limits.TasksPerDay
+	case store.LimitEventsPerSession:
+		return limits.EventsPerSession
+	case store.LimitLLMCallsPerDay:
+		return limits.LLMCallsPerDay
+	}
+	return 0
+}
+
+func newLimitStatus(limit string, maxAllowed, used int, window string) LimitStatus {
+	s := LimitStatus{Limit: limit, Max: maxAllowed, Used: used, Window: window}
+	if maxAllowed > 0 {
+		remaining := max(maxAllowed-used, 0)
+		s.Remaining = &remaining
+		s.Reached = used >= maxAllowed
+	}
+	return s
+}
+
+// LimitsStatus reports the effective limits and today's usage.
+func LimitsStatus(ctx context.Context, db *sql.DB) (_ *LimitsStatusResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	limits, workspace := app.EffectiveLimits()
+	now := time.Now()
+	day := now.UTC().Format(time.DateOnly)
+
+	res := &LimitsStatusResult{Workspace: workspace, Settings: limits}
+	for _, limit := range []string{store.LimitTasksPerDay, store.LimitLLMCallsPerDay} {
+		used, err := store.LimitUsage(ctx, db, limit, now)
+		if err != nil {
+			return nil, err
+		}
+		res.Limits = append(res.Limits, newLimitStatus(limit, limitMax(limits, limit), used, day))
+	}
+
+	sessionID, err := store.BusiestRecentSession(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	used := 0
+	if sessionID != "" {
+		if used, err = store.SessionEventCount(ctx, db, sessionID); err != nil {
+			return nil, err
+		}
+	}
+	res.Limits = append(res.Limits, newLimitStatus(store.LimitEventsPerSession, limits.EventsPerSession, used, sessionID))
+	return res, nil
+}
+
+// CheckDailyLimit returns a *store.LimitExceededError when today's usage of
+// limit has reached its effective cap.
+func CheckDailyLimit(ctx context.Context, db *sql.DB, limit string) error {
+	limits, _ := app.EffectiveLimits()
+	maxAllowed := limitMax(limits, limit)
+	if maxAllowed <= 0 {
+		return nil
+	}
+	used, err := store.LimitUsage(ctx, db, limit, time.Now())
+	if err != nil {
+		return err
+	}
+	if used >= maxAllowed {
+		return &store.LimitExceededError{Limit: limit, Used: used, Max: maxAllowed}
+	}
+	return nil
+}
+
+// CheckSessionEventLimit returns a *store.LimitExceededError when sessionID
+// has already recorded events_per_session events.
+func CheckSessionEventLimit(ctx context.Context, db *sql.DB, sessionID string) error {
+	limits, _ := app.EffectiveLimits()
+	if limits.EventsPerSession <= 0 || sessionID == "" {
+		return nil
+	}
+	used, err := store.SessionEventCount(ctx, db, sessionID)
+	if err != nil {
+		return err
+	}
+	if used >= limits.EventsPerSession {
+		return &store.LimitExceededError{Limit: store.LimitEventsPerSession, Used: used, Max: limits.EventsPerSession}
+	}
+	return nil
+}
+
+// RecordLLMCall counts one external LLM invocation toward llm_calls_per_day.
+func RecordLLMCall(ctx context.Context, db *sql.DB) error {
+	return store.IncrementLimitUsage(ctx, db, store.LimitLLMCallsPerDay, time.Now())
+}
+
+// limitReachedName returns the limit named by err, or "" when err is not a
+// *store.LimitExceededError.
+func limitReachedName(err error) string {
+	var le *store.LimitExceededError
+	if errors.As(err, &le) {
+		return le.Limit
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package actions
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// useLimitedWorkspace registers the database at dbPath as a workspace with
+// limits, under a throwaway HOME.
+func useLimitedWorkspace(t *testing.T, dbPath string, limits app.LimitSettings) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VYBE_DB_PATH", dbPath)
+	_, err := app.AddWorkspace("limited", dbPath)
+	require.NoError(t, err)
+	_, err = app.SetWorkspaceLimits("limited", limits)
+	require.NoError(t, err)
+}
+
+func TestResume_TasksPerDayLimit(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "limits.db")
+	db, err := store.InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	useLimitedWorkspace(t, dbPath, app.LimitSettings{TasksPerDay: 1})
+
+	first, err := store.CreateTask(context.Background(), db, "First", "", "", 0)
+	require.NoError(t, err)
+	_, err = store.CreateTask(context.Background(), db, "Second", "", "", 0)
+	require.NoError(t, err)
+
+	resp, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "r1", ResumeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, resp.FocusTaskID)
+	assert.Empty(t, resp.LimitReached)
+
+	// Keeping the same focus is not a new claim.
+	resp, err = ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "r2", ResumeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, resp.FocusTaskID)
+
+	require.NoError(t, store.UpdateTaskStatus(context.Background(), db, first.ID, "completed", first.Version))
+	resp, err = ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "r3", ResumeOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.FocusTaskID)
+	assert.Equal(t, store.LimitTasksPerDay, resp.LimitReached)
+
+	_, err = TaskClaimIdempotent(context.Background(), db, "agent2", "c1", "", store.FocusFilter{}, false, store.ClaimOptions{})
+	require.True(t, store.IsLimitExceeded(err), "task claim is held to the same limit: %v", err)
+
+	resp, err = ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "r4", ResumeOptions{OverrideLimits: true})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.FocusTaskID)
+
+	status, err := LimitsStatus(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, "limited", status.Workspace)
+	require.NotEmpty(t, status.Limits)
+	tasks := status.Limits[0]
+	assert.Equal(t, store.LimitTasksPerDay, tasks.Limit)
+	assert.Equal(t, 2, tasks.Used, "overridden claims still count")
+	assert.True(t, tasks.Reached)
+	require.NotNil(t, tasks.Remaining)
+	assert.Equal(t, 0, *tasks.Remaining)
+}
+
+func TestCheckSessionEventLimit(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "limits.db")
+	db, err := store.InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	useLimitedWorkspace(t, dbPath, app.LimitSettings{EventsPerSession: 2})
+
+	for _, req := range []string{"e1", "e2"} {
+		require.NoError(t, CheckSessionEventLimit(context.Background(), db, "sess-1"))
+		_, err := store.AppendEventWithMetadataIdempotent(context.Background(), db, "agent1", req, "progress", "", "step", `{"session_id":"sess-1"}`)
+		require.NoError(t, err)
+	}
+	err = CheckSessionEventLimit(context.Background(), db, "sess-1")
+	require.True(t, store.IsLimitExceeded(err))
+	assert.NoError(t, CheckSessionEventLimit(context.Background(), db, "sess-2"), "other sessions are metered separately")
+}
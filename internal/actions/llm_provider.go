@@ -0,0 +1,180 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+// LLMProvider completes a prompt with an external model.
+type LLMProvider interface {
+	Name() string
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// Provider defaults, used when the retrospective config leaves a field empty.
+const (
+	defaultClaudeCommand = "claude"
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-4o-mini"
+	defaultOpenAIKeyEnv  = "OPENAI_API_KEY"
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultOllamaModel   = "llama3.1"
+)
+
+// maxLLMResponseBytes caps how much of a provider response is read.
+const maxLLMResponseBytes = 1 << 20
+
+// NewLLMProvider builds the provider s selects (see app.RetrospectiveSettings).
+func NewLLMProvider(s app.RetrospectiveSettings) (LLMProvider, error) {
+	switch s.Provider {
+	case "", app.RetrospectiveProviderClaude:
+		return &claudeCLIProvider{command: orDefault(s.Command, defaultClaudeCommand), model: s.Model}, nil
+	case app.RetrospectiveProviderOpenAI:
+		keyEnv := orDefault(s.APIKeyEnv, defaultOpenAIKeyEnv)
+		key := os.Getenv(keyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("openai provider needs an API key in $%s", keyEnv)
+		}
+		return &openAIProvider{
+			baseURL: strings.TrimRight(orDefault(s.BaseURL, defaultOpenAIBaseURL), "/"),
+			model:   orDefault(s.Model, defaultOpenAIModel),
+			apiKey:  key,
+		}, nil
+	case app.RetrospectiveProviderOllama:
+		return &ollamaProvider{
+			baseURL: strings.TrimRight(orDefault(s.BaseURL, defaultOllamaBaseURL), "/"),
+			model:   orDefault(s.Model, defaultOllamaModel),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q (use %s)", s.Provider, strings.Join(app.RetrospectiveProviders(), ", "))
+	}
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// claudeCLIProvider runs the claude CLI in print mode with hooks disabled, so
+// the call does not recurse into vybe.
+type claudeCLIProvider struct {
+	command string
+	model   string
+}
+
+func (p *claudeCLIProvider) Name() string { return app.RetrospectiveProviderClaude }
+
+func (p *claudeCLIProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	args := []string{"-p", "--output-format", "text", "--settings", `{"hooks":{}}`}
+	if p.model != "" {
+		args = append(args, "--model", p.model)
+	}
+	cmd := exec.CommandContext(ctx, p.command, args...) //nolint:gosec // G204: command is operator-configured
+	cmd.Env = append(os.Environ(), "VYBE_DISABLE_EXTERNAL_LLM=1")
+	cmd.Stdin = strings.NewReader(prompt)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s failed: %w: %s", p.command, err, msg)
+		}
+		return "", fmt.Errorf("%s failed: %w", p.command, err)
+	}
+	return string(out), nil
+}
+
+// openAIProvider calls an OpenAI-compatible chat completions endpoint.
+type openAIProvider struct {
+	baseURL string
+	model   string
+	apiKey  string
+}
+
+func (p *openAIProvider) Name() string { return app.RetrospectiveProviderOpenAI }
+
+func (p *openAIProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	body := map[string]any{
+		"model":       p.model,
+		"messages":    []map[string]string{{"role": "user", "content": prompt}},
+		"temperature": 0,
+	}
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(ctx, p.baseURL+"/chat/completions", "Bearer "+p.apiKey, body, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("openai provider returned no choices")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// ollamaProvider calls a local ollama server's generate endpoint.
+type ollamaProvider struct {
+	baseURL string
+	model   string
+}
+
+func (p *ollamaProvider) Name() string { return app.RetrospectiveProviderOllama }
+
+func (p *ollamaProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	body := map[string]any{"model": p.model, "prompt": prompt, "stream": false, "format": "json"}
+	var resp struct {
+		Response string `json:"response"`
+	}
+	if err := postJSON(ctx, p.baseURL+"/api/generate", "", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Response, nil
+}
+
+// postJSON posts body as JSON to url and decodes the response into out.
+func postJSON(ctx context.Context, url, authorization string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("LLM request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLLMResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read LLM response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("LLM request failed: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode LLM response: %w", err)
+	}
+	return nil
+}
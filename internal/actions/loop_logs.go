@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// LoopLogRecordIdempotent stores one loop iteration's captured output against
+// its task, once per (agent_name, request_id).
+func LoopLogRecordIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, log store.LoopLog, output []byte) (*store.LoopLog, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if err := validateTaskID(log.TaskID); err != nil {
+		return nil, err
+	}
+	return store.RecordLoopLogIdempotent(ctx, db, agentName, requestID, log, output)
+}
+
+// LoopLogs returns a task's loop iterations, newest first. withOutput also
+// loads what each iteration's command printed.
+func LoopLogs(ctx context.Context, db *sql.DB, taskID string, limit int, withOutput bool) ([]store.LoopLog, error) {
+	if err := validateTaskID(taskID); err != nil {
+		return nil, err
+	}
+	logs, err := store.ListLoopLogs(ctx, db, taskID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if !withOutput {
+		return logs, nil
+	}
+	for i := range logs {
+		if logs[i].ArtifactID == "" {
+			continue
+		}
+		content, err := store.GetArtifactContent(ctx, db, logs[i].ArtifactID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read loop log %s: %w", logs[i].ArtifactID, err)
+		}
+		output := string(content)
+		logs[i].Output = &output
+	}
+	return logs, nil
+}
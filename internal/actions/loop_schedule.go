@@ -0,0 +1,19 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// LoopScheduleSave records the state of an agent's scheduled loop.
+func LoopScheduleSave(ctx context.Context, db *sql.DB, s store.LoopSchedule) error {
+	return store.SaveLoopSchedule(ctx, db, s)
+}
+
+// LoopScheduleStatus returns scheduled loop state for agentName, or for every
+// agent when agentName is empty.
+func LoopScheduleStatus(ctx context.Context, db *sql.DB, agentName string) ([]store.LoopSchedule, error) {
+	return store.ListLoopSchedules(ctx, db, agentName)
+}
@@ -18,7 +18,7 @@ import (
 // halfLifeDays is nil to preserve any stored value, or a non-negative float to override decay rate.
 // sourceTaskID is optional provenance; pass "" when not known. source_event_id is NOT auto-populated
 // here — doing so would be circular (memory → the event that created it).
-func MemorySetIdempotent(db *sql.DB, agentName, requestID, key, value, valueType, scope, scopeID string, expiresAt *time.Time, pinned bool, kind string, halfLifeDays *float64, sourceTaskID string) (int64, error) { //nolint:revive // argument-limit: memory params are distinct; struct degrades call-site readability
+func MemorySetIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, key, value, valueType, scope, scopeID string, expiresAt *time.Time, pinned bool, kind string, halfLifeDays *float64, sourceTaskID string) (int64, error) { //nolint:revive // argument-limit: memory params are distinct; struct degrades call-site readability
 	if agentName == "" {
 		return 0, errors.New("agent name is required")
 	}
@@ -34,7 +34,7 @@ func MemorySetIdempotent(db *sql.DB, agentName, requestID, key, value, valueType
 	if halfLifeDays != nil && *halfLifeDays < 0 {
 		return 0, fmt.Errorf("half_life_days must be >= 0, got %g", *halfLifeDays)
 	}
-	return store.UpsertMemoryWithEventIdempotent(db, agentName, requestID, key, value, valueType, scope, scopeID, expiresAt, pinned, kind, halfLifeDays, sourceTaskID)
+	return store.UpsertMemoryWithEventIdempotent(ctx, db, agentName, requestID, key, value, valueType, scope, scopeID, expiresAt, pinned, kind, halfLifeDays, sourceTaskID)
 }
 
 // ValidateMemoryKind reports whether kind is valid. Returns a structured error whose Error()
@@ -48,33 +48,87 @@ func ValidateMemoryKind(kind string) error {
 
 // MemoryGCResult holds the outcome of a memory garbage collection operation.
 type MemoryGCResult struct {
-	EventID int64 `json:"event_id"`
-	Deleted int   `json:"deleted"`
+	EventID            int64                `json:"event_id"`
+	Deleted            int                  `json:"deleted"`
+	IdempotencyDeleted int64                `json:"idempotency_deleted"`
+	LeasesReclaimed    []store.ExpiredLease `json:"leases_reclaimed"`
 }
 
 // MemoryGCIdempotent runs garbage collection on expired memory entries.
-func MemoryGCIdempotent(db *sql.DB, agentName, requestID string, limit int) (*MemoryGCResult, error) {
+func MemoryGCIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, limit int) (*MemoryGCResult, error) {
+	return MemoryGCWithOptionsIdempotent(ctx, db, agentName, requestID, store.GCOptions{Limit: limit})
+}
+
+// MemoryGCWithOptionsIdempotent runs garbage collection on expired memory
+// entries and, when opts.IdempotencyTTLDays is set, on idempotency records
+// older than it. Tasks whose claim lease ran out go back to pending.
+func MemoryGCWithOptionsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, opts store.GCOptions) (*MemoryGCResult, error) {
 	if agentName == "" {
 		return nil, errors.New("agent name is required")
 	}
 	if requestID == "" {
 		return nil, errors.New("request id is required")
 	}
-	if limit <= 0 {
+	if opts.Limit <= 0 {
 		return nil, errors.New("limit must be > 0")
 	}
 
-	eventID, deleted, err := store.GCMemoryWithEventIdempotent(db, agentName, requestID, limit)
+	r, err := store.GCWithEventIdempotent(ctx, db, agentName, requestID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemoryGCResult{EventID: r.EventID, Deleted: r.Deleted, IdempotencyDeleted: r.IdempotencyDeleted,
+		LeasesReclaimed: r.LeasesReclaimed}, nil
+}
+
+// MemoryCompactOptions holds the CLI-level compaction inputs; MaxAge accepts
+// the same "30d"/"2w" shorthands as --expires-in.
+type MemoryCompactOptions struct {
+	Scope   string
+	ScopeID string
+	KeepTop int
+	MaxAge  string
+	Limit   int
+	Explain bool
+}
+
+func (o MemoryCompactOptions) params() (store.MemoryCompactParams, error) {
+	p := store.MemoryCompactParams{Scope: o.Scope, ScopeID: o.ScopeID, KeepTop: o.KeepTop, Limit: o.Limit, Explain: o.Explain}
+	if o.MaxAge != "" {
+		d, err := parseDurationExtended(o.MaxAge)
+		if err != nil {
+			return p, fmt.Errorf("invalid max-age: %w", err)
+		}
+		p.MaxAge = d
+	}
+	return p, nil
+}
+
+// PreviewMemoryCompact reports the entries a compaction would remove without deleting anything.
+func PreviewMemoryCompact(ctx context.Context, db *sql.DB, opts MemoryCompactOptions) (*store.MemoryCompactStats, error) {
+	p, err := opts.params()
 	if err != nil {
 		return nil, err
 	}
+	return store.FindMemoryCompaction(ctx, db, p)
+}
 
-	return &MemoryGCResult{EventID: eventID, Deleted: deleted}, nil
+// MemoryCompactIdempotent removes low-relevance or idle memory once per (agent_name, request_id).
+func MemoryCompactIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, opts MemoryCompactOptions) (*store.MemoryCompactStats, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	p, err := opts.params()
+	if err != nil {
+		return nil, err
+	}
+	return store.CompactMemoryIdempotent(ctx, db, agentName, requestID, p)
 }
 
 // MemoryGet retrieves a memory entry by key, scope, and scope_id.
-func MemoryGet(db *sql.DB, key, scope, scopeID string) (*models.Memory, error) {
-	mem, err := store.GetMemory(db, key, scope, scopeID)
+func MemoryGet(ctx context.Context, db *sql.DB, key, scope, scopeID string) (*models.Memory, error) {
+	mem, err := store.GetMemory(ctx, db, key, scope, scopeID)
 	if err != nil {
 		return nil, err
 	}
@@ -87,8 +141,13 @@ func MemoryGet(db *sql.DB, key, scope, scopeID string) (*models.Memory, error) {
 }
 
 // MemoryList retrieves all memory entries for a scope and scope_id.
-func MemoryList(db *sql.DB, scope, scopeID string) ([]*models.Memory, error) {
-	return store.ListMemory(db, scope, scopeID)
+func MemoryList(ctx context.Context, db *sql.DB, scope, scopeID string) ([]*models.Memory, error) {
+	return store.ListMemory(ctx, db, scope, scopeID)
+}
+
+// MemoryListPrefix retrieves memory entries whose key is prefix or lies under it.
+func MemoryListPrefix(ctx context.Context, db *sql.DB, scope, scopeID, prefix string) ([]*models.Memory, error) {
+	return store.ListMemoryWithPrefix(ctx, db, scope, scopeID, prefix)
 }
 
 // MemoryPinIdempotent sets or clears the pinned flag on an existing memory entry.
@@ -99,6 +158,9 @@ func MemoryPinIdempotent(ctx context.Context, db *sql.DB, agentName, requestID,
 	if requestID == "" {
 		return 0, errors.New("request id is required")
 	}
+	if err := checkConfinedMemoryWrite(ctx, db, agentName, scope, scopeID); err != nil {
+		return 0, err
+	}
 	return store.PinMemoryIdempotent(ctx, db, agentName, requestID, key, scope, scopeID, pin)
 }
 
@@ -110,9 +172,44 @@ func MemoryDeleteIdempotent(ctx context.Context, db *sql.DB, agentName, requestI
 	if requestID == "" {
 		return 0, errors.New("request id is required")
 	}
+	if err := checkConfinedMemoryWrite(ctx, db, agentName, scope, scopeID); err != nil {
+		return 0, err
+	}
 	return store.DeleteMemoryWithEventIdempotent(ctx, db, agentName, requestID, key, scope, scopeID)
 }
 
+// MemoryDeleteMatchingIdempotent deletes every entry whose key matches a
+// wildcard pattern such as build/* or build/**.
+func MemoryDeleteMatchingIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, pattern, scope, scopeID string) (*store.DeleteMemoryMatchingResult, error) { //nolint:revive // argument-limit: all params are required and distinct
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	if err := checkConfinedMemoryWrite(ctx, db, agentName, scope, scopeID); err != nil {
+		return nil, err
+	}
+	return store.DeleteMemoryMatchingIdempotent(ctx, db, agentName, requestID, pattern, scope, scopeID)
+}
+
+// MemoryHistory returns the value timeline of one key, newest first.
+func MemoryHistory(ctx context.Context, db *sql.DB, key, scope, scopeID string, limit int) ([]*models.MemoryHistoryEntry, error) {
+	return store.ListMemoryHistory(ctx, db, key, scope, scopeID, limit)
+}
+
+// MemoryRestoreIdempotent writes the value recorded by a history entry back
+// to its key.
+func MemoryRestoreIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, historyID int64) (*store.MemoryRestoreResult, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	return store.RestoreMemoryFromHistoryIdempotent(ctx, db, agentName, requestID, historyID)
+}
+
 // ParseExpiresIn parses a duration string and returns the corresponding expiration time.
 func ParseExpiresIn(duration string) (*time.Time, error) {
 	if duration == "" {
@@ -159,3 +256,113 @@ func parseDurationExtended(input string) (time.Duration, error) {
 
 	return time.Duration(n) * 24 * time.Hour, nil
 }
+
+// ParseMemoryScopeRef parses a scope reference: "global", or "<scope>:<id>"
+// for project, task, and agent scopes (e.g. "project:/repo/app").
+func ParseMemoryScopeRef(ref string) (scope, scopeID string, err error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "global" {
+		return "global", "", nil
+	}
+	scope, scopeID, ok := strings.Cut(ref, ":")
+	if !ok || scopeID == "" {
+		return "", "", fmt.Errorf("invalid scope %q: use global or <project|task|agent>:<id>", ref)
+	}
+	switch scope {
+	case "project", "task", "agent":
+		return scope, scopeID, nil
+	}
+	return "", "", fmt.Errorf("invalid scope %q: use global or <project|task|agent>:<id>", ref)
+}
+
+// MemoryPromoteIdempotent copies (or with opts.Move, moves) a memory entry into
+// a wider scope, or stages it for review when opts.RequiresReview is set.
+//
+//nolint:revive // argument-limit: key and both scope pairs are all required
+func MemoryPromoteIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, key, fromScope, fromScopeID, toScope, toScopeID string, opts store.MemoryTransferOptions) (*store.MemoryPromoteResult, error) {
+	if err := checkMemoryTransfer(ctx, db, agentName, requestID, fromScope, fromScopeID, toScope, toScopeID, opts.Move); err != nil {
+		return nil, err
+	}
+	return store.PromoteMemoryIdempotent(ctx, db, agentName, requestID, key, fromScope, fromScopeID, toScope, toScopeID, opts)
+}
+
+// MemoryDemoteIdempotent copies (or with move, moves) a memory entry into a
+// narrower scope.
+//
+//nolint:revive // argument-limit: key and both scope pairs are all required
+func MemoryDemoteIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, key, fromScope, fromScopeID, toScope, toScopeID string, move bool) (*store.MemoryPromoteResult, error) {
+	if err := checkMemoryTransfer(ctx, db, agentName, requestID, fromScope, fromScopeID, toScope, toScopeID, move); err != nil {
+		return nil, err
+	}
+	return store.DemoteMemoryIdempotent(ctx, db, agentName, requestID, key, fromScope, fromScopeID, toScope, toScopeID, move)
+}
+
+// checkMemoryTransfer validates the identity of a promotion or demotion and
+// the agent's confinement: it must be able to read the source and write the
+// target, and to write the source too when the entry is moved.
+//
+//nolint:revive // argument-limit: both scope pairs and the move flag are all required
+func checkMemoryTransfer(ctx context.Context, db *sql.DB, agentName, requestID, fromScope, fromScopeID, toScope, toScopeID string, move bool) error {
+	if agentName == "" {
+		return errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return errors.New("request id is required")
+	}
+	if err := CheckConfinedMemoryRead(ctx, db, agentName, fromScope, fromScopeID); err != nil {
+		return err
+	}
+	if move {
+		if err := checkConfinedMemoryWrite(ctx, db, agentName, fromScope, fromScopeID); err != nil {
+			return err
+		}
+	}
+	return checkConfinedMemoryWrite(ctx, db, agentName, toScope, toScopeID)
+}
+
+// MemoryPromotionReviewIdempotent approves or rejects a staged promotion.
+func MemoryPromotionReviewIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, id int64, approve bool, note string) (*store.MemoryPromoteResult, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	return store.ReviewMemoryPromotionIdempotent(ctx, db, agentName, requestID, id, approve, note)
+}
+
+// MemoryPromotions lists staged promotions by status ("" for all).
+func MemoryPromotions(ctx context.Context, db *sql.DB, status string, limit int) ([]*models.MemoryPromotion, error) {
+	return store.ListMemoryPromotions(ctx, db, status, limit)
+}
+
+// MemoryConflicts lists cross-agent memory conflicts by status ("" for all).
+func MemoryConflicts(ctx context.Context, db *sql.DB, status string, limit int) ([]*models.MemoryConflict, error) {
+	return store.ListMemoryConflicts(ctx, db, status, limit)
+}
+
+// MemoryResolveIdempotent settles a memory conflict by keeping value a, value
+// b, or a merged value. The agent must be able to write the conflicted entry.
+//
+//nolint:revive // argument-limit: conflict id, choice, and merged value are all required
+func MemoryResolveIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, id int64, keep, mergedValue string) (*store.MemoryConflictResolution, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	c, err := store.GetMemoryConflict(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConfinedMemoryWrite(ctx, db, agentName, string(c.Scope), c.ScopeID); err != nil {
+		return nil, err
+	}
+	return store.ResolveMemoryConflictIdempotent(ctx, db, agentName, requestID, id, keep, mergedValue)
+}
+
+// Decisions lists decision records, newest first (see store.ListDecisions).
+func Decisions(ctx context.Context, db *sql.DB, projectID string, limit int) ([]*models.Decision, error) {
+	return store.ListDecisions(ctx, db, projectID, limit)
+}
@@ -0,0 +1,192 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// DefaultMemoryExtractLimit is how many recent events of each scanned kind
+// one extraction run reads.
+const DefaultMemoryExtractLimit = 200
+
+// memoryExtractKinds are the event kinds the fact extractor scans.
+var memoryExtractKinds = []string{models.EventKindProgress, models.EventKindToolSuccess}
+
+// factRule is one pattern of the rule-based fact extractor. build turns a
+// match into a memory key, value, and value type, or reports false to skip it.
+type factRule struct {
+	name  string
+	re    *regexp.Regexp
+	build func(m []string) (key, value, valueType string, ok bool)
+}
+
+// factRules recognize statements that read like durable project facts:
+// "uses X [for Y]", "decided to Y", "<name> config is Z", and "a.b = c".
+var factRules = []factRule{
+	{
+		name: "uses",
+		re:   regexp.MustCompile(`(?i)\b(?:uses|we use|switched to|migrated to)\s+([a-z][\w.+#-]*(?:\s+v?\d[\w.]*)?)(?:\s+for\s+([a-z][\w -]{1,40}?))?\s*(?:[,.;:!)]|$|\s+(?:and|but|so|because|instead)\b)`),
+		build: func(m []string) (string, string, string, bool) {
+			tool := cleanFactValue(m[1])
+			if isFactStopword(tool) {
+				return "", "", "", false
+			}
+			purpose := trimFactArticles(m[2])
+			if purpose == "" {
+				purpose = strings.Fields(tool)[0]
+			}
+			return "stack/" + factSlug(purpose), tool, "", true
+		},
+	},
+	{
+		name: "decided",
+		re:   regexp.MustCompile(`(?i)\bdecided\s+(?:to|on|that)\s+([^.;\n]{3,160})`),
+		build: func(m []string) (string, string, string, bool) {
+			text := strings.TrimSpace(m[1])
+			words := strings.Fields(text)
+			if len(words) > 6 {
+				words = words[:6]
+			}
+			value, err := json.Marshal(models.DecisionRecord{Decision: text})
+			if err != nil {
+				return "", "", "", false
+			}
+			return "decisions/" + factSlug(strings.Join(words, " ")), string(value), models.MemoryValueTypeDecision, true
+		},
+	},
+	{
+		name: "config",
+		re:   regexp.MustCompile(`(?i)\b([a-z][\w-]*)\s+config(?:uration)?(?:\s+file)?\s+(?:is|lives)\s+(?:in\s+|at\s+)?([^\s,;]+)`),
+		build: func(m []string) (string, string, string, bool) {
+			value := cleanFactValue(m[2])
+			if isFactStopword(m[1]) || value == "" {
+				return "", "", "", false
+			}
+			return "config/" + factSlug(m[1]), value, "", true
+		},
+	},
+	{
+		name: "setting",
+		re:   regexp.MustCompile(`(?i)\b([a-z][\w-]*(?:\.[a-z][\w-]*)+)\s*(?:=|is set to)\s*([^\s,;]+)`),
+		build: func(m []string) (string, string, string, bool) {
+			value := cleanFactValue(m[2])
+			if value == "" {
+				return "", "", "", false
+			}
+			return "config/" + strings.ToLower(m[1]), value, "", true
+		},
+	},
+}
+
+// factStopwords are words a "uses" or "config" match must not name: they
+// point at something said earlier rather than a tool or component.
+var factStopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "this": true, "that": true, "it": true, "its": true,
+	"them": true, "our": true, "their": true, "some": true, "same": true, "new": true, "old": true,
+	"default": true, "my": true, "your": true,
+}
+
+func isFactStopword(s string) bool {
+	return factStopwords[strings.ToLower(strings.TrimSpace(s))]
+}
+
+func trimFactArticles(s string) string {
+	words := strings.Fields(s)
+	for len(words) > 0 && isFactStopword(words[0]) {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// cleanFactValue strips quoting and trailing punctuation, keeping a leading
+// dot so dotfile names survive.
+func cleanFactValue(s string) string {
+	s = strings.TrimLeft(strings.TrimSpace(s), "(\"'`")
+	return strings.TrimRight(s, ".,;:!)\"'`")
+}
+
+var factSlugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// factSlug turns free text into one memory key segment.
+func factSlug(s string) string {
+	slug := strings.Trim(factSlugSeparators.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(slug) > 48 {
+		slug = slug[:48]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// ExtractFactDrafts applies the fact rules to each event's message, keeping
+// the first match per key within an event.
+func ExtractFactDrafts(events []*models.Event) []store.MemoryProposalDraft {
+	var drafts []store.MemoryProposalDraft
+	for _, e := range events {
+		seen := map[string]bool{}
+		for _, rule := range factRules {
+			for _, m := range rule.re.FindAllStringSubmatch(e.Message, -1) {
+				key, value, valueType, ok := rule.build(m)
+				if !ok || seen[key] || strings.HasSuffix(key, "/") {
+					continue
+				}
+				seen[key] = true
+				drafts = append(drafts, store.MemoryProposalDraft{
+					Key: key, Value: value, ValueType: valueType, Rule: rule.name,
+					Excerpt: strings.TrimSpace(m[0]), SourceEventID: e.ID, SourceTaskID: e.TaskID,
+				})
+			}
+		}
+	}
+	return drafts
+}
+
+// MemoryExtractIdempotent scans the project's most recent progress and
+// tool_success events (limit of each, 0 for DefaultMemoryExtractLimit) and
+// stages the facts they state as memory proposals for review.
+func MemoryExtractIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, limit int) (_ *store.MemoryProposalStageResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultMemoryExtractLimit
+	}
+	var events []*models.Event
+	for _, kind := range memoryExtractKinds {
+		batch, err := store.ListEvents(ctx, db, store.ListEventsParams{ProjectID: projectID, Kind: kind, Limit: limit, Desc: true})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, batch...)
+	}
+	result, err := store.StageMemoryProposalsIdempotent(ctx, db, agentName, requestID, projectID, ExtractFactDrafts(events))
+	if err != nil {
+		return nil, err
+	}
+	result.Scanned = len(events)
+	return result, nil
+}
+
+// MemoryProposals lists staged memory proposals by status ("" for all).
+func MemoryProposals(ctx context.Context, db *sql.DB, status, projectID string, limit int) ([]*models.MemoryProposal, error) {
+	return store.ListMemoryProposals(ctx, db, status, projectID, limit)
+}
+
+// MemoryProposalReviewIdempotent accepts or rejects a staged memory proposal.
+func MemoryProposalReviewIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, id int64, review store.MemoryProposalReview) (*store.MemoryProposalReviewResult, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	return store.ReviewMemoryProposalIdempotent(ctx, db, agentName, requestID, id, review)
+}
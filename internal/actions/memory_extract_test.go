@@ -0,0 +1,41 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestExtractFactDrafts(t *testing.T) {
+	events := []*models.Event{
+		{ID: 1, TaskID: "task_1", Message: "The project uses Postgres for the job queue, and we decided to keep migrations in goose."},
+		{ID: 2, Message: "Switched to Cobra. The lint config is .golangci.yml; server.port = 8080"},
+		{ID: 3, Message: "Fixed it so it uses the same flag and the config is fine."},
+	}
+
+	drafts := ExtractFactDrafts(events)
+	got := map[string]string{}
+	for _, d := range drafts {
+		got[d.Key] = d.Value
+	}
+	assert.Equal(t, map[string]string{
+		"stack/job-queue":                    "Postgres",
+		"decisions/keep-migrations-in-goose": `{"decision":"keep migrations in goose"}`,
+		"stack/cobra":                        "Cobra",
+		"config/lint":                        ".golangci.yml",
+		"config/server.port":                 "8080",
+	}, got)
+
+	require.NotEmpty(t, drafts)
+	assert.Equal(t, int64(1), drafts[0].SourceEventID)
+	assert.Equal(t, "task_1", drafts[0].SourceTaskID)
+	assert.Equal(t, "uses", drafts[0].Rule)
+	for _, d := range drafts {
+		if d.Rule == "decided" {
+			assert.Equal(t, models.MemoryValueTypeDecision, d.ValueType)
+		}
+	}
+}
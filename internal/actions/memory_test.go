@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -65,7 +66,7 @@ func TestMemorySetIdempotent_RejectsInvalidValueType(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := MemorySetIdempotent(db, "agent1", "req_bad_vt", "k", "v", "invalid_type", "global", "", nil, false, "", nil, "")
+	_, err := MemorySetIdempotent(context.Background(), db, "agent1", "req_bad_vt", "k", "v", "invalid_type", "global", "", nil, false, "", nil, "")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "invalid value_type")
 }
@@ -74,7 +75,7 @@ func TestMemoryGet_NotFound(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := MemoryGet(db, "nonexistent", "global", "")
+	_, err := MemoryGet(context.Background(), db, "nonexistent", "global", "")
 	require.ErrorContains(t, err, "not found")
 }
 
@@ -82,10 +83,10 @@ func TestMemoryGet_Found(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := MemorySetIdempotent(db, "agent-a", "req-mem-get-1", "k1", "v1", "", "global", "", nil, false, "", nil, "")
+	_, err := MemorySetIdempotent(context.Background(), db, "agent-a", "req-mem-get-1", "k1", "v1", "", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 
-	mem, err := MemoryGet(db, "k1", "global", "")
+	mem, err := MemoryGet(context.Background(), db, "k1", "global", "")
 	require.NoError(t, err)
 	require.Equal(t, "v1", mem.Value)
 }
@@ -94,12 +95,12 @@ func TestMemoryList_Basic(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := MemorySetIdempotent(db, "agent-a", "req-mem-list-1", "x1", "v1", "", "global", "", nil, false, "", nil, "")
+	_, err := MemorySetIdempotent(context.Background(), db, "agent-a", "req-mem-list-1", "x1", "v1", "", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
-	_, err = MemorySetIdempotent(db, "agent-a", "req-mem-list-2", "x2", "v2", "", "global", "", nil, false, "", nil, "")
+	_, err = MemorySetIdempotent(context.Background(), db, "agent-a", "req-mem-list-2", "x2", "v2", "", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 
-	list, err := MemoryList(db, "global", "")
+	list, err := MemoryList(context.Background(), db, "global", "")
 	require.NoError(t, err)
 	require.Len(t, list, 2)
 }
@@ -118,10 +119,10 @@ func TestMemorySetIdempotent_DefaultsKindToFact(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := MemorySetIdempotent(db, "agent1", "req-kind-default-1", "k", "v", "", "global", "", nil, false, "", nil, "")
+	_, err := MemorySetIdempotent(context.Background(), db, "agent1", "req-kind-default-1", "k", "v", "", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 
-	mem, err := MemoryGet(db, "k", "global", "")
+	mem, err := MemoryGet(context.Background(), db, "k", "global", "")
 	require.NoError(t, err)
 	assert.Equal(t, "fact", mem.Kind, "omitted kind must default to 'fact'")
 }
@@ -130,7 +131,7 @@ func TestMemorySetIdempotent_RejectsInvalidKind(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := MemorySetIdempotent(db, "agent1", "req-kind-invalid-1", "k", "v", "", "global", "", nil, false, "opinion", nil, "")
+	_, err := MemorySetIdempotent(context.Background(), db, "agent1", "req-kind-invalid-1", "k", "v", "", "global", "", nil, false, "opinion", nil, "")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid kind")
 }
@@ -141,11 +142,28 @@ func TestMemoryGCIdempotent(t *testing.T) {
 	defer cleanup()
 
 	expired := time.Now().UTC().Add(-1 * time.Hour)
-	_, err := MemorySetIdempotent(db, "agent1", "req_expire_setup", "expired", "v", "string", "global", "", &expired, false, "", nil, "")
+	_, err := MemorySetIdempotent(context.Background(), db, "agent1", "req_expire_setup", "expired", "v", "string", "global", "", &expired, false, "", nil, "")
 	require.NoError(t, err)
 
-	gc, err := MemoryGCIdempotent(db, "agent1", "req_gc_action", 100)
+	gc, err := MemoryGCIdempotent(context.Background(), db, "agent1", "req_gc_action", 100)
 	require.NoError(t, err)
 	require.NotNil(t, gc)
 	assert.GreaterOrEqual(t, gc.Deleted, 1)
 }
+
+func TestParseMemoryScopeRef(t *testing.T) {
+	scope, id, err := ParseMemoryScopeRef("global")
+	require.NoError(t, err)
+	assert.Equal(t, "global", scope)
+	assert.Empty(t, id)
+
+	scope, id, err = ParseMemoryScopeRef("project:/repo/app:v2")
+	require.NoError(t, err)
+	assert.Equal(t, "project", scope)
+	assert.Equal(t, "/repo/app:v2", id, "only the first colon separates scope from id")
+
+	for _, bad := range []string{"", "project", "project:", "global:x", "team:core"} {
+		_, _, err := ParseMemoryScopeRef(bad)
+		assert.Error(t, err, bad)
+	}
+}
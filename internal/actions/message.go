@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// MessageSendIdempotent delivers a message from agentName to toAgent's inbox.
+//
+//nolint:revive // argument-limit: request id plus every message field
+func MessageSendIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, toAgent, subject, body, taskID string) (*models.Message, int64, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, 0, err
+	}
+	if toAgent == "" {
+		return nil, 0, errors.New("recipient agent is required")
+	}
+	return store.SendMessageIdempotent(ctx, db, agentName, requestID, toAgent, subject, body, taskID)
+}
+
+// MessageInbox lists agentName's messages (unread only unless all is set) with the unread total.
+func MessageInbox(ctx context.Context, db *sql.DB, agentName string, all bool, limit int) ([]models.Message, int, error) {
+	if agentName == "" {
+		return nil, 0, errors.New("agent name is required")
+	}
+	msgs, err := store.ListInbox(ctx, db, agentName, !all, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list inbox: %w", err)
+	}
+	unread, err := store.CountUnreadMessages(ctx, db, agentName)
+	if err != nil {
+		return nil, 0, err
+	}
+	return msgs, unread, nil
+}
+
+// MessageMarkReadIdempotent marks the given messages read; no ids marks the whole inbox.
+func MessageMarkReadIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, ids []string) (int64, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return 0, err
+	}
+	return store.MarkMessagesReadIdempotent(ctx, db, agentName, requestID, ids)
+}
@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// conventionsFiles are checked in order; the first one present in the project
+// directory is included in the onboarding brief.
+var conventionsFiles = []string{"CONVENTIONS.md", "AGENTS.md", "CLAUDE.md", "CONTRIBUTING.md"}
+
+// conventionsMaxBytes caps the conventions excerpt carried in the brief.
+const conventionsMaxBytes = 6000
+
+// isFreshCursor reports whether an agent has never resumed before: no cursor
+// progress and no focus. Such agents get the onboarding brief.
+func isFreshCursor(cursor int64, focusTaskID string) bool {
+	return cursor == 0 && focusTaskID == ""
+}
+
+// buildOnboarding assembles the onboarding brief for projectID. Failures are
+// returned to the caller, which treats onboarding as best-effort.
+func buildOnboarding(ctx context.Context, db *sql.DB, projectID string) (*store.OnboardingBrief, error) {
+	ob, err := store.BuildOnboardingBrief(ctx, db, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build onboarding brief: %w", err)
+	}
+	ob.Conventions = readConventionsDoc(projectID)
+	return ob, nil
+}
+
+// readConventionsDoc returns the first conventions file found in dir, or nil
+// when dir is not a local directory or has none.
+func readConventionsDoc(dir string) *store.ConventionsDoc {
+	if dir == "" || !filepath.IsAbs(dir) {
+		return nil
+	}
+	for _, name := range conventionsFiles {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path) //nolint:gosec // G304: fixed file names inside the project directory
+		if err != nil {
+			continue
+		}
+		doc := &store.ConventionsDoc{Path: path}
+		if len(data) > conventionsMaxBytes {
+			data = data[:conventionsMaxBytes]
+			for len(data) > 0 && !utf8.Valid(data) {
+				data = data[:len(data)-1]
+			}
+			doc.Truncated = true
+		}
+		doc.Content = string(data)
+		return doc
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package actions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestResume_OnboardingOnFreshCursor(t *testing.T) {
+	db, cleanup := setupTestDBWithCleanup(t)
+	defer cleanup()
+
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "AGENTS.md"), []byte("Run make test before pushing.\n"), 0o600))
+
+	base, err := store.CreateTask(context.Background(), db, "Schema", "", projectDir, 5)
+	require.NoError(t, err)
+	child, err := store.CreateTask(context.Background(), db, "API", "", projectDir, 9)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO task_dependencies (task_id, depends_on_task_id) VALUES (?, ?)`, child.ID, base.ID)
+	require.NoError(t, err)
+	require.NoError(t, store.SetMemory(context.Background(), db, "build", "make build", "string", "project", projectDir, nil, false, "", nil))
+	require.NoError(t, store.SetMemory(context.Background(), db, "flaky-ci", "retry integration once", "string", "global", "", nil, false, "lesson", nil))
+
+	r, err := ResumeWithOptionsIdempotent(context.Background(), db, "newcomer", "onboard-1", ResumeOptions{ProjectDir: projectDir})
+	require.NoError(t, err)
+	ob := r.Brief.Onboarding
+	require.NotNil(t, ob)
+	require.Len(t, ob.ProjectMemory, 1)
+	require.Len(t, ob.Lessons, 1)
+	assert.Equal(t, "flaky-ci", ob.Lessons[0].Key)
+	assert.Equal(t, 1, ob.TaskGraph.DependencyEdges)
+	assert.Equal(t, 1, ob.TaskGraph.WaitingOnDeps)
+	require.Len(t, ob.TaskGraph.Ready, 1)
+	assert.Equal(t, base.ID, ob.TaskGraph.Ready[0].ID)
+	require.NotNil(t, ob.Conventions)
+	assert.Equal(t, filepath.Join(projectDir, "AGENTS.md"), ob.Conventions.Path)
+	assert.Contains(t, r.Prompt, "ONBOARDING")
+	assert.Contains(t, r.Prompt, "Run make test before pushing.")
+
+	// The returning agent gets the incremental brief only.
+	r, err = ResumeWithOptionsIdempotent(context.Background(), db, "newcomer", "onboard-2", ResumeOptions{ProjectDir: projectDir})
+	require.NoError(t, err)
+	assert.Nil(t, r.Brief.Onboarding)
+	assert.NotContains(t, r.Prompt, "ONBOARDING")
+
+	r, err = ResumeWithOptionsIdempotent(context.Background(), db, "newcomer", "onboard-3", ResumeOptions{ProjectDir: projectDir, Onboarding: true})
+	require.NoError(t, err)
+	assert.NotNil(t, r.Brief.Onboarding)
+}
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -11,12 +12,12 @@ import (
 
 // ProjectCreateIdempotent creates a project once per (agent_name, request_id).
 // On retries with the same request id, it returns the originally created project + event id.
-func ProjectCreateIdempotent(db *sql.DB, agentName, requestID, name, metadata string) (*models.Project, int64, error) {
+func ProjectCreateIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, name, metadata string) (*models.Project, int64, error) {
 	if name == "" {
 		return nil, 0, errors.New("project name is required")
 	}
 
-	project, eventID, err := runCreateWithEvent(db, agentName, requestID, "project.create", "create project", func(tx *sql.Tx) (models.Project, int64, error) {
+	project, eventID, err := runCreateWithEvent(ctx, db, agentName, requestID, "project.create", "create project", func(tx *sql.Tx) (models.Project, int64, error) {
 		createdProject, err := store.CreateProjectTx(tx, name, metadata)
 		if err != nil {
 			return models.Project{}, 0, err
@@ -37,23 +38,23 @@ func ProjectCreateIdempotent(db *sql.DB, agentName, requestID, name, metadata st
 }
 
 // ProjectFocusIdempotent sets the agent's focus project once per (agent_name, request_id).
-func ProjectFocusIdempotent(db *sql.DB, agentName, requestID, projectID string) (int64, error) {
+func ProjectFocusIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) (int64, error) {
 	if agentName == "" {
 		return 0, errors.New("agent name is required")
 	}
 	if requestID == "" {
 		return 0, errors.New("request id is required")
 	}
-	return store.SetAgentFocusProjectWithEventIdempotent(db, agentName, requestID, projectID)
+	return store.SetAgentFocusProjectWithEventIdempotent(ctx, db, agentName, requestID, projectID)
 }
 
 // ProjectGet retrieves a project by ID.
-func ProjectGet(db *sql.DB, projectID string) (*models.Project, error) {
+func ProjectGet(ctx context.Context, db *sql.DB, projectID string) (*models.Project, error) {
 	if projectID == "" {
 		return nil, errors.New("project ID is required")
 	}
 
-	project, err := store.GetProject(db, projectID)
+	project, err := store.GetProject(ctx, db, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project: %w", err)
 	}
@@ -61,12 +62,28 @@ func ProjectGet(db *sql.DB, projectID string) (*models.Project, error) {
 	return project, nil
 }
 
-// ProjectList retrieves all projects.
-func ProjectList(db *sql.DB) ([]*models.Project, error) {
-	projects, err := store.ListProjects(db)
+// ProjectList retrieves active projects, plus archived ones when includeArchived is set.
+func ProjectList(ctx context.Context, db *sql.DB, includeArchived bool) ([]*models.Project, error) {
+	list := store.ListProjects
+	if includeArchived {
+		list = store.ListProjectsIncludingArchived
+	}
+	projects, err := list(ctx, db)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
 
 	return projects, nil
 }
+
+// ProjectTrends returns a project's daily stats snapshots over the last days days.
+func ProjectTrends(ctx context.Context, db *sql.DB, projectID string, days int) (*store.ProjectTrends, error) {
+	if projectID == "" {
+		return nil, errors.New("project ID is required")
+	}
+	trends, err := store.ListProjectTrends(ctx, db, projectID, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project trends: %w", err)
+	}
+	return trends, nil
+}
@@ -0,0 +1,29 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// ProjectArchiveIdempotent archives or unarchives a project once per (agent_name, request_id).
+func ProjectArchiveIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, archived bool) (int64, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return 0, err
+	}
+	return store.SetProjectArchivedIdempotent(ctx, db, agentName, requestID, projectID, archived)
+}
+
+// ProjectPurgePreview reports the rows a purge of projectID would delete.
+func ProjectPurgePreview(ctx context.Context, db *sql.DB, projectID string) (*store.ProjectPurgeReport, error) {
+	return store.PreviewProjectPurge(ctx, db, projectID)
+}
+
+// ProjectPurgeIdempotent deletes a project and everything scoped to it.
+func ProjectPurgeIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) (*store.ProjectPurgeReport, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	return store.PurgeProjectIdempotent(ctx, db, agentName, requestID, projectID)
+}
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -9,8 +10,8 @@ import (
 )
 
 // ProjectDeleteIdempotent deletes a project and appends a project_deleted event, idempotent on request_id.
-func ProjectDeleteIdempotent(db *sql.DB, agentName, requestID, projectID string) (int64, error) {
-	return runDeleteIdempotent(db, deleteParams{
+func ProjectDeleteIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) (int64, error) {
+	return runDeleteIdempotent(ctx, db, deleteParams{
 		AgentName:    agentName,
 		RequestID:    requestID,
 		ResourceID:   projectID,
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -12,17 +13,17 @@ func TestProjectFocusIdempotent_ReplaysAfterProjectDeleted(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := store.CreateProject(db, "Replay Project", "")
+	project, err := store.CreateProject(context.Background(), db, "Replay Project", "")
 	require.NoError(t, err)
 
-	eventID1, err := ProjectFocusIdempotent(db, "agent-project", "req_project_focus", project.ID)
+	eventID1, err := ProjectFocusIdempotent(context.Background(), db, "agent-project", "req_project_focus", project.ID)
 	require.NoError(t, err)
 	require.Greater(t, eventID1, int64(0))
 
 	_, err = db.Exec(`DELETE FROM projects WHERE id = ?`, project.ID)
 	require.NoError(t, err)
 
-	eventID2, err := ProjectFocusIdempotent(db, "agent-project", "req_project_focus", project.ID)
+	eventID2, err := ProjectFocusIdempotent(context.Background(), db, "agent-project", "req_project_focus", project.ID)
 	require.NoError(t, err)
 	require.Equal(t, eventID1, eventID2)
 }
@@ -31,7 +32,7 @@ func TestProjectFocusIdempotent_RejectsUnknownProject(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := ProjectFocusIdempotent(db, "agent-project", "req_project_focus_missing", "project_missing")
+	_, err := ProjectFocusIdempotent(context.Background(), db, "agent-project", "req_project_focus_missing", "project_missing")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "project not found")
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // normalizePushMemories defaults empty Kind to "fact" and validates all kinds before
@@ -61,6 +62,7 @@ type PushTaskStatusInput struct {
 	Summary       string `json:"summary,omitempty"`
 	Label         string `json:"label,omitempty"`
 	BlockedReason string `json:"blocked_reason,omitempty"`
+	Strict        bool   `json:"strict,omitempty"` // refuse completion while acceptance criteria are unchecked
 }
 
 // PushInput holds all the optional sub-operations for a push.
@@ -70,6 +72,10 @@ type PushInput struct {
 	Memories   []PushMemoryInput    `json:"memories,omitempty"`
 	Artifacts  []PushArtifactInput  `json:"artifacts,omitempty"`
 	TaskStatus *PushTaskStatusInput `json:"task_status,omitempty"`
+
+	// StrictKinds rejects an event kind outside the registry even when
+	// strict_kinds is off in config.
+	StrictKinds bool `json:"strict_kinds,omitempty"`
 }
 
 // PushMemoryResult holds the result of a single memory upsert within push.
@@ -105,7 +111,10 @@ type PushResult struct {
 // Uses RunIdempotentWithRetry with maxAttempts=3 to handle CAS version conflicts on task status.
 //
 //nolint:gocognit,gocyclo,funlen // batch orchestration requires sequential sub-operations in one tx
-func PushIdempotent(db *sql.DB, agentName, requestID string, input PushInput) (*PushResult, error) {
+func PushIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, input PushInput) (_ *PushResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	if agentName == "" {
 		return nil, errors.New("agent name is required")
 	}
@@ -128,6 +137,10 @@ func PushIdempotent(db *sql.DB, agentName, requestID string, input PushInput) (*
 		}
 	}
 
+	if err := CheckConfinedTask(ctx, db, agentName, input.TaskID); err != nil {
+		return nil, err
+	}
+
 	// Must have at least one operation
 	if input.Event == nil && len(input.Memories) == 0 && len(input.Artifacts) == 0 && input.TaskStatus == nil {
 		return nil, errors.New("push requires at least one operation (event, memories, artifacts, or task_status)")
@@ -154,14 +167,21 @@ func PushIdempotent(db *sql.DB, agentName, requestID string, input PushInput) (*
 	}
 
 	r, _, err := store.RunIdempotentWithRetry(
-		context.Background(), db, agentName, requestID, "push",
+		ctx, db, agentName, requestID, "push",
 		3,
 		func(err error) bool { return errors.Is(err, store.ErrVersionConflict) },
 		func(tx *sql.Tx) (PushResult, error) {
 			var result PushResult
 
+			if err := store.CheckReferencesTx(tx, store.Reference{Field: "task_id", Kind: store.RefTask, ID: input.TaskID}); err != nil {
+				return PushResult{}, err
+			}
+
 			// 1. Insert event (if provided)
 			if input.Event != nil {
+				if err := store.CheckEventKindTx(tx, input.Event.Kind, input.StrictKinds); err != nil {
+					return PushResult{}, err
+				}
 				eventID, err := store.InsertEventTx(tx, input.Event.Kind, agentName, input.TaskID, input.Event.Message, string(input.Event.Metadata))
 				if err != nil {
 					return PushResult{}, fmt.Errorf("failed to insert event: %w", err)
@@ -216,6 +236,12 @@ func PushIdempotent(db *sql.DB, agentName, requestID string, input PushInput) (*
 				ts := input.TaskStatus
 				status := ts.Status
 
+				if ts.Strict && status == completedStatus {
+					if err := store.RequireCriteriaDoneTx(tx, input.TaskID); err != nil {
+						return PushResult{}, err
+					}
+				}
+
 				if status == completedStatus || status == blockedStatus {
 					// Use CloseTaskTx for terminal states
 					if ts.Summary == "" {
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ func TestPushIdempotent_EventOnly(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	result, err := PushIdempotent(db, "test-agent", "push_event_only", PushInput{
+	result, err := PushIdempotent(context.Background(), db, "test-agent", "push_event_only", PushInput{
 		Event: &PushEventInput{
 			Kind:    "progress",
 			Message: "doing work",
@@ -31,7 +32,7 @@ func TestPushIdempotent_MemoryOnly(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	result, err := PushIdempotent(db, "test-agent", "push_mem_only", PushInput{
+	result, err := PushIdempotent(context.Background(), db, "test-agent", "push_mem_only", PushInput{
 		Memories: []PushMemoryInput{
 			{
 				Key:   "foo",
@@ -51,10 +52,10 @@ func TestPushIdempotent_ArtifactsOnly(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, _, err := TaskCreateIdempotent(db, "test-agent", "req_create_art", "Artifact Task", "", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req_create_art", "Artifact Task", "", "", 0)
 	require.NoError(t, err)
 
-	result, err := PushIdempotent(db, "test-agent", "push_artifact_only", PushInput{
+	result, err := PushIdempotent(context.Background(), db, "test-agent", "push_artifact_only", PushInput{
 		TaskID: task.ID,
 		Artifacts: []PushArtifactInput{
 			{FilePath: "/tmp/output.txt"},
@@ -72,10 +73,10 @@ func TestPushIdempotent_TaskStatusOnly(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, _, err := TaskCreateIdempotent(db, "test-agent", "req_create_status", "Status Task", "", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req_create_status", "Status Task", "", "", 0)
 	require.NoError(t, err)
 
-	result, err := PushIdempotent(db, "test-agent", "push_task_status_only", PushInput{
+	result, err := PushIdempotent(context.Background(), db, "test-agent", "push_task_status_only", PushInput{
 		TaskID: task.ID,
 		TaskStatus: &PushTaskStatusInput{
 			Status: "in_progress",
@@ -87,7 +88,7 @@ func TestPushIdempotent_TaskStatusOnly(t *testing.T) {
 	assert.Equal(t, "in_progress", result.TaskStatus.Status)
 	assert.Greater(t, result.TaskStatus.StatusEventID, int64(0))
 
-	updated, err := store.GetTask(db, task.ID)
+	updated, err := store.GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "in_progress", string(updated.Status))
 }
@@ -96,10 +97,10 @@ func TestPushIdempotent_AllFour(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, _, err := TaskCreateIdempotent(db, "test-agent", "req_create_all4", "All Four Task", "", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req_create_all4", "All Four Task", "", "", 0)
 	require.NoError(t, err)
 
-	result, err := PushIdempotent(db, "test-agent", "push_all_four", PushInput{
+	result, err := PushIdempotent(context.Background(), db, "test-agent", "push_all_four", PushInput{
 		TaskID: task.ID,
 		Event: &PushEventInput{
 			Kind:    "progress",
@@ -127,7 +128,7 @@ func TestPushIdempotent_AllFour(t *testing.T) {
 	require.NotNil(t, result.TaskStatus)
 	assert.Equal(t, "completed", result.TaskStatus.Status)
 
-	updated, err := store.GetTask(db, task.ID)
+	updated, err := store.GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "completed", string(updated.Status))
 }
@@ -143,11 +144,11 @@ func TestPushIdempotent_Replay(t *testing.T) {
 		},
 	}
 
-	first, err := PushIdempotent(db, "test-agent", "push_replay_req", input)
+	first, err := PushIdempotent(context.Background(), db, "test-agent", "push_replay_req", input)
 	require.NoError(t, err)
 	require.NotNil(t, first)
 
-	second, err := PushIdempotent(db, "test-agent", "push_replay_req", input)
+	second, err := PushIdempotent(context.Background(), db, "test-agent", "push_replay_req", input)
 	require.NoError(t, err)
 	require.NotNil(t, second)
 
@@ -158,7 +159,7 @@ func TestPushIdempotent_ArtifactRequiresTaskID(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := PushIdempotent(db, "test-agent", "push_art_no_task", PushInput{
+	_, err := PushIdempotent(context.Background(), db, "test-agent", "push_art_no_task", PushInput{
 		Artifacts: []PushArtifactInput{
 			{FilePath: "/tmp/no_task.txt"},
 		},
@@ -171,7 +172,7 @@ func TestPushIdempotent_TaskStatusRequiresTaskID(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := PushIdempotent(db, "test-agent", "push_status_no_task", PushInput{
+	_, err := PushIdempotent(context.Background(), db, "test-agent", "push_status_no_task", PushInput{
 		TaskStatus: &PushTaskStatusInput{
 			Status: "in_progress",
 		},
@@ -184,7 +185,7 @@ func TestPushIdempotent_EmptyPush(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := PushIdempotent(db, "test-agent", "push_empty", PushInput{})
+	_, err := PushIdempotent(context.Background(), db, "test-agent", "push_empty", PushInput{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "at least one operation")
 }
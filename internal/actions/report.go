@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// Heatmap metrics: which count the rendered grid shades.
+const (
+	HeatmapMetricEvents      = "events"
+	HeatmapMetricCompletions = "completions"
+	HeatmapMetricFailures    = "failures"
+)
+
+// heatmapShades runs from no activity to the busiest cell.
+var heatmapShades = []rune(" ░▒▓█")
+
+var heatmapWeekdays = [7]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// ActivityHeatmap builds the activity heatmap for projectID (all projects when
+// empty) over the last sinceDays days. tz is an IANA zone name, "Local", or
+// empty for UTC.
+func ActivityHeatmap(ctx context.Context, db *sql.DB, projectID string, sinceDays int, tz string) (*store.ActivityHeatmap, error) {
+	loc := time.UTC
+	if tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", tz, err)
+		}
+	}
+	h, err := store.BuildActivityHeatmap(ctx, db, projectID, sinceDays, loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build activity heatmap: %w", err)
+	}
+	return h, nil
+}
+
+// ValidateHeatmapMetric reports an error for a metric the renderer does not know.
+func ValidateHeatmapMetric(metric string) error {
+	switch metric {
+	case HeatmapMetricEvents, HeatmapMetricCompletions, HeatmapMetricFailures:
+		return nil
+	}
+	return fmt.Errorf("invalid metric %q (valid: events, completions, failures)", metric)
+}
+
+func heatmapValue(c store.ActivityCounts, metric string) int {
+	switch metric {
+	case HeatmapMetricCompletions:
+		return c.Completions
+	case HeatmapMetricFailures:
+		return c.Failures
+	default:
+		return c.Events
+	}
+}
+
+// RenderActivityHeatmapMarkdown renders h as a weekday-by-hour shaded grid of
+// metric, followed by hourly and daily tables.
+func RenderActivityHeatmapMarkdown(h *store.ActivityHeatmap, metric string) string {
+	var b strings.Builder
+	scope := "all projects"
+	if h.ProjectID != "" {
+		scope = h.ProjectID
+	}
+	fmt.Fprintf(&b, "# Activity heatmap: %s\n\n", scope)
+	fmt.Fprintf(&b, "- Window: last %d days (%s)\n", h.SinceDays, h.Timezone)
+	fmt.Fprintf(&b, "- Totals: %d events, %d completions, %d failures\n\n",
+		h.Totals.Events, h.Totals.Completions, h.Totals.Failures)
+
+	peak := 0
+	for _, row := range h.Grid {
+		for _, c := range row {
+			peak = max(peak, heatmapValue(c, metric))
+		}
+	}
+
+	fmt.Fprintf(&b, "## %s by weekday and hour\n\n", strings.ToUpper(metric[:1])+metric[1:])
+	var hours strings.Builder
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(&hours, "%-3d", hour)
+	}
+	fmt.Fprintf(&b, "```\n     %s\n", strings.TrimRight(hours.String(), " "))
+	for wd, row := range h.Grid {
+		fmt.Fprintf(&b, "%s  ", heatmapWeekdays[wd])
+		for _, c := range row {
+			b.WriteRune(heatmapShade(heatmapValue(c, metric), peak))
+		}
+		fmt.Fprintf(&b, "  %d\n", heatmapValue(h.ByWeekday[wd], metric))
+	}
+	fmt.Fprintf(&b, "```\n\nScale: \"%c\" none … \"%c\" %d per hour (peak)\n\n",
+		heatmapShades[0], heatmapShades[len(heatmapShades)-1], peak)
+
+	b.WriteString("## By hour\n\n| Hour | Events | Completions | Failures |\n|------|--------|-------------|----------|\n")
+	for hour, c := range h.ByHour {
+		if c.Events == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "| %02d:00 | %d | %d | %d |\n", hour, c.Events, c.Completions, c.Failures)
+	}
+
+	b.WriteString("\n## By day\n\n| Day | Events | Completions | Failures |\n|-----|--------|-------------|----------|\n")
+	for _, d := range h.ByDay {
+		fmt.Fprintf(&b, "| %s | %d | %d | %d |\n", d.Day, d.Events, d.Completions, d.Failures)
+	}
+	return b.String()
+}
+
+// heatmapShade maps v onto heatmapShades relative to peak; any activity gets
+// at least the lightest non-blank shade.
+func heatmapShade(v, peak int) rune {
+	if v <= 0 || peak <= 0 {
+		return heatmapShades[0]
+	}
+	steps := len(heatmapShades) - 1
+	i := (v*steps + peak - 1) / peak
+	return heatmapShades[min(max(i, 1), steps)]
+}
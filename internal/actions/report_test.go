@@ -0,0 +1,59 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestHeatmapShade(t *testing.T) {
+	assert.Equal(t, ' ', heatmapShade(0, 10))
+	assert.Equal(t, '░', heatmapShade(1, 100), "any activity is visible")
+	assert.Equal(t, '█', heatmapShade(10, 10))
+	assert.Equal(t, '▒', heatmapShade(5, 10))
+}
+
+func TestRenderActivityHeatmapMarkdown(t *testing.T) {
+	h := &store.ActivityHeatmap{ProjectID: "/repo", SinceDays: 7, Timezone: "UTC",
+		ByDay: []store.ActivityDay{{Day: "2026-01-05", ActivityCounts: store.ActivityCounts{Events: 3, Failures: 2}}}}
+	h.Totals = h.ByDay[0].ActivityCounts
+	h.ByHour[9] = h.Totals
+	h.ByWeekday[0] = h.Totals
+	h.Grid[0][9] = h.Totals
+
+	md := RenderActivityHeatmapMarkdown(h, HeatmapMetricFailures)
+	assert.Contains(t, md, "# Activity heatmap: /repo")
+	assert.Contains(t, md, "## Failures by weekday and hour")
+	assert.Contains(t, md, "Mon  "+strings.Repeat(" ", 9)+"█"+strings.Repeat(" ", 14)+"  2\n")
+	assert.Contains(t, md, "| 09:00 | 3 | 0 | 2 |")
+	assert.Contains(t, md, "| 2026-01-05 | 3 | 0 | 2 |")
+
+	require.Error(t, ValidateHeatmapMetric("tokens"))
+}
+
+func TestRenderAnalyticsText(t *testing.T) {
+	a := &store.Analytics{
+		SinceDays:      7,
+		Throughput:     store.AnalyticsThroughput{Completed: 3, PerDay: 0.43, ByDay: []store.AnalyticsDayCount{{Day: "2026-01-05", Completed: 3}}},
+		TimeToComplete: store.AnalyticsTimeToComplete{Tasks: 3, MeanSeconds: 5400, MedianSeconds: 3600},
+		ToolFailures:   []store.ToolFailureRate{{Tool: "Bash", Calls: 4, Failures: 1, FailureRate: 0.25}},
+		Projects:       []store.ProjectActivity{{ProjectID: "/repo", Events: 10, Completions: 3}},
+		EventsByKind:   []store.EventKindCount{{Kind: "progress", Count: 10}},
+		TotalEvents:    10,
+	}
+
+	txt := RenderAnalyticsText(a)
+	assert.Contains(t, txt, "vybe analytics: all projects, last 7 days")
+	assert.Contains(t, txt, "Throughput: 3 tasks completed (0.43/day)")
+	assert.Contains(t, txt, "  2026-01-05  "+strings.Repeat("#", 30)+" 3\n")
+	assert.Contains(t, txt, "mean 1h30m0s, median 1h0m0s (3 tasks)")
+	assert.Contains(t, txt, "Bash           "+strings.Repeat("#", 7)+strings.Repeat(".", 23)+"  25.0%  1/4")
+	assert.Contains(t, txt, "10 events, 3 completed  /repo")
+
+	assert.Equal(t, strings.Repeat(".", 30), analyticsBar(0, 10))
+	assert.Equal(t, "#"+strings.Repeat(".", 29), analyticsBar(1, 1000), "any activity is visible")
+}
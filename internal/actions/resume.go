@@ -1,12 +1,15 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // ResumeResponse contains the complete response from a resume operation.
@@ -18,19 +21,43 @@ type ResumeResponse struct {
 	FocusTaskID    string             `json:"focus_task_id"`
 	FocusProjectID string             `json:"focus_project_id,omitempty"`
 	FocusRule      string             `json:"focus_rule,omitempty"`
+	LimitReached   string             `json:"limit_reached,omitempty"` // limit that kept resume from claiming a new task
 	Brief          *store.BriefPacket `json:"brief"`
 	Prompt         string             `json:"prompt"`
+	ContextProfile string             `json:"context_profile,omitempty"` // profile that shaped Prompt
 }
 
 // ResumeOptions controls the behavior of a resume operation.
 type ResumeOptions struct {
-	EventLimit        int
-	ProjectDir        string // When set, scope resume to this project and include recent prompts for it
-	FocusTaskOverride string // When set, override focus task atomically within the resume transaction
+	EventLimit         int
+	ProjectDir         string          // When set, scope resume to this project and include recent prompts for it
+	FocusTaskOverride  string          // When set, override focus task atomically within the resume transaction
+	MaxTokens          int             // When > 0, shape the brief to fit this token budget (see store.ShapeBrief)
+	FocusPolicy        app.FocusPolicy // When set, overrides focus.policy from config for this call
+	SessionID          string          // When set, read and write this session's focus instead of the agent-wide focus
+	Onboarding         bool            // Attach the onboarding brief even when the agent's cursor is not fresh
+	Tags               []string        // When set, only focus tasks carrying every one of these tags
+	MinPriority        *int            // When set, only focus tasks with at least this effective priority
+	OverrideLimits     bool            // Claim a new task even when the tasks_per_day limit is reached
+	IncludeAgentMemory bool            // Merge the agent's own agent-scoped memory into the brief (default: brief.include_agent_memory)
+	ContextProfile     string          // Names the app.ContextProfile that shapes the prompt (default: context.profile for the focus project)
+}
+
+// BriefOptions controls the behavior of a read-only brief.
+type BriefOptions struct {
+	MaxTokens          int    // When > 0, shape the brief to fit this token budget
+	ProjectDir         string // When set, build the brief for this project instead of the agent's focus project
+	SessionID          string // When set, brief the session's focus instead of the agent-wide focus
+	TaskID             string // When set, brief this task and its project regardless of any focus
+	Onboarding         bool   // Attach the onboarding brief for the focus project
+	IncludeAgentMemory bool   // Merge the agent's own agent-scoped memory into the brief (default: brief.include_agent_memory)
 }
 
 // ResumeWithOptionsIdempotent performs Resume once per (agentName, requestID); replays the original response on retries.
-func ResumeWithOptionsIdempotent(db *sql.DB, agentName, requestID string, opts ResumeOptions) (*ResumeResponse, error) {
+func ResumeWithOptionsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, opts ResumeOptions) (_ *ResumeResponse, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	if agentName == "" {
 		return nil, errors.New("agent name is required")
 	}
@@ -39,36 +66,82 @@ func ResumeWithOptionsIdempotent(db *sql.DB, agentName, requestID string, opts R
 	}
 	opts = normalizeResumeOptions(opts)
 
-	pkt, err := computeResumePacket(db, agentName, opts)
+	pkt, err := computeResumePacket(ctx, db, agentName, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	resp := buildResumeResponse(agentName, pkt)
-	persisted, err := persistResumeResponse(db, agentName, requestID, opts, resp)
+	persisted, err := persistResumeResponse(ctx, db, agentName, requestID, opts, resp, pkt.claimed)
 	if err != nil {
 		return nil, err
 	}
 
-	reconcileResumeContention(db, agentName, pkt, &persisted)
+	reconcileResumeContention(ctx, db, agentName, pkt, &persisted)
 	return &persisted, nil
 }
 
 // Brief returns a brief packet for an agent's current focus without advancing cursor.
-func Brief(db *sql.DB, agentName string) (*store.BriefPacket, error) {
+func Brief(ctx context.Context, db *sql.DB, agentName string) (*store.BriefPacket, error) {
+	return BriefWithOptions(ctx, db, agentName, BriefOptions{})
+}
+
+// BriefWithOptions is Brief with optional token-budget shaping.
+func BriefWithOptions(ctx context.Context, db *sql.DB, agentName string, opts BriefOptions) (_ *store.BriefPacket, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	if agentName == "" {
 		return nil, errors.New("agent name is required")
 	}
 
-	state, err := store.LoadOrCreateAgentState(db, agentName)
+	state, err := store.LoadOrCreateAgentState(ctx, db, agentName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load agent state: %w", err)
 	}
 
-	brief, err := store.BuildBrief(db, state.FocusTaskID, state.FocusProjectID, agentName)
+	focusTaskID, focusProjectID := state.FocusTaskID, state.FocusProjectID
+	if opts.SessionID != "" {
+		sf, _, err := store.GetSessionFocus(ctx, db, agentName, opts.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		focusTaskID = sf.FocusTaskID
+		if sf.FocusProjectID != "" {
+			focusProjectID = sf.FocusProjectID
+		}
+	}
+	projectID, err := ConfineProjectFilter(ctx, db, agentName, opts.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckConfinedTask(ctx, db, agentName, opts.TaskID); err != nil {
+		return nil, err
+	}
+	if opts.TaskID != "" {
+		task, err := store.GetTask(ctx, db, opts.TaskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get task: %w", err)
+		}
+		focusTaskID, focusProjectID = task.ID, task.ProjectID
+	}
+	if projectID != "" {
+		focusProjectID = projectID
+	}
+
+	brief, err := store.BuildBriefWithOptions(ctx, db, focusTaskID, focusProjectID, agentName, store.BriefBuildOptions{
+		IncludeAgentMemory: opts.IncludeAgentMemory || app.AgentMemoryInBriefs(),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to build brief: %w", err)
 	}
+	store.ShapeBrief(brief, opts.MaxTokens)
+
+	if opts.Onboarding && focusProjectID != "" {
+		if brief.Onboarding, err = buildOnboarding(ctx, db, focusProjectID); err != nil {
+			return nil, err
+		}
+	}
 
 	return brief, nil
 }
@@ -21,18 +21,18 @@ func TestResumeInterruptionScenario(t *testing.T) {
 	// === Phase 1: Initial work session ===
 
 	// Create some tasks (using actions layer to log events)
-	task1, _, err := TaskCreateIdempotent(db, agentName, "req-integ-create-1", "Task 1", "First task", "", 0)
+	task1, _, err := TaskCreateIdempotent(context.Background(), db, agentName, "req-integ-create-1", "Task 1", "First task", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task 1: %v", err)
 	}
 
-	task2, _, err := TaskCreateIdempotent(db, agentName, "req-integ-create-2", "Task 2", "Second task", "", 0)
+	task2, _, err := TaskCreateIdempotent(context.Background(), db, agentName, "req-integ-create-2", "Task 2", "Second task", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task 2: %v", err)
 	}
 
 	// Agent resumes for the first time
-	response1, err := ResumeWithOptionsIdempotent(db, agentName, "req-integ-resume-1", ResumeOptions{EventLimit: 1000})
+	response1, err := ResumeWithOptionsIdempotent(context.Background(), db, agentName, "req-integ-resume-1", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("First resume failed: %v", err)
 	}
@@ -51,7 +51,7 @@ func TestResumeInterruptionScenario(t *testing.T) {
 	}
 
 	// Agent starts working on task1 (using actions layer to log events)
-	_, _, err = TaskSetStatusIdempotent(db, agentName, "req-integ-status-1", task1.ID, "in_progress", "")
+	_, _, err = TaskSetStatusIdempotent(context.Background(), db, agentName, "req-integ-status-1", task1.ID, "in_progress", "")
 	if err != nil {
 		t.Fatalf("Failed to update task status: %v", err)
 	}
@@ -70,7 +70,7 @@ func TestResumeInterruptionScenario(t *testing.T) {
 	// === Phase 2: Resume after interruption ===
 
 	// Agent resumes after restart
-	response2, err := ResumeWithOptionsIdempotent(db, agentName, "req-integ-resume-2", ResumeOptions{EventLimit: 1000})
+	response2, err := ResumeWithOptionsIdempotent(context.Background(), db, agentName, "req-integ-resume-2", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Second resume failed: %v", err)
 	}
@@ -100,13 +100,13 @@ func TestResumeInterruptionScenario(t *testing.T) {
 	// === Phase 3: Complete task and pick up next ===
 
 	// Agent completes task1 (using actions layer)
-	_, _, err = TaskSetStatusIdempotent(db, agentName, "req-integ-status-2", task1.ID, "completed", "")
+	_, _, err = TaskSetStatusIdempotent(context.Background(), db, agentName, "req-integ-status-2", task1.ID, "completed", "")
 	if err != nil {
 		t.Fatalf("Failed to complete task: %v", err)
 	}
 
 	// Resume again
-	response3, err := ResumeWithOptionsIdempotent(db, agentName, "req-integ-resume-3", ResumeOptions{EventLimit: 1000})
+	response3, err := ResumeWithOptionsIdempotent(context.Background(), db, agentName, "req-integ-resume-3", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Third resume failed: %v", err)
 	}
@@ -124,13 +124,13 @@ func TestResumeInterruptionScenario(t *testing.T) {
 	// === Phase 4: No more work ===
 
 	// Complete task2 (using actions layer)
-	_, _, err = TaskSetStatusIdempotent(db, agentName, "req-integ-status-3", task2.ID, "completed", "")
+	_, _, err = TaskSetStatusIdempotent(context.Background(), db, agentName, "req-integ-status-3", task2.ID, "completed", "")
 	if err != nil {
 		t.Fatalf("Failed to complete task2: %v", err)
 	}
 
 	// Resume with no pending work
-	response4, err := ResumeWithOptionsIdempotent(db, agentName, "req-integ-resume-4", ResumeOptions{EventLimit: 1000})
+	response4, err := ResumeWithOptionsIdempotent(context.Background(), db, agentName, "req-integ-resume-4", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Fourth resume failed: %v", err)
 	}
@@ -156,31 +156,31 @@ func TestResumeWithMemoryContext(t *testing.T) {
 	agentName := "memory-agent"
 
 	// Create a task (using actions layer)
-	task, _, err := TaskCreateIdempotent(db, agentName, "req-mem-ctx-create-1", "Task with context", "Description", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, agentName, "req-mem-ctx-create-1", "Task with context", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// Add global memory
-	err = store.SetMemory(db, "api_url", "https://api.example.com", "string", "global", "", nil, false, "", nil)
+	err = store.SetMemory(context.Background(), db, "api_url", "https://api.example.com", "string", "global", "", nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set global memory: %v", err)
 	}
 
 	// Add task-specific memory
-	err = store.SetMemory(db, "checkpoint", "step_3", "string", "task", task.ID, nil, false, "", nil)
+	err = store.SetMemory(context.Background(), db, "checkpoint", "step_3", "string", "task", task.ID, nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set task memory: %v", err)
 	}
 
 	// Add agent-specific memory (should NOT be included in brief)
-	err = store.SetMemory(db, "local_cache", "/tmp/cache", "string", "agent", agentName, nil, false, "", nil)
+	err = store.SetMemory(context.Background(), db, "local_cache", "/tmp/cache", "string", "agent", agentName, nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set agent memory: %v", err)
 	}
 
 	// Resume
-	response, err := ResumeWithOptionsIdempotent(db, agentName, "req-mem-ctx-resume-1", ResumeOptions{EventLimit: 1000})
+	response, err := ResumeWithOptionsIdempotent(context.Background(), db, agentName, "req-mem-ctx-resume-1", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Resume failed: %v", err)
 	}
@@ -242,7 +242,7 @@ func TestMonotonicCursorAdvancement(t *testing.T) {
 	}
 
 	// First resume (cursor 0 -> 5)
-	response1, err := ResumeWithOptionsIdempotent(db, agentName, "req-mono-resume-1", ResumeOptions{EventLimit: 1000})
+	response1, err := ResumeWithOptionsIdempotent(context.Background(), db, agentName, "req-mono-resume-1", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("First resume failed: %v", err)
 	}
@@ -256,7 +256,7 @@ func TestMonotonicCursorAdvancement(t *testing.T) {
 	}
 
 	// Second resume immediately (no new events, cursor stays at 5)
-	response2, err := ResumeWithOptionsIdempotent(db, agentName, "req-mono-resume-2", ResumeOptions{EventLimit: 1000})
+	response2, err := ResumeWithOptionsIdempotent(context.Background(), db, agentName, "req-mono-resume-2", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Second resume failed: %v", err)
 	}
@@ -284,7 +284,7 @@ func TestMonotonicCursorAdvancement(t *testing.T) {
 	}
 
 	// Third resume (cursor 5 -> 8)
-	response3, err := ResumeWithOptionsIdempotent(db, agentName, "req-mono-resume-3", ResumeOptions{EventLimit: 1000})
+	response3, err := ResumeWithOptionsIdempotent(context.Background(), db, agentName, "req-mono-resume-3", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Third resume failed: %v", err)
 	}
@@ -1,11 +1,16 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // resumePacket holds pre-computed state for both resume variants.
@@ -16,15 +21,22 @@ type resumePacket struct {
 	focusProjectID string
 	focusTaskID    string
 	focusRule      string
+	limitReached   string // limit that blocked a new claim
+	claimed        bool   // focus moved to a newly picked task; counts toward tasks_per_day
 	deltas         []*models.Event
 	brief          *store.BriefPacket
 	recentPrompts  []*models.Event
+	maxTokens      int
+	briefOpts      store.BriefBuildOptions
+	onboarding     *store.OnboardingBrief
+	profile        app.ContextProfile
 }
 
 type resumeStateSnapshot struct {
 	oldCursor      int64
 	oldFocusID     string
 	focusProjectID string
+	excludeTaskIDs []string // tasks held by sibling sessions (session-scoped resume only)
 }
 
 func normalizeResumeOptions(opts ResumeOptions) ResumeOptions {
@@ -34,25 +46,56 @@ func normalizeResumeOptions(opts ResumeOptions) ResumeOptions {
 	if opts.EventLimit > 1000 {
 		opts.EventLimit = 1000
 	}
+	if opts.FocusPolicy == "" {
+		opts.FocusPolicy = app.FocusPolicyFor("")
+	}
+	if !opts.IncludeAgentMemory {
+		opts.IncludeAgentMemory = app.AgentMemoryInBriefs()
+	}
 	return opts
 }
 
-func loadResumeStateSnapshot(db *sql.DB, agentName string, opts ResumeOptions) (*resumeStateSnapshot, error) {
-	state, err := store.LoadOrCreateAgentState(db, agentName)
+func loadResumeStateSnapshot(ctx context.Context, db *sql.DB, agentName string, opts ResumeOptions) (*resumeStateSnapshot, error) {
+	state, err := store.LoadOrCreateAgentState(ctx, db, agentName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load agent state: %w", err)
 	}
 
-	focusProjectID := state.FocusProjectID
-	if opts.ProjectDir != "" {
-		focusProjectID = opts.ProjectDir
-	}
-
-	return &resumeStateSnapshot{
+	snapshot := &resumeStateSnapshot{
 		oldCursor:      state.LastSeenEventID,
 		oldFocusID:     state.FocusTaskID,
-		focusProjectID: focusProjectID,
-	}, nil
+		focusProjectID: state.FocusProjectID,
+	}
+
+	// A session starts without focus and never inherits the agent-wide pointer,
+	// which belongs to whichever session resumed last.
+	if opts.SessionID != "" {
+		sf, _, err := store.GetSessionFocus(ctx, db, agentName, opts.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.oldFocusID = sf.FocusTaskID
+		if sf.FocusProjectID != "" {
+			snapshot.focusProjectID = sf.FocusProjectID
+		}
+		snapshot.excludeTaskIDs, err = store.SiblingSessionFocusTasks(ctx, db, agentName, opts.SessionID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A confined agent always resumes inside its project.
+	projectID, err := ConfineProjectFilter(ctx, db, agentName, opts.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	if projectID != "" {
+		snapshot.focusProjectID = projectID
+	}
+	if err := CheckConfinedTask(ctx, db, agentName, opts.FocusTaskOverride); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
 }
 
 func calculateResumeCursor(oldCursor int64, deltas []*models.Event) int64 {
@@ -65,8 +108,8 @@ func calculateResumeCursor(oldCursor int64, deltas []*models.Event) int64 {
 	return newCursor
 }
 
-func fetchResumeDeltas(db *sql.DB, snapshot *resumeStateSnapshot, opts ResumeOptions) ([]*models.Event, int64, error) {
-	deltas, err := store.FetchEventsSince(db, snapshot.oldCursor, opts.EventLimit, snapshot.focusProjectID)
+func fetchResumeDeltas(ctx context.Context, db *sql.DB, snapshot *resumeStateSnapshot, opts ResumeOptions) ([]*models.Event, int64, error) {
+	deltas, err := store.FetchEventsSince(ctx, db, snapshot.oldCursor, opts.EventLimit, snapshot.focusProjectID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to fetch deltas: %w", err)
 	}
@@ -78,28 +121,69 @@ func fetchResumeDeltas(db *sql.DB, snapshot *resumeStateSnapshot, opts ResumeOpt
 // A concurrent agent can change focus_project_id between this read and the
 // subsequent state update. The response reflects computed state, not necessarily
 // the final persisted state.
-func computeResumePacket(db *sql.DB, agentName string, opts ResumeOptions) (*resumePacket, error) {
-	snapshot, err := loadResumeStateSnapshot(db, agentName, opts)
+func computeResumePacket(ctx context.Context, db *sql.DB, agentName string, opts ResumeOptions) (_ *resumePacket, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	snapshot, err := loadResumeStateSnapshot(ctx, db, agentName, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	deltas, newCursor, err := fetchResumeDeltas(db, snapshot, opts)
+	deltas, newCursor, err := fetchResumeDeltas(ctx, db, snapshot, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	focusResult, err := store.DetermineFocusTask(db, agentName, snapshot.oldFocusID, deltas, snapshot.focusProjectID)
+	focusResult, err := store.DetermineFocusTaskFiltered(ctx, db, agentName, snapshot.oldFocusID, deltas, snapshot.focusProjectID, opts.FocusPolicy, store.FocusFilter{
+		Exclude:     snapshot.excludeTaskIDs,
+		Tags:        opts.Tags,
+		MinPriority: opts.MinPriority,
+		RoutedTo:    agentName,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine focus task: %w", err)
 	}
+	claimed := focusResult.TaskID != "" && focusResult.TaskID != snapshot.oldFocusID && opts.FocusTaskOverride == ""
+	limitReached := ""
+	if claimed && !opts.OverrideLimits {
+		if limitErr := CheckDailyLimit(ctx, db, store.LimitTasksPerDay); limitErr != nil {
+			limitReached = limitReachedName(limitErr)
+			if limitReached == "" {
+				return nil, limitErr
+			}
+			focusResult = store.FocusResult{Rule: "limit: " + limitErr.Error()}
+			claimed = false
+		}
+	}
+
+	profile := app.EffectiveContextProfile(snapshot.focusProjectID)
+	if opts.ContextProfile != "" {
+		if profile, err = app.LookupContextProfile(opts.ContextProfile); err != nil {
+			return nil, err
+		}
+	}
 
-	brief, err := store.BuildBrief(db, focusResult.TaskID, snapshot.focusProjectID, agentName)
+	briefOpts := store.BriefBuildOptions{IncludeAgentMemory: opts.IncludeAgentMemory, LessonLimit: contextLessonLimit(profile)}
+	brief, err := store.BuildBriefWithOptions(ctx, db, focusResult.TaskID, snapshot.focusProjectID, agentName, briefOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build brief: %w", err)
 	}
+	store.ShapeBrief(brief, opts.MaxTokens)
+
+	// Onboarding is attached after shaping: it is a one-time expanded brief and
+	// is deliberately not trimmed by --max-tokens.
+	var onboarding *store.OnboardingBrief
+	if snapshot.focusProjectID != "" && (opts.Onboarding || isFreshCursor(snapshot.oldCursor, snapshot.oldFocusID)) {
+		if ob, obErr := buildOnboarding(ctx, db, snapshot.focusProjectID); obErr == nil {
+			onboarding = ob
+			brief.Onboarding = ob
+		} else {
+			slog.Default().Warn("onboarding brief failed", "error", obErr, "project", snapshot.focusProjectID)
+		}
+	}
 
-	recentPrompts, _ := store.FetchRecentUserPrompts(db, snapshot.focusProjectID, 5) //nolint:errcheck // supplementary context; nil slice is safe
+	recentPrompts, _ := store.FetchRecentUserPrompts(ctx, db, snapshot.focusProjectID, 5) //nolint:errcheck // supplementary context; nil slice is safe
 
 	return &resumePacket{
 		oldCursor:      snapshot.oldCursor,
@@ -108,9 +192,15 @@ func computeResumePacket(db *sql.DB, agentName string, opts ResumeOptions) (*res
 		focusProjectID: snapshot.focusProjectID,
 		focusTaskID:    focusResult.TaskID,
 		focusRule:      focusResult.Rule,
+		limitReached:   limitReached,
+		claimed:        claimed,
 		deltas:         deltas,
 		brief:          brief,
 		recentPrompts:  recentPrompts,
+		maxTokens:      opts.MaxTokens,
+		briefOpts:      briefOpts,
+		onboarding:     onboarding,
+		profile:        profile,
 	}, nil
 }
 
@@ -123,7 +213,9 @@ func buildResumeResponse(agentName string, pkt *resumePacket) *ResumeResponse {
 		FocusTaskID:    pkt.focusTaskID,
 		FocusProjectID: pkt.focusProjectID,
 		FocusRule:      pkt.focusRule,
+		LimitReached:   pkt.limitReached,
 		Brief:          pkt.brief,
-		Prompt:         buildPrompt(agentName, pkt.brief, pkt.recentPrompts),
+		Prompt:         buildPromptWithProfile(agentName, pkt.brief, pkt.recentPrompts, pkt.profile),
+		ContextProfile: pkt.profile.Name,
 	}
 }
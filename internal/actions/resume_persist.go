@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/store"
@@ -15,6 +16,9 @@ func applyResumeFocusOverride(tx *sql.Tx, agentName string, opts ResumeOptions,
 		return resp, nil
 	}
 
+	if err := store.CheckReferencesTx(tx, store.Reference{Field: "focus", Kind: store.RefTask, ID: opts.FocusTaskOverride}); err != nil {
+		return ResumeResponse{}, err
+	}
 	resp.FocusTaskID = opts.FocusTaskOverride
 	if _, err := store.InsertEventTx(tx, models.EventKindAgentFocus, agentName, opts.FocusTaskOverride, fmt.Sprintf("Focus set: %s", opts.FocusTaskOverride), ""); err != nil {
 		return ResumeResponse{}, fmt.Errorf("failed to emit focus event: %w", err)
@@ -42,9 +46,9 @@ func loadAuthoritativeResumeState(tx *sql.Tx, agentName string, resp ResumeRespo
 	return resp, nil
 }
 
-func persistResumeResponse(db *sql.DB, agentName, requestID string, opts ResumeOptions, resp *ResumeResponse) (ResumeResponse, error) {
+func persistResumeResponse(ctx context.Context, db *sql.DB, agentName, requestID string, opts ResumeOptions, resp *ResumeResponse, claimed bool) (ResumeResponse, error) {
 	persisted, _, err := store.RunIdempotentWithRetry(
-		context.Background(),
+		ctx,
 		db,
 		agentName,
 		requestID,
@@ -63,8 +67,25 @@ func persistResumeResponse(db *sql.DB, agentName, requestID string, opts ResumeO
 			if err := updateResumeAgentState(tx, agentName, opts, applied); err != nil {
 				return ResumeResponse{}, err
 			}
+			if claimed {
+				if err := store.IncrementLimitUsageTx(tx, store.LimitTasksPerDay, time.Now()); err != nil {
+					return ResumeResponse{}, err
+				}
+			}
 
-			return loadAuthoritativeResumeState(tx, agentName, applied)
+			sessionProjectID := applied.FocusProjectID
+			applied, err = loadAuthoritativeResumeState(tx, agentName, applied)
+			if err != nil {
+				return ResumeResponse{}, err
+			}
+			if opts.SessionID != "" {
+				// The agent-wide project pointer may belong to a sibling session.
+				applied.FocusProjectID = sessionProjectID
+				if err := store.SetSessionFocusTx(tx, agentName, opts.SessionID, applied.FocusTaskID, applied.FocusProjectID); err != nil {
+					return ResumeResponse{}, err
+				}
+			}
+			return applied, nil
 		},
 	)
 	if err != nil {
@@ -78,17 +99,19 @@ func resumeStateChanged(pkt *resumePacket, resp ResumeResponse) bool {
 	return resp.FocusTaskID != pkt.focusTaskID || resp.NewCursor != pkt.newCursor || resp.FocusProjectID != pkt.focusProjectID
 }
 
-func reconcileResumeContention(db *sql.DB, agentName string, pkt *resumePacket, resp *ResumeResponse) {
+func reconcileResumeContention(ctx context.Context, db *sql.DB, agentName string, pkt *resumePacket, resp *ResumeResponse) {
 	if !resumeStateChanged(pkt, *resp) {
 		return
 	}
 
-	newBrief, err := store.BuildBrief(db, resp.FocusTaskID, resp.FocusProjectID, agentName)
+	newBrief, err := store.BuildBriefWithOptions(ctx, db, resp.FocusTaskID, resp.FocusProjectID, agentName, pkt.briefOpts)
 	if err != nil {
 		slog.Default().Warn("failed to rebuild brief after contention", "error", err)
-		resp.Brief = &store.BriefPacket{}
+		resp.Brief = &store.BriefPacket{BriefVersion: store.BriefSchemaVersion}
 	} else {
+		store.ShapeBrief(newBrief, pkt.maxTokens)
+		newBrief.Onboarding = pkt.onboarding
 		resp.Brief = newBrief
 	}
-	resp.Prompt = buildPrompt(agentName, resp.Brief, pkt.recentPrompts)
+	resp.Prompt = buildPromptWithProfile(agentName, resp.Brief, pkt.recentPrompts, pkt.profile)
 }
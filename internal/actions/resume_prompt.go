@@ -8,6 +8,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/store"
 )
@@ -37,10 +38,6 @@ func sortMemoryByScope(ms []*models.Memory) {
 }
 
 const (
-	// defaultContextBudget is the token budget for variable prompt sections
-	// (memory, recent prompts, events, reasoning). Fixed sections always included.
-	defaultContextBudget = 1500
-
 	// staleSoftDays: memory older than this gets a soft age marker; agent
 	// should treat it as possibly drifted.
 	staleSoftDays = 30
@@ -70,20 +67,40 @@ func staleTag(updatedAt time.Time, pinned bool, expiresAt *time.Time, now time.T
 	}
 }
 
-// buildPrompt generates the context prompt injected into agent sessions.
+// buildPrompt generates the context prompt injected into agent sessions, in the
+// standard context profile.
 func buildPrompt(agentName string, brief *store.BriefPacket, recentPrompts []*models.Event) string {
+	profile, _ := app.LookupContextProfile(app.ContextStandard)
+	return buildPromptWithProfile(agentName, brief, recentPrompts, profile)
+}
+
+// buildPromptWithProfile generates the context prompt with the sections and
+// limits profile selects.
+func buildPromptWithProfile(agentName string, brief *store.BriefPacket, recentPrompts []*models.Event, profile app.ContextProfile) string {
 	var b strings.Builder
+	brief = profileBrief(brief, profile)
+	recentPrompts = profileLimit(recentPrompts, profile.History)
 
 	b.WriteString("== VYBE (task tracker) ==\n")
 	task := getBriefTask(brief)
 
 	// Fixed sections — always included, not counted against budget.
+	appendFocusNotices(&b, brief)
+	appendOverdueNotice(&b, brief)
+	appendMemoryConflictNotice(&b, brief)
 	appendTaskContext(&b, brief, task)
+	appendDependencyContext(&b, brief)
+	appendDelegationContext(&b, brief)
+	appendNextActions(&b, brief)
+	appendInboxNotice(&b, brief)
+	appendOnboardingContext(&b, brief)
 	appendDecisionProtocol(&b, task)
 
 	// Variable sections — ranked by priority, filled until budget exhausted.
-	budget := defaultContextBudget
+	budget := profile.Budget
 	appendMemoryContext(&b, brief, &budget)
+	appendDecisionsContext(&b, brief, &budget)
+	appendLessonsContext(&b, brief, &budget)
 	appendRecentPromptsContext(&b, recentPrompts, &budget)
 	appendEventContext(&b, brief, &budget)
 	appendReasoningContext(&b, brief, &budget)
@@ -115,6 +132,19 @@ func appendTaskContext(b *strings.Builder, brief *store.BriefPacket, task *model
 	if task.Description != "" {
 		fmt.Fprintf(b, "  Description: %s\n", task.Description)
 	}
+	if task.Attempts > 0 {
+		fmt.Fprintf(b, "  Previous failed attempts: %d (what they printed: vybe loop logs --task %s --output)\n", task.Attempts, task.ID)
+	}
+	if brief != nil && len(brief.Criteria) > 0 {
+		fmt.Fprintf(b, "  Acceptance criteria (verify each, then: vybe task criteria check --id=%s --criterion=N):\n", task.ID)
+		for _, c := range brief.Criteria {
+			mark := " "
+			if c.Done {
+				mark = "x"
+			}
+			fmt.Fprintf(b, "    [%s] #%d %s\n", mark, c.ID, c.Text)
+		}
+	}
 
 	actionable := 1
 	if brief != nil && brief.Counts != nil {
@@ -123,6 +153,166 @@ func appendTaskContext(b *strings.Builder, brief *store.BriefPacket, task *model
 	fmt.Fprintf(b, "\n%d task(s) awaiting action in this project.\n", actionable)
 }
 
+// appendDependencyContext lists the unfinished tasks the focus task waits on.
+// Only profiles with Dependencies set keep them in the brief they render.
+func appendDependencyContext(b *strings.Builder, brief *store.BriefPacket) {
+	if brief == nil || len(brief.Dependencies) == 0 {
+		return
+	}
+	b.WriteString("\nWaiting on dependencies:\n")
+	for _, d := range brief.Dependencies {
+		fmt.Fprintf(b, "  - %s (%s, %s)\n", d.Title, d.ID, d.Status)
+	}
+}
+
+// appendDelegationContext lists the tasks the agent handed to sub-agents and
+// how far each sub-agent got.
+func appendDelegationContext(b *strings.Builder, brief *store.BriefPacket) {
+	if brief == nil || len(brief.Delegations) == 0 {
+		return
+	}
+	b.WriteString("\nDelegated to sub-agents:\n")
+	for _, d := range brief.Delegations {
+		fmt.Fprintf(b, "  - %s (%s) -> %s: %s, task %s\n", d.TaskTitle, d.TaskID, d.Subagent, d.Status, d.TaskStatus)
+	}
+}
+
+func appendOverdueNotice(b *strings.Builder, brief *store.BriefPacket) {
+	if brief == nil || len(brief.Overdue) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\nOVERDUE: %d task(s) past their deadline:\n", len(brief.Overdue))
+	for _, t := range brief.Overdue {
+		due := ""
+		if t.DueAt != nil {
+			due = t.DueAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(b, "  - %s (%s) due %s\n", t.Title, t.ID, due)
+	}
+}
+
+// appendMemoryConflictNotice lists keys two agents disagree on, so the agent
+// does not act on a value that is still in dispute.
+func appendMemoryConflictNotice(b *strings.Builder, brief *store.BriefPacket) {
+	if brief == nil || len(brief.MemoryConflicts) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\nMEMORY CONFLICTS: %d key(s) with disputed values (vybe memory resolve --id=N --keep=a|b|merge):\n", len(brief.MemoryConflicts))
+	for _, c := range brief.MemoryConflicts {
+		fmt.Fprintf(b, "  - #%d %s: a=%q (%s) b=%q (%s, current)\n", c.ID, c.Key,
+			truncateConflictValue(c.ValueA), c.AgentA, truncateConflictValue(c.ValueB), c.AgentB)
+	}
+}
+
+func truncateConflictValue(v string) string {
+	v, _ = truncatePromptRunes(v, 80)
+	return v
+}
+
+// appendFocusNotices lists unread vybe notices first: they usually say a task
+// was taken away, and an agent still working it must stop.
+func appendFocusNotices(b *strings.Builder, brief *store.BriefPacket) {
+	if brief == nil || len(brief.Notices) == 0 {
+		return
+	}
+	b.WriteString("\nNOTICE from vybe:\n")
+	ack := make([]string, len(brief.Notices))
+	for i, n := range brief.Notices {
+		fmt.Fprintf(b, "  - %s\n", n.Subject)
+		ack[i] = "--id " + n.ID
+	}
+	fmt.Fprintf(b, "Acknowledge with: vybe msg read %s\n", strings.Join(ack, " "))
+}
+
+func appendNextActions(b *strings.Builder, brief *store.BriefPacket) {
+	if brief == nil || len(brief.NextActions) == 0 {
+		return
+	}
+	b.WriteString("\nNext actions:\n")
+	for i, a := range brief.NextActions {
+		fmt.Fprintf(b, "  %d. %s\n", i+1, a.Reason)
+		if a.Command != "" {
+			fmt.Fprintf(b, "     %s\n", a.Command)
+		}
+	}
+}
+
+func appendInboxNotice(b *strings.Builder, brief *store.BriefPacket) {
+	if brief == nil || brief.UnreadMessages == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\nInbox: %d unread message(s) from other agents. Read with: vybe msg inbox\n", brief.UnreadMessages)
+}
+
+// onboardingPromptLimits keep the first-visit section readable; the full
+// onboarding packet stays available in brief.onboarding.
+const (
+	onboardingPromptItems       = 8
+	onboardingPromptValueRunes  = 200
+	onboardingPromptConventions = 2000
+)
+
+func appendOnboardingContext(b *strings.Builder, brief *store.BriefPacket) {
+	if brief == nil || brief.Onboarding == nil {
+		return
+	}
+	ob := brief.Onboarding
+
+	b.WriteString("\n== ONBOARDING: first visit to this project ==\n")
+	if g := ob.TaskGraph; g != nil && g.Counts != nil {
+		fmt.Fprintf(b, "Task graph: %d pending (%d waiting on dependencies), %d in progress, %d blocked, %d completed; %d dependency edge(s).\n",
+			g.Counts.Pending, g.WaitingOnDeps, g.Counts.InProgress, g.Counts.Blocked, g.Counts.Completed, g.DependencyEdges)
+		appendOnboardingTasks(b, "In progress", g.InProgress)
+		appendOnboardingTasks(b, "Ready to start", g.Ready)
+	}
+	appendOnboardingMemory(b, "Project memory", ob.ProjectMemory)
+	appendOnboardingMemory(b, "Lessons learned", ob.Lessons)
+	if doc := ob.Conventions; doc != nil {
+		content, cut := truncatePromptRunes(doc.Content, onboardingPromptConventions)
+		fmt.Fprintf(b, "Conventions (%s):\n%s\n", doc.Path, strings.TrimRight(content, "\n"))
+		if cut || doc.Truncated {
+			fmt.Fprintf(b, "[conventions truncated; read %s for the rest]\n", doc.Path)
+		}
+	}
+}
+
+func appendOnboardingTasks(b *strings.Builder, label string, tasks []store.PipelineTask) {
+	if len(tasks) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", label)
+	for i, t := range tasks {
+		if i == onboardingPromptItems {
+			fmt.Fprintf(b, "  ... %d more\n", len(tasks)-i)
+			break
+		}
+		fmt.Fprintf(b, "  - %s (%s, priority %d)\n", t.Title, t.ID, t.Priority)
+	}
+}
+
+func appendOnboardingMemory(b *strings.Builder, label string, ms []*models.Memory) {
+	if len(ms) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", label)
+	for i, m := range ms {
+		if i == onboardingPromptItems {
+			fmt.Fprintf(b, "  ... %d more in brief.onboarding\n", len(ms)-i)
+			break
+		}
+		value, _ := truncatePromptRunes(m.Value, onboardingPromptValueRunes)
+		fmt.Fprintf(b, "  - %s: %s\n", m.Key, value)
+	}
+}
+
+// truncatePromptRunes cuts s to at most n runes and reports whether it did.
+func truncatePromptRunes(s string, n int) (string, bool) {
+	if utf8.RuneCountInString(s) <= n {
+		return s, false
+	}
+	return string([]rune(s)[:n]) + "…", true
+}
+
 func appendDecisionProtocol(b *strings.Builder, task *models.Task) {
 	if task == nil {
 		return
@@ -167,6 +357,10 @@ func appendMemoryContext(b *strings.Builder, brief *store.BriefPacket, remaining
 
 	var directives, facts []*models.Memory
 	for _, m := range brief.RelevantMemory {
+		if briefHasDecision(brief, m) {
+			// Rendered in the decisions section instead of as raw JSON.
+			continue
+		}
 		if m.Kind == string(models.MemoryKindDirective) {
 			directives = append(directives, m)
 		} else {
@@ -205,13 +399,60 @@ func appendMemoryContext(b *strings.Builder, brief *store.BriefPacket, remaining
 	}
 }
 
+// briefHasDecision reports whether m is one of the decision records the brief
+// lists under decisions.
+func briefHasDecision(brief *store.BriefPacket, m *models.Memory) bool {
+	if m.ValueType != models.MemoryValueTypeDecision {
+		return false
+	}
+	for _, d := range brief.Decisions {
+		if d.Key == m.Key && d.Scope == m.Scope && d.ScopeID == m.ScopeID {
+			return true
+		}
+	}
+	return false
+}
+
+// appendDecisionsContext renders the brief's recent decision records, newest
+// first, with the alternatives they ruled out.
+func appendDecisionsContext(b *strings.Builder, brief *store.BriefPacket, remainingBudget *int) {
+	if brief == nil || len(brief.Decisions) == 0 {
+		return
+	}
+	lines := make([]string, len(brief.Decisions))
+	for i, d := range brief.Decisions {
+		text, _ := truncatePromptRunes(d.Decision, onboardingPromptValueRunes)
+		line := fmt.Sprintf("  - %s: %s", d.Key, text)
+		if len(d.Alternatives) > 0 {
+			rejected, _ := truncatePromptRunes(strings.Join(d.Alternatives, "; "), onboardingPromptValueRunes)
+			line += " (rejected: " + rejected + ")"
+		}
+		lines[i] = line + "\n"
+	}
+	appendBudgetedSection(b, "\n=== Decisions ===\n", lines, remainingBudget)
+}
+
+// appendLessonsContext renders the brief's top-ranked lessons, best first,
+// each cut to onboardingPromptValueRunes.
+func appendLessonsContext(b *strings.Builder, brief *store.BriefPacket, remainingBudget *int) {
+	if brief == nil || len(brief.Lessons) == 0 {
+		return
+	}
+	lines := make([]string, len(brief.Lessons))
+	for i, l := range brief.Lessons {
+		text, _ := truncatePromptRunes(l.Text, onboardingPromptValueRunes)
+		lines[i] = fmt.Sprintf("  - %s (lesson %d)\n", text, l.ID)
+	}
+	appendBudgetedSection(b, "\nLessons from past sessions:\n", lines, remainingBudget)
+}
+
 func appendEventContext(b *strings.Builder, brief *store.BriefPacket, remainingBudget *int) {
 	if brief == nil || len(brief.RecentEvents) == 0 {
 		return
 	}
 	lines := make([]string, len(brief.RecentEvents))
 	for i, event := range brief.RecentEvents {
-		lines[i] = fmt.Sprintf("  [%s] %s\n", event.Kind, event.Message)
+		lines[i] = fmt.Sprintf("  [%s] %s\n", event.Kind, store.BriefEventLabel(event))
 	}
 	appendBudgetedSection(b, "\nRecent activity:\n", lines, remainingBudget)
 }
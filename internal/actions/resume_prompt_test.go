@@ -4,9 +4,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildPrompt_BudgetLimitsVariableSections(t *testing.T) {
@@ -76,6 +78,62 @@ func TestBuildPrompt_EmptyMemoryExpandsEventBudget(t *testing.T) {
 	assert.Equal(t, 10, eventLines, "with no memories, all short events should fit in budget")
 }
 
+func TestBuildPromptWithProfile_SelectsSections(t *testing.T) {
+	brief := &store.BriefPacket{
+		Task: &models.Task{
+			ID:          "task_789",
+			Title:       "Fix flaky test",
+			Status:      "in_progress",
+			Description: strings.Repeat("d", 500),
+		},
+		Dependencies: []store.DependencyRef{{ID: "task_dep", Title: "Land schema change", Status: "pending"}},
+		RelevantMemory: []*models.Memory{
+			{Key: "m1", Value: "v1"}, {Key: "m2", Value: "v2"}, {Key: "m3", Value: "v3"},
+			{Key: "m4", Value: "v4"}, {Key: "m5", Value: "v5"}, {Key: "m6", Value: "v6"},
+		},
+		RecentEvents: []*models.Event{
+			{Kind: models.EventKindToolFailure, Message: "go test failed"},
+			{Kind: "progress", Message: "refactored fixtures"},
+		},
+		PriorReasoning: []*models.Event{{Kind: "reasoning", Message: "suspect the clock"}},
+		Lessons:        []*store.Lesson{{ID: 1, Text: "l1"}, {ID: 2, Text: "l2"}, {ID: 3, Text: "l3"}},
+	}
+	prompts := []*models.Event{{Kind: "user_prompt", Message: "make the test pass"}}
+
+	standard := buildPrompt("agent1", brief, prompts)
+	assert.Contains(t, standard, strings.Repeat("d", 500))
+	assert.NotContains(t, standard, "Waiting on dependencies")
+	assert.Contains(t, standard, "m6 = v6")
+	assert.Contains(t, standard, "refactored fixtures")
+	assert.Contains(t, standard, "suspect the clock")
+	assert.Contains(t, standard, "make the test pass")
+
+	minimal, err := app.LookupContextProfile(app.ContextMinimal)
+	require.NoError(t, err)
+	prompt := buildPromptWithProfile("agent1", brief, prompts, minimal)
+	assert.NotContains(t, prompt, strings.Repeat("d", 301), "minimal cuts the description")
+	assert.Contains(t, prompt, "m5 = v5")
+	assert.NotContains(t, prompt, "m6 = v6")
+	assert.Contains(t, prompt, "(lesson 2)")
+	assert.NotContains(t, prompt, "(lesson 3)")
+	assert.Contains(t, prompt, "go test failed", "minimal keeps recent failures")
+	assert.NotContains(t, prompt, "refactored fixtures")
+	assert.NotContains(t, prompt, "suspect the clock")
+	assert.NotContains(t, prompt, "make the test pass")
+
+	deep, err := app.LookupContextProfile(app.ContextDeepDebug)
+	require.NoError(t, err)
+	prompt = buildPromptWithProfile("agent1", brief, prompts, deep)
+	assert.Contains(t, prompt, "Waiting on dependencies:\n  - Land schema change (task_dep, pending)")
+	assert.Contains(t, prompt, "refactored fixtures")
+
+	assert.Len(t, brief.RelevantMemory, 6, "shaping the prompt leaves the brief intact")
+	assert.Len(t, brief.Task.Description, 500)
+
+	_, err = app.LookupContextProfile("verbose")
+	require.Error(t, err)
+}
+
 func TestEstimateTokens(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -91,3 +149,35 @@ func TestEstimateTokens(t *testing.T) {
 		assert.Equal(t, tt.expected, estimateTokens(tt.input), "input: %q", tt.input)
 	}
 }
+
+func TestBuildPrompt_DecisionsReplaceRawMemory(t *testing.T) {
+	raw := `{"decision":"Use SQLite","alternatives":["Postgres"]}`
+	brief := &store.BriefPacket{
+		RelevantMemory: []*models.Memory{
+			{Key: "decisions/db", Value: raw, ValueType: models.MemoryValueTypeDecision, Scope: models.MemoryScopeGlobal},
+			{Key: "build/go", Value: "1.26", ValueType: "number", Scope: models.MemoryScopeGlobal},
+		},
+		Decisions: []*models.Decision{{
+			Key: "decisions/db", Scope: models.MemoryScopeGlobal,
+			DecisionRecord: models.DecisionRecord{Decision: "Use SQLite", Alternatives: []string{"Postgres"}},
+		}},
+	}
+
+	prompt := buildPrompt("agent1", brief, nil)
+	assert.Contains(t, prompt, "=== Decisions ===\n  - decisions/db: Use SQLite (rejected: Postgres)")
+	assert.Contains(t, prompt, "build/go = 1.26")
+	assert.NotContains(t, prompt, raw)
+}
+
+func TestBuildPrompt_RolledUpEventsShowFileAndCount(t *testing.T) {
+	brief := &store.BriefPacket{
+		RecentEvents: []*models.Event{
+			{ID: 2, Kind: models.EventKindToolSuccess, Message: "Edit succeeded", Metadata: []byte(`{"file_path":"foo.go"}`), RollupCount: 7},
+			{ID: 1, Kind: models.EventKindProgress, Message: "started"},
+		},
+	}
+
+	prompt := buildPrompt("agent1", brief, nil)
+	assert.Contains(t, prompt, "  [tool_success] Edit succeeded: foo.go ×7\n")
+	assert.Contains(t, prompt, "  [progress] started\n")
+}
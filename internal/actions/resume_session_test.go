@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestResume_SessionScopedFocus(t *testing.T) {
+	db, cleanup := setupTestDBWithCleanup(t)
+	defer cleanup()
+
+	first, err := store.CreateTask(context.Background(), db, "First", "", "", 10)
+	require.NoError(t, err)
+	second, err := store.CreateTask(context.Background(), db, "Second", "", "", 5)
+	require.NoError(t, err)
+
+	a, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "resume-a1", ResumeOptions{SessionID: "sess-a"})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, a.FocusTaskID)
+
+	// A second session of the same agent skips the task session A holds.
+	b, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "resume-b1", ResumeOptions{SessionID: "sess-b"})
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, b.FocusTaskID)
+
+	// Each session keeps its own focus on the next resume, even though the
+	// agent-wide pointer now names session B's task.
+	a, err = ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "resume-a2", ResumeOptions{SessionID: "sess-a"})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, a.FocusTaskID, "rule3 keeps the session's pending focus")
+
+	brief, err := BriefWithOptions(context.Background(), db, "agent1", BriefOptions{SessionID: "sess-b"})
+	require.NoError(t, err)
+	require.NotNil(t, brief.Task)
+	assert.Equal(t, second.ID, brief.Task.ID)
+
+	sf, found, err := store.GetSessionFocus(context.Background(), db, "agent1", "sess-b")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, second.ID, sf.FocusTaskID)
+}
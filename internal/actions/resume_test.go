@@ -14,7 +14,7 @@ func TestResume_NewAgent(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	response, err := ResumeWithOptionsIdempotent(db, "new-agent", "req-new-agent-1", ResumeOptions{EventLimit: 1000})
+	response, err := ResumeWithOptionsIdempotent(context.Background(), db, "new-agent", "req-new-agent-1", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Resume failed: %v", err)
 	}
@@ -45,7 +45,7 @@ func TestResume_WithEvents(t *testing.T) {
 	defer cleanup()
 
 	// Create agent state
-	_, err := store.LoadOrCreateAgentState(db, "agent1")
+	_, err := store.LoadOrCreateAgentState(context.Background(), db, "agent1")
 	if err != nil {
 		t.Fatalf("Failed to create agent state: %v", err)
 	}
@@ -65,7 +65,7 @@ func TestResume_WithEvents(t *testing.T) {
 	}
 
 	// Resume
-	response, err := ResumeWithOptionsIdempotent(db, "agent1", "req-with-events-1", ResumeOptions{EventLimit: 1000})
+	response, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "req-with-events-1", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Resume failed: %v", err)
 	}
@@ -88,13 +88,13 @@ func TestResume_WithPendingTask(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	task, err := store.CreateTask(db, "Test Task", "Description", "", 0)
+	task, err := store.CreateTask(context.Background(), db, "Test Task", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// Resume
-	response, err := ResumeWithOptionsIdempotent(db, "agent1", "req-pending-task-1", ResumeOptions{EventLimit: 1000})
+	response, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "req-pending-task-1", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Resume failed: %v", err)
 	}
@@ -129,7 +129,7 @@ func TestResume_CursorAdvancement(t *testing.T) {
 	}
 
 	// First resume
-	response1, err := ResumeWithOptionsIdempotent(db, "agent1", "req-cursor-adv-1", ResumeOptions{EventLimit: 1000})
+	response1, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "req-cursor-adv-1", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Resume failed: %v", err)
 	}
@@ -151,7 +151,7 @@ func TestResume_CursorAdvancement(t *testing.T) {
 	}
 
 	// Second resume — distinct request ID so it computes fresh state
-	response2, err := ResumeWithOptionsIdempotent(db, "agent1", "req-cursor-adv-2", ResumeOptions{EventLimit: 1000})
+	response2, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "req-cursor-adv-2", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Resume failed: %v", err)
 	}
@@ -174,13 +174,13 @@ func TestResume_FocusTaskPersistence(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	task, err := store.CreateTask(db, "Test Task", "Description", "", 0)
+	task, err := store.CreateTask(context.Background(), db, "Test Task", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// First resume (should select the task)
-	response1, err := ResumeWithOptionsIdempotent(db, "agent1", "req-focus-persist-1", ResumeOptions{EventLimit: 1000})
+	response1, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "req-focus-persist-1", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Resume failed: %v", err)
 	}
@@ -190,13 +190,13 @@ func TestResume_FocusTaskPersistence(t *testing.T) {
 	}
 
 	// Update task to in_progress
-	err = store.UpdateTaskStatus(db, task.ID, "in_progress", task.Version)
+	err = store.UpdateTaskStatus(context.Background(), db, task.ID, "in_progress", task.Version)
 	if err != nil {
 		t.Fatalf("Failed to update task status: %v", err)
 	}
 
 	// Second resume (should keep focus on in_progress task) — distinct request ID
-	response2, err := ResumeWithOptionsIdempotent(db, "agent1", "req-focus-persist-2", ResumeOptions{EventLimit: 1000})
+	response2, err := ResumeWithOptionsIdempotent(context.Background(), db, "agent1", "req-focus-persist-2", ResumeOptions{EventLimit: 1000})
 	if err != nil {
 		t.Fatalf("Resume failed: %v", err)
 	}
@@ -210,7 +210,7 @@ func TestResume_RequiresAgentName(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	_, err := ResumeWithOptionsIdempotent(db, "", "req-empty-agent", ResumeOptions{EventLimit: 1000})
+	_, err := ResumeWithOptionsIdempotent(context.Background(), db, "", "req-empty-agent", ResumeOptions{EventLimit: 1000})
 	if err == nil {
 		t.Error("Expected error for empty agent name")
 	}
@@ -221,23 +221,23 @@ func TestBrief_ExistingFocus(t *testing.T) {
 	defer cleanup()
 
 	// Create task and agent with focus
-	task, err := store.CreateTask(db, "Test Task", "Description", "", 0)
+	task, err := store.CreateTask(context.Background(), db, "Test Task", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
-	state, err := store.LoadOrCreateAgentState(db, "agent1")
+	state, err := store.LoadOrCreateAgentState(context.Background(), db, "agent1")
 	if err != nil {
 		t.Fatalf("Failed to create agent state: %v", err)
 	}
 
-	err = store.UpdateAgentStateAtomic(db, state.AgentName, 0, task.ID)
+	err = store.UpdateAgentStateAtomic(context.Background(), db, state.AgentName, 0, task.ID)
 	if err != nil {
 		t.Fatalf("Failed to set focus: %v", err)
 	}
 
 	// Get brief
-	brief, err := Brief(db, "agent1")
+	brief, err := Brief(context.Background(), db, "agent1")
 	if err != nil {
 		t.Fatalf("Brief failed: %v", err)
 	}
@@ -256,13 +256,13 @@ func TestBrief_NoFocus(t *testing.T) {
 	defer cleanup()
 
 	// Create agent with no focus
-	_, err := store.LoadOrCreateAgentState(db, "agent1")
+	_, err := store.LoadOrCreateAgentState(context.Background(), db, "agent1")
 	if err != nil {
 		t.Fatalf("Failed to create agent state: %v", err)
 	}
 
 	// Get brief
-	brief, err := Brief(db, "agent1")
+	brief, err := Brief(context.Background(), db, "agent1")
 	if err != nil {
 		t.Fatalf("Brief failed: %v", err)
 	}
@@ -277,7 +277,7 @@ func TestBrief_RequiresAgentName(t *testing.T) {
 	defer cleanup()
 
 	// Brief with empty agent name
-	_, err := Brief(db, "")
+	_, err := Brief(context.Background(), db, "")
 	if err == nil {
 		t.Error("Expected error for empty agent name")
 	}
@@ -288,7 +288,7 @@ func TestBrief_DoesNotAdvanceCursor(t *testing.T) {
 	defer cleanup()
 
 	// Create agent and events
-	_, err := store.LoadOrCreateAgentState(db, "agent1")
+	_, err := store.LoadOrCreateAgentState(context.Background(), db, "agent1")
 	if err != nil {
 		t.Fatalf("Failed to create agent state: %v", err)
 	}
@@ -301,13 +301,13 @@ func TestBrief_DoesNotAdvanceCursor(t *testing.T) {
 	}
 
 	// Get brief (should not advance cursor)
-	_, err = Brief(db, "agent1")
+	_, err = Brief(context.Background(), db, "agent1")
 	if err != nil {
 		t.Fatalf("Brief failed: %v", err)
 	}
 
 	// Check cursor is still at 0
-	state, err := store.LoadOrCreateAgentState(db, "agent1")
+	state, err := store.LoadOrCreateAgentState(context.Background(), db, "agent1")
 	if err != nil {
 		t.Fatalf("Failed to get agent state: %v", err)
 	}
@@ -321,7 +321,7 @@ func TestBuildPrompt_IncludesPriorReasoning(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	task, err := store.CreateTask(db, "Test Task", "Desc", "", 0)
+	task, err := store.CreateTask(context.Background(), db, "Test Task", "Desc", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
@@ -342,7 +342,7 @@ func TestBuildPrompt_IncludesPriorReasoning(t *testing.T) {
 	}
 
 	// Fetch reasoning events to populate brief
-	reasoning, err := store.FetchPriorReasoning(db, "", 10)
+	reasoning, err := store.FetchPriorReasoning(context.Background(), db, "", 10)
 	if err != nil {
 		t.Fatalf("Failed to fetch reasoning: %v", err)
 	}
@@ -368,22 +368,22 @@ func TestResumeWithProjectScope_FirstUseAgent(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	project, err := store.CreateProject(db, "Scoped", "")
+	project, err := store.CreateProject(context.Background(), db, "Scoped", "")
 	if err != nil {
 		t.Fatalf("Failed to create project: %v", err)
 	}
 
-	projectTask, err := store.CreateTask(db, "Project Task", "", project.ID, 0)
+	projectTask, err := store.CreateTask(context.Background(), db, "Project Task", "", project.ID, 0)
 	if err != nil {
 		t.Fatalf("Failed to create project task: %v", err)
 	}
 
-	_, err = store.CreateTask(db, "Global Task", "", "", 0)
+	_, err = store.CreateTask(context.Background(), db, "Global Task", "", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create global task: %v", err)
 	}
 
-	response, err := ResumeWithOptionsIdempotent(db, "new-project-agent", "req_proj_resume", ResumeOptions{
+	response, err := ResumeWithOptionsIdempotent(context.Background(), db, "new-project-agent", "req_proj_resume", ResumeOptions{
 		EventLimit: 100,
 		ProjectDir: project.ID,
 	})
@@ -399,7 +399,7 @@ func TestResumeWithProjectScope_FirstUseAgent(t *testing.T) {
 		t.Fatalf("Expected focus task %s, got %s", projectTask.ID, response.FocusTaskID)
 	}
 
-	state, err := store.LoadOrCreateAgentState(db, "new-project-agent")
+	state, err := store.LoadOrCreateAgentState(context.Background(), db, "new-project-agent")
 	if err != nil {
 		t.Fatalf("Failed to get agent state: %v", err)
 	}
@@ -408,3 +408,142 @@ func TestResumeWithProjectScope_FirstUseAgent(t *testing.T) {
 		t.Fatalf("Expected persisted focus project %s, got %s", project.ID, state.FocusProjectID)
 	}
 }
+
+func TestBriefWithOptions_MaxTokensReportsElision(t *testing.T) {
+	db, cleanup := setupTestDBWithCleanup(t)
+	defer cleanup()
+
+	task, err := store.CreateTask(context.Background(), db, "Budgeted task", "", "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if _, err := store.LoadOrCreateAgentState(context.Background(), db, "agent1"); err != nil {
+		t.Fatalf("Failed to create agent state: %v", err)
+	}
+	if err := store.UpdateAgentStateAtomic(context.Background(), db, "agent1", 0, task.ID); err != nil {
+		t.Fatalf("Failed to set focus: %v", err)
+	}
+
+	for i := range 5 {
+		reqID := "req-budget-" + strings.Repeat("x", i+1)
+		if _, err := store.AppendEventIdempotent(context.Background(), db, "agent1", reqID, "progress", task.ID, strings.Repeat("progress detail ", 20)); err != nil {
+			t.Fatalf("Failed to append event: %v", err)
+		}
+	}
+
+	full, err := Brief(context.Background(), db, "agent1")
+	if err != nil {
+		t.Fatalf("Brief failed: %v", err)
+	}
+	if full.Budget != nil {
+		t.Errorf("Expected no budget report without --max-tokens")
+	}
+
+	shaped, err := BriefWithOptions(context.Background(), db, "agent1", BriefOptions{MaxTokens: 200})
+	if err != nil {
+		t.Fatalf("BriefWithOptions failed: %v", err)
+	}
+	if shaped.Budget == nil {
+		t.Fatalf("Expected budget report")
+	}
+	if len(shaped.RecentEvents) >= len(full.RecentEvents) {
+		t.Fatalf("Expected events to be trimmed: full=%d shaped=%d", len(full.RecentEvents), len(shaped.RecentEvents))
+	}
+	if got, want := shaped.Budget.Elided["recent_events"], len(full.RecentEvents)-len(shaped.RecentEvents); got != want {
+		t.Errorf("Expected %d elided events, got %d", want, got)
+	}
+}
+
+func TestBriefWithOptions_TaskIDBriefsUnfocusedTask(t *testing.T) {
+	db, cleanup := setupTestDBWithCleanup(t)
+	defer cleanup()
+
+	focused, err := store.CreateTask(context.Background(), db, "Focused task", "", "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	other, err := store.CreateTask(context.Background(), db, "Other task", "Ship the markdown brief", "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if _, err := store.LoadOrCreateAgentState(context.Background(), db, "agent1"); err != nil {
+		t.Fatalf("Failed to create agent state: %v", err)
+	}
+	if err := store.UpdateAgentStateAtomic(context.Background(), db, "agent1", 0, focused.ID); err != nil {
+		t.Fatalf("Failed to set focus: %v", err)
+	}
+	if _, _, err := store.AddTaskCriterionIdempotent(context.Background(), db, "agent1", "req-crit", other.ID, "renders criteria"); err != nil {
+		t.Fatalf("Failed to add criterion: %v", err)
+	}
+
+	brief, err := BriefWithOptions(context.Background(), db, "agent1", BriefOptions{TaskID: other.ID})
+	if err != nil {
+		t.Fatalf("BriefWithOptions failed: %v", err)
+	}
+	if brief.Task == nil || brief.Task.ID != other.ID {
+		t.Fatalf("Expected brief for %s, got %+v", other.ID, brief.Task)
+	}
+
+	md := RenderBriefMarkdown("agent1", brief)
+	for _, want := range []string{"# Brief: Other task", "Ship the markdown brief", "## Acceptance criteria", "- [ ] renders criteria"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected markdown to contain %q:\n%s", want, md)
+		}
+	}
+
+	state, err := store.LoadOrCreateAgentState(context.Background(), db, "agent1")
+	if err != nil {
+		t.Fatalf("Failed to load agent state: %v", err)
+	}
+	if state.FocusTaskID != focused.ID {
+		t.Errorf("Expected focus to stay on %s, got %s", focused.ID, state.FocusTaskID)
+	}
+
+	if _, err := BriefWithOptions(context.Background(), db, "agent1", BriefOptions{TaskID: "task_missing"}); err == nil {
+		t.Error("Expected an error for an unknown task")
+	}
+}
+
+func TestBriefDiff_ReportsFocusAndMemoryDifferences(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	taskA, _, err := TaskCreateIdempotent(context.Background(), db, "author", "req_diff_task_a", "Author task", "", "", 0)
+	if err != nil {
+		t.Fatalf("TaskCreateIdempotent failed: %v", err)
+	}
+	taskB, _, err := TaskCreateIdempotent(context.Background(), db, "author", "req_diff_task_b", "Review task", "", "", 0)
+	if err != nil {
+		t.Fatalf("TaskCreateIdempotent failed: %v", err)
+	}
+	for agent, task := range map[string]string{"author": taskA.ID, "reviewer": taskB.ID} {
+		if _, err := store.LoadOrCreateAgentState(context.Background(), db, agent); err != nil {
+			t.Fatalf("LoadOrCreateAgentState failed: %v", err)
+		}
+		if err := store.UpdateAgentStateAtomic(context.Background(), db, agent, 0, task); err != nil {
+			t.Fatalf("UpdateAgentStateAtomic failed: %v", err)
+		}
+	}
+	if _, err := MemorySetIdempotent(context.Background(), db, "author", "req_diff_mem", "hint", "only for author task", "", "task", taskA.ID, nil, false, "", nil, ""); err != nil {
+		t.Fatalf("MemorySetIdempotent failed: %v", err)
+	}
+
+	diff, err := BriefDiff(context.Background(), db, "author", "reviewer", BriefOptions{})
+	if err != nil {
+		t.Fatalf("BriefDiff failed: %v", err)
+	}
+	if diff.Identical || diff.FocusTask.Same {
+		t.Fatalf("expected differing focus, got %+v", diff.FocusTask)
+	}
+	if len(diff.Memory.OnlyA) != 1 || diff.Memory.OnlyA[0] != "task:"+taskA.ID+":hint" {
+		t.Fatalf("expected author-only task memory, got %+v", diff.Memory)
+	}
+
+	self, err := BriefDiff(context.Background(), db, "author", "author", BriefOptions{})
+	if err != nil {
+		t.Fatalf("BriefDiff failed: %v", err)
+	}
+	if !self.Identical {
+		t.Fatalf("expected identical briefs for the same agent, got %+v", self)
+	}
+}
@@ -0,0 +1,200 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// Retrospective modes, recorded on the session_retrospective event.
+const (
+	RetrospectiveRules = "rules"
+	RetrospectiveLLM   = "llm"
+)
+
+// DefaultRetrospectiveMaxLessons caps the lessons one retrospective stores.
+const DefaultRetrospectiveMaxLessons = 5
+
+const (
+	// ruleLessonConfidence starts rule-extracted lessons below hand-written
+	// ones (store.DefaultLessonConfidence): they are mechanical observations.
+	ruleLessonConfidence = 0.3
+	// retrospectiveTimelineRunes caps the session timeline sent to an LLM.
+	retrospectiveTimelineRunes = 30000
+	// retrospectiveTextRunes caps quoted event text in summaries and lessons.
+	retrospectiveTextRunes = 200
+)
+
+// RetrospectiveOptions configures SessionRetrospectiveIdempotent.
+type RetrospectiveOptions struct {
+	SessionID string
+	// ProjectID overrides the session's project for the summary and lessons.
+	ProjectID string
+	// Provider runs the LLM retrospective; nil runs SessionRetrospectiveRuleOnly.
+	Provider   LLMProvider
+	MaxLessons int
+}
+
+// SessionRetrospectiveIdempotent reviews a session and stores a summary memory
+// and extracted lessons (see store.RecordRetrospectiveTx). The LLM call, when
+// there is one, happens before the write transaction.
+func SessionRetrospectiveIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, opts RetrospectiveOptions) (*store.RetrospectiveResult, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if opts.MaxLessons <= 0 {
+		opts.MaxLessons = DefaultRetrospectiveMaxLessons
+	}
+	replay, err := ReplaySession(ctx, db, opts.SessionID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	record := store.RetrospectiveRecord{SessionID: replay.SessionID, ProjectID: opts.ProjectID, Mode: RetrospectiveRules}
+	if record.ProjectID == "" && replay.Session != nil {
+		record.ProjectID = replay.Session.ProjectID
+	}
+	if opts.Provider == nil {
+		record.Summary, record.Lessons = SessionRetrospectiveRuleOnly(replay)
+	} else {
+		record.Mode, record.Provider = RetrospectiveLLM, opts.Provider.Name()
+		record.Summary, record.Lessons, err = SessionRetrospectiveWithLLM(ctx, opts.Provider, replay, opts.MaxLessons)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(record.Lessons) > opts.MaxLessons {
+		record.Lessons = record.Lessons[:opts.MaxLessons]
+	}
+	return store.RecordRetrospectiveIdempotent(ctx, db, agentName, requestID, record)
+}
+
+// SessionRetrospectiveRuleOnly summarizes a session from its step counts and
+// completions, and turns repeated tool failures and task failures into
+// low-confidence lessons. It needs no external calls.
+func SessionRetrospectiveRuleOnly(r *SessionReplay) (string, []store.LessonDraft) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session %s (%s): %d prompts, %d tool calls, %d progress updates, %d completions.",
+		r.SessionID, r.EndedAt.Sub(r.StartedAt).Round(time.Second), r.Counts[ReplayPrompt], r.Counts[ReplayTool],
+		r.Counts[ReplayProgress], r.Counts[ReplayCompletion])
+	if r.Session != nil && r.Session.Outcome != "" {
+		fmt.Fprintf(&b, " Outcome: %s.", r.Session.Outcome)
+	}
+
+	var completed, toolOrder []string
+	seenTask := map[string]bool{}
+	toolFailures := map[string][]ReplayEntry{}
+	var lastProgress string
+	var lessons []store.LessonDraft
+	for _, e := range r.Entries {
+		switch {
+		case e.Category == ReplayCompletion && e.TaskID != "" && !seenTask[e.TaskID]:
+			seenTask[e.TaskID] = true
+			completed = append(completed, e.TaskID)
+		case e.Kind == models.EventKindToolFailure:
+			tool := e.Tool
+			if tool == "" {
+				tool = "A tool"
+			}
+			if _, ok := toolFailures[tool]; !ok {
+				toolOrder = append(toolOrder, tool)
+			}
+			toolFailures[tool] = append(toolFailures[tool], e)
+		case e.Kind == models.EventKindTaskFailed:
+			lessons = append(lessons, store.LessonDraft{
+				Text:       "Task " + e.TaskID + " failed: " + retrospectiveText(e.Text),
+				Confidence: ruleLessonConfidence,
+			})
+		case e.Category == ReplayProgress:
+			lastProgress = e.Text
+		}
+	}
+	if len(completed) > 0 {
+		fmt.Fprintf(&b, " Completed: %s.", strings.Join(completed, ", "))
+	}
+	if lastProgress != "" {
+		fmt.Fprintf(&b, " Last progress: %s", retrospectiveText(lastProgress))
+	}
+
+	for _, tool := range toolOrder {
+		failures := toolFailures[tool]
+		if len(failures) < 2 {
+			continue
+		}
+		lessons = append(lessons, store.LessonDraft{
+			Text: fmt.Sprintf("%s failed %d times in one session; check why before retrying. Last error: %s",
+				tool, len(failures), retrospectiveText(failures[len(failures)-1].Text)),
+			Confidence: ruleLessonConfidence,
+		})
+	}
+	return b.String(), lessons
+}
+
+// SessionRetrospectiveWithLLM asks provider for a session summary and up to
+// maxLessons reusable lessons, given the session timeline.
+func SessionRetrospectiveWithLLM(ctx context.Context, provider LLMProvider, r *SessionReplay, maxLessons int) (string, []store.LessonDraft, error) {
+	timeline := RenderSessionReplayMarkdown(r)
+	if runes := []rune(timeline); len(runes) > retrospectiveTimelineRunes {
+		timeline = "(earlier steps omitted)\n" + string(runes[len(runes)-retrospectiveTimelineRunes:])
+	}
+	out, err := provider.Complete(ctx, buildRetrospectivePrompt(timeline, maxLessons))
+	if err != nil {
+		return "", nil, fmt.Errorf("%s retrospective failed: %w", provider.Name(), err)
+	}
+	return parseRetrospectiveResponse(out)
+}
+
+func buildRetrospectivePrompt(timeline string, maxLessons int) string {
+	return fmt.Sprintf(`You are reviewing a finished coding-agent session recorded by vybe, a task tracker.
+Reply with ONLY a JSON object, no prose and no code fences:
+{"summary": "2-4 sentences: what was attempted, what got done, what is left",
+ "lessons": [{"text": "one specific, reusable lesson for future sessions in this project", "confidence": 0.0-1.0}]}
+Give at most %d lessons. Leave out anything that only mattered in this session; an empty list is fine.
+
+%s`, maxLessons, timeline)
+}
+
+// parseRetrospectiveResponse extracts the JSON object from an LLM reply,
+// tolerating surrounding prose or code fences, and normalizes its lessons.
+func parseRetrospectiveResponse(out string) (string, []store.LessonDraft, error) {
+	start, end := strings.Index(out, "{"), strings.LastIndex(out, "}")
+	if start < 0 || end < start {
+		return "", nil, errors.New("LLM reply contained no JSON object")
+	}
+	var parsed struct {
+		Summary string              `json:"summary"`
+		Lessons []store.LessonDraft `json:"lessons"`
+	}
+	if err := json.Unmarshal([]byte(out[start:end+1]), &parsed); err != nil {
+		return "", nil, fmt.Errorf("failed to parse LLM reply: %w", err)
+	}
+	summary := strings.TrimSpace(parsed.Summary)
+	if summary == "" {
+		return "", nil, errors.New("LLM reply had no summary")
+	}
+	lessons := make([]store.LessonDraft, 0, len(parsed.Lessons))
+	for _, l := range parsed.Lessons {
+		l.Text = strings.TrimSpace(l.Text)
+		if l.Text == "" {
+			continue
+		}
+		if l.Confidence <= 0 || l.Confidence > 1 {
+			l.Confidence = store.DefaultLessonConfidence
+		}
+		lessons = append(lessons, l)
+	}
+	return summary, lessons, nil
+}
+
+func retrospectiveText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s, _ = truncatePromptRunes(s, retrospectiveTextRunes)
+	return s
+}
@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+type fakeLLMProvider struct {
+	reply  string
+	prompt string
+}
+
+func (p *fakeLLMProvider) Name() string { return "fake" }
+
+func (p *fakeLLMProvider) Complete(_ context.Context, prompt string) (string, error) {
+	p.prompt = prompt
+	return p.reply, nil
+}
+
+func TestSessionRetrospective_RulesAndLLM(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insert := func(kind, msg, meta string) {
+		t.Helper()
+		require.NoError(t, store.Transact(context.Background(), db, func(tx *sql.Tx) error {
+			_, err := store.InsertEventTx(tx, kind, "agent1", "", msg, meta)
+			return err
+		}))
+	}
+	insert(models.EventKindUserPrompt, "fix the flaky test", `{"session_id":"sess-1"}`)
+	insert(models.EventKindToolFailure, "go test: connection refused", `{"session_id":"sess-1","tool_name":"Bash"}`)
+	insert(models.EventKindToolFailure, "go test: connection refused again", `{"session_id":"sess-1","tool_name":"Bash"}`)
+	insert(models.EventKindProgress, "started postgres, tests pass", `{"session_id":"sess-1"}`)
+
+	rules, err := SessionRetrospectiveIdempotent(context.Background(), db, "agent1", "retro_rules", RetrospectiveOptions{SessionID: "sess-1", ProjectID: "p1"})
+	require.NoError(t, err)
+	assert.Equal(t, RetrospectiveRules, rules.Mode)
+	assert.Contains(t, rules.Summary, "2 tool calls")
+	assert.Contains(t, rules.Summary, "started postgres")
+	require.Len(t, rules.Lessons, 1)
+	assert.Contains(t, rules.Lessons[0].Text, "Bash failed 2 times")
+	assert.Equal(t, "retrospective:sess-1", rules.Lessons[0].Source)
+
+	provider := &fakeLLMProvider{reply: "Here you go:\n```json\n" +
+		`{"summary": "Fixed the flaky test by starting postgres.", "lessons": [` +
+		`{"text": "Start postgres before go test", "confidence": 0.8}, {"text": " "}, {"text": "Second", "confidence": 7}]}` +
+		"\n```"}
+	llm, err := SessionRetrospectiveIdempotent(context.Background(), db, "agent1", "retro_llm", RetrospectiveOptions{
+		SessionID: "sess-1", ProjectID: "p1", Provider: provider, MaxLessons: 1,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, provider.prompt, "connection refused again", "the prompt carries the session timeline")
+	assert.Equal(t, RetrospectiveLLM, llm.Mode)
+	assert.Equal(t, "fake", llm.Provider)
+	require.Len(t, llm.Lessons, 1, "capped by MaxLessons")
+	assert.Equal(t, "Start postgres before go test", llm.Lessons[0].Text)
+	assert.InDelta(t, 0.8, llm.Lessons[0].Confidence, 0.001)
+
+	mem, err := store.GetMemory(context.Background(), db, store.RetrospectiveSummaryKey("sess-1"), "project", "p1")
+	require.NoError(t, err)
+	require.NotNil(t, mem)
+	assert.Equal(t, "Fixed the flaky test by starting postgres.", mem.Value)
+
+	_, _, err = parseRetrospectiveResponse("no json here")
+	require.Error(t, err)
+}
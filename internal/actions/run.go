@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -19,7 +20,7 @@ type RunResult struct {
 }
 
 // PersistRunResultIdempotent emits a run_completed event with run metrics as metadata.
-func PersistRunResultIdempotent(db *sql.DB, agentName, requestID, projectID string, result RunResult) (int64, error) {
+func PersistRunResultIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, result RunResult) (int64, error) {
 	if agentName == "" {
 		return 0, errors.New("agent name is required")
 	}
@@ -35,7 +36,7 @@ func PersistRunResultIdempotent(db *sql.DB, agentName, requestID, projectID stri
 	msg := fmt.Sprintf("Run completed: %d/%d tasks done, %d failed, %.0fs",
 		result.Completed, result.Total, result.Failed, result.Duration)
 
-	return store.AppendEventWithProjectAndMetadataIdempotent(
+	return store.AppendEventWithProjectAndMetadataIdempotent(ctx,
 		db, agentName, requestID,
 		models.EventKindRunCompleted, projectID, "",
 		msg, string(metaBytes),
@@ -0,0 +1,364 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a user-defined sequence of vybe commands with assertions on
+// their output, loaded from YAML by LoadScenario.
+type Scenario struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Env         map[string]string `yaml:"env"`
+	Steps       []ScenarioStep    `yaml:"steps"`
+}
+
+// ScenarioStep runs one vybe command line. Run is split like a shell would
+// (quotes group words; a leading "vybe" is dropped); Args gives the words
+// directly. Save copies JSON fields of the response into variables for later
+// steps (${name}).
+type ScenarioStep struct {
+	Name   string            `yaml:"name"`
+	Run    string            `yaml:"run"`
+	Args   []string          `yaml:"args"`
+	Stdin  string            `yaml:"stdin"`
+	Expect ScenarioExpect    `yaml:"expect"`
+	Save   map[string]string `yaml:"save"`
+}
+
+// ScenarioExpect holds a step's assertions. Without ExitCode a step must exit
+// 0, or non-zero when Success is false. JSON maps dotted paths into the
+// response (data.task.status, data.tasks.0.id) to expected values.
+type ScenarioExpect struct {
+	ExitCode *int           `yaml:"exit_code"`
+	Success  *bool          `yaml:"success"`
+	JSON     map[string]any `yaml:"json"`
+	Exists   []string       `yaml:"exists"`
+	Contains []string       `yaml:"contains"`
+}
+
+// ScenarioExec runs vybe with args, stdin, and env, and reports its output and
+// exit code. err is only for failures to run the process at all.
+type ScenarioExec func(args []string, stdin string, env []string) (stdout, stderr string, exitCode int, err error)
+
+// ScenarioStepResult reports one step. Stdout and Stderr are kept only for
+// failed steps.
+type ScenarioStepResult struct {
+	Index      int      `json:"index"`
+	Name       string   `json:"name,omitempty"`
+	Args       []string `json:"args"`
+	Passed     bool     `json:"passed"`
+	Skipped    bool     `json:"skipped,omitempty"`
+	ExitCode   int      `json:"exit_code"`
+	DurationMS int64    `json:"duration_ms"`
+	Failures   []string `json:"failures,omitempty"`
+	Stdout     string   `json:"stdout,omitempty"`
+	Stderr     string   `json:"stderr,omitempty"`
+}
+
+// ScenarioResult is the outcome of RunScenario.
+type ScenarioResult struct {
+	Name    string               `json:"name"`
+	Passed  bool                 `json:"passed"`
+	Total   int                  `json:"total"`
+	Failed  int                  `json:"failed"`
+	Skipped int                  `json:"skipped"`
+	Steps   []ScenarioStepResult `json:"steps"`
+}
+
+// LoadScenario reads and validates a scenario file. Unknown keys are errors,
+// so a typo in an assertion cannot silently pass.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // user-supplied scenario path is the point of the flag
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var s Scenario
+	if err := dec.Decode(&s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	if len(s.Steps) == 0 {
+		return nil, fmt.Errorf("scenario %s has no steps", path)
+	}
+	for i, st := range s.Steps {
+		if (st.Run == "") == (len(st.Args) == 0) {
+			return nil, fmt.Errorf("scenario step %d: set exactly one of run or args", i+1)
+		}
+		if st.Run != "" {
+			if _, err := splitCommandLine(st.Run); err != nil {
+				return nil, fmt.Errorf("scenario step %d: %w", i+1, err)
+			}
+		}
+	}
+	return &s, nil
+}
+
+// RunScenario runs the steps of s in order through exec. vars seeds the
+// ${name} variables (scenario env and saved values are added as it runs).
+// Unless keepGoing is set, the steps after the first failure are skipped.
+func RunScenario(s *Scenario, exec ScenarioExec, env []string, vars map[string]string, keepGoing bool) *ScenarioResult {
+	res := &ScenarioResult{Name: s.Name, Passed: true, Total: len(s.Steps), Steps: []ScenarioStepResult{}}
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	for k, v := range s.Env {
+		vars[k] = v
+	}
+	for k, v := range s.Env {
+		env = append(env, k+"="+expandScenarioVars(v, vars, nil))
+	}
+
+	for i, st := range s.Steps {
+		sr := ScenarioStepResult{Index: i + 1, Name: st.Name}
+		if !res.Passed && !keepGoing {
+			sr.Skipped = true
+			res.Skipped++
+			res.Steps = append(res.Steps, sr)
+			continue
+		}
+		runScenarioStep(st, exec, env, vars, &sr)
+		if !sr.Passed {
+			res.Passed = false
+			res.Failed++
+		}
+		res.Steps = append(res.Steps, sr)
+	}
+	return res
+}
+
+func runScenarioStep(st ScenarioStep, exec ScenarioExec, env []string, vars map[string]string, sr *ScenarioStepResult) {
+	var missing []string
+	words := st.Args
+	if st.Run != "" {
+		words, _ = splitCommandLine(st.Run) // validated by LoadScenario
+	}
+	if len(words) > 0 && words[0] == "vybe" {
+		words = words[1:]
+	}
+	for _, w := range words {
+		sr.Args = append(sr.Args, expandScenarioVars(w, vars, &missing))
+	}
+	stdin := expandScenarioVars(st.Stdin, vars, &missing)
+	if len(missing) > 0 {
+		sr.Failures = append(sr.Failures, "undefined variable(s): "+strings.Join(missing, ", "))
+		return
+	}
+
+	start := time.Now()
+	stdout, stderr, code, err := exec(sr.Args, stdin, env)
+	sr.DurationMS = time.Since(start).Milliseconds()
+	sr.ExitCode = code
+	if err != nil {
+		sr.Failures = append(sr.Failures, "failed to run: "+err.Error())
+		sr.Stderr = stderr
+		return
+	}
+
+	sr.Failures = checkScenarioExpect(st.Expect, stdout, code)
+	if len(sr.Failures) == 0 {
+		resp, _ := lastJSONObject(stdout)
+		for name, path := range st.Save {
+			v, ok := jsonPathValue(resp, path)
+			if !ok {
+				sr.Failures = append(sr.Failures, fmt.Sprintf("save %s: %s not found in response", name, path))
+				continue
+			}
+			vars[name] = scenarioValueString(v)
+		}
+	}
+	sr.Passed = len(sr.Failures) == 0
+	if !sr.Passed {
+		sr.Stdout, sr.Stderr = stdout, stderr
+	}
+}
+
+func checkScenarioExpect(exp ScenarioExpect, stdout string, code int) []string {
+	var failures []string
+	switch {
+	case exp.ExitCode != nil:
+		if code != *exp.ExitCode {
+			failures = append(failures, fmt.Sprintf("exit code %d, want %d", code, *exp.ExitCode))
+		}
+	case exp.Success != nil && !*exp.Success:
+		if code == 0 {
+			failures = append(failures, "exit code 0, want a failure")
+		}
+	default:
+		if code != 0 {
+			failures = append(failures, fmt.Sprintf("exit code %d, want 0", code))
+		}
+	}
+	for _, s := range exp.Contains {
+		if !strings.Contains(stdout, s) {
+			failures = append(failures, fmt.Sprintf("output does not contain %q", s))
+		}
+	}
+
+	if exp.Success == nil && len(exp.JSON) == 0 && len(exp.Exists) == 0 {
+		return failures
+	}
+	resp, ok := lastJSONObject(stdout)
+	if !ok {
+		return append(failures, "output is not a JSON object")
+	}
+	if exp.Success != nil {
+		if got, _ := resp["success"].(bool); got != *exp.Success {
+			failures = append(failures, fmt.Sprintf("success %v, want %v", got, *exp.Success))
+		}
+	}
+	for _, path := range exp.Exists {
+		if _, ok := jsonPathValue(resp, path); !ok {
+			failures = append(failures, path+" not found")
+		}
+	}
+	paths := make([]string, 0, len(exp.JSON))
+	for path := range exp.JSON {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		want := exp.JSON[path]
+		got, ok := jsonPathValue(resp, path)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s not found, want %s", path, scenarioJSON(want)))
+			continue
+		}
+		if scenarioJSON(got) != scenarioJSON(want) {
+			failures = append(failures, fmt.Sprintf("%s = %s, want %s", path, scenarioJSON(got), scenarioJSON(want)))
+		}
+	}
+	return failures
+}
+
+// lastJSONObject parses the last line of out that is a JSON object.
+func lastJSONObject(out string) (map[string]any, bool) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		var m map[string]any
+		if json.Unmarshal([]byte(strings.TrimSpace(lines[i])), &m) == nil {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// jsonPathValue follows a dotted path through objects and array indexes.
+func jsonPathValue(v any, path string) (any, bool) {
+	for _, part := range strings.Split(path, ".") {
+		switch cur := v.(type) {
+		case map[string]any:
+			next, ok := cur[part]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(cur) {
+				return nil, false
+			}
+			v = cur[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// scenarioJSON renders a value as canonical JSON so YAML and JSON values
+// compare equal (YAML 1 and JSON 1.0 both become 1).
+func scenarioJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func scenarioValueString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return scenarioJSON(v)
+}
+
+var scenarioVarRe = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandScenarioVars replaces ${name} with vars[name]; names with no value are
+// appended to missing (when non-nil) and left in place.
+func expandScenarioVars(s string, vars map[string]string, missing *[]string) string {
+	return scenarioVarRe.ReplaceAllStringFunc(s, func(m string) string {
+		name := m[2 : len(m)-1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		if missing != nil {
+			*missing = append(*missing, name)
+		}
+		return m
+	})
+}
+
+// splitCommandLine splits s into words like a POSIX shell without expansion:
+// single quotes are literal, double quotes group words and honor \" and \\.
+func splitCommandLine(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote in run")
+			}
+			cur.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\') {
+					i++
+				}
+				cur.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, errors.New("unterminated double quote in run")
+			}
+			inWord = true
+		case c == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScenario(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+func TestLoadScenario_Validation(t *testing.T) {
+	_, err := LoadScenario(writeScenario(t, "name: x\nsteps: []\n"))
+	require.Error(t, err, "no steps")
+	_, err = LoadScenario(writeScenario(t, "steps:\n  - run: status\n    expect:\n      sucess: true\n"))
+	require.Error(t, err, "unknown keys are rejected")
+	_, err = LoadScenario(writeScenario(t, "steps:\n  - run: status\n    args: [status]\n"))
+	require.Error(t, err, "run and args are exclusive")
+	_, err = LoadScenario(writeScenario(t, "steps:\n  - run: task create --title \"open\n"))
+	require.Error(t, err, "unterminated quote")
+
+	s, err := LoadScenario(writeScenario(t, "name: ok\nsteps:\n  - run: status\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", s.Name)
+}
+
+func TestRunScenario_AssertionsAndVariables(t *testing.T) {
+	s, err := LoadScenario(writeScenario(t, `
+name: flow
+env:
+  VYBE_AGENT: scn
+steps:
+  - run: vybe task create --title "Two words" --request-id ${scenario_run}_1
+    expect:
+      success: true
+      json:
+        data.task.priority: 0
+        data.tags.1: b
+      exists: [data.task.id]
+    save:
+      task_id: data.task.id
+  - args: [task, get, --id, "${task_id}"]
+    expect:
+      success: false
+  - run: task get --id ${unknown}
+`))
+	require.NoError(t, err)
+
+	var calls [][]string
+	var envs [][]string
+	exec := func(args []string, stdin string, env []string) (string, string, int, error) {
+		calls = append(calls, args)
+		envs = append(envs, env)
+		if args[0] == "task" && args[1] == "create" {
+			return `{"success":true,"data":{"task":{"id":"t1","priority":0},"tags":["a","b"]}}`, "", 0, nil
+		}
+		return `{"success":false,"error":"not found"}`, "", 1, nil
+	}
+
+	res := RunScenario(s, exec, []string{"HOME=/h"}, map[string]string{"scenario_run": "r1"}, true)
+	assert.False(t, res.Passed)
+	assert.Equal(t, 3, res.Total)
+	assert.Equal(t, 1, res.Failed)
+
+	require.Len(t, calls, 2, "the step with an undefined variable never runs")
+	assert.Equal(t, []string{"task", "create", "--title", "Two words", "--request-id", "r1_1"}, calls[0])
+	assert.Equal(t, []string{"task", "get", "--id", "t1"}, calls[1])
+	assert.Contains(t, envs[0], "VYBE_AGENT=scn")
+
+	assert.True(t, res.Steps[0].Passed, res.Steps[0].Failures)
+	assert.True(t, res.Steps[1].Passed, res.Steps[1].Failures)
+	assert.False(t, res.Steps[2].Passed)
+	assert.Contains(t, res.Steps[2].Failures[0], "unknown")
+}
+
+func TestRunScenario_StopsAtFirstFailure(t *testing.T) {
+	s, err := LoadScenario(writeScenario(t, `
+steps:
+  - run: status
+    expect:
+      json:
+        data.ok: true
+  - run: status
+`))
+	require.NoError(t, err)
+	exec := func(args []string, stdin string, env []string) (string, string, int, error) {
+		return "log line\n" + `{"success":true,"data":{"ok":false}}`, "", 0, nil
+	}
+
+	res := RunScenario(s, exec, nil, nil, false)
+	assert.False(t, res.Passed)
+	assert.Equal(t, 1, res.Skipped)
+	assert.True(t, res.Steps[1].Skipped)
+	assert.Equal(t, []string{"data.ok = false, want true"}, res.Steps[0].Failures)
+	assert.NotEmpty(t, res.Steps[0].Stdout, "failed steps keep their output")
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	words, err := splitCommandLine(`task create --title "say \"hi\"" --desc 'a  b' x\ y`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task", "create", "--title", `say "hi"`, "--desc", "a  b", "x y"}, words)
+}
@@ -1,11 +1,13 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // AutoSummarizeEventsIdempotent archives old events when active count exceeds threshold,
@@ -13,7 +15,10 @@ import (
 // Returns (summaryEventID, archivedCount) or (0, 0) if below threshold.
 //
 //nolint:revive // argument-limit: all params (agent, req, project, threshold, keepRecent) required together
-func AutoSummarizeEventsIdempotent(db *sql.DB, agentName, requestID, projectID string, threshold, keepRecent int) (summaryEventID int64, archivedCount int64, err error) {
+func AutoSummarizeEventsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, threshold, keepRecent int) (summaryEventID int64, archivedCount int64, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	if agentName == "" {
 		return 0, 0, errors.New("agent name is required")
 	}
@@ -26,7 +31,7 @@ func AutoSummarizeEventsIdempotent(db *sql.DB, agentName, requestID, projectID s
 	// by the computed ID range, idempotency prevents double-archiving, and the worst case
 	// is keeping slightly more or fewer events than keepRecent. A single-tx approach would
 	// add complexity for negligible benefit.
-	count, err := store.CountActiveEvents(db, projectID)
+	count, err := store.CountActiveEvents(ctx, db, projectID)
 	if err != nil {
 		return 0, 0, fmt.Errorf("count active events: %w", err)
 	}
@@ -34,7 +39,7 @@ func AutoSummarizeEventsIdempotent(db *sql.DB, agentName, requestID, projectID s
 		return 0, 0, nil
 	}
 
-	fromID, toID, err := store.FindArchiveWindow(db, projectID, keepRecent)
+	fromID, toID, err := store.FindArchiveWindow(ctx, db, projectID, keepRecent)
 	if err != nil {
 		return 0, 0, fmt.Errorf("find archive window: %w", err)
 	}
@@ -45,7 +50,7 @@ func AutoSummarizeEventsIdempotent(db *sql.DB, agentName, requestID, projectID s
 	summary := fmt.Sprintf("Auto-compressed events %d–%d (%d active exceeded threshold %d)", fromID, toID, count, threshold)
 
 	var autoSumErr error
-	summaryEventID, archivedCount, autoSumErr = store.ArchiveEventsRangeWithSummaryIdempotent(
+	summaryEventID, archivedCount, autoSumErr = store.ArchiveEventsRangeWithSummaryIdempotent(ctx,
 		db, agentName, requestID, projectID, "", fromID, toID, summary,
 	)
 	if autoSumErr != nil {
@@ -57,7 +62,7 @@ func AutoSummarizeEventsIdempotent(db *sql.DB, agentName, requestID, projectID s
 
 // AutoPruneArchivedEventsIdempotent permanently deletes archived events older
 // than olderThanDays. Deletion is bounded by limit per call.
-func AutoPruneArchivedEventsIdempotent(db *sql.DB, agentName, requestID, projectID string, olderThanDays, limit int) (deletedCount int64, err error) {
+func AutoPruneArchivedEventsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, olderThanDays, limit int) (deletedCount int64, err error) {
 	if agentName == "" {
 		return 0, errors.New("agent name is required")
 	}
@@ -65,10 +70,56 @@ func AutoPruneArchivedEventsIdempotent(db *sql.DB, agentName, requestID, project
 		return 0, errors.New("request id is required")
 	}
 
-	deleted, err := store.PruneArchivedEventsIdempotent(db, agentName, requestID, projectID, olderThanDays, limit)
+	deleted, err := store.PruneArchivedEventsIdempotent(ctx, db, agentName, requestID, projectID, olderThanDays, limit)
 	if err != nil {
 		return 0, fmt.Errorf("prune archived events: %w", err)
 	}
 
 	return deleted, nil
 }
+
+// AutoPruneEventsByRetentionIdempotent applies the default archived-event window and
+// per-kind retention rules for a project. Deletion is bounded by limit per call.
+//
+//nolint:revive // argument-limit: mirrors store.PruneEventsByRetentionIdempotent
+func AutoPruneEventsByRetentionIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, defaultDays int, rules []store.RetentionRule, limit int) (deletedCount int64, err error) {
+	if agentName == "" {
+		return 0, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return 0, errors.New("request id is required")
+	}
+
+	deleted, err := store.PruneEventsByRetentionIdempotent(ctx, db, agentName, requestID, projectID, defaultDays, rules, limit)
+	if err != nil {
+		return 0, fmt.Errorf("prune events by retention: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// PreviewEventsPrune reports per-rule deletion counts without modifying anything.
+func PreviewEventsPrune(ctx context.Context, db *sql.DB, projectID string, defaultDays int, rules []store.RetentionRule) ([]store.PruneCandidate, error) {
+	candidates, err := store.PreviewRetentionPrune(ctx, db, projectID, defaultDays, rules)
+	if err != nil {
+		return nil, fmt.Errorf("preview events prune: %w", err)
+	}
+	return candidates, nil
+}
+
+// PreviewEventsDedupe reports duplicate event groups without modifying anything.
+func PreviewEventsDedupe(ctx context.Context, db *sql.DB, params store.DedupeParams) (*store.DedupeStats, error) {
+	stats, err := store.FindDuplicateEvents(ctx, db, params)
+	if err != nil {
+		return nil, fmt.Errorf("preview events dedupe: %w", err)
+	}
+	return stats, nil
+}
+
+// DedupeEventsIdempotent archives duplicate events once per (agent_name, request_id).
+func DedupeEventsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, params store.DedupeParams) (*store.DedupeStats, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	return store.DedupeEventsIdempotent(ctx, db, agentName, requestID, params)
+}
@@ -0,0 +1,158 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// Replay entry categories, in the order the narrative summary lists them.
+const (
+	ReplayPrompt     = "prompt"
+	ReplayTool       = "tool"
+	ReplayProgress   = "progress"
+	ReplayCompletion = "completion"
+	ReplayOther      = "other"
+)
+
+// ReplayEntry is one step of a session narrative.
+type ReplayEntry struct {
+	EventID  int64     `json:"event_id"`
+	At       time.Time `json:"at"`
+	Category string    `json:"category"`
+	Kind     string    `json:"kind"`
+	Agent    string    `json:"agent"`
+	TaskID   string    `json:"task_id,omitempty"`
+	Tool     string    `json:"tool,omitempty"`
+	Text     string    `json:"text"`
+	// Tagged is false for events attributed to the session by agent and time
+	// window rather than by metadata.session_id.
+	Tagged bool `json:"tagged"`
+}
+
+// SessionReplay is the reconstructed narrative of one client session.
+type SessionReplay struct {
+	SessionID string          `json:"session_id"`
+	Session   *models.Session `json:"session,omitempty"`
+	Agents    []string        `json:"agents"`
+	StartedAt time.Time       `json:"started_at"`
+	EndedAt   time.Time       `json:"ended_at"`
+	Counts    map[string]int  `json:"counts"`
+	Entries   []ReplayEntry   `json:"entries"`
+}
+
+// ReplaySession rebuilds the chronological narrative of sessionID.
+func ReplaySession(ctx context.Context, db *sql.DB, sessionID string, limit int) (*SessionReplay, error) {
+	if sessionID == "" {
+		return nil, errors.New("session id is required")
+	}
+	se, err := store.FetchSessionEventsByID(ctx, db, sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SessionReplay{
+		SessionID: sessionID,
+		Session:   se.Session,
+		Agents:    se.Agents,
+		StartedAt: se.Events[0].CreatedAt,
+		EndedAt:   se.Events[len(se.Events)-1].CreatedAt,
+		Counts:    map[string]int{},
+		Entries:   make([]ReplayEntry, 0, len(se.Events)),
+	}
+	for _, e := range se.Events {
+		entry := ReplayEntry{
+			EventID:  e.ID,
+			At:       e.CreatedAt,
+			Category: replayCategory(e),
+			Kind:     e.Kind,
+			Agent:    e.AgentName,
+			TaskID:   e.TaskID,
+			Tool:     metadataString(e.Metadata, "tool_name"),
+			Text:     e.Message,
+			Tagged:   se.Tagged[e.ID],
+		}
+		r.Counts[entry.Category]++
+		r.Entries = append(r.Entries, entry)
+	}
+	return r, nil
+}
+
+func replayCategory(e *models.Event) string {
+	switch e.Kind {
+	case models.EventKindUserPrompt:
+		return ReplayPrompt
+	case models.EventKindToolFailure:
+		return ReplayTool
+	case models.EventKindProgress, models.EventKindReasoning, models.EventKindAssistantSummary:
+		return ReplayProgress
+	case models.EventKindTaskClosed, "task_completed_signal":
+		return ReplayCompletion
+	case models.EventKindTaskStatus:
+		if strings.Contains(e.Message, "completed") {
+			return ReplayCompletion
+		}
+		return ReplayProgress
+	}
+	if metadataString(e.Metadata, "tool_name") != "" {
+		return ReplayTool
+	}
+	return ReplayOther
+}
+
+func metadataString(raw json.RawMessage, key string) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return ""
+	}
+	s, _ := m[key].(string)
+	return s
+}
+
+// RenderSessionReplayMarkdown renders r as a markdown timeline.
+func RenderSessionReplayMarkdown(r *SessionReplay) string {
+	var b strings.Builder
+	if s := r.Session; s != nil && s.Label != "" {
+		fmt.Fprintf(&b, "# Session: %s\n\n- ID: %s\n", s.Label, r.SessionID)
+	} else {
+		fmt.Fprintf(&b, "# Session %s\n\n", r.SessionID)
+	}
+	fmt.Fprintf(&b, "- Agents: %s\n", strings.Join(r.Agents, ", "))
+	fmt.Fprintf(&b, "- Started: %s\n", r.StartedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Ended: %s (%s)\n", r.EndedAt.UTC().Format(time.RFC3339), r.EndedAt.Sub(r.StartedAt).Round(time.Second))
+	fmt.Fprintf(&b, "- Steps: %d prompts, %d tool calls, %d progress, %d completions\n",
+		r.Counts[ReplayPrompt], r.Counts[ReplayTool], r.Counts[ReplayProgress], r.Counts[ReplayCompletion])
+	if s := r.Session; s != nil && s.Outcome != "" {
+		fmt.Fprintf(&b, "- Outcome: %s (%d tasks completed)\n", s.Outcome, s.TasksCompleted)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Timeline\n\n")
+	for _, e := range r.Entries {
+		label := e.Category
+		if e.Tool != "" {
+			label += " " + e.Tool
+		}
+		text := strings.ReplaceAll(strings.TrimSpace(e.Text), "\n", " ")
+		fmt.Fprintf(&b, "- `%s` **%s**", e.At.UTC().Format("15:04:05"), label)
+		if e.TaskID != "" {
+			fmt.Fprintf(&b, " [%s]", e.TaskID)
+		}
+		fmt.Fprintf(&b, " %s", text)
+		if !e.Tagged {
+			b.WriteString(" _(by time window)_")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestReplaySession_TimelineAndWindow(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insert := func(kind, agent, msg, meta string) {
+		t.Helper()
+		require.NoError(t, store.Transact(context.Background(), db, func(tx *sql.Tx) error {
+			_, err := store.InsertEventTx(tx, kind, agent, "", msg, meta)
+			return err
+		}))
+	}
+
+	insert(models.EventKindProgress, "agent1", "before session", "")
+	insert(models.EventKindUserPrompt, "agent1", "fix the login bug", `{"session_id":"sess-1"}`)
+	insert(models.EventKindProgress, "agent1", "found the cause", "")
+	insert(models.EventKindProgress, "agent2", "other agent", "")
+	insert(models.EventKindUserPrompt, "agent1", "other session", `{"session_id":"sess-2"}`)
+	insert(models.EventKindToolFailure, "agent1", "Bash failed", `{"session_id":"sess-1","tool_name":"Bash"}`)
+	insert(models.EventKindProgress, "agent1", "after session", "")
+
+	r, err := ReplaySession(context.Background(), db, "sess-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"agent1"}, r.Agents)
+	require.Len(t, r.Entries, 3)
+	assert.Equal(t, ReplayPrompt, r.Entries[0].Category)
+	assert.Equal(t, "found the cause", r.Entries[1].Text)
+	assert.False(t, r.Entries[1].Tagged, "untagged push inside the window is attributed by time")
+	assert.Equal(t, ReplayTool, r.Entries[2].Category)
+	assert.Equal(t, "Bash", r.Entries[2].Tool)
+	assert.Equal(t, 1, r.Counts[ReplayProgress])
+
+	md := RenderSessionReplayMarkdown(r)
+	assert.Contains(t, md, "# Session sess-1")
+	assert.Contains(t, md, "**tool Bash** Bash failed")
+	assert.Contains(t, md, "_(by time window)_")
+
+	_, err = ReplaySession(context.Background(), db, "missing", 0)
+	var nf *store.SessionNotFoundError
+	require.ErrorAs(t, err, &nf)
+}
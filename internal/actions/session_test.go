@@ -13,7 +13,7 @@ func TestAutoSummarizeEventsIdempotent(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	_, err := store.LoadOrCreateAgentState(db, "test-agent")
+	_, err := store.LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	require.NoError(t, err)
 
 	// Below threshold — no-op
@@ -24,7 +24,7 @@ func TestAutoSummarizeEventsIdempotent(t *testing.T) {
 		}))
 	}
 
-	summaryID, archived, err := AutoSummarizeEventsIdempotent(db, "test-agent", "req-sum-1", "", 200, 50)
+	summaryID, archived, err := AutoSummarizeEventsIdempotent(context.Background(), db, "test-agent", "req-sum-1", "", 200, 50)
 	require.NoError(t, err)
 	require.Equal(t, int64(0), summaryID)
 	require.Equal(t, int64(0), archived)
@@ -34,7 +34,7 @@ func TestAutoPruneArchivedEventsIdempotent(t *testing.T) {
 	db, cleanup := setupTestDBWithCleanup(t)
 	defer cleanup()
 
-	_, err := store.LoadOrCreateAgentState(db, "test-agent")
+	_, err := store.LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	require.NoError(t, err)
 
 	for range 3 {
@@ -44,13 +44,13 @@ func TestAutoPruneArchivedEventsIdempotent(t *testing.T) {
 		}))
 	}
 
-	_, _, err = store.ArchiveEventsRangeWithSummaryIdempotent(db, "test-agent", "req-archive-prune", "", "", 1, 2, "compressed")
+	_, _, err = store.ArchiveEventsRangeWithSummaryIdempotent(context.Background(), db, "test-agent", "req-archive-prune", "", "", 1, 2, "compressed")
 	require.NoError(t, err)
 
 	_, err = db.Exec(`UPDATE events SET archived_at = datetime('now', '-40 days') WHERE id IN (1,2)`)
 	require.NoError(t, err)
 
-	deleted, err := AutoPruneArchivedEventsIdempotent(db, "test-agent", "req-prune-action", "", 30, 10)
+	deleted, err := AutoPruneArchivedEventsIdempotent(context.Background(), db, "test-agent", "req-prune-action", "", 30, 10)
 	require.NoError(t, err)
 	require.Equal(t, int64(2), deleted)
 }
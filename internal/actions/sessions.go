@@ -0,0 +1,135 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// validSessionOutcomes are the outcomes `session end --outcome` accepts.
+var validSessionOutcomes = map[string]bool{
+	models.SessionOutcomeCompleted:  true,
+	models.SessionOutcomeIncomplete: true,
+	models.SessionOutcomeFailed:     true,
+	models.SessionOutcomeAbandoned:  true,
+}
+
+// SessionList lists recorded sessions, newest first.
+func SessionList(ctx context.Context, db *sql.DB, params store.ListSessionsParams) ([]*models.Session, error) {
+	sessions, err := store.ListSessions(ctx, db, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// SessionGet returns one recorded session.
+func SessionGet(ctx context.Context, db *sql.DB, sessionID string) (*models.Session, error) {
+	if sessionID == "" {
+		return nil, errors.New("session id is required")
+	}
+	return store.GetSession(ctx, db, sessionID)
+}
+
+// SessionEndIdempotent closes a session. An empty outcome is derived from the
+// tasks the agent completed during the session.
+//
+//nolint:revive // argument-limit: agent, request, session, reason, outcome are all required
+func SessionEndIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, sessionID, reason, outcome string) (*models.Session, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	if sessionID == "" {
+		return nil, errors.New("session id is required")
+	}
+	if outcome != "" && !validSessionOutcomes[outcome] {
+		return nil, fmt.Errorf("invalid outcome %q (valid: completed, incomplete, failed, abandoned)", outcome)
+	}
+	return store.EndSessionIdempotent(ctx, db, agentName, requestID, sessionID, reason, outcome)
+}
+
+// maxSessionLabelRunes caps a session label; longer text is cut at a word boundary.
+const maxSessionLabelRunes = 60
+
+// SessionLabelFromPrompt derives a session label from a user prompt: its first
+// non-blank line, with control characters dropped and whitespace collapsed,
+// truncated to 60 characters. Slash commands and markup-only prompts yield "".
+func SessionLabelFromPrompt(prompt string) string {
+	for _, line := range strings.Split(prompt, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "/") || strings.HasPrefix(line, "<") {
+			return ""
+		}
+		return normalizeSessionLabel(line)
+	}
+	return ""
+}
+
+// normalizeSessionLabel makes label safe to print on one line and bounds its length.
+func normalizeSessionLabel(label string) string {
+	label = strings.Join(strings.FieldsFunc(label, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}), " ")
+	runes := []rune(label)
+	if len(runes) <= maxSessionLabelRunes {
+		return label
+	}
+	cut := string(runes[:maxSessionLabelRunes-1])
+	if i := strings.LastIndexByte(cut, ' '); i > maxSessionLabelRunes/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:-") + "…"
+}
+
+// SessionLabelIdempotent names a session, replacing any earlier label.
+//
+//nolint:revive // argument-limit: agent, request, session, label are all required
+func SessionLabelIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, sessionID, label string) (*models.Session, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if sessionID == "" {
+		return nil, errors.New("session id is required")
+	}
+	label = normalizeSessionLabel(label)
+	if label == "" {
+		return nil, errors.New("label is required")
+	}
+	return store.SetSessionLabelIdempotent(ctx, db, agentName, requestID, sessionID, label, false)
+}
+
+// SessionAutoLabelIdempotent names a recorded, unlabeled session after prompt.
+// It returns nil without writing when the session is unknown (no session-start
+// hook ran), already labeled, or prompt makes no usable label.
+//
+//nolint:revive // argument-limit: agent, request, session, prompt are all required
+func SessionAutoLabelIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, sessionID, prompt string) (*models.Session, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+	label := SessionLabelFromPrompt(prompt)
+	if label == "" {
+		return nil, nil
+	}
+	s, err := store.GetSession(ctx, db, sessionID)
+	var notFound *store.SessionNotFoundError
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	if err != nil || s.Label != "" {
+		return s, err
+	}
+	return store.SetSessionLabelIdempotent(ctx, db, agentName, requestID, sessionID, label, true)
+}
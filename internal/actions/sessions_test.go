@@ -0,0 +1,57 @@
+package actions
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestSessionLabelFromPrompt(t *testing.T) {
+	assert.Equal(t, "Fix the flaky login test", SessionLabelFromPrompt("\n  Fix the   flaky\tlogin test\nIt fails on CI."))
+	assert.Equal(t, "", SessionLabelFromPrompt("/clear"))
+	assert.Equal(t, "", SessionLabelFromPrompt("<command-name>review</command-name>"))
+	assert.Equal(t, "", SessionLabelFromPrompt("   \n\t"))
+	assert.Equal(t, "bell gone", SessionLabelFromPrompt("bell\x07gone"))
+
+	long := SessionLabelFromPrompt(strings.Repeat("refactor the storage layer ", 10))
+	assert.LessOrEqual(t, utf8.RuneCountInString(long), maxSessionLabelRunes)
+	assert.True(t, strings.HasSuffix(long, "…"))
+	assert.False(t, strings.HasSuffix(strings.TrimSuffix(long, "…"), " "), "cut at a word boundary")
+}
+
+func TestSessionAutoLabelIdempotent(t *testing.T) {
+	db, _ := setupTestDBWithCleanup(t)
+
+	// Unknown sessions are skipped, not errors.
+	s, err := SessionAutoLabelIdempotent(context.Background(), db, "agent1", "auto-0", "sess-unknown", "Plan the migration")
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	_, err = store.StartSessionIdempotent(context.Background(), db, "agent1", "start-1", "sess-1", "", "startup")
+	require.NoError(t, err)
+
+	s, err = SessionAutoLabelIdempotent(context.Background(), db, "agent1", "auto-1", "sess-1", "/compact")
+	require.NoError(t, err)
+	assert.Nil(t, s, "slash commands do not name a session")
+
+	s, err = SessionAutoLabelIdempotent(context.Background(), db, "agent1", "auto-2", "sess-1", "Plan the migration")
+	require.NoError(t, err)
+	assert.Equal(t, "Plan the migration", s.Label)
+
+	s, err = SessionAutoLabelIdempotent(context.Background(), db, "agent1", "auto-3", "sess-1", "Now write the tests")
+	require.NoError(t, err)
+	assert.Equal(t, "Plan the migration", s.Label)
+
+	s, err = SessionLabelIdempotent(context.Background(), db, "agent1", "manual-1", "sess-1", "  Migration   plan ")
+	require.NoError(t, err)
+	assert.Equal(t, "Migration plan", s.Label)
+
+	_, err = SessionLabelIdempotent(context.Background(), db, "agent1", "manual-2", "sess-1", "   ")
+	require.Error(t, err)
+}
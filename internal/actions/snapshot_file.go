@@ -0,0 +1,78 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// SnapshotToFile writes a point-in-time copy of the database to path with
+// VACUUM INTO and returns a description of the written file.
+func SnapshotToFile(ctx context.Context, db *sql.DB, path string) (*store.SnapshotFileInfo, error) {
+	if path == "" {
+		return nil, errors.New("snapshot file path is required")
+	}
+	if err := store.VacuumInto(ctx, db, path); err != nil {
+		return nil, err
+	}
+	return store.InspectSnapshotFile(ctx, path)
+}
+
+// SnapshotMountResult is the read-only view of a snapshot file.
+type SnapshotMountResult struct {
+	Info *store.SnapshotFileInfo `json:"info"`
+	Rows []map[string]any        `json:"rows,omitempty"`
+}
+
+// SnapshotMount opens a snapshot file read-only, describes it, and runs query
+// against it when one is given.
+func SnapshotMount(ctx context.Context, path, query string) (*SnapshotMountResult, error) {
+	if path == "" {
+		return nil, errors.New("snapshot file path is required")
+	}
+	info, err := store.InspectSnapshotFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	res := &SnapshotMountResult{Info: info}
+	if query != "" {
+		res.Rows, err = store.QuerySnapshotFile(path, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// SnapshotCreateIdempotent writes a named snapshot into dir and registers it.
+func SnapshotCreateIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, dir, name string) (*store.NamedSnapshotResult, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	return store.CreateNamedSnapshotIdempotent(ctx, db, agentName, requestID, dir, name)
+}
+
+// SnapshotList returns the registered named snapshots, newest first.
+func SnapshotList(ctx context.Context, db *sql.DB) ([]*store.NamedSnapshot, error) {
+	return store.ListNamedSnapshots(ctx, db)
+}
+
+// SnapshotRestoreIdempotent rolls task and/or memory state back to a named
+// snapshot. The event log is not rolled back.
+func SnapshotRestoreIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, idOrName, scope string) (*store.SnapshotRestoreResult, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	if idOrName == "" {
+		return nil, errors.New("snapshot id is required")
+	}
+	return store.RestoreNamedSnapshotIdempotent(ctx, db, agentName, requestID, idOrName, scope)
+}
@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// burndownWidth is the length of the longest bar in the burndown chart.
+const burndownWidth = 40
+
+// RenderBurndownText renders t as a per-day burndown chart: one bar per
+// snapshot, split into pending (p), in-progress (i), and blocked (b) tasks.
+func RenderBurndownText(t *store.ProjectTrends) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Queue depth: %s, last %d days\n\n", t.ProjectID, t.Days)
+	if len(t.Snapshots) == 0 {
+		b.WriteString("No snapshots yet; the checkpoint hook records one per day.\n")
+		return b.String()
+	}
+
+	peak := 0
+	for _, s := range t.Snapshots {
+		peak = max(peak, s.Backlog())
+	}
+	for _, s := range t.Snapshots {
+		fmt.Fprintf(&b, "%s  %-*s  %3d open (%d pending, %d in progress, %d blocked), %d done\n",
+			s.Day, burndownWidth, burndownBar(s, peak), s.Backlog(),
+			s.TasksPending, s.TasksInProgress, s.TasksBlocked, s.TasksCompleted)
+	}
+	if t.Delta != nil {
+		fmt.Fprintf(&b, "\nChange: %+d open, %+d done\n", t.Delta.Backlog, t.Delta.TasksCompleted)
+	}
+	return b.String()
+}
+
+// burndownBar scales each status to the busiest day so bars stay comparable.
+// A status with any tasks gets at least one mark.
+func burndownBar(s store.ProjectStatsSnapshot, peak int) string {
+	if peak <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, part := range []struct {
+		n    int
+		mark string
+	}{{s.TasksPending, "p"}, {s.TasksInProgress, "i"}, {s.TasksBlocked, "b"}} {
+		if part.n > 0 {
+			b.WriteString(strings.Repeat(part.mark, max(1, part.n*burndownWidth/peak)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,26 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestRenderBurndownText(t *testing.T) {
+	trends := &store.ProjectTrends{ProjectID: "/repo", Days: 7, Snapshots: []store.ProjectStatsSnapshot{
+		{Day: "2026-01-05", TasksPending: 6, TasksInProgress: 1, TasksBlocked: 1, TasksCompleted: 2},
+		{Day: "2026-01-06", TasksPending: 1, TasksCompleted: 9},
+	}, Delta: &store.ProjectTrendDelta{Backlog: -7, TasksCompleted: 7}}
+
+	txt := RenderBurndownText(trends)
+	assert.Contains(t, txt, "Queue depth: /repo, last 7 days")
+	assert.Contains(t, txt, "2026-01-05  "+strings.Repeat("p", 30)+strings.Repeat("i", 5)+strings.Repeat("b", 5)+
+		"    8 open (6 pending, 1 in progress, 1 blocked), 2 done")
+	assert.Contains(t, txt, "2026-01-06  "+strings.Repeat("p", 5)+strings.Repeat(" ", 35)+"    1 open")
+	assert.Contains(t, txt, "Change: -7 open, +7 done")
+
+	assert.Contains(t, RenderBurndownText(&store.ProjectTrends{ProjectID: "/repo", Days: 7}), "No snapshots yet")
+}
@@ -1,12 +1,17 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 const (
@@ -54,12 +59,35 @@ func validateTaskStatus(status string) error {
 // TaskCreateIdempotent creates a new task once per (agent_name, request_id).
 // On retries with the same request id, it returns the originally created task + event id.
 // If projectID is non-empty, the task is associated with that project.
-func TaskCreateIdempotent(db *sql.DB, agentName, requestID, title, description, projectID string, priority int) (*models.Task, int64, error) { //nolint:revive // argument-limit: all params are required and semantically distinct; a struct would degrade test readability
+func TaskCreateIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, title, description, projectID string, priority int) (*models.Task, int64, error) { //nolint:revive // argument-limit: all params are required and semantically distinct; a struct would degrade test readability
+	return TaskCreateWithOptionsIdempotent(ctx, db, agentName, requestID, title, description, projectID, priority, TaskCreateOptions{})
+}
+
+// TaskCreateOptions holds optional inputs for task creation.
+type TaskCreateOptions struct {
+	DueAt *time.Time      // Deadline; nil for none
+	Size  models.TaskSize // Effort size; "" for unsized
+	Tags  []string        // Routing tags; nil for none
+	// Requires lists capabilities an agent must have registered to be routed the task.
+	Requires []string
+}
+
+// TaskCreateWithOptionsIdempotent is TaskCreateIdempotent with a deadline, size, tags, and required capabilities, set in the same transaction.
+//
+//nolint:revive // argument-limit: mirrors TaskCreateIdempotent plus options
+func TaskCreateWithOptionsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, title, description, projectID string, priority int, opts TaskCreateOptions) (_ *models.Task, _ int64, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	if title == "" {
 		return nil, 0, errors.New("task title is required")
 	}
+	projectID, err = confineProjectID(ctx, db, agentName, projectID)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	createdTask, eventID, err := runCreateWithEvent(db, agentName, requestID, "task.create", "create task", func(tx *sql.Tx) (models.Task, int64, error) {
+	createdTask, eventID, err := runCreateWithEvent(ctx, db, agentName, requestID, "task.create", "create task", func(tx *sql.Tx) (models.Task, int64, error) {
 		createdTask, err := store.CreateTaskTx(tx, title, description, projectID, priority)
 		if err != nil {
 			return models.Task{}, 0, err
@@ -70,6 +98,38 @@ func TaskCreateIdempotent(db *sql.DB, agentName, requestID, title, description,
 			return models.Task{}, 0, fmt.Errorf("failed to append event: %w", err)
 		}
 
+		if opts.DueAt != nil {
+			if _, err := store.SetTaskDueTx(tx, agentName, createdTask.ID, opts.DueAt); err != nil {
+				return models.Task{}, 0, fmt.Errorf("failed to set deadline: %w", err)
+			}
+		}
+		if opts.Size != "" {
+			if _, err := store.SetTaskSizeTx(tx, agentName, createdTask.ID, opts.Size); err != nil {
+				return models.Task{}, 0, fmt.Errorf("failed to set size: %w", err)
+			}
+		}
+		if opts.DueAt != nil || opts.Size != "" {
+			task, err := store.GetTaskTx(tx, createdTask.ID)
+			if err != nil {
+				return models.Task{}, 0, err
+			}
+			createdTask = task
+		}
+		if len(opts.Tags) > 0 {
+			change, err := store.AddTaskTagsTx(tx, agentName, createdTask.ID, opts.Tags)
+			if err != nil {
+				return models.Task{}, 0, fmt.Errorf("failed to tag task: %w", err)
+			}
+			createdTask.Tags = change.Tags
+		}
+		if len(opts.Requires) > 0 {
+			requires, err := store.SetTaskRequirementsTx(tx, createdTask.ID, opts.Requires)
+			if err != nil {
+				return models.Task{}, 0, fmt.Errorf("failed to set required capabilities: %w", err)
+			}
+			createdTask.Requires = requires
+		}
+
 		return *createdTask, eventID, nil
 	})
 	if err != nil {
@@ -92,7 +152,25 @@ func TaskCreateIdempotent(db *sql.DB, agentName, requestID, title, description,
 // and CAS version conflicts in a single retry loop.
 //
 //nolint:gocognit,gocyclo,revive // idempotent variant adds request deduplication around TaskSetStatus logic; all branches are required
-func TaskSetStatusIdempotent(db *sql.DB, agentName, requestID, taskID, status, blockedReason string) (*models.Task, int64, error) {
+func TaskSetStatusIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, status, blockedReason string) (*models.Task, int64, error) {
+	return TaskSetStatusWithOptionsIdempotent(ctx, db, agentName, requestID, taskID, status, TaskStatusOptions{BlockedReason: blockedReason})
+}
+
+// TaskStatusOptions holds optional inputs for a status change.
+type TaskStatusOptions struct {
+	BlockedReason string // Recorded when status is blocked
+	Strict        bool   // Refuse completion while acceptance criteria are unchecked
+	IfVersion     *int   // Fail with a *store.StaleVersionError unless the task is at this version
+}
+
+// TaskSetStatusWithOptionsIdempotent is TaskSetStatusIdempotent with strict-completion support.
+//
+//nolint:revive // argument-limit: mirrors TaskSetStatusIdempotent plus options
+func TaskSetStatusWithOptionsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, status string, opts TaskStatusOptions) (_ *models.Task, _ int64, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	blockedReason := opts.BlockedReason
 	if status == "" {
 		return nil, 0, errors.New("status is required")
 	}
@@ -101,12 +179,21 @@ func TaskSetStatusIdempotent(db *sql.DB, agentName, requestID, taskID, status, b
 		return nil, 0, err
 	}
 
-	updatedTask, result, err := runTaskMutationWithRetry(db, agentName, requestID, taskID, "task.set_status", "updated", func(tx *sql.Tx) (eventResult, error) {
+	updatedTask, result, err := runTaskMutationWithRetry(ctx, db, agentName, requestID, taskID, "task.set_status", "updated", func(tx *sql.Tx) (eventResult, error) {
+		if err := checkIfVersion(tx, taskID, opts.IfVersion); err != nil {
+			return eventResult{}, err
+		}
 		version, err := store.GetTaskVersionTx(tx, taskID)
 		if err != nil {
 			return eventResult{}, fmt.Errorf("failed to get task: %w", err)
 		}
 
+		if opts.Strict && status == completedStatus {
+			if err := store.RequireCriteriaDoneTx(tx, taskID); err != nil {
+				return eventResult{}, err
+			}
+		}
+
 		eventID, err := store.UpdateTaskStatusWithEventTx(tx, agentName, taskID, status, version)
 		if err != nil {
 			return eventResult{}, err
@@ -131,14 +218,17 @@ func TaskSetStatusIdempotent(db *sql.DB, agentName, requestID, taskID, status, b
 
 // TaskStartResult holds the output of a TaskStart operation.
 type TaskStartResult struct {
-	Task          *models.Task `json:"task"`
-	StatusEventID int64        `json:"status_event_id"`
-	FocusEventID  int64        `json:"focus_event_id"`
+	Task          *models.Task     `json:"task"`
+	StatusEventID int64            `json:"status_event_id"`
+	FocusEventID  int64            `json:"focus_event_id"`
+	Lease         *store.TaskLease `json:"lease,omitempty"`       // set by task claim when the claim is leased
+	StolenFrom    string           `json:"stolen_from,omitempty"` // set by task claim --steal-stale on a takeover
+	StolenEventID int64            `json:"stolen_event_id,omitempty"`
 }
 
 // TaskStartIdempotent performs TaskStart once per (agent_name, request_id).
 // On retries with the same request id, it returns the originally created event ids and current task state.
-func TaskStartIdempotent(db *sql.DB, agentName, requestID, taskID string) (*TaskStartResult, error) {
+func TaskStartIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string) (*TaskStartResult, error) {
 	if agentName == "" {
 		return nil, errors.New("agent name is required")
 	}
@@ -148,13 +238,16 @@ func TaskStartIdempotent(db *sql.DB, agentName, requestID, taskID string) (*Task
 	if taskID == "" {
 		return nil, errors.New("task ID is required")
 	}
+	if err := CheckConfinedTask(ctx, db, agentName, taskID); err != nil {
+		return nil, err
+	}
 
-	statusEventID, focusEventID, err := store.StartTaskAndFocusIdempotent(db, agentName, requestID, taskID)
+	statusEventID, focusEventID, err := store.StartTaskAndFocusIdempotent(ctx, db, agentName, requestID, taskID)
 	if err != nil {
 		return nil, err
 	}
 
-	task, err := store.GetTask(db, taskID)
+	task, err := store.GetTask(ctx, db, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch task: %w", err)
 	}
@@ -162,24 +255,195 @@ func TaskStartIdempotent(db *sql.DB, agentName, requestID, taskID string) (*Task
 	return &TaskStartResult{Task: task, StatusEventID: statusEventID, FocusEventID: focusEventID}, nil
 }
 
+// TaskClaimIdempotent claims the next pending task passing filter (see
+// store.ClaimNextTaskIdempotent) under the configured focus policy. Tasks
+// requiring capabilities the agent has not registered are skipped. The result
+// has a nil Task when nothing matched. Unless overrideLimits is set, a reached
+// tasks_per_day limit fails the claim with a *store.LimitExceededError. See
+// store.ClaimOptions for the lease override and work stealing.
+func TaskClaimIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, filter store.FocusFilter, overrideLimits bool, opts store.ClaimOptions) (_ *TaskStartResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	projectID, err = confineProjectID(ctx, db, agentName, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !overrideLimits {
+		if err := CheckDailyLimit(ctx, db, store.LimitTasksPerDay); err != nil {
+			return nil, err
+		}
+	}
+
+	filter.RoutedTo = agentName
+	claim, err := store.ClaimNextTaskIdempotent(ctx, db, agentName, requestID, projectID, app.FocusPolicyFor(""), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if claim.TaskID == "" {
+		return &TaskStartResult{}, nil
+	}
+
+	task, err := store.GetTask(ctx, db, claim.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task: %w", err)
+	}
+	if tags, err := store.ListTaskTags(ctx, db, task.ID); err == nil && len(tags) > 0 {
+		task.Tags = tags
+	}
+	if requires, err := store.ListTaskRequirements(ctx, db, task.ID); err == nil && len(requires) > 0 {
+		task.Requires = requires
+	}
+	return &TaskStartResult{Task: task, StatusEventID: claim.StatusEventID, FocusEventID: claim.FocusEventID,
+		Lease: claim.Lease, StolenFrom: claim.StolenFrom, StolenEventID: claim.StolenEventID}, nil
+}
+
 // TaskGet retrieves a task by ID
-func TaskGet(db *sql.DB, taskID string) (*models.Task, error) {
+func TaskGet(ctx context.Context, db *sql.DB, taskID string) (_ *models.Task, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	if taskID == "" {
 		return nil, errors.New("task ID is required")
 	}
 
-	task, err := store.GetTask(db, taskID)
+	task, err := store.GetTask(ctx, db, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
+	meta, err := store.ListTaskMeta(ctx, db, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task metadata: %w", err)
+	}
+	task.Metadata = store.TaskMetaMap(meta)
+
+	tags, err := store.ListTaskTags(ctx, db, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task tags: %w", err)
+	}
+	if len(tags) > 0 {
+		task.Tags = tags
+	}
+
+	requires, err := store.ListTaskRequirements(ctx, db, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task requirements: %w", err)
+	}
+	if len(requires) > 0 {
+		task.Requires = requires
+	}
+
 	return task, nil
 }
 
+// TaskMetaSetIdempotent sets one typed metadata entry on a task.
+// An empty valueType infers the type from the value.
+//
+//nolint:revive // argument-limit: agent, request, task, key, value, and type are all required
+func TaskMetaSetIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, key, value, valueType string) (*models.TaskMeta, int64, error) {
+	if err := validateTaskMutation(ctx, db, agentName, requestID, taskID); err != nil {
+		return nil, 0, err
+	}
+	return store.SetTaskMetaIdempotent(ctx, db, agentName, requestID, taskID, key, value, valueType)
+}
+
+// TaskMetaUnsetIdempotent removes one metadata entry; eventID is 0 when the key was absent.
+func TaskMetaUnsetIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, key string) (int64, error) {
+	if err := validateTaskMutation(ctx, db, agentName, requestID, taskID); err != nil {
+		return 0, err
+	}
+	return store.UnsetTaskMetaIdempotent(ctx, db, agentName, requestID, taskID, key)
+}
+
+// TaskMetaList returns a task's metadata entries.
+func TaskMetaList(ctx context.Context, db *sql.DB, taskID string) ([]models.TaskMeta, error) {
+	if err := validateTaskID(taskID); err != nil {
+		return nil, err
+	}
+	return store.ListTaskMeta(ctx, db, taskID)
+}
+
+// TaskTagAddIdempotent adds tags to a task; tags it already carries are ignored.
+func TaskTagAddIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string, tags []string) (*store.TaskTagChange, error) {
+	if err := validateTaskMutation(ctx, db, agentName, requestID, taskID); err != nil {
+		return nil, err
+	}
+	return store.AddTaskTagsIdempotent(ctx, db, agentName, requestID, taskID, tags)
+}
+
+// TaskTagRemoveIdempotent removes tags from a task; tags it does not carry are ignored.
+func TaskTagRemoveIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string, tags []string) (*store.TaskTagChange, error) {
+	if err := validateTaskMutation(ctx, db, agentName, requestID, taskID); err != nil {
+		return nil, err
+	}
+	return store.RemoveTaskTagsIdempotent(ctx, db, agentName, requestID, taskID, tags)
+}
+
+// TaskTagList returns a task's tags.
+func TaskTagList(ctx context.Context, db *sql.DB, taskID string) ([]string, error) {
+	if err := validateTaskID(taskID); err != nil {
+		return nil, err
+	}
+	return store.ListTaskTags(ctx, db, taskID)
+}
+
+// TaskTagCounts returns every tag in use with per-status task counts.
+func TaskTagCounts(ctx context.Context, db *sql.DB, projectID string) ([]store.TagCount, error) {
+	return store.ListTagCounts(ctx, db, projectID)
+}
+
+// TaskCriteriaAddIdempotent appends an acceptance criterion to a task.
+func TaskCriteriaAddIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, text string) (*models.TaskCriterion, int64, error) {
+	if err := validateTaskMutation(ctx, db, agentName, requestID, taskID); err != nil {
+		return nil, 0, err
+	}
+	return store.AddTaskCriterionIdempotent(ctx, db, agentName, requestID, taskID, text)
+}
+
+// TaskCriteriaCheckIdempotent marks a criterion done, or not done when done is false.
+//
+//nolint:revive // argument-limit: agent, request, task, criterion, and done flag are all required
+func TaskCriteriaCheckIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string, criterionID int64, done bool) (*models.TaskCriterion, int64, error) {
+	if err := validateTaskMutation(ctx, db, agentName, requestID, taskID); err != nil {
+		return nil, 0, err
+	}
+	if criterionID <= 0 {
+		return nil, 0, errors.New("criterion id is required")
+	}
+	return store.CheckTaskCriterionIdempotent(ctx, db, agentName, requestID, taskID, criterionID, done)
+}
+
+// TaskCriteriaList returns a task's acceptance criteria.
+func TaskCriteriaList(ctx context.Context, db *sql.DB, taskID string) ([]models.TaskCriterion, error) {
+	if err := validateTaskID(taskID); err != nil {
+		return nil, err
+	}
+	return store.ListTaskCriteria(ctx, db, taskID)
+}
+
+// TaskContention reports claim contention across agents over the last sinceDays days.
+func TaskContention(ctx context.Context, db *sql.DB, sinceDays int) (*store.ContentionReport, error) {
+	if sinceDays < 0 {
+		return nil, errors.New("since days must be >= 0")
+	}
+	report, err := store.TaskContentionReport(ctx, db, sinceDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build contention report: %w", err)
+	}
+	return report, nil
+}
+
 // TaskList retrieves all tasks, optionally filtered by status, project, and/or priority.
 // priorityFilter < 0 means no filter.
-func TaskList(db *sql.DB, statusFilter, projectFilter string, priorityFilter int) ([]*models.Task, error) {
-	tasks, err := store.ListTasks(db, statusFilter, projectFilter, priorityFilter)
+func TaskList(ctx context.Context, db *sql.DB, statusFilter, projectFilter string, priorityFilter int) ([]*models.Task, error) {
+	tasks, err := store.ListTasks(ctx, db, statusFilter, projectFilter, priorityFilter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tasks: %w", err)
 	}
@@ -187,6 +451,46 @@ func TaskList(db *sql.DB, statusFilter, projectFilter string, priorityFilter int
 	return tasks, nil
 }
 
+// TaskListByMeta is TaskList narrowed to tasks whose metadata matches every
+// "key=value" pair in metaFilters.
+func TaskListByMeta(ctx context.Context, db *sql.DB, statusFilter, projectFilter string, priorityFilter int, metaFilters []string) ([]*models.Task, error) {
+	filters, err := store.ParseTaskMetaFilters(metaFilters)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := TaskList(ctx, db, statusFilter, projectFilter, priorityFilter)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err = store.FilterTasksByMeta(ctx, db, tasks, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter tasks by metadata: %w", err)
+	}
+	return tasks, nil
+}
+
+// TaskListTagged is TaskListByMeta with tags attached to every task, narrowed
+// to tasks carrying every tag in tags.
+//
+//nolint:revive // argument-limit: mirrors TaskListByMeta plus tags
+func TaskListTagged(ctx context.Context, db *sql.DB, statusFilter, projectFilter string, priorityFilter int, metaFilters, tags []string) (_ []*models.Task, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	tags, err = store.NormalizeTaskTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := TaskListByMeta(ctx, db, statusFilter, projectFilter, priorityFilter, metaFilters)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.AttachTaskTags(ctx, db, tasks); err != nil {
+		return nil, fmt.Errorf("failed to load task tags: %w", err)
+	}
+	return store.FilterTasksByTags(tasks, tags), nil
+}
+
 // TaskCloseResult captures the output of a close operation.
 type TaskCloseResult struct {
 	Task          *models.Task `json:"task"`
@@ -194,38 +498,80 @@ type TaskCloseResult struct {
 	CloseEventID  int64        `json:"close_event_id"`
 }
 
-// resolveCloseOutcome maps outcome alias ("done"/"blocked") to task status.
-// Returns empty string if the outcome is invalid.
-func resolveCloseOutcome(outcome string) string {
-	switch outcome {
-	case "done":
-		return completedStatus
-	case "blocked":
-		return blockedStatus
+// resolveCloseOutcome maps a close outcome to the task status and, for
+// completions, the task outcome: "blocked" blocks the task, and any task
+// outcome (done, partial, wontfix, duplicate, superseded) completes it.
+// Returns an empty status if the outcome is invalid.
+func resolveCloseOutcome(outcome string) (string, models.TaskOutcome) {
+	if outcome == blockedStatus {
+		return blockedStatus, ""
+	}
+	if o := models.TaskOutcome(outcome); o.IsValid() {
+		return completedStatus, o
 	}
-	return ""
+	return "", ""
+}
+
+// TaskCloseOptions holds optional inputs for closing a task.
+type TaskCloseOptions struct {
+	Label         string // stored in the task_closed event metadata
+	BlockedReason string // recorded when the outcome is blocked
+	SupersededBy  string // the replacing task, for superseded or duplicate outcomes
+	IfVersion     *int   // fail with a *store.StaleVersionError unless the task is at this version
+	Strict        bool   // refuse completion while acceptance criteria are unchecked
 }
 
 // TaskCloseIdempotent atomically closes a task (status + summary event),
-// once per request-id. Outcome must be "done" or "blocked".
-func TaskCloseIdempotent(db *sql.DB, agentName, requestID, taskID, outcome, summary, label, blockedReason string) (*TaskCloseResult, error) { //nolint:revive // argument-limit: all params are required close-task inputs; a struct adds boilerplate without clarity
+// once per request-id. Outcome is blocked or a task outcome (done, partial,
+// wontfix, duplicate, superseded).
+func TaskCloseIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, outcome, summary, label, blockedReason string) (*TaskCloseResult, error) { //nolint:revive // argument-limit: all params are required close-task inputs; a struct adds boilerplate without clarity
+	return TaskCloseWithOptionsIdempotent(ctx, db, agentName, requestID, taskID, outcome, summary, TaskCloseOptions{
+		Label:         label,
+		BlockedReason: blockedReason,
+	})
+}
+
+// TaskCloseWithOptionsIdempotent is TaskCloseIdempotent with a superseded_by link.
+//
+//nolint:revive // argument-limit: mirrors TaskCloseIdempotent plus options
+func TaskCloseWithOptionsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, outcome, summary string, opts TaskCloseOptions) (_ *TaskCloseResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	if summary == "" {
 		return nil, errors.New("summary is required")
 	}
 
-	status := resolveCloseOutcome(outcome)
+	status, taskOutcome := resolveCloseOutcome(outcome)
 	if status == "" {
-		return nil, fmt.Errorf("invalid outcome '%s': must be done or blocked", outcome)
+		return nil, fmt.Errorf("invalid outcome '%s': must be blocked or one of done, partial, wontfix, duplicate, superseded", outcome)
+	}
+	if status == completedStatus {
+		if err := store.ValidateTaskOutcome(taskOutcome, opts.SupersededBy); err != nil {
+			return nil, err
+		}
+	} else if opts.SupersededBy != "" {
+		return nil, errors.New("superseded_by applies only to superseded or duplicate outcomes")
 	}
 
-	task, result, err := runTaskMutationWithRetry(db, agentName, requestID, taskID, "task.close", "closed", func(tx *sql.Tx) (store.CloseTaskResult, error) {
+	task, result, err := runTaskMutationWithRetry(ctx, db, agentName, requestID, taskID, "task.close", "closed", func(tx *sql.Tx) (store.CloseTaskResult, error) {
+		if err := checkIfVersion(tx, taskID, opts.IfVersion); err != nil {
+			return store.CloseTaskResult{}, err
+		}
+		if opts.Strict && status == completedStatus {
+			if err := store.RequireCriteriaDoneTx(tx, taskID); err != nil {
+				return store.CloseTaskResult{}, err
+			}
+		}
 		result, err := store.CloseTaskTx(tx, store.CloseTaskParams{
 			AgentName:     agentName,
 			TaskID:        taskID,
 			Status:        status,
 			Summary:       summary,
-			Label:         label,
-			BlockedReason: blockedReason,
+			Label:         opts.Label,
+			BlockedReason: opts.BlockedReason,
+			Outcome:       taskOutcome,
+			SupersededBy:  opts.SupersededBy,
 		})
 		if err != nil {
 			return store.CloseTaskResult{}, err
@@ -243,3 +589,180 @@ func TaskCloseIdempotent(db *sql.DB, agentName, requestID, taskID, outcome, summ
 		CloseEventID:  result.CloseEventID,
 	}, nil
 }
+
+// TaskStats rolls a project's tasks (or all tasks) up by status and outcome.
+func TaskStats(ctx context.Context, db *sql.DB, projectID string) (*store.TaskStats, error) {
+	return store.GetTaskStats(ctx, db, projectID)
+}
+
+// ParseDue parses a deadline: RFC3339 ("2026-03-01T17:00:00Z"), a date
+// ("2026-03-01", due at the end of that UTC day), or a duration from now
+// ("36h", "3d", "2w"). "none" returns nil to clear a deadline.
+func ParseDue(raw string, now time.Time) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	switch strings.ToLower(raw) {
+	case "":
+		return nil, errors.New("due value is required (use \"none\" to clear)")
+	case "none":
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+	if d, err := time.Parse(time.DateOnly, raw); err == nil {
+		t := d.Add(24*time.Hour - time.Second)
+		return &t, nil
+	}
+	if d, err := parseDurationExtended(raw); err == nil && d > 0 {
+		t := now.Add(d).UTC().Truncate(time.Second)
+		return &t, nil
+	}
+	return nil, fmt.Errorf("invalid due %q: use RFC3339, YYYY-MM-DD, a duration like 3d, or none", raw)
+}
+
+// TaskUpdateOptions selects the fields task update changes.
+type TaskUpdateOptions struct {
+	SetDue  bool
+	DueAt   *time.Time // nil clears the deadline
+	SetSize bool
+	Size    models.TaskSize // "" clears the size
+	// SetLease sets the task's default claim lease; a zero Lease clears it.
+	SetLease bool
+	Lease    time.Duration
+	// IfVersion fails the update with a *store.StaleVersionError unless the
+	// task is at this version.
+	IfVersion *int
+}
+
+// TaskUpdateIdempotent applies the selected field changes in one transaction
+// once per (agent_name, request_id). The returned event ID is the last change's.
+func TaskUpdateIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string, opts TaskUpdateOptions) (_ *models.Task, _ int64, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if !opts.SetDue && !opts.SetSize && !opts.SetLease {
+		return nil, 0, errors.New("nothing to update")
+	}
+	task, result, err := runTaskMutationWithRetry(ctx, db, agentName, requestID, taskID, "task.update", "updated", func(tx *sql.Tx) (eventResult, error) {
+		if err := checkIfVersion(tx, taskID, opts.IfVersion); err != nil {
+			return eventResult{}, err
+		}
+		var res eventResult
+		if opts.SetDue {
+			eventID, err := store.SetTaskDueTx(tx, agentName, taskID, opts.DueAt)
+			if err != nil {
+				return eventResult{}, err
+			}
+			res.EventID = eventID
+		}
+		if opts.SetSize {
+			eventID, err := store.SetTaskSizeTx(tx, agentName, taskID, opts.Size)
+			if err != nil {
+				return eventResult{}, err
+			}
+			res.EventID = eventID
+		}
+		if opts.SetLease {
+			eventID, err := store.SetTaskDefaultLeaseTx(tx, agentName, taskID, opts.Lease)
+			if err != nil {
+				return eventResult{}, err
+			}
+			res.EventID = eventID
+		}
+		return res, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return task, result.EventID, nil
+}
+
+// ParseTaskSize parses a --size value; "none" and "" clear the size.
+func ParseTaskSize(raw string) (models.TaskSize, error) {
+	size := models.TaskSize(strings.ToLower(strings.TrimSpace(raw)))
+	if size == "" || size == "none" {
+		return "", nil
+	}
+	if !size.IsValid() {
+		return "", fmt.Errorf("invalid size %q (valid: xs, s, m, l, xl)", raw)
+	}
+	return size, nil
+}
+
+// ParseLease parses a claim lease such as 30m, 4h, or 2d; "none" and "" mean
+// no lease (0).
+func ParseLease(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.EqualFold(raw, "none") {
+		return 0, nil
+	}
+	d, err := parseDurationExtended(raw)
+	if err != nil || d < time.Second {
+		return 0, fmt.Errorf("invalid lease %q: use a duration like 30m, 4h, or 2d, or none", raw)
+	}
+	return d, nil
+}
+
+// PlanCapacity picks the pending tasks that fit capacity points in priority order.
+func PlanCapacity(ctx context.Context, db *sql.DB, projectID string, capacity int, defaultSize models.TaskSize) (*store.CapacityPlan, error) {
+	return store.PlanCapacity(ctx, db, projectID, capacity, defaultSize)
+}
+
+// TaskNext returns pending tasks passing filter in pick order, overdue first,
+// with their tags.
+func TaskNext(ctx context.Context, db *sql.DB, projectID string, limit int, filter store.FocusFilter) ([]*models.Task, error) {
+	tasks, err := store.ListNextTasksFiltered(ctx, db, projectID, time.Now(), limit, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.AttachTaskTags(ctx, db, tasks); err != nil {
+		return nil, fmt.Errorf("failed to load task tags: %w", err)
+	}
+	return tasks, nil
+}
+
+// TaskInheritedPriorities explains which of taskIDs rank above their own
+// priority because a higher-priority task waits on them.
+func TaskInheritedPriorities(ctx context.Context, db *sql.DB, taskIDs []string) (map[string]store.InheritedPriority, error) {
+	return store.LoadInheritedPriorities(ctx, db, taskIDs)
+}
+
+// TaskSweepIdempotent emits task_overdue events for newly overdue tasks once per (agent_name, request_id).
+func TaskSweepIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) ([]store.OverdueNotice, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	projectID, err := confineProjectID(ctx, db, agentName, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return store.SweepOverdueIdempotent(ctx, db, agentName, requestID, projectID)
+}
+
+// TaskStale lists in_progress tasks idle for longer than threshold.
+func TaskStale(ctx context.Context, db *sql.DB, agentName, projectID string, threshold time.Duration) ([]store.StaleTask, error) {
+	if threshold <= 0 {
+		return nil, errors.New("threshold must be positive")
+	}
+	projectID, err := ConfineProjectFilter(ctx, db, agentName, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return store.ListStaleTasks(ctx, db, projectID, threshold)
+}
+
+// TaskStaleNotifyIdempotent emits task_stale events for newly stale tasks once per (agent_name, request_id).
+func TaskStaleNotifyIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, threshold time.Duration) ([]store.StaleTask, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	if threshold <= 0 {
+		return nil, errors.New("threshold must be positive")
+	}
+	projectID, err := confineProjectID(ctx, db, agentName, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return store.NotifyStaleTasksIdempotent(ctx, db, agentName, requestID, projectID, threshold)
+}
@@ -0,0 +1,19 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// TaskDelegateIdempotent records that agentName handed taskID to the named
+// sub-agent. The SubagentStart and SubagentStop hooks then attach that
+// sub-agent's lifecycle events to the task, and agentName's briefs list the
+// delegation with the task's current status.
+func TaskDelegateIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, subagent string) (*store.TaskDelegation, int64, error) {
+	if err := validateTaskMutation(ctx, db, agentName, requestID, taskID); err != nil {
+		return nil, 0, err
+	}
+	return store.DelegateTaskIdempotent(ctx, db, agentName, requestID, taskID, subagent)
+}
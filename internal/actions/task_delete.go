@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -9,8 +10,11 @@ import (
 )
 
 // TaskDeleteIdempotent deletes a task and appends a task_deleted event, idempotent on request_id.
-func TaskDeleteIdempotent(db *sql.DB, agentName, requestID, taskID string) (int64, error) {
-	return runDeleteIdempotent(db, deleteParams{
+func TaskDeleteIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string) (int64, error) {
+	if err := CheckConfinedTask(ctx, db, agentName, taskID); err != nil {
+		return 0, err
+	}
+	return runDeleteIdempotent(ctx, db, deleteParams{
 		AgentName:    agentName,
 		RequestID:    requestID,
 		ResourceID:   taskID,
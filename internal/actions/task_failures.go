@@ -0,0 +1,82 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// TaskFailResult is a failed attempt recorded against a task.
+type TaskFailResult struct {
+	Task    *models.Task       `json:"task"`
+	Failure *store.TaskFailure `json:"failure"`
+}
+
+// TaskFailIdempotent records a structured failure on a task: it adds the reason
+// and error class to the task's failure history, counts the attempt, blocks the
+// task as failure-blocked (so resume moves on), and schedules a retry under
+// policy, all in one transaction.
+func TaskFailIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, reason, errorClass string, policy RetryPolicy) (*TaskFailResult, error) {
+	return TaskFailWithOptionsIdempotent(ctx, db, agentName, requestID, taskID, reason, errorClass, TaskFailOptions{Policy: policy})
+}
+
+// TaskFailOptions holds optional inputs for recording a failure.
+type TaskFailOptions struct {
+	Policy    RetryPolicy
+	IfVersion *int // Fail with a *store.StaleVersionError unless the task is at this version
+}
+
+// TaskFailWithOptionsIdempotent is TaskFailIdempotent with a compare-and-set precondition.
+//
+//nolint:revive // argument-limit: mirrors TaskFailIdempotent plus options
+func TaskFailWithOptionsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, reason, errorClass string, opts TaskFailOptions) (_ *TaskFailResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	policy := opts.Policy
+	if strings.TrimSpace(reason) == "" {
+		return nil, errors.New("reason is required")
+	}
+	task, failure, err := runTaskMutationWithRetry(ctx, db, agentName, requestID, taskID, "task.fail", "failed", func(tx *sql.Tx) (*store.TaskFailure, error) {
+		if err := checkIfVersion(tx, taskID, opts.IfVersion); err != nil {
+			return nil, err
+		}
+		version, err := store.GetTaskVersionTx(tx, taskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get task: %w", err)
+		}
+		if _, err := store.UpdateTaskStatusWithEventTx(tx, agentName, taskID, blockedStatus, version); err != nil {
+			return nil, err
+		}
+		if err := store.SetBlockedReasonTx(tx, taskID, models.BlockedReasonFailurePrefix+strings.TrimSpace(reason)); err != nil {
+			return nil, fmt.Errorf("failed to set blocked reason: %w", err)
+		}
+		f, err := store.RecordTaskFailureTx(tx, agentName, taskID, reason, errorClass)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.SetTaskRetryTx(tx, taskID, policy.RetryAt(f.Attempt, time.Now())); err != nil {
+			return nil, err
+		}
+		return f, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TaskFailResult{Task: task, Failure: failure}, nil
+}
+
+// TaskFailures returns a task's failure history, newest first.
+func TaskFailures(ctx context.Context, db *sql.DB, taskID string, limit int) ([]store.TaskFailure, error) {
+	if err := validateTaskID(taskID); err != nil {
+		return nil, err
+	}
+	return store.ListTaskFailures(ctx, db, taskID, limit)
+}
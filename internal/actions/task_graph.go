@@ -0,0 +1,196 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// Task graph output formats.
+const (
+	GraphFormatMermaid = "mermaid"
+	GraphFormatDOT     = "dot"
+	GraphFormatJSON    = "json"
+)
+
+// graphStatusColors are the fill colors per task status, shared by both renderers.
+var graphStatusColors = map[models.TaskStatus]string{
+	models.TaskStatusPending:    "#e5e7eb",
+	models.TaskStatusInProgress: "#93c5fd",
+	models.TaskStatusBlocked:    "#fca5a5",
+	models.TaskStatusCompleted:  "#86efac",
+}
+
+// TaskGraph loads the dependency graph of projectID (all tasks when empty).
+func TaskGraph(ctx context.Context, db *sql.DB, projectID string) (*store.TaskGraph, error) {
+	g, err := store.LoadTaskGraph(ctx, db, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load task graph: %w", err)
+	}
+	return g, nil
+}
+
+// graphNodeLabel is "title", then status, priority, and the holding agents.
+func graphNodeLabel(n store.TaskGraphNode) []string {
+	lines := []string{n.Title, fmt.Sprintf("%s · p%d", n.Status, n.Priority)}
+	if len(n.HeldBy) > 0 {
+		lines = append(lines, "@"+strings.Join(n.HeldBy, ", @"))
+	}
+	if n.External {
+		lines = append(lines, "project: "+n.ProjectID)
+	}
+	return lines
+}
+
+// graphNodeIDs assigns short, syntax-safe node identifiers (n0, n1, ...).
+// Edge endpoints missing from Nodes get an ID too so the edge still renders.
+func graphNodeIDs(g *store.TaskGraph) map[string]string {
+	ids := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		ids[n.ID] = "n" + strconv.Itoa(len(ids))
+	}
+	for _, e := range g.Edges {
+		for _, id := range []string{e.DependsOn, e.TaskID} {
+			if _, ok := ids[id]; !ok {
+				ids[id] = "n" + strconv.Itoa(len(ids))
+			}
+		}
+	}
+	return ids
+}
+
+// RenderTaskGraphMermaid renders g as a Mermaid flowchart. Arrows point from a
+// dependency to the task waiting on it; nodes are colored by status.
+func RenderTaskGraphMermaid(g *store.TaskGraph) string {
+	ids := graphNodeIDs(g)
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, status := range taskStatusOptions() {
+		fmt.Fprintf(&b, "  classDef %s fill:%s,stroke:#374151\n", status, graphStatusColors[models.TaskStatus(status)])
+	}
+	b.WriteString("  classDef external stroke-dasharray:4 3\n")
+
+	known := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		known[n.ID] = true
+		lines := graphNodeLabel(n)
+		for i, l := range lines {
+			lines[i] = mermaidEscape(l)
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]:::%s\n", ids[n.ID], strings.Join(lines, "<br/>"), n.Status)
+		if n.External {
+			fmt.Fprintf(&b, "  class %s external\n", ids[n.ID])
+		}
+	}
+	for _, e := range g.Edges {
+		for _, id := range []string{e.DependsOn, e.TaskID} {
+			if !known[id] {
+				known[id] = true
+				fmt.Fprintf(&b, "  %s[\"%s (missing)\"]\n", ids[id], mermaidEscape(id))
+			}
+		}
+		fmt.Fprintf(&b, "  %s --> %s\n", ids[e.DependsOn], ids[e.TaskID])
+	}
+	return b.String()
+}
+
+// mermaidEscape makes s safe inside a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ").Replace(s)
+}
+
+// RenderTaskGraphDOT renders g as a Graphviz digraph with the same conventions
+// as RenderTaskGraphMermaid.
+func RenderTaskGraphDOT(g *store.TaskGraph) string {
+	ids := graphNodeIDs(g)
+	var b strings.Builder
+	b.WriteString("digraph tasks {\n")
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+
+	known := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		known[n.ID] = true
+		lines := graphNodeLabel(n)
+		for i, l := range lines {
+			lines[i] = dotEscape(l)
+		}
+		style := ""
+		if n.External {
+			style = `, style="rounded,filled,dashed"`
+		}
+		fmt.Fprintf(&b, "  %s [label=\"%s\", tooltip=\"%s\", fillcolor=\"%s\"%s];\n",
+			ids[n.ID], strings.Join(lines, `\n`), dotEscape(n.ID), graphStatusColors[n.Status], style)
+	}
+	for _, e := range g.Edges {
+		for _, id := range []string{e.DependsOn, e.TaskID} {
+			if !known[id] {
+				known[id] = true
+				fmt.Fprintf(&b, "  %s [label=\"%s (missing)\", fillcolor=\"white\", style=dotted];\n", ids[id], dotEscape(id))
+			}
+		}
+		fmt.Fprintf(&b, "  %s -> %s;\n", ids[e.DependsOn], ids[e.TaskID])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotEscape makes s safe inside a quoted DOT string.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s)
+}
+
+// TaskAddDepIdempotent records that taskID waits on dependsOn, rejecting edges
+// that would create a cycle.
+func TaskAddDepIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, dependsOn string) (*store.AddDependencyResult, error) {
+	if err := validateTaskMutation(ctx, db, agentName, requestID, taskID); err != nil {
+		return nil, err
+	}
+	if dependsOn == "" {
+		return nil, errors.New("depends-on task ID is required")
+	}
+	if err := CheckConfinedTask(ctx, db, agentName, dependsOn); err != nil {
+		return nil, err
+	}
+	return store.AddTaskDependencyIdempotent(ctx, db, agentName, requestID, taskID, dependsOn)
+}
+
+// TaskGraphValidate reports self-edges, orphaned edges, and cycles in the
+// dependency graph of projectID (all tasks when empty).
+func TaskGraphValidate(ctx context.Context, db *sql.DB, projectID string) (*store.TaskGraphValidation, error) {
+	v, err := store.ValidateTaskGraph(ctx, db, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate task graph: %w", err)
+	}
+	return v, nil
+}
+
+// TaskGraphRepairIdempotent deletes the edges TaskGraphValidate reports.
+func TaskGraphRepairIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) (*store.TaskGraphValidation, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	projectID, err := confineProjectID(ctx, db, agentName, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return store.RepairTaskGraphIdempotent(ctx, db, agentName, requestID, projectID)
+}
+
+// TaskSuggestDeps proposes dependency edges for taskID from title similarity
+// and file overlap with the other open tasks of its project.
+func TaskSuggestDeps(ctx context.Context, db *sql.DB, taskID string, minConfidence float64, limit int) (*store.DepSuggestReport, error) {
+	if err := validateTaskID(taskID); err != nil {
+		return nil, err
+	}
+	if minConfidence < 0 || minConfidence > 1 {
+		return nil, fmt.Errorf("min confidence must be between 0 and 1, got %g", minConfidence)
+	}
+	return store.SuggestTaskDependencies(ctx, db, taskID, store.DepSuggestOptions{MinConfidence: minConfidence, Limit: limit})
+}
@@ -0,0 +1,50 @@
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestTaskGraph_RendersStatusHoldersAndExternalDeps(t *testing.T) {
+	db, _ := setupTestDBWithCleanup(t)
+
+	p1, err := store.CreateProject(context.Background(), db, "One", "")
+	require.NoError(t, err)
+	p2, err := store.CreateProject(context.Background(), db, "Two", "")
+	require.NoError(t, err)
+
+	schema, err := store.CreateTask(context.Background(), db, "Design \"schema\"", "", p1.ID, 1)
+	require.NoError(t, err)
+	api, err := store.CreateTask(context.Background(), db, "Build API", "", p1.ID, 2)
+	require.NoError(t, err)
+	lib, err := store.CreateTask(context.Background(), db, "Shared lib", "", p2.ID, 0)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO task_dependencies (task_id, depends_on_task_id) VALUES (?, ?), (?, ?)`,
+		api.ID, schema.ID, api.ID, lib.ID)
+	require.NoError(t, err)
+	_, _, err = store.StartTaskAndFocus(context.Background(), db, "builder", schema.ID)
+	require.NoError(t, err)
+
+	g, err := TaskGraph(context.Background(), db, p1.ID)
+	require.NoError(t, err)
+	require.Len(t, g.Nodes, 3)
+	require.Len(t, g.Edges, 2)
+	assert.Equal(t, []string{"builder"}, g.Nodes[0].HeldBy)
+	assert.True(t, g.Nodes[2].External, "dependency in another project is pulled in")
+
+	mermaid := RenderTaskGraphMermaid(g)
+	assert.Contains(t, mermaid, "flowchart TD")
+	assert.Contains(t, mermaid, `n0["Design #quot;schema#quot;<br/>in_progress · p1<br/>@builder"]:::in_progress`)
+	assert.Contains(t, mermaid, "n0 --> n1")
+	assert.Contains(t, mermaid, "class n2 external")
+
+	dot := RenderTaskGraphDOT(g)
+	assert.Contains(t, dot, `label="Design \"schema\"\nin_progress · p1\n@builder"`)
+	assert.Contains(t, dot, "n2 -> n1;")
+}
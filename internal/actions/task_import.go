@@ -0,0 +1,221 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// Plan file formats accepted by task import.
+const (
+	PlanFormatMarkdown = "md"
+	PlanFormatJSON     = "json"
+)
+
+// planCheckboxPattern matches a markdown task list item: "- [ ] title" or "* [x] title".
+var planCheckboxPattern = regexp.MustCompile(`^[-*+] \[([ xX])\]\s+(.+?)\s*$`)
+
+// planTabWidth is the indentation a tab counts for when nesting list items.
+const planTabWidth = 4
+
+// PlanFormatFor returns the plan format for a file name: json for .json,
+// markdown otherwise.
+func PlanFormatFor(name string) string {
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		return PlanFormatJSON
+	}
+	return PlanFormatMarkdown
+}
+
+// ParsePlan parses a plan in the given format (PlanFormatMarkdown or PlanFormatJSON).
+func ParsePlan(data []byte, format string) ([]store.PlanItem, error) {
+	var items []store.PlanItem
+	var err error
+	switch format {
+	case PlanFormatMarkdown:
+		items, err = ParsePlanMarkdown(data)
+	case PlanFormatJSON:
+		items, err = ParsePlanJSON(data)
+	default:
+		return nil, fmt.Errorf("invalid plan format %q (valid: md, json)", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := store.ValidatePlan(items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ParsePlanMarkdown turns a markdown checklist into plan items. Each "- [ ]"
+// line is a task ("- [x]" is imported completed). An item indented under
+// another is its subtask: the parent depends on it. Other lines indented under
+// an item become its description; everything else is ignored. Keys are
+// positional paths: "1", "1.2", ...
+func ParsePlanMarkdown(data []byte) ([]store.PlanItem, error) {
+	type frame struct {
+		indent int
+		index  int // into items
+		kids   int
+	}
+	var items []store.PlanItem
+	var stack []frame
+	roots := 0
+	last := -1
+	lastIndent := 0
+
+	for _, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(raw, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := planIndent(line)
+		m := planCheckboxPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			if last >= 0 && indent > lastIndent && !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				text := strings.TrimSpace(line)
+				if items[last].Description != "" {
+					items[last].Description += "\n"
+				}
+				items[last].Description += text
+			}
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		var key string
+		if len(stack) == 0 {
+			roots++
+			key = strconv.Itoa(roots)
+		} else {
+			parent := &stack[len(stack)-1]
+			parent.kids++
+			key = items[parent.index].Key + "." + strconv.Itoa(parent.kids)
+			items[parent.index].DependsOn = append(items[parent.index].DependsOn, key)
+		}
+		items = append(items, store.PlanItem{Key: key, Title: m[2], Done: m[1] != " "})
+		last = len(items) - 1
+		lastIndent = indent
+		stack = append(stack, frame{indent: indent, index: last})
+	}
+	return items, nil
+}
+
+func planIndent(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += planTabWidth
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// planJSONTask is one task in a JSON plan. Subtasks nest like indented
+// markdown items: the parent depends on each of them.
+type planJSONTask struct {
+	Key         string         `json:"key"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Priority    int            `json:"priority"`
+	Done        bool           `json:"done"`
+	DependsOn   []string       `json:"depends_on"`
+	Subtasks    []planJSONTask `json:"subtasks"`
+}
+
+// ParsePlanJSON reads {"tasks": [...]} or a bare array of tasks. Items without
+// a key get their positional path ("1", "1.2"), as in markdown plans.
+func ParsePlanJSON(data []byte) ([]store.PlanItem, error) {
+	var tasks []planJSONTask
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := decodePlanJSON(trimmed, &tasks); err != nil {
+			return nil, err
+		}
+	} else {
+		var doc struct {
+			Tasks []planJSONTask `json:"tasks"`
+		}
+		if err := decodePlanJSON(trimmed, &doc); err != nil {
+			return nil, err
+		}
+		tasks = doc.Tasks
+	}
+
+	var items []store.PlanItem
+	var walk func(tasks []planJSONTask, prefix string) []string
+	walk = func(tasks []planJSONTask, prefix string) []string {
+		keys := make([]string, 0, len(tasks))
+		for i, t := range tasks {
+			key := t.Key
+			if key == "" {
+				key = prefix + strconv.Itoa(i+1)
+			}
+			idx := len(items)
+			items = append(items, store.PlanItem{
+				Key: key, Title: strings.TrimSpace(t.Title), Description: t.Description,
+				Priority: t.Priority, Done: t.Done, DependsOn: t.DependsOn,
+			})
+			if len(t.Subtasks) > 0 {
+				kids := walk(t.Subtasks, key+".")
+				items[idx].DependsOn = append(items[idx].DependsOn, kids...)
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	}
+	walk(tasks, "")
+	return items, nil
+}
+
+func decodePlanJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON plan: %w", err)
+	}
+	return nil
+}
+
+// PlanHash identifies a parsed plan: the same items hash the same regardless
+// of formatting, so re-importing an unchanged plan reuses its tasks.
+func PlanHash(items []store.PlanItem) string {
+	data, _ := json.Marshal(items)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// TaskImportIdempotent creates the tasks and dependencies of a parsed plan.
+// Re-importing the same plan into the same project reuses existing tasks.
+func TaskImportIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, items []store.PlanItem) (*store.PlanImportResult, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	projectID, err := confineProjectID(ctx, db, agentName, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errors.New("plan has no tasks")
+	}
+	return store.ImportPlanIdempotent(ctx, db, agentName, requestID, projectID, items, PlanHash(items))
+}
@@ -0,0 +1,87 @@
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestParsePlanMarkdown_NestingAndDescriptions(t *testing.T) {
+	items, err := ParsePlan([]byte(`# Plan
+
+Intro prose is ignored.
+
+- [ ] Ship v2
+  - [x] Write migration
+    Keep it reversible.
+  - [ ] Update docs
+- [ ] Announce
+* [X] Already done
+`), PlanFormatMarkdown)
+	require.NoError(t, err)
+
+	require.Len(t, items, 5)
+	assert.Equal(t, store.PlanItem{Key: "1", Title: "Ship v2", DependsOn: []string{"1.1", "1.2"}}, items[0])
+	assert.Equal(t, store.PlanItem{Key: "1.1", Title: "Write migration", Description: "Keep it reversible.", Done: true}, items[1])
+	assert.Equal(t, "1.2", items[2].Key)
+	assert.Equal(t, "2", items[3].Key)
+	assert.True(t, items[4].Done)
+
+	tabbed, err := ParsePlanMarkdown([]byte("- [ ] Parent\n\t- [ ] Tab-indented child\n"))
+	require.NoError(t, err)
+	require.Len(t, tabbed, 2)
+	assert.Equal(t, []string{"1.1"}, tabbed[0].DependsOn)
+}
+
+func TestParsePlanJSON_KeysSubtasksAndErrors(t *testing.T) {
+	items, err := ParsePlan([]byte(`{"tasks": [
+		{"key": "db", "title": "Migrate", "priority": 3},
+		{"title": "API", "depends_on": ["db"], "subtasks": [{"title": "Handlers"}]}
+	]}`), PlanFormatJSON)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, "db", items[0].Key)
+	assert.Equal(t, 3, items[0].Priority)
+	assert.Equal(t, []string{"db", "2.1"}, items[1].DependsOn)
+	assert.Equal(t, "2.1", items[2].Key)
+
+	_, err = ParsePlan([]byte(`[{"title": "A", "depends_on": ["nope"]}]`), PlanFormatJSON)
+	assert.ErrorContains(t, err, "unknown key")
+	_, err = ParsePlan([]byte(`[{"title": "A", "prio": 1}]`), PlanFormatJSON)
+	assert.ErrorContains(t, err, "invalid JSON plan")
+	_, err = ParsePlan([]byte("just prose\n"), PlanFormatMarkdown)
+	assert.ErrorContains(t, err, "no tasks")
+}
+
+func TestTaskImportIdempotent_ReimportReusesTasks(t *testing.T) {
+	db, _ := setupTestDBWithCleanup(t)
+
+	items, err := ParsePlan([]byte("- [ ] Parent\n  - [x] Child\n- [ ] Other\n"), PlanFormatMarkdown)
+	require.NoError(t, err)
+
+	first, err := TaskImportIdempotent(context.Background(), db, "agent1", "import-1", "p1", items)
+	require.NoError(t, err)
+	assert.Equal(t, 3, first.Created)
+	assert.Equal(t, 1, first.Dependencies)
+
+	child, err := store.GetTask(context.Background(), db, first.Tasks[1].TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusCompleted, child.Status)
+	assert.Equal(t, "p1", child.ProjectID)
+
+	again, err := TaskImportIdempotent(context.Background(), db, "agent1", "import-2", "p1", items)
+	require.NoError(t, err)
+	assert.Equal(t, 0, again.Created)
+	assert.Equal(t, 3, again.Existing)
+	assert.Equal(t, first.PlanHash, again.PlanHash)
+	assert.Equal(t, first.Tasks[0].TaskID, again.Tasks[0].TaskID)
+
+	elsewhere, err := TaskImportIdempotent(context.Background(), db, "agent1", "import-3", "p2", items)
+	require.NoError(t, err)
+	assert.Equal(t, 3, elsewhere.Created, "another project gets its own copy")
+}
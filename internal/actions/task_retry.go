@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// RetryPolicy re-queues tasks the loop blocks on failure. The first retry
+// waits After; each later one waits twice as long as the previous. A task
+// that has failed more than MaxRetries times stays blocked.
+type RetryPolicy struct {
+	After      time.Duration // zero disables retries
+	MaxRetries int
+}
+
+// maxRetryBackoffShift caps the doubling so long retry chains cannot overflow.
+const maxRetryBackoffShift = 16
+
+// RetryAt returns when a task that has now failed attempts times should go
+// back to pending, or nil when it should stay blocked.
+func (p RetryPolicy) RetryAt(attempts int, now time.Time) *time.Time {
+	if p.After <= 0 || attempts < 1 || attempts > p.MaxRetries {
+		return nil
+	}
+	at := now.Add(p.After << min(attempts-1, maxRetryBackoffShift))
+	return &at
+}
+
+// TaskRequeueRetriesIdempotent returns failure-blocked tasks whose retry time
+// has passed to pending. projectID, when non-empty, limits the sweep.
+func TaskRequeueRetriesIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) ([]store.RequeuedTask, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	projectID, err := confineProjectID(ctx, db, agentName, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return store.RequeueDueRetriesIdempotent(ctx, db, agentName, requestID, projectID)
+}
@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestRetryPolicy_BacksOffAndGivesUp(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	p := RetryPolicy{After: 30 * time.Minute, MaxRetries: 3}
+
+	require.NotNil(t, p.RetryAt(1, now))
+	assert.Equal(t, now.Add(30*time.Minute), *p.RetryAt(1, now))
+	assert.Equal(t, now.Add(time.Hour), *p.RetryAt(2, now))
+	assert.Equal(t, now.Add(2*time.Hour), *p.RetryAt(3, now))
+	assert.Nil(t, p.RetryAt(4, now), "past max retries the task stays blocked")
+	assert.Nil(t, RetryPolicy{MaxRetries: 3}.RetryAt(1, now), "no backoff disables retries")
+}
+
+func TestTaskFail_CountsAttemptsAndRequeues(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := store.CreateTask(context.Background(), db, "Flaky", "", "", 0)
+	require.NoError(t, err)
+
+	policy := RetryPolicy{After: time.Hour, MaxRetries: 1}
+	res, err := TaskFailIdempotent(context.Background(), db, "loop", "block_1", task.ID, "timed out", store.ErrorClassTimeout, policy)
+	require.NoError(t, err)
+	blocked := res.Task
+	assert.Equal(t, models.TaskStatusBlocked, blocked.Status)
+	assert.Equal(t, models.BlockedReason("failure:timed out"), blocked.BlockedReason)
+	assert.Equal(t, 1, blocked.Attempts)
+	require.NotNil(t, blocked.RetryAt)
+
+	requeued, err := TaskRequeueRetriesIdempotent(context.Background(), db, "loop", "requeue_1", "")
+	require.NoError(t, err)
+	assert.Empty(t, requeued, "retry not due yet")
+
+	_, err = db.Exec(`UPDATE tasks SET retry_at = ? WHERE id = ?`, time.Now().Add(-time.Minute).UTC().Format("2006-01-02 15:04:05"), task.ID)
+	require.NoError(t, err)
+	requeued, err = TaskRequeueRetriesIdempotent(context.Background(), db, "loop", "requeue_2", "")
+	require.NoError(t, err)
+	require.Len(t, requeued, 1)
+	assert.Equal(t, 1, requeued[0].Attempts)
+
+	got, err := store.GetTask(context.Background(), db, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusPending, got.Status)
+	assert.Empty(t, got.BlockedReason)
+	assert.Nil(t, got.RetryAt)
+	assert.Equal(t, 1, got.Attempts, "attempts survive the requeue")
+
+	again, err := TaskFailIdempotent(context.Background(), db, "loop", "block_2", task.ID, "timed out", store.ErrorClassTimeout, policy)
+	require.NoError(t, err)
+	assert.Equal(t, 2, again.Task.Attempts)
+	assert.Nil(t, again.Task.RetryAt, "out of retries")
+}
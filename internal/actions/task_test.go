@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
@@ -25,7 +26,7 @@ func TestTaskCreate(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, eventID, err := TaskCreateIdempotent(db, "test-agent", "req-create-1", "Test Task", "Test Description", "", 0)
+	task, eventID, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-create-1", "Test Task", "Test Description", "", 0)
 	require.NoError(t, err)
 	require.NotNil(t, task)
 	assert.Greater(t, eventID, int64(0))
@@ -41,7 +42,7 @@ func TestTaskCreateEmptyTitle(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, _, err := TaskCreateIdempotent(db, "test-agent", "req-create-empty-1", "", "Description", "", 0)
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-create-empty-1", "", "Description", "", 0)
 	assert.Error(t, err)
 	assert.Nil(t, task)
 	assert.Contains(t, err.Error(), "title is required")
@@ -52,11 +53,11 @@ func TestTaskGet(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	created, _, err := TaskCreateIdempotent(db, "test-agent", "req-get-1", "Get Test", "Description", "", 0)
+	created, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-get-1", "Get Test", "Description", "", 0)
 	require.NoError(t, err)
 
 	// Get the task
-	task, err := TaskGet(db, created.ID)
+	task, err := TaskGet(context.Background(), db, created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, created.ID, task.ID)
 	assert.Equal(t, created.Title, task.Title)
@@ -66,7 +67,7 @@ func TestTaskGetEmptyID(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := TaskGet(db, "")
+	task, err := TaskGet(context.Background(), db, "")
 	assert.Error(t, err)
 	assert.Nil(t, task)
 	assert.Contains(t, err.Error(), "task ID is required")
@@ -77,11 +78,11 @@ func TestTaskSetStatus(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	created, _, err := TaskCreateIdempotent(db, "test-agent", "req-setstatus-create-1", "Status Test", "Description", "", 0)
+	created, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-setstatus-create-1", "Status Test", "Description", "", 0)
 	require.NoError(t, err)
 
 	// Set status
-	task, eventID, err := TaskSetStatusIdempotent(db, "test-agent", "req-setstatus-1", created.ID, "in_progress", "")
+	task, eventID, err := TaskSetStatusIdempotent(context.Background(), db, "test-agent", "req-setstatus-1", created.ID, "in_progress", "")
 	require.NoError(t, err)
 	require.NotNil(t, task)
 	assert.Greater(t, eventID, int64(0))
@@ -102,10 +103,10 @@ func TestTaskSetStatusInvalidStatus(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	created, _, err := TaskCreateIdempotent(db, "test-agent", "req-invalid-status-create-1", "Invalid Status Test", "Description", "", 0)
+	created, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-invalid-status-create-1", "Invalid Status Test", "Description", "", 0)
 	require.NoError(t, err)
 
-	task, eventID, err := TaskSetStatusIdempotent(db, "test-agent", "req-invalid-status-1", created.ID, "invalid_status", "")
+	task, eventID, err := TaskSetStatusIdempotent(context.Background(), db, "test-agent", "req-invalid-status-1", created.ID, "invalid_status", "")
 	assert.Error(t, err)
 	assert.Nil(t, task)
 	assert.Equal(t, int64(0), eventID)
@@ -116,7 +117,7 @@ func TestTaskSetStatusEmptyTaskID(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, eventID, err := TaskSetStatusIdempotent(db, "test-agent", "req-empty-id-1", "", "in_progress", "")
+	task, eventID, err := TaskSetStatusIdempotent(context.Background(), db, "test-agent", "req-empty-id-1", "", "in_progress", "")
 	assert.Error(t, err)
 	assert.Nil(t, task)
 	assert.Equal(t, int64(0), eventID)
@@ -127,10 +128,10 @@ func TestTaskSetStatusEmptyStatus(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	created, _, err := TaskCreateIdempotent(db, "test-agent", "req-empty-status-create-1", "Empty Status Test", "Description", "", 0)
+	created, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-empty-status-create-1", "Empty Status Test", "Description", "", 0)
 	require.NoError(t, err)
 
-	task, eventID, err := TaskSetStatusIdempotent(db, "test-agent", "req-empty-status-1", created.ID, "", "")
+	task, eventID, err := TaskSetStatusIdempotent(context.Background(), db, "test-agent", "req-empty-status-1", created.ID, "", "")
 	assert.Error(t, err)
 	assert.Nil(t, task)
 	assert.Equal(t, int64(0), eventID)
@@ -156,10 +157,10 @@ func TestTaskSetStatusValidTransitions(t *testing.T) {
 			db, cleanup := setupTestDB(t)
 			defer cleanup()
 
-			created, _, err := TaskCreateIdempotent(db, "test-agent", "req-trans-create-"+tt.name, "Transition Test", "Description", "", 0)
+			created, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-trans-create-"+tt.name, "Transition Test", "Description", "", 0)
 			require.NoError(t, err)
 
-			task, eventID, err := TaskSetStatusIdempotent(db, "test-agent", "req-trans-status-"+tt.name, created.ID, tt.status, "")
+			task, eventID, err := TaskSetStatusIdempotent(context.Background(), db, "test-agent", "req-trans-status-"+tt.name, created.ID, tt.status, "")
 
 			if tt.valid {
 				require.NoError(t, err)
@@ -205,17 +206,17 @@ func TestTaskSetStatusConcurrency(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	created, _, err := TaskCreateIdempotent(db, "test-agent", "req-conc-create-1", "Concurrency Test", "Description", "", 0)
+	created, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-conc-create-1", "Concurrency Test", "Description", "", 0)
 	require.NoError(t, err)
 
 	// First status change
-	task1, eventID1, err := TaskSetStatusIdempotent(db, "test-agent", "req-conc-status-1", created.ID, "in_progress", "")
+	task1, eventID1, err := TaskSetStatusIdempotent(context.Background(), db, "test-agent", "req-conc-status-1", created.ID, "in_progress", "")
 	require.NoError(t, err)
 	assert.Equal(t, models.TaskStatusInProgress, task1.Status)
 	assert.Greater(t, eventID1, int64(0))
 
 	// Second status change (should succeed with retry)
-	task2, eventID2, err := TaskSetStatusIdempotent(db, "test-agent", "req-conc-status-2", created.ID, "completed", "")
+	task2, eventID2, err := TaskSetStatusIdempotent(context.Background(), db, "test-agent", "req-conc-status-2", created.ID, "completed", "")
 	require.NoError(t, err)
 	assert.Equal(t, models.TaskStatusCompleted, task2.Status)
 	assert.Equal(t, 3, task2.Version)
@@ -227,28 +228,28 @@ func TestTaskList(t *testing.T) {
 	defer cleanup()
 
 	// Create tasks
-	_, _, err := TaskCreateIdempotent(db, "test-agent", "req-list-create-1", "Task 1", "Desc 1", "", 0)
+	_, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-list-create-1", "Task 1", "Desc 1", "", 0)
 	require.NoError(t, err)
 
-	task2, _, err := TaskCreateIdempotent(db, "test-agent", "req-list-create-2", "Task 2", "Desc 2", "", 0)
+	task2, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-list-create-2", "Task 2", "Desc 2", "", 0)
 	require.NoError(t, err)
 
 	// Change status of task2
-	_, _, err = TaskSetStatusIdempotent(db, "test-agent", "req-list-status-1", task2.ID, "completed", "")
+	_, _, err = TaskSetStatusIdempotent(context.Background(), db, "test-agent", "req-list-status-1", task2.ID, "completed", "")
 	require.NoError(t, err)
 
 	// List all tasks
-	allTasks, err := TaskList(db, "", "", -1)
+	allTasks, err := TaskList(context.Background(), db, "", "", -1)
 	require.NoError(t, err)
 	assert.Len(t, allTasks, 2)
 
 	// List only pending
-	pendingTasks, err := TaskList(db, "pending", "", -1)
+	pendingTasks, err := TaskList(context.Background(), db, "pending", "", -1)
 	require.NoError(t, err)
 	assert.Len(t, pendingTasks, 1)
 
 	// List only completed
-	completedTasks, err := TaskList(db, "completed", "", -1)
+	completedTasks, err := TaskList(context.Background(), db, "completed", "", -1)
 	require.NoError(t, err)
 	assert.Len(t, completedTasks, 1)
 }
@@ -258,16 +259,16 @@ func TestTaskSetStatusAtomicity(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	created, _, err := TaskCreateIdempotent(db, "test-agent", "req-atomic-create-1", "Atomicity Test", "Description", "", 0)
+	created, _, err := TaskCreateIdempotent(context.Background(), db, "test-agent", "req-atomic-create-1", "Atomicity Test", "Description", "", 0)
 	require.NoError(t, err)
 
 	// Update status
-	task, eventID, err := TaskSetStatusIdempotent(db, "test-agent", "req-atomic-status-1", created.ID, "in_progress", "")
+	task, eventID, err := TaskSetStatusIdempotent(context.Background(), db, "test-agent", "req-atomic-status-1", created.ID, "in_progress", "")
 	require.NoError(t, err)
 
 	// Verify both task and event were updated
 	// Check task
-	updatedTask, err := TaskGet(db, created.ID)
+	updatedTask, err := TaskGet(context.Background(), db, created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, task.Status, updatedTask.Status)
 	assert.Equal(t, task.Version, updatedTask.Version)
@@ -278,3 +279,78 @@ func TestTaskSetStatusAtomicity(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, eventMessage, "in_progress")
 }
+
+func TestTaskSetStatusWithOptions_StrictRequiresCriteria(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "agent1", "req_strict_create", "Strict task", "", "", 0)
+	require.NoError(t, err)
+	c, _, err := TaskCriteriaAddIdempotent(context.Background(), db, "agent1", "req_strict_crit", task.ID, "all tests green")
+	require.NoError(t, err)
+
+	_, _, err = TaskSetStatusWithOptionsIdempotent(context.Background(), db, "agent1", "req_strict_done_1", task.ID, "completed", TaskStatusOptions{Strict: true})
+	require.ErrorIs(t, err, store.ErrOpenCriteria)
+
+	_, _, err = TaskCriteriaCheckIdempotent(context.Background(), db, "agent1", "req_strict_check", task.ID, c.ID, true)
+	require.NoError(t, err)
+
+	updated, _, err := TaskSetStatusWithOptionsIdempotent(context.Background(), db, "agent1", "req_strict_done_2", task.ID, "completed", TaskStatusOptions{Strict: true})
+	require.NoError(t, err)
+	require.Equal(t, "completed", string(updated.Status))
+}
+
+func TestTaskCloseWithOptions_StrictRequiresCriteria(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "agent1", "req_strict_close_create", "Strict close", "", "", 0)
+	require.NoError(t, err)
+	c, _, err := TaskCriteriaAddIdempotent(context.Background(), db, "agent1", "req_strict_close_crit", task.ID, "docs updated")
+	require.NoError(t, err)
+
+	_, err = TaskCloseWithOptionsIdempotent(context.Background(), db, "agent1", "req_strict_close_1", task.ID, "done", "shipped", TaskCloseOptions{Strict: true})
+	require.ErrorIs(t, err, store.ErrOpenCriteria)
+	got, err := TaskGet(context.Background(), db, task.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, "completed", string(got.Status))
+
+	_, _, err = TaskCriteriaCheckIdempotent(context.Background(), db, "agent1", "req_strict_close_check", task.ID, c.ID, true)
+	require.NoError(t, err)
+	r, err := TaskCloseWithOptionsIdempotent(context.Background(), db, "agent1", "req_strict_close_2", task.ID, "done", "shipped", TaskCloseOptions{Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, "completed", string(r.Task.Status))
+}
+
+func TestTaskMutations_IfVersionComparesAndSets(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "agent1", "req_cas_create", "CAS task", "", "", 0)
+	require.NoError(t, err)
+	current := task.Version
+
+	stale := current - 1
+	_, _, err = TaskSetStatusWithOptionsIdempotent(context.Background(), db, "agent1", "req_cas_stale", task.ID, "in_progress", TaskStatusOptions{IfVersion: &stale})
+	var staleErr *store.StaleVersionError
+	require.ErrorAs(t, err, &staleErr)
+	assert.Equal(t, current, staleErr.Current)
+	assert.Equal(t, "STALE_VERSION", staleErr.ErrorCode())
+	assert.NotErrorIs(t, err, store.ErrVersionConflict)
+
+	updated, _, err := TaskSetStatusWithOptionsIdempotent(context.Background(), db, "agent1", "req_cas_ok", task.ID, "in_progress", TaskStatusOptions{IfVersion: &current})
+	require.NoError(t, err)
+	assert.Greater(t, updated.Version, current)
+
+	// The version read before the status change is now stale for every mutation.
+	_, err = TaskFailWithOptionsIdempotent(context.Background(), db, "agent1", "req_cas_fail", task.ID, "flaky", "", TaskFailOptions{IfVersion: &current})
+	require.ErrorAs(t, err, &staleErr)
+	_, _, err = TaskUpdateIdempotent(context.Background(), db, "agent1", "req_cas_update", task.ID, TaskUpdateOptions{SetSize: true, Size: models.TaskSizeS, IfVersion: &current})
+	require.ErrorAs(t, err, &staleErr)
+	_, err = TaskCloseWithOptionsIdempotent(context.Background(), db, "agent1", "req_cas_close", task.ID, "done", "finished", TaskCloseOptions{IfVersion: &current})
+	require.ErrorAs(t, err, &staleErr)
+
+	closed, err := TaskCloseWithOptionsIdempotent(context.Background(), db, "agent1", "req_cas_close_ok", task.ID, "done", "finished", TaskCloseOptions{IfVersion: &updated.Version})
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusCompleted, closed.Task.Status)
+}
@@ -0,0 +1,121 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// TaskWaitResult is the task as it stood when it reached a wanted status.
+type TaskWaitResult struct {
+	Task     *models.Task `json:"task"`
+	WaitedMS int64        `json:"waited_ms"`
+}
+
+// TaskWaitTimeoutError reports a task wait that ran out of time before the
+// task reached any of the wanted statuses.
+type TaskWaitTimeoutError struct {
+	TaskID  string
+	Status  models.TaskStatus
+	Until   []models.TaskStatus
+	Timeout time.Duration
+}
+
+func (e *TaskWaitTimeoutError) Error() string {
+	return fmt.Sprintf("task %s is still %s after %s (waiting for %s)", e.TaskID, e.Status, e.Timeout, e.until())
+}
+func (e *TaskWaitTimeoutError) ErrorCode() string { return "WAIT_TIMEOUT" }
+func (e *TaskWaitTimeoutError) Context() map[string]string {
+	return map[string]string{
+		"task_id": e.TaskID,
+		"status":  string(e.Status),
+		"until":   e.until(),
+		"timeout": e.Timeout.String(),
+	}
+}
+func (e *TaskWaitTimeoutError) SuggestedAction() string {
+	return fmt.Sprintf("wait again, or inspect the task with 'vybe task get --id %s'", e.TaskID)
+}
+
+func (e *TaskWaitTimeoutError) until() string {
+	parts := make([]string, len(e.Until))
+	for i, s := range e.Until {
+		parts[i] = string(s)
+	}
+	return strings.Join(parts, ",")
+}
+
+// errTaskWaitReached stops FollowEvents once the task reaches a wanted status.
+var errTaskWaitReached = errors.New("task reached a wanted status")
+
+// TaskWait blocks until the task's status is one of until. It re-reads the
+// task whenever an event on it is committed, following the event stream the
+// way events tail --follow does, so an idle wait costs one pragma per poll.
+// A positive timeout bounds the wait and fails it with *TaskWaitTimeoutError;
+// otherwise it waits until ctx is done and returns ctx.Err().
+func TaskWait(ctx context.Context, db *sql.DB, taskID string, until []models.TaskStatus, timeout time.Duration) (_ *TaskWaitResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if err := validateTaskID(taskID); err != nil {
+		return nil, err
+	}
+	if len(until) == 0 {
+		return nil, errors.New("at least one status to wait for is required")
+	}
+	for _, s := range until {
+		if err := validateTaskStatus(string(s)); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Take the cursor before the first read, so a change committed in
+	// between is still seen as an event.
+	cursor, err := store.LatestEventID(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	task, err := store.GetTask(ctx, db, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	if !slices.Contains(until, task.Status) {
+		params := store.ListEventsParams{TaskID: taskID, SinceID: cursor}
+		err = store.FollowEvents(ctx, db, params, store.FollowOptions{}, func(*models.Event) error {
+			t, err := store.GetTask(ctx, db, taskID)
+			if err != nil {
+				return fmt.Errorf("failed to get task: %w", err)
+			}
+			task = t
+			if slices.Contains(until, task.Status) {
+				return errTaskWaitReached
+			}
+			return nil
+		})
+		switch {
+		case errors.Is(err, errTaskWaitReached):
+		case timeout > 0 && errors.Is(err, context.DeadlineExceeded):
+			return nil, &TaskWaitTimeoutError{TaskID: taskID, Status: task.Status, Until: until, Timeout: timeout}
+		default:
+			return nil, err
+		}
+	}
+
+	return &TaskWaitResult{Task: task, WaitedMS: time.Since(start).Milliseconds()}, nil
+}
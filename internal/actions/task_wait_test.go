@@ -0,0 +1,59 @@
+package actions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestTaskWait_ReturnsWhenAnotherProcessCompletesTask(t *testing.T) {
+	path := t.TempDir() + "/wait.db"
+	db, err := store.InitDBWithPath(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	// A second handle stands in for the sub-agent's vybe process.
+	writer, err := store.InitDBWithPath(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = writer.Close() })
+
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "parent", "req_wait_create", "Sub-agent work", "", "", 0)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _, _ = TaskSetStatusIdempotent(context.Background(), writer, "child", "req_wait_progress", task.ID, "in_progress", "")
+		_, _, _ = TaskSetStatusIdempotent(context.Background(), writer, "child", "req_wait_done", task.ID, "completed", "")
+	}()
+
+	res, err := TaskWait(context.Background(), db, task.ID, []models.TaskStatus{models.TaskStatusCompleted}, 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusCompleted, res.Task.Status)
+
+	// A task already in a wanted status returns at once.
+	res, err = TaskWait(context.Background(), db, task.ID, []models.TaskStatus{models.TaskStatusCompleted}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusCompleted, res.Task.Status)
+}
+
+func TestTaskWait_TimesOutWithCurrentStatus(t *testing.T) {
+	db, _ := setupTestDBWithCleanup(t)
+
+	task, _, err := TaskCreateIdempotent(context.Background(), db, "parent", "req_wait_timeout", "Never finishes", "", "", 0)
+	require.NoError(t, err)
+
+	_, err = TaskWait(context.Background(), db, task.ID, []models.TaskStatus{models.TaskStatusCompleted, models.TaskStatusBlocked}, 50*time.Millisecond)
+	var timeoutErr *TaskWaitTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, models.TaskStatusPending, timeoutErr.Status)
+	assert.Equal(t, "completed,blocked", timeoutErr.Context()["until"])
+
+	_, err = TaskWait(context.Background(), db, task.ID, []models.TaskStatus{"finished"}, time.Second)
+	require.ErrorContains(t, err, "invalid status")
+}
@@ -0,0 +1,220 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// Transcript message limits. User prompts are cut like the prompt hook cuts
+// them, so an ingested prompt matches the hook-recorded one.
+const (
+	transcriptPromptRunes  = 500
+	transcriptSummaryRunes = 500
+	transcriptPreviewRunes = 1000
+)
+
+// TranscriptPlan is a parsed Claude Code transcript, mapped onto events.
+// Unparsed counts lines that were not JSON records.
+type TranscriptPlan struct {
+	SessionID string                  `json:"session_id"`
+	CWD       string                  `json:"cwd,omitempty"`
+	Entries   []store.TranscriptEntry `json:"entries"`
+	Unparsed  int                     `json:"unparsed"`
+
+	metas []map[string]any // per-entry metadata, encoded once the session id is known
+}
+
+type claudeTranscriptRecord struct {
+	Type        string `json:"type"`
+	UUID        string `json:"uuid"`
+	SessionID   string `json:"sessionId"`
+	CWD         string `json:"cwd"`
+	Timestamp   string `json:"timestamp"`
+	IsMeta      bool   `json:"isMeta"`
+	IsSidechain bool   `json:"isSidechain"`
+	Message     struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+type claudeContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+type transcriptToolUse struct {
+	name  string
+	input string
+}
+
+// PlanTranscriptIngest parses a Claude Code JSONL transcript into events:
+// user_prompt for what the user typed, assistant_summary for the assistant's
+// text replies, and tool_success or tool_failure for each tool result. Meta
+// records, sub-agent (sidechain) records, and thinking blocks are left out.
+// sessionID overrides the transcript's own session id.
+func PlanTranscriptIngest(r io.Reader, sessionID string) (*TranscriptPlan, error) {
+	plan := &TranscriptPlan{SessionID: sessionID, Entries: []store.TranscriptEntry{}}
+	tools := map[string]transcriptToolUse{}
+
+	br := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			var rec claudeTranscriptRecord
+			if jerr := json.Unmarshal(trimmed, &rec); jerr != nil {
+				plan.Unparsed++
+			} else {
+				plan.addRecord(&rec, lineNo, tools)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	if plan.SessionID == "" {
+		return nil, errors.New("transcript has no session id; pass --session")
+	}
+	for i, meta := range plan.metas {
+		meta["session_id"] = plan.SessionID
+		raw, err := json.Marshal(meta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode transcript metadata: %w", err)
+		}
+		plan.Entries[i].Metadata = string(raw)
+	}
+	plan.metas = nil
+	return plan, nil
+}
+
+func (p *TranscriptPlan) addRecord(rec *claudeTranscriptRecord, lineNo int, tools map[string]transcriptToolUse) {
+	if rec.Type != "user" && rec.Type != "assistant" {
+		return
+	}
+	if p.SessionID == "" {
+		p.SessionID = rec.SessionID
+	}
+	if p.CWD == "" {
+		p.CWD = rec.CWD
+	}
+	if rec.IsMeta || rec.IsSidechain {
+		return
+	}
+
+	blocks := transcriptBlocks(rec.Message.Content)
+	keyBase := rec.UUID
+	if keyBase == "" {
+		keyBase = "line" + strconv.Itoa(lineNo)
+	}
+	for i, blk := range blocks {
+		key := keyBase + "/" + strconv.Itoa(i)
+		meta := map[string]any{
+			"source":          "transcript",
+			"transcript_uuid": rec.UUID,
+			"transcript_at":   rec.Timestamp,
+		}
+		switch {
+		case blk.Type == "text" && rec.Type == "user":
+			text := strings.TrimSpace(blk.Text)
+			if text == "" {
+				continue
+			}
+			if runes := []rune(text); len(runes) > transcriptPromptRunes {
+				text = string(runes[:transcriptPromptRunes])
+			}
+			p.add(key, models.EventKindUserPrompt, text, meta, store.TranscriptSignature(models.EventKindUserPrompt, text))
+		case blk.Type == "text":
+			text := strings.Join(strings.Fields(blk.Text), " ")
+			if text == "" {
+				continue
+			}
+			text, _ = truncatePromptRunes(text, transcriptSummaryRunes)
+			p.add(key, models.EventKindAssistantSummary, text, meta, "")
+		case blk.Type == "tool_use":
+			tools[blk.ID] = transcriptToolUse{name: blk.Name, input: string(blk.Input)}
+		case blk.Type == "tool_result":
+			use, ok := tools[blk.ToolUseID]
+			if !ok {
+				use.name = "unknown tool"
+			}
+			kind, verb := models.EventKindToolSuccess, "succeeded"
+			if blk.IsError {
+				kind, verb = models.EventKindToolFailure, "failed"
+			}
+			input, _ := truncatePromptRunes(use.input, transcriptPreviewRunes)
+			output, _ := truncatePromptRunes(transcriptResultText(blk.Content), transcriptPreviewRunes)
+			meta["tool_name"] = use.name
+			meta["tool_use_id"] = blk.ToolUseID
+			meta["tool_input_preview"] = input
+			meta["tool_output_preview"] = output
+			if path := store.ToolInputFilePath(use.input); path != "" {
+				meta["file_path"] = path
+			}
+			p.add(key, kind, use.name+" "+verb, meta, store.TranscriptSignature(kind, use.name))
+		}
+	}
+}
+
+func (p *TranscriptPlan) add(key, kind, message string, meta map[string]any, signature string) {
+	p.Entries = append(p.Entries, store.TranscriptEntry{Key: key, Kind: kind, Message: message, Signature: signature})
+	p.metas = append(p.metas, meta)
+}
+
+// transcriptBlocks decodes message content, which is either a plain string or
+// a list of typed blocks.
+func transcriptBlocks(raw json.RawMessage) []claudeContentBlock {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return []claudeContentBlock{{Type: "text", Text: text}}
+	}
+	var blocks []claudeContentBlock
+	_ = json.Unmarshal(raw, &blocks)
+	return blocks
+}
+
+// transcriptResultText flattens a tool result's content to its text.
+func transcriptResultText(raw json.RawMessage) string {
+	var parts []string
+	for _, blk := range transcriptBlocks(raw) {
+		if blk.Text != "" {
+			parts = append(parts, blk.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// TranscriptIngestIdempotent writes plan's entries as events in projectID
+// (see store.IngestTranscriptTx).
+func TranscriptIngestIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, plan *TranscriptPlan) (*store.TranscriptIngestResult, error) {
+	if err := validateAgentRequest(agentName, requestID); err != nil {
+		return nil, err
+	}
+	projectID, err := confineProjectID(ctx, db, agentName, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, errors.New("transcript plan is required")
+	}
+	return store.IngestTranscriptIdempotent(ctx, db, agentName, requestID, plan.SessionID, projectID, plan.Entries)
+}
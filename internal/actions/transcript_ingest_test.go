@@ -0,0 +1,76 @@
+package actions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+const testTranscript = `{"type":"summary","summary":"Fix the build"}
+{"type":"user","uuid":"u1","sessionId":"sess-t","cwd":"/work/repo","timestamp":"2026-01-01T10:00:00Z","message":{"role":"user","content":"fix the build"}}
+{"type":"user","uuid":"u0","sessionId":"sess-t","isMeta":true,"message":{"role":"user","content":"Caveat: local command output"}}
+{"type":"assistant","uuid":"a1","sessionId":"sess-t","message":{"role":"assistant","content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Running   the tests."},{"type":"tool_use","id":"tu1","name":"Bash","input":{"command":"go test ./..."}}]}}
+{"type":"user","uuid":"u2","sessionId":"sess-t","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu1","content":"FAIL","is_error":true}]}}
+{"type":"assistant","uuid":"a2","sessionId":"sess-t","message":{"role":"assistant","content":[{"type":"tool_use","id":"tu2","name":"Edit","input":{}}]}}
+{"type":"user","uuid":"u3","sessionId":"sess-t","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu2","content":[{"type":"text","text":"ok"}]}]}}
+{"type":"assistant","uuid":"a3","sessionId":"sess-t","isSidechain":true,"message":{"role":"assistant","content":[{"type":"text","text":"sub-agent chatter"}]}}
+not json
+`
+
+func TestTranscriptIngest_MapsKindsAndDedupes(t *testing.T) {
+	plan, err := PlanTranscriptIngest(strings.NewReader(testTranscript), "")
+	require.NoError(t, err)
+	assert.Equal(t, "sess-t", plan.SessionID)
+	assert.Equal(t, "/work/repo", plan.CWD)
+	assert.Equal(t, 1, plan.Unparsed)
+
+	kinds := make([]string, len(plan.Entries))
+	for i, e := range plan.Entries {
+		kinds[i] = e.Kind
+	}
+	assert.Equal(t, []string{
+		models.EventKindUserPrompt, models.EventKindAssistantSummary, models.EventKindToolFailure, models.EventKindToolSuccess,
+	}, kinds)
+	assert.Equal(t, "Running the tests.", plan.Entries[1].Message)
+	assert.Equal(t, "Bash failed", plan.Entries[2].Message)
+	assert.Contains(t, plan.Entries[2].Metadata, `"session_id":"sess-t"`)
+	assert.Contains(t, plan.Entries[2].Metadata, `"tool_output_preview":"FAIL"`)
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// The prompt hook already logged the prompt live.
+	hookEventID, err := store.AppendEventWithProjectAndMetadataIdempotent(context.Background(), db, "claude", "hook_prompt_1",
+		models.EventKindUserPrompt, "", "", "fix the build", `{"source":"claude","session_id":"sess-t"}`)
+	require.NoError(t, err)
+
+	res, err := TranscriptIngestIdempotent(context.Background(), db, "claude", "ingest_1", "", plan)
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Entries)
+	assert.Equal(t, 3, res.Ingested)
+	assert.Equal(t, 1, res.Duplicates)
+	assert.Equal(t, map[string]int{
+		models.EventKindAssistantSummary: 1, models.EventKindToolFailure: 1, models.EventKindToolSuccess: 1,
+	}, res.ByKind)
+
+	replay, err := ReplaySession(context.Background(), db, "sess-t", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, replay.Counts[ReplayPrompt])
+	assert.Equal(t, hookEventID, replay.Entries[0].EventID)
+	assert.Equal(t, 2, replay.Counts[ReplayTool])
+
+	// A second run with a new request id writes nothing new.
+	again, err := TranscriptIngestIdempotent(context.Background(), db, "claude", "ingest_2", "", plan)
+	require.NoError(t, err)
+	assert.Equal(t, 0, again.Ingested)
+	assert.Equal(t, 4, again.Skipped)
+
+	_, err = PlanTranscriptIngest(strings.NewReader(`{"type":"user","message":{"content":"hi"}}`), "")
+	require.Error(t, err, "a transcript without a session id needs --session")
+}
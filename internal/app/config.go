@@ -43,4 +43,156 @@ const defaultConfig = `# vybe configuration
 # events_prune_batch: 500
 # events_summarize_threshold: 200
 # events_summarize_keep_recent: 50
+
+# Optional: order of sources used to resolve the agent identity.
+# flag = --agent, env = VYBE_AGENT, file = agent in .vybe.toml, git = hash of git user.email.
+# git is off unless listed. Hooks fall back to their own default (claude, cursor) after these.
+# agent_resolution: [flag, env, file, git]
+
+# Optional: hooks that return in ~1ms and write in a detached background process.
+# Trades a small durability window (a crash before the write lands loses the event)
+# for bounded hook latency. Only write-only hooks qualify:
+# tool-failure, task-completed, checkpoint, session-end. Also: VYBE_HOOK_ASYNC.
+# hook_async: [tool-failure, task-completed]
+
+# Optional: turn hook kinds on or off. A disabled kind's handler exits at once and
+# "vybe hook install" leaves it unregistered (re-run install after changing).
+# Kinds: session_start, prompt, tool_failure, tool_success, pre_tool, checkpoint,
+# task_completed, session_end, subagent_start, subagent_stop. tool_success (every successful tool call) and
+# pre_tool (the guard below) are off by default; all others are on.
+# Example: vybe config set hooks.tool_failure.enabled false
+# hooks:
+#   tool_success:
+#     enabled: true
+
+# Optional: policies for the PreToolUse guard (enable with hooks.pre_tool.enabled).
+# Each policy returns deny or ask for matching tool calls. Rules: command (Bash
+# command matches pattern), edit_outside_project (Write/Edit outside the git
+# repository or working directory), require_focus_task (Write/Edit with no focus
+# task). tools narrows a policy to the named tools. Unset uses the defaults:
+# deny rm -rf, ask for edits outside the project. An empty list disables all.
+# guard:
+#   policies:
+#     - name: no-rm-rf
+#       rule: command
+#       pattern: '\brm\s+-[a-zA-Z]*r[a-zA-Z]*f'
+#       decision: deny
+#     - name: edits-in-project
+#       rule: edit_outside_project
+#       decision: ask
+#     - name: focus-first
+#       rule: require_focus_task
+#       decision: deny
+#       reason: claim a task with vybe resume before editing
+
+# Optional: reject writes that reference unknown tasks, projects, or agents
+# (push --task-id, memory --scope-id, --source-task). Also: VYBE_STRICT_REFS=1.
+# Run "vybe doctor --orphans" to find dangling references already stored.
+# strict_references: true
+
+# Optional: reject push and events add with an event kind that is neither built in
+# nor registered ("vybe events kinds add"), so dashboards see a stable vocabulary.
+# Also: VYBE_STRICT_KINDS=1, or --strict-kinds per command.
+# strict_kinds: true
+
+# Optional: behavior when the database is on a network filesystem (NFS, SMB), where
+# SQLite locking is unreliable. warn (default) logs on every open; lock uses a
+# rollback journal and a single-writer lock file next to the database (commands wait
+# their turn); ignore skips detection. Also: VYBE_NETWORK_FS.
+# network_fs: lock
+
+# Optional: how hooks and --project-dir map a directory to a project. path (default)
+# uses the directory; git uses the normalized origin remote (github.com/owner/repo) so
+# clones in different places, CI checkouts, and teammates share one project. Falls back
+# to the path outside a repo or without a remote. Also: VYBE_PROJECT_IDENTITY.
+# project_identity: git
+
+# Optional: run database maintenance (incremental vacuum, ANALYZE, WAL checkpoint)
+# from the checkpoint hook once the last run is older than this window. Set with
+# "vybe db maintain --schedule 7d"; "off" disables.
+# db_maintain_every: 7d
+
+# Optional: how long replay records of completed requests (--request-id) are kept.
+# memory gc and the checkpoint hook prune older ones; the default is 30d, the floor
+# is 7d so a retried request still replays, and "off" keeps them forever.
+# idempotency_ttl: 14d
+
+# Optional: how long an in_progress task may go without updates or events before the
+# checkpoint hook logs a task_stale event (once per idle stretch). Default 2h; "off"
+# disables. "vybe task stale" lists them on demand.
+# stale_task_after: 2h
+
+# Optional: have the checkpoint hook scan recent progress and tool events for stated
+# facts ("uses Postgres for the queue", "decided to ...", "lint config is .golangci.yml",
+# "server.port = 8080") and stage them as memory proposals. Nothing is written to
+# memory until "vybe memory proposals accept". Run once with "vybe memory proposals extract".
+# extract_facts: true
+
+# Optional: how long a "vybe task claim" holds its task with no activity on it before
+# memory gc (and the checkpoint hook's gc) returns it to pending. Default 1h; "off"
+# leaves claims unleased. task claim --lease, task update --lease, and agent lease
+# override it per claim, per task, and per agent.
+# claim_lease: 1h
+
+# Optional: rolling backups written by "vybe db backup --rolling" (online backup API,
+# safe while agents write). dir defaults to a backups directory next to the database;
+# keep (default 7) is how many are retained. "vybe db restore --at <time>" restores
+# the newest one taken at or before that time.
+# backup:
+#   dir: /mnt/backup/vybe
+#   keep: 14
+
+# Optional: how resume picks the next pending task (vybe config set focus.policy round-robin).
+# priority-first (default), deadline-first, project-affinity, round-robin.
+# Override per call with: vybe resume --policy <name>
+# Set per_session to give each Claude Code session of one agent its own focus
+# (hooks pass the session id; CLI callers use --session or VYBE_SESSION_ID).
+# focus:
+#   policy: priority-first
+#   per_session: true
+
+# Optional: merge each agent's own agent-scoped memory into its briefs, so personal
+# preferences and lessons travel with the agent (per call: resume --include-agent-memory).
+# Briefs also carry the top-ranked lessons (vybe lesson list), capped by max_lessons
+# (default 5; -1 disables).
+# brief:
+#   include_agent_memory: true
+#   max_lessons: 5
+
+# Optional: the shape of session-start context. profile is minimal (task, a little
+# memory and lessons, recent failures), standard (default), or deep-debug (adds task
+# dependencies and a longer previous-session transcript). Override per project with
+# "vybe config set context.profile deep-debug --project <project-id>".
+# context:
+#   profile: standard
+
+# Optional: the LLM behind "vybe hook retrospective --llm". provider is claude (the
+# claude CLI, default), openai (any OpenAI-compatible endpoint; the key is read from
+# the api_key_env variable, default OPENAI_API_KEY), or ollama (default base_url
+# http://localhost:11434). VYBE_DISABLE_EXTERNAL_LLM=1 blocks these calls.
+# retrospective:
+#   provider: ollama
+#   model: llama3.1
+
+# Optional: the environment variable holding the HMAC key of a signed event chain
+# ("vybe events enable-chain --hmac"; default VYBE_AUDIT_KEY). Every writer and
+# "vybe events verify" need the key once the chain is signed.
+# audit:
+#   key_env: VYBE_AUDIT_KEY
+
+# Optional: guardrails on autonomous activity (0 = unlimited). Counted per database,
+# so each workspace is metered on its own; "vybe limits set" overrides them for one
+# workspace. loop, resume, and task claim accept --override-limits.
+# limits:
+#   tasks_per_day: 20
+#   events_per_session: 2000
+#   llm_calls_per_day: 40
+
+# Optional: per-kind event retention (vybe config set retention.tool_success 7d).
+# "default" replaces events_retention_days for archived events; other keys delete
+# events of that kind once older than the window. Per-project overrides live under
+# projects.<project_id>.retention (vybe config set ... --project <project_id>).
+# retention:
+#   default: 30d
+#   tool_success: 7d
 `
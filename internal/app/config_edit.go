@@ -0,0 +1,240 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfigPath returns ~/.config/vybe/config.yaml, the file edited by `vybe config set`.
+func UserConfigPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// SetConfigValue writes value at the dotted key path into the user config file.
+// Comments and unrelated keys are preserved. The result must still decode into
+// Settings (unknown keys are rejected) or nothing is written.
+// projectID, when non-empty, nests the key under projects.<projectID>.
+func SetConfigValue(key, value, projectID string) (string, error) {
+	segments, err := configKeySegments(key, projectID)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := UserConfigPath()
+	if err != nil {
+		return "", err
+	}
+
+	doc, preamble, err := readConfigDocument(path)
+	if err != nil {
+		return "", err
+	}
+
+	setConfigNode(doc.Content[0], segments, value)
+
+	var buf bytes.Buffer
+	buf.Write(preamble)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("encode config: %w", err)
+	}
+
+	if err := validateSettingsYAML(buf.Bytes()); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return path, nil
+}
+
+// GetConfigValue returns the raw scalar stored at the dotted key path in the user
+// config file, and whether it was present.
+func GetConfigValue(key, projectID string) (string, bool, error) {
+	segments, err := configKeySegments(key, projectID)
+	if err != nil {
+		return "", false, err
+	}
+
+	path, err := UserConfigPath()
+	if err != nil {
+		return "", false, err
+	}
+
+	doc, _, err := readConfigDocument(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	node := doc.Content[0]
+	for _, seg := range segments {
+		node = mappingValue(node, seg)
+		if node == nil {
+			return "", false, nil
+		}
+	}
+	if node.Kind != yaml.ScalarNode {
+		return "", false, fmt.Errorf("config key %q is not a scalar", key)
+	}
+	return node.Value, true, nil
+}
+
+func configKeySegments(key, projectID string) ([]string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, errors.New("config key is required")
+	}
+	parts := strings.Split(key, ".")
+	for _, p := range parts {
+		if strings.TrimSpace(p) == "" {
+			return nil, fmt.Errorf("invalid config key %q", key)
+		}
+	}
+	if projectID == "" {
+		return parts, nil
+	}
+	if parts[0] != "retention" && key != "context.profile" {
+		return nil, fmt.Errorf("config key %q cannot be set per project", key)
+	}
+	return append([]string{"projects", projectID}, parts...), nil
+}
+
+// readConfigDocument parses the config file into a node tree rooted at a mapping.
+// A comment-only file (like the generated default) has no mapping to attach
+// comments to, so its text is returned as a preamble to be written back verbatim.
+func readConfigDocument(path string) (doc *yaml.Node, preamble []byte, err error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the fixed user config location
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, err
+	}
+
+	doc = &yaml.Node{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+		return doc, nil, nil
+	}
+
+	if trimmed := bytes.TrimRight(data, "\n"); len(trimmed) > 0 {
+		preamble = append(append(preamble, trimmed...), '\n', '\n')
+	}
+	doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	return doc, preamble, nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setConfigNode(node *yaml.Node, segments []string, value string) {
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		child := mappingValue(node, seg)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: seg}, child)
+		}
+		if last {
+			*child = yaml.Node{Kind: yaml.ScalarNode, Value: value}
+			return
+		}
+		if child.Kind != yaml.MappingNode {
+			*child = yaml.Node{Kind: yaml.MappingNode}
+		}
+		node = child
+	}
+}
+
+func validateSettingsYAML(data []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var s Settings
+	if err := dec.Decode(&s); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if _, err := ParseFocusPolicy(s.Focus.Policy); err != nil {
+		return fmt.Errorf("focus.policy: %w", err)
+	}
+	if raw := strings.TrimSpace(s.DBMaintainEvery); raw != "" && !strings.EqualFold(raw, "off") {
+		if _, err := ParseRetentionDays(raw); err != nil {
+			return fmt.Errorf("db_maintain_every: %w", err)
+		}
+	}
+	if raw := strings.TrimSpace(s.IdempotencyTTL); raw != "" {
+		if _, err := ParseIdempotencyTTL(raw); err != nil {
+			return fmt.Errorf("idempotency_ttl: %w", err)
+		}
+	}
+	if _, err := LookupContextProfile(s.Context.Profile); err != nil {
+		return fmt.Errorf("context.profile: %w", err)
+	}
+	if p := s.Retrospective.Provider; p != "" && !slices.Contains(RetrospectiveProviders(), p) {
+		return fmt.Errorf("retrospective.provider: unknown provider %q (use %s)", p, strings.Join(RetrospectiveProviders(), ", "))
+	}
+	if s.Backup.Keep < 0 {
+		return fmt.Errorf("backup.keep: must be positive, got %d", s.Backup.Keep)
+	}
+	for kind := range s.Hooks {
+		if err := validateHookKind(kind); err != nil {
+			return fmt.Errorf("hooks.%s: %w", kind, err)
+		}
+	}
+	for i, p := range s.Guard.Policies {
+		if err := ValidateGuardPolicy(p); err != nil {
+			return fmt.Errorf("guard.policies[%d]: %w", i, err)
+		}
+	}
+	for kind, raw := range s.Retention {
+		if _, err := ParseRetentionDays(raw); err != nil {
+			return fmt.Errorf("retention.%s: %w", kind, err)
+		}
+	}
+	for projectID, ps := range s.Projects {
+		for kind, raw := range ps.Retention {
+			if _, err := ParseRetentionDays(raw); err != nil {
+				return fmt.Errorf("projects.%s.retention.%s: %w", projectID, kind, err)
+			}
+		}
+		if _, err := LookupContextProfile(ps.Context.Profile); err != nil {
+			return fmt.Errorf("projects.%s.context.profile: %w", projectID, err)
+		}
+	}
+	return nil
+}
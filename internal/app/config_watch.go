@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// reloadStampFileName is touched by `vybe config reload` to ask running processes to reload.
+const reloadStampFileName = "reload.stamp"
+
+// DefaultConfigWatchInterval is how often ConfigWatcher polls for changes.
+const DefaultConfigWatchInterval = 2 * time.Second
+
+// ReloadStampPath returns ~/.config/vybe/reload.stamp.
+func ReloadStampPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, reloadStampFileName), nil
+}
+
+// RequestConfigReload touches the reload stamp so every ConfigWatcher picks up
+// changes on its next poll, even when the config files themselves did not change
+// (e.g. an edit that preserved mtime).
+func RequestConfigReload() (string, error) {
+	path, err := ReloadStampPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(time.Now().UTC().Format(time.RFC3339Nano)+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("write reload stamp: %w", err)
+	}
+	return path, nil
+}
+
+// watchedConfigPaths mirrors the LoadSettings lookup order plus the reload stamp.
+func watchedConfigPaths() []string {
+	paths := []string{}
+	if dir, err := ConfigDir(); err == nil {
+		paths = append(paths,
+			filepath.Join(dir, "config.yaml"),
+			filepath.Join(dir, reloadStampFileName),
+		)
+	}
+	return append(paths,
+		filepath.Join(string(os.PathSeparator), "etc", "vybe", "config.yaml"),
+		"config.yaml",
+	)
+}
+
+// ConfigWatcher polls config file modification times and reloads settings on change.
+// Polling keeps the watcher dependency-free and works on network filesystems where
+// inotify-style events are unreliable.
+type ConfigWatcher struct {
+	Interval time.Duration
+	// OnReload is called after every reload attempt; err is non-nil when the new
+	// config failed to load (the process keeps running on defaults in that case).
+	OnReload func(s Settings, err error)
+
+	paths []string
+	last  map[string]time.Time
+}
+
+// NewConfigWatcher creates a watcher over the standard config locations.
+func NewConfigWatcher(onReload func(Settings, error)) *ConfigWatcher {
+	w := &ConfigWatcher{
+		Interval: DefaultConfigWatchInterval,
+		OnReload: onReload,
+		paths:    watchedConfigPaths(),
+	}
+	w.last = w.snapshot()
+	return w
+}
+
+func (w *ConfigWatcher) snapshot() map[string]time.Time {
+	m := make(map[string]time.Time, len(w.paths))
+	for _, p := range w.paths {
+		if info, err := os.Stat(p); err == nil {
+			m[p] = info.ModTime()
+		}
+	}
+	return m
+}
+
+// Check reloads settings if any watched file was created, modified, or removed
+// since the previous check. It reports whether a reload happened.
+func (w *ConfigWatcher) Check() bool {
+	current := w.snapshot()
+	changed := len(current) != len(w.last)
+	if !changed {
+		for p, t := range current {
+			if prev, ok := w.last[p]; !ok || !prev.Equal(t) {
+				changed = true
+				break
+			}
+		}
+	}
+	w.last = current
+	if !changed {
+		return false
+	}
+	w.Reload()
+	return true
+}
+
+// Reload forces a settings reload regardless of file state.
+func (w *ConfigWatcher) Reload() {
+	s, err := ReloadSettings()
+	if w.OnReload != nil {
+		w.OnReload(s, err)
+	}
+}
+
+// Run polls until ctx is cancelled. A receive on force (e.g. SIGHUP) reloads
+// immediately; force may be nil.
+func (w *ConfigWatcher) Run(ctx context.Context, force <-chan os.Signal) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultConfigWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		case <-force:
+			w.last = w.snapshot()
+			w.Reload()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigWatcher_ReloadsOnConfigChange(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+	require.NoError(t, EnsureConfigDir())
+	require.Equal(t, defaultEventsRetentionDays, EffectiveEventMaintenanceSettings().RetentionDays)
+
+	reloads := 0
+	w := NewConfigWatcher(func(_ Settings, err error) {
+		require.NoError(t, err)
+		reloads++
+	})
+	require.False(t, w.Check(), "no change since the watcher started")
+
+	cfgPath, err := UserConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cfgPath, []byte("events_retention_days: 9\n"), 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(cfgPath, future, future))
+
+	require.True(t, w.Check())
+	require.Equal(t, 1, reloads)
+	require.Equal(t, 9, EffectiveEventMaintenanceSettings().RetentionDays)
+}
+
+func TestConfigWatcher_ReloadStampTriggersReload(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	reloads := 0
+	w := NewConfigWatcher(func(Settings, error) { reloads++ })
+
+	stamp, err := RequestConfigReload()
+	require.NoError(t, err)
+	require.Equal(t, reloadStampFileName, filepath.Base(stamp))
+
+	require.True(t, w.Check())
+	require.Equal(t, 1, reloads)
+}
+
+func TestReloadSettings_KeepsLastGoodConfigOnError(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	require.NoError(t, EnsureConfigDir())
+	cfgPath, err := UserConfigPath()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(cfgPath, []byte("events_retention_days: 9\n"), 0o600))
+	_, err = ReloadSettings()
+	require.NoError(t, err)
+	require.Equal(t, 9, EffectiveEventMaintenanceSettings().RetentionDays)
+
+	// A half-written file fails to parse; the running config must not reset.
+	require.NoError(t, os.WriteFile(cfgPath, []byte("events_retention_days: [9\n"), 0o600))
+	s, err := ReloadSettings()
+	require.Error(t, err)
+	require.Equal(t, 9, s.EventsRetentionDays)
+	require.Equal(t, 9, EffectiveEventMaintenanceSettings().RetentionDays)
+
+	require.NoError(t, os.WriteFile(cfgPath, []byte("events_retention_days: 12\n"), 0o600))
+	_, err = ReloadSettings()
+	require.NoError(t, err)
+	require.Equal(t, 12, EffectiveEventMaintenanceSettings().RetentionDays)
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextProfile selects which sections session-start context carries and how
+// much of each. Limits use -1 for "everything available" and 0 for "leave the
+// section out".
+type ContextProfile struct {
+	Name string
+	// TaskDescriptionRunes cuts the focus task's description; -1 keeps it whole.
+	TaskDescriptionRunes int
+	// Dependencies lists the unfinished tasks the focus task depends on.
+	Dependencies bool
+	// Memory caps relevant memory entries.
+	Memory int
+	// Lessons caps lessons learned; -1 defers to brief.max_lessons.
+	Lessons int
+	// RecentFailures caps failure events (tool failures, blocked tasks).
+	RecentFailures int
+	// History caps each of the other recent-activity sections: user prompts,
+	// events, and prior reasoning.
+	History int
+	// TranscriptRunes caps the previous session's transcript excerpt that the
+	// session-start hook appends; 0 leaves it out.
+	TranscriptRunes int
+	// Budget is the token budget shared by memory, lessons, and history.
+	Budget int
+}
+
+// Built-in context profile names.
+const (
+	// ContextMinimal carries the task and a little memory: for short, focused
+	// sessions where context space matters more than history.
+	ContextMinimal = "minimal"
+	// ContextStandard is the default shape.
+	ContextStandard = "standard"
+	// ContextDeepDebug adds dependencies and a longer transcript excerpt, with
+	// a larger budget, for picking up a stubborn failure.
+	ContextDeepDebug = "deep-debug"
+)
+
+// ContextProfiles lists every built-in profile, smallest first.
+func ContextProfiles() []ContextProfile {
+	return []ContextProfile{
+		{
+			Name:                 ContextMinimal,
+			TaskDescriptionRunes: 300,
+			Memory:               5,
+			Lessons:              2,
+			RecentFailures:       3,
+			Budget:               500,
+		},
+		{
+			Name:                 ContextStandard,
+			TaskDescriptionRunes: -1,
+			Memory:               -1,
+			Lessons:              -1,
+			RecentFailures:       -1,
+			History:              -1,
+			TranscriptRunes:      2000,
+			Budget:               1500,
+		},
+		{
+			Name:                 ContextDeepDebug,
+			TaskDescriptionRunes: -1,
+			Dependencies:         true,
+			Memory:               -1,
+			Lessons:              10,
+			RecentFailures:       -1,
+			History:              -1,
+			TranscriptRunes:      6000,
+			Budget:               4000,
+		},
+	}
+}
+
+// LookupContextProfile returns the named profile. Empty input yields ContextStandard.
+func LookupContextProfile(name string) (ContextProfile, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = ContextStandard
+	}
+	names := make([]string, 0, len(ContextProfiles()))
+	for _, p := range ContextProfiles() {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return ContextProfile{}, fmt.Errorf("unknown context profile %q (valid: %s)", name, strings.Join(names, ", "))
+}
+
+// EffectiveContextProfile returns the profile for projectID: the project's
+// context.profile override, then context.profile, then ContextStandard. An
+// invalid name falls back to ContextStandard.
+func EffectiveContextProfile(projectID string) ContextProfile {
+	standard, _ := LookupContextProfile(ContextStandard)
+	s, err := LoadSettings()
+	if err != nil {
+		return standard
+	}
+	name := s.Context.Profile
+	if projectID != "" {
+		if ps, ok := s.Projects[projectID]; ok && ps.Context.Profile != "" {
+			name = ps.Context.Profile
+		}
+	}
+	p, err := LookupContextProfile(name)
+	if err != nil {
+		return standard
+	}
+	return p
+}
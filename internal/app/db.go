@@ -11,8 +11,9 @@ import (
 // Order of precedence:
 // 1) CLI override (e.g. --db-path)
 // 2) Environment variable: VYBE_DB_PATH
-// 3) config.yaml: db_path
-// 4) Default: ~/.config/vybe/vybe.db
+// 3) Workspace registry: the workspace whose root contains the cwd (see vybe ws)
+// 4) config.yaml: db_path
+// 5) Default: ~/.config/vybe/vybe.db
 // Returns an absolute path to vybe.db and ensures the parent directory exists.
 func GetDBPath() (string, error) {
 	if override := getDBPathOverride(); override != "" {
@@ -23,6 +24,10 @@ func GetDBPath() (string, error) {
 		return EnsureDBDir(envPath)
 	}
 
+	if wsPath, _ := workspaceDBPathForCwd(); wsPath != "" {
+		return EnsureDBDir(wsPath)
+	}
+
 	cfg, err := LoadSettings()
 	if err != nil {
 		return "", fmt.Errorf("failed to load config: %w", err)
@@ -51,6 +56,11 @@ func ResolveDBPathDetailed() (path string, source string, err error) {
 		return resolvedPath, "env(VYBE_DB_PATH)", ensureErr
 	}
 
+	if wsPath, wsName := workspaceDBPathForCwd(); wsPath != "" {
+		resolvedPath, ensureErr := EnsureDBDir(wsPath)
+		return resolvedPath, fmt.Sprintf("workspace(%s)", wsName), ensureErr
+	}
+
 	dir, err := ConfigDir()
 	if err != nil {
 		return "", "", fmt.Errorf("failed to determine config directory: %w", err)
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FocusPolicy selects how resume picks a new pending task once the current focus,
+// assignments, and previously-blocked focus have all been ruled out.
+type FocusPolicy string
+
+// Supported focus policies.
+const (
+	// FocusPriorityFirst picks the highest-priority pending task, oldest first on ties.
+	FocusPriorityFirst FocusPolicy = "priority-first"
+	// FocusDeadlineFirst picks the pending task due soonest; tasks without a
+	// deadline rank after those with one and fall back to priority-first order.
+	FocusDeadlineFirst FocusPolicy = "deadline-first"
+	// FocusProjectAffinity prefers pending tasks in the project the agent last
+	// worked in before looking elsewhere.
+	FocusProjectAffinity FocusPolicy = "project-affinity"
+	// FocusRoundRobin rotates across projects with pending work so one busy
+	// project cannot starve the others.
+	FocusRoundRobin FocusPolicy = "round-robin"
+)
+
+// FocusPolicies lists every supported policy in documentation order.
+func FocusPolicies() []FocusPolicy {
+	return []FocusPolicy{FocusPriorityFirst, FocusDeadlineFirst, FocusProjectAffinity, FocusRoundRobin}
+}
+
+// ParseFocusPolicy validates raw. Empty input yields FocusPriorityFirst.
+func ParseFocusPolicy(raw string) (FocusPolicy, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return FocusPriorityFirst, nil
+	}
+	for _, p := range FocusPolicies() {
+		if string(p) == raw {
+			return p, nil
+		}
+	}
+	names := make([]string, 0, len(FocusPolicies()))
+	for _, p := range FocusPolicies() {
+		names = append(names, string(p))
+	}
+	return "", fmt.Errorf("unknown focus policy %q (valid: %s)", raw, strings.Join(names, ", "))
+}
+
+// EffectiveFocusPolicy returns focus.policy from config, or FocusPriorityFirst
+// when it is unset or invalid.
+func EffectiveFocusPolicy() FocusPolicy {
+	s, err := LoadSettings()
+	if err != nil {
+		return FocusPriorityFirst
+	}
+	p, err := ParseFocusPolicy(s.Focus.Policy)
+	if err != nil {
+		return FocusPriorityFirst
+	}
+	return p
+}
+
+// FocusPolicyFor returns the focus_policy of the .vybe.toml governing dir
+// ("" means the working directory), falling back to EffectiveFocusPolicy.
+func FocusPolicyFor(dir string) FocusPolicy {
+	cfg, err := FindProjectConfig(dir)
+	if err == nil {
+		if p, ok := cfg.FocusPolicy(); ok {
+			return p
+		}
+	}
+	return EffectiveFocusPolicy()
+}
+
+// SessionIDEnv names the session a CLI resume belongs to when --session is not passed.
+const SessionIDEnv = "VYBE_SESSION_ID"
+
+// SessionFocusEnabled reports whether hooks should scope focus to the client
+// session (focus.per_session in config).
+func SessionFocusEnabled() bool {
+	s, err := LoadSettings()
+	if err != nil {
+		return false
+	}
+	return s.Focus.PerSession
+}
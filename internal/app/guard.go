@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Guard policy rules, matched by the PreToolUse guard (vybe hook pre-tool).
+const (
+	// GuardRuleCommand matches Bash commands against Pattern.
+	GuardRuleCommand = "command"
+	// GuardRuleEditOutsideProject matches edits to files outside the project
+	// directory (the enclosing git repository, or the working directory).
+	GuardRuleEditOutsideProject = "edit_outside_project"
+	// GuardRuleRequireFocusTask matches edits made while the agent has no
+	// focus task.
+	GuardRuleRequireFocusTask = "require_focus_task"
+)
+
+// Guard decisions, as Claude Code's permissionDecision values.
+const (
+	GuardDeny = "deny"
+	GuardAsk  = "ask"
+)
+
+// GuardSettings configures the PreToolUse guard. A nil Policies uses
+// DefaultGuardPolicies; an empty list disables every policy.
+type GuardSettings struct {
+	Policies []GuardPolicy `yaml:"policies"`
+}
+
+// GuardPolicy is one guard rule. Tools limits it to the named tools; empty
+// means the rule's default tools (Bash for command, the edit tools otherwise).
+type GuardPolicy struct {
+	Name     string   `yaml:"name"`
+	Rule     string   `yaml:"rule"`
+	Tools    []string `yaml:"tools"`
+	Pattern  string   `yaml:"pattern"`
+	Decision string   `yaml:"decision"`
+	Reason   string   `yaml:"reason"`
+}
+
+// GuardRules lists every policy rule.
+func GuardRules() []string {
+	return []string{GuardRuleCommand, GuardRuleEditOutsideProject, GuardRuleRequireFocusTask}
+}
+
+// DefaultGuardPolicies apply when guard.policies is unset: recursive forced
+// deletes are denied and edits outside the project need confirmation.
+func DefaultGuardPolicies() []GuardPolicy {
+	return []GuardPolicy{
+		{
+			Name:     "no-rm-rf",
+			Rule:     GuardRuleCommand,
+			Pattern:  `\brm\s+(-[a-zA-Z]*[rR][a-zA-Z]*f|-[a-zA-Z]*f[a-zA-Z]*[rR]|-[rR]\s+-f|-f\s+-[rR])`,
+			Decision: GuardDeny,
+			Reason:   "recursive forced delete (rm -rf) is blocked by vybe guard policy",
+		},
+		{
+			Name:     "edits-in-project",
+			Rule:     GuardRuleEditOutsideProject,
+			Decision: GuardAsk,
+			Reason:   "file is outside the project directory",
+		},
+	}
+}
+
+// ValidateGuardPolicy checks a policy's rule, decision, and pattern.
+func ValidateGuardPolicy(p GuardPolicy) error {
+	rule := p.Rule
+	if rule == "" {
+		rule = GuardRuleCommand
+	}
+	if !slices.Contains(GuardRules(), rule) {
+		return fmt.Errorf("unknown rule %q (valid: %s)", p.Rule, strings.Join(GuardRules(), ", "))
+	}
+	if p.Decision != GuardDeny && p.Decision != GuardAsk {
+		return fmt.Errorf("decision must be %s or %s, got %q", GuardDeny, GuardAsk, p.Decision)
+	}
+	if rule == GuardRuleCommand {
+		if p.Pattern == "" {
+			return fmt.Errorf("rule %s needs a pattern", GuardRuleCommand)
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// EffectiveGuardPolicies returns guard.policies from config, or
+// DefaultGuardPolicies when unset. Invalid policies are dropped.
+func EffectiveGuardPolicies() []GuardPolicy {
+	s, err := LoadSettings()
+	if err != nil || s.Guard.Policies == nil {
+		return DefaultGuardPolicies()
+	}
+	out := make([]GuardPolicy, 0, len(s.Guard.Policies))
+	for _, p := range s.Guard.Policies {
+		if ValidateGuardPolicy(p) == nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
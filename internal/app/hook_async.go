@@ -0,0 +1,48 @@
+package app
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// HookAsyncEnv overrides hook_async from config: a comma-separated list of hook
+// kinds, "all" for every write-behind capable kind, or "none" to disable.
+const HookAsyncEnv = "VYBE_HOOK_ASYNC"
+
+// WriteBehindHookKinds are the hook handlers that write nothing to stdout and can
+// therefore acknowledge the hook before their database write completes.
+func WriteBehindHookKinds() []string {
+	return []string{"tool-failure", "tool-success", "task-completed", "checkpoint", "session-end"}
+}
+
+// EffectiveHookAsyncKinds returns the hook kinds configured for write-behind,
+// dropping unknown kinds and kinds that must produce output synchronously.
+func EffectiveHookAsyncKinds() []string {
+	var raw []string
+	if env, ok := os.LookupEnv(HookAsyncEnv); ok {
+		raw = strings.Split(env, ",")
+	} else if s, err := LoadSettings(); err == nil {
+		raw = s.HookAsync
+	}
+
+	capable := WriteBehindHookKinds()
+	out := make([]string, 0, len(capable))
+	for _, k := range raw {
+		k = strings.ToLower(strings.TrimSpace(k))
+		switch {
+		case k == "none":
+			return nil
+		case k == "all":
+			return capable
+		case slices.Contains(capable, k) && !slices.Contains(out, k):
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// HookWriteBehindEnabled reports whether the hook kind should run in write-behind mode.
+func HookWriteBehindEnabled(kind string) bool {
+	return slices.Contains(EffectiveHookAsyncKinds(), kind)
+}
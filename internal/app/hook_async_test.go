@@ -0,0 +1,49 @@
+package app
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveHookAsyncKinds_ConfigAndEnv(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	t.Setenv(HookAsyncEnv, "")
+	require.NoError(t, os.Unsetenv(HookAsyncEnv))
+	require.NoError(t, os.WriteFile("config.yaml", []byte("hook_async: [tool-failure, session-start, bogus]\n"), 0o600))
+
+	// session-start must write its context to stdout, so it is never write-behind.
+	require.Equal(t, []string{"tool-failure"}, EffectiveHookAsyncKinds())
+	require.True(t, HookWriteBehindEnabled("tool-failure"))
+	require.False(t, HookWriteBehindEnabled("session-start"))
+
+	t.Setenv(HookAsyncEnv, "all")
+	require.Equal(t, WriteBehindHookKinds(), EffectiveHookAsyncKinds())
+
+	t.Setenv(HookAsyncEnv, "none")
+	require.Empty(t, EffectiveHookAsyncKinds())
+}
+
+func TestHookKindEnabled_DefaultsAndConfig(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	require.True(t, HookKindEnabled(HookToolFailure))
+	require.False(t, HookKindEnabled(HookToolSuccess), "tool_success is opt-in")
+
+	resetSettingsStateForTest()
+	require.NoError(t, os.WriteFile("config.yaml", []byte("hooks:\n  tool_failure:\n    enabled: false\n  tool_success:\n    enabled: true\n"), 0o600))
+	require.False(t, HookKindEnabled(HookToolFailure))
+	require.True(t, HookKindEnabled(HookToolSuccess))
+	require.True(t, HookKindEnabled(HookPrompt))
+
+	require.NoError(t, validateSettingsYAML([]byte("hooks:\n  tool_success:\n    enabled: false\n")))
+	require.Error(t, validateSettingsYAML([]byte("hooks:\n  bogus:\n    enabled: false\n")))
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Hook kinds, configurable as hooks.<kind>.enabled. Each names a `vybe hook`
+// handler, with underscores for dashes.
+const (
+	HookSessionStart  = "session_start"
+	HookPrompt        = "prompt"
+	HookToolFailure   = "tool_failure"
+	HookToolSuccess   = "tool_success"
+	HookPreTool       = "pre_tool"
+	HookCheckpoint    = "checkpoint"
+	HookTaskCompleted = "task_completed"
+	HookSessionEnd    = "session_end"
+	HookSubagentStart = "subagent_start"
+	HookSubagentStop  = "subagent_stop"
+)
+
+// HookSettings configures one hook kind. A nil Enabled keeps the kind's default.
+type HookSettings struct {
+	Enabled *bool `yaml:"enabled"`
+}
+
+// HookKinds lists every configurable hook kind.
+func HookKinds() []string {
+	return []string{
+		HookSessionStart, HookPrompt, HookToolFailure, HookToolSuccess, HookPreTool,
+		HookCheckpoint, HookTaskCompleted, HookSessionEnd, HookSubagentStart, HookSubagentStop,
+	}
+}
+
+// HookKindForHandler maps a `vybe hook` handler name ("tool-failure") to its kind.
+func HookKindForHandler(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+func validateHookKind(kind string) error {
+	if !slices.Contains(HookKinds(), kind) {
+		return fmt.Errorf("unknown hook kind %q (valid: %s)", kind, strings.Join(HookKinds(), ", "))
+	}
+	return nil
+}
+
+// HookKindEnabled reports whether handlers of kind run and installers register
+// them (hooks.<kind>.enabled in config). tool_success and pre_tool run on every
+// tool call, and pre_tool can block it, so both are off unless enabled; every
+// other kind is on unless disabled.
+func HookKindEnabled(kind string) bool {
+	enabled := kind != HookToolSuccess && kind != HookPreTool
+	s, err := LoadSettings()
+	if err != nil {
+		return enabled
+	}
+	if hs, ok := s.Hooks[kind]; ok && hs.Enabled != nil {
+		return *hs.Enabled
+	}
+	return enabled
+}
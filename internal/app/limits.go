@@ -0,0 +1,115 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LimitSettings cap autonomous activity in one workspace database. Zero means
+// unlimited. Counters live in the database, so each workspace is metered on
+// its own.
+type LimitSettings struct {
+	// TasksPerDay caps tasks auto-claimed (picked by resume or task claim)
+	// per UTC day.
+	TasksPerDay int `yaml:"tasks_per_day" json:"tasks_per_day,omitempty"`
+	// EventsPerSession caps events hooks record for one client session.
+	EventsPerSession int `yaml:"events_per_session" json:"events_per_session,omitempty"`
+	// LLMCallsPerDay caps external agent commands spawned by loop per UTC day.
+	LLMCallsPerDay int `yaml:"llm_calls_per_day" json:"llm_calls_per_day,omitempty"`
+}
+
+// overlay returns l with every non-zero field of o applied on top.
+func (l LimitSettings) overlay(o LimitSettings) LimitSettings {
+	if o.TasksPerDay != 0 {
+		l.TasksPerDay = o.TasksPerDay
+	}
+	if o.EventsPerSession != 0 {
+		l.EventsPerSession = o.EventsPerSession
+	}
+	if o.LLMCallsPerDay != 0 {
+		l.LLMCallsPerDay = o.LLMCallsPerDay
+	}
+	return l
+}
+
+// Validate rejects negative limits.
+func (l LimitSettings) Validate() error {
+	if l.TasksPerDay < 0 || l.EventsPerSession < 0 || l.LLMCallsPerDay < 0 {
+		return errors.New("limits must be zero (unlimited) or positive")
+	}
+	return nil
+}
+
+// EffectiveLimits returns the limits for the active database: limits from
+// config.yaml, overridden field by field by the limits of the workspace that
+// owns the database. workspace names that workspace, or is empty.
+func EffectiveLimits() (limits LimitSettings, workspace string) {
+	if s, err := LoadSettings(); err == nil {
+		limits = s.Limits
+	}
+	ws := activeWorkspace()
+	if ws == nil {
+		return limits, ""
+	}
+	if ws.Limits != nil {
+		limits = limits.overlay(*ws.Limits)
+	}
+	return limits, ws.Name
+}
+
+// activeWorkspace returns the workspace whose database is the one commands
+// will open, following GetDBPath's precedence without creating directories.
+func activeWorkspace() *Workspace {
+	reg, err := LoadWorkspaces()
+	if err != nil || len(reg.Workspaces) == 0 {
+		return nil
+	}
+	path := getDBPathOverride()
+	if path == "" {
+		path = os.Getenv("VYBE_DB_PATH")
+	}
+	if path == "" {
+		if _, name := workspaceDBPathForCwd(); name != "" {
+			return reg.Find(name)
+		}
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	for i := range reg.Workspaces {
+		if reg.Workspaces[i].DBPath == abs {
+			return &reg.Workspaces[i]
+		}
+	}
+	return nil
+}
+
+// SetWorkspaceLimits replaces the named workspace's limits. A zero field falls
+// back to the config.yaml value.
+func SetWorkspaceLimits(name string, limits LimitSettings) (*Workspace, error) {
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+	reg, err := LoadWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	ws := reg.Find(name)
+	if ws == nil {
+		return nil, fmt.Errorf("workspace not found: %s", name)
+	}
+	if limits == (LimitSettings{}) {
+		ws.Limits = nil
+	} else {
+		ws.Limits = &limits
+	}
+	out := *ws
+	if err := SaveWorkspaces(reg); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
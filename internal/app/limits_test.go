@@ -0,0 +1,40 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveLimits_WorkspaceOverridesConfig(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	require.NoError(t, EnsureConfigDir())
+	cfgPath, err := UserConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cfgPath, []byte("limits:\n  tasks_per_day: 10\n  llm_calls_per_day: 5\n"), 0o600))
+
+	dbPath := filepath.Join(t.TempDir(), "ws.db")
+	t.Setenv("VYBE_DB_PATH", dbPath)
+	limits, workspace := EffectiveLimits()
+	assert.Empty(t, workspace)
+	assert.Equal(t, LimitSettings{TasksPerDay: 10, LLMCallsPerDay: 5}, limits)
+
+	_, err = AddWorkspace("ci", dbPath)
+	require.NoError(t, err)
+	_, err = SetWorkspaceLimits("ci", LimitSettings{TasksPerDay: 3, EventsPerSession: 100})
+	require.NoError(t, err)
+
+	limits, workspace = EffectiveLimits()
+	assert.Equal(t, "ci", workspace)
+	assert.Equal(t, LimitSettings{TasksPerDay: 3, EventsPerSession: 100, LLMCallsPerDay: 5}, limits)
+
+	_, err = SetWorkspaceLimits("ci", LimitSettings{TasksPerDay: -1})
+	require.Error(t, err)
+}
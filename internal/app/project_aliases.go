@@ -0,0 +1,152 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ProjectAliasesFileName is the alias registry file inside ConfigDir.
+const ProjectAliasesFileName = "project_aliases.json"
+
+// ProjectAlias maps a directory root to an existing project ID, so a repo
+// that moved (or is checked out elsewhere) keeps the project its history is
+// recorded under. The alias covers the root and everything below it.
+type ProjectAlias struct {
+	Path      string `json:"path"`
+	ProjectID string `json:"project_id"`
+}
+
+// ProjectAliasRegistry is the on-disk shape of project_aliases.json.
+type ProjectAliasRegistry struct {
+	Aliases []ProjectAlias `json:"aliases"`
+}
+
+// ProjectAliasesPath returns ~/.config/vybe/project_aliases.json.
+func ProjectAliasesPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ProjectAliasesFileName), nil
+}
+
+// LoadProjectAliases reads the registry. A missing file is an empty registry.
+func LoadProjectAliases() (*ProjectAliasRegistry, error) {
+	path, err := ProjectAliasesPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path) //nolint:gosec // path is derived from the user's config dir
+	if errors.Is(err, os.ErrNotExist) {
+		return &ProjectAliasRegistry{Aliases: []ProjectAlias{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read project aliases: %w", err)
+	}
+	var reg ProjectAliasRegistry
+	if err := json.Unmarshal(b, &reg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if reg.Aliases == nil {
+		reg.Aliases = []ProjectAlias{}
+	}
+	return &reg, nil
+}
+
+// SaveProjectAliases writes the registry atomically, sorted by path.
+func SaveProjectAliases(reg *ProjectAliasRegistry) error {
+	path, err := ProjectAliasesPath()
+	if err != nil {
+		return err
+	}
+	sort.Slice(reg.Aliases, func(i, j int) bool { return reg.Aliases[i].Path < reg.Aliases[j].Path })
+	b, err := json.MarshalIndent(reg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode project aliases: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Match returns the alias whose path contains dir, preferring the deepest path.
+func (r *ProjectAliasRegistry) Match(dir string) *ProjectAlias {
+	dir = canonicalDir(dir)
+	var best *ProjectAlias
+	for i := range r.Aliases {
+		a := &r.Aliases[i]
+		if pathWithin(dir, a.Path) && (best == nil || len(a.Path) > len(best.Path)) {
+			best = a
+		}
+	}
+	return best
+}
+
+// AddProjectAlias maps path (and below) to projectID, replacing any alias
+// already registered for exactly that path.
+func AddProjectAlias(path, projectID string) (*ProjectAlias, error) {
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return nil, errors.New("project id is required")
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, errors.New("path is required")
+	}
+	root := canonicalDir(path)
+
+	reg, err := LoadProjectAliases()
+	if err != nil {
+		return nil, err
+	}
+	reg.Aliases = slices.DeleteFunc(reg.Aliases, func(a ProjectAlias) bool { return a.Path == root })
+	alias := ProjectAlias{Path: root, ProjectID: projectID}
+	reg.Aliases = append(reg.Aliases, alias)
+	if err := SaveProjectAliases(reg); err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+// RemoveProjectAlias deletes the alias registered for exactly path.
+func RemoveProjectAlias(path string) (*ProjectAlias, error) {
+	root := canonicalDir(path)
+	reg, err := LoadProjectAliases()
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(reg.Aliases, func(a ProjectAlias) bool { return a.Path == root })
+	if i < 0 {
+		return nil, fmt.Errorf("project alias not found: %s", root)
+	}
+	removed := reg.Aliases[i]
+	reg.Aliases = slices.Delete(reg.Aliases, i, i+1)
+	if err := SaveProjectAliases(reg); err != nil {
+		return nil, err
+	}
+	return &removed, nil
+}
+
+// ProjectAliasFor returns the alias covering dir, or nil. Registry errors are
+// ignored so a broken project_aliases.json never blocks hooks.
+func ProjectAliasFor(dir string) *ProjectAlias {
+	reg, err := LoadProjectAliases()
+	if err != nil || len(reg.Aliases) == 0 {
+		return nil
+	}
+	return reg.Match(dir)
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectAliases_AddMatchRemove(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	moved := filepath.Join(home, "work", "api")
+	require.NoError(t, os.MkdirAll(filepath.Join(moved, "cmd"), 0o750))
+
+	require.Nil(t, ProjectAliasFor(moved), "no registry file means no alias")
+
+	_, err := AddProjectAlias(moved, "/old/src/api")
+	require.NoError(t, err)
+	alias, err := AddProjectAlias(moved, "/old/src/api-v2")
+	require.NoError(t, err)
+	require.Equal(t, canonicalDir(moved), alias.Path)
+
+	reg, err := LoadProjectAliases()
+	require.NoError(t, err)
+	require.Len(t, reg.Aliases, 1, "re-adding a path replaces its alias")
+
+	got := ProjectAliasFor(filepath.Join(moved, "cmd"))
+	require.NotNil(t, got)
+	require.Equal(t, "/old/src/api-v2", got.ProjectID, "subdirectories follow the alias")
+	require.Nil(t, ProjectAliasFor(moved+"-other"))
+
+	removed, err := RemoveProjectAlias(moved)
+	require.NoError(t, err)
+	require.Equal(t, "/old/src/api-v2", removed.ProjectID)
+	require.Nil(t, ProjectAliasFor(moved))
+
+	_, err = RemoveProjectAlias(moved)
+	require.Error(t, err)
+	_, err = AddProjectAlias(moved, " ")
+	require.Error(t, err)
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProjectConfigFileName is the repo-local config file discovered by walking up from the working directory.
+const ProjectConfigFileName = ".vybe.toml"
+
+// ProjectConfig holds values read from a .vybe.toml file.
+// Keys inside a [table] are flattened as "table.key".
+//
+//	[project]
+//	name = "github.com/acme/api"  # stable project ID, independent of the checkout path
+//	default_priority = 2          # task create priority when --priority is omitted
+//	focus_policy = "deadline-first"
+//
+//	[retention]
+//	default = "30d"
+//	tool_success = "7d"
+type ProjectConfig struct {
+	Path   string
+	Values map[string]string
+}
+
+// Get returns the value for key, or "" when unset.
+func (c *ProjectConfig) Get(key string) string {
+	if c == nil {
+		return ""
+	}
+	return c.Values[key]
+}
+
+// Agent returns the agent name configured in the file, if any.
+func (c *ProjectConfig) Agent() string {
+	return strings.TrimSpace(c.Get("agent"))
+}
+
+// ProjectName returns the [project] name, the stable project ID for the repo.
+func (c *ProjectConfig) ProjectName() string {
+	return strings.TrimSpace(c.Get("project.name"))
+}
+
+// DefaultPriority returns the [project] default_priority and whether it is set
+// to a valid integer.
+func (c *ProjectConfig) DefaultPriority() (int, bool) {
+	raw := strings.TrimSpace(c.Get("project.default_priority"))
+	if raw == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// FocusPolicy returns the [project] focus_policy and whether it is set to a
+// supported policy.
+func (c *ProjectConfig) FocusPolicy() (FocusPolicy, bool) {
+	raw := strings.TrimSpace(c.Get("project.focus_policy"))
+	if raw == "" {
+		return "", false
+	}
+	p, err := ParseFocusPolicy(raw)
+	if err != nil {
+		return "", false
+	}
+	return p, true
+}
+
+// Retention returns the [retention] rules keyed by event kind (or "default"),
+// in the same form as the retention map in config.yaml.
+func (c *ProjectConfig) Retention() map[string]string {
+	rules := map[string]string{}
+	if c == nil {
+		return rules
+	}
+	for key, value := range c.Values {
+		if kind, ok := strings.CutPrefix(key, "retention."); ok {
+			rules[kind] = value
+		}
+	}
+	return rules
+}
+
+// FindProjectConfig walks from startDir up looking for .vybe.toml. The walk
+// stops at the enclosing git root (the first directory holding .git), so a
+// repo never inherits a file from outside it; outside a repo it continues to
+// the filesystem root. Returns (nil, nil) when no file is found.
+func FindProjectConfig(startDir string) (*ProjectConfig, error) {
+	if startDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		startDir = wd
+	}
+
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectConfigFileName)
+		data, err := os.ReadFile(path) //nolint:gosec // G304: fixed filename discovered by walking parent directories
+		if err == nil {
+			values, parseErr := parseProjectConfig(data)
+			if parseErr != nil {
+				return nil, fmt.Errorf("parse %s: %w", path, parseErr)
+			}
+			return &ProjectConfig{Path: path, Values: values}, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return nil, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// parseProjectConfig reads the flat subset of TOML vybe needs: comments, [table]
+// headers, and key = value pairs with quoted strings, numbers, or booleans.
+func parseProjectConfig(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	table := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			// Only an inline comment may follow the header.
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected text after table header", lineNo)
+			}
+			table = strings.TrimSpace(line[1:end])
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", lineNo)
+		}
+
+		value, err := parseProjectConfigValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if table != "" {
+			key = table + "." + key
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func parseProjectConfigValue(raw string) (string, error) {
+	if raw == "" {
+		return "", errors.New("missing value")
+	}
+
+	if quote := raw[0]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(raw[1:], quote)
+		if end < 0 {
+			return "", errors.New("unterminated string")
+		}
+		// Only whitespace or an inline comment may follow the closing quote.
+		if rest := strings.TrimSpace(raw[end+2:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after string", rest)
+		}
+		return raw[1 : end+1], nil
+	}
+
+	// Bare values (numbers, booleans) run until an inline comment.
+	if idx := strings.Index(raw, "#"); idx >= 0 {
+		raw = raw[:idx]
+	}
+	return strings.TrimSpace(raw), nil
+}
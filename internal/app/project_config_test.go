@@ -0,0 +1,106 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProjectConfig_WalksUpToAncestor(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ProjectConfigFileName), []byte(`
+# repo identity
+agent = "builder" # inline comment
+
+[retention]
+tool_success = "7d"
+`), 0o600))
+
+	cfg, err := FindProjectConfig(nested)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	require.Equal(t, filepath.Join(root, ProjectConfigFileName), cfg.Path)
+	require.Equal(t, "builder", cfg.Agent())
+	require.Equal(t, "7d", cfg.Get("retention.tool_success"))
+}
+
+func TestFindProjectConfig_NoneFound(t *testing.T) {
+	cfg, err := FindProjectConfig(t.TempDir())
+	require.NoError(t, err)
+	require.Nil(t, cfg)
+	require.Empty(t, cfg.Agent())
+}
+
+func TestParseProjectConfig_RejectsMalformedLines(t *testing.T) {
+	_, err := parseProjectConfig([]byte("agent \"x\"\n"))
+	require.Error(t, err)
+
+	_, err = parseProjectConfig([]byte("agent = \"unterminated\n"))
+	require.Error(t, err)
+
+	_, err = parseProjectConfig([]byte("[project]\ndefault_priority = \"3\" junk\n"))
+	require.ErrorContains(t, err, "junk")
+
+	values, err := parseProjectConfig([]byte("agent = 'builder'   # comment\n"))
+	require.NoError(t, err)
+	require.Equal(t, "builder", values["agent"])
+}
+
+func TestFindProjectConfig_StopsAtGitRoot(t *testing.T) {
+	outer := t.TempDir()
+	repo := filepath.Join(outer, "repo")
+	nested := filepath.Join(repo, "pkg")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(outer, ProjectConfigFileName), []byte("agent = \"outer\"\n"), 0o600))
+
+	cfg, err := FindProjectConfig(nested)
+	require.NoError(t, err)
+	require.Nil(t, cfg, "a file above the git root does not apply to the repo")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ProjectConfigFileName), []byte("agent = \"inner\"\n"), 0o600))
+	cfg, err = FindProjectConfig(nested)
+	require.NoError(t, err)
+	require.Equal(t, "inner", cfg.Agent())
+}
+
+func TestProjectConfig_ProjectSettings(t *testing.T) {
+	values, err := parseProjectConfig([]byte(`
+[project]  # api
+name = "github.com/acme/api"
+default_priority = 3
+focus_policy = "Deadline-First"
+
+[retention]
+default = "30d"
+tool_success = "7d"
+`))
+	require.NoError(t, err)
+	cfg := &ProjectConfig{Values: values}
+
+	require.Equal(t, "github.com/acme/api", cfg.ProjectName())
+	p, ok := cfg.DefaultPriority()
+	require.True(t, ok)
+	require.Equal(t, 3, p)
+	policy, ok := cfg.FocusPolicy()
+	require.True(t, ok)
+	require.Equal(t, FocusDeadlineFirst, policy)
+	require.Equal(t, map[string]string{"default": "30d", "tool_success": "7d"}, cfg.Retention())
+
+	bad := &ProjectConfig{Values: map[string]string{"project.default_priority": "high", "project.focus_policy": "random"}}
+	_, ok = bad.DefaultPriority()
+	require.False(t, ok)
+	_, ok = bad.FocusPolicy()
+	require.False(t, ok)
+
+	_, err = parseProjectConfig([]byte("[project] name = \"x\"\n"))
+	require.ErrorContains(t, err, "after table header")
+
+	var none *ProjectConfig
+	require.Empty(t, none.ProjectName())
+	require.Empty(t, none.Retention())
+}
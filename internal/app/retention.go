@@ -0,0 +1,103 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RetentionDefaultKey is the retention map key that replaces events_retention_days.
+const RetentionDefaultKey = "default"
+
+// maxRetentionDays matches the clamp applied to events_retention_days.
+const maxRetentionDays = 3650
+
+// RetentionPolicy is the effective retention for one project.
+// DefaultDays applies to archived events; KindDays applies to every event of that kind.
+type RetentionPolicy struct {
+	DefaultDays int            `json:"default_days"`
+	KindDays    map[string]int `json:"kind_days,omitempty"`
+}
+
+// Kinds returns the kinds with explicit rules in sorted order.
+func (p RetentionPolicy) Kinds() []string {
+	kinds := make([]string, 0, len(p.KindDays))
+	for k := range p.KindDays {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// ParseRetentionDays parses a retention window: "7d", "2w", or a bare day count.
+func ParseRetentionDays(raw string) (int, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if s == "" {
+		return 0, fmt.Errorf("retention value is empty")
+	}
+
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(s, "d"):
+		s = strings.TrimSuffix(s, "d")
+	case strings.HasSuffix(s, "w"):
+		s = strings.TrimSuffix(s, "w")
+		multiplier = 7
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid retention %q: expected a positive day count like 7d or 2w", raw)
+	}
+	days := n * multiplier
+	if days > maxRetentionDays {
+		days = maxRetentionDays
+	}
+	return days, nil
+}
+
+// EffectiveRetentionPolicy merges global retention rules with overrides for projectID.
+// Project rules win over global rules for the same kind. Unparseable values are ignored.
+func EffectiveRetentionPolicy(projectID string) RetentionPolicy {
+	return EffectiveRetentionPolicyFor(projectID, nil)
+}
+
+// EffectiveRetentionPolicyFor is EffectiveRetentionPolicy with the [retention]
+// table of a repo's .vybe.toml layered between the global rules and the
+// projects.<id> overrides in config.yaml. cfg may be nil.
+func EffectiveRetentionPolicyFor(projectID string, cfg *ProjectConfig) RetentionPolicy {
+	policy := RetentionPolicy{
+		DefaultDays: EffectiveEventMaintenanceSettings().RetentionDays,
+		KindDays:    map[string]int{},
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		s = Settings{}
+	}
+
+	apply := func(rules map[string]string) {
+		for kind, raw := range rules {
+			days, err := ParseRetentionDays(raw)
+			if err != nil {
+				continue
+			}
+			kind = strings.TrimSpace(kind)
+			if kind == RetentionDefaultKey {
+				policy.DefaultDays = days
+				continue
+			}
+			policy.KindDays[kind] = days
+		}
+	}
+
+	apply(s.Retention)
+	apply(cfg.Retention())
+	if projectID != "" {
+		if ps, ok := s.Projects[projectID]; ok {
+			apply(ps.Retention)
+		}
+	}
+	return policy
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetentionDays(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"7d", 7, false},
+		{"2w", 14, false},
+		{"30", 30, false},
+		{" 5D ", 5, false},
+		{"99999d", maxRetentionDays, false},
+		{"0d", 0, true},
+		{"-1", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRetentionDays(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEffectiveRetentionPolicy_ProjectOverridesGlobal(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	userConfigPath := filepath.Join(home, ".config", "vybe", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(userConfigPath), 0o755))
+	require.NoError(t, os.WriteFile(userConfigPath, []byte(`
+events_retention_days: 20
+retention:
+  tool_success: 7d
+  user_prompt: 2w
+projects:
+  /work/repo:
+    retention:
+      default: 10d
+      tool_success: 1d
+`), 0o600))
+
+	global := EffectiveRetentionPolicy("")
+	require.Equal(t, 20, global.DefaultDays)
+	require.Equal(t, map[string]int{"tool_success": 7, "user_prompt": 14}, global.KindDays)
+
+	project := EffectiveRetentionPolicy("/work/repo")
+	require.Equal(t, 10, project.DefaultDays)
+	require.Equal(t, map[string]int{"tool_success": 1, "user_prompt": 14}, project.KindDays)
+	require.Equal(t, []string{"tool_success", "user_prompt"}, project.Kinds())
+}
+
+func TestEffectiveRetentionPolicyFor_RepoFileBetweenGlobalAndProject(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	userConfigPath := filepath.Join(home, ".config", "vybe", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(userConfigPath), 0o755))
+	require.NoError(t, os.WriteFile(userConfigPath, []byte(`
+events_retention_days: 20
+retention:
+  tool_success: 7d
+projects:
+  acme:
+    retention:
+      tool_success: 1d
+`), 0o600))
+
+	repo := &ProjectConfig{Values: map[string]string{
+		"retention.default":      "45d",
+		"retention.tool_success": "3d",
+		"retention.user_prompt":  "2w",
+	}}
+
+	other := EffectiveRetentionPolicyFor("other", repo)
+	require.Equal(t, 45, other.DefaultDays)
+	require.Equal(t, map[string]int{"tool_success": 3, "user_prompt": 14}, other.KindDays)
+
+	acme := EffectiveRetentionPolicyFor("acme", repo)
+	require.Equal(t, map[string]int{"tool_success": 1, "user_prompt": 14}, acme.KindDays, "config.yaml project overrides win")
+}
+
+func TestSetConfigValue_PreservesCommentsAndValidates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, EnsureConfigDir())
+
+	path, err := SetConfigValue("retention.tool_success", "7d", "")
+	require.NoError(t, err)
+	_, err = SetConfigValue("retention.tool_success", "3d", "/work/repo")
+	require.NoError(t, err)
+
+	_, err = SetConfigValue("retention.tool_success", "soon", "")
+	require.Error(t, err)
+	_, err = SetConfigValue("not_a_setting", "1", "")
+	require.Error(t, err)
+	_, err = SetConfigValue("db_path", "/tmp/x.db", "/work/repo")
+	require.Error(t, err)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(b), "# vybe configuration")
+
+	s, err := loadSettingsFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "7d", s.Retention["tool_success"])
+	require.Equal(t, "3d", s.Projects["/work/repo"].Retention["tool_success"])
+
+	v, found, err := GetConfigValue("retention.tool_success", "/work/repo")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "3d", v)
+
+	_, found, err = GetConfigValue("retention.user_prompt", "")
+	require.NoError(t, err)
+	require.False(t, found)
+}
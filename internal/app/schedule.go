@@ -0,0 +1,266 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed five-field cron expression (minute hour
+// day-of-month month day-of-week), evaluated in local time.
+type CronSchedule struct {
+	expr                     string
+	minute, hour, dom, month uint64 // bit i set when value i matches
+	dow                      uint64 // 0-6, Sunday = 0
+	domAny, dowAny           bool   // the field was *
+}
+
+// cronMacros are the shorthand schedules accepted in place of five fields.
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseCron parses a standard five-field cron expression. Fields accept *,
+// numbers, ranges (1-5), lists (1,15), and steps (*/15, 9-17/2); day-of-week
+// takes 0-7 with both 0 and 7 meaning Sunday. @hourly, @daily, @midnight,
+// @weekly, and @monthly are also accepted. As in cron, when both day-of-month
+// and day-of-week are restricted a day matching either fires.
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	fields := strings.Fields(expr)
+	if m, ok := cronMacros[strings.ToLower(expr)]; ok {
+		fields = strings.Fields(m)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: want 5 fields (minute hour day-of-month month day-of-week)", expr)
+	}
+
+	s := &CronSchedule{expr: expr}
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: minute: %w", expr, err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: hour: %w", expr, err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day-of-month: %w", expr, err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: month: %w", expr, err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day-of-week: %w", expr, err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+	return s, nil
+}
+
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepRaw, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepRaw)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepRaw)
+			}
+			step = n
+		}
+
+		start, end := lo, hi
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if start, err = cronValue(a, lo, hi); err != nil {
+				return 0, err
+			}
+			if end, err = cronValue(b, lo, hi); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := cronValue(rng, lo, hi)
+			if err != nil {
+				return 0, err
+			}
+			start = v
+			if !hasStep {
+				end = v
+			}
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(raw string, lo, hi int) (int, error) {
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", raw)
+	}
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, lo, hi)
+	}
+	return v, nil
+}
+
+// String returns the expression as given.
+func (s *CronSchedule) String() string {
+	return s.expr
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if !s.domAny && !s.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Next returns the first time after t that the schedule fires, or the zero
+// time when it never fires within five years (e.g. "0 0 31 2 *").
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// TimeWindow is a daily local-time window such as 22:00-06:00. A window whose
+// end is not after its start wraps past midnight.
+type TimeWindow struct {
+	expr       string
+	start, end int // minutes after midnight
+}
+
+// ParseTimeWindow parses "HH:MM-HH:MM".
+func ParseTimeWindow(expr string) (*TimeWindow, error) {
+	expr = strings.TrimSpace(expr)
+	a, b, ok := strings.Cut(expr, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid window %q: want HH:MM-HH:MM", expr)
+	}
+	start, err := parseClock(a)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window %q: %w", expr, err)
+	}
+	end, err := parseClock(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window %q: %w", expr, err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("invalid window %q: start and end are equal", expr)
+	}
+	return &TimeWindow{expr: expr, start: start, end: end}, nil
+}
+
+func parseClock(raw string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(raw))
+	if err != nil {
+		return 0, errors.New("times must be HH:MM (24-hour)")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// String returns the window as given.
+func (w *TimeWindow) String() string {
+	return w.expr
+}
+
+// Contains reports whether t falls inside the window.
+func (w *TimeWindow) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return m >= w.start && m < w.end
+	}
+	return m >= w.start || m < w.end
+}
+
+// clock returns the time on t's date at minutes after midnight.
+func clock(t time.Time, minutes int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), minutes/60, minutes%60, 0, 0, t.Location())
+}
+
+// NextOpen returns t when t is inside the window, otherwise the next time the
+// window opens.
+func (w *TimeWindow) NextOpen(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	open := clock(t, w.start)
+	if !open.After(t) {
+		open = open.AddDate(0, 0, 1)
+	}
+	return open
+}
+
+// CloseAfter returns when the window containing t closes. t must be inside
+// the window.
+func (w *TimeWindow) CloseAfter(t time.Time) time.Time {
+	end := clock(t, w.end)
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// NextRun returns the next time a scheduled run may start at or after now
+// (schedule fires are strictly after now): the schedule's next fire inside
+// window, the window's next opening when there is no schedule, or the next
+// fire when there is no window. It errors when the schedule never fires
+// inside the window.
+func NextRun(schedule *CronSchedule, window *TimeWindow, now time.Time) (time.Time, error) {
+	switch {
+	case schedule == nil && window == nil:
+		return now, nil
+	case schedule == nil:
+		return window.NextOpen(now), nil
+	}
+
+	t := now
+	for range 10000 {
+		t = schedule.Next(t)
+		if t.IsZero() {
+			break
+		}
+		if window == nil || window.Contains(t) {
+			return t, nil
+		}
+	}
+	if window != nil {
+		return time.Time{}, fmt.Errorf("schedule %q never fires inside window %s", schedule, window)
+	}
+	return time.Time{}, fmt.Errorf("schedule %q never fires", schedule)
+}
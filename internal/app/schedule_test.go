@@ -0,0 +1,104 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func at(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseCron_Next(t *testing.T) {
+	cases := []struct {
+		expr, from, want string
+	}{
+		{"0 2 * * *", "2026-03-10 01:59", "2026-03-10 02:00"},
+		{"0 2 * * *", "2026-03-10 02:00", "2026-03-11 02:00"},
+		{"*/15 * * * *", "2026-03-10 10:07", "2026-03-10 10:15"},
+		{"30 9-17/4 * * 1-5", "2026-03-13 18:00", "2026-03-16 09:30"}, // Friday evening -> Monday
+		{"0 0 1 * *", "2026-12-15 00:00", "2027-01-01 00:00"},
+		{"0 0 * * 7", "2026-03-10 00:00", "2026-03-15 00:00"}, // 7 is Sunday
+		{"@hourly", "2026-03-10 10:01", "2026-03-10 11:00"},
+		{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+	}
+	for _, tc := range cases {
+		s, err := ParseCron(tc.expr)
+		require.NoError(t, err, tc.expr)
+		require.Equal(t, at(tc.want), s.Next(at(tc.from)), tc.expr)
+	}
+}
+
+func TestParseCron_DayFieldsAreORedWhenBothRestricted(t *testing.T) {
+	// The 15th, or any Monday.
+	s, err := ParseCron("0 0 15 * 1")
+	require.NoError(t, err)
+	require.Equal(t, at("2026-03-09 00:00"), s.Next(at("2026-03-05 00:00"))) // Monday
+	require.Equal(t, at("2026-03-15 00:00"), s.Next(at("2026-03-09 00:00"))) // Sunday the 15th
+}
+
+func TestParseCron_RejectsInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := ParseCron(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestParseCron_NeverFires(t *testing.T) {
+	s, err := ParseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	require.True(t, s.Next(at("2026-01-01 00:00")).IsZero())
+}
+
+func TestTimeWindow_WrapsMidnight(t *testing.T) {
+	w, err := ParseTimeWindow("22:00-06:00")
+	require.NoError(t, err)
+
+	require.True(t, w.Contains(at("2026-03-10 23:30")))
+	require.True(t, w.Contains(at("2026-03-10 05:59")))
+	require.False(t, w.Contains(at("2026-03-10 06:00")))
+	require.False(t, w.Contains(at("2026-03-10 12:00")))
+
+	require.Equal(t, at("2026-03-10 22:00"), w.NextOpen(at("2026-03-10 12:00")))
+	require.Equal(t, at("2026-03-10 23:30"), w.NextOpen(at("2026-03-10 23:30")))
+	require.Equal(t, at("2026-03-11 06:00"), w.CloseAfter(at("2026-03-10 23:30")))
+	require.Equal(t, at("2026-03-10 06:00"), w.CloseAfter(at("2026-03-10 01:00")))
+}
+
+func TestParseTimeWindow_RejectsInvalid(t *testing.T) {
+	for _, expr := range []string{"", "22:00", "25:00-06:00", "22:00-22:00", "10pm-6am"} {
+		_, err := ParseTimeWindow(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	cron, err := ParseCron("0 * * * *")
+	require.NoError(t, err)
+	window, err := ParseTimeWindow("22:00-06:00")
+	require.NoError(t, err)
+
+	now := at("2026-03-10 12:10")
+	next, err := NextRun(cron, nil, now)
+	require.NoError(t, err)
+	require.Equal(t, at("2026-03-10 13:00"), next)
+
+	next, err = NextRun(nil, window, now)
+	require.NoError(t, err)
+	require.Equal(t, at("2026-03-10 22:00"), next)
+
+	next, err = NextRun(cron, window, now)
+	require.NoError(t, err)
+	require.Equal(t, at("2026-03-10 22:00"), next)
+
+	noon, err := ParseCron("0 12 * * *")
+	require.NoError(t, err)
+	_, err = NextRun(noon, window, now)
+	require.Error(t, err)
+}
@@ -2,9 +2,13 @@ package app
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,6 +22,418 @@ type Settings struct {
 	EventsPruneBatch          int    `yaml:"events_prune_batch"`
 	EventsSummarizeThreshold  int    `yaml:"events_summarize_threshold"`
 	EventsSummarizeKeepRecent int    `yaml:"events_summarize_keep_recent"`
+
+	// AgentResolution orders the sources consulted when no agent is given explicitly.
+	// Valid entries: flag, env, file, git. Empty means DefaultAgentResolution.
+	AgentResolution []string `yaml:"agent_resolution"`
+
+	// HookAsync lists hook kinds that return immediately and write in a detached
+	// background process (write-behind). See HookWriteBehindEnabled.
+	HookAsync []string `yaml:"hook_async"`
+
+	// Hooks turns hook kinds on or off, keyed by kind (see HookKinds). See
+	// HookKindEnabled.
+	Hooks map[string]HookSettings `yaml:"hooks"`
+
+	// Guard configures the policies the PreToolUse guard enforces. See
+	// EffectiveGuardPolicies.
+	Guard GuardSettings `yaml:"guard"`
+
+	// StrictReferences rejects writes whose task, project, or agent references do
+	// not exist instead of storing them silently. See StrictReferencesEnabled.
+	StrictReferences bool `yaml:"strict_references"`
+
+	// StrictKinds rejects agent-written events whose kind is neither built in
+	// nor registered with 'vybe events kinds add'. See StrictKindsEnabled.
+	StrictKinds bool `yaml:"strict_kinds"`
+
+	// NetworkFS sets how vybe behaves when the database lives on a network
+	// filesystem (NFS, SMB). See NetworkFSMode.
+	NetworkFS string `yaml:"network_fs"`
+
+	// ProjectIdentity chooses how a working directory maps to a project ID:
+	// "path" (default) or "git" (normalized remote URL). See ProjectIdentityMode.
+	ProjectIdentity string `yaml:"project_identity"`
+
+	// DBMaintainEvery schedules `db maintain` from the checkpoint hook: it runs
+	// when the last maintenance is older than this window ("7d", "2w"). Empty
+	// or "off" disables. See DBMaintainEveryDays.
+	DBMaintainEvery string `yaml:"db_maintain_every"`
+
+	// IdempotencyTTL is how long replay records of completed requests are kept
+	// before memory gc and the checkpoint hook prune them ("30d", "2w"). Empty
+	// means DefaultIdempotencyTTLDays; "off" keeps them forever. See
+	// IdempotencyTTLDays.
+	IdempotencyTTL string `yaml:"idempotency_ttl"`
+
+	// StaleTaskAfter is how long an in_progress task may go without activity
+	// before the checkpoint hook emits a task_stale event ("2h", "90m"). Empty
+	// means DefaultStaleTaskAfter; "off" disables. See StaleTaskThreshold.
+	StaleTaskAfter string `yaml:"stale_task_after"`
+
+	// ExtractFacts runs the rule-based fact extractor from the checkpoint hook,
+	// staging facts stated in progress and tool events as memory proposals.
+	// See FactExtractionEnabled.
+	ExtractFacts bool `yaml:"extract_facts"`
+
+	// ClaimLease is how long a task claim holds its task without activity
+	// before memory gc and the checkpoint hook return it to pending ("1h",
+	// "45m"). Empty means DefaultClaimLease; "off" leaves claims unleased
+	// unless the claim, task, or agent sets a lease. See ClaimLeaseTTL.
+	ClaimLease string `yaml:"claim_lease"`
+
+	// Backup configures rolling backups written by `db backup --rolling`.
+	Backup BackupSettings `yaml:"backup"`
+
+	// Focus controls how resume selects the next task.
+	Focus FocusSettings `yaml:"focus"`
+
+	// Brief controls what briefs include. See AgentMemoryInBriefs.
+	Brief BriefSettings `yaml:"brief"`
+
+	// Context selects the session-start context profile. See EffectiveContextProfile.
+	Context ContextSettings `yaml:"context"`
+
+	// Retrospective selects the LLM behind `hook retrospective --llm`.
+	Retrospective RetrospectiveSettings `yaml:"retrospective"`
+
+	// Audit configures the tamper-evident event chain. See AuditHMACKey.
+	Audit AuditSettings `yaml:"audit"`
+
+	// Retention maps event kinds to retention windows ("7d", "2w", "30").
+	// The special key "default" overrides events_retention_days for archived events.
+	Retention map[string]string `yaml:"retention"`
+
+	// Limits caps autonomous activity per workspace database. A workspace's
+	// own limits (vybe limits set) override these field by field.
+	Limits LimitSettings `yaml:"limits"`
+
+	// Projects holds per-project overrides keyed by project ID.
+	Projects map[string]ProjectSettings `yaml:"projects"`
+}
+
+// FocusSettings configures focus selection. Policy is one of FocusPolicies().
+// PerSession keys hook-driven resumes by the client session id so concurrent
+// sessions of one agent keep separate focus; see SessionFocusEnabled.
+type FocusSettings struct {
+	Policy     string `yaml:"policy"`
+	PerSession bool   `yaml:"per_session"`
+}
+
+// BriefSettings configures briefs. IncludeAgentMemory merges the agent's own
+// agent-scoped memory into relevant_memory. MaxLessons caps the lessons a brief
+// carries; 0 means DefaultBriefMaxLessons and a negative value disables them.
+type BriefSettings struct {
+	IncludeAgentMemory bool `yaml:"include_agent_memory"`
+	MaxLessons         int  `yaml:"max_lessons"`
+}
+
+// ContextSettings configures session-start context. Profile is the name of one
+// of ContextProfiles(); empty means ContextStandard.
+type ContextSettings struct {
+	Profile string `yaml:"profile"`
+}
+
+// AuditSettings configures the event chain started by 'vybe events enable-chain'.
+// KeyEnv names the environment variable holding the HMAC key of a signed chain
+// (the key itself never lives in config); empty means DefaultAuditKeyEnv.
+type AuditSettings struct {
+	KeyEnv string `yaml:"key_env"`
+}
+
+// DefaultAuditKeyEnv holds the event chain HMAC key when audit.key_env is unset.
+const DefaultAuditKeyEnv = "VYBE_AUDIT_KEY"
+
+// AuditHMACKey returns the event chain HMAC key and the variable it was read
+// from. The key is empty when the variable is unset.
+func AuditHMACKey() (key []byte, envName string) {
+	envName = DefaultAuditKeyEnv
+	if s, err := LoadSettings(); err == nil && strings.TrimSpace(s.Audit.KeyEnv) != "" {
+		envName = strings.TrimSpace(s.Audit.KeyEnv)
+	}
+	return []byte(os.Getenv(envName)), envName
+}
+
+// DefaultBriefMaxLessons is how many lessons a brief carries when brief.max_lessons is unset.
+const DefaultBriefMaxLessons = 5
+
+// RetrospectiveSettings configures the LLM provider for retrospectives.
+// Provider is one of RetrospectiveProviders(); empty means claude. Command is
+// the claude CLI binary, BaseURL the OpenAI-compatible or ollama endpoint, and
+// APIKeyEnv names the environment variable holding the OpenAI-compatible key
+// (the key itself never lives in config).
+type RetrospectiveSettings struct {
+	Provider  string `yaml:"provider"`
+	Model     string `yaml:"model"`
+	Command   string `yaml:"command"`
+	BaseURL   string `yaml:"base_url"`
+	APIKeyEnv string `yaml:"api_key_env"`
+}
+
+// Retrospective LLM providers.
+const (
+	RetrospectiveProviderClaude = "claude"
+	RetrospectiveProviderOpenAI = "openai"
+	RetrospectiveProviderOllama = "ollama"
+)
+
+// RetrospectiveProviders returns the supported retrospective LLM providers.
+func RetrospectiveProviders() []string {
+	return []string{RetrospectiveProviderClaude, RetrospectiveProviderOpenAI, RetrospectiveProviderOllama}
+}
+
+// BackupSettings configures rolling backups. Dir defaults to a backups
+// directory next to the database; Keep defaults to DefaultBackupKeep.
+type BackupSettings struct {
+	Dir  string `yaml:"dir"`
+	Keep int    `yaml:"keep"`
+}
+
+// DefaultBackupKeep is how many rolling backups are kept when backup.keep is unset.
+const DefaultBackupKeep = 7
+
+// ProjectSettings are overrides applied when operating inside a single project.
+type ProjectSettings struct {
+	Retention map[string]string `yaml:"retention"`
+	Context   ContextSettings   `yaml:"context"`
+}
+
+// AgentMemoryInBriefs reports whether briefs include the agent's own
+// agent-scoped memory (brief.include_agent_memory in config).
+func AgentMemoryInBriefs() bool {
+	s, err := LoadSettings()
+	if err != nil {
+		return false
+	}
+	return s.Brief.IncludeAgentMemory
+}
+
+// BriefLessonLimit returns how many lessons a brief carries (brief.max_lessons
+// in config); 0 means none.
+func BriefLessonLimit() int {
+	s, err := LoadSettings()
+	if err != nil || s.Brief.MaxLessons == 0 {
+		return DefaultBriefMaxLessons
+	}
+	return max(s.Brief.MaxLessons, 0)
+}
+
+// StrictReferencesEnv overrides strict_references from config ("1"/"true" or "0"/"false").
+const StrictReferencesEnv = "VYBE_STRICT_REFS"
+
+// StrictReferencesEnabled reports whether writes must reference existing tasks,
+// projects, and agents. The environment variable wins over config.
+func StrictReferencesEnabled() bool {
+	if env, ok := os.LookupEnv(StrictReferencesEnv); ok {
+		if v, err := strconv.ParseBool(strings.TrimSpace(env)); err == nil {
+			return v
+		}
+	}
+	s, err := LoadSettings()
+	if err != nil {
+		return false
+	}
+	return s.StrictReferences
+}
+
+// StrictKindsEnv overrides strict_kinds from config ("1"/"true" or "0"/"false").
+const StrictKindsEnv = "VYBE_STRICT_KINDS"
+
+// StrictKindsEnabled reports whether push and events add must use a known
+// event kind. The environment variable wins over config.
+func StrictKindsEnabled() bool {
+	if env, ok := os.LookupEnv(StrictKindsEnv); ok {
+		if v, err := strconv.ParseBool(strings.TrimSpace(env)); err == nil {
+			return v
+		}
+	}
+	s, err := LoadSettings()
+	if err != nil {
+		return false
+	}
+	return s.StrictKinds
+}
+
+// Network filesystem modes. Warn logs and proceeds; lock switches to the
+// degraded single-writer mode; ignore skips detection.
+const (
+	NetworkFSWarn   = "warn"
+	NetworkFSLock   = "lock"
+	NetworkFSIgnore = "ignore"
+)
+
+// NetworkFSEnv overrides network_fs from config.
+const NetworkFSEnv = "VYBE_NETWORK_FS"
+
+// NetworkFSMode returns the effective network filesystem mode. The environment
+// variable wins over config; unknown values fall back to NetworkFSWarn.
+func NetworkFSMode() string {
+	mode := ""
+	if env, ok := os.LookupEnv(NetworkFSEnv); ok {
+		mode = env
+	} else if s, err := LoadSettings(); err == nil {
+		mode = s.NetworkFS
+	}
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case NetworkFSLock, NetworkFSIgnore:
+		return m
+	default:
+		return NetworkFSWarn
+	}
+}
+
+// Project identity modes for project_identity.
+const (
+	ProjectIdentityPath = "path"
+	ProjectIdentityGit  = "git"
+)
+
+// ProjectIdentityEnv overrides project_identity from config.
+const ProjectIdentityEnv = "VYBE_PROJECT_IDENTITY"
+
+// ProjectIdentityMode returns the effective project identity mode. The
+// environment variable wins over config; unknown values fall back to
+// ProjectIdentityPath.
+func ProjectIdentityMode() string {
+	mode := ""
+	if env, ok := os.LookupEnv(ProjectIdentityEnv); ok {
+		mode = env
+	} else if s, err := LoadSettings(); err == nil {
+		mode = s.ProjectIdentity
+	}
+	if strings.EqualFold(strings.TrimSpace(mode), ProjectIdentityGit) {
+		return ProjectIdentityGit
+	}
+	return ProjectIdentityPath
+}
+
+// DBMaintainEveryDays returns the db_maintain_every window in days, or 0 when
+// scheduled maintenance is off or the value does not parse.
+func DBMaintainEveryDays() int {
+	s, err := LoadSettings()
+	if err != nil {
+		return 0
+	}
+	raw := strings.TrimSpace(s.DBMaintainEvery)
+	if raw == "" || strings.EqualFold(raw, "off") {
+		return 0
+	}
+	days, err := ParseRetentionDays(raw)
+	if err != nil {
+		return 0
+	}
+	return days
+}
+
+// Idempotency record retention bounds. MinIdempotencyTTLDays is the longest
+// plausible retry window: a resumed client session can replay a request ID
+// days after it first ran, and a pruned record would run it a second time.
+const (
+	DefaultIdempotencyTTLDays = 30
+	MinIdempotencyTTLDays     = 7
+)
+
+// ParseIdempotencyTTL parses an idempotency_ttl value into days. "off" returns
+// 0; windows shorter than MinIdempotencyTTLDays are rejected.
+func ParseIdempotencyTTL(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.EqualFold(raw, "off") {
+		return 0, nil
+	}
+	days, err := ParseRetentionDays(raw)
+	if err != nil {
+		return 0, err
+	}
+	if days < MinIdempotencyTTLDays {
+		return 0, fmt.Errorf("must be at least %dd so retried requests still replay, got %q", MinIdempotencyTTLDays, raw)
+	}
+	return days, nil
+}
+
+// IdempotencyTTLDays returns the idempotency_ttl window in days, or 0 when
+// pruning is off. Unset or invalid values fall back to DefaultIdempotencyTTLDays.
+func IdempotencyTTLDays() int {
+	s, err := LoadSettings()
+	if err != nil || strings.TrimSpace(s.IdempotencyTTL) == "" {
+		return DefaultIdempotencyTTLDays
+	}
+	days, err := ParseIdempotencyTTL(s.IdempotencyTTL)
+	if err != nil {
+		return DefaultIdempotencyTTLDays
+	}
+	return days
+}
+
+// DefaultStaleTaskAfter is the idle time after which the checkpoint hook
+// reports an in_progress task as stale.
+const DefaultStaleTaskAfter = 2 * time.Hour
+
+// StaleTaskThreshold returns stale_task_after from config, or 0 when it is
+// "off". Invalid or non-positive values fall back to DefaultStaleTaskAfter.
+func StaleTaskThreshold() time.Duration {
+	s, err := LoadSettings()
+	if err != nil {
+		return DefaultStaleTaskAfter
+	}
+	raw := strings.TrimSpace(s.StaleTaskAfter)
+	if strings.EqualFold(raw, "off") {
+		return 0
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return DefaultStaleTaskAfter
+	}
+	return d
+}
+
+// FactExtractionEnabled reports whether the checkpoint hook stages memory
+// proposals from recent events (extract_facts in config; off by default).
+func FactExtractionEnabled() bool {
+	s, err := LoadSettings()
+	if err != nil {
+		return false
+	}
+	return s.ExtractFacts
+}
+
+// DefaultClaimLease is the lease task claim takes when neither the claim, the
+// task, nor the agent sets one.
+const DefaultClaimLease = time.Hour
+
+// ClaimLeaseTTL returns claim_lease from config, or 0 when it is "off".
+// Invalid or non-positive values fall back to DefaultClaimLease.
+func ClaimLeaseTTL() time.Duration {
+	s, err := LoadSettings()
+	if err != nil {
+		return DefaultClaimLease
+	}
+	raw := strings.TrimSpace(s.ClaimLease)
+	if strings.EqualFold(raw, "off") {
+		return 0
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return DefaultClaimLease
+	}
+	return d
+}
+
+// RollingBackupConfig returns the rolling backup directory and how many backups
+// to keep for the database at dbPath.
+func RollingBackupConfig(dbPath string) (dir string, keep int) {
+	dir = filepath.Join(filepath.Dir(dbPath), "backups")
+	keep = DefaultBackupKeep
+	s, err := LoadSettings()
+	if err != nil {
+		return dir, keep
+	}
+	if d := strings.TrimSpace(s.Backup.Dir); d != "" {
+		dir = d
+	}
+	if s.Backup.Keep > 0 {
+		keep = s.Backup.Keep
+	}
+	return dir, keep
 }
 
 // EventMaintenanceSettings are effective runtime values used by checkpoint/session-end maintenance.
@@ -75,15 +491,61 @@ func EffectiveEventMaintenanceSettings() EventMaintenanceSettings {
 	return cfg
 }
 
+// Agent identity sources. DefaultAgentResolution consults all but git, in this order.
+const (
+	AgentSourceFlag = "flag"
+	AgentSourceEnv  = "env"
+	AgentSourceFile = "file"
+	AgentSourceGit  = "git"
+)
+
+// DefaultAgentResolution is the identity chain used when agent_resolution is unset.
+// git is opt-in: on by default it would rename every hook's agent (and orphan
+// its focus state) in any repository with user.email set.
+func DefaultAgentResolution() []string {
+	return []string{AgentSourceFlag, AgentSourceEnv, AgentSourceFile}
+}
+
+// EffectiveAgentResolution returns the configured identity chain with unknown and
+// duplicate entries dropped. Falls back to DefaultAgentResolution when nothing valid remains.
+func EffectiveAgentResolution() []string {
+	s, err := LoadSettings()
+	if err != nil || len(s.AgentResolution) == 0 {
+		return DefaultAgentResolution()
+	}
+
+	seen := map[string]bool{}
+	chain := make([]string, 0, len(s.AgentResolution))
+	for _, raw := range s.AgentResolution {
+		src := strings.ToLower(strings.TrimSpace(raw))
+		switch src {
+		case AgentSourceFlag, AgentSourceEnv, AgentSourceFile, AgentSourceGit:
+		default:
+			continue
+		}
+		if seen[src] {
+			continue
+		}
+		seen[src] = true
+		chain = append(chain, src)
+	}
+	if len(chain) == 0 {
+		return DefaultAgentResolution()
+	}
+	return chain
+}
+
 // settingsOnce, settings, settingsErr implement the sync.Once lazy-load singleton for config.
+// settingsReloadMu lets ReloadSettings swap in a fresh sync.Once while readers hold the read lock.
 // dbPathOverrideMu and dbPathOverride implement a mutex-protected process-wide override for CLI --db-path.
 // These globals are required by the sync.Once pattern and the RWMutex pattern; they cannot be avoided.
 //
 //nolint:gochecknoglobals // sync.Once singleton + RWMutex override are intentional process-wide state
 var (
-	settingsOnce sync.Once
-	settings     Settings
-	settingsErr  error
+	settingsReloadMu sync.RWMutex
+	settingsOnce     sync.Once
+	settings         Settings
+	settingsErr      error
 
 	dbPathOverrideMu sync.RWMutex
 	dbPathOverride   string
@@ -97,6 +559,11 @@ func SetDBPathOverride(path string) {
 	dbPathOverrideMu.Unlock()
 }
 
+// DBPathOverride returns the process-wide database path override, if any.
+func DBPathOverride() string {
+	return getDBPathOverride()
+}
+
 func getDBPathOverride() string {
 	dbPathOverrideMu.RLock()
 	v := dbPathOverride
@@ -111,43 +578,58 @@ func getDBPathOverride() string {
 // 3) ./config.yaml (lowest priority; allows repo-local overrides if desired)
 // Environment variables are handled separately.
 func LoadSettings() (Settings, error) {
+	settingsReloadMu.RLock()
+	defer settingsReloadMu.RUnlock()
+
 	settingsOnce.Do(func() {
-		settings = Settings{}
+		settings, settingsErr = readSettings()
+	})
 
-		// 1) User config (~/.config/vybe/config.yaml)
-		dir, err := ConfigDir()
-		if err != nil {
-			settingsErr = err
-			return
-		}
-		if s, err := loadSettingsFile(filepath.Join(dir, "config.yaml")); err == nil {
-			settings = s
-			return
-		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
-			settingsErr = err
-			return
-		}
+	return settings, settingsErr
+}
 
+// readSettings reads the first config file found in LoadSettings' lookup order.
+func readSettings() (Settings, error) {
+	// 1) User config (~/.config/vybe/config.yaml)
+	dir, err := ConfigDir()
+	if err != nil {
+		return Settings{}, err
+	}
+	paths := []string{
+		filepath.Join(dir, "config.yaml"),
 		// 2) /etc
-		if s, err := loadSettingsFile(filepath.Join(string(os.PathSeparator), "etc", "vybe", "config.yaml")); err == nil {
-			settings = s
-			return
-		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
-			settingsErr = err
-			return
-		}
-
+		filepath.Join(string(os.PathSeparator), "etc", "vybe", "config.yaml"),
 		// 3) Local ./config.yaml (lowest priority)
-		if s, err := loadSettingsFile("config.yaml"); err == nil {
-			settings = s
-			return
-		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
-			settingsErr = err
-			return
+		"config.yaml",
+	}
+	for _, path := range paths {
+		s, err := loadSettingsFile(path)
+		if err == nil {
+			return s, nil
 		}
-	})
+		if !errors.Is(err, os.ErrNotExist) {
+			return Settings{}, err
+		}
+	}
+	return Settings{}, nil
+}
 
-	return settings, settingsErr
+// ReloadSettings reads the config again from disk and, only if that succeeds,
+// replaces the cached settings. On failure the last good settings stay in
+// effect and are returned with the error, so a half-saved or invalid file
+// never drops a running process (loop) back to defaults.
+func ReloadSettings() (Settings, error) {
+	fresh, err := readSettings()
+	if err != nil {
+		current, _ := LoadSettings()
+		return current, err
+	}
+	settingsReloadMu.Lock()
+	settingsOnce = sync.Once{}
+	settingsOnce.Do(func() {})
+	settings, settingsErr = fresh, nil
+	settingsReloadMu.Unlock()
+	return fresh, nil
 }
 
 func loadSettingsFile(path string) (Settings, error) {
@@ -124,3 +124,107 @@ func TestEffectiveEventMaintenanceSettings_DefaultsAndClamp(t *testing.T) {
 	require.Equal(t, 20, cfg.SummarizeThreshold)
 	require.Equal(t, 50, cfg.SummarizeKeepRecent)
 }
+
+func TestEffectiveAgentResolution_FiltersUnknownAndDuplicates(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	userConfigPath := filepath.Join(home, ".config", "vybe", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(userConfigPath), 0o755))
+	require.NoError(t, os.WriteFile(userConfigPath, []byte("agent_resolution: [ENV, bogus, flag, env]\n"), 0o600))
+
+	require.Equal(t, []string{AgentSourceEnv, AgentSourceFlag}, EffectiveAgentResolution())
+}
+
+func TestEffectiveAgentResolution_DefaultsWhenUnset(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+	t.Setenv("HOME", t.TempDir())
+
+	require.Equal(t, DefaultAgentResolution(), EffectiveAgentResolution())
+}
+
+func TestEffectiveFocusPolicy_ConfigAndValidation(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+	t.Setenv("HOME", t.TempDir())
+
+	require.Equal(t, FocusPriorityFirst, EffectiveFocusPolicy())
+
+	_, err := SetConfigValue("focus.policy", "whatever", "")
+	require.Error(t, err)
+	_, err = SetConfigValue("focus.policy", "round-robin", "")
+	require.NoError(t, err)
+
+	resetSettingsStateForTest()
+	require.Equal(t, FocusRoundRobin, EffectiveFocusPolicy())
+
+	p, err := ParseFocusPolicy(" Deadline-First ")
+	require.NoError(t, err)
+	require.Equal(t, FocusDeadlineFirst, p)
+}
+
+func TestDBMaintainEveryDays_ConfigAndValidation(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+	t.Setenv("HOME", t.TempDir())
+
+	require.Equal(t, 0, DBMaintainEveryDays())
+
+	_, err := SetConfigValue("db_maintain_every", "weekly", "")
+	require.Error(t, err)
+	_, err = SetConfigValue("db_maintain_every", "2w", "")
+	require.NoError(t, err)
+	resetSettingsStateForTest()
+	require.Equal(t, 14, DBMaintainEveryDays())
+
+	_, err = SetConfigValue("db_maintain_every", "off", "")
+	require.NoError(t, err)
+	resetSettingsStateForTest()
+	require.Equal(t, 0, DBMaintainEveryDays())
+}
+
+func TestIdempotencyTTLDays_DefaultFloorAndOff(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+	t.Setenv("HOME", t.TempDir())
+
+	require.Equal(t, DefaultIdempotencyTTLDays, IdempotencyTTLDays())
+
+	_, err := SetConfigValue("idempotency_ttl", "3d", "")
+	require.ErrorContains(t, err, "at least 7d")
+	_, err = SetConfigValue("idempotency_ttl", "2w", "")
+	require.NoError(t, err)
+	resetSettingsStateForTest()
+	require.Equal(t, 14, IdempotencyTTLDays())
+
+	_, err = SetConfigValue("idempotency_ttl", "off", "")
+	require.NoError(t, err)
+	resetSettingsStateForTest()
+	require.Equal(t, 0, IdempotencyTTLDays())
+}
+
+func TestRollingBackupConfig_DefaultsAndOverrides(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+	t.Setenv("HOME", t.TempDir())
+
+	dbPath := filepath.Join(t.TempDir(), "vybe.db")
+	dir, keep := RollingBackupConfig(dbPath)
+	require.Equal(t, filepath.Join(filepath.Dir(dbPath), "backups"), dir)
+	require.Equal(t, DefaultBackupKeep, keep)
+
+	_, err := SetConfigValue("backup.keep", "-1", "")
+	require.Error(t, err)
+	_, err = SetConfigValue("backup.keep", "3", "")
+	require.NoError(t, err)
+	_, err = SetConfigValue("backup.dir", "/srv/vybe-backups", "")
+	require.NoError(t, err)
+	resetSettingsStateForTest()
+	dir, keep = RollingBackupConfig(dbPath)
+	require.Equal(t, "/srv/vybe-backups", dir)
+	require.Equal(t, 3, keep)
+}
@@ -0,0 +1,218 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// WorkspacesFileName is the registry file inside ConfigDir.
+const WorkspacesFileName = "workspaces.json"
+
+// Workspace binds a database to one or more directory roots. Any command run
+// from inside a root (or below it) uses the workspace's database.
+type Workspace struct {
+	Name   string   `json:"name"`
+	DBPath string   `json:"db_path"`
+	Roots  []string `json:"roots,omitempty"`
+	// Limits override config.yaml limits for this workspace's database.
+	Limits *LimitSettings `json:"limits,omitempty"`
+}
+
+// WorkspaceRegistry is the on-disk shape of workspaces.json.
+type WorkspaceRegistry struct {
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+// WorkspacesPath returns ~/.config/vybe/workspaces.json.
+func WorkspacesPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, WorkspacesFileName), nil
+}
+
+// LoadWorkspaces reads the registry. A missing file is an empty registry.
+func LoadWorkspaces() (*WorkspaceRegistry, error) {
+	path, err := WorkspacesPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path) //nolint:gosec // path is derived from the user's config dir
+	if errors.Is(err, os.ErrNotExist) {
+		return &WorkspaceRegistry{Workspaces: []Workspace{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read workspaces: %w", err)
+	}
+	var reg WorkspaceRegistry
+	if err := json.Unmarshal(b, &reg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if reg.Workspaces == nil {
+		reg.Workspaces = []Workspace{}
+	}
+	return &reg, nil
+}
+
+// SaveWorkspaces writes the registry atomically, sorted by name.
+func SaveWorkspaces(reg *WorkspaceRegistry) error {
+	path, err := WorkspacesPath()
+	if err != nil {
+		return err
+	}
+	sort.Slice(reg.Workspaces, func(i, j int) bool { return reg.Workspaces[i].Name < reg.Workspaces[j].Name })
+	b, err := json.MarshalIndent(reg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode workspaces: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Find returns the named workspace, or nil.
+func (r *WorkspaceRegistry) Find(name string) *Workspace {
+	for i := range r.Workspaces {
+		if r.Workspaces[i].Name == name {
+			return &r.Workspaces[i]
+		}
+	}
+	return nil
+}
+
+// Match returns the workspace whose root contains dir, preferring the deepest root.
+func (r *WorkspaceRegistry) Match(dir string) (*Workspace, string) {
+	dir = canonicalDir(dir)
+	var best *Workspace
+	bestRoot := ""
+	for i := range r.Workspaces {
+		for _, root := range r.Workspaces[i].Roots {
+			if pathWithin(dir, root) && len(root) > len(bestRoot) {
+				best, bestRoot = &r.Workspaces[i], root
+			}
+		}
+	}
+	return best, bestRoot
+}
+
+// AddWorkspace registers or updates a workspace's database path.
+func AddWorkspace(name, dbPath string) (*Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("workspace name is required")
+	}
+	if dbPath == "" {
+		return nil, errors.New("db path is required")
+	}
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve db path: %w", err)
+	}
+
+	reg, err := LoadWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	ws := reg.Find(name)
+	if ws == nil {
+		reg.Workspaces = append(reg.Workspaces, Workspace{Name: name})
+		ws = &reg.Workspaces[len(reg.Workspaces)-1]
+	}
+	ws.DBPath = abs
+	out := *ws
+	if err := SaveWorkspaces(reg); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UseWorkspace binds dir to the named workspace, moving the root from any other
+// workspace that previously claimed exactly that directory.
+func UseWorkspace(name, dir string) (*Workspace, error) {
+	reg, err := LoadWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	ws := reg.Find(name)
+	if ws == nil {
+		return nil, fmt.Errorf("workspace not found: %s (add it with: vybe ws add %s --path <path>)", name, name)
+	}
+	root := canonicalDir(dir)
+	for i := range reg.Workspaces {
+		reg.Workspaces[i].Roots = slices.DeleteFunc(reg.Workspaces[i].Roots, func(r string) bool { return r == root })
+	}
+	ws.Roots = append(ws.Roots, root)
+	sort.Strings(ws.Roots)
+	out := *ws
+	if err := SaveWorkspaces(reg); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RemoveWorkspace deletes the named workspace. The database file is left untouched.
+func RemoveWorkspace(name string) error {
+	reg, err := LoadWorkspaces()
+	if err != nil {
+		return err
+	}
+	if reg.Find(name) == nil {
+		return fmt.Errorf("workspace not found: %s", name)
+	}
+	reg.Workspaces = slices.DeleteFunc(reg.Workspaces, func(w Workspace) bool { return w.Name == name })
+	return SaveWorkspaces(reg)
+}
+
+// workspaceDBPathForCwd returns the workspace database for the current directory, if any.
+// Registry errors are ignored so a broken workspaces.json never blocks the CLI.
+func workspaceDBPathForCwd() (path, name string) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", ""
+	}
+	reg, err := LoadWorkspaces()
+	if err != nil {
+		return "", ""
+	}
+	ws, _ := reg.Match(cwd)
+	if ws == nil {
+		return "", ""
+	}
+	return ws.DBPath, ws.Name
+}
+
+// canonicalDir returns an absolute, symlink-resolved form of dir when possible.
+func canonicalDir(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Clean(dir)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+func pathWithin(dir, root string) bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
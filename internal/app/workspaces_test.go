@@ -0,0 +1,75 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceRegistry_MatchPrefersDeepestRoot(t *testing.T) {
+	base := t.TempDir()
+	outer := filepath.Join(base, "repos")
+	inner := filepath.Join(outer, "api")
+	require.NoError(t, os.MkdirAll(filepath.Join(inner, "cmd"), 0o750))
+
+	reg := &WorkspaceRegistry{Workspaces: []Workspace{
+		{Name: "all", DBPath: "/db/all.db", Roots: []string{canonicalDir(outer)}},
+		{Name: "api", DBPath: "/db/api.db", Roots: []string{canonicalDir(inner)}},
+	}}
+
+	ws, _ := reg.Match(filepath.Join(inner, "cmd"))
+	require.NotNil(t, ws)
+	require.Equal(t, "api", ws.Name)
+
+	ws, _ = reg.Match(outer)
+	require.NotNil(t, ws)
+	require.Equal(t, "all", ws.Name)
+
+	ws, _ = reg.Match(base)
+	require.Nil(t, ws)
+
+	// A sibling sharing a name prefix must not match.
+	ws, _ = reg.Match(inner + "-other")
+	require.NotNil(t, ws)
+	require.Equal(t, "all", ws.Name)
+}
+
+func TestGetDBPath_UsesWorkspaceForCwd(t *testing.T) {
+	resetSettingsStateForTest()
+	t.Cleanup(resetSettingsStateForTest)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("VYBE_DB_PATH", "")
+
+	repo := filepath.Join(home, "repo")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "pkg"), 0o750))
+	dbPath := filepath.Join(home, "dbs", "repo.db")
+
+	_, err := AddWorkspace("repo", dbPath)
+	require.NoError(t, err)
+	ws, err := UseWorkspace("repo", repo)
+	require.NoError(t, err)
+	require.Len(t, ws.Roots, 1)
+
+	t.Chdir(filepath.Join(repo, "pkg"))
+
+	resolved, source, err := ResolveDBPathDetailed()
+	require.NoError(t, err)
+	require.Equal(t, dbPath, resolved)
+	require.Equal(t, "workspace(repo)", source)
+
+	require.NoError(t, RemoveWorkspace("repo"))
+	resolved, err = GetDBPath()
+	require.NoError(t, err)
+	require.NotEqual(t, dbPath, resolved)
+}
+
+func TestUseWorkspace_RequiresRegisteredName(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := UseWorkspace("missing", t.TempDir())
+	require.Error(t, err)
+}
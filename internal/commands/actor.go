@@ -1,43 +1,146 @@
 package commands
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/store"
 )
 
-// resolveActorName resolves the agent used for event attribution and agent_state identity.
-// Precedence:
-// 1) per-command flag override (when a command defines one)
-// 2) global flag --agent
-// 3) env var VYBE_AGENT
-func resolveActorName(cmd *cobra.Command, perCmdFlag string) string {
-	raw := ""
-	if perCmdFlag != "" {
-		if v, err := cmd.Flags().GetString(perCmdFlag); err == nil && v != "" {
-			raw = v
+// actorSourceDefault marks an identity that no configured source produced.
+const actorSourceDefault = "default"
+
+// gitIdentityTimeout bounds the git config lookup so a slow git never stalls a hook.
+const gitIdentityTimeout = 2 * time.Second
+
+// actorIdentity is a resolved agent name and the source that produced it.
+type actorIdentity struct {
+	Name   string `json:"agent"`
+	Source string `json:"source"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// actorLookup is where the file and git identity sources look. Dir is the
+// directory whose .vybe.toml and git config apply ("" for the process working
+// directory); GitEmail reads git's user.email there.
+type actorLookup struct {
+	Dir      string
+	GitEmail func(dir string) string
+}
+
+// actorLookupIn returns the lookup for dir backed by the real git config.
+func actorLookupIn(dir string) actorLookup {
+	return actorLookup{Dir: dir, GitEmail: gitUserEmail}
+}
+
+// resolveActorIdentity walks the configured identity chain (app.EffectiveAgentResolution)
+// from the process working directory.
+func resolveActorIdentity(cmd *cobra.Command, perCmdFlag string) actorIdentity {
+	return resolveActorIdentityWith(cmd, perCmdFlag, actorLookupIn(""))
+}
+
+// resolveActorIdentityWith is resolveActorIdentity with the file and git
+// sources read through lookup. The "flag" source checks the per-command flag
+// override (when a command defines one) before the global --agent flag.
+// Returns a zero identity when no source matched.
+func resolveActorIdentityWith(cmd *cobra.Command, perCmdFlag string, lookup actorLookup) actorIdentity {
+	for _, src := range app.EffectiveAgentResolution() {
+		if id, ok := actorFromSource(cmd, perCmdFlag, src, lookup); ok {
+			id.Name = normalizeActorName(id.Name)
+			id.Source = src
+			return id
 		}
 	}
-	if raw == "" {
-		if v, err := cmd.Flags().GetString("agent"); err == nil && v != "" {
-			raw = v
+	return actorIdentity{}
+}
+
+func actorFromSource(cmd *cobra.Command, perCmdFlag, src string, lookup actorLookup) (actorIdentity, bool) {
+	switch src {
+	case app.AgentSourceFlag:
+		if perCmdFlag != "" {
+			if v, err := cmd.Flags().GetString(perCmdFlag); err == nil && strings.TrimSpace(v) != "" {
+				return actorIdentity{Name: v, Detail: "--" + perCmdFlag}, true
+			}
+		}
+		if v, err := cmd.Flags().GetString("agent"); err == nil && strings.TrimSpace(v) != "" {
+			return actorIdentity{Name: v, Detail: "--agent"}, true
+		}
+	case app.AgentSourceEnv:
+		if v := os.Getenv("VYBE_AGENT"); strings.TrimSpace(v) != "" {
+			return actorIdentity{Name: v, Detail: "VYBE_AGENT"}, true
+		}
+	case app.AgentSourceFile:
+		cfg, err := app.FindProjectConfig(lookup.Dir)
+		if err != nil {
+			slog.Default().Warn("project config unreadable; skipping file identity source", "error", err)
+			return actorIdentity{}, false
+		}
+		if name := cfg.Agent(); name != "" {
+			return actorIdentity{Name: name, Detail: cfg.Path}, true
+		}
+	case app.AgentSourceGit:
+		if lookup.GitEmail == nil {
+			return actorIdentity{}, false
+		}
+		if email := strings.TrimSpace(lookup.GitEmail(lookup.Dir)); email != "" {
+			return actorIdentity{Name: gitActorName(email), Detail: "git user.email"}, true
 		}
 	}
-	if raw == "" {
-		raw = os.Getenv("VYBE_AGENT")
+	return actorIdentity{}, false
+}
+
+// gitActorName derives a stable, non-reversible agent name from a git email.
+// Hashing keeps the address itself out of events and agent_state.
+func gitActorName(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "git-" + hex.EncodeToString(sum[:6])
+}
+
+// gitUserEmail returns git's user.email as configured for dir ("" for the
+// process working directory), or "" when unset.
+func gitUserEmail(dir string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), gitIdentityTimeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, "git", "config", "--get", "user.email")
+	c.Dir = dir
+	out, err := c.Output()
+	if err != nil {
+		return ""
 	}
+	return string(out)
+}
+
+func normalizeActorName(raw string) string {
 	return strings.ToLower(strings.TrimSpace(raw))
 }
 
+// resolveActorName resolves the agent used for event attribution and agent_state identity.
+// Default precedence (configurable via agent_resolution in config.yaml):
+// 1) per-command flag override (when a command defines one)
+// 2) global flag --agent
+// 3) env var VYBE_AGENT
+// 4) agent key in the nearest .vybe.toml
+// The hash of git user.email is a source only when agent_resolution lists git.
+func resolveActorName(cmd *cobra.Command, perCmdFlag string) string {
+	return resolveActorIdentity(cmd, perCmdFlag).Name
+}
+
 func requireActorName(cmd *cobra.Command, perCmdFlag string) (string, error) {
 	agent := resolveActorName(cmd, perCmdFlag)
 	if agent == "" {
-		return "", errors.New("agent is required (set --agent or VYBE_AGENT)")
+		return "", errors.New("agent is required (set --agent, VYBE_AGENT, or agent in " + app.ProjectConfigFileName + ")")
 	}
 	if len(agent) > store.MaxEventAgentNameLength {
 		return "", fmt.Errorf("agent name exceeds maximum length (%d chars)", store.MaxEventAgentNameLength)
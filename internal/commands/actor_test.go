@@ -1,11 +1,17 @@
 package commands
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
 )
 
 func newActorTestCmd(t *testing.T) *cobra.Command {
@@ -16,6 +22,36 @@ func newActorTestCmd(t *testing.T) *cobra.Command {
 	return cmd
 }
 
+// stubGitLookup returns an actor lookup in dir whose git user.email is email.
+func stubGitLookup(dir, email string) actorLookup {
+	return actorLookup{Dir: dir, GitEmail: func(string) string { return email }}
+}
+
+// enableGitIdentity writes a user config that adds git to the identity chain.
+func enableGitIdentity(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".config", "vybe")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"),
+		[]byte("agent_resolution: [flag, env, file, git]\n"), 0o600))
+	_, err := app.ReloadSettings()
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = app.ReloadSettings() })
+}
+
+// chdirTemp switches into a fresh temp dir so no ancestor .vybe.toml is visible.
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	oldwd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(oldwd) })
+	return dir
+}
+
 func TestResolveActorName_Precedence(t *testing.T) {
 	cmd := newActorTestCmd(t)
 	t.Setenv("VYBE_AGENT", "env-agent")
@@ -64,6 +100,8 @@ func TestResolveActorName_Normalization(t *testing.T) {
 		{"empty stays empty", "", ""},
 	}
 
+	t.Setenv("VYBE_AGENT", "")
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cmd := newTestCmd()
@@ -82,3 +120,91 @@ func TestResolveActorName_EnvNormalized(t *testing.T) {
 	got := resolveActorName(cmd, "")
 	assert.Equal(t, "claude", got)
 }
+
+func TestResolveActorIdentity_ProjectFileBeforeGit(t *testing.T) {
+	cmd := newActorTestCmd(t)
+	t.Setenv("VYBE_AGENT", "")
+	enableGitIdentity(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".vybe.toml"), []byte("agent = \"Repo-Agent\"\n"), 0o600))
+
+	id := resolveActorIdentityWith(cmd, "", stubGitLookup(dir, "dev@example.com"))
+	require.Equal(t, "repo-agent", id.Name)
+	require.Equal(t, "file", id.Source)
+	require.Contains(t, id.Detail, ".vybe.toml")
+}
+
+func TestResolveActorIdentity_GitEmailHash(t *testing.T) {
+	cmd := newActorTestCmd(t)
+	t.Setenv("VYBE_AGENT", "")
+	enableGitIdentity(t)
+
+	id := resolveActorIdentityWith(cmd, "", stubGitLookup(t.TempDir(), " Dev@Example.com\n"))
+	require.Equal(t, "git", id.Source)
+	require.Equal(t, gitActorName("dev@example.com"), id.Name)
+	require.NotContains(t, id.Name, "example")
+}
+
+func TestResolveActorIdentity_GitIsOptIn(t *testing.T) {
+	cmd := newActorTestCmd(t)
+	t.Setenv("VYBE_AGENT", "")
+	t.Setenv("HOME", t.TempDir())
+	_, err := app.ReloadSettings()
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = app.ReloadSettings() })
+
+	id := resolveActorIdentityWith(cmd, "", stubGitLookup(t.TempDir(), "dev@example.com"))
+	require.Equal(t, actorIdentity{}, id, "git is not consulted unless agent_resolution lists it")
+}
+
+func TestResolveActorIdentity_FlagSourceDetail(t *testing.T) {
+	cmd := newActorTestCmd(t)
+	t.Setenv("VYBE_AGENT", "env-agent")
+	require.NoError(t, cmd.Flags().Set("agent", "flag-agent"))
+
+	id := resolveActorIdentity(cmd, "")
+	require.Equal(t, actorIdentity{Name: "flag-agent", Source: "flag", Detail: "--agent"}, id)
+}
+
+func TestResolveHookContext_ReadsProjectFileFromPayloadCWD(t *testing.T) {
+	t.Setenv("VYBE_AGENT", "")
+	t.Setenv("HOME", t.TempDir())
+	_, err := app.ReloadSettings()
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = app.ReloadSettings() })
+
+	processDir := chdirTemp(t)
+	require.NoError(t, os.WriteFile(filepath.Join(processDir, ".vybe.toml"), []byte("agent = \"process-agent\"\n"), 0o600))
+	sessionDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sessionDir, ".vybe.toml"), []byte("agent = \"session-agent\"\n"), 0o600))
+
+	cmd := newActorTestCmd(t)
+	payload := []byte(`{"cwd":` + strconv.Quote(sessionDir) + `}`)
+	cmd.SetContext(context.WithValue(context.Background(), hookPayloadKey{}, payload))
+
+	hctx := resolveHookContextAs(cmd, defaultAgentName)
+	require.Equal(t, "session-agent", hctx.AgentName)
+	require.Equal(t, sessionDir, hctx.CWD)
+}
+
+func TestWriteBehindAgent_MatchesHookIdentity(t *testing.T) {
+	t.Setenv("VYBE_AGENT", "")
+	t.Setenv("HOME", t.TempDir())
+	_, err := app.ReloadSettings()
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = app.ReloadSettings() })
+	chdirTemp(t)
+
+	sessionDir := t.TempDir()
+	payload := []byte(`{"cwd":` + strconv.Quote(sessionDir) + `}`)
+
+	cmd := newActorTestCmd(t)
+	withHookDispatch(cmd, payload, "gemini")
+	require.Equal(t, "gemini", writeBehindAgent(cmd, payload), "the dispatcher's fallback reaches the child")
+
+	require.NoError(t, os.WriteFile(filepath.Join(sessionDir, ".vybe.toml"), []byte("agent = \"session-agent\"\n"), 0o600))
+	require.Equal(t, "session-agent", writeBehindAgent(cmd, payload))
+
+	require.Empty(t, writeBehindAgent(newActorTestCmd(t), []byte(`{}`)))
+}
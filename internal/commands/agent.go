@@ -0,0 +1,324 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewAgentCmd creates the agent command group for agent registration and
+// fleet presence.
+func NewAgentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Register agents, list the fleet, and evict dead agents",
+		Long: `Agents register the capabilities they have (languages, areas, tools). Tasks
+created with --requires are only routed, by resume and task claim, to agents
+that have registered every required capability. Tasks without requirements go
+to any agent.
+
+agent list shows every agent vybe knows with its liveness; agent evict releases
+the tasks a dead agent still holds. agent init --confine-to-project keeps an
+agent inside one project. agent lease sets how long the agent's task claims
+hold their task without activity.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newAgentInitCmd())
+	cmd.AddCommand(newAgentRegisterCmd())
+	cmd.AddCommand(newAgentShowCmd())
+	cmd.AddCommand(newAgentListCmd())
+	cmd.AddCommand(newAgentEvictCmd())
+	cmd.AddCommand(newAgentLeaseCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newAgentInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create the agent's state, optionally confining it to one project",
+		Long: `init creates the agent's state if it has none. With --confine-to-project the
+agent is confined to that project: its focus stays on the project, writes to
+tasks, memory, and events outside it fail with PROJECT_CONFINED, global memory
+becomes read-only, and task, memory, and event reads are filtered to the
+project. --release lifts the confinement.
+
+Without either flag init only reports the agent's current confinement.`,
+		Example: `  vybe agent init --agent worker-api --confine-to-project proj_api --request-id init-1
+  vybe agent init --agent worker-api --release --request-id init-2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("confine-to-project")
+			release, _ := cmd.Flags().GetBool("release")
+			if release && projectID != "" {
+				return cmdErr(errors.New("--confine-to-project and --release are mutually exclusive"))
+			}
+
+			if projectID == "" && !release {
+				agentName, err := requireActorName(cmd, "")
+				if err != nil {
+					return cmdErr(err)
+				}
+				var result *store.AgentConfinement
+				if err := withDB(cmd.Context(), func(db *DB) error {
+					if _, err := store.LoadOrCreateAgentState(cmd.Context(), db, agentName); err != nil {
+						return err
+					}
+					confined, err := store.GetAgentConfinement(cmd.Context(), db, agentName)
+					result = &store.AgentConfinement{AgentName: agentName, ProjectID: confined}
+					return err
+				}); err != nil {
+					return err
+				}
+				return output.PrintSuccess(result)
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			var result *store.AgentConfinement
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.ConfineAgentIdempotent(cmd.Context(), db, agentName, requestID, projectID)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("confine-to-project", "", "Confine the agent to this project ID")
+	cmd.Flags().Bool("release", false, "Lift the agent's project confinement")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
+	return cmd
+}
+
+func newAgentRegisterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "register",
+		Short: "Register the agent's capabilities, replacing any registered before",
+		Long: `Replaces the agent's capability set and writes an agent_registered event.
+Capabilities use tag syntax: lowercase letters, digits, '.', '_', '-', ':', '/'.
+Registering with no capabilities clears the set, so the agent is only routed
+tasks without requirements.`,
+		Example: `  vybe agent register --agent worker-go --capabilities go,db-migrations`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			capabilities, _ := cmd.Flags().GetStringSlice("capabilities")
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *store.AgentRegistration
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.AgentRegisterIdempotent(cmd.Context(), db, agentName, requestID, capabilities)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().StringSlice("capabilities", nil, "Capabilities the agent has (comma-separated, e.g. go,frontend)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newAgentShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the agent's registered capabilities",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, err := requireActorName(cmd, "")
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			var capabilities []string
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				c, err := actions.AgentCapabilities(cmd.Context(), db, agentName)
+				if err != nil {
+					return err
+				}
+				capabilities = c
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				AgentName    string   `json:"agent_name"`
+				Capabilities []string `json:"capabilities"`
+			}
+			return output.PrintSuccess(resp{AgentName: agentName, Capabilities: capabilities})
+		},
+	}
+}
+
+func newAgentListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List agents with last activity, focus, held tasks, and liveness",
+		Long: `Lists every agent with state, most recently active first. Last activity is
+the later of the agent's last resume or focus change and its newest event.
+leases are the in_progress tasks the agent holds through its focus, agent-wide
+or in a session. Liveness is active (within 15m), idle, or stale (quiet longer
+than --stale-after); stale agents holding leases are candidates for agent evict.`,
+		Example: `  vybe agent list | jq -r '.data.agents[] | "\(.liveness)\t\(.agent_name)\t\(.leases | length)"'
+  vybe agent list --stale-after 2h`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			staleAfter, _ := cmd.Flags().GetDuration("stale-after")
+			if staleAfter <= 0 {
+				return cmdErr(errors.New("--stale-after must be positive"))
+			}
+
+			var agents []store.AgentPresence
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				a, err := actions.AgentList(cmd.Context(), db, staleAfter)
+				if err != nil {
+					return err
+				}
+				agents = a
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Count  int                   `json:"count"`
+				Agents []store.AgentPresence `json:"agents"`
+			}
+			if agents == nil {
+				agents = []store.AgentPresence{}
+			}
+			return output.PrintSuccess(resp{Count: len(agents), Agents: agents})
+		},
+	}
+
+	cmd.Flags().Duration("stale-after", store.DefaultAgentStaleAfter, "Inactivity after which an agent is stale")
+	return cmd
+}
+
+func newAgentEvictCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "evict",
+		Short: "Release a dead agent's claims: its in_progress tasks return to pending",
+		Long: `Releases everything the named agent holds in one call: each in_progress task
+it is focused on (agent-wide or in any session) goes back to pending, its focus
+is cleared, and it is left an inbox notice in case it comes back. The eviction
+is recorded as an agent_evicted event by the calling agent. The evicted agent's
+registered capabilities are kept.`,
+		Example: `  vybe agent evict --agent ops --request-id evict_1 --name worker-3`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, _ := cmd.Flags().GetString("name")
+			if target == "" {
+				return cmdErr(errors.New("--name is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *store.AgentEviction
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.AgentEvictIdempotent(cmd.Context(), db, agentName, requestID, target)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Agent to evict (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
+	return cmd
+}
+
+func newAgentLeaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lease [duration|none]",
+		Short: "Show or set the agent's default task claim lease",
+		Long: `lease sets how long the agent's task claims hold their task with no activity
+before memory gc returns it to pending. It applies when neither task claim
+--lease nor the task's own lease (task update --lease) is set, and overrides
+claim_lease in config. none clears it. Without an argument lease only reports
+the agent's default; lease_seconds 0 means none is set.`,
+		Example: `  vybe agent lease 45m --agent worker-api --request-id lease-1
+  vybe agent lease none --agent worker-api --request-id lease-2`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				agentName, err := requireActorName(cmd, "")
+				if err != nil {
+					return cmdErr(err)
+				}
+				var result *store.AgentLeaseSetting
+				if err := withDB(cmd.Context(), func(db *DB) error {
+					r, err := actions.AgentLease(cmd.Context(), db, agentName)
+					result = r
+					return err
+				}); err != nil {
+					return err
+				}
+				return output.PrintSuccess(result)
+			}
+
+			lease, err := actions.ParseLease(args[0])
+			if err != nil {
+				return cmdErr(err)
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			var result *store.AgentLeaseSetting
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.AgentLeaseSetIdempotent(cmd.Context(), db, agentName, requestID, lease)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+// confinedProjectFilter narrows a read's project filter to the project the
+// calling agent is confined to (see 'vybe agent init --confine-to-project').
+func confinedProjectFilter(cmd *cobra.Command, db *DB, projectFilter string) (string, error) {
+	return actions.ConfineProjectFilter(cmd.Context(), db, resolveActorName(cmd, ""), projectFilter)
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewAnalyticsCmd creates the analytics command.
+func NewAnalyticsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analytics",
+		Short: "Aggregate throughput, time to complete, failures, and event volume",
+		Long: `Analytics summarizes the last --since window from the event log and task table:
+
+  throughput        tasks completed, per UTC day and on average
+  time to complete  mean and median from task creation to completion
+  tool failures     tool_failure share of tool events, by tool
+  projects          the busiest projects by event count
+  events by kind    event volume per kind
+
+--format json returns the numbers; --format text prints an ASCII report.`,
+		Example: `  vybe analytics --since 7d --format text
+  vybe analytics --project "$PWD" --since 30d | jq '.data.tool_failures'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project")
+			sinceRaw, _ := cmd.Flags().GetString("since")
+			format, _ := cmd.Flags().GetString("format")
+
+			if format != "json" && format != "text" {
+				return cmdErr(fmt.Errorf("invalid --format %q (valid: json, text)", format))
+			}
+			sinceDays, err := app.ParseRetentionDays(sinceRaw)
+			if err != nil {
+				return cmdErr(fmt.Errorf("invalid --since: %w", err))
+			}
+			if filepath.IsAbs(projectID) {
+				projectID = resolveProjectID(filepath.Clean(projectID))
+			}
+
+			var report *store.Analytics
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				projectID, err := confinedProjectFilter(cmd, db, projectID)
+				if err != nil {
+					return err
+				}
+				report, err = actions.Analytics(cmd.Context(), db, projectID, sinceDays)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			if format == "text" {
+				_, err := fmt.Fprint(cmd.OutOrStdout(), actions.RenderAnalyticsText(report))
+				return err
+			}
+			return output.PrintSuccess(report)
+		},
+	}
+
+	cmd.Flags().String("project", "", "Only this project (default: all projects)")
+	cmd.Flags().String("since", "7d", "Window to report on (e.g. 7d, 2w)")
+	cmd.Flags().String("format", "json", "Output format: json|text")
+	return cmd
+}
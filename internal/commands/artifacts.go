@@ -1,6 +1,17 @@
 package commands
 
-import "github.com/spf13/cobra"
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
 
 // NewArtifactsCmd creates the artifacts command.
 func NewArtifactsCmd() *cobra.Command {
@@ -10,15 +21,116 @@ func NewArtifactsCmd() *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:   "artifacts",
-		Short: "List artifacts linked to a task",
+		Use:     "artifacts",
+		Aliases: []string{"artifact"},
+		Short:   "List artifacts linked to a task",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runArtifactsMode(taskID, limit)
+			return runArtifactsMode(cmd.Context(), taskID, limit)
 		},
 	}
 
 	cmd.Flags().StringVar(&taskID, "task-id", "", "Task ID (required)")
 	cmd.Flags().IntVar(&limit, "limit", 50, "Max artifacts to return")
 
+	cmd.AddCommand(newArtifactAddCmd())
+	cmd.AddCommand(newArtifactDiffCmd())
+
+	return cmd
+}
+
+func newArtifactAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Link a file to a task, optionally storing its content",
+		Long: `Add records a produced file against a task. With --store-content the file body is
+hashed into the blob store so it survives workspace wipes and can be compared
+across attempts with 'vybe artifact diff'. Identical content is stored once.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("task-id")
+			path, _ := cmd.Flags().GetString("path")
+			contentType, _ := cmd.Flags().GetString("content-type")
+			storeContent, _ := cmd.Flags().GetBool("store-content")
+			if taskID == "" {
+				return cmdErr(errors.New("--task-id is required"))
+			}
+			if path == "" {
+				return cmdErr(errors.New("--path is required"))
+			}
+
+			var content []byte
+			if storeContent {
+				info, err := os.Stat(path)
+				if err != nil {
+					return cmdErr(fmt.Errorf("read artifact: %w", err))
+				}
+				if info.Size() > store.MaxArtifactContentBytes {
+					return cmdErr(fmt.Errorf("%s is %d bytes; --store-content limit is %d", path, info.Size(), store.MaxArtifactContentBytes))
+				}
+				content, err = os.ReadFile(path) //nolint:gosec // G304: path is the operator-supplied artifact to capture
+				if err != nil {
+					return cmdErr(fmt.Errorf("read artifact: %w", err))
+				}
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			type resp struct {
+				Artifact *models.Artifact `json:"artifact"`
+				EventID  int64            `json:"event_id"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				a, eid, err := actions.ArtifactAddWithContentIdempotent(cmd.Context(), db, agentName, requestID, taskID, path, contentType, content)
+				if err != nil {
+					return err
+				}
+				r = resp{Artifact: a, EventID: eid}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("task-id", "", "Task ID (required)")
+	cmd.Flags().String("path", "", "File path to link (required)")
+	cmd.Flags().String("content-type", "", "Optional MIME type")
+	cmd.Flags().Bool("store-content", false, "Store the file content in the database for later diffing")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newArtifactDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show how stored artifact content changed between two artifacts",
+		Long: `Diff compares the stored content of two artifacts (added with --store-content)
+and returns a unified diff from the first --id to the second. Binary content
+reports only whether the files differ.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, _ := cmd.Flags().GetStringArray("id")
+			if len(ids) != 2 {
+				return cmdErr(errors.New("pass --id exactly twice: --id <old> --id <new>"))
+			}
+
+			var res *actions.ArtifactDiffResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				res, err = actions.ArtifactDiff(cmd.Context(), db, ids[0], ids[1])
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().StringArray("id", nil, "Artifact ID; pass twice (old, then new)")
 	return cmd
 }
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// maxBatchLineBytes bounds one JSONL operation, matching the push input limit.
+const maxBatchLineBytes = 1 << 20
+
+// batchLineResult is one JSONL output line: the standard response envelope
+// tagged with the input line it answers.
+type batchLineResult struct {
+	Line      int    `json:"line"`
+	Op        string `json:"op,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	output.Response
+}
+
+// NewBatchCmd creates the batch command — many mutations over one process and connection.
+func NewBatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Short: "Apply a JSONL stream of mutations from stdin",
+		Long: `Batch reads one operation per line and writes one JSONL result per line.
+Each line is its own idempotent transaction, keyed by its request_id:
+
+  {"op": "task.create", "request_id": "r1", "args": {"title": "Write tests"}}
+
+Lines without a request_id use "<--request-id>.<line>" when --request-id is set,
+so replaying the same stream is safe. Blank lines are skipped. A failed line
+does not stop the stream unless --stop-on-error is set; the exit code is
+non-zero when any line failed.
+
+Ops: task.create, task.set_status, task.begin, task.close, task.add_dep,
+task.set_meta, task.criteria_add, memory.set, events.add, artifacts.add,
+msg.send, push. Args use the JSON field names of the matching command output
+(task_id, project_id, ...); push takes the vybe push payload.`,
+		Example: `  hook-events | vybe batch --agent worker
+  vybe batch --agent worker --request-id sync-42 --file ops.jsonl`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			stopOnError, _ := cmd.Flags().GetBool("stop-on-error")
+			agentName, err := requireActorName(cmd, "")
+			if err != nil {
+				return cmdErr(err)
+			}
+			requestPrefix := resolveRequestID(cmd)
+
+			in := cmd.InOrStdin()
+			if file != "-" {
+				f, err := os.Open(file) //nolint:gosec // user-supplied batch path is the point of the flag
+				if err != nil {
+					return cmdErr(fmt.Errorf("failed to open batch file: %w", err))
+				}
+				defer func() { _ = f.Close() }()
+				in = f
+			}
+
+			var failed int
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				failed, err = runBatch(cmd.Context(), db, in, cmd.OutOrStdout(), agentName, requestPrefix, stopOnError)
+				return err
+			}); err != nil {
+				return err
+			}
+			if failed > 0 {
+				// Each failure is already reported on its own result line.
+				return printedError{err: fmt.Errorf("%d batch operation(s) failed", failed)}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("file", "-", "JSONL operations file (- for stdin)")
+	cmd.Flags().Bool("stop-on-error", false, "Stop at the first failed line")
+
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "conditional"}
+	return cmd
+}
+
+// runBatch applies each JSONL operation from in and writes its result to out.
+// It returns the number of failed lines; a read error aborts the stream.
+func runBatch(ctx context.Context, db *DB, in io.Reader, out io.Writer, agentName, requestPrefix string, stopOnError bool) (int, error) {
+	cfg := output.Config{Writer: out}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBatchLineBytes)
+
+	failed := 0
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		result := batchLineResult{Line: lineNo}
+		var op actions.BatchOp
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		err := dec.Decode(&op)
+		if err != nil {
+			err = fmt.Errorf("invalid batch line: %w", err)
+		} else {
+			if op.RequestID == "" && requestPrefix != "" {
+				op.RequestID = fmt.Sprintf("%s.%d", requestPrefix, lineNo)
+			}
+			result.Op, result.RequestID = op.Op, op.RequestID
+			var data any
+			data, err = actions.ApplyBatchOp(ctx, db, agentName, op)
+			if err == nil {
+				result.Response = output.Success(data)
+			}
+		}
+		if err != nil {
+			result.Response = output.Error(err)
+			failed++
+		}
+
+		if werr := output.PrintWith(cfg, result); werr != nil {
+			return failed, fmt.Errorf("failed to write batch result: %w", werr)
+		}
+		if err != nil && stopOnError {
+			return failed, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return failed, fmt.Errorf("failed to read batch input: %w", err)
+	}
+	return failed, nil
+}
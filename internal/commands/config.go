@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// NewConfigCmd creates the config command group.
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and write settings in ~/.config/vybe/config.yaml",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newConfigSetCmd())
+	cmd.AddCommand(newConfigGetCmd())
+	cmd.AddCommand(newConfigReloadCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newConfigSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a config value by dotted key (e.g. retention.tool_success 7d)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project")
+
+			path, err := app.SetConfigValue(args[0], args[1], projectID)
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				Path      string `json:"path"`
+				Key       string `json:"key"`
+				Value     string `json:"value"`
+				ProjectID string `json:"project_id,omitempty"`
+			}
+			return output.PrintSuccess(resp{Path: path, Key: args[0], Value: args[1], ProjectID: projectID})
+		},
+	}
+
+	cmd.Flags().String("project", "", "Store the value as an override for this project ID (retention.* and context.profile only)")
+	cmd.Annotations = map[string]string{"mutates": "true", "admin": "true"}
+	return cmd
+}
+
+func newConfigGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Show a config value by dotted key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project")
+
+			value, found, err := app.GetConfigValue(args[0], projectID)
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				Key       string `json:"key"`
+				Value     string `json:"value"`
+				Found     bool   `json:"found"`
+				ProjectID string `json:"project_id,omitempty"`
+			}
+			return output.PrintSuccess(resp{Key: args[0], Value: value, Found: found, ProjectID: projectID})
+		},
+	}
+
+	cmd.Flags().String("project", "", "Read the override stored for this project ID")
+	return cmd
+}
+
+func newConfigReloadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reload",
+		Short: "Validate config and signal running loops to reload it",
+		Long: `Reload re-reads config.yaml and fails if it no longer parses, then touches
+~/.config/vybe/reload.stamp. Running vybe loop processes watch that stamp and
+reload their settings on the next poll (a few seconds). Sending SIGHUP to a
+loop process reloads it immediately.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := app.ReloadSettings()
+			if err != nil {
+				return cmdErr(fmt.Errorf("config did not reload: %w", err))
+			}
+			stamp, err := app.RequestConfigReload()
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				Stamp     string                       `json:"stamp"`
+				Retention app.RetentionPolicy          `json:"retention"`
+				Events    app.EventMaintenanceSettings `json:"events"`
+				DBPath    string                       `json:"db_path,omitempty"`
+			}
+			return output.PrintSuccess(resp{
+				Stamp:     stamp,
+				Retention: app.EffectiveRetentionPolicy(""),
+				Events:    app.EffectiveEventMaintenanceSettings(),
+				DBPath:    s.DBPath,
+			})
+		},
+	}
+	cmd.Annotations = map[string]string{"mutates": "true", "admin": "true"}
+	return cmd
+}
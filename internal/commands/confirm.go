@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// addDestructiveFlags registers --yes and --backup-first on a command that
+// removes data.
+func addDestructiveFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("yes", false, "Confirm the deletion (required when stdin is not a terminal)")
+	addBackupFirstFlag(cmd)
+}
+
+// addBackupFirstFlag registers --backup-first, read by backupFirst.
+func addBackupFirstFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("backup-first", false, "Write a database snapshot to <db dir>/backups before deleting")
+}
+
+// confirmDestructive returns nil when the caller passed --yes, or answered y
+// to a prompt on an interactive terminal. Non-interactive callers (agents,
+// scripts) must pass --yes, so a hallucinated cleanup cannot run unattended.
+func confirmDestructive(cmd *cobra.Command, action string) error {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return nil
+	}
+	in, ok := cmd.InOrStdin().(*os.File)
+	if !ok || !isTerminal(in) {
+		return fmt.Errorf("refusing to %s without --yes", action)
+	}
+
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "About to %s. Continue? [y/N] ", action)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("aborted: %s not confirmed", action)
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal. A character-device
+// check is not enough: /dev/null is one too.
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// backupFirst writes a VACUUM INTO snapshot when --backup-first is set and
+// returns its path ("" when the flag is off). label names the operation in
+// the file name.
+func backupFirst(cmd *cobra.Command, db *DB, label string) (string, error) {
+	if backup, _ := cmd.Flags().GetBool("backup-first"); !backup {
+		return "", nil
+	}
+	dbPath, err := app.GetDBPath()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(filepath.Dir(dbPath), "backups",
+		fmt.Sprintf("%s-%s.db", label, time.Now().UTC().Format("20060102T150405.000000000Z")))
+	if err := store.VacuumInto(cmd.Context(), db, path); err != nil {
+		return "", fmt.Errorf("backup before %s failed: %w", label, err)
+	}
+	return path, nil
+}
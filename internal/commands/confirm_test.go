@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmDestructive(t *testing.T) {
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "x"}
+		addDestructiveFlags(cmd)
+		cmd.SetIn(strings.NewReader("y\n"))
+		return cmd
+	}
+
+	cmd := newCmd()
+	err := confirmDestructive(cmd, "delete task t1")
+	require.EqualError(t, err, "refusing to delete task t1 without --yes", "piped stdin never answers the prompt")
+
+	cmd = newCmd()
+	require.NoError(t, cmd.Flags().Set("yes", "true"))
+	require.NoError(t, confirmDestructive(cmd, "delete task t1"))
+}
+
+func TestDestructiveCommands_RequireYes(t *testing.T) {
+	t.Setenv("VYBE_AGENT", "agent-1")
+	t.Setenv("VYBE_REQUEST_ID", "req-1")
+
+	for name, cmd := range map[string]*cobra.Command{
+		"task delete":           newTaskDeleteCmd(),
+		"project delete":        newProjectDeleteCmd(),
+		"memory delete pattern": newMemoryDeleteCmd(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			requireFlagExists(t, cmd, "yes")
+			requireFlagExists(t, cmd, "backup-first")
+			if cmd.Flag("key") != nil {
+				require.NoError(t, cmd.Flags().Set("key", "build/*"))
+			} else {
+				require.NoError(t, cmd.Flags().Set("id", "x"))
+			}
+			cmd.SetIn(strings.NewReader(""))
+			err := cmd.RunE(cmd, nil)
+			require.Error(t, err)
+			require.IsType(t, printedError{}, err)
+		})
+	}
+}
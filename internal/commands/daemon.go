@@ -0,0 +1,653 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+const (
+	// daemonSocketSuffix is appended to the database path to name its socket,
+	// so a CLI finds the daemon serving exactly the database it resolved.
+	daemonSocketSuffix = ".sock"
+	// daemonDialTimeout bounds the auto-detect probe; a dead socket costs at
+	// most this much before the CLI falls back to running in-process.
+	daemonDialTimeout = 100 * time.Millisecond
+	// maxDaemonSocketPath stays under the sun_path limit (104 on macOS, 108 on Linux).
+	maxDaemonSocketPath = 100
+)
+
+// Daemon request ops.
+const (
+	daemonOpRun  = "run"
+	daemonOpPing = "ping"
+	daemonOpStop = "stop"
+)
+
+// daemonProxyExcluded lists commands that always run in-process: the daemon
+// itself, long-running loops, scenario runs (which spawn their own vybe
+// processes), binary upgrades, and task wait, which would stall every other
+// request while it blocks.
+var daemonProxyExcluded = map[string]bool{"daemon": true, "loop": true, "scenario": true, "upgrade": true, "task wait": true}
+
+// daemonRequest is the first line a client writes. For runs, the rest of the
+// connection carries the client's stdin until the client half-closes it.
+type daemonRequest struct {
+	Op    string   `json:"op"`
+	Args  []string `json:"args,omitempty"`
+	Cwd   string   `json:"cwd,omitempty"`
+	Env   []string `json:"env,omitempty"`
+	Token string   `json:"token,omitempty"`
+}
+
+// daemonResponse is the single line the daemon writes back.
+type daemonResponse struct {
+	Stdout   []byte        `json:"stdout,omitempty"`
+	Stderr   []byte        `json:"stderr,omitempty"`
+	ExitCode int           `json:"exit_code"`
+	Error    string        `json:"error,omitempty"`
+	Status   *daemonStatus `json:"status,omitempty"`
+}
+
+// daemonStatus describes a running daemon.
+type daemonStatus struct {
+	PID       int       `json:"pid"`
+	Version   string    `json:"version"`
+	DBPath    string    `json:"db_path"`
+	Socket    string    `json:"socket"`
+	StartedAt time.Time `json:"started_at"`
+	Served    int64     `json:"served"`
+
+	// RequireToken means every run needs a VYBE_TOKEN whose role covers the
+	// command (see daemonServer.authorizeRequest).
+	RequireToken bool `json:"require_token,omitempty"`
+}
+
+// daemonStartOptions configures daemon start. SocketMode wider than 0600
+// shares the socket with other users and is only allowed with RequireToken.
+type daemonStartOptions struct {
+	Idle         time.Duration
+	RequireToken bool
+	SocketMode   os.FileMode
+}
+
+// daemonTokenEnv lists the only client variables a token-protected daemon
+// adopts. Everything else (PATH, HOME, VYBE_DB_PATH, ...) stays the daemon's
+// own, so a token holder cannot steer which binaries or config it loads.
+func daemonTokenEnv() []string {
+	return []string{"VYBE_AGENT", "VYBE_SESSION_ID", "VYBE_TOKEN"}
+}
+
+// daemonServer is one running daemon: the database it serves and the requests
+// it has handled. run serializes requests: each swaps process-wide stdio,
+// env, and cwd.
+type daemonServer struct {
+	version string
+	status  daemonStatus
+	db      *DB // the warm connection; nil makes token checks open status.DBPath per call
+	run     sync.Mutex
+	served  atomic.Int64
+}
+
+// daemonWarm is the server running in this process, if any. openDB has no
+// context to carry the warm connection through, so it looks here.
+//
+//nolint:gochecknoglobals // set once for the daemon's lifetime; read by openDBAt
+var daemonWarm atomic.Pointer[daemonServer]
+
+// daemonWarmDB returns the running daemon's open connection when dbPath is the
+// database it serves, or nil outside a daemon.
+func daemonWarmDB(dbPath string) *DB {
+	s := daemonWarm.Load()
+	if s == nil || s.db == nil || dbPath != s.status.DBPath {
+		return nil
+	}
+	return s.db
+}
+
+// NewDaemonCmd creates the daemon command group.
+func NewDaemonCmd(version string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Keep the database warm and serve CLI calls over a unix socket",
+		Long: `The daemon opens and migrates the database once, then serves commands over a
+unix socket next to it (<db path>.sock). Every vybe invocation whose stdin is
+not a terminal checks for that socket and, when the daemon answers, runs through
+it, skipping the database open and migration check. Humans typing in a terminal,
+and the daemon, loop, and upgrade commands, always run in-process.
+
+With --require-token, each call must carry a VYBE_TOKEN (see 'vybe token') whose
+role covers the command: read, agent (mutations and hooks), or admin (including
+commands that read or write a file path: events export, snapshot --to-file,
+snapshot mount). Only then
+may --socket-mode open the socket to other users. A token-protected daemon keeps
+its own environment and config: only VYBE_AGENT, VYBE_SESSION_ID, and VYBE_TOKEN
+pass through from the client, and commands that run external programs (hook
+retrospective --llm) or name a trace collector (--otel-endpoint) are refused.
+
+Set VYBE_NO_DAEMON=1 to bypass a running daemon.`,
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newDaemonStartCmd(version), newDaemonStatusCmd(), newDaemonStopCmd())
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newDaemonStartCmd(version string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Run the daemon in the foreground until stopped or idle",
+		Example: `  vybe daemon start &
+  vybe daemon start --idle-timeout 2h
+  vybe daemon start --require-token --socket-mode 0660 --idle-timeout 0`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := daemonStartOptions{}
+			opts.Idle, _ = cmd.Flags().GetDuration("idle-timeout")
+			opts.RequireToken, _ = cmd.Flags().GetBool("require-token")
+			modeRaw, _ := cmd.Flags().GetString("socket-mode")
+			mode, err := strconv.ParseUint(modeRaw, 8, 32)
+			if err != nil || mode&^0o666 != 0 || mode&0o600 != 0o600 {
+				return cmdErr(fmt.Errorf("invalid --socket-mode %q: want octal read/write bits such as 0600 or 0660", modeRaw))
+			}
+			opts.SocketMode = os.FileMode(mode)
+			if opts.SocketMode != 0o600 && !opts.RequireToken {
+				return cmdErr(errors.New("--socket-mode wider than 0600 requires --require-token"))
+			}
+			return runDaemon(cmd.Context(), version, opts)
+		},
+	}
+	cmd.Flags().Duration("idle-timeout", 30*time.Minute, "Exit after this long without requests (0 = never)")
+	cmd.Flags().Bool("require-token", false, "Reject calls without a VYBE_TOKEN whose role covers the command")
+	cmd.Flags().String("socket-mode", "0600", "Socket permissions (octal); wider than 0600 needs --require-token")
+	return cmd
+}
+
+func newDaemonStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Report the daemon serving the current database",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := callDaemon(daemonRequest{Op: daemonOpPing})
+			if err != nil {
+				return cmdErr(err)
+			}
+			return output.PrintSuccess(resp.Status)
+		},
+	}
+}
+
+func newDaemonStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the daemon serving the current database",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := callDaemon(daemonRequest{Op: daemonOpStop})
+			if err != nil {
+				return cmdErr(err)
+			}
+			type stopResp struct {
+				Stopped bool          `json:"stopped"`
+				Status  *daemonStatus `json:"status"`
+			}
+			return output.PrintSuccess(stopResp{Stopped: true, Status: resp.Status})
+		},
+	}
+}
+
+func daemonSocketPath(dbPath string) (string, error) {
+	sock := dbPath + daemonSocketSuffix
+	if len(sock) > maxDaemonSocketPath {
+		return "", fmt.Errorf("daemon socket path too long (%d > %d bytes): %s", len(sock), maxDaemonSocketPath, sock)
+	}
+	return sock, nil
+}
+
+// callDaemon sends a control request to the daemon for the current database.
+func callDaemon(req daemonRequest) (*daemonResponse, error) {
+	dbPath, err := app.GetDBPath()
+	if err != nil {
+		return nil, err
+	}
+	sock, err := daemonSocketPath(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", sock, daemonDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("no daemon is serving %s", dbPath)
+	}
+	defer func() { _ = conn.Close() }()
+	req.Token = os.Getenv("VYBE_TOKEN")
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send daemon request: %w", err)
+	}
+	var resp daemonResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read daemon response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+func runDaemon(ctx context.Context, version string, opts daemonStartOptions) error {
+	dbPath, err := app.GetDBPath()
+	if err != nil {
+		return cmdErr(err)
+	}
+	sock, err := daemonSocketPath(dbPath)
+	if err != nil {
+		return cmdErr(err)
+	}
+	if conn, err := net.DialTimeout("unix", sock, daemonDialTimeout); err == nil {
+		_ = conn.Close()
+		return cmdErr(fmt.Errorf("a daemon is already serving %s", dbPath))
+	}
+	_ = os.Remove(sock) // stale socket from a crashed daemon
+
+	db, closeDB, err := openDB(ctx)
+	if err != nil {
+		return cmdErr(err)
+	}
+	defer closeDB()
+
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		return cmdErr(fmt.Errorf("failed to listen on %s: %w", sock, err))
+	}
+	defer func() { _ = os.Remove(sock) }()
+	if err := os.Chmod(sock, opts.SocketMode); err != nil {
+		_ = ln.Close()
+		return cmdErr(fmt.Errorf("failed to restrict socket permissions: %w", err))
+	}
+
+	srv := &daemonServer{version: version, db: db, status: daemonStatus{PID: os.Getpid(), Version: version,
+		DBPath: dbPath, Socket: sock, StartedAt: time.Now().UTC(), RequireToken: opts.RequireToken}}
+	daemonWarm.Store(srv)
+	defer daemonWarm.Store(nil)
+	if err := output.PrintSuccess(srv.status); err != nil {
+		_ = ln.Close()
+		return err
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv.serve(ctx, ln, opts.Idle)
+	return nil
+}
+
+// serve accepts connections until ctx is done, a stop request arrives, or
+// idle passes without a request.
+func (s *daemonServer) serve(ctx context.Context, ln net.Listener, idle time.Duration) {
+	var closeOnce sync.Once
+	shutdown := func() { closeOnce.Do(func() { _ = ln.Close() }) }
+	defer shutdown()
+
+	lastActive := atomic.Int64{}
+	lastActive.Store(time.Now().UnixNano())
+	go func() {
+		tick := time.NewTicker(time.Second)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				shutdown()
+				return
+			case <-tick.C:
+				if idle > 0 && time.Since(time.Unix(0, lastActive.Load())) > idle {
+					slog.Default().Info("daemon idle timeout", "idle", idle.String())
+					shutdown()
+					return
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			break
+		}
+		lastActive.Store(time.Now().UnixNano())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { _ = conn.Close() }()
+			if stopRequested := s.handleConn(ctx, conn); stopRequested {
+				shutdown()
+			}
+			lastActive.Store(time.Now().UnixNano())
+		}()
+	}
+	wg.Wait()
+}
+
+// handleConn serves one request and reports whether it asked the daemon to stop.
+func (s *daemonServer) handleConn(ctx context.Context, conn net.Conn) bool {
+	br := bufio.NewReader(conn)
+	line, err := br.ReadBytes('\n')
+	if err != nil {
+		return false
+	}
+	var req daemonRequest
+	var resp daemonResponse
+	if err := json.Unmarshal(line, &req); err != nil {
+		resp.Error = "invalid daemon request: " + err.Error()
+	} else {
+		status := s.status
+		status.Served = s.served.Load()
+		switch req.Op {
+		case daemonOpPing:
+			resp.Status = &status
+		case daemonOpStop:
+			if err := s.authorizeStop(ctx, req); err != nil {
+				resp.Error = err.Error()
+				break
+			}
+			resp.Status = &status
+		case daemonOpRun, "":
+			resp = s.runRequest(ctx, req, br)
+			s.served.Add(1)
+		default:
+			resp.Error = fmt.Sprintf("unknown daemon op %q", req.Op)
+		}
+	}
+	_ = json.NewEncoder(conn).Encode(resp)
+	return req.Op == daemonOpStop && resp.Error == ""
+}
+
+// runRequest executes one command line as if it ran in the client's process:
+// the client's args, env, and cwd, with stdin streamed from the connection and
+// stdout/stderr captured for the response. A token-protected daemon keeps its
+// own env and settings apart from daemonTokenEnv.
+func (s *daemonServer) runRequest(ctx context.Context, req daemonRequest, stdin io.Reader) daemonResponse {
+	s.run.Lock()
+	defer s.run.Unlock()
+
+	restore, err := s.enterRequestEnv(req)
+	if err != nil {
+		return daemonResponse{Error: err.Error()}
+	}
+	defer restore()
+
+	capture, err := swapStdio(stdin)
+	if err != nil {
+		return daemonResponse{Error: err.Error()}
+	}
+	var runErr error
+	if s.status.RequireToken {
+		runErr = s.authorizeRequest(ctx, req)
+		if runErr != nil {
+			output.SetFormat(output.FormatJSON)
+			_ = output.PrintError(runErr)
+		}
+	}
+	if runErr == nil {
+		runErr = executeArgs(s.version, req.Args)
+	}
+	stdout, stderr := capture()
+
+	resp := daemonResponse{Stdout: stdout, Stderr: stderr}
+	if runErr != nil {
+		resp.ExitCode = 1
+	}
+	return resp
+}
+
+// enterRequestEnv applies the client's env and cwd and resets per-process CLI
+// state; the returned func restores the daemon's own. On a token-protected
+// daemon only daemonTokenEnv crosses over, the database stays pinned to the
+// daemon's, and settings are never reloaded, so neither the client's env nor
+// a config.yaml in its cwd can change what the daemon runs.
+func (s *daemonServer) enterRequestEnv(req daemonRequest) (func(), error) {
+	prevEnv := os.Environ()
+	prevCwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read daemon cwd: %w", err)
+	}
+	if req.Cwd != "" {
+		if err := os.Chdir(req.Cwd); err != nil {
+			return nil, fmt.Errorf("failed to enter client cwd: %w", err)
+		}
+	}
+
+	if s.status.RequireToken {
+		setEnviron(mergeDaemonEnv(prevEnv, req.Env, daemonTokenEnv()))
+		app.SetDBPathOverride(s.status.DBPath)
+		return func() {
+			setEnviron(prevEnv)
+			_ = os.Chdir(prevCwd)
+			app.SetDBPathOverride("")
+		}, nil
+	}
+
+	setEnviron(req.Env)
+	app.SetDBPathOverride("")
+	_, _ = app.ReloadSettings() // env and ./config.yaml may differ per client
+
+	return func() {
+		setEnviron(prevEnv)
+		_ = os.Chdir(prevCwd)
+		app.SetDBPathOverride("")
+		_, _ = app.ReloadSettings()
+	}, nil
+}
+
+// mergeDaemonEnv returns base with the keys in allow taken from client instead:
+// set when the client sets them, unset when it does not.
+func mergeDaemonEnv(base, client, allow []string) []string {
+	allowed := func(kv string) bool {
+		k, _, _ := strings.Cut(kv, "=")
+		return slices.Contains(allow, k)
+	}
+	out := make([]string, 0, len(base))
+	for _, kv := range base {
+		if !allowed(kv) {
+			out = append(out, kv)
+		}
+	}
+	for _, kv := range client {
+		if allowed(kv) {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+func setEnviron(env []string) {
+	os.Clearenv()
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k != "" {
+			_ = os.Setenv(k, v)
+		}
+	}
+}
+
+// swapStdio points os.Stdin at stdin and captures os.Stdout, os.Stderr, and the
+// default logger. The returned func restores them and yields what was written.
+func swapStdio(stdin io.Reader) (func() ([]byte, []byte), error) {
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		_ = inR.Close()
+		_ = inW.Close()
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		for _, f := range []*os.File{inR, inW, outR, outW} {
+			_ = f.Close()
+		}
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	go func() {
+		_, _ = io.Copy(inW, stdin)
+		_ = inW.Close()
+	}()
+	var stdout, stderr bytes.Buffer
+	var drained sync.WaitGroup
+	drained.Add(2)
+	go func() { defer drained.Done(); _, _ = io.Copy(&stdout, outR) }()
+	go func() { defer drained.Done(); _, _ = io.Copy(&stderr, errR) }()
+
+	prevIn, prevOut, prevErr, prevLog := os.Stdin, os.Stdout, os.Stderr, slog.Default()
+	os.Stdin, os.Stdout, os.Stderr = inR, outW, errW
+	slog.SetDefault(slog.New(slog.NewJSONHandler(errW, nil)))
+
+	return func() ([]byte, []byte) {
+		os.Stdin, os.Stdout, os.Stderr = prevIn, prevOut, prevErr
+		slog.SetDefault(prevLog)
+		_ = outW.Close()
+		_ = errW.Close()
+		_ = inR.Close() // unblocks the stdin copier if the command never read it
+		drained.Wait()
+		_ = outR.Close()
+		_ = errR.Close()
+		return stdout.Bytes(), stderr.Bytes()
+	}, nil
+}
+
+// proxyToDaemon runs args through a daemon serving the resolved database.
+// ok is false when no daemon answered and the caller should run in-process.
+func proxyToDaemon(args []string) (error, bool) { //nolint:revive // error-return: ok reports whether the proxy handled the call
+	if os.Getenv("VYBE_NO_DAEMON") != "" || !daemonProxyable(args) || isTerminal(os.Stdin) {
+		return nil, false
+	}
+	dbPath, err := daemonClientDBPath(args)
+	if err != nil {
+		return nil, false
+	}
+	sock, err := daemonSocketPath(dbPath)
+	if err != nil {
+		return nil, false
+	}
+	if _, err := os.Stat(sock); err != nil {
+		return nil, false
+	}
+	conn, err := net.DialTimeout("unix", sock, daemonDialTimeout)
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = conn.Close() }()
+
+	cwd, _ := os.Getwd()
+	req := daemonRequest{Op: daemonOpRun, Args: args, Cwd: cwd, Env: os.Environ(), Token: os.Getenv("VYBE_TOKEN")}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, false // nothing ran yet; fall back
+	}
+	go func() {
+		_, _ = io.Copy(conn, os.Stdin)
+		if uc, ok := conn.(*net.UnixConn); ok {
+			_ = uc.CloseWrite()
+		}
+	}()
+
+	var resp daemonResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		// The command may have run; re-running could repeat a side effect.
+		_ = output.PrintError(fmt.Errorf("daemon connection lost: %w", err))
+		return printedError{err: err}, true
+	}
+	if resp.Error != "" {
+		_ = output.PrintError(fmt.Errorf("daemon: %s", resp.Error))
+		return printedError{err: errors.New(resp.Error)}, true
+	}
+	_, _ = os.Stdout.Write(resp.Stdout)
+	_, _ = os.Stderr.Write(resp.Stderr)
+	if resp.ExitCode != 0 {
+		return printedError{err: fmt.Errorf("exit code %d", resp.ExitCode)}, true
+	}
+	return nil, true
+}
+
+// daemonValueFlags returns the root persistent flags, long and short, whose
+// value may follow as a separate argument, so it is not mistaken for the
+// command name.
+func daemonValueFlags() map[string]bool {
+	fs := pflag.NewFlagSet("vybe", pflag.ContinueOnError)
+	addRootPersistentFlags(fs)
+	flags := map[string]bool{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.NoOptDefVal != "" {
+			return // bool flags take no separate value
+		}
+		flags["--"+f.Name] = true
+		if f.Shorthand != "" {
+			flags["-"+f.Shorthand] = true
+		}
+	})
+	return flags
+}
+
+func daemonProxyable(args []string) bool {
+	valueFlags := daemonValueFlags()
+	var words []string
+	for i := 0; i < len(args) && len(words) < 2; i++ {
+		a := args[i]
+		if strings.HasPrefix(a, "-") {
+			if valueFlags[a] {
+				i++
+			}
+			continue
+		}
+		words = append(words, a)
+		if daemonProxyExcluded[strings.Join(words, " ")] {
+			return false
+		}
+	}
+	return true
+}
+
+// daemonClientDBPath resolves the database a command line would open,
+// honoring --db-path without running the command.
+func daemonClientDBPath(args []string) (string, error) {
+	for i, a := range args {
+		var v string
+		switch {
+		case strings.HasPrefix(a, "--db-path="):
+			v = strings.TrimPrefix(a, "--db-path=")
+		case a == "--db-path" && i+1 < len(args):
+			v = args[i+1]
+		default:
+			continue
+		}
+		prev := app.DBPathOverride()
+		app.SetDBPathOverride(v)
+		defer app.SetDBPathOverride(prev)
+		break
+	}
+	return app.GetDBPath()
+}
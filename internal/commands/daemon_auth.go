@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// daemonRequiredRole is the token role cmd needs on a token-protected daemon:
+// admin for commands annotated "admin", agent for mutations and the hidden
+// hook handlers, read for everything else. The admin annotation covers only the
+// command that carries it, so "snapshot" (which writes --to-file) does not
+// raise "snapshot list" to admin.
+func daemonRequiredRole(cmd *cobra.Command) string {
+	if cmd.Annotations["admin"] == "true" {
+		return store.TokenRoleAdmin
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if isMutatingCommand(c) || c.Hidden {
+			return store.TokenRoleAgent
+		}
+	}
+	return store.TokenRoleRead
+}
+
+// daemonRunsExternal reports whether cmd, run with args, would start a
+// configured binary or call a configured endpoint. Commands opt in with the
+// "runs_external" annotation: "true" always, or the name of the bool flag
+// that turns it on (e.g. "llm"). Unparseable args count as external.
+func daemonRunsExternal(cmd *cobra.Command, args []string) bool {
+	flag, ok := cmd.Annotations["runs_external"]
+	if !ok {
+		return false
+	}
+	if flag == "true" {
+		return true
+	}
+	if err := cmd.ParseFlags(args); err != nil {
+		return true
+	}
+	on, err := cmd.Flags().GetBool(flag)
+	return err != nil || on
+}
+
+// daemonSetsOtelEndpoint reports whether args pass --otel-endpoint. A
+// token-protected daemon traces only to the collector in its own environment,
+// never to one the client names.
+func daemonSetsOtelEndpoint(args []string) bool {
+	for _, a := range args {
+		if a == "--otel-endpoint" || strings.HasPrefix(a, "--otel-endpoint=") {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeRequest checks a run request against a token-protected daemon. The
+// command must be one the daemon serves, must not run external programs as
+// the daemon's user or trace to a collector the client picks, and must open
+// the daemon's own database, so a token cannot reach another database through
+// --db-path.
+func (s *daemonServer) authorizeRequest(ctx context.Context, req daemonRequest) error {
+	if !daemonProxyable(req.Args) {
+		return &store.TokenAuthError{Reason: "command does not run through the daemon"}
+	}
+	dbPath, err := daemonClientDBPath(req.Args)
+	if err != nil {
+		return err
+	}
+	if dbPath != s.status.DBPath {
+		return &store.TokenAuthError{Reason: "request targets " + dbPath + ", not the daemon's database"}
+	}
+	cmd, args, err := newRootCmd(s.version).Find(req.Args)
+	if err != nil {
+		return err
+	}
+	if daemonRunsExternal(cmd, args) {
+		return &store.TokenAuthError{Reason: commandKey(cmd) + " runs external programs; run it outside a token-protected daemon"}
+	}
+	if daemonSetsOtelEndpoint(req.Args) {
+		return &store.TokenAuthError{Reason: "--otel-endpoint sends traces to an external endpoint; run it outside a token-protected daemon"}
+	}
+	_, err = s.authorizeToken(ctx, req.Token, daemonRequiredRole(cmd))
+	return err
+}
+
+// authorizeStop lets only admin tokens stop a token-protected daemon.
+func (s *daemonServer) authorizeStop(ctx context.Context, req daemonRequest) error {
+	if !s.status.RequireToken {
+		return nil
+	}
+	_, err := s.authorizeToken(ctx, req.Token, store.TokenRoleAdmin)
+	return err
+}
+
+// authorizeToken authenticates secret against the daemon's database and checks
+// its role covers required.
+func (s *daemonServer) authorizeToken(ctx context.Context, secret, required string) (*store.APIToken, error) {
+	db := s.db
+	if db == nil {
+		opened, closeDB, err := openDBAt(ctx, s.status.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		defer closeDB()
+		db = opened
+	}
+	tok, err := store.AuthenticateAPIToken(ctx, db, secret)
+	var authErr *store.TokenAuthError
+	if errors.As(err, &authErr) {
+		authErr.Required = required
+		return nil, authErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !store.TokenRoleAllows(tok.Role, required) {
+		return nil, &store.TokenAuthError{Reason: fmt.Sprintf("token %s has role %s", tok.ID, tok.Role), Required: required}
+	}
+	return tok, nil
+}
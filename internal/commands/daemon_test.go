@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestDaemonProxyable(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, daemonProxyable([]string{"task", "list"}))
+	assert.True(t, daemonProxyable([]string{"--agent", "loop", "task", "list"}), "flag value must not be taken as the command")
+	assert.True(t, daemonProxyable([]string{"--db-path", "daemon.db", "status"}))
+	assert.False(t, daemonProxyable([]string{"daemon", "start"}))
+	assert.False(t, daemonProxyable([]string{"-a", "worker", "loop"}))
+	assert.False(t, daemonProxyable([]string{"--agent=worker", "upgrade"}))
+	assert.False(t, daemonProxyable([]string{"task", "wait", "--id", "task_1"}))
+	assert.False(t, daemonProxyable([]string{"--format", "json", "loop"}), "flag value must not hide an excluded command")
+	assert.False(t, daemonProxyable([]string{"--format", "table", "task", "wait", "--id", "task_1"}))
+	assert.False(t, daemonProxyable([]string{"--format", "json", "daemon", "start"}))
+	assert.False(t, daemonProxyable([]string{"--otel-endpoint", "http://localhost:4318", "loop"}))
+	assert.False(t, daemonProxyable([]string{"--otel-endpoint", "http://localhost:4318", "upgrade"}))
+	assert.False(t, daemonProxyable([]string{"-q", "scenario"}), "bool flags take no value")
+}
+
+func TestDaemonValueFlagsMatchRootFlags(t *testing.T) {
+	t.Parallel()
+
+	got := daemonValueFlags()
+	newRootCmd("test").PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.NoOptDefVal == "" {
+			assert.True(t, got["--"+f.Name], f.Name)
+		}
+	})
+	assert.True(t, got["-a"])
+	assert.False(t, got["--quiet"])
+}
+
+func TestDaemonSocketPath(t *testing.T) {
+	t.Parallel()
+
+	sock, err := daemonSocketPath("/tmp/vybe.db")
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/vybe.db.sock", sock)
+
+	_, err = daemonSocketPath("/" + strings.Repeat("x", maxDaemonSocketPath) + ".db")
+	require.Error(t, err)
+}
+
+// TestDaemonRoundTrip serves one socket in-process and runs commands through it.
+// Not parallel: requests swap process-wide stdio, env, and cwd.
+func TestDaemonRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "d.db")
+	sock, err := daemonSocketPath(dbPath)
+	require.NoError(t, err)
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		srv := &daemonServer{version: "test", status: daemonStatus{DBPath: dbPath, Socket: sock}}
+		srv.serve(ctx, ln, time.Minute)
+		close(done)
+	}()
+
+	env := []string{"HOME=" + dir, "VYBE_DB_PATH=" + dbPath, "VYBE_AGENT=daemon-test"}
+	call := func(req daemonRequest) daemonResponse {
+		t.Helper()
+		conn, err := net.Dial("unix", sock)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+		require.NoError(t, json.NewEncoder(conn).Encode(req))
+		require.NoError(t, conn.(*net.UnixConn).CloseWrite())
+		var resp daemonResponse
+		require.NoError(t, json.NewDecoder(bufio.NewReader(conn)).Decode(&resp))
+		return resp
+	}
+
+	resp := call(daemonRequest{Op: daemonOpRun, Cwd: dir, Env: env,
+		Args: []string{"task", "create", "--title", "via daemon", "--request-id", "daemon-rt-1"}})
+	require.Empty(t, resp.Error)
+	require.Equal(t, 0, resp.ExitCode, string(resp.Stderr))
+	var created struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Task struct {
+				Title string `json:"title"`
+			} `json:"task"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Stdout, &created))
+	assert.True(t, created.Success)
+	assert.Equal(t, "via daemon", created.Data.Task.Title)
+
+	resp = call(daemonRequest{Op: daemonOpRun, Cwd: dir, Env: env, Args: []string{"task", "get", "--id", "nope"}})
+	assert.Equal(t, 1, resp.ExitCode)
+
+	resp = call(daemonRequest{Op: daemonOpStop})
+	require.NotNil(t, resp.Status)
+	assert.Equal(t, int64(2), resp.Status.Served)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+}
+
+// TestDaemonRequireToken checks a token-protected daemon enforces roles.
+// Not parallel: requests swap process-wide stdio, env, and cwd.
+func TestDaemonRequireToken(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "d.db")
+	db, closeDB, err := openDBAt(context.Background(), dbPath)
+	require.NoError(t, err)
+	reader, err := store.CreateAPITokenIdempotent(context.Background(), db, "admin", "tok-read", "reader", store.TokenRoleRead)
+	require.NoError(t, err)
+	admin, err := store.CreateAPITokenIdempotent(context.Background(), db, "admin", "tok-admin", "ops", store.TokenRoleAdmin)
+	require.NoError(t, err)
+	closeDB()
+
+	sock, err := daemonSocketPath(dbPath)
+	require.NoError(t, err)
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		srv := &daemonServer{version: "test", status: daemonStatus{DBPath: dbPath, Socket: sock, RequireToken: true}}
+		srv.serve(ctx, ln, time.Minute)
+		close(done)
+	}()
+
+	env := []string{"HOME=" + dir, "VYBE_DB_PATH=" + dbPath, "VYBE_AGENT=daemon-test"}
+	call := func(req daemonRequest) daemonResponse {
+		t.Helper()
+		conn, err := net.Dial("unix", sock)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+		require.NoError(t, json.NewEncoder(conn).Encode(req))
+		require.NoError(t, conn.(*net.UnixConn).CloseWrite())
+		var resp daemonResponse
+		require.NoError(t, json.NewDecoder(bufio.NewReader(conn)).Decode(&resp))
+		return resp
+	}
+	run := func(token string, args ...string) daemonResponse {
+		t.Helper()
+		return call(daemonRequest{Op: daemonOpRun, Cwd: dir, Env: env, Token: token, Args: args})
+	}
+
+	resp := run("", "task", "list")
+	assert.Equal(t, 1, resp.ExitCode)
+	assert.Contains(t, string(resp.Stdout), "UNAUTHORIZED")
+
+	resp = run(reader.Secret, "task", "list")
+	assert.Equal(t, 0, resp.ExitCode, string(resp.Stdout))
+
+	resp = run(reader.Secret, "task", "create", "--title", "nope", "--request-id", "daemon-tok-1")
+	assert.Equal(t, 1, resp.ExitCode)
+	assert.Contains(t, string(resp.Stdout), `"required_role":"agent"`)
+
+	// Commands that read or write a caller-chosen path need an admin token.
+	exported := filepath.Join(dir, "events.csv")
+	snapped := filepath.Join(dir, "snap.db")
+	for _, args := range [][]string{
+		{"events", "export", "--out", exported},
+		{"snapshot", "--to-file", snapped},
+		{"snapshot", "mount", "--file", dbPath, "--query", "SELECT 1"},
+	} {
+		resp = run(reader.Secret, args...)
+		assert.Equal(t, 1, resp.ExitCode, args)
+		assert.Contains(t, string(resp.Stdout), `"required_role":"admin"`, args)
+	}
+	assert.NoFileExists(t, exported)
+	assert.NoFileExists(t, snapped)
+
+	resp = run(reader.Secret, "snapshot", "list")
+	assert.Equal(t, 0, resp.ExitCode, string(resp.Stdout))
+
+	resp = run(admin.Secret, "--db-path", filepath.Join(dir, "other.db"), "task", "list")
+	assert.Equal(t, 1, resp.ExitCode, "a token must not reach another database")
+
+	resp = run(admin.Secret, "task", "create", "--title", "ok", "--request-id", "daemon-tok-2")
+	assert.Equal(t, 0, resp.ExitCode, string(resp.Stdout))
+
+	resp = run(admin.Secret, "hook", "retrospective", "--llm", "--request-id", "daemon-tok-3")
+	assert.Equal(t, 1, resp.ExitCode)
+	assert.Contains(t, string(resp.Stdout), "runs external programs")
+
+	// Traces go only to the daemon's own collector, never to one the client
+	// names by flag or environment.
+	var posts atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+	}))
+	defer collector.Close()
+	resp = run(admin.Secret, "--otel-endpoint", collector.URL, "task", "list")
+	assert.Equal(t, 1, resp.ExitCode)
+	assert.Contains(t, string(resp.Stdout), "--otel-endpoint")
+	resp = run(admin.Secret, "--otel-endpoint="+collector.URL, "task", "list")
+	assert.Equal(t, 1, resp.ExitCode)
+	resp = call(daemonRequest{Op: daemonOpRun, Cwd: dir, Token: reader.Secret, Args: []string{"task", "list"},
+		Env: append(slices.Clone(env), "OTEL_EXPORTER_OTLP_ENDPOINT="+collector.URL)})
+	assert.Equal(t, 0, resp.ExitCode, string(resp.Stdout))
+	assert.Zero(t, posts.Load())
+
+	// Only the identity variables cross over: the client's VYBE_DB_PATH is
+	// ignored rather than followed.
+	otherDB := filepath.Join(dir, "elsewhere.db")
+	resp = call(daemonRequest{Op: daemonOpRun, Cwd: dir, Token: reader.Secret, Args: []string{"whoami"},
+		Env: []string{"VYBE_DB_PATH=" + otherDB, "VYBE_AGENT=client-agent"}})
+	assert.Equal(t, 0, resp.ExitCode, string(resp.Stdout))
+	assert.Contains(t, string(resp.Stdout), "client-agent")
+	assert.NoFileExists(t, otherDB)
+
+	resp = call(daemonRequest{Op: daemonOpStop, Token: reader.Secret})
+	assert.NotEmpty(t, resp.Error)
+	resp = call(daemonRequest{Op: daemonOpStop, Token: admin.Secret})
+	require.NotNil(t, resp.Status)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+}
+
+func TestMergeDaemonEnv(t *testing.T) {
+	t.Parallel()
+
+	got := mergeDaemonEnv(
+		[]string{"PATH=/usr/bin", "VYBE_AGENT=daemon", "VYBE_TOKEN=old"},
+		[]string{"PATH=/tmp/evil", "VYBE_AGENT=client", "VYBE_DB_PATH=/tmp/x.db"},
+		daemonTokenEnv(),
+	)
+	assert.Equal(t, []string{"PATH=/usr/bin", "VYBE_AGENT=client"}, got)
+}
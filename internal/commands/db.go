@@ -0,0 +1,277 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewDBCmd creates the db command group for database upkeep.
+func NewDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Database upkeep: maintenance, online backups, and restore",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newDBMaintainCmd())
+	cmd.AddCommand(newDBBackupCmd())
+	cmd.AddCommand(newDBBackupsCmd())
+	cmd.AddCommand(newDBRestoreCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newDBMaintainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintain",
+		Short: "Check integrity, reclaim free pages, refresh planner stats, and truncate the WAL",
+		Long: `Maintain runs four steps and reports each one:
+
+  integrity   PRAGMA integrity_check (--quick uses quick_check)
+  vacuum      PRAGMA incremental_vacuum; needs incremental auto-vacuum, which
+              --full enables by rebuilding the file once with VACUUM
+  analyze     ANALYZE, so the query planner sees current table sizes
+  checkpoint  PRAGMA wal_checkpoint(TRUNCATE)
+
+An integrity failure is reported in integrity_ok/integrity_errors; the command
+still succeeds so the report can be read. Each run records a db_maintained event.
+
+--schedule saves db_maintain_every in the user config. The checkpoint hook then
+runs vacuum, analyze, and checkpoint (not the integrity check) whenever the last
+maintenance is older than that window. --schedule off turns it off.`,
+		Example: `  vybe db maintain --request-id "maint_$(date +%s)"
+  vybe db maintain --full --request-id maint_full_1
+  vybe db maintain --skip integrity --schedule 7d --request-id maint_2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			skip, _ := cmd.Flags().GetStringSlice("skip")
+			quick, _ := cmd.Flags().GetBool("quick")
+			full, _ := cmd.Flags().GetBool("full")
+			schedule, _ := cmd.Flags().GetString("schedule")
+
+			if schedule != "" && !strings.EqualFold(schedule, "off") {
+				if _, err := app.ParseRetentionDays(schedule); err != nil {
+					return cmdErr(fmt.Errorf("invalid --schedule: %w", err))
+				}
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			dbPath, err := app.GetDBPath()
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				*store.DBMaintainReport
+				Schedule string `json:"schedule,omitempty"`
+			}
+			r := resp{}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				r.DBMaintainReport, err = store.MaintainDB(cmd.Context(), db, dbPath, agentName, requestID,
+					store.DBMaintainOptions{Skip: skip, QuickCheck: quick, FullVacuum: full})
+				return err
+			}); err != nil {
+				return err
+			}
+
+			if schedule != "" {
+				value := strings.ToLower(schedule)
+				if value == "off" {
+					value = ""
+				}
+				if _, err := app.SetConfigValue("db_maintain_every", value, ""); err != nil {
+					return cmdErr(fmt.Errorf("maintenance ran but the schedule was not saved: %w", err))
+				}
+				r.Schedule = schedule
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().StringSlice("skip", nil, "Steps to skip: integrity,vacuum,analyze,checkpoint")
+	cmd.Flags().Bool("quick", false, "Use PRAGMA quick_check instead of the full integrity_check")
+	cmd.Flags().Bool("full", false, "Rebuild with VACUUM and switch to incremental auto-vacuum")
+	cmd.Flags().String("schedule", "", "Also run from the checkpoint hook at this interval (e.g. 7d, 2w, off)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
+	return cmd
+}
+
+func newDBBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Copy the live database to a file with SQLite's online backup API",
+		Long: `Backup copies the database page by page with SQLite's online backup API, so it
+is safe while agents keep writing: a write during the copy restarts it, and the
+file is always one consistent point in time. Copying vybe.db with cp while the
+WAL is active can produce a corrupt copy; use this instead.
+
+--out writes one file (it must not exist). --rolling writes
+vybe-<UTC time>.db into the rolling backup directory (backup.dir in config,
+default <db dir>/backups) and deletes all but the newest backup.keep (default 7).
+Restore a backup with 'db restore'.`,
+		Example: `  vybe db backup --out vybe-2025-06-01.db
+  vybe db backup --rolling`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, _ := cmd.Flags().GetString("out")
+			rolling, _ := cmd.Flags().GetBool("rolling")
+			if (out == "") == !rolling {
+				return cmdErr(errors.New("pass exactly one of --out or --rolling"))
+			}
+			dbPath, err := app.GetDBPath()
+			if err != nil {
+				return cmdErr(err)
+			}
+			dir, keep := app.RollingBackupConfig(dbPath)
+			if rolling {
+				out = store.RollingBackupPath(dir, time.Now())
+			}
+
+			type resp struct {
+				*store.SnapshotFileInfo
+				Pruned []string `json:"pruned,omitempty"`
+			}
+			r := resp{}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				r.SnapshotFileInfo, err = store.BackupDB(cmd.Context(), db, out)
+				return err
+			}); err != nil {
+				return err
+			}
+			if rolling {
+				if r.Pruned, err = store.PruneRollingBackups(dir, keep); err != nil {
+					return cmdErr(fmt.Errorf("backup written but pruning old backups failed: %w", err))
+				}
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("out", "", "Path of the backup file to write (must not exist)")
+	cmd.Flags().Bool("rolling", false, "Write a timestamped backup to the rolling backup directory and prune old ones")
+	cmd.Annotations = map[string]string{"admin": "true"}
+	return cmd
+}
+
+func newDBBackupsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "backups",
+		Short: "List rolling backups, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbPath, err := app.GetDBPath()
+			if err != nil {
+				return cmdErr(err)
+			}
+			dir, keep := app.RollingBackupConfig(dbPath)
+			backups, err := store.ListRollingBackups(dir)
+			if err != nil {
+				return cmdErr(err)
+			}
+			type resp struct {
+				Dir     string                `json:"dir"`
+				Keep    int                   `json:"keep"`
+				Backups []store.RollingBackup `json:"backups"`
+			}
+			return output.PrintSuccess(resp{Dir: dir, Keep: keep, Backups: backups})
+		},
+	}
+}
+
+func newDBRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Replace the live database with a backup",
+		Long: `Restore replaces the whole live database with a backup, in place, through the
+online backup API; other connections see either the old or the restored data.
+The backup must pass an integrity check and must not come from a newer vybe; an
+older schema is migrated. Everything written after the backup was taken is lost,
+so restore requires --yes (or a y at a terminal prompt), and --backup-first
+snapshots the current database before overwriting it.
+
+--from restores one file. --at restores the newest rolling backup taken at or
+before a time (RFC3339, or YYYY-MM-DD for the end of that UTC day). The restored
+database records a db_restored event.`,
+		Example: `  vybe db restore --from vybe-2025-06-01.db --yes --request-id restore_1
+  vybe db restore --at 2025-06-01T09:00:00Z --backup-first --yes --request-id restore_2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, _ := cmd.Flags().GetString("from")
+			atRaw, _ := cmd.Flags().GetString("at")
+			if (from == "") == (atRaw == "") {
+				return cmdErr(errors.New("pass exactly one of --from or --at"))
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			dbPath, err := app.GetDBPath()
+			if err != nil {
+				return cmdErr(err)
+			}
+			if atRaw != "" {
+				at, err := parseRestorePoint(atRaw)
+				if err != nil {
+					return cmdErr(err)
+				}
+				dir, _ := app.RollingBackupConfig(dbPath)
+				b, err := store.RollingBackupAt(dir, at)
+				if err != nil {
+					return cmdErr(err)
+				}
+				from = b.Path
+			}
+			if err := confirmDestructive(cmd, "replace the database with "+from); err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				*store.SnapshotFileInfo
+				EventID    int64  `json:"event_id"`
+				BackupPath string `json:"backup_path,omitempty"`
+			}
+			r := resp{}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if r.BackupPath, err = backupFirst(cmd, db, "db-restore"); err != nil {
+					return err
+				}
+				r.SnapshotFileInfo, r.EventID, err = store.RestoreDB(cmd.Context(), db, dbPath, from, agentName, requestID)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("from", "", "Backup file to restore")
+	cmd.Flags().String("at", "", "Restore the newest rolling backup at or before this time")
+	addDestructiveFlags(cmd)
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
+	return cmd
+}
+
+// parseRestorePoint parses --at: RFC3339, or a date meaning the end of that UTC day.
+func parseRestorePoint(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if d, err := time.Parse(time.DateOnly, raw); err == nil {
+		return d.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q: use RFC3339 or YYYY-MM-DD", raw)
+}
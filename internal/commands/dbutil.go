@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log/slog"
@@ -23,18 +24,27 @@ func (e printedError) Error() string {
 	return "error already printed"
 }
 
-func openDB() (*DB, func(), error) {
+func openDB(ctx context.Context) (*DB, func(), error) {
 	dbPath, err := app.GetDBPath()
 	if err != nil {
 		return nil, nil, err
 	}
+	return openDBAt(ctx, dbPath)
+}
+
+// openDBAt opens and migrates the database at dbPath, reusing the daemon's
+// warm handle when it serves that path.
+func openDBAt(ctx context.Context, dbPath string) (*DB, func(), error) {
+	if db := daemonWarmDB(dbPath); db != nil {
+		return db, func() {}, nil
+	}
 
-	db, err := store.OpenDB(dbPath)
+	db, err := store.OpenDB(ctx, dbPath)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if err := store.MigrateDB(db, dbPath); err != nil {
+	if err := store.MigrateDB(ctx, db, dbPath); err != nil {
 		_ = store.CloseDB(db)
 		return nil, nil, err
 	}
@@ -42,8 +52,8 @@ func openDB() (*DB, func(), error) {
 	return db, func() { _ = store.CloseDB(db) }, nil
 }
 
-func withDB(fn func(db *DB) error) error {
-	db, closeDB, err := openDB()
+func withDB(ctx context.Context, fn func(db *DB) error) error {
+	db, closeDB, err := openDB(ctx)
 	if err != nil {
 		return cmdErr(err)
 	}
@@ -59,13 +69,13 @@ func withDB(fn func(db *DB) error) error {
 // Used in hook handlers where stdout must never be corrupted with error JSON.
 // Retries openDB once on transient failures (e.g. migration lock contention).
 // fn errors are not retried — store operations already use RetryWithBackoff internally.
-func withDBSilent(fn func(db *DB) error) {
+func withDBSilent(ctx context.Context, fn func(db *DB) error) {
 	var db *DB
 	var closeDB func()
 	var err error
 
 	for attempt := range 2 {
-		db, closeDB, err = openDB()
+		db, closeDB, err = openDB(ctx)
 		if err == nil {
 			break
 		}
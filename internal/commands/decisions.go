@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// NewDecisionsCmd creates the decisions command group, a view over decision
+// records stored with 'memory set --type decision'.
+func NewDecisionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decisions",
+		Short: "List decision records kept in memory",
+		Long: `Decision records are memory entries with --type decision: what was decided,
+the context that forced it, the alternatives ruled out, and the consequences.
+Write them with 'vybe memory set --type decision'; the five most recent for the
+focus project (plus global ones) are carried into briefs and session-start context.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newDecisionsListCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newDecisionsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List decision records, newest first",
+		Long: `Lists live decision records, most recently updated first. With a project,
+lists that project's decisions, its tasks' decisions, and global ones; without,
+lists every decision. An agent confined to a project only sees that project's.`,
+		Example: `  vybe decisions list --project-dir "$PWD"`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID := projectScopeFlag(cmd)
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			var decisions []*models.Decision
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				if projectID, err = confinedProjectFilter(cmd, db, projectID); err != nil {
+					return err
+				}
+				decisions, err = actions.Decisions(cmd.Context(), db, projectID, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				ProjectID string             `json:"project_id,omitempty"`
+				Count     int                `json:"count"`
+				Decisions []*models.Decision `json:"decisions"`
+			}
+			return output.PrintSuccess(resp{ProjectID: projectID, Count: len(decisions), Decisions: decisions})
+		},
+	}
+
+	addProjectScopeFlags(cmd, "Project ID (its decisions plus global ones)")
+	cmd.Flags().Int("limit", 50, "Maximum decisions to return")
+	return cmd
+}
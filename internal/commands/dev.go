@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewDevCmd creates the dev command group for developer tooling.
+func NewDevCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Developer tooling (synthetic data for benchmarks and demos)",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newDevSeedCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newDevSeedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Populate the database with realistic synthetic tasks, dependencies, and events",
+		Long: `Seed creates a project named "seed" with a task dependency graph (chains,
+fan-in, hub fan-out), a realistic status mix, agents named seed-agent-1..N, and an
+event history spread over --days with a few hot tasks receiving most activity.
+
+The same --seed reproduces the same graph shape, statuses, and event mix.
+Seeding refuses to touch a database that already has tasks unless --force is
+given; point --db-path at a scratch file instead.`,
+		Example: `  vybe dev seed --db-path /tmp/bench.db --tasks 50 --events 5000 --agents 3`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tasks, _ := cmd.Flags().GetInt("tasks")
+			events, _ := cmd.Flags().GetInt("events")
+			agents, _ := cmd.Flags().GetInt("agents")
+			days, _ := cmd.Flags().GetInt("days")
+			seed, _ := cmd.Flags().GetUint64("seed")
+			force, _ := cmd.Flags().GetBool("force")
+
+			var res *store.SeedResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := store.SeedSyntheticData(cmd.Context(), db, store.SeedOptions{
+					Tasks:         tasks,
+					Events:        events,
+					Agents:        agents,
+					Days:          days,
+					Seed:          seed,
+					AllowExisting: force,
+				})
+				if err != nil {
+					return err
+				}
+				res = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().Int("tasks", 50, "Tasks to create")
+	cmd.Flags().Int("events", 5000, "Activity events to generate")
+	cmd.Flags().Int("agents", 3, "Agents to spread activity across")
+	cmd.Flags().Int("days", 14, "History window in days, ending now")
+	cmd.Flags().Uint64("seed", 1, "PRNG seed for reproducible output")
+	cmd.Flags().Bool("force", false, "Seed even if the database already contains tasks")
+	cmd.Annotations = map[string]string{"mutates": "true"}
+	return cmd
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewDoctorCmd creates the doctor command for data-integrity reports.
+func NewDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Report data-integrity problems (dangling references, network filesystem)",
+		Long: `Doctor inspects stored data without modifying it.
+
+--orphans lists events, memory, agent focus, and artifacts that reference a task,
+project, or agent that does not exist. Events of tasks deleted through vybe are
+history and are not reported. With no flags, every check runs.
+
+--filesystem reports the filesystem holding the database. On NFS or SMB, SQLite
+locking is unreliable: move the database to a local disk, or set network_fs: lock
+(VYBE_NETWORK_FS=lock) so vybe uses a rollback journal and one process at a time.
+
+Enable strict_references in config (or VYBE_STRICT_REFS=1) to reject new dangling
+references at write time.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			orphans, _ := cmd.Flags().GetBool("orphans")
+			filesystem, _ := cmd.Flags().GetBool("filesystem")
+			samples, _ := cmd.Flags().GetInt("samples")
+			all := !orphans && !filesystem
+
+			type resp struct {
+				StrictReferences bool                  `json:"strict_references"`
+				Orphans          *store.OrphanReport   `json:"orphans,omitempty"`
+				Filesystem       *store.FilesystemInfo `json:"filesystem,omitempty"`
+			}
+			r := resp{StrictReferences: app.StrictReferencesEnabled()}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if filesystem || all {
+					fs, err := databaseFilesystem(cmd.Context(), db)
+					if err != nil {
+						return err
+					}
+					r.Filesystem = fs
+				}
+				if orphans || all {
+					report, err := store.FindOrphans(cmd.Context(), db, samples)
+					if err != nil {
+						return err
+					}
+					r.Orphans = report
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().Bool("orphans", false, "Report dangling task/project/agent references")
+	cmd.Flags().Bool("filesystem", false, "Report whether the database is on a network filesystem")
+	cmd.Flags().Int("samples", 10, "Max sample IDs per category")
+	return cmd
+}
+
+// databaseFilesystem describes the filesystem of the open database, with the
+// effective network_fs mode and journal mode.
+func databaseFilesystem(ctx context.Context, db *DB) (*store.FilesystemInfo, error) {
+	dbPath, err := app.GetDBPath()
+	if err != nil {
+		return nil, err
+	}
+	if abs, err := filepath.Abs(dbPath); err == nil {
+		dbPath = abs
+	}
+	fs, err := store.DetectFilesystem(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	fs.Mode = app.NetworkFSMode()
+	if err := db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&fs.Journal); err != nil {
+		return nil, err
+	}
+	return &fs, nil
+}
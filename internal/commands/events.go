@@ -1,36 +1,309 @@
 package commands
 
 import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
 	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
 )
 
-// NewEventsCmd creates the events command.
+// NewEventsCmd creates the events command. Bare 'vybe events' is the same
+// listing as 'vybe events list'.
 func NewEventsCmd() *cobra.Command {
-	var (
-		all             bool
-		taskID          string
-		kind            string
-		limit           int
-		since           int64
-		asc             bool
-		includeArchived bool
-	)
+	var opts eventsListOptions
 
 	cmd := &cobra.Command{
 		Use:   "events",
 		Short: "List events from the event stream",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runEventsMode(cmd, all, taskID, kind, since, limit, asc, includeArchived)
+			return runEventsMode(cmd, opts)
+		},
+	}
+	bindEventsListFlags(cmd, &opts)
+
+	cmd.AddCommand(newEventsListCmd())
+	cmd.AddCommand(newEventsAddCmd())
+	cmd.AddCommand(newEventsKindsCmd())
+	cmd.AddCommand(newEventsPruneCmd())
+	cmd.AddCommand(newEventsTailCmd())
+	cmd.AddCommand(newEventsExportCmd())
+	cmd.AddCommand(newEventsDedupeCmd())
+	cmd.AddCommand(newEventsEnableChainCmd())
+	cmd.AddCommand(newEventsVerifyCmd())
+
+	return cmd
+}
+
+func newEventsListCmd() *cobra.Command {
+	var opts eventsListOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List events, optionally filtered on their metadata",
+		Long: `list returns events newest first. --meta filters on the structured metadata
+hooks and commands attach to events:
+
+  field op value [AND|OR field op value ...]
+
+op is one of = != > >= < <= and ~ (contains). Fields are metadata keys or
+dotted paths into nested objects; "tool" is short for tool_name and "event"
+for hook_event. Values are numbers, true/false, null, bare words, or quoted
+strings. AND binds tighter than OR; use parentheses to group.
+
+tool_name, hook_event, exit_code, and session_id are indexed.`,
+		Example: `  vybe events list --all --meta 'exit_code>0 AND tool=Bash'
+  vybe events list --all --kind tool_failure --meta 'tool=Edit OR tool=Write'
+  vybe events list --all --meta "file_path~'internal/store' AND session_id='$SESSION'"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEventsMode(cmd, opts)
+		},
+	}
+	bindEventsListFlags(cmd, &opts)
+	return cmd
+}
+
+func newEventsPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete events past their retention window",
+		Long: `Prune applies the effective retention policy: archived events older than the
+default window, plus every event of a kind with its own retention rule.
+Rules come from config.yaml (retention.*), then the [retention] table of the
+.vybe.toml governing an absolute --project-dir, then per-project overrides
+under projects.<project_id>.retention. Use --dry-run to preview counts per rule.
+Once the event chain is on (events enable-chain), prune deletes nothing.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			projectID, _ := cmd.Flags().GetString("project-dir")
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			dir := ""
+			if filepath.IsAbs(projectID) {
+				dir, projectID = projectID, resolveProjectID(projectID)
+			}
+			defaultDays, rules := retentionRulesFor(projectID, dir)
+
+			type resp struct {
+				ProjectID   string                 `json:"project_id,omitempty"`
+				DryRun      bool                   `json:"dry_run"`
+				DefaultDays int                    `json:"default_days"`
+				Rules       []store.RetentionRule  `json:"rules"`
+				Candidates  []store.PruneCandidate `json:"candidates,omitempty"`
+				Deleted     int64                  `json:"deleted"`
+			}
+			result := resp{ProjectID: projectID, DryRun: dryRun, DefaultDays: defaultDays, Rules: rules}
+
+			if dryRun {
+				if err := withDB(cmd.Context(), func(db *DB) error {
+					c, err := actions.PreviewEventsPrune(cmd.Context(), db, projectID, defaultDays, rules)
+					if err != nil {
+						return err
+					}
+					result.Candidates = c
+					return nil
+				}); err != nil {
+					return err
+				}
+				return output.PrintSuccess(result)
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				n, err := actions.AutoPruneEventsByRetentionIdempotent(cmd.Context(), db, agentName, requestID, projectID, defaultDays, rules, limit)
+				if err != nil {
+					return err
+				}
+				result.Deleted = n
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Report what would be deleted without deleting")
+	cmd.Flags().String("project-dir", "", "Restrict pruning to one project and apply its retention overrides")
+	cmd.Flags().Int("limit", 1000, "Maximum events to delete in one run")
+
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newEventsDedupeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Collapse identical events logged twice within a time window",
+		Long: `Dedupe finds active events identical in kind, agent, task, message, and
+metadata hash to an earlier event no more than --window after it (IDE hook
+retries). The first event is kept; later copies are archived with duplicate_of
+pointing at it, so they leave resume context and age out with retention.
+
+Use --dry-run to see the groups and per-kind stats without changing anything.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			windowRaw, _ := cmd.Flags().GetString("window")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			projectID, _ := cmd.Flags().GetString("project-dir")
+			sinceID, _ := cmd.Flags().GetInt64("since-id")
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			window, err := time.ParseDuration(windowRaw)
+			if err != nil || window <= 0 {
+				return cmdErr(fmt.Errorf("invalid --window %q: use a positive duration like 5s", windowRaw))
+			}
+			params := store.DedupeParams{Window: window, ProjectID: projectID, SinceID: sinceID, Limit: limit}
+
+			type resp struct {
+				DryRun bool `json:"dry_run"`
+				*store.DedupeStats
+			}
+
+			if dryRun {
+				var stats *store.DedupeStats
+				if err := withDB(cmd.Context(), func(db *DB) error {
+					var err error
+					stats, err = actions.PreviewEventsDedupe(cmd.Context(), db, params)
+					return err
+				}); err != nil {
+					return err
+				}
+				return output.PrintSuccess(resp{DryRun: true, DedupeStats: stats})
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			var stats *store.DedupeStats
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				stats, err = actions.DedupeEventsIdempotent(cmd.Context(), db, agentName, requestID, params)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(resp{DedupeStats: stats})
 		},
 	}
 
-	cmd.Flags().BoolVar(&all, "all", false, "List events across all agents (ignores --agent)")
-	cmd.Flags().StringVar(&taskID, "task-id", "", "Filter events by task ID")
-	cmd.Flags().StringVar(&kind, "kind", "", "Filter events by kind")
-	cmd.Flags().IntVar(&limit, "limit", 50, "Max events to return")
-	cmd.Flags().Int64Var(&since, "since-id", 0, "Only events with id > since-id")
-	cmd.Flags().BoolVar(&asc, "asc", false, "Sort oldest first (default newest first)")
-	cmd.Flags().BoolVar(&includeArchived, "include-archived", false, "Include archived events")
+	cmd.Flags().String("window", "5s", "Max time after the first event for an identical event to count as a duplicate")
+	cmd.Flags().Bool("dry-run", false, "Report duplicates without archiving them")
+	cmd.Flags().String("project-dir", "", "Restrict to one project")
+	cmd.Flags().Int64("since-id", 0, "Only scan events with id > since-id")
+	cmd.Flags().Int("limit", 10000, "Max events to scan")
+
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+// retentionRulesFor converts the effective retention policy for projectID into
+// store rules. When dir is set, the [retention] table of its .vybe.toml applies.
+func retentionRulesFor(projectID, dir string) (int, []store.RetentionRule) {
+	var cfg *app.ProjectConfig
+	if dir != "" {
+		var err error
+		if cfg, err = app.FindProjectConfig(dir); err != nil {
+			slog.Default().Warn("project config unreadable; skipping its retention rules", "error", err)
+		}
+	}
+	policy := app.EffectiveRetentionPolicyFor(projectID, cfg)
+	rules := make([]store.RetentionRule, 0, len(policy.KindDays))
+	for _, kind := range policy.Kinds() {
+		rules = append(rules, store.RetentionRule{Kind: kind, OlderThanDays: policy.KindDays[kind]})
+	}
+	return policy.DefaultDays, rules
+}
+
+func newEventsEnableChainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable-chain",
+		Short: "Start a tamper-evident hash chain over new events",
+		Long: `enable-chain makes every later event store the hash of the event before it
+and a hash over its own kind, agent, task, message, metadata, and timestamp, so
+'vybe events verify' detects an edited, removed, or truncated log. With --hmac the
+hashes are HMAC-SHA256 keyed by VYBE_AUDIT_KEY (audit.key_env names another
+variable); every writer and verifier then needs the key.
+
+The chain starts at an event_chain_started event and cannot be turned off.
+While it is on, events prune and the checkpoint hook delete no events and
+project purge is refused, because a deleted event breaks the chain.`,
+		Example: `  vybe events enable-chain --request-id chain-1
+  VYBE_AUDIT_KEY=... vybe events enable-chain --hmac --request-id chain-1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			signed, _ := cmd.Flags().GetBool("hmac")
 
+			var chain *store.EventChain
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				c, err := actions.EnableEventChainIdempotent(cmd.Context(), db, agentName, requestID, signed)
+				if err != nil {
+					return err
+				}
+				chain = c
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(chain)
+		},
+	}
+
+	cmd.Flags().Bool("hmac", false, "Sign the chain with HMAC-SHA256 using the audit key")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
 	return cmd
 }
+
+func newEventsVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Check the event chain for tampering or truncation",
+		Long: `verify recomputes the event chain from its start and reports each event whose
+hash no longer matches (hash_mismatch), whose link to the event before it is
+broken because an event was removed or rewritten (broken_link), or that was
+written without a hash (unchained), plus a log whose newest event is not the
+recorded head (truncated). A signed chain needs the audit key.
+
+The report is printed either way; a broken chain exits 1. Record head_hash
+somewhere outside the database to also catch a log rolled back as a whole.`,
+		Example: `  vybe events verify | jq '.data | {ok, checked, problems}'`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var report *store.EventChainReport
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.VerifyEventChain(cmd.Context(), db)
+				if err != nil {
+					return err
+				}
+				report = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			if err := output.PrintSuccess(report); err != nil {
+				return err
+			}
+			if !report.OK {
+				// Each problem is already in the printed report.
+				return printedError{err: fmt.Errorf("event chain broken: %d problem(s)", len(report.Problems))}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/export"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newEventsExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export events to CSV, JSONL, or Parquet for offline analysis",
+		Long: `Export writes events oldest-first with a flat schema (id, kind, agent_name,
+project_id, task_id, message, metadata, created_at) so DuckDB, pandas, or Spark can
+analyze agent behavior without opening the live database.
+
+The file is written to a temp path next to --out and renamed into place, so readers
+never see a partial export. --out - writes csv/jsonl to stdout.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatRaw, _ := cmd.Flags().GetString("format")
+			sinceRaw, _ := cmd.Flags().GetString("since")
+			out, _ := cmd.Flags().GetString("out")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			kind, _ := cmd.Flags().GetString("kind")
+			includeArchived, _ := cmd.Flags().GetBool("include-archived")
+
+			format, err := export.ParseFormat(formatRaw)
+			if err != nil {
+				return cmdErr(err)
+			}
+			if out == "" {
+				return cmdErr(errors.New("--out is required"))
+			}
+			if out == "-" && format == export.FormatParquet {
+				return cmdErr(errors.New("parquet cannot be written to stdout; pass a file path to --out"))
+			}
+			var since time.Time
+			if sinceRaw != "" {
+				since, err = actions.ParseSince(sinceRaw, time.Now())
+				if err != nil {
+					return cmdErr(fmt.Errorf("invalid --since: %w", err))
+				}
+			}
+			if projectDir != "" {
+				if abs, absErr := filepath.Abs(projectDir); absErr == nil {
+					projectDir = resolveProjectID(abs)
+				}
+			}
+			params := store.ExportEventsParams{
+				ProjectID:       projectDir,
+				Kind:            kind,
+				Since:           since,
+				IncludeArchived: includeArchived,
+			}
+
+			if out == "-" {
+				return withDB(cmd.Context(), func(db *DB) error {
+					if params.ProjectID, err = confinedProjectFilter(cmd, db, params.ProjectID); err != nil {
+						return err
+					}
+					_, err := exportEvents(cmd.Context(), db, params, format, os.Stdout)
+					return err
+				})
+			}
+
+			var count int
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if params.ProjectID, err = confinedProjectFilter(cmd, db, params.ProjectID); err != nil {
+					return err
+				}
+				n, err := exportEventsToFile(cmd.Context(), db, params, format, out)
+				count = n
+				return err
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Out    string     `json:"out"`
+				Format string     `json:"format"`
+				Since  *time.Time `json:"since,omitempty"`
+				Count  int        `json:"count"`
+			}
+			r := resp{Out: out, Format: string(format), Count: count}
+			if !since.IsZero() {
+				r.Since = &since
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("format", "csv", "Output format: csv|jsonl|parquet")
+	cmd.Flags().String("since", "", "Only events at or after this time: RFC3339, YYYY-MM-DD, or a window like 12h, 30d, 2w; default all")
+	cmd.Flags().String("out", "", "Output file path, or - for stdout (required)")
+	cmd.Flags().String("project-dir", "", "Restrict to one project")
+	cmd.Flags().String("kind", "", "Restrict to one event kind")
+	cmd.Flags().Bool("include-archived", false, "Include archived events")
+	cmd.Annotations = map[string]string{"admin": "true"}
+	return cmd
+}
+
+func exportEvents(ctx context.Context, db *DB, p store.ExportEventsParams, format export.Format, w *os.File) (int, error) {
+	ew, err := export.NewEventWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+	n, err := store.IterateEvents(ctx, db, p, func(e *models.Event) error { return ew.Write(e) })
+	if err != nil {
+		return n, err
+	}
+	return n, ew.Close()
+}
+
+// exportEventsToFile writes to a sibling temp file and renames it over path on success.
+func exportEventsToFile(ctx context.Context, db *DB, p store.ExportEventsParams, format export.Format, path string) (int, error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("create export file: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	n, err := exportEvents(ctx, db, p, format, tmp)
+	if err != nil {
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("close export file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("finalize export file: %w", err)
+	}
+	committed = true
+	return n, nil
+}
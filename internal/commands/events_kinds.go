@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newEventsAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Append one event to the log",
+		Long: `add appends a single event, like push with only an event. With --strict-kinds,
+or strict_kinds in config, a kind that is neither built in nor registered with
+'events kinds add' is rejected with UNKNOWN_EVENT_KIND.`,
+		Example: `  vybe events add --kind progress --message "tests green" --task-id "$TASK_ID" --request-id ev-1
+  vybe events add --kind deploy_started --message "deploy to prod" --metadata '{"env":"prod"}' --strict-kinds --request-id ev-2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			kind, _ := cmd.Flags().GetString("kind")
+			message, _ := cmd.Flags().GetString("message")
+			metadata, _ := cmd.Flags().GetString("metadata")
+			taskID, _ := cmd.Flags().GetString("task-id")
+			strict, _ := cmd.Flags().GetBool("strict-kinds")
+
+			if strings.TrimSpace(kind) == "" || strings.TrimSpace(message) == "" {
+				return cmdErr(errors.New("--kind and --message are required"))
+			}
+			input := actions.PushInput{
+				TaskID:      taskID,
+				Event:       &actions.PushEventInput{Kind: kind, Message: message},
+				StrictKinds: strict,
+			}
+			if metadata != "" {
+				if !json.Valid([]byte(metadata)) {
+					return cmdErr(fmt.Errorf("--metadata is not valid JSON"))
+				}
+				input.Event.Metadata = json.RawMessage(metadata)
+			}
+
+			var result *actions.PushResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.PushIdempotent(cmd.Context(), db, agentName, requestID, input)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				EventID int64  `json:"event_id"`
+				Kind    string `json:"kind"`
+			}
+			return output.PrintSuccess(resp{EventID: result.EventID, Kind: kind})
+		},
+	}
+
+	cmd.Flags().String("kind", "", "Event kind (required)")
+	cmd.Flags().String("message", "", "Event message (required)")
+	cmd.Flags().String("metadata", "", "Event metadata as a JSON object")
+	cmd.Flags().String("task-id", "", "Task the event belongs to")
+	cmd.Flags().Bool("strict-kinds", false, "Reject a kind that is not built in or registered")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newEventsKindsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kinds",
+		Short: "List known event kinds (built in and registered)",
+		Long: `kinds lists the event kind vocabulary: the kinds vybe writes itself and the
+custom kinds registered with 'events kinds add'. Strict kinds mode
+(strict_kinds in config, VYBE_STRICT_KINDS, or --strict-kinds on push and
+events add) rejects any other kind.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			customOnly, _ := cmd.Flags().GetBool("custom")
+
+			var kinds []store.EventKindInfo
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				k, err := store.ListEventKinds(cmd.Context(), db)
+				if err != nil {
+					return err
+				}
+				kinds = k
+				return nil
+			}); err != nil {
+				return err
+			}
+			if customOnly {
+				custom := make([]store.EventKindInfo, 0)
+				for _, k := range kinds {
+					if !k.Builtin {
+						custom = append(custom, k)
+					}
+				}
+				kinds = custom
+			}
+
+			return output.PrintSuccess(eventKindsResponse{Count: len(kinds), Kinds: kinds})
+		},
+	}
+
+	cmd.Flags().Bool("custom", false, "Only registered custom kinds")
+
+	cmd.AddCommand(newEventsKindsAddCmd())
+	cmd.AddCommand(newEventsKindsRemoveCmd())
+	return cmd
+}
+
+type eventKindsResponse struct {
+	Count int                   `json:"count"`
+	Kinds []store.EventKindInfo `json:"kinds"`
+}
+
+func newEventsKindsAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "add <kind>",
+		Short:   "Register a custom event kind (re-adding updates the description)",
+		Example: `  vybe events kinds add deploy_started --description "A deploy began; metadata.env names the target" --request-id k-1`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			description, _ := cmd.Flags().GetString("description")
+
+			var kind *store.EventKindInfo
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				k, err := actions.EventKindRegisterIdempotent(cmd.Context(), db, agentName, requestID, args[0], description)
+				if err != nil {
+					return err
+				}
+				kind = k
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(kind)
+		},
+	}
+
+	cmd.Flags().String("description", "", "What the kind means and which metadata it carries")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newEventsKindsRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <kind>",
+		Short: "Unregister a custom event kind (logged events keep it)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var kind *store.EventKindInfo
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				k, err := actions.EventKindRemoveIdempotent(cmd.Context(), db, agentName, requestID, args[0])
+				if err != nil {
+					return err
+				}
+				kind = k
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(kind)
+		},
+	}
+
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
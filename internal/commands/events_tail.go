@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newEventsTailCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "Stream events as JSONL (--follow blocks for new events)",
+		Long: `Tail writes one JSON event per line instead of the usual response envelope.
+
+Without --since-id it starts with the last --limit events, like tail(1). With
+--since-id it replays every event after that id, so a consumer that records the
+last id it processed can restart without missing events.
+
+--once (the default) exits after printing; --follow keeps the process open and
+emits events as other vybe processes commit them. --format=sse emits
+Server-Sent Events frames (id/event/data) for piping into an HTTP relay.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+			taskID, _ := cmd.Flags().GetString("task-id")
+			kind, _ := cmd.Flags().GetString("kind")
+			limit, _ := cmd.Flags().GetInt("limit")
+			sinceID, _ := cmd.Flags().GetInt64("since-id")
+			once, _ := cmd.Flags().GetBool("once")
+			follow, _ := cmd.Flags().GetBool("follow")
+			format, _ := cmd.Flags().GetString("format")
+			metaExpr, _ := cmd.Flags().GetString("meta")
+
+			if once && follow {
+				return cmdErr(errors.New("--once and --follow are mutually exclusive"))
+			}
+			if format != "jsonl" && format != "sse" {
+				return cmdErr(fmt.Errorf("invalid --format %q (must be jsonl or sse)", format))
+			}
+			if sinceID < 0 {
+				return cmdErr(errors.New("--since-id must be >= 0"))
+			}
+
+			agentName := resolveActorName(cmd, "")
+			if all {
+				agentName = ""
+			}
+			if !all && agentName == "" {
+				return cmdErr(errors.New("agent is required unless --all is set (set --agent or VYBE_AGENT)"))
+			}
+
+			meta, err := store.ParseMetaFilter(metaExpr)
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			params := store.ListEventsParams{AgentName: agentName, TaskID: taskID, Kind: kind, SinceID: sinceID, Meta: meta}
+			emit := eventLineWriter(cmd.OutOrStdout(), format)
+
+			return withDB(cmd.Context(), func(db *DB) error {
+				projectID, err := confinedProjectFilter(cmd, db, "")
+				if err != nil {
+					return err
+				}
+				if err := actions.CheckConfinedTask(cmd.Context(), db, resolveActorName(cmd, ""), taskID); err != nil {
+					return err
+				}
+				params.ProjectID = projectID
+				if !cmd.Flags().Changed("since-id") {
+					cursor, err := emitRecentEvents(cmd.Context(), db, params, limit, emit)
+					if err != nil {
+						return err
+					}
+					params.SinceID = cursor
+				}
+				if !follow {
+					if cmd.Flags().Changed("since-id") {
+						params.Limit = limit
+						events, err := store.ListEvents(cmd.Context(), db, params)
+						if err != nil {
+							return err
+						}
+						for _, e := range events {
+							if err := emit(e); err != nil {
+								return err
+							}
+						}
+					}
+					return nil
+				}
+
+				ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+				defer stop()
+				err = store.FollowEvents(ctx, db, params, store.FollowOptions{}, emit)
+				if errors.Is(err, context.Canceled) {
+					return nil
+				}
+				return err
+			})
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "Tail events across all agents (ignores --agent)")
+	cmd.Flags().String("task-id", "", "Filter events by task ID")
+	cmd.Flags().String("kind", "", "Filter events by kind")
+	cmd.Flags().String("meta", "", "Filter on metadata (same syntax as events list --meta)")
+	cmd.Flags().Int("limit", 10, "Events to print before following (or per --once page with --since-id)")
+	cmd.Flags().Int64("since-id", 0, "Resume after this event id (replays everything newer)")
+	cmd.Flags().Bool("once", false, "Print and exit (default)")
+	cmd.Flags().Bool("follow", false, "Block and stream new events until interrupted")
+	cmd.Flags().String("format", "jsonl", "Output format: jsonl|sse")
+	return cmd
+}
+
+// emitRecentEvents prints the last limit matching events oldest-first and returns
+// the cursor to follow from: the newest event id in the database, so events that
+// did not match the filters are not rescanned.
+func emitRecentEvents(ctx context.Context, db *DB, p store.ListEventsParams, limit int, emit func(*models.Event) error) (int64, error) {
+	cursor, err := store.LatestEventID(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	if limit <= 0 {
+		return cursor, nil
+	}
+	p.Limit = limit
+	p.Desc = true
+	events, err := store.ListEvents(ctx, db, p)
+	if err != nil {
+		return 0, err
+	}
+	slices.Reverse(events)
+	for _, e := range events {
+		if err := emit(e); err != nil {
+			return 0, err
+		}
+		cursor = max(cursor, e.ID)
+	}
+	return cursor, nil
+}
+
+// eventLineWriter returns an emitter that writes one event per line (jsonl) or
+// one Server-Sent Events frame per event (sse).
+func eventLineWriter(w io.Writer, format string) func(*models.Event) error {
+	return func(e *models.Event) error {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %d: %w", e.ID, err)
+		}
+		if format == "sse" {
+			_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Kind, b)
+		} else {
+			_, err = fmt.Fprintf(w, "%s\n", b)
+		}
+		return err
+	}
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// NewFederateCmd creates the federate command group: read-only queries merged
+// across several vybe databases.
+func NewFederateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "federate",
+		Short: "Query several vybe databases at once (read-only)",
+		Long: `Federate runs a read query against each database in --dbs and merges the
+results, tagging every row with a "source" column (the file's base name). Each
+database is opened read-only and never migrated, so it must already be on the
+current schema. A database that cannot be opened is reported in "sources"
+with its error, and the rest still answer.
+
+Project IDs are per database, so federated queries do not filter by project.`,
+		Example: `  vybe federate --dbs ~/src/api/.vybe.db,~/src/web/.vybe.db task list --status blocked
+  vybe federate --dbs a.db,b.db memory list --prefix build/`,
+		Args: cobra.NoArgs,
+	}
+	cmd.PersistentFlags().StringSlice("dbs", nil, "Comma-separated database paths (required)")
+
+	taskCmd := &cobra.Command{Use: "task", Short: "Federated task queries", Args: cobra.NoArgs}
+	taskCmd.AddCommand(newFederateTaskListCmd())
+	namespaceIndex(taskCmd)
+
+	memoryCmd := &cobra.Command{Use: "memory", Short: "Federated memory queries", Args: cobra.NoArgs}
+	memoryCmd.AddCommand(newFederateMemoryListCmd())
+	namespaceIndex(memoryCmd)
+
+	cmd.AddCommand(taskCmd, memoryCmd)
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func federatedSources(cmd *cobra.Command) ([]actions.FederatedSource, error) {
+	paths, _ := cmd.Flags().GetStringSlice("dbs")
+	if len(paths) == 0 {
+		return nil, errors.New("--dbs is required")
+	}
+	return actions.ParseFederatedSources(paths)
+}
+
+func newFederateTaskListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tasks across databases, highest priority first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statusFilter, _ := cmd.Flags().GetString("status")
+			priorityFilter, _ := cmd.Flags().GetInt("priority")
+			metaFilters, _ := cmd.Flags().GetStringArray("meta")
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			sources, err := federatedSources(cmd)
+			if err != nil {
+				return cmdErr(err)
+			}
+			tasks, err := actions.FederatedTaskList(cmd.Context(), sources, statusFilter, priorityFilter, metaFilters)
+			if err != nil {
+				return cmdErr(err)
+			}
+			total := len(tasks)
+			if limit > 0 && len(tasks) > limit {
+				tasks = tasks[:limit]
+			}
+
+			type resp struct {
+				Sources []actions.FederatedSource `json:"sources"`
+				Total   int                       `json:"total"`
+				Shown   int                       `json:"shown"`
+				Tasks   []actions.FederatedTask   `json:"tasks"`
+			}
+			if tasks == nil {
+				tasks = []actions.FederatedTask{}
+			}
+			return output.PrintSuccess(resp{Sources: sources, Total: total, Shown: len(tasks), Tasks: tasks})
+		},
+	}
+
+	cmd.Flags().String("status", "", "Filter by status: pending|in_progress|completed|blocked")
+	cmd.Flags().Int("priority", -1, "Filter by exact priority (default -1 = no filter)")
+	cmd.Flags().StringArray("meta", nil, "Filter by metadata key=value (repeatable; all must match)")
+	cmd.Flags().Int("limit", 100, "Max tasks to return after merging (0 = all)")
+	return cmd
+}
+
+func newFederateMemoryListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List one memory scope across databases",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scope, _ := cmd.Flags().GetString("scope")
+			scopeID, _ := cmd.Flags().GetString("scope-id")
+			prefix, _ := cmd.Flags().GetString("prefix")
+
+			sources, err := federatedSources(cmd)
+			if err != nil {
+				return cmdErr(err)
+			}
+			memories := actions.FederatedMemoryList(cmd.Context(), sources, scope, scopeID, prefix)
+			if memories == nil {
+				memories = []actions.FederatedMemory{}
+			}
+
+			type resp struct {
+				Sources  []actions.FederatedSource `json:"sources"`
+				Scope    string                    `json:"scope"`
+				ScopeID  string                    `json:"scope_id,omitempty"`
+				Prefix   string                    `json:"prefix,omitempty"`
+				Count    int                       `json:"count"`
+				Memories []actions.FederatedMemory `json:"memories"`
+			}
+			return output.PrintSuccess(resp{Sources: sources, Scope: scope, ScopeID: scopeID, Prefix: prefix, Count: len(memories), Memories: memories})
+		},
+	}
+
+	cmd.Flags().StringP("scope", "s", "global", "Scope (global, project, task, agent)")
+	cmd.Flags().String("scope-id", "", "Scope ID (required for non-global scopes)")
+	cmd.Flags().String("prefix", "", "Only keys at or under this hierarchical prefix (e.g. build/)")
+	return cmd
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewFilesCmd creates the files command group: a per-file change journal built
+// from successful Write/Edit/MultiEdit/NotebookEdit tool events.
+func NewFilesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "files",
+		Short: "Per-file change journal built from successful edit tool events",
+		Long: `The file journal aggregates tool_success events for Write, Edit, MultiEdit, and
+NotebookEdit into one entry per file: how many edits, by which tools, agents,
+and tasks, and when. Events come from the tool-success hook
+(hooks.tool_success.enabled) and from 'vybe ingest transcript'.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newFilesTouchedCmd())
+	cmd.AddCommand(newFilesHotCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+type filesResponse struct {
+	SessionID string             `json:"session_id,omitempty"`
+	Files     []store.FileChange `json:"files"`
+	Count     int                `json:"count"`
+}
+
+func newFilesTouchedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "touched",
+		Short: "List files changed by a task, session, or project, most recent first",
+		Long: `Touched lists the files edited under the given filters, most recently changed
+first. With no --task, --session, --project, or --since it reports the agent's
+previous session: the latest one that has ended, else the latest one, so a
+resuming agent sees what it last modified.`,
+		Example: `  vybe files touched --task task_123
+  vybe files touched --agent claude
+  vybe files touched --project "$PWD" --since 2d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("task")
+			sessionID, _ := cmd.Flags().GetString("session")
+			projectID, _ := cmd.Flags().GetString("project")
+			sinceRaw, _ := cmd.Flags().GetString("since")
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			p := store.FileJournalParams{TaskID: taskID, SessionID: sessionID, ProjectID: projectID}
+			if sinceRaw != "" {
+				days, err := app.ParseRetentionDays(sinceRaw)
+				if err != nil {
+					return cmdErr(fmt.Errorf("invalid --since: %w", err))
+				}
+				p.SinceDays = days
+			}
+			if filepath.IsAbs(p.ProjectID) {
+				p.ProjectID = resolveProjectID(filepath.Clean(p.ProjectID))
+			}
+			var agentName string
+			if p == (store.FileJournalParams{}) {
+				var err error
+				if agentName, err = requireActorName(cmd, ""); err != nil {
+					return cmdErr(err)
+				}
+			}
+
+			resp := filesResponse{}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if agentName != "" {
+					id, err := previousSessionID(cmd.Context(), db, agentName)
+					if err != nil {
+						return err
+					}
+					p.SessionID, resp.SessionID = id, id
+				}
+				var err error
+				resp.Files, err = actions.FilesTouched(cmd.Context(), db, p, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+			resp.Count = len(resp.Files)
+			return output.PrintSuccess(resp)
+		},
+	}
+
+	cmd.Flags().String("task", "", "Only edits attributed to this task")
+	cmd.Flags().String("session", "", "Only edits from this session")
+	cmd.Flags().String("project", "", "Only edits in this project (ID or directory)")
+	cmd.Flags().String("since", "", "Only edits newer than this window (e.g. 7d, 2w)")
+	cmd.Flags().Int("limit", 100, "Max files to return (0 = all)")
+	return cmd
+}
+
+// previousSessionID returns the agent's latest ended session, or its latest
+// session when none has ended.
+func previousSessionID(ctx context.Context, db *DB, agentName string) (string, error) {
+	sessions, err := actions.SessionList(ctx, db, store.ListSessionsParams{AgentName: agentName, Limit: 10})
+	if err != nil {
+		return "", err
+	}
+	if len(sessions) == 0 {
+		return "", errors.New("no sessions recorded for agent " + agentName + "; pass --task, --session, --project, or --since")
+	}
+	for _, s := range sessions {
+		if s.EndedAt != nil {
+			return s.ID, nil
+		}
+	}
+	return sessions[0].ID, nil
+}
+
+func newFilesHotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hot",
+		Short: "List the most frequently edited files in a window",
+		Long: `Hot ranks files by the number of edits in the last --since window, most edits
+first (ties: most recently changed first). Frequently rewritten files often mark
+unstable code or work that keeps getting redone.`,
+		Example: `  vybe files hot --since 7d
+  vybe files hot --project "$PWD" --since 30d --limit 10`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project")
+			sinceRaw, _ := cmd.Flags().GetString("since")
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			sinceDays, err := app.ParseRetentionDays(sinceRaw)
+			if err != nil {
+				return cmdErr(fmt.Errorf("invalid --since: %w", err))
+			}
+			if filepath.IsAbs(projectID) {
+				projectID = resolveProjectID(filepath.Clean(projectID))
+			}
+
+			resp := filesResponse{}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				resp.Files, err = actions.FilesHot(cmd.Context(), db, projectID, sinceDays, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+			resp.Count = len(resp.Files)
+			return output.PrintSuccess(resp)
+		},
+	}
+
+	cmd.Flags().String("project", "", "Only edits in this project (ID or directory; default: all projects)")
+	cmd.Flags().String("since", "7d", "Window to rank (e.g. 7d, 2w)")
+	cmd.Flags().Int("limit", 20, "Max files to return (0 = all)")
+	return cmd
+}
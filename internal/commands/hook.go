@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -9,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/store"
 	"github.com/spf13/cobra"
@@ -18,12 +20,15 @@ import (
 func NewHookCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "hook",
-		Short: "Hook handlers and installers for Claude/OpenCode",
+		Short: "Hook handlers and installers for Claude/OpenCode/Cursor/Gemini/Codex",
 		Args:  cobra.NoArgs,
 	}
 
 	cmd.AddCommand(newHookInstallCmd())
 	cmd.AddCommand(newHookUninstallCmd())
+	cmd.AddCommand(newHookDoctorCmd())
+	cmd.AddCommand(newHookTestCmd())
+	cmd.AddCommand(newHookRetrospectiveCmd())
 
 	// Hook handler subcommands — called by the hook system, not agents directly.
 	// Hidden from help output to reduce command surface noise.
@@ -31,9 +36,16 @@ func NewHookCmd() *cobra.Command {
 		newHookSessionStartCmd(),
 		newHookPromptCmd(),
 		newHookToolFailureCmd(),
+		newHookToolSuccessCmd(),
+		newHookPreToolCmd(),
 		newHookCheckpointCmd(),
 		newHookTaskCompletedCmd(),
+		newHookSubagentStartCmd(),
+		newHookSubagentStopCmd(),
 		newHookSessionEndCmd(),
+		newHookCursorCmd(),
+		newHookGeminiCmd(),
+		newHookCodexCmd(),
 	} {
 		sub.Hidden = true
 		cmd.AddCommand(sub)
@@ -59,7 +71,7 @@ Register via 'vybe hook install'.
 This runs alongside any existing SessionStart hooks — no conflicts.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: whenHookEnabled(app.HookSessionStart, func(cmd *cobra.Command, args []string) error {
 			hctx := resolveHookContext(cmd)
 
 			// On compact, the model already has session context — skip full resume.
@@ -67,17 +79,17 @@ This runs alongside any existing SessionStart hooks — no conflicts.`,
 			// track of what it's working on after context compression.
 			if hctx.Input.Source == "compact" {
 				var reminder string
-				withDBSilent(func(db *DB) error {
+				withDBSilent(cmd.Context(), func(db *DB) error {
 					// Ensure project focus is maintained
-					if hctx.CWD != "" {
-						_, _ = store.EnsureProjectByID(db, hctx.CWD, filepath.Base(hctx.CWD))
+					if hctx.ProjectID != "" {
+						_, _ = store.EnsureProjectByID(cmd.Context(), db, hctx.ProjectID, filepath.Base(hctx.ProjectID))
 					}
 
-					state, err := store.GetAgentState(db, hctx.AgentName)
-					if err != nil || state == nil || state.FocusTaskID == "" {
+					focusTaskID := resolveHookFocusTaskID(cmd.Context(), db, hctx)
+					if focusTaskID == "" {
 						return nil
 					}
-					task, err := store.GetTask(db, state.FocusTaskID)
+					task, err := store.GetTask(cmd.Context(), db, focusTaskID)
 					if err != nil || task == nil {
 						return nil
 					}
@@ -102,27 +114,36 @@ This runs alongside any existing SessionStart hooks — no conflicts.`,
 
 			requestID := hookRequestID("session", hctx.AgentName)
 
-			var prompt string
-			if err := withDB(func(db *DB) error {
+			var prompt, profileName string
+			if err := withDB(cmd.Context(), func(db *DB) error {
 				// Ensure project exists before setting focus scope
-				if hctx.CWD != "" {
-					if _, err := store.EnsureProjectByID(db, hctx.CWD, filepath.Base(hctx.CWD)); err != nil {
-						slog.Default().Warn("project ensure failed", "error", err, "cwd", hctx.CWD)
+				if hctx.ProjectID != "" {
+					if _, err := store.EnsureProjectByID(cmd.Context(), db, hctx.ProjectID, filepath.Base(hctx.ProjectID)); err != nil {
+						slog.Default().Warn("project ensure failed", "error", err, "project", hctx.ProjectID)
 					} else {
-						if _, err := store.SetAgentFocusProjectWithEventIdempotent(db, hctx.AgentName, requestID+"_projfocus", hctx.CWD); err != nil {
-							slog.Default().Warn("project focus failed", "error", err, "cwd", hctx.CWD)
+						if _, err := store.SetAgentFocusProjectWithEventIdempotent(cmd.Context(), db, hctx.AgentName, requestID+"_projfocus", hctx.ProjectID); err != nil {
+							slog.Default().Warn("project focus failed", "error", err, "project", hctx.ProjectID)
 						}
 					}
 				}
 
-				r, err := actions.ResumeWithOptionsIdempotent(db, hctx.AgentName, requestID, actions.ResumeOptions{
-					EventLimit: 100,
-					ProjectDir: hctx.CWD,
+				if hctx.Input.SessionID != "" {
+					if _, err := store.StartSessionIdempotent(cmd.Context(), db, hctx.AgentName, requestID+"_session",
+						hctx.Input.SessionID, hctx.ProjectID, hctx.Input.Source); err != nil {
+						slog.Default().Warn("session start record failed", "error", err, "session", hctx.Input.SessionID)
+					}
+				}
+
+				r, err := actions.ResumeWithOptionsIdempotent(cmd.Context(), db, hctx.AgentName, requestID, actions.ResumeOptions{
+					EventLimit:  100,
+					ProjectDir:  hctx.ProjectID,
+					FocusPolicy: app.FocusPolicyFor(hctx.CWD),
+					SessionID:   hctx.focusSessionID(),
 				})
 				if err != nil {
 					return err
 				}
-				prompt = r.Prompt
+				prompt, profileName = r.Prompt, r.ContextProfile
 				return nil
 			}); err != nil {
 				// Hooks must never block Claude Code — log diagnostic and exit clean.
@@ -130,7 +151,9 @@ This runs alongside any existing SessionStart hooks — no conflicts.`,
 				return nil
 			}
 
-			prevContext := readPreviousSessionContext(hctx.CWD, hctx.Input.SessionID)
+			profile, _ := app.LookupContextProfile(profileName)
+			labelOf := func(sessionID string) string { return hookSessionLabel(cmd.Context(), sessionID) }
+			prevContext := readPreviousSessionContext(hctx.CWD, hctx.Input.SessionID, profile.TranscriptRunes, labelOf)
 			if prevContext != "" {
 				prompt += "\n" + prevContext
 			}
@@ -144,10 +167,22 @@ This runs alongside any existing SessionStart hooks — no conflicts.`,
 
 			enc := json.NewEncoder(os.Stdout)
 			return enc.Encode(out)
-		},
+		}),
 	}
 }
 
+// hookSessionLabel returns the recorded label of sessionID, or "".
+func hookSessionLabel(ctx context.Context, sessionID string) string {
+	var label string
+	withDBSilent(ctx, func(db *DB) error {
+		if s, err := store.GetSession(ctx, db, sessionID); err == nil {
+			label = s.Label
+		}
+		return nil
+	})
+	return label
+}
+
 // newHookPromptCmd creates the user-prompt-submit hook handler.
 //
 // Usage:
@@ -163,7 +198,7 @@ logs the prompt as a user_prompt event in vybe.
 Register via 'vybe hook install'.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: whenHookEnabled(app.HookPrompt, func(cmd *cobra.Command, args []string) error {
 			hctx := resolveHookContext(cmd)
 			if hctx.Input.Prompt == "" {
 				return nil
@@ -175,44 +210,53 @@ Register via 'vybe hook install'.`,
 			requestID := hookRequestID("prompt", hctx.AgentName)
 
 			// Hooks must never block Claude Code — errors are swallowed.
-			withDBSilent(func(db *DB) error {
+			withDBSilent(cmd.Context(), func(db *DB) error {
 				metadata, _ := json.Marshal(map[string]string{
 					"source":        defaultAgentName,
 					"session_id":    hctx.Input.SessionID,
 					"hook_event":    hctx.Input.HookEventName,
 					"resume_source": hctx.Input.Source,
 				})
-				_, _ = appendEventWithFocusTask(
-					db, hctx.AgentName, requestID, models.EventKindUserPrompt, hctx.CWD, "", msg, string(metadata),
+				focusTaskID := resolveHookFocusTaskID(cmd.Context(), db, hctx)
+				_, _ = appendEventWithFocusTask(cmd.Context(),
+					db, hctx.AgentName, requestID, models.EventKindUserPrompt, hctx.ProjectID, focusTaskID, msg, string(metadata),
 				)
 
+				// Detect trigger words for rich summary
+				lower := strings.ToLower(strings.TrimSpace(hctx.Input.Prompt))
+				isTrigger := lower == "brief me" || lower == "remember" ||
+					lower == "remember?" || lower == "brief" ||
+					lower == "what's pending" || lower == "status"
+
+				// The first real prompt names the session; trigger words make poor names.
+				if !isTrigger {
+					if _, err := actions.SessionAutoLabelIdempotent(cmd.Context(), db, hctx.AgentName, requestID+"_label",
+						hctx.Input.SessionID, hctx.Input.Prompt); err != nil {
+						slog.Default().Warn("session label failed", "error", err, "session", hctx.Input.SessionID)
+					}
+				}
+
 				// Inject task context into model. Richer output for trigger words.
-				state, err := store.LoadOrCreateAgentState(db, hctx.AgentName)
+				state, err := store.LoadOrCreateAgentState(cmd.Context(), db, hctx.AgentName)
 				if err != nil {
 					return err
 				}
 
 				focusProjectID := state.FocusProjectID
-				if hctx.CWD != "" {
-					focusProjectID = hctx.CWD
+				if hctx.ProjectID != "" {
+					focusProjectID = hctx.ProjectID
 				}
 
-				// Detect trigger words for rich summary
-				lower := strings.ToLower(strings.TrimSpace(hctx.Input.Prompt))
-				isTrigger := lower == "brief me" || lower == "remember" ||
-					lower == "remember?" || lower == "brief" ||
-					lower == "what's pending" || lower == "status"
-
 				if isTrigger {
-					return emitRichBrief(db, hctx.AgentName, state.FocusTaskID, focusProjectID)
+					return emitRichBrief(cmd.Context(), db, hctx.AgentName, focusTaskID, focusProjectID)
 				}
 
 				// Non-trigger: lightweight reminder if focus task exists
-				if state.FocusTaskID == "" {
+				if focusTaskID == "" {
 					return nil
 				}
 
-				brief, err := store.BuildBrief(db, state.FocusTaskID, focusProjectID, hctx.AgentName)
+				brief, err := store.BuildBrief(cmd.Context(), db, focusTaskID, focusProjectID, hctx.AgentName)
 				if err != nil {
 					return err
 				}
@@ -237,7 +281,7 @@ Register via 'vybe hook install'.`,
 			})
 
 			return nil
-		},
+		}),
 	}
 }
 
@@ -247,7 +291,7 @@ func newHookToolFailureCmd() *cobra.Command {
 		Short:         "PostToolUseFailure hook — logs failed tool calls to vybe",
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: whenHookEnabled(app.HookToolFailure, withWriteBehind("tool-failure", func(cmd *cobra.Command, args []string) error {
 			hctx := resolveHookContext(cmd)
 			if hctx.Input.ToolName == "" {
 				return nil
@@ -262,9 +306,9 @@ func newHookToolFailureCmd() *cobra.Command {
 			metadata := buildToolMetadata(hctx.Input)
 
 			// Hooks must never block Claude Code — log diagnostic and exit clean.
-			if err := withDB(func(db *DB) error {
-				_, err := appendEventWithFocusTask(
-					db, hctx.AgentName, requestID, models.EventKindToolFailure, hctx.CWD, "", msg, metadata,
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				_, err := appendEventWithFocusTask(cmd.Context(),
+					db, hctx.AgentName, requestID, models.EventKindToolFailure, hctx.ProjectID, resolveHookFocusTaskID(cmd.Context(), db, hctx), msg, metadata,
 				)
 				return err
 			}); err != nil {
@@ -272,7 +316,38 @@ func newHookToolFailureCmd() *cobra.Command {
 			}
 
 			return nil
-		},
+		})),
+	}
+}
+
+func newHookToolSuccessCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:           "tool-success",
+		Short:         "PostToolUse hook — logs successful tool calls to vybe (off unless hooks.tool_success.enabled)",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: whenHookEnabled(app.HookToolSuccess, withWriteBehind("tool-success", func(cmd *cobra.Command, args []string) error {
+			hctx := resolveHookContext(cmd)
+			if hctx.Input.ToolName == "" {
+				return nil
+			}
+
+			requestID := hookRequestID("tool_success", hctx.AgentName)
+			msg := fmt.Sprintf("%s succeeded", hctx.Input.ToolName)
+			metadata := buildToolMetadata(hctx.Input)
+
+			// Hooks must never block Claude Code — log diagnostic and exit clean.
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				_, err := appendEventWithFocusTask(cmd.Context(),
+					db, hctx.AgentName, requestID, models.EventKindToolSuccess, hctx.ProjectID, resolveHookFocusTaskID(cmd.Context(), db, hctx), msg, metadata,
+				)
+				return err
+			}); err != nil {
+				slog.Default().Error("tool-success hook failed", "error", err, "tool_name", hctx.Input.ToolName)
+			}
+
+			return nil
+		})),
 	}
 }
 
@@ -282,22 +357,22 @@ func newHookCheckpointCmd() *cobra.Command {
 		Short:         "PreCompact hook — checkpoint maintenance",
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: whenHookEnabled(app.HookCheckpoint, withWriteBehind("checkpoint", func(cmd *cobra.Command, args []string) error {
 			_ = os.Setenv(disableExternalLLMEnv, "1")
 			slog.Default().Debug("LLM subprocess execution disabled for hook", "env", disableExternalLLMEnv)
 
 			hctx := resolveHookContext(cmd)
 			requestIDPrefix := hookRequestID("checkpoint", hctx.AgentName)
 
-			if err := withDB(func(db *DB) error {
-				runCheckpoint(db, hctx, requestIDPrefix)
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				runCheckpoint(cmd.Context(), db, hctx, requestIDPrefix)
 				return nil
 			}); err != nil {
 				slog.Default().Error("checkpoint hook failed", "error", err, "hook_event", hctx.Input.HookEventName)
 			}
 
 			return nil
-		},
+		})),
 	}
 }
 
@@ -307,7 +382,7 @@ func newHookTaskCompletedCmd() *cobra.Command {
 		Short:         "TaskCompleted hook — logs completion signals to vybe",
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: whenHookEnabled(app.HookTaskCompleted, withWriteBehind("task-completed", func(cmd *cobra.Command, args []string) error {
 			hctx := resolveHookContext(cmd)
 			requestID := hookRequestID("task_completed", hctx.AgentName)
 
@@ -335,15 +410,15 @@ func newHookTaskCompletedCmd() *cobra.Command {
 			}
 
 			// Hooks must never block Claude Code — log diagnostic and exit clean.
-			if err := withDB(func(db *DB) error {
+			if err := withDB(cmd.Context(), func(db *DB) error {
 				// Best-effort: promote task to completed status
 				taskID := hctx.Input.TaskID
 				if taskID == "" {
-					taskID = resolveAgentFocusTaskID(db, hctx.AgentName)
+					taskID = resolveHookFocusTaskID(cmd.Context(), db, hctx)
 				}
 				if taskID != "" {
 					statusReqID := hookRequestID("task_done", hctx.AgentName)
-					_, _, statusErr := actions.TaskSetStatusIdempotent(
+					_, _, statusErr := actions.TaskSetStatusIdempotent(cmd.Context(),
 						db, hctx.AgentName, statusReqID, taskID, "completed", "",
 					)
 					if statusErr != nil {
@@ -352,10 +427,10 @@ func newHookTaskCompletedCmd() *cobra.Command {
 					}
 				}
 
-				// Prefer explicit task_id from hook payload, fall back to agent's current focus
-				_, err := appendEventWithFocusTask(
+				// Prefer explicit task_id from hook payload, fall back to the session's or agent's focus
+				_, err := appendEventWithFocusTask(cmd.Context(),
 					db, hctx.AgentName, requestID, "task_completed_signal",
-					hctx.CWD, hctx.Input.TaskID, "TaskCompleted hook fired", string(metadata),
+					hctx.ProjectID, taskID, "TaskCompleted hook fired", string(metadata),
 				)
 				return err
 			}); err != nil {
@@ -363,18 +438,19 @@ func newHookTaskCompletedCmd() *cobra.Command {
 			}
 
 			return nil
-		},
+		})),
 	}
 }
 
-// newHookSessionEndCmd creates a SessionEnd hook that runs checkpoint only.
+// newHookSessionEndCmd creates a SessionEnd hook that closes the session row
+// and runs checkpoint.
 func newHookSessionEndCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:           "session-end",
-		Short:         "SessionEnd hook — best-effort checkpoint",
+		Short:         "SessionEnd hook — close the session and run a best-effort checkpoint",
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: whenHookEnabled(app.HookSessionEnd, withWriteBehind("session-end", func(cmd *cobra.Command, args []string) error {
 			_ = os.Setenv(disableExternalLLMEnv, "1")
 			slog.Default().Debug("LLM subprocess execution disabled for hook", "env", disableExternalLLMEnv)
 
@@ -382,14 +458,21 @@ func newHookSessionEndCmd() *cobra.Command {
 			sessionID := hctx.Input.SessionID
 			requestIDPrefix := stableHookRequestID("session_end", hctx.AgentName, sessionID)
 
-			if err := withDB(func(db *DB) error {
-				runCheckpoint(db, hctx, requestIDPrefix)
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if sessionID != "" {
+					reason, _ := hctx.Input.Raw["reason"].(string)
+					if _, err := store.EndSessionIdempotent(cmd.Context(), db, hctx.AgentName, requestIDPrefix+"_end",
+						sessionID, reason, ""); err != nil {
+						slog.Default().Warn("session end record failed", "error", err, "session", sessionID)
+					}
+				}
+				runCheckpoint(cmd.Context(), db, hctx, requestIDPrefix)
 				return nil
 			}); err != nil {
 				slog.Default().Error("session-end checkpoint failed", "error", err)
 			}
 
 			return nil
-		},
+		})),
 	}
 }
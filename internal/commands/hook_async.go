@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+// hookWriteBehindChildEnv marks the detached child of a write-behind hook so it
+// runs the handler synchronously instead of forking again.
+const hookWriteBehindChildEnv = "VYBE_HOOK_WRITE_BEHIND_CHILD"
+
+// hookPayloadKey carries stdin bytes already consumed by the write-behind wrapper
+// or a hook dispatcher.
+type hookPayloadKey struct{}
+
+// hookAgentKey carries the fallback agent identity a hook dispatcher sets for
+// the handler it routes to (see resolveHookContext).
+type hookAgentKey struct{}
+
+// withHookDispatch stores payload and the dispatcher's fallback agent on cmd's
+// context, so a Claude Code handler run from a dispatcher neither re-reads
+// stdin nor falls back to the "claude" identity.
+func withHookDispatch(cmd *cobra.Command, payload []byte, fallbackAgent string) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, hookPayloadKey{}, payload)
+	cmd.SetContext(context.WithValue(ctx, hookAgentKey{}, fallbackAgent))
+}
+
+// withWriteBehind wraps a write-only hook handler. When the hook kind is enabled
+// via hook_async (or VYBE_HOOK_ASYNC), the parent buffers stdin, hands it to a
+// detached child re-running the invoked hook command, and exits 0 without
+// touching the database. The child re-runs the command rather than `hook <kind>`
+// so dispatchers such as `vybe hook gemini` route the payload the same way.
+// A crash of the child loses that one event; any spawn failure falls back to
+// running the handler inline, so enabling the mode never drops events on its own.
+func withWriteBehind(kind string, run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if os.Getenv(hookWriteBehindChildEnv) != "" || !app.HookWriteBehindEnabled(kind) {
+			return run(cmd, args)
+		}
+
+		payload, buffered := bufferedHookPayload(cmd)
+		if !buffered {
+			var err error
+			payload, err = io.ReadAll(io.LimitReader(os.Stdin, maxHookStdinBytes))
+			if err != nil {
+				payload = nil
+			}
+		}
+		if err := spawnWriteBehind(cmd, payload); err != nil {
+			slog.Default().Warn("hook write-behind spawn failed; running inline", "hook", kind, "error", err)
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			cmd.SetContext(context.WithValue(ctx, hookPayloadKey{}, payload))
+			return run(cmd, args)
+		}
+		return nil
+	}
+}
+
+// spawnWriteBehind starts a detached child that replays payload on stdin.
+// The payload goes through an unlinked temp file rather than a pipe so the
+// parent never blocks on a full pipe buffer and can exit immediately.
+func spawnWriteBehind(cmd *cobra.Command, payload []byte) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolve executable: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "vybe-hook-*.json")
+	if err != nil {
+		return fmt.Errorf("buffer payload: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if _, err := f.Write(payload); err != nil {
+		return fmt.Errorf("buffer payload: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("buffer payload: %w", err)
+	}
+
+	args := []string{"hook", cmd.Name()}
+	if agent := writeBehindAgent(cmd, payload); agent != "" {
+		args = append(args, "--agent", agent)
+	}
+	if fl := cmd.Flags().Lookup("db-path"); fl != nil && fl.Changed {
+		args = append(args, "--db-path", fl.Value.String())
+	}
+
+	//nolint:gosec // G204: re-executes this binary with a fixed hook subcommand
+	child := exec.Command(exe, args...)
+	child.Env = append(os.Environ(), hookWriteBehindChildEnv+"=1")
+	child.Stdin = f
+	detachWriteBehind(child)
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("start child: %w", err)
+	}
+	// The child outlives this process; never wait on it.
+	return child.Process.Release()
+}
+
+// writeBehindAgent resolves the identity the hook would run as here (see
+// resolveHookContext), so the child records events under the same agent even
+// though it no longer carries the dispatcher's context. Empty leaves the child
+// to its own fallback.
+func writeBehindAgent(cmd *cobra.Command, payload []byte) string {
+	cwd := parseHookInput(payload).CWD
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	if name := resolveActorIdentityWith(cmd, "", actorLookupIn(cwd)).Name; name != "" {
+		return name
+	}
+	if ctx := cmd.Context(); ctx != nil {
+		if agent, ok := ctx.Value(hookAgentKey{}).(string); ok {
+			return agent
+		}
+	}
+	return ""
+}
+
+// bufferedHookPayload returns stdin bytes captured by withWriteBehind or a hook
+// dispatcher, if any.
+func bufferedHookPayload(cmd *cobra.Command) ([]byte, bool) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		return nil, false
+	}
+	b, ok := ctx.Value(hookPayloadKey{}).([]byte)
+	return b, ok
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package commands
+
+import "os/exec"
+
+func detachWriteBehind(*exec.Cmd) {}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package commands
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachWriteBehind starts the child in its own session so the host's hook
+// timeout, which signals the parent's process group, does not kill it.
+func detachWriteBehind(child *exec.Cmd) {
+	child.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
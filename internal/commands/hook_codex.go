@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// codexAgentName is the agent identity the Codex notify hook uses when none is configured.
+const codexAgentName = "codex"
+
+// newHookCodexCmd creates the notify program registered in the Codex config by
+// 'vybe hook install --codex'. Codex runs it after each agent turn with the
+// event JSON as the last argument (stdin is read when no argument is given).
+// On agent-turn-complete it opens the session on the first turn of a thread,
+// logs the turn's prompts and the assistant's reply (the prompt hook kind), and
+// runs checkpoint maintenance (the checkpoint hook kind).
+func newHookCodexCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:           "codex [payload]",
+		Short:         "Codex notify hook — logs Codex turns to vybe",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var payload []byte
+			if len(args) == 1 {
+				payload = []byte(args[0])
+			} else {
+				payload = readHookPayload()
+			}
+			withHookDispatch(cmd, payload, codexAgentName)
+			hctx := resolveHookContext(cmd)
+			if hctx.Input.HookEventName != "agent-turn-complete" {
+				return nil
+			}
+
+			_ = os.Setenv(disableExternalLLMEnv, "1")
+			withDBSilent(cmd.Context(), func(db *DB) error {
+				if app.HookKindEnabled(app.HookPrompt) {
+					handleCodexTurn(cmd.Context(), db, hctx)
+				}
+				if app.HookKindEnabled(app.HookCheckpoint) {
+					runCheckpoint(cmd.Context(), db, hctx, hookRequestID("codex_turn", hctx.AgentName))
+				}
+				return nil
+			})
+			return nil
+		},
+	}
+}
+
+// handleCodexTurn records one Codex turn: a Codex thread is one vybe session,
+// and the assistant's last message becomes an assistant_summary event.
+func handleCodexTurn(ctx context.Context, db *DB, hctx hookContext) {
+	handleClientPrompt(ctx, db, hctx, codexAgentName)
+
+	reply, _ := hctx.Input.Raw["last-assistant-message"].(string)
+	reply = strings.Join(strings.Fields(reply), " ")
+	if reply == "" {
+		return
+	}
+	msg, _ := truncateString(reply, 500)
+	turnID, _ := hctx.Input.Raw["turn-id"].(string)
+	metadata, _ := json.Marshal(map[string]string{
+		"source":     codexAgentName,
+		"session_id": hctx.Input.SessionID,
+		"turn_id":    turnID,
+		"hook_event": hctx.Input.HookEventName,
+	})
+	if _, err := appendEventWithFocusTask(ctx, db, hctx.AgentName, hookRequestID("codex_reply", hctx.AgentName),
+		models.EventKindAssistantSummary, hctx.ProjectID, resolveHookFocusTaskID(ctx, db, hctx), msg, string(metadata)); err != nil {
+		slog.Default().Warn("codex reply log failed", "error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// cursorAgentName is the agent identity Cursor hooks use when none is configured.
+const cursorAgentName = "cursor"
+
+// newHookCursorCmd creates the single handler registered for Cursor hook events
+// by 'vybe hook install --cursor'. It dispatches on hook_event_name:
+//
+//   - beforeSubmitPrompt: opens the session on the first prompt of a
+//     conversation, ensures the project, and logs the prompt.
+//   - stop: runs checkpoint maintenance.
+//
+// Cursor reads a JSON response from stdout; the handler always allows the
+// prompt and never fails the hook, even when the prompt or checkpoint hook
+// kind is disabled.
+func newHookCursorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:           "cursor",
+		Short:         "Cursor hook — session, prompt, and stop handling for Cursor",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hctx := resolveHookContextAs(cmd, cursorAgentName)
+
+			switch hctx.Input.HookEventName {
+			case "beforeSubmitPrompt":
+				if app.HookKindEnabled(app.HookPrompt) {
+					withDBSilent(cmd.Context(), func(db *DB) error {
+						handleClientPrompt(cmd.Context(), db, hctx, cursorAgentName)
+						return nil
+					})
+				}
+				return writeCursorResponse(map[string]any{"continue": true})
+			case "stop":
+				if !app.HookKindEnabled(app.HookCheckpoint) {
+					break
+				}
+				_ = os.Setenv(disableExternalLLMEnv, "1")
+				withDBSilent(cmd.Context(), func(db *DB) error {
+					runCheckpoint(cmd.Context(), db, hctx, hookRequestID("cursor_stop", hctx.AgentName))
+					return nil
+				})
+			}
+			return writeCursorResponse(map[string]any{})
+		},
+	}
+}
+
+// handleClientPrompt records a prompt from an agent CLI without a session-start
+// hook (Cursor, Codex): it ensures the project, opens the session on the first
+// prompt of a conversation, and logs the prompt with client as its source.
+func handleClientPrompt(ctx context.Context, db *DB, hctx hookContext, client string) {
+	if hctx.ProjectID != "" {
+		if _, err := store.EnsureProjectByID(ctx, db, hctx.ProjectID, filepath.Base(hctx.ProjectID)); err != nil {
+			slog.Default().Warn("project ensure failed", "error", err, "project", hctx.ProjectID)
+		}
+	}
+
+	// One session per conversation: the stable request id makes every later
+	// prompt a replay of the first start.
+	if sessionID := hctx.Input.SessionID; sessionID != "" {
+		requestID := stableHookRequestID(client+"_session", hctx.AgentName, sessionID)
+		if _, err := store.StartSessionIdempotent(ctx, db, hctx.AgentName, requestID, sessionID, hctx.ProjectID, "startup"); err != nil {
+			slog.Default().Warn("session start record failed", "error", err, "session", sessionID)
+		}
+		if hctx.ProjectID != "" {
+			if _, err := store.SetAgentFocusProjectWithEventIdempotent(ctx, db, hctx.AgentName, requestID+"_projfocus", hctx.ProjectID); err != nil {
+				slog.Default().Warn("project focus failed", "error", err, "project", hctx.ProjectID)
+			}
+		}
+	}
+
+	if hctx.Input.Prompt == "" {
+		return
+	}
+	msg, _ := truncateString(hctx.Input.Prompt, 500)
+	metadata, _ := json.Marshal(map[string]string{
+		"source":     client,
+		"session_id": hctx.Input.SessionID,
+		"hook_event": hctx.Input.HookEventName,
+	})
+	if _, err := appendEventWithFocusTask(ctx, db, hctx.AgentName, hookRequestID(client+"_prompt", hctx.AgentName),
+		models.EventKindUserPrompt, hctx.ProjectID, resolveHookFocusTaskID(ctx, db, hctx), msg, string(metadata)); err != nil {
+		slog.Default().Warn("prompt log failed", "client", client, "error", err)
+	}
+}
+
+// writeCursorResponse writes the JSON object Cursor reads from hook stdout.
+func writeCursorResponse(resp map[string]any) error {
+	return json.NewEncoder(os.Stdout).Encode(resp)
+}
@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// hookTestHandlers maps the Claude Code hook events 'vybe hook test' can
+// synthesize onto the handler 'vybe hook install' registers for them.
+var hookTestHandlers = map[string]func() *cobra.Command{ //nolint:gochecknoglobals // fixed lookup table of hook handler constructors
+	"SessionStart":       newHookSessionStartCmd,
+	"UserPromptSubmit":   newHookPromptCmd,
+	"PostToolUseFailure": newHookToolFailureCmd,
+	"PostToolUse":        newHookToolSuccessCmd,
+	"PreToolUse":         newHookPreToolCmd,
+	"PreCompact":         newHookCheckpointCmd,
+	"SessionEnd":         newHookSessionEndCmd,
+	"TaskCompleted":      newHookTaskCompletedCmd,
+	"SubagentStart":      newHookSubagentStartCmd,
+	"SubagentStop":       newHookSubagentStopCmd,
+}
+
+func hookTestEventNames() []string {
+	names := make([]string, 0, len(hookTestHandlers))
+	for name := range hookTestHandlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hookTestPayload synthesizes the stdin payload Claude Code sends for event.
+func hookTestPayload(cmd *cobra.Command, event, cwd string) map[string]any {
+	session, _ := cmd.Flags().GetString("session")
+	payload := map[string]any{
+		"hook_event_name": event,
+		"session_id":      session,
+		"cwd":             cwd,
+	}
+	switch event {
+	case "SessionStart":
+		payload["source"], _ = cmd.Flags().GetString("source")
+	case "UserPromptSubmit":
+		payload["prompt"], _ = cmd.Flags().GetString("prompt")
+	case "PostToolUseFailure":
+		tool, _ := cmd.Flags().GetString("tool-name")
+		payload["tool_name"] = tool
+		payload["tool_input"] = map[string]any{"command": "false"}
+		payload["error"] = "hook test: synthetic failure"
+	case "PreToolUse":
+		tool, _ := cmd.Flags().GetString("tool-name")
+		command, _ := cmd.Flags().GetString("command")
+		file, _ := cmd.Flags().GetString("file")
+		input := map[string]any{}
+		if command != "" {
+			input["command"] = command
+		}
+		if file != "" {
+			input["file_path"] = file
+		}
+		payload["tool_name"] = tool
+		payload["tool_input"] = input
+	case "PostToolUse":
+		tool, _ := cmd.Flags().GetString("tool-name")
+		payload["tool_name"] = tool
+		payload["tool_input"] = map[string]any{"command": "true"}
+		payload["tool_response"] = map[string]any{"stdout": ""}
+	case "PreCompact":
+		payload["trigger"] = "manual"
+	case "SessionEnd":
+		payload["reason"] = "other"
+	case "SubagentStart", "SubagentStop":
+		payload["agent_type"], _ = cmd.Flags().GetString("subagent")
+		payload["agent_id"] = "hook-test-subagent"
+	}
+	return payload
+}
+
+// newHookTestCmd creates 'vybe hook test', which runs a hook handler on a
+// synthesized payload against a scratch copy of the database and reports what
+// the handler printed (the context an agent would receive) and which events it
+// wrote. The real database is only read.
+func newHookTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Dry-run a hook handler and show what it would inject",
+		Long: `Synthesizes the payload Claude Code sends for --event, runs the vybe handler
+registered for it against a scratch copy of the database, and prints:
+
+  - payload: the synthesized stdin payload
+  - output: what the handler wrote to stdout, decoded when it is JSON
+  - additional_context: the context injected into the model, if any
+  - permission_decision: the PreToolUse guard's deny or ask, if any
+  - events: the events the handler would have recorded
+
+The real database is copied, never written, and external LLM calls are
+disabled. A hook kind disabled in config (hooks.<kind>.enabled) produces no
+output and no events. Use it to debug a broken integration: if output is empty here, the
+agent receives nothing either.`,
+		Example: `  vybe hook test --event SessionStart
+  vybe hook test --event UserPromptSubmit --prompt "fix the flaky test" --cwd ~/src/app
+  vybe hook test --event PostToolUseFailure --tool-name Bash
+  vybe hook test --event PreToolUse --command "rm -rf build"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			event, _ := cmd.Flags().GetString("event")
+			newHandler, ok := hookTestHandlers[event]
+			if !ok {
+				return cmdErr(fmt.Errorf("unknown --event %q (valid: %s)", event, strings.Join(hookTestEventNames(), ", ")))
+			}
+			cwd, _ := cmd.Flags().GetString("cwd")
+			if cwd == "" {
+				cwd, _ = os.Getwd()
+			}
+			if abs, err := filepath.Abs(cwd); err == nil {
+				cwd = abs
+			}
+
+			payload := hookTestPayload(cmd, event, cwd)
+			raw, err := json.Marshal(payload)
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			res, err := runHookTest(cmd, newHandler(), raw)
+			if err != nil {
+				return cmdErr(err)
+			}
+			res.Event = event
+			res.Handler = newHandler().Name()
+			res.Payload = payload
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().String("event", "SessionStart", "Hook event: "+strings.Join(hookTestEventNames(), ", "))
+	cmd.Flags().String("cwd", "", "Working directory in the payload (default: current directory)")
+	cmd.Flags().String("session", "hook-test", "Session ID in the payload")
+	cmd.Flags().String("source", "startup", "SessionStart source: startup, resume, clear, or compact")
+	cmd.Flags().String("prompt", "hook test prompt", "UserPromptSubmit prompt text")
+	cmd.Flags().String("tool-name", "Bash", "PreToolUse, PostToolUse, and PostToolUseFailure tool name")
+	cmd.Flags().String("command", "", "PreToolUse Bash command")
+	cmd.Flags().String("file", "", "PreToolUse file path for edit tools")
+	cmd.Flags().String("subagent", "general-purpose", "SubagentStart and SubagentStop sub-agent type")
+	return cmd
+}
+
+type hookTestResult struct {
+	Event              string          `json:"event"`
+	Handler            string          `json:"handler"`
+	Payload            map[string]any  `json:"payload"`
+	Output             any             `json:"output"`
+	AdditionalContext  string          `json:"additional_context,omitempty"`
+	PermissionDecision string          `json:"permission_decision,omitempty"`
+	Events             []*models.Event `json:"events"`
+	Error              string          `json:"error,omitempty"`
+}
+
+// runHookTest copies the database to a scratch file, runs handler on payload
+// against it with stdout captured, and collects the events it wrote.
+func runHookTest(cmd *cobra.Command, handler *cobra.Command, payload []byte) (*hookTestResult, error) {
+	dir, err := os.MkdirTemp("", "vybe-hook-test-*")
+	if err != nil {
+		return nil, fmt.Errorf("create scratch directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	scratch := filepath.Join(dir, "vybe.db")
+
+	db, closeDB, err := openDB(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+	err = store.VacuumInto(cmd.Context(), db, scratch)
+	closeDB()
+	if err != nil {
+		return nil, err
+	}
+
+	sdb, err := store.OpenDB(cmd.Context(), scratch)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = store.CloseDB(sdb) }()
+	before, err := store.LatestEventID(cmd.Context(), sdb)
+	if err != nil {
+		return nil, err
+	}
+
+	prevOverride := app.DBPathOverride()
+	app.SetDBPathOverride(scratch)
+	defer app.SetDBPathOverride(prevOverride)
+	for _, env := range []string{hookWriteBehindChildEnv, disableExternalLLMEnv} {
+		prev, had := os.LookupEnv(env)
+		_ = os.Setenv(env, "1")
+		defer func() {
+			if had {
+				_ = os.Setenv(env, prev)
+			} else {
+				_ = os.Unsetenv(env)
+			}
+		}()
+	}
+
+	stdout, runErr := captureHookStdout(func() error {
+		withHookDispatch(cmd, payload, defaultAgentName)
+		return handler.RunE(cmd, nil)
+	})
+	if stdout == nil {
+		return nil, runErr
+	}
+
+	res := &hookTestResult{Output: string(stdout)}
+	if runErr != nil {
+		res.Error = runErr.Error()
+	}
+	var decoded hookOutput
+	if trimmed := strings.TrimSpace(string(stdout)); trimmed == "" {
+		res.Output = nil
+	} else if json.Unmarshal([]byte(trimmed), &decoded) == nil {
+		var anyOut any
+		_ = json.Unmarshal([]byte(trimmed), &anyOut)
+		res.Output = anyOut
+		if decoded.HookSpecificOutput != nil {
+			res.AdditionalContext = decoded.HookSpecificOutput.AdditionalContext
+			res.PermissionDecision = decoded.HookSpecificOutput.PermissionDecision
+		}
+	}
+
+	events, err := store.ListEvents(cmd.Context(), sdb, store.ListEventsParams{SinceID: before, Limit: 1000})
+	if err != nil {
+		return nil, err
+	}
+	res.Events = events
+	return res, nil
+}
+
+// captureHookStdout runs fn with os.Stdout redirected to a temp file and
+// returns what fn wrote. A nil result means capturing itself failed.
+func captureHookStdout(fn func() error) ([]byte, error) {
+	f, err := os.CreateTemp("", "vybe-hook-test-*.out")
+	if err != nil {
+		return nil, fmt.Errorf("capture hook output: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	prev := os.Stdout
+	os.Stdout = f
+	runErr := fn()
+	os.Stdout = prev
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Join(fmt.Errorf("capture hook output: %w", err), runErr)
+	}
+	out, err := io.ReadAll(f)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("capture hook output: %w", err), runErr)
+	}
+	return out, runErr
+}
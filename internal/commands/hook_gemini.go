@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// geminiAgentName is the agent identity Gemini CLI hooks use when none is configured.
+const geminiAgentName = "gemini"
+
+// geminiHookHandler returns the Claude Code handler serving the same lifecycle
+// point as a Gemini CLI hook event, or nil for events vybe does not handle.
+// Gemini's payloads carry the Claude Code field names (session_id, cwd,
+// prompt, tool_name, ...), so the handlers read them unchanged.
+func geminiHookHandler(input hookInput) *cobra.Command {
+	switch input.HookEventName {
+	case "SessionStart":
+		return newHookSessionStartCmd()
+	case "BeforeAgent":
+		return newHookPromptCmd()
+	case "AfterTool":
+		if geminiToolFailed(input) {
+			return newHookToolFailureCmd()
+		}
+		return newHookToolSuccessCmd()
+	case "PreCompress":
+		return newHookCheckpointCmd()
+	case "SessionEnd":
+		return newHookSessionEndCmd()
+	}
+	return nil
+}
+
+// newHookGeminiCmd creates the single handler registered for Gemini CLI hook
+// events by 'vybe hook install --gemini'. It dispatches on hook_event_name to
+// the Claude Code handler for the same lifecycle point:
+//
+//   - SessionStart: session-start (injects context)
+//   - BeforeAgent: prompt
+//   - AfterTool: tool-failure when the tool reported an error, else tool-success
+//   - PreCompress: checkpoint
+//   - SessionEnd: session-end
+func newHookGeminiCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:           "gemini",
+		Short:         "Gemini CLI hook — routes Gemini lifecycle events to the vybe handlers",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			payload, ok := bufferedHookPayload(cmd)
+			if !ok {
+				payload = readHookPayload()
+			}
+			input := parseHookInput(payload)
+
+			handler := geminiHookHandler(input)
+			if handler == nil {
+				return nil
+			}
+
+			withHookDispatch(cmd, payload, geminiAgentName)
+			return handler.RunE(cmd, args)
+		},
+	}
+}
+
+// geminiToolFailed reports whether an AfterTool payload's tool_response
+// carries an error.
+func geminiToolFailed(input hookInput) bool {
+	resp, ok := input.Raw["tool_response"].(map[string]any)
+	if !ok {
+		return false
+	}
+	switch e := resp["error"].(type) {
+	case nil:
+		return false
+	case string:
+		return e != ""
+	default:
+		return true
+	}
+}
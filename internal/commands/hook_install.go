@@ -14,3 +14,8 @@ func newHookInstallCmd() *cobra.Command {
 func newHookUninstallCmd() *cobra.Command {
 	return hookcmd.NewUninstallCmd()
 }
+
+// newHookDoctorCmd creates the hook doctor command (delegates to hookcmd).
+func newHookDoctorCmd() *cobra.Command {
+	return hookcmd.NewDoctorCmd()
+}
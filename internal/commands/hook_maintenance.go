@@ -11,20 +11,21 @@ import (
 	"github.com/dotcommander/vybe/internal/store"
 )
 
-// runCheckpoint performs best-effort memory GC and event summarization.
+// runCheckpoint performs best-effort memory and idempotency GC and event summarization.
 // Used by both the checkpoint and session-end hook handlers.
-func runCheckpoint(db *DB, hctx hookContext, requestIDPrefix string) {
+func runCheckpoint(ctx context.Context, db *DB, hctx hookContext, requestIDPrefix string) {
 	maint := app.EffectiveEventMaintenanceSettings()
 
-	_, gcErr := actions.MemoryGCIdempotent(db, hctx.AgentName, requestIDPrefix+"_gc", 500)
+	_, gcErr := actions.MemoryGCWithOptionsIdempotent(ctx, db, hctx.AgentName, requestIDPrefix+"_gc",
+		store.GCOptions{Limit: 500, IdempotencyTTLDays: app.IdempotencyTTLDays()})
 	if gcErr != nil {
 		slog.Default().Warn("checkpoint gc failed", "error", gcErr, "hook_event", hctx.Input.HookEventName)
 	}
 
 	// Auto-compress old events when active count exceeds threshold
 	summarizeReqID := requestIDPrefix + "_summarize"
-	projectID := hctx.CWD
-	_, _, summarizeErr := actions.AutoSummarizeEventsIdempotent(
+	projectID := hctx.ProjectID
+	_, _, summarizeErr := actions.AutoSummarizeEventsIdempotent(ctx,
 		db, hctx.AgentName, summarizeReqID, projectID,
 		maint.SummarizeThreshold, maint.SummarizeKeepRecent,
 	)
@@ -32,16 +33,37 @@ func runCheckpoint(db *DB, hctx hookContext, requestIDPrefix string) {
 		slog.Default().Warn("checkpoint auto-summarize failed", "error", summarizeErr, "hook_event", hctx.Input.HookEventName)
 	}
 
-	deleted, pruneErr := actions.AutoPruneArchivedEventsIdempotent(
+	if projectID != "" {
+		if _, err := store.RecordProjectStatsSnapshot(ctx, db, projectID); err != nil {
+			slog.Default().Warn("checkpoint stats snapshot failed", "error", err, "hook_event", hctx.Input.HookEventName)
+		}
+	}
+
+	if threshold := app.StaleTaskThreshold(); threshold > 0 {
+		if _, err := actions.TaskStaleNotifyIdempotent(ctx, db, hctx.AgentName, requestIDPrefix+"_stale", projectID, threshold); err != nil {
+			slog.Default().Warn("checkpoint stale task check failed", "error", err, "hook_event", hctx.Input.HookEventName)
+		}
+	}
+
+	if projectID != "" && app.FactExtractionEnabled() {
+		if _, err := actions.MemoryExtractIdempotent(ctx, db, hctx.AgentName, requestIDPrefix+"_extract", projectID, 0); err != nil {
+			slog.Default().Warn("checkpoint fact extraction failed", "error", err, "hook_event", hctx.Input.HookEventName)
+		}
+	}
+
+	runScheduledDBMaintenance(ctx, db, hctx, requestIDPrefix+"_maintain")
+
+	defaultDays, rules := retentionRulesFor(projectID, hctx.CWD)
+	deleted, pruneErr := actions.AutoPruneEventsByRetentionIdempotent(ctx,
 		db, hctx.AgentName, requestIDPrefix+"_prune", projectID,
-		maint.RetentionDays, maint.PruneBatch,
+		defaultDays, rules, maint.PruneBatch,
 	)
 	if pruneErr != nil {
 		slog.Default().Warn("checkpoint archived-event prune failed", "error", pruneErr, "hook_event", hctx.Input.HookEventName)
 		return
 	}
 	if deleted > 0 {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 		defer cancel()
 		if err := store.CheckpointWAL(ctx, db, "TRUNCATE"); err != nil {
 			slog.Default().Warn("checkpoint wal truncate failed", "error", err, "hook_event", hctx.Input.HookEventName)
@@ -49,6 +71,29 @@ func runCheckpoint(db *DB, hctx hookContext, requestIDPrefix string) {
 	}
 }
 
+// runScheduledDBMaintenance runs `db maintain` without the integrity check when
+// db_maintain_every is set and the last maintenance is older than that window.
+func runScheduledDBMaintenance(ctx context.Context, db *DB, hctx hookContext, requestID string) {
+	everyDays := app.DBMaintainEveryDays()
+	if everyDays == 0 {
+		return
+	}
+	last, ok, err := store.LastDBMaintenance(ctx, db)
+	if err != nil || (ok && time.Since(last) < time.Duration(everyDays)*24*time.Hour) {
+		return
+	}
+	dbPath, err := app.GetDBPath()
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if _, err := store.MaintainDB(ctx, db, dbPath, hctx.AgentName, requestID,
+		store.DBMaintainOptions{Skip: []string{store.MaintainStepIntegrity}}); err != nil {
+		slog.Default().Warn("checkpoint db maintenance failed", "error", err, "hook_event", hctx.Input.HookEventName)
+	}
+}
+
 func buildToolMetadata(input hookInput) string {
 	inputPreview, inputTruncated := truncateString(string(input.ToolInput), 2048)
 	outputPreview, outputTruncated := truncateString(string(input.ToolResponse), 4096)
@@ -66,6 +111,9 @@ func buildToolMetadata(input hookInput) string {
 		"tool_output_truncated":   outputTruncated,
 		"metadata_schema_version": "v1",
 	}
+	if path := store.ToolInputFilePath(string(input.ToolInput)); path != "" {
+		metaObj["file_path"] = path
+	}
 
 	metadata, _ := json.Marshal(metaObj)
 	if len(metadata) <= store.MaxEventMetadataLength {
@@ -95,6 +143,9 @@ func buildToolMetadata(input hookInput) string {
 		"tool_output_bytes":       len(input.ToolResponse),
 		"metadata_schema_version": "v1",
 	}
+	if path, ok := metaObj["file_path"]; ok {
+		fallback["file_path"] = path
+	}
 	minimal, _ := json.Marshal(fallback)
 	return string(minimal)
 }
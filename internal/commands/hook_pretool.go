@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// newHookPreToolCmd creates the PreToolUse guard. It applies the guard policies
+// from config (see app.EffectiveGuardPolicies) to the tool call and answers
+// deny or ask when one matches; allowed calls produce no output, leaving
+// Claude Code's own permission rules in charge. Matches are logged as
+// guard_decision events. The pre_tool hook kind is off unless enabled.
+func newHookPreToolCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:           "pre-tool",
+		Short:         "PreToolUse hook — denies or asks for tool calls matching vybe guard policies",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: whenHookEnabled(app.HookPreTool, func(cmd *cobra.Command, args []string) error {
+			hctx := resolveHookContext(cmd)
+			if hctx.Input.ToolName == "" {
+				return nil
+			}
+			policies := app.EffectiveGuardPolicies()
+			if len(policies) == 0 {
+				return nil
+			}
+
+			verdict := actions.EvaluateGuard(policies, guardCallFor(cmd.Context(), hctx))
+			if verdict == nil {
+				return nil
+			}
+
+			withDBSilent(cmd.Context(), func(db *DB) error {
+				metadata, _ := json.Marshal(map[string]any{
+					"source":     defaultAgentName,
+					"session_id": hctx.Input.SessionID,
+					"hook_event": hctx.Input.HookEventName,
+					"tool_name":  hctx.Input.ToolName,
+					"policy":     verdict.Policy,
+					"rule":       verdict.Rule,
+					"decision":   verdict.Decision,
+				})
+				msg := fmt.Sprintf("%s %s: %s", verdict.Decision, hctx.Input.ToolName, verdict.Reason)
+				_, err := appendEventWithFocusTask(cmd.Context(), db, hctx.AgentName, hookRequestID("guard", hctx.AgentName),
+					models.EventKindGuardDecision, hctx.ProjectID, resolveHookFocusTaskID(cmd.Context(), db, hctx), msg, string(metadata))
+				return err
+			})
+
+			return json.NewEncoder(os.Stdout).Encode(hookOutput{
+				HookSpecificOutput: &hookSpecific{
+					HookEventName:            "PreToolUse",
+					PermissionDecision:       verdict.Decision,
+					PermissionDecisionReason: verdict.Reason,
+				},
+			})
+		}),
+	}
+}
+
+// guardCallFor extracts what the guard policies inspect from a PreToolUse
+// payload. For file edits the project directory is the enclosing git
+// repository, or the working directory outside one.
+func guardCallFor(ctx context.Context, hctx hookContext) actions.GuardCall {
+	var toolInput struct {
+		Command      string `json:"command"`
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	}
+	if len(hctx.Input.ToolInput) > 0 {
+		if err := json.Unmarshal(hctx.Input.ToolInput, &toolInput); err != nil {
+			slog.Default().Debug("pre-tool: unreadable tool_input", "error", err)
+		}
+	}
+	path := toolInput.FilePath
+	if path == "" {
+		path = toolInput.NotebookPath
+	}
+
+	var projectDir string
+	if path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(hctx.CWD, path)
+		}
+		if projectDir = gitTopLevel(hctx.CWD); projectDir == "" {
+			projectDir = hctx.CWD
+		}
+	}
+
+	return actions.GuardCall{
+		Tool:       hctx.Input.ToolName,
+		Command:    toolInput.Command,
+		Path:       path,
+		ProjectDir: projectDir,
+		// An unreachable database counts as having a focus task: the guard
+		// must not block work because vybe itself is broken.
+		HasFocusTask: func() bool {
+			has := true
+			withDBSilent(ctx, func(db *DB) error {
+				has = resolveHookFocusTaskID(ctx, db, hctx) != ""
+				return nil
+			})
+			return has
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -9,13 +10,13 @@ import (
 )
 
 // emitRichBrief builds a comprehensive vybe summary for trigger words like "brief me" and "remember".
-func emitRichBrief(db *DB, agentName, focusTaskID, projectID string) error {
+func emitRichBrief(ctx context.Context, db *DB, agentName, focusTaskID, projectID string) error {
 	var b strings.Builder
 
 	b.WriteString("== VYBE PROJECT SUMMARY ==\n")
 
 	// List all non-completed tasks for this project
-	tasks, err := store.ListTasks(db, "", projectID, -1)
+	tasks, err := store.ListTasks(ctx, db, "", projectID, -1)
 	if err != nil {
 		return err
 	}
@@ -77,7 +78,7 @@ func emitRichBrief(db *DB, agentName, focusTaskID, projectID string) error {
 
 	// Add memory if available
 	if focusTaskID != "" {
-		brief, err := store.BuildBrief(db, focusTaskID, projectID, agentName)
+		brief, err := store.BuildBrief(ctx, db, focusTaskID, projectID, agentName)
 		if err == nil && brief != nil && len(brief.RelevantMemory) > 0 {
 			b.WriteString("\nSaved notes:\n")
 			for _, m := range brief.RelevantMemory {
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// externalLLMDisabled reports whether disableExternalLLMEnv blocks external
+// LLM calls. Any value but an explicit false disables them.
+func externalLLMDisabled() bool {
+	raw, ok := os.LookupEnv(disableExternalLLMEnv)
+	if !ok || strings.TrimSpace(raw) == "" {
+		return false
+	}
+	v, err := strconv.ParseBool(strings.TrimSpace(raw))
+	return err != nil || v
+}
+
+func newHookRetrospectiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retrospective",
+		Short: "Review a session and store its summary and lessons",
+		Long: `Reviews one session's events and stores what it learned: a summary as project
+memory (key session/<id>/summary, 7-day half-life) and lessons in the lessons
+knowledge base (vybe lesson list), with source retrospective:<id>.
+
+By default the review is rule-based: the summary counts the session's steps and
+completions, and repeated tool failures and task failures become low-confidence
+lessons. --llm sends the session timeline to the configured provider
+(retrospective.provider in config: claude, openai, or ollama) for a written
+summary and reusable lessons. LLM calls count toward llm_calls_per_day and are
+refused while VYBE_DISABLE_EXTERNAL_LLM is set.
+
+Without --session, the agent's most recent session is reviewed.`,
+		Example: `  vybe hook retrospective --session "$SESSION_ID"
+  vybe hook retrospective --llm --provider ollama --model llama3.1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionID, _ := cmd.Flags().GetString("session")
+			projectID := projectScopeFlag(cmd)
+			useLLM, _ := cmd.Flags().GetBool("llm")
+			providerName, _ := cmd.Flags().GetString("provider")
+			model, _ := cmd.Flags().GetString("model")
+			maxLessons, _ := cmd.Flags().GetInt("max-lessons")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			overrideLimits, _ := cmd.Flags().GetBool("override-limits")
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var provider actions.LLMProvider
+			if useLLM {
+				if externalLLMDisabled() {
+					return cmdErr(errors.New("external LLM calls are disabled (" + disableExternalLLMEnv + " is set)"))
+				}
+				settings, _ := app.LoadSettings()
+				cfg := settings.Retrospective
+				if providerName != "" {
+					cfg.Provider = providerName
+				}
+				if model != "" {
+					cfg.Model = model
+				}
+				if provider, err = actions.NewLLMProvider(cfg); err != nil {
+					return cmdErr(err)
+				}
+			}
+
+			var result *store.RetrospectiveResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if sessionID == "" {
+					sessions, err := actions.SessionList(cmd.Context(), db, store.ListSessionsParams{AgentName: agentName, Limit: 1})
+					if err != nil {
+						return err
+					}
+					if len(sessions) == 0 {
+						return errors.New("no sessions recorded for agent " + agentName + "; pass --session")
+					}
+					sessionID = sessions[0].ID
+				}
+				if provider != nil {
+					if !overrideLimits {
+						if err := actions.CheckDailyLimit(cmd.Context(), db, store.LimitLLMCallsPerDay); err != nil {
+							return err
+						}
+					}
+					if err := actions.RecordLLMCall(cmd.Context(), db); err != nil {
+						return err
+					}
+				}
+
+				ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+				defer cancel()
+				r, err := actions.SessionRetrospectiveIdempotent(ctx, db, agentName, requestID, actions.RetrospectiveOptions{
+					SessionID:  sessionID,
+					ProjectID:  projectID,
+					Provider:   provider,
+					MaxLessons: maxLessons,
+				})
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("session", "", "Session ID to review (default: the agent's most recent session)")
+	addProjectScopeFlags(cmd, "Project for the summary and lessons (default: the session's project)")
+	cmd.Flags().Bool("llm", false, "Use the configured LLM provider instead of the rule-based review")
+	cmd.Flags().String("provider", "", "LLM provider for this call: claude|openai|ollama (overrides config)")
+	cmd.Flags().String("model", "", "LLM model for this call (overrides config)")
+	cmd.Flags().Int("max-lessons", actions.DefaultRetrospectiveMaxLessons, "Maximum lessons to store")
+	cmd.Flags().Duration("timeout", 2*time.Minute, "Time limit for the LLM call")
+	cmd.Flags().Bool("override-limits", false, "Call the LLM past the llm_calls_per_day limit")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "runs_external": "llm"}
+	return cmd
+}
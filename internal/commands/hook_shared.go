@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
@@ -12,6 +13,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -45,38 +48,73 @@ type hookInput struct {
 	Raw           map[string]any  `json:"-"`
 }
 
-// hookOutput is the JSON Claude Code expects on stdout from SessionStart hooks.
+// hookOutput is the JSON Claude Code expects on stdout from SessionStart and
+// PreToolUse hooks.
 type hookOutput struct {
 	HookSpecificOutput *hookSpecific `json:"hookSpecificOutput,omitempty"`
 }
 
 type hookSpecific struct {
-	HookEventName     string `json:"hookEventName"`
-	AdditionalContext string `json:"additionalContext,omitempty"`
+	HookEventName            string `json:"hookEventName"`
+	AdditionalContext        string `json:"additionalContext,omitempty"`
+	PermissionDecision       string `json:"permissionDecision,omitempty"`
+	PermissionDecisionReason string `json:"permissionDecisionReason,omitempty"`
 }
 
 // hookContext holds resolved common state shared by all hook commands.
+// ProjectID is the project the working directory maps to (see resolveProjectID);
+// CWD stays the directory for filesystem lookups.
 type hookContext struct {
 	Input     hookInput
 	AgentName string
 	CWD       string
+	ProjectID string
 }
 
-// resolveHookContext reads stdin and resolves agent name and working directory.
+// resolveHookContext reads stdin and resolves agent name, working directory, and project.
 func resolveHookContext(cmd *cobra.Command) hookContext {
-	input := readHookStdin()
-	agentName := resolveActorName(cmd, "")
+	fallback := defaultAgentName
+	if ctx := cmd.Context(); ctx != nil {
+		if agent, ok := ctx.Value(hookAgentKey{}).(string); ok && agent != "" {
+			fallback = agent
+		}
+	}
+	return resolveHookContextAs(cmd, fallback)
+}
+
+// resolveHookContextAs is resolveHookContext with the agent identity used when
+// none is configured (e.g. "cursor" for Cursor hooks).
+func resolveHookContextAs(cmd *cobra.Command, fallbackAgent string) hookContext {
+	var input hookInput
+	if payload, ok := bufferedHookPayload(cmd); ok {
+		input = parseHookInput(payload)
+	} else {
+		input = readHookStdin()
+	}
+	cwd := input.CWD
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	// The host's payload names the session's directory; the hook process may run elsewhere.
+	agentName := resolveActorIdentityWith(cmd, "", actorLookupIn(cwd)).Name
 	if agentName == "" {
-		agentName = defaultAgentName
+		agentName = fallbackAgent
 		slog.Default().Warn("hook using default agent identity",
 			"agent", agentName,
 			"hint", "set VYBE_AGENT or --agent to avoid cross-session contamination")
 	}
-	cwd := input.CWD
-	if cwd == "" {
-		cwd, _ = os.Getwd()
+	return hookContext{Input: input, AgentName: agentName, CWD: cwd, ProjectID: resolveProjectID(cwd)}
+}
+
+// whenHookEnabled wraps a hook handler so it exits without output or database
+// access when its kind is disabled (hooks.<kind>.enabled: false).
+func whenHookEnabled(kind string, run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if !app.HookKindEnabled(kind) {
+			return nil
+		}
+		return run(cmd, args)
 	}
-	return hookContext{Input: input, AgentName: agentName, CWD: cwd}
 }
 
 func randomHex(bytesLen int) string {
@@ -146,10 +184,19 @@ func truncateString(raw string, max int) (string, bool) {
 }
 
 func readHookStdin() hookInput {
+	return parseHookInput(readHookPayload())
+}
+
+// readHookPayload reads the raw hook payload from stdin; nil on read failure.
+func readHookPayload() []byte {
 	data, err := io.ReadAll(io.LimitReader(os.Stdin, maxHookStdinBytes))
 	if err != nil {
-		return hookInput{}
+		return nil
 	}
+	return data
+}
+
+func parseHookInput(data []byte) hookInput {
 	var input hookInput
 	if err := json.Unmarshal(data, &input); err != nil {
 		slog.Default().Warn("hook stdin unmarshal failed", "error", err, "bytes", len(data))
@@ -160,30 +207,105 @@ func readHookStdin() hookInput {
 	var raw map[string]any
 	_ = json.Unmarshal(data, &raw)
 	input.Raw = raw
+	normalizeCursorInput(&input)
+	normalizeCodexInput(&input)
 	return input
 }
 
+// normalizeCursorInput maps Cursor's hook payload onto the Claude Code fields:
+// conversation_id is the session and the first workspace root is the cwd.
+// Payloads from other clients are left unchanged.
+func normalizeCursorInput(input *hookInput) {
+	conversationID, ok := input.Raw["conversation_id"].(string)
+	if !ok {
+		return
+	}
+	if input.SessionID == "" {
+		input.SessionID = conversationID
+	}
+	if input.CWD == "" {
+		if roots, ok := input.Raw["workspace_roots"].([]any); ok && len(roots) > 0 {
+			input.CWD, _ = roots[0].(string)
+		}
+	}
+}
+
+// normalizeCodexInput maps a Codex notify payload onto the Claude Code fields:
+// the event type is the hook event, thread-id is the session, and the turn's
+// input messages become the prompt. Payloads from other clients are left
+// unchanged.
+func normalizeCodexInput(input *hookInput) {
+	eventType, ok := input.Raw["type"].(string)
+	if !ok || input.HookEventName != "" {
+		return
+	}
+	input.HookEventName = eventType
+	if input.SessionID == "" {
+		input.SessionID, _ = input.Raw["thread-id"].(string)
+	}
+	if input.Prompt == "" {
+		messages, _ := input.Raw["input-messages"].([]any)
+		var parts []string
+		for _, m := range messages {
+			if text, ok := m.(string); ok && strings.TrimSpace(text) != "" {
+				parts = append(parts, text)
+			}
+		}
+		input.Prompt = strings.Join(parts, "\n\n")
+	}
+}
+
 // resolveAgentFocusTaskID loads the agent's current focus task ID.
 // Returns empty string if no focus task is set or on any error.
-func resolveAgentFocusTaskID(db *DB, agentName string) string {
-	state, err := store.GetAgentState(db, agentName)
+func resolveAgentFocusTaskID(ctx context.Context, db *DB, agentName string) string {
+	state, err := store.GetAgentState(ctx, db, agentName)
 	if err != nil || state == nil {
 		return ""
 	}
 	return state.FocusTaskID
 }
 
+// focusSessionID returns the client session id when focus.per_session is on,
+// or "" to use the agent-wide focus.
+func (h hookContext) focusSessionID() string {
+	if h.Input.SessionID == "" || !app.SessionFocusEnabled() {
+		return ""
+	}
+	return h.Input.SessionID
+}
+
+// resolveHookFocusTaskID is resolveAgentFocusTaskID that prefers the hook
+// session's own focus when per-session focus is enabled.
+func resolveHookFocusTaskID(ctx context.Context, db *DB, hctx hookContext) string {
+	if sessionID := hctx.focusSessionID(); sessionID != "" {
+		if sf, found, err := store.GetSessionFocus(ctx, db, hctx.AgentName, sessionID); err == nil && found {
+			return sf.FocusTaskID
+		}
+	}
+	return resolveAgentFocusTaskID(ctx, db, hctx.AgentName)
+}
+
 // appendEventWithFocusTask resolves the agent's focus task (unless overridden)
 // and appends an event with project and metadata. Consolidates the repeated
 // resolve-then-append pattern used by prompt, tool-failure, and task-completed hooks.
+// Events for a session that has reached the events_per_session limit are
+// refused with a *store.LimitExceededError.
 //
 //nolint:revive // argument-limit: all 8 params are required for the unified hook event path
-func appendEventWithFocusTask(db *DB, agentName, requestID, kind, projectID, taskIDOverride, msg, metadata string) (int64, error) {
+func appendEventWithFocusTask(ctx context.Context, db *DB, agentName, requestID, kind, projectID, taskIDOverride, msg, metadata string) (int64, error) {
+	var meta struct {
+		SessionID string `json:"session_id"`
+	}
+	if json.Unmarshal([]byte(metadata), &meta) == nil {
+		if err := actions.CheckSessionEventLimit(ctx, db, meta.SessionID); err != nil {
+			return 0, err
+		}
+	}
 	taskID := taskIDOverride
 	if taskID == "" {
-		taskID = resolveAgentFocusTaskID(db, agentName)
+		taskID = resolveAgentFocusTaskID(ctx, db, agentName)
 	}
-	return store.AppendEventWithProjectAndMetadataIdempotent(
+	return store.AppendEventWithProjectAndMetadataIdempotent(ctx,
 		db, agentName, requestID, kind, projectID, taskID, msg, metadata,
 	)
 }
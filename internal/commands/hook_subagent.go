@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// hookSubagent returns the sub-agent type and instance id Claude Code sends
+// with SubagentStart and SubagentStop.
+func hookSubagent(input hookInput) (subagent, subagentID string) {
+	subagent, _ = input.Raw["agent_type"].(string)
+	subagentID, _ = input.Raw["agent_id"].(string)
+	return subagent, subagentID
+}
+
+// newHookSubagentStartCmd attaches a starting sub-agent to the oldest task
+// delegated to it with 'vybe task delegate'. A sub-agent nothing was delegated
+// to is logged against the focus task instead.
+func newHookSubagentStartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:           "subagent-start",
+		Short:         "SubagentStart hook — attaches a sub-agent to the task delegated to it",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: whenHookEnabled(app.HookSubagentStart, func(cmd *cobra.Command, args []string) error {
+			hctx := resolveHookContext(cmd)
+			subagent, subagentID := hookSubagent(hctx.Input)
+			if subagent == "" {
+				return nil
+			}
+			requestID := stableHookRequestID("subagent_start", hctx.AgentName, subagentID)
+
+			// Hooks must never block Claude Code — log diagnostic and exit clean.
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				d, _, err := store.StartDelegationIdempotent(cmd.Context(), db, hctx.AgentName, requestID, subagent, subagentID, hctx.Input.SessionID)
+				if err != nil || d != nil {
+					return err
+				}
+				return appendUndelegatedSubagentEvent(cmd.Context(), db, hctx, requestID+"_log", models.EventKindSubagentStarted, "Sub-agent started: "+subagent)
+			}); err != nil {
+				slog.Default().Error("subagent-start hook failed", "error", err, "subagent", subagent)
+			}
+			return nil
+		}),
+	}
+}
+
+// newHookSubagentStopCmd marks the sub-agent's delegation finished.
+func newHookSubagentStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:           "subagent-stop",
+		Short:         "SubagentStop hook — marks a sub-agent's delegated task as finished",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: whenHookEnabled(app.HookSubagentStop, func(cmd *cobra.Command, args []string) error {
+			hctx := resolveHookContext(cmd)
+			subagent, subagentID := hookSubagent(hctx.Input)
+			if subagent == "" && subagentID == "" {
+				return nil
+			}
+			requestID := stableHookRequestID("subagent_stop", hctx.AgentName, subagentID)
+
+			// Hooks must never block Claude Code — log diagnostic and exit clean.
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				d, _, err := store.FinishDelegationIdempotent(cmd.Context(), db, hctx.AgentName, requestID, subagent, subagentID)
+				if err != nil || d != nil {
+					return err
+				}
+				return appendUndelegatedSubagentEvent(cmd.Context(), db, hctx, requestID+"_log", models.EventKindSubagentStopped, "Sub-agent finished: "+subagent)
+			}); err != nil {
+				slog.Default().Error("subagent-stop hook failed", "error", err, "subagent", subagent)
+			}
+			return nil
+		}),
+	}
+}
+
+// appendUndelegatedSubagentEvent records a sub-agent that was not delegated a
+// task, against the session's or agent's focus task.
+func appendUndelegatedSubagentEvent(ctx context.Context, db *DB, hctx hookContext, requestID, kind, msg string) error {
+	subagent, subagentID := hookSubagent(hctx.Input)
+	metadata, _ := json.Marshal(map[string]any{
+		"source":      defaultAgentName,
+		"session_id":  hctx.Input.SessionID,
+		"hook_event":  hctx.Input.HookEventName,
+		"subagent":    subagent,
+		"subagent_id": subagentID,
+		"delegated":   false,
+	})
+	_, err := appendEventWithFocusTask(ctx, db, hctx.AgentName, requestID, kind, hctx.ProjectID,
+		resolveHookFocusTaskID(ctx, db, hctx), msg, string(metadata))
+	return err
+}
@@ -1,10 +1,17 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/store"
 	"github.com/stretchr/testify/require"
 )
@@ -189,6 +196,160 @@ func TestPrevSessionCacheVariables(t *testing.T) {
 
 	// Verify readPreviousSessionContext returns empty for nonexistent path
 	// (doesn't panic on cache operations).
-	result := readPreviousSessionContext("/nonexistent/path/for/cache/test", "sess_test")
+	result := readPreviousSessionContext("/nonexistent/path/for/cache/test", "sess_test", 2000, nil)
 	require.Empty(t, result)
 }
+
+func TestParseHookInput_NormalizesCursorPayload(t *testing.T) {
+	input := parseHookInput([]byte(`{
+		"conversation_id": "conv-1",
+		"generation_id": "gen-1",
+		"hook_event_name": "beforeSubmitPrompt",
+		"workspace_roots": ["/work/app", "/work/lib"],
+		"prompt": "fix the build"
+	}`))
+	require.Equal(t, "conv-1", input.SessionID)
+	require.Equal(t, "/work/app", input.CWD)
+	require.Equal(t, "beforeSubmitPrompt", input.HookEventName)
+	require.Equal(t, "fix the build", input.Prompt)
+
+	claude := parseHookInput([]byte(`{"session_id":"s1","cwd":"/x"}`))
+	require.Equal(t, "s1", claude.SessionID)
+	require.Equal(t, "/x", claude.CWD)
+}
+
+func TestParseHookInput_NormalizesCodexPayload(t *testing.T) {
+	input := parseHookInput([]byte(`{
+		"type": "agent-turn-complete",
+		"thread-id": "thread-1",
+		"turn-id": "turn-1",
+		"cwd": "/work/app",
+		"input-messages": ["fix the build", "and run the tests"],
+		"last-assistant-message": "Build fixed."
+	}`))
+	require.Equal(t, "agent-turn-complete", input.HookEventName)
+	require.Equal(t, "thread-1", input.SessionID)
+	require.Equal(t, "/work/app", input.CWD)
+	require.Equal(t, "fix the build\n\nand run the tests", input.Prompt)
+
+	gemini := parseHookInput([]byte(`{"hook_event_name":"AfterTool","tool_name":"run_shell_command","tool_response":{"error":"exit 1"}}`))
+	require.Equal(t, "AfterTool", gemini.HookEventName)
+	require.True(t, geminiToolFailed(gemini))
+	require.False(t, geminiToolFailed(parseHookInput([]byte(`{"hook_event_name":"AfterTool","tool_response":{"llmContent":"ok"}}`))))
+}
+
+func TestRunHookTest_WritesOnlyScratchCopy(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "real.db")
+	t.Setenv("HOME", dir)
+	t.Setenv("VYBE_DB_PATH", dbPath)
+	t.Setenv("VYBE_AGENT", "hook-test-agent")
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	cmd.Flags().String("agent", "", "")
+	payload := []byte(`{"hook_event_name":"PostToolUseFailure","session_id":"s1","cwd":"` + dir + `","tool_name":"Bash"}`)
+
+	res, err := runHookTest(cmd, newHookToolFailureCmd(), payload)
+	require.NoError(t, err)
+	require.Len(t, res.Events, 1)
+	require.Equal(t, models.EventKindToolFailure, res.Events[0].Kind)
+	require.Equal(t, "Bash failed (PostToolUseFailure)", res.Events[0].Message)
+
+	db, err := store.OpenDB(context.Background(), dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.CloseDB(db) }()
+	latest, err := store.LatestEventID(context.Background(), db)
+	require.NoError(t, err)
+	require.Zero(t, latest, "the real database must not be written")
+	require.Empty(t, app.DBPathOverride(), "the scratch override is restored")
+}
+
+func TestHookHandlers_HonorDisabledKinds(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("VYBE_DB_PATH", filepath.Join(dir, "vybe.db"))
+	t.Setenv("VYBE_AGENT", "hook-test-agent")
+	configDir := filepath.Join(dir, ".config", "vybe")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"),
+		[]byte("hooks:\n  tool_failure:\n    enabled: false\n  tool_success:\n    enabled: true\n"), 0o600))
+	_, err := app.ReloadSettings()
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = app.ReloadSettings() })
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	cmd.Flags().String("agent", "", "")
+
+	res, err := runHookTest(cmd, newHookToolFailureCmd(),
+		[]byte(`{"hook_event_name":"PostToolUseFailure","session_id":"s1","cwd":"`+dir+`","tool_name":"Bash"}`))
+	require.NoError(t, err)
+	require.Empty(t, res.Events, "a disabled kind writes nothing")
+	require.Nil(t, res.Output)
+
+	res, err = runHookTest(cmd, newHookToolSuccessCmd(),
+		[]byte(`{"hook_event_name":"PostToolUse","session_id":"s1","cwd":"`+dir+`","tool_name":"Read"}`))
+	require.NoError(t, err)
+	require.Len(t, res.Events, 1)
+	require.Equal(t, models.EventKindToolSuccess, res.Events[0].Kind)
+	require.Equal(t, "Read succeeded", res.Events[0].Message)
+}
+
+func TestHookPreTool_DeniesMatchingCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("VYBE_DB_PATH", filepath.Join(dir, "vybe.db"))
+	t.Setenv("VYBE_AGENT", "hook-test-agent")
+	configDir := filepath.Join(dir, ".config", "vybe")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"),
+		[]byte("hooks:\n  pre_tool:\n    enabled: true\n"), 0o600))
+	_, err := app.ReloadSettings()
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = app.ReloadSettings() })
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	cmd.Flags().String("agent", "", "")
+
+	res, err := runHookTest(cmd, newHookPreToolCmd(),
+		[]byte(`{"hook_event_name":"PreToolUse","session_id":"s1","cwd":"`+dir+`","tool_name":"Bash","tool_input":{"command":"rm -rf build"}}`))
+	require.NoError(t, err)
+	require.Equal(t, "deny", res.PermissionDecision)
+	require.Len(t, res.Events, 1)
+	require.Equal(t, models.EventKindGuardDecision, res.Events[0].Kind)
+
+	res, err = runHookTest(cmd, newHookPreToolCmd(),
+		[]byte(`{"hook_event_name":"PreToolUse","session_id":"s1","cwd":"`+dir+`","tool_name":"Bash","tool_input":{"command":"go test ./..."}}`))
+	require.NoError(t, err)
+	require.Nil(t, res.Output, "allowed calls print nothing")
+	require.Empty(t, res.Events)
+}
+
+func TestHookSubagentStart_AttachesToDelegatedTask(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "vybe.db")
+	t.Setenv("HOME", dir)
+	t.Setenv("VYBE_DB_PATH", dbPath)
+	t.Setenv("VYBE_AGENT", "hook-test-agent")
+
+	db, err := store.InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	task, err := store.CreateTask(context.Background(), db, "Run the linters", "", "", 0)
+	require.NoError(t, err)
+	_, _, err = store.DelegateTaskIdempotent(context.Background(), db, "hook-test-agent", "delegate_1", task.ID, "quality-agent")
+	require.NoError(t, err)
+	require.NoError(t, store.CloseDB(db))
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	cmd.Flags().String("agent", "", "")
+
+	res, err := runHookTest(cmd, newHookSubagentStartCmd(),
+		[]byte(`{"hook_event_name":"SubagentStart","session_id":"s1","cwd":"`+dir+`","agent_id":"sa_1","agent_type":"quality-agent"}`))
+	require.NoError(t, err)
+	require.Len(t, res.Events, 1)
+	require.Equal(t, models.EventKindSubagentStarted, res.Events[0].Kind)
+	require.Equal(t, task.ID, res.Events[0].TaskID)
+}
@@ -17,11 +17,14 @@ import (
 var (
 	prevSessionCachePath    string
 	prevSessionCacheModTime time.Time
+	prevSessionCacheRunes   int
 	prevSessionCacheResult  string
 )
 
 const maxAutoMemoryChars = 2000
 
+const prevSessionHeader = "Previous session context (last session before this one):\n"
+
 type transcriptContentItem struct {
 	Type string `json:"type"`
 	Text string `json:"text"`
@@ -133,12 +136,33 @@ func findMostRecentTranscript(projectDir, excludeSessionID string) (string, time
 	return bestPath, bestModTime, found
 }
 
+// findSessionTranscript returns the Claude Code transcript of sessionID: first
+// in the project directory for cwd, then in any project directory.
+func findSessionTranscript(cwd, sessionID string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	projectsDir := filepath.Join(home, ".claude", "projects")
+	name := sessionID + ".jsonl"
+	if cwd != "" {
+		path := filepath.Join(projectsDir, encodeProjectPath(cwd), name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	matches, _ := filepath.Glob(filepath.Join(projectsDir, "*", name))
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no transcript for session %q under %s; pass --file", sessionID, projectsDir)
+	}
+	return matches[0], nil
+}
+
 // parseTranscriptExchanges parses JSONL transcript lines and builds a formatted
 // string of user/assistant exchanges, truncating individual messages and total output.
 func parseTranscriptExchanges(lines []string, maxMsgLen, maxTotalLen int) string {
-	const header = "Previous session context (last session before this one):\n"
 	var sb strings.Builder
-	sb.WriteString(header)
+	sb.WriteString(prevSessionHeader)
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if line == "" {
@@ -177,7 +201,7 @@ func parseTranscriptExchanges(lines []string, maxMsgLen, maxTotalLen int) string
 	}
 
 	result := sb.String()
-	if result == header {
+	if result == prevSessionHeader {
 		return ""
 	}
 	return result
@@ -185,10 +209,14 @@ func parseTranscriptExchanges(lines []string, maxMsgLen, maxTotalLen int) string
 
 // readPreviousSessionContext finds the most recent Claude Code session transcript
 // for the given working directory (excluding the current session) and returns a
-// formatted string of the last few user/assistant exchanges.
+// formatted string of the last few user/assistant exchanges, at most maxRunes
+// long. When labelOf knows a label for the previous session, the header names it.
 //
 // All errors are silently swallowed - hooks must never block Claude Code.
-func readPreviousSessionContext(cwd, currentSessionID string) string {
+func readPreviousSessionContext(cwd, currentSessionID string, maxRunes int, labelOf func(sessionID string) string) string {
+	if maxRunes <= 0 {
+		return ""
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return ""
@@ -200,20 +228,26 @@ func readPreviousSessionContext(cwd, currentSessionID string) string {
 		return ""
 	}
 
-	if path == prevSessionCachePath && modTime.Equal(prevSessionCacheModTime) {
-		return prevSessionCacheResult
+	if path != prevSessionCachePath || !modTime.Equal(prevSessionCacheModTime) || maxRunes != prevSessionCacheRunes {
+		// Longer excerpts read further back: about 40 runes of exchange per line.
+		lines, err := readTailLines(path, max(50, maxRunes/40))
+		if err != nil {
+			return ""
+		}
+		prevSessionCachePath = path
+		prevSessionCacheModTime = modTime
+		prevSessionCacheRunes = maxRunes
+		prevSessionCacheResult = parseTranscriptExchanges(lines, 200, maxRunes)
 	}
 
-	lines, err := readTailLines(path, 50)
-	if err != nil {
-		return ""
+	result := prevSessionCacheResult
+	if result == "" || labelOf == nil {
+		return result
+	}
+	if label := labelOf(strings.TrimSuffix(filepath.Base(path), ".jsonl")); label != "" {
+		result = fmt.Sprintf("Previous session context (last session before this one: %q):\n", label) +
+			strings.TrimPrefix(result, prevSessionHeader)
 	}
-
-	result := parseTranscriptExchanges(lines, 200, 2000)
-
-	prevSessionCachePath = path
-	prevSessionCacheModTime = modTime
-	prevSessionCacheResult = result
 	return result
 }
 
@@ -1,6 +1,7 @@
 package hookcmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -68,24 +69,45 @@ func HasVybeHook(entries []any) bool {
 // IsVybeHookCommand checks if a command string is a vybe hook command.
 // Handles quoted executable paths that may contain spaces (e.g. "/path with spaces/vybe").
 func IsVybeHookCommand(command string) bool {
+	exe, rest, ok := splitHookCommand(command)
+	if !ok || filepath.Base(exe) != "vybe" {
+		return false
+	}
+
+	parts := strings.Fields(rest)
+	if len(parts) < 2 || parts[0] != "hook" {
+		return false
+	}
+
+	switch parts[1] {
+	case "session-start", "session-end", "prompt", "tool-failure", "tool-success", "pre-tool",
+		"checkpoint", "task-completed", "subagent-start", "subagent-stop", "cursor", "gemini", "codex":
+		return true
+	default:
+		return false
+	}
+}
+
+// splitHookCommand splits a hook command line into its executable, which may
+// be quoted, and the arguments after it. ok is false when there are no arguments.
+func splitHookCommand(command string) (exe, rest string, ok bool) {
 	cmd := strings.TrimSpace(command)
 	if cmd == "" {
-		return false
+		return "", "", false
 	}
 
-	var exe, rest string
 	if cmd[0] == '"' {
 		// Quoted executable: find closing quote
 		end := strings.Index(cmd[1:], "\"")
 		if end < 0 {
-			return false
+			return "", "", false
 		}
 		exe = cmd[1 : end+1]
 		rest = strings.TrimSpace(cmd[end+2:])
 	} else if cmd[0] == '\'' {
 		end := strings.Index(cmd[1:], "'")
 		if end < 0 {
-			return false
+			return "", "", false
 		}
 		exe = cmd[1 : end+1]
 		rest = strings.TrimSpace(cmd[end+2:])
@@ -93,28 +115,12 @@ func IsVybeHookCommand(command string) bool {
 		// Unquoted: split on first space
 		idx := strings.IndexByte(cmd, ' ')
 		if idx < 0 {
-			return false
+			return "", "", false
 		}
 		exe = cmd[:idx]
 		rest = strings.TrimSpace(cmd[idx+1:])
 	}
-
-	if filepath.Base(exe) != "vybe" {
-		return false
-	}
-
-	parts := strings.Fields(rest)
-	if len(parts) < 2 || parts[0] != "hook" {
-		return false
-	}
-
-	switch parts[1] {
-	case "session-start", "session-end", "prompt", "tool-failure",
-		"checkpoint", "task-completed":
-		return true
-	default:
-		return false
-	}
+	return exe, rest, true
 }
 
 func hookEntryEqual(a, b map[string]any) bool {
@@ -200,6 +206,7 @@ type claudeInstallResult struct {
 	Installed []string `json:"installed"`
 	Updated   []string `json:"updated,omitempty"`
 	Skipped   []string `json:"skipped"`
+	Disabled  []string `json:"disabled,omitempty"`
 }
 
 type claudeUninstallResult struct {
@@ -208,12 +215,15 @@ type claudeUninstallResult struct {
 }
 
 // installClaudeHooks installs vybe hooks into the Claude Code settings file.
-func installClaudeHooks(projectScoped bool) (*claudeInstallResult, error) {
+// Events whose hook kind is disabled in config are left unregistered, and any
+// vybe entry already there is removed.
+func installClaudeHooks(ctx context.Context, projectScoped bool) (*claudeInstallResult, error) {
 	path := resolveClaudeSettingsPath(projectScoped)
 
 	var installed []string
 	var updated []string
 	var skipped []string
+	var disabled []string
 
 	if err := withLockedSettings(path, func(settings map[string]any) error {
 		hooksObj, _ := settings["hooks"].(map[string]any)
@@ -224,6 +234,12 @@ func installClaudeHooks(projectScoped bool) (*claudeInstallResult, error) {
 		for eventName, entry := range vybeHooks() {
 			existing, _ := hooksObj[eventName].([]any)
 
+			if !hookEventEnabled(claudeHookKinds, eventName) {
+				disabled = append(disabled, eventName)
+				removeHookEntries(hooksObj, eventName, existing, isVybeHookEntry)
+				continue
+			}
+
 			entryJSON, _ := json.Marshal(entry)
 			var entryMap map[string]any
 			_ = json.Unmarshal(entryJSON, &entryMap)
@@ -247,12 +263,13 @@ func installClaudeHooks(projectScoped bool) (*claudeInstallResult, error) {
 		return nil, err
 	}
 
-	ensureHookAgentStateBestEffort("claude")
+	ensureHookAgentStateBestEffort(ctx, "claude")
 
 	sort.Strings(installed)
 	sort.Strings(updated)
 	sort.Strings(skipped)
-	return &claudeInstallResult{Path: path, Installed: installed, Updated: updated, Skipped: skipped}, nil
+	sort.Strings(disabled)
+	return &claudeInstallResult{Path: path, Installed: installed, Updated: updated, Skipped: skipped, Disabled: disabled}, nil
 }
 
 // uninstallClaudeHooks removes vybe hook entries from the Claude Code settings file.
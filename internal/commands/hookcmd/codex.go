@@ -0,0 +1,349 @@
+package hookcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// Codex CLI has no hook system; it runs a single notify program after each
+// agent turn with the event JSON as the last argument. vybe registers
+// 'vybe hook codex' as that program in config.toml, which Codex reads from
+// $CODEX_HOME (default ~/.codex) only.
+
+const (
+	codexAgentsBegin = "<!-- vybe:begin -->"
+	codexAgentsEnd   = "<!-- vybe:end -->"
+)
+
+const codexAgentsBlock = codexAgentsBegin + `
+## vybe task continuity
+
+This workspace records tasks, memory, and progress in vybe. Each turn is logged
+by the vybe notify hook; read and update state with the CLI:
+
+- At the start of a session run ` + "`vybe resume --peek --agent codex`" + ` and
+  continue the focus task it reports.
+- Record progress and completion with ` + "`vybe push --agent codex --request-id <unique id> --json '{...}'`" + `.
+- Store durable facts with ` + "`vybe memory set --agent codex --request-id <unique id> --key <k> --value <v>`" + `.
+` + codexAgentsEnd + "\n"
+
+// codexHookKinds maps the Codex notify event to the hook kinds it serves:
+// each turn logs the prompt and reply, then runs checkpoint maintenance.
+var codexHookKinds = map[string][]string{ //nolint:gochecknoglobals // fixed lookup table of event to hook kind
+	codexTurnEvent: {app.HookPrompt, app.HookCheckpoint},
+}
+
+const codexTurnEvent = "agent-turn-complete"
+
+var (
+	codexNotifyLine = regexp.MustCompile(`^\s*notify\s*=`)
+	codexTOMLString = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"|'([^']*)'`)
+)
+
+func codexConfigPath() string {
+	if dir := strings.TrimSpace(os.Getenv("CODEX_HOME")); dir != "" {
+		return filepath.Join(dir, "config.toml")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".codex", "config.toml")
+}
+
+func codexAgentsPath() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "AGENTS.md"
+	}
+	return filepath.Join(wd, "AGENTS.md")
+}
+
+// codexNotifyArgs returns the notify command vybe registers.
+func codexNotifyArgs() []string {
+	return []string{vybeExecutable(), "hook", "codex"}
+}
+
+func formatCodexNotify(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = strconv.Quote(a)
+	}
+	return "notify = [" + strings.Join(quoted, ", ") + "]"
+}
+
+// parseCodexNotify extracts the string elements of a notify array.
+func parseCodexNotify(value string) []string {
+	var args []string
+	for _, m := range codexTOMLString.FindAllStringSubmatch(value, -1) {
+		if m[0][0] == '\'' {
+			args = append(args, m[2])
+			continue
+		}
+		s, err := strconv.Unquote(`"` + m[1] + `"`)
+		if err != nil {
+			s = m[1]
+		}
+		args = append(args, s)
+	}
+	return args
+}
+
+// isVybeCodexNotify reports whether a notify command runs 'vybe hook codex'.
+func isVybeCodexNotify(args []string) bool {
+	if len(args) != 3 || args[1] != "hook" || args[2] != "codex" {
+		return false
+	}
+	return filepath.Base(args[0]) == "vybe" || args[0] == vybeExecutable()
+}
+
+// findCodexNotify locates the top-level notify setting in config.toml lines.
+// It returns the first and last line of the setting (arrays may span lines),
+// or -1 when there is none. Settings under a [table] header are not top-level.
+func findCodexNotify(lines []string) (start, end int) {
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "[") {
+			break
+		}
+		if !codexNotifyLine.MatchString(lines[i]) {
+			continue
+		}
+		end = i
+		if strings.Contains(lines[i], "[") && !strings.Contains(lines[i], "]") {
+			for end < len(lines)-1 && !strings.Contains(lines[end], "]") {
+				end++
+			}
+		}
+		return i, end
+	}
+	return -1, -1
+}
+
+// codexTopLevelEnd returns the index of the first table header, or len(lines).
+func codexTopLevelEnd(lines []string) int {
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+type codexInstallResult struct {
+	Path       string `json:"path"`
+	Status     string `json:"status"`
+	Existing   string `json:"existing,omitempty"`
+	AgentsPath string `json:"agents_path,omitempty"`
+}
+
+type codexUninstallResult struct {
+	Path          string `json:"path"`
+	Removed       bool   `json:"removed"`
+	AgentsRemoved bool   `json:"agents_removed,omitempty"`
+}
+
+// withLockedCodexConfig runs fn on config.toml's lines under the settings lock
+// and writes the result back unless fn returns errSkipWrite.
+func withLockedCodexConfig(path string, fn func(lines []string) ([]string, error)) error {
+	lock, err := store.LockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer store.UnlockFile(lock)
+
+	var lines []string
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the Codex config file
+	switch {
+	case err == nil:
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(data) == 0 {
+			lines = nil
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("read codex config: %w", err)
+	}
+
+	lines, err = fn(lines)
+	if err != nil {
+		if errors.Is(err, errSkipWrite) {
+			return nil
+		}
+		return err
+	}
+	return atomicWriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+}
+
+// installCodexNotify sets notify in the Codex config to run 'vybe hook codex'.
+// A notify program that is not vybe's is left in place and reported with
+// status "conflict", since Codex runs only one. With projectScoped, a vybe
+// section is also written to ./AGENTS.md, because notify cannot add context.
+// When every hook kind a turn serves is disabled in config, vybe's notify is
+// removed instead and the status is "disabled".
+func installCodexNotify(ctx context.Context, projectScoped bool) (*codexInstallResult, error) {
+	path := codexConfigPath()
+	res := &codexInstallResult{Path: path}
+	want := formatCodexNotify(codexNotifyArgs())
+
+	if !hookEventEnabled(codexHookKinds, codexTurnEvent) {
+		if _, err := uninstallCodexNotify(false); err != nil {
+			return nil, err
+		}
+		res.Status = "disabled"
+		return res, nil
+	}
+
+	if err := withLockedCodexConfig(path, func(lines []string) ([]string, error) {
+		start, end := findCodexNotify(lines)
+		if start < 0 {
+			res.Status = "installed"
+			at := codexTopLevelEnd(lines)
+			for at > 0 && strings.TrimSpace(lines[at-1]) == "" {
+				at--
+			}
+			insert := []string{want}
+			if at < len(lines) && strings.TrimSpace(lines[at]) != "" {
+				insert = append(insert, "")
+			}
+			return append(lines[:at:at], append(insert, lines[at:]...)...), nil
+		}
+
+		current := strings.Join(lines[start:end+1], "\n")
+		if !isVybeCodexNotify(parseCodexNotify(current[strings.Index(current, "=")+1:])) {
+			res.Status = "conflict"
+			res.Existing = strings.TrimSpace(current)
+			return nil, errSkipWrite
+		}
+		if start == end && strings.TrimSpace(lines[start]) == want {
+			res.Status = "skipped"
+			return nil, errSkipWrite
+		}
+		res.Status = "updated"
+		return append(lines[:start:start], append([]string{want}, lines[end+1:]...)...), nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if projectScoped {
+		agents := codexAgentsPath()
+		if err := writeCodexAgentsBlock(agents); err != nil {
+			return nil, err
+		}
+		res.AgentsPath = agents
+	}
+
+	ensureHookAgentStateBestEffort(ctx, "codex")
+	return res, nil
+}
+
+// uninstallCodexNotify removes vybe's notify setting from the Codex config and,
+// with projectScoped, the vybe section of ./AGENTS.md.
+func uninstallCodexNotify(projectScoped bool) (*codexUninstallResult, error) {
+	path := codexConfigPath()
+	res := &codexUninstallResult{Path: path}
+
+	if err := withLockedCodexConfig(path, func(lines []string) ([]string, error) {
+		start, end := findCodexNotify(lines)
+		if start < 0 {
+			return nil, errSkipWrite
+		}
+		current := strings.Join(lines[start:end+1], "\n")
+		if !isVybeCodexNotify(parseCodexNotify(current[strings.Index(current, "=")+1:])) {
+			return nil, errSkipWrite
+		}
+		res.Removed = true
+		return append(lines[:start:start], lines[end+1:]...), nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if projectScoped {
+		removed, err := removeCodexAgentsBlock(codexAgentsPath())
+		if err != nil {
+			return nil, err
+		}
+		res.AgentsRemoved = removed
+	}
+	return res, nil
+}
+
+// writeCodexAgentsBlock replaces the vybe section of AGENTS.md, or appends it.
+func writeCodexAgentsBlock(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: fixed AGENTS.md path in the working directory
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read AGENTS.md: %w", err)
+	}
+	content := string(data)
+	if before, after, ok := cutCodexAgentsBlock(content); ok {
+		content = before + codexAgentsBlock + after
+	} else {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		if content != "" {
+			content += "\n"
+		}
+		content += codexAgentsBlock
+	}
+	if err := atomicWriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("write AGENTS.md: %w", err)
+	}
+	return nil
+}
+
+// removeCodexAgentsBlock drops the vybe section from AGENTS.md, deleting the
+// file when nothing else is left.
+func removeCodexAgentsBlock(path string) (bool, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: fixed AGENTS.md path in the working directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("read AGENTS.md: %w", err)
+	}
+	before, after, ok := cutCodexAgentsBlock(string(data))
+	if !ok {
+		return false, nil
+	}
+	rest := strings.TrimRight(before, "\n")
+	if tail := strings.TrimLeft(after, "\n"); tail != "" {
+		rest += "\n\n" + tail
+	}
+	if strings.TrimSpace(rest) == "" {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("remove AGENTS.md: %w", err)
+		}
+		return true, nil
+	}
+	if !strings.HasSuffix(rest, "\n") {
+		rest += "\n"
+	}
+	if err := atomicWriteFile(path, []byte(rest), 0o644); err != nil {
+		return false, fmt.Errorf("write AGENTS.md: %w", err)
+	}
+	return true, nil
+}
+
+// cutCodexAgentsBlock splits content around the vybe section, including the
+// end marker's newline.
+func cutCodexAgentsBlock(content string) (before, after string, ok bool) {
+	start := strings.Index(content, codexAgentsBegin)
+	if start < 0 {
+		return content, "", false
+	}
+	end := strings.Index(content[start:], codexAgentsEnd)
+	if end < 0 {
+		return content, "", false
+	}
+	end += start + len(codexAgentsEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start], content[end:], true
+}
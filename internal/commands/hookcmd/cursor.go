@@ -0,0 +1,208 @@
+package hookcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+// cursorHookEvents are the Cursor hook events routed to 'vybe hook cursor'.
+// Cursor cannot inject context from hooks, so the project rule written by
+// --project tells the agent to read the brief itself.
+var cursorHookEvents = []string{"beforeSubmitPrompt", "stop"}
+
+// cursorHookKinds maps each Cursor event to the hook kind it serves.
+var cursorHookKinds = map[string][]string{ //nolint:gochecknoglobals // fixed lookup table of event to hook kind
+	"beforeSubmitPrompt": {app.HookPrompt},
+	"stop":               {app.HookCheckpoint},
+}
+
+const cursorRuleFilename = "vybe.mdc"
+
+const cursorRuleContent = `---
+description: vybe task continuity
+alwaysApply: true
+---
+This workspace records tasks, memory, and progress in vybe. Prompts and stops
+are logged by vybe hooks; read and update state with the CLI:
+
+- At the start of a conversation run ` + "`vybe resume --peek --agent cursor`" + ` and
+  continue the focus task it reports.
+- Record progress and completion with ` + "`vybe push --agent cursor --request-id <unique id> --json '{...}'`" + `.
+- Store durable facts with ` + "`vybe memory set --agent cursor --request-id <unique id> --key <k> --value <v>`" + `.
+`
+
+func cursorDir(projectScoped bool) string {
+	if projectScoped {
+		wd, err := os.Getwd()
+		if err != nil {
+			return ".cursor"
+		}
+		return filepath.Join(wd, ".cursor")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cursor")
+}
+
+func cursorHooksPath(projectScoped bool) string {
+	return filepath.Join(cursorDir(projectScoped), "hooks.json")
+}
+
+func cursorRulePath() string {
+	return filepath.Join(cursorDir(true), "rules", cursorRuleFilename)
+}
+
+// isVybeCursorEntry reports whether a Cursor hooks entry runs a vybe handler.
+func isVybeCursorEntry(entry any) bool {
+	m, ok := entry.(map[string]any)
+	if !ok {
+		return false
+	}
+	cmd, _ := m["command"].(string)
+	return IsVybeHookCommand(cmd) || cmd == buildVybeHookCommand("cursor")
+}
+
+type cursorInstallResult struct {
+	Path      string   `json:"path"`
+	Installed []string `json:"installed"`
+	Updated   []string `json:"updated,omitempty"`
+	Skipped   []string `json:"skipped"`
+	Disabled  []string `json:"disabled,omitempty"`
+	RulePath  string   `json:"rule_path,omitempty"`
+}
+
+type cursorUninstallResult struct {
+	Path        string   `json:"path"`
+	Removed     []string `json:"removed"`
+	RuleRemoved bool     `json:"rule_removed,omitempty"`
+}
+
+// installCursorHooks registers 'vybe hook cursor' for each Cursor hook event in
+// hooks.json (user-level, or ./.cursor with projectScoped), except events whose
+// hook kind is disabled in config. Project installs also write the vybe rule
+// to .cursor/rules.
+func installCursorHooks(ctx context.Context, projectScoped bool) (*cursorInstallResult, error) {
+	path := cursorHooksPath(projectScoped)
+	command := buildVybeHookCommand("cursor")
+	res := &cursorInstallResult{Path: path, Installed: []string{}, Skipped: []string{}}
+
+	if err := withLockedSettings(path, func(settings map[string]any) error {
+		if _, ok := settings["version"]; !ok {
+			settings["version"] = 1
+		}
+		hooksObj, _ := settings["hooks"].(map[string]any)
+		if hooksObj == nil {
+			hooksObj = map[string]any{}
+		}
+
+		for _, event := range cursorHookEvents {
+			existing, _ := hooksObj[event].([]any)
+			if !hookEventEnabled(cursorHookKinds, event) {
+				res.Disabled = append(res.Disabled, event)
+				removeHookEntries(hooksObj, event, existing, isVybeCursorEntry)
+				continue
+			}
+			var kept []any
+			hadVybe, current := false, false
+			for _, e := range existing {
+				if isVybeCursorEntry(e) {
+					hadVybe = true
+					if m, _ := e.(map[string]any); m["command"] == command {
+						current = true
+					}
+					continue
+				}
+				kept = append(kept, e)
+			}
+			hooksObj[event] = append(kept, map[string]any{"command": command})
+
+			switch {
+			case current:
+				res.Skipped = append(res.Skipped, event)
+			case hadVybe:
+				res.Updated = append(res.Updated, event)
+			default:
+				res.Installed = append(res.Installed, event)
+			}
+		}
+
+		settings["hooks"] = hooksObj
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if projectScoped {
+		rule := cursorRulePath()
+		if err := atomicWriteFile(rule, []byte(cursorRuleContent), 0o644); err != nil {
+			return nil, fmt.Errorf("write cursor rule: %w", err)
+		}
+		res.RulePath = rule
+	}
+
+	ensureHookAgentStateBestEffort(ctx, "cursor")
+
+	sort.Strings(res.Installed)
+	sort.Strings(res.Updated)
+	sort.Strings(res.Skipped)
+	return res, nil
+}
+
+// uninstallCursorHooks removes vybe entries from Cursor hooks.json and, for
+// project installs, the vybe rule if it is unmodified.
+func uninstallCursorHooks(projectScoped bool) (*cursorUninstallResult, error) {
+	path := cursorHooksPath(projectScoped)
+	res := &cursorUninstallResult{Path: path, Removed: []string{}}
+
+	if err := withLockedSettings(path, func(settings map[string]any) error {
+		hooksObj, _ := settings["hooks"].(map[string]any)
+		if hooksObj == nil {
+			return errSkipWrite
+		}
+		for _, event := range cursorHookEvents {
+			entries, ok := hooksObj[event].([]any)
+			if !ok {
+				continue
+			}
+			var kept []any
+			for _, e := range entries {
+				if isVybeCursorEntry(e) {
+					continue
+				}
+				kept = append(kept, e)
+			}
+			if len(kept) == len(entries) {
+				continue
+			}
+			res.Removed = append(res.Removed, event)
+			if len(kept) == 0 {
+				delete(hooksObj, event)
+			} else {
+				hooksObj[event] = kept
+			}
+		}
+		if len(res.Removed) == 0 {
+			return errSkipWrite
+		}
+		settings["hooks"] = hooksObj
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if projectScoped {
+		rule := cursorRulePath()
+		if data, err := os.ReadFile(rule); err == nil && string(data) == cursorRuleContent { //nolint:gosec // G304: fixed path under the project .cursor dir
+			if err := os.Remove(rule); err != nil {
+				return nil, fmt.Errorf("remove cursor rule: %w", err)
+			}
+			res.RuleRemoved = true
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,319 @@
+package hookcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// Doctor check statuses, worst last.
+const (
+	doctorNotInstalled = "not_installed"
+	doctorOK           = "ok"
+	doctorWarn         = "warn"
+	doctorError        = "error"
+)
+
+type doctorCheck struct {
+	Target   string   `json:"target"`
+	Path     string   `json:"path"`
+	Status   string   `json:"status"`
+	Events   []string `json:"events,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+func (c *doctorCheck) problem(status, format string, args ...any) {
+	c.Problems = append(c.Problems, fmt.Sprintf(format, args...))
+	if status == doctorError || c.Status != doctorError {
+		c.Status = status
+	}
+}
+
+type doctorDB struct {
+	Path  string `json:"path"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type doctorReport struct {
+	OK        bool          `json:"ok"`
+	Installed []string      `json:"installed"`
+	DB        doctorDB      `json:"db"`
+	Checks    []doctorCheck `json:"checks"`
+}
+
+// checkHookBinary returns a problem when exe cannot be run, or "".
+func checkHookBinary(exe string) string {
+	if !strings.ContainsRune(exe, filepath.Separator) {
+		if _, err := exec.LookPath(exe); err != nil {
+			return fmt.Sprintf("binary %q is not on PATH", exe)
+		}
+		return ""
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return fmt.Sprintf("binary %s does not exist", exe)
+	}
+	if info.IsDir() || info.Mode()&0o111 == 0 {
+		return fmt.Sprintf("binary %s is not executable", exe)
+	}
+	return ""
+}
+
+// vybeCommandLine reports whether a hook command runs a vybe hook handler,
+// including through this executable when it is not named vybe.
+func vybeCommandLine(command string) bool {
+	if IsVybeHookCommand(command) {
+		return true
+	}
+	exe, rest, ok := splitHookCommand(command)
+	return ok && exe == vybeExecutable() && strings.HasPrefix(rest, "hook ")
+}
+
+// entryCommands extracts the command lines of a hooks-array entry in either
+// the Claude Code shape ({"hooks": [{"command": ...}]}) or the Cursor shape
+// ({"command": ...}).
+func entryCommands(entry any) []string {
+	m, ok := entry.(map[string]any)
+	if !ok {
+		return nil
+	}
+	if cmd, ok := m["command"].(string); ok {
+		return []string{cmd}
+	}
+	var cmds []string
+	hooks, _ := m["hooks"].([]any)
+	for _, h := range hooks {
+		if hm, ok := h.(map[string]any); ok {
+			if cmd, ok := hm["command"].(string); ok {
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+	return cmds
+}
+
+// checkJSONHooks validates a hooks file in the Claude Code, Gemini, or Cursor
+// layout: the JSON is well-formed, every event whose hook kind is enabled has a
+// vybe entry, no disabled event still has one, and each vybe command's
+// executable exists.
+func checkJSONHooks(target, path string, events []string, kinds map[string][]string) doctorCheck {
+	c := doctorCheck{Target: target, Path: path, Status: doctorNotInstalled}
+	if _, err := os.Stat(path); err != nil {
+		return c
+	}
+	settings, err := readSettings(path)
+	if err != nil {
+		c.problem(doctorError, "settings file is not valid JSON: %v", err)
+		return c
+	}
+	raw, present := settings["hooks"]
+	if !present {
+		return c
+	}
+	hooksObj, ok := raw.(map[string]any)
+	if !ok {
+		c.problem(doctorError, "hooks is not a JSON object")
+		return c
+	}
+
+	var missing, stale []string
+	checked := map[string]bool{}
+	for _, event := range events {
+		enabled := hookEventEnabled(kinds, event)
+		entries, _ := hooksObj[event].([]any)
+		found := 0
+		for _, e := range entries {
+			for _, cmd := range entryCommands(e) {
+				if !vybeCommandLine(cmd) {
+					continue
+				}
+				found++
+				exe, _, _ := splitHookCommand(cmd)
+				if !checked[exe] {
+					checked[exe] = true
+					if p := checkHookBinary(exe); p != "" {
+						c.problem(doctorError, "%s", p)
+					}
+				}
+			}
+		}
+		switch {
+		case !enabled:
+			if found > 0 {
+				stale = append(stale, event)
+			}
+			continue
+		case found == 0:
+			missing = append(missing, event)
+		case found > 1:
+			c.problem(doctorWarn, "%s has %d vybe entries; re-run hook install to dedupe", event, found)
+		}
+		if found > 0 {
+			c.Events = append(c.Events, event)
+		}
+	}
+	if len(c.Events) == 0 {
+		c.Status = doctorNotInstalled
+		c.Problems = nil
+		return c
+	}
+	if c.Status == doctorNotInstalled {
+		c.Status = doctorOK
+	}
+	if len(missing) > 0 {
+		c.problem(doctorWarn, "no vybe hook for %s; re-run hook install", strings.Join(missing, ", "))
+	}
+	if len(stale) > 0 {
+		c.problem(doctorWarn, "%s disabled in config but still registered; re-run hook install", strings.Join(stale, ", "))
+	}
+	return c
+}
+
+// checkOpenCode validates the OpenCode bridge plugin file and its registration.
+func checkOpenCode() doctorCheck {
+	c := doctorCheck{Target: "opencode", Path: opencodePluginPath(), Status: doctorNotInstalled}
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return c
+	}
+	c.Status = doctorOK
+	if string(data) != opencodeBridgePluginSource {
+		c.problem(doctorWarn, "plugin differs from this vybe version; re-run hook install --opencode")
+	}
+	settings, err := readSettings(opencodeConfigPath())
+	if err != nil {
+		c.problem(doctorError, "%s is not valid JSON: %v", opencodeConfigPath(), err)
+		return c
+	}
+	plugins, _ := settings["plugin"].([]any)
+	for _, p := range plugins {
+		if p == opencodePluginEntry {
+			return c
+		}
+	}
+	c.problem(doctorWarn, "plugin is not listed in %s; re-run hook install --opencode", opencodeConfigPath())
+	return c
+}
+
+// checkCodex validates the notify setting in the Codex config.
+func checkCodex() doctorCheck {
+	c := doctorCheck{Target: "codex", Path: codexConfigPath(), Status: doctorNotInstalled}
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return c
+	}
+	lines := strings.Split(string(data), "\n")
+	start, end := findCodexNotify(lines)
+	if start < 0 {
+		return c
+	}
+	current := strings.Join(lines[start:end+1], "\n")
+	args := parseCodexNotify(current[strings.Index(current, "=")+1:])
+	if !isVybeCodexNotify(args) {
+		c.Problems = []string{"notify runs another program: " + strings.TrimSpace(current)}
+		return c
+	}
+	c.Status = doctorOK
+	c.Events = []string{codexTurnEvent}
+	if !hookEventEnabled(codexHookKinds, codexTurnEvent) {
+		c.problem(doctorWarn, "prompt and checkpoint hook kinds are disabled in config but notify is still set; re-run hook install --codex")
+	}
+	if p := checkHookBinary(args[0]); p != "" {
+		c.problem(doctorError, "%s", p)
+	}
+	return c
+}
+
+// checkHookDB opens the database hooks write to and runs a trivial query. It
+// does not create a missing database or run migrations.
+func checkHookDB(ctx context.Context) doctorDB {
+	dbPath, err := app.GetDBPath()
+	if err != nil {
+		return doctorDB{Error: err.Error()}
+	}
+	res := doctorDB{Path: dbPath}
+	if _, err := os.Stat(dbPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			res.Error = "database does not exist yet; the first hook or vybe command creates it"
+		} else {
+			res.Error = err.Error()
+		}
+		return res
+	}
+	db, err := store.OpenDB(ctx, dbPath)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer func() { _ = store.CloseDB(db) }()
+	var one int
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.OK = true
+	return res
+}
+
+// runDoctor checks every hook integration, user-level and in the current
+// directory, plus the database.
+func runDoctor(ctx context.Context) doctorReport {
+	checks := []doctorCheck{
+		checkJSONHooks("claude", claudeSettingsPath(), vybeHookEventNames(), claudeHookKinds),
+		checkJSONHooks("claude-project", projectClaudeSettingsPath(), vybeHookEventNames(), claudeHookKinds),
+		checkOpenCode(),
+		checkJSONHooks("cursor", cursorHooksPath(false), cursorHookEvents, cursorHookKinds),
+		checkJSONHooks("cursor-project", cursorHooksPath(true), cursorHookEvents, cursorHookKinds),
+		checkJSONHooks("gemini", geminiSettingsPath(false), geminiHookEventNames(), geminiHookKinds),
+		checkJSONHooks("gemini-project", geminiSettingsPath(true), geminiHookEventNames(), geminiHookKinds),
+		checkCodex(),
+	}
+
+	r := doctorReport{Installed: []string{}, DB: checkHookDB(ctx), Checks: checks}
+	r.OK = r.DB.OK
+	for _, c := range checks {
+		if c.Status != doctorNotInstalled {
+			r.Installed = append(r.Installed, c.Target)
+		}
+		if c.Status == doctorError {
+			r.OK = false
+		}
+	}
+	return r
+}
+
+// NewDoctorCmd creates the hook doctor command.
+func NewDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Validate installed hook configurations",
+		Long: `Checks every hook integration vybe can install, user-level and for the current
+directory, without changing anything:
+
+  - the settings file is well-formed JSON (config.toml for Codex)
+  - each event whose hook kind is enabled has exactly one vybe entry, and
+    events disabled in config (hooks.<kind>.enabled) have none
+  - the vybe binary each entry runs exists and is executable (or is on PATH)
+  - the OpenCode plugin matches this vybe version and is registered
+  - the database hooks write to opens and answers a query
+
+Each check reports ok, warn, error, or not_installed with its problems. ok is
+false when the database is unreachable or any check reports an error. To see
+what a hook would inject, use 'vybe hook test'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return output.PrintSuccess(runDoctor(cmd.Context()))
+		},
+	}
+}
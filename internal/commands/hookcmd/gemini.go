@@ -0,0 +1,187 @@
+package hookcmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+// geminiHookTimeouts lists the Gemini CLI hook events routed to
+// 'vybe hook gemini', with their timeouts in milliseconds. The handler maps
+// each onto the Claude Code handler for the same lifecycle point.
+var geminiHookTimeouts = map[string]int{
+	"SessionStart": 5000,
+	"BeforeAgent":  3000,
+	"AfterTool":    3000,
+	"PreCompress":  5000,
+	"SessionEnd":   5000,
+}
+
+// geminiHookKinds maps each Gemini CLI event to the hook kinds it serves.
+// AfterTool carries both failed and successful tool calls.
+var geminiHookKinds = map[string][]string{ //nolint:gochecknoglobals // fixed lookup table of event to hook kind
+	"SessionStart": {app.HookSessionStart},
+	"BeforeAgent":  {app.HookPrompt},
+	"AfterTool":    {app.HookToolFailure, app.HookToolSuccess},
+	"PreCompress":  {app.HookCheckpoint},
+	"SessionEnd":   {app.HookSessionEnd},
+}
+
+func geminiSettingsPath(projectScoped bool) string {
+	if projectScoped {
+		wd, err := os.Getwd()
+		if err != nil {
+			return filepath.Join(".gemini", "settings.json")
+		}
+		return filepath.Join(wd, ".gemini", "settings.json")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".gemini", "settings.json")
+}
+
+// isVybeGeminiEntry reports whether a Gemini hooks entry runs a vybe handler.
+func isVybeGeminiEntry(entry any) bool {
+	if isVybeHookEntry(entry) {
+		return true
+	}
+	m, _ := entry.(map[string]any)
+	hooks, _ := m["hooks"].([]any)
+	for _, h := range hooks {
+		if hm, ok := h.(map[string]any); ok && hm["command"] == buildVybeHookCommand("gemini") {
+			return true
+		}
+	}
+	return false
+}
+
+func geminiHookEventNames() []string {
+	events := make([]string, 0, len(geminiHookTimeouts))
+	for name := range geminiHookTimeouts {
+		events = append(events, name)
+	}
+	sort.Strings(events)
+	return events
+}
+
+type geminiInstallResult struct {
+	Path      string   `json:"path"`
+	Installed []string `json:"installed"`
+	Updated   []string `json:"updated,omitempty"`
+	Skipped   []string `json:"skipped"`
+	Disabled  []string `json:"disabled,omitempty"`
+}
+
+type geminiUninstallResult struct {
+	Path    string   `json:"path"`
+	Removed []string `json:"removed"`
+}
+
+// installGeminiHooks registers 'vybe hook gemini' for each Gemini CLI hook
+// event in settings.json (user-level, or ./.gemini with projectScoped). Gemini
+// uses the Claude Code hook entry shape; other entries are kept. Events whose
+// hook kinds are all disabled in config are left unregistered.
+func installGeminiHooks(ctx context.Context, projectScoped bool) (*geminiInstallResult, error) {
+	path := geminiSettingsPath(projectScoped)
+	command := buildVybeHookCommand("gemini")
+	res := &geminiInstallResult{Path: path, Installed: []string{}, Skipped: []string{}}
+
+	if err := withLockedSettings(path, func(settings map[string]any) error {
+		hooksObj, _ := settings["hooks"].(map[string]any)
+		if hooksObj == nil {
+			hooksObj = map[string]any{}
+		}
+
+		for _, event := range geminiHookEventNames() {
+			existing, _ := hooksObj[event].([]any)
+			if !hookEventEnabled(geminiHookKinds, event) {
+				res.Disabled = append(res.Disabled, event)
+				removeHookEntries(hooksObj, event, existing, isVybeGeminiEntry)
+				continue
+			}
+			entry := map[string]any{
+				"matcher": "",
+				"hooks": []any{map[string]any{
+					"type":    "command",
+					"command": command,
+					"timeout": geminiHookTimeouts[event],
+				}},
+			}
+
+			var kept []any
+			hadVybe, current := false, false
+			for _, e := range existing {
+				if isVybeGeminiEntry(e) {
+					hadVybe = true
+					if m, _ := e.(map[string]any); hookEntryEqual(m, entry) {
+						current = true
+					}
+					continue
+				}
+				kept = append(kept, e)
+			}
+			hooksObj[event] = append(kept, entry)
+
+			switch {
+			case current:
+				res.Skipped = append(res.Skipped, event)
+			case hadVybe:
+				res.Updated = append(res.Updated, event)
+			default:
+				res.Installed = append(res.Installed, event)
+			}
+		}
+
+		settings["hooks"] = hooksObj
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ensureHookAgentStateBestEffort(ctx, "gemini")
+	return res, nil
+}
+
+// uninstallGeminiHooks removes vybe entries from the Gemini CLI settings file.
+func uninstallGeminiHooks(projectScoped bool) (*geminiUninstallResult, error) {
+	path := geminiSettingsPath(projectScoped)
+	res := &geminiUninstallResult{Path: path, Removed: []string{}}
+
+	if err := withLockedSettings(path, func(settings map[string]any) error {
+		hooksObj, _ := settings["hooks"].(map[string]any)
+		if hooksObj == nil {
+			return errSkipWrite
+		}
+		for _, event := range geminiHookEventNames() {
+			entries, ok := hooksObj[event].([]any)
+			if !ok {
+				continue
+			}
+			var kept []any
+			for _, e := range entries {
+				if !isVybeGeminiEntry(e) {
+					kept = append(kept, e)
+				}
+			}
+			if len(kept) == len(entries) {
+				continue
+			}
+			res.Removed = append(res.Removed, event)
+			if len(kept) == 0 {
+				delete(hooksObj, event)
+			} else {
+				hooksObj[event] = kept
+			}
+		}
+		if len(res.Removed) == 0 {
+			return errSkipWrite
+		}
+		settings["hooks"] = hooksObj
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
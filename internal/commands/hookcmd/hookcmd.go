@@ -6,10 +6,15 @@
 //   - registry.go  — hook definitions (what hooks exist and their timeouts)
 //   - claude.go    — Claude Code settings I/O (read, merge, install, uninstall)
 //   - opencode.go  — OpenCode config and plugin file management
+//   - cursor.go    — Cursor hooks.json and project rule management
+//   - gemini.go    — Gemini CLI settings.json hook management
+//   - codex.go     — Codex CLI config.toml notify and AGENTS.md management
+//   - doctor.go    — read-only validation of installed hook configurations
 //   - hookcmd.go   — thin coordinator: CLI commands and message assembly
 package hookcmd
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -20,7 +25,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func ensureHookAgentStateBestEffort(agentName string) {
+func ensureHookAgentStateBestEffort(ctx context.Context, agentName string) {
 	if strings.TrimSpace(agentName) == "" {
 		return
 	}
@@ -31,19 +36,19 @@ func ensureHookAgentStateBestEffort(agentName string) {
 		return
 	}
 
-	db, err := store.OpenDB(dbPath)
+	db, err := store.OpenDB(ctx, dbPath)
 	if err != nil {
 		slog.Default().Warn("hook install: open db failed", "error", err)
 		return
 	}
 	defer func() { _ = store.CloseDB(db) }()
 
-	if err := store.RunMigrations(db); err != nil {
+	if err := store.RunMigrations(ctx, db); err != nil {
 		slog.Default().Warn("hook install: run migrations failed", "error", err)
 		return
 	}
 
-	if _, err := store.LoadOrCreateAgentState(db, agentName); err != nil {
+	if _, err := store.LoadOrCreateAgentState(ctx, db, agentName); err != nil {
 		slog.Default().Warn("hook install: initialize agent state failed", "agent", agentName, "error", err)
 	}
 }
@@ -71,8 +76,30 @@ func ResolveTargetFlags(cmd *cobra.Command) (claude bool, opencode bool, err err
 	return c, o, nil
 }
 
+// installTargets are the agent CLIs an install or uninstall applies to.
+type installTargets struct {
+	Claude, OpenCode, Cursor, Gemini, Codex bool
+}
+
+// resolveInstallTargets extends ResolveTargetFlags with --cursor, --gemini, and
+// --codex. Passing only those flags selects them alone instead of the Claude
+// default.
+func resolveInstallTargets(cmd *cobra.Command) (installTargets, error) {
+	var t installTargets
+	t.Cursor, _ = cmd.Flags().GetBool("cursor")
+	t.Gemini, _ = cmd.Flags().GetBool("gemini")
+	t.Codex, _ = cmd.Flags().GetBool("codex")
+	if (t.Cursor || t.Gemini || t.Codex) && !cmd.Flags().Changed("claude") && !cmd.Flags().Changed("opencode") {
+		return t, nil
+	}
+	var err error
+	t.Claude, t.OpenCode, err = ResolveTargetFlags(cmd)
+	return t, err
+}
+
 // buildInstallMessage assembles a human-readable summary of the install operation.
-func buildInstallMessage(claude *claudeInstallResult, opencode *opencodeInstallResult) string {
+func buildInstallMessage(claude *claudeInstallResult, opencode *opencodeInstallResult, cursor *cursorInstallResult,
+	gemini *geminiInstallResult, codex *codexInstallResult) string {
 	var parts []string
 	if claude != nil {
 		if len(claude.Installed) > 0 {
@@ -95,6 +122,40 @@ func buildInstallMessage(claude *claudeInstallResult, opencode *opencodeInstallR
 			parts = append(parts, "OpenCode bridge plugin already installed")
 		}
 	}
+	if cursor != nil {
+		switch {
+		case len(cursor.Installed) > 0:
+			parts = append(parts, fmt.Sprintf("Cursor hooks installed (%s)", strings.Join(cursor.Installed, ", ")))
+		case len(cursor.Updated) > 0:
+			parts = append(parts, fmt.Sprintf("Cursor hooks updated (%s)", strings.Join(cursor.Updated, ", ")))
+		default:
+			parts = append(parts, "Cursor hooks already installed")
+		}
+	}
+	if gemini != nil {
+		switch {
+		case len(gemini.Installed) > 0:
+			parts = append(parts, fmt.Sprintf("Gemini CLI hooks installed (%s)", strings.Join(gemini.Installed, ", ")))
+		case len(gemini.Updated) > 0:
+			parts = append(parts, fmt.Sprintf("Gemini CLI hooks updated (%s)", strings.Join(gemini.Updated, ", ")))
+		default:
+			parts = append(parts, "Gemini CLI hooks already installed")
+		}
+	}
+	if codex != nil {
+		switch codex.Status {
+		case "installed":
+			parts = append(parts, "Codex notify hook installed")
+		case "updated":
+			parts = append(parts, "Codex notify hook updated")
+		case "conflict":
+			parts = append(parts, "Codex notify hook not installed: config.toml already sets notify to another program")
+		case "disabled":
+			parts = append(parts, "Codex notify hook not installed: prompt and checkpoint hook kinds are disabled")
+		default:
+			parts = append(parts, "Codex notify hook already installed")
+		}
+	}
 	if len(parts) == 0 {
 		return ""
 	}
@@ -105,10 +166,32 @@ func buildInstallMessage(claude *claudeInstallResult, opencode *opencodeInstallR
 func NewInstallCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "install",
-		Short: "Install vybe hooks for Claude and/or OpenCode",
-		Long:  "Installs Claude Code hooks and/or OpenCode bridge plugin.",
+		Short: "Install vybe hooks for Claude, OpenCode, Cursor, Gemini CLI, and/or Codex",
+		Long: `Installs Claude Code hooks, the OpenCode bridge plugin, and/or Cursor, Gemini CLI,
+or Codex hooks.
+
+--cursor writes ~/.cursor/hooks.json (./.cursor/hooks.json with --project) so Cursor
+runs 'vybe hook cursor' on beforeSubmitPrompt and stop. Cursor hooks cannot add
+context to the prompt, so --project also writes .cursor/rules/vybe.mdc telling the
+agent to read its brief with 'vybe resume --peek'.
+
+--gemini writes ~/.gemini/settings.json (./.gemini/settings.json with --project) so
+Gemini CLI runs 'vybe hook gemini' on SessionStart, BeforeAgent, AfterTool,
+PreCompress, and SessionEnd. Each event is handled like its Claude Code
+counterpart, including session-start context.
+
+--codex sets notify in $CODEX_HOME/config.toml (default ~/.codex) so Codex runs
+'vybe hook codex' after each agent turn, logging the turn's prompts and reply.
+An existing notify program is left alone and reported as a conflict. Codex
+cannot take context from notify, so --project also adds a vybe section to
+./AGENTS.md telling the agent to read its brief with 'vybe resume --peek'.
+
+Hook kinds disabled in config (vybe config set hooks.<kind>.enabled false) are
+left unregistered, and vybe entries already installed for them are removed;
+their events are listed under "disabled". tool_success (Claude Code PostToolUse)
+is off unless enabled. Re-run install after changing these settings.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			installClaude, installOpenCode, err := ResolveTargetFlags(cmd)
+			targets, err := resolveInstallTargets(cmd)
 			if err != nil {
 				return err
 			}
@@ -119,25 +202,49 @@ func NewInstallCmd() *cobra.Command {
 				Message  string                 `json:"message"`
 				Claude   *claudeInstallResult   `json:"claude,omitempty"`
 				OpenCode *opencodeInstallResult `json:"opencode,omitempty"`
+				Cursor   *cursorInstallResult   `json:"cursor,omitempty"`
+				Gemini   *geminiInstallResult   `json:"gemini,omitempty"`
+				Codex    *codexInstallResult    `json:"codex,omitempty"`
 			}
 
 			resp := result{}
 
-			if installClaude {
-				resp.Claude, err = installClaudeHooks(projectScoped)
+			if targets.Claude {
+				resp.Claude, err = installClaudeHooks(cmd.Context(), projectScoped)
+				if err != nil {
+					return err
+				}
+			}
+
+			if targets.OpenCode {
+				resp.OpenCode, err = installOpenCodePlugin(cmd.Context())
+				if err != nil {
+					return err
+				}
+			}
+
+			if targets.Cursor {
+				resp.Cursor, err = installCursorHooks(cmd.Context(), projectScoped)
+				if err != nil {
+					return err
+				}
+			}
+
+			if targets.Gemini {
+				resp.Gemini, err = installGeminiHooks(cmd.Context(), projectScoped)
 				if err != nil {
 					return err
 				}
 			}
 
-			if installOpenCode {
-				resp.OpenCode, err = installOpenCodePlugin()
+			if targets.Codex {
+				resp.Codex, err = installCodexNotify(cmd.Context(), projectScoped)
 				if err != nil {
 					return err
 				}
 			}
 
-			resp.Message = buildInstallMessage(resp.Claude, resp.OpenCode)
+			resp.Message = buildInstallMessage(resp.Claude, resp.OpenCode, resp.Cursor, resp.Gemini, resp.Codex)
 
 			return output.PrintSuccess(resp)
 		},
@@ -145,7 +252,11 @@ func NewInstallCmd() *cobra.Command {
 
 	cmd.Flags().Bool("claude", false, "Install Claude Code hooks")
 	cmd.Flags().Bool("opencode", false, "Install OpenCode bridge plugin")
-	cmd.Flags().Bool("project", false, "Install Claude hooks in ./.claude/settings.json")
+	cmd.Flags().Bool("cursor", false, "Install Cursor hooks")
+	cmd.Flags().Bool("gemini", false, "Install Gemini CLI hooks")
+	cmd.Flags().Bool("codex", false, "Install the Codex notify hook")
+	cmd.Flags().Bool("project", false, "Install Claude hooks in ./.claude/settings.json (Cursor: ./.cursor; Gemini: ./.gemini; Codex: also ./AGENTS.md)")
+	cmd.Annotations = map[string]string{"admin": "true"}
 
 	return cmd
 }
@@ -154,10 +265,12 @@ func NewInstallCmd() *cobra.Command {
 func NewUninstallCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "uninstall",
-		Short: "Remove vybe hooks for Claude and/or OpenCode",
-		Long:  "Removes Claude Code hook entries and/or OpenCode bridge plugin.",
+		Short: "Remove vybe hooks for Claude, OpenCode, Cursor, Gemini CLI, and/or Codex",
+		Long: `Removes Claude Code hook entries, the OpenCode bridge plugin, and/or Cursor or
+Gemini CLI hook entries. --codex removes vybe's notify setting from the Codex
+config (and, with --project, the vybe section of ./AGENTS.md).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			uninstallClaude, uninstallOpenCode, err := ResolveTargetFlags(cmd)
+			targets, err := resolveInstallTargets(cmd)
 			if err != nil {
 				return err
 			}
@@ -167,18 +280,21 @@ func NewUninstallCmd() *cobra.Command {
 			type result struct {
 				Claude   *claudeUninstallResult   `json:"claude,omitempty"`
 				OpenCode *opencodeUninstallResult `json:"opencode,omitempty"`
+				Cursor   *cursorUninstallResult   `json:"cursor,omitempty"`
+				Gemini   *geminiUninstallResult   `json:"gemini,omitempty"`
+				Codex    *codexUninstallResult    `json:"codex,omitempty"`
 			}
 
 			resp := result{}
 
-			if uninstallClaude {
+			if targets.Claude {
 				resp.Claude, err = uninstallClaudeHooks(projectScoped)
 				if err != nil {
 					return err
 				}
 			}
 
-			if uninstallOpenCode {
+			if targets.OpenCode {
 				force, _ := cmd.Flags().GetBool("force")
 				resp.OpenCode, err = uninstallOpenCodePlugin(force)
 				if err != nil {
@@ -186,14 +302,39 @@ func NewUninstallCmd() *cobra.Command {
 				}
 			}
 
+			if targets.Cursor {
+				resp.Cursor, err = uninstallCursorHooks(projectScoped)
+				if err != nil {
+					return err
+				}
+			}
+
+			if targets.Gemini {
+				resp.Gemini, err = uninstallGeminiHooks(projectScoped)
+				if err != nil {
+					return err
+				}
+			}
+
+			if targets.Codex {
+				resp.Codex, err = uninstallCodexNotify(projectScoped)
+				if err != nil {
+					return err
+				}
+			}
+
 			return output.PrintSuccess(resp)
 		},
 	}
 
 	cmd.Flags().Bool("claude", false, "Uninstall Claude Code hooks")
 	cmd.Flags().Bool("opencode", false, "Uninstall OpenCode bridge plugin")
-	cmd.Flags().Bool("project", false, "Uninstall Claude hooks from ./.claude/settings.json")
+	cmd.Flags().Bool("cursor", false, "Uninstall Cursor hooks")
+	cmd.Flags().Bool("gemini", false, "Uninstall Gemini CLI hooks")
+	cmd.Flags().Bool("codex", false, "Uninstall the Codex notify hook")
+	cmd.Flags().Bool("project", false, "Uninstall Claude hooks from ./.claude/settings.json (Cursor: ./.cursor; Gemini: ./.gemini; Codex: also ./AGENTS.md)")
 	cmd.Flags().Bool("force", false, "Remove modified OpenCode plugin file")
+	cmd.Annotations = map[string]string{"admin": "true"}
 
 	return cmd
 }
@@ -202,12 +343,13 @@ func NewUninstallCmd() *cobra.Command {
 func NewHookCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "hook",
-		Short: "Hook installation and management for Claude/OpenCode",
+		Short: "Hook installation and management for Claude/OpenCode/Cursor/Gemini/Codex",
 		Args:  cobra.NoArgs,
 	}
 
 	cmd.AddCommand(NewInstallCmd())
 	cmd.AddCommand(NewUninstallCmd())
+	cmd.AddCommand(NewDoctorCmd())
 
 	return cmd
 }
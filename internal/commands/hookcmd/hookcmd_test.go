@@ -2,6 +2,7 @@ package hookcmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
 )
 
 func newTargetFlagCmd() *cobra.Command {
@@ -82,9 +85,9 @@ func TestIsVybeHookCommand(t *testing.T) {
 	require.False(t, IsVybeHookCommand("vybe hook retrospective"))
 	require.False(t, IsVybeHookCommand("vybe hook retrospective-bg"))
 	require.True(t, IsVybeHookCommand("vybe hook session-end"))
-	require.False(t, IsVybeHookCommand("vybe hook tool-success"))
-	require.False(t, IsVybeHookCommand("vybe hook subagent-stop"))
-	require.False(t, IsVybeHookCommand("vybe hook subagent-start"))
+	require.True(t, IsVybeHookCommand("vybe hook tool-success"))
+	require.True(t, IsVybeHookCommand("vybe hook subagent-stop"))
+	require.True(t, IsVybeHookCommand("vybe hook subagent-start"))
 	require.False(t, IsVybeHookCommand("vybe hook stop"))
 }
 
@@ -94,9 +97,13 @@ func TestVybeHookEventNames_ContainsAllEvents(t *testing.T) {
 		"SessionStart",
 		"UserPromptSubmit",
 		"PostToolUseFailure",
+		"PostToolUse",
+		"PreToolUse",
 		"PreCompact",
 		"SessionEnd",
 		"TaskCompleted",
+		"SubagentStart",
+		"SubagentStop",
 	}
 	for _, e := range expected {
 		require.Contains(t, events, e, "missing hook event: %s", e)
@@ -390,8 +397,11 @@ func TestInstallCmd_Claude_ProjectScoped(t *testing.T) {
 	// Verify all expected hook events are present and each has at least one entry
 	// with a hook subcommand. We can't use HasVybeHook here because the test binary
 	// is not named "vybe", so IsVybeHookCommand rejects the generated command.
-	// Instead we verify the structural shape directly.
-	for _, eventName := range vybeHookEventNames() {
+	// Instead we verify the structural shape directly. tool_success and pre_tool
+	// are off by default, so PostToolUse and PreToolUse are left unregistered.
+	require.NotContains(t, hooksObj, "PostToolUse")
+	require.NotContains(t, hooksObj, "PreToolUse")
+	for _, eventName := range enabledHookEvents(vybeHookEventNames(), claudeHookKinds) {
 		entries, ok := hooksObj[eventName].([]any)
 		require.True(t, ok, "missing hook event: %s", eventName)
 		require.NotEmpty(t, entries, "hook event %s has no entries", eventName)
@@ -441,7 +451,7 @@ func TestInstallCmd_Claude_ProjectScoped(t *testing.T) {
 
 	hooksObj2, ok := settings2["hooks"].(map[string]any)
 	require.True(t, ok, "settings should still have hooks key after second install")
-	require.Len(t, hooksObj2, len(vybeHookEventNames()), "hook count should be unchanged after second install")
+	require.Len(t, hooksObj2, len(enabledHookEvents(vybeHookEventNames(), claudeHookKinds)), "hook count should be unchanged after second install")
 }
 
 func TestInstallCmd_OpenCode(t *testing.T) {
@@ -637,3 +647,250 @@ func TestUninstallOpenCode_ForcedRemovesModifiedPlugin(t *testing.T) {
 	_, err := os.Stat(path)
 	require.True(t, os.IsNotExist(err), "plugin file should have been removed with --force")
 }
+
+func TestInstallUninstallCmd_Cursor_ProjectScoped(t *testing.T) {
+	dir := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+	hooksPath := filepath.Join(dir, ".cursor", "hooks.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(hooksPath), 0o755))
+	require.NoError(t, os.WriteFile(hooksPath, []byte(`{"version":1,"hooks":{"stop":[{"command":"./other.sh"}]}}`), 0o600))
+
+	for range 2 {
+		cmd := NewInstallCmd()
+		cmd.SetArgs([]string{"--cursor", "--project"})
+		require.NoError(t, cmd.Execute())
+	}
+
+	settings, err := readSettings(hooksPath)
+	require.NoError(t, err)
+	hooksObj, ok := settings["hooks"].(map[string]any)
+	require.True(t, ok)
+	for _, event := range cursorHookEvents {
+		entries, _ := hooksObj[event].([]any)
+		vybe := 0
+		for _, e := range entries {
+			if isVybeCursorEntry(e) {
+				vybe++
+			}
+		}
+		require.Equal(t, 1, vybe, "event %s should have exactly one vybe entry after reinstall", event)
+	}
+	require.Len(t, hooksObj["stop"], 2, "existing stop hook is preserved")
+	require.FileExists(t, filepath.Join(dir, ".cursor", "rules", cursorRuleFilename))
+	require.NoFileExists(t, filepath.Join(dir, ".claude", "settings.json"), "--cursor alone does not install Claude hooks")
+
+	cmd := NewUninstallCmd()
+	cmd.SetArgs([]string{"--cursor", "--project"})
+	require.NoError(t, cmd.Execute())
+
+	settings, err = readSettings(hooksPath)
+	require.NoError(t, err)
+	hooksObj, _ = settings["hooks"].(map[string]any)
+	require.NotContains(t, hooksObj, "beforeSubmitPrompt")
+	require.Len(t, hooksObj["stop"], 1)
+	require.NoFileExists(t, filepath.Join(dir, ".cursor", "rules", cursorRuleFilename))
+}
+
+func TestInstallUninstallCmd_Gemini_ProjectScoped(t *testing.T) {
+	dir := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+	settingsPath := filepath.Join(dir, ".gemini", "settings.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(settingsPath), 0o755))
+	require.NoError(t, os.WriteFile(settingsPath, []byte(`{"theme":"Default","hooks":{"AfterTool":[{"matcher":"","hooks":[{"type":"command","command":"./lint.sh"}]}]}}`), 0o600))
+
+	for range 2 {
+		cmd := NewInstallCmd()
+		cmd.SetArgs([]string{"--gemini", "--project"})
+		require.NoError(t, cmd.Execute())
+	}
+
+	settings, err := readSettings(settingsPath)
+	require.NoError(t, err)
+	require.Equal(t, "Default", settings["theme"])
+	hooksObj, ok := settings["hooks"].(map[string]any)
+	require.True(t, ok)
+	for _, event := range geminiHookEventNames() {
+		entries, _ := hooksObj[event].([]any)
+		vybe := 0
+		for _, e := range entries {
+			if isVybeGeminiEntry(e) {
+				vybe++
+			}
+		}
+		require.Equal(t, 1, vybe, "event %s should have exactly one vybe entry after reinstall", event)
+	}
+	require.Len(t, hooksObj["AfterTool"], 2, "existing AfterTool hook is preserved")
+	require.NoFileExists(t, filepath.Join(dir, ".claude", "settings.json"), "--gemini alone does not install Claude hooks")
+
+	cmd := NewUninstallCmd()
+	cmd.SetArgs([]string{"--gemini", "--project"})
+	require.NoError(t, cmd.Execute())
+
+	settings, err = readSettings(settingsPath)
+	require.NoError(t, err)
+	hooksObj, _ = settings["hooks"].(map[string]any)
+	require.NotContains(t, hooksObj, "SessionStart")
+	require.Len(t, hooksObj["AfterTool"], 1)
+}
+
+func TestInstallUninstallCmd_Codex(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	configPath := filepath.Join(codexHome, "config.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte("model = \"o3\"\n\n[profiles.fast]\nnotify = [\"other\"]\n"), 0o600))
+
+	dir := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("# Rules\n\nRun make test.\n"), 0o600))
+
+	for range 2 {
+		cmd := NewInstallCmd()
+		cmd.SetArgs([]string{"--codex", "--project"})
+		require.NoError(t, cmd.Execute())
+	}
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	want := formatCodexNotify(codexNotifyArgs())
+	require.Equal(t, "model = \"o3\"\n"+want+"\n\n[profiles.fast]\nnotify = [\"other\"]\n", string(data))
+	require.True(t, isVybeCodexNotify(parseCodexNotify(strings.TrimPrefix(want, "notify ="))))
+
+	agents, err := os.ReadFile(filepath.Join(dir, "AGENTS.md"))
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(string(agents), codexAgentsBegin), "reinstall replaces the vybe section")
+	require.True(t, strings.HasPrefix(string(agents), "# Rules\n\nRun make test.\n\n"))
+
+	cmd := NewUninstallCmd()
+	cmd.SetArgs([]string{"--codex", "--project"})
+	require.NoError(t, cmd.Execute())
+
+	data, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	require.Equal(t, "model = \"o3\"\n\n[profiles.fast]\nnotify = [\"other\"]\n", string(data))
+	agents, err = os.ReadFile(filepath.Join(dir, "AGENTS.md"))
+	require.NoError(t, err)
+	require.Equal(t, "# Rules\n\nRun make test.\n", string(agents))
+}
+
+func TestInstallCodexNotify_LeavesOtherNotify(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	configPath := filepath.Join(codexHome, "config.toml")
+	original := "notify = [\n  \"notify-send\",\n  \"codex\",\n]\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0o600))
+
+	res, err := installCodexNotify(context.Background(), false)
+	require.NoError(t, err)
+	require.Equal(t, "conflict", res.Status)
+	require.Contains(t, res.Existing, "notify-send")
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	require.Equal(t, original, string(data))
+}
+
+func TestRunDoctor_ReportsInstalledAndMalformed(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CODEX_HOME", filepath.Join(home, ".codex"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("VYBE_DB_PATH", filepath.Join(home, "vybe.db"))
+	dir := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+	_, err = installGeminiHooks(context.Background(), true)
+	require.NoError(t, err)
+	cursorPath := cursorHooksPath(false)
+	require.NoError(t, os.MkdirAll(filepath.Dir(cursorPath), 0o755))
+	require.NoError(t, os.WriteFile(cursorPath, []byte(`{"hooks":`), 0o600))
+
+	report := runDoctor(context.Background())
+	require.False(t, report.OK, "a malformed settings file fails the report")
+	require.True(t, report.DB.OK, "install created the database")
+	require.ElementsMatch(t, []string{"cursor", "gemini-project"}, report.Installed)
+
+	byTarget := map[string]doctorCheck{}
+	for _, c := range report.Checks {
+		byTarget[c.Target] = c
+	}
+	require.Equal(t, doctorOK, byTarget["gemini-project"].Status)
+	require.Equal(t, geminiHookEventNames(), byTarget["gemini-project"].Events)
+	require.Equal(t, doctorError, byTarget["cursor"].Status)
+	require.Equal(t, doctorNotInstalled, byTarget["claude"].Status)
+	require.Equal(t, doctorNotInstalled, byTarget["codex"].Status)
+}
+
+func TestInstallHooks_OmitsDisabledKinds(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CODEX_HOME", filepath.Join(home, ".codex"))
+	t.Setenv("VYBE_DB_PATH", filepath.Join(home, "vybe.db"))
+	dir := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+	configDir := filepath.Join(home, ".config", "vybe")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"),
+		[]byte("hooks:\n  tool_failure:\n    enabled: false\n  prompt:\n    enabled: false\n  checkpoint:\n    enabled: false\n"), 0o600))
+	_, err = app.ReloadSettings()
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = app.ReloadSettings() })
+
+	// A vybe entry installed before the kind was disabled is removed; others stay.
+	settingsPath := projectClaudeSettingsPath()
+	require.NoError(t, os.MkdirAll(filepath.Dir(settingsPath), 0o755))
+	require.NoError(t, os.WriteFile(settingsPath, []byte(`{"hooks":{"PostToolUseFailure":[
+		{"matcher":"","hooks":[{"type":"command","command":"vybe hook tool-failure","timeout":2000}]},
+		{"matcher":"","hooks":[{"type":"command","command":"notify-send failed"}]}]}}`), 0o600))
+
+	claude, err := installClaudeHooks(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PostToolUse", "PostToolUseFailure", "PreCompact", "PreToolUse", "UserPromptSubmit"}, claude.Disabled)
+	require.ElementsMatch(t, []string{"SessionEnd", "SessionStart", "SubagentStart", "SubagentStop", "TaskCompleted"}, claude.Installed)
+
+	settings, err := readSettings(settingsPath)
+	require.NoError(t, err)
+	hooksObj := settings["hooks"].(map[string]any)
+	require.NotContains(t, hooksObj, "UserPromptSubmit")
+	require.NotContains(t, hooksObj, "PreCompact")
+	failure := hooksObj["PostToolUseFailure"].([]any)
+	require.Len(t, failure, 1)
+	require.Equal(t, []string{"notify-send failed"}, entryCommands(failure[0]))
+
+	gemini, err := installGeminiHooks(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"AfterTool", "BeforeAgent", "PreCompress"}, gemini.Disabled)
+
+	cursor, err := installCursorHooks(context.Background(), false)
+	require.NoError(t, err)
+	require.Empty(t, cursor.Installed)
+	require.Equal(t, cursorHookEvents, cursor.Disabled)
+
+	codex, err := installCodexNotify(context.Background(), false)
+	require.NoError(t, err)
+	require.Equal(t, "disabled", codex.Status)
+	_, err = os.Stat(codexConfigPath())
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	report := runDoctor(context.Background())
+	for _, c := range report.Checks {
+		require.NotEqual(t, doctorWarn, c.Status, "%s: %v", c.Target, c.Problems)
+	}
+}
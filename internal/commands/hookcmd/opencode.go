@@ -1,6 +1,7 @@
 package hookcmd
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"log/slog"
@@ -103,7 +104,7 @@ type opencodeUninstallResult struct {
 }
 
 // installOpenCodePlugin installs the OpenCode bridge plugin file and registers it.
-func installOpenCodePlugin() (*opencodeInstallResult, error) {
+func installOpenCodePlugin(ctx context.Context) (*opencodeInstallResult, error) {
 	path := opencodePluginPath()
 
 	var status string
@@ -133,7 +134,7 @@ func installOpenCodePlugin() (*opencodeInstallResult, error) {
 		registered = reg
 	}
 
-	ensureHookAgentStateBestEffort("opencode-agent")
+	ensureHookAgentStateBestEffort(ctx, "opencode-agent")
 
 	return &opencodeInstallResult{Path: path, Status: status, Registered: registered}, nil
 }
@@ -6,6 +6,8 @@ import (
 	"sort"
 	"strings"
 	"sync"
+
+	"github.com/dotcommander/vybe/internal/app"
 )
 
 const vybeCommandFallback = "vybe"
@@ -60,6 +62,22 @@ func buildVybeHooks() map[string]hookEntry {
 				Timeout: 2000,
 			}},
 		},
+		"PostToolUse": {
+			Matcher: "",
+			Hooks: []hookHandler{{
+				Type:    "command",
+				Command: buildVybeHookCommand("tool-success"),
+				Timeout: 2000,
+			}},
+		},
+		"PreToolUse": {
+			Matcher: "",
+			Hooks: []hookHandler{{
+				Type:    "command",
+				Command: buildVybeHookCommand("pre-tool"),
+				Timeout: 2000,
+			}},
+		},
 		"PreCompact": {
 			Matcher: "",
 			Hooks: []hookHandler{{
@@ -84,6 +102,82 @@ func buildVybeHooks() map[string]hookEntry {
 				Timeout: 2000,
 			}},
 		},
+		"SubagentStart": {
+			Matcher: "",
+			Hooks: []hookHandler{{
+				Type:    "command",
+				Command: buildVybeHookCommand("subagent-start"),
+				Timeout: 2000,
+			}},
+		},
+		"SubagentStop": {
+			Matcher: "",
+			Hooks: []hookHandler{{
+				Type:    "command",
+				Command: buildVybeHookCommand("subagent-stop"),
+				Timeout: 2000,
+			}},
+		},
+	}
+}
+
+// claudeHookKinds maps each Claude Code event vybe registers to the hook kind
+// (hooks.<kind>.enabled) gating it.
+var claudeHookKinds = map[string][]string{ //nolint:gochecknoglobals // fixed lookup table of event to hook kind
+	"SessionStart":       {app.HookSessionStart},
+	"UserPromptSubmit":   {app.HookPrompt},
+	"PostToolUseFailure": {app.HookToolFailure},
+	"PostToolUse":        {app.HookToolSuccess},
+	"PreToolUse":         {app.HookPreTool},
+	"PreCompact":         {app.HookCheckpoint},
+	"SessionEnd":         {app.HookSessionEnd},
+	"TaskCompleted":      {app.HookTaskCompleted},
+	"SubagentStart":      {app.HookSubagentStart},
+	"SubagentStop":       {app.HookSubagentStop},
+}
+
+// hookEventEnabled reports whether any hook kind an event serves is enabled.
+// Events with no listed kind are always enabled.
+func hookEventEnabled(kinds map[string][]string, event string) bool {
+	listed, ok := kinds[event]
+	if !ok {
+		return true
+	}
+	for _, kind := range listed {
+		if app.HookKindEnabled(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// enabledHookEvents filters events to those hookEventEnabled allows.
+func enabledHookEvents(events []string, kinds map[string][]string) []string {
+	out := make([]string, 0, len(events))
+	for _, event := range events {
+		if hookEventEnabled(kinds, event) {
+			out = append(out, event)
+		}
+	}
+	return out
+}
+
+// removeHookEntries drops the entries of one event that isVybe matches,
+// deleting the event when nothing else is left.
+func removeHookEntries(hooksObj map[string]any, event string, existing []any, isVybe func(any) bool) {
+	var kept []any
+	for _, e := range existing {
+		if !isVybe(e) {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(existing) {
+		return
+	}
+	if len(kept) == 0 {
+		delete(hooksObj, event)
+	} else {
+		hooksObj[event] = kept
 	}
 }
 
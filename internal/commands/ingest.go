@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// maxIngestFileBytes bounds export files read by ingest subcommands.
+const maxIngestFileBytes = 32 << 20
+
+// NewIngestCmd creates the ingest command group for importing other agent-memory exports.
+func NewIngestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ingest",
+		Short: "Import memories from other agent-memory tools and session transcripts",
+		Long: `Ingest maps exports from other agent-memory products into vybe memory.
+Each record becomes a memory keyed "<source>.<id>", so re-running an import updates
+entries in place. Source confidence is kept in the memory_ingested event metadata.
+
+ingest transcript turns a Claude Code session transcript into events instead.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newIngestSourceCmd(actions.IngestSourceMem0,
+		"Import a mem0 export (get_all results JSON)",
+		`Maps mem0 memories: agent_id becomes agent scope, other records are global.
+metadata.confidence (or score) is treated as confidence; expiration_date becomes expires_at.`))
+	cmd.AddCommand(newIngestSourceCmd(actions.IngestSourceZep,
+		"Import a Zep facts/edges export",
+		`Maps Zep facts or graph edges into global memory. rating is treated as confidence;
+invalid_at/expired_at become expires_at, and facts already invalidated are skipped.`))
+	cmd.AddCommand(newIngestTranscriptCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newIngestSourceCmd(source, short, long string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   source,
+		Short: short,
+		Long:  long + "\n\nUse --dry-run to preview the mapping without writing (no request-id required).",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			scope, _ := cmd.Flags().GetString("scope")
+			scopeID, _ := cmd.Flags().GetString("scope-id")
+			minConfidence, _ := cmd.Flags().GetFloat64("min-confidence")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			if file == "" {
+				return cmdErr(errors.New("--file is required"))
+			}
+			data, err := readIngestFile(file)
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			plan, err := actions.PlanIngest(source, data, actions.IngestOptions{
+				Scope:         scope,
+				ScopeID:       scopeID,
+				MinConfidence: minConfidence,
+			})
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			if dryRun {
+				type resp struct {
+					DryRun bool                `json:"dry_run"`
+					Plan   *actions.IngestPlan `json:"plan"`
+				}
+				return output.PrintSuccess(resp{DryRun: true, Plan: plan})
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *actions.IngestResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.IngestMemoryIdempotent(cmd.Context(), db, agentName, requestID, plan)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				*actions.IngestResult
+				SkippedRecords []actions.IngestSkip `json:"skipped_records,omitempty"`
+			}
+			return output.PrintSuccess(resp{IngestResult: result, SkippedRecords: plan.Skipped})
+		},
+	}
+
+	cmd.Flags().String("file", "", "Path to the export JSON file (- for stdin)")
+	cmd.Flags().String("scope", "", "Override scope for all records (global, project, task, agent)")
+	cmd.Flags().String("scope-id", "", "Scope ID when --scope is not global")
+	cmd.Flags().Float64("min-confidence", 0, "Skip records whose source confidence is below this value (0-1)")
+	cmd.Flags().Bool("dry-run", false, "Preview mapped records without writing")
+
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "conditional"}
+	return cmd
+}
+
+func readIngestFile(path string) ([]byte, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path) //nolint:gosec // user-supplied export path is the point of the command
+		if err != nil {
+			return nil, fmt.Errorf("failed to open export file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxIngestFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export file: %w", err)
+	}
+	if len(data) > maxIngestFileBytes {
+		return nil, fmt.Errorf("export file exceeds %d bytes", maxIngestFileBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newIngestTranscriptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transcript",
+		Short: "Import a Claude Code session transcript as events",
+		Long: `Parses a Claude Code JSONL transcript into events tagged with the session id:
+user_prompt for each prompt, assistant_summary for each assistant text reply
+(cut to 500 characters), and tool_success or tool_failure for each tool result.
+Meta records, sub-agent records, and thinking blocks are left out.
+
+The transcript is found under ~/.claude/projects by --session, or read from
+--file. Events go to --project-id/--project-dir, or to the project the
+transcript's working directory maps to.
+
+Re-running an ingest skips entries already ingested. Prompts and tool failures
+the hooks already logged for the session are not written twice.
+
+Use --dry-run to preview the mapped events without writing (no request-id required).`,
+		Example: `  vybe ingest transcript --session "$SESSION_ID"
+  vybe ingest transcript --file ~/.claude/projects/-work-repo/abc.jsonl --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionID, _ := cmd.Flags().GetString("session")
+			file, _ := cmd.Flags().GetString("file")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			if sessionID == "" && file == "" {
+				return cmdErr(errors.New("--session or --file is required"))
+			}
+			if file == "" {
+				cwd := projectDir
+				if cwd == "" {
+					cwd, _ = os.Getwd()
+				}
+				if abs, err := filepath.Abs(cwd); err == nil {
+					cwd = abs
+				}
+				path, err := findSessionTranscript(cwd, sessionID)
+				if err != nil {
+					return cmdErr(err)
+				}
+				file = path
+			}
+
+			plan, err := planTranscriptFile(file, sessionID)
+			if err != nil {
+				return cmdErr(err)
+			}
+			projectID := projectScopeFlag(cmd)
+			if projectID == "" && plan.CWD != "" {
+				projectID = resolveProjectID(plan.CWD)
+			}
+
+			if dryRun {
+				type resp struct {
+					DryRun    bool                    `json:"dry_run"`
+					File      string                  `json:"file"`
+					ProjectID string                  `json:"project_id,omitempty"`
+					Plan      *actions.TranscriptPlan `json:"plan"`
+				}
+				return output.PrintSuccess(resp{DryRun: true, File: file, ProjectID: projectID, Plan: plan})
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *store.TranscriptIngestResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if projectID != "" {
+					if _, err := store.EnsureProjectByID(cmd.Context(), db, projectID, filepath.Base(projectID)); err != nil {
+						return err
+					}
+				}
+				r, err := actions.TranscriptIngestIdempotent(cmd.Context(), db, agentName, requestID, projectID, plan)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("session", "", "Session ID whose transcript to ingest")
+	cmd.Flags().String("file", "", "Transcript JSONL path (- for stdin; default: found by --session)")
+	addProjectScopeFlags(cmd, "Project for the events (default: the transcript's working directory)")
+	cmd.Flags().Bool("dry-run", false, "Preview mapped events without writing")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "conditional"}
+	return cmd
+}
+
+func planTranscriptFile(path, sessionID string) (*actions.TranscriptPlan, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path) //nolint:gosec // user-supplied transcript path is the point of the command
+		if err != nil {
+			return nil, fmt.Errorf("failed to open transcript: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+	return actions.PlanTranscriptIngest(r, sessionID)
+}
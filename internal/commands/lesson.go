@@ -0,0 +1,289 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewLessonCmd creates the lesson command group for the lessons-learned
+// knowledge base.
+func NewLessonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lesson",
+		Short: "Record, rank, and search lessons learned",
+		Long: `Lessons are short, reusable findings ("run migrations before the API tests")
+kept per project or globally. Each has a confidence (0-1, new lessons start at
+0.5) and a hit count of how many briefs carried it. Briefs and session-start
+context include the top-ranked lessons for the focus project plus global ones,
+capped by brief.max_lessons in config (default 5).
+
+promote and demote move confidence by 0.2. feedback records whether a lesson
+helped: --helpful adds 0.1, --wrong takes 0.15. Lessons below 0.2 stay listed
+but no longer reach briefs.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newLessonListCmd())
+	cmd.AddCommand(newLessonSearchCmd())
+	cmd.AddCommand(newLessonAddCmd())
+	cmd.AddCommand(newLessonPromoteCmd())
+	cmd.AddCommand(newLessonDemoteCmd())
+	cmd.AddCommand(newLessonFeedbackCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func runLessonQuery(cmd *cobra.Command, query string) error {
+	projectID := projectScopeFlag(cmd)
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	var lessons []*store.Lesson
+	if err := withDB(cmd.Context(), func(db *DB) error {
+		l, err := actions.LessonList(cmd.Context(), db, store.LessonQuery{ProjectID: projectID, Query: query, Limit: limit})
+		if err != nil {
+			return err
+		}
+		lessons = l
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	type resp struct {
+		ProjectID string          `json:"project_id,omitempty"`
+		Query     string          `json:"query,omitempty"`
+		Count     int             `json:"count"`
+		Lessons   []*store.Lesson `json:"lessons"`
+	}
+	return output.PrintSuccess(resp{ProjectID: projectID, Query: query, Count: len(lessons), Lessons: lessons})
+}
+
+func newLessonListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List lessons, best ranked first",
+		Long: `Lists lessons ranked by confidence, then hit count. With a project, lists that
+project's lessons plus global ones; without, lists every lesson.`,
+		Example: `  vybe lesson list --project-dir "$PWD"`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLessonQuery(cmd, "")
+		},
+	}
+
+	addProjectScopeFlags(cmd, "Project ID (its lessons plus global ones)")
+	cmd.Flags().Int("limit", 50, "Maximum lessons to return")
+	return cmd
+}
+
+func newLessonSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Search lessons by text",
+		Long: `Returns lessons whose text contains every word of --query (case-insensitive),
+ranked like lesson list.`,
+		Example: `  vybe lesson search --query "migration test"`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query, _ := cmd.Flags().GetString("query")
+			if query == "" {
+				return cmdErr(errors.New("--query is required"))
+			}
+			return runLessonQuery(cmd, query)
+		},
+	}
+
+	cmd.Flags().String("query", "", "Words the lesson must contain (required)")
+	addProjectScopeFlags(cmd, "Project ID (its lessons plus global ones)")
+	cmd.Flags().Int("limit", 50, "Maximum lessons to return")
+	return cmd
+}
+
+func newLessonAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Record a lesson",
+		Long: `Records a lesson for a project, or a global lesson when no project is given,
+and writes a lesson_added event. Adding text the project already has returns
+the existing lesson unchanged.`,
+		Example: `  vybe lesson add --project-dir "$PWD" --text "Run migrations before the API tests"`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text, _ := cmd.Flags().GetString("text")
+			source, _ := cmd.Flags().GetString("source")
+			if text == "" {
+				return cmdErr(errors.New("--text is required"))
+			}
+			var confidence *float64
+			if cmd.Flags().Changed("confidence") {
+				c, _ := cmd.Flags().GetFloat64("confidence")
+				confidence = &c
+			}
+			projectID := projectScopeFlag(cmd)
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *store.LessonChange
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.LessonAddIdempotent(cmd.Context(), db, agentName, requestID, projectID, text, source, confidence)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("text", "", "The lesson (required)")
+	addProjectScopeFlags(cmd, "Project the lesson applies to (default: global)")
+	cmd.Flags().String("source", "", "Where the lesson came from (e.g. a task ID or session)")
+	cmd.Flags().Float64("confidence", store.DefaultLessonConfidence, "Initial confidence (0-1)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newLessonPromoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "promote",
+		Short: "Raise a lesson's confidence",
+		Long: `Raises a lesson's confidence by 0.2 (capped at 1) and writes a lesson_ranked
+event. --global also makes a project lesson global, so every project's briefs
+can carry it.`,
+		Example: `  vybe lesson promote --id 12
+  vybe lesson promote --id 12 --global`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, _ := cmd.Flags().GetInt64("id")
+			global, _ := cmd.Flags().GetBool("global")
+			if id <= 0 {
+				return cmdErr(errors.New("--id is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *store.LessonChange
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.LessonPromoteIdempotent(cmd.Context(), db, agentName, requestID, id, global)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().Int64("id", 0, "Lesson ID (required)")
+	cmd.Flags().Bool("global", false, "Also make a project lesson global")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newLessonDemoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "demote",
+		Short: "Lower a lesson's confidence",
+		Long: `Lowers a lesson's confidence by 0.2 (floored at 0) and writes a lesson_ranked
+event. Lessons below 0.2 no longer reach briefs.`,
+		Example: `  vybe lesson demote --id 12`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, _ := cmd.Flags().GetInt64("id")
+			if id <= 0 {
+				return cmdErr(errors.New("--id is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *store.LessonChange
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.LessonDemoteIdempotent(cmd.Context(), db, agentName, requestID, id)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().Int64("id", 0, "Lesson ID (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newLessonFeedbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "feedback",
+		Short: "Report whether a lesson helped",
+		Long: `Records a verdict on a lesson and writes a lesson_feedback event. --helpful
+raises confidence by 0.1; --wrong lowers it by 0.15, so a lesson reported wrong
+three times drops below 0.2 and out of briefs. The lesson's helpful_count and
+wrong_count keep the tally.`,
+		Example: `  vybe lesson feedback --id 12 --helpful
+  vybe lesson feedback --id 12 --wrong --note "the migration order changed in v2"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, _ := cmd.Flags().GetInt64("id")
+			helpful, _ := cmd.Flags().GetBool("helpful")
+			wrong, _ := cmd.Flags().GetBool("wrong")
+			note, _ := cmd.Flags().GetString("note")
+			if id <= 0 {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if helpful == wrong {
+				return cmdErr(errors.New("pass exactly one of --helpful or --wrong"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *store.LessonChange
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.LessonFeedbackIdempotent(cmd.Context(), db, agentName, requestID, id, helpful, note)
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().Int64("id", 0, "Lesson ID (required)")
+	cmd.Flags().Bool("helpful", false, "The lesson helped")
+	cmd.Flags().Bool("wrong", false, "The lesson was wrong or misleading")
+	cmd.Flags().String("note", "", "Why, kept on the feedback event")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
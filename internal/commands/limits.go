@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// limitFlags maps limits set flags to their config keys.
+var limitFlags = []struct {
+	flag, key, usage string
+}{
+	{"tasks-per-day", "tasks_per_day", "Max tasks auto-claimed per UTC day (0 = unlimited)"},
+	{"events-per-session", "events_per_session", "Max events hooks record per client session (0 = unlimited)"},
+	{"llm-calls-per-day", "llm_calls_per_day", "Max agent commands loop spawns per UTC day (0 = unlimited)"},
+}
+
+// NewLimitsCmd creates the limits command group.
+func NewLimitsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "limits",
+		Short: "Workspace guardrails on auto-claimed tasks, session events, and LLM calls",
+		Long: `Limits contain runaway autonomous loops by policy. Each limit is counted in
+the active database, so every workspace is metered on its own:
+
+  tasks_per_day       tasks picked by resume or task claim per UTC day
+  events_per_session  events hooks record for one client session
+  llm_calls_per_day   agent commands spawned by loop per UTC day
+
+Defaults come from config.yaml (limits.*); a workspace's own limits override
+them field by field. Zero means unlimited. When a limit is reached, resume stops
+picking new tasks (limit_reached says which), task claim and loop stop, and
+hooks stop recording the session's events. loop, resume, and task claim accept
+--override-limits to go past tasks_per_day and llm_calls_per_day.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newLimitsStatusCmd())
+	cmd.AddCommand(newLimitsSetCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newLimitsStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show effective limits and today's usage",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var res *actions.LimitsStatusResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.LimitsStatus(cmd.Context(), db)
+				if err != nil {
+					return err
+				}
+				res = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+}
+
+func newLimitsSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set limits for a workspace, or the config.yaml defaults with --global",
+		Long: `Set writes the given limits to the workspace that owns the active database
+(or --workspace), replacing its previous overrides; unset flags fall back to
+config.yaml. With --global, the flags given are written to config.yaml instead.`,
+		Example: `  vybe limits set --tasks-per-day 20 --llm-calls-per-day 40
+  vybe limits set --global --events-per-session 2000`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			global, _ := cmd.Flags().GetBool("global")
+			name, _ := cmd.Flags().GetString("workspace")
+
+			if global {
+				changed := false
+				for _, f := range limitFlags {
+					if !cmd.Flags().Changed(f.flag) {
+						continue
+					}
+					v, _ := cmd.Flags().GetInt(f.flag)
+					if v < 0 {
+						return cmdErr(errors.New("limits must be zero (unlimited) or positive"))
+					}
+					if _, err := app.SetConfigValue("limits."+f.key, strconv.Itoa(v), ""); err != nil {
+						return cmdErr(err)
+					}
+					changed = true
+				}
+				if !changed {
+					return cmdErr(errors.New("no limits given"))
+				}
+				if _, err := app.ReloadSettings(); err != nil {
+					return cmdErr(err)
+				}
+			} else {
+				if name == "" {
+					if _, name = app.EffectiveLimits(); name == "" {
+						return cmdErr(errors.New("the active database belongs to no workspace; pass --workspace or --global"))
+					}
+				}
+				var limits app.LimitSettings
+				limits.TasksPerDay, _ = cmd.Flags().GetInt("tasks-per-day")
+				limits.EventsPerSession, _ = cmd.Flags().GetInt("events-per-session")
+				limits.LLMCallsPerDay, _ = cmd.Flags().GetInt("llm-calls-per-day")
+				ws, err := app.SetWorkspaceLimits(name, limits)
+				if err != nil {
+					return cmdErr(err)
+				}
+				return output.PrintSuccess(ws)
+			}
+
+			limits, workspace := app.EffectiveLimits()
+			type resp struct {
+				Workspace string            `json:"workspace,omitempty"`
+				Limits    app.LimitSettings `json:"limits"`
+			}
+			return output.PrintSuccess(resp{Workspace: workspace, Limits: limits})
+		},
+	}
+
+	for _, f := range limitFlags {
+		cmd.Flags().Int(f.flag, 0, f.usage)
+	}
+	cmd.Flags().String("workspace", "", "Workspace to set limits for (default: the active database's workspace)")
+	cmd.Flags().Bool("global", false, "Write the given limits to config.yaml as defaults for every workspace")
+	cmd.Annotations = map[string]string{"mutates": "true", "admin": "true"}
+	return cmd
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
@@ -16,6 +17,7 @@ import (
 	"time"
 
 	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/output"
 	"github.com/dotcommander/vybe/internal/store"
@@ -31,15 +33,22 @@ const (
 // NewLoopCmd creates the autonomous driver command.
 func NewLoopCmd() *cobra.Command {
 	var (
-		projectDir   string
-		maxTasks     int
-		maxFails     int
-		taskTimeout  string
-		cooldown     string
-		dryRun       bool
-		command      string
-		postHook     string
-		disableHooks bool
+		projectDir     string
+		projectID      string
+		maxTasks       int
+		maxFails       int
+		workers        int
+		taskTimeout    string
+		cooldown       string
+		dryRun         bool
+		command        string
+		postHook       string
+		disableHooks   bool
+		overrideLimits bool
+		schedule       string
+		window         string
+		retryAfter     time.Duration
+		maxRetries     int
 	)
 
 	cmd := &cobra.Command{
@@ -53,9 +62,54 @@ waits for completion, and moves to the next task.
 Safety rails:
   --max-tasks     Stop after N tasks completed (default: 10)
   --max-fails     Circuit breaker: stop after N consecutive failures (default: 3)
+  --workers       Run up to N tasks at once (default: 1)
   --task-timeout  Kill spawned command after duration (default: 10m)
   --cooldown      Wait between tasks (default: 5s)
-  --dry-run       Show what would run without spawning`,
+  --dry-run       Show what would run without spawning
+
+Queue filters point a dedicated loop at a slice of the queue:
+  --project       Only work tasks in this project ID (overrides --project-dir's scope)
+  --tag           Only work tasks carrying this tag (repeatable; all must match)
+  --min-priority  Only work tasks whose effective priority is at least N
+
+A current focus outside the filters is set aside rather than resumed.
+
+Each spawned command counts toward the llm_calls_per_day limit and each picked
+task toward tasks_per_day (see vybe limits). The loop exits cleanly when either
+is reached and reports it as limit_reached; --override-limits ignores both.
+
+With --workers N, N workers claim and run tasks concurrently. Each resumes
+under its own session (loop-<pid>-w<N>, passed to the spawned command as
+VYBE_SESSION_ID), so no two workers hold the same task; a worker refreshes its
+session every 30s while its agent runs. --max-tasks and --max-fails count
+across all workers, and the results report which worker ran each task.
+
+Blocked tasks can be retried:
+  --retry-after   Return a task the loop blocked (timeout, or exit without
+                  completing) to pending after this long, doubling each time
+  --max-retries   Leave it blocked after this many retries (default: 3)
+
+Each task counts its failed attempts (attempts, shown in task get and briefs);
+retry_at says when it goes back to pending. Due retries are requeued as the
+loop claims work, so a later or scheduled run picks them up.
+
+Each iteration's command output (stdout and stderr, also streamed to stderr) is
+stored on its task; results carry it as log_artifact_id, and vybe loop logs
+--task <id> lists a task's iterations.
+
+Scheduling keeps the loop running and starts runs only at set times:
+  --schedule      Cron expression; each time it fires, a run starts
+  --window        Daily window such as 22:00-06:00; runs start only inside it
+                  and stop claiming tasks when it closes
+
+With only --window, one run starts each time the window opens (or at once when
+started inside it). With both, fires outside the window are skipped. Each run
+honors the safety rails above and --post-hook. The next run time is saved for
+vybe loop schedule status; the loop exits on SIGINT or SIGTERM and reports the
+runs it made.
+
+Config changes (config.yaml edits, vybe config reload, or SIGHUP) are picked up
+between tasks without restarting the loop.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentName, err := requireActorName(cmd, "")
 			if err != nil {
@@ -71,52 +125,96 @@ Safety rails:
 				return cmdErr(fmt.Errorf("invalid --cooldown: %w", err))
 			}
 
+			if workers < 1 {
+				return cmdErr(fmt.Errorf("--workers must be at least 1"))
+			}
+			if retryAfter < 0 || maxRetries < 0 {
+				return cmdErr(fmt.Errorf("--retry-after and --max-retries must not be negative"))
+			}
 			if !dryRun && command == "" {
 				return cmdErr(fmt.Errorf("required flag(s) \"command\" not set"))
 			}
+			var cron *app.CronSchedule
+			if schedule != "" {
+				if cron, err = app.ParseCron(schedule); err != nil {
+					return cmdErr(err)
+				}
+			}
+			var runWindow *app.TimeWindow
+			if window != "" {
+				if runWindow, err = app.ParseTimeWindow(window); err != nil {
+					return cmdErr(err)
+				}
+			}
 
 			opts := runOptions{
-				agentName:    agentName,
-				project:      projectDir,
-				maxTasks:     maxTasks,
-				maxFails:     maxFails,
-				taskTimeout:  timeout,
-				cooldown:     cool,
-				dryRun:       dryRun,
-				command:      command,
-				postHook:     postHook,
-				disableHooks: disableHooks,
+				agentName:      agentName,
+				project:        projectDir,
+				projectID:      projectID,
+				filter:         queueFilterFromFlags(cmd),
+				maxTasks:       maxTasks,
+				maxFails:       maxFails,
+				workers:        workers,
+				taskTimeout:    timeout,
+				cooldown:       cool,
+				dryRun:         dryRun,
+				command:        command,
+				postHook:       postHook,
+				disableHooks:   disableHooks,
+				overrideLimits: overrideLimits,
+				schedule:       cron,
+				window:         runWindow,
+				retry:          actions.RetryPolicy{After: retryAfter, MaxRetries: maxRetries},
 			}
 
-			return runLoop(opts)
+			return runLoop(cmd.Context(), opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&projectDir, "project-dir", "", "Project directory to scope tasks and resume")
+	cmd.Flags().StringVar(&projectID, "project", "", "Only work tasks in this project ID")
+	addQueueFilterFlags(cmd)
 	cmd.Flags().IntVar(&maxTasks, "max-tasks", 10, "Stop after N tasks completed")
 	cmd.Flags().IntVar(&maxFails, "max-fails", 3, "Circuit breaker: stop after N consecutive failures")
+	cmd.Flags().IntVar(&workers, "workers", 1, "Run up to N tasks concurrently, each worker holding its own session")
 	cmd.Flags().StringVar(&taskTimeout, "task-timeout", "10m", "Kill spawned command after this duration")
 	cmd.Flags().StringVar(&cooldown, "cooldown", "5s", "Wait between tasks")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would run without spawning")
 	cmd.Flags().StringVar(&command, "command", "", "Command to spawn (receives prompt via -p flag)")
 	cmd.Flags().StringVar(&postHook, "post-hook", "", "Command to pipe run results JSON to on completion (must be explicitly set per run)")
+	cmd.Flags().BoolVar(&overrideLimits, "override-limits", false, "Keep going past the tasks_per_day and llm_calls_per_day limits")
+	cmd.Flags().DurationVar(&retryAfter, "retry-after", 0, "Requeue tasks the loop blocks after this backoff, doubling per attempt (0 = never)")
+	cmd.Flags().IntVar(&maxRetries, "max-retries", 3, "Retries per task before it stays blocked (with --retry-after)")
+	cmd.Flags().StringVar(&schedule, "schedule", "", "Cron expression (5 fields, local time) for when runs start; the loop waits between runs")
+	cmd.Flags().StringVar(&window, "window", "", "Daily local-time window runs are confined to, e.g. 22:00-06:00")
 	cmd.Flags().BoolVar(&disableHooks, "spawn-disable-hooks", false, "Disable hooks for spawned agents (sets hookless mode and isolation env vars)")
 
+	cmd.AddCommand(newLoopLogsCmd())
+	cmd.AddCommand(newLoopScheduleCmd())
+
 	cmd.Annotations = map[string]string{"mutates": "true"}
 	return cmd
 }
 
 type runOptions struct {
-	agentName    string
-	project      string
-	maxTasks     int
-	maxFails     int
-	taskTimeout  time.Duration
-	cooldown     time.Duration
-	dryRun       bool
-	command      string
-	postHook     string
-	disableHooks bool
+	agentName      string
+	project        string
+	projectID      string            // resume scope; defaults to project's resolved ID
+	filter         store.FocusFilter // tag and priority floor for picked tasks
+	maxTasks       int
+	maxFails       int
+	workers        int
+	taskTimeout    time.Duration
+	cooldown       time.Duration
+	dryRun         bool
+	command        string
+	postHook       string
+	disableHooks   bool
+	overrideLimits bool
+	schedule       *app.CronSchedule // start runs when this fires
+	window         *app.TimeWindow   // only run inside this daily window
+	until          time.Time         // stop claiming tasks at this time; zero = never
+	retry          actions.RetryPolicy
 }
 
 type taskResult struct {
@@ -124,159 +222,55 @@ type taskResult struct {
 	TaskTitle string `json:"task_title"`
 	Status    string `json:"status"` // completed, blocked, failed, timeout
 	Duration  string `json:"duration"`
+	Worker    int    `json:"worker,omitempty"` // set when --workers > 1
+	LogID     string `json:"log_artifact_id,omitempty"`
 }
 
-//nolint:gocognit,gocyclo,funlen,revive // run loop orchestrates per-task execution with claim, run, status-update, and retry phases
-func runLoop(opts runOptions) error {
-	loopStart := time.Now()
+// loopRunResponse summarizes one loop run. It is printed, and piped to
+// --post-hook.
+type loopRunResponse struct {
+	Completed    int          `json:"completed"`
+	Failed       int          `json:"failed"`
+	Total        int          `json:"total"`
+	Workers      int          `json:"workers"`
+	LimitReached string       `json:"limit_reached,omitempty"`
+	DurationSec  float64      `json:"duration_sec"`
+	Results      []taskResult `json:"results"`
+}
 
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+func runLoop(ctx context.Context, opts runOptions) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	var (
-		completed        int
-		failed           int
-		totalRun         int
-		consecutiveFails int
-		results          []taskResult
-	)
-
-	for completed < opts.maxTasks {
-		if ctx.Err() != nil {
-			slog.Default().Info("shutdown signal received, exiting gracefully",
-				"completed", completed, "failed", failed)
-			break
-		}
-
-		// Resume to get focus task
-		requestID := fmt.Sprintf("run_%d_%d", time.Now().UnixMilli(), totalRun)
+	startConfigWatch(ctx)
 
-		var response *actions.ResumeResponse
-		if err := withDB(func(db *DB) error {
-			r, err := actions.ResumeWithOptionsIdempotent(db, opts.agentName, requestID, actions.ResumeOptions{
-				EventLimit: 100,
-				ProjectDir: opts.project,
-			})
-			if err != nil {
-				return err
-			}
-			response = r
-			return nil
-		}); err != nil {
-			return cmdErr(err)
-		}
-
-		// No focus task = no more work
-		if response.FocusTaskID == "" {
-			slog.Default().Info("no pending tasks, exiting", "completed", completed, "failed", failed)
-			break
-		}
-
-		taskTitle := ""
-		if response.Brief != nil && response.Brief.Task != nil {
-			taskTitle = response.Brief.Task.Title
-		}
-
-		slog.Default().Info("task selected",
-			"task_id", response.FocusTaskID,
-			"title", taskTitle,
-			"iteration", totalRun+1,
-		)
-
-		if opts.dryRun {
-			results = append(results, taskResult{
-				TaskID:    response.FocusTaskID,
-				TaskTitle: taskTitle,
-				Status:    "dry_run",
-			})
-			totalRun++
-			completed++
-			continue
-		}
-
-		// Build the prompt for the agent
-		prompt := buildAgentPrompt(response, opts.project)
-
-		// Spawn the command
-		start := time.Now()
-		exitCode := spawnAgent(opts.command, prompt, opts.project, opts.taskTimeout, opts.disableHooks)
-		duration := time.Since(start)
-
-		// Check task status after agent finishes
-		var finalStatus models.TaskStatus
-		if err := withDB(func(db *DB) error {
-			task, err := store.GetTask(db, response.FocusTaskID)
-			if err != nil {
-				return err
-			}
-			finalStatus = task.Status
-			return nil
-		}); err != nil {
-			finalStatus = "unknown"
-		}
-
-		// Determine result
-		result := taskResult{
-			TaskID:    response.FocusTaskID,
-			TaskTitle: taskTitle,
-			Duration:  duration.Round(time.Second).String(),
-		}
+	if opts.schedule != nil || opts.window != nil {
+		return runScheduledLoop(ctx, opts)
+	}
 
-		switch {
-		case exitCode != 0 && duration >= opts.taskTimeout:
-			result.Status = "timeout"
-			markTaskBlocked(opts.agentName, response.FocusTaskID, "timed out")
-			consecutiveFails++
-			failed++
-		case finalStatus == "completed":
-			result.Status = "completed"
-			consecutiveFails = 0
-			completed++
-		case finalStatus == "in_progress" || finalStatus == "pending":
-			// Agent didn't mark it done — treat as blocked
-			result.Status = "blocked"
-			markTaskBlocked(opts.agentName, response.FocusTaskID, "agent exited without completing")
-			consecutiveFails++
-			failed++
-		default:
-			result.Status = string(finalStatus)
-			if finalStatus == "blocked" {
-				consecutiveFails++
-				failed++
-			} else {
-				consecutiveFails = 0
-				completed++
-			}
-		}
+	r, err := runLoopPass(ctx, opts)
+	if err != nil {
+		return cmdErr(err)
+	}
+	return output.PrintSuccess(r)
+}
 
-		results = append(results, result)
-		totalRun++
-
-		slog.Default().Info("task finished",
-			"task_id", response.FocusTaskID,
-			"status", result.Status,
-			"duration", result.Duration,
-			"completed", completed,
-			"failed", failed,
-		)
-
-		// Circuit breaker
-		if consecutiveFails >= opts.maxFails {
-			slog.Default().Warn("circuit breaker tripped", "consecutive_fails", consecutiveFails, "max_fails", opts.maxFails)
-			break
-		}
+// runLoopPass runs the workers until a safety rail stops them, records the
+// run_completed event, and runs the post-run hook.
+func runLoopPass(ctx context.Context, opts runOptions) (*loopRunResponse, error) {
+	loopStart := time.Now()
 
-		// Cooldown between tasks (interruptible by shutdown signal)
-		if completed < opts.maxTasks {
-			select {
-			case <-time.After(opts.cooldown):
-			case <-ctx.Done():
-			}
-		}
+	pool := newLoopPool(opts, spawnAgent)
+	if err := pool.run(ctx); err != nil {
+		return nil, err
 	}
+	completed, failed, totalRun, results := pool.completed, pool.failed, pool.totalRun, pool.results
 
 	duration := time.Since(loopStart)
 
+	// A run stopped by a signal still records its results.
+	ctx = context.WithoutCancel(ctx)
+
 	// Persist run results as event (non-fatal)
 	runResult := actions.RunResult{
 		Completed: completed,
@@ -285,26 +279,21 @@ func runLoop(opts runOptions) error {
 		Duration:  duration.Seconds(),
 	}
 	persistRequestID := fmt.Sprintf("run_result_%d", time.Now().UnixMilli())
-	if err := withDB(func(db *DB) error {
-		_, err := actions.PersistRunResultIdempotent(db, opts.agentName, persistRequestID, opts.project, runResult)
+	if err := withDB(ctx, func(db *DB) error {
+		_, err := actions.PersistRunResultIdempotent(ctx, db, opts.agentName, persistRequestID, opts.project, runResult)
 		return err
 	}); err != nil {
 		slog.Default().Warn("failed to persist run results", "error", err)
 	}
 
-	type resp struct {
-		Completed   int          `json:"completed"`
-		Failed      int          `json:"failed"`
-		Total       int          `json:"total"`
-		DurationSec float64      `json:"duration_sec"`
-		Results     []taskResult `json:"results"`
-	}
-	r := resp{
-		Completed:   completed,
-		Failed:      failed,
-		Total:       totalRun,
-		DurationSec: duration.Seconds(),
-		Results:     results,
+	r := &loopRunResponse{
+		Completed:    completed,
+		Failed:       failed,
+		Total:        totalRun,
+		Workers:      pool.opts.workers,
+		LimitReached: pool.limitReached,
+		DurationSec:  duration.Seconds(),
+		Results:      results,
 	}
 
 	// Execute post-run hook if configured (non-fatal)
@@ -317,7 +306,26 @@ func runLoop(opts runOptions) error {
 		}
 	}
 
-	return output.PrintSuccess(r)
+	return r, nil
+}
+
+// startConfigWatch reloads settings when config files change, `vybe config reload`
+// is run, or the process receives SIGHUP, so later iterations see new values.
+func startConfigWatch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	w := app.NewConfigWatcher(func(_ app.Settings, err error) {
+		if err != nil {
+			slog.Default().Warn("config reload failed; keeping the previous config", "error", err)
+			return
+		}
+		slog.Default().Info("config reloaded")
+	})
+	go func() {
+		defer signal.Stop(hup)
+		w.Run(ctx, hup)
+	}()
 }
 
 // execPostRunHook pipes run results JSON to an external command via stdin.
@@ -433,7 +441,14 @@ func buildAgentPrompt(r *actions.ResumeResponse, projectDir string) string {
 
 // spawnAgent runs the external command with the prompt and returns the exit code.
 // The prompt is passed via a temp file to avoid macOS's 256KB CLI argument size limit.
-func spawnAgent(command, prompt, project string, timeout time.Duration, disableHooks bool) int {
+// A pool worker passes its sessionID; its agent gets VYBE_SESSION_ID and no
+// stdin, which concurrent agents cannot share. The command's stdout and stderr
+// go to our stderr and, when out is non-nil, to out as well.
+func spawnAgent(command, prompt, project string, timeout time.Duration, disableHooks bool, sessionID string, out io.Writer) int {
+	if out == nil {
+		out = io.Discard
+	}
+
 	tmpFile, err := os.CreateTemp("", "vybe-prompt-*.txt")
 	if err != nil {
 		slog.Default().Error("failed to create temp file for prompt", "error", err)
@@ -465,13 +480,20 @@ func spawnAgent(command, prompt, project string, timeout time.Duration, disableH
 	} else {
 		cmd.Env = os.Environ()
 	}
-	cmd.Stdout = os.Stderr
-	cmd.Stderr = os.Stderr
+	// One writer for both streams keeps their interleaving in the transcript.
+	stream := io.MultiWriter(os.Stderr, out)
+	cmd.Stdout = stream
+	cmd.Stderr = stream
 	cmd.Stdin = os.Stdin
+	if sessionID != "" {
+		cmd.Env = append(cmd.Env, "VYBE_SESSION_ID="+sessionID)
+		cmd.Stdin = nil
+	}
 
 	// Start with timeout
 	if err := cmd.Start(); err != nil {
 		slog.Default().Error("failed to start command", "command", command, "error", err)
+		_, _ = fmt.Fprintf(out, "vybe: failed to start %s: %v\n", command, err)
 		return 1
 	}
 
@@ -496,6 +518,7 @@ func spawnAgent(command, prompt, project string, timeout time.Duration, disableH
 		}
 		waitForProcessExit(done)
 		slog.Default().Warn("command timed out, killed", "timeout", timeout)
+		_, _ = fmt.Fprintf(out, "vybe: killed after %s timeout\n", timeout)
 		return 124 // standard timeout exit code
 	}
 }
@@ -505,18 +528,22 @@ func isClaudeCommand(command string) bool {
 	return base == "claude"
 }
 
-// markTaskBlocked sets a task to blocked status via vybe and records the failure reason.
+// markTaskBlocked sets a task to blocked status via vybe and records the failure reason
+// and class in its failure history, scheduling a retry under policy.
 // Best-effort: called from error recovery path; DB errors are logged but not propagated.
-func markTaskBlocked(agentName, taskID, reason string) {
+func markTaskBlocked(ctx context.Context, agentName, taskID, reason, errorClass string, policy actions.RetryPolicy) {
 	//nolint:errcheck // best-effort recovery — if DB is also down, nothing to do
-	_ = withDB(func(db *DB) error {
+	_ = withDB(ctx, func(db *DB) error {
 		requestID := fmt.Sprintf("block_%s_%d", taskID, time.Now().UnixMilli())
 
 		// Log why it's blocked
-		_, _ = store.AppendEventIdempotent(db, agentName, requestID+"_log", "task_blocked", taskID, reason)
+		_, _ = store.AppendEventIdempotent(ctx, db, agentName, requestID+"_log", models.EventKindTaskBlocked, taskID, reason)
 
-		// Set status + blocked_reason atomically
-		_, _, err := actions.TaskSetStatusIdempotent(db, agentName, requestID, taskID, "blocked", models.BlockedReasonFailurePrefix+reason)
+		// Set status + blocked_reason + failure record atomically
+		res, err := actions.TaskFailIdempotent(ctx, db, agentName, requestID, taskID, reason, errorClass, policy)
+		if err == nil && res.Task.RetryAt != nil {
+			slog.Default().Info("task retry scheduled", "task_id", taskID, "attempts", res.Task.Attempts, "retry_at", res.Task.RetryAt.Format(time.RFC3339))
+		}
 		return err
 	})
 }
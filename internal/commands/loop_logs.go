@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// loopLogMaxBytes caps the output kept per loop iteration. A chattier command
+// keeps its last loopLogMaxBytes, where failures usually show.
+const loopLogMaxBytes = 1 << 20
+
+// tailBuffer is an io.Writer that keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu        sync.Mutex
+	max       int
+	buf       []byte
+	truncated bool
+}
+
+func newTailBuffer(maxBytes int) *tailBuffer {
+	return &tailBuffer{max: maxBytes}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// Bytes returns a copy of the kept output.
+func (b *tailBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte{}, b.buf...)
+}
+
+// Truncated reports whether earlier output was dropped.
+func (b *tailBuffer) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.truncated
+}
+
+func newLoopLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show what loop's spawned commands printed for a task",
+		Long: `Every loop iteration stores the spawned command's combined stdout and stderr
+(the last 1 MiB) as an artifact on its task, with a loop_iteration event
+recording the status, exit code, duration, and worker. logs lists a task's
+iterations newest first; --output includes the captured text.`,
+		Example: `  vybe loop logs --task task_123
+  vybe loop logs --task task_123 --limit 1 --output | jq -r '.data.logs[0].output'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("task")
+			limit, _ := cmd.Flags().GetInt("limit")
+			withOutput, _ := cmd.Flags().GetBool("output")
+			if taskID == "" {
+				return cmdErr(errors.New("--task is required"))
+			}
+
+			var logs []store.LoopLog
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				l, err := actions.LoopLogs(cmd.Context(), db, taskID, limit, withOutput)
+				if err != nil {
+					return err
+				}
+				logs = l
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				TaskID string          `json:"task_id"`
+				Count  int             `json:"count"`
+				Logs   []store.LoopLog `json:"logs"`
+			}
+			return output.PrintSuccess(resp{TaskID: taskID, Count: len(logs), Logs: logs})
+		},
+	}
+
+	cmd.Flags().String("task", "", "Task ID (required)")
+	cmd.Flags().Int("limit", 20, "Max iterations to return")
+	cmd.Flags().Bool("output", false, "Include each iteration's captured output")
+	return cmd
+}
@@ -0,0 +1,418 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/store"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// loopHeartbeatInterval is how often a pool worker marks its session active
+// while its spawned agent runs.
+const loopHeartbeatInterval = 30 * time.Second
+
+// loopSpawnFunc runs the agent command for one task and returns its exit code.
+// sessionID is the worker's session, empty for a single-worker loop. Everything
+// the command prints is also written to out.
+type loopSpawnFunc func(command, prompt, project string, timeout time.Duration, disableHooks bool, sessionID string, out io.Writer) int
+
+// loopPool runs up to opts.workers tasks at once. With more than one worker,
+// each worker resumes under its own session, so the task it holds is its
+// lease: session-scoped resume never hands a sibling session's focus to
+// another worker. Claims are serialized so every resume sees the ones before
+// it; the agents themselves run concurrently.
+type loopPool struct {
+	opts  runOptions
+	spawn loopSpawnFunc
+
+	claimMu sync.Mutex
+
+	mu               sync.Mutex
+	completed        int
+	failed           int
+	totalRun         int
+	running          int
+	consecutiveFails int
+	stopped          bool
+	limitReached     string // activity limit that stopped the loop
+	err              error
+	results          []taskResult
+}
+
+func newLoopPool(opts runOptions, spawn loopSpawnFunc) *loopPool {
+	if opts.workers < 1 {
+		opts.workers = 1
+	}
+	return &loopPool{opts: opts, spawn: spawn}
+}
+
+// run starts the workers and waits for all of them. The error is the first
+// resume failure; results gathered until then are kept.
+func (p *loopPool) run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for w := 1; w <= p.opts.workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			p.work(ctx, worker)
+		}(w)
+	}
+	wg.Wait()
+	return p.err
+}
+
+// sessionID names a worker's session. A single-worker loop uses the agent-wide
+// focus, as it always has.
+func (p *loopPool) sessionID(worker int) string {
+	if p.opts.workers <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("loop-%d-w%d", os.Getpid(), worker)
+}
+
+// scopeProjectID is the project resume is scoped to: --project when given,
+// otherwise the resolved --project-dir.
+func (p *loopPool) scopeProjectID() string {
+	if p.opts.projectID != "" {
+		return p.opts.projectID
+	}
+	return resolveProjectID(p.opts.project)
+}
+
+func (p *loopPool) work(ctx context.Context, worker int) {
+	ctx, span := telemetry.Start(ctx, "loop worker")
+	span.Set("vybe.loop.worker", worker)
+	defer span.End(nil)
+
+	// ctx only signals shutdown. Work it interrupts still settles the task and
+	// releases the session, under a context that keeps ctx's span but not its
+	// cancellation.
+	settleCtx := context.WithoutCancel(ctx)
+	sessionID := p.sessionID(worker)
+	if sessionID != "" {
+		defer p.releaseSession(settleCtx, sessionID)
+	}
+
+	for {
+		response, ok := p.claim(ctx, worker, sessionID)
+		if !ok {
+			return
+		}
+		result, success := p.execute(settleCtx, worker, sessionID, response)
+		if !p.finish(result, success) {
+			return
+		}
+		if p.opts.dryRun {
+			continue
+		}
+
+		// Cooldown between tasks (interruptible by shutdown signal)
+		select {
+		case <-time.After(p.opts.cooldown):
+		case <-ctx.Done():
+		}
+	}
+}
+
+// claim resumes the worker's next focus task. It returns false when the loop
+// should stop: shutdown, limits reached, circuit breaker, window closed, no
+// work, or error.
+func (p *loopPool) claim(ctx context.Context, worker int, sessionID string) (*actions.ResumeResponse, bool) {
+	p.claimMu.Lock()
+	defer p.claimMu.Unlock()
+
+	p.mu.Lock()
+	if ctx.Err() != nil {
+		slog.Default().Info("shutdown signal received, exiting gracefully",
+			"worker", worker, "completed", p.completed, "failed", p.failed)
+		p.mu.Unlock()
+		return nil, false
+	}
+	if p.stopped || p.completed+p.running >= p.opts.maxTasks {
+		p.mu.Unlock()
+		return nil, false
+	}
+	if !p.opts.until.IsZero() && !time.Now().Before(p.opts.until) {
+		slog.Default().Info("run window closed, exiting",
+			"worker", worker, "completed", p.completed, "failed", p.failed)
+		p.stopped = true
+		p.mu.Unlock()
+		return nil, false
+	}
+	seq := p.totalRun + p.running
+	p.mu.Unlock()
+
+	requestID := fmt.Sprintf("run_%d_%d", time.Now().UnixMilli(), seq)
+	if sessionID != "" {
+		requestID = fmt.Sprintf("run_%d_w%d_%d", time.Now().UnixMilli(), worker, seq)
+	}
+
+	// A claim under way when shutdown arrives still finishes.
+	ctx = context.WithoutCancel(ctx)
+	var response *actions.ResumeResponse
+	var limitErr *store.LimitExceededError
+	if err := withDB(ctx, func(db *DB) error {
+		if !p.opts.dryRun && !p.opts.overrideLimits {
+			// A reached limit ends the loop cleanly; it is not a command error.
+			if err := actions.CheckDailyLimit(ctx, db, store.LimitLLMCallsPerDay); errors.As(err, &limitErr) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+		if !p.opts.dryRun {
+			requeued, err := actions.TaskRequeueRetriesIdempotent(ctx, db, p.opts.agentName, requestID+"_requeue", p.scopeProjectID())
+			if err != nil {
+				return err
+			}
+			for _, t := range requeued {
+				slog.Default().Info("task requeued for retry", "task_id", t.TaskID, "attempts", t.Attempts)
+			}
+		}
+		r, err := actions.ResumeWithOptionsIdempotent(ctx, db, p.opts.agentName, requestID, actions.ResumeOptions{
+			EventLimit:     100,
+			ProjectDir:     p.scopeProjectID(),
+			SessionID:      sessionID,
+			Tags:           p.opts.filter.Tags,
+			MinPriority:    p.opts.filter.MinPriority,
+			OverrideLimits: p.opts.overrideLimits,
+		})
+		if err != nil {
+			return err
+		}
+		response = r
+		return nil
+	}); err != nil {
+		p.mu.Lock()
+		p.stopped = true
+		if p.err == nil {
+			p.err = err
+		}
+		p.mu.Unlock()
+		return nil, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if limitErr != nil {
+		p.stopAtLimit(limitErr.Limit, worker)
+		return nil, false
+	}
+	if response.LimitReached != "" {
+		p.stopAtLimit(response.LimitReached, worker)
+		return nil, false
+	}
+
+	// No focus task = no more work
+	if response.FocusTaskID == "" {
+		slog.Default().Info("no pending tasks, exiting", "worker", worker, "completed", p.completed, "failed", p.failed)
+		return nil, false
+	}
+	p.running++
+	return response, true
+}
+
+// stopAtLimit stops the loop on a reached activity limit. p.mu must be held.
+func (p *loopPool) stopAtLimit(limit string, worker int) {
+	p.stopped = true
+	if p.limitReached == "" {
+		p.limitReached = limit
+		slog.Default().Warn("activity limit reached, exiting (see vybe limits status)",
+			"limit", limit, "worker", worker, "completed", p.completed, "failed", p.failed)
+	}
+}
+
+// execute runs the spawned agent for one claimed task and classifies the
+// outcome. success reports whether the result resets the circuit breaker.
+func (p *loopPool) execute(ctx context.Context, worker int, sessionID string, response *actions.ResumeResponse) (taskResult, bool) {
+	taskTitle := ""
+	if response.Brief != nil && response.Brief.Task != nil {
+		taskTitle = response.Brief.Task.Title
+	}
+
+	slog.Default().Info("task selected",
+		"task_id", response.FocusTaskID,
+		"title", taskTitle,
+		"worker", worker,
+	)
+
+	result := taskResult{
+		TaskID:    response.FocusTaskID,
+		TaskTitle: taskTitle,
+	}
+	if sessionID != "" {
+		result.Worker = worker
+	}
+
+	if p.opts.dryRun {
+		result.Status = "dry_run"
+		return result, true
+	}
+
+	// Build the prompt for the agent
+	prompt := buildAgentPrompt(response, p.opts.project)
+
+	withDBSilent(ctx, func(db *DB) error {
+		return actions.RecordLLMCall(ctx, db)
+	})
+
+	// Spawn the command, keeping the worker's session alive while it runs
+	output := newTailBuffer(loopLogMaxBytes)
+	stopHeartbeat := p.heartbeat(ctx, sessionID)
+	start := time.Now()
+	exitCode := p.spawn(p.opts.command, prompt, p.opts.project, p.opts.taskTimeout, p.opts.disableHooks, sessionID, output)
+	duration := time.Since(start)
+	stopHeartbeat()
+
+	result.Duration = duration.Round(time.Second).String()
+	success := p.settle(ctx, &result, exitCode, duration)
+	result.LogID = p.recordLog(ctx, result, exitCode, duration, output)
+	return result, success
+}
+
+// settle sets result.Status from how the agent ended and what it left the task
+// at, blocking tasks it abandoned. It reports whether the result resets the
+// circuit breaker.
+func (p *loopPool) settle(ctx context.Context, result *taskResult, exitCode int, duration time.Duration) bool {
+	// Check task status after agent finishes
+	var finalStatus models.TaskStatus
+	if err := withDB(ctx, func(db *DB) error {
+		task, err := store.GetTask(ctx, db, result.TaskID)
+		if err != nil {
+			return err
+		}
+		finalStatus = task.Status
+		return nil
+	}); err != nil {
+		finalStatus = "unknown"
+	}
+
+	switch {
+	case exitCode != 0 && duration >= p.opts.taskTimeout:
+		result.Status = "timeout"
+		markTaskBlocked(ctx, p.opts.agentName, result.TaskID, "timed out", store.ErrorClassTimeout, p.opts.retry)
+		return false
+	case finalStatus == "completed":
+		result.Status = "completed"
+		return true
+	case finalStatus == "in_progress" || finalStatus == "pending":
+		// Agent didn't mark it done — treat as blocked
+		result.Status = "blocked"
+		markTaskBlocked(ctx, p.opts.agentName, result.TaskID, "agent exited without completing", store.ErrorClassIncomplete, p.opts.retry)
+		return false
+	default:
+		result.Status = string(finalStatus)
+		return finalStatus != "blocked"
+	}
+}
+
+// recordLog stores the iteration's captured output as a loop log artifact on
+// the task and returns its artifact ID. Best-effort: a failure is logged and
+// returns "".
+func (p *loopPool) recordLog(ctx context.Context, result taskResult, exitCode int, duration time.Duration, output *tailBuffer) string {
+	var artifactID string
+	withDBSilent(ctx, func(db *DB) error {
+		requestID := fmt.Sprintf("loop_log_%s_%d", result.TaskID, time.Now().UnixMilli())
+		l, err := actions.LoopLogRecordIdempotent(ctx, db, p.opts.agentName, requestID, store.LoopLog{
+			TaskID:      result.TaskID,
+			Status:      result.Status,
+			ExitCode:    exitCode,
+			DurationSec: duration.Seconds(),
+			Worker:      result.Worker,
+			Truncated:   output.Truncated(),
+		}, output.Bytes())
+		if err != nil {
+			return err
+		}
+		artifactID = l.ArtifactID
+		return nil
+	})
+	return artifactID
+}
+
+// finish records a task result and reports whether the worker should claim
+// another task.
+func (p *loopPool) finish(result taskResult, success bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.running--
+	p.totalRun++
+	if success {
+		p.completed++
+		p.consecutiveFails = 0
+	} else {
+		p.failed++
+		p.consecutiveFails++
+	}
+	p.results = append(p.results, result)
+
+	if !p.opts.dryRun {
+		slog.Default().Info("task finished",
+			"task_id", result.TaskID,
+			"status", result.Status,
+			"duration", result.Duration,
+			"worker", result.Worker,
+			"completed", p.completed,
+			"failed", p.failed,
+		)
+	}
+
+	// Circuit breaker
+	if p.consecutiveFails >= p.opts.maxFails && !p.stopped {
+		slog.Default().Warn("circuit breaker tripped", "consecutive_fails", p.consecutiveFails, "max_fails", p.opts.maxFails)
+		p.stopped = true
+	}
+	return !p.stopped && p.completed < p.opts.maxTasks
+}
+
+// heartbeat marks the worker's session active every loopHeartbeatInterval
+// until the returned func is called. It is a no-op without a session.
+func (p *loopPool) heartbeat(ctx context.Context, sessionID string) func() {
+	if sessionID == "" {
+		return func() {}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(loopHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				withDBSilent(ctx, func(db *DB) error {
+					return store.TouchSessionFocus(ctx, db, p.opts.agentName, sessionID)
+				})
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// releaseSession clears the worker's session focus when it exits, so a task
+// it left pending is not withheld from later sessions.
+func (p *loopPool) releaseSession(ctx context.Context, sessionID string) {
+	withDBSilent(ctx, func(db *DB) error {
+		return store.Transact(ctx, db, func(tx *sql.Tx) error {
+			return store.SetSessionFocusTx(tx, p.opts.agentName, sessionID, "", "")
+		})
+	})
+}
@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func TestLoopPool_WorkersRunDistinctTasksConcurrently(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "loop.db")
+	t.Setenv("VYBE_DB_PATH", dbPath)
+
+	db, err := store.InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	for i := range 4 {
+		_, err := store.CreateTask(context.Background(), db, fmt.Sprintf("Task %d", i), "", "", 0)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	sessions := map[string]bool{}
+	spawn := func(_, _, _ string, _ time.Duration, _ bool, sessionID string, _ io.Writer) int {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		sessions[sessionID] = true
+		mu.Unlock()
+
+		// Hold the task long enough for the other worker to claim one too,
+		// then complete it the way an agent would.
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, withDB(context.Background(), func(db *DB) error {
+			sf, _, err := store.GetSessionFocus(context.Background(), db, "loop-agent", sessionID)
+			if err != nil {
+				return err
+			}
+			task, err := store.GetTask(context.Background(), db, sf.FocusTaskID)
+			if err != nil {
+				return err
+			}
+			return store.UpdateTaskStatus(context.Background(), db, task.ID, "completed", task.Version)
+		}))
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return 0
+	}
+
+	pool := newLoopPool(runOptions{
+		agentName:   "loop-agent",
+		maxTasks:    10,
+		maxFails:    3,
+		workers:     2,
+		taskTimeout: time.Minute,
+		command:     "agent",
+	}, spawn)
+	require.NoError(t, pool.run(t.Context()))
+
+	assert.Equal(t, 4, pool.completed)
+	assert.Equal(t, 0, pool.failed)
+	assert.Equal(t, 2, peak, "both workers ran at once")
+	assert.Len(t, sessions, 2, "each worker has its own session")
+
+	seen := map[string]bool{}
+	for _, r := range pool.results {
+		assert.Equal(t, "completed", r.Status)
+		assert.NotZero(t, r.Worker)
+		assert.False(t, seen[r.TaskID], "task %s ran twice", r.TaskID)
+		seen[r.TaskID] = true
+	}
+}
+
+func TestLoopPool_MaxTasksCountsAcrossWorkers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "loop.db")
+	t.Setenv("VYBE_DB_PATH", dbPath)
+
+	db, err := store.InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	for i := range 5 {
+		_, err := store.CreateTask(context.Background(), db, fmt.Sprintf("Task %d", i), "", "", 0)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	pool := newLoopPool(runOptions{
+		agentName: "loop-agent",
+		maxTasks:  3,
+		maxFails:  3,
+		workers:   3,
+		dryRun:    true,
+	}, nil)
+	require.NoError(t, pool.run(t.Context()))
+
+	assert.Equal(t, 3, pool.completed)
+	assert.Len(t, pool.results, 3)
+}
+
+func TestLoopPool_StopsClaimingWhenWindowCloses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "loop.db")
+	t.Setenv("VYBE_DB_PATH", dbPath)
+
+	db, err := store.InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	_, err = store.CreateTask(context.Background(), db, "Task", "", "", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	pool := newLoopPool(runOptions{
+		agentName: "loop-agent",
+		maxTasks:  3,
+		maxFails:  3,
+		workers:   1,
+		dryRun:    true,
+		until:     time.Now().Add(-time.Minute),
+	}, nil)
+	require.NoError(t, pool.run(t.Context()))
+
+	assert.Equal(t, 0, pool.totalRun)
+	assert.Empty(t, pool.results)
+}
+
+func TestLoopPool_QueueFilters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "loop.db")
+	t.Setenv("VYBE_DB_PATH", dbPath)
+
+	db, err := store.InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	_, err = store.CreateTask(context.Background(), db, "Feature", "", "", 9)
+	require.NoError(t, err)
+	bug, err := store.CreateTask(context.Background(), db, "Bug", "", "", 2)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO task_tags (task_id, tag) VALUES (?, 'bugfix')`, bug.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	pool := newLoopPool(runOptions{
+		agentName: "loop-agent",
+		maxTasks:  1,
+		maxFails:  3,
+		dryRun:    true,
+		filter:    store.FocusFilter{Tags: []string{"bugfix"}},
+	}, nil)
+	require.NoError(t, pool.run(t.Context()))
+	require.Len(t, pool.results, 1)
+	assert.Equal(t, bug.ID, pool.results[0].TaskID)
+
+	floor := 3
+	pool = newLoopPool(runOptions{
+		agentName: "loop-agent",
+		maxTasks:  1,
+		maxFails:  3,
+		dryRun:    true,
+		filter:    store.FocusFilter{Tags: []string{"bugfix"}, MinPriority: &floor},
+	}, nil)
+	require.NoError(t, pool.run(t.Context()))
+	assert.Empty(t, pool.results, "nothing passes both filters")
+}
+
+func TestLoopPool_StopsAtLLMCallLimit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "loop.db")
+	t.Setenv("VYBE_DB_PATH", dbPath)
+	_, err := app.AddWorkspace("capped", dbPath)
+	require.NoError(t, err)
+	_, err = app.SetWorkspaceLimits("capped", app.LimitSettings{LLMCallsPerDay: 2})
+	require.NoError(t, err)
+
+	db, err := store.InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	for i := range 4 {
+		_, err := store.CreateTask(context.Background(), db, fmt.Sprintf("Task %d", i), "", "", 0)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	spawn := func(_, _, _ string, _ time.Duration, _ bool, _ string, _ io.Writer) int {
+		assert.NoError(t, withDB(context.Background(), func(db *DB) error {
+			state, err := store.GetAgentState(context.Background(), db, "loop-agent")
+			if err != nil {
+				return err
+			}
+			task, err := store.GetTask(context.Background(), db, state.FocusTaskID)
+			if err != nil {
+				return err
+			}
+			return store.UpdateTaskStatus(context.Background(), db, task.ID, "completed", task.Version)
+		}))
+		return 0
+	}
+
+	pool := newLoopPool(runOptions{
+		agentName:   "loop-agent",
+		maxTasks:    10,
+		maxFails:    3,
+		taskTimeout: time.Minute,
+		command:     "agent",
+	}, spawn)
+	require.NoError(t, pool.run(t.Context()))
+
+	assert.Equal(t, 2, pool.completed)
+	assert.Equal(t, store.LimitLLMCallsPerDay, pool.limitReached)
+}
+
+func TestLoopPool_RecordsIterationLogs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "loop.db")
+	t.Setenv("VYBE_DB_PATH", dbPath)
+
+	db, err := store.InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	task, err := store.CreateTask(context.Background(), db, "Flaky task", "", "", 0)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	spawn := func(_, _, _ string, _ time.Duration, _ bool, _ string, out io.Writer) int {
+		_, _ = fmt.Fprintln(out, "running tests")
+		_, _ = fmt.Fprintln(out, "FAIL: TestLogin")
+		return 1
+	}
+
+	pool := newLoopPool(runOptions{
+		agentName:   "loop-agent",
+		maxTasks:    1,
+		maxFails:    1,
+		taskTimeout: time.Minute,
+		command:     "agent",
+	}, spawn)
+	require.NoError(t, pool.run(t.Context()))
+	require.Len(t, pool.results, 1)
+	assert.Equal(t, "blocked", pool.results[0].Status)
+	assert.NotEmpty(t, pool.results[0].LogID)
+
+	require.NoError(t, withDB(context.Background(), func(db *DB) error {
+		logs, err := actions.LoopLogs(context.Background(), db, task.ID, 10, true)
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Equal(t, pool.results[0].LogID, logs[0].ArtifactID)
+		assert.Equal(t, "blocked", logs[0].Status)
+		assert.Equal(t, 1, logs[0].ExitCode)
+		require.NotNil(t, logs[0].Output)
+		assert.Equal(t, "running tests\nFAIL: TestLogin\n", *logs[0].Output)
+		return nil
+	}))
+}
+
+func TestTailBuffer_KeepsLastBytes(t *testing.T) {
+	b := newTailBuffer(8)
+	_, _ = b.Write([]byte("hello "))
+	assert.False(t, b.Truncated())
+	_, _ = b.Write([]byte("world!"))
+	assert.Equal(t, "o world!", string(b.Bytes()))
+	assert.True(t, b.Truncated())
+}
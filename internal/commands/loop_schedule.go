@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// scheduledLoopResponse summarizes a scheduled loop once it exits.
+type scheduledLoopResponse struct {
+	Schedule  string           `json:"schedule,omitempty"`
+	Window    string           `json:"window,omitempty"`
+	Runs      int              `json:"runs"`
+	Completed int              `json:"completed"`
+	Failed    int              `json:"failed"`
+	Total     int              `json:"total"`
+	LastRun   *loopRunResponse `json:"last_run,omitempty"`
+}
+
+// runScheduledLoop sleeps until the schedule (inside the window, if any)
+// allows a run, runs one pass, and repeats until ctx is cancelled. State and
+// the next run time are saved for `vybe loop schedule status`.
+func runScheduledLoop(ctx context.Context, opts runOptions) error {
+	state := store.LoopSchedule{
+		AgentName: opts.agentName,
+		PID:       os.Getpid(),
+	}
+	if opts.schedule != nil {
+		state.Schedule = opts.schedule.String()
+	}
+	if opts.window != nil {
+		state.Window = opts.window.String()
+	}
+	save := func() {
+		withDBSilent(ctx, func(db *DB) error {
+			if err := actions.LoopScheduleSave(ctx, db, state); err != nil {
+				slog.Default().Warn("failed to save loop schedule", "error", err)
+			}
+			return nil
+		})
+	}
+	defer func() {
+		state.State = store.LoopScheduleStopped
+		state.NextRunAt = nil
+		save()
+	}()
+
+	resp := scheduledLoopResponse{Schedule: state.Schedule, Window: state.Window}
+	after := time.Now()
+	for {
+		next, err := app.NextRun(opts.schedule, opts.window, after)
+		if err != nil {
+			return cmdErr(err)
+		}
+		state.State = store.LoopScheduleWaiting
+		state.NextRunAt = &next
+		save()
+		slog.Default().Info("waiting for next scheduled run", "at", next.Format(time.RFC3339))
+
+		if !sleepUntil(ctx, next) {
+			break
+		}
+
+		start := time.Now()
+		state.State = store.LoopScheduleRunning
+		state.LastRunAt = &start
+		save()
+
+		pass := opts
+		if opts.window != nil {
+			pass.until = opts.window.CloseAfter(start)
+		}
+		r, err := runLoopPass(ctx, pass)
+		if err != nil {
+			return cmdErr(err)
+		}
+
+		resp.Runs++
+		resp.Completed += r.Completed
+		resp.Failed += r.Failed
+		resp.Total += r.Total
+		resp.LastRun = r
+		state.Runs = resp.Runs
+		state.LastCompleted = r.Completed
+		state.LastFailed = r.Failed
+
+		if ctx.Err() != nil {
+			break
+		}
+		// Without a schedule, one pass per window: wait for it to close
+		// before looking for the next opening.
+		after = time.Now()
+		if opts.schedule == nil && pass.until.After(after) {
+			after = pass.until
+		}
+	}
+
+	return output.PrintSuccess(resp)
+}
+
+// sleepUntil blocks until t or until ctx is cancelled, reporting whether t
+// was reached.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// processAlive reports whether a process with pid exists on this host.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+func newLoopScheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Inspect scheduled loops",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newLoopScheduleStatusCmd())
+	return cmd
+}
+
+func newLoopScheduleStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show each scheduled loop's state and next run time",
+		Long: `Shows what vybe loop --schedule / --window saved: the schedule and window,
+whether the loop is waiting, running, or stopped, its next and last run times,
+and the last run's completed and failed counts. A loop recorded as waiting or
+running whose process is gone is reported with alive=false.`,
+		Example: `  vybe loop schedule status
+  vybe loop schedule status --agent night-worker`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Every agent's schedule unless --agent is given explicitly.
+			var agentName string
+			if cmd.Flags().Changed("agent") {
+				agentName, _ = cmd.Flags().GetString("agent")
+			}
+
+			var schedules []store.LoopSchedule
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				s, err := actions.LoopScheduleStatus(cmd.Context(), db, agentName)
+				if err != nil {
+					return err
+				}
+				schedules = s
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			type item struct {
+				store.LoopSchedule
+				Alive bool `json:"alive"`
+			}
+			items := make([]item, 0, len(schedules))
+			for _, s := range schedules {
+				alive := s.State != store.LoopScheduleStopped && processAlive(s.PID)
+				items = append(items, item{LoopSchedule: s, Alive: alive})
+			}
+
+			type resp struct {
+				Count     int    `json:"count"`
+				Schedules []item `json:"schedules"`
+			}
+			return output.PrintSuccess(resp{Count: len(items), Schedules: items})
+		},
+	}
+
+	return cmd
+}
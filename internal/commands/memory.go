@@ -9,10 +9,13 @@ import (
 	"github.com/dotcommander/vybe/internal/actions"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
 )
 
 // NewMemoryCmd creates the memory command with subcommands.
-// Admin subcommands (gc, delete, pin) live in memory_admin.go.
+// Admin subcommands (gc, delete, pin, history, restore, promote, demote,
+// promotions, review, conflicts, resolve) live in memory_admin.go; proposals
+// lives in memory_proposals.go.
 func NewMemoryCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "memory",
@@ -23,19 +26,50 @@ func NewMemoryCmd() *cobra.Command {
 
 	cmd.AddCommand(newMemorySetCmd())
 	cmd.AddCommand(newMemoryGCCmd())
+	cmd.AddCommand(newMemoryCompactCmd())
 	cmd.AddCommand(newMemoryGetCmd())
 	cmd.AddCommand(newMemoryListCmd())
 	cmd.AddCommand(newMemoryDeleteCmd())
 	cmd.AddCommand(newMemoryPinCmd())
+	cmd.AddCommand(newMemoryHistoryCmd())
+	cmd.AddCommand(newMemoryRestoreCmd())
+	cmd.AddCommand(newMemoryPromoteCmd())
+	cmd.AddCommand(newMemoryDemoteCmd())
+	cmd.AddCommand(newMemoryPromotionsCmd())
+	cmd.AddCommand(newMemoryReviewCmd())
+	cmd.AddCommand(newMemoryConflictsCmd())
+	cmd.AddCommand(newMemoryResolveCmd())
+	cmd.AddCommand(newMemoryProposalsCmd())
 
 	namespaceIndex(cmd)
 	return cmd
 }
 
+// memorySetResponse is the response of memory set.
+type memorySetResponse struct {
+	EventID      int64      `json:"event_id"`
+	Key          string     `json:"key"`
+	Scope        string     `json:"scope"`
+	ScopeID      string     `json:"scope_id,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Pinned       bool       `json:"pinned"`
+	Kind         string     `json:"kind"`
+	HalfLifeDays *float64   `json:"half_life_days,omitempty"`
+	SourceTaskID string     `json:"source_task_id,omitzero"`
+}
+
 func newMemorySetCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "set",
 		Short: "Set a memory value",
+		Long: `set writes a memory value. --type decision stores a decision record: a JSON
+object with decision (required), context, alternatives (array of strings), and
+consequences; anything else is rejected. Recent decisions are listed by
+'vybe decisions list' and carried into session-start context.`,
+		Example: `  vybe memory set --key build/go_version --value 1.26 --scope project --scope-id "$PWD" --request-id mem_1
+  vybe memory set --key decisions/storage --type decision --scope project --scope-id "$PWD" --request-id mem_2 \
+    --value '{"context":"Single-writer CLI, many readers","decision":"Use SQLite in WAL mode",
+              "alternatives":["Postgres","flat JSON files"],"consequences":"No network DB to run"}'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentName, requestID, err := requireMutationParams(cmd)
 			if err != nil {
@@ -63,10 +97,14 @@ func newMemorySetCmd() *cobra.Command {
 			if err != nil {
 				return cmdErr(fmt.Errorf("invalid expires-in duration: %w", err))
 			}
+			key, err = store.CanonicalMemoryKey(key)
+			if err != nil {
+				return cmdErr(err)
+			}
 
 			var eventID int64
-			if err := withDB(func(db *DB) error {
-				eid, err := actions.MemorySetIdempotent(db, agentName, requestID, key, value, valueType, scope, scopeID, expiresAt, pinned, kind, halfLifeDays, sourceTaskID)
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				eid, err := actions.MemorySetIdempotent(cmd.Context(), db, agentName, requestID, key, value, valueType, scope, scopeID, expiresAt, pinned, kind, halfLifeDays, sourceTaskID)
 				if err != nil {
 					return err
 				}
@@ -76,18 +114,7 @@ func newMemorySetCmd() *cobra.Command {
 				return err
 			}
 
-			type resp struct {
-				EventID      int64      `json:"event_id"`
-				Key          string     `json:"key"`
-				Scope        string     `json:"scope"`
-				ScopeID      string     `json:"scope_id,omitempty"`
-				ExpiresAt    *time.Time `json:"expires_at,omitempty"`
-				Pinned       bool       `json:"pinned"`
-				Kind         string     `json:"kind"`
-				HalfLifeDays *float64   `json:"half_life_days,omitempty"`
-				SourceTaskID string     `json:"source_task_id,omitzero"`
-			}
-			return output.PrintSuccess(resp{
+			return output.PrintSuccess(memorySetResponse{
 				EventID: eventID, Key: key, Scope: scope, ScopeID: scopeID,
 				ExpiresAt: expiresAt, Pinned: pinned, Kind: kind, HalfLifeDays: halfLifeDays,
 				SourceTaskID: sourceTaskID,
@@ -95,9 +122,9 @@ func newMemorySetCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringP("key", "k", "", "Memory key (required)")
+	cmd.Flags().StringP("key", "k", "", "Memory key; use / for hierarchy, e.g. build/commands/test (required)")
 	cmd.Flags().StringP("value", "v", "", "Memory value (required)")
-	cmd.Flags().StringP("type", "t", "", "Value type (string, number, boolean, json, array) - auto-detected if not specified")
+	cmd.Flags().StringP("type", "t", "", "Value type (string, number, boolean, json, array, decision) - auto-detected if not specified")
 	cmd.Flags().StringP("scope", "s", "global", "Scope (global, project, task, agent)")
 	cmd.Flags().String("scope-id", "", "Scope ID (required for non-global scopes)")
 	cmd.Flags().String("expires-in", "", "Expiration duration (e.g., 24h, 7d, 2w)")
@@ -123,8 +150,11 @@ func newMemoryGetCmd() *cobra.Command {
 			scopeID, _ := cmd.Flags().GetString("scope-id")
 
 			var mem *models.Memory
-			if err := withDB(func(db *DB) error {
-				m, err := actions.MemoryGet(db, key, scope, scopeID)
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if err := actions.CheckConfinedMemoryRead(cmd.Context(), db, resolveActorName(cmd, ""), scope, scopeID); err != nil {
+					return err
+				}
+				m, err := actions.MemoryGet(cmd.Context(), db, key, scope, scopeID)
 				if err != nil {
 					return err
 				}
@@ -147,6 +177,15 @@ func newMemoryGetCmd() *cobra.Command {
 	return cmd
 }
 
+// memoryListResponse is the response of memory list.
+type memoryListResponse struct {
+	Scope    string           `json:"scope"`
+	ScopeID  string           `json:"scope_id,omitempty"`
+	Prefix   string           `json:"prefix,omitempty"`
+	Count    int              `json:"count"`
+	Memories []*models.Memory `json:"memories"`
+}
+
 func newMemoryListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -154,10 +193,14 @@ func newMemoryListCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			scope, _ := cmd.Flags().GetString("scope")
 			scopeID, _ := cmd.Flags().GetString("scope-id")
+			prefix, _ := cmd.Flags().GetString("prefix")
 
 			var memories []*models.Memory
-			if err := withDB(func(db *DB) error {
-				m, err := actions.MemoryList(db, scope, scopeID)
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if err := actions.CheckConfinedMemoryRead(cmd.Context(), db, resolveActorName(cmd, ""), scope, scopeID); err != nil {
+					return err
+				}
+				m, err := actions.MemoryListPrefix(cmd.Context(), db, scope, scopeID, prefix)
 				if err != nil {
 					return err
 				}
@@ -167,18 +210,13 @@ func newMemoryListCmd() *cobra.Command {
 				return err
 			}
 
-			type resp struct {
-				Scope    string           `json:"scope"`
-				ScopeID  string           `json:"scope_id,omitempty"`
-				Count    int              `json:"count"`
-				Memories []*models.Memory `json:"memories"`
-			}
-			return output.PrintSuccess(resp{Scope: scope, ScopeID: scopeID, Count: len(memories), Memories: memories})
+			return output.PrintSuccess(memoryListResponse{Scope: scope, ScopeID: scopeID, Prefix: prefix, Count: len(memories), Memories: memories})
 		},
 	}
 
 	cmd.Flags().StringP("scope", "s", "global", "Scope (global, project, task, agent)")
 	cmd.Flags().String("scope-id", "", "Scope ID (required for non-global scopes)")
+	cmd.Flags().String("prefix", "", "Only keys at or under this hierarchical prefix (e.g. build/)")
 
 	return cmd
 }
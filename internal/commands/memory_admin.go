@@ -1,16 +1,27 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
 )
 
 func newMemoryGCCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "gc",
-		Short: "Delete expired memory rows",
+		Short: "Delete expired memory rows and stale idempotency records, reclaim expired task leases",
+		Long: `gc deletes expired, unpinned memory rows. It also prunes replay records of
+completed requests (--request-id) older than idempotency_ttl (default 30d, never
+below 7d; "off" keeps them), and returns tasks whose claim lease ran out (see
+task claim --lease) to pending. The checkpoint hook runs the same GC. The
+counts are reported here and in the memory_gc event metadata.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentName, requestID, err := requireMutationParams(cmd)
 			if err != nil {
@@ -19,8 +30,9 @@ func newMemoryGCCmd() *cobra.Command {
 			limit, _ := cmd.Flags().GetInt("limit")
 
 			var result *actions.MemoryGCResult
-			if err := withDB(func(db *DB) error {
-				r, err := actions.MemoryGCIdempotent(db, agentName, requestID, limit)
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.MemoryGCWithOptionsIdempotent(cmd.Context(), db, agentName, requestID,
+					store.GCOptions{Limit: limit, IdempotencyTTLDays: app.IdempotencyTTLDays()})
 				if err != nil {
 					return err
 				}
@@ -31,23 +43,103 @@ func newMemoryGCCmd() *cobra.Command {
 			}
 
 			type resp struct {
-				EventID int64 `json:"event_id"`
-				Deleted int   `json:"deleted"`
-				Limit   int   `json:"limit"`
+				EventID            int64                `json:"event_id"`
+				Deleted            int                  `json:"deleted"`
+				IdempotencyDeleted int64                `json:"idempotency_deleted"`
+				LeasesReclaimed    []store.ExpiredLease `json:"leases_reclaimed"`
+				Limit              int                  `json:"limit"`
 			}
-			return output.PrintSuccess(resp{EventID: result.EventID, Deleted: result.Deleted, Limit: limit})
+			return output.PrintSuccess(resp{EventID: result.EventID, Deleted: result.Deleted,
+				IdempotencyDeleted: result.IdempotencyDeleted, LeasesReclaimed: result.LeasesReclaimed, Limit: limit})
 		},
 	}
 
-	cmd.Flags().Int("limit", 500, "Maximum rows to delete in one run")
+	cmd.Flags().Int("limit", 500, "Maximum rows to delete per table in one run")
 	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
 	return cmd
 }
 
+func newMemoryCompactCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Remove low-relevance or idle memory, with a preview",
+		Long: `compact ranks live memory per (scope, scope_id) the way the brief does: pinned
+first, then half-life relevance. It removes entries ranked below --keep-top and
+entries idle (not accessed or updated) for longer than --max-age. Pinned and
+expired entries are never chosen; expired ones are memory gc's job.
+
+Each removal is recorded in memory history, so 'memory restore' can undo it.
+Run with --dry-run first (no request-id required); --explain adds each entry's
+kind, relevance, idle days, and rank to show why it was chosen.`,
+		Example: `  vybe memory compact --keep-top 50 --max-age 30d --dry-run --explain
+  vybe memory compact --scope project --scope-id "$PROJECT_ID" --keep-top 20 --request-id compact-1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := actions.MemoryCompactOptions{}
+			opts.Scope, _ = cmd.Flags().GetString("scope")
+			opts.ScopeID, _ = cmd.Flags().GetString("scope-id")
+			opts.KeepTop, _ = cmd.Flags().GetInt("keep-top")
+			opts.MaxAge, _ = cmd.Flags().GetString("max-age")
+			opts.Limit, _ = cmd.Flags().GetInt("limit")
+			opts.Explain, _ = cmd.Flags().GetBool("explain")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			type resp struct {
+				DryRun bool `json:"dry_run"`
+				*store.MemoryCompactStats
+			}
+
+			if dryRun {
+				var stats *store.MemoryCompactStats
+				if err := withDB(cmd.Context(), func(db *DB) error {
+					var err error
+					stats, err = actions.PreviewMemoryCompact(cmd.Context(), db, opts)
+					return err
+				}); err != nil {
+					return err
+				}
+				return output.PrintSuccess(resp{DryRun: true, MemoryCompactStats: stats})
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			var stats *store.MemoryCompactStats
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				stats, err = actions.MemoryCompactIdempotent(cmd.Context(), db, agentName, requestID, opts)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(resp{MemoryCompactStats: stats})
+		},
+	}
+
+	cmd.Flags().String("scope", "", "Restrict to one scope (global, project, task, agent); default all")
+	cmd.Flags().String("scope-id", "", "Scope ID (required for non-global scopes)")
+	cmd.Flags().Int("keep-top", 0, "Keep the N most relevant entries per scope (0 = no cap)")
+	cmd.Flags().String("max-age", "", "Remove entries idle longer than this (e.g. 720h, 30d, 2w)")
+	cmd.Flags().Int("limit", 500, "Maximum entries to remove in one run")
+	cmd.Flags().Bool("dry-run", false, "List the entries that would be removed without deleting")
+	cmd.Flags().Bool("explain", false, "Include kind, relevance, idle days, and rank for each entry")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "conditional"}
+	return cmd
+}
+
 func newMemoryDeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "delete",
-		Short: "Delete a memory entry",
+		Short: "Delete a memory entry, or every entry matching a key pattern",
+		Long: `A --key containing wildcards deletes every matching entry in the scope.
+Wildcards (*, ?, [...]) match within one key segment; a final ** matches all
+descendants: build/* removes build/test but not build/commands/test, build/**
+removes both. Patterns must start with a literal segment.
+
+Pattern deletes require --yes when stdin is not a terminal (on a terminal you are
+prompted); --backup-first snapshots the database first. Single-key deletes are
+recoverable with 'memory restore' and need neither.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			agentName, requestID, err := requireMutationParams(cmd)
@@ -58,8 +150,41 @@ func newMemoryDeleteCmd() *cobra.Command {
 			scope, _ := cmd.Flags().GetString("scope")
 			scopeID, _ := cmd.Flags().GetString("scope-id")
 
+			if store.IsMemoryKeyPattern(key) {
+				if err := confirmDestructive(cmd, "delete every memory key matching "+key); err != nil {
+					return cmdErr(err)
+				}
+				var res *store.DeleteMemoryMatchingResult
+				var backupPath string
+				if err := withDB(ctx, func(db *DB) error {
+					var err error
+					if backupPath, err = backupFirst(cmd, db, "memory-delete"); err != nil {
+						return err
+					}
+					res, err = actions.MemoryDeleteMatchingIdempotent(ctx, db, agentName, requestID, key, scope, scopeID)
+					return err
+				}); err != nil {
+					return err
+				}
+
+				type resp struct {
+					Pattern    string   `json:"pattern"`
+					Scope      string   `json:"scope"`
+					ScopeID    string   `json:"scope_id,omitempty"`
+					Deleted    int      `json:"deleted"`
+					Keys       []string `json:"keys"`
+					EventIDs   []int64  `json:"event_ids"`
+					BackupPath string   `json:"backup_path,omitempty"`
+				}
+				return output.PrintSuccess(resp{
+					Pattern: key, Scope: scope, ScopeID: scopeID,
+					Deleted: len(res.Keys), Keys: res.Keys, EventIDs: res.EventIDs,
+					BackupPath: backupPath,
+				})
+			}
+
 			var eventID int64
-			if err := withDB(func(db *DB) error {
+			if err := withDB(ctx, func(db *DB) error {
 				eid, err := actions.MemoryDeleteIdempotent(ctx, db, agentName, requestID, key, scope, scopeID)
 				if err != nil {
 					return err
@@ -80,9 +205,10 @@ func newMemoryDeleteCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringP("key", "k", "", "Memory key (required)")
+	cmd.Flags().StringP("key", "k", "", "Memory key or wildcard pattern (required)")
 	cmd.Flags().StringP("scope", "s", "global", "Scope (global, project, task, agent)")
 	cmd.Flags().String("scope-id", "", "Scope ID (required for non-global scopes)")
+	addDestructiveFlags(cmd)
 
 	_ = cmd.MarkFlagRequired("key")
 
@@ -107,7 +233,7 @@ func newMemoryPinCmd() *cobra.Command {
 			unpin, _ := cmd.Flags().GetBool("unpin")
 
 			var eventID int64
-			if err := withDB(func(db *DB) error {
+			if err := withDB(ctx, func(db *DB) error {
 				eid, err := actions.MemoryPinIdempotent(ctx, db, agentName, requestID, key, scope, scopeID, !unpin)
 				if err != nil {
 					return err
@@ -138,3 +264,381 @@ func newMemoryPinCmd() *cobra.Command {
 	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
 	return cmd
 }
+
+func newMemoryHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the value timeline of a memory key",
+		Long: `history lists every recorded change to a key, newest first: created, updated
+(with old and new value), and deleted. Each entry links the event that caused it.
+Deleted keys keep their history. Restore a past value with 'memory restore'.`,
+		Example: `  vybe memory history --key build/commands/test
+  vybe memory history --key go_version --scope project --scope-id proj_1 --limit 10`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, _ := cmd.Flags().GetString("key")
+			scope, _ := cmd.Flags().GetString("scope")
+			scopeID, _ := cmd.Flags().GetString("scope-id")
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			var entries []*models.MemoryHistoryEntry
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if err := actions.CheckConfinedMemoryRead(cmd.Context(), db, resolveActorName(cmd, ""), scope, scopeID); err != nil {
+					return err
+				}
+				var err error
+				entries, err = actions.MemoryHistory(cmd.Context(), db, key, scope, scopeID, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Key     string                       `json:"key"`
+				Scope   string                       `json:"scope"`
+				ScopeID string                       `json:"scope_id,omitempty"`
+				Count   int                          `json:"count"`
+				History []*models.MemoryHistoryEntry `json:"history"`
+			}
+			return output.PrintSuccess(resp{Key: key, Scope: scope, ScopeID: scopeID, Count: len(entries), History: entries})
+		},
+	}
+
+	cmd.Flags().StringP("key", "k", "", "Memory key (required)")
+	cmd.Flags().StringP("scope", "s", "global", "Scope (global, project, task, agent)")
+	cmd.Flags().String("scope-id", "", "Scope ID (required for non-global scopes)")
+	cmd.Flags().Int("limit", 50, "Maximum entries to return")
+
+	_ = cmd.MarkFlagRequired("key")
+	return cmd
+}
+
+func newMemoryRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a memory value from its history",
+		Long: `restore writes the value recorded by a history entry back to its key. For a
+deleted entry the deleted value is restored. The restore is an ordinary upsert, so
+it appears in events and in the key's history.`,
+		Example: `  vybe memory restore --history-id 42`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			historyID, _ := cmd.Flags().GetInt64("history-id")
+			if historyID <= 0 {
+				return cmdErr(errors.New("--history-id is required"))
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var res *store.MemoryRestoreResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				res, err = actions.MemoryRestoreIdempotent(cmd.Context(), db, agentName, requestID, historyID)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().Int64("history-id", 0, "History entry to restore, from 'memory history' (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newMemoryPromoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "promote",
+		Aliases: []string{"promote-scope"},
+		Short:   "Copy or move a memory entry into a wider scope, optionally pending review",
+		Long: `promote copies a memory entry into a wider scope (task or agent to project or
+global, project to global) so other tasks, projects, and agents can reuse it.
+The source entry is kept unless --move is given, which deletes it in the same
+transaction. Scopes are written global or <scope>:<id>.
+
+--requires-review only stages the copy: it waits in 'memory promotions' until
+'memory review --approve' writes it (and, with --move, deletes the source), so a
+project-specific fact is not shared by accident. A direct promotion to global
+needs --yes (or a y at a terminal prompt). 'memory demote' goes the other way.`,
+		Example: `  vybe memory promote --key test/flags --from task:task_123 --to "project:$PWD" --move --request-id promo_1
+  vybe memory promote --key build/go_version --from "project:$PWD" --to global --requires-review --request-id promo_2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, _ := cmd.Flags().GetString("key")
+			requiresReview, _ := cmd.Flags().GetBool("requires-review")
+			move, _ := cmd.Flags().GetBool("move")
+
+			fromScope, fromScopeID, toScope, toScopeID, err := memoryTransferScopes(cmd)
+			if err != nil {
+				return cmdErr(err)
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			if toScope == "global" && !requiresReview {
+				if err := confirmDestructive(cmd, "share "+key+" with every project"); err != nil {
+					return cmdErr(fmt.Errorf("%w (or stage it with --requires-review)", err))
+				}
+			}
+
+			var res *store.MemoryPromoteResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				res, err = actions.MemoryPromoteIdempotent(cmd.Context(), db, agentName, requestID, key, fromScope, fromScopeID, toScope, toScopeID,
+					store.MemoryTransferOptions{Move: move, RequiresReview: requiresReview})
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().StringP("key", "k", "", "Memory key (required)")
+	cmd.Flags().String("from", "", "Source scope: <project|task|agent>:<id> (required)")
+	cmd.Flags().String("to", "global", "Target scope: global or project:<id>")
+	cmd.Flags().Bool("move", false, "Delete the source entry once the copy is written")
+	cmd.Flags().Bool("requires-review", false, "Stage the promotion until it is approved with 'memory review'")
+	cmd.Flags().Bool("yes", false, "Confirm a direct promotion to global (required when stdin is not a terminal)")
+
+	_ = cmd.MarkFlagRequired("key")
+	_ = cmd.MarkFlagRequired("from")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newMemoryDemoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "demote",
+		Short: "Copy or move a memory entry into a narrower scope",
+		Long: `demote copies a memory entry into a narrower scope (global to project, project
+to task or agent) for a fact that turned out to hold only there. Pass --move to
+delete the wider entry in the same transaction so other projects stop seeing it;
+moving out of global needs --yes (or a y at a terminal prompt).`,
+		Example: `  vybe memory demote --key db/host --from global --to "project:$PWD" --move --yes --request-id demote_1`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, _ := cmd.Flags().GetString("key")
+			move, _ := cmd.Flags().GetBool("move")
+
+			fromScope, fromScopeID, toScope, toScopeID, err := memoryTransferScopes(cmd)
+			if err != nil {
+				return cmdErr(err)
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			if fromScope == "global" && move {
+				if err := confirmDestructive(cmd, "remove "+key+" from every other project"); err != nil {
+					return cmdErr(err)
+				}
+			}
+
+			var res *store.MemoryPromoteResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				res, err = actions.MemoryDemoteIdempotent(cmd.Context(), db, agentName, requestID, key, fromScope, fromScopeID, toScope, toScopeID, move)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().StringP("key", "k", "", "Memory key (required)")
+	cmd.Flags().String("from", "", "Source scope: global or project:<id> (required)")
+	cmd.Flags().String("to", "", "Target scope: <project|task|agent>:<id> (required)")
+	cmd.Flags().Bool("move", false, "Delete the source entry once the copy is written")
+	cmd.Flags().Bool("yes", false, "Confirm moving an entry out of global (required when stdin is not a terminal)")
+
+	_ = cmd.MarkFlagRequired("key")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+// memoryTransferScopes parses --from and --to for promote and demote.
+func memoryTransferScopes(cmd *cobra.Command) (fromScope, fromScopeID, toScope, toScopeID string, err error) {
+	fromRaw, _ := cmd.Flags().GetString("from")
+	toRaw, _ := cmd.Flags().GetString("to")
+	if fromScope, fromScopeID, err = actions.ParseMemoryScopeRef(fromRaw); err != nil {
+		return "", "", "", "", fmt.Errorf("--from: %w", err)
+	}
+	if toScope, toScopeID, err = actions.ParseMemoryScopeRef(toRaw); err != nil {
+		return "", "", "", "", fmt.Errorf("--to: %w", err)
+	}
+	return fromScope, fromScopeID, toScope, toScopeID, nil
+}
+
+func newMemoryPromotionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "promotions",
+		Short: "List memory promotions staged for review",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, _ := cmd.Flags().GetString("status")
+			limit, _ := cmd.Flags().GetInt("limit")
+			if status == "all" {
+				status = ""
+			}
+
+			var promotions []*models.MemoryPromotion
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				promotions, err = actions.MemoryPromotions(cmd.Context(), db, status, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Count      int                       `json:"count"`
+				Promotions []*models.MemoryPromotion `json:"promotions"`
+			}
+			return output.PrintSuccess(resp{Count: len(promotions), Promotions: promotions})
+		},
+	}
+
+	cmd.Flags().String("status", models.MemoryPromotionPending, "Filter: pending, approved, rejected, or all")
+	cmd.Flags().Int("limit", 50, "Maximum promotions to return")
+	return cmd
+}
+
+func newMemoryReviewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Approve or reject a staged memory promotion",
+		Long: `review settles a promotion staged by 'memory promote --requires-review'.
+--approve writes the staged value into the target scope; --reject discards it.
+Either way the decision, reviewer, and --note are kept on the promotion.`,
+		Example: `  vybe memory review --id 3 --approve --request-id review_3
+  vybe memory review --id 4 --reject --note "only true for this repo" --request-id review_4`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, _ := cmd.Flags().GetInt64("id")
+			approve, _ := cmd.Flags().GetBool("approve")
+			reject, _ := cmd.Flags().GetBool("reject")
+			note, _ := cmd.Flags().GetString("note")
+			if id <= 0 {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if approve == reject {
+				return cmdErr(errors.New("pass exactly one of --approve or --reject"))
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var res *store.MemoryPromoteResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				res, err = actions.MemoryPromotionReviewIdempotent(cmd.Context(), db, agentName, requestID, id, approve, note)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().Int64("id", 0, "Promotion ID from 'memory promotions' (required)")
+	cmd.Flags().Bool("approve", false, "Write the staged value into the target scope")
+	cmd.Flags().Bool("reject", false, "Discard the promotion")
+	cmd.Flags().String("note", "", "Reason recorded with the decision")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newMemoryConflictsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conflicts",
+		Short: "List keys two agents wrote different values to",
+		Long: `conflicts lists memory keys where one agent overwrote another agent's value.
+The overwrite still lands (value b is what memory get returns), but the
+displaced value a is kept on the conflict until 'memory resolve' settles it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, _ := cmd.Flags().GetString("status")
+			limit, _ := cmd.Flags().GetInt("limit")
+			if status == "all" {
+				status = ""
+			}
+
+			var conflicts []*models.MemoryConflict
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				conflicts, err = actions.MemoryConflicts(cmd.Context(), db, status, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Count     int                      `json:"count"`
+				Conflicts []*models.MemoryConflict `json:"conflicts"`
+			}
+			return output.PrintSuccess(resp{Count: len(conflicts), Conflicts: conflicts})
+		},
+	}
+
+	cmd.Flags().String("status", models.MemoryConflictOpen, "Filter: open, resolved, superseded, or all")
+	cmd.Flags().Int("limit", 50, "Maximum conflicts to return")
+	return cmd
+}
+
+func newMemoryResolveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resolve [merged-value]",
+		Short: "Settle a memory conflict by keeping a, b, or a merged value",
+		Long: `resolve settles a conflict listed by 'memory conflicts'. --keep a writes the
+displaced value back, --keep b keeps the current value, and --keep merge writes
+the merged value (positional or --value). Other open conflicts on the same key
+are marked superseded.`,
+		Example: `  vybe memory resolve --id 3 --keep a --request-id resolve_3
+  vybe memory resolve --id 4 --keep merge "go test ./... -race" --request-id resolve_4`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, _ := cmd.Flags().GetInt64("id")
+			keep, _ := cmd.Flags().GetString("keep")
+			value, _ := cmd.Flags().GetString("value")
+			if id <= 0 {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if len(args) == 1 {
+				if value != "" {
+					return cmdErr(errors.New("pass the merged value positionally or with --value, not both"))
+				}
+				value = args[0]
+			}
+			if value != "" && keep != models.MemoryConflictMerge {
+				return cmdErr(errors.New("a merged value only applies with --keep merge"))
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var res *store.MemoryConflictResolution
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				res, err = actions.MemoryResolveIdempotent(cmd.Context(), db, agentName, requestID, id, keep, value)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().Int64("id", 0, "Conflict ID from 'memory conflicts' (required)")
+	cmd.Flags().String("keep", "", "Resolution: a, b, or merge (required)")
+	cmd.Flags().String("value", "", "Merged value for --keep merge")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
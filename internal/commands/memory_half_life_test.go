@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -26,14 +27,14 @@ func TestMemorySetCmd_HalfLifeDaysFlag(t *testing.T) {
 	defer func() { _ = db.Close() }()
 
 	halfLife := 7.0
-	_, err = store.UpsertMemoryWithEventIdempotent(
+	_, err = store.UpsertMemoryWithEventIdempotent(context.Background(),
 		db, "agent-hl", "req-hl-flag-1",
 		"lesson-key", "some insight", "string",
 		"global", "", nil, false, "lesson", &halfLife, "",
 	)
 	require.NoError(t, err)
 
-	mems, err := store.ListMemory(db, "global", "")
+	mems, err := store.ListMemory(context.Background(), db, "global", "")
 	require.NoError(t, err)
 	require.Len(t, mems, 1)
 
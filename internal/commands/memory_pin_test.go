@@ -26,13 +26,13 @@ func TestMemorySetPinFlag(t *testing.T) {
 	require.NoError(t, err)
 	defer func() { _ = db.Close() }()
 
-	_, err = store.UpsertMemoryWithEventIdempotent(
+	_, err = store.UpsertMemoryWithEventIdempotent(context.Background(),
 		db, "agent-pin-flag", "req-pin-set-flag-1",
 		"arch", "monolith", "string", "global", "", nil, true, "", nil, "",
 	)
 	require.NoError(t, err)
 
-	mem, err := store.GetMemory(db, "arch", "global", "")
+	mem, err := store.GetMemory(context.Background(), db, "arch", "global", "")
 	require.NoError(t, err)
 	require.NotNil(t, mem)
 	assert.True(t, mem.Pinned, "UpsertMemoryWithEventIdempotent with pinned=true must set Pinned field")
@@ -62,14 +62,14 @@ func TestMemoryPinSubcommand(t *testing.T) {
 	require.NoError(t, dbErr)
 	defer func() { _ = db.Close() }()
 
-	require.NoError(t, store.SetMemory(db, "toggle-key", "v", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, store.SetMemory(context.Background(), db, "toggle-key", "v", "string", "global", "", nil, false, "", nil))
 
 	// Pin
 	eid1, err := store.PinMemoryIdempotent(context.Background(), db, "agent", "req-toggle-pin", "toggle-key", "global", "", true)
 	require.NoError(t, err)
 	require.Greater(t, eid1, int64(0))
 
-	mem1, err := store.GetMemory(db, "toggle-key", "global", "")
+	mem1, err := store.GetMemory(context.Background(), db, "toggle-key", "global", "")
 	require.NoError(t, err)
 	require.NotNil(t, mem1)
 	assert.True(t, mem1.Pinned, "after pin: Pinned must be true")
@@ -80,7 +80,7 @@ func TestMemoryPinSubcommand(t *testing.T) {
 	require.Greater(t, eid2, int64(0))
 	assert.NotEqual(t, eid1, eid2, "pin and unpin must produce distinct events")
 
-	mem2, err := store.GetMemory(db, "toggle-key", "global", "")
+	mem2, err := store.GetMemory(context.Background(), db, "toggle-key", "global", "")
 	require.NoError(t, err)
 	require.NotNil(t, mem2)
 	assert.False(t, mem2.Pinned, "after unpin: Pinned must be false")
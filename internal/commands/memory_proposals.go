@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newMemoryProposalsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proposals",
+		Short: "Review memory facts extracted from progress and tool events",
+		Long: `Proposals are memory facts the rule-based extractor found in progress and
+tool_success events: "uses X [for Y]" (stack/<y>), "decided to Y" (a decision
+record under decisions/), "<name> config is Z" (config/<name>), and "a.b = c"
+(config/a.b). They are project-scoped and nothing reaches memory until a
+proposal is accepted. The checkpoint hook runs the extractor when extract_facts
+is set in config; 'proposals extract' runs it on demand. An agent confined to
+a project only sees that project's proposals.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newMemoryProposalsListCmd())
+	cmd.AddCommand(newMemoryProposalsExtractCmd())
+	cmd.AddCommand(newMemoryProposalsAcceptCmd())
+	cmd.AddCommand(newMemoryProposalsRejectCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+// memoryExtractProject returns the project extract scans: --project-id or the
+// project --project-dir resolves to, else the project the agent is confined
+// to, else the current directory's project. A confined agent naming another
+// project gets a *store.ConfinementError.
+func memoryExtractProject(cmd *cobra.Command, db *DB) (string, error) {
+	projectID, err := confinedProjectFilter(cmd, db, projectScopeFlag(cmd))
+	if err != nil || projectID != "" {
+		return projectID, err
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		return resolveProjectID(cwd), nil
+	}
+	return "", nil
+}
+
+func newMemoryProposalsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List memory proposals, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, _ := cmd.Flags().GetString("status")
+			limit, _ := cmd.Flags().GetInt("limit")
+			if status == "all" {
+				status = ""
+			}
+			projectID := projectScopeFlag(cmd)
+
+			var proposals []*models.MemoryProposal
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				if projectID, err = confinedProjectFilter(cmd, db, projectID); err != nil {
+					return err
+				}
+				proposals, err = actions.MemoryProposals(cmd.Context(), db, status, projectID, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Count     int                      `json:"count"`
+				Proposals []*models.MemoryProposal `json:"proposals"`
+			}
+			return output.PrintSuccess(resp{Count: len(proposals), Proposals: proposals})
+		},
+	}
+
+	cmd.Flags().String("status", models.MemoryProposalPending, "Filter: pending, accepted, rejected, or all")
+	addProjectScopeFlags(cmd, "Only this project's proposals (default: every project)")
+	cmd.Flags().Int("limit", 50, "Maximum proposals to return")
+	return cmd
+}
+
+func newMemoryProposalsExtractCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "extract",
+		Short: "Scan a project's recent events and stage the facts they state",
+		Long: `extract runs the fact extractor once over the project's most recent progress
+and tool_success events (--limit of each). Facts a source event already
+proposed, or that memory already holds, are skipped. An agent confined to a
+project extracts only from that project.`,
+		Example: `  vybe memory proposals extract --project-dir "$PWD" --request-id extract_1`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var res *store.MemoryProposalStageResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				projectID, err := memoryExtractProject(cmd, db)
+				if err != nil {
+					return err
+				}
+				if projectID == "" {
+					return errors.New("--project-id or --project-dir is required")
+				}
+				res, err = actions.MemoryExtractIdempotent(cmd.Context(), db, agentName, requestID, projectID, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	addProjectScopeFlags(cmd, "Project ID to scan (default: the current directory's project)")
+	cmd.Flags().Int("limit", actions.DefaultMemoryExtractLimit, "Recent events of each kind to scan")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newMemoryProposalsAcceptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "accept",
+		Short: "Write a memory proposal into memory",
+		Long: `accept writes the proposal into project memory through the normal memory set
+path, with the source event and task recorded as provenance. --key and --value
+replace the extracted ones.`,
+		Example: `  vybe memory proposals accept --id 3 --request-id accept_3
+  vybe memory proposals accept --id 5 --key stack/database --value "Postgres 16" --request-id accept_5`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, _ := cmd.Flags().GetString("key")
+			value, _ := cmd.Flags().GetString("value")
+			return runMemoryProposalReview(cmd, store.MemoryProposalReview{Accept: true, Key: key, Value: value})
+		},
+	}
+
+	cmd.Flags().Int64("id", 0, "Proposal ID from 'memory proposals list' (required)")
+	cmd.Flags().String("key", "", "Memory key to write instead of the proposed one")
+	cmd.Flags().String("value", "", "Value to write instead of the proposed one")
+	cmd.Flags().String("note", "", "Reason recorded with the decision")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newMemoryProposalsRejectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "reject",
+		Short:   "Drop a memory proposal",
+		Example: `  vybe memory proposals reject --id 4 --note "one-off workaround" --request-id reject_4`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMemoryProposalReview(cmd, store.MemoryProposalReview{})
+		},
+	}
+
+	cmd.Flags().Int64("id", 0, "Proposal ID from 'memory proposals list' (required)")
+	cmd.Flags().String("note", "", "Reason recorded with the decision")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func runMemoryProposalReview(cmd *cobra.Command, review store.MemoryProposalReview) error {
+	id, _ := cmd.Flags().GetInt64("id")
+	review.Note, _ = cmd.Flags().GetString("note")
+	if id <= 0 {
+		return cmdErr(errors.New("--id is required"))
+	}
+	agentName, requestID, err := requireMutationParams(cmd)
+	if err != nil {
+		return err
+	}
+
+	var res *store.MemoryProposalReviewResult
+	if err := withDB(cmd.Context(), func(db *DB) error {
+		var err error
+		res, err = actions.MemoryProposalReviewIdempotent(cmd.Context(), db, agentName, requestID, id, review)
+		return err
+	}); err != nil {
+		return err
+	}
+	return output.PrintSuccess(res)
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// NewMsgCmd creates the msg command group for agent-to-agent messaging.
+func NewMsgCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "msg",
+		Short: "Send and read agent-to-agent messages",
+		Long: `Messages are direct hand-off notes between agents, kept in a per-agent inbox
+with read/unread state. Use them instead of global memory keys for coordination.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newMsgSendCmd())
+	cmd.AddCommand(newMsgInboxCmd())
+	cmd.AddCommand(newMsgReadCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newMsgSendCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "send",
+		Short: "Send a message to another agent's inbox",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			to, _ := cmd.Flags().GetString("to")
+			subject, _ := cmd.Flags().GetString("subject")
+			body, _ := cmd.Flags().GetString("body")
+			taskID, _ := cmd.Flags().GetString("task-id")
+			if to == "" {
+				return cmdErr(errors.New("--to is required"))
+			}
+			if subject == "" {
+				return cmdErr(errors.New("--subject is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			type resp struct {
+				Message *models.Message `json:"message"`
+				EventID int64           `json:"event_id"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				m, eid, err := actions.MessageSendIdempotent(cmd.Context(), db, agentName, requestID, to, subject, body, taskID)
+				if err != nil {
+					return err
+				}
+				r = resp{Message: m, EventID: eid}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("to", "", "Recipient agent name (required)")
+	cmd.Flags().String("subject", "", "Message subject (required)")
+	cmd.Flags().String("body", "", "Message body")
+	cmd.Flags().String("task-id", "", "Optional task the message refers to")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newMsgInboxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inbox",
+		Short: "List messages addressed to the current agent (unread by default)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			agentName, err := requireActorName(cmd, "")
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				Agent    string           `json:"agent"`
+				Unread   int              `json:"unread"`
+				Count    int              `json:"count"`
+				Messages []models.Message `json:"messages"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				msgs, unread, err := actions.MessageInbox(cmd.Context(), db, agentName, all, limit)
+				if err != nil {
+					return err
+				}
+				r = resp{Agent: agentName, Unread: unread, Count: len(msgs), Messages: msgs}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "Include messages already read")
+	cmd.Flags().Int("limit", 50, "Max messages to return")
+	return cmd
+}
+
+func newMsgReadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "read",
+		Short: "Mark inbox messages read (--id repeatable, or --all)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, _ := cmd.Flags().GetStringArray("id")
+			all, _ := cmd.Flags().GetBool("all")
+			if len(ids) == 0 && !all {
+				return cmdErr(errors.New("--id or --all is required"))
+			}
+			if len(ids) > 0 && all {
+				return cmdErr(errors.New("--id and --all are mutually exclusive"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			type resp struct {
+				Marked int64 `json:"marked"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				n, err := actions.MessageMarkReadIdempotent(cmd.Context(), db, agentName, requestID, ids)
+				if err != nil {
+					return err
+				}
+				r.Marked = n
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().StringArray("id", nil, "Message ID to mark read (repeatable)")
+	cmd.Flags().Bool("all", false, "Mark every unread message read")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewPlanCmd creates the plan command.
+func NewPlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Pick the pending tasks that fit a capacity budget, in priority order",
+		Long: `plan walks pending tasks by priority (oldest first within a priority) and
+selects each one whose size fits the remaining capacity. Sizes are worth
+xs=1, s=2, m=3, l=5, xl=8 points. A task that does not fit is skipped and
+smaller, lower-priority tasks may fill the rest.
+
+Tasks waiting on an unfinished dependency are only selected when that
+dependency is selected too. Unsized tasks are skipped unless --default-size
+is given. Use the selection to scope what to hand the loop for a run.`,
+		Example: `  vybe plan --capacity 10
+  vybe plan --capacity 20 --project-dir "$PWD" --default-size m`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			capacity, _ := cmd.Flags().GetInt("capacity")
+			projectID, _ := cmd.Flags().GetString("project-dir")
+			defaultRaw, _ := cmd.Flags().GetString("default-size")
+			if capacity <= 0 {
+				return cmdErr(errors.New("--capacity must be > 0"))
+			}
+			if projectID != "" {
+				if abs, err := filepath.Abs(projectID); err == nil {
+					projectID = resolveProjectID(abs)
+				}
+			}
+			defaultSize, err := actions.ParseTaskSize(defaultRaw)
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			var plan *store.CapacityPlan
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				plan, err = actions.PlanCapacity(cmd.Context(), db, projectID, capacity, defaultSize)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(plan)
+		},
+	}
+
+	cmd.Flags().Int("capacity", 0, "Capacity in points (required)")
+	cmd.Flags().String("project-dir", "", "Only plan tasks in this project")
+	cmd.Flags().String("default-size", "", "Size assumed for unsized tasks (default: skip them)")
+	return cmd
+}
@@ -0,0 +1,356 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewProjectCmd creates the project command group.
+func NewProjectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "project",
+		Short: "List, archive, and delete projects",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newProjectListCmd())
+	cmd.AddCommand(newProjectDetectCmd())
+	cmd.AddCommand(newProjectAliasCmd())
+	cmd.AddCommand(newProjectTrendsCmd())
+	cmd.AddCommand(newProjectArchiveCmd(true))
+	cmd.AddCommand(newProjectArchiveCmd(false))
+	cmd.AddCommand(newProjectDeleteCmd())
+	cmd.AddCommand(newProjectPurgeCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+// projectScopeFlag returns --project-id, or the project --project-dir resolves
+// to (see addProjectScopeFlags).
+func projectScopeFlag(cmd *cobra.Command) string {
+	projectID, _ := cmd.Flags().GetString("project-id")
+	projectDir, _ := cmd.Flags().GetString("project-dir")
+	if projectDir != "" && projectID == "" {
+		if abs, err := filepath.Abs(projectDir); err == nil {
+			projectID = resolveProjectID(abs)
+		}
+	}
+	return projectID
+}
+
+// addProjectScopeFlags registers --project-id (described by usage) and
+// --project-dir for commands scoped to one project.
+func addProjectScopeFlags(cmd *cobra.Command, usage string) {
+	cmd.Flags().String("project-id", "", usage)
+	cmd.Flags().String("project-dir", "", "Project directory path (resolves to project_id)")
+}
+
+// projectIDFlag reads --id, cleaning directory-path IDs the way hooks store them.
+func projectIDFlag(cmd *cobra.Command) (string, error) {
+	projectID, _ := cmd.Flags().GetString("id")
+	if projectID == "" {
+		return "", errors.New("--id is required")
+	}
+	if filepath.IsAbs(projectID) {
+		projectID = filepath.Clean(projectID)
+	}
+	return projectID, nil
+}
+
+func newProjectListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List projects (archived projects are hidden unless --all)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+
+			var projects []*models.Project
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				p, err := actions.ProjectList(cmd.Context(), db, all)
+				projects = p
+				return err
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Count    int               `json:"count"`
+				Projects []*models.Project `json:"projects"`
+			}
+			return output.PrintSuccess(resp{Count: len(projects), Projects: projects})
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "Include archived projects")
+	return cmd
+}
+
+func newProjectDetectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "detect [dir]",
+		Short: "Show the project ID and .vybe.toml settings hooks resolve for a directory",
+		Long: `Detect walks up from dir (default: the working directory) to the git root
+looking for .vybe.toml, then reports the project ID hooks and resume would use
+and where it came from: "alias" for a project alias, "config" for a [project]
+name, "git" for the remote under project_identity: git, or "path" for the
+directory itself.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := ""
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			if dir == "" {
+				wd, err := os.Getwd()
+				if err != nil {
+					return cmdErr(err)
+				}
+				dir = wd
+			}
+			dir, err := filepath.Abs(dir)
+			if err != nil {
+				return cmdErr(err)
+			}
+			cfg, err := app.FindProjectConfig(dir)
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				Dir             string            `json:"dir"`
+				ProjectID       string            `json:"project_id"`
+				Source          string            `json:"source"`
+				AliasPath       string            `json:"alias_path,omitempty"`
+				ConfigPath      string            `json:"config_path,omitempty"`
+				DefaultPriority *int              `json:"default_priority,omitempty"`
+				FocusPolicy     app.FocusPolicy   `json:"focus_policy"`
+				Retention       map[string]string `json:"retention,omitempty"`
+			}
+			r := resp{Dir: dir, ProjectID: resolveProjectID(dir), FocusPolicy: app.FocusPolicyFor(dir)}
+			alias := app.ProjectAliasFor(dir)
+			switch {
+			case alias != nil:
+				r.Source, r.AliasPath = "alias", alias.Path
+			case cfg.ProjectName() != "":
+				r.Source = "config"
+			case r.ProjectID != dir:
+				r.Source = "git"
+			default:
+				r.Source = "path"
+			}
+			if cfg != nil {
+				r.ConfigPath = cfg.Path
+				if p, ok := cfg.DefaultPriority(); ok {
+					r.DefaultPriority = &p
+				}
+				if rules := cfg.Retention(); len(rules) > 0 {
+					r.Retention = rules
+				}
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+}
+
+func newProjectTrendsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trends",
+		Short: "Show daily stats snapshots (backlog, events, memory, DB size) for a project",
+		Long: `Snapshots are recorded once per day per project by the checkpoint hook
+(the last checkpoint of the day wins). delta compares the first and last snapshot
+in the window; a positive backlog delta means work is piling up.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			days, _ := cmd.Flags().GetInt("days")
+			projectID, err := projectIDFlag(cmd)
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			var trends *store.ProjectTrends
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				t, err := actions.ProjectTrends(cmd.Context(), db, projectID, days)
+				if err != nil {
+					return err
+				}
+				trends = t
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(trends)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Project ID (required; project directory path for hook-created projects)")
+	cmd.Flags().Int("days", 30, "Window size in days")
+	return cmd
+}
+
+func newProjectDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a project and its project-scoped memory (requires --yes)",
+		Long: `delete removes a project, its project-scoped memory and stats history, and
+detaches its tasks, events, and artifacts. Non-interactive callers must pass --yes;
+on a terminal you are prompted instead. --backup-first snapshots the database before
+deleting.`,
+		Example: `  vybe project delete --id "$PWD" --yes --backup-first`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := projectIDFlag(cmd)
+			if err != nil {
+				return cmdErr(err)
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			if err := confirmDestructive(cmd, "delete project "+projectID); err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				ProjectID  string `json:"project_id"`
+				EventID    int64  `json:"event_id"`
+				BackupPath string `json:"backup_path,omitempty"`
+			}
+			r := resp{ProjectID: projectID}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if r.BackupPath, err = backupFirst(cmd, db, "project-delete"); err != nil {
+					return err
+				}
+				r.EventID, err = actions.ProjectDeleteIdempotent(cmd.Context(), db, agentName, requestID, projectID)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Project ID (required)")
+	addDestructiveFlags(cmd)
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
+	return cmd
+}
+
+func newProjectArchiveCmd(archive bool) *cobra.Command {
+	use, short := "unarchive", "Return an archived project to lists, resume, and task claims"
+	if archive {
+		use, short = "archive", "Hide a project from lists, resume focus selection, and task claims"
+	}
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long: `An archived project keeps all of its data but no longer surfaces work: project
+list hides it (unless --all), resume and task next never pick its tasks, and task
+begin refuses them. Archiving also clears agent and session focus on the project
+and its tasks. unarchive reverses it.`,
+		Example: "  vybe project " + use + ` --id "$PWD"`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := projectIDFlag(cmd)
+			if err != nil {
+				return cmdErr(err)
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			type resp struct {
+				ProjectID string `json:"project_id"`
+				Archived  bool   `json:"archived"`
+				EventID   int64  `json:"event_id"`
+			}
+			r := resp{ProjectID: projectID, Archived: archive}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r.EventID, err = actions.ProjectArchiveIdempotent(cmd.Context(), db, agentName, requestID, projectID, archive)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Project ID (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newProjectPurgeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete a project with its tasks, events, memory, and artifacts (dry run without --confirm)",
+		Long: `purge removes the project and everything scoped to it: tasks (with their
+dependencies, criteria, and metadata), events, project- and task-scoped memory and
+its history, artifacts, sessions, and stats history. Unlike delete, nothing is
+detached and kept.
+
+Without --confirm, purge only reports how many rows each table would lose. With
+--confirm, rows are deleted in batches, each in its own transaction, so a large
+project never blocks other writers for long; an interrupted purge can be rerun.
+--backup-first snapshots the database before deleting.`,
+		Example: `  vybe project purge --id "$PWD"
+  vybe project purge --id "$PWD" --confirm --backup-first --request-id purge-1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := projectIDFlag(cmd)
+			if err != nil {
+				return cmdErr(err)
+			}
+			confirm, _ := cmd.Flags().GetBool("confirm")
+
+			if !confirm {
+				var report *store.ProjectPurgeReport
+				if err := withDB(cmd.Context(), func(db *DB) error {
+					report, err = actions.ProjectPurgePreview(cmd.Context(), db, projectID)
+					return err
+				}); err != nil {
+					return err
+				}
+				return output.PrintSuccess(report)
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			type resp struct {
+				*store.ProjectPurgeReport
+				BackupPath string `json:"backup_path,omitempty"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if r.BackupPath, err = backupFirst(cmd, db, "project-purge"); err != nil {
+					return err
+				}
+				r.ProjectPurgeReport, err = actions.ProjectPurgeIdempotent(cmd.Context(), db, agentName, requestID, projectID)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Project ID (required)")
+	cmd.Flags().Bool("confirm", false, "Delete for real (default: dry-run report)")
+	addBackupFirstFlag(cmd)
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
+	return cmd
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+func newProjectAliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Map moved or re-cloned directories onto an existing project",
+		Long: `Aliases map a directory root to a project ID (~/.config/vybe/project_aliases.json).
+Hooks, resume, and every --project-dir run inside an aliased root resolve to the
+aliased project instead of the directory path, so tasks, memory, and history
+follow the project when a repo moves or is checked out at another path. The
+deepest matching root wins, and an alias beats a [project] name in .vybe.toml.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newProjectAliasAddCmd())
+	cmd.AddCommand(newProjectAliasListCmd())
+	cmd.AddCommand(newProjectAliasRemoveCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newProjectAliasAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Resolve a directory (and below) to an existing project",
+		Example: `  # The repo moved from /home/me/src/api; keep its history
+  vybe project alias add --path /work/api --id /home/me/src/api`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _ := cmd.Flags().GetString("path")
+			projectID, err := projectIDFlag(cmd)
+			if err != nil {
+				return cmdErr(err)
+			}
+			if path == "" {
+				return cmdErr(errors.New("--path is required"))
+			}
+			// An alias to a mistyped ID would silently start a new, empty project.
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				_, err := actions.ProjectGet(cmd.Context(), db, projectID)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			alias, err := app.AddProjectAlias(path, projectID)
+			if err != nil {
+				return cmdErr(err)
+			}
+			return output.PrintSuccess(alias)
+		},
+	}
+	cmd.Flags().String("path", "", "Directory to alias (required)")
+	cmd.Flags().String("id", "", "Existing project ID the directory resolves to (required; e.g. the old path, see vybe project list)")
+	cmd.Annotations = map[string]string{"mutates": "true", "admin": "true"}
+	return cmd
+}
+
+func newProjectAliasListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List project aliases and which one the current directory resolves to",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reg, err := app.LoadProjectAliases()
+			if err != nil {
+				return cmdErr(err)
+			}
+			type resp struct {
+				Aliases []app.ProjectAlias `json:"aliases"`
+				Current *app.ProjectAlias  `json:"current,omitempty"`
+			}
+			r := resp{Aliases: reg.Aliases}
+			if cwd, err := os.Getwd(); err == nil {
+				r.Current = reg.Match(cwd)
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+}
+
+func newProjectAliasRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove the alias registered for a directory",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _ := cmd.Flags().GetString("path")
+			if path == "" {
+				return cmdErr(errors.New("--path is required"))
+			}
+			removed, err := app.RemoveProjectAlias(path)
+			if err != nil {
+				return cmdErr(err)
+			}
+			type resp struct {
+				Removed *app.ProjectAlias `json:"removed"`
+			}
+			return output.PrintSuccess(resp{Removed: removed})
+		},
+	}
+	cmd.Flags().String("path", "", "Aliased directory (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "admin": "true"}
+	return cmd
+}
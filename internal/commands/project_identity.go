@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+// gitRemoteTimeout bounds the git call made per hook when project_identity is git.
+const gitRemoteTimeout = 2 * time.Second
+
+// resolveProjectID maps a working directory to its project ID. For an absolute
+// dir, a project alias covering it wins (see vybe project alias), then a
+// [project] name in the .vybe.toml governing it, so history follows the
+// project rather than the path. Otherwise, in the default path mode the
+// directory itself is the ID. With project_identity: git the ID is the normalized remote
+// URL of the enclosing repository, so every clone of a repo shares one
+// project; directories outside a repo or without a remote keep their path.
+func resolveProjectID(dir string) string {
+	if filepath.IsAbs(dir) {
+		if alias := app.ProjectAliasFor(dir); alias != nil {
+			return alias.ProjectID
+		}
+	}
+	if id := configuredProjectID(dir); id != "" {
+		return id
+	}
+	if dir == "" || app.ProjectIdentityMode() != app.ProjectIdentityGit {
+		return dir
+	}
+	if id := normalizeGitRemote(gitRemoteURL(dir)); id != "" {
+		return id
+	}
+	return dir
+}
+
+// configuredProjectID returns the [project] name from the .vybe.toml governing
+// dir, or "" when dir is not an absolute path or no file names a project.
+func configuredProjectID(dir string) string {
+	if !filepath.IsAbs(dir) {
+		return ""
+	}
+	cfg, err := app.FindProjectConfig(dir)
+	if err != nil {
+		slog.Default().Warn("project config unreadable; skipping configured project identity", "error", err)
+		return ""
+	}
+	return cfg.ProjectName()
+}
+
+// projectConfigFor returns the .vybe.toml governing projectID's directory: the
+// working directory when projectID is empty or names the working directory's
+// project, projectID itself when it is a path (path identity), or else the
+// first alias registered for it. nil when no directory is known for projectID
+// or none holds a readable file.
+func projectConfigFor(projectID string) *app.ProjectConfig {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	dir := ""
+	switch {
+	case projectID == "" || resolveProjectID(cwd) == projectID:
+		dir = cwd
+	case filepath.IsAbs(projectID):
+		dir = projectID
+	default:
+		if reg, err := app.LoadProjectAliases(); err == nil {
+			for _, a := range reg.Aliases {
+				if a.ProjectID == projectID {
+					dir = a.Path
+					break
+				}
+			}
+		}
+	}
+	if dir == "" {
+		return nil
+	}
+	cfg, err := app.FindProjectConfig(dir)
+	if err != nil {
+		slog.Default().Warn("project config unreadable; skipping project defaults", "project_id", projectID, "error", err)
+		return nil
+	}
+	return cfg
+}
+
+// gitRemoteURL returns the origin remote URL of the repository containing dir,
+// or the first configured remote when there is no origin.
+func gitRemoteURL(dir string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), gitRemoteTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "config", "--get-regexp", `^remote\..*\.url$`).Output() //nolint:gosec // G204: git is a known system tool
+	if err != nil {
+		return ""
+	}
+	first := ""
+	for line := range strings.SplitSeq(strings.TrimSpace(string(out)), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		if key == "remote.origin.url" {
+			return value
+		}
+		if first == "" {
+			first = value
+		}
+	}
+	return first
+}
+
+// gitTopLevel returns the root of the git repository containing dir, or "".
+func gitTopLevel(dir string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), gitRemoteTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--show-toplevel").Output() //nolint:gosec // G204: git is a known system tool
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// normalizeGitRemote reduces the spellings of one remote to a single ID:
+// https://github.com/Owner/Repo.git, git@github.com:Owner/Repo, and
+// ssh://git@github.com:22/Owner/Repo all become github.com/Owner/Repo.
+// Credentials, ports, and the .git suffix are dropped and the host is
+// lowercased; the path keeps its case. Local-path remotes return "".
+func normalizeGitRemote(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	var host, repoPath string
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "file" {
+			return ""
+		}
+		host, repoPath = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		h, p, ok := strings.Cut(raw, ":")
+		if !ok || strings.ContainsAny(h, `/\`) {
+			return ""
+		}
+		if _, afterAt, found := strings.Cut(h, "@"); found {
+			h = afterAt
+		}
+		host, repoPath = h, p
+	}
+
+	host = strings.ToLower(host)
+	repoPath = strings.TrimSuffix(strings.Trim(path.Clean("/"+repoPath), "/"), ".git")
+	if host == "" || repoPath == "" {
+		return ""
+	}
+	return host + "/" + repoPath
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+func TestNormalizeGitRemote(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://github.com/Owner/Repo.git":          "github.com/Owner/Repo",
+		"https://token:x@GitHub.com/Owner/Repo/":     "github.com/Owner/Repo",
+		"git@github.com:Owner/Repo.git":              "github.com/Owner/Repo",
+		"ssh://git@github.com:22/Owner/Repo":         "github.com/Owner/Repo",
+		"git://gitlab.example.com/group/sub/project": "gitlab.example.com/group/sub/project",
+		"/srv/git/repo.git":                          "",
+		"file:///srv/git/repo.git":                   "",
+		"":                                           "",
+	} {
+		assert.Equal(t, want, normalizeGitRemote(raw), raw)
+	}
+}
+
+func TestResolveProjectID_GitMode(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "upstream", "https://example.com/fork/repo.git"},
+		{"remote", "add", "origin", "git@github.com:owner/repo.git"},
+	} {
+		require.NoError(t, exec.Command("git", append([]string{"-C", dir}, args...)...).Run())
+	}
+
+	t.Setenv(app.ProjectIdentityEnv, "path")
+	assert.Equal(t, dir, resolveProjectID(dir))
+
+	t.Setenv(app.ProjectIdentityEnv, "git")
+	assert.Equal(t, "github.com/owner/repo", resolveProjectID(dir), "origin wins over other remotes")
+
+	plain := t.TempDir()
+	assert.Equal(t, plain, resolveProjectID(plain), "no repository falls back to the path")
+}
+
+func TestResolveProjectID_ConfiguredName(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := t.TempDir()
+	nested := filepath.Join(repo, "cmd", "api")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, app.ProjectConfigFileName),
+		[]byte("[project]\nname = \"acme-api\"\n"), 0o600))
+
+	t.Setenv(app.ProjectIdentityEnv, "path")
+	assert.Equal(t, "acme-api", resolveProjectID(nested), "the configured name wins over the path")
+	assert.Equal(t, "acme-api", resolveProjectID(repo))
+	assert.Equal(t, "relative/dir", resolveProjectID("relative/dir"), "non-path IDs are left alone")
+}
+
+func TestResolveProjectID_AliasWins(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, app.ProjectConfigFileName),
+		[]byte("[project]\nname = \"acme-api\"\n"), 0o600))
+
+	_, err := app.AddProjectAlias(repo, "/old/checkout/api")
+	require.NoError(t, err)
+	assert.Equal(t, "/old/checkout/api", resolveProjectID(filepath.Join(repo, "pkg")))
+
+	_, err = app.RemoveProjectAlias(repo)
+	require.NoError(t, err)
+	assert.Equal(t, "acme-api", resolveProjectID(repo))
+}
+
+func TestProjectConfigFor_UsesTargetProjectDirectory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(app.ProjectIdentityEnv, "path")
+	cwd := chdirTemp(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(cwd, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(cwd, app.ProjectConfigFileName),
+		[]byte("[project]\ndefault_priority = 1\n"), 0o600))
+	other := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(other, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(other, app.ProjectConfigFileName),
+		[]byte("[project]  # the other repo\ndefault_priority = 5\n"), 0o600))
+
+	priority := func(projectID string) int {
+		p, _ := projectConfigFor(projectID).DefaultPriority()
+		return p
+	}
+	assert.Equal(t, 1, priority(""))
+	assert.Equal(t, 1, priority(resolveProjectID(cwd)))
+	assert.Equal(t, 5, priority(other), "a path project ID reads that directory's file")
+	assert.Zero(t, priority("unknown-project"), "the caller's file does not leak into another project")
+
+	_, err := app.AddProjectAlias(other, "acme-api")
+	require.NoError(t, err)
+	assert.Equal(t, 5, priority("acme-api"), "an aliased project reads its checkout's file")
+}
@@ -47,10 +47,13 @@ func NewPushCmd() *cobra.Command {
 			if err := json.Unmarshal(inputBytes, &input); err != nil {
 				return cmdErr(fmt.Errorf("invalid JSON input: %w", err))
 			}
+			if strict, _ := cmd.Flags().GetBool("strict-kinds"); strict {
+				input.StrictKinds = true
+			}
 
 			var result *actions.PushResult
-			if err := withDB(func(db *DB) error {
-				r, err := actions.PushIdempotent(db, agentName, requestID, input)
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.PushIdempotent(cmd.Context(), db, agentName, requestID, input)
 				if err != nil {
 					return err
 				}
@@ -65,6 +68,7 @@ func NewPushCmd() *cobra.Command {
 	}
 
 	cmd.Flags().String("json", "", "JSON input payload")
+	cmd.Flags().Bool("strict-kinds", false, "Reject an event kind that is not built in or registered (see events kinds)")
 
 	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
 	return cmd
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewReportCmd creates the report command group: read-only views over history.
+func NewReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Read-only activity reports built from the event log",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newReportHeatmapCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newReportHeatmapCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "heatmap",
+		Short: "Show when work happens: events, completions, and failures by hour and day",
+		Long: `Heatmap buckets the events of the last --since window by hour of day, weekday,
+and calendar day. Completions are tasks moved to completed; failures are tool
+failures plus tasks moved to blocked. Buckets use --tz (default: local time).
+
+--format json returns every bucket; --format markdown prints a weekday-by-hour
+shaded grid of --metric followed by hourly and daily tables.`,
+		Example: `  vybe report heatmap --project "$PWD" --since 30d --format markdown
+  vybe report heatmap --since 2w --metric failures --format markdown`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project")
+			sinceRaw, _ := cmd.Flags().GetString("since")
+			tz, _ := cmd.Flags().GetString("tz")
+			format, _ := cmd.Flags().GetString("format")
+			metric, _ := cmd.Flags().GetString("metric")
+
+			if format != "json" && format != "markdown" {
+				return cmdErr(fmt.Errorf("invalid --format %q (valid: json, markdown)", format))
+			}
+			if err := actions.ValidateHeatmapMetric(metric); err != nil {
+				return cmdErr(err)
+			}
+			sinceDays, err := app.ParseRetentionDays(sinceRaw)
+			if err != nil {
+				return cmdErr(fmt.Errorf("invalid --since: %w", err))
+			}
+			if filepath.IsAbs(projectID) {
+				projectID = resolveProjectID(filepath.Clean(projectID))
+			}
+
+			var heatmap *store.ActivityHeatmap
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				heatmap, err = actions.ActivityHeatmap(cmd.Context(), db, projectID, sinceDays, tz)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			if format == "markdown" {
+				_, err := fmt.Fprint(cmd.OutOrStdout(), actions.RenderActivityHeatmapMarkdown(heatmap, metric))
+				return err
+			}
+			return output.PrintSuccess(heatmap)
+		},
+	}
+
+	cmd.Flags().String("project", "", "Only this project's events (default: all projects)")
+	cmd.Flags().String("since", "30d", "Window to report on (e.g. 30d, 2w)")
+	cmd.Flags().String("tz", "Local", "Time zone for buckets (IANA name, Local, or UTC)")
+	cmd.Flags().String("format", "json", "Output format: json|markdown")
+	cmd.Flags().String("metric", actions.HeatmapMetricEvents, "Grid metric for markdown: events|completions|failures")
+	return cmd
+}
@@ -1,7 +1,12 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
 	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/output"
 	"github.com/dotcommander/vybe/internal/store"
 	"github.com/spf13/cobra"
@@ -10,10 +15,17 @@ import (
 // NewResumeCmd creates the resume command
 func NewResumeCmd() *cobra.Command {
 	var (
-		limit      int
-		projectDir string
-		peek       bool
-		focus      string
+		limit          int
+		projectDir     string
+		peek           bool
+		focus          string
+		maxTokens      int
+		policy         string
+		session        string
+		onboarding     bool
+		overrideLimits bool
+		agentMemory    bool
+		contextProfile string
 	)
 
 	cmd := &cobra.Command{
@@ -25,30 +37,42 @@ and builds a brief packet with all context needed to resume work.
 The cursor is advanced monotonically and the focus task is updated atomically.
 Use --project-dir to scope resume to a specific project directory.
 Use --peek to read the current brief without advancing the cursor (no request-id required).
-Use --focus <task-id> to set the agent's focus task before resuming (request-id required).`,
+Use --focus <task-id> to set the agent's focus task before resuming (request-id required).
+Use --max-tokens to trim the brief to a token budget; brief.budget reports what was elided.
+Use --policy to override focus.policy for this call: priority-first, deadline-first,
+project-affinity, or round-robin.
+Use --session (or VYBE_SESSION_ID) when several sessions share one agent name: each
+session keeps its own focus and never picks a task another active session holds.
+
+An agent resuming a project for the first time (cursor 0, no focus) also receives
+brief.onboarding: project memory, top lessons, a task graph overview, and the
+project's conventions file. Use --onboarding to request it on any resume.
+
+Agent-scoped memory is personal to one agent and left out of briefs unless
+--include-agent-memory is given or brief.include_agent_memory is set in config;
+then the resuming agent's own entries are ranked in with the rest.
+
+The prompt is shaped by a context profile: minimal, standard, or deep-debug (see
+context.profile in config, overridable per project). Use --context-profile to pick
+one for this call; the response names the profile it used.
+
+Picking a new task counts toward the tasks_per_day limit (see vybe limits); once
+it is reached resume keeps no new focus and reports limit_reached, unless
+--override-limits is given.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentName, err := requireActorName(cmd, "")
 			if err != nil {
 				return cmdErr(err)
 			}
 
+			if session == "" {
+				session = os.Getenv(app.SessionIDEnv)
+			}
+
 			if peek {
-				type briefResponse struct {
-					AgentName string             `json:"agent_name"`
-					Brief     *store.BriefPacket `json:"brief"`
-				}
-				var resp briefResponse
-				if err := withDB(func(db *DB) error {
-					b, err := actions.Brief(db, agentName)
-					if err != nil {
-						return err
-					}
-					resp = briefResponse{AgentName: agentName, Brief: b}
-					return nil
-				}); err != nil {
-					return err
-				}
-				return output.PrintSuccess(resp)
+				return runBriefMode(cmd, agentName, "json", actions.BriefOptions{
+					MaxTokens: maxTokens, SessionID: session, Onboarding: onboarding, IncludeAgentMemory: agentMemory,
+				})
 			}
 
 			requestID, err := requireRequestID(cmd)
@@ -56,12 +80,32 @@ Use --focus <task-id> to set the agent's focus task before resuming (request-id
 				return cmdErr(err)
 			}
 
+			var focusPolicy app.FocusPolicy
+			if policy != "" {
+				focusPolicy, err = app.ParseFocusPolicy(policy)
+				if err != nil {
+					return cmdErr(err)
+				}
+			}
+			if contextProfile != "" {
+				if _, err := app.LookupContextProfile(contextProfile); err != nil {
+					return cmdErr(err)
+				}
+			}
+
 			var response *actions.ResumeResponse
-			if err := withDB(func(db *DB) error {
-				r, err := actions.ResumeWithOptionsIdempotent(db, agentName, requestID, actions.ResumeOptions{
-					EventLimit:        limit,
-					ProjectDir:        projectDir,
-					FocusTaskOverride: focus,
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.ResumeWithOptionsIdempotent(cmd.Context(), db, agentName, requestID, actions.ResumeOptions{
+					EventLimit:         limit,
+					ProjectDir:         resolveProjectID(projectDir),
+					FocusTaskOverride:  focus,
+					MaxTokens:          maxTokens,
+					FocusPolicy:        focusPolicy,
+					SessionID:          session,
+					Onboarding:         onboarding,
+					OverrideLimits:     overrideLimits,
+					IncludeAgentMemory: agentMemory,
+					ContextProfile:     contextProfile,
 				})
 				if err != nil {
 					return err
@@ -80,7 +124,143 @@ Use --focus <task-id> to set the agent's focus task before resuming (request-id
 	cmd.Flags().StringVar(&projectDir, "project-dir", "", "Scope resume to a project directory path")
 	cmd.Flags().BoolVar(&peek, "peek", false, "Read current brief without advancing cursor (no request-id required)")
 	cmd.Flags().StringVar(&focus, "focus", "", "Set agent focus task before resuming (request-id required)")
+	cmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Trim the brief to this approximate token budget (0 = unlimited)")
+	cmd.Flags().StringVar(&policy, "policy", "", "Focus selection policy for this call (default: focus.policy from config)")
+	cmd.Flags().StringVar(&session, "session", "", "Session ID for session-scoped focus (default: $VYBE_SESSION_ID)")
+	cmd.Flags().BoolVar(&onboarding, "onboarding", false, "Include the first-visit onboarding brief even for returning agents")
+	cmd.Flags().BoolVar(&overrideLimits, "override-limits", false, "Pick a new task even when the tasks_per_day limit is reached")
+	cmd.Flags().BoolVar(&agentMemory, "include-agent-memory", false, "Merge this agent's own agent-scoped memory into the brief (default: brief.include_agent_memory from config)")
+	cmd.Flags().StringVar(&contextProfile, "context-profile", "", "Prompt context profile: minimal|standard|deep-debug (default: context.profile from config)")
 
 	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "conditional"}
 	return cmd
 }
+
+// NewBriefCmd creates the read-only brief command (equivalent to resume --peek).
+func NewBriefCmd() *cobra.Command {
+	var (
+		maxTokens   int
+		session     string
+		onboarding  bool
+		agentMemory bool
+		format      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "brief",
+		Short: "Show the current brief without advancing the cursor",
+		Long: `Brief returns the brief packet for the agent's current focus task and project.
+It never advances the event cursor or changes focus, so no request-id is needed.
+
+Use --max-tokens to trim the brief to a budget. Sections are kept in priority
+order: task, dependencies, recent failures, memory, then history. The
+brief.budget field lists how many entries were elided from each section.
+
+--format markdown prints the brief as a markdown document (task, criteria,
+dependencies, memory, recent events, artifacts) instead of the JSON envelope,
+for pasting into agents without hook integration. 'vybe task brief --id'
+renders the same packet for any task.`,
+		Example: `  vybe brief --max-tokens 2000
+  vybe brief --format markdown | pbcopy`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, err := requireActorName(cmd, "")
+			if err != nil {
+				return cmdErr(err)
+			}
+			if session == "" {
+				session = os.Getenv(app.SessionIDEnv)
+			}
+			return runBriefMode(cmd, agentName, format, actions.BriefOptions{
+				MaxTokens: maxTokens, SessionID: session, Onboarding: onboarding, IncludeAgentMemory: agentMemory,
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Trim the brief to this approximate token budget (0 = unlimited)")
+	cmd.Flags().StringVar(&session, "session", "", "Brief this session's focus (default: $VYBE_SESSION_ID)")
+	cmd.Flags().BoolVar(&onboarding, "onboarding", false, "Include the onboarding brief for the focus project")
+	cmd.Flags().BoolVar(&agentMemory, "include-agent-memory", false, "Merge this agent's own agent-scoped memory into the brief (default: brief.include_agent_memory from config)")
+	cmd.Flags().StringVar(&format, "format", "json", "Output format: json|markdown")
+	cmd.AddCommand(newBriefDiffCmd())
+	return cmd
+}
+
+func newBriefDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the briefs two agents would receive",
+		Long: `Diff builds the read-only brief for two agents and reports where their injected
+context differs: focus task, project, memory selection, recent events, prior
+reasoning, and artifacts. Use --project-dir to compare both agents against the
+same project regardless of their own project focus.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentA, _ := cmd.Flags().GetString("agent-a")
+			agentB, _ := cmd.Flags().GetString("agent-b")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			maxTokens, _ := cmd.Flags().GetInt("max-tokens")
+
+			if agentA == "" {
+				agentA = resolveActorName(cmd, "")
+			}
+			if agentA == "" || agentB == "" {
+				return cmdErr(errors.New("--agent-b is required, and --agent-a (or an actor identity) must resolve"))
+			}
+			if maxTokens < 0 {
+				return cmdErr(errors.New("--max-tokens must be >= 0"))
+			}
+
+			var result *actions.BriefDiffResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.BriefDiff(cmd.Context(), db, agentA, agentB, actions.BriefOptions{MaxTokens: maxTokens, ProjectDir: resolveProjectID(projectDir)})
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("agent-a", "", "First agent (defaults to the resolved actor)")
+	cmd.Flags().String("agent-b", "", "Second agent to compare against")
+	cmd.Flags().String("project-dir", "", "Build both briefs for this project")
+	cmd.Flags().Int("max-tokens", 0, "Shape both briefs to this token budget before comparing (0 = unlimited)")
+	return cmd
+}
+
+// briefResponse is the JSON response of brief and task brief.
+type briefResponse struct {
+	AgentName string             `json:"agent_name"`
+	Brief     *store.BriefPacket `json:"brief"`
+}
+
+func runBriefMode(cmd *cobra.Command, agentName, format string, opts actions.BriefOptions) error {
+	if opts.MaxTokens < 0 {
+		return cmdErr(errors.New("--max-tokens must be >= 0"))
+	}
+	if format != "json" && format != "markdown" {
+		return cmdErr(fmt.Errorf("invalid --format %q (valid: json, markdown)", format))
+	}
+
+	var resp briefResponse
+	if err := withDB(cmd.Context(), func(db *DB) error {
+		b, err := actions.BriefWithOptions(cmd.Context(), db, agentName, opts)
+		if err != nil {
+			return err
+		}
+		resp = briefResponse{AgentName: agentName, Brief: b}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if format == "markdown" {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), actions.RenderBriefMarkdown(agentName, resp.Brief))
+		return err
+	}
+	return output.PrintSuccess(resp)
+}
@@ -6,15 +6,39 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/output"
 )
 
-// Execute runs the CLI application.
+// addRootPersistentFlags declares the flags every command inherits. The daemon
+// proxy reads the same set to tell flag values from command names.
+func addRootPersistentFlags(fs *pflag.FlagSet) {
+	fs.String("db-path", "", "Override database path")
+	fs.StringP("agent", "a", "", "Agent name (default: $VYBE_AGENT, .vybe.toml; see vybe whoami)")
+	fs.String("request-id", "", "Idempotency key for mutating operations (default: $VYBE_REQUEST_ID)")
+	fs.String("format", string(output.FormatJSON), "Output format: json|table|quiet|yaml")
+	fs.BoolP("quiet", "q", false, "Print only primary IDs, one per line (same as --format quiet)")
+	fs.Bool("validate", false, "Check the response against its published schema (vybe schema responses); exit 1 on mismatch")
+	_ = fs.MarkHidden("validate")
+	fs.String("otel-endpoint", "", "Export a trace of this command to an OTLP/HTTP collector (default: $OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, $OTEL_EXPORTER_OTLP_ENDPOINT)")
+}
+
+// Execute runs the CLI application. When a vybe daemon serves the resolved
+// database, the command is proxied to it instead of running in-process.
 func Execute(version string) error {
 	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
 
+	if err, ok := proxyToDaemon(os.Args[1:]); ok {
+		return err
+	}
+	return executeArgs(version, os.Args[1:])
+}
+
+// newRootCmd builds the full command tree. Each call returns fresh commands,
+// so flag state never leaks between daemon requests.
+func newRootCmd(version string) *cobra.Command {
 	root := &cobra.Command{
 		Use:           "vybe",
 		Short:         "Agent continuity primitives (resume, push, task, memory, status)",
@@ -59,28 +83,74 @@ func Execute(version string) error {
 				app.SetDBPathOverride(dbPath)
 			}
 
-			return nil
+			startCommandTrace(cmd, version)
+
+			if validate, _ := cmd.Flags().GetBool("validate"); validate {
+				output.SetResponseSchema(responseSchemaFor(commandKey(cmd)))
+			}
+			return applyOutputFormat(cmd)
 		},
 	}
 
-	root.PersistentFlags().String("db-path", "", "Override database path")
-	root.PersistentFlags().StringP("agent", "a", "", "Agent name (default: $VYBE_AGENT)")
-	root.PersistentFlags().String("request-id", "", "Idempotency key for mutating operations (default: $VYBE_REQUEST_ID)")
+	addRootPersistentFlags(root.PersistentFlags())
 	root.Flags().BoolP("version", "v", false, "version for vybe")
 
 	root.AddCommand(NewTaskCmd())
 	root.AddCommand(NewMemoryCmd())
 	root.AddCommand(NewResumeCmd())
+	root.AddCommand(NewBriefCmd())
 	root.AddCommand(NewLoopCmd())
 	root.AddCommand(NewHookCmd())
 	root.AddCommand(NewStatusCmd(root)) // root passed for --schema mode
 	root.AddCommand(NewUpgradeCmd())
 	root.AddCommand(NewPushCmd())
+	root.AddCommand(NewBatchCmd())
 	root.AddCommand(NewEventsCmd())
 	root.AddCommand(NewArtifactsCmd())
 	root.AddCommand(NewSchemaCmd(root))
+	root.AddCommand(NewWhoamiCmd())
+	root.AddCommand(NewConfigCmd())
+	root.AddCommand(NewIngestCmd())
+	root.AddCommand(NewFederateCmd())
+	root.AddCommand(NewWorkspaceCmd())
+	root.AddCommand(NewLimitsCmd())
+	root.AddCommand(NewMsgCmd())
+	root.AddCommand(NewAgentCmd())
+	root.AddCommand(NewLessonCmd())
+	root.AddCommand(NewDecisionsCmd())
+	root.AddCommand(NewProjectCmd())
+	root.AddCommand(NewDevCmd())
+	root.AddCommand(NewDoctorCmd())
+	root.AddCommand(NewDBCmd())
+	root.AddCommand(NewSessionCmd())
+	root.AddCommand(NewReportCmd())
+	root.AddCommand(NewAnalyticsCmd())
+	root.AddCommand(NewStatsCmd())
+	root.AddCommand(NewFilesCmd())
+	root.AddCommand(NewScenarioCmd())
+	root.AddCommand(NewSnapshotCmd())
+	root.AddCommand(NewPlanCmd())
+	root.AddCommand(NewDaemonCmd(version))
+	root.AddCommand(NewTokenCmd())
+	return root
+}
 
-	err := root.Execute()
+// executeArgs runs one command line in-process.
+func executeArgs(version string, args []string) error {
+	// The daemon runs many command lines in one process; start each as JSON,
+	// without validation.
+	output.SetFormat(output.FormatJSON)
+	output.SetResponseSchema(nil)
+	root := newRootCmd(version)
+	root.SetArgs(args)
+	cmd, err := root.ExecuteC()
+	finishCommandTrace(cmd, err)
+	var ve *output.ResponseValidationError
+	if errors.As(err, &ve) {
+		// The response is already on stdout; report the drift on stderr only.
+		slog.Default().Error("response does not match its schema", "violations", ve.Violations)
+		return err
+	}
 	if err != nil {
 		var pe printedError
 		if !errors.As(err, &pe) {
@@ -92,3 +162,21 @@ func Execute(version string) error {
 	}
 	return err
 }
+
+// applyOutputFormat sets the output format from the root --format and --quiet
+// flags. Commands with their own --format (brief, task graph, events export,
+// ...) shadow the root flag, so it is read from the root flag set directly.
+func applyOutputFormat(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+	if quiet, _ := flags.GetBool("quiet"); quiet {
+		output.SetFormat(output.FormatQuiet)
+		return nil
+	}
+	raw, _ := flags.GetString("format")
+	format, err := output.ParseFormat(raw)
+	if err != nil {
+		return err
+	}
+	output.SetFormat(format)
+	return nil
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// NewScenarioCmd creates the scenario command group: user-defined end-to-end
+// checks of a vybe setup.
+func NewScenarioCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scenario",
+		Short: "Run user-defined command sequences with JSON assertions",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newScenarioRunCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newScenarioRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run a scenario file step by step and report each assertion",
+		Long: `run executes the steps of a YAML scenario with this vybe binary, each as its own
+process, and checks the exit code and JSON response of every step. Use it to
+confirm a setup and custom configuration still behave after an upgrade.
+
+Steps run against a scratch database that is deleted afterwards, unless --db
+names one (it is created if missing). Your config.yaml still applies.
+
+  name: smoke
+  env:
+    VYBE_AGENT: scenario
+  steps:
+    - name: create a task
+      run: task create --title "Scenario task" --request-id ${scenario_run}_create
+      expect:
+        success: true
+        json:
+          data.task.status: pending
+      save:
+        task_id: data.task.id
+    - run: task get --id ${task_id}
+      expect:
+        json:
+          data.title: Scenario task
+    - run: task get --id does-not-exist
+      expect:
+        success: false
+
+Each step sets run (split like a shell; a leading "vybe" is optional) or args
+(a list of words), and optionally stdin. expect takes exit_code, success, json
+(dotted path to expected value; array items by index, e.g. data.tasks.0.id),
+exists (paths that must be present), and contains (substrings of stdout).
+Without exit_code a step must exit 0, or non-zero when success is false. save
+copies response fields into ${variables}. ${scenario_run} is unique per run,
+${db_path} is the database, and ${workdir} a scratch directory.
+
+The first failing step stops the run unless --keep-going is set. The command
+exits 1 when any step fails; the report lists each failure with its output.`,
+		Example: `  vybe scenario run --file scenarios/smoke.yaml
+  vybe scenario run --file smoke.yaml --db /tmp/smoke.db --keep-going`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			dbPath, _ := cmd.Flags().GetString("db")
+			keepGoing, _ := cmd.Flags().GetBool("keep-going")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			if file == "" {
+				return cmdErr(errors.New("--file is required"))
+			}
+
+			scenario, err := actions.LoadScenario(file)
+			if err != nil {
+				return cmdErr(err)
+			}
+			self, err := os.Executable()
+			if err != nil {
+				return cmdErr(fmt.Errorf("failed to locate the vybe binary: %w", err))
+			}
+			workdir, err := os.MkdirTemp("", "vybe-scenario-")
+			if err != nil {
+				return cmdErr(fmt.Errorf("failed to create scratch directory: %w", err))
+			}
+			defer func() { _ = os.RemoveAll(workdir) }()
+			if dbPath == "" {
+				dbPath = filepath.Join(workdir, "scenario.db")
+			} else if dbPath, err = filepath.Abs(dbPath); err != nil {
+				return cmdErr(err)
+			}
+
+			vars := map[string]string{
+				"scenario_run": fmt.Sprintf("scn_%d", time.Now().UnixNano()),
+				"db_path":      dbPath,
+				"workdir":      workdir,
+			}
+			res := actions.RunScenario(scenario, scenarioExec(self, workdir, timeout), scenarioEnv(dbPath), vars, keepGoing)
+			if res.Name == "" {
+				res.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			}
+			if err := output.PrintSuccess(res); err != nil {
+				return err
+			}
+			if !res.Passed {
+				// Each failure is already in the printed report.
+				return printedError{err: fmt.Errorf("scenario %s: %d step(s) failed", res.Name, res.Failed)}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("file", "", "Scenario YAML file (required)")
+	cmd.Flags().String("db", "", "Database to run against (default: a scratch database)")
+	cmd.Flags().Bool("keep-going", false, "Run the remaining steps after a failure")
+	cmd.Flags().Duration("timeout", time.Minute, "Time limit for each step")
+	return cmd
+}
+
+// scenarioEnv is the caller's environment pointed at dbPath, with the daemon
+// bypassed so every step opens the scenario database itself.
+func scenarioEnv(dbPath string) []string {
+	env := []string{}
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "VYBE_DB_PATH=") || strings.HasPrefix(kv, "VYBE_NO_DAEMON=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	env = append(env, "VYBE_DB_PATH="+dbPath, "VYBE_NO_DAEMON=1")
+	if os.Getenv("VYBE_AGENT") == "" {
+		env = append(env, "VYBE_AGENT=scenario")
+	}
+	return env
+}
+
+// scenarioExec runs each step as a child process of the vybe binary at self,
+// inside dir.
+func scenarioExec(self, dir string, timeout time.Duration) actions.ScenarioExec {
+	return func(args []string, stdin string, env []string) (string, string, int, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		c := exec.CommandContext(ctx, self, args...) //nolint:gosec // runs this binary with the scenario's own arguments
+		c.Dir = dir
+		c.Env = env
+		c.Stdin = strings.NewReader(stdin)
+		var stdout, stderr bytes.Buffer
+		c.Stdout, c.Stderr = &stdout, &stderr
+		err := c.Run()
+		if ctx.Err() != nil {
+			return stdout.String(), stderr.String(), -1, fmt.Errorf("timed out after %s", timeout)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return stdout.String(), stderr.String(), exitErr.ExitCode(), nil
+		}
+		if err != nil {
+			return stdout.String(), stderr.String(), -1, err
+		}
+		return stdout.String(), stderr.String(), 0, nil
+	}
+}
@@ -1,15 +1,60 @@
 package commands
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// briefCompatibility is the promise published with the brief schema.
+const briefCompatibility = "additive-only within a major version: fields are never removed, renamed, or retyped; new fields may appear and should be ignored by parsers that do not know them"
 
 // NewSchemaCmd creates the schema command. root is used to collect command schemas.
 func NewSchemaCmd(root *cobra.Command) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "schema",
 		Short: "Show command argument schemas with mutation hints",
-		Args:  cobra.NoArgs,
+		Long: `schema lists every command's flags as JSON Schema, with mutation hints.
+
+--brief instead prints the JSON Schema of the brief packet (data.brief in
+resume and brief responses) for its current major version, which every brief
+reports as brief_version. Within a major version the brief only grows: fields
+are never removed, renamed, or retyped, so prompt templates and parsers built
+against the schema keep working.
+
+'vybe schema responses' prints the JSON Schema of each command's response
+envelope.`,
+		Example: `  vybe schema
+  vybe schema --brief | jq '.data.schema.properties | keys'
+  vybe schema responses --command "task get"`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if brief, _ := cmd.Flags().GetBool("brief"); brief {
+				return output.PrintSuccess(briefSchema())
+			}
 			return runSchemaMode(root)
 		},
 	}
+
+	cmd.Flags().Bool("brief", false, "Print the versioned JSON Schema of the brief packet")
+	cmd.AddCommand(newSchemaResponsesCmd(root))
+	return cmd
+}
+
+type briefSchemaResponse struct {
+	Version       string         `json:"version"`
+	Compatibility string         `json:"compatibility"`
+	Schema        map[string]any `json:"schema"`
+}
+
+func briefSchema() briefSchemaResponse {
+	schema := output.JSONSchema(store.BriefPacket{})
+	schema["$id"] = "urn:vybe:brief:" + store.BriefSchemaVersion
+	schema["title"] = "vybe brief packet " + store.BriefSchemaVersion
+	return briefSchemaResponse{
+		Version:       store.BriefSchemaVersion,
+		Compatibility: briefCompatibility,
+		Schema:        schema,
+	}
 }
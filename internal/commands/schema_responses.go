@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"errors"
+	"maps"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// responseTypes maps a command path (without the leading "vybe ") to the Go
+// types its JSON data can take; a command with several shapes, such as task
+// list with and without --full, lists one per shape. 'vybe schema responses'
+// publishes their JSON Schemas and --validate checks responses against them.
+// Commands missing here publish the bare envelope with unconstrained data.
+//
+//nolint:gochecknoglobals // read-only registry
+var responseTypes = map[string][]any{
+	"resume":          {actions.ResumeResponse{}},
+	"brief":           {briefResponse{}},
+	"task brief":      {briefResponse{}},
+	"task create":     {taskCmdResult{}},
+	"task set-status": {taskCmdResult{}},
+	"task begin":      {taskBeginResponse{}},
+	"task claim":      {taskClaimResponse{}},
+	"task get":        {models.Task{}},
+	"task list":       {taskListSummaryResponse{}, taskListFullResponse{}},
+	"task complete":   {actions.TaskCloseResult{}},
+	"task wait":       {actions.TaskWaitResult{}},
+	"task delegate":   {taskDelegateResponse{}},
+	"push":            {actions.PushResult{}},
+	"analytics":       {store.Analytics{}},
+	"stats history":   {store.ProjectTrends{}},
+	"events verify":   {store.EventChainReport{}},
+	"events kinds":    {eventKindsResponse{}},
+	"token create":    {store.APIToken{}},
+	"memory set":      {memorySetResponse{}},
+	"memory get":      {models.Memory{}},
+	"memory list":     {memoryListResponse{}},
+	"files touched":   {filesResponse{}},
+	"files hot":       {filesResponse{}},
+}
+
+type commandResponseSchema struct {
+	Command    string         `json:"command"`
+	Documented bool           `json:"documented"`
+	Schema     map[string]any `json:"schema"`
+}
+
+func newSchemaResponsesCmd(root *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "responses",
+		Short: "Show JSON Schemas of command response envelopes",
+		Long: `responses prints, for every command, the JSON Schema of its response envelope
+({schema_version, success, data, ...}), generated from the Go types the command
+returns. Commands whose data shape is not yet published report documented=false
+and leave data unconstrained.
+
+Run any command with the root --validate flag to check its response against
+the published schema; a mismatch is logged and exits 1 after the response is
+printed.`,
+		Example: `  vybe schema responses --command "task get" | jq '.data.commands[0].schema'
+  vybe task list --validate`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			only, _ := cmd.Flags().GetString("command")
+			only = strings.TrimPrefix(strings.TrimSpace(only), "vybe ")
+
+			var schemas []commandResponseSchema
+			walkRunnable(root, func(c *cobra.Command) {
+				path := commandKey(c)
+				if only != "" && path != only {
+					return
+				}
+				_, documented := responseTypes[path]
+				schemas = append(schemas, commandResponseSchema{
+					Command:    c.CommandPath(),
+					Documented: documented,
+					Schema:     responseSchemaFor(path),
+				})
+			})
+			if only != "" && len(schemas) == 0 {
+				return cmdErr(errors.New("unknown command " + only))
+			}
+
+			type resp struct {
+				Count    int                     `json:"count"`
+				Commands []commandResponseSchema `json:"commands"`
+			}
+			return output.PrintSuccess(resp{Count: len(schemas), Commands: schemas})
+		},
+	}
+
+	cmd.Flags().String("command", "", `Only this command, e.g. "task get"`)
+	return cmd
+}
+
+// walkRunnable calls fn for every visible command that runs something.
+func walkRunnable(cmd *cobra.Command, fn func(*cobra.Command)) {
+	if cmd.HasParent() && !cmd.Hidden && cmd.Runnable() {
+		fn(cmd)
+	}
+	for _, child := range cmd.Commands() {
+		walkRunnable(child, fn)
+	}
+}
+
+// commandKey is cmd's path without the root command name, e.g. "task get".
+func commandKey(cmd *cobra.Command) string {
+	path := cmd.CommandPath()
+	if cmd.Root() != nil {
+		path = strings.TrimPrefix(path, cmd.Root().Name())
+	}
+	return strings.TrimSpace(path)
+}
+
+// responseSchemaFor returns the JSON Schema of the response envelope for the
+// command at path. data is constrained by the types in responseTypes, one
+// oneOf branch per shape, and left open for unregistered commands.
+func responseSchemaFor(path string) map[string]any {
+	envelope := output.JSONSchema(output.Response{})
+	envelope["$id"] = "urn:vybe:response:" + strings.ReplaceAll(path, " ", ".")
+	envelope["title"] = "vybe " + path + " response"
+
+	shapes := responseTypes[path]
+	if len(shapes) == 0 {
+		return envelope
+	}
+	defs, _ := envelope["$defs"].(map[string]any)
+	if defs == nil {
+		defs = map[string]any{}
+	}
+	branches := make([]any, 0, len(shapes))
+	for _, shape := range shapes {
+		s := output.JSONSchema(shape)
+		delete(s, "$schema")
+		if d, ok := s["$defs"].(map[string]any); ok {
+			maps.Copy(defs, d)
+			delete(s, "$defs")
+		}
+		branches = append(branches, s)
+	}
+	data := branches[0].(map[string]any)
+	if len(branches) > 1 {
+		data = map[string]any{"oneOf": branches}
+	}
+	envelope["properties"].(map[string]any)["data"] = data
+	if len(defs) > 0 {
+		envelope["$defs"] = defs
+	}
+	return envelope
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// TestBriefSchema_AdditiveOnly guards the brief compatibility promise: every
+// field in the published v1 schema must still exist, with the same type and
+// still required if it was. New fields are fine. A change this test rejects
+// needs a new BriefSchemaVersion (and a new golden file), not an edit here.
+func TestBriefSchema_AdditiveOnly(t *testing.T) {
+	require.Equal(t, "v1", store.BriefSchemaVersion, "a new major version needs its own golden schema")
+
+	raw, err := os.ReadFile("testdata/brief_schema_v1.json")
+	require.NoError(t, err)
+	var published map[string]any
+	require.NoError(t, json.Unmarshal(raw, &published))
+
+	var current map[string]any
+	b, err := json.Marshal(briefSchema().Schema)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &current))
+
+	for _, problem := range schemaBreaks("brief", published, current) {
+		t.Error(problem)
+	}
+}
+
+func TestBriefSchema_Metadata(t *testing.T) {
+	s := briefSchema()
+	assert.Equal(t, store.BriefSchemaVersion, s.Version)
+	assert.Equal(t, "urn:vybe:brief:v1", s.Schema["$id"])
+	assert.NotEmpty(t, s.Compatibility)
+}
+
+func TestSchemaBreaks_DetectsRemovalsAndRetypes(t *testing.T) {
+	old := map[string]any{
+		"properties": map[string]any{
+			"a": map[string]any{"type": "string"},
+			"b": map[string]any{"type": "integer"},
+		},
+		"required": []any{"a"},
+	}
+	grown := map[string]any{
+		"properties": map[string]any{
+			"a": map[string]any{"type": "string"},
+			"b": map[string]any{"type": "integer"},
+			"c": map[string]any{"type": "boolean"},
+		},
+		"required": []any{"a", "c"},
+	}
+	assert.Empty(t, schemaBreaks("x", old, grown))
+
+	broken := map[string]any{
+		"properties": map[string]any{
+			"a": map[string]any{"type": "integer"},
+		},
+	}
+	assert.Len(t, schemaBreaks("x", old, broken), 3, "retyped a, removed b, a no longer required")
+}
+
+// schemaBreaks lists the ways current is not an additive extension of old.
+func schemaBreaks(path string, old, current map[string]any) []string {
+	var out []string
+	for key, ov := range old {
+		cv, ok := current[key]
+		switch key {
+		case "properties", "$defs":
+			oldProps, _ := ov.(map[string]any)
+			curProps, _ := cv.(map[string]any)
+			for name, op := range oldProps {
+				cp, ok := curProps[name].(map[string]any)
+				if !ok {
+					out = append(out, path+"."+name+" was removed")
+					continue
+				}
+				out = append(out, schemaBreaks(path+"."+name, op.(map[string]any), cp)...)
+			}
+		case "required":
+			curReq, _ := cv.([]any)
+			for _, name := range ov.([]any) {
+				if !slices.Contains(curReq, name) {
+					out = append(out, path+"."+name.(string)+" is no longer required")
+				}
+			}
+		case "items", "additionalProperties":
+			om, _ := ov.(map[string]any)
+			cm, _ := cv.(map[string]any)
+			if cm == nil {
+				out = append(out, path+" "+key+" was removed")
+				continue
+			}
+			out = append(out, schemaBreaks(path+"[]", om, cm)...)
+		default:
+			if !ok || !reflect.DeepEqual(ov, cv) {
+				out = append(out, path+" changed "+key)
+			}
+		}
+	}
+	return out
+}
+
+func TestResponseSchemas_RegistryNamesRealCommands(t *testing.T) {
+	root := newRootCmd("test")
+	known := map[string]bool{}
+	walkRunnable(root, func(c *cobra.Command) { known[commandKey(c)] = true })
+	for path := range responseTypes {
+		assert.True(t, known[path], "responseTypes lists unknown command %q", path)
+	}
+}
+
+func TestValidateFlag_ChecksResponsesAgainstSchema(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("VYBE_DB_PATH", filepath.Join(dir, "v.db"))
+	t.Setenv("VYBE_AGENT", "validator")
+	t.Setenv("VYBE_NO_DAEMON", "1")
+
+	var created struct {
+		Data struct {
+			Task struct {
+				ID string `json:"id"`
+			} `json:"task"`
+		} `json:"data"`
+	}
+	out := captureStdout(t, func() {
+		require.NoError(t, executeArgs("test", []string{"task", "create", "--validate", "--title", "schema", "--request-id", "validate-1"}))
+	})
+	require.NoError(t, json.Unmarshal([]byte(out), &created))
+	captureStdout(t, func() {
+		require.NoError(t, executeArgs("test", []string{"task", "get", "--validate", "--id", created.Data.Task.ID}))
+		require.NoError(t, executeArgs("test", []string{"task", "list", "--validate", "--full"}))
+	})
+
+	// A response of the wrong shape is printed, then rejected.
+	t.Cleanup(func() { output.SetResponseSchema(nil) })
+	output.SetResponseSchema(responseSchemaFor("task get"))
+	var buf bytes.Buffer
+	err := output.PrintWith(output.Config{Writer: &buf}, output.Success(memoryListResponse{Scope: "global"}))
+	var ve *output.ResponseValidationError
+	require.ErrorAs(t, err, &ve)
+	assert.Contains(t, strings.Join(ve.Violations, "\n"), "missing required field title")
+	assert.NotEmpty(t, buf.String())
+}
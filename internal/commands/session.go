@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewSessionCmd creates the session command group.
+func NewSessionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "Inspect and close client sessions recorded by hooks",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newSessionListCmd())
+	cmd.AddCommand(newSessionGetCmd())
+	cmd.AddCommand(newSessionEndCmd())
+	cmd.AddCommand(newSessionLabelCmd())
+	cmd.AddCommand(newSessionReplayCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newSessionListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List sessions with start/end time, agent, project, event counts, and outcome",
+		Long: `List returns sessions newest first. An explicit --agent restricts the list to
+that agent; without it all agents' sessions are listed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, _ := cmd.Flags().GetString("agent")
+			projectID, _ := cmd.Flags().GetString("project-dir")
+			active, _ := cmd.Flags().GetBool("active")
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			var sessions []*models.Session
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				sessions, err = actions.SessionList(cmd.Context(), db, store.ListSessionsParams{
+					AgentName:  agentName,
+					ProjectID:  projectID,
+					ActiveOnly: active,
+					Limit:      limit,
+				})
+				return err
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Sessions []*models.Session `json:"sessions"`
+				Count    int               `json:"count"`
+			}
+			if sessions == nil {
+				sessions = []*models.Session{}
+			}
+			return output.PrintSuccess(resp{Sessions: sessions, Count: len(sessions)})
+		},
+	}
+
+	cmd.Flags().String("project-dir", "", "Only sessions started in this project")
+	cmd.Flags().Bool("active", false, "Only sessions that have not ended")
+	cmd.Flags().Int("limit", 50, "Max sessions to return")
+	return cmd
+}
+
+func newSessionGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Show one session",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionID, _ := cmd.Flags().GetString("session")
+			if sessionID == "" {
+				return cmdErr(errors.New("--session is required"))
+			}
+
+			var sess *models.Session
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				sess, err = actions.SessionGet(cmd.Context(), db, sessionID)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(sess)
+		},
+	}
+
+	cmd.Flags().String("session", "", "Session ID (required)")
+	return cmd
+}
+
+func newSessionEndCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "end",
+		Short: "Close a session the session-end hook did not close",
+		Long: `End records the session's end time and outcome. Without --outcome the outcome is
+derived: "completed" if the agent completed a task during the session, otherwise
+"incomplete". Ending an already-ended session returns it unchanged.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionID, _ := cmd.Flags().GetString("session")
+			outcome, _ := cmd.Flags().GetString("outcome")
+			reason, _ := cmd.Flags().GetString("reason")
+			if sessionID == "" {
+				return cmdErr(errors.New("--session is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var sess *models.Session
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				sess, err = actions.SessionEndIdempotent(cmd.Context(), db, agentName, requestID, sessionID, reason, outcome)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(sess)
+		},
+	}
+
+	cmd.Flags().String("session", "", "Session ID (required)")
+	cmd.Flags().String("outcome", "", "Outcome: completed|incomplete|failed|abandoned (default: derived)")
+	cmd.Flags().String("reason", "manual", "Why the session ended")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newSessionLabelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "label",
+		Short: "Name a session, replacing the label derived from its first prompt",
+		Long: `Label sets the human-friendly name shown in session list, replays, and the
+previous-session context header. The prompt hook labels each session from its
+first prompt automatically; use this to rename one.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionID, _ := cmd.Flags().GetString("session")
+			label, _ := cmd.Flags().GetString("label")
+			if sessionID == "" {
+				return cmdErr(errors.New("--session is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var sess *models.Session
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				sess, err = actions.SessionLabelIdempotent(cmd.Context(), db, agentName, requestID, sessionID, label)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(sess)
+		},
+	}
+
+	cmd.Flags().String("session", "", "Session ID (required)")
+	cmd.Flags().String("label", "", "Session label, up to 60 characters (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newSessionReplayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Reconstruct a session's timeline of prompts, tool calls, progress, and completions",
+		Long: `Replay collects the events hooks tagged with metadata.session_id, plus untagged
+events (CLI pushes, status changes) by the same agents between the session's first
+and last tagged event, and renders them in order.
+
+--format markdown prints a readable timeline; --format json returns the entries.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionID, _ := cmd.Flags().GetString("session")
+			format, _ := cmd.Flags().GetString("format")
+			limit, _ := cmd.Flags().GetInt("limit")
+			if sessionID == "" {
+				return cmdErr(errors.New("--session is required"))
+			}
+			if format != "json" && format != "markdown" {
+				return cmdErr(fmt.Errorf("invalid --format %q (valid: json, markdown)", format))
+			}
+
+			var replay *actions.SessionReplay
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				replay, err = actions.ReplaySession(cmd.Context(), db, sessionID, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			if format == "markdown" {
+				_, err := fmt.Fprint(cmd.OutOrStdout(), actions.RenderSessionReplayMarkdown(replay))
+				return err
+			}
+			return output.PrintSuccess(replay)
+		},
+	}
+
+	cmd.Flags().String("session", "", "Session ID from hook metadata (required)")
+	cmd.Flags().String("format", "json", "Output format: json|markdown")
+	cmd.Flags().Int("limit", 2000, "Max events to replay")
+	return cmd
+}
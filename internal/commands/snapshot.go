@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewSnapshotCmd creates the snapshot command: a file-level copy of the whole
+// database, read-only inspection of such copies, and named snapshots that can
+// be restored by scope.
+func NewSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Write a point-in-time copy of the database to a file",
+		Long: `snapshot --to-file writes a compact copy of the entire database with SQLite's
+VACUUM INTO. The copy is consistent as of one moment, does not block other agents,
+and can be opened with any SQLite client. The target file must not exist.
+
+Inspect a copy without touching the live database with 'snapshot mount'.
+
+Named snapshots ('snapshot create') are kept in <db dir>/snapshots, listed with
+'snapshot list', and rolled back to with 'snapshot restore'.`,
+		Example: `  vybe snapshot --to-file snap-2025-06-01.db
+  vybe snapshot mount --file snap-2025-06-01.db
+  vybe snapshot mount --file snap-2025-06-01.db --query "SELECT id, title, status FROM tasks"
+  vybe snapshot create --name before-refactor --request-id snap_1
+  vybe snapshot restore --id before-refactor --scope tasks --yes --request-id restore_1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _ := cmd.Flags().GetString("to-file")
+			if path == "" {
+				return cmdErr(errors.New("--to-file is required"))
+			}
+
+			var info *store.SnapshotFileInfo
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				info, err = actions.SnapshotToFile(cmd.Context(), db, path)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(info)
+		},
+	}
+
+	cmd.Flags().String("to-file", "", "Path of the snapshot file to write (must not exist)")
+	cmd.Annotations = map[string]string{"admin": "true"}
+	cmd.AddCommand(newSnapshotMountCmd())
+	cmd.AddCommand(newSnapshotCreateCmd())
+	cmd.AddCommand(newSnapshotListCmd())
+	cmd.AddCommand(newSnapshotRestoreCmd())
+	return cmd
+}
+
+func newSnapshotMountCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mount",
+		Short: "Open a snapshot file read-only and describe or query it",
+		Long: `mount opens a snapshot file read-only (no locks, no migrations) and reports its
+schema version, integrity check, row counts, and last event. --query runs one SQL
+statement against the file and returns up to 1000 rows; writes are rejected.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _ := cmd.Flags().GetString("file")
+			query, _ := cmd.Flags().GetString("query")
+			if path == "" {
+				return cmdErr(errors.New("--file is required"))
+			}
+
+			res, err := actions.SnapshotMount(cmd.Context(), path, query)
+			if err != nil {
+				return cmdErr(err)
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().String("file", "", "Snapshot file written by 'snapshot --to-file' (required)")
+	cmd.Flags().String("query", "", "Read-only SQL to run against the snapshot")
+	cmd.Annotations = map[string]string{"admin": "true"}
+	return cmd
+}
+
+// snapshotDir is where named snapshots are written: beside the database.
+func snapshotDir() (string, error) {
+	dbPath, err := app.GetDBPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), "snapshots"), nil
+}
+
+func newSnapshotCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Take a named snapshot that 'snapshot restore' can roll back to",
+		Long: `create writes a VACUUM INTO copy of the database to <db dir>/snapshots and
+registers it under --name, which must be unique. Take one before letting an
+agent loose on the task board; 'snapshot restore' rolls tasks or memory back to
+it. A snapshot_created event is recorded.`,
+		Example: `  vybe snapshot create --name before-refactor --request-id snap_1`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			if name == "" {
+				return cmdErr(errors.New("--name is required"))
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			dir, err := snapshotDir()
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			var res *store.NamedSnapshotResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				res, err = actions.SnapshotCreateIdempotent(cmd.Context(), db, agentName, requestID, dir, name)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(res)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Unique snapshot name (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newSnapshotListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List named snapshots, newest first",
+		Long: `list shows every snapshot taken with 'snapshot create'. missing is set when a
+snapshot's file has been deleted from disk; such snapshots cannot be restored.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var snapshots []*store.NamedSnapshot
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				snapshots, err = actions.SnapshotList(cmd.Context(), db)
+				return err
+			}); err != nil {
+				return err
+			}
+			type resp struct {
+				Count     int                    `json:"count"`
+				Snapshots []*store.NamedSnapshot `json:"snapshots"`
+			}
+			return output.PrintSuccess(resp{Count: len(snapshots), Snapshots: snapshots})
+		},
+	}
+}
+
+func newSnapshotRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Roll task or memory state back to a named snapshot",
+		Long: `restore replaces live state with the state in a named snapshot, in one
+transaction. --scope picks what is rolled back:
+
+  tasks   tasks with their dependencies, criteria, metadata, and artifacts;
+          tasks created after the snapshot are removed
+  memory  every memory entry
+  all     both
+
+The event log is append-only and is never rolled back: the restore is recorded
+as a snapshot_restored event after everything that happened since. Restored
+tasks get a new version, so an agent still holding an older one fails its next
+update instead of overwriting the restore.
+
+Work written after the snapshot is lost, so restore requires --yes (or a y at a
+terminal prompt); --backup-first copies the current database first.`,
+		Example: `  vybe snapshot restore --id before-refactor --scope tasks --yes --request-id restore_1
+  vybe snapshot restore --id snap_1717000000_ab12cd34ef56 --scope all --backup-first --yes --request-id restore_2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, _ := cmd.Flags().GetString("id")
+			scope, _ := cmd.Flags().GetString("scope")
+			if id == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			switch scope {
+			case store.SnapshotScopeTasks, store.SnapshotScopeMemory, store.SnapshotScopeAll:
+			case "":
+				return cmdErr(errors.New("--scope is required (tasks, memory, or all)"))
+			default:
+				return cmdErr(fmt.Errorf("invalid --scope %q (use tasks, memory, or all)", scope))
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			if err := confirmDestructive(cmd, fmt.Sprintf("restore %s state from snapshot %s", scope, id)); err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				*store.SnapshotRestoreResult
+				BackupPath string `json:"backup_path,omitempty"`
+			}
+			r := resp{}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if r.BackupPath, err = backupFirst(cmd, db, "snapshot-restore"); err != nil {
+					return err
+				}
+				r.SnapshotRestoreResult, err = actions.SnapshotRestoreIdempotent(cmd.Context(), db, agentName, requestID, id, scope)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Snapshot id or name (required)")
+	cmd.Flags().String("scope", "", "What to roll back: tasks, memory, or all (required)")
+	addDestructiveFlags(cmd)
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
+	return cmd
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewStatsCmd creates the stats command group: history of the task queue.
+func NewStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Task queue history recorded by the checkpoint hook",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newStatsHistoryCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newStatsHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show a project's queue depth (pending, in progress, blocked) per day",
+		Long: `history shows the daily queue snapshots the checkpoint hook records for each
+project: pending, in-progress, blocked, and completed task counts, plus event
+and memory counts. The last checkpoint of a day wins. delta compares the first
+and last snapshot in the window; a negative backlog delta means the queue is
+burning down. 'task stats' is the same counts right now.
+
+--format text prints a burndown chart.`,
+		Example: `  vybe stats history --project "$PWD" --days 30 --format text
+  vybe stats history --days 14 | jq '.data.snapshots[] | {day, tasks_pending, tasks_blocked}'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project")
+			days, _ := cmd.Flags().GetInt("days")
+			format, _ := cmd.Flags().GetString("format")
+
+			if format != "json" && format != "text" {
+				return cmdErr(fmt.Errorf("invalid --format %q (valid: json, text)", format))
+			}
+			if projectID == "" {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return cmdErr(fmt.Errorf("--project is required: %w", err))
+				}
+				projectID = resolveProjectID(cwd)
+			} else if filepath.IsAbs(projectID) {
+				projectID = resolveProjectID(filepath.Clean(projectID))
+			}
+
+			var trends *store.ProjectTrends
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				projectID, err := confinedProjectFilter(cmd, db, projectID)
+				if err != nil {
+					return err
+				}
+				trends, err = actions.ProjectTrends(cmd.Context(), db, projectID, days)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			if format == "text" {
+				_, err := fmt.Fprint(cmd.OutOrStdout(), actions.RenderBurndownText(trends))
+				return err
+			}
+			return output.PrintSuccess(trends)
+		},
+	}
+
+	cmd.Flags().String("project", "", "Project ID or directory (default: the current directory's project)")
+	cmd.Flags().Int("days", 30, "Window size in days")
+	cmd.Flags().String("format", "json", "Output format: json|text")
+	return cmd
+}
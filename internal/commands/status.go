@@ -36,25 +36,58 @@ func NewStatusCmd(root *cobra.Command) *cobra.Command {
 	return cmd
 }
 
-func runEventsMode(cmd *cobra.Command, all bool, taskID, kind string, since int64, limit int, asc, includeArchived bool) error {
+// eventsListOptions holds the flags shared by 'vybe events' and 'vybe events list'.
+type eventsListOptions struct {
+	all             bool
+	taskID          string
+	kind            string
+	meta            string
+	limit           int
+	since           int64
+	asc             bool
+	includeArchived bool
+}
+
+func bindEventsListFlags(cmd *cobra.Command, opts *eventsListOptions) {
+	cmd.Flags().BoolVar(&opts.all, "all", false, "List events across all agents (ignores --agent)")
+	cmd.Flags().StringVar(&opts.taskID, "task-id", "", "Filter events by task ID")
+	cmd.Flags().StringVar(&opts.kind, "kind", "", "Filter events by kind")
+	cmd.Flags().StringVar(&opts.meta, "meta", "", `Filter on metadata, e.g. 'exit_code>0 AND tool=Bash'`)
+	cmd.Flags().IntVar(&opts.limit, "limit", 50, "Max events to return")
+	cmd.Flags().Int64Var(&opts.since, "since-id", 0, "Only events with id > since-id")
+	cmd.Flags().BoolVar(&opts.asc, "asc", false, "Sort oldest first (default newest first)")
+	cmd.Flags().BoolVar(&opts.includeArchived, "include-archived", false, "Include archived events")
+}
+
+func runEventsMode(cmd *cobra.Command, opts eventsListOptions) error {
 	agentName := resolveActorName(cmd, "")
-	if all {
+	if opts.all {
 		agentName = ""
 	}
-	if !all && agentName == "" {
+	if !opts.all && agentName == "" {
 		return cmdErr(errors.New("agent is required unless --all is set (set --agent or VYBE_AGENT)"))
 	}
+	meta, err := store.ParseMetaFilter(opts.meta)
+	if err != nil {
+		return cmdErr(err)
+	}
 
 	var events []*models.Event
-	if err := withDB(func(db *DB) error {
-		ev, err := store.ListEvents(db, store.ListEventsParams{
+	if err := withDB(cmd.Context(), func(db *DB) error {
+		projectID, err := confinedProjectFilter(cmd, db, "")
+		if err != nil {
+			return err
+		}
+		ev, err := store.ListEvents(cmd.Context(), db, store.ListEventsParams{
 			AgentName:       agentName,
-			TaskID:          taskID,
-			Kind:            kind,
-			SinceID:         since,
-			Limit:           limit,
-			Desc:            !asc,
-			IncludeArchived: includeArchived,
+			ProjectID:       projectID,
+			TaskID:          opts.taskID,
+			Kind:            opts.kind,
+			SinceID:         opts.since,
+			Limit:           opts.limit,
+			Desc:            !opts.asc,
+			IncludeArchived: opts.includeArchived,
+			Meta:            meta,
 		})
 		if err != nil {
 			return err
@@ -69,15 +102,17 @@ func runEventsMode(cmd *cobra.Command, all bool, taskID, kind string, since int6
 		Agent  string          `json:"agent,omitempty"`
 		TaskID string          `json:"task_id,omitempty"`
 		Kind   string          `json:"kind,omitempty"`
+		Meta   string          `json:"meta,omitempty"`
 		Since  int64           `json:"since_id,omitempty"`
 		Count  int             `json:"count"`
 		Events []*models.Event `json:"events"`
 	}
 	return output.PrintSuccess(resp{
 		Agent:  agentName,
-		TaskID: taskID,
-		Kind:   kind,
-		Since:  since,
+		TaskID: opts.taskID,
+		Kind:   opts.kind,
+		Meta:   opts.meta,
+		Since:  opts.since,
 		Count:  len(events),
 		Events: events,
 	})
@@ -111,14 +146,14 @@ func runSchemaMode(root *cobra.Command) error {
 	return output.PrintSuccess(resp{Commands: schemas, AgentProtocol: protocol})
 }
 
-func runArtifactsMode(taskID string, limit int) error {
+func runArtifactsMode(ctx context.Context, taskID string, limit int) error {
 	if taskID == "" {
 		return cmdErr(errors.New("--task-id is required"))
 	}
 
 	var artifacts []*models.Artifact
-	if err := withDB(func(db *DB) error {
-		a, err := actions.ArtifactListByTask(db, taskID, limit)
+	if err := withDB(ctx, func(db *DB) error {
+		a, err := actions.ArtifactListByTask(ctx, db, taskID, limit)
 		if err != nil {
 			return err
 		}
@@ -165,7 +200,7 @@ func runDefaultStatus(cmd *cobra.Command, check bool) error {
 		},
 	}
 
-	db, err := store.OpenDB(dbPath)
+	db, err := store.OpenDB(cmd.Context(), dbPath)
 	if err != nil {
 		result.DB.OK = false
 		result.DB.Error = err.Error()
@@ -182,14 +217,14 @@ func runDefaultStatus(cmd *cobra.Command, check bool) error {
 
 	agentName := resolveActorName(cmd, "")
 	if agentName != "" {
-		if state, err := store.LoadOrCreateAgentState(db, agentName); err == nil {
+		if state, err := store.LoadOrCreateAgentState(cmd.Context(), db, agentName); err == nil {
 			result.AgentState = state
 		}
 	}
 
 	if check {
 		var one int
-		qErr := db.QueryRowContext(context.Background(), "SELECT 1").Scan(&one)
+		qErr := db.QueryRowContext(cmd.Context(), "SELECT 1").Scan(&one)
 		qOK := qErr == nil
 		result.QueryOK = &qOK
 		if !qOK {
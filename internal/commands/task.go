@@ -4,10 +4,12 @@ import (
 	"errors"
 	"path/filepath"
 	"slices"
+	"time"
 
 	"github.com/dotcommander/vybe/internal/actions"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +19,13 @@ type taskCmdResult struct {
 	EventID int64        `json:"event_id"`
 }
 
+// taskBeginResponse is the response of task begin.
+type taskBeginResponse struct {
+	Task          *models.Task `json:"task"`
+	StatusEventID int64        `json:"status_event_id,omitempty"`
+	FocusEventID  int64        `json:"focus_event_id"`
+}
+
 // requireMutationParams resolves the agent name and request ID required for all
 // mutating commands. It returns a cmdErr-wrapped error ready to return from RunE.
 func requireMutationParams(cmd *cobra.Command) (agentName, requestID string, err error) {
@@ -40,7 +49,7 @@ func runTaskCmd(cmd *cobra.Command, fn func(db *DB, agentName, requestID string)
 	}
 
 	var result taskCmdResult
-	if err := withDB(func(db *DB) error {
+	if err := withDB(cmd.Context(), func(db *DB) error {
 		r, err := fn(db, agentName, requestID)
 		if err != nil {
 			return err
@@ -65,9 +74,30 @@ func NewTaskCmd() *cobra.Command {
 
 	cmd.AddCommand(newTaskCreateCmd())
 	cmd.AddCommand(newTaskBeginCmd())
+	cmd.AddCommand(newTaskClaimCmd())
 	cmd.AddCommand(newTaskSetStatusCmd())
+	cmd.AddCommand(newTaskCompleteCmd())
+	cmd.AddCommand(newTaskFailCmd())
+	cmd.AddCommand(newTaskDelegateCmd())
+	cmd.AddCommand(newTaskFailuresCmd())
 	cmd.AddCommand(newTaskGetCmd())
+	cmd.AddCommand(newTaskBriefCmd())
+	cmd.AddCommand(newTaskWaitCmd())
 	cmd.AddCommand(newTaskListCmd())
+	cmd.AddCommand(newTaskStatsCmd())
+	cmd.AddCommand(newTaskContentionCmd())
+	cmd.AddCommand(newTaskCriteriaCmd())
+	cmd.AddCommand(newTaskMetaCmd())
+	cmd.AddCommand(newTaskTagCmd())
+	cmd.AddCommand(newTaskUpdateCmd())
+	cmd.AddCommand(newTaskNextCmd())
+	cmd.AddCommand(newTaskGraphCmd())
+	cmd.AddCommand(newTaskAddDepCmd())
+	cmd.AddCommand(newTaskSuggestDepsCmd())
+	cmd.AddCommand(newTaskImportCmd())
+	cmd.AddCommand(newTaskSweepCmd())
+	cmd.AddCommand(newTaskStaleCmd())
+	cmd.AddCommand(newTaskDeleteCmd())
 
 	namespaceIndex(cmd)
 	return cmd
@@ -82,13 +112,35 @@ func newTaskCreateCmd() *cobra.Command {
 			desc, _ := cmd.Flags().GetString("desc")
 			projectID, _ := cmd.Flags().GetString("project-id")
 			priority, _ := cmd.Flags().GetInt("priority")
+			dueRaw, _ := cmd.Flags().GetString("due")
+			sizeRaw, _ := cmd.Flags().GetString("size")
+			tags, _ := cmd.Flags().GetStringArray("tag")
+			requires, _ := cmd.Flags().GetStringSlice("requires")
 
 			if title == "" {
 				return cmdErr(errors.New("--title is required"))
 			}
+			if !cmd.Flags().Changed("priority") {
+				// The target project's own file governs, not the caller's directory.
+				if p, ok := projectConfigFor(projectID).DefaultPriority(); ok {
+					priority = p
+				}
+			}
+			size, err := actions.ParseTaskSize(sizeRaw)
+			if err != nil {
+				return cmdErr(err)
+			}
+			opts := actions.TaskCreateOptions{Size: size, Tags: tags, Requires: requires}
+			if dueRaw != "" {
+				due, err := actions.ParseDue(dueRaw, time.Now())
+				if err != nil {
+					return cmdErr(err)
+				}
+				opts.DueAt = due
+			}
 
 			return runTaskCmd(cmd, func(db *DB, agentName, requestID string) (taskCmdResult, error) {
-				t, eid, err := actions.TaskCreateIdempotent(db, agentName, requestID, title, desc, projectID, priority)
+				t, eid, err := actions.TaskCreateWithOptionsIdempotent(cmd.Context(), db, agentName, requestID, title, desc, projectID, priority, opts)
 				return taskCmdResult{Task: t, EventID: eid}, err
 			})
 		},
@@ -97,12 +149,28 @@ func newTaskCreateCmd() *cobra.Command {
 	cmd.Flags().String("title", "", "Task title (required)")
 	cmd.Flags().String("desc", "", "Task description")
 	cmd.Flags().String("project-id", "", "Project ID to associate task with")
-	cmd.Flags().Int("priority", 0, "Task priority (higher = more urgent, default 0)")
+	cmd.Flags().Int("priority", 0, "Task priority (higher = more urgent; default: default_priority in the project's .vybe.toml, else 0)")
+	cmd.Flags().String("due", "", dueFlagHelp)
+	cmd.Flags().String("size", "", sizeFlagHelp)
+	cmd.Flags().StringArray("tag", nil, "Tag the task (repeatable)")
+	cmd.Flags().StringSlice("requires", nil, "Capabilities an agent must have registered to be routed this task (comma-separated)")
 
 	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
 	return cmd
 }
 
+// ifVersionFlagHelp documents --if-version on task mutations.
+const ifVersionFlagHelp = "Fail with STALE_VERSION unless the task is at this version (see task get)"
+
+// ifVersionFlag returns --if-version when it was passed, nil otherwise.
+func ifVersionFlag(cmd *cobra.Command) *int {
+	if !cmd.Flags().Changed("if-version") {
+		return nil
+	}
+	version, _ := cmd.Flags().GetInt("if-version")
+	return &version
+}
+
 // newTaskSetStatusCmd updates task status
 func newTaskSetStatusCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -113,6 +181,8 @@ func newTaskSetStatusCmd() *cobra.Command {
 			taskID, _ := cmd.Flags().GetString("id")
 			status, _ := cmd.Flags().GetString("status")
 			blockedReason, _ := cmd.Flags().GetString("blocked-reason")
+			strict, _ := cmd.Flags().GetBool("strict")
+			ifVersion := ifVersionFlag(cmd)
 
 			if taskID == "" {
 				return cmdErr(errors.New("--id is required"))
@@ -122,7 +192,11 @@ func newTaskSetStatusCmd() *cobra.Command {
 			}
 
 			return runTaskCmd(cmd, func(db *DB, agentName, requestID string) (taskCmdResult, error) {
-				t, eid, err := actions.TaskSetStatusIdempotent(db, agentName, requestID, taskID, status, blockedReason)
+				t, eid, err := actions.TaskSetStatusWithOptionsIdempotent(cmd.Context(), db, agentName, requestID, taskID, status, actions.TaskStatusOptions{
+					BlockedReason: blockedReason,
+					Strict:        strict,
+					IfVersion:     ifVersion,
+				})
 				return taskCmdResult{Task: t, EventID: eid}, err
 			})
 		},
@@ -131,6 +205,8 @@ func newTaskSetStatusCmd() *cobra.Command {
 	cmd.Flags().String("id", "", "Task ID (required)")
 	cmd.Flags().String("status", "", "New status (required): pending|in_progress|completed|blocked")
 	cmd.Flags().String("blocked-reason", "", "Reason for blocking (used with --status=blocked)")
+	cmd.Flags().Bool("strict", false, "Refuse --status=completed while acceptance criteria are unchecked")
+	cmd.Flags().Int("if-version", 0, ifVersionFlagHelp)
 
 	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
 	return cmd
@@ -152,20 +228,15 @@ func newTaskBeginCmd() *cobra.Command {
 			}
 
 			var result *actions.TaskStartResult
-			if err := withDB(func(db *DB) error {
+			if err := withDB(cmd.Context(), func(db *DB) error {
 				var startErr error
-				result, startErr = actions.TaskStartIdempotent(db, agentName, requestID, taskID)
+				result, startErr = actions.TaskStartIdempotent(cmd.Context(), db, agentName, requestID, taskID)
 				return startErr
 			}); err != nil {
 				return err
 			}
 
-			type resp struct {
-				Task          *models.Task `json:"task"`
-				StatusEventID int64        `json:"status_event_id,omitempty"`
-				FocusEventID  int64        `json:"focus_event_id"`
-			}
-			return output.PrintSuccess(resp{Task: result.Task, StatusEventID: result.StatusEventID, FocusEventID: result.FocusEventID})
+			return output.PrintSuccess(taskBeginResponse{Task: result.Task, StatusEventID: result.StatusEventID, FocusEventID: result.FocusEventID})
 		},
 	}
 
@@ -185,19 +256,25 @@ func newTaskListCmd() *cobra.Command {
 			priorityFilter, _ := cmd.Flags().GetInt("priority")
 			full, _ := cmd.Flags().GetBool("full")
 			limit, _ := cmd.Flags().GetInt("limit")
+			metaFilters, _ := cmd.Flags().GetStringArray("meta")
+			tags, _ := cmd.Flags().GetStringArray("tag")
 
 			// --project-dir takes precedence over --project-id.
 			// It resolves the directory path to the project_id stored in the DB.
 			if projectDir != "" && projectFilter == "" {
 				absDir, err := filepath.Abs(projectDir)
 				if err == nil {
-					projectFilter = absDir
+					projectFilter = resolveProjectID(absDir)
 				}
 			}
 
 			var tasks []*models.Task
-			if err := withDB(func(db *DB) error {
-				t, err := actions.TaskList(db, statusFilter, projectFilter, priorityFilter)
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				projectFilter, err := confinedProjectFilter(cmd, db, projectFilter)
+				if err != nil {
+					return err
+				}
+				t, err := actions.TaskListTagged(cmd.Context(), db, statusFilter, projectFilter, priorityFilter, metaFilters, tags)
 				if err != nil {
 					return err
 				}
@@ -208,11 +285,7 @@ func newTaskListCmd() *cobra.Command {
 			}
 
 			if full {
-				type fullResp struct {
-					Count int            `json:"count"`
-					Tasks []*models.Task `json:"tasks"`
-				}
-				return output.PrintSuccess(fullResp{Count: len(tasks), Tasks: tasks})
+				return output.PrintSuccess(taskListFullResponse{Count: len(tasks), Tasks: tasks})
 			}
 
 			return printTaskSummary(tasks, limit)
@@ -225,17 +298,37 @@ func newTaskListCmd() *cobra.Command {
 	cmd.Flags().Int("priority", -1, "Filter by exact priority (default -1 = no filter)")
 	cmd.Flags().Bool("full", false, "Output full task objects (warning: can be very large)")
 	cmd.Flags().Int("limit", 20, "Max pending/in_progress tasks to include in summary")
+	cmd.Flags().StringArray("meta", nil, "Filter by metadata key=value (repeatable; all must match)")
+	cmd.Flags().StringArray("tag", nil, "Filter by tag (repeatable; all must match)")
 
 	return cmd
 }
 
 // taskSummaryItem is a lightweight task representation for summary mode.
 type taskSummaryItem struct {
-	ID        string `json:"id"`
-	Title     string `json:"title"`
-	Status    string `json:"status"`
-	Priority  int    `json:"priority"`
-	ProjectID string `json:"project_id,omitempty"`
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Status    string          `json:"status"`
+	Priority  int             `json:"priority"`
+	ProjectID string          `json:"project_id,omitempty"`
+	DueAt     *time.Time      `json:"due_at,omitempty"`
+	Overdue   bool            `json:"overdue,omitempty"`
+	Size      models.TaskSize `json:"size,omitempty"`
+	Tags      []string        `json:"tags,omitempty"`
+}
+
+// taskListSummaryResponse is the default task list response.
+type taskListSummaryResponse struct {
+	Total  int               `json:"total"`
+	Counts map[string]int    `json:"counts"`
+	Shown  int               `json:"shown"`
+	Tasks  []taskSummaryItem `json:"tasks"`
+}
+
+// taskListFullResponse is the task list --full response.
+type taskListFullResponse struct {
+	Count int            `json:"count"`
+	Tasks []*models.Task `json:"tasks"`
 }
 
 // printTaskSummary outputs a compact summary: status counts + recent non-completed tasks.
@@ -261,6 +354,7 @@ func printTaskSummary(tasks []*models.Task, limit int) error {
 		active = active[:limit]
 	}
 
+	now := time.Now()
 	items := make([]taskSummaryItem, len(active))
 	for i, t := range active {
 		items[i] = taskSummaryItem{
@@ -269,16 +363,14 @@ func printTaskSummary(tasks []*models.Task, limit int) error {
 			Status:    string(t.Status),
 			Priority:  t.Priority,
 			ProjectID: t.ProjectID,
+			DueAt:     t.DueAt,
+			Overdue:   t.IsOverdue(now),
+			Size:      t.Size,
+			Tags:      t.Tags,
 		}
 	}
 
-	type summaryResp struct {
-		Total   int              `json:"total"`
-		Counts  map[string]int   `json:"counts"`
-		Shown   int              `json:"shown"`
-		Tasks   []taskSummaryItem `json:"tasks"`
-	}
-	return output.PrintSuccess(summaryResp{
+	return output.PrintSuccess(taskListSummaryResponse{
 		Total:  len(tasks),
 		Counts: counts,
 		Shown:  len(items),
@@ -286,6 +378,38 @@ func printTaskSummary(tasks []*models.Task, limit int) error {
 	})
 }
 
+func newTaskContentionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "contention",
+		Short: "Report task claim contention per agent with backoff guidance",
+		Long: `Contention summarizes task claims (task begin) per agent: claim count, average
+wait from task creation to claim, overlaps (another agent already had the task
+as its focus), lost version races, and claims each agent lost to lease expiry
+(lease_expired) or to another agent's steal (stolen). Use it to tune loop
+cooldowns, retry backoff, and claim leases.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sinceDays, _ := cmd.Flags().GetInt("since-days")
+
+			var report *store.ContentionReport
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.TaskContention(cmd.Context(), db, sinceDays)
+				if err != nil {
+					return err
+				}
+				report = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(report)
+		},
+	}
+
+	cmd.Flags().Int("since-days", 7, "Report window in days")
+	return cmd
+}
+
 func newTaskGetCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "get",
@@ -298,8 +422,11 @@ func newTaskGetCmd() *cobra.Command {
 			}
 
 			var task *models.Task
-			if err := withDB(func(db *DB) error {
-				t, err := actions.TaskGet(db, taskID)
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				if err := actions.CheckConfinedTask(cmd.Context(), db, resolveActorName(cmd, ""), taskID); err != nil {
+					return err
+				}
+				t, err := actions.TaskGet(cmd.Context(), db, taskID)
 				if err != nil {
 					return err
 				}
@@ -317,3 +444,51 @@ func newTaskGetCmd() *cobra.Command {
 
 	return cmd
 }
+
+func newTaskDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a task (requires --yes)",
+		Long: `delete removes a task and its dependencies, criteria, and metadata. Events that
+referenced the task are kept. Non-interactive callers must pass --yes; on a terminal
+you are prompted instead. --backup-first snapshots the database before deleting.`,
+		Example: `  vybe task delete --id task_123 --yes --backup-first`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			if err := confirmDestructive(cmd, "delete task "+taskID); err != nil {
+				return cmdErr(err)
+			}
+
+			type resp struct {
+				TaskID     string `json:"task_id"`
+				EventID    int64  `json:"event_id"`
+				BackupPath string `json:"backup_path,omitempty"`
+			}
+			r := resp{TaskID: taskID}
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				if r.BackupPath, err = backupFirst(cmd, db, "task-delete"); err != nil {
+					return err
+				}
+				r.EventID, err = actions.TaskDeleteIdempotent(cmd.Context(), db, agentName, requestID, taskID)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	addDestructiveFlags(cmd)
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+)
+
+func newTaskBriefCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "brief",
+		Short: "Show the brief packet for a specific task",
+		Long: `Brief builds the same packet as 'vybe brief' for the given task and its project,
+whatever the agent's focus is. It is read-only: focus and the event cursor are
+untouched. With --format markdown it prints a document ready to paste into an
+agent that has no vybe hooks.`,
+		Example: `  vybe task brief --id task_123 --format markdown
+  vybe task brief --id task_123 --max-tokens 1500`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			format, _ := cmd.Flags().GetString("format")
+			maxTokens, _ := cmd.Flags().GetInt("max-tokens")
+			agentMemory, _ := cmd.Flags().GetBool("include-agent-memory")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			agentName, err := requireActorName(cmd, "")
+			if err != nil {
+				return cmdErr(err)
+			}
+			return runBriefMode(cmd, agentName, format, actions.BriefOptions{
+				TaskID: taskID, MaxTokens: maxTokens, IncludeAgentMemory: agentMemory,
+			})
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().String("format", "json", "Output format: json|markdown")
+	cmd.Flags().Int("max-tokens", 0, "Trim the brief to this approximate token budget (0 = unlimited)")
+	cmd.Flags().Bool("include-agent-memory", false, "Merge this agent's own agent-scoped memory into the brief (default: brief.include_agent_memory from config)")
+	return cmd
+}
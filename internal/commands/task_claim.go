@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// addQueueFilterFlags registers the --tag and --min-priority flags shared by
+// commands that pick work from a slice of the queue.
+func addQueueFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("tag", nil, "Only pick tasks carrying this tag (repeatable; all must match)")
+	cmd.Flags().Int("min-priority", 0, "Only pick tasks whose effective priority is at least this")
+}
+
+// queueFilterFromFlags reads the flags added by addQueueFilterFlags. The
+// priority floor applies only when --min-priority was given.
+func queueFilterFromFlags(cmd *cobra.Command) store.FocusFilter {
+	var filter store.FocusFilter
+	filter.Tags, _ = cmd.Flags().GetStringArray("tag")
+	if cmd.Flags().Changed("min-priority") {
+		minPriority, _ := cmd.Flags().GetInt("min-priority")
+		filter.MinPriority = &minPriority
+	}
+	return filter
+}
+
+// taskClaimResponse is the response of task claim.
+type taskClaimResponse struct {
+	Task          *models.Task     `json:"task"`
+	StatusEventID int64            `json:"status_event_id,omitempty"`
+	FocusEventID  int64            `json:"focus_event_id,omitempty"`
+	Lease         *store.TaskLease `json:"lease,omitempty"`
+	StolenFrom    string           `json:"stolen_from,omitempty"`
+	StolenEventID int64            `json:"stolen_event_id,omitempty"`
+}
+
+func newTaskClaimCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "claim",
+		Short: "Claim the next pending task matching filters: set it in_progress and focus it",
+		Long: `Claim picks the next pending task in resume's order and begins it (in_progress
+plus agent focus) in one transaction, so concurrent claimers never get the same
+task. --project, --tag, and --min-priority narrow the pick to a slice of the
+queue; --min-priority compares against effective priority, so a task blocking
+higher-priority work qualifies. With nothing matching, task is null. Claims
+count toward the tasks_per_day limit (see vybe limits).
+
+A claim is leased: once --lease passes with no activity on the task (no update
+and no event), memory gc and the checkpoint hook return it to pending and log a
+task_lease_expired event. Without --lease the task's lease (task update
+--lease) applies, then the agent's (agent lease), then claim_lease in config
+(default 1h; "off" leaves claims unleased).
+
+With --steal-stale and nothing pending, claim takes over the in_progress task
+(passing the same filters) whose lease expired longest ago, without waiting for
+gc: the prior owner loses its focus and gets an inbox notice, a task_stolen
+event names it, and stolen_from is set in the response.`,
+		Example: `  vybe task claim --tag bugfix --project-dir "$PWD" --request-id claim_1
+  vybe task claim --min-priority 5 --request-id claim_2 | jq -r '.data.task.id // empty'
+  vybe task claim --lease 30m --request-id claim_3 | jq '.data.lease'
+  vybe task claim --steal-stale --request-id claim_4 | jq '{task: .data.task.id, stolen_from: .data.stolen_from}'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			if projectDir != "" && projectID == "" {
+				abs, err := filepath.Abs(projectDir)
+				if err != nil {
+					return cmdErr(errors.New("invalid --project-dir"))
+				}
+				projectID = resolveProjectID(abs)
+			}
+			filter := queueFilterFromFlags(cmd)
+			overrideLimits, _ := cmd.Flags().GetBool("override-limits")
+			leaseRaw, _ := cmd.Flags().GetString("lease")
+			lease, err := actions.ParseLease(leaseRaw)
+			if err != nil {
+				return cmdErr(err)
+			}
+			steal, _ := cmd.Flags().GetBool("steal-stale")
+			opts := store.ClaimOptions{Lease: lease, StealStale: steal}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *actions.TaskStartResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var claimErr error
+				result, claimErr = actions.TaskClaimIdempotent(cmd.Context(), db, agentName, requestID, projectID, filter, overrideLimits, opts)
+				return claimErr
+			}); err != nil {
+				return err
+			}
+
+			return output.PrintSuccess(taskClaimResponse{Task: result.Task, StatusEventID: result.StatusEventID,
+				FocusEventID: result.FocusEventID, Lease: result.Lease, StolenFrom: result.StolenFrom, StolenEventID: result.StolenEventID})
+		},
+	}
+
+	cmd.Flags().String("project", "", "Only pick tasks in this project ID")
+	cmd.Flags().String("project-dir", "", "Only pick tasks in this project directory (resolves to project_id)")
+	addQueueFilterFlags(cmd)
+	cmd.Flags().Bool("override-limits", false, "Claim even when the tasks_per_day limit is reached")
+	cmd.Flags().String("lease", "", "How long the claim holds the task without activity (e.g. 30m, 4h)")
+	cmd.Flags().Bool("steal-stale", false, "With nothing pending, take over an in_progress task whose lease expired")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+func newTaskCriteriaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "criteria",
+		Short: "Manage per-task acceptance criteria checklists",
+		Long: `Acceptance criteria are checklist items shown in the brief under the task.
+Use task set-status --status=completed --strict (or push task_status.strict) to
+refuse completion while any item is unchecked.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newTaskCriteriaAddCmd())
+	cmd.AddCommand(newTaskCriteriaCheckCmd())
+	cmd.AddCommand(newTaskCriteriaListCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+type criterionCmdResult struct {
+	Criterion *models.TaskCriterion `json:"criterion"`
+	EventID   int64                 `json:"event_id"`
+}
+
+func newTaskCriteriaAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add an acceptance criterion to a task",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			text, _ := cmd.Flags().GetString("text")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if text == "" {
+				return cmdErr(errors.New("--text is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result criterionCmdResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				c, eid, err := actions.TaskCriteriaAddIdempotent(cmd.Context(), db, agentName, requestID, taskID, text)
+				if err != nil {
+					return err
+				}
+				result = criterionCmdResult{Criterion: c, EventID: eid}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().String("text", "", "Criterion text (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newTaskCriteriaCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Mark an acceptance criterion done (--undo to reopen)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			criterionID, _ := cmd.Flags().GetInt64("criterion")
+			undo, _ := cmd.Flags().GetBool("undo")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if criterionID <= 0 {
+				return cmdErr(errors.New("--criterion is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result criterionCmdResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				c, eid, err := actions.TaskCriteriaCheckIdempotent(cmd.Context(), db, agentName, requestID, taskID, criterionID, !undo)
+				if err != nil {
+					return err
+				}
+				result = criterionCmdResult{Criterion: c, EventID: eid}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().Int64("criterion", 0, "Criterion ID (required)")
+	cmd.Flags().Bool("undo", false, "Mark the criterion not done")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newTaskCriteriaListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a task's acceptance criteria",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+
+			type resp struct {
+				TaskID   string                 `json:"task_id"`
+				Criteria []models.TaskCriterion `json:"criteria"`
+				Open     int                    `json:"open"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				cs, err := actions.TaskCriteriaList(cmd.Context(), db, taskID)
+				if err != nil {
+					return err
+				}
+				r = resp{TaskID: taskID, Criteria: cs}
+				for _, c := range cs {
+					if !c.Done {
+						r.Open++
+					}
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	return cmd
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// taskDelegateResponse is the data task delegate prints.
+type taskDelegateResponse struct {
+	Delegation *store.TaskDelegation `json:"delegation"`
+	EventID    int64                 `json:"event_id"`
+}
+
+func newTaskDelegateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delegate",
+		Short: "Record that a task was handed to a sub-agent",
+		Long: `Delegate records that the agent handed a task to a named sub-agent (the
+sub-agent type, e.g. quality-agent). When Claude Code starts a sub-agent of
+that type, the SubagentStart hook attaches it to the oldest waiting delegation
+and logs subagent_started on the task; SubagentStop logs subagent_stopped and
+marks the delegation finished.
+
+The delegating agent's brief lists its delegations (delegated, running, or
+finished) with each task's current status. The task's own status is not
+changed; the sub-agent or the parent still completes it.`,
+		Example: `  vybe task delegate --id task_123 --to-subagent quality-agent`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			subagent, _ := cmd.Flags().GetString("to-subagent")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if subagent == "" {
+				return cmdErr(errors.New("--to-subagent is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var resp taskDelegateResponse
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				d, eventID, err := actions.TaskDelegateIdempotent(cmd.Context(), db, agentName, requestID, taskID, subagent)
+				if err != nil {
+					return err
+				}
+				resp = taskDelegateResponse{Delegation: d, EventID: eventID}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(resp)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().String("to-subagent", "", "Sub-agent type the task is handed to (required)")
+
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+const (
+	dueFlagHelp  = "Deadline: RFC3339, YYYY-MM-DD (end of day UTC), or a duration from now like 3d"
+	sizeFlagHelp = "Effort size: xs|s|m|l|xl (1, 2, 3, 5, 8 capacity points)"
+)
+
+func newTaskUpdateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update task fields (--due, --size, --lease)",
+		Long: `update changes a task's deadline, effort size, or default claim lease. --lease
+suits known long jobs: every claim of the task is leased that long regardless
+of the claiming agent's default (an explicit task claim --lease still wins).`,
+		Example: `  vybe task update --id "$TASK_ID" --due 3d --size m --request-id upd-1
+  vybe task update --id "$TASK_ID" --lease 6h --request-id upd-2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			opts := actions.TaskUpdateOptions{IfVersion: ifVersionFlag(cmd)}
+			if cmd.Flags().Changed("due") {
+				dueRaw, _ := cmd.Flags().GetString("due")
+				due, err := actions.ParseDue(dueRaw, time.Now())
+				if err != nil {
+					return cmdErr(err)
+				}
+				opts.SetDue, opts.DueAt = true, due
+			}
+			if cmd.Flags().Changed("size") {
+				sizeRaw, _ := cmd.Flags().GetString("size")
+				size, err := actions.ParseTaskSize(sizeRaw)
+				if err != nil {
+					return cmdErr(err)
+				}
+				opts.SetSize, opts.Size = true, size
+			}
+			if cmd.Flags().Changed("lease") {
+				leaseRaw, _ := cmd.Flags().GetString("lease")
+				lease, err := actions.ParseLease(leaseRaw)
+				if err != nil {
+					return cmdErr(err)
+				}
+				opts.SetLease, opts.Lease = true, lease
+			}
+			if !opts.SetDue && !opts.SetSize && !opts.SetLease {
+				return cmdErr(errors.New("nothing to update: pass --due, --size, or --lease"))
+			}
+
+			return runTaskCmd(cmd, func(db *DB, agentName, requestID string) (taskCmdResult, error) {
+				t, eid, err := actions.TaskUpdateIdempotent(cmd.Context(), db, agentName, requestID, taskID, opts)
+				return taskCmdResult{Task: t, EventID: eid}, err
+			})
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().String("due", "", dueFlagHelp+"; none clears it")
+	cmd.Flags().String("size", "", sizeFlagHelp+"; none clears it")
+	cmd.Flags().String("lease", "", "Default claim lease for this task (e.g. 6h); none clears it")
+	cmd.Flags().Int("if-version", 0, ifVersionFlagHelp)
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+// nextTaskItem is a pending task as listed by task next. EffectivePriority and
+// InheritedFrom are set when a higher-priority task waiting on this one raised
+// its rank.
+type nextTaskItem struct {
+	ID                string          `json:"id"`
+	Title             string          `json:"title"`
+	Priority          int             `json:"priority"`
+	EffectivePriority int             `json:"effective_priority,omitempty"`
+	InheritedFrom     string          `json:"inherited_from,omitempty"`
+	ProjectID         string          `json:"project_id,omitempty"`
+	DueAt             *time.Time      `json:"due_at,omitempty"`
+	Overdue           bool            `json:"overdue,omitempty"`
+	Size              models.TaskSize `json:"size,omitempty"`
+	Tags              []string        `json:"tags,omitempty"`
+}
+
+func newTaskNextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "next",
+		Short: "List the next pending tasks to pick up, overdue first",
+		Long: `Next lists pending tasks in pick order: overdue tasks first, then by
+effective priority. A task blocking a higher-priority task inherits that
+priority; such tasks report effective_priority and inherited_from. --tag and
+--min-priority narrow the list the same way they narrow task claim.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project-id")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			limit, _ := cmd.Flags().GetInt("limit")
+			filter := queueFilterFromFlags(cmd)
+			if projectDir != "" && projectID == "" {
+				if abs, err := filepath.Abs(projectDir); err == nil {
+					projectID = resolveProjectID(abs)
+				}
+			}
+
+			var tasks []*models.Task
+			var inherited map[string]store.InheritedPriority
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				projectID, err := confinedProjectFilter(cmd, db, projectID)
+				if err != nil {
+					return err
+				}
+				t, err := actions.TaskNext(cmd.Context(), db, projectID, limit, filter)
+				if err != nil {
+					return err
+				}
+				tasks = t
+				ids := make([]string, len(t))
+				for i, task := range t {
+					ids[i] = task.ID
+				}
+				inherited, err = actions.TaskInheritedPriorities(cmd.Context(), db, ids)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			items := make([]nextTaskItem, len(tasks))
+			overdue := 0
+			for i, t := range tasks {
+				items[i] = nextTaskItem{
+					ID: t.ID, Title: t.Title, Priority: t.Priority, ProjectID: t.ProjectID,
+					DueAt: t.DueAt, Overdue: t.IsOverdue(now), Size: t.Size, Tags: t.Tags,
+				}
+				if ip, ok := inherited[t.ID]; ok {
+					items[i].EffectivePriority = ip.Effective
+					items[i].InheritedFrom = ip.From
+				}
+				if items[i].Overdue {
+					overdue++
+				}
+			}
+
+			type resp struct {
+				Overdue int            `json:"overdue"`
+				Tasks   []nextTaskItem `json:"tasks"`
+			}
+			return output.PrintSuccess(resp{Overdue: overdue, Tasks: items})
+		},
+	}
+
+	cmd.Flags().String("project-id", "", "Restrict to a project ID")
+	cmd.Flags().String("project-dir", "", "Restrict to a project directory path (resolves to project_id)")
+	cmd.Flags().Int("limit", 5, "Max tasks to return")
+	addQueueFilterFlags(cmd)
+	return cmd
+}
+
+func newTaskSweepCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sweep",
+		Short: "Emit task_overdue events for tasks past their deadline",
+		Long: `Sweep is a maintenance command for cron, loops, or hooks. Each unfinished task
+that has passed its deadline gets exactly one task_overdue event; moving the
+deadline with task update re-arms it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project-id")
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var notices []store.OverdueNotice
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				n, err := actions.TaskSweepIdempotent(cmd.Context(), db, agentName, requestID, projectID)
+				notices = n
+				return err
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Count   int                   `json:"count"`
+				Overdue []store.OverdueNotice `json:"overdue"`
+			}
+			return output.PrintSuccess(resp{Count: len(notices), Overdue: notices})
+		},
+	}
+
+	cmd.Flags().String("project-id", "", "Restrict to a project ID")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newTaskFailCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fail",
+		Short: "Record a structured failure on a task and block it",
+		Long: `Records why an attempt at a task failed: the reason and an error class are
+added to the task's failure history (see task failures), its attempts count
+goes up, and a task_failed event is written. The task is then failure-blocked,
+so resume moves on to other work.
+
+Error classes are short tokens that group failures across tasks, for example
+test_failure, build_error, env:network, or missing_context. The loop records
+timeout and incomplete for tasks it blocks.`,
+		Example: `  vybe task fail --id task_123 --reason "integration tests need a running postgres" --error-class env:database`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			reason, _ := cmd.Flags().GetString("reason")
+			errorClass, _ := cmd.Flags().GetString("error-class")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if reason == "" {
+				return cmdErr(errors.New("--reason is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *actions.TaskFailResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.TaskFailWithOptionsIdempotent(cmd.Context(), db, agentName, requestID, taskID, reason, errorClass, actions.TaskFailOptions{
+					IfVersion: ifVersionFlag(cmd),
+				})
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().String("reason", "", "What went wrong (required)")
+	cmd.Flags().String("error-class", "", "Failure category, e.g. test_failure or env:network")
+	cmd.Flags().Int("if-version", 0, ifVersionFlagHelp)
+
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newTaskFailuresCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "failures",
+		Short:   "Show a task's failure history, newest first",
+		Example: `  vybe task failures --id task_123`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			limit, _ := cmd.Flags().GetInt("limit")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+
+			var failures []store.TaskFailure
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				f, err := actions.TaskFailures(cmd.Context(), db, taskID, limit)
+				if err != nil {
+					return err
+				}
+				failures = f
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				TaskID   string              `json:"task_id"`
+				Count    int                 `json:"count"`
+				Failures []store.TaskFailure `json:"failures"`
+			}
+			return output.PrintSuccess(resp{TaskID: taskID, Count: len(failures), Failures: failures})
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().Int("limit", 20, "Max failures to return")
+	return cmd
+}
@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newTaskGraphCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Export the task dependency graph as Mermaid, Graphviz DOT, or JSON",
+		Long: `Graph emits tasks and their dependency edges for visualization. Arrows point
+from a dependency to the task waiting on it. Nodes are colored by status (pending
+gray, in_progress blue, blocked red, completed green) and in-progress tasks name the
+agents whose focus holds them. Tasks from other projects that an edge reaches are
+drawn dashed.
+
+mermaid and dot print the diagram as plain text; json returns the graph in the
+usual response envelope.`,
+		Example: `  vybe task graph --project-dir "$PWD" > tasks.mmd
+  vybe task graph --format dot --project-dir "$PWD" | dot -Tsvg > tasks.svg`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			projectID, _ := cmd.Flags().GetString("project-id")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			switch format {
+			case actions.GraphFormatMermaid, actions.GraphFormatDOT, actions.GraphFormatJSON:
+			default:
+				return cmdErr(fmt.Errorf("invalid --format %q (valid: mermaid, dot, json)", format))
+			}
+			if projectDir != "" && projectID == "" {
+				if abs, err := filepath.Abs(projectDir); err == nil {
+					projectID = resolveProjectID(abs)
+				}
+			}
+
+			var graph *store.TaskGraph
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				projectID, err := confinedProjectFilter(cmd, db, projectID)
+				if err != nil {
+					return err
+				}
+				g, err := actions.TaskGraph(cmd.Context(), db, projectID)
+				graph = g
+				return err
+			}); err != nil {
+				return err
+			}
+
+			switch format {
+			case actions.GraphFormatMermaid:
+				_, err := fmt.Fprint(cmd.OutOrStdout(), actions.RenderTaskGraphMermaid(graph))
+				return err
+			case actions.GraphFormatDOT:
+				_, err := fmt.Fprint(cmd.OutOrStdout(), actions.RenderTaskGraphDOT(graph))
+				return err
+			default:
+				return output.PrintSuccess(graph)
+			}
+		},
+	}
+
+	cmd.Flags().String("format", actions.GraphFormatMermaid, "Output format: mermaid|dot|json")
+	cmd.Flags().String("project-id", "", "Restrict to a project ID (default: all tasks)")
+	cmd.Flags().String("project-dir", "", "Restrict to a project directory path (resolves to project_id)")
+	cmd.AddCommand(newTaskGraphValidateCmd())
+	return cmd
+}
+
+func newTaskGraphValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Find dependency cycles, self-edges, and edges to deleted tasks (--fix removes them)",
+		Long: `Validate checks the dependency graph for self-edges, orphaned edges whose task
+no longer exists, and cycles. Each cycle is reported with the tasks on it and the
+edge --fix would remove: the most recently added edge on the loop.
+
+--fix deletes every reported edge in one transaction and appends a
+task_graph_repaired event; it needs --agent and --request-id.`,
+		Example: `  vybe task graph validate --project-dir "$PWD"
+  vybe task graph validate --project-dir "$PWD" --fix --request-id graph-fix-1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fix, _ := cmd.Flags().GetBool("fix")
+			projectID, _ := cmd.Flags().GetString("project-id")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			if projectDir != "" && projectID == "" {
+				if abs, err := filepath.Abs(projectDir); err == nil {
+					projectID = resolveProjectID(abs)
+				}
+			}
+
+			if !fix {
+				var v *store.TaskGraphValidation
+				if err := withDB(cmd.Context(), func(db *DB) error {
+					var err error
+					v, err = actions.TaskGraphValidate(cmd.Context(), db, projectID)
+					return err
+				}); err != nil {
+					return err
+				}
+				return output.PrintSuccess(v)
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			var v *store.TaskGraphValidation
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				v, err = actions.TaskGraphRepairIdempotent(cmd.Context(), db, agentName, requestID, projectID)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(v)
+		},
+	}
+
+	cmd.Flags().Bool("fix", false, "Delete the reported edges (default: report only)")
+	cmd.Flags().String("project-id", "", "Restrict to a project ID (default: all tasks)")
+	cmd.Flags().String("project-dir", "", "Restrict to a project directory path (resolves to project_id)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "conditional"}
+	return cmd
+}
+
+func newTaskAddDepCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-dep",
+		Short: "Make a task wait on another task (rejects cycles)",
+		Long: `add-dep records that --id cannot start until --depends-on is completed. The edge
+is rejected when --depends-on already waits on --id, directly or through other
+tasks, since the two could then never start; the error names the existing chain.
+Adding an edge that already exists is a no-op (added: false).`,
+		Example: `  vybe task add-dep --id "$TASK" --depends-on "$BLOCKER" --request-id dep-1`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			dependsOn, _ := cmd.Flags().GetString("depends-on")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if dependsOn == "" {
+				return cmdErr(errors.New("--depends-on is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *store.AddDependencyResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				result, err = actions.TaskAddDepIdempotent(cmd.Context(), db, agentName, requestID, taskID, dependsOn)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID that waits (required)")
+	cmd.Flags().String("depends-on", "", "Task ID that must complete first (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newTaskSuggestDepsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suggest-deps",
+		Short: "Propose likely dependencies from title similarity and shared files",
+		Long: `suggest-deps compares --id with the other open tasks of its project and proposes
+dependency edges, most confident first. Evidence is title similarity (shared
+significant words) and file overlap (artifact paths, plus file paths named in
+task descriptions); with files on both sides, overlap weighs more. Pairs already
+linked, directly or through a chain, are skipped.
+
+direction is depends_on when --id should wait on the suggestion (the suggestion
+is older or already in progress) and blocks when the suggestion should wait on
+--id. Nothing is written; wire an edge you agree with using 'task add-dep'.`,
+		Example: `  vybe task suggest-deps --id "$TASK"
+  vybe task suggest-deps --id "$TASK" --min-confidence 0.4 | jq '.data.suggestions[] | {task_id, direction, confidence}'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			minConfidence, _ := cmd.Flags().GetFloat64("min-confidence")
+			limit, _ := cmd.Flags().GetInt("limit")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+
+			var report *store.DepSuggestReport
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				report, err = actions.TaskSuggestDeps(cmd.Context(), db, taskID, minConfidence, limit)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(report)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID to find dependencies for (required)")
+	cmd.Flags().Float64("min-confidence", 0.2, "Drop suggestions scoring below this (0-1)")
+	cmd.Flags().Int("limit", 10, "Maximum suggestions to return")
+	return cmd
+}
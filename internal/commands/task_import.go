@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// maxPlanFileBytes bounds plan files read by task import.
+const maxPlanFileBytes = 4 << 20
+
+func newTaskImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Create tasks from a markdown checklist or JSON plan",
+		Long: `Import creates one task per plan item in a single transaction.
+
+Markdown: every "- [ ] title" line is a task ("- [x]" is imported completed).
+Items indented under another are its subtasks; the parent depends on them. Other
+lines indented under an item become its description.
+
+JSON: {"tasks": [...]} or a bare array; each task has title and optional key,
+description, priority, done, depends_on (keys), and subtasks.
+
+Each task is tagged with plan_hash and plan_key metadata. Re-importing an
+unchanged plan into the same project reuses its tasks instead of duplicating
+them. Use --dry-run to preview the parsed plan (no request-id required).`,
+		Example: `  vybe task import --file plan.md --project-dir "$PWD" --request-id import-1
+  generate-plan | vybe task import --file - --format json --request-id import-2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			format, _ := cmd.Flags().GetString("format")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			projectID, _ := cmd.Flags().GetString("project-id")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			if file == "" {
+				return cmdErr(errors.New("--file is required"))
+			}
+			if format == "" {
+				format = actions.PlanFormatFor(file)
+			}
+			if projectDir != "" && projectID == "" {
+				if abs, err := filepath.Abs(projectDir); err == nil {
+					projectID = resolveProjectID(abs)
+				}
+			}
+
+			data, err := readPlanFile(file)
+			if err != nil {
+				return cmdErr(err)
+			}
+			items, err := actions.ParsePlan(data, format)
+			if err != nil {
+				return cmdErr(err)
+			}
+
+			if dryRun {
+				type resp struct {
+					DryRun    bool             `json:"dry_run"`
+					PlanHash  string           `json:"plan_hash"`
+					ProjectID string           `json:"project_id,omitempty"`
+					Tasks     []store.PlanItem `json:"tasks"`
+				}
+				return output.PrintSuccess(resp{DryRun: true, PlanHash: actions.PlanHash(items), ProjectID: projectID, Tasks: items})
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *store.PlanImportResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				result, err = actions.TaskImportIdempotent(cmd.Context(), db, agentName, requestID, projectID, items)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("file", "", "Plan file path (- for stdin)")
+	cmd.Flags().String("format", "", "Plan format: md|json (default: json for .json files, else md)")
+	cmd.Flags().String("project-id", "", "Project ID for the imported tasks")
+	cmd.Flags().String("project-dir", "", "Project directory path (resolves to project_id)")
+	cmd.Flags().Bool("dry-run", false, "Print the parsed plan without creating tasks")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "conditional"}
+	return cmd
+}
+
+func readPlanFile(path string) ([]byte, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path) //nolint:gosec // user-supplied plan path is the point of the command
+		if err != nil {
+			return nil, fmt.Errorf("failed to open plan file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxPlanFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+	if len(data) > maxPlanFileBytes {
+		return nil, fmt.Errorf("plan file exceeds %d bytes", maxPlanFileBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+func newTaskMetaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "meta",
+		Short: "Manage typed per-task metadata (reviewer, PR URL, environment)",
+		Long: `Task metadata is a typed key/value bag for integrations. Values are returned
+under "metadata" by task get and can be filtered with task list --meta key=value.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newTaskMetaSetCmd())
+	cmd.AddCommand(newTaskMetaUnsetCmd())
+	cmd.AddCommand(newTaskMetaListCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newTaskMetaSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set a metadata key on a task",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			key, _ := cmd.Flags().GetString("key")
+			value, _ := cmd.Flags().GetString("value")
+			valueType, _ := cmd.Flags().GetString("type")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if key == "" {
+				return cmdErr(errors.New("--key is required"))
+			}
+			if !cmd.Flags().Changed("value") {
+				return cmdErr(errors.New("--value is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			type resp struct {
+				Meta    *models.TaskMeta `json:"meta"`
+				EventID int64            `json:"event_id"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				m, eid, err := actions.TaskMetaSetIdempotent(cmd.Context(), db, agentName, requestID, taskID, key, value, valueType)
+				if err != nil {
+					return err
+				}
+				r = resp{Meta: m, EventID: eid}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().String("key", "", "Metadata key (required)")
+	cmd.Flags().String("value", "", "Metadata value (required)")
+	cmd.Flags().String("type", "", "Value type: string|number|boolean|json|array (default: inferred)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newTaskMetaUnsetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset",
+		Short: "Remove a metadata key from a task",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			key, _ := cmd.Flags().GetString("key")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if key == "" {
+				return cmdErr(errors.New("--key is required"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			type resp struct {
+				TaskID  string `json:"task_id"`
+				Key     string `json:"key"`
+				Removed bool   `json:"removed"`
+				EventID int64  `json:"event_id,omitempty"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				eid, err := actions.TaskMetaUnsetIdempotent(cmd.Context(), db, agentName, requestID, taskID, key)
+				if err != nil {
+					return err
+				}
+				r = resp{TaskID: taskID, Key: key, Removed: eid > 0, EventID: eid}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().String("key", "", "Metadata key (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newTaskMetaListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a task's metadata entries with their types",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+
+			type resp struct {
+				TaskID  string            `json:"task_id"`
+				Entries []models.TaskMeta `json:"entries"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				entries, err := actions.TaskMetaList(cmd.Context(), db, taskID)
+				if err != nil {
+					return err
+				}
+				r = resp{TaskID: taskID, Entries: entries}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	return cmd
+}
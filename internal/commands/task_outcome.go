@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newTaskCompleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "complete",
+		Short: "Complete a task with an outcome and summary",
+		Long: `Completes a task, recording how it was resolved and a summary (kept on the
+task_closed event). Outcomes:
+  done        the work was delivered (default)
+  partial     some of the work was delivered
+  wontfix     closed deliberately without doing it
+  duplicate   another task covers the same work (--superseded-by may name it)
+  superseded  replaced by another task (--superseded-by is required)
+
+With --strict, the task is not completed while any acceptance criterion (task
+criteria) is unchecked; the check runs in the same transaction as the close.
+
+task stats rolls outcomes up per project.`,
+		Example: `  vybe task complete --id task_123 --summary "Login fixed, tests added"
+  vybe task complete --id task_123 --strict --summary "All criteria met"
+  vybe task complete --id task_123 --outcome superseded --superseded-by task_456 --summary "Folded into the auth rewrite"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			outcome, _ := cmd.Flags().GetString("outcome")
+			summary, _ := cmd.Flags().GetString("summary")
+			supersededBy, _ := cmd.Flags().GetString("superseded-by")
+			label, _ := cmd.Flags().GetString("label")
+			strict, _ := cmd.Flags().GetBool("strict")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if summary == "" {
+				return cmdErr(errors.New("--summary is required"))
+			}
+			if outcome == "blocked" {
+				return cmdErr(errors.New("task complete does not block; use task fail or task set-status --status blocked"))
+			}
+
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			var result *actions.TaskCloseResult
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				r, err := actions.TaskCloseWithOptionsIdempotent(cmd.Context(), db, agentName, requestID, taskID, outcome, summary, actions.TaskCloseOptions{
+					Label:        label,
+					SupersededBy: supersededBy,
+					IfVersion:    ifVersionFlag(cmd),
+					Strict:       strict,
+				})
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().String("outcome", "done", "How it was resolved: done|partial|wontfix|duplicate|superseded")
+	cmd.Flags().String("summary", "", "What was done, or why it was closed (required)")
+	cmd.Flags().String("superseded-by", "", "Task that replaces this one (superseded or duplicate)")
+	cmd.Flags().String("label", "", "Optional label stored with the close event")
+	cmd.Flags().Int("if-version", 0, ifVersionFlagHelp)
+	cmd.Flags().Bool("strict", false, "Refuse to complete while acceptance criteria are unchecked")
+
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newTaskStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show queue health: tasks by status and completed tasks by outcome",
+		Long: `Counts tasks by status and completed tasks by outcome. Tasks completed
+without an outcome (for example with task set-status) count as done.
+done_ratio is the share of completed tasks that were done rather than partial,
+wontfix, duplicate, or superseded. --project-id or --project-dir restricts the
+counts to one project.`,
+		Example: `  vybe task stats
+  vybe task stats --project-dir "$PWD" | jq '.data.outcomes'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, _ := cmd.Flags().GetString("project-id")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			if projectDir != "" && projectID == "" {
+				if abs, err := filepath.Abs(projectDir); err == nil {
+					projectID = resolveProjectID(abs)
+				}
+			}
+
+			var stats *store.TaskStats
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				projectID, err := confinedProjectFilter(cmd, db, projectID)
+				if err != nil {
+					return err
+				}
+				s, err := actions.TaskStats(cmd.Context(), db, projectID)
+				if err != nil {
+					return err
+				}
+				stats = s
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(stats)
+		},
+	}
+
+	cmd.Flags().String("project-id", "", "Restrict stats to a project ID")
+	cmd.Flags().String("project-dir", "", "Restrict stats to a project directory path (resolves to project_id)")
+	return cmd
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newTaskStaleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stale",
+		Short: "List in_progress tasks with no recent updates or events",
+		Long: `stale lists in_progress tasks whose last update and latest event are older
+than --threshold, longest idle first, with the agents focused on them. Use it
+to spot work an agent abandoned before anyone reclaims it.
+
+--notify also logs one task_stale event per task not yet reported for its
+current idle stretch (any new activity re-arms it); it needs --request-id.
+The checkpoint hook does the same with stale_task_after from config
+(default 2h, "off" disables).`,
+		Example: `  vybe task stale --threshold 2h
+  vybe task stale --threshold 30m --notify --request-id "stale_$(date +%s)"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			thresholdRaw, _ := cmd.Flags().GetString("threshold")
+			projectID, _ := cmd.Flags().GetString("project-id")
+			notify, _ := cmd.Flags().GetBool("notify")
+
+			threshold, err := time.ParseDuration(thresholdRaw)
+			if err != nil || threshold <= 0 {
+				return cmdErr(fmt.Errorf("invalid --threshold %q: use a positive duration like 2h", thresholdRaw))
+			}
+
+			type resp struct {
+				Threshold string            `json:"threshold"`
+				Notify    bool              `json:"notify"`
+				Count     int               `json:"count"`
+				Tasks     []store.StaleTask `json:"tasks"`
+			}
+
+			var tasks []store.StaleTask
+			if notify {
+				agentName, requestID, err := requireMutationParams(cmd)
+				if err != nil {
+					return err
+				}
+				if err := withDB(cmd.Context(), func(db *DB) error {
+					var err error
+					tasks, err = actions.TaskStaleNotifyIdempotent(cmd.Context(), db, agentName, requestID, projectID, threshold)
+					return err
+				}); err != nil {
+					return err
+				}
+			} else if err := withDB(cmd.Context(), func(db *DB) error {
+				var err error
+				tasks, err = actions.TaskStale(cmd.Context(), db, resolveActorName(cmd, ""), projectID, threshold)
+				return err
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(resp{Threshold: threshold.String(), Notify: notify, Count: len(tasks), Tasks: tasks})
+		},
+	}
+
+	cmd.Flags().String("threshold", app.DefaultStaleTaskAfter.String(), "Idle time after which an in_progress task is stale")
+	cmd.Flags().String("project-id", "", "Restrict to a project ID")
+	cmd.Flags().Bool("notify", false, "Log task_stale events for newly stale tasks")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
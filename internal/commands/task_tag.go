@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"context"
+
+	"errors"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+func newTaskTagCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Manage free-form task tags used to route work to agents",
+		Long: `Tags label tasks by kind of work (bugfix, docs, area:frontend) so dedicated
+agents can take only their slice of the queue. Tags are lowercased; letters,
+digits, and '.', '_', '-', ':', '/' are allowed. Filter with --tag on task list,
+task next, task claim, resume, and loop; a task matches when it carries every
+tag given. Tags appear under "tags" in task output and briefs.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newTaskTagAddCmd())
+	cmd.AddCommand(newTaskTagRemoveCmd())
+	cmd.AddCommand(newTaskTagListCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newTaskTagAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "add",
+		Short:   "Add tags to a task",
+		Example: `  vybe task tag add --id task_123 --tag bugfix --tag area:api --request-id tag_1`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTaskTagChange(cmd, actions.TaskTagAddIdempotent)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().StringArray("tag", nil, "Tag to add (required, repeatable)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+func newTaskTagRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove tags from a task",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTaskTagChange(cmd, actions.TaskTagRemoveIdempotent)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().StringArray("tag", nil, "Tag to remove (required, repeatable)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true"}
+	return cmd
+}
+
+type taskTagChangeFunc func(ctx context.Context, db *DB, agentName, requestID, taskID string, tags []string) (*store.TaskTagChange, error)
+
+func runTaskTagChange(cmd *cobra.Command, change taskTagChangeFunc) error {
+	taskID, _ := cmd.Flags().GetString("id")
+	tags, _ := cmd.Flags().GetStringArray("tag")
+	if taskID == "" {
+		return cmdErr(errors.New("--id is required"))
+	}
+	if len(tags) == 0 {
+		return cmdErr(errors.New("--tag is required"))
+	}
+
+	agentName, requestID, err := requireMutationParams(cmd)
+	if err != nil {
+		return err
+	}
+
+	var r *store.TaskTagChange
+	if err := withDB(cmd.Context(), func(db *DB) error {
+		c, err := change(cmd.Context(), db, agentName, requestID, taskID, tags)
+		if err != nil {
+			return err
+		}
+		r = c
+		return nil
+	}); err != nil {
+		return err
+	}
+	return output.PrintSuccess(r)
+}
+
+func newTaskTagListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a task's tags, or every tag in use with task counts by status",
+		Long: `With --id, list prints that task's tags. Without it, list prints every tag in
+use with how many tasks carry it, broken down by status, most used first;
+--project-id or --project-dir restricts the counts to one project.`,
+		Example: `  vybe task tag list --id task_123
+  vybe task tag list --project-dir "$PWD"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			projectID, _ := cmd.Flags().GetString("project-id")
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+			if projectDir != "" && projectID == "" {
+				if abs, err := filepath.Abs(projectDir); err == nil {
+					projectID = resolveProjectID(abs)
+				}
+			}
+
+			if taskID != "" {
+				type resp struct {
+					TaskID string   `json:"task_id"`
+					Tags   []string `json:"tags"`
+				}
+				var r resp
+				if err := withDB(cmd.Context(), func(db *DB) error {
+					tags, err := actions.TaskTagList(cmd.Context(), db, taskID)
+					if err != nil {
+						return err
+					}
+					r = resp{TaskID: taskID, Tags: tags}
+					return nil
+				}); err != nil {
+					return err
+				}
+				return output.PrintSuccess(r)
+			}
+
+			type resp struct {
+				ProjectID string           `json:"project_id,omitempty"`
+				Count     int              `json:"count"`
+				Tags      []store.TagCount `json:"tags"`
+			}
+			var r resp
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				counts, err := actions.TaskTagCounts(cmd.Context(), db, projectID)
+				if err != nil {
+					return err
+				}
+				r = resp{ProjectID: projectID, Count: len(counts), Tags: counts}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (omit to list every tag with counts)")
+	cmd.Flags().String("project-id", "", "Restrict tag counts to a project ID")
+	cmd.Flags().String("project-dir", "", "Restrict tag counts to a project directory path (resolves to project_id)")
+	return cmd
+}
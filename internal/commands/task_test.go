@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -22,6 +23,7 @@ func TestNewTaskCmd_HasExpectedSubcommands(t *testing.T) {
 
 func TestTaskGetCmd_ValidationErrorsBeforeDB(t *testing.T) {
 	cmd := newTaskGetCmd()
+	cmd.SetContext(context.Background())
 
 	err := cmd.RunE(cmd, nil)
 	require.Error(t, err)
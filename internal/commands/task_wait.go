@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+func newTaskWaitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Block until a task reaches a status",
+		Long: `Wait blocks until the task's status is one of --until, then prints the task.
+It follows the event stream instead of re-reading the task in a loop, so a
+parent agent or shell pipeline can wait on a sub-agent's work cheaply.
+
+With --timeout, a wait that runs out fails with WAIT_TIMEOUT (exit 1) and the
+task's current status in error_context. Without it, wait blocks until the task
+gets there or the process is interrupted. task wait always runs in-process,
+never through the daemon.`,
+		Example: `  vybe task wait --id task_123 --timeout 10m
+  vybe task wait --id task_123 --until completed,blocked && vybe task get --id task_123`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID, _ := cmd.Flags().GetString("id")
+			untilRaw, _ := cmd.Flags().GetStringSlice("until")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			if taskID == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+			if timeout < 0 {
+				return cmdErr(errors.New("--timeout must be >= 0"))
+			}
+			until := make([]models.TaskStatus, 0, len(untilRaw))
+			for _, s := range untilRaw {
+				until = append(until, models.TaskStatus(strings.TrimSpace(s)))
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			var result *actions.TaskWaitResult
+			if err := withDB(ctx, func(db *DB) error {
+				r, err := actions.TaskWait(ctx, db, taskID, until, timeout)
+				if errors.Is(err, context.Canceled) {
+					return errors.New("interrupted before the task reached " + strings.Join(untilRaw, ","))
+				}
+				if err != nil {
+					return err
+				}
+				result = r
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(result)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Task ID (required)")
+	cmd.Flags().StringSlice("until", []string{string(models.TaskStatusCompleted)}, "Statuses to wait for (comma-separated): pending|in_progress|completed|blocked")
+	cmd.Flags().Duration("timeout", 0, "Give up after this long, e.g. 10m (0 = wait indefinitely)")
+	return cmd
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// traceExportTimeout bounds how long a command waits on the collector after
+// it finishes; a slow or missing collector never holds up the CLI longer.
+const traceExportTimeout = 3 * time.Second
+
+// startCommandTrace begins a trace for cmd when --otel-endpoint or the
+// OTEL_EXPORTER_OTLP_* environment names a collector, and sets cmd's context
+// to one carrying it.
+func startCommandTrace(cmd *cobra.Command, version string) {
+	endpoint, _ := cmd.Root().PersistentFlags().GetString("otel-endpoint")
+	cfg, ok := telemetry.ConfigFromEnv(endpoint)
+	if !ok {
+		return
+	}
+	cfg.ServiceVersion = version
+	key := commandKey(cmd)
+	ctx, span := telemetry.StartTrace(cmd.Context(), cfg, "vybe "+key)
+	span.Set("vybe.command", key)
+	cmd.SetContext(ctx)
+}
+
+// finishCommandTrace ends and exports the trace cmd's context carries, if one
+// was started. Export failures are logged, not returned: tracing never fails a
+// command.
+func finishCommandTrace(cmd *cobra.Command, err error) {
+	if cmd == nil || cmd.Context() == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(cmd.Context()), traceExportTimeout)
+	defer cancel()
+	if exportErr := telemetry.Finish(ctx, err); exportErr != nil {
+		slog.Default().Warn("trace export failed", "error", exportErr)
+	}
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/actions"
+	"github.com/dotcommander/vybe/internal/output"
+	"github.com/dotcommander/vybe/internal/store"
+)
+
+// NewTokenCmd creates the token command group.
+func NewTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage API tokens for a token-protected daemon",
+		Long: `Tokens gate a daemon started with --require-token. Clients send theirs in
+VYBE_TOKEN, and the daemon checks its role against each command:
+
+  read   commands that do not change state (task list, brief, events, ...)
+  agent  plus mutations (task, memory, push, ...) and hook handlers
+  admin  plus database, config, project purge, and token administration
+
+Only a hash of each token is stored. The secret is printed once, by create.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newTokenCreateCmd())
+	cmd.AddCommand(newTokenListCmd())
+	cmd.AddCommand(newTokenRevokeCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newTokenCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a token and print its secret once",
+		Example: `  vybe token create --name ci-reader --role read --request-id tok-1 | jq -r .data.token
+  vybe token create --name worker-3 --role agent --request-id tok-2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			name, _ := cmd.Flags().GetString("name")
+			role, _ := cmd.Flags().GetString("role")
+			if strings.TrimSpace(name) == "" {
+				return cmdErr(errors.New("--name is required"))
+			}
+
+			var tok *store.APIToken
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				t, err := actions.TokenCreateIdempotent(cmd.Context(), db, agentName, requestID, name, role)
+				if err != nil {
+					return err
+				}
+				tok = t
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(tok)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Who or what the token is for (required)")
+	cmd.Flags().String("role", store.TokenRoleAgent, "Role: "+strings.Join(store.TokenRoles(), "|"))
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
+	return cmd
+}
+
+func newTokenListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tokens (secrets are never shown)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+
+			var tokens []store.APIToken
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				t, err := store.ListAPITokens(cmd.Context(), db, all)
+				if err != nil {
+					return err
+				}
+				tokens = t
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			type resp struct {
+				Count  int              `json:"count"`
+				Tokens []store.APIToken `json:"tokens"`
+			}
+			return output.PrintSuccess(resp{Count: len(tokens), Tokens: tokens})
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "Include revoked tokens")
+	cmd.Annotations = map[string]string{"admin": "true"}
+	return cmd
+}
+
+func newTokenRevokeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke a token; the daemon rejects it from the next request on",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, requestID, err := requireMutationParams(cmd)
+			if err != nil {
+				return err
+			}
+			id, _ := cmd.Flags().GetString("id")
+			if id == "" {
+				return cmdErr(errors.New("--id is required"))
+			}
+
+			var tok *store.APIToken
+			if err := withDB(cmd.Context(), func(db *DB) error {
+				t, err := actions.TokenRevokeIdempotent(cmd.Context(), db, agentName, requestID, id)
+				if err != nil {
+					return err
+				}
+				tok = t
+				return nil
+			}); err != nil {
+				return err
+			}
+			return output.PrintSuccess(tok)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Token ID (required)")
+	cmd.Annotations = map[string]string{"mutates": "true", "request_id": "true", "admin": "true"}
+	return cmd
+}
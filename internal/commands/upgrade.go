@@ -70,9 +70,9 @@ func NewUpgradeCmd() *cobra.Command {
 			srcDir := findSourceDir()
 			if srcDir == "" {
 				// Fallback: go install from module path
-				return upgradeViaGoInstall()
+				return upgradeViaGoInstall(cmd.Context())
 			}
-			return upgradeViaGitPull(srcDir)
+			return upgradeViaGitPull(cmd.Context(), srcDir)
 		},
 	}
 	cmd.Annotations = map[string]string{"mutates": "true"}
@@ -160,12 +160,12 @@ func isVybeSourceDir(dir string) bool {
 	return false
 }
 
-func upgradeViaGitPull(srcDir string) error {
+func upgradeViaGitPull(ctx context.Context, srcDir string) error {
 	// Get current version before pull
 	oldVersion := getGitVersion(srcDir)
 
 	// Pull latest
-	pullCtx, pullCancel := context.WithTimeout(context.Background(), upgradeGitPullTimeout)
+	pullCtx, pullCancel := context.WithTimeout(ctx, upgradeGitPullTimeout)
 	defer pullCancel()
 
 	pull := exec.CommandContext(pullCtx, "git", "pull", "--ff-only") //nolint:gosec // G204: git is a known system tool
@@ -178,7 +178,7 @@ func upgradeViaGitPull(srcDir string) error {
 	}
 
 	// Build and install
-	installCtx, installCancel := context.WithTimeout(context.Background(), upgradeGoInstallTimeout)
+	installCtx, installCancel := context.WithTimeout(ctx, upgradeGoInstallTimeout)
 	defer installCancel()
 
 	install := exec.CommandContext(installCtx, "go", "install", "./cmd/vybe") //nolint:gosec // G204: go is a known system tool
@@ -191,7 +191,7 @@ func upgradeViaGitPull(srcDir string) error {
 
 	newVersion := getGitVersion(srcDir)
 
-	migrated, migrateErr := migrateAfterUpgrade()
+	migrated, migrateErr := migrateAfterUpgrade(ctx)
 	hooksReinstalled, hooksErr := reinstallHooks(resolveInstalledBinary())
 
 	return printUpgradeResult(upgradeResult{
@@ -206,10 +206,10 @@ func upgradeViaGitPull(srcDir string) error {
 	})
 }
 
-func upgradeViaGoInstall() error {
+func upgradeViaGoInstall(ctx context.Context) error {
 	oldVersion := getVybeVersion()
 
-	installCtx, installCancel := context.WithTimeout(context.Background(), upgradeGoInstallTimeout)
+	installCtx, installCancel := context.WithTimeout(ctx, upgradeGoInstallTimeout)
 	defer installCancel()
 
 	install := exec.CommandContext(installCtx, "go", "install", expectedModule+"/cmd/vybe@latest") //nolint:gosec // G204: go is a known system tool
@@ -220,7 +220,7 @@ func upgradeViaGoInstall() error {
 	}
 
 	newVersion := getVybeVersion()
-	migrated, migrateErr := migrateAfterUpgrade()
+	migrated, migrateErr := migrateAfterUpgrade(ctx)
 	hooksReinstalled, hooksErr := reinstallHooks(resolveInstalledBinary())
 
 	return printUpgradeResult(upgradeResult{
@@ -237,17 +237,17 @@ func upgradeViaGoInstall() error {
 }
 
 // migrateAfterUpgrade opens the database and runs pending migrations.
-func migrateAfterUpgrade() (bool, string) {
+func migrateAfterUpgrade(ctx context.Context) (bool, string) {
 	dbPath, err := app.GetDBPath()
 	if err != nil {
 		return false, err.Error()
 	}
-	db, err := store.OpenDB(dbPath)
+	db, err := store.OpenDB(ctx, dbPath)
 	if err != nil {
 		return false, err.Error()
 	}
 	defer func() { _ = store.CloseDB(db) }()
-	if err := store.MigrateDB(db, dbPath); err != nil {
+	if err := store.MigrateDB(ctx, db, dbPath); err != nil {
 		return false, err.Error()
 	}
 	return true, ""
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// NewWhoamiCmd creates the whoami command.
+func NewWhoamiCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the resolved agent identity and which source supplied it",
+		Long: `Whoami walks the agent resolution chain (default: --agent, VYBE_AGENT,
+agent in .vybe.toml; add git to agent_resolution to fall back to a hash of
+git user.email) and reports the first match.
+When nothing matches, hooks fall back to the default agent and other
+commands require an explicit --agent.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			type resp struct {
+				actorIdentity
+				Chain []string `json:"chain"`
+			}
+
+			id := resolveActorIdentity(cmd, "")
+			if id.Name == "" {
+				id = actorIdentity{Name: defaultAgentName, Source: actorSourceDefault, Detail: "hook fallback"}
+			}
+			return output.PrintSuccess(resp{actorIdentity: id, Chain: app.EffectiveAgentResolution()})
+		},
+	}
+}
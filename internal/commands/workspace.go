@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/output"
+)
+
+// NewWorkspaceCmd creates the ws command group for cwd-based database routing.
+func NewWorkspaceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ws",
+		Aliases: []string{"workspace"},
+		Short:   "Route commands to per-workspace databases based on the current directory",
+		Long: `Workspaces map directory roots to database paths (~/.config/vybe/workspaces.json).
+Commands run inside a bound root use that workspace's database unless --db-path or
+VYBE_DB_PATH is set. The deepest matching root wins.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newWorkspaceAddCmd())
+	cmd.AddCommand(newWorkspaceUseCmd())
+	cmd.AddCommand(newWorkspaceListCmd())
+	cmd.AddCommand(newWorkspaceRemoveCmd())
+
+	namespaceIndex(cmd)
+	return cmd
+}
+
+func newWorkspaceAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Register a workspace and its database path",
+		Long: `Add registers name with the database at --path. The root --db-path flag is
+not read here: it picks the database this command itself runs against.`,
+		Example: `  vybe ws add api --path ~/.config/vybe/api.db`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbPath, _ := cmd.Flags().GetString("path")
+			ws, err := app.AddWorkspace(args[0], dbPath)
+			if err != nil {
+				return cmdErr(err)
+			}
+			return output.PrintSuccess(ws)
+		},
+	}
+	cmd.Flags().String("path", "", "Database file for the workspace")
+	_ = cmd.MarkFlagRequired("path")
+	cmd.Annotations = map[string]string{"mutates": "true", "admin": "true"}
+	return cmd
+}
+
+func newWorkspaceUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use <name>",
+		Short: "Bind the current directory (and below) to a workspace",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			if dir == "" {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return cmdErr(err)
+				}
+				dir = cwd
+			}
+			ws, err := app.UseWorkspace(args[0], dir)
+			if err != nil {
+				return cmdErr(err)
+			}
+			return output.PrintSuccess(ws)
+		},
+	}
+	cmd.Flags().String("dir", "", "Directory to bind (default: current directory)")
+	cmd.Annotations = map[string]string{"mutates": "true", "admin": "true"}
+	return cmd
+}
+
+func newWorkspaceListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List workspaces and which one the current directory resolves to",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reg, err := app.LoadWorkspaces()
+			if err != nil {
+				return cmdErr(err)
+			}
+			type resp struct {
+				Workspaces []app.Workspace `json:"workspaces"`
+				Current    string          `json:"current,omitempty"`
+				MatchedOn  string          `json:"matched_root,omitempty"`
+			}
+			r := resp{Workspaces: reg.Workspaces}
+			if cwd, err := os.Getwd(); err == nil {
+				if ws, root := reg.Match(cwd); ws != nil {
+					r.Current, r.MatchedOn = ws.Name, root
+				}
+			}
+			return output.PrintSuccess(r)
+		},
+	}
+}
+
+func newWorkspaceRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a workspace (its database file is kept)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := app.RemoveWorkspace(args[0]); err != nil {
+				return cmdErr(err)
+			}
+			type resp struct {
+				Removed string `json:"removed"`
+			}
+			return output.PrintSuccess(resp{Removed: args[0]})
+		},
+	}
+	cmd.Annotations = map[string]string{"mutates": "true", "admin": "true"}
+	return cmd
+}
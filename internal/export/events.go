@@ -0,0 +1,123 @@
+// Package export writes event streams in analytics-friendly file formats.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Format is an event export file format.
+type Format string
+
+// Supported export formats.
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat validates a --format value.
+func ParseFormat(raw string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(raw))); f {
+	case FormatCSV, FormatJSONL, FormatParquet:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid format %q (must be csv, jsonl, or parquet)", raw)
+}
+
+// EventWriter streams events to an export file.
+type EventWriter interface {
+	Write(e *models.Event) error
+	// Close flushes buffered output; it does not close the underlying writer.
+	Close() error
+}
+
+// eventColumns is the flat export schema shared by every format.
+var eventColumns = []ParquetColumn{
+	{Name: "id", Kind: ParquetInt64},
+	{Name: "kind", Kind: ParquetString},
+	{Name: "agent_name", Kind: ParquetString},
+	{Name: "project_id", Kind: ParquetString},
+	{Name: "task_id", Kind: ParquetString},
+	{Name: "message", Kind: ParquetString},
+	{Name: "metadata", Kind: ParquetString},
+	{Name: "created_at", Kind: ParquetTimestampMillis},
+}
+
+// NewEventWriter returns a writer for format f.
+func NewEventWriter(w io.Writer, f Format) (EventWriter, error) {
+	switch f {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		header := make([]string, len(eventColumns))
+		for i, c := range eventColumns {
+			header[i] = c.Name
+		}
+		if err := cw.Write(header); err != nil {
+			return nil, err
+		}
+		return &csvEventWriter{w: cw}, nil
+	case FormatJSONL:
+		return &jsonlEventWriter{enc: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return &parquetEventWriter{w: NewParquetWriter(w, eventColumns)}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", f)
+}
+
+// metadataString renders metadata as compact JSON text, empty when absent.
+func metadataString(e *models.Event) string {
+	if len(e.Metadata) == 0 || string(e.Metadata) == "null" {
+		return ""
+	}
+	return string(e.Metadata)
+}
+
+type csvEventWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvEventWriter) Write(e *models.Event) error {
+	return c.w.Write([]string{
+		strconv.FormatInt(e.ID, 10),
+		e.Kind,
+		e.AgentName,
+		e.ProjectID,
+		e.TaskID,
+		e.Message,
+		metadataString(e),
+		e.CreatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (c *csvEventWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlEventWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlEventWriter) Write(e *models.Event) error { return j.enc.Encode(e) }
+
+func (j *jsonlEventWriter) Close() error { return nil }
+
+type parquetEventWriter struct {
+	w *ParquetWriter
+}
+
+func (p *parquetEventWriter) Write(e *models.Event) error {
+	return p.w.Write([]any{
+		e.ID, e.Kind, e.AgentName, e.ProjectID, e.TaskID, e.Message, metadataString(e), e.CreatedAt.UnixMilli(),
+	})
+}
+
+func (p *parquetEventWriter) Close() error { return p.w.Close() }
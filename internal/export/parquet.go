@@ -0,0 +1,396 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Parquet physical and logical type codes (parquet.thrift).
+const (
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+
+	parquetRepetitionRequired = 0
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetCodecUncompressed  = 0
+	parquetPageTypeData       = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// ParquetColumnKind selects a column's physical encoding.
+type ParquetColumnKind int
+
+// Supported column kinds. All columns are REQUIRED, so pages carry no
+// definition or repetition levels.
+const (
+	ParquetString ParquetColumnKind = iota
+	ParquetInt64
+	ParquetTimestampMillis
+)
+
+// ParquetColumn describes one flat column.
+type ParquetColumn struct {
+	Name string
+	Kind ParquetColumnKind
+}
+
+// ParquetWriter writes a flat, uncompressed, PLAIN-encoded Parquet file.
+// Rows are buffered and flushed as one row group every RowGroupSize rows.
+// It covers the subset DuckDB, pandas/pyarrow, and Spark all read; nested or
+// nullable columns are out of scope.
+type ParquetWriter struct {
+	RowGroupSize int
+
+	bw      *bufio.Writer
+	w       *countingWriter
+	columns []ParquetColumn
+	ints    [][]int64
+	strs    [][]string
+	rows    int
+	groups  []parquetRowGroup
+	total   int64
+	started bool
+}
+
+type parquetColumnChunk struct {
+	size       int64
+	numValues  int64
+	column     ParquetColumn
+	pageOffset int64
+}
+
+type parquetRowGroup struct {
+	chunks   []parquetColumnChunk
+	numRows  int64
+	byteSize int64
+}
+
+// NewParquetWriter creates a writer with the given schema.
+func NewParquetWriter(w io.Writer, columns []ParquetColumn) *ParquetWriter {
+	bw := bufio.NewWriter(w)
+	return &ParquetWriter{
+		RowGroupSize: 50000,
+		bw:           bw,
+		w:            &countingWriter{w: bw},
+		columns:      columns,
+		ints:         make([][]int64, len(columns)),
+		strs:         make([][]string, len(columns)),
+	}
+}
+
+// Write appends one row. Values must match the schema: string for ParquetString,
+// int64 for ParquetInt64 and ParquetTimestampMillis (milliseconds since epoch).
+func (p *ParquetWriter) Write(row []any) error {
+	if len(row) != len(p.columns) {
+		return fmt.Errorf("parquet row has %d values, schema has %d columns", len(row), len(p.columns))
+	}
+	for i, c := range p.columns {
+		switch c.Kind {
+		case ParquetString:
+			s, ok := row[i].(string)
+			if !ok {
+				return fmt.Errorf("parquet column %s: want string, got %T", c.Name, row[i])
+			}
+			p.strs[i] = append(p.strs[i], s)
+		default:
+			n, ok := row[i].(int64)
+			if !ok {
+				return fmt.Errorf("parquet column %s: want int64, got %T", c.Name, row[i])
+			}
+			p.ints[i] = append(p.ints[i], n)
+		}
+	}
+	p.rows++
+	if p.RowGroupSize > 0 && p.rows >= p.RowGroupSize {
+		return p.flushRowGroup()
+	}
+	return nil
+}
+
+// Close flushes buffered rows and writes the footer. It does not close the underlying writer.
+func (p *ParquetWriter) Close() error {
+	if err := p.start(); err != nil {
+		return err
+	}
+	if p.rows > 0 {
+		if err := p.flushRowGroup(); err != nil {
+			return err
+		}
+	}
+
+	footer := p.footer()
+	if _, err := p.w.Write(footer); err != nil {
+		return err
+	}
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(footer))) //nolint:gosec // G115: footer size is bounded by schema and row group count
+	if _, err := p.w.Write(n[:]); err != nil {
+		return err
+	}
+	if _, err := p.w.Write(parquetMagic); err != nil {
+		return err
+	}
+	return p.bw.Flush()
+}
+
+func (p *ParquetWriter) start() error {
+	if p.started {
+		return nil
+	}
+	p.started = true
+	_, err := p.w.Write(parquetMagic)
+	return err
+}
+
+func (p *ParquetWriter) flushRowGroup() error {
+	if err := p.start(); err != nil {
+		return err
+	}
+	rg := parquetRowGroup{numRows: int64(p.rows)}
+	for i, c := range p.columns {
+		data := p.encodeColumn(i)
+		if len(data) > math.MaxInt32 {
+			return errors.New("parquet page exceeds 2 GiB; lower RowGroupSize")
+		}
+		header := encodeDataPageHeader(len(data), p.rows)
+
+		chunk := parquetColumnChunk{column: c, numValues: int64(p.rows), pageOffset: p.w.n}
+		if _, err := p.w.Write(header); err != nil {
+			return err
+		}
+		if _, err := p.w.Write(data); err != nil {
+			return err
+		}
+		chunk.size = int64(len(header) + len(data))
+		rg.chunks = append(rg.chunks, chunk)
+		rg.byteSize += chunk.size
+
+		p.ints[i] = p.ints[i][:0]
+		p.strs[i] = p.strs[i][:0]
+	}
+	p.groups = append(p.groups, rg)
+	p.total += int64(p.rows)
+	p.rows = 0
+	return nil
+}
+
+func (p *ParquetWriter) encodeColumn(i int) []byte {
+	if p.columns[i].Kind == ParquetString {
+		size := 0
+		for _, s := range p.strs[i] {
+			size += 4 + len(s)
+		}
+		buf := make([]byte, 0, size)
+		for _, s := range p.strs[i] {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(len(s))) //nolint:gosec // G115: values are bounded by event field limits
+			buf = append(buf, s...)
+		}
+		return buf
+	}
+	buf := make([]byte, 0, 8*len(p.ints[i]))
+	for _, n := range p.ints[i] {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(n)) //nolint:gosec // G115: two's-complement reinterpretation is the PLAIN int64 encoding
+	}
+	return buf
+}
+
+func encodeDataPageHeader(size, numValues int) []byte {
+	var t thriftWriter
+	t.i32(1, parquetPageTypeData)
+	t.i32(2, int32(size)) //nolint:gosec // G115: checked against MaxInt32 by the caller
+	t.i32(3, int32(size)) //nolint:gosec // G115: uncompressed, same as above
+	t.beginStruct(5)
+	t.i32(1, int32(numValues)) //nolint:gosec // G115: bounded by RowGroupSize
+	t.i32(2, parquetEncodingPlain)
+	t.i32(3, parquetEncodingRLE)
+	t.i32(4, parquetEncodingRLE)
+	t.endStruct()
+	t.stop()
+	return t.buf
+}
+
+func (p *ParquetWriter) footer() []byte {
+	var t thriftWriter
+	t.i32(1, 1) // version
+
+	t.listOfStructs(2, len(p.columns)+1)
+	t.beginListStruct()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(p.columns))) //nolint:gosec // G115: schema is a small fixed list
+	t.endStruct()
+	for _, c := range p.columns {
+		t.beginListStruct()
+		t.i32(1, physicalType(c.Kind))
+		t.i32(3, parquetRepetitionRequired)
+		t.binary(4, c.Name)
+		switch c.Kind {
+		case ParquetString:
+			t.i32(6, parquetConvertedUTF8)
+		case ParquetTimestampMillis:
+			t.i32(6, parquetConvertedTimestampMillis)
+		}
+		t.endStruct()
+	}
+
+	t.i64(3, p.total)
+
+	t.listOfStructs(4, len(p.groups))
+	for _, rg := range p.groups {
+		t.beginListStruct()
+		t.listOfStructs(1, len(rg.chunks))
+		for _, ch := range rg.chunks {
+			t.beginListStruct()
+			t.i64(2, ch.pageOffset)
+			t.beginStruct(3)
+			t.i32(1, physicalType(ch.column.Kind))
+			t.listOfI32(2, []int32{parquetEncodingPlain, parquetEncodingRLE})
+			t.listOfStrings(3, []string{ch.column.Name})
+			t.i32(4, parquetCodecUncompressed)
+			t.i64(5, ch.numValues)
+			t.i64(6, ch.size)
+			t.i64(7, ch.size)
+			t.i64(9, ch.pageOffset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, rg.byteSize)
+		t.i64(3, rg.numRows)
+		t.endStruct()
+	}
+
+	t.binary(6, "vybe")
+	t.stop()
+	return t.buf
+}
+
+func physicalType(k ParquetColumnKind) int32 {
+	if k == ParquetString {
+		return parquetTypeByteArray
+	}
+	return parquetTypeInt64
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// Thrift compact protocol type ids.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter emits the Thrift compact protocol subset needed for Parquet metadata.
+type thriftWriter struct {
+	buf   []byte
+	last  int16
+	stack []int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	delta := id - t.last
+	if delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(zigzag64(int64(id)))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf = binary.AppendUvarint(t.buf, v)
+}
+
+func zigzag64(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63)) //nolint:gosec // G115: zigzag encoding reinterprets the sign bit by design
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(zigzag64(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(zigzag64(v))
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.varint(uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+func (t *thriftWriter) listHeader(size int, elem byte) {
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elem) //nolint:gosec // G115: size < 15
+		return
+	}
+	t.buf = append(t.buf, 0xF0|elem)
+	t.varint(uint64(size)) //nolint:gosec // G115: size is a non-negative count
+}
+
+func (t *thriftWriter) listOfI32(id int16, vals []int32) {
+	t.fieldHeader(id, thriftList)
+	t.listHeader(len(vals), thriftI32)
+	for _, v := range vals {
+		t.varint(zigzag64(int64(v)))
+	}
+}
+
+func (t *thriftWriter) listOfStrings(id int16, vals []string) {
+	t.fieldHeader(id, thriftList)
+	t.listHeader(len(vals), thriftBinary)
+	for _, v := range vals {
+		t.varint(uint64(len(v)))
+		t.buf = append(t.buf, v...)
+	}
+}
+
+// listOfStructs writes a list header; each element is then written with
+// beginListStruct ... endStruct.
+func (t *thriftWriter) listOfStructs(id int16, size int) {
+	t.fieldHeader(id, thriftList)
+	t.listHeader(size, thriftStruct)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) beginListStruct() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *thriftWriter) stop() {
+	t.buf = append(t.buf, 0)
+}
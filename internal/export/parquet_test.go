@@ -0,0 +1,148 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// thriftReader decodes Thrift compact structs into field-id maps, enough to
+// check the footer and page headers the writer produces.
+type thriftReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *thriftReader) uvarint(t *testing.T) uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	require.Positive(t, n)
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag(t *testing.T) int64 {
+	u := r.uvarint(t)
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r *thriftReader) value(t *testing.T, typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.zigzag(t)
+	case thriftBinary:
+		n := int(r.uvarint(t))
+		s := string(r.b[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		h := r.b[r.pos]
+		r.pos++
+		size, elem := int(h>>4), h&0x0F
+		if size == 15 {
+			size = int(r.uvarint(t))
+		}
+		out := make([]any, size)
+		for i := range out {
+			out[i] = r.value(t, elem)
+		}
+		return out
+	case thriftStruct:
+		return r.structure(t)
+	}
+	t.Fatalf("unexpected thrift type %d", typ)
+	return nil
+}
+
+func (r *thriftReader) structure(t *testing.T) map[int16]any {
+	out := map[int16]any{}
+	var last int16
+	for {
+		h := r.b[r.pos]
+		r.pos++
+		if h == 0 {
+			return out
+		}
+		delta, typ := int16(h>>4), h&0x0F
+		id := last + delta
+		if delta == 0 {
+			id = int16(r.zigzag(t))
+		}
+		out[id] = r.value(t, typ)
+		last = id
+	}
+}
+
+func TestParquetWriter_FooterAndPagesRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	ew, err := NewEventWriter(&buf, FormatParquet)
+	require.NoError(t, err)
+	ew.(*parquetEventWriter).w.RowGroupSize = 2
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := int64(1); i <= 3; i++ {
+		require.NoError(t, ew.Write(&models.Event{
+			ID: i, Kind: "progress", AgentName: "a1", TaskID: "t1",
+			Message: "step", Metadata: json.RawMessage(`{"n":1}`), CreatedAt: created,
+		}))
+	}
+	require.NoError(t, ew.Close())
+
+	b := buf.Bytes()
+	require.Equal(t, "PAR1", string(b[:4]))
+	require.Equal(t, "PAR1", string(b[len(b)-4:]))
+	footerLen := int(binary.LittleEndian.Uint32(b[len(b)-8 : len(b)-4]))
+	footer := &thriftReader{b: b[len(b)-8-footerLen : len(b)-8]}
+	meta := footer.structure(t)
+	require.Equal(t, footerLen, footer.pos)
+
+	require.Equal(t, int64(3), meta[3], "num_rows")
+	schema := meta[2].([]any)
+	require.Len(t, schema, len(eventColumns)+1)
+	require.Equal(t, int64(len(eventColumns)), schema[0].(map[int16]any)[5])
+
+	groups := meta[4].([]any)
+	require.Len(t, groups, 2, "RowGroupSize=2 splits 3 rows into two groups")
+	require.Equal(t, int64(1), groups[1].(map[int16]any)[3])
+
+	// Read the "kind" column (index 1) of the first row group back from its page.
+	chunk := groups[0].(map[int16]any)[1].([]any)[1].(map[int16]any)
+	colMeta := chunk[3].(map[int16]any)
+	require.Equal(t, []any{"kind"}, colMeta[3])
+	page := &thriftReader{b: b, pos: int(colMeta[9].(int64))}
+	header := page.structure(t)
+	require.Equal(t, int64(2), header[5].(map[int16]any)[1], "num_values")
+	data := b[page.pos : page.pos+int(header[3].(int64))]
+	for range 2 {
+		n := int(binary.LittleEndian.Uint32(data))
+		require.Equal(t, "progress", string(data[4:4+n]))
+		data = data[4+n:]
+	}
+	require.Empty(t, data)
+
+	// created_at is stored as epoch milliseconds.
+	tsMeta := groups[0].(map[int16]any)[1].([]any)[7].(map[int16]any)[3].(map[int16]any)
+	page = &thriftReader{b: b, pos: int(tsMeta[9].(int64))}
+	page.structure(t)
+	require.Equal(t, created.UnixMilli(), int64(binary.LittleEndian.Uint64(b[page.pos:])))
+}
+
+func TestNewEventWriter_CSVHeaderAndRow(t *testing.T) {
+	var buf bytes.Buffer
+	ew, err := NewEventWriter(&buf, FormatCSV)
+	require.NoError(t, err)
+	require.NoError(t, ew.Write(&models.Event{ID: 7, Kind: "note", AgentName: "a1", Message: "hi, there",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}))
+	require.NoError(t, ew.Close())
+	require.Equal(t,
+		"id,kind,agent_name,project_id,task_id,message,metadata,created_at\n7,note,a1,,,\"hi, there\",,2026-01-02T03:04:05Z\n",
+		buf.String())
+
+	_, err = ParseFormat("xlsx")
+	require.Error(t, err)
+}
@@ -7,18 +7,77 @@ const (
 	EventKindTaskStatus        = "task_status"
 	EventKindProjectCreated    = "project_created"
 	EventKindProjectDeleted    = "project_deleted"
+	EventKindProjectArchived   = "project_archived"
+	EventKindProjectUnarchived = "project_unarchived"
+	EventKindProjectPurged     = "project_purged"
 	EventKindArtifactAdded     = "artifact_added"
 	EventKindAgentFocus        = "agent_focus"
 	EventKindAgentProjectFocus = "agent_project_focus"
+	EventKindAgentRegistered   = "agent_registered"
+	EventKindAgentEvicted      = "agent_evicted"
+	EventKindAgentLeaseSet     = "agent_lease_set"
 	EventKindMemoryUpserted    = "memory_upserted"
 	EventKindMemoryConflict    = "memory_conflict"
+	EventKindMemoryResolved    = "memory_conflict_resolved"
 	EventKindMemoryDelete      = "memory_delete"
 	EventKindMemoryGC          = "memory_gc"
 	EventKindMemoryPin         = "memory_pin"
+	EventKindMemoryCompacted   = "memory_compacted"
+	EventKindMemoryPromoted    = "memory_promoted"
+	EventKindMemoryDemoted     = "memory_demoted"
+	EventKindMemoryStaged      = "memory_promotion_staged"
+	EventKindMemoryReviewed    = "memory_promotion_reviewed"
+	EventKindMemoryProposed    = "memory_proposed"
+	EventKindProposalReviewed  = "memory_proposal_reviewed"
+	EventKindLessonAdded       = "lesson_added"
+	EventKindLessonRanked      = "lesson_ranked"
+	EventKindLessonFeedback    = "lesson_feedback"
 	EventKindEventsSummary     = "events_summary"
 	EventKindTaskClosed        = "task_closed"
 	EventKindRunCompleted      = "run_completed"
+	EventKindLoopIteration     = "loop_iteration"
 	EventKindCheckpoint        = "checkpoint"
+	EventKindMemoryIngested    = "memory_ingested"
+	EventKindTaskContention    = "task_contention"
+	EventKindCriterionAdded    = "task_criterion_added"
+	EventKindCriterionChecked  = "task_criterion_checked"
+	EventKindTaskMetaSet       = "task_meta_set"
+	EventKindTaskMetaUnset     = "task_meta_unset"
+	EventKindTaskTagged        = "task_tagged"
+	EventKindTaskUntagged      = "task_untagged"
+	EventKindMessageSent       = "message_sent"
+	EventKindTaskDueSet        = "task_due_set"
+	EventKindTaskOverdue       = "task_overdue"
+	EventKindTaskStale         = "task_stale"
+	EventKindTaskRequeued      = "task_requeued"
+	EventKindTaskFailed        = "task_failed"
+	EventKindTaskSizeSet       = "task_size_set"
+	EventKindTaskLeaseSet      = "task_lease_set"
+	EventKindTaskLeaseExpired  = "task_lease_expired"
+	EventKindTaskStolen        = "task_stolen"
+	EventKindDependencyAdded   = "task_dependency_added"
+	EventKindTaskGraphRepaired = "task_graph_repaired"
+	EventKindEventsDeduped     = "events_deduped"
+	EventKindSessionStarted    = "session_started"
+	EventKindSessionEnded      = "session_ended"
+	EventKindSessionLabeled    = "session_labeled"
+	EventKindRetrospective     = "session_retrospective"
+	EventKindTranscriptIngest  = "transcript_ingested"
+	EventKindDBMaintained      = "db_maintained"
+	EventKindDBRestored        = "db_restored"
+	EventKindSnapshotCreated   = "snapshot_created"
+	EventKindSnapshotRestored  = "snapshot_restored"
+	EventKindGuardDecision     = "guard_decision"
+	EventKindTaskDelegated     = "task_delegated"
+	EventKindSubagentStarted   = "subagent_started"
+	EventKindSubagentStopped   = "subagent_stopped"
+	EventKindEventChainStarted = "event_chain_started"
+	EventKindTokenCreated      = "token_created"
+	EventKindTokenRevoked      = "token_revoked"
+	EventKindAgentConfined     = "agent_confined"
+	EventKindAgentReleased     = "agent_released"
+	EventKindKindRegistered    = "event_kind_registered"
+	EventKindKindRemoved       = "event_kind_removed"
 )
 
 // Agent event kinds with system significance.
@@ -30,3 +89,45 @@ const (
 	EventKindToolFailure = "tool_failure"
 	EventKindProgress    = "progress"
 )
+
+// Transcript event kinds, written by `ingest transcript` alongside
+// EventKindUserPrompt and EventKindToolFailure.
+const (
+	EventKindToolSuccess      = "tool_success"
+	EventKindAssistantSummary = "assistant_summary"
+)
+
+// EventKindTaskBlocked is logged by the loop runner when it gives up on a task.
+const EventKindTaskBlocked = "task_blocked"
+
+// BuiltinEventKinds returns every kind vybe itself writes or gives meaning
+// to. 'vybe events kinds' lists them next to the custom kinds registered in
+// the database, and strict kinds mode accepts both.
+func BuiltinEventKinds() []string {
+	return []string{
+		EventKindTaskCreated, EventKindTaskDeleted, EventKindTaskStatus, EventKindTaskClosed,
+		EventKindTaskContention, EventKindTaskMetaSet, EventKindTaskMetaUnset, EventKindTaskTagged,
+		EventKindTaskUntagged, EventKindTaskDueSet, EventKindTaskOverdue, EventKindTaskStale, EventKindTaskRequeued,
+		EventKindTaskFailed, EventKindTaskSizeSet, EventKindTaskBlocked, EventKindTaskDelegated,
+		EventKindTaskLeaseSet, EventKindTaskLeaseExpired, EventKindTaskStolen,
+		EventKindCriterionAdded, EventKindCriterionChecked, EventKindDependencyAdded, EventKindTaskGraphRepaired,
+		EventKindProjectCreated, EventKindProjectDeleted, EventKindProjectArchived, EventKindProjectUnarchived,
+		EventKindProjectPurged, EventKindArtifactAdded,
+		EventKindAgentFocus, EventKindAgentProjectFocus, EventKindAgentRegistered, EventKindAgentEvicted,
+		EventKindAgentConfined, EventKindAgentReleased, EventKindAgentLeaseSet,
+		EventKindMemoryUpserted, EventKindMemoryConflict, EventKindMemoryResolved, EventKindMemoryDelete,
+		EventKindMemoryGC, EventKindMemoryPin, EventKindMemoryCompacted, EventKindMemoryPromoted, EventKindMemoryStaged,
+		EventKindMemoryReviewed, EventKindMemoryDemoted, EventKindMemoryIngested,
+		EventKindMemoryProposed, EventKindProposalReviewed,
+		EventKindLessonAdded, EventKindLessonRanked, EventKindLessonFeedback,
+		EventKindEventsSummary, EventKindEventsDeduped, EventKindEventChainStarted,
+		EventKindKindRegistered, EventKindKindRemoved,
+		EventKindRunCompleted, EventKindLoopIteration, EventKindCheckpoint, EventKindMessageSent,
+		EventKindSessionStarted, EventKindSessionEnded, EventKindSessionLabeled, EventKindRetrospective,
+		EventKindTranscriptIngest, EventKindSubagentStarted, EventKindSubagentStopped, EventKindGuardDecision,
+		EventKindDBMaintained, EventKindDBRestored, EventKindSnapshotCreated, EventKindSnapshotRestored,
+		EventKindTokenCreated, EventKindTokenRevoked,
+		EventKindUserPrompt, EventKindReasoning, EventKindToolFailure, EventKindProgress,
+		EventKindToolSuccess, EventKindAssistantSummary,
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"slices"
 	"strings"
 	"time"
 )
@@ -25,6 +26,58 @@ const (
 	TaskStatusBlocked    TaskStatus = "blocked"
 )
 
+// TaskSize is a t-shirt effort estimate used for capacity planning.
+type TaskSize string
+
+// Task size constants, smallest to largest.
+const (
+	TaskSizeXS TaskSize = "xs"
+	TaskSizeS  TaskSize = "s"
+	TaskSizeM  TaskSize = "m"
+	TaskSizeL  TaskSize = "l"
+	TaskSizeXL TaskSize = "xl"
+)
+
+// taskSizePoints maps sizes to capacity points on a Fibonacci-like scale.
+var taskSizePoints = map[TaskSize]int{
+	TaskSizeXS: 1,
+	TaskSizeS:  2,
+	TaskSizeM:  3,
+	TaskSizeL:  5,
+	TaskSizeXL: 8,
+}
+
+// Points returns the capacity points for the size, or 0 when unsized or invalid.
+func (s TaskSize) Points() int {
+	return taskSizePoints[s]
+}
+
+// IsValid reports whether s is one of the known sizes.
+func (s TaskSize) IsValid() bool {
+	_, ok := taskSizePoints[s]
+	return ok
+}
+
+// TaskOutcome says how a completed task was resolved.
+type TaskOutcome string
+
+// Task outcomes. A task completed without an explicit outcome counts as done.
+const (
+	TaskOutcomeDone       TaskOutcome = "done"       // the work was delivered
+	TaskOutcomePartial    TaskOutcome = "partial"    // some of the work was delivered
+	TaskOutcomeWontFix    TaskOutcome = "wontfix"    // closed deliberately without doing it
+	TaskOutcomeDuplicate  TaskOutcome = "duplicate"  // another task covers the same work
+	TaskOutcomeSuperseded TaskOutcome = "superseded" // replaced by another task (superseded_by)
+)
+
+// TaskOutcomes lists the valid outcomes in display order.
+var TaskOutcomes = []TaskOutcome{TaskOutcomeDone, TaskOutcomePartial, TaskOutcomeWontFix, TaskOutcomeDuplicate, TaskOutcomeSuperseded}
+
+// IsValid reports whether o is one of the known outcomes.
+func (o TaskOutcome) IsValid() bool {
+	return slices.Contains(TaskOutcomes, o)
+}
+
 // MemoryScope represents the visibility scope of a memory entry.
 type MemoryScope string
 
@@ -72,6 +125,9 @@ type Event struct {
 	Message   string          `json:"message"`
 	Metadata  json.RawMessage `json:"metadata"`
 	CreatedAt time.Time       `json:"created_at"`
+	// RollupCount is set on brief events that stand for several identical
+	// tool events (the newest is kept); 0 everywhere else.
+	RollupCount int `json:"rollup_count,omitzero"`
 }
 
 // BlockedReason represents why a task is blocked.
@@ -109,9 +165,48 @@ type Task struct {
 	Priority      int           `json:"priority"`
 	ProjectID     string        `json:"project_id,omitempty"`
 	BlockedReason BlockedReason `json:"blocked_reason,omitempty"`
+	DueAt         *time.Time    `json:"due_at,omitempty"`
+	Size          TaskSize      `json:"size,omitempty"`
+	Attempts      int           `json:"attempts,omitempty"`      // failed attempts (task fail, or the loop blocking it)
+	RetryAt       *time.Time    `json:"retry_at,omitempty"`      // when a failure-blocked task goes back to pending
+	Outcome       TaskOutcome   `json:"outcome,omitempty"`       // how a completed task was resolved
+	SupersededBy  string        `json:"superseded_by,omitempty"` // the task that replaces or duplicates this one
 	Version       int           `json:"version"`
 	CreatedAt     time.Time     `json:"created_at"`
 	UpdatedAt     time.Time     `json:"updated_at"`
+	// Metadata holds typed integration fields (reviewer, PR URL, ...); only populated by task get.
+	Metadata map[string]any `json:"metadata,omitempty"`
+	// Tags are free-form routing labels; populated by task get, list, next, claim, and briefs.
+	Tags []string `json:"tags,omitempty"`
+	// Requires lists capabilities an agent must have registered (agent register)
+	// to be routed the task by resume or task claim; populated by task get and claim.
+	Requires []string `json:"requires,omitempty"`
+}
+
+// IsOverdue reports whether the task has a deadline before now and is not completed.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.DueAt != nil && t.Status != TaskStatusCompleted && t.DueAt.Before(now)
+}
+
+// TaskMeta is one typed key/value entry in a task's metadata bag.
+type TaskMeta struct {
+	TaskID    string    `json:"task_id"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	ValueType string    `json:"value_type"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaskCriterion is one acceptance-criteria checklist item on a task.
+type TaskCriterion struct {
+	ID        int64      `json:"id"`
+	TaskID    string     `json:"task_id"`
+	Text      string     `json:"text"`
+	Done      bool       `json:"done"`
+	CheckedBy string     `json:"checked_by,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 // AgentState tracks the last known state for an agent
@@ -154,6 +249,148 @@ func (m *Memory) IsExpired(now time.Time) bool {
 	return m.ExpiresAt != nil && m.ExpiresAt.Before(now)
 }
 
+// Memory history change kinds.
+const (
+	MemoryChangeCreated = "created"
+	MemoryChangeUpdated = "updated"
+	MemoryChangeDeleted = "deleted"
+)
+
+// Memory promotion statuses.
+const (
+	MemoryPromotionPending  = "pending"
+	MemoryPromotionApproved = "approved"
+	MemoryPromotionRejected = "rejected"
+)
+
+// MemoryPromotion is a memory entry staged for copying into a wider scope.
+// Value, ValueType, and Kind are captured when it is staged; approving writes
+// exactly that value, even if the source changed since. Move deletes the
+// source entry once the copy is written.
+type MemoryPromotion struct {
+	ID          int64      `json:"id"`
+	Key         string     `json:"key"`
+	FromScope   string     `json:"from_scope"`
+	FromScopeID string     `json:"from_scope_id,omitzero"`
+	ToScope     string     `json:"to_scope"`
+	ToScopeID   string     `json:"to_scope_id,omitzero"`
+	Value       string     `json:"value"`
+	ValueType   string     `json:"value_type"`
+	Kind        string     `json:"kind"`
+	Move        bool       `json:"move,omitzero"`
+	Status      string     `json:"status"`
+	RequestedBy string     `json:"requested_by"`
+	ReviewedBy  string     `json:"reviewed_by,omitzero"`
+	ReviewNote  string     `json:"review_note,omitzero"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// Memory proposal statuses.
+const (
+	MemoryProposalPending  = "pending"
+	MemoryProposalAccepted = "accepted"
+	MemoryProposalRejected = "rejected"
+)
+
+// MemoryProposal is a memory fact the extractor found in an event, staged for
+// review. Rule names the pattern that matched and Excerpt the text it matched
+// in; accepting writes Value (or an edited value) under Key.
+type MemoryProposal struct {
+	ID            int64       `json:"id"`
+	Key           string      `json:"key"`
+	Value         string      `json:"value"`
+	ValueType     string      `json:"value_type"`
+	Scope         MemoryScope `json:"scope"`
+	ScopeID       string      `json:"scope_id,omitzero"`
+	Rule          string      `json:"rule"`
+	Excerpt       string      `json:"excerpt"`
+	SourceEventID int64       `json:"source_event_id"`
+	SourceTaskID  string      `json:"source_task_id,omitzero"`
+	Status        string      `json:"status"`
+	ProposedBy    string      `json:"proposed_by"`
+	ReviewedBy    string      `json:"reviewed_by,omitzero"`
+	ReviewNote    string      `json:"review_note,omitzero"`
+	CreatedAt     time.Time   `json:"created_at"`
+	ReviewedAt    *time.Time  `json:"reviewed_at,omitempty"`
+}
+
+// Memory conflict statuses. A conflict is superseded when another conflict
+// on the same key is resolved first.
+const (
+	MemoryConflictOpen       = "open"
+	MemoryConflictResolved   = "resolved"
+	MemoryConflictSuperseded = "superseded"
+)
+
+// Memory conflict resolutions: keep the earlier value, keep the later one, or
+// write a merged value.
+const (
+	MemoryConflictKeepA = "a"
+	MemoryConflictKeepB = "b"
+	MemoryConflictMerge = "merge"
+)
+
+// MemoryConflict records agent B writing ValueB over ValueA, written by a
+// different agent A. ValueB stays live until the conflict is resolved.
+type MemoryConflict struct {
+	ID            int64       `json:"id"`
+	Key           string      `json:"key"`
+	Scope         MemoryScope `json:"scope"`
+	ScopeID       string      `json:"scope_id,omitzero"`
+	ValueA        string      `json:"value_a"`
+	AgentA        string      `json:"agent_a"`
+	ValueB        string      `json:"value_b"`
+	AgentB        string      `json:"agent_b"`
+	ValueType     string      `json:"value_type"`
+	Status        string      `json:"status"`
+	Resolution    string      `json:"resolution,omitzero"`
+	ResolvedValue *string     `json:"resolved_value,omitempty"`
+	ResolvedBy    string      `json:"resolved_by,omitzero"`
+	CreatedAt     time.Time   `json:"created_at"`
+	ResolvedAt    *time.Time  `json:"resolved_at,omitempty"`
+}
+
+// MemoryValueTypeDecision is the memory value type of a decision record. Its
+// value is a DecisionRecord encoded as JSON.
+const MemoryValueTypeDecision = "decision"
+
+// DecisionRecord is an architecture decision kept in memory: the situation
+// that forced it, what was decided, what was rejected, and what follows.
+type DecisionRecord struct {
+	Context      string   `json:"context,omitzero"`
+	Decision     string   `json:"decision"`
+	Alternatives []string `json:"alternatives,omitempty"`
+	Consequences string   `json:"consequences,omitzero"`
+}
+
+// Decision is a decision record together with the memory entry holding it.
+type Decision struct {
+	Key     string      `json:"key"`
+	Scope   MemoryScope `json:"scope"`
+	ScopeID string      `json:"scope_id,omitzero"`
+	DecisionRecord
+	Pinned    bool      `json:"pinned,omitzero"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MemoryHistoryEntry records one value change of a memory key. OldValue is nil
+// for created entries; NewValue is nil for deleted entries.
+type MemoryHistoryEntry struct {
+	ID        int64       `json:"id"`
+	Key       string      `json:"key"`
+	Scope     MemoryScope `json:"scope"`
+	ScopeID   string      `json:"scope_id"`
+	Change    string      `json:"change"`
+	OldValue  *string     `json:"old_value,omitempty"`
+	NewValue  *string     `json:"new_value,omitempty"`
+	ValueType string      `json:"value_type"`
+	AgentName string      `json:"agent_name,omitzero"`
+	EventID   *int64      `json:"event_id,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
 // Artifact represents a file or output artifact
 type Artifact struct {
 	ID          string    `json:"id"`
@@ -161,13 +398,55 @@ type Artifact struct {
 	EventID     int64     `json:"event_id"`
 	FilePath    string    `json:"file_path"`
 	ContentType string    `json:"content_type"`
+	ContentHash string    `json:"content_hash,omitempty"`
+	ContentSize int64     `json:"content_size,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// Message is a note sent from one agent to another's inbox.
+type Message struct {
+	ID        string     `json:"id"`
+	FromAgent string     `json:"from_agent"`
+	ToAgent   string     `json:"to_agent"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body,omitempty"`
+	TaskID    string     `json:"task_id,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Session outcomes. SessionOutcomeCompleted and SessionOutcomeIncomplete are
+// derived when a session ends without an explicit outcome.
+const (
+	SessionOutcomeCompleted  = "completed"
+	SessionOutcomeIncomplete = "incomplete"
+	SessionOutcomeFailed     = "failed"
+	SessionOutcomeAbandoned  = "abandoned"
+)
+
+// Session is one client session (e.g. a Claude Code conversation) of an agent,
+// opened by the session-start hook and closed by session-end or `session end`.
+type Session struct {
+	ID             string     `json:"id"`
+	AgentName      string     `json:"agent_name"`
+	Label          string     `json:"label,omitempty"`
+	ProjectID      string     `json:"project_id,omitempty"`
+	Source         string     `json:"source,omitempty"`
+	StartedAt      time.Time  `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	EndReason      string     `json:"end_reason,omitempty"`
+	Outcome        string     `json:"outcome,omitempty"`
+	StartEventID   int64      `json:"start_event_id"`
+	EndEventID     int64      `json:"end_event_id,omitempty"`
+	EventCount     int        `json:"event_count"`
+	TasksCompleted int        `json:"tasks_completed"`
+}
+
 // Project represents a project in the system
 type Project struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Metadata  string    `json:"metadata"` // JSON string
-	CreatedAt time.Time `json:"created_at"`
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Metadata   string     `json:"metadata"` // JSON string
+	CreatedAt  time.Time  `json:"created_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
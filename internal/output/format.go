@@ -0,0 +1,344 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how PrintWith renders a value.
+type Format string
+
+const (
+	// FormatJSON is the default: one compact JSON envelope per command.
+	FormatJSON Format = "json"
+	// FormatYAML renders the same envelope as YAML.
+	FormatYAML Format = "yaml"
+	// FormatTable renders the envelope's data as an aligned text table.
+	FormatTable Format = "table"
+	// FormatQuiet prints only the primary IDs in the envelope's data, one per line.
+	FormatQuiet Format = "quiet"
+)
+
+// tableCellRunes caps each table cell so long descriptions do not wrap rows.
+const tableCellRunes = 48
+
+// idFields are the fields FormatQuiet prints, in order of preference.
+var idFields = []string{"id", "key", "name", "path"} //nolint:gochecknoglobals // read-only lookup table
+
+// current is the process-wide format set from the root --format flag. Commands
+// run one at a time (the daemon serializes requests), so no locking is needed.
+var current = FormatJSON //nolint:gochecknoglobals // set once per command from --format
+
+// ParseFormat validates a --format value.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatJSON, FormatYAML, FormatTable, FormatQuiet:
+		return f, nil
+	case "":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("invalid --format %q (valid: json, table, quiet, yaml)", s)
+}
+
+// SetFormat sets the format Print, PrintSuccess, and PrintError use.
+func SetFormat(f Format) {
+	if f == "" {
+		f = FormatJSON
+	}
+	current = f
+}
+
+// CurrentFormat returns the format set by SetFormat.
+func CurrentFormat() Format {
+	return current
+}
+
+// render writes v in a non-JSON format. A Response is unwrapped for table and
+// quiet output: its data on success, its error otherwise.
+func render(w io.Writer, f Format, v any) error {
+	if resp, ok := v.(Response); ok && f != FormatYAML {
+		if !resp.Success {
+			if f == FormatQuiet {
+				return nil // the exit status and the stderr log carry the failure
+			}
+			msg := "error: " + resp.Error + "\n"
+			if resp.SuggestedAction != "" {
+				msg += "hint: " + resp.SuggestedAction + "\n"
+			}
+			_, err := io.WriteString(w, msg)
+			return err
+		}
+		v = resp.Data
+	}
+
+	node, err := toNode(v)
+	if err != nil {
+		return err
+	}
+	switch f {
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(node); err != nil {
+			return fmt.Errorf("failed to encode yaml: %w", err)
+		}
+		return enc.Close()
+	case FormatQuiet:
+		return writeQuiet(w, node)
+	default:
+		return writeTable(w, node)
+	}
+}
+
+// toNode converts v to a YAML node through its JSON encoding, so field names
+// and omitempty follow the json tags and key order is preserved.
+func toNode(v any) (*yaml.Node, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to convert output: %w", err)
+	}
+	node := &doc
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 {
+		node = doc.Content[0]
+	}
+	resetStyle(node)
+	return node, nil
+}
+
+// resetStyle drops the flow and quoting styles the JSON source implies, so the
+// YAML encoder picks block style and quotes only where needed.
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
+
+// primaryRows returns the list a table or quiet rendering is about: n itself
+// when it is a sequence, else the first sequence-of-objects field of n (or
+// its first sequence field when none holds objects).
+func primaryRows(n *yaml.Node) *yaml.Node {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		return n
+	case yaml.MappingNode:
+		var first *yaml.Node
+		for i := 1; i < len(n.Content); i += 2 {
+			v := n.Content[i]
+			if v.Kind != yaml.SequenceNode {
+				continue
+			}
+			if len(v.Content) > 0 && v.Content[0].Kind == yaml.MappingNode {
+				return v
+			}
+			if first == nil {
+				first = v
+			}
+		}
+		return first
+	}
+	return nil
+}
+
+func writeQuiet(w io.Writer, n *yaml.Node) error {
+	var ids []string
+	if rows := primaryRows(n); rows != nil {
+		for _, row := range rows.Content {
+			if id := primaryID(row); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	} else if id := primaryID(n); id != "" {
+		ids = append(ids, id)
+	} else if n.Kind == yaml.MappingNode {
+		// e.g. {"task": {...}}: the first nested object with an ID.
+		for i := 1; i < len(n.Content); i += 2 {
+			v := n.Content[i]
+			if v.Kind != yaml.MappingNode {
+				continue
+			}
+			if id := primaryID(v); id != "" {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	for _, id := range ids {
+		if _, err := fmt.Fprintln(w, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// primaryID returns a scalar's value or an object's first idFields value.
+func primaryID(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" {
+			return ""
+		}
+		return n.Value
+	case yaml.MappingNode:
+		for _, name := range idFields {
+			if v := mappingValue(n, name); v != nil && v.Kind == yaml.ScalarNode && v.Value != "" {
+				return v.Value
+			}
+		}
+	}
+	return ""
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// writeTable prints the primary list as columns of its scalar fields, then the
+// remaining scalar fields of the enclosing object as "key: value" lines. An
+// object without a list prints as a two-column field/value table.
+func writeTable(w io.Writer, n *yaml.Node) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	rows := primaryRows(n)
+	switch {
+	case rows != nil:
+		writeRows(tw, rows)
+		if n.Kind == yaml.MappingNode {
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			tw = tabwriter.NewWriter(&buf, 0, 0, 1, ' ', 0)
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if v := n.Content[i+1]; v != rows && v.Kind == yaml.ScalarNode && v.Tag != "!!null" {
+					fmt.Fprintf(tw, "%s:\t%s\n", n.Content[i].Value, cell(v))
+				}
+			}
+		}
+	case n.Kind == yaml.MappingNode:
+		fmt.Fprintln(tw, "FIELD\tVALUE")
+		writeFields(tw, "", n, 0)
+	default:
+		fmt.Fprintln(tw, cell(n))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeRows(tw io.Writer, rows *yaml.Node) {
+	if len(rows.Content) == 0 {
+		fmt.Fprintln(tw, "(none)")
+		return
+	}
+	if rows.Content[0].Kind != yaml.MappingNode {
+		for _, r := range rows.Content {
+			fmt.Fprintln(tw, cell(r))
+		}
+		return
+	}
+
+	// Columns: scalar (or scalar-list) fields in first-seen order, dropping
+	// fields that are empty in every row.
+	var cols []string
+	seen := map[string]bool{}
+	for _, r := range rows.Content {
+		for i := 0; i+1 < len(r.Content); i += 2 {
+			k, v := r.Content[i].Value, r.Content[i+1]
+			if seen[k] || !tabular(v) || cell(v) == "" {
+				continue
+			}
+			seen[k] = true
+			cols = append(cols, k)
+		}
+	}
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, r := range rows.Content {
+		vals := make([]string, len(cols))
+		for i, c := range cols {
+			if v := mappingValue(r, c); v != nil {
+				vals[i] = cell(v)
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(vals, "\t"))
+	}
+}
+
+// writeFields prints an object's fields, flattening nested objects one level
+// as "parent.child".
+func writeFields(tw io.Writer, prefix string, n *yaml.Node, depth int) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := prefix+n.Content[i].Value, n.Content[i+1]
+		switch {
+		case v.Kind == yaml.MappingNode && depth == 0:
+			writeFields(tw, k+".", v, depth+1)
+		case v.Kind == yaml.MappingNode:
+			fmt.Fprintf(tw, "%s\t{%d fields}\n", k, len(v.Content)/2)
+		case v.Kind == yaml.SequenceNode && !tabular(v):
+			fmt.Fprintf(tw, "%s\t[%d items]\n", k, len(v.Content))
+		default:
+			fmt.Fprintf(tw, "%s\t%s\n", k, cell(v))
+		}
+	}
+}
+
+// tabular reports whether v fits in one cell: a scalar or a list of scalars.
+func tabular(v *yaml.Node) bool {
+	switch v.Kind {
+	case yaml.ScalarNode:
+		return true
+	case yaml.SequenceNode:
+		for _, c := range v.Content {
+			if c.Kind != yaml.ScalarNode {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func cell(v *yaml.Node) string {
+	var s string
+	switch v.Kind {
+	case yaml.ScalarNode:
+		if v.Tag == "!!null" {
+			return ""
+		}
+		s = v.Value
+	case yaml.SequenceNode:
+		parts := make([]string, 0, len(v.Content))
+		for _, c := range v.Content {
+			parts = append(parts, cell(c))
+		}
+		s = strings.Join(parts, ",")
+	default:
+		return ""
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) > tableCellRunes {
+		s = string([]rune(s)[:tableCellRunes-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type formatRow struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Note  string   `json:"note,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+	Meta  struct {
+		A int `json:"a"`
+	} `json:"meta"`
+}
+
+type formatList struct {
+	Rows  []formatRow `json:"rows"`
+	Count int         `json:"count"`
+}
+
+func printFormatted(t *testing.T, f Format, v any) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, PrintWith(Config{Writer: &buf, Format: f}, v))
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"json", "TABLE", " quiet ", "yaml"} {
+		_, err := ParseFormat(s)
+		require.NoError(t, err, s)
+	}
+	f, err := ParseFormat("")
+	require.NoError(t, err)
+	require.Equal(t, FormatJSON, f)
+
+	_, err = ParseFormat("xml")
+	require.ErrorContains(t, err, "valid: json, table, quiet, yaml")
+}
+
+func TestPrintWith_TableRendersPrimaryList(t *testing.T) {
+	data := formatList{Rows: []formatRow{{ID: "t1", Title: "First", Tags: []string{"a", "b"}}, {ID: "t2", Title: "Second\nline"}}, Count: 2}
+
+	out := printFormatted(t, FormatTable, Success(data))
+	require.Equal(t, "ID  TITLE        TAGS\n"+
+		"t1  First        a,b\n"+
+		"t2  Second line  \n"+
+		"count: 2\n", out)
+
+	out = printFormatted(t, FormatTable, Success(formatList{Rows: []formatRow{}}))
+	require.Contains(t, out, "(none)")
+}
+
+func TestPrintWith_TableRendersObjectFields(t *testing.T) {
+	out := printFormatted(t, FormatTable, Success(map[string]any{"task": formatRow{ID: "t1", Title: "First"}}))
+	require.Contains(t, out, "FIELD")
+	require.Contains(t, out, "task.id")
+	require.Contains(t, out, "task.meta")
+	require.Contains(t, out, "{1 fields}")
+}
+
+func TestPrintWith_QuietPrintsIDs(t *testing.T) {
+	data := formatList{Rows: []formatRow{{ID: "t1"}, {ID: "t2"}}, Count: 2}
+	require.Equal(t, "t1\nt2\n", printFormatted(t, FormatQuiet, Success(data)))
+
+	// A single object nested under a field prints its ID.
+	require.Equal(t, "t9\n", printFormatted(t, FormatQuiet, Success(map[string]any{"agent_name": "a", "task": formatRow{ID: "t9"}})))
+
+	// Falls back to key when there is no id.
+	require.Equal(t, "k1\n", printFormatted(t, FormatQuiet, Success(map[string]string{"key": "k1", "value": "v"})))
+
+	// Failures print nothing; the exit status reports them.
+	require.Empty(t, printFormatted(t, FormatQuiet, Error(errors.New("boom"))))
+}
+
+func TestPrintWith_YAMLKeepsEnvelope(t *testing.T) {
+	out := printFormatted(t, FormatYAML, Success(map[string]any{"id": "123", "count": 4}))
+	require.Equal(t, "schema_version: v1\nsuccess: true\ndata:\n  count: 4\n  id: \"123\"\n", out)
+}
+
+func TestPrintWith_TableShowsErrors(t *testing.T) {
+	out := printFormatted(t, FormatTable, Error(errors.New("boom")))
+	require.Equal(t, "error: boom\n", out)
+}
+
+func TestSetFormat_DrivesDefaultConfig(t *testing.T) {
+	t.Cleanup(func() { SetFormat(FormatJSON) })
+
+	SetFormat(FormatQuiet)
+	require.Equal(t, FormatQuiet, DefaultConfig().Format)
+
+	SetFormat("")
+	require.Equal(t, FormatJSON, CurrentFormat())
+}
@@ -33,6 +33,7 @@ type Response struct {
 type Config struct {
 	Writer io.Writer
 	Pretty bool
+	Format Format // "" means FormatJSON
 }
 
 // DefaultConfig returns configuration using stdout and environment
@@ -41,6 +42,7 @@ func DefaultConfig() Config {
 	return Config{
 		Writer: os.Stdout,
 		Pretty: pretty,
+		Format: current,
 	}
 }
 
@@ -69,16 +71,27 @@ func Error(err error) Response {
 	return resp
 }
 
-// PrintWith prints a value as JSON to the configured writer
+// PrintWith prints a value to the configured writer, as JSON unless cfg.Format
+// selects another rendering. With a schema set by SetResponseSchema, a
+// successful Response is then validated against it.
 func PrintWith(cfg Config, v any) error {
+	if cfg.Format != "" && cfg.Format != FormatJSON {
+		if err := render(cfg.Writer, cfg.Format, v); err != nil {
+			return err
+		}
+		return checkResponse(v)
+	}
 	enc := json.NewEncoder(cfg.Writer)
 	if cfg.Pretty {
 		enc.SetIndent("", "  ")
 	}
-	return enc.Encode(v)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return checkResponse(v)
 }
 
-// Print prints a value as JSON to stdout
+// Print prints a value to stdout in the format set by SetFormat.
 // Default to compact JSON to minimize token/output size for agent consumption.
 // Enable pretty JSON for humans via env var: VYBE_PRETTY_JSON=1.
 func Print(v any) error {
@@ -95,4 +108,5 @@ func PrintError(err error) error {
 	return Print(Error(err))
 }
 
-// Keep output package focused: commands should handle human-readable formatting.
+// Keep output package focused: it renders envelopes generically (see format.go);
+// commands handle command-specific human-readable formatting.
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaNode struct {
+	Name     string        `json:"name"`
+	Children []*schemaNode `json:"children,omitempty"`
+}
+
+type schemaBase struct {
+	ID string `json:"id"`
+}
+
+type schemaSample struct {
+	schemaBase
+	At      time.Time      `json:"at"`
+	Due     *time.Time     `json:"due,omitempty"`
+	Count   int64          `json:"count"`
+	Tags    []string       `json:"tags"`
+	Meta    map[string]any `json:"meta,omitzero"`
+	Root    *schemaNode    `json:"root"`
+	Skipped string         `json:"-"`
+	hidden  string
+}
+
+func TestJSONSchema_StructShapes(t *testing.T) {
+	s := JSONSchema(&schemaSample{hidden: "x"})
+	assert.Equal(t, jsonSchemaDialect, s["$schema"])
+	assert.Equal(t, "object", s["type"])
+	assert.Equal(t, []string{"id", "at", "count", "tags", "root"}, s["required"])
+
+	props, ok := s["properties"].(map[string]any)
+	require.True(t, ok)
+	assert.NotContains(t, props, "Skipped")
+	assert.NotContains(t, props, "hidden")
+	assert.Equal(t, map[string]any{"type": "string"}, props["id"], "embedded fields are flattened")
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, props["at"])
+	assert.Equal(t, map[string]any{"type": []string{"string", "null"}, "format": "date-time"}, props["due"])
+	assert.Equal(t, map[string]any{"type": "integer"}, props["count"])
+	assert.Equal(t, map[string]any{"type": []string{"array", "null"}, "items": map[string]any{"type": "string"}}, props["tags"])
+
+	ref := map[string]any{"$ref": "#/$defs/output.schemaNode"}
+	assert.Equal(t, map[string]any{"anyOf": []any{ref, map[string]any{"type": "null"}}}, props["root"])
+
+	defs, ok := s["$defs"].(map[string]any)
+	require.True(t, ok)
+	node, ok := defs["output.schemaNode"].(map[string]any)
+	require.True(t, ok, "recursive types resolve through $defs")
+	assert.Equal(t, []string{"name"}, node["required"])
+}
@@ -0,0 +1,179 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// responseSchema is the JSON Schema successful envelopes are checked against
+// when set (the root --validate flag). Like current, it is per command.
+var responseSchema map[string]any //nolint:gochecknoglobals // set once per command from --validate
+
+// SetResponseSchema makes PrintWith validate every successful Response against
+// schema after writing it. nil turns validation off.
+func SetResponseSchema(schema map[string]any) {
+	responseSchema = schema
+}
+
+// ResponseValidationError reports a response that did not match its published
+// schema. The response has already been written when it is returned.
+type ResponseValidationError struct {
+	Violations []string
+}
+
+func (e *ResponseValidationError) Error() string {
+	return "response does not match its schema: " + strings.Join(e.Violations, "; ")
+}
+
+// checkResponse validates v, if it is a successful Response, against the
+// schema set by SetResponseSchema.
+func checkResponse(v any) error {
+	resp, ok := v.(Response)
+	if responseSchema == nil || !ok || !resp.Success {
+		return nil
+	}
+	if violations := ValidateSchema(responseSchema, resp); len(violations) > 0 {
+		return &ResponseValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// ValidateSchema checks v's JSON encoding against schema and returns one
+// message per violation. It understands the subset of JSON Schema that
+// JSONSchema emits: type, properties, required, items,
+// additionalProperties, $ref into $defs, anyOf, and oneOf.
+func ValidateSchema(schema map[string]any, v any) []string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return []string{"value does not encode as JSON: " + err.Error()}
+	}
+	var doc any
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return []string{"value does not decode as JSON: " + err.Error()}
+	}
+	defs, _ := schema["$defs"].(map[string]any)
+	var out []string
+	validateNode(schema, defs, doc, "$", &out)
+	return out
+}
+
+func validateNode(s map[string]any, defs map[string]any, v any, path string, out *[]string) {
+	if ref, ok := s["$ref"].(string); ok {
+		def, _ := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		if def == nil {
+			*out = append(*out, path+": unresolved "+ref)
+			return
+		}
+		s = def
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		alts, ok := s[key].([]any)
+		if !ok {
+			continue
+		}
+		matched := false
+		for _, alt := range alts {
+			var sub []string
+			if m, ok := alt.(map[string]any); ok {
+				validateNode(m, defs, v, path, &sub)
+			}
+			if len(sub) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*out = append(*out, fmt.Sprintf("%s: matches none of %d %s alternatives", path, len(alts), key))
+			return
+		}
+	}
+
+	if types := schemaTypes(s["type"]); len(types) > 0 && !slices.Contains(types, jsonType(v)) &&
+		(jsonType(v) != "integer" || !slices.Contains(types, "number")) {
+		*out = append(*out, fmt.Sprintf("%s: got %s, want %s", path, jsonType(v), strings.Join(types, " or ")))
+		return
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		props, _ := s["properties"].(map[string]any)
+		for _, r := range schemaStrings(s["required"]) {
+			if _, ok := val[r]; !ok {
+				*out = append(*out, path+": missing required field "+r)
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := props[k].(map[string]any); ok {
+				validateNode(ps, defs, val[k], path+"."+k, out)
+			} else if ap, ok := s["additionalProperties"].(map[string]any); ok {
+				validateNode(ap, defs, val[k], path+"."+k, out)
+			} else if props != nil && s["additionalProperties"] == nil {
+				*out = append(*out, path+": undocumented field "+k)
+			}
+		}
+	case []any:
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, item := range val {
+				validateNode(items, defs, item, fmt.Sprintf("%s[%d]", path, i), out)
+			}
+		}
+	}
+}
+
+// jsonType names v's JSON Schema type.
+func jsonType(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if strings.ContainsAny(val.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func schemaTypes(t any) []string {
+	switch tt := t.(type) {
+	case string:
+		return []string{tt}
+	default:
+		return schemaStrings(t)
+	}
+}
+
+// schemaStrings reads a string list from a schema built in Go ([]string) or
+// decoded from JSON ([]any).
+func schemaStrings(v any) []string {
+	switch vv := v.(type) {
+	case []string:
+		return vv
+	case []any:
+		out := make([]string, 0, len(vv))
+		for _, x := range vv {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
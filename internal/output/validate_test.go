@@ -0,0 +1,52 @@
+package output
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type validateItem struct {
+	Name string `json:"name"`
+}
+
+type validateShape struct {
+	ID    int64          `json:"id"`
+	At    time.Time      `json:"at"`
+	Item  *validateItem  `json:"item"`
+	Items []validateItem `json:"items,omitempty"`
+	Note  string         `json:"note,omitempty"`
+}
+
+func TestValidateSchema(t *testing.T) {
+	schema := JSONSchema(validateShape{})
+
+	require.Empty(t, ValidateSchema(schema, validateShape{ID: 1, Items: []validateItem{{Name: "a"}}}))
+
+	require.Equal(t, []string{"$: missing required field item"},
+		ValidateSchema(schema, map[string]any{"id": 1, "at": "2026-01-01T00:00:00Z"}))
+
+	require.Equal(t, []string{"$.id: got string, want integer"},
+		ValidateSchema(schema, map[string]any{"id": "1", "at": "x", "item": nil}))
+
+	require.Equal(t, []string{"$: undocumented field extra", "$.items[0]: undocumented field other"},
+		ValidateSchema(schema, map[string]any{"id": 1, "at": "x", "item": nil, "extra": true, "items": []any{map[string]any{"name": "a", "other": 1}}}))
+
+	require.Equal(t, []string{"$.item: matches none of 2 anyOf alternatives"},
+		ValidateSchema(schema, map[string]any{"id": 1, "at": "x", "item": 3}))
+}
+
+func TestCheckResponse_OnlyValidatesSuccessEnvelopes(t *testing.T) {
+	t.Cleanup(func() { SetResponseSchema(nil) })
+	require.NoError(t, checkResponse(Success(validateItem{})))
+
+	schema := JSONSchema(Response{})
+	schema["properties"].(map[string]any)["data"] = JSONSchema(validateItem{})
+	SetResponseSchema(schema)
+
+	require.NoError(t, checkResponse(Success(validateItem{Name: "a"})))
+	require.NoError(t, checkResponse(Error(errors.New("boom"))))
+	require.Error(t, checkResponse(Success(map[string]any{"name": true})))
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// blockedStatusMsg is the task_status message written when a task moves to
+// blocked; the heatmap counts these as failures.
+const blockedStatusMsg = "Status changed to: blocked"
+
+// ActivityCounts tallies one heatmap bucket. Completions are tasks moved to
+// completed; failures are tool failures plus tasks moved to blocked.
+type ActivityCounts struct {
+	Events      int `json:"events"`
+	Completions int `json:"completions"`
+	Failures    int `json:"failures"`
+}
+
+// ActivityDay is one calendar day of activity, in the heatmap's time zone.
+type ActivityDay struct {
+	Day string `json:"day"` // YYYY-MM-DD
+	ActivityCounts
+}
+
+// ActivityHeatmap buckets events by hour of day, weekday, and calendar day.
+// ByWeekday and Grid rows start on Monday; Grid[weekday][hour] holds all three
+// counts so any of them can be rendered.
+type ActivityHeatmap struct {
+	ProjectID string                `json:"project_id,omitempty"`
+	SinceDays int                   `json:"since_days"`
+	Timezone  string                `json:"timezone"`
+	Totals    ActivityCounts        `json:"totals"`
+	ByHour    [24]ActivityCounts    `json:"by_hour"`
+	ByWeekday [7]ActivityCounts     `json:"by_weekday"`
+	ByDay     []ActivityDay         `json:"by_day"`
+	Grid      [7][24]ActivityCounts `json:"grid"`
+}
+
+// BuildActivityHeatmap counts events from the last sinceDays days (all
+// projects when projectID is empty), bucketed in loc. Archived events count:
+// summarizing history does not erase the work it recorded.
+func BuildActivityHeatmap(ctx context.Context, db *sql.DB, projectID string, sinceDays int, loc *time.Location) (*ActivityHeatmap, error) {
+	if sinceDays <= 0 {
+		sinceDays = 30
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	query := `SELECT kind, message, created_at FROM events WHERE created_at >= datetime('now', ?)`
+	args := []any{fmt.Sprintf("-%d days", sinceDays)}
+	if projectID != "" {
+		query += ` AND project_id = ?`
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY created_at ASC, id ASC`
+
+	var h *ActivityHeatmap
+	err := RetryWithBackoff(ctx, func() error {
+		h = &ActivityHeatmap{ProjectID: projectID, SinceDays: sinceDays, Timezone: loc.String(), ByDay: []ActivityDay{}}
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query events for heatmap: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		days := map[string]int{} // day -> index in ByDay
+		for rows.Next() {
+			var kind, message string
+			var at time.Time
+			if err := rows.Scan(&kind, &message, &at); err != nil {
+				return fmt.Errorf("failed to scan event: %w", err)
+			}
+			at = at.In(loc)
+			wd := (int(at.Weekday()) + 6) % 7 // Monday = 0
+			day := at.Format(time.DateOnly)
+			i, ok := days[day]
+			if !ok {
+				i = len(h.ByDay)
+				days[day] = i
+				h.ByDay = append(h.ByDay, ActivityDay{Day: day})
+			}
+			for _, c := range []*ActivityCounts{&h.Totals, &h.ByHour[at.Hour()], &h.ByWeekday[wd], &h.Grid[wd][at.Hour()], &h.ByDay[i].ActivityCounts} {
+				c.add(kind, message)
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (c *ActivityCounts) add(kind, message string) {
+	c.Events++
+	switch {
+	case kind == models.EventKindTaskStatus && message == sessionCompletedStatusMsg:
+		c.Completions++
+	case kind == models.EventKindTaskStatus && message == blockedStatusMsg,
+		kind == models.EventKindToolFailure:
+		c.Failures++
+	}
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestBuildActivityHeatmap(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Yesterday at 14:xx UTC, plus one event outside the window and one in another project.
+	day := time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+	at := day.Add(14*time.Hour + 5*time.Minute)
+	insert := func(kind, message, project string, when time.Time) {
+		t.Helper()
+		_, err := db.Exec(`INSERT INTO events (kind, agent_name, project_id, message, created_at) VALUES (?, 'agent1', ?, ?, ?)`,
+			kind, project, message, when.Format(time.DateTime))
+		require.NoError(t, err)
+	}
+	insert(models.EventKindTaskStatus, "Status changed to: completed", "/repo", at)
+	insert(models.EventKindTaskStatus, "Status changed to: blocked", "/repo", at)
+	insert(models.EventKindToolFailure, "Bash failed", "/repo", at.Add(time.Hour))
+	insert(models.EventKindProgress, "halfway", "/repo", at.Add(time.Hour))
+	insert(models.EventKindProgress, "other project", "/other", at)
+	insert(models.EventKindProgress, "too old", "/repo", day.AddDate(0, 0, -40))
+
+	h, err := BuildActivityHeatmap(context.Background(), db, "/repo", 7, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, ActivityCounts{Events: 4, Completions: 1, Failures: 2}, h.Totals)
+	assert.Equal(t, ActivityCounts{Events: 2, Completions: 1, Failures: 1}, h.ByHour[14])
+	assert.Equal(t, ActivityCounts{Events: 2, Failures: 1}, h.ByHour[15])
+
+	wd := (int(at.Weekday()) + 6) % 7
+	assert.Equal(t, h.Totals, h.ByWeekday[wd])
+	assert.Equal(t, h.ByHour[14], h.Grid[wd][14])
+	require.Len(t, h.ByDay, 1)
+	assert.Equal(t, day.Format(time.DateOnly), h.ByDay[0].Day)
+
+	// Buckets follow the requested zone: 14:05 UTC is 23:05 in Tokyo.
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	h, err = BuildActivityHeatmap(context.Background(), db, "", 7, tokyo)
+	require.NoError(t, err)
+	assert.Equal(t, 5, h.Totals.Events)
+	assert.Equal(t, 3, h.ByHour[23].Events)
+	assert.Equal(t, 2, h.ByHour[0].Events)
+	assert.Len(t, h.ByDay, 2)
+}
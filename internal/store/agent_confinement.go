@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// AgentConfinement is the project an agent is confined to. ProjectID is empty
+// for an unconfined agent.
+type AgentConfinement struct {
+	AgentName string `json:"agent_name"`
+	ProjectID string `json:"project_id"`
+	EventID   int64  `json:"event_id,omitempty"`
+}
+
+// ConfinementError reports an operation by a confined agent that reaches
+// outside its project.
+type ConfinementError struct {
+	AgentName string
+	ProjectID string
+	Target    string // what was out of bounds, e.g. "global memory" or "task task_1 (project other)"
+}
+
+func (e *ConfinementError) Error() string {
+	return fmt.Sprintf("agent %s is confined to project %s: %s is outside it", e.AgentName, e.ProjectID, e.Target)
+}
+func (e *ConfinementError) ErrorCode() string { return "PROJECT_CONFINED" }
+func (e *ConfinementError) Context() map[string]string {
+	return map[string]string{"agent": e.AgentName, "project_id": e.ProjectID, "target": e.Target}
+}
+func (e *ConfinementError) SuggestedAction() string {
+	return "stay inside project " + e.ProjectID + ", or release the agent with 'vybe agent init --agent " + e.AgentName + " --release'"
+}
+
+func getAgentConfinementTx(tx *sql.Tx, agentName string) (string, error) {
+	var projectID sql.NullString
+	err := tx.QueryRowContext(context.Background(),
+		`SELECT confined_project_id FROM agent_state WHERE agent_name = ?`, agentName).Scan(&projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read agent confinement: %w", err)
+	}
+	return projectID.String, nil
+}
+
+// GetAgentConfinement returns the project agentName is confined to, or ""
+// when it is not confined.
+func GetAgentConfinement(ctx context.Context, db *sql.DB, agentName string) (string, error) {
+	if agentName == "" {
+		return "", nil
+	}
+	var projectID string
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		projectID, err = getAgentConfinementTx(tx, agentName)
+		return err
+	})
+	return projectID, err
+}
+
+// checkConfinedFocusTx rejects moving a confined agent's focus project off the
+// project it is confined to, including clearing it.
+func checkConfinedFocusTx(tx *sql.Tx, agentName, projectID string) error {
+	confined, err := getAgentConfinementTx(tx, agentName)
+	if err != nil || confined == "" || projectID == confined {
+		return err
+	}
+	target := "clearing the project focus"
+	if projectID != "" {
+		target = "project " + projectID
+	}
+	return &ConfinementError{AgentName: agentName, ProjectID: confined, Target: target}
+}
+
+// checkConfinedTaskTx rejects taskID when agentName is confined to another project.
+func checkConfinedTaskTx(tx *sql.Tx, agentName, taskID string) error {
+	confined, err := getAgentConfinementTx(tx, agentName)
+	if err != nil || confined == "" || taskID == "" {
+		return err
+	}
+	return checkTaskInProjectTx(tx, agentName, confined, taskID)
+}
+
+func checkTaskInProjectTx(tx *sql.Tx, agentName, confined, taskID string) error {
+	var projectID sql.NullString
+	err := tx.QueryRowContext(context.Background(), `SELECT project_id FROM tasks WHERE id = ?`, taskID).Scan(&projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load task project: %w", err)
+	}
+	if projectID.String != confined {
+		return &ConfinementError{AgentName: agentName, ProjectID: confined,
+			Target: fmt.Sprintf("task %s (project %q)", taskID, projectID.String)}
+	}
+	return nil
+}
+
+// checkConfinedMemoryTx rejects memory in scope/scopeID that belongs to
+// another project, task, or agent when agentName is confined. Global memory
+// stays readable but is rejected when write is set.
+func checkConfinedMemoryTx(tx *sql.Tx, agentName, scope, scopeID string, write bool) error {
+	confined, err := getAgentConfinementTx(tx, agentName)
+	if err != nil || confined == "" {
+		return err
+	}
+	outside := func(target string) error {
+		return &ConfinementError{AgentName: agentName, ProjectID: confined, Target: target}
+	}
+	switch models.MemoryScope(scope) {
+	case models.MemoryScopeGlobal:
+		if write {
+			return outside("global memory")
+		}
+	case models.MemoryScopeProject:
+		if scopeID != confined {
+			return outside("project " + scopeID + " memory")
+		}
+	case models.MemoryScopeTask:
+		return checkTaskInProjectTx(tx, agentName, confined, scopeID)
+	case models.MemoryScopeAgent:
+		if scopeID != agentName {
+			return outside("agent " + scopeID + " memory")
+		}
+	}
+	return nil
+}
+
+// CheckConfinedTask rejects taskID when agentName is confined to another project.
+func CheckConfinedTask(ctx context.Context, db *sql.DB, agentName, taskID string) error {
+	return Transact(ctx, db, func(tx *sql.Tx) error {
+		return checkConfinedTaskTx(tx, agentName, taskID)
+	})
+}
+
+// CheckConfinedMemory is checkConfinedMemoryTx in its own transaction.
+func CheckConfinedMemory(ctx context.Context, db *sql.DB, agentName, scope, scopeID string, write bool) error {
+	return Transact(ctx, db, func(tx *sql.Tx) error {
+		return checkConfinedMemoryTx(tx, agentName, scope, scopeID, write)
+	})
+}
+
+// ConfineAgentIdempotent confines agentName to projectID, or releases it when
+// projectID is empty. Confining also focuses the agent on the project and
+// drops a focus task that belongs to another project. Idempotent per
+// (agentName, requestID).
+func ConfineAgentIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) (*AgentConfinement, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	return RunIdempotent(ctx, db, agentName, requestID, "agent.confine", func(tx *sql.Tx) (*AgentConfinement, error) {
+		if err := ensureAgentStateTx(tx, agentName); err != nil {
+			return nil, err
+		}
+		if err := validateProjectExistsTx(tx, projectID); err != nil {
+			return nil, err
+		}
+
+		kind, msg := models.EventKindAgentReleased, "Agent released from project confinement"
+		if projectID == "" {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE agent_state SET confined_project_id = NULL, version = version + 1 WHERE agent_name = ?`, agentName); err != nil {
+				return nil, fmt.Errorf("failed to release agent: %w", err)
+			}
+		} else {
+			kind, msg = models.EventKindAgentConfined, "Agent confined to project "+projectID
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE agent_state
+				SET confined_project_id = ?,
+				    focus_project_id = ?,
+				    focus_task_id = CASE
+				        WHEN focus_task_id IN (SELECT id FROM tasks WHERE project_id = ?) THEN focus_task_id
+				        ELSE NULL
+				    END,
+				    version = version + 1
+				WHERE agent_name = ?
+			`, projectID, projectID, projectID, agentName); err != nil {
+				return nil, fmt.Errorf("failed to confine agent: %w", err)
+			}
+		}
+
+		meta, _ := json.Marshal(map[string]any{"project_id": projectID})
+		eventID, err := InsertEventTx(tx, kind, agentName, "", msg, string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append confinement event: %w", err)
+		}
+		return &AgentConfinement{AgentName: agentName, ProjectID: projectID, EventID: eventID}, nil
+	})
+}
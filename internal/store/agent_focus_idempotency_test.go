@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -12,7 +13,7 @@ func TestSetAgentFocusTaskWithEventIdempotent_Replay(t *testing.T) {
 	var err error
 
 	agent := "agent1"
-	_, err = LoadOrCreateAgentState(db, agent)
+	_, err = LoadOrCreateAgentState(context.Background(), db, agent)
 	require.NoError(t, err)
 
 	// Need a real task row to focus.
@@ -23,9 +24,9 @@ func TestSetAgentFocusTaskWithEventIdempotent_Replay(t *testing.T) {
 	require.NoError(t, err)
 
 	req := "req_focus"
-	e1, err := SetAgentFocusTaskWithEventIdempotent(db, agent, req, "task_1")
+	e1, err := SetAgentFocusTaskWithEventIdempotent(context.Background(), db, agent, req, "task_1")
 	require.NoError(t, err)
-	e2, err := SetAgentFocusTaskWithEventIdempotent(db, agent, req, "task_1")
+	e2, err := SetAgentFocusTaskWithEventIdempotent(context.Background(), db, agent, req, "task_1")
 	require.NoError(t, err)
 	require.Equal(t, e1, e2)
 
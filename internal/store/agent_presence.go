@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Agent liveness, derived from the agent's last activity.
+const (
+	AgentActive = "active" // active within AgentActiveWindow
+	AgentIdle   = "idle"   // quiet for longer, but not past the stale threshold
+	AgentStale  = "stale"  // quiet past the stale threshold; a candidate for agent evict
+)
+
+// AgentActiveWindow is how recently an agent must have acted to count as active.
+const AgentActiveWindow = 15 * time.Minute
+
+// DefaultAgentStaleAfter is the inactivity after which an agent counts as stale.
+const DefaultAgentStaleAfter = 24 * time.Hour
+
+// AgentLease is an in_progress task an agent holds through its focus, either
+// agent-wide or in one of its sessions.
+type AgentLease struct {
+	TaskID string `json:"task_id"`
+	Title  string `json:"title"`
+}
+
+// AgentPresence is one agent's row in the fleet overview.
+type AgentPresence struct {
+	AgentName      string       `json:"agent_name"`
+	LastActiveAt   time.Time    `json:"last_active_at"`
+	IdleSeconds    int64        `json:"idle_seconds"`
+	Liveness       string       `json:"liveness"`
+	FocusTaskID    string       `json:"focus_task_id,omitempty"`
+	FocusProjectID string       `json:"focus_project_id,omitempty"`
+	Leases         []AgentLease `json:"leases"`
+	Capabilities   []string     `json:"capabilities,omitempty"`
+}
+
+// agentLiveness classifies an agent by how long it has been idle.
+func agentLiveness(idle, staleAfter time.Duration) string {
+	switch {
+	case idle <= AgentActiveWindow:
+		return AgentActive
+	case idle > staleAfter:
+		return AgentStale
+	default:
+		return AgentIdle
+	}
+}
+
+// ListAgentPresence returns every agent with state, most recently active
+// first. Last activity is the later of the agent's last resume or focus change
+// and its newest event. Agents idle longer than staleAfter are stale.
+func ListAgentPresence(ctx context.Context, db *sql.DB, now time.Time, staleAfter time.Duration) ([]AgentPresence, error) {
+	if staleAfter <= 0 {
+		staleAfter = DefaultAgentStaleAfter
+	}
+
+	var out []AgentPresence
+	err := RetryWithBackoff(ctx, func() error {
+		out = nil
+		rows, err := db.QueryContext(ctx, `
+			SELECT a.agent_name, a.focus_task_id, a.focus_project_id,
+			       MAX(COALESCE(CAST(strftime('%s', a.last_active_at) AS INTEGER), 0),
+			           COALESCE((SELECT CAST(strftime('%s', e.created_at) AS INTEGER) FROM events e
+			                     WHERE e.agent_name = a.agent_name ORDER BY e.id DESC LIMIT 1), 0)) AS last_active
+			FROM agent_state a
+			ORDER BY last_active DESC, a.agent_name ASC
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to query agents: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var p AgentPresence
+			var focusTaskID, focusProjectID sql.NullString
+			var unix int64
+			if err := rows.Scan(&p.AgentName, &focusTaskID, &focusProjectID, &unix); err != nil {
+				return fmt.Errorf("failed to scan agent: %w", err)
+			}
+			p.FocusTaskID, p.FocusProjectID = focusTaskID.String, focusProjectID.String
+			p.LastActiveAt = time.Unix(unix, 0).UTC()
+			idle := max(now.Sub(p.LastActiveAt), 0)
+			p.IdleSeconds = int64(idle / time.Second)
+			p.Liveness = agentLiveness(idle, staleAfter)
+			p.Leases = []AgentLease{}
+			out = append(out, p)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	holders, err := queryFocusHolders(ctx, db, `t.status = 'in_progress'`)
+	if err != nil {
+		return nil, err
+	}
+	capabilities := map[string][]string{}
+	err = RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx,
+			`SELECT agent_name, capability FROM agent_capabilities ORDER BY agent_name, capability`)
+		if err != nil {
+			return fmt.Errorf("failed to query agent capabilities: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		clear(capabilities)
+		for rows.Next() {
+			var agent, c string
+			if err := rows.Scan(&agent, &c); err != nil {
+				return fmt.Errorf("failed to scan agent capability: %w", err)
+			}
+			capabilities[agent] = append(capabilities[agent], c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range out {
+		p := &out[i]
+		for _, h := range holders {
+			if h.agentName == p.AgentName {
+				p.Leases = append(p.Leases, AgentLease{TaskID: h.taskID, Title: h.title})
+			}
+		}
+		p.Capabilities = capabilities[p.AgentName]
+	}
+	return out, nil
+}
+
+// AgentEviction is the result of evicting an agent.
+type AgentEviction struct {
+	AgentName string   `json:"agent_name"`
+	Released  []string `json:"released"` // tasks returned to pending
+	EventID   int64    `json:"event_id"`
+}
+
+// EvictAgentTx releases everything target holds: each in_progress task it is
+// focused on (agent-wide or in any session) returns to pending, its focus is
+// cleared, it is left an inbox notice, and an agent_evicted event is written
+// by agentName. Registered capabilities are kept.
+func EvictAgentTx(tx *sql.Tx, agentName, target string) (*AgentEviction, error) {
+	if target == "" {
+		return nil, errors.New("agent to evict is required")
+	}
+	if _, err := readAgentVersionTx(tx, target); err != nil {
+		return nil, err
+	}
+
+	holders, err := focusHoldersTx(tx, `f.agent_name = ? AND t.status = 'in_progress'`, target)
+	if err != nil {
+		return nil, err
+	}
+	released := []string{}
+	for _, h := range holders {
+		version, err := GetTaskVersionTx(tx, h.taskID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := UpdateTaskStatusWithEventTx(tx, agentName, h.taskID, string(models.TaskStatusPending), version); err != nil {
+			return nil, fmt.Errorf("failed to release task %s: %w", h.taskID, err)
+		}
+		released = append(released, h.taskID)
+	}
+
+	if _, err := tx.ExecContext(context.Background(), `
+		UPDATE agent_state SET focus_task_id = NULL, version = version + 1 WHERE agent_name = ?
+	`, target); err != nil {
+		return nil, fmt.Errorf("failed to clear agent focus: %w", err)
+	}
+	if _, err := tx.ExecContext(context.Background(), `
+		UPDATE agent_session_state SET focus_task_id = NULL WHERE agent_name = ?
+	`, target); err != nil {
+		return nil, fmt.Errorf("failed to clear session focus: %w", err)
+	}
+	if err := notifyFocusLostTx(tx, holders, "agent "+target+" was evicted by "+agentName); err != nil {
+		return nil, err
+	}
+
+	meta, _ := json.Marshal(map[string]any{"agent": target, "released": released})
+	msg := fmt.Sprintf("Agent evicted: %s", target)
+	if len(released) > 0 {
+		msg += " (released " + strings.Join(released, ", ") + ")"
+	}
+	eventID, err := InsertEventTx(tx, models.EventKindAgentEvicted, agentName, "", msg, string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append agent evicted event: %w", err)
+	}
+	return &AgentEviction{AgentName: target, Released: released, EventID: eventID}, nil
+}
+
+// EvictAgentIdempotent performs EvictAgentTx once per (agent_name, request_id).
+func EvictAgentIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, target string) (*AgentEviction, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "agent.evict", func(tx *sql.Tx) (*AgentEviction, error) {
+		return EvictAgentTx(tx, agentName, target)
+	})
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestAgentPresence_ListAndEvict(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Long job", "", "", 0)
+	require.NoError(t, err)
+	_, _, err = StartTaskAndFocusIdempotent(context.Background(), db, "worker", "start_1", task.ID)
+	require.NoError(t, err)
+	_, err = RegisterAgentIdempotent(context.Background(), db, "idler", "reg_1", []string{"go"})
+	require.NoError(t, err)
+
+	agents, err := ListAgentPresence(context.Background(), db, time.Now().Add(2*time.Hour), 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, agents, 2)
+	byName := map[string]AgentPresence{}
+	for _, a := range agents {
+		byName[a.AgentName] = a
+	}
+	worker := byName["worker"]
+	assert.Equal(t, AgentIdle, worker.Liveness)
+	assert.Equal(t, task.ID, worker.FocusTaskID)
+	require.Len(t, worker.Leases, 1)
+	assert.Equal(t, task.ID, worker.Leases[0].TaskID)
+	assert.Equal(t, []string{"go"}, byName["idler"].Capabilities)
+	assert.Empty(t, byName["idler"].Leases)
+
+	agents, err = ListAgentPresence(context.Background(), db, time.Now().Add(48*time.Hour), 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, AgentStale, agents[0].Liveness)
+
+	eviction, err := EvictAgentIdempotent(context.Background(), db, "ops", "evict_1", "worker")
+	require.NoError(t, err)
+	assert.Equal(t, []string{task.ID}, eviction.Released)
+
+	got, err := GetTask(context.Background(), db, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusPending, got.Status)
+	state, err := GetAgentState(context.Background(), db, "worker")
+	require.NoError(t, err)
+	assert.Empty(t, state.FocusTaskID)
+	notices, err := ListUnreadNotices(context.Background(), db, "worker")
+	require.NoError(t, err)
+	assert.Len(t, notices, 1)
+
+	_, err = EvictAgentIdempotent(context.Background(), db, "ops", "evict_2", "ghost")
+	require.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // AgentCursorFocus holds the persisted cursor position and focus pointers for an agent.
@@ -98,7 +99,7 @@ func LoadOrCreateAgentCursorAndFocusTx(tx *sql.Tx, agentName string) (AgentCurso
 	return LoadAgentCursorAndFocusTx(tx, agentName)
 }
 
-func runFocusEventIdempotent(db *sql.DB, agentName, requestID, command string, setFocus func(tx *sql.Tx) (int64, error)) (int64, error) {
+func runFocusEventIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, command string, setFocus func(tx *sql.Tx) (int64, error)) (int64, error) {
 	if agentName == "" {
 		return 0, errors.New("agent name is required")
 	}
@@ -107,7 +108,7 @@ func runFocusEventIdempotent(db *sql.DB, agentName, requestID, command string, s
 		EventID int64 `json:"event_id"`
 	}
 
-	r, err := RunIdempotent(context.Background(), db, agentName, requestID, command, func(tx *sql.Tx) (idemResult, error) {
+	r, err := RunIdempotent(ctx, db, agentName, requestID, command, func(tx *sql.Tx) (idemResult, error) {
 		eventID, setErr := setFocus(tx)
 		if setErr != nil {
 			return idemResult{}, setErr
@@ -123,11 +124,14 @@ func runFocusEventIdempotent(db *sql.DB, agentName, requestID, command string, s
 
 // GetAgentState loads an existing agent state without creating one.
 // Returns (nil, nil) when the agent has no state row.
-func GetAgentState(db *sql.DB, agentName string) (*models.AgentState, error) {
+func GetAgentState(ctx context.Context, db *sql.DB, agentName string) (_ *models.AgentState, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	var state models.AgentState
 	var focusTaskID, focusProjectID sql.NullString
 
-	err := db.QueryRowContext(context.Background(), `
+	err = db.QueryRowContext(ctx, `
 		SELECT agent_name, last_seen_event_id, focus_task_id, focus_project_id, version, last_active_at
 		FROM agent_state
 		WHERE agent_name = ?
@@ -157,21 +161,21 @@ func GetAgentState(db *sql.DB, agentName string) (*models.AgentState, error) {
 }
 
 // LoadOrCreateAgentState loads the agent state or creates it if it doesn't exist
-func LoadOrCreateAgentState(db *sql.DB, agentName string) (*models.AgentState, error) {
+func LoadOrCreateAgentState(ctx context.Context, db *sql.DB, agentName string) (*models.AgentState, error) {
 	var state models.AgentState
 
-	err := RetryWithBackoff(context.Background(), func() error {
-		return Transact(context.Background(), db, func(tx *sql.Tx) error {
+	err := RetryWithBackoff(ctx, func() error {
+		return Transact(ctx, db, func(tx *sql.Tx) error {
 			// Concurrency-safe create: two workers may race to create the same agent.
 			// INSERT OR IGNORE ensures only one wins and others fall through to the SELECT.
-			if _, err := tx.ExecContext(context.Background(), `
+			if _, err := tx.ExecContext(ctx, `
 				INSERT OR IGNORE INTO agent_state (agent_name, last_seen_event_id, version, last_active_at)
 				VALUES (?, 0, 1, ?)
 			`, agentName, time.Now()); err != nil {
 				return fmt.Errorf("failed to ensure agent state: %w", err)
 			}
 
-			row := tx.QueryRowContext(context.Background(), `
+			row := tx.QueryRowContext(ctx, `
 				SELECT agent_name, last_seen_event_id, focus_task_id, focus_project_id, version, last_active_at
 				FROM agent_state
 				WHERE agent_name = ?
@@ -209,8 +213,8 @@ func LoadOrCreateAgentState(db *sql.DB, agentName string) (*models.AgentState, e
 
 // SetAgentFocusTaskWithEventIdempotent performs SetAgentFocusTaskWithEvent once per (agent_name, request_id).
 // On retries with the same request id, returns the originally created event id.
-func SetAgentFocusTaskWithEventIdempotent(db *sql.DB, agentName, requestID, taskID string) (int64, error) {
-	return runFocusEventIdempotent(db, agentName, requestID, "agent.focus", func(tx *sql.Tx) (int64, error) {
+func SetAgentFocusTaskWithEventIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string) (int64, error) {
+	return runFocusEventIdempotent(ctx, db, agentName, requestID, "agent.focus", func(tx *sql.Tx) (int64, error) {
 		return setAgentFocusTaskWithEventTx(tx, agentName, taskID)
 	})
 }
@@ -250,8 +254,8 @@ func setAgentFocusTaskWithEventTx(tx *sql.Tx, agentName, taskID string) (int64,
 
 // SetAgentFocusProjectWithEventIdempotent performs SetAgentFocusProjectWithEvent once per (agent_name, request_id).
 // On retries with the same request id, returns the originally created event id.
-func SetAgentFocusProjectWithEventIdempotent(db *sql.DB, agentName, requestID, projectID string) (int64, error) {
-	return runFocusEventIdempotent(db, agentName, requestID, "agent.project_focus", func(tx *sql.Tx) (int64, error) {
+func SetAgentFocusProjectWithEventIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) (int64, error) {
+	return runFocusEventIdempotent(ctx, db, agentName, requestID, "agent.project_focus", func(tx *sql.Tx) (int64, error) {
 		return setAgentFocusProjectWithEventTx(tx, agentName, projectID)
 	})
 }
@@ -263,6 +267,9 @@ func setAgentFocusProjectWithEventTx(tx *sql.Tx, agentName, projectID string) (i
 	if err := validateProjectExistsTx(tx, projectID); err != nil {
 		return 0, err
 	}
+	if err := checkConfinedFocusTx(tx, agentName, projectID); err != nil {
+		return 0, err
+	}
 
 	currentVersion, err := readAgentVersionTx(tx, agentName)
 	if err != nil {
@@ -0,0 +1,237 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// analyticsTopN bounds the per-tool, per-project, and per-kind lists.
+const analyticsTopN = 10
+
+// Analytics aggregates the event log and task table over a window: how much
+// got done, how long it took, which tools fail, and where the activity is.
+// Days are UTC calendar days.
+type Analytics struct {
+	ProjectID      string                  `json:"project_id,omitempty"`
+	SinceDays      int                     `json:"since_days"`
+	Throughput     AnalyticsThroughput     `json:"throughput"`
+	TimeToComplete AnalyticsTimeToComplete `json:"time_to_complete"`
+	ToolFailures   []ToolFailureRate       `json:"tool_failures"`
+	Projects       []ProjectActivity       `json:"projects"`
+	EventsByKind   []EventKindCount        `json:"events_by_kind"`
+	TotalEvents    int                     `json:"total_events"`
+}
+
+// AnalyticsThroughput counts tasks completed in the window. PerDay averages
+// over the whole window, idle days included.
+type AnalyticsThroughput struct {
+	Completed int                 `json:"completed"`
+	PerDay    float64             `json:"per_day"`
+	ByDay     []AnalyticsDayCount `json:"by_day"`
+}
+
+// AnalyticsDayCount is the number of completions on one UTC day (YYYY-MM-DD).
+type AnalyticsDayCount struct {
+	Day       string `json:"day"`
+	Completed int    `json:"completed"`
+}
+
+// AnalyticsTimeToComplete measures task creation to its last completion in the
+// window, over the tasks completed in the window.
+type AnalyticsTimeToComplete struct {
+	Tasks         int     `json:"tasks"`
+	MeanSeconds   float64 `json:"mean_seconds"`
+	MedianSeconds float64 `json:"median_seconds"`
+}
+
+// ToolFailureRate is one tool's tool_failure share of its tool_success and
+// tool_failure events.
+type ToolFailureRate struct {
+	Tool        string  `json:"tool"`
+	Calls       int     `json:"calls"`
+	Failures    int     `json:"failures"`
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// ProjectActivity is one project's event and completion counts. Events
+// without a project are reported under an empty project_id.
+type ProjectActivity struct {
+	ProjectID   string `json:"project_id"`
+	Events      int    `json:"events"`
+	Completions int    `json:"completions"`
+}
+
+// EventKindCount is the number of events of one kind.
+type EventKindCount struct {
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+}
+
+// BuildAnalytics aggregates the last sinceDays days (all projects when
+// projectID is empty). Archived events count, as in the activity heatmap.
+//
+//nolint:funlen // four independent aggregate queries, kept together so one retry covers them
+func BuildAnalytics(ctx context.Context, db *sql.DB, projectID string, sinceDays int) (*Analytics, error) {
+	if sinceDays <= 0 {
+		sinceDays = 7
+	}
+	window := fmt.Sprintf("-%d days", sinceDays)
+	scope, scopeArgs := "", []any{}
+	if projectID != "" {
+		scope, scopeArgs = " AND e.project_id = ?", []any{projectID}
+	}
+	withScope := func(args ...any) []any { return append(args, scopeArgs...) }
+
+	var a *Analytics
+	err := RetryWithBackoff(ctx, func() error {
+		a = &Analytics{
+			ProjectID:    projectID,
+			SinceDays:    sinceDays,
+			Throughput:   AnalyticsThroughput{ByDay: []AnalyticsDayCount{}},
+			ToolFailures: []ToolFailureRate{},
+			Projects:     []ProjectActivity{},
+			EventsByKind: []EventKindCount{},
+		}
+
+		// Completions: the last completion of each task in the window.
+		var durations []float64
+		byDay := map[string]int{}
+		err := queryRows(ctx, db, `
+			SELECT date(MAX(e.created_at)),
+				MAX(0, (julianday(MAX(e.created_at)) - julianday(t.created_at)) * 86400.0)
+			FROM events e
+			JOIN tasks t ON t.id = e.task_id
+			WHERE e.kind = ? AND e.message = ? AND e.created_at >= datetime('now', ?)`+scope+`
+			GROUP BY e.task_id
+		`, withScope(models.EventKindTaskStatus, sessionCompletedStatusMsg, window), func(rows *sql.Rows) error {
+			var day string
+			var secs float64
+			if err := rows.Scan(&day, &secs); err != nil {
+				return err
+			}
+			byDay[day]++
+			durations = append(durations, secs)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to query completions: %w", err)
+		}
+		a.Throughput.Completed = len(durations)
+		a.Throughput.PerDay = round2(float64(len(durations)) / float64(sinceDays))
+		for day, n := range byDay {
+			a.Throughput.ByDay = append(a.Throughput.ByDay, AnalyticsDayCount{Day: day, Completed: n})
+		}
+		sort.Slice(a.Throughput.ByDay, func(i, j int) bool { return a.Throughput.ByDay[i].Day < a.Throughput.ByDay[j].Day })
+		a.TimeToComplete = timeToComplete(durations)
+
+		err = queryRows(ctx, db, `
+			SELECT COALESCE(`+metaFieldExpr("tool_name")+`, ''),
+				COUNT(*), SUM(e.kind = ?)
+			FROM events e
+			WHERE e.kind IN (?, ?) AND e.created_at >= datetime('now', ?)`+scope+`
+			GROUP BY 1
+			ORDER BY 3 DESC, 2 DESC, 1
+			LIMIT ?
+		`, append(withScope(models.EventKindToolFailure, models.EventKindToolFailure, models.EventKindToolSuccess, window), analyticsTopN),
+			func(rows *sql.Rows) error {
+				var r ToolFailureRate
+				if err := rows.Scan(&r.Tool, &r.Calls, &r.Failures); err != nil {
+					return err
+				}
+				r.FailureRate = round2(float64(r.Failures) / float64(r.Calls))
+				a.ToolFailures = append(a.ToolFailures, r)
+				return nil
+			})
+		if err != nil {
+			return fmt.Errorf("failed to query tool failures: %w", err)
+		}
+
+		err = queryRows(ctx, db, `
+			SELECT COALESCE(e.project_id, ''), COUNT(*), SUM(e.kind = ? AND e.message = ?)
+			FROM events e
+			WHERE e.created_at >= datetime('now', ?)`+scope+`
+			GROUP BY 1
+			ORDER BY 2 DESC, 1
+			LIMIT ?
+		`, append(withScope(models.EventKindTaskStatus, sessionCompletedStatusMsg, window), analyticsTopN),
+			func(rows *sql.Rows) error {
+				var p ProjectActivity
+				if err := rows.Scan(&p.ProjectID, &p.Events, &p.Completions); err != nil {
+					return err
+				}
+				a.Projects = append(a.Projects, p)
+				return nil
+			})
+		if err != nil {
+			return fmt.Errorf("failed to query project activity: %w", err)
+		}
+
+		err = queryRows(ctx, db, `
+			SELECT e.kind, COUNT(*)
+			FROM events e
+			WHERE e.created_at >= datetime('now', ?)`+scope+`
+			GROUP BY e.kind
+			ORDER BY 2 DESC, 1
+		`, withScope(window), func(rows *sql.Rows) error {
+			var k EventKindCount
+			if err := rows.Scan(&k.Kind, &k.Count); err != nil {
+				return err
+			}
+			a.TotalEvents += k.Count
+			if len(a.EventsByKind) < analyticsTopN {
+				a.EventsByKind = append(a.EventsByKind, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to query event volume: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// queryRows runs query and calls scan for each row.
+func queryRows(ctx context.Context, db *sql.DB, query string, args []any, scan func(*sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func timeToComplete(secs []float64) AnalyticsTimeToComplete {
+	if len(secs) == 0 {
+		return AnalyticsTimeToComplete{}
+	}
+	sort.Float64s(secs)
+	var sum float64
+	for _, s := range secs {
+		sum += s
+	}
+	median := secs[len(secs)/2]
+	if len(secs)%2 == 0 {
+		median = (secs[len(secs)/2-1] + secs[len(secs)/2]) / 2
+	}
+	return AnalyticsTimeToComplete{
+		Tasks:         len(secs),
+		MeanSeconds:   math.Round(sum / float64(len(secs))),
+		MedianSeconds: math.Round(median),
+	}
+}
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
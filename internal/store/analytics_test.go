@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestBuildAnalytics(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	day := time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+	at := day.Add(10 * time.Hour)
+	_, err := db.Exec(`INSERT INTO tasks (id, title, status, project_id, created_at) VALUES
+		('t1', 'one', 'completed', '/repo', ?), ('t2', 'two', 'completed', '/repo', ?)`,
+		at.Add(-time.Hour).Format(time.DateTime), at.Add(-3*time.Hour).Format(time.DateTime))
+	require.NoError(t, err)
+
+	insert := func(kind, task, message, project, metadata string, when time.Time) {
+		t.Helper()
+		_, err := db.Exec(`INSERT INTO events (kind, agent_name, project_id, task_id, message, metadata, created_at)
+			VALUES (?, 'agent1', ?, NULLIF(?, ''), ?, NULLIF(?, ''), ?)`,
+			kind, project, task, message, metadata, when.Format(time.DateTime))
+		require.NoError(t, err)
+	}
+	insert(models.EventKindTaskStatus, "t1", "Status changed to: completed", "/repo", "", at)
+	insert(models.EventKindTaskStatus, "t2", "Status changed to: completed", "/repo", "", at)
+	insert(models.EventKindToolFailure, "", "Bash failed", "/repo", `{"tool_name":"Bash"}`, at)
+	insert(models.EventKindToolSuccess, "", "Bash ok", "/repo", `{"tool_name":"Bash"}`, at)
+	insert(models.EventKindToolSuccess, "", "Edit ok", "/other", `{"tool_name":"Edit"}`, at)
+	insert(models.EventKindProgress, "", "too old", "/repo", "", day.AddDate(0, 0, -40))
+
+	a, err := BuildAnalytics(context.Background(), db, "", 7)
+	require.NoError(t, err)
+	assert.Equal(t, 2, a.Throughput.Completed)
+	assert.InDelta(t, 2.0/7, a.Throughput.PerDay, 0.01)
+	assert.Equal(t, []AnalyticsDayCount{{Day: day.Format(time.DateOnly), Completed: 2}}, a.Throughput.ByDay)
+	assert.Equal(t, AnalyticsTimeToComplete{Tasks: 2, MeanSeconds: 7200, MedianSeconds: 7200}, a.TimeToComplete)
+	assert.Equal(t, []ToolFailureRate{
+		{Tool: "Bash", Calls: 2, Failures: 1, FailureRate: 0.5},
+		{Tool: "Edit", Calls: 1, Failures: 0, FailureRate: 0},
+	}, a.ToolFailures)
+	assert.Equal(t, []ProjectActivity{
+		{ProjectID: "/repo", Events: 4, Completions: 2},
+		{ProjectID: "/other", Events: 1},
+	}, a.Projects)
+	assert.Equal(t, 5, a.TotalEvents)
+	assert.Equal(t, EventKindCount{Kind: models.EventKindTaskStatus, Count: 2}, a.EventsByKind[0])
+
+	a, err = BuildAnalytics(context.Background(), db, "/other", 7)
+	require.NoError(t, err)
+	assert.Zero(t, a.Throughput.Completed)
+	assert.Equal(t, 1, a.TotalEvents)
+}
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// API token roles, from least to most privileged. Each role may run whatever
+// the roles before it may.
+const (
+	TokenRoleRead  = "read"  // commands that do not change state
+	TokenRoleAgent = "agent" // plus mutations and hook handlers
+	TokenRoleAdmin = "admin" // plus database, config, and token administration
+)
+
+// tokenSecretPrefix marks vybe API token secrets, so a leaked one is recognizable.
+const tokenSecretPrefix = "vybe_"
+
+// TokenRoles returns the valid roles, least privileged first.
+func TokenRoles() []string {
+	return []string{TokenRoleRead, TokenRoleAgent, TokenRoleAdmin}
+}
+
+// TokenRoleAllows reports whether a token with role may run an action that
+// requires required.
+func TokenRoleAllows(role, required string) bool {
+	have, need := slices.Index(TokenRoles(), role), slices.Index(TokenRoles(), required)
+	return have >= 0 && need >= 0 && have >= need
+}
+
+// APIToken is a stored token. The secret itself is never stored; Secret is
+// set only on the value CreateAPITokenIdempotent returns.
+type APIToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Secret     string     `json:"token,omitempty"`
+}
+
+// TokenAuthError reports a missing, unknown, revoked, or under-privileged token.
+type TokenAuthError struct {
+	Reason   string
+	Required string
+}
+
+func (e *TokenAuthError) Error() string {
+	if e.Required != "" {
+		return fmt.Sprintf("token not authorized: %s (requires role %s)", e.Reason, e.Required)
+	}
+	return "token not authorized: " + e.Reason
+}
+func (e *TokenAuthError) ErrorCode() string { return "UNAUTHORIZED" }
+func (e *TokenAuthError) Context() map[string]string {
+	ctx := map[string]string{"reason": e.Reason}
+	if e.Required != "" {
+		ctx["required_role"] = e.Required
+	}
+	return ctx
+}
+func (e *TokenAuthError) SuggestedAction() string {
+	return "set VYBE_TOKEN to a token with the required role (an admin creates one with 'vybe token create')"
+}
+
+// hashTokenSecret is the stored form of a secret. Secrets carry 256 random
+// bits, so a plain SHA-256 cannot be brute-forced the way a password hash can.
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTokenSecret() string {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("crypto/rand unavailable: %v", err))
+	}
+	return tokenSecretPrefix + hex.EncodeToString(b[:])
+}
+
+const apiTokenColumns = `id, name, role, created_by, created_at, last_used_at, revoked_at`
+
+func scanAPIToken(row interface{ Scan(dest ...any) error }) (*APIToken, error) {
+	var t APIToken
+	var lastUsed, revoked sql.NullTime
+	if err := row.Scan(&t.ID, &t.Name, &t.Role, &t.CreatedBy, &t.CreatedAt, &lastUsed, &revoked); err != nil {
+		return nil, err
+	}
+	if lastUsed.Valid {
+		t.LastUsedAt = &lastUsed.Time
+	}
+	if revoked.Valid {
+		t.RevokedAt = &revoked.Time
+	}
+	return &t, nil
+}
+
+// CreateAPITokenIdempotent stores a new token and returns it with its secret.
+// A retry with the same request id returns the token without the secret,
+// which exists only in the first response.
+func CreateAPITokenIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, name, role string) (*APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("token name is required")
+	}
+	if !slices.Contains(TokenRoles(), role) {
+		return nil, fmt.Errorf("invalid role %q (use %s)", role, strings.Join(TokenRoles(), ", "))
+	}
+
+	secret := newTokenSecret()
+	tok, replayed, err := RunIdempotentWithRetry(ctx, db, agentName, requestID, "token.create", 3, defaultRetryPredicate,
+		func(tx *sql.Tx) (*APIToken, error) {
+			id := generatePrefixedID("tok")
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO api_tokens (id, name, role, token_hash, created_by) VALUES (?, ?, ?, ?, ?)
+			`, id, name, role, hashTokenSecret(secret), agentName); err != nil {
+				return nil, fmt.Errorf("failed to create token: %w", err)
+			}
+			meta, _ := json.Marshal(map[string]any{"token_id": id, "name": name, "role": role})
+			if _, err := InsertEventTx(tx, models.EventKindTokenCreated, agentName, "",
+				fmt.Sprintf("API token created: %s (%s)", name, role), string(meta)); err != nil {
+				return nil, fmt.Errorf("failed to append token event: %w", err)
+			}
+			return scanAPIToken(tx.QueryRowContext(ctx,
+				`SELECT `+apiTokenColumns+` FROM api_tokens WHERE id = ?`, id))
+		})
+	if err != nil {
+		return nil, err
+	}
+	if !replayed {
+		tok.Secret = secret
+	}
+	return tok, nil
+}
+
+// RevokeAPITokenIdempotent revokes token id. Revoking a revoked token is a no-op.
+func RevokeAPITokenIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, id string) (*APIToken, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "token.revoke", func(tx *sql.Tx) (*APIToken, error) {
+		tok, err := scanAPIToken(tx.QueryRowContext(ctx,
+			`SELECT `+apiTokenColumns+` FROM api_tokens WHERE id = ?`, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("token not found: %s", id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load token: %w", err)
+		}
+		if tok.RevokedAt != nil {
+			return tok, nil
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = ?`, id); err != nil {
+			return nil, fmt.Errorf("failed to revoke token: %w", err)
+		}
+		meta, _ := json.Marshal(map[string]any{"token_id": id, "name": tok.Name, "role": tok.Role})
+		if _, err := InsertEventTx(tx, models.EventKindTokenRevoked, agentName, "",
+			"API token revoked: "+tok.Name, string(meta)); err != nil {
+			return nil, fmt.Errorf("failed to append token event: %w", err)
+		}
+		return scanAPIToken(tx.QueryRowContext(ctx,
+			`SELECT `+apiTokenColumns+` FROM api_tokens WHERE id = ?`, id))
+	})
+}
+
+// ListAPITokens returns every token, newest first. Secrets are never returned.
+func ListAPITokens(ctx context.Context, db *sql.DB, includeRevoked bool) ([]APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens`
+	if !includeRevoked {
+		query += ` WHERE revoked_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	var out []APIToken
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("failed to query tokens: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = make([]APIToken, 0)
+		for rows.Next() {
+			t, err := scanAPIToken(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan token: %w", err)
+			}
+			out = append(out, *t)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuthenticateAPIToken returns the live token whose secret is secret and
+// records its use. Unknown and revoked secrets fail with *TokenAuthError.
+func AuthenticateAPIToken(ctx context.Context, db *sql.DB, secret string) (*APIToken, error) {
+	if secret == "" {
+		return nil, &TokenAuthError{Reason: "no token (set VYBE_TOKEN)"}
+	}
+	var tok *APIToken
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
+		t, err := scanAPIToken(tx.QueryRowContext(ctx,
+			`SELECT `+apiTokenColumns+` FROM api_tokens WHERE token_hash = ?`, hashTokenSecret(secret)))
+		if errors.Is(err, sql.ErrNoRows) {
+			return &TokenAuthError{Reason: "unknown token"}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load token: %w", err)
+		}
+		if t.RevokedAt != nil {
+			return &TokenAuthError{Reason: "token " + t.ID + " is revoked"}
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE api_tokens SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?`, t.ID); err != nil {
+			return fmt.Errorf("failed to record token use: %w", err)
+		}
+		tok = t
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRoleAllows(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, TokenRoleAllows(TokenRoleAdmin, TokenRoleRead))
+	assert.True(t, TokenRoleAllows(TokenRoleAgent, TokenRoleAgent))
+	assert.False(t, TokenRoleAllows(TokenRoleRead, TokenRoleAgent))
+	assert.False(t, TokenRoleAllows(TokenRoleAgent, TokenRoleAdmin))
+	assert.False(t, TokenRoleAllows("root", TokenRoleRead))
+}
+
+func TestAPIToken_CreateAuthenticateRevoke(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tok, err := CreateAPITokenIdempotent(context.Background(), db, "admin1", "req_tok", "ci", TokenRoleRead)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(tok.Secret, tokenSecretPrefix))
+
+	var stored int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM api_tokens WHERE token_hash = ?`, tok.Secret).Scan(&stored))
+	assert.Zero(t, stored, "secret must not be stored in clear")
+
+	replay, err := CreateAPITokenIdempotent(context.Background(), db, "admin1", "req_tok", "ci", TokenRoleRead)
+	require.NoError(t, err)
+	assert.Equal(t, tok.ID, replay.ID)
+	assert.Empty(t, replay.Secret, "a replay must not reveal the secret")
+
+	got, err := AuthenticateAPIToken(context.Background(), db, tok.Secret)
+	require.NoError(t, err)
+	assert.Equal(t, TokenRoleRead, got.Role)
+
+	var authErr *TokenAuthError
+	_, err = AuthenticateAPIToken(context.Background(), db, tok.Secret+"x")
+	require.True(t, errors.As(err, &authErr))
+
+	_, err = RevokeAPITokenIdempotent(context.Background(), db, "admin1", "req_revoke", tok.ID)
+	require.NoError(t, err)
+	_, err = AuthenticateAPIToken(context.Background(), db, tok.Secret)
+	require.True(t, errors.As(err, &authErr))
+
+	live, err := ListAPITokens(context.Background(), db, false)
+	require.NoError(t, err)
+	assert.Empty(t, live)
+	all, err := ListAPITokens(context.Background(), db, true)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.NotNil(t, all[0].RevokedAt)
+	assert.NotNil(t, all[0].LastUsedAt)
+
+	_, err = CreateAPITokenIdempotent(context.Background(), db, "admin1", "req_bad", "x", "root")
+	require.Error(t, err)
+}
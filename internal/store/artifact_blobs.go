@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// MaxArtifactContentBytes caps a single captured artifact body. Larger files
+// should stay path-only links; the database is not a general file store.
+const MaxArtifactContentBytes = 8 << 20
+
+// ContentHash returns the hex SHA-256 used as the artifact_blobs key.
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// PutBlobTx stores content under its hash and returns the hash. Storing the
+// same bytes twice is a no-op, so repeated attempts share one blob.
+func PutBlobTx(tx *sql.Tx, content []byte) (string, error) {
+	if len(content) > MaxArtifactContentBytes {
+		return "", fmt.Errorf("artifact content is %d bytes; limit is %d", len(content), MaxArtifactContentBytes)
+	}
+	hash := ContentHash(content)
+	if _, err := tx.ExecContext(context.Background(), `
+		INSERT INTO artifact_blobs (hash, size, content) VALUES (?, ?, ?)
+		ON CONFLICT(hash) DO NOTHING
+	`, hash, len(content), content); err != nil {
+		return "", fmt.Errorf("failed to store artifact content: %w", err)
+	}
+	return hash, nil
+}
+
+// GetArtifactContent returns the captured body of an artifact. Artifacts added
+// without content capture return an error naming the artifact.
+func GetArtifactContent(ctx context.Context, db *sql.DB, artifactID string) ([]byte, error) {
+	var content []byte
+	err := RetryWithBackoff(ctx, func() error {
+		return db.QueryRowContext(ctx, `
+			SELECT b.content FROM artifacts a
+			JOIN artifact_blobs b ON b.hash = a.content_hash
+			WHERE a.id = ?
+		`, artifactID).Scan(&content)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := GetArtifact(ctx, db, artifactID); getErr != nil {
+			return nil, getErr
+		}
+		return nil, fmt.Errorf("artifact %s has no stored content (add it with --store-content)", artifactID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact content: %w", err)
+	}
+	if content == nil {
+		content = []byte{}
+	}
+	return content, nil
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // generateArtifactID returns a unique artifact identifier with the "artifact" prefix.
@@ -15,71 +16,46 @@ func generateArtifactID() string {
 	return generatePrefixedID("artifact")
 }
 
-// AddArtifact creates an artifact linked to a task by first appending an event and then inserting the artifact row
-// in the same transaction. Returns the artifact and the event id.
-func AddArtifact(db *sql.DB, agentName, taskID, filePath, contentType string) (*models.Artifact, int64, error) {
-	if agentName == "" {
-		return nil, 0, errors.New("agent name is required")
-	}
-	if taskID == "" {
-		return nil, 0, errors.New("task ID is required")
-	}
-	if filePath == "" {
-		return nil, 0, errors.New("file path is required")
-	}
+// artifactSelectSQL selects the columns read by scanArtifact. Callers append
+// WHERE/ORDER clauses against alias a.
+const artifactSelectSQL = `SELECT a.id, a.task_id, a.event_id, a.file_path, a.content_type, a.content_hash, b.size, a.created_at
+	FROM artifacts a
+	LEFT JOIN artifact_blobs b ON b.hash = a.content_hash`
 
-	artifactID := generateArtifactID()
-	meta := struct {
-		ArtifactID  string `json:"artifact_id"`
-		FilePath    string `json:"file_path"`
-		ContentType string `json:"content_type,omitempty"`
-	}{
-		ArtifactID:  artifactID,
-		FilePath:    filePath,
-		ContentType: contentType,
+func scanArtifact(row interface {
+	Scan(dest ...any) error
+}) (*models.Artifact, error) {
+	var a models.Artifact
+	var ct, hash sql.NullString
+	var size sql.NullInt64
+	if err := row.Scan(&a.ID, &a.TaskID, &a.EventID, &a.FilePath, &ct, &hash, &size, &a.CreatedAt); err != nil {
+		return nil, err
 	}
-	metaBytes, _ := json.Marshal(meta)
+	a.ContentType = ct.String
+	a.ContentHash = hash.String
+	a.ContentSize = size.Int64
+	return &a, nil
+}
 
+// AddArtifact creates an artifact linked to a task by first appending an event and then inserting the artifact row
+// in the same transaction. Returns the artifact and the event id.
+func AddArtifact(ctx context.Context, db *sql.DB, agentName, taskID, filePath, contentType string) (*models.Artifact, int64, error) {
 	var (
 		eventID  int64
 		artifact *models.Artifact
 	)
 
-	err := Transact(context.Background(), db, func(tx *sql.Tx) error {
-		projectID, err := resolveTaskProjectIDTx(tx, taskID)
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
+		artifactID, id, err := AddArtifactTx(tx, agentName, taskID, filePath, contentType)
 		if err != nil {
 			return err
 		}
-
-		id, err := InsertEventTx(tx, models.EventKindArtifactAdded, agentName, taskID, fmt.Sprintf("Artifact added: %s", filePath), string(metaBytes))
-		if err != nil {
-			return fmt.Errorf("failed to append event: %w", err)
-		}
 		eventID = id
 
-		_, err = tx.ExecContext(context.Background(), `
-			INSERT INTO artifacts (id, task_id, project_id, event_id, file_path, content_type, created_at)
-			VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
-		`, artifactID, taskID, projectID, eventID, filePath, nullIfEmpty(contentType))
-		if err != nil {
-			return fmt.Errorf("failed to insert artifact: %w", err)
-		}
-
-		var a models.Artifact
-		var ct sql.NullString
-		err = tx.QueryRowContext(context.Background(), `
-			SELECT id, task_id, event_id, file_path, content_type, created_at
-			FROM artifacts
-			WHERE id = ?
-		`, artifactID).Scan(&a.ID, &a.TaskID, &a.EventID, &a.FilePath, &ct, &a.CreatedAt)
+		artifact, err = scanArtifact(tx.QueryRowContext(ctx, artifactSelectSQL+` WHERE a.id = ?`, artifactID))
 		if err != nil {
 			return fmt.Errorf("failed to fetch artifact: %w", err)
 		}
-		if ct.Valid {
-			a.ContentType = ct.String
-		}
-		artifact = &a
-
 		return nil
 	})
 	if err != nil {
@@ -92,6 +68,14 @@ func AddArtifact(db *sql.DB, agentName, taskID, filePath, contentType string) (*
 // AddArtifactTx inserts an artifact row and its event within an existing transaction.
 // Exported for use by batch operations (e.g., push).
 func AddArtifactTx(tx *sql.Tx, agentName, taskID, filePath, contentType string) (artifactID string, eventID int64, err error) {
+	return AddArtifactWithContentTx(tx, agentName, taskID, filePath, contentType, nil)
+}
+
+// AddArtifactWithContentTx is AddArtifactTx that also captures the file body.
+// A nil content stores a path-only link, matching AddArtifactTx.
+//
+//nolint:revive // argument-limit: mirrors AddArtifactTx plus the captured body
+func AddArtifactWithContentTx(tx *sql.Tx, agentName, taskID, filePath, contentType string, content []byte) (artifactID string, eventID int64, err error) {
 	if agentName == "" {
 		return "", 0, errors.New("agent name is required")
 	}
@@ -108,14 +92,24 @@ func AddArtifactTx(tx *sql.Tx, agentName, taskID, filePath, contentType string)
 		return "", 0, err
 	}
 
+	var contentHash string
+	if content != nil {
+		contentHash, err = PutBlobTx(tx, content)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
 	meta := struct {
 		ArtifactID  string `json:"artifact_id"`
 		FilePath    string `json:"file_path"`
 		ContentType string `json:"content_type,omitempty"`
+		ContentHash string `json:"content_hash,omitempty"`
 	}{
 		ArtifactID:  artifactID,
 		FilePath:    filePath,
 		ContentType: contentType,
+		ContentHash: contentHash,
 	}
 	metaBytes, _ := json.Marshal(meta)
 
@@ -125,9 +119,9 @@ func AddArtifactTx(tx *sql.Tx, agentName, taskID, filePath, contentType string)
 	}
 
 	_, err = tx.ExecContext(context.Background(), `
-		INSERT INTO artifacts (id, task_id, project_id, event_id, file_path, content_type, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
-	`, artifactID, taskID, projectID, eventID, filePath, nullIfEmpty(contentType))
+		INSERT INTO artifacts (id, task_id, project_id, event_id, file_path, content_type, content_hash, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, artifactID, taskID, projectID, eventID, filePath, nullIfEmpty(contentType), nullIfEmpty(contentHash))
 	if err != nil {
 		return "", 0, fmt.Errorf("failed to insert artifact: %w", err)
 	}
@@ -139,14 +133,21 @@ func AddArtifactTx(tx *sql.Tx, agentName, taskID, filePath, contentType string)
 // On retries with the same request id, it returns the originally created artifact + event id.
 //
 //nolint:revive // argument-limit: all artifact params are required and distinct; a struct would add boilerplate at every callsite
-func AddArtifactIdempotent(db *sql.DB, agentName, requestID, taskID, filePath, contentType string) (*models.Artifact, int64, error) {
+func AddArtifactIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, filePath, contentType string) (*models.Artifact, int64, error) {
+	return AddArtifactWithContentIdempotent(ctx, db, agentName, requestID, taskID, filePath, contentType, nil)
+}
+
+// AddArtifactWithContentIdempotent is AddArtifactIdempotent with optional content capture.
+//
+//nolint:revive // argument-limit: mirrors AddArtifactIdempotent plus the captured body
+func AddArtifactWithContentIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, filePath, contentType string, content []byte) (*models.Artifact, int64, error) {
 	type idemResult struct {
 		ArtifactID string `json:"artifact_id"`
 		EventID    int64  `json:"event_id"`
 	}
 
-	r, err := RunIdempotent(context.Background(), db, agentName, requestID, "artifact.add", func(tx *sql.Tx) (idemResult, error) {
-		artifactID, eventID, err := AddArtifactTx(tx, agentName, taskID, filePath, contentType)
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "artifact.add", func(tx *sql.Tx) (idemResult, error) {
+		artifactID, eventID, err := AddArtifactWithContentTx(tx, agentName, taskID, filePath, contentType, content)
 		if err != nil {
 			return idemResult{}, err
 		}
@@ -156,7 +157,7 @@ func AddArtifactIdempotent(db *sql.DB, agentName, requestID, taskID, filePath, c
 		return nil, 0, err
 	}
 
-	artifact, err := GetArtifact(db, r.ArtifactID)
+	artifact, err := GetArtifact(ctx, db, r.ArtifactID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -165,15 +166,12 @@ func AddArtifactIdempotent(db *sql.DB, agentName, requestID, taskID, filePath, c
 }
 
 // GetArtifact retrieves a single artifact by ID.
-func GetArtifact(db *sql.DB, id string) (*models.Artifact, error) {
-	var a models.Artifact
-	var ct sql.NullString
-	err := RetryWithBackoff(context.Background(), func() error {
-		return db.QueryRowContext(context.Background(), `
-			SELECT id, task_id, event_id, file_path, content_type, created_at
-			FROM artifacts
-			WHERE id = ?
-		`, id).Scan(&a.ID, &a.TaskID, &a.EventID, &a.FilePath, &ct, &a.CreatedAt)
+func GetArtifact(ctx context.Context, db *sql.DB, id string) (*models.Artifact, error) {
+	var a *models.Artifact
+	err := RetryWithBackoff(ctx, func() error {
+		var err error
+		a, err = scanArtifact(db.QueryRowContext(ctx, artifactSelectSQL+` WHERE a.id = ?`, id))
+		return err
 	})
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("artifact not found: %s", id)
@@ -181,14 +179,14 @@ func GetArtifact(db *sql.DB, id string) (*models.Artifact, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get artifact: %w", err)
 	}
-	if ct.Valid {
-		a.ContentType = ct.String
-	}
-	return &a, nil
+	return a, nil
 }
 
 // ListArtifactsByTask returns artifacts linked to a task, newest first.
-func ListArtifactsByTask(db *sql.DB, taskID string, limit int) ([]*models.Artifact, error) {
+func ListArtifactsByTask(ctx context.Context, db *sql.DB, taskID string, limit int) (_ []*models.Artifact, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	if taskID == "" {
 		return nil, errors.New("task ID is required")
 	}
@@ -198,16 +196,16 @@ func ListArtifactsByTask(db *sql.DB, taskID string, limit int) ([]*models.Artifa
 	if limit > 1000 {
 		limit = 1000
 	}
+	return queryArtifacts(ctx, db, artifactSelectSQL+`
+		WHERE a.task_id = ?
+		ORDER BY a.created_at DESC
+		LIMIT ?`, taskID, limit)
+}
 
+func queryArtifacts(ctx context.Context, db *sql.DB, query string, args ...any) ([]*models.Artifact, error) {
 	var out []*models.Artifact
-	err := RetryWithBackoff(context.Background(), func() error {
-		rows, err := db.QueryContext(context.Background(), `
-			SELECT id, task_id, event_id, file_path, content_type, created_at
-			FROM artifacts
-			WHERE task_id = ?
-			ORDER BY created_at DESC
-			LIMIT ?
-		`, taskID, limit)
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
 		if err != nil {
 			return fmt.Errorf("failed to list artifacts: %w", err)
 		}
@@ -215,15 +213,11 @@ func ListArtifactsByTask(db *sql.DB, taskID string, limit int) ([]*models.Artifa
 
 		out = make([]*models.Artifact, 0)
 		for rows.Next() {
-			var a models.Artifact
-			var ct sql.NullString
-			if err := rows.Scan(&a.ID, &a.TaskID, &a.EventID, &a.FilePath, &ct, &a.CreatedAt); err != nil {
+			a, err := scanArtifact(rows)
+			if err != nil {
 				return fmt.Errorf("failed to scan artifact: %w", err)
 			}
-			if ct.Valid {
-				a.ContentType = ct.String
-			}
-			out = append(out, &a)
+			out = append(out, a)
 		}
 		return rows.Err()
 	})
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"regexp"
 	"testing"
 
@@ -13,20 +14,20 @@ func TestAddArtifactAndList(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "t", "d", "", 0)
+	task, err := CreateTask(context.Background(), db, "t", "d", "", 0)
 	require.NoError(t, err)
 
-	artifact, eventID, err := AddArtifact(db, "agent1", task.ID, "/tmp/out.txt", "text/plain")
+	artifact, eventID, err := AddArtifact(context.Background(), db, "agent1", task.ID, "/tmp/out.txt", "text/plain")
 	require.NoError(t, err)
 	require.NotNil(t, artifact)
 	require.Greater(t, eventID, int64(0))
 	require.Equal(t, task.ID, artifact.TaskID)
 
-	got, err := GetArtifact(db, artifact.ID)
+	got, err := GetArtifact(context.Background(), db, artifact.ID)
 	require.NoError(t, err)
 	require.Equal(t, artifact.ID, got.ID)
 
-	list, err := ListArtifactsByTask(db, task.ID, 10)
+	list, err := ListArtifactsByTask(context.Background(), db, task.ID, 10)
 	require.NoError(t, err)
 	require.Len(t, list, 1)
 	require.Equal(t, artifact.ID, list[0].ID)
@@ -0,0 +1,301 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// backupPagesPerStep is how many pages one backup step copies before yielding,
+// so writers on other connections are not locked out for a whole large copy.
+const backupPagesPerStep = 1024
+
+// rollingBackupPrefix and rollingBackupLayout name rolling backup files:
+// vybe-20250601T120000Z.db. The UTC timestamp is the backup's point in time.
+const (
+	rollingBackupPrefix = "vybe-"
+	rollingBackupLayout = "20060102T150405Z"
+)
+
+// RollingBackup is one file in a rolling backup directory.
+type RollingBackup struct {
+	Path      string    `json:"path"`
+	TakenAt   time.Time `json:"taken_at"`
+	SizeBytes int64     `json:"size_bytes"`
+}
+
+type backupConn interface {
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+}
+
+type restoreConn interface {
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// BackupDB copies the live database to path with SQLite's online backup API.
+// Other connections keep reading and writing; a write from another connection
+// during the copy makes SQLite restart it, so the file is always one consistent
+// point in time. The copy is written beside path and renamed into place, so a
+// failed backup never leaves a partial file. path must not exist.
+func BackupDB(ctx context.Context, db *sql.DB, path string) (_ *SnapshotFileInfo, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if path == "" {
+		return nil, errors.New("backup path is required")
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("backup file already exists: %s", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to check backup file: %w", err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve backup path: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(abs), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	tmp := abs + ".partial"
+	_ = os.Remove(tmp)
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	err = conn.Raw(func(dc any) error {
+		bc, ok := dc.(backupConn)
+		if !ok {
+			return errors.New("sqlite driver does not support online backup")
+		}
+		b, err := bc.NewBackup("file:" + tmp)
+		if err != nil {
+			return err
+		}
+		return runBackupSteps(ctx, b)
+	})
+	if err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("backup failed: %w", err)
+	}
+	if err := os.Rename(tmp, abs); err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("failed to move backup into place: %w", err)
+	}
+	return InspectSnapshotFile(ctx, abs)
+}
+
+// RestoreDB replaces the contents of the live database with the backup at
+// path, in place, through the online backup API: other connections see either
+// the old or the restored database, never a mix. The backup must pass an
+// integrity check and must not be newer than this binary's schema; an older
+// schema is migrated after the restore. A db_restored event is then recorded
+// in the restored database under requestID. A retry of a restore that already
+// completed finds that record in the live database and replays it without
+// overwriting anything written since.
+//
+//nolint:revive // argument-limit: db, paths, agent, request are all required
+func RestoreDB(ctx context.Context, db *sql.DB, dbPath, path, agentName, requestID string) (_ *SnapshotFileInfo, _ int64, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	replayedEventID, err := restoreReplayEventID(ctx, db, agentName, requestID)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := InspectSnapshotFile(ctx, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if replayedEventID > 0 {
+		return info, replayedEventID, nil
+	}
+	if !info.IntegrityOK {
+		return nil, 0, fmt.Errorf("backup %s fails its integrity check", path)
+	}
+	if info.SchemaVersion > info.LatestVersion {
+		return nil, 0, fmt.Errorf("backup schema version %d is newer than this vybe (%d); upgrade first", info.SchemaVersion, info.LatestVersion)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to resolve backup path: %w", err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open restore connection: %w", err)
+	}
+	err = conn.Raw(func(dc any) error {
+		rc, ok := dc.(restoreConn)
+		if !ok {
+			return errors.New("sqlite driver does not support online restore")
+		}
+		b, err := rc.NewRestore("file:" + abs + "?mode=ro")
+		if err != nil {
+			return err
+		}
+		return runBackupSteps(ctx, b)
+	})
+	_ = conn.Close()
+	if err != nil {
+		return nil, 0, fmt.Errorf("restore failed: %w", err)
+	}
+	if err := MigrateDB(ctx, db, dbPath); err != nil {
+		return nil, 0, fmt.Errorf("restored, but migrating the restored schema failed: %w", err)
+	}
+
+	meta, err := json.Marshal(map[string]any{
+		"source":         abs,
+		"schema_version": info.SchemaVersion,
+		"last_event_id":  info.LastEventID,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal restore metadata: %w", err)
+	}
+	message := "Database restored from " + filepath.Base(abs)
+	eventID, err := appendEventIdempotentResult(ctx, db, agentName, requestID, dbRestoreCommand, models.EventKindDBRestored,
+		message, string(meta), func(tx *sql.Tx) (int64, error) {
+			return insertEventRowTx(tx, models.EventKindDBRestored, agentName, "", message, string(meta))
+		})
+	if err != nil {
+		return nil, 0, fmt.Errorf("restored, but recording the db_restored event failed: %w", err)
+	}
+	return info, eventID, nil
+}
+
+// dbRestoreCommand is the idempotency command a restore's db_restored event is
+// recorded under.
+const dbRestoreCommand = "db.restore"
+
+// restoreReplayEventID looks up (agentName, requestID) in the live database
+// before a restore overwrites it. It returns the recorded db_restored event ID
+// when that request already restored this database, and 0 when it has not.
+func restoreReplayEventID(ctx context.Context, db *sql.DB, agentName, requestID string) (int64, error) {
+	if agentName == "" {
+		return 0, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return 0, errors.New("request id is required")
+	}
+	var command, resultJSON string
+	err := db.QueryRowContext(ctx, `
+		SELECT command, result_json FROM idempotency WHERE agent_name = ? AND request_id = ?
+	`, agentName, requestID).Scan(&command, &resultJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load idempotency row: %w", err)
+	}
+	if command != dbRestoreCommand {
+		return 0, fmt.Errorf("idempotency key collision: request_id %q already used for command %q (new: %q)", requestID, command, dbRestoreCommand)
+	}
+	if strings.TrimSpace(resultJSON) == "" {
+		return 0, &IdempotencyInProgressError{AgentName: agentName, RequestID: requestID, Command: command}
+	}
+	var r eventIDResult
+	if err := json.Unmarshal([]byte(resultJSON), &r); err != nil {
+		return 0, fmt.Errorf("failed to decode restore replay: %w", err)
+	}
+	return r.EventID, nil
+}
+
+// runBackupSteps copies every page, yielding between steps, then releases b.
+func runBackupSteps(ctx context.Context, b *sqlite.Backup) error {
+	for {
+		more, err := b.Step(backupPagesPerStep)
+		if err != nil {
+			_ = b.Finish()
+			return err
+		}
+		if !more {
+			return b.Finish()
+		}
+		select {
+		case <-ctx.Done():
+			_ = b.Finish()
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// RollingBackupPath names a new rolling backup in dir taken at t.
+func RollingBackupPath(dir string, t time.Time) string {
+	return filepath.Join(dir, rollingBackupPrefix+t.UTC().Format(rollingBackupLayout)+".db")
+}
+
+// ListRollingBackups returns the rolling backups in dir, newest first. Files
+// that do not follow the rolling naming scheme are ignored.
+func ListRollingBackups(dir string) ([]RollingBackup, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []RollingBackup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	out := []RollingBackup{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, rollingBackupPrefix) || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		at, err := time.Parse(rollingBackupLayout, strings.TrimSuffix(strings.TrimPrefix(name, rollingBackupPrefix), ".db"))
+		if err != nil {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, RollingBackup{Path: filepath.Join(dir, name), TakenAt: at, SizeBytes: fi.Size()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TakenAt.After(out[j].TakenAt) })
+	return out, nil
+}
+
+// PruneRollingBackups deletes all but the keep newest rolling backups in dir
+// and returns the removed paths.
+func PruneRollingBackups(dir string, keep int) ([]string, error) {
+	backups, err := ListRollingBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+	removed := []string{}
+	for i := max(keep, 1); i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return removed, fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		removed = append(removed, backups[i].Path)
+	}
+	return removed, nil
+}
+
+// RollingBackupAt returns the newest rolling backup in dir taken at or before
+// t: the restore point for a point-in-time restore.
+func RollingBackupAt(dir string, t time.Time) (*RollingBackup, error) {
+	backups, err := ListRollingBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range backups {
+		if !backups[i].TakenAt.After(t) {
+			return &backups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no rolling backup in %s at or before %s", dir, t.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupDB_ConsistentDuringWritesAndRestores(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "live.db")
+	db, err := InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	_, err = CreateTask(context.Background(), db, "Before backup", "", "", 0)
+	require.NoError(t, err)
+
+	// Keep writing from another goroutine while the backup runs.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			_, _ = CreateTask(context.Background(), db, fmt.Sprintf("Concurrent %d", i), "", "", 0)
+		}
+	}()
+	path := filepath.Join(t.TempDir(), "nested", "backup.db")
+	info, err := BackupDB(context.Background(), db, path)
+	wg.Wait()
+	require.NoError(t, err)
+	assert.True(t, info.IntegrityOK)
+	assert.Equal(t, info.LatestVersion, info.SchemaVersion)
+	assert.GreaterOrEqual(t, info.Tables["tasks"], int64(1))
+	_, err = os.Stat(path + ".partial")
+	assert.True(t, os.IsNotExist(err), "temporary file is renamed away")
+
+	_, err = BackupDB(context.Background(), db, path)
+	require.Error(t, err, "existing file is never overwritten")
+
+	_, err = CreateTask(context.Background(), db, "After backup", "", "", 0)
+	require.NoError(t, err)
+
+	restored, eventID, err := RestoreDB(context.Background(), db, dbPath, path, "agent1", "restore_1")
+	require.NoError(t, err)
+	assert.Equal(t, info.Tables["tasks"], restored.Tables["tasks"])
+	assert.Positive(t, eventID)
+
+	var count int64
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM tasks`).Scan(&count))
+	assert.Equal(t, info.Tables["tasks"], count, "writes after the backup are gone")
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE title = 'After backup'`).Scan(&count))
+	assert.Zero(t, count)
+
+	// A retried restore replays instead of wiping what was written since.
+	_, err = CreateTask(context.Background(), db, "After restore", "", "", 0)
+	require.NoError(t, err)
+	_, replayID, err := RestoreDB(context.Background(), db, dbPath, path, "agent1", "restore_1")
+	require.NoError(t, err)
+	assert.Equal(t, eventID, replayID)
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE title = 'After restore'`).Scan(&count))
+	assert.Equal(t, int64(1), count)
+
+	_, err = AppendEventIdempotent(context.Background(), db, "agent1", "used_elsewhere", "progress", "", "note")
+	require.NoError(t, err)
+	_, _, err = RestoreDB(context.Background(), db, dbPath, path, "agent1", "used_elsewhere")
+	require.ErrorContains(t, err, "collision")
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE title = 'After restore'`).Scan(&count))
+	assert.Equal(t, int64(1), count, "a colliding request id fails before overwriting")
+}
+
+func TestRestoreDB_RejectsCorruptFile(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "live.db")
+	db, err := InitDBWithPath(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	bad := filepath.Join(t.TempDir(), "bad.db")
+	require.NoError(t, os.WriteFile(bad, []byte("not a database"), 0o600))
+	_, _, err = RestoreDB(context.Background(), db, dbPath, bad, "agent1", "restore_bad")
+	require.Error(t, err)
+}
+
+func TestRollingBackups_ListPruneAndPointInTime(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, os.WriteFile(RollingBackupPath(dir, base.Add(time.Duration(i)*time.Hour)), []byte("x"), 0o600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "task-delete-20250601T120000.000000000Z.db"), []byte("x"), 0o600))
+
+	backups, err := ListRollingBackups(dir)
+	require.NoError(t, err)
+	require.Len(t, backups, 4, "non-rolling files are ignored")
+	assert.Equal(t, base.Add(3*time.Hour), backups[0].TakenAt, "newest first")
+
+	at, err := RollingBackupAt(dir, base.Add(90*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, base.Add(time.Hour), at.TakenAt)
+	_, err = RollingBackupAt(dir, base.Add(-time.Minute))
+	require.Error(t, err)
+
+	removed, err := PruneRollingBackups(dir, 2)
+	require.NoError(t, err)
+	assert.Len(t, removed, 2)
+	backups, err = ListRollingBackups(dir)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, base.Add(2*time.Hour), backups[1].TakenAt)
+
+	missing, err := ListRollingBackups(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
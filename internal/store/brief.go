@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 const andProjectIDFilter = " AND project_id = ?"
@@ -16,96 +19,206 @@ const andProjectIDFilter = " AND project_id = ?"
 // Keep in sync with the LIMIT clause in fetchRelevantMemory SQL queries.
 const memoryBriefLimit = 50
 
+// overdueBriefLimit caps the overdue tasks surfaced at the top of a brief.
+const overdueBriefLimit = 5
+
+// delegationBriefLimit caps the sub-agent delegations a brief lists.
+const delegationBriefLimit = 10
+
 // PipelineTask is a lightweight task reference for discovery context.
 type PipelineTask struct {
-	ID       string `json:"id"`
-	Title    string `json:"title"`
-	Priority int    `json:"priority"`
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags,omitempty"`
 }
 
+// BriefSchemaVersion is the major version of the BriefPacket JSON shape,
+// reported as brief_version and published by 'vybe schema --brief'. Within a
+// major version changes are additive only: fields are never removed, renamed,
+// or retyped, so parsers written against it keep working. Anything else needs
+// a new major version.
+const BriefSchemaVersion = "v1"
+
 // BriefPacket contains all context needed for an agent to resume work.
 type BriefPacket struct {
-	BriefVersion   string             `json:"brief_version"`
-	Task           *models.Task       `json:"task"`
-	Project        *models.Project    `json:"project,omitempty"`
-	RelevantMemory []*models.Memory   `json:"relevant_memory"`
-	RecentEvents   []*models.Event    `json:"recent_events"`
-	Artifacts      []*models.Artifact `json:"artifacts"`
-	PriorReasoning []*models.Event    `json:"prior_reasoning"`
-	ApproxTokens   int                `json:"approx_tokens"`
-	Counts         *TaskStatusCounts  `json:"counts,omitempty"`
-	Pipeline       []PipelineTask     `json:"pipeline,omitempty"`
+	BriefVersion    string                   `json:"brief_version"`
+	Task            *models.Task             `json:"task"`
+	Criteria        []models.TaskCriterion   `json:"criteria,omitempty"`
+	Dependencies    []DependencyRef          `json:"dependencies,omitempty"`
+	Project         *models.Project          `json:"project,omitempty"`
+	RelevantMemory  []*models.Memory         `json:"relevant_memory"`
+	RecentEvents    []*models.Event          `json:"recent_events"`
+	Artifacts       []*models.Artifact       `json:"artifacts"`
+	PriorReasoning  []*models.Event          `json:"prior_reasoning"`
+	ApproxTokens    int                      `json:"approx_tokens"`
+	Counts          *TaskStatusCounts        `json:"counts,omitempty"`
+	Pipeline        []PipelineTask           `json:"pipeline,omitempty"`
+	Budget          *BriefBudget             `json:"budget,omitempty"`
+	UnreadMessages  int                      `json:"unread_messages,omitempty"`
+	Notices         []models.Message         `json:"notices,omitempty"`
+	NextActions     []NextAction             `json:"next_actions,omitempty"`
+	Overdue         []*models.Task           `json:"overdue,omitempty"`
+	Onboarding      *OnboardingBrief         `json:"onboarding,omitempty"`
+	Lessons         []*Lesson                `json:"lessons,omitempty"`
+	Decisions       []*models.Decision       `json:"decisions,omitempty"`
+	Delegations     []TaskDelegation         `json:"delegations,omitempty"`
+	MemoryConflicts []*models.MemoryConflict `json:"memory_conflicts,omitempty"`
 }
 
-// BuildBrief constructs a brief packet for a focus task and optional project.
-func BuildBrief(db *sql.DB, focusTaskID, focusProjectID, agentName string) (*BriefPacket, error) {
+// BriefBuildOptions adjusts what BuildBriefWithOptions puts in a brief.
+type BriefBuildOptions struct {
+	// IncludeAgentMemory ranks the agent's own agent-scoped memory into
+	// relevant_memory, so personal preferences and lessons travel with it.
+	IncludeAgentMemory bool
+	// LessonLimit caps the top-ranked lessons the brief carries. 0 means the
+	// brief.max_lessons config value; negative means none.
+	LessonLimit int
+}
+
+// BuildBrief constructs a brief packet for a focus task and optional project,
+// ending with the next_actions derived from it. Agent-scoped memory is
+// included when brief.include_agent_memory is set in config.
+func BuildBrief(ctx context.Context, db *sql.DB, focusTaskID, focusProjectID, agentName string) (*BriefPacket, error) {
+	return BuildBriefWithOptions(ctx, db, focusTaskID, focusProjectID, agentName, BriefBuildOptions{
+		IncludeAgentMemory: app.AgentMemoryInBriefs(),
+	})
+}
+
+// BuildBriefWithOptions is BuildBrief with explicit options instead of config.
+func BuildBriefWithOptions(ctx context.Context, db *sql.DB, focusTaskID, focusProjectID, agentName string, opts BriefBuildOptions) (brief *BriefPacket, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	brief, err = buildBriefSections(ctx, db, focusTaskID, focusProjectID, agentName, opts)
+	if err != nil {
+		return nil, err
+	}
+	brief.NextActions = buildNextActions(ctx, db, brief, agentName, focusProjectID)
+	return brief, nil
+}
+
+func buildBriefSections(ctx context.Context, db *sql.DB, focusTaskID, focusProjectID, agentName string, opts BriefBuildOptions) (*BriefPacket, error) {
 	brief := &BriefPacket{
-		BriefVersion:   "v1",
+		BriefVersion:   BriefSchemaVersion,
 		RelevantMemory: []*models.Memory{},
 		RecentEvents:   []*models.Event{},
 		Artifacts:      []*models.Artifact{},
 		PriorReasoning: []*models.Event{},
 	}
 
+	if agentName != "" {
+		if n, mErr := CountUnreadMessages(ctx, db, agentName); mErr == nil {
+			brief.UnreadMessages = n
+		}
+		if notices, nErr := ListUnreadNotices(ctx, db, agentName); nErr == nil && len(notices) > 0 {
+			brief.Notices = notices
+		}
+		if delegations, dErr := ListAgentDelegations(ctx, db, agentName, delegationBriefLimit); dErr == nil && len(delegations) > 0 {
+			brief.Delegations = delegations
+		}
+	}
+
+	if overdue, oErr := ListOverdueTasks(ctx, db, focusProjectID, time.Now(), overdueBriefLimit); oErr == nil && len(overdue) > 0 {
+		brief.Overdue = overdue
+	}
+	if conflicts, cErr := listBriefMemoryConflicts(ctx, db, focusTaskID, focusProjectID, agentName, memoryConflictBriefLimit); cErr == nil && len(conflicts) > 0 {
+		brief.MemoryConflicts = conflicts
+	}
+
+	lessonLimit := opts.LessonLimit
+	if lessonLimit == 0 {
+		lessonLimit = app.BriefLessonLimit()
+	}
+	if lessons, lErr := TopLessonsForBrief(ctx, db, focusProjectID, lessonLimit); lErr == nil && len(lessons) > 0 {
+		brief.Lessons = lessons
+	}
+
+	if decisions, dErr := listBriefDecisions(ctx, db, focusTaskID, focusProjectID, decisionBriefLimit); dErr == nil && len(decisions) > 0 {
+		brief.Decisions = decisions
+	}
+
+	memoryAgent := ""
+	if opts.IncludeAgentMemory {
+		memoryAgent = agentName
+	}
+
 	if focusTaskID == "" && focusProjectID == "" {
+		if memoryAgent != "" {
+			memory, err := fetchAgentMemory(ctx, db, memoryAgent)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch memory: %w", err)
+			}
+			brief.RelevantMemory = memory
+		}
 		return brief, nil
 	}
 
 	if focusProjectID != "" {
-		project, err := GetProject(db, focusProjectID)
+		project, err := GetProject(ctx, db, focusProjectID)
 		if err == nil {
 			brief.Project = project
 		}
 	}
 
 	if focusTaskID == "" {
-		memory, err := fetchRelevantMemory(db, "", focusProjectID)
+		memory, err := fetchBriefMemory(ctx, db, "", focusProjectID, memoryAgent)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch memory: %w", err)
 		}
 		brief.RelevantMemory = memory
-		reasoning, err := FetchPriorReasoning(db, focusProjectID, 10)
+		reasoning, err := FetchPriorReasoning(ctx, db, focusProjectID, 10)
 		if err == nil && len(reasoning) > 0 {
 			brief.PriorReasoning = reasoning
 		}
 		return brief, nil
 	}
 
-	task, err := GetTask(db, focusTaskID)
+	task, err := GetTask(ctx, db, focusTaskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get focus task: %w", err)
 	}
+	if tags, tErr := ListTaskTags(ctx, db, focusTaskID); tErr == nil && len(tags) > 0 {
+		task.Tags = tags
+	}
 	brief.Task = task
 
-	memory, err := fetchRelevantMemory(db, focusTaskID, focusProjectID)
+	if criteria, cErr := ListTaskCriteria(ctx, db, focusTaskID); cErr == nil && len(criteria) > 0 {
+		brief.Criteria = criteria
+	}
+	if deps, dErr := fetchTaskDependencies(ctx, db, focusTaskID); dErr == nil && len(deps) > 0 {
+		brief.Dependencies = deps
+	}
+
+	memory, err := fetchBriefMemory(ctx, db, focusTaskID, focusProjectID, memoryAgent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch memory: %w", err)
 	}
 	brief.RelevantMemory = memory
 
-	events, err := fetchRecentEvents(db, focusTaskID)
+	events, err := fetchRecentEvents(ctx, db, focusTaskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch events: %w", err)
 	}
-	brief.RecentEvents = events
-	brief.ApproxTokens = estimateApproxTokensFromEventMessages(events)
+	brief.RecentEvents = rollupBriefEvents(events, briefEventLimit)
+	brief.ApproxTokens = estimateApproxTokensFromEventMessages(brief.RecentEvents)
 
-	artifacts, err := fetchArtifacts(db, focusTaskID)
+	artifacts, err := fetchArtifacts(ctx, db, focusTaskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch artifacts: %w", err)
 	}
 	brief.Artifacts = artifacts
 
-	reasoning, err := FetchPriorReasoning(db, focusProjectID, 10)
+	reasoning, err := FetchPriorReasoning(ctx, db, focusProjectID, 10)
 	if err == nil && len(reasoning) > 0 {
 		brief.PriorReasoning = reasoning
 		brief.ApproxTokens += estimateApproxTokensFromEventMessages(reasoning)
 	}
 
-	if counts, cErr := GetTaskStatusCounts(db, focusProjectID); cErr == nil {
+	if counts, cErr := GetTaskStatusCounts(ctx, db, focusProjectID); cErr == nil {
 		brief.Counts = counts
 	}
-	if pipeline, pErr := FetchPipelineTasks(db, focusTaskID, agentName, focusProjectID, 5); pErr == nil && len(pipeline) > 0 {
+	if pipeline, pErr := FetchPipelineTasks(ctx, db, focusTaskID, agentName, focusProjectID, 5); pErr == nil && len(pipeline) > 0 {
 		brief.Pipeline = pipeline
 	}
 
@@ -113,13 +226,13 @@ func BuildBrief(db *sql.DB, focusTaskID, focusProjectID, agentName string) (*Bri
 }
 
 // FetchRecentUserPrompts retrieves the most recent user_prompt events for a project.
-func FetchRecentUserPrompts(db *sql.DB, projectDir string, limit int) ([]*models.Event, error) {
+func FetchRecentUserPrompts(ctx context.Context, db *sql.DB, projectDir string, limit int) ([]*models.Event, error) {
 	if limit <= 0 {
 		limit = 5
 	}
 
 	var events []*models.Event
-	err := RetryWithBackoff(context.Background(), func() error {
+	err := RetryWithBackoff(ctx, func() error {
 		var query string
 		var args []any
 
@@ -142,7 +255,7 @@ func FetchRecentUserPrompts(db *sql.DB, projectDir string, limit int) ([]*models
 			args = []any{limit}
 		}
 
-		rows, err := db.QueryContext(context.Background(), query, args...)
+		rows, err := db.QueryContext(ctx, query, args...)
 		if err != nil {
 			return fmt.Errorf("failed to fetch user prompts: %w", err)
 		}
@@ -159,13 +272,13 @@ func FetchRecentUserPrompts(db *sql.DB, projectDir string, limit int) ([]*models
 }
 
 // FetchPriorReasoning retrieves the most recent reasoning events for a project.
-func FetchPriorReasoning(db *sql.DB, projectID string, limit int) ([]*models.Event, error) {
+func FetchPriorReasoning(ctx context.Context, db *sql.DB, projectID string, limit int) ([]*models.Event, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 
 	var events []*models.Event
-	err := RetryWithBackoff(context.Background(), func() error {
+	err := RetryWithBackoff(ctx, func() error {
 		var query string
 		var args []any
 
@@ -188,7 +301,7 @@ func FetchPriorReasoning(db *sql.DB, projectID string, limit int) ([]*models.Eve
 			args = []any{limit}
 		}
 
-		rows, err := db.QueryContext(context.Background(), query, args...)
+		rows, err := db.QueryContext(ctx, query, args...)
 		if err != nil {
 			return fmt.Errorf("failed to fetch prior reasoning: %w", err)
 		}
@@ -205,9 +318,9 @@ func FetchPriorReasoning(db *sql.DB, projectID string, limit int) ([]*models.Eve
 }
 
 // GetTaskStatusCounts returns task status aggregation, optionally scoped to a project.
-func GetTaskStatusCounts(db *sql.DB, projectID string) (*TaskStatusCounts, error) {
+func GetTaskStatusCounts(ctx context.Context, db *sql.DB, projectID string) (*TaskStatusCounts, error) {
 	counts := &TaskStatusCounts{}
-	err := RetryWithBackoff(context.Background(), func() error {
+	err := RetryWithBackoff(ctx, func() error {
 		var query string
 		var args []any
 
@@ -233,7 +346,7 @@ func GetTaskStatusCounts(db *sql.DB, projectID string) (*TaskStatusCounts, error
 			`
 		}
 
-		return db.QueryRowContext(context.Background(), query, args...).Scan(
+		return db.QueryRowContext(ctx, query, args...).Scan(
 			&counts.Pending,
 			&counts.InProgress,
 			&counts.Completed,
@@ -248,13 +361,13 @@ func GetTaskStatusCounts(db *sql.DB, projectID string) (*TaskStatusCounts, error
 }
 
 // FetchPipelineTasks returns the next pending tasks in queue order, excluding the current focus task.
-func FetchPipelineTasks(db *sql.DB, excludeTaskID, _ /*agentName*/, projectID string, limit int) ([]PipelineTask, error) {
+func FetchPipelineTasks(ctx context.Context, db *sql.DB, excludeTaskID, _ /*agentName*/, projectID string, limit int) ([]PipelineTask, error) {
 	if limit <= 0 {
 		limit = 5
 	}
 
 	var tasks []PipelineTask
-	err := RetryWithBackoff(context.Background(), func() error {
+	err := RetryWithBackoff(ctx, func() error {
 		query := `
 			SELECT id, title, priority FROM tasks
 			WHERE status = 'pending'
@@ -268,7 +381,7 @@ func FetchPipelineTasks(db *sql.DB, excludeTaskID, _ /*agentName*/, projectID st
 		query += " ORDER BY priority DESC, created_at ASC LIMIT ?"
 		args = append(args, limit)
 
-		rows, err := db.QueryContext(context.Background(), query, args...)
+		rows, err := db.QueryContext(ctx, query, args...)
 		if err != nil {
 			return fmt.Errorf("failed to query pipeline tasks: %w", err)
 		}
@@ -288,6 +401,16 @@ func FetchPipelineTasks(db *sql.DB, excludeTaskID, _ /*agentName*/, projectID st
 		return nil, err
 	}
 
+	ids := make([]string, len(tasks))
+	for i, pt := range tasks {
+		ids[i] = pt.ID
+	}
+	if tags, tErr := LoadTaskTags(ctx, db, ids); tErr == nil {
+		for i := range tasks {
+			tasks[i].Tags = tags[tasks[i].ID]
+		}
+	}
+
 	return tasks, nil
 }
 
@@ -303,19 +426,10 @@ func estimateApproxTokensFromEventMessages(events []*models.Event) int {
 	return (totalChars + 3) / 4
 }
 
-// fetchRelevantMemory retrieves memory relevant to a task and/or project, ranked by ACT-R score.
-func fetchRelevantMemory(db *sql.DB, taskID, projectID string) ([]*models.Memory, error) {
-	var memories []*models.Memory
-	var ids []int64
-
-	err := RetryWithBackoff(context.Background(), func() error {
-		var query string
-		var args []any
-
-		// Half-life decay formula: relevance halves every half_life_days days.
-		// Per-entry half_life_days overrides kind defaults (directive→∞, lesson→14d, fact→90d).
-		// Pinned entries sort first; formula is tiebreaker only.
-		relevanceExpr := `(1.0 + access_count) / (1.0 + MAX(
+// memoryRelevanceExpr scores a memory row by half-life decay: relevance halves
+// every half_life_days days since last access. Per-entry half_life_days
+// overrides kind defaults (directive→∞, lesson→14d, fact→90d).
+const memoryRelevanceExpr = `(1.0 + access_count) / (1.0 + MAX(
   (julianday('now') - julianday(COALESCE(last_accessed_at, updated_at)))
   / COALESCE(
       NULLIF(half_life_days, 0),
@@ -326,39 +440,54 @@ func fetchRelevantMemory(db *sql.DB, taskID, projectID string) ([]*models.Memory
       END
     ),
   0.0
-)) AS relevance`
+))`
 
-		if projectID != "" {
-			query = `
-				SELECT id, key, value, value_type, scope, scope_id, expires_at, updated_at, created_at, access_count, last_accessed_at, pinned, kind, half_life_days, ` + relevanceExpr + `
-				FROM memory
-				WHERE (
-					scope = 'global'
-					OR (scope = 'task' AND scope_id = ?)
-					OR (scope = 'project' AND scope_id = ?)
-				)
-				AND (pinned = 1 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
-				ORDER BY pinned DESC, relevance DESC
-				LIMIT 50
-			`
-			args = []any{taskID, projectID}
-		} else {
-			query = `
-				SELECT id, key, value, value_type, scope, scope_id, expires_at, updated_at, created_at, access_count, last_accessed_at, pinned, kind, half_life_days, ` + relevanceExpr + `
-				FROM memory
-				WHERE (
-					scope = 'global'
-					OR (scope = 'task' AND scope_id = ?)
-					OR scope = 'project'
-				)
-				AND (pinned = 1 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
-				ORDER BY pinned DESC, relevance DESC
-				LIMIT 50
-			`
-			args = []any{taskID}
-		}
+// fetchRelevantMemory retrieves memory relevant to a task and/or project, ranked by ACT-R score.
+func fetchRelevantMemory(ctx context.Context, db *sql.DB, taskID, projectID string) ([]*models.Memory, error) {
+	return fetchBriefMemory(ctx, db, taskID, projectID, "")
+}
+
+// fetchBriefMemory is fetchRelevantMemory that also ranks in agentName's own
+// agent-scoped memory when agentName is set. Agent scope is otherwise left out
+// of briefs: it is personal to one agent, and briefs are often shared.
+func fetchBriefMemory(ctx context.Context, db *sql.DB, taskID, projectID, agentName string) ([]*models.Memory, error) {
+	scopes := `scope = 'global' OR (scope = 'task' AND scope_id = ?) OR scope = 'project'`
+	args := []any{taskID}
+	if projectID != "" {
+		scopes = `scope = 'global' OR (scope = 'task' AND scope_id = ?) OR (scope = 'project' AND scope_id = ?)`
+		args = append(args, projectID)
+	}
+	if agentName != "" {
+		scopes += ` OR (scope = 'agent' AND scope_id = ?)`
+		args = append(args, agentName)
+	}
+	return queryBriefMemory(ctx, db, scopes, args)
+}
 
-		rows, err := db.QueryContext(context.Background(), query, args...)
+// fetchAgentMemory retrieves only agentName's agent-scoped memory, for briefs
+// with no focus task or project.
+func fetchAgentMemory(ctx context.Context, db *sql.DB, agentName string) ([]*models.Memory, error) {
+	return queryBriefMemory(ctx, db, `scope = 'agent' AND scope_id = ?`, []any{agentName})
+}
+
+// queryBriefMemory returns up to memoryBriefLimit live memory rows matching
+// scopes, pinned first and then by relevance, and records the access.
+func queryBriefMemory(ctx context.Context, db *sql.DB, scopes string, args []any) ([]*models.Memory, error) {
+	var memories []*models.Memory
+	var ids []int64
+
+	err := RetryWithBackoff(ctx, func() error {
+		// Pinned entries sort first; the relevance formula is tiebreaker only.
+		query := `
+			SELECT id, key, value, value_type, scope, scope_id, expires_at, updated_at, created_at, access_count, last_accessed_at, pinned, kind, half_life_days, ` + memoryRelevanceExpr + ` AS relevance
+			FROM memory
+			WHERE (` + scopes + `)
+			AND (pinned = 1 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			ORDER BY pinned DESC, relevance DESC
+			LIMIT 50
+		`
+
+		rows, err := db.QueryContext(ctx, query, args...)
 		if err != nil {
 			return fmt.Errorf("failed to query memory: %w", err)
 		}
@@ -393,7 +522,7 @@ func fetchRelevantMemory(db *sql.DB, taskID, projectID string) ([]*models.Memory
 		for i, id := range ids {
 			args[i] = id
 		}
-		if _, err := db.ExecContext(context.Background(), updateQuery, args...); err != nil {
+		if _, err := db.ExecContext(ctx, updateQuery, args...); err != nil {
 			slog.Warn("failed to update memory access counts", "error", err)
 		}
 	}
@@ -401,16 +530,16 @@ func fetchRelevantMemory(db *sql.DB, taskID, projectID string) ([]*models.Memory
 	return memories, nil
 }
 
-func fetchRecentEvents(db *sql.DB, taskID string) ([]*models.Event, error) {
+func fetchRecentEvents(ctx context.Context, db *sql.DB, taskID string) ([]*models.Event, error) {
 	var events []*models.Event
-	err := RetryWithBackoff(context.Background(), func() error {
-		rows, err := db.QueryContext(context.Background(), `
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
 			SELECT id, kind, agent_name, project_id, task_id, message, metadata, created_at
 			FROM events
 			WHERE task_id = ? AND archived_at IS NULL
 			ORDER BY id DESC
-			LIMIT 20
-		`, taskID)
+			LIMIT ?
+		`, taskID, briefEventScanLimit)
 		if err != nil {
 			return fmt.Errorf("failed to query events: %w", err)
 		}
@@ -426,46 +555,13 @@ func fetchRecentEvents(db *sql.DB, taskID string) ([]*models.Event, error) {
 	return events, nil
 }
 
-func fetchArtifacts(db *sql.DB, taskID string) ([]*models.Artifact, error) {
-	var artifacts []*models.Artifact
-	err := RetryWithBackoff(context.Background(), func() error {
-		rows, err := db.QueryContext(context.Background(), `
-			SELECT id, task_id, event_id, file_path, content_type, created_at
-			FROM artifacts
-			WHERE task_id = ?
-			ORDER BY created_at DESC
-			LIMIT 100
-		`, taskID)
-		if err != nil {
-			return fmt.Errorf("failed to query artifacts: %w", err)
-		}
-		defer func() { _ = rows.Close() }()
-
-		artifacts = make([]*models.Artifact, 0)
-		for rows.Next() {
-			var artifact models.Artifact
-			var contentType sql.NullString
-			if err := rows.Scan(
-				&artifact.ID,
-				&artifact.TaskID,
-				&artifact.EventID,
-				&artifact.FilePath,
-				&contentType,
-				&artifact.CreatedAt,
-			); err != nil {
-				return fmt.Errorf("failed to scan artifact: %w", err)
-			}
-			if contentType.Valid {
-				artifact.ContentType = contentType.String
-			}
-			artifacts = append(artifacts, &artifact)
-		}
-
-		return rows.Err()
-	})
+func fetchArtifacts(ctx context.Context, db *sql.DB, taskID string) ([]*models.Artifact, error) {
+	artifacts, err := queryArtifacts(ctx, db, artifactSelectSQL+`
+		WHERE a.task_id = ?
+		ORDER BY a.created_at DESC
+		LIMIT 100`, taskID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to query artifacts: %w", err)
 	}
-
 	return artifacts, nil
 }
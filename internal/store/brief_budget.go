@@ -0,0 +1,208 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// DependencyRef is a lightweight reference to a task the focus task depends on.
+type DependencyRef struct {
+	ID     string            `json:"id"`
+	Title  string            `json:"title"`
+	Status models.TaskStatus `json:"status"`
+}
+
+// BriefBudget reports how a brief was shaped to fit --max-tokens.
+// Elided counts are the number of entries dropped from each section.
+type BriefBudget struct {
+	MaxTokens  int            `json:"max_tokens"`
+	UsedTokens int            `json:"used_tokens"`
+	Elided     map[string]int `json:"elided,omitempty"`
+}
+
+// briefFailureKinds are event kinds ranked as "recent failures" when shaping a brief.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var briefFailureKinds = map[string]bool{
+	models.EventKindToolFailure: true,
+	"task_blocked":              true,
+}
+
+// IsBriefFailureKind reports whether events of kind count as recent failures
+// in briefs and session-start context.
+func IsBriefFailureKind(kind string) bool {
+	return briefFailureKinds[kind]
+}
+
+// estimateTextTokens applies the chars/4 heuristic used across brief and prompt sizing.
+func estimateTextTokens(parts ...string) int {
+	n := 0
+	for _, p := range parts {
+		n += utf8.RuneCountInString(p)
+	}
+	return (n + 3) / 4
+}
+
+func eventTokens(e *models.Event) int {
+	return estimateTextTokens(e.Kind, e.Message, string(e.Metadata))
+}
+
+// ShapeBrief trims brief in place so its variable sections fit within maxTokens.
+// Sections are admitted in a fixed priority order — task, dependencies, recent
+// failures, memory and lessons, then history (other events, prior reasoning, artifacts, pipeline)
+// — and within a section entries keep their existing ranking. Each entry is admitted
+// only if it fits, so a large entry never starves smaller ones behind it.
+// The task and its acceptance criteria are always kept. maxTokens <= 0 leaves the brief untouched.
+func ShapeBrief(brief *BriefPacket, maxTokens int) {
+	if brief == nil || maxTokens <= 0 {
+		return
+	}
+
+	budget := &BriefBudget{MaxTokens: maxTokens, Elided: map[string]int{}}
+	remaining := maxTokens
+	admit := func(cost int) bool {
+		if cost > remaining {
+			return false
+		}
+		remaining -= cost
+		return true
+	}
+
+	// 1. Task and its acceptance criteria (always kept, but charged).
+	if brief.Task != nil {
+		remaining -= estimateTextTokens(brief.Task.ID, brief.Task.Title, brief.Task.Description, string(brief.Task.Status))
+	}
+	for _, c := range brief.Criteria {
+		remaining -= estimateTextTokens(c.Text) + 1
+	}
+
+	// 2. Dependencies.
+	deps := brief.Dependencies[:0]
+	for _, d := range brief.Dependencies {
+		if admit(estimateTextTokens(d.ID, d.Title, string(d.Status))) {
+			deps = append(deps, d)
+		} else {
+			budget.Elided["dependencies"]++
+		}
+	}
+	brief.Dependencies = deps
+
+	// 3. Recent failures, then 5. remaining history events — one pass decides failures first.
+	keepEvent := make(map[int64]bool, len(brief.RecentEvents))
+	for _, e := range brief.RecentEvents {
+		if briefFailureKinds[e.Kind] && admit(eventTokens(e)) {
+			keepEvent[e.ID] = true
+		}
+	}
+
+	// 4. Memory, then lessons.
+	memory := brief.RelevantMemory[:0]
+	for _, m := range brief.RelevantMemory {
+		if admit(estimateTextTokens(m.Key, m.Value)) {
+			memory = append(memory, m)
+		} else {
+			budget.Elided["relevant_memory"]++
+		}
+	}
+	brief.RelevantMemory = memory
+
+	lessons := brief.Lessons[:0]
+	for _, l := range brief.Lessons {
+		if admit(estimateTextTokens(l.Text)) {
+			lessons = append(lessons, l)
+		} else {
+			budget.Elided["lessons"]++
+		}
+	}
+	brief.Lessons = lessons
+
+	// 5. History.
+	for _, e := range brief.RecentEvents {
+		if !briefFailureKinds[e.Kind] && admit(eventTokens(e)) {
+			keepEvent[e.ID] = true
+		}
+	}
+	events := brief.RecentEvents[:0]
+	for _, e := range brief.RecentEvents {
+		if keepEvent[e.ID] {
+			events = append(events, e)
+		} else {
+			budget.Elided["recent_events"]++
+		}
+	}
+	brief.RecentEvents = events
+
+	reasoning := brief.PriorReasoning[:0]
+	for _, e := range brief.PriorReasoning {
+		if admit(eventTokens(e)) {
+			reasoning = append(reasoning, e)
+		} else {
+			budget.Elided["prior_reasoning"]++
+		}
+	}
+	brief.PriorReasoning = reasoning
+
+	artifacts := brief.Artifacts[:0]
+	for _, a := range brief.Artifacts {
+		if admit(estimateTextTokens(a.FilePath, a.ContentType)) {
+			artifacts = append(artifacts, a)
+		} else {
+			budget.Elided["artifacts"]++
+		}
+	}
+	brief.Artifacts = artifacts
+
+	pipeline := brief.Pipeline[:0]
+	for _, p := range brief.Pipeline {
+		if admit(estimateTextTokens(p.ID, p.Title)) {
+			pipeline = append(pipeline, p)
+		} else {
+			budget.Elided["pipeline"]++
+		}
+	}
+	brief.Pipeline = pipeline
+
+	if len(budget.Elided) == 0 {
+		budget.Elided = nil
+	}
+	budget.UsedTokens = maxTokens - remaining
+	brief.ApproxTokens = estimateApproxTokensFromEventMessages(brief.RecentEvents) +
+		estimateApproxTokensFromEventMessages(brief.PriorReasoning)
+	brief.Budget = budget
+}
+
+// fetchTaskDependencies returns the tasks taskID depends on that are not yet completed.
+func fetchTaskDependencies(ctx context.Context, db *sql.DB, taskID string) ([]DependencyRef, error) {
+	var deps []DependencyRef
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT t.id, t.title, t.status
+			FROM task_dependencies d
+			JOIN tasks t ON t.id = d.depends_on_task_id
+			WHERE d.task_id = ? AND t.status != 'completed'
+			ORDER BY t.priority DESC, t.created_at ASC
+		`, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to query task dependencies: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		deps = make([]DependencyRef, 0)
+		for rows.Next() {
+			var d DependencyRef
+			if err := rows.Scan(&d.ID, &d.Title, &d.Status); err != nil {
+				return fmt.Errorf("failed to scan task dependency: %w", err)
+			}
+			deps = append(deps, d)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
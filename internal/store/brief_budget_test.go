@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestShapeBrief_PriorityOrder(t *testing.T) {
+	long := strings.Repeat("x", 400) // ~100 tokens each
+
+	brief := &BriefPacket{
+		Task:         &models.Task{ID: "task_1", Title: "Focus"},
+		Dependencies: []DependencyRef{{ID: "task_dep", Title: "Dependency", Status: models.TaskStatusPending}},
+		RelevantMemory: []*models.Memory{
+			{Key: "m1", Value: long},
+			{Key: "m2", Value: long},
+		},
+		RecentEvents: []*models.Event{
+			{ID: 3, Kind: "progress", Message: long},
+			{ID: 2, Kind: models.EventKindToolFailure, Message: long},
+			{ID: 1, Kind: "note", Message: "short"},
+		},
+		Artifacts:      []*models.Artifact{{FilePath: "a.go"}},
+		PriorReasoning: []*models.Event{{ID: 9, Kind: models.EventKindReasoning, Message: long}},
+	}
+
+	ShapeBrief(brief, 250)
+
+	require.Len(t, brief.Dependencies, 1)
+	require.Len(t, brief.RecentEvents, 2, "failure outranks progress; short note still fits")
+	require.Equal(t, models.EventKindToolFailure, brief.RecentEvents[0].Kind)
+	require.Equal(t, "note", brief.RecentEvents[1].Kind)
+	require.Len(t, brief.RelevantMemory, 1)
+	require.Empty(t, brief.PriorReasoning)
+	require.Len(t, brief.Artifacts, 1)
+
+	require.NotNil(t, brief.Budget)
+	require.Equal(t, 250, brief.Budget.MaxTokens)
+	require.LessOrEqual(t, brief.Budget.UsedTokens, 250)
+	require.Equal(t, map[string]int{"relevant_memory": 1, "recent_events": 1, "prior_reasoning": 1}, brief.Budget.Elided)
+}
+
+func TestShapeBrief_ZeroBudgetIsNoop(t *testing.T) {
+	brief := &BriefPacket{RecentEvents: []*models.Event{{ID: 1, Kind: "note", Message: "hello"}}}
+	ShapeBrief(brief, 0)
+	require.Len(t, brief.RecentEvents, 1)
+	require.Nil(t, brief.Budget)
+
+	ShapeBrief(nil, 100)
+}
+
+func TestBuildBrief_IncludesOpenDependencies(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	focus, err := CreateTask(context.Background(), db, "Focus", "", "", 0)
+	require.NoError(t, err)
+	open, err := CreateTask(context.Background(), db, "Open dep", "", "", 0)
+	require.NoError(t, err)
+	done, err := CreateTask(context.Background(), db, "Done dep", "", "", 0)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE tasks SET status = 'completed' WHERE id = ?`, done.ID)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO task_dependencies (task_id, depends_on_task_id) VALUES (?, ?), (?, ?)`,
+		focus.ID, open.ID, focus.ID, done.ID)
+	require.NoError(t, err)
+
+	brief, err := BuildBrief(context.Background(), db, focus.ID, "", "agent1")
+	require.NoError(t, err)
+	require.Len(t, brief.Dependencies, 1)
+	require.Equal(t, open.ID, brief.Dependencies[0].ID)
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -14,14 +15,14 @@ func TestFetchRelevantMemoryPinnedRanksFirst(t *testing.T) {
 	t.Cleanup(cleanup)
 
 	// Write pinned entry with access_count=0 (freshly inserted, never read)
-	require.NoError(t, SetMemory(db, "pinned-arch", "decision", "string", "global", "", nil, true, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "pinned-arch", "decision", "string", "global", "", nil, true, "", nil))
 
 	// Write unpinned entry then simulate high access_count via direct SQL
-	require.NoError(t, SetMemory(db, "hot-key", "frequent", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "hot-key", "frequent", "string", "global", "", nil, false, "", nil))
 	_, err := db.Exec(`UPDATE memory SET access_count = 100 WHERE key = 'hot-key'`)
 	require.NoError(t, err)
 
-	mems, err := fetchRelevantMemory(db, "", "")
+	mems, err := fetchRelevantMemory(context.Background(), db, "", "")
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, len(mems), 2)
 
@@ -48,11 +49,11 @@ func TestFetchRelevantMemoryPinnedBypassesTTL(t *testing.T) {
 	past := time.Now().Add(-2 * time.Hour)
 
 	// Pinned entry with past expires_at
-	require.NoError(t, SetMemory(db, "pinned-expired", "value", "string", "global", "", &past, true, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "pinned-expired", "value", "string", "global", "", &past, true, "", nil))
 	// Unpinned entry with past expires_at — should NOT appear
-	require.NoError(t, SetMemory(db, "unpinned-expired", "value", "string", "global", "", &past, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "unpinned-expired", "value", "string", "global", "", &past, false, "", nil))
 
-	mems, err := fetchRelevantMemory(db, "", "")
+	mems, err := fetchRelevantMemory(context.Background(), db, "", "")
 	require.NoError(t, err)
 
 	var foundPinned, foundUnpinned bool
@@ -67,3 +68,33 @@ func TestFetchRelevantMemoryPinnedBypassesTTL(t *testing.T) {
 	assert.True(t, foundPinned, "pinned expired entry must appear in brief")
 	assert.False(t, foundUnpinned, "unpinned expired entry must not appear in brief")
 }
+
+func TestBuildBriefIncludeAgentMemory(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupMemoryTestDB(t)
+	t.Cleanup(cleanup)
+
+	require.NoError(t, SetMemory(context.Background(), db, "style", "terse commits", "string", "agent", "alice", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "style", "verbose commits", "string", "agent", "bob", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "repo", "monorepo", "string", "project", "p1", nil, false, "", nil))
+
+	keys := func(b *BriefPacket) []string {
+		var out []string
+		for _, m := range b.RelevantMemory {
+			out = append(out, m.Key+"="+m.Value)
+		}
+		return out
+	}
+
+	brief, err := BuildBriefWithOptions(context.Background(), db, "", "p1", "alice", BriefBuildOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"repo=monorepo"}, keys(brief), "agent scope is opt-in")
+
+	brief, err = BuildBriefWithOptions(context.Background(), db, "", "p1", "alice", BriefBuildOptions{IncludeAgentMemory: true})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"repo=monorepo", "style=terse commits"}, keys(brief), "only the agent's own entries")
+
+	brief, err = BuildBriefWithOptions(context.Background(), db, "", "", "alice", BriefBuildOptions{IncludeAgentMemory: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"style=terse commits"}, keys(brief), "agent memory travels without a focus")
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+const (
+	// briefEventScanLimit is how many recent task events a brief reads before
+	// rolling up repeats; briefEventLimit caps the entries it keeps.
+	briefEventScanLimit = 100
+	briefEventLimit     = 20
+)
+
+// isBriefRollupKind reports whether repeats of an event kind collapse into one
+// brief entry. Only hook tool events qualify: they are high-volume and
+// near-identical, while progress and notes carry distinct signal.
+func isBriefRollupKind(kind string) bool {
+	return kind == models.EventKindToolSuccess || kind == models.EventKindToolFailure
+}
+
+// briefEventFile returns the file a tool event touched, from its metadata.
+func briefEventFile(e *models.Event) string {
+	var meta struct {
+		FilePath         string `json:"file_path"`
+		ToolInputPreview string `json:"tool_input_preview"`
+	}
+	if len(e.Metadata) == 0 || json.Unmarshal(e.Metadata, &meta) != nil {
+		return ""
+	}
+	if meta.FilePath != "" {
+		return meta.FilePath
+	}
+	return ToolInputFilePath(meta.ToolInputPreview)
+}
+
+// rollupBriefEvents collapses tool events with the same kind, message, and
+// file into their newest occurrence, counting the repeats in RollupCount, and
+// keeps at most limit entries. events must be newest first; order is kept.
+func rollupBriefEvents(events []*models.Event, limit int) []*models.Event {
+	out := make([]*models.Event, 0, min(len(events), limit))
+	groups := make(map[string]*models.Event)
+	for _, e := range events {
+		var key string
+		if isBriefRollupKind(e.Kind) {
+			key = e.Kind + "\x00" + e.Message + "\x00" + briefEventFile(e)
+			if kept, ok := groups[key]; ok {
+				kept.RollupCount++
+				continue
+			}
+		}
+		if len(out) == limit {
+			continue
+		}
+		if key != "" {
+			e.RollupCount = 1
+			groups[key] = e
+		}
+		out = append(out, e)
+	}
+	for _, e := range out {
+		if e.RollupCount == 1 {
+			e.RollupCount = 0
+		}
+	}
+	return out
+}
+
+// BriefEventLabel renders a brief event's text: the message, the file a
+// tool event touched, and "×N" when the entry stands for N identical events.
+func BriefEventLabel(e *models.Event) string {
+	label := e.Message
+	if isBriefRollupKind(e.Kind) {
+		if file := briefEventFile(e); file != "" {
+			label += ": " + file
+		}
+	}
+	if e.RollupCount > 1 {
+		label += fmt.Sprintf(" ×%d", e.RollupCount)
+	}
+	return label
+}
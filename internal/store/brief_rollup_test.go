@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestBuildBrief_RollsUpRepeatedToolEvents(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Focus", "", "", 0)
+	require.NoError(t, err)
+
+	appendEvent(t, db, models.EventKindProgress, "agent1", task.ID, "started")
+	appendEvent(t, db, models.EventKindProgress, "agent1", task.ID, "started")
+	for range 7 {
+		appendEventWithMetadata(t, db, models.EventKindToolSuccess, "agent1", task.ID, "Edit succeeded", `{"tool_name":"Edit","file_path":"foo.go"}`)
+	}
+	appendEventWithMetadata(t, db, models.EventKindToolSuccess, "agent1", task.ID, "Edit succeeded", `{"tool_name":"Edit","tool_input_preview":"{\"file_path\":\"bar.go\""}`)
+	last := appendEventWithMetadata(t, db, models.EventKindToolSuccess, "agent1", task.ID, "Edit succeeded", `{"tool_name":"Edit","file_path":"foo.go"}`)
+
+	brief, err := BuildBrief(context.Background(), db, task.ID, "", "agent1")
+	require.NoError(t, err)
+	require.Len(t, brief.RecentEvents, 4, "progress events are never rolled up")
+
+	foo := brief.RecentEvents[0]
+	assert.Equal(t, last, foo.ID, "the newest occurrence is kept")
+	assert.Equal(t, 8, foo.RollupCount)
+	assert.Equal(t, "Edit succeeded: foo.go ×8", BriefEventLabel(foo))
+
+	bar := brief.RecentEvents[1]
+	assert.Equal(t, 0, bar.RollupCount)
+	assert.Equal(t, "Edit succeeded: bar.go", BriefEventLabel(bar))
+
+	assert.Equal(t, "started", BriefEventLabel(brief.RecentEvents[2]))
+}
+
+func TestRollupBriefEvents_CapsDistinctEntries(t *testing.T) {
+	var events []*models.Event
+	for i := range 30 {
+		events = append(events, &models.Event{ID: int64(100 - i), Kind: models.EventKindToolFailure,
+			Message: fmt.Sprintf("Bash failed (%d)", i%25)})
+	}
+
+	out := rollupBriefEvents(events, 20)
+	require.Len(t, out, 20)
+	assert.Equal(t, int64(100), out[0].ID)
+	assert.Equal(t, 2, out[0].RollupCount, "repeats past the cap still count toward kept entries")
+	assert.Equal(t, 0, out[19].RollupCount)
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// NormalizeCapabilities normalizes capability names the way tags are
+// normalized (trimmed, lowercased, deduplicated) and rejects invalid ones, so a
+// task's requirements and an agent's capabilities compare equal.
+func NormalizeCapabilities(capabilities []string) ([]string, error) {
+	out := normalizeTags(capabilities)
+	for _, c := range out {
+		if len(c) > maxTaskTagLength {
+			return nil, fmt.Errorf("capability exceeds %d characters: %s", maxTaskTagLength, c)
+		}
+		if !taskTagPattern.MatchString(c) {
+			return nil, fmt.Errorf("invalid capability %q (use letters, digits, '.', '_', '-', ':', '/')", c)
+		}
+	}
+	return out, nil
+}
+
+// AgentRegistration is the result of registering an agent's capabilities.
+type AgentRegistration struct {
+	AgentName    string   `json:"agent_name"`
+	Capabilities []string `json:"capabilities"`
+	EventID      int64    `json:"event_id"`
+}
+
+// SetAgentCapabilitiesTx replaces the agent's capability set and emits an
+// agent_registered event. An empty set clears it, leaving the agent able to
+// take only tasks without requirements.
+func SetAgentCapabilitiesTx(tx *sql.Tx, agentName string, capabilities []string) (*AgentRegistration, error) {
+	capabilities, err := NormalizeCapabilities(capabilities)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureAgentStateTx(tx, agentName); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(context.Background(),
+		`DELETE FROM agent_capabilities WHERE agent_name = ?`, agentName); err != nil {
+		return nil, fmt.Errorf("failed to clear agent capabilities: %w", err)
+	}
+	for _, c := range capabilities {
+		if _, err := tx.ExecContext(context.Background(),
+			`INSERT INTO agent_capabilities (agent_name, capability) VALUES (?, ?)`, agentName, c); err != nil {
+			return nil, fmt.Errorf("failed to insert agent capability: %w", err)
+		}
+	}
+
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+	slices.Sort(capabilities)
+	meta, _ := json.Marshal(map[string]any{"capabilities": capabilities})
+	msg := "Agent registered with no capabilities"
+	if len(capabilities) > 0 {
+		msg = "Agent registered: " + strings.Join(capabilities, ", ")
+	}
+	eventID, err := InsertEventTx(tx, models.EventKindAgentRegistered, agentName, "", msg, string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append agent registered event: %w", err)
+	}
+	return &AgentRegistration{AgentName: agentName, Capabilities: capabilities, EventID: eventID}, nil
+}
+
+// RegisterAgentIdempotent performs SetAgentCapabilitiesTx once per (agent_name, request_id).
+func RegisterAgentIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, capabilities []string) (*AgentRegistration, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "agent.register", func(tx *sql.Tx) (*AgentRegistration, error) {
+		return SetAgentCapabilitiesTx(tx, agentName, capabilities)
+	})
+}
+
+// GetAgentCapabilities returns the agent's registered capabilities in
+// alphabetical order.
+func GetAgentCapabilities(ctx context.Context, db *sql.DB, agentName string) ([]string, error) {
+	var out []string
+	err := RetryWithBackoff(ctx, func() error {
+		c, err := queryStrings(ctx, db,
+			`SELECT capability FROM agent_capabilities WHERE agent_name = ? ORDER BY capability`, agentName)
+		out = c
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent capabilities: %w", err)
+	}
+	return out, nil
+}
+
+// SetTaskRequirementsTx records the capabilities an agent needs to be routed
+// the task. Requirements already present are kept.
+func SetTaskRequirementsTx(tx *sql.Tx, taskID string, capabilities []string) ([]string, error) {
+	capabilities, err := NormalizeCapabilities(capabilities)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range capabilities {
+		if _, err := tx.ExecContext(context.Background(),
+			`INSERT OR IGNORE INTO task_requirements (task_id, capability) VALUES (?, ?)`, taskID, c); err != nil {
+			return nil, fmt.Errorf("failed to insert task requirement: %w", err)
+		}
+	}
+	return queryStrings(context.Background(), tx,
+		`SELECT capability FROM task_requirements WHERE task_id = ? ORDER BY capability`, taskID)
+}
+
+// ListTaskRequirements returns the capabilities a task requires in
+// alphabetical order.
+func ListTaskRequirements(ctx context.Context, db *sql.DB, taskID string) ([]string, error) {
+	var out []string
+	err := RetryWithBackoff(ctx, func() error {
+		c, err := queryStrings(ctx, db,
+			`SELECT capability FROM task_requirements WHERE task_id = ? ORDER BY capability`, taskID)
+		out = c
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task requirements: %w", err)
+	}
+	return out, nil
+}
+
+func queryStrings(ctx context.Context, q queryer, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]string, 0)
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+func TestCapabilities_RouteClaims(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	requires := func(title string, priority int, capabilities ...string) string {
+		task, err := CreateTask(context.Background(), db, title, "", "", priority)
+		require.NoError(t, err)
+		require.NoError(t, Transact(t.Context(), db, func(tx *sql.Tx) error {
+			_, err := SetTaskRequirementsTx(tx, task.ID, capabilities)
+			return err
+		}))
+		return task.ID
+	}
+	migration := requires("Migrate schema", 9, "go", "db-migrations")
+	frontend := requires("Fix button", 5, "Frontend")
+	anyone := requires("Update README", 1)
+
+	got, err := ListTaskRequirements(context.Background(), db, migration)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db-migrations", "go"}, got)
+
+	reg, err := RegisterAgentIdempotent(context.Background(), db, "gopher", "reg_1", []string{" Go ", "go"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go"}, reg.Capabilities)
+	assert.NotZero(t, reg.EventID)
+	_, err = RegisterAgentIdempotent(context.Background(), db, "gopher", "reg_bad", []string{"has space"})
+	require.Error(t, err)
+
+	claim := func(agent, req string) string {
+		r, err := ClaimNextTaskIdempotent(context.Background(), db, agent, req, "", app.FocusPriorityFirst, FocusFilter{RoutedTo: agent}, ClaimOptions{})
+		require.NoError(t, err)
+		return r.TaskID
+	}
+
+	// gopher lacks db-migrations and frontend, so only the unrestricted task fits.
+	assert.Equal(t, anyone, claim("gopher", "claim_1"))
+	assert.Empty(t, claim("gopher", "claim_2"))
+
+	_, err = RegisterAgentIdempotent(context.Background(), db, "gopher", "reg_2", []string{"go", "db-migrations"})
+	require.NoError(t, err)
+	assert.Equal(t, migration, claim("gopher", "claim_3"))
+
+	_, err = RegisterAgentIdempotent(context.Background(), db, "designer", "reg_3", []string{"frontend"})
+	require.NoError(t, err)
+	assert.Equal(t, frontend, claim("designer", "claim_4"))
+
+	caps, err := GetAgentCapabilities(context.Background(), db, "gopher")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db-migrations", "go"}, caps)
+}
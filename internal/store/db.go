@@ -10,15 +10,21 @@ import (
 	"strings"
 
 	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/telemetry"
 	_ "modernc.org/sqlite"
 )
 
 // CloseDB runs PRAGMA optimize then closes the connection.
 // Use this instead of db.Close() for proper SQLite lifecycle management.
 // PRAGMA optimize updates query planner statistics accumulated during the session.
+// Releases the network_fs: lock writer lock if OpenDB took one.
 func CloseDB(db *sql.DB) error {
 	_, _ = db.ExecContext(context.Background(), "PRAGMA optimize")
-	return db.Close()
+	err := db.Close()
+	if release, ok := writerLocks.LoadAndDelete(db); ok {
+		release.(func())()
+	}
+	return err
 }
 
 // CheckpointWAL triggers a WAL checkpoint.
@@ -51,18 +57,27 @@ const defaultBusyTimeoutMS = 5000
 // OpenDB opens a database connection and configures SQLite pragmas, but does
 // NOT run migrations. Use InitDBWithPath for test/upgrade scenarios, or pair
 // with MigrateDB for production commands (auto-migration on every open).
-func OpenDB(dbPath string) (*sql.DB, error) {
+func OpenDB(ctx context.Context, dbPath string) (_ *sql.DB, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	absPath, err := app.EnsureDBDir(dbPath)
 	if err != nil {
 		return nil, err
 	}
 
+	journalMode, releaseLock, err := prepareNetworkFS(absPath)
+	if err != nil {
+		return nil, err
+	}
+
 	// Open database connection
 	//
 	// modernc.org/sqlite is strict about DSNs. Use a file: URI with mode=rwc
 	// so the database can be created/written consistently across platforms.
 	db, err := sql.Open("sqlite", normalizeSQLiteDSN(absPath))
 	if err != nil {
+		releaseLock()
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
@@ -91,6 +106,8 @@ func OpenDB(dbPath string) (*sql.DB, error) {
 	//                        last WAL checkpoint on OS crash, not data corruption).
 	//   journal_mode=WAL   — allows concurrent readers + one writer; required
 	//                        for multi-agent access to the same DB file.
+	//                        DELETE in network_fs: lock mode (WAL needs shared
+	//                        memory, which network filesystems do not provide).
 	//   temp_store=MEMORY  — keeps temp tables/indices in RAM instead of disk files.
 	//   mmap_size          — 64MB virtual memory mapping for faster reads (no physical RAM cost).
 	//   cache_size         — ~8MB page cache (default is ~2MB); reduces disk I/O for repeated access.
@@ -100,7 +117,7 @@ func OpenDB(dbPath string) (*sql.DB, error) {
 		fmt.Sprintf("PRAGMA busy_timeout=%d", busyTimeout),
 		"PRAGMA foreign_keys=ON",
 		"PRAGMA synchronous=NORMAL",
-		"PRAGMA journal_mode=WAL",
+		"PRAGMA journal_mode=" + journalMode,
 		"PRAGMA temp_store=MEMORY",
 		"PRAGMA mmap_size=67108864",      // 64MB — virtual memory, not physical
 		"PRAGMA cache_size=-8000",        // ~8MB page cache (negative = kibibytes)
@@ -108,26 +125,61 @@ func OpenDB(dbPath string) (*sql.DB, error) {
 	}
 
 	for _, pragma := range pragmas {
-		if err := RetryWithBackoff(context.Background(), func() error {
-			_, err := db.ExecContext(context.Background(), pragma)
+		if err := RetryWithBackoff(ctx, func() error {
+			_, err := db.ExecContext(ctx, pragma)
 			return err
 		}); err != nil {
 			_ = db.Close()
+			releaseLock()
 			return nil, fmt.Errorf("failed to set pragma %q: %w", pragma, err)
 		}
 	}
 
+	if journalMode != "WAL" {
+		writerLocks.Store(db, releaseLock)
+	}
 	return db, nil
 }
 
+// prepareNetworkFS checks whether the database is on a network filesystem and
+// applies app.NetworkFSMode: warn logs loudly; lock takes the single-writer
+// lock and selects the rollback journal. Returns the journal mode and a
+// release func (a no-op unless a lock was taken).
+func prepareNetworkFS(absPath string) (string, func(), error) {
+	noop := func() {}
+	if strings.Contains(absPath, ":memory:") {
+		return "WAL", noop, nil
+	}
+	mode := app.NetworkFSMode()
+	if mode == app.NetworkFSIgnore {
+		return "WAL", noop, nil
+	}
+	fs, err := DetectFilesystem(absPath)
+	if err != nil || !fs.Network {
+		return "WAL", noop, nil //nolint:nilerr // detection is best effort
+	}
+
+	if mode != app.NetworkFSLock {
+		slog.Default().Warn("database is on a network filesystem; SQLite locking is unreliable there and concurrent writers can corrupt it",
+			"path", absPath, "filesystem", fs.Type,
+			"fix", "move db_path to a local disk, or set network_fs: lock (VYBE_NETWORK_FS=lock) for single-writer mode")
+		return "WAL", noop, nil
+	}
+	release, err := acquireWriterLock(absPath, writerLockWait)
+	if err != nil {
+		return "", nil, err
+	}
+	return "DELETE", release, nil
+}
+
 // InitDBWithPath opens a database and runs migrations. Used by tests and
 // scenarios that need a single open+migrate call.
 func InitDBWithPath(dbPath string) (*sql.DB, error) {
-	db, err := OpenDB(dbPath)
+	db, err := OpenDB(context.Background(), dbPath)
 	if err != nil {
 		return nil, err
 	}
-	if err := MigrateDB(db, dbPath); err != nil {
+	if err := MigrateDB(context.Background(), db, dbPath); err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
@@ -0,0 +1,278 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// Maintenance steps, in the order MaintainDB runs them.
+const (
+	MaintainStepIntegrity  = "integrity"
+	MaintainStepVacuum     = "vacuum"
+	MaintainStepAnalyze    = "analyze"
+	MaintainStepCheckpoint = "checkpoint"
+)
+
+// MaintainSteps lists every maintenance step in run order.
+func MaintainSteps() []string {
+	return []string{MaintainStepIntegrity, MaintainStepVacuum, MaintainStepAnalyze, MaintainStepCheckpoint}
+}
+
+// DBMaintainOptions selects the maintenance steps to run.
+type DBMaintainOptions struct {
+	// Skip names steps to leave out (see MaintainSteps).
+	Skip []string
+	// QuickCheck runs PRAGMA quick_check instead of the slower integrity_check.
+	QuickCheck bool
+	// FullVacuum rebuilds the file with VACUUM and switches it to incremental
+	// auto-vacuum, so later passes can reclaim space without a rebuild.
+	FullVacuum bool
+	// MaxIntegrityErrors caps the problems integrity_check reports.
+	MaxIntegrityErrors int
+}
+
+// DBFileStats is the on-disk footprint of a database.
+type DBFileStats struct {
+	PageSize      int64 `json:"page_size"`
+	PageCount     int64 `json:"page_count"`
+	FreelistCount int64 `json:"freelist_count"`
+	DBBytes       int64 `json:"db_bytes"`
+	WALBytes      int64 `json:"wal_bytes"`
+}
+
+// DBMaintainStep reports one maintenance step. Skipped steps carry the reason
+// in Detail.
+type DBMaintainStep struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // ok, skipped, failed
+	DurationMS int64  `json:"duration_ms"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// DBMaintainReport is the result of a maintenance pass.
+type DBMaintainReport struct {
+	IntegrityOK     *bool            `json:"integrity_ok,omitempty"`
+	IntegrityErrors []string         `json:"integrity_errors,omitempty"`
+	AutoVacuum      string           `json:"auto_vacuum"`
+	JournalMode     string           `json:"journal_mode"`
+	Before          DBFileStats      `json:"before"`
+	After           DBFileStats      `json:"after"`
+	ReclaimedBytes  int64            `json:"reclaimed_bytes"`
+	Steps           []DBMaintainStep `json:"steps"`
+	EventID         int64            `json:"event_id,omitempty"`
+}
+
+// autoVacuumModes maps PRAGMA auto_vacuum values to names.
+var autoVacuumModes = map[int]string{0: "none", 1: "full", 2: "incremental"}
+
+// MaintainDB runs integrity check, vacuum, ANALYZE, and WAL checkpoint on the
+// database at dbPath (db must be its open connection), then records a
+// db_maintained event under requestID. A failing step is reported and the rest
+// still run; an integrity failure is reported, not returned as an error.
+//
+//nolint:revive // argument-limit: db, path, agent, request, options are all required
+func MaintainDB(ctx context.Context, db *sql.DB, dbPath, agentName, requestID string, opts DBMaintainOptions) (_ *DBMaintainReport, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	skip := map[string]bool{}
+	for _, s := range opts.Skip {
+		s = strings.TrimSpace(s)
+		if !isMaintainStep(s) {
+			return nil, fmt.Errorf("unknown maintenance step %q (valid: %s)", s, strings.Join(MaintainSteps(), ", "))
+		}
+		skip[s] = true
+	}
+	if opts.FullVacuum && skip[MaintainStepVacuum] {
+		return nil, errors.New("full vacuum conflicts with skipping the vacuum step")
+	}
+	if opts.MaxIntegrityErrors <= 0 {
+		opts.MaxIntegrityErrors = 20
+	}
+
+	report := &DBMaintainReport{Steps: []DBMaintainStep{}}
+	if report.Before, err = dbFileStats(ctx, db, dbPath); err != nil {
+		return nil, err
+	}
+	var autoVacuum int
+	if err := db.QueryRowContext(ctx, `PRAGMA auto_vacuum`).Scan(&autoVacuum); err != nil {
+		return nil, fmt.Errorf("failed to read auto_vacuum: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, `PRAGMA journal_mode`).Scan(&report.JournalMode); err != nil {
+		return nil, fmt.Errorf("failed to read journal_mode: %w", err)
+	}
+	report.JournalMode = strings.ToLower(report.JournalMode)
+
+	run := func(name string, fn func() (string, error)) {
+		step := DBMaintainStep{Name: name, Status: "ok"}
+		if skip[name] {
+			step.Status, step.Detail = "skipped", "skipped by request"
+			report.Steps = append(report.Steps, step)
+			return
+		}
+		start := time.Now()
+		detail, err := fn()
+		step.DurationMS = time.Since(start).Milliseconds()
+		step.Detail = detail
+		var skipped maintainStepSkipped
+		switch {
+		case errors.As(err, &skipped):
+			step.Status, step.Detail = "skipped", string(skipped)
+		case err != nil:
+			step.Status, step.Detail = "failed", err.Error()
+		}
+		report.Steps = append(report.Steps, step)
+	}
+
+	run(MaintainStepIntegrity, func() (string, error) {
+		problems, err := integrityProblems(ctx, db, opts.QuickCheck, opts.MaxIntegrityErrors)
+		if err != nil {
+			return "", err
+		}
+		ok := len(problems) == 0
+		report.IntegrityOK = &ok
+		report.IntegrityErrors = problems
+		if !ok {
+			return fmt.Sprintf("%d problem(s) found", len(problems)), nil
+		}
+		return "ok", nil
+	})
+	run(MaintainStepVacuum, func() (string, error) {
+		if opts.FullVacuum {
+			if _, err := db.ExecContext(ctx, `PRAGMA auto_vacuum = INCREMENTAL`); err != nil {
+				return "", fmt.Errorf("failed to set auto_vacuum: %w", err)
+			}
+			if _, err := db.ExecContext(ctx, `VACUUM`); err != nil {
+				return "", fmt.Errorf("vacuum failed: %w", err)
+			}
+			autoVacuum = 2
+			return "rebuilt with VACUUM; auto_vacuum is now incremental", nil
+		}
+		if autoVacuum != 2 {
+			return "", maintainStepSkipped(fmt.Sprintf("auto_vacuum is %s; run once with --full to enable incremental vacuum", autoVacuumModes[autoVacuum]))
+		}
+		if _, err := db.ExecContext(ctx, `PRAGMA incremental_vacuum`); err != nil {
+			return "", fmt.Errorf("incremental vacuum failed: %w", err)
+		}
+		return fmt.Sprintf("released %d free page(s)", report.Before.FreelistCount), nil
+	})
+	run(MaintainStepAnalyze, func() (string, error) {
+		if _, err := db.ExecContext(ctx, `ANALYZE`); err != nil {
+			return "", fmt.Errorf("analyze failed: %w", err)
+		}
+		return "planner statistics refreshed", nil
+	})
+	run(MaintainStepCheckpoint, func() (string, error) {
+		if report.JournalMode != "wal" {
+			return "", maintainStepSkipped("journal_mode is " + report.JournalMode)
+		}
+		var busy, logFrames, checkpointed int
+		if err := db.QueryRowContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`).Scan(&busy, &logFrames, &checkpointed); err != nil {
+			return "", fmt.Errorf("wal checkpoint failed: %w", err)
+		}
+		if busy != 0 {
+			return fmt.Sprintf("partial: a reader held the WAL; %d of %d frame(s) checkpointed", checkpointed, logFrames), nil
+		}
+		return "wal truncated", nil
+	})
+
+	report.AutoVacuum = autoVacuumModes[autoVacuum]
+	if report.After, err = dbFileStats(ctx, db, dbPath); err != nil {
+		return nil, err
+	}
+	report.ReclaimedBytes = report.Before.DBBytes + report.Before.WALBytes - report.After.DBBytes - report.After.WALBytes
+
+	meta, err := json.Marshal(map[string]any{
+		"integrity_ok":    report.IntegrityOK,
+		"reclaimed_bytes": report.ReclaimedBytes,
+		"db_bytes":        report.After.DBBytes,
+		"steps":           report.Steps,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal maintenance metadata: %w", err)
+	}
+	report.EventID, err = AppendEventWithMetadataIdempotent(ctx, db, agentName, requestID, models.EventKindDBMaintained, "",
+		fmt.Sprintf("Database maintenance reclaimed %d bytes", report.ReclaimedBytes), string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append db_maintained event: %w", err)
+	}
+	return report, nil
+}
+
+// LastDBMaintenance returns when the most recent db_maintained event was
+// recorded, and false when maintenance has never run.
+func LastDBMaintenance(ctx context.Context, db *sql.DB) (time.Time, bool, error) {
+	var at time.Time
+	err := RetryWithBackoff(ctx, func() error {
+		return db.QueryRowContext(ctx,
+			`SELECT created_at FROM events WHERE kind = ? ORDER BY id DESC LIMIT 1`, models.EventKindDBMaintained).Scan(&at)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to read last maintenance: %w", err)
+	}
+	return at, true, nil
+}
+
+// maintainStepSkipped is returned by a step that does not apply; it carries the reason.
+type maintainStepSkipped string
+
+func (s maintainStepSkipped) Error() string { return string(s) }
+
+func isMaintainStep(name string) bool {
+	for _, s := range MaintainSteps() {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func integrityProblems(ctx context.Context, db *sql.DB, quick bool, limit int) ([]string, error) {
+	pragma := fmt.Sprintf("PRAGMA integrity_check(%d)", limit)
+	if quick {
+		pragma = fmt.Sprintf("PRAGMA quick_check(%d)", limit)
+	}
+	rows, err := db.QueryContext(ctx, pragma)
+	if err != nil {
+		return nil, fmt.Errorf("integrity check failed: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity result: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
+
+func dbFileStats(ctx context.Context, db *sql.DB, dbPath string) (DBFileStats, error) {
+	var s DBFileStats
+	if err := db.QueryRowContext(ctx,
+		`SELECT page_size, page_count, freelist_count FROM pragma_page_size(), pragma_page_count(), pragma_freelist_count()`,
+	).Scan(&s.PageSize, &s.PageCount, &s.FreelistCount); err != nil {
+		return s, fmt.Errorf("failed to read database size: %w", err)
+	}
+	s.DBBytes = s.PageSize * s.PageCount
+	if fi, err := os.Stat(dbPath + "-wal"); err == nil {
+		s.WALBytes = fi.Size()
+	}
+	return s, nil
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintainDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maint.db")
+	db, err := InitDBWithPath(path)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+
+	_, never, err := LastDBMaintenance(ctx, db)
+	require.NoError(t, err)
+	assert.False(t, never)
+
+	report, err := MaintainDB(ctx, db, path, "agent1", "maint-1", DBMaintainOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, report.IntegrityOK)
+	assert.True(t, *report.IntegrityOK)
+	assert.Equal(t, "none", report.AutoVacuum)
+	require.Len(t, report.Steps, 4)
+	assert.Equal(t, "skipped", report.Steps[1].Status, "incremental vacuum needs auto_vacuum=incremental")
+	assert.Equal(t, "ok", report.Steps[2].Status)
+	assert.Positive(t, report.EventID)
+
+	_, ran, err := LastDBMaintenance(ctx, db)
+	require.NoError(t, err)
+	assert.True(t, ran)
+
+	report, err = MaintainDB(ctx, db, path, "agent1", "maint-2", DBMaintainOptions{FullVacuum: true, Skip: []string{MaintainStepIntegrity}})
+	require.NoError(t, err)
+	assert.Nil(t, report.IntegrityOK)
+	assert.Equal(t, "skipped", report.Steps[0].Status)
+	assert.Equal(t, "ok", report.Steps[1].Status)
+	assert.Equal(t, "incremental", report.AutoVacuum)
+
+	report, err = MaintainDB(ctx, db, path, "agent1", "maint-3", DBMaintainOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", report.Steps[1].Status, "later passes vacuum incrementally")
+
+	_, err = MaintainDB(ctx, db, path, "agent1", "maint-4", DBMaintainOptions{Skip: []string{"defrag"}})
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"os"
 	"testing"
 )
@@ -58,7 +59,7 @@ func TestOpenDB(t *testing.T) {
 	tempDir := t.TempDir()
 	testDBPath := tempDir + "/test_open.db"
 
-	db, err := OpenDB(testDBPath)
+	db, err := OpenDB(context.Background(), testDBPath)
 	if err != nil {
 		t.Fatalf("OpenDB failed: %v", err)
 	}
@@ -89,13 +90,13 @@ func TestSchemaVersion_Fresh(t *testing.T) {
 	tempDir := t.TempDir()
 	testDBPath := tempDir + "/test_version.db"
 
-	db, err := OpenDB(testDBPath)
+	db, err := OpenDB(context.Background(), testDBPath)
 	if err != nil {
 		t.Fatalf("OpenDB failed: %v", err)
 	}
 	defer func() { _ = db.Close() }()
 
-	current, latest, err := SchemaVersion(db)
+	current, latest, err := SchemaVersion(context.Background(), db)
 	if err != nil {
 		t.Fatalf("SchemaVersion failed: %v", err)
 	}
@@ -117,7 +118,7 @@ func TestSchemaVersion_AfterMigrate(t *testing.T) {
 	}
 	defer func() { _ = db.Close() }()
 
-	current, latest, err := SchemaVersion(db)
+	current, latest, err := SchemaVersion(context.Background(), db)
 	if err != nil {
 		t.Fatalf("SchemaVersion failed: %v", err)
 	}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"fmt"
 	"strconv"
 )
 
@@ -27,6 +28,32 @@ func (e *VersionConflictError) SuggestedAction() string {
 }
 func (e *VersionConflictError) Is(target error) bool { return target == ErrVersionConflict }
 
+// StaleVersionError reports a compare-and-set (--if-version) whose expected
+// version no longer matches the record. Unlike VersionConflictError it is not
+// retried: the caller's view is stale and must be re-read.
+type StaleVersionError struct {
+	Entity   string
+	ID       string
+	Expected int
+	Current  int
+}
+
+func (e *StaleVersionError) Error() string {
+	return fmt.Sprintf("%s %s is at version %d, not %d", e.Entity, e.ID, e.Current, e.Expected)
+}
+func (e *StaleVersionError) ErrorCode() string { return "STALE_VERSION" }
+func (e *StaleVersionError) Context() map[string]string {
+	return map[string]string{
+		"entity":           e.Entity,
+		"id":               e.ID,
+		"expected_version": strconv.Itoa(e.Expected),
+		"current_version":  strconv.Itoa(e.Current),
+	}
+}
+func (e *StaleVersionError) SuggestedAction() string {
+	return fmt.Sprintf("re-read with 'vybe %s get --id %s' and retry with --if-version %d if the change still applies", e.Entity, e.ID, e.Current)
+}
+
 // IdempotencyInProgressError replaces ErrIdempotencyInProgress with structured context.
 type IdempotencyInProgressError struct {
 	AgentName string
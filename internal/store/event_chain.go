@@ -0,0 +1,294 @@
+package store
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// maxChainProblems bounds the problems a chain verification reports.
+const maxChainProblems = 50
+
+// EventChain is the state of the tamper-evident event chain.
+type EventChain struct {
+	HMAC           bool   `json:"hmac"`
+	StartedEventID int64  `json:"started_event_id"`
+	HeadEventID    int64  `json:"head_event_id"`
+	HeadHash       string `json:"head_hash"`
+	EnabledBy      string `json:"enabled_by"`
+}
+
+// EventChainKeyError reports a signed chain used without its HMAC key.
+type EventChainKeyError struct {
+	KeyEnv string
+}
+
+func (e *EventChainKeyError) Error() string {
+	return fmt.Sprintf("event chain is HMAC-signed but %s is not set", e.KeyEnv)
+}
+func (e *EventChainKeyError) ErrorCode() string { return "AUDIT_KEY_MISSING" }
+func (e *EventChainKeyError) Context() map[string]string {
+	return map[string]string{"key_env": e.KeyEnv}
+}
+func (e *EventChainKeyError) SuggestedAction() string {
+	return "export " + e.KeyEnv + " with the chain's HMAC key (or set audit.key_env to the variable that holds it)"
+}
+
+// getEventChainTx returns the chain state, or nil when no chain was started.
+func getEventChainTx(tx *sql.Tx) (*EventChain, error) {
+	var c EventChain
+	err := tx.QueryRowContext(context.Background(), `
+		SELECT hmac, started_event_id, head_event_id, head_hash, enabled_by FROM event_chain WHERE id = 1
+	`).Scan(&c.HMAC, &c.StartedEventID, &c.HeadEventID, &c.HeadHash, &c.EnabledBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event chain: %w", err)
+	}
+	return &c, nil
+}
+
+// eventChainEnabledTx reports whether a chain was started. Event deletion
+// checks it: a deleted event breaks the chain the same way tampering does.
+func eventChainEnabledTx(tx *sql.Tx) (bool, error) {
+	c, err := getEventChainTx(tx)
+	return c != nil, err
+}
+
+// newChainHash returns the hash the chain uses: HMAC-SHA256 for a signed chain,
+// plain SHA-256 otherwise.
+func newChainHash(c *EventChain) (hash.Hash, error) {
+	if !c.HMAC {
+		return sha256.New(), nil
+	}
+	key, keyEnv := app.AuditHMACKey()
+	if len(key) == 0 {
+		return nil, &EventChainKeyError{KeyEnv: keyEnv}
+	}
+	return hmac.New(sha256.New, key), nil
+}
+
+// chainedEvent holds the event fields the chain hash covers. project_id,
+// archived_at, and duplicate_of are left out: retiring a project, archiving,
+// and deduplication legitimately change them.
+type chainedEvent struct {
+	ID        int64
+	Kind      string
+	AgentName string
+	TaskID    string
+	Message   string
+	Metadata  string
+	CreatedAt string
+	PrevHash  sql.NullString
+	Hash      sql.NullString
+}
+
+const chainedEventColumns = `id, kind, agent_name, COALESCE(task_id, ''), message, COALESCE(metadata, ''),
+	CAST(created_at AS TEXT), prev_hash, hash`
+
+func scanChainedEvent(row interface{ Scan(dest ...any) error }) (*chainedEvent, error) {
+	var e chainedEvent
+	if err := row.Scan(&e.ID, &e.Kind, &e.AgentName, &e.TaskID, &e.Message, &e.Metadata,
+		&e.CreatedAt, &e.PrevHash, &e.Hash); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// chainHash hashes prevHash and e's covered fields. The fields are encoded as
+// a JSON array, so no two field lists share an encoding.
+func chainHash(h hash.Hash, prevHash string, e *chainedEvent) string {
+	h.Reset()
+	payload, _ := json.Marshal([]any{prevHash, e.ID, e.Kind, e.AgentName, e.TaskID, e.Message, e.Metadata, e.CreatedAt})
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// chainEventTx links the just-inserted event eventID to the chain head when a
+// chain was started. Writes are serialized by SQLite, so the head read here is
+// the newest chained event.
+func chainEventTx(tx *sql.Tx, eventID int64) error {
+	c, err := getEventChainTx(tx)
+	if err != nil || c == nil {
+		return err
+	}
+	h, err := newChainHash(c)
+	if err != nil {
+		return err
+	}
+	e, err := scanChainedEvent(tx.QueryRowContext(context.Background(),
+		`SELECT `+chainedEventColumns+` FROM events WHERE id = ?`, eventID))
+	if err != nil {
+		return fmt.Errorf("failed to read event for chain: %w", err)
+	}
+
+	sum := chainHash(h, c.HeadHash, e)
+	if _, err := tx.ExecContext(context.Background(),
+		`UPDATE events SET prev_hash = ?, hash = ? WHERE id = ?`, c.HeadHash, sum, eventID); err != nil {
+		return fmt.Errorf("failed to chain event: %w", err)
+	}
+	if _, err := tx.ExecContext(context.Background(),
+		`UPDATE event_chain SET head_event_id = ?, head_hash = ? WHERE id = 1`, eventID, sum); err != nil {
+		return fmt.Errorf("failed to advance event chain: %w", err)
+	}
+	return nil
+}
+
+// EnableEventChainIdempotent starts the event chain with an event_chain_started
+// event; every event after it is chained. A signed chain needs the HMAC key
+// (app.AuditHMACKey) now and for every later write. The chain cannot be
+// turned off. Idempotent per (agentName, requestID).
+func EnableEventChainIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, signed bool) (*EventChain, error) {
+	if signed {
+		if key, keyEnv := app.AuditHMACKey(); len(key) == 0 {
+			return nil, &EventChainKeyError{KeyEnv: keyEnv}
+		}
+	}
+	return RunIdempotent(ctx, db, agentName, requestID, "events.enable_chain", func(tx *sql.Tx) (*EventChain, error) {
+		existing, err := getEventChainTx(tx)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("event chain already started at event %d", existing.StartedEventID)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO event_chain (id, hmac, enabled_by) VALUES (1, ?, ?)`, signed, agentName); err != nil {
+			return nil, fmt.Errorf("failed to start event chain: %w", err)
+		}
+
+		meta, _ := json.Marshal(map[string]any{"hmac": signed})
+		eventID, err := InsertEventTx(tx, models.EventKindEventChainStarted, agentName, "",
+			"Tamper-evident event chain started", string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append event chain event: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE event_chain SET started_event_id = ? WHERE id = 1`, eventID); err != nil {
+			return nil, fmt.Errorf("failed to start event chain: %w", err)
+		}
+		return getEventChainTx(tx)
+	})
+}
+
+// Chain verification problems.
+const (
+	ChainProblemUnchained      = "unchained"       // event after the chain start has no hash
+	ChainProblemBrokenLink     = "broken_link"     // prev_hash is not the previous event's hash: an event was removed or rewritten
+	ChainProblemHashMismatch   = "hash_mismatch"   // the event's fields no longer hash to its stored hash
+	ChainProblemTruncated      = "truncated"       // the newest chained event is not the recorded head
+	ChainProblemGenesisMissing = "genesis_missing" // the event_chain_started event is gone
+)
+
+// ChainProblem is one integrity failure found by VerifyEventChain.
+type ChainProblem struct {
+	EventID int64  `json:"event_id"`
+	Problem string `json:"problem"`
+}
+
+// EventChainReport is the outcome of VerifyEventChain. Checked counts events
+// from the chain start through the newest event.
+type EventChainReport struct {
+	OK             bool           `json:"ok"`
+	HMAC           bool           `json:"hmac"`
+	StartedEventID int64          `json:"started_event_id"`
+	HeadEventID    int64          `json:"head_event_id"`
+	HeadHash       string         `json:"head_hash"`
+	Checked        int            `json:"checked"`
+	Problems       []ChainProblem `json:"problems"`
+	ProblemsCapped bool           `json:"problems_capped,omitempty"`
+}
+
+func (r *EventChainReport) add(eventID int64, problem string) {
+	if len(r.Problems) >= maxChainProblems {
+		r.ProblemsCapped = true
+		return
+	}
+	r.Problems = append(r.Problems, ChainProblem{EventID: eventID, Problem: problem})
+}
+
+// VerifyEventChain recomputes the chain from its start and reports every event
+// whose hash or link does not match, events written without a hash, and a log
+// whose newest chained event is not the recorded head (truncation). A signed
+// chain needs the HMAC key.
+func VerifyEventChain(ctx context.Context, db *sql.DB) (*EventChainReport, error) {
+	var report *EventChainReport
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
+		c, err := getEventChainTx(tx)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return errors.New("no event chain; start one with 'vybe events enable-chain'")
+		}
+		h, err := newChainHash(c)
+		if err != nil {
+			return err
+		}
+		report = &EventChainReport{
+			HMAC: c.HMAC, StartedEventID: c.StartedEventID,
+			HeadEventID: c.HeadEventID, HeadHash: c.HeadHash, Problems: []ChainProblem{},
+		}
+		return verifyEventChainTx(tx, c, h, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.OK = len(report.Problems) == 0
+	return report, nil
+}
+
+func verifyEventChainTx(tx *sql.Tx, c *EventChain, h hash.Hash, report *EventChainReport) error {
+	rows, err := tx.QueryContext(context.Background(),
+		`SELECT `+chainedEventColumns+` FROM events WHERE id >= ? ORDER BY id`, c.StartedEventID)
+	if err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	prevHash := ""
+	var lastID int64
+	lastHash := ""
+	for rows.Next() {
+		e, err := scanChainedEvent(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan event: %w", err)
+		}
+		report.Checked++
+		if report.Checked == 1 && e.ID != c.StartedEventID {
+			report.add(c.StartedEventID, ChainProblemGenesisMissing)
+		}
+		if !e.Hash.Valid {
+			report.add(e.ID, ChainProblemUnchained)
+			continue
+		}
+		if e.PrevHash.String != prevHash {
+			report.add(e.ID, ChainProblemBrokenLink)
+		}
+		if chainHash(h, e.PrevHash.String, e) != e.Hash.String {
+			report.add(e.ID, ChainProblemHashMismatch)
+		}
+		prevHash = e.Hash.String
+		lastID, lastHash = e.ID, e.Hash.String
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	if report.Checked == 0 {
+		report.add(c.StartedEventID, ChainProblemGenesisMissing)
+	}
+	if lastID != c.HeadEventID || lastHash != c.HeadHash {
+		report.add(c.HeadEventID, ChainProblemTruncated)
+	}
+	return nil
+}
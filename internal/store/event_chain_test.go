@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+func chainProblems(t *testing.T, r *EventChainReport) []string {
+	t.Helper()
+	out := make([]string, 0, len(r.Problems))
+	for _, p := range r.Problems {
+		out = append(out, p.Problem)
+	}
+	return out
+}
+
+func TestEventChain_VerifyDetectsTamperingAndTruncation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	before, err := AppendEventIdempotent(context.Background(), db, "agent1", "req_before", "progress", "", "before the chain")
+	require.NoError(t, err)
+
+	chain, err := EnableEventChainIdempotent(context.Background(), db, "agent1", "req_chain", false)
+	require.NoError(t, err)
+	assert.Greater(t, chain.StartedEventID, before)
+	assert.Equal(t, chain.StartedEventID, chain.HeadEventID)
+
+	var ids []int64
+	for _, req := range []string{"req_a", "req_b", "req_c"} {
+		id, err := AppendEventIdempotent(context.Background(), db, "agent1", req, "progress", "", "step "+req)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	report, err := VerifyEventChain(context.Background(), db)
+	require.NoError(t, err)
+	assert.True(t, report.OK, "problems: %v", report.Problems)
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, ids[2], report.HeadEventID)
+
+	// Archiving and retiring a project do not touch hashed fields.
+	_, err = db.Exec(`UPDATE events SET archived_at = CURRENT_TIMESTAMP, project_id = NULL WHERE id = ?`, ids[0])
+	require.NoError(t, err)
+	report, err = VerifyEventChain(context.Background(), db)
+	require.NoError(t, err)
+	assert.True(t, report.OK)
+
+	_, err = db.Exec(`UPDATE events SET message = 'rewritten' WHERE id = ?`, ids[0])
+	require.NoError(t, err)
+	report, err = VerifyEventChain(context.Background(), db)
+	require.NoError(t, err)
+	assert.False(t, report.OK)
+	assert.Equal(t, []string{ChainProblemHashMismatch}, chainProblems(t, report))
+
+	_, err = db.Exec(`DELETE FROM events WHERE id IN (?, ?)`, ids[0], ids[2])
+	require.NoError(t, err)
+	report, err = VerifyEventChain(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ChainProblemBrokenLink, ChainProblemTruncated}, chainProblems(t, report))
+}
+
+func TestEventChain_BlocksEventDeletion(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := EnableEventChainIdempotent(context.Background(), db, "agent1", "req_chain", false)
+	require.NoError(t, err)
+	_, err = EnableEventChainIdempotent(context.Background(), db, "agent1", "req_chain_again", false)
+	require.ErrorContains(t, err, "already started")
+
+	id, err := AppendEventIdempotent(context.Background(), db, "agent1", "req_old", "progress", "", "old")
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE events SET archived_at = datetime(CURRENT_TIMESTAMP, '-90 days') WHERE id = ?`, id)
+	require.NoError(t, err)
+
+	deleted, err := PruneArchivedEventsIdempotent(context.Background(), db, "agent1", "req_prune", "", 30, 100)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+	deleted, err = PruneEventsByRetentionIdempotent(context.Background(), db, "agent1", "req_prune_ret", "", 30, nil, 100)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	report, err := VerifyEventChain(context.Background(), db)
+	require.NoError(t, err)
+	assert.True(t, report.OK)
+}
+
+func TestEventChain_HMACNeedsKey(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	t.Setenv(app.DefaultAuditKeyEnv, "")
+	_, err := EnableEventChainIdempotent(context.Background(), db, "agent1", "req_chain", true)
+	var keyErr *EventChainKeyError
+	require.ErrorAs(t, err, &keyErr)
+
+	t.Setenv(app.DefaultAuditKeyEnv, "secret-1")
+	chain, err := EnableEventChainIdempotent(context.Background(), db, "agent1", "req_chain", true)
+	require.NoError(t, err)
+	assert.True(t, chain.HMAC)
+	_, err = AppendEventIdempotent(context.Background(), db, "agent1", "req_a", "progress", "", "signed")
+	require.NoError(t, err)
+
+	report, err := VerifyEventChain(context.Background(), db)
+	require.NoError(t, err)
+	assert.True(t, report.OK)
+
+	// A different key makes every hash mismatch; no key at all refuses writes.
+	t.Setenv(app.DefaultAuditKeyEnv, "secret-2")
+	report, err = VerifyEventChain(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ChainProblemHashMismatch, ChainProblemHashMismatch}, chainProblems(t, report))
+
+	t.Setenv(app.DefaultAuditKeyEnv, "")
+	_, err = AppendEventIdempotent(context.Background(), db, "agent1", "req_b", "progress", "", "unsigned")
+	require.ErrorAs(t, err, &keyErr)
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// EventKindInfo is one entry of the event kind registry: a built-in kind, or a
+// custom one registered with 'vybe events kinds add'.
+type EventKindInfo struct {
+	Kind        string     `json:"kind"`
+	Builtin     bool       `json:"builtin"`
+	Description string     `json:"description,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// eventKindPattern is the shape of a custom kind: snake_case, like the
+// built-in ones.
+var eventKindPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// UnknownEventKindError reports an event kind outside the registry while
+// strict kinds mode is on.
+type UnknownEventKindError struct {
+	Kind string
+}
+
+func (e *UnknownEventKindError) Error() string {
+	return fmt.Sprintf("unknown event kind %q", e.Kind)
+}
+func (e *UnknownEventKindError) ErrorCode() string { return "UNKNOWN_EVENT_KIND" }
+func (e *UnknownEventKindError) Context() map[string]string {
+	return map[string]string{"kind": e.Kind}
+}
+func (e *UnknownEventKindError) SuggestedAction() string {
+	return "use a kind from 'vybe events kinds', or register it with 'vybe events kinds add " + e.Kind + "'"
+}
+
+// CheckEventKindTx returns an *UnknownEventKindError when kind is neither
+// built in nor registered. It is a no-op unless strict is set or strict kinds
+// mode is enabled in config.
+func CheckEventKindTx(tx *sql.Tx, kind string, strict bool) error {
+	if !strict && !app.StrictKindsEnabled() {
+		return nil
+	}
+	if slices.Contains(models.BuiltinEventKinds(), kind) {
+		return nil
+	}
+	var n int
+	if err := tx.QueryRowContext(context.Background(),
+		`SELECT COUNT(*) FROM event_kinds WHERE kind = ?`, kind).Scan(&n); err != nil {
+		return fmt.Errorf("failed to look up event kind: %w", err)
+	}
+	if n == 0 {
+		return &UnknownEventKindError{Kind: kind}
+	}
+	return nil
+}
+
+func scanEventKind(row interface{ Scan(dest ...any) error }) (*EventKindInfo, error) {
+	var k EventKindInfo
+	var createdAt time.Time
+	if err := row.Scan(&k.Kind, &k.Description, &k.CreatedBy, &createdAt); err != nil {
+		return nil, err
+	}
+	k.CreatedAt = &createdAt
+	return &k, nil
+}
+
+// ListEventKinds returns the built-in kinds followed by the registered custom
+// kinds, each group sorted by name.
+func ListEventKinds(ctx context.Context, db *sql.DB) ([]EventKindInfo, error) {
+	builtin := models.BuiltinEventKinds()
+	slices.Sort(builtin)
+	out := make([]EventKindInfo, 0, len(builtin))
+	for _, k := range builtin {
+		out = append(out, EventKindInfo{Kind: k, Builtin: true})
+	}
+
+	var custom []EventKindInfo
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx,
+			`SELECT kind, description, created_by, created_at FROM event_kinds ORDER BY kind`)
+		if err != nil {
+			return fmt.Errorf("failed to query event kinds: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		custom = custom[:0]
+		for rows.Next() {
+			k, err := scanEventKind(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan event kind: %w", err)
+			}
+			custom = append(custom, *k)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(out, custom...), nil
+}
+
+// RegisterEventKindIdempotent adds a custom event kind, or updates the
+// description of one already registered. Built-in kinds cannot be registered.
+func RegisterEventKindIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, kind, description string) (*EventKindInfo, error) {
+	if !eventKindPattern.MatchString(kind) {
+		return nil, fmt.Errorf("invalid event kind %q (use snake_case: a-z, 0-9, _; at most 64 characters)", kind)
+	}
+	if slices.Contains(models.BuiltinEventKinds(), kind) {
+		return nil, fmt.Errorf("event kind %q is built in", kind)
+	}
+	return RunIdempotent(ctx, db, agentName, requestID, "events.kinds.add", func(tx *sql.Tx) (*EventKindInfo, error) {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO event_kinds (kind, description, created_by) VALUES (?, ?, ?)
+			ON CONFLICT(kind) DO UPDATE SET description = excluded.description
+		`, kind, description, agentName); err != nil {
+			return nil, fmt.Errorf("failed to register event kind: %w", err)
+		}
+		meta, _ := json.Marshal(map[string]any{"kind": kind, "description": description})
+		if _, err := InsertEventTx(tx, models.EventKindKindRegistered, agentName, "",
+			"Event kind registered: "+kind, string(meta)); err != nil {
+			return nil, fmt.Errorf("failed to append event kind event: %w", err)
+		}
+		return scanEventKind(tx.QueryRowContext(ctx,
+			`SELECT kind, description, created_by, created_at FROM event_kinds WHERE kind = ?`, kind))
+	})
+}
+
+// RemoveEventKindIdempotent unregisters a custom event kind. Events already
+// logged with it are kept.
+func RemoveEventKindIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, kind string) (*EventKindInfo, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "events.kinds.remove", func(tx *sql.Tx) (*EventKindInfo, error) {
+		k, err := scanEventKind(tx.QueryRowContext(ctx,
+			`SELECT kind, description, created_by, created_at FROM event_kinds WHERE kind = ?`, kind))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("event kind not registered: %s", kind)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load event kind: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM event_kinds WHERE kind = ?`, kind); err != nil {
+			return nil, fmt.Errorf("failed to remove event kind: %w", err)
+		}
+		meta, _ := json.Marshal(map[string]any{"kind": kind})
+		if _, err := InsertEventTx(tx, models.EventKindKindRemoved, agentName, "",
+			"Event kind removed: "+kind, string(meta)); err != nil {
+			return nil, fmt.Errorf("failed to append event kind event: %w", err)
+		}
+		return k, nil
+	})
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// TestBuiltinEventKinds_CoversEveryConstant keeps the registry in step with
+// the EventKind* constants: a new kind that is missing from
+// models.BuiltinEventKinds would be rejected in strict kinds mode.
+func TestBuiltinEventKinds_CoversEveryConstant(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "../models/event_kinds.go", nil, 0)
+	require.NoError(t, err)
+
+	builtin := models.BuiltinEventKinds()
+	seen := map[string]bool{}
+	for _, k := range builtin {
+		assert.False(t, seen[k], "duplicate builtin kind %s", k)
+		seen[k] = true
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, v := range spec.Values {
+			lit, ok := v.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				continue
+			}
+			kind, _ := strconv.Unquote(lit.Value)
+			assert.True(t, seen[kind], "%s (%s) missing from BuiltinEventKinds", spec.Names[i].Name, kind)
+		}
+		return true
+	})
+}
+
+func TestEventKinds_StrictMode(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	t.Setenv(app.StrictKindsEnv, "0")
+
+	check := func(kind string, strict bool) error {
+		return Transact(t.Context(), db, func(tx *sql.Tx) error {
+			return CheckEventKindTx(tx, kind, strict)
+		})
+	}
+
+	require.NoError(t, check("deploy_started", false), "lenient by default")
+	var unknown *UnknownEventKindError
+	require.ErrorAs(t, check("deploy_started", true), &unknown)
+	require.NoError(t, check(models.EventKindProgress, true))
+
+	k, err := RegisterEventKindIdempotent(context.Background(), db, "a", "k1", "deploy_started", "a deploy began")
+	require.NoError(t, err)
+	assert.Equal(t, "a deploy began", k.Description)
+	require.NoError(t, check("deploy_started", true))
+
+	t.Setenv(app.StrictKindsEnv, "1")
+	require.ErrorAs(t, check("deploy_finished", false), &unknown, "config turns strict mode on")
+
+	kinds, err := ListEventKinds(context.Background(), db)
+	require.NoError(t, err)
+	idx := slices.IndexFunc(kinds, func(k EventKindInfo) bool { return k.Kind == "deploy_started" })
+	require.GreaterOrEqual(t, idx, 0)
+	assert.False(t, kinds[idx].Builtin)
+
+	_, err = RegisterEventKindIdempotent(context.Background(), db, "a", "k2", models.EventKindProgress, "")
+	require.Error(t, err, "built-in kinds cannot be registered")
+	_, err = RegisterEventKindIdempotent(context.Background(), db, "a", "k3", "Bad Kind", "")
+	require.Error(t, err)
+
+	_, err = RemoveEventKindIdempotent(context.Background(), db, "a", "k4", "deploy_started")
+	require.NoError(t, err)
+	require.ErrorAs(t, check("deploy_started", true), &unknown)
+}
@@ -0,0 +1,285 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MetaFilter is a parsed event metadata filter, such as
+// `exit_code>0 AND tool=Bash`. ListEvents applies it as a WHERE clause over
+// json_extract(metadata, ...).
+//
+// Grammar (AND binds tighter than OR; keywords are case-insensitive):
+//
+//	expr   := term { OR term }
+//	term   := factor { AND factor }
+//	factor := '(' expr ')' | field op value
+//	op     := = | != | > | >= | < | <= | ~      (~ is "contains")
+//	value  := number | 'quoted' | "quoted" | bare word | true | false | null
+//
+// A field is a metadata key, or a dotted path into nested objects
+// (input.command). Missing keys, and events whose metadata is not JSON, read as
+// null: they fail every comparison except != and = null.
+type MetaFilter struct {
+	Expr  string
+	where string
+	args  []any
+}
+
+// metaFieldAliases lets filters use the short names people type for the
+// metadata keys hooks write.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var metaFieldAliases = map[string]string{
+	"tool":  "tool_name",
+	"event": "hook_event",
+}
+
+// metaFieldExpr extracts field from events.metadata. The path is embedded
+// (it is restricted to [A-Za-z0-9_.]) rather than bound, so the expression
+// matches the expression indexes in migrations 00036 and 00060 and the
+// planner can use them.
+func metaFieldExpr(field string) string {
+	return `(CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.` + field + `') END)`
+}
+
+// ParseMetaFilter parses expr into a MetaFilter. An empty expr yields nil.
+func ParseMetaFilter(expr string) (*MetaFilter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil //nolint:nilnil // no filter
+	}
+	toks, err := lexMetaFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &metaParser{toks: toks}
+	where, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != metaTokEOF {
+		return nil, fmt.Errorf("invalid --meta filter: unexpected %q at offset %d", t.text, t.pos)
+	}
+	return &MetaFilter{Expr: expr, where: where, args: p.args}, nil
+}
+
+type metaTokKind int
+
+const (
+	metaTokEOF metaTokKind = iota
+	metaTokWord
+	metaTokString
+	metaTokOp
+	metaTokLParen
+	metaTokRParen
+)
+
+type metaToken struct {
+	kind metaTokKind
+	text string
+	pos  int
+}
+
+func isMetaWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-' || r == '/' || r == ':'
+}
+
+func lexMetaFilter(s string) ([]metaToken, error) {
+	var toks []metaToken
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			toks = append(toks, metaToken{metaTokLParen, "(", i})
+			i++
+		case r == ')':
+			toks = append(toks, metaToken{metaTokRParen, ")", i})
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(rs) && rs[end] != r {
+				end++
+			}
+			if end >= len(rs) {
+				return nil, fmt.Errorf("invalid --meta filter: unterminated string at offset %d", i)
+			}
+			toks = append(toks, metaToken{metaTokString, string(rs[i+1 : end]), i})
+			i = end + 1
+		case strings.ContainsRune("=!<>~", r):
+			op := string(r)
+			if i+1 < len(rs) && rs[i+1] == '=' && r != '=' && r != '~' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("invalid --meta filter: '!' must be followed by '=' at offset %d", i)
+			}
+			toks = append(toks, metaToken{metaTokOp, op, i})
+			i += len(op)
+		case isMetaWordRune(r):
+			end := i
+			for end < len(rs) && isMetaWordRune(rs[end]) {
+				end++
+			}
+			toks = append(toks, metaToken{metaTokWord, string(rs[i:end]), i})
+			i = end
+		default:
+			return nil, fmt.Errorf("invalid --meta filter: unexpected %q at offset %d", r, i)
+		}
+	}
+	return append(toks, metaToken{metaTokEOF, "end of filter", len(rs)}), nil
+}
+
+type metaParser struct {
+	toks []metaToken
+	pos  int
+	args []any
+}
+
+func (p *metaParser) peek() metaToken { return p.toks[p.pos] }
+
+func (p *metaParser) next() metaToken {
+	t := p.toks[p.pos]
+	if t.kind != metaTokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *metaParser) keyword(kw string) bool {
+	t := p.peek()
+	if t.kind == metaTokWord && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *metaParser) expr() (string, error) {
+	return p.joined("OR", p.term)
+}
+
+func (p *metaParser) term() (string, error) {
+	return p.joined("AND", p.factor)
+}
+
+func (p *metaParser) joined(kw string, operand func() (string, error)) (string, error) {
+	first, err := operand()
+	if err != nil {
+		return "", err
+	}
+	parts := []string{first}
+	for p.keyword(kw) {
+		next, err := operand()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, next)
+	}
+	if len(parts) == 1 {
+		return first, nil
+	}
+	return "(" + strings.Join(parts, " "+kw+" ") + ")", nil
+}
+
+func (p *metaParser) factor() (string, error) {
+	t := p.next()
+	switch t.kind {
+	case metaTokLParen:
+		inner, err := p.expr()
+		if err != nil {
+			return "", err
+		}
+		if closing := p.next(); closing.kind != metaTokRParen {
+			return "", fmt.Errorf("invalid --meta filter: expected ')' at offset %d, got %q", closing.pos, closing.text)
+		}
+		return inner, nil
+	case metaTokWord:
+		return p.comparison(t)
+	default:
+		return "", fmt.Errorf("invalid --meta filter: expected a field at offset %d, got %q", t.pos, t.text)
+	}
+}
+
+func (p *metaParser) comparison(field metaToken) (string, error) {
+	name, err := metaFieldPath(field)
+	if err != nil {
+		return "", err
+	}
+	op := p.next()
+	if op.kind != metaTokOp {
+		return "", fmt.Errorf("invalid --meta filter: expected an operator after %s at offset %d, got %q", field.text, op.pos, op.text)
+	}
+	val := p.next()
+	if val.kind != metaTokWord && val.kind != metaTokString {
+		return "", fmt.Errorf("invalid --meta filter: expected a value after %s%s at offset %d, got %q", field.text, op.text, val.pos, val.text)
+	}
+
+	lhs := metaFieldExpr(name)
+	if val.kind == metaTokWord && strings.EqualFold(val.text, "null") {
+		switch op.text {
+		case "=":
+			return lhs + " IS NULL", nil
+		case "!=":
+			return lhs + " IS NOT NULL", nil
+		default:
+			return "", fmt.Errorf("invalid --meta filter: null only supports = and != (offset %d)", op.pos)
+		}
+	}
+	if op.text == "~" {
+		p.args = append(p.args, val.text)
+		return "instr(" + lhs + ", ?) > 0", nil
+	}
+
+	p.args = append(p.args, metaValue(val))
+	if op.text == "!=" {
+		// Missing keys are "not equal" too, as a reader of the filter expects.
+		return "(" + lhs + " IS NULL OR " + lhs + " != ?)", nil
+	}
+	return lhs + " " + op.text + " ?", nil
+}
+
+// metaFieldPath validates a field token and resolves aliases.
+func metaFieldPath(t metaToken) (string, error) {
+	name := t.text
+	if alias, ok := metaFieldAliases[name]; ok {
+		name = alias
+	}
+	for _, seg := range strings.Split(name, ".") {
+		if seg == "" {
+			return "", fmt.Errorf("invalid --meta filter: bad field %q at offset %d", t.text, t.pos)
+		}
+		for _, r := range seg {
+			if r != '_' && (r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r))) {
+				return "", fmt.Errorf("invalid --meta filter: bad field %q at offset %d (use letters, digits, _ and .)", t.text, t.pos)
+			}
+		}
+	}
+	return name, nil
+}
+
+// metaValue converts a value token to the SQLite value json_extract yields
+// for it: numbers stay numeric and JSON booleans are 1 and 0. Quoted values
+// are always text.
+func metaValue(t metaToken) any {
+	if t.kind == metaTokString {
+		return t.text
+	}
+	switch strings.ToLower(t.text) {
+	case "true":
+		return int64(1)
+	case "false":
+		return int64(0)
+	}
+	if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+		return f
+	}
+	return t.text
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEvents_MetaFilter(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	bashFail := appendEventWithMetadata(t, db, "tool_failure", "a", "", "bash failed", `{"tool_name":"Bash","exit_code":2,"input":{"command":"go test ./..."}}`)
+	bashOK := appendEventWithMetadata(t, db, "tool_success", "a", "", "bash ok", `{"tool_name":"Bash","exit_code":0}`)
+	edit := appendEventWithMetadata(t, db, "tool_success", "a", "", "edit", `{"tool_name":"Edit","file_path":"internal/store/x.go","ok":true}`)
+	plain := appendEvent(t, db, "progress", "a", "", "no metadata")
+
+	tests := []struct {
+		expr string
+		want []int64
+	}{
+		{`exit_code>0 AND tool=Bash`, []int64{bashFail}},
+		{`tool_name = "Bash"`, []int64{bashFail, bashOK}},
+		{`tool=Edit OR exit_code>=2`, []int64{bashFail, edit}},
+		{`(tool=Edit OR tool=Bash) and exit_code<1`, []int64{bashOK}},
+		{`tool!=Bash`, []int64{edit, plain}},
+		{`exit_code=null`, []int64{edit, plain}},
+		{`file_path~'internal/store'`, []int64{edit}},
+		{`input.command~test`, []int64{bashFail}},
+		{`ok=true`, []int64{edit}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			meta, err := ParseMetaFilter(tt.expr)
+			require.NoError(t, err)
+			events, err := ListEvents(context.Background(), db, ListEventsParams{Meta: meta})
+			require.NoError(t, err)
+			got := make([]int64, 0, len(events))
+			for _, e := range events {
+				got = append(got, e.ID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseMetaFilter_Errors(t *testing.T) {
+	for _, expr := range []string{
+		`exit_code>`,
+		`tool=Bash AND`,
+		`(tool=Bash`,
+		`tool Bash`,
+		`tool='Bash`,
+		`a..b=1`,
+		`x'); DROP TABLE events; --=1`,
+		`exit_code>null`,
+		`tool=Bash)`,
+	} {
+		_, err := ParseMetaFilter(expr)
+		assert.Error(t, err, expr)
+	}
+
+	meta, err := ParseMetaFilter("  ")
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+}
+
+func TestParseMetaFilter_UsesMetadataIndex(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	meta, err := ParseMetaFilter(`tool=Bash`)
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(context.Background(), `EXPLAIN QUERY PLAN SELECT id FROM events WHERE `+meta.where, meta.args...)
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	var plan strings.Builder
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		require.NoError(t, rows.Scan(&id, &parent, &notUsed, &detail))
+		plan.WriteString(detail + "\n")
+	}
+	require.NoError(t, rows.Err())
+	assert.Contains(t, plan.String(), "idx_events_meta_tool_name")
+}
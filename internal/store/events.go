@@ -97,6 +97,9 @@ func insertEventRowTx(tx *sql.Tx, kind, agentName, taskID, message, metadata str
 	if err != nil {
 		return 0, fmt.Errorf("failed to get last insert id: %w", err)
 	}
+	if err := chainEventTx(tx, eventID); err != nil {
+		return 0, err
+	}
 
 	return eventID, nil
 }
@@ -177,10 +180,17 @@ func InsertEventWithProjectTx(tx *sql.Tx, kind, agentName, projectID, taskID, me
 		return 0, fmt.Errorf("failed to insert event: %w", err)
 	}
 
-	return result.LastInsertId()
+	eventID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	if err := chainEventTx(tx, eventID); err != nil {
+		return 0, err
+	}
+	return eventID, nil
 }
 
-func appendEventIdempotentResult(
+func appendEventIdempotentResult(ctx context.Context,
 	db *sql.DB,
 	agentName, requestID, command, kind, message, metadata string,
 	insert func(tx *sql.Tx) (int64, error),
@@ -189,7 +199,7 @@ func appendEventIdempotentResult(
 		return 0, err
 	}
 
-	r, err := RunIdempotent(context.Background(), db, agentName, requestID, command, func(tx *sql.Tx) (eventIDResult, error) {
+	r, err := RunIdempotent(ctx, db, agentName, requestID, command, func(tx *sql.Tx) (eventIDResult, error) {
 		eventID, err := insert(tx)
 		if err != nil {
 			return eventIDResult{}, err
@@ -206,8 +216,8 @@ func appendEventIdempotentResult(
 // idempotent on (agent_name, request_id).
 //
 //nolint:revive // argument-limit: all 8 event params (agent, req, kind, project, task, msg, metadata) required for idempotent path
-func AppendEventWithProjectAndMetadataIdempotent(db *sql.DB, agentName, requestID, kind, projectID, taskID, message, metadata string) (int64, error) {
-	return appendEventIdempotentResult(db, agentName, requestID, "events.append_with_project", kind, message, metadata, func(tx *sql.Tx) (int64, error) {
+func AppendEventWithProjectAndMetadataIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, kind, projectID, taskID, message, metadata string) (int64, error) {
+	return appendEventIdempotentResult(ctx, db, agentName, requestID, "events.append_with_project", kind, message, metadata, func(tx *sql.Tx) (int64, error) {
 		return InsertEventWithProjectTx(tx, kind, agentName, projectID, taskID, message, metadata)
 	})
 }
@@ -216,8 +226,8 @@ func AppendEventWithProjectAndMetadataIdempotent(db *sql.DB, agentName, requestI
 // On retries with the same request id, it returns the previously-created event id.
 //
 //nolint:revive // argument-limit: event params (agent, req, kind, task, msg) are all required
-func AppendEventIdempotent(db *sql.DB, agentName, requestID, kind, taskID, message string) (int64, error) {
-	return appendEventIdempotentResult(db, agentName, requestID, "events.append", kind, message, "", func(tx *sql.Tx) (int64, error) {
+func AppendEventIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, kind, taskID, message string) (int64, error) {
+	return appendEventIdempotentResult(ctx, db, agentName, requestID, "events.append", kind, message, "", func(tx *sql.Tx) (int64, error) {
 		return insertEventRowTx(tx, kind, agentName, taskID, message, "")
 	})
 }
@@ -225,8 +235,8 @@ func AppendEventIdempotent(db *sql.DB, agentName, requestID, kind, taskID, messa
 // AppendEventWithMetadataIdempotent is the metadata variant of AppendEventIdempotent.
 //
 //nolint:revive // argument-limit: event params (agent, req, kind, task, msg, metadata) are all required
-func AppendEventWithMetadataIdempotent(db *sql.DB, agentName, requestID, kind, taskID, message, metadata string) (int64, error) {
-	return appendEventIdempotentResult(db, agentName, requestID, "events.append_with_metadata", kind, message, metadata, func(tx *sql.Tx) (int64, error) {
+func AppendEventWithMetadataIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, kind, taskID, message, metadata string) (int64, error) {
+	return appendEventIdempotentResult(ctx, db, agentName, requestID, "events.append_with_metadata", kind, message, metadata, func(tx *sql.Tx) (int64, error) {
 		return insertEventRowTx(tx, kind, agentName, taskID, message, metadata)
 	})
 }
@@ -236,7 +246,7 @@ func AppendEventWithMetadataIdempotent(db *sql.DB, agentName, requestID, kind, t
 // When projectID is non-empty, only events matching that project are archived (global events excluded).
 //
 //nolint:revive // argument-limit: all 8 params (agent, req, project, task, from, to, summary) required together
-func ArchiveEventsRangeWithSummaryIdempotent(db *sql.DB, agentName, requestID, projectID, taskID string, fromID, toID int64, summary string) (summaryEventID int64, archivedCount int64, err error) {
+func ArchiveEventsRangeWithSummaryIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID, taskID string, fromID, toID int64, summary string) (summaryEventID int64, archivedCount int64, err error) {
 	if agentName == "" {
 		return 0, 0, errors.New("agent name is required")
 	}
@@ -259,7 +269,7 @@ func ArchiveEventsRangeWithSummaryIdempotent(db *sql.DB, agentName, requestID, p
 		ArchivedCount  int64 `json:"archived_count"`
 	}
 
-	r, err := RunIdempotent(context.Background(), db, agentName, requestID, "events.summarize", func(tx *sql.Tx) (idemResult, error) {
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "events.summarize", func(tx *sql.Tx) (idemResult, error) {
 		updateSQL := `
 			UPDATE events
 			SET archived_at = CURRENT_TIMESTAMP
@@ -275,7 +285,7 @@ func ArchiveEventsRangeWithSummaryIdempotent(db *sql.DB, agentName, requestID, p
 			args = append(args, taskID)
 		}
 
-		res, txErr := tx.ExecContext(context.Background(), updateSQL, args...)
+		res, txErr := tx.ExecContext(ctx, updateSQL, args...)
 		if txErr != nil {
 			return idemResult{}, fmt.Errorf("failed to archive events: %w", txErr)
 		}
@@ -309,16 +319,16 @@ func ArchiveEventsRangeWithSummaryIdempotent(db *sql.DB, agentName, requestID, p
 
 // CountActiveEvents returns the number of non-archived events,
 // optionally filtered by project.
-func CountActiveEvents(db *sql.DB, projectID string) (int64, error) {
+func CountActiveEvents(ctx context.Context, db *sql.DB, projectID string) (int64, error) {
 	var count int64
-	err := RetryWithBackoff(context.Background(), func() error {
+	err := RetryWithBackoff(ctx, func() error {
 		query := `SELECT COUNT(*) FROM events WHERE archived_at IS NULL`
 		args := []any{}
 		if projectID != "" {
 			query += ` AND ` + ProjectScopeClause
 			args = append(args, projectID)
 		}
-		return db.QueryRowContext(context.Background(), query, args...).Scan(&count)
+		return db.QueryRowContext(ctx, query, args...).Scan(&count)
 	})
 	if err != nil {
 		return 0, fmt.Errorf("count active events: %w", err)
@@ -329,11 +339,11 @@ func CountActiveEvents(db *sql.DB, projectID string) (int64, error) {
 // FindArchiveWindow computes the ID range of active events to archive,
 // keeping the most recent keepRecent events active.
 // Returns (0, 0, nil) when there are not enough events to archive.
-func FindArchiveWindow(db *sql.DB, projectID string, keepRecent int) (fromID, toID int64, err error) {
+func FindArchiveWindow(ctx context.Context, db *sql.DB, projectID string, keepRecent int) (fromID, toID int64, err error) {
 	if keepRecent < 0 {
 		keepRecent = 0
 	}
-	err = RetryWithBackoff(context.Background(), func() error {
+	err = RetryWithBackoff(ctx, func() error {
 		query := `
 			SELECT MIN(id), MAX(id) FROM (
 				SELECT id FROM events
@@ -361,7 +371,7 @@ func FindArchiveWindow(db *sql.DB, projectID string, keepRecent int) (fromID, to
 			)
 		`
 		var minID, maxID sql.NullInt64
-		if scanErr := db.QueryRowContext(context.Background(), query, args...).Scan(&minID, &maxID); scanErr != nil {
+		if scanErr := db.QueryRowContext(ctx, query, args...).Scan(&minID, &maxID); scanErr != nil {
 			return scanErr
 		}
 		if !minID.Valid || !maxID.Valid {
@@ -379,9 +389,10 @@ func FindArchiveWindow(db *sql.DB, projectID string, keepRecent int) (fromID, to
 }
 
 // PruneArchivedEventsIdempotent permanently deletes archived events older than
-// olderThanDays. Deletion is bounded by limit per call.
+// olderThanDays. Deletion is bounded by limit per call. Nothing is deleted once
+// an event chain is started (see EnableEventChainIdempotent).
 // Returns the number of rows deleted in this call.
-func PruneArchivedEventsIdempotent(db *sql.DB, agentName, requestID, projectID string, olderThanDays, limit int) (int64, error) {
+func PruneArchivedEventsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, olderThanDays, limit int) (int64, error) {
 	if agentName == "" {
 		return 0, errors.New("agent name is required")
 	}
@@ -399,7 +410,10 @@ func PruneArchivedEventsIdempotent(db *sql.DB, agentName, requestID, projectID s
 		Deleted int64 `json:"deleted"`
 	}
 
-	r, err := RunIdempotent(context.Background(), db, agentName, requestID, "events.prune_archived", func(tx *sql.Tx) (idemResult, error) {
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "events.prune_archived", func(tx *sql.Tx) (idemResult, error) {
+		if chained, err := eventChainEnabledTx(tx); err != nil || chained {
+			return idemResult{}, err
+		}
 		query := `
 			DELETE FROM events
 			WHERE id IN (
@@ -420,7 +434,7 @@ func PruneArchivedEventsIdempotent(db *sql.DB, agentName, requestID, projectID s
 		`
 		args = append(args, limit)
 
-		res, execErr := tx.ExecContext(context.Background(), query, args...)
+		res, execErr := tx.ExecContext(ctx, query, args...)
 		if execErr != nil {
 			return idemResult{}, fmt.Errorf("prune archived events: %w", execErr)
 		}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// dedupeMessagePreview caps the message carried in a duplicate group.
+const dedupeMessagePreview = 120
+
+// DedupeParams selects the events scanned for duplicates.
+type DedupeParams struct {
+	// Window is how long after the kept event an identical event still counts
+	// as a duplicate. Measured from the first occurrence, not the previous one,
+	// so a steady heartbeat is not collapsed into a single event.
+	Window    time.Duration
+	ProjectID string
+	SinceID   int64
+	// Limit caps the events scanned (oldest first after SinceID).
+	Limit int
+}
+
+// DuplicateGroup is one kept event and the identical events collapsed into it.
+type DuplicateGroup struct {
+	KeepID       int64   `json:"keep_id"`
+	DuplicateIDs []int64 `json:"duplicate_ids"`
+	Kind         string  `json:"kind"`
+	AgentName    string  `json:"agent_name"`
+	TaskID       string  `json:"task_id,omitempty"`
+	Message      string  `json:"message"`
+}
+
+// DedupeStats summarizes a dedupe pass.
+type DedupeStats struct {
+	Scanned    int              `json:"scanned"`
+	Duplicates int              `json:"duplicates"`
+	ByKind     map[string]int   `json:"by_kind"`
+	Groups     []DuplicateGroup `json:"groups"`
+	EventID    int64            `json:"event_id,omitempty"`
+}
+
+// FindDuplicateEvents reports active events identical in (kind, agent, task,
+// message, metadata hash) to an earlier event within the window.
+func FindDuplicateEvents(ctx context.Context, db *sql.DB, p DedupeParams) (*DedupeStats, error) {
+	var stats *DedupeStats
+	err := RetryWithBackoff(ctx, func() error {
+		var err error
+		stats, err = findDuplicateEvents(ctx, db, p)
+		return err
+	})
+	return stats, err
+}
+
+// DedupeEventsIdempotent archives the duplicates FindDuplicateEvents reports,
+// records duplicate_of on each, and appends one events_deduped event with the
+// stats. Kept events are untouched.
+func DedupeEventsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, p DedupeParams) (*DedupeStats, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	return RunIdempotent(ctx, db, agentName, requestID, "events.dedupe", func(tx *sql.Tx) (*DedupeStats, error) {
+		stats, err := findDuplicateEvents(ctx, tx, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range stats.Groups {
+			for _, id := range g.DuplicateIDs {
+				if _, err := tx.ExecContext(ctx, `
+					UPDATE events SET archived_at = CURRENT_TIMESTAMP, duplicate_of = ?
+					WHERE id = ? AND archived_at IS NULL
+				`, g.KeepID, id); err != nil {
+					return nil, fmt.Errorf("failed to mark duplicate event: %w", err)
+				}
+			}
+		}
+
+		meta, err := json.Marshal(map[string]any{
+			"window_seconds": p.Window.Seconds(),
+			"scanned":        stats.Scanned,
+			"duplicates":     stats.Duplicates,
+			"groups":         len(stats.Groups),
+			"by_kind":        stats.ByKind,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal dedupe metadata: %w", err)
+		}
+		stats.EventID, err = InsertEventWithProjectTx(tx, models.EventKindEventsDeduped, agentName, p.ProjectID, "",
+			fmt.Sprintf("Collapsed %d duplicate events", stats.Duplicates), string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append dedupe event: %w", err)
+		}
+		return stats, nil
+	})
+}
+
+type dedupeQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func findDuplicateEvents(ctx context.Context, q dedupeQuerier, p DedupeParams) (*DedupeStats, error) {
+	if p.Window <= 0 {
+		return nil, errors.New("dedupe window must be > 0")
+	}
+	if p.Limit <= 0 {
+		p.Limit = 10000
+	}
+
+	query := `
+		SELECT id, kind, agent_name, COALESCE(task_id, ''), message, COALESCE(metadata, ''), created_at
+		FROM events
+		WHERE archived_at IS NULL AND id > ?`
+	args := []any{p.SinceID}
+	if p.ProjectID != "" {
+		query += ` AND ` + ProjectScopeClause
+		args = append(args, p.ProjectID)
+	}
+	query += ` ORDER BY id ASC LIMIT ?`
+	args = append(args, p.Limit)
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan events for duplicates: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	type kept struct {
+		group int
+		at    time.Time
+	}
+	stats := &DedupeStats{ByKind: map[string]int{}, Groups: []DuplicateGroup{}}
+	open := map[string]kept{}
+	var groups []DuplicateGroup
+	for rows.Next() {
+		var (
+			id                                int64
+			kind, agent, taskID, msg, metaRaw string
+			at                                time.Time
+		)
+		if err := rows.Scan(&id, &kind, &agent, &taskID, &msg, &metaRaw, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		stats.Scanned++
+
+		key := dedupeKey(kind, agent, taskID, msg, metaRaw)
+		if k, ok := open[key]; ok && at.Sub(k.at) <= p.Window {
+			groups[k.group].DuplicateIDs = append(groups[k.group].DuplicateIDs, id)
+			stats.Duplicates++
+			stats.ByKind[kind]++
+			continue
+		}
+		groups = append(groups, DuplicateGroup{
+			KeepID: id, Kind: kind, AgentName: agent, TaskID: taskID,
+			Message: truncateRunes(msg, dedupeMessagePreview),
+		})
+		open[key] = kept{group: len(groups) - 1, at: at}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan events for duplicates: %w", err)
+	}
+
+	for _, g := range groups {
+		if len(g.DuplicateIDs) > 0 {
+			stats.Groups = append(stats.Groups, g)
+		}
+	}
+	return stats, nil
+}
+
+// dedupeKey identifies identical events; metadata is compared by hash.
+func dedupeKey(kind, agent, taskID, msg, metadata string) string {
+	sum := sha256.Sum256([]byte(metadata))
+	return strings.Join([]string{kind, agent, taskID, msg, hex.EncodeToString(sum[:])}, "\x00")
+}
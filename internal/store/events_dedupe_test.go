@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupeEvents_CollapsesRetriesWithinWindow(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insert := func(msg, meta string) int64 {
+		t.Helper()
+		var id int64
+		require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
+			var err error
+			id, err = InsertEventTx(tx, "tool_failure", "agent1", "", msg, meta)
+			return err
+		}))
+		return id
+	}
+
+	first := insert("Bash failed", `{"tool_name":"Bash"}`)
+	dup := insert("Bash failed", `{"tool_name":"Bash"}`)
+	otherMeta := insert("Bash failed", `{"tool_name":"Edit"}`)
+	late := insert("Bash failed", `{"tool_name":"Bash"}`)
+	_, err := db.Exec(`UPDATE events SET created_at = datetime(created_at, '+1 minute') WHERE id = ?`, late)
+	require.NoError(t, err)
+
+	preview, err := FindDuplicateEvents(context.Background(), db, DedupeParams{Window: 5 * time.Second})
+	require.NoError(t, err)
+	require.Len(t, preview.Groups, 1)
+	assert.Equal(t, first, preview.Groups[0].KeepID)
+	assert.Equal(t, []int64{dup}, preview.Groups[0].DuplicateIDs)
+	assert.Equal(t, 1, preview.ByKind["tool_failure"])
+
+	stats, err := DedupeEventsIdempotent(context.Background(), db, "agent1", "dedupe-1", DedupeParams{Window: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Duplicates)
+	assert.NotZero(t, stats.EventID)
+
+	var archived sql.NullString
+	var duplicateOf sql.NullInt64
+	require.NoError(t, db.QueryRow(`SELECT archived_at, duplicate_of FROM events WHERE id = ?`, dup).Scan(&archived, &duplicateOf))
+	assert.True(t, archived.Valid)
+	assert.Equal(t, first, duplicateOf.Int64)
+
+	for _, id := range []int64{first, otherMeta, late} {
+		require.NoError(t, db.QueryRow(`SELECT archived_at FROM events WHERE id = ?`, id).Scan(&archived))
+		assert.False(t, archived.Valid, "event %d must stay active", id)
+	}
+
+	again, err := FindDuplicateEvents(context.Background(), db, DedupeParams{Window: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Zero(t, again.Duplicates)
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+const (
+	defaultFollowMinInterval = 50 * time.Millisecond
+	defaultFollowMaxInterval = 2 * time.Second
+	followPageSize           = 500
+)
+
+// FollowOptions tunes the change-detection backoff used by FollowEvents.
+type FollowOptions struct {
+	// MinInterval is the first poll delay after a change; zero uses 50ms.
+	MinInterval time.Duration
+	// MaxInterval caps the doubling backoff while the database is idle; zero uses 2s.
+	MaxInterval time.Duration
+}
+
+// LatestEventID returns the highest event id, or 0 when the table is empty.
+func LatestEventID(ctx context.Context, db *sql.DB) (int64, error) {
+	var id int64
+	err := RetryWithBackoff(ctx, func() error {
+		return db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM events`).Scan(&id)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query latest event id: %w", err)
+	}
+	return id, nil
+}
+
+// FollowEvents emits every event matching p with id > p.SinceID in ascending order,
+// then blocks until ctx is cancelled, emitting new events as other processes commit them.
+//
+// Change detection polls PRAGMA data_version, which only moves when another
+// connection commits, so idle waits cost one pragma per tick instead of an
+// events query. Poll delay doubles from MinInterval to MaxInterval
+// with jitter while idle and resets after each change. Returns ctx.Err() on
+// cancellation, or the first error from emit.
+func FollowEvents(ctx context.Context, db *sql.DB, p ListEventsParams, opts FollowOptions, emit func(*models.Event) error) error {
+	minInterval := opts.MinInterval
+	if minInterval <= 0 {
+		minInterval = defaultFollowMinInterval
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultFollowMaxInterval
+	}
+	maxInterval = max(maxInterval, minInterval)
+
+	// The store pool holds a single connection, so data_version is read on the
+	// same connection every time and only moves when another process commits.
+	readVersion := func() (int64, error) {
+		var v int64
+		err := db.QueryRowContext(ctx, `PRAGMA data_version`).Scan(&v)
+		return v, err
+	}
+
+	p.Desc = false
+	p.Limit = followPageSize
+	drain := func() error {
+		for {
+			events, err := ListEvents(ctx, db, p)
+			if err != nil {
+				return err
+			}
+			for _, e := range events {
+				if err := emit(e); err != nil {
+					return err
+				}
+				p.SinceID = e.ID
+			}
+			if len(events) < followPageSize {
+				return nil
+			}
+		}
+	}
+
+	version, err := readVersion()
+	if err != nil {
+		return fmt.Errorf("failed to read data_version: %w", err)
+	}
+	if err := drain(); err != nil {
+		return err
+	}
+
+	delay := minInterval
+	timer := time.NewTimer(jitter(delay))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		v, err := readVersion()
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to read data_version: %w", err)
+		}
+		switch {
+		case v != version:
+			version = v
+			if err := drain(); err != nil {
+				return err
+			}
+			delay = minInterval
+		case delay >= maxInterval:
+			// Safety net for writes on this process's own connection, which
+			// data_version does not report.
+			if err := drain(); err != nil {
+				return err
+			}
+		default:
+			delay = min(delay*2, maxInterval)
+		}
+		timer.Reset(jitter(delay))
+	}
+}
+
+// jitter spreads d by ±20% so many followers don't poll in lockstep.
+func jitter(d time.Duration) time.Duration {
+	spread := int64(d) / 5
+	if spread <= 0 {
+		return d
+	}
+	//nolint:gosec // G404: poll jitter does not need a cryptographic source
+	return d - time.Duration(spread) + time.Duration(rand.Int64N(2*spread+1))
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestFollowEvents_ReplaysThenStreamsNewEvents(t *testing.T) {
+	path := t.TempDir() + "/follow.db"
+	db, err := InitDBWithPath(path)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	// A second handle stands in for another vybe process writing concurrently.
+	writer, err := InitDBWithPath(path)
+	require.NoError(t, err)
+	defer func() { _ = writer.Close() }()
+
+	first, err := AppendEventIdempotent(context.Background(), db, "agent1", "follow_1", "note", "", "before")
+	require.NoError(t, err)
+	_, err = AppendEventIdempotent(context.Background(), db, "agent1", "follow_2", "note", "", "after cursor")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	got := make(chan *models.Event, 8)
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowEvents(ctx, db, ListEventsParams{AgentName: "agent1", SinceID: first},
+			FollowOptions{MinInterval: 5 * time.Millisecond, MaxInterval: 20 * time.Millisecond},
+			func(e *models.Event) error {
+				got <- e
+				return nil
+			})
+	}()
+
+	replayed := <-got
+	require.Equal(t, "after cursor", replayed.Message)
+
+	_, err = AppendEventIdempotent(ctx, writer, "agent2", "follow_other", "note", "", "filtered out")
+	require.NoError(t, err)
+	_, err = AppendEventIdempotent(ctx, writer, "agent1", "follow_3", "note", "", "live")
+	require.NoError(t, err)
+
+	select {
+	case live := <-got:
+		require.Equal(t, "live", live.Message)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for followed event")
+	}
+
+	cancel()
+	require.True(t, errors.Is(<-done, context.Canceled) || errors.Is(ctx.Err(), context.DeadlineExceeded))
+	require.Empty(t, got)
+}
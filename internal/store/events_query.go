@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // ListEventsParams configures the ListEvents query.
@@ -19,12 +21,13 @@ type ListEventsParams struct {
 	Limit           int
 	Desc            bool
 	IncludeArchived bool
+	Meta            *MetaFilter // optional metadata filter (ParseMetaFilter)
 }
 
 // ListEvents retrieves events matching the given params, supporting optional filtering by task, project, agent, kind, and archive status.
 //
 //nolint:gocognit,gocyclo,funlen,revive // dynamic query building across many optional filter params (task, project, agent, kind, archived) requires many branches
-func ListEvents(db *sql.DB, p ListEventsParams) ([]*models.Event, error) {
+func ListEvents(ctx context.Context, db *sql.DB, p ListEventsParams) ([]*models.Event, error) {
 	if p.Limit <= 0 {
 		p.Limit = 50
 	}
@@ -58,13 +61,17 @@ func ListEvents(db *sql.DB, p ListEventsParams) ([]*models.Event, error) {
 	if !p.IncludeArchived {
 		where = append(where, "archived_at IS NULL")
 	}
+	if p.Meta != nil {
+		where = append(where, p.Meta.where)
+		args = append(args, p.Meta.args...)
+	}
 
 	query := `
 		SELECT id, kind, agent_name, project_id, task_id, message, metadata, created_at
 		FROM events
 	`
 	if len(where) > 0 {
-		query += " WHERE " + strings.Join(where, " AND ") //nolint:gosec // G202: clauses are hardcoded literals
+		query += " WHERE " + strings.Join(where, " AND ") //nolint:gosec // G202: clauses are hardcoded literals or parsed by ParseMetaFilter
 	}
 	if p.Desc {
 		query += " ORDER BY id DESC"
@@ -75,8 +82,8 @@ func ListEvents(db *sql.DB, p ListEventsParams) ([]*models.Event, error) {
 	args = append(args, p.Limit)
 
 	var out []*models.Event
-	err := RetryWithBackoff(context.Background(), func() error {
-		rows, err := db.QueryContext(context.Background(), query, args...)
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
 		if err != nil {
 			return fmt.Errorf("failed to list events: %w", err)
 		}
@@ -107,3 +114,64 @@ func ListEvents(db *sql.DB, p ListEventsParams) ([]*models.Event, error) {
 	}
 	return out, nil
 }
+
+// ExportEventsParams selects events for IterateEvents.
+type ExportEventsParams struct {
+	ProjectID       string
+	Kind            string
+	Since           time.Time // zero means all time
+	IncludeArchived bool
+}
+
+// IterateEvents streams matching events oldest-first to fn and returns how many were emitted.
+// Unlike ListEvents it is not retried: fn may already have written output when a
+// failure occurs, so the caller decides whether to restart.
+func IterateEvents(ctx context.Context, db *sql.DB, p ExportEventsParams, fn func(*models.Event) error) (_ int, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	where := []string{"1=1"}
+	var args []any
+	if p.ProjectID != "" {
+		where = append(where, ProjectScopeClause)
+		args = append(args, p.ProjectID)
+	}
+	if p.Kind != "" {
+		where = append(where, "kind = ?")
+		args = append(args, p.Kind)
+	}
+	if !p.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, p.Since.UTC().Format(time.DateTime))
+	}
+	if !p.IncludeArchived {
+		where = append(where, "archived_at IS NULL")
+	}
+
+	//nolint:gosec // G202: clauses are hardcoded literals
+	query := `SELECT id, kind, agent_name, project_id, task_id, message, metadata, created_at FROM events WHERE ` +
+		strings.Join(where, " AND ") + ` ORDER BY id ASC`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	n := 0
+	for rows.Next() {
+		var e models.Event
+		var projectID, taskID, meta sql.NullString
+		if err := rows.Scan(&e.ID, &e.Kind, &e.AgentName, &projectID, &taskID, &e.Message, &meta, &e.CreatedAt); err != nil {
+			return n, fmt.Errorf("failed to scan event: %w", err)
+		}
+		e.ProjectID = projectID.String
+		e.TaskID = taskID.String
+		e.Metadata = decodeEventMetadata(meta)
+		if err := fn(&e); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RetentionRule deletes every event of Kind (archived or active) older than OlderThanDays.
+type RetentionRule struct {
+	Kind          string `json:"kind"`
+	OlderThanDays int    `json:"older_than_days"`
+}
+
+// PruneCandidate reports how many events one retention rule would delete.
+// Kind is empty for the default rule, which only covers archived events.
+type PruneCandidate struct {
+	Kind          string `json:"kind,omitempty"`
+	OlderThanDays int    `json:"older_than_days"`
+	ArchivedOnly  bool   `json:"archived_only"`
+	Count         int64  `json:"count"`
+}
+
+// retentionPredicate is one WHERE clause (with args) selecting events a rule would delete.
+type retentionPredicate struct {
+	candidate PruneCandidate
+	where     string
+	args      []any
+}
+
+// buildRetentionPredicates returns the default archived-event predicate followed by
+// one predicate per kind rule. Kinds with their own rule are excluded from the
+// default so a longer per-kind window is not undercut by the global one.
+func buildRetentionPredicates(projectID string, defaultDays int, rules []RetentionRule) []retentionPredicate {
+	if defaultDays < 1 {
+		defaultDays = 30
+	}
+
+	scope := func(where string, args []any) (string, []any) {
+		if projectID == "" {
+			return where, args
+		}
+		return where + " AND " + ProjectScopeClause, append(args, projectID)
+	}
+
+	defaultWhere := `archived_at IS NOT NULL AND archived_at < datetime(CURRENT_TIMESTAMP, '-' || ? || ' days')`
+	defaultArgs := []any{defaultDays}
+	if len(rules) > 0 {
+		placeholders := make([]string, len(rules))
+		for i, r := range rules {
+			placeholders[i] = "?"
+			defaultArgs = append(defaultArgs, r.Kind)
+		}
+		defaultWhere += " AND kind NOT IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	defaultWhere, defaultArgs = scope(defaultWhere, defaultArgs)
+
+	preds := []retentionPredicate{{
+		candidate: PruneCandidate{OlderThanDays: defaultDays, ArchivedOnly: true},
+		where:     defaultWhere,
+		args:      defaultArgs,
+	}}
+
+	for _, r := range rules {
+		where, args := scope(
+			`kind = ? AND created_at < datetime(CURRENT_TIMESTAMP, '-' || ? || ' days')`,
+			[]any{r.Kind, r.OlderThanDays},
+		)
+		preds = append(preds, retentionPredicate{
+			candidate: PruneCandidate{Kind: r.Kind, OlderThanDays: r.OlderThanDays},
+			where:     where,
+			args:      args,
+		})
+	}
+	return preds
+}
+
+func validateRetentionRules(rules []RetentionRule) error {
+	for _, r := range rules {
+		if strings.TrimSpace(r.Kind) == "" {
+			return errors.New("retention rule kind is required")
+		}
+		if r.OlderThanDays < 1 {
+			return fmt.Errorf("retention rule for %q must be at least 1 day", r.Kind)
+		}
+	}
+	return nil
+}
+
+// PreviewRetentionPrune counts the events each retention rule would delete without deleting anything.
+func PreviewRetentionPrune(ctx context.Context, db *sql.DB, projectID string, defaultDays int, rules []RetentionRule) ([]PruneCandidate, error) {
+	if err := validateRetentionRules(rules); err != nil {
+		return nil, err
+	}
+
+	preds := buildRetentionPredicates(projectID, defaultDays, rules)
+	out := make([]PruneCandidate, 0, len(preds))
+	for _, p := range preds {
+		c := p.candidate
+		err := RetryWithBackoff(ctx, func() error {
+			return db.QueryRowContext(ctx,
+				`SELECT COUNT(*) FROM events WHERE `+p.where, p.args...,
+			).Scan(&c.Count)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("preview retention prune: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// PruneEventsByRetentionIdempotent applies the default archived-event window plus
+// per-kind rules in one idempotent transaction. At most limit rows are deleted per
+// call, spent in rule order (default first). Nothing is deleted once an event
+// chain is started: a pruned event would break it.
+//
+//nolint:revive // argument-limit: agent, request, project, default window, rules, and limit are all required
+func PruneEventsByRetentionIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, defaultDays int, rules []RetentionRule, limit int) (int64, error) {
+	if agentName == "" {
+		return 0, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return 0, errors.New("request id is required")
+	}
+	if err := validateRetentionRules(rules); err != nil {
+		return 0, err
+	}
+	if limit < 1 {
+		limit = 1000
+	}
+
+	type idemResult struct {
+		Deleted int64 `json:"deleted"`
+	}
+
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "events.prune_retention", func(tx *sql.Tx) (idemResult, error) {
+		if chained, err := eventChainEnabledTx(tx); err != nil || chained {
+			return idemResult{}, err
+		}
+		var total int64
+		for _, p := range buildRetentionPredicates(projectID, defaultDays, rules) {
+			remaining := int64(limit) - total
+			if remaining <= 0 {
+				break
+			}
+			args := append(append([]any{}, p.args...), remaining)
+			res, execErr := tx.ExecContext(ctx, `
+				DELETE FROM events
+				WHERE id IN (
+					SELECT id FROM events
+					WHERE `+p.where+`
+					ORDER BY id ASC
+					LIMIT ?
+				)
+			`, args...)
+			if execErr != nil {
+				return idemResult{}, fmt.Errorf("prune events by retention: %w", execErr)
+			}
+			n, rowsErr := res.RowsAffected()
+			if rowsErr != nil {
+				return idemResult{}, fmt.Errorf("count pruned events: %w", rowsErr)
+			}
+			total += n
+		}
+		return idemResult{Deleted: total}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return r.Deleted, nil
+}
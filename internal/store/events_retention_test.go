@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneEventsByRetention_KindRulesAndDefault(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	noisy := appendEventWithProject(t, db, "tool_success", "agent1", "proj_a", "", "old tool success")
+	keptNoisy := appendEventWithProject(t, db, "tool_success", "agent1", "proj_a", "", "fresh tool success")
+	archivedNote := appendEventWithProject(t, db, "note", "agent1", "proj_a", "", "archived note")
+	archivedLongLived := appendEventWithProject(t, db, "decision", "agent1", "proj_a", "", "archived decision")
+	otherProject := appendEventWithProject(t, db, "tool_success", "agent1", "proj_b", "", "other project")
+
+	old := time.Now().Add(-45 * 24 * time.Hour).UTC().Format("2006-01-02 15:04:05")
+	_, err := db.Exec(`UPDATE events SET created_at = ? WHERE id IN (?, ?)`, old, noisy, otherProject)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE events SET archived_at = ? WHERE id IN (?, ?)`, old, archivedNote, archivedLongLived)
+	require.NoError(t, err)
+
+	rules := []RetentionRule{
+		{Kind: "tool_success", OlderThanDays: 7},
+		{Kind: "decision", OlderThanDays: 365},
+	}
+
+	preview, err := PreviewRetentionPrune(context.Background(), db, "proj_a", 30, rules)
+	require.NoError(t, err)
+	require.Len(t, preview, 3)
+	require.True(t, preview[0].ArchivedOnly)
+	require.Equal(t, int64(1), preview[0].Count, "default rule must skip kinds with their own rule")
+	require.Equal(t, "tool_success", preview[1].Kind)
+	require.Equal(t, int64(1), preview[1].Count)
+	require.Equal(t, int64(0), preview[2].Count)
+
+	deleted, err := PruneEventsByRetentionIdempotent(context.Background(), db, "agent1", "req-retention-1", "proj_a", 30, rules, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deleted)
+
+	replayed, err := PruneEventsByRetentionIdempotent(context.Background(), db, "agent1", "req-retention-1", "proj_a", 30, rules, 100)
+	require.NoError(t, err)
+	require.Equal(t, deleted, replayed)
+
+	remaining := map[int64]bool{}
+	rows, err := db.Query(`SELECT id FROM events`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		require.NoError(t, rows.Scan(&id))
+		remaining[id] = true
+	}
+	require.NoError(t, rows.Err())
+
+	require.False(t, remaining[noisy])
+	require.False(t, remaining[archivedNote])
+	require.True(t, remaining[keptNoisy])
+	require.True(t, remaining[archivedLongLived])
+	require.True(t, remaining[otherProject], "project-scoped prune must not touch other projects")
+}
+
+func TestPruneEventsByRetention_LimitSpansRules(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for range 3 {
+		appendEvent(t, db, "tool_success", "agent1", "", "noise")
+	}
+	old := time.Now().Add(-10 * 24 * time.Hour).UTC().Format("2006-01-02 15:04:05")
+	_, err := db.Exec(`UPDATE events SET created_at = ?`, old)
+	require.NoError(t, err)
+
+	deleted, err := PruneEventsByRetentionIdempotent(context.Background(), db, "agent1", "req-retention-limit", "", 30,
+		[]RetentionRule{{Kind: "tool_success", OlderThanDays: 7}}, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deleted)
+}
+
+func TestPruneEventsByRetention_RejectsInvalidRule(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := PruneEventsByRetentionIdempotent(context.Background(), db, "agent1", "req-bad", "", 30, []RetentionRule{{Kind: "x", OlderThanDays: 0}}, 10)
+	require.Error(t, err)
+
+	_, err = PreviewRetentionPrune(context.Background(), db, "", 30, []RetentionRule{{Kind: " ", OlderThanDays: 3}})
+	require.Error(t, err)
+}
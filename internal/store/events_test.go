@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -69,9 +70,9 @@ func TestAppendEventIdempotent_Replay(t *testing.T) {
 	agent := "agent1"
 	req := "req_1"
 
-	id1, err := AppendEventIdempotent(db, agent, req, "test.event", "task-1", "hello")
+	id1, err := AppendEventIdempotent(context.Background(), db, agent, req, "test.event", "task-1", "hello")
 	require.NoError(t, err)
-	id2, err := AppendEventIdempotent(db, agent, req, "test.event", "task-1", "hello")
+	id2, err := AppendEventIdempotent(context.Background(), db, agent, req, "test.event", "task-1", "hello")
 	require.NoError(t, err)
 	require.Equal(t, id1, id2)
 
@@ -113,7 +114,7 @@ func TestListEvents_MetadataIsNativeJSON(t *testing.T) {
 	var err error
 	require.NoError(t, err)
 
-	events, err := ListEvents(db, ListEventsParams{AgentName: "test-agent", Limit: 10})
+	events, err := ListEvents(context.Background(), db, ListEventsParams{AgentName: "test-agent", Limit: 10})
 	require.NoError(t, err)
 	require.Len(t, events, 1)
 
@@ -142,7 +143,7 @@ func TestFetchEventsSince_MetadataIsNativeJSON(t *testing.T) {
 	var err error
 	require.NoError(t, err)
 
-	events, err := FetchEventsSince(db, 0, 10, "")
+	events, err := FetchEventsSince(context.Background(), db, 0, 10, "")
 	require.NoError(t, err)
 	require.Len(t, events, 1)
 
@@ -168,24 +169,24 @@ func TestArchiveEventsRangeWithSummaryIdempotent(t *testing.T) {
 	appendEvent(t, db, "task.note", "agent1", "task-1", "event two")
 	appendEvent(t, db, "task.note", "agent1", "task-1", "event three")
 
-	summaryEventID, archivedCount, err := ArchiveEventsRangeWithSummaryIdempotent(db, "agent1", "req-archive-1", "", "task-1", 1, 2, "Compressed old events")
+	summaryEventID, archivedCount, err := ArchiveEventsRangeWithSummaryIdempotent(context.Background(), db, "agent1", "req-archive-1", "", "task-1", 1, 2, "Compressed old events")
 	require.NoError(t, err)
 	require.Greater(t, summaryEventID, int64(0))
 	require.Equal(t, int64(2), archivedCount)
 
 	// Idempotent replay should not create a second summary event.
-	replayedEventID, replayedCount, err := ArchiveEventsRangeWithSummaryIdempotent(db, "agent1", "req-archive-1", "", "task-1", 1, 2, "Compressed old events")
+	replayedEventID, replayedCount, err := ArchiveEventsRangeWithSummaryIdempotent(context.Background(), db, "agent1", "req-archive-1", "", "task-1", 1, 2, "Compressed old events")
 	require.NoError(t, err)
 	require.Equal(t, summaryEventID, replayedEventID)
 	require.Equal(t, archivedCount, replayedCount)
 
-	visible, err := ListEvents(db, ListEventsParams{AgentName: "agent1", Limit: 20})
+	visible, err := ListEvents(context.Background(), db, ListEventsParams{AgentName: "agent1", Limit: 20})
 	require.NoError(t, err)
 	require.Len(t, visible, 2)
 	require.Equal(t, "event three", visible[0].Message)
 	require.Equal(t, "events_summary", visible[1].Kind)
 
-	all, err := ListEvents(db, ListEventsParams{AgentName: "agent1", Limit: 20, IncludeArchived: true})
+	all, err := ListEvents(context.Background(), db, ListEventsParams{AgentName: "agent1", Limit: 20, IncludeArchived: true})
 	require.NoError(t, err)
 	require.Len(t, all, 4)
 
@@ -202,10 +203,10 @@ func TestFetchEventsSince_ExcludesArchived(t *testing.T) {
 	appendEvent(t, db, "task.note", "agent1", "task-1", "event one")
 	appendEvent(t, db, "task.note", "agent1", "task-1", "event two")
 
-	_, _, err := ArchiveEventsRangeWithSummaryIdempotent(db, "agent1", "req-archive-2", "", "task-1", 1, 1, "Compressed oldest")
+	_, _, err := ArchiveEventsRangeWithSummaryIdempotent(context.Background(), db, "agent1", "req-archive-2", "", "task-1", 1, 1, "Compressed oldest")
 	require.NoError(t, err)
 
-	events, err := FetchEventsSince(db, 0, 20, "")
+	events, err := FetchEventsSince(context.Background(), db, 0, 20, "")
 	require.NoError(t, err)
 	require.Len(t, events, 2)
 	require.Equal(t, "event two", events[0].Message)
@@ -217,7 +218,7 @@ func TestCountActiveEvents(t *testing.T) {
 	defer cleanup()
 
 	// Empty DB
-	count, err := CountActiveEvents(db, "")
+	count, err := CountActiveEvents(context.Background(), db, "")
 	require.NoError(t, err)
 	require.Equal(t, int64(0), count)
 
@@ -226,16 +227,16 @@ func TestCountActiveEvents(t *testing.T) {
 	appendEvent(t, db, "note", "agent1", "", "event two")
 	appendEvent(t, db, "note", "agent1", "", "event three")
 
-	count, err = CountActiveEvents(db, "")
+	count, err = CountActiveEvents(context.Background(), db, "")
 	require.NoError(t, err)
 	require.Equal(t, int64(3), count)
 
 	// Archive one event
-	_, _, err = ArchiveEventsRangeWithSummaryIdempotent(db, "agent1", "req-cnt-1", "", "", 1, 1, "compressed")
+	_, _, err = ArchiveEventsRangeWithSummaryIdempotent(context.Background(), db, "agent1", "req-cnt-1", "", "", 1, 1, "compressed")
 	require.NoError(t, err)
 
 	// Should count 3 active (event two + event three + summary event), not the archived one
-	count, err = CountActiveEvents(db, "")
+	count, err = CountActiveEvents(context.Background(), db, "")
 	require.NoError(t, err)
 	require.Equal(t, int64(3), count)
 }
@@ -245,7 +246,7 @@ func TestFindArchiveWindow(t *testing.T) {
 	defer cleanup()
 
 	// Empty DB
-	fromID, toID, err := FindArchiveWindow(db, "", 5)
+	fromID, toID, err := FindArchiveWindow(context.Background(), db, "", 5)
 	require.NoError(t, err)
 	require.Equal(t, int64(0), fromID)
 	require.Equal(t, int64(0), toID)
@@ -256,19 +257,19 @@ func TestFindArchiveWindow(t *testing.T) {
 	}
 
 	// Keep 3 recent: should archive events 1-2
-	fromID, toID, err = FindArchiveWindow(db, "", 3)
+	fromID, toID, err = FindArchiveWindow(context.Background(), db, "", 3)
 	require.NoError(t, err)
 	require.Equal(t, int64(1), fromID)
 	require.Equal(t, int64(2), toID)
 
 	// Keep all: should return 0,0
-	fromID, toID, err = FindArchiveWindow(db, "", 5)
+	fromID, toID, err = FindArchiveWindow(context.Background(), db, "", 5)
 	require.NoError(t, err)
 	require.Equal(t, int64(0), fromID)
 	require.Equal(t, int64(0), toID)
 
 	// Keep more than available: should return 0,0
-	fromID, toID, err = FindArchiveWindow(db, "", 100)
+	fromID, toID, err = FindArchiveWindow(context.Background(), db, "", 100)
 	require.NoError(t, err)
 	require.Equal(t, int64(0), fromID)
 	require.Equal(t, int64(0), toID)
@@ -282,19 +283,19 @@ func TestPruneArchivedEventsIdempotent(t *testing.T) {
 	appendEvent(t, db, "note", "agent1", "", "event two")
 	appendEvent(t, db, "note", "agent1", "", "event three")
 
-	_, _, err := ArchiveEventsRangeWithSummaryIdempotent(db, "agent1", "req-prune-archive", "", "", 1, 2, "compressed")
+	_, _, err := ArchiveEventsRangeWithSummaryIdempotent(context.Background(), db, "agent1", "req-prune-archive", "", "", 1, 2, "compressed")
 	require.NoError(t, err)
 
 	old := time.Now().Add(-45 * 24 * time.Hour).UTC().Format("2006-01-02 15:04:05")
 	_, err = db.Exec(`UPDATE events SET archived_at = ? WHERE id IN (1,2)`, old)
 	require.NoError(t, err)
 
-	deleted, err := PruneArchivedEventsIdempotent(db, "agent1", "req-prune-1", "", 30, 10)
+	deleted, err := PruneArchivedEventsIdempotent(context.Background(), db, "agent1", "req-prune-1", "", 30, 10)
 	require.NoError(t, err)
 	require.Equal(t, int64(2), deleted)
 
 	// Idempotent replay with same request ID returns same count.
-	replayedDeleted, err := PruneArchivedEventsIdempotent(db, "agent1", "req-prune-1", "", 30, 10)
+	replayedDeleted, err := PruneArchivedEventsIdempotent(context.Background(), db, "agent1", "req-prune-1", "", 30, 10)
 	require.NoError(t, err)
 	require.Equal(t, deleted, replayedDeleted)
 
@@ -328,7 +329,7 @@ func TestArchiveEventsRangeWithSummaryIdempotent_SummaryUsesExplicitProject(t *t
 	id1 := appendEventWithProject(t, db, "task.note", agentName, explicitProject, "", "event one")
 	id2 := appendEventWithProject(t, db, "task.note", agentName, explicitProject, "", "event two")
 
-	summaryEventID, archivedCount, err := ArchiveEventsRangeWithSummaryIdempotent(
+	summaryEventID, archivedCount, err := ArchiveEventsRangeWithSummaryIdempotent(context.Background(),
 		db, agentName, "req-summary-proj-1", explicitProject, "", id1, id2, "Compressed project events",
 	)
 	require.NoError(t, err)
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// OpenDBReadOnly opens a live vybe database for reading only: no migrations,
+// no pragma writes, and any statement that writes fails. Unlike
+// OpenSnapshotReadOnly it is not immutable, so it sees committed WAL content
+// from writers using the same file. The schema must be fully migrated, since
+// store queries assume the latest columns.
+func OpenDBReadOnly(ctx context.Context, path string) (*sql.DB, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve database path: %w", err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro&_pragma=busy_timeout(%d)", abs, defaultBusyTimeoutMS))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	current, latest, err := SchemaVersion(ctx, db)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("not a vybe database: %w", err)
+	}
+	if current != latest {
+		_ = db.Close()
+		return nil, fmt.Errorf("database schema is at version %d, this vybe expects %d; run vybe against it once to migrate", current, latest)
+	}
+	return db, nil
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// FileEditTools are the tools whose tool_success events record a file write.
+func FileEditTools() []string {
+	return []string{"Write", "Edit", "MultiEdit", "NotebookEdit"}
+}
+
+// filePathInPreview finds the target path in a (possibly truncated) tool_input
+// preview, for events written before file_path was recorded in metadata.
+var filePathInPreview = regexp.MustCompile(`"(?:file_path|notebook_path)"\s*:\s*"((?:[^"\\]|\\.)*)"`)
+
+// FileChange aggregates the recorded writes to one file.
+type FileChange struct {
+	Path        string    `json:"path"`
+	Edits       int       `json:"edits"`
+	Tools       []string  `json:"tools"`
+	Agents      []string  `json:"agents"`
+	TaskIDs     []string  `json:"task_ids"`
+	FirstAt     time.Time `json:"first_at"`
+	LastAt      time.Time `json:"last_at"`
+	LastEventID int64     `json:"last_event_id"`
+}
+
+// FileJournalParams filters the events ListFileChanges aggregates. Zero
+// values do not filter.
+type FileJournalParams struct {
+	TaskID    string
+	ProjectID string
+	SessionID string
+	AgentName string
+	SinceDays int
+}
+
+// ListFileChanges aggregates successful Write/Edit/MultiEdit/NotebookEdit
+// events into one FileChange per path, most recently changed first. Archived
+// events count: summarizing history does not undo the edits it recorded.
+func ListFileChanges(ctx context.Context, db *sql.DB, p FileJournalParams) ([]FileChange, error) {
+	tools := FileEditTools()
+	query := `SELECT id, agent_name, COALESCE(task_id, ''), created_at,
+		CASE WHEN json_valid(metadata) THEN COALESCE(json_extract(metadata, '$.tool_name'), '') ELSE '' END,
+		CASE WHEN json_valid(metadata) THEN COALESCE(json_extract(metadata, '$.file_path'), '') ELSE '' END,
+		CASE WHEN json_valid(metadata) THEN COALESCE(json_extract(metadata, '$.tool_input_preview'), '') ELSE '' END
+		FROM events
+		WHERE kind = ?
+		  AND (CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.tool_name') END) IN (?` + strings.Repeat(", ?", len(tools)-1) + `)`
+	args := []any{models.EventKindToolSuccess}
+	for _, t := range tools {
+		args = append(args, t)
+	}
+	if p.TaskID != "" {
+		query += ` AND task_id = ?`
+		args = append(args, p.TaskID)
+	}
+	if p.ProjectID != "" {
+		query += ` AND project_id = ?`
+		args = append(args, p.ProjectID)
+	}
+	if p.SessionID != "" {
+		query += ` AND ` + sessionIDExpr + ` = ?`
+		args = append(args, p.SessionID)
+	}
+	if p.AgentName != "" {
+		query += ` AND agent_name = ?`
+		args = append(args, p.AgentName)
+	}
+	if p.SinceDays > 0 {
+		query += ` AND created_at >= datetime('now', ?)`
+		args = append(args, fmt.Sprintf("-%d days", p.SinceDays))
+	}
+	query += ` ORDER BY created_at ASC, id ASC`
+
+	var out []FileChange
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query file edits: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = []FileChange{}
+		byPath := map[string]int{}
+		for rows.Next() {
+			var (
+				id                                 int64
+				agent, taskID, tool, path, preview string
+				at                                 time.Time
+			)
+			if err := rows.Scan(&id, &agent, &taskID, &at, &tool, &path, &preview); err != nil {
+				return fmt.Errorf("failed to scan file edit: %w", err)
+			}
+			if path == "" {
+				path = ToolInputFilePath(preview)
+			}
+			if path == "" {
+				continue
+			}
+			i, ok := byPath[path]
+			if !ok {
+				i = len(out)
+				byPath[path] = i
+				out = append(out, FileChange{Path: path, Tools: []string{}, Agents: []string{}, TaskIDs: []string{}, FirstAt: at})
+			}
+			c := &out[i]
+			c.Edits++
+			c.LastAt, c.LastEventID = at, id
+			c.Tools = appendUnique(c.Tools, tool)
+			c.Agents = appendUnique(c.Agents, agent)
+			c.TaskIDs = appendUnique(c.TaskIDs, taskID)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(out, func(a, b FileChange) int {
+		if c := b.LastAt.Compare(a.LastAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.LastEventID, a.LastEventID)
+	})
+	return out, nil
+}
+
+// ToolInputFilePath returns the file a tool call's input (JSON, possibly a
+// truncated preview) targets, or "".
+func ToolInputFilePath(input string) string {
+	var v struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	}
+	if json.Unmarshal([]byte(input), &v) == nil {
+		if v.FilePath != "" {
+			return v.FilePath
+		}
+		return v.NotebookPath
+	}
+	m := filePathInPreview.FindStringSubmatch(input)
+	if m == nil {
+		return ""
+	}
+	path, err := strconv.Unquote(`"` + m[1] + `"`)
+	if err != nil {
+		return m[1]
+	}
+	return path
+}
+
+func appendUnique(list []string, v string) []string {
+	if v == "" || slices.Contains(list, v) {
+		return list
+	}
+	return append(list, v)
+}
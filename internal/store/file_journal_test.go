@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestListFileChanges(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now().UTC()
+	insert := func(kind, agent, task, metadata string, when time.Time) {
+		t.Helper()
+		_, err := db.Exec(`INSERT INTO events (kind, agent_name, project_id, task_id, message, metadata, created_at) VALUES (?, ?, '/repo', NULLIF(?, ''), 'x', ?, ?)`,
+			kind, agent, task, metadata, when.Format(time.DateTime))
+		require.NoError(t, err)
+	}
+	insert(models.EventKindToolSuccess, "a", "t1", `{"tool_name":"Edit","file_path":"/repo/main.go","session_id":"s1"}`, now.Add(-3*time.Hour))
+	insert(models.EventKindToolSuccess, "b", "t1", `{"tool_name":"Write","file_path":"/repo/util.go","session_id":"s1"}`, now.Add(-2*time.Hour))
+	insert(models.EventKindToolSuccess, "a", "t2", `{"tool_name":"MultiEdit","file_path":"/repo/main.go","session_id":"s2"}`, now.Add(-time.Hour))
+	// Older events carry the path only in a truncated input preview.
+	insert(models.EventKindToolSuccess, "a", "", `{"tool_name":"Write","tool_input_preview":"{\"file_path\":\"/repo/old.go\",\"content\":\"pack"}`, now.AddDate(0, 0, -10))
+	insert(models.EventKindToolSuccess, "a", "t1", `{"tool_name":"Read","file_path":"/repo/readme.md"}`, now)
+	insert(models.EventKindToolFailure, "a", "t1", `{"tool_name":"Edit","file_path":"/repo/failed.go"}`, now)
+
+	all, err := ListFileChanges(context.Background(), db, FileJournalParams{})
+	require.NoError(t, err)
+	paths := make([]string, len(all))
+	for i, f := range all {
+		paths[i] = f.Path
+	}
+	assert.Equal(t, []string{"/repo/main.go", "/repo/util.go", "/repo/old.go"}, paths)
+	assert.Equal(t, 2, all[0].Edits)
+	assert.Equal(t, []string{"Edit", "MultiEdit"}, all[0].Tools)
+	assert.Equal(t, []string{"t1", "t2"}, all[0].TaskIDs)
+
+	byTask, err := ListFileChanges(context.Background(), db, FileJournalParams{TaskID: "t1"})
+	require.NoError(t, err)
+	require.Len(t, byTask, 2)
+	assert.Equal(t, 1, byTask[1].Edits)
+
+	bySession, err := ListFileChanges(context.Background(), db, FileJournalParams{SessionID: "s2"})
+	require.NoError(t, err)
+	require.Len(t, bySession, 1)
+	assert.Equal(t, "/repo/main.go", bySession[0].Path)
+
+	recent, err := ListFileChanges(context.Background(), db, FileJournalParams{SinceDays: 7, AgentName: "a"})
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, 2, recent[0].Edits)
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 const (
@@ -19,12 +23,12 @@ type FocusResult struct {
 	Rule   string
 }
 
-func keepCurrentFocus(db *sql.DB, currentFocusID string) (bool, string) {
+func keepCurrentFocus(ctx context.Context, db *sql.DB, currentFocusID string) (bool, string) {
 	if currentFocusID == "" {
 		return false, ""
 	}
 
-	task, err := GetTask(db, currentFocusID)
+	task, err := GetTask(ctx, db, currentFocusID)
 	if err != nil {
 		return false, ""
 	}
@@ -38,8 +42,8 @@ func keepCurrentFocus(db *sql.DB, currentFocusID string) (bool, string) {
 	return false, ""
 }
 
-func pickAssignedTask(db *sql.DB, taskID, projectID string) string {
-	task, err := GetTask(db, taskID)
+func pickAssignedTask(ctx context.Context, db *sql.DB, taskID, projectID string) string {
+	task, err := GetTask(ctx, db, taskID)
 	if err != nil {
 		return ""
 	}
@@ -49,23 +53,117 @@ func pickAssignedTask(db *sql.DB, taskID, projectID string) string {
 	if projectID != "" && task.ProjectID != projectID {
 		return ""
 	}
+	if archived, err := isProjectArchived(db, task.ProjectID); err != nil || archived {
+		return ""
+	}
 
 	return taskID
 }
 
-// DetermineFocusTask selects a task to focus on using deterministic rules.
-func DetermineFocusTask(db *sql.DB, agentName, currentFocusID string, deltas []*models.Event, projectID string) (FocusResult, error) {
-	_ = agentName
+// DetermineFocusTask selects a task to focus on using deterministic rules and the
+// priority-first policy for rule 4.
+func DetermineFocusTask(ctx context.Context, db *sql.DB, agentName, currentFocusID string, deltas []*models.Event, projectID string) (FocusResult, error) {
+	return DetermineFocusTaskWithPolicy(ctx, db, agentName, currentFocusID, deltas, projectID, app.FocusPriorityFirst)
+}
+
+// DetermineFocusTaskWithPolicy is DetermineFocusTask with an explicit policy for
+// choosing among pending tasks once rules 1-3 have not matched.
+func DetermineFocusTaskWithPolicy(ctx context.Context, db *sql.DB, agentName, currentFocusID string, deltas []*models.Event, projectID string, policy app.FocusPolicy) (FocusResult, error) {
+	return DetermineFocusTaskExcluding(ctx, db, agentName, currentFocusID, deltas, projectID, policy, nil)
+}
+
+// DetermineFocusTaskExcluding is DetermineFocusTaskWithPolicy that never picks a
+// new task from exclude. Session-scoped resumes pass the tasks held by sibling
+// sessions of the same agent; a session always keeps its own current focus.
+func DetermineFocusTaskExcluding(ctx context.Context, db *sql.DB, agentName, currentFocusID string, deltas []*models.Event, projectID string, policy app.FocusPolicy, exclude []string) (FocusResult, error) {
+	return DetermineFocusTaskFiltered(ctx, db, agentName, currentFocusID, deltas, projectID, policy, FocusFilter{Exclude: exclude})
+}
+
+// FocusFilter narrows the tasks focus selection may pick, so a dedicated loop
+// can work one slice of the queue.
+type FocusFilter struct {
+	Exclude     []string // never pick these as a new focus
+	Tags        []string // the task must carry every one of these tags
+	MinPriority *int     // when set, the task's effective priority must be at least this
+	// RoutedTo, when set, names the agent the task is being routed to: every
+	// capability the task requires must be registered by that agent.
+	RoutedTo string
+}
+
+// narrows reports whether the filter restricts tasks beyond Exclude.
+func (f FocusFilter) narrows() bool {
+	return f.narrowsQueue() || f.RoutedTo != ""
+}
+
+// narrowsQueue reports whether the caller asked for a slice of the queue, as
+// opposed to only routing by capability.
+func (f FocusFilter) narrowsQueue() bool {
+	return len(f.Tags) > 0 || f.MinPriority != nil
+}
 
-	if keep, rule := keepCurrentFocus(db, currentFocusID); keep {
-		return FocusResult{TaskID: currentFocusID, Rule: rule}, nil
+// clause returns the SQL conditions (each prefixed with AND) and arguments that
+// restrict a query over tasks to the filter's tags, priority floor, and
+// capability routing. The query must include effectivePriorityCTE and
+// effectivePriorityJoin.
+func (f FocusFilter) clause() (string, []any) {
+	var b strings.Builder
+	var args []any
+	if tags := normalizeTags(f.Tags); len(tags) > 0 {
+		b.WriteString(` AND (SELECT COUNT(*) FROM task_tags WHERE task_tags.task_id = tasks.id AND task_tags.tag IN (?` +
+			strings.Repeat(", ?", len(tags)-1) + `)) = ?`)
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+		args = append(args, len(tags))
+	}
+	if f.MinPriority != nil {
+		b.WriteString(` AND ` + effectivePriorityExpr + ` >= ?`)
+		args = append(args, *f.MinPriority)
+	}
+	if f.RoutedTo != "" {
+		b.WriteString(` AND NOT EXISTS (SELECT 1 FROM task_requirements tr WHERE tr.task_id = tasks.id` +
+			` AND tr.capability NOT IN (SELECT capability FROM agent_capabilities WHERE agent_name = ?))`)
+		args = append(args, f.RoutedTo)
+	}
+	return b.String(), args
+}
+
+// focusFilterMatches reports whether taskID passes filter's tags, priority
+// floor, and capability routing. Exclude is not consulted: rules 1 and 3 keep the caller's own focus.
+func focusFilterMatches(ctx context.Context, db *sql.DB, taskID string, filter FocusFilter) bool {
+	if !filter.narrows() {
+		return true
+	}
+	cond, args := filter.clause()
+	query := effectivePriorityCTE + `SELECT 1 FROM tasks` + effectivePriorityJoin + ` WHERE tasks.id = ?` + cond
+	var one int
+	err := db.QueryRowContext(ctx, query, append([]any{taskID}, args...)...).Scan(&one)
+	return err == nil
+}
+
+// DetermineFocusTaskFiltered is DetermineFocusTaskWithPolicy restricted to
+// tasks passing filter. The filter applies to every rule: a current focus
+// outside it is set aside rather than kept, and an assignment outside it is
+// ignored.
+func DetermineFocusTaskFiltered(ctx context.Context, db *sql.DB, agentName, currentFocusID string, deltas []*models.Event, projectID string, policy app.FocusPolicy, filter FocusFilter) (_ FocusResult, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	currentMatches := currentFocusID != "" && focusFilterMatches(ctx, db, currentFocusID, filter)
+	if currentMatches {
+		if keep, rule := keepCurrentFocus(ctx, db, currentFocusID); keep {
+			return FocusResult{TaskID: currentFocusID, Rule: rule}, nil
+		}
 	}
 
 	for _, event := range deltas {
 		if event.Kind != "task_assigned" || event.TaskID == "" {
 			continue
 		}
-		if taskID := pickAssignedTask(db, event.TaskID, projectID); taskID != "" {
+		if slices.Contains(filter.Exclude, event.TaskID) {
+			continue
+		}
+		if taskID := pickAssignedTask(ctx, db, event.TaskID, projectID); taskID != "" && focusFilterMatches(ctx, db, taskID, filter) {
 			return FocusResult{
 				TaskID: taskID,
 				Rule:   fmt.Sprintf("rule2: assigned via task_assigned event for %s", taskID),
@@ -73,8 +171,8 @@ func DetermineFocusTask(db *sql.DB, agentName, currentFocusID string, deltas []*
 		}
 	}
 
-	if currentFocusID != "" {
-		task, err := GetTask(db, currentFocusID)
+	if currentMatches {
+		task, err := GetTask(ctx, db, currentFocusID)
 		if err == nil && task.Status == "pending" {
 			return FocusResult{
 				TaskID: currentFocusID,
@@ -83,35 +181,197 @@ func DetermineFocusTask(db *sql.DB, agentName, currentFocusID string, deltas []*
 		}
 	}
 
-	var taskID string
-	err := RetryWithBackoff(context.Background(), func() error {
-		if projectID != "" {
-			err := db.QueryRowContext(context.Background(), `
-				SELECT id FROM tasks WHERE status = 'pending' AND project_id = ? ORDER BY priority DESC, created_at ASC LIMIT 1
-			`, projectID).Scan(&taskID)
-			if err == sql.ErrNoRows {
-				taskID = ""
-				return nil
+	result, err := selectPendingTask(ctx, db, agentName, currentFocusID, projectID, policy, filter)
+	if err != nil {
+		return FocusResult{}, fmt.Errorf("failed to select focus task: %w", err)
+	}
+	if result.TaskID != "" {
+		return result, nil
+	}
+
+	if filter.narrowsQueue() {
+		return FocusResult{TaskID: "", Rule: "rule5: no pending tasks match the filter"}, nil
+	}
+	return FocusResult{TaskID: "", Rule: "rule5: no pending tasks available"}, nil
+}
+
+// selectPendingTask applies rule 4 under policy. Project-affinity and round-robin
+// only change the outcome when resume is not already scoped to a project.
+func selectPendingTask(ctx context.Context, db *sql.DB, agentName, currentFocusID, projectID string, policy app.FocusPolicy, filter FocusFilter) (FocusResult, error) {
+	if projectID == "" {
+		switch policy {
+		case app.FocusProjectAffinity:
+			lastProject, err := lastWorkedProject(ctx, db, agentName, currentFocusID)
+			if err != nil || lastProject == "" {
+				break
+			}
+			taskID, err := topPendingTask(ctx, db, lastProject, true, filter)
+			if err != nil {
+				return FocusResult{}, err
+			}
+			if taskID != "" {
+				return FocusResult{
+					TaskID: taskID,
+					Rule:   fmt.Sprintf("rule4: selected pending task %s in last-worked project %s (project-affinity)", taskID, lastProject),
+				}, nil
+			}
+		case app.FocusRoundRobin:
+			lastProject, err := lastWorkedProject(ctx, db, agentName, currentFocusID)
+			if err != nil {
+				return FocusResult{}, err
+			}
+			next, ok, err := nextRoundRobinProject(ctx, db, lastProject)
+			if err != nil {
+				return FocusResult{}, err
+			}
+			if ok {
+				taskID, err := topPendingTask(ctx, db, next, true, filter)
+				if err != nil {
+					return FocusResult{}, err
+				}
+				if taskID != "" {
+					return FocusResult{
+						TaskID: taskID,
+						Rule:   fmt.Sprintf("rule4: selected pending task %s from project %q (round-robin)", taskID, next) + inheritedPriorityNote(ctx, db, taskID),
+					}, nil
+				}
 			}
-			return err
 		}
+	}
+
+	if policy == app.FocusDeadlineFirst {
+		taskID, err := topPendingTaskOrdered(ctx, db, projectID, projectID != "", deadlineFirstOrder, filter)
+		if err != nil {
+			return FocusResult{}, err
+		}
+		if taskID == "" {
+			return FocusResult{}, nil
+		}
+		return FocusResult{TaskID: taskID, Rule: fmt.Sprintf("rule4: selected earliest-deadline pending task %s (deadline-first)", taskID) + inheritedPriorityNote(ctx, db, taskID)}, nil
+	}
+
+	taskID, err := topPendingTask(ctx, db, projectID, projectID != "", filter)
+	if err != nil {
+		return FocusResult{}, err
+	}
+	if taskID == "" {
+		return FocusResult{}, nil
+	}
+	return FocusResult{TaskID: taskID, Rule: fmt.Sprintf("rule4: selected highest-priority pending task %s", taskID) + inheritedPriorityNote(ctx, db, taskID)}, nil
+}
+
+// topPendingTask returns the highest effective priority, oldest pending task
+// passing filter and not in its Exclude list, skipping archived projects. When scoped, only tasks whose project matches projectID are
+// considered; an empty projectID then matches tasks without a project.
+func topPendingTask(ctx context.Context, db *sql.DB, projectID string, scoped bool, filter FocusFilter) (string, error) {
+	return topPendingTaskOrdered(ctx, db, projectID, scoped, priorityFirstOrder, filter)
+}
+
+// Rule 4 orderings, by effective priority (see task_priority.go); queries using
+// them must include effectivePriorityCTE and effectivePriorityJoin. Deadline-first
+// puts dated tasks ahead of undated ones and falls back to priority order among
+// equal deadlines.
+const (
+	priorityFirstOrder = effectivePriorityOrder + `, created_at ASC`
+	deadlineFirstOrder = `(due_at IS NULL) ASC, due_at ASC, ` + effectivePriorityOrder + `, created_at ASC`
+)
+
+func topPendingTaskOrdered(ctx context.Context, db *sql.DB, projectID string, scoped bool, orderBy string, filter FocusFilter) (string, error) {
+	query, args := pendingTaskQuery(projectID, scoped, orderBy, filter)
 
-		err := db.QueryRowContext(context.Background(), `
-			SELECT id FROM tasks WHERE status = 'pending' ORDER BY priority DESC, created_at ASC LIMIT 1
-		`).Scan(&taskID)
+	var taskID string
+	err := RetryWithBackoff(ctx, func() error {
+		err := db.QueryRowContext(ctx, query, args...).Scan(&taskID)
 		if err == sql.ErrNoRows {
 			taskID = ""
 			return nil
 		}
 		return err
 	})
-	if err != nil {
-		return FocusResult{}, fmt.Errorf("failed to select focus task: %w", err)
+	return taskID, err
+}
+
+// pendingTaskQuery builds the rule 4 query selecting the first pending task in
+// orderBy order that passes filter.
+func pendingTaskQuery(projectID string, scoped bool, orderBy string, filter FocusFilter) (string, []any) {
+	query := effectivePriorityCTE + `SELECT id FROM tasks` + effectivePriorityJoin +
+		` WHERE status = 'pending' AND ` + activeProjectTaskClause
+	var args []any
+	if scoped {
+		query += ` AND COALESCE(project_id, '') = ?`
+		args = append(args, projectID)
 	}
+	if len(filter.Exclude) > 0 {
+		query += ` AND id NOT IN (?` + strings.Repeat(", ?", len(filter.Exclude)-1) + `)`
+		for _, id := range filter.Exclude {
+			args = append(args, id)
+		}
+	}
+	cond, condArgs := filter.clause()
+	query += cond + ` ORDER BY ` + orderBy + ` LIMIT 1`
+	return query, append(args, condArgs...)
+}
 
-	if taskID != "" {
-		return FocusResult{TaskID: taskID, Rule: fmt.Sprintf("rule4: selected highest-priority pending task %s", taskID)}, nil
+// lastWorkedProject returns the project of the agent's previous focus task, or
+// failing that the project of the agent's most recent project-scoped event.
+func lastWorkedProject(ctx context.Context, db *sql.DB, agentName, currentFocusID string) (string, error) {
+	if currentFocusID != "" {
+		if task, err := GetTask(ctx, db, currentFocusID); err == nil && task.ProjectID != "" {
+			return task.ProjectID, nil
+		}
+	}
+	if agentName == "" {
+		return "", nil
 	}
 
-	return FocusResult{TaskID: "", Rule: "rule5: no pending tasks available"}, nil
+	var projectID string
+	err := RetryWithBackoff(ctx, func() error {
+		err := db.QueryRowContext(ctx, `
+			SELECT project_id FROM events
+			WHERE agent_name = ? AND project_id IS NOT NULL AND project_id != ''
+			ORDER BY id DESC LIMIT 1
+		`, agentName).Scan(&projectID)
+		if err == sql.ErrNoRows {
+			projectID = ""
+			return nil
+		}
+		return err
+	})
+	return projectID, err
+}
+
+// nextRoundRobinProject returns the project with pending work that follows
+// lastProject in lexical order, wrapping around. Tasks without a project form
+// their own "" bucket, which sorts first.
+func nextRoundRobinProject(ctx context.Context, db *sql.DB, lastProject string) (string, bool, error) {
+	var projects []string
+	err := RetryWithBackoff(ctx, func() error {
+		projects = projects[:0]
+		rows, err := db.QueryContext(ctx, `
+			SELECT DISTINCT COALESCE(project_id, '') AS p FROM tasks
+			WHERE status = 'pending' AND `+activeProjectTaskClause+`
+			ORDER BY p ASC
+		`)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var p string
+			if err := rows.Scan(&p); err != nil {
+				return err
+			}
+			projects = append(projects, p)
+		}
+		return rows.Err()
+	})
+	if err != nil || len(projects) == 0 {
+		return "", false, err
+	}
+	for _, p := range projects {
+		if p > lastProject {
+			return p, true, nil
+		}
+	}
+	return projects[0], true, nil
 }
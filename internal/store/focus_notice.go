@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// NoticeSender is the from_agent of inbox messages vybe writes itself. Unread
+// notices are listed in the recipient's brief until marked read.
+const NoticeSender = "vybe"
+
+// maxBriefNotices bounds the notices embedded in one brief.
+const maxBriefNotices = 10
+
+// focusHolder is an agent whose focus (agent-wide or per session) is a task.
+type focusHolder struct {
+	agentName string
+	taskID    string
+	title     string
+}
+
+// focusHoldersTx lists the distinct (agent, task) focus pairs matching where,
+// a condition on tasks aliased t (and on the holder's agent_name aliased f);
+// args bind its placeholders.
+func focusHoldersTx(tx *sql.Tx, where string, args ...any) ([]focusHolder, error) {
+	return queryFocusHolders(context.Background(), tx, where, args...)
+}
+
+func queryFocusHolders(ctx context.Context, q queryer, where string, args ...any) ([]focusHolder, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT DISTINCT f.agent_name, t.id, t.title FROM (
+			SELECT agent_name, focus_task_id FROM agent_state WHERE focus_task_id IS NOT NULL
+			UNION
+			SELECT agent_name, focus_task_id FROM agent_session_state WHERE focus_task_id IS NOT NULL
+		) f JOIN tasks t ON t.id = f.focus_task_id
+		WHERE `+where+`
+		ORDER BY f.agent_name, t.id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query focus holders: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var holders []focusHolder
+	for rows.Next() {
+		var h focusHolder
+		if err := rows.Scan(&h.agentName, &h.taskID, &h.title); err != nil {
+			return nil, fmt.Errorf("failed to scan focus holder: %w", err)
+		}
+		holders = append(holders, h)
+	}
+	return holders, rows.Err()
+}
+
+// notifyFocusLostTx leaves each holder an inbox notice that its task was taken
+// away, so an agent still working it learns on its next brief that it no longer
+// owns the task. reason completes "... was released: <reason>".
+func notifyFocusLostTx(tx *sql.Tx, holders []focusHolder, reason string) error {
+	for _, h := range holders {
+		subject := truncateRunes(fmt.Sprintf("Your focus on %s was released: %s", h.taskID, reason), maxMessageSubjectLength)
+		body := fmt.Sprintf("Task %s (%s) is no longer yours: %s. Stop working on it; run vybe resume to pick up new work.",
+			h.taskID, h.title, reason)
+		if _, _, err := SendMessageTx(tx, NoticeSender, h.agentName, subject, body, h.taskID); err != nil {
+			return fmt.Errorf("failed to notify %s: %w", h.agentName, err)
+		}
+	}
+	return nil
+}
+
+// ListUnreadNotices returns agentName's unread notices from NoticeSender,
+// oldest first.
+func ListUnreadNotices(ctx context.Context, db *sql.DB, agentName string) ([]models.Message, error) {
+	var out []models.Message
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, messageSelect+`
+			WHERE to_agent = ? AND from_agent = ? AND read_at IS NULL
+			ORDER BY created_at ASC, rowid ASC LIMIT ?
+		`, agentName, NoticeSender, maxBriefNotices)
+		if err != nil {
+			return fmt.Errorf("failed to query notices: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		out = make([]models.Message, 0)
+		for rows.Next() {
+			m, err := scanMessage(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, *m)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
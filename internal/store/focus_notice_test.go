@@ -0,0 +1,43 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFocusNotice_DeliveredInBriefUntilRead(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, err := CreateProject(context.Background(), db, "Old", "")
+	require.NoError(t, err)
+	task, err := CreateTask(context.Background(), db, "Long job", "", project.ID, 1)
+	require.NoError(t, err)
+	_, _, err = StartTaskAndFocus(context.Background(), db, "worker", task.ID)
+	require.NoError(t, err)
+
+	_, err = SetProjectArchivedIdempotent(context.Background(), db, "operator", "archive-1", project.ID, true)
+	require.NoError(t, err)
+
+	brief, err := BuildBrief(context.Background(), db, "", "", "worker")
+	require.NoError(t, err)
+	require.Len(t, brief.Notices, 1)
+	notice := brief.Notices[0]
+	assert.Equal(t, NoticeSender, notice.FromAgent)
+	assert.Equal(t, task.ID, notice.TaskID)
+	assert.Contains(t, notice.Subject, "Your focus on "+task.ID+" was released")
+	assert.Contains(t, notice.Body, "archived")
+
+	other, err := BuildBrief(context.Background(), db, "", "", "operator")
+	require.NoError(t, err)
+	assert.Empty(t, other.Notices, "only the agent that lost the task is notified")
+
+	_, err = MarkMessagesReadIdempotent(context.Background(), db, "worker", "read-1", []string{notice.ID})
+	require.NoError(t, err)
+	brief, err = BuildBrief(context.Background(), db, "", "", "worker")
+	require.NoError(t, err)
+	assert.Empty(t, brief.Notices)
+}
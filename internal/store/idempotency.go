@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	sqlite "modernc.org/sqlite"
+
+	"github.com/dotcommander/vybe/internal/app"
 )
 
 // ErrIdempotencyInProgress is returned when a request is still being processed by another agent.
@@ -88,6 +90,38 @@ func completeIdempotencyTx(tx *sql.Tx, agentName, requestID, resultJSON string)
 	return nil
 }
 
+// PruneIdempotencyTx deletes completed idempotency records older than
+// olderThanDays, oldest first, up to limit. Windows shorter than
+// app.MinIdempotencyTTLDays are raised to it, so a request retried within the
+// retry window still replays instead of running twice. Rows still being
+// written (empty result_json) are never touched.
+func PruneIdempotencyTx(tx *sql.Tx, olderThanDays, limit int) (int64, error) {
+	if olderThanDays < app.MinIdempotencyTTLDays {
+		olderThanDays = app.MinIdempotencyTTLDays
+	}
+	if limit < 1 {
+		limit = 1000
+	}
+	res, err := tx.ExecContext(context.Background(), `
+		DELETE FROM idempotency
+		WHERE rowid IN (
+			SELECT rowid FROM idempotency
+			WHERE result_json != ''
+			  AND created_at < datetime(CURRENT_TIMESTAMP, '-' || ? || ' days')
+			ORDER BY created_at ASC
+			LIMIT ?
+		)
+	`, olderThanDays, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune idempotency rows: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check idempotency rows affected: %w", err)
+	}
+	return deleted, nil
+}
+
 // IsUniqueConstraintErr checks for SQLite duplicate-key violations.
 // Exported for use by batch operations in actions layer.
 //
@@ -3,9 +3,16 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 func TestIdempotency_BeginCompleteReplay(t *testing.T) {
@@ -221,3 +228,102 @@ func TestRunIdempotent_ReplaySkipsOperation(t *testing.T) {
 	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM projects`).Scan(&projectCount))
 	require.Equal(t, 1, projectCount)
 }
+
+func TestGCWithEventIdempotent_PrunesIdempotencyPastTTL(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, req := range []string{"req_old", "req_week", "req_fresh"} {
+		_, err := RunIdempotent(context.Background(), db, "agent1", req, "unit.prune", func(tx *sql.Tx) (int, error) {
+			return 1, nil
+		})
+		require.NoError(t, err)
+	}
+	_, err := db.Exec(`UPDATE idempotency SET created_at = datetime(CURRENT_TIMESTAMP, '-40 days') WHERE request_id = 'req_old'`)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE idempotency SET created_at = datetime(CURRENT_TIMESTAMP, '-5 days') WHERE request_id = 'req_week'`)
+	require.NoError(t, err)
+
+	// A 1-day TTL is raised to the 7-day floor: the 5-day-old record survives.
+	r, err := GCWithEventIdempotent(context.Background(), db, "agent1", "req_gc", GCOptions{Limit: 10, IdempotencyTTLDays: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r.IdempotencyDeleted)
+
+	var left []string
+	rows, err := db.Query(`SELECT request_id FROM idempotency ORDER BY request_id`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+		left = append(left, id)
+	}
+	require.NoError(t, rows.Close())
+	require.Equal(t, []string{"req_fresh", "req_gc", "req_week"}, left)
+
+	var kind, metaRaw string
+	require.NoError(t, db.QueryRow(`SELECT kind, metadata FROM events WHERE id = ?`, r.EventID).Scan(&kind, &metaRaw))
+	require.Equal(t, models.EventKindMemoryGC, kind)
+	var meta map[string]any
+	require.NoError(t, json.Unmarshal([]byte(metaRaw), &meta))
+	require.InDelta(t, 1, meta["idempotency_deleted"], 0)
+	require.InDelta(t, 7, meta["idempotency_ttl_days"], 0)
+
+	// Without a TTL, GC leaves idempotency records alone.
+	_, err = db.Exec(`UPDATE idempotency SET created_at = datetime(CURRENT_TIMESTAMP, '-40 days')`)
+	require.NoError(t, err)
+	r, err = GCWithEventIdempotent(context.Background(), db, "agent1", "req_gc_off", GCOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Zero(t, r.IdempotencyDeleted)
+}
+
+func TestRunIdempotent_TracesReplayAndQueries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	type span struct {
+		Name       string `json:"name"`
+		Attributes []struct {
+			Key   string         `json:"key"`
+			Value map[string]any `json:"value"`
+		} `json:"attributes"`
+	}
+	var exported []span
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []span `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		exported = req.ResourceSpans[0].ScopeSpans[0].Spans
+	}))
+	defer srv.Close()
+
+	op := func(tx *sql.Tx) (string, error) { return "done", nil }
+	_, err := RunIdempotent(context.Background(), db, "agent1", "req_trace", "test.op", op)
+	require.NoError(t, err)
+
+	ctx, _ := telemetry.StartTrace(context.Background(), telemetry.Config{Endpoint: srv.URL, ServiceName: "vybe"}, "vybe test")
+	_, err = RunIdempotent(ctx, db, "agent1", "req_trace", "test.op", op)
+	require.NoError(t, err)
+	_, err = GetMemoryConflict(ctx, db, 42)
+	require.Error(t, err)
+	require.NoError(t, telemetry.Finish(ctx, nil))
+
+	attrs := map[string]map[string]any{}
+	for _, s := range exported {
+		attrs[s.Name] = map[string]any{}
+		for _, a := range s.Attributes {
+			for _, v := range a.Value {
+				attrs[s.Name][a.Key] = v
+			}
+		}
+	}
+	require.Contains(t, attrs, "idempotent test.op")
+	assert.Equal(t, true, attrs["idempotent test.op"]["vybe.idempotency.replayed"])
+	require.Contains(t, attrs, "store.GetMemoryConflict")
+	assert.Equal(t, "sqlite", attrs["store.GetMemoryConflict"]["db.system.name"])
+	assert.Equal(t, "0", attrs["store.GetMemoryConflict"]["vybe.retries"])
+}
@@ -13,28 +13,28 @@ func TestStartTaskAndFocus_AndIdempotent(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "start me", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "start me", "", "", 0)
 	require.NoError(t, err)
 
-	statusEventID, focusEventID, err := StartTaskAndFocus(db, "agent-a", task.ID)
+	statusEventID, focusEventID, err := StartTaskAndFocus(context.Background(), db, "agent-a", task.ID)
 	require.NoError(t, err)
 	require.Greater(t, statusEventID, int64(0))
 	require.Greater(t, focusEventID, int64(0))
 
-	updated, err := GetTask(db, task.ID)
+	updated, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	require.Equal(t, models.TaskStatusInProgress, updated.Status)
 
-	state, err := LoadOrCreateAgentState(db, "agent-a")
+	state, err := LoadOrCreateAgentState(context.Background(), db, "agent-a")
 	require.NoError(t, err)
 	require.Equal(t, task.ID, state.FocusTaskID)
 
-	statusEventID2, focusEventID2, err := StartTaskAndFocusIdempotent(db, "agent-a", "req-start-1", task.ID)
+	statusEventID2, focusEventID2, err := StartTaskAndFocusIdempotent(context.Background(), db, "agent-a", "req-start-1", task.ID)
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, statusEventID2, int64(0))
 	require.Greater(t, focusEventID2, int64(0))
 
-	statusEventID3, focusEventID3, err := StartTaskAndFocusIdempotent(db, "agent-a", "req-start-1", task.ID)
+	statusEventID3, focusEventID3, err := StartTaskAndFocusIdempotent(context.Background(), db, "agent-a", "req-start-1", task.ID)
 	require.NoError(t, err)
 	require.Equal(t, statusEventID2, statusEventID3)
 	require.Equal(t, focusEventID2, focusEventID3)
@@ -44,29 +44,29 @@ func TestAgentStateLoadOrCreateAndCursorHelpers(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	state1, err := LoadOrCreateAgentState(db, "agent-a")
+	state1, err := LoadOrCreateAgentState(context.Background(), db, "agent-a")
 	require.NoError(t, err)
-	state2, err := LoadOrCreateAgentState(db, "agent-a")
+	state2, err := LoadOrCreateAgentState(context.Background(), db, "agent-a")
 	require.NoError(t, err)
 	require.Equal(t, state1.AgentName, state2.AgentName)
 
-	task, err := CreateTask(db, "focus task", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "focus task", "", "", 0)
 	require.NoError(t, err)
 
-	focusEventID, err := SetAgentFocusTaskWithEventIdempotent(db, "agent-a", "req-focus-task", task.ID)
+	focusEventID, err := SetAgentFocusTaskWithEventIdempotent(context.Background(), db, "agent-a", "req-focus-task", task.ID)
 	require.NoError(t, err)
 	require.Greater(t, focusEventID, int64(0))
 
-	project, err := CreateProject(db, "proj", "")
+	project, err := CreateProject(context.Background(), db, "proj", "")
 	require.NoError(t, err)
 
-	projEvent1, err := SetAgentFocusProjectWithEventIdempotent(db, "agent-a", "req-proj-focus", project.ID)
+	projEvent1, err := SetAgentFocusProjectWithEventIdempotent(context.Background(), db, "agent-a", "req-proj-focus", project.ID)
 	require.NoError(t, err)
-	projEvent2, err := SetAgentFocusProjectWithEventIdempotent(db, "agent-a", "req-proj-focus", project.ID)
+	projEvent2, err := SetAgentFocusProjectWithEventIdempotent(context.Background(), db, "agent-a", "req-proj-focus", project.ID)
 	require.NoError(t, err)
 	require.Equal(t, projEvent1, projEvent2)
 
-	require.NoError(t, UpdateAgentStateAtomicWithProject(db, "agent-a", 42, task.ID, project.ID))
+	require.NoError(t, UpdateAgentStateAtomicWithProject(context.Background(), db, "agent-a", 42, task.ID, project.ID))
 
 	err = Transact(context.Background(), db, func(tx *sql.Tx) error {
 		cf, txErr := LoadAgentCursorAndFocusTx(tx, "agent-a")
@@ -81,7 +81,7 @@ func TestAgentStateLoadOrCreateAndCursorHelpers(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	st, err := LoadOrCreateAgentState(db, "agent-a")
+	st, err := LoadOrCreateAgentState(context.Background(), db, "agent-a")
 	require.NoError(t, err)
 	require.Equal(t, int64(50), st.LastSeenEventID)
 	require.Equal(t, task.ID, st.FocusTaskID)
@@ -92,7 +92,7 @@ func TestEventIdempotentVariantsWithProjectAndMetadata(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := CreateProject(db, "Project X", "")
+	project, err := CreateProject(context.Background(), db, "Project X", "")
 	require.NoError(t, err)
 
 	// Insert event with project.
@@ -104,15 +104,15 @@ func TestEventIdempotentVariantsWithProjectAndMetadata(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	id1, err := AppendEventWithProjectAndMetadataIdempotent(db, "agent-a", "req-evt-proj-1", "user_prompt", project.ID, "", "msg", `{"project":"`+project.ID+`"}`)
+	id1, err := AppendEventWithProjectAndMetadataIdempotent(context.Background(), db, "agent-a", "req-evt-proj-1", "user_prompt", project.ID, "", "msg", `{"project":"`+project.ID+`"}`)
 	require.NoError(t, err)
-	id2, err := AppendEventWithProjectAndMetadataIdempotent(db, "agent-a", "req-evt-proj-1", "user_prompt", project.ID, "", "msg", `{"project":"`+project.ID+`"}`)
+	id2, err := AppendEventWithProjectAndMetadataIdempotent(context.Background(), db, "agent-a", "req-evt-proj-1", "user_prompt", project.ID, "", "msg", `{"project":"`+project.ID+`"}`)
 	require.NoError(t, err)
 	require.Equal(t, id1, id2)
 
-	m1, err := AppendEventWithMetadataIdempotent(db, "agent-a", "req-evt-meta-1", "memory_set", "", "memo", `{"k":"v"}`)
+	m1, err := AppendEventWithMetadataIdempotent(context.Background(), db, "agent-a", "req-evt-meta-1", "memory_set", "", "memo", `{"k":"v"}`)
 	require.NoError(t, err)
-	m2, err := AppendEventWithMetadataIdempotent(db, "agent-a", "req-evt-meta-1", "memory_set", "", "memo", `{"k":"v"}`)
+	m2, err := AppendEventWithMetadataIdempotent(context.Background(), db, "agent-a", "req-evt-meta-1", "memory_set", "", "memo", `{"k":"v"}`)
 	require.NoError(t, err)
 	require.Equal(t, m1, m2)
 }
@@ -121,12 +121,12 @@ func TestAddArtifactIdempotent_Replay(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "art task", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "art task", "", "", 0)
 	require.NoError(t, err)
 
-	a1, e1, err := AddArtifactIdempotent(db, "agent-a", "req-art-1", task.ID, "/tmp/a.txt", "text/plain")
+	a1, e1, err := AddArtifactIdempotent(context.Background(), db, "agent-a", "req-art-1", task.ID, "/tmp/a.txt", "text/plain")
 	require.NoError(t, err)
-	a2, e2, err := AddArtifactIdempotent(db, "agent-a", "req-art-1", task.ID, "/tmp/a.txt", "text/plain")
+	a2, e2, err := AddArtifactIdempotent(context.Background(), db, "agent-a", "req-art-1", task.ID, "/tmp/a.txt", "text/plain")
 	require.NoError(t, err)
 	require.Equal(t, a1.ID, a2.ID)
 	require.Equal(t, e1, e2)
@@ -136,7 +136,7 @@ func TestDeleteMemoryWithEventIdempotent_Replay(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	require.NoError(t, SetMemory(db, "key-x", "value-x", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key-x", "value-x", "string", "global", "", nil, false, "", nil))
 
 	e1, err := DeleteMemoryWithEventIdempotent(context.Background(), db, "agent-a", "req-mem-del-1", "key-x", "global", "")
 	require.NoError(t, err)
@@ -149,11 +149,11 @@ func TestTaskTxHelpers_GetTaskTxAndUpdateWithEvent(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "tx task", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "tx task", "", "", 0)
 	require.NoError(t, err)
 
 	err = Transact(context.Background(), db, func(tx *sql.Tx) error {
-		fetched, getErr := getTaskTx(tx, task.ID)
+		fetched, getErr := GetTaskTx(tx, task.ID)
 		require.NoError(t, getErr)
 		require.Equal(t, task.ID, fetched.ID)
 
@@ -167,7 +167,7 @@ func TestTaskTxHelpers_GetTaskTxAndUpdateWithEvent(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	updated, err := GetTask(db, task.ID)
+	updated, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	require.Equal(t, models.TaskStatusInProgress, updated.Status)
 }
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
@@ -49,7 +50,7 @@ func TestInitDBIntegration(t *testing.T) {
 	}
 
 	// Verify we can load agent state
-	state, err := LoadOrCreateAgentState(db, "test-agent")
+	state, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	if err != nil {
 		t.Fatalf("LoadOrCreateAgentState failed: %v", err)
 	}
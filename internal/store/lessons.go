@@ -0,0 +1,357 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// Lesson confidence bounds the knowledge base's ranking: new lessons start at
+// DefaultLessonConfidence, promote and demote move it by LessonConfidenceStep,
+// and briefs skip lessons below LessonBriefMinConfidence.
+const (
+	DefaultLessonConfidence  = 0.5
+	LessonConfidenceStep     = 0.2
+	LessonBriefMinConfidence = 0.2
+)
+
+// Feedback steps: a wrong verdict costs more than a helpful one earns, so a
+// lesson reported wrong three times drops out of briefs from the default
+// confidence even with an occasional helpful report in between.
+const (
+	LessonHelpfulStep = 0.1
+	LessonWrongStep   = 0.15
+)
+
+// maxLessonLength bounds a lesson's text in runes.
+const maxLessonLength = 1000
+
+// lessonRankOrder ranks lessons: most trusted first, then most surfaced.
+const lessonRankOrder = `confidence DESC, hit_count DESC, id DESC`
+
+const lessonSelect = `SELECT id, project_id, text, source, confidence, hit_count, last_hit_at, helpful_count, wrong_count, created_by, created_at, updated_at FROM lessons`
+
+// Lesson is one entry in the lessons-learned knowledge base. An empty
+// ProjectID marks a global lesson.
+type Lesson struct {
+	ID           int64      `json:"id"`
+	ProjectID    string     `json:"project_id,omitempty"`
+	Text         string     `json:"text"`
+	Source       string     `json:"source,omitempty"`
+	Confidence   float64    `json:"confidence"`
+	HitCount     int        `json:"hit_count"`
+	LastHitAt    *time.Time `json:"last_hit_at,omitempty"`
+	HelpfulCount int        `json:"helpful_count"`
+	WrongCount   int        `json:"wrong_count"`
+	CreatedBy    string     `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// LessonChange is the result of adding or re-ranking a lesson.
+type LessonChange struct {
+	Lesson             *Lesson  `json:"lesson"`
+	Created            bool     `json:"created,omitempty"`
+	PreviousConfidence *float64 `json:"previous_confidence,omitempty"`
+	EventID            int64    `json:"event_id,omitempty"`
+}
+
+func scanLesson(row interface{ Scan(dest ...any) error }) (*Lesson, error) {
+	var l Lesson
+	var lastHit sql.NullTime
+	if err := row.Scan(&l.ID, &l.ProjectID, &l.Text, &l.Source, &l.Confidence, &l.HitCount, &lastHit, &l.HelpfulCount, &l.WrongCount, &l.CreatedBy, &l.CreatedAt, &l.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastHit.Valid {
+		t := lastHit.Time
+		l.LastHitAt = &t
+	}
+	return &l, nil
+}
+
+func getLesson(ctx context.Context, q queryer, id int64) (*Lesson, error) {
+	rows, err := q.QueryContext(ctx, lessonSelect+` WHERE id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lesson: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("lesson not found: %d", id)
+	}
+	l, err := scanLesson(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan lesson: %w", err)
+	}
+	return l, nil
+}
+
+// AddLessonTx records a lesson for projectID ("" for global) and emits a
+// lesson_added event. A lesson with the same text in the same project is
+// returned as is, with Created unset and no event. confidence nil means
+// DefaultLessonConfidence.
+func AddLessonTx(tx *sql.Tx, agentName, projectID, text, source string, confidence *float64) (*LessonChange, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.New("lesson text is required")
+	}
+	if runes := []rune(text); len(runes) > maxLessonLength {
+		return nil, fmt.Errorf("lesson exceeds %d characters", maxLessonLength)
+	}
+	c := DefaultLessonConfidence
+	if confidence != nil {
+		c = *confidence
+	}
+	if c < 0 || c > 1 {
+		return nil, fmt.Errorf("confidence must be between 0 and 1, got %g", c)
+	}
+	if err := CheckReferencesTx(tx, Reference{Field: "project_id", Kind: RefProject, ID: projectID}); err != nil {
+		return nil, err
+	}
+
+	res, err := tx.ExecContext(context.Background(), `
+		INSERT INTO lessons (project_id, text, source, confidence, created_by)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (project_id, text) DO NOTHING
+	`, projectID, text, source, c, agentName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert lesson: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		var id int64
+		if err := tx.QueryRowContext(context.Background(),
+			`SELECT id FROM lessons WHERE project_id = ? AND text = ?`, projectID, text).Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to load existing lesson: %w", err)
+		}
+		l, err := getLesson(context.Background(), tx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &LessonChange{Lesson: l}, nil
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lesson id: %w", err)
+	}
+	l, err := getLesson(context.Background(), tx, id)
+	if err != nil {
+		return nil, err
+	}
+	meta, _ := json.Marshal(map[string]any{"lesson_id": id, "project_id": projectID, "source": source, "confidence": c})
+	eventID, err := InsertEventTx(tx, models.EventKindLessonAdded, agentName, "",
+		"Lesson added: "+truncateRunes(text, 200), string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append lesson event: %w", err)
+	}
+	return &LessonChange{Lesson: l, Created: true, EventID: eventID}, nil
+}
+
+// AddLessonIdempotent performs AddLessonTx once per (agent_name, request_id).
+func AddLessonIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID, text, source string, confidence *float64) (*LessonChange, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "lesson.add", func(tx *sql.Tx) (*LessonChange, error) {
+		return AddLessonTx(tx, agentName, projectID, text, source, confidence)
+	})
+}
+
+// RankLessonTx moves a lesson's confidence by delta, clamped to [0, 1], and
+// emits a lesson_ranked event. When global is set a project lesson also
+// becomes global, so every project's briefs can surface it.
+func RankLessonTx(tx *sql.Tx, agentName string, lessonID int64, delta float64, global bool) (*LessonChange, error) {
+	l, err := getLesson(context.Background(), tx, lessonID)
+	if err != nil {
+		return nil, err
+	}
+	previous := l.Confidence
+	next := clampLessonConfidence(previous + delta)
+
+	projectID := l.ProjectID
+	if global {
+		projectID = ""
+	}
+	if _, err := tx.ExecContext(context.Background(), `
+		UPDATE lessons SET confidence = ?, project_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, next, projectID, lessonID); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, fmt.Errorf("a global lesson with the same text already exists; rank that one instead")
+		}
+		return nil, fmt.Errorf("failed to update lesson: %w", err)
+	}
+	if l, err = getLesson(context.Background(), tx, lessonID); err != nil {
+		return nil, err
+	}
+
+	meta, _ := json.Marshal(map[string]any{
+		"lesson_id":           lessonID,
+		"confidence":          next,
+		"previous_confidence": previous,
+		"project_id":          projectID,
+	})
+	verb := "promoted"
+	if delta < 0 {
+		verb = "demoted"
+	}
+	eventID, err := InsertEventTx(tx, models.EventKindLessonRanked, agentName, "",
+		fmt.Sprintf("Lesson %d %s: confidence %.2f -> %.2f", lessonID, verb, previous, next), string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append lesson event: %w", err)
+	}
+	return &LessonChange{Lesson: l, PreviousConfidence: &previous, EventID: eventID}, nil
+}
+
+// RankLessonIdempotent performs RankLessonTx once per (agent_name, request_id).
+// command names the operation in the idempotency log (lesson.promote, ...).
+func RankLessonIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, command string, lessonID int64, delta float64, global bool) (*LessonChange, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, command, func(tx *sql.Tx) (*LessonChange, error) {
+		return RankLessonTx(tx, agentName, lessonID, delta, global)
+	})
+}
+
+// FeedbackLessonTx records a helpful or wrong verdict on a lesson: the
+// matching tally goes up, confidence moves by LessonHelpfulStep or
+// -LessonWrongStep (clamped to [0, 1]), and a lesson_feedback event is written.
+func FeedbackLessonTx(tx *sql.Tx, agentName string, lessonID int64, helpful bool, note string) (*LessonChange, error) {
+	l, err := getLesson(context.Background(), tx, lessonID)
+	if err != nil {
+		return nil, err
+	}
+	previous := l.Confidence
+	verdict, next, counter := "helpful", clampLessonConfidence(previous+LessonHelpfulStep), "helpful_count"
+	if !helpful {
+		verdict, next, counter = "wrong", clampLessonConfidence(previous-LessonWrongStep), "wrong_count"
+	}
+	// counter is one of two fixed column names.
+	if _, err := tx.ExecContext(context.Background(), `
+		UPDATE lessons SET confidence = ?, `+counter+` = `+counter+` + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, next, lessonID); err != nil {
+		return nil, fmt.Errorf("failed to update lesson: %w", err)
+	}
+	if l, err = getLesson(context.Background(), tx, lessonID); err != nil {
+		return nil, err
+	}
+
+	meta, _ := json.Marshal(map[string]any{
+		"lesson_id":           lessonID,
+		"verdict":             verdict,
+		"note":                note,
+		"confidence":          next,
+		"previous_confidence": previous,
+	})
+	msg := fmt.Sprintf("Lesson %d marked %s: confidence %.2f -> %.2f", lessonID, verdict, previous, next)
+	if previous >= LessonBriefMinConfidence && next < LessonBriefMinConfidence {
+		msg += " (no longer in briefs)"
+	}
+	eventID, err := InsertEventTx(tx, models.EventKindLessonFeedback, agentName, "", msg, string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append lesson event: %w", err)
+	}
+	return &LessonChange{Lesson: l, PreviousConfidence: &previous, EventID: eventID}, nil
+}
+
+// FeedbackLessonIdempotent performs FeedbackLessonTx once per (agent_name, request_id).
+func FeedbackLessonIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, lessonID int64, helpful bool, note string) (*LessonChange, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "lesson.feedback", func(tx *sql.Tx) (*LessonChange, error) {
+		return FeedbackLessonTx(tx, agentName, lessonID, helpful, note)
+	})
+}
+
+// clampLessonConfidence bounds v to [0, 1], rounded to two decimals so
+// repeated steps do not accumulate float noise.
+func clampLessonConfidence(v float64) float64 {
+	return math.Round(math.Min(1, math.Max(0, v))*100) / 100
+}
+
+// LessonQuery filters ListLessons. A ProjectID selects that project's lessons
+// plus global ones; empty selects every lesson. Each whitespace-separated word
+// of Query must appear in the lesson text (case-insensitive).
+type LessonQuery struct {
+	ProjectID string
+	Query     string
+	Limit     int
+}
+
+// ListLessons returns lessons matching q, best ranked first.
+func ListLessons(ctx context.Context, db *sql.DB, q LessonQuery) (_ []*Lesson, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	query := lessonSelect + ` WHERE 1 = 1`
+	var args []any
+	if q.ProjectID != "" {
+		query += ` AND project_id IN ('', ?)`
+		args = append(args, q.ProjectID)
+	}
+	for _, word := range strings.Fields(q.Query) {
+		query += ` AND instr(lower(text), lower(?)) > 0`
+		args = append(args, word)
+	}
+	query += ` ORDER BY ` + lessonRankOrder + ` LIMIT ?`
+	args = append(args, q.Limit)
+	return queryLessons(ctx, db, query, args...)
+}
+
+// TopLessonsForBrief returns up to limit lessons for projectID and global
+// lessons (only global ones when projectID is empty) whose confidence is at
+// least LessonBriefMinConfidence, and counts the hit on each.
+func TopLessonsForBrief(ctx context.Context, db *sql.DB, projectID string, limit int) (_ []*Lesson, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if limit <= 0 {
+		return nil, nil
+	}
+	lessons, err := queryLessons(ctx, db, lessonSelect+`
+		WHERE project_id IN ('', ?) AND confidence >= ?
+		ORDER BY `+lessonRankOrder+` LIMIT ?`, projectID, LessonBriefMinConfidence, limit)
+	if err != nil || len(lessons) == 0 {
+		return lessons, err
+	}
+
+	ids := make([]any, len(lessons))
+	for i, l := range lessons {
+		ids[i] = l.ID
+	}
+	if _, err := db.ExecContext(ctx, `
+		UPDATE lessons SET hit_count = hit_count + 1, last_hit_at = CURRENT_TIMESTAMP
+		WHERE id IN (?`+strings.Repeat(", ?", len(ids)-1)+`)`, ids...); err != nil {
+		return nil, fmt.Errorf("failed to record lesson hits: %w", err)
+	}
+	return lessons, nil
+}
+
+func queryLessons(ctx context.Context, db *sql.DB, query string, args ...any) ([]*Lesson, error) {
+	var out []*Lesson
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query lessons: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		out = make([]*Lesson, 0)
+		for rows.Next() {
+			l, err := scanLesson(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan lesson: %w", err)
+			}
+			out = append(out, l)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
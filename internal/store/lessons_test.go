@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestLessons_RankAndBrief(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	add := func(req, project, text string) *Lesson {
+		c, err := AddLessonIdempotent(context.Background(), db, "agent1", req, project, text, "", nil)
+		require.NoError(t, err)
+		return c.Lesson
+	}
+	migrations := add("l1", "p1", "Run migrations before the API tests")
+	global := add("l2", "", "Keep commits small")
+	add("l3", "p2", "Other project only")
+
+	again, err := AddLessonIdempotent(context.Background(), db, "agent1", "l4", "p1", "  Run migrations before the API tests ", "", nil)
+	require.NoError(t, err)
+	assert.False(t, again.Created, "same text in the same project is not duplicated")
+	assert.Equal(t, migrations.ID, again.Lesson.ID)
+
+	promoted, err := RankLessonIdempotent(context.Background(), db, "agent1", "r1", "lesson.promote", migrations.ID, LessonConfidenceStep, false)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.7, promoted.Lesson.Confidence, 0.001)
+	require.NotNil(t, promoted.PreviousConfidence)
+	assert.InDelta(t, DefaultLessonConfidence, *promoted.PreviousConfidence, 0.001)
+
+	lessons, err := ListLessons(context.Background(), db, LessonQuery{ProjectID: "p1"})
+	require.NoError(t, err)
+	require.Len(t, lessons, 2, "project lessons plus global ones")
+	assert.Equal(t, migrations.ID, lessons[0].ID, "higher confidence ranks first")
+	assert.Equal(t, global.ID, lessons[1].ID)
+
+	found, err := ListLessons(context.Background(), db, LessonQuery{Query: "API migrations"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, migrations.ID, found[0].ID)
+
+	brief, err := BuildBriefWithOptions(context.Background(), db, "", "p1", "agent1", BriefBuildOptions{LessonLimit: 1})
+	require.NoError(t, err)
+	require.Len(t, brief.Lessons, 1, "capped by the limit")
+	assert.Equal(t, migrations.ID, brief.Lessons[0].ID)
+	lessons, err = ListLessons(context.Background(), db, LessonQuery{ProjectID: "p1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, lessons[0].HitCount, "briefs count hits")
+	assert.NotNil(t, lessons[0].LastHitAt)
+
+	// Demoted below the brief floor, a lesson stays listed but leaves briefs.
+	for i, req := range []string{"d1", "d2"} {
+		_, err := RankLessonIdempotent(context.Background(), db, "agent1", req, "lesson.demote", global.ID, -LessonConfidenceStep, false)
+		require.NoError(t, err, "demote %d", i)
+	}
+	brief, err = BuildBriefWithOptions(context.Background(), db, "", "p2", "agent1", BriefBuildOptions{LessonLimit: 5})
+	require.NoError(t, err)
+	require.Len(t, brief.Lessons, 1)
+	assert.Equal(t, "Other project only", brief.Lessons[0].Text)
+
+	// Promoting with global makes a project lesson reach every project.
+	_, err = RankLessonIdempotent(context.Background(), db, "agent1", "g1", "lesson.promote", migrations.ID, LessonConfidenceStep, true)
+	require.NoError(t, err)
+	lessons, err = ListLessons(context.Background(), db, LessonQuery{ProjectID: "p2"})
+	require.NoError(t, err)
+	assert.Len(t, lessons, 3)
+
+	var kinds []string
+	rows, err := db.Query(`SELECT DISTINCT kind FROM events WHERE kind LIKE 'lesson_%' ORDER BY kind`)
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var k string
+		require.NoError(t, rows.Scan(&k))
+		kinds = append(kinds, k)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{models.EventKindLessonAdded, models.EventKindLessonRanked}, kinds)
+}
+
+func TestLessons_FeedbackDecaysOutOfBriefs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	c, err := AddLessonIdempotent(context.Background(), db, "agent1", "add", "p1", "Always rebuild the cache", "retrospective:s1", nil)
+	require.NoError(t, err)
+	id := c.Lesson.ID
+
+	helped, err := FeedbackLessonIdempotent(context.Background(), db, "agent1", "f1", id, true, "")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6, helped.Lesson.Confidence, 0.001)
+	assert.Equal(t, 1, helped.Lesson.HelpfulCount)
+
+	var last *LessonChange
+	for _, req := range []string{"w1", "w2", "w3"} {
+		last, err = FeedbackLessonIdempotent(context.Background(), db, "agent1", req, id, false, "stale advice")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, last.Lesson.WrongCount)
+	assert.Less(t, last.Lesson.Confidence, LessonBriefMinConfidence)
+
+	lessons, err := TopLessonsForBrief(context.Background(), db, "p1", 5)
+	require.NoError(t, err)
+	assert.Empty(t, lessons, "repeatedly wrong lessons leave briefs")
+
+	var n int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM events WHERE kind = ?`, models.EventKindLessonFeedback).Scan(&n))
+	assert.Equal(t, 4, n)
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Limit counters, named after their app.LimitSettings keys.
+const (
+	LimitTasksPerDay      = "tasks_per_day"
+	LimitEventsPerSession = "events_per_session"
+	LimitLLMCallsPerDay   = "llm_calls_per_day"
+)
+
+// LimitExceededError reports that an activity limit stopped an action.
+type LimitExceededError struct {
+	Limit string
+	Used  int
+	Max   int
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s limit reached (%d/%d); raise it with vybe limits set or pass --override-limits", e.Limit, e.Used, e.Max)
+}
+
+// IsLimitExceeded reports whether err is a *LimitExceededError.
+func IsLimitExceeded(err error) bool {
+	var le *LimitExceededError
+	return errors.As(err, &le)
+}
+
+// limitDay is the UTC day a daily counter is kept under.
+func limitDay(now time.Time) string {
+	return now.UTC().Format(time.DateOnly)
+}
+
+// IncrementLimitUsageTx adds one to counter for now's UTC day.
+func IncrementLimitUsageTx(tx *sql.Tx, counter string, now time.Time) error {
+	_, err := tx.ExecContext(context.Background(), `
+		INSERT INTO limit_usage (counter, day, count, updated_at) VALUES (?, ?, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT(counter, day) DO UPDATE SET count = count + 1, updated_at = CURRENT_TIMESTAMP
+	`, counter, limitDay(now))
+	if err != nil {
+		return fmt.Errorf("failed to record %s usage: %w", counter, err)
+	}
+	return nil
+}
+
+// IncrementLimitUsage is IncrementLimitUsageTx in its own transaction.
+func IncrementLimitUsage(ctx context.Context, db *sql.DB, counter string, now time.Time) error {
+	return Transact(ctx, db, func(tx *sql.Tx) error {
+		return IncrementLimitUsageTx(tx, counter, now)
+	})
+}
+
+// LimitUsage returns counter's count for now's UTC day.
+func LimitUsage(ctx context.Context, db *sql.DB, counter string, now time.Time) (int, error) {
+	var count int
+	err := RetryWithBackoff(ctx, func() error {
+		err := db.QueryRowContext(ctx,
+			`SELECT count FROM limit_usage WHERE counter = ? AND day = ?`, counter, limitDay(now)).Scan(&count)
+		if errors.Is(err, sql.ErrNoRows) {
+			count = 0
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s usage: %w", counter, err)
+	}
+	return count, nil
+}
+
+// SessionEventCount counts events recorded for a client session (hooks store
+// it as metadata.session_id).
+func SessionEventCount(ctx context.Context, db *sql.DB, sessionID string) (int, error) {
+	var count int
+	err := RetryWithBackoff(ctx, func() error {
+		return db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM events
+			WHERE (CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.session_id') END) = ?
+		`, sessionID).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count session events: %w", err)
+	}
+	return count, nil
+}
+
+// BusiestRecentSession returns the client session with the most events
+// recorded in the last day, or "" when none recorded any.
+func BusiestRecentSession(ctx context.Context, db *sql.DB) (string, error) {
+	var sessionID string
+	err := RetryWithBackoff(ctx, func() error {
+		err := db.QueryRowContext(ctx, `
+			SELECT s FROM (
+				SELECT CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.session_id') END AS s
+				FROM events WHERE created_at >= datetime('now', '-1 day')
+			)
+			WHERE s IS NOT NULL AND s != ''
+			GROUP BY s ORDER BY COUNT(*) DESC, s LIMIT 1
+		`).Scan(&sessionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			sessionID = ""
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to find busiest session: %w", err)
+	}
+	return sessionID, nil
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// LoopLogContentType marks artifacts holding a loop iteration's captured output.
+const LoopLogContentType = "text/x-vybe-loop-log"
+
+// LoopLog is one loop iteration on a task: how the spawned command ended and
+// the artifact holding what it printed.
+type LoopLog struct {
+	EventID     int64     `json:"event_id"`
+	TaskID      string    `json:"task_id"`
+	ArtifactID  string    `json:"artifact_id"`
+	Status      string    `json:"status"` // completed, blocked, failed, timeout
+	ExitCode    int       `json:"exit_code"`
+	DurationSec float64   `json:"duration_sec"`
+	Worker      int       `json:"worker,omitempty"`
+	Bytes       int       `json:"bytes"`
+	Truncated   bool      `json:"truncated,omitempty"` // output beyond the capture limit was dropped from the start
+	CreatedAt   time.Time `json:"created_at"`
+	Output      *string   `json:"output,omitempty"` // set only when content is requested
+}
+
+// loopLogMeta is the loop_iteration event metadata; the event row supplies
+// the rest of a LoopLog.
+type loopLogMeta struct {
+	ArtifactID  string  `json:"artifact_id"`
+	Status      string  `json:"status"`
+	ExitCode    int     `json:"exit_code"`
+	DurationSec float64 `json:"duration_sec"`
+	Worker      int     `json:"worker,omitempty"`
+	Bytes       int     `json:"bytes"`
+	Truncated   bool    `json:"truncated,omitempty"`
+}
+
+// RecordLoopLogTx stores output as a loop log artifact on the task and emits a
+// loop_iteration event describing the iteration. log supplies the task and
+// outcome fields; the ids, size, and timestamp are filled in.
+func RecordLoopLogTx(tx *sql.Tx, agentName string, log LoopLog, output []byte) (*LoopLog, error) {
+	if output == nil {
+		output = []byte{}
+	}
+	filePath := fmt.Sprintf("loop/%s/%d.log", log.TaskID, time.Now().UnixMilli())
+	artifactID, _, err := AddArtifactWithContentTx(tx, agentName, log.TaskID, filePath, LoopLogContentType, output)
+	if err != nil {
+		return nil, err
+	}
+	log.ArtifactID = artifactID
+	log.Bytes = len(output)
+	log.Output = nil
+	log.CreatedAt = time.Now().UTC()
+
+	meta, err := json.Marshal(loopLogMeta{
+		ArtifactID:  log.ArtifactID,
+		Status:      log.Status,
+		ExitCode:    log.ExitCode,
+		DurationSec: log.DurationSec,
+		Worker:      log.Worker,
+		Bytes:       log.Bytes,
+		Truncated:   log.Truncated,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal loop log metadata: %w", err)
+	}
+	msg := fmt.Sprintf("Loop iteration %s (exit %d, %.0fs)", log.Status, log.ExitCode, log.DurationSec)
+	log.EventID, err = InsertEventTx(tx, models.EventKindLoopIteration, agentName, log.TaskID, msg, string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append loop iteration event: %w", err)
+	}
+	return &log, nil
+}
+
+// RecordLoopLogIdempotent performs RecordLoopLogTx once per (agent_name, request_id).
+func RecordLoopLogIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, log LoopLog, output []byte) (*LoopLog, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "loop.log", func(tx *sql.Tx) (*LoopLog, error) {
+		return RecordLoopLogTx(tx, agentName, log, output)
+	})
+}
+
+// ListLoopLogs returns a task's loop iterations, newest first.
+func ListLoopLogs(ctx context.Context, db *sql.DB, taskID string, limit int) ([]LoopLog, error) {
+	if taskID == "" {
+		return nil, errors.New("task ID is required")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var out []LoopLog
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT id, metadata, created_at FROM events
+			WHERE kind = ? AND task_id = ?
+			ORDER BY id DESC LIMIT ?
+		`, models.EventKindLoopIteration, taskID, limit)
+		if err != nil {
+			return fmt.Errorf("failed to query loop logs: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = make([]LoopLog, 0)
+		for rows.Next() {
+			var (
+				id        int64
+				meta      sql.NullString
+				createdAt time.Time
+			)
+			if err := rows.Scan(&id, &meta, &createdAt); err != nil {
+				return fmt.Errorf("failed to scan loop log: %w", err)
+			}
+			var m loopLogMeta
+			if meta.Valid {
+				_ = json.Unmarshal([]byte(meta.String), &m)
+			}
+			out = append(out, LoopLog{
+				EventID:     id,
+				TaskID:      taskID,
+				ArtifactID:  m.ArtifactID,
+				Status:      m.Status,
+				ExitCode:    m.ExitCode,
+				DurationSec: m.DurationSec,
+				Worker:      m.Worker,
+				Bytes:       m.Bytes,
+				Truncated:   m.Truncated,
+				CreatedAt:   createdAt,
+			})
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
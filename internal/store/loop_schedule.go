@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Loop schedule states.
+const (
+	LoopScheduleWaiting = "waiting" // sleeping until next_run_at
+	LoopScheduleRunning = "running" // a run is in progress
+	LoopScheduleStopped = "stopped" // the scheduled loop exited
+)
+
+// LoopSchedule is the persisted state of one agent's scheduled loop.
+type LoopSchedule struct {
+	AgentName     string     `json:"agent_name"`
+	Schedule      string     `json:"schedule,omitempty"`
+	Window        string     `json:"window,omitempty"`
+	State         string     `json:"state"`
+	PID           int        `json:"pid,omitempty"`
+	NextRunAt     *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastCompleted int        `json:"last_completed"`
+	LastFailed    int        `json:"last_failed"`
+	Runs          int        `json:"runs"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func nullableTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatDue(*t)
+}
+
+// SaveLoopSchedule upserts an agent's scheduled loop state.
+func SaveLoopSchedule(ctx context.Context, db *sql.DB, s LoopSchedule) error {
+	if s.AgentName == "" {
+		return errors.New("agent name is required")
+	}
+	return RetryWithBackoff(ctx, func() error {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO loop_schedules (agent_name, schedule, run_window, state, pid, next_run_at, last_run_at,
+				last_completed, last_failed, runs, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(agent_name) DO UPDATE SET
+				schedule = excluded.schedule,
+				run_window = excluded.run_window,
+				state = excluded.state,
+				pid = excluded.pid,
+				next_run_at = excluded.next_run_at,
+				last_run_at = excluded.last_run_at,
+				last_completed = excluded.last_completed,
+				last_failed = excluded.last_failed,
+				runs = excluded.runs,
+				updated_at = CURRENT_TIMESTAMP
+		`, s.AgentName, s.Schedule, s.Window, s.State, s.PID, nullableTime(s.NextRunAt), nullableTime(s.LastRunAt),
+			s.LastCompleted, s.LastFailed, s.Runs)
+		if err != nil {
+			return fmt.Errorf("failed to save loop schedule: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListLoopSchedules returns scheduled loop state for agentName, or for every
+// agent when agentName is empty, ordered by next run.
+func ListLoopSchedules(ctx context.Context, db *sql.DB, agentName string) ([]LoopSchedule, error) {
+	query := `
+		SELECT agent_name, schedule, run_window, state, pid, next_run_at, last_run_at,
+			last_completed, last_failed, runs, updated_at
+		FROM loop_schedules`
+	var args []any
+	if agentName != "" {
+		query += ` WHERE agent_name = ?`
+		args = append(args, agentName)
+	}
+	query += ` ORDER BY next_run_at IS NULL, next_run_at, agent_name`
+
+	var out []LoopSchedule
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query loop schedules: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = make([]LoopSchedule, 0)
+		for rows.Next() {
+			var s LoopSchedule
+			var next, last sql.NullTime
+			if err := rows.Scan(&s.AgentName, &s.Schedule, &s.Window, &s.State, &s.PID, &next, &last,
+				&s.LastCompleted, &s.LastFailed, &s.Runs, &s.UpdatedAt); err != nil {
+				return fmt.Errorf("failed to scan loop schedule: %w", err)
+			}
+			if next.Valid {
+				s.NextRunAt = &next.Time
+			}
+			if last.Valid {
+				s.LastRunAt = &last.Time
+			}
+			out = append(out, s)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // SetMemory creates or updates a memory entry with type inference and scoping.
@@ -17,12 +18,14 @@ import (
 // halfLifeDays is nil if the caller does not override decay; a stored value is then preserved.
 //
 //nolint:revive // argument-limit: all memory params (key, value, type, scope, scope_id, expires, pinned, kind, halfLifeDays) are required and distinct
-func SetMemory(db *sql.DB, key, value, valueType, scope, scopeID string, expiresAt *time.Time, pinned bool, kind string, halfLifeDays *float64) error {
-	if err := validateValueType(valueType); err != nil {
+func SetMemory(ctx context.Context, db *sql.DB, key, value, valueType, scope, scopeID string, expiresAt *time.Time, pinned bool, kind string, halfLifeDays *float64) error {
+	key, err := CanonicalMemoryKey(key)
+	if err != nil {
 		return err
 	}
-	if valueType == "" {
-		valueType = inferValueType(value)
+	value, valueType, err = normalizeMemoryValue(value, valueType)
+	if err != nil {
+		return err
 	}
 	if err := validateScope(scope, scopeID); err != nil {
 		return err
@@ -36,8 +39,12 @@ func SetMemory(db *sql.DB, key, value, valueType, scope, scopeID string, expires
 	if halfLifeDays != nil && *halfLifeDays < 0 {
 		return fmt.Errorf("half_life_days must be >= 0, got %g", *halfLifeDays)
 	}
-	return RetryWithBackoff(context.Background(), func() error {
-		_, err := db.ExecContext(context.Background(), `
+	return Transact(ctx, db, func(tx *sql.Tx) error {
+		oldValue, _, found, err := memoryValueTx(ctx, tx, key, scope, scopeID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
 			INSERT INTO memory (key, value, value_type, scope, scope_id, expires_at, pinned, kind, half_life_days, updated_at)
 			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 			ON CONFLICT(scope, scope_id, key) DO UPDATE SET
@@ -56,7 +63,7 @@ func SetMemory(db *sql.DB, key, value, valueType, scope, scopeID string, expires
 		if err != nil {
 			return fmt.Errorf("failed to set memory: %w", err)
 		}
-		return nil
+		return recordMemoryWriteTx(ctx, tx, "", key, scope, scopeID, value, valueType, oldValue, found, nil)
 	})
 }
 
@@ -67,20 +74,33 @@ func SetMemory(db *sql.DB, key, value, valueType, scope, scopeID string, expires
 // sourceEventID and sourceTaskID are optional provenance links; pass nil/"" when unknown.
 // Provenance is sticky: the first non-null write wins; subsequent upserts do not overwrite.
 //
+// Overwriting a value another agent wrote records an open memory conflict (see
+// ResolveMemoryConflictIdempotent); the new value is still written.
+//
 //nolint:revive // argument-limit: all memory params (key, value, type, scope, scope_id, expires, pinned, kind, halfLifeDays, sourceEventID, sourceTaskID) are required and distinct
 func UpsertMemoryTx(tx *sql.Tx, agentName, key, value, valueType, scope, scopeID string, expiresAt *time.Time, pinned bool, kind string, halfLifeDays *float64, sourceEventID *int64, sourceTaskID string) (int64, error) {
-	if key == "" {
-		return 0, errors.New("memory key is required")
-	}
-	if err := validateValueType(valueType); err != nil {
+	return upsertMemoryTx(tx, agentName, key, value, valueType, scope, scopeID, expiresAt, pinned, kind, halfLifeDays, sourceEventID, sourceTaskID, true)
+}
+
+// upsertMemoryTx is UpsertMemoryTx; trackConflicts false skips conflict
+// detection for writes that settle a conflict.
+//
+//nolint:revive // argument-limit: UpsertMemoryTx's params plus the conflict switch
+func upsertMemoryTx(tx *sql.Tx, agentName, key, value, valueType, scope, scopeID string, expiresAt *time.Time, pinned bool, kind string, halfLifeDays *float64, sourceEventID *int64, sourceTaskID string, trackConflicts bool) (int64, error) {
+	key, err := CanonicalMemoryKey(key)
+	if err != nil {
 		return 0, err
 	}
-	if valueType == "" {
-		valueType = inferValueType(value)
+	value, valueType, err = normalizeMemoryValue(value, valueType)
+	if err != nil {
+		return 0, err
 	}
 	if err := validateScope(scope, scopeID); err != nil {
 		return 0, err
 	}
+	if err := checkConfinedMemoryTx(tx, agentName, scope, scopeID, true); err != nil {
+		return 0, err
+	}
 	if kind == "" {
 		kind = string(models.MemoryKindFact)
 	}
@@ -90,6 +110,15 @@ func UpsertMemoryTx(tx *sql.Tx, agentName, key, value, valueType, scope, scopeID
 	if halfLifeDays != nil && *halfLifeDays < 0 {
 		return 0, fmt.Errorf("half_life_days must be >= 0, got %g", *halfLifeDays)
 	}
+	refs := []Reference{{Field: "source_task_id", Kind: RefTask, ID: sourceTaskID}}
+	// An agent may always write its own agent-scoped memory, even before its
+	// first resume creates agent state.
+	if ref, ok := MemoryScopeReference(scope, scopeID); ok && !(ref.Kind == RefAgent && scopeID == agentName) {
+		refs = append(refs, ref)
+	}
+	if err := CheckReferencesTx(tx, refs...); err != nil {
+		return 0, err
+	}
 
 	taskID := ""
 	if scope == string(models.MemoryScopeTask) {
@@ -103,21 +132,33 @@ func UpsertMemoryTx(tx *sql.Tx, agentName, key, value, valueType, scope, scopeID
 		scope, scopeID, key,
 	).Scan(&oldValue)
 
-	if oldValue.Valid && oldValue.String != value {
-		// Emit conflict event — best effort, don't fail the upsert
-		oldVal := truncateRunes(oldValue.String, 512)
-		newVal := truncateRunes(value, 512)
-		conflictMeta, _ := json.Marshal(map[string]string{
+	if trackConflicts && oldValue.Valid && oldValue.String != value {
+		conflictMeta := map[string]any{
 			"key":       key,
 			"scope":     scope,
 			"scope_id":  scopeID,
-			"old_value": oldVal,
-			"new_value": newVal,
-		})
-		_, _ = InsertEventTx(tx, models.EventKindMemoryConflict, agentName, taskID, fmt.Sprintf("Memory conflict: %s", key), string(conflictMeta))
+			"old_value": truncateRunes(oldValue.String, 512),
+			"new_value": truncateRunes(value, 512),
+		}
+		// A different agent's value is kept as an open conflict to resolve.
+		if prior := lastMemoryWriterTx(tx, key, scope, scopeID); prior != "" && agentName != "" && prior != agentName {
+			conflictID, err := insertMemoryConflictTx(tx, key, scope, scopeID, oldValue.String, prior, value, agentName, valueType)
+			if err != nil {
+				return 0, err
+			}
+			conflictMeta["conflict_id"], conflictMeta["agent_a"] = conflictID, prior
+		}
+		// Emit conflict event — best effort, don't fail the upsert
+		metaBytes, _ := json.Marshal(conflictMeta)
+		_, _ = InsertEventTx(tx, models.EventKindMemoryConflict, agentName, taskID, fmt.Sprintf("Memory conflict: %s", key), string(metaBytes))
 	}
 
-	_, err := tx.ExecContext(context.Background(), `
+	prevValue, _, prevFound, err := memoryValueTx(context.Background(), tx, key, scope, scopeID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(context.Background(), `
 		INSERT INTO memory (key, value, value_type, scope, scope_id, expires_at, pinned, kind, half_life_days, source_event_id, source_task_id, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 		ON CONFLICT(scope, scope_id, key) DO UPDATE SET
@@ -155,6 +196,9 @@ func UpsertMemoryTx(tx *sql.Tx, agentName, key, value, valueType, scope, scopeID
 	if err != nil {
 		return 0, fmt.Errorf("failed to append event: %w", err)
 	}
+	if err := recordMemoryWriteTx(context.Background(), tx, agentName, key, scope, scopeID, value, valueType, prevValue, prevFound, &eventID); err != nil {
+		return 0, err
+	}
 	return eventID, nil
 }
 
@@ -163,11 +207,11 @@ func UpsertMemoryTx(tx *sql.Tx, agentName, key, value, valueType, scope, scopeID
 // (standalone set would produce a circular reference — memory → the event that created memory).
 //
 //nolint:revive // argument-limit: all memory params are required and distinct
-func UpsertMemoryWithEventIdempotent(db *sql.DB, agentName, requestID, key, value, valueType, scope, scopeID string, expiresAt *time.Time, pinned bool, kind string, halfLifeDays *float64, sourceTaskID string) (int64, error) {
+func UpsertMemoryWithEventIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, key, value, valueType, scope, scopeID string, expiresAt *time.Time, pinned bool, kind string, halfLifeDays *float64, sourceTaskID string) (int64, error) {
 	type idemResult struct {
 		EventID int64 `json:"event_id"`
 	}
-	r, err := RunIdempotent(context.Background(), db, agentName, requestID, "memory.upsert", func(tx *sql.Tx) (idemResult, error) {
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "memory.upsert", func(tx *sql.Tx) (idemResult, error) {
 		eid, txErr := UpsertMemoryTx(tx, agentName, key, value, valueType, scope, scopeID, expiresAt, pinned, kind, halfLifeDays, nil, sourceTaskID)
 		if txErr != nil {
 			return idemResult{}, txErr
@@ -182,14 +226,15 @@ func UpsertMemoryWithEventIdempotent(db *sql.DB, agentName, requestID, key, valu
 
 // GetMemory retrieves a memory entry by key, scope, and scope_id.
 // Returns nil if not found or expired.
-func GetMemory(db *sql.DB, key, scope, scopeID string) (*models.Memory, error) {
+func GetMemory(ctx context.Context, db *sql.DB, key, scope, scopeID string) (*models.Memory, error) {
 	if err := validateScope(scope, scopeID); err != nil {
 		return nil, err
 	}
+	key = canonicalMemoryPath(key)
 	var mem models.Memory
-	err := RetryWithBackoff(context.Background(), func() error {
+	err := RetryWithBackoff(ctx, func() error {
 		var sourceTaskID sql.NullString
-		if err := db.QueryRowContext(context.Background(), `
+		if err := db.QueryRowContext(ctx, `
 			SELECT id, key, value, value_type, scope, scope_id, expires_at, updated_at, created_at, access_count, last_accessed_at, pinned, kind, half_life_days, source_event_id, source_task_id
 			FROM memory
 			WHERE key = ? AND scope = ? AND scope_id = ?
@@ -212,7 +257,7 @@ func GetMemory(db *sql.DB, key, scope, scopeID string) (*models.Memory, error) {
 	}
 
 	// Best-effort access tracking — don't fail the read if the update fails.
-	if _, err := db.ExecContext(context.Background(),
+	if _, err := db.ExecContext(ctx,
 		`UPDATE memory SET access_count = access_count + 1, last_accessed_at = CURRENT_TIMESTAMP WHERE id = ?`,
 		mem.ID,
 	); err != nil {
@@ -223,19 +268,31 @@ func GetMemory(db *sql.DB, key, scope, scopeID string) (*models.Memory, error) {
 }
 
 // ListMemory retrieves all active memory entries for a scope and scope_id, ordered by updated_at DESC.
-func ListMemory(db *sql.DB, scope, scopeID string) ([]*models.Memory, error) {
+func ListMemory(ctx context.Context, db *sql.DB, scope, scopeID string) (_ []*models.Memory, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	return ListMemoryWithPrefix(ctx, db, scope, scopeID, "")
+}
+
+// ListMemoryWithPrefix is ListMemory restricted to keys under a hierarchical
+// prefix: "build" and "build/" both match "build" and "build/commands/test",
+// but not "builder".
+func ListMemoryWithPrefix(ctx context.Context, db *sql.DB, scope, scopeID, prefix string) ([]*models.Memory, error) {
 	if err := validateScope(scope, scopeID); err != nil {
 		return nil, err
 	}
+	prefixSQL, prefixArgs := memoryPrefixClause(canonicalMemoryPath(prefix))
+	args := append([]any{scope, scopeID}, prefixArgs...)
 	var memories []*models.Memory
-	err := RetryWithBackoff(context.Background(), func() error {
-		rows, err := db.QueryContext(context.Background(), `
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
 			SELECT id, key, value, value_type, scope, scope_id, expires_at, updated_at, created_at, access_count, last_accessed_at, pinned, kind, half_life_days, source_event_id, source_task_id
 			FROM memory
-			WHERE scope = ? AND scope_id = ?
+			WHERE scope = ? AND scope_id = ? AND `+prefixSQL+`
 			AND (pinned = 1 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
 			ORDER BY updated_at DESC
-		`, scope, scopeID)
+		`, args...)
 		if err != nil {
 			return fmt.Errorf("failed to list memory: %w", err)
 		}
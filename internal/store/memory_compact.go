@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Compaction reasons: why an entry was chosen.
+const (
+	CompactReasonKeepTop = "keep_top" // ranked below the per-scope keep-top cutoff
+	CompactReasonMaxAge  = "max_age"  // not accessed or updated within max-age
+)
+
+// MemoryCompactParams selects the entries a compaction pass removes.
+// At least one of KeepTop and MaxAge must be set.
+type MemoryCompactParams struct {
+	// Scope/ScopeID restrict the pass to one scope; empty means every scope.
+	Scope   string
+	ScopeID string
+	// KeepTop keeps the N most relevant entries per (scope, scope_id), ranked
+	// as in the brief (pinned first, then half-life relevance). 0 disables.
+	KeepTop int
+	// MaxAge removes entries idle (no access or update) for longer. 0 disables.
+	MaxAge time.Duration
+	// Limit caps the entries removed in one pass, lowest relevance first.
+	Limit int
+	// Explain fills Explanation, Relevance, IdleDays, and Rank on each entry.
+	Explain bool
+}
+
+// MemoryCompactEntry is one entry a compaction pass removes, with the reasons.
+type MemoryCompactEntry struct {
+	Key         string   `json:"key"`
+	Scope       string   `json:"scope"`
+	ScopeID     string   `json:"scope_id,omitempty"`
+	Reasons     []string `json:"reasons"`
+	Kind        string   `json:"kind,omitempty"`
+	Relevance   float64  `json:"relevance,omitempty"`
+	IdleDays    float64  `json:"idle_days,omitempty"`
+	Rank        int      `json:"rank,omitempty"`
+	ScopeTotal  int      `json:"scope_total,omitempty"`
+	Explanation string   `json:"explanation,omitempty"`
+}
+
+// MemoryCompactStats summarizes a compaction pass.
+type MemoryCompactStats struct {
+	Scanned  int                  `json:"scanned"`
+	Removed  int                  `json:"removed"`
+	ByReason map[string]int       `json:"by_reason"`
+	Entries  []MemoryCompactEntry `json:"entries"`
+	EventID  int64                `json:"event_id,omitempty"`
+}
+
+// FindMemoryCompaction reports the entries CompactMemoryIdempotent would remove
+// with the same params. Pinned and expired entries are never chosen (expired
+// ones are memory gc's job).
+func FindMemoryCompaction(ctx context.Context, db *sql.DB, p MemoryCompactParams) (*MemoryCompactStats, error) {
+	var stats *MemoryCompactStats
+	err := RetryWithBackoff(ctx, func() error {
+		var err error
+		stats, err = findMemoryCompaction(ctx, db, p)
+		return err
+	})
+	return stats, err
+}
+
+// CompactMemoryIdempotent deletes the entries FindMemoryCompaction reports.
+// Each deletion is recorded in memory history, so `memory restore` can bring an
+// entry back; one memory_compacted event carries the params and counts.
+func CompactMemoryIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, p MemoryCompactParams) (*MemoryCompactStats, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.compact", func(tx *sql.Tx) (*MemoryCompactStats, error) {
+		stats, err := findMemoryCompaction(ctx, tx, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range stats.Entries {
+			if _, _, err := DeleteMemoryTx(ctx, tx, agentName, e.Key, e.Scope, e.ScopeID); err != nil {
+				return nil, err
+			}
+		}
+
+		meta, err := json.Marshal(map[string]any{
+			"scope":          p.Scope,
+			"scope_id":       p.ScopeID,
+			"keep_top":       p.KeepTop,
+			"max_age_days":   p.MaxAge.Hours() / 24,
+			"scanned":        stats.Scanned,
+			"removed":        stats.Removed,
+			"by_reason":      stats.ByReason,
+			"removed_sample": compactKeySample(stats.Entries, 20),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal compaction metadata: %w", err)
+		}
+		stats.EventID, err = InsertEventTx(tx, models.EventKindMemoryCompacted, agentName, "",
+			fmt.Sprintf("Memory compaction removed %d entries", stats.Removed), string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append memory_compacted event: %w", err)
+		}
+		return stats, nil
+	})
+}
+
+type memoryCompactQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func findMemoryCompaction(ctx context.Context, q memoryCompactQuerier, p MemoryCompactParams) (*MemoryCompactStats, error) {
+	if p.KeepTop < 0 || p.MaxAge < 0 {
+		return nil, errors.New("keep-top and max-age must not be negative")
+	}
+	if p.KeepTop == 0 && p.MaxAge == 0 {
+		return nil, errors.New("compaction needs --keep-top or --max-age")
+	}
+	if p.Scope != "" {
+		if err := validateScope(p.Scope, p.ScopeID); err != nil {
+			return nil, err
+		}
+	}
+	if p.Limit <= 0 {
+		p.Limit = 500
+	}
+
+	where := `(pinned = 1 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`
+	var args []any
+	if p.Scope != "" {
+		where += ` AND scope = ? AND scope_id = ?`
+		args = append(args, p.Scope, p.ScopeID)
+	}
+	query := `
+		SELECT key, scope, scope_id, kind, pinned, relevance, idle_days, rank, scope_total FROM (
+			SELECT key, scope, scope_id, kind, pinned,
+				` + memoryRelevanceExpr + ` AS relevance,
+				julianday('now') - julianday(COALESCE(last_accessed_at, updated_at)) AS idle_days,
+				ROW_NUMBER() OVER (PARTITION BY scope, scope_id
+					ORDER BY pinned DESC, ` + memoryRelevanceExpr + ` DESC, updated_at DESC, id DESC) AS rank,
+				COUNT(*) OVER (PARTITION BY scope, scope_id) AS scope_total
+			FROM memory
+			WHERE ` + where + `
+		)
+		ORDER BY relevance ASC, idle_days DESC, key ASC`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan memory for compaction: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	stats := &MemoryCompactStats{ByReason: map[string]int{}, Entries: []MemoryCompactEntry{}}
+	for rows.Next() {
+		var e MemoryCompactEntry
+		var pinned bool
+		if err := rows.Scan(&e.Key, &e.Scope, &e.ScopeID, &e.Kind, &pinned, &e.Relevance, &e.IdleDays, &e.Rank, &e.ScopeTotal); err != nil {
+			return nil, fmt.Errorf("failed to scan memory row: %w", err)
+		}
+		stats.Scanned++
+		if pinned || len(stats.Entries) >= p.Limit {
+			continue
+		}
+		if p.KeepTop > 0 && e.Rank > p.KeepTop {
+			e.Reasons = append(e.Reasons, CompactReasonKeepTop)
+		}
+		if p.MaxAge > 0 && e.IdleDays*24*float64(time.Hour) > float64(p.MaxAge) {
+			e.Reasons = append(e.Reasons, CompactReasonMaxAge)
+		}
+		if len(e.Reasons) == 0 {
+			continue
+		}
+		for _, r := range e.Reasons {
+			stats.ByReason[r]++
+		}
+		if p.Explain {
+			e.Explanation = explainCompaction(e, p)
+		} else {
+			e.Kind, e.Relevance, e.IdleDays, e.Rank, e.ScopeTotal = "", 0, 0, 0, 0
+		}
+		stats.Entries = append(stats.Entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan memory for compaction: %w", err)
+	}
+	stats.Removed = len(stats.Entries)
+	return stats, nil
+}
+
+func explainCompaction(e MemoryCompactEntry, p MemoryCompactParams) string {
+	s := fmt.Sprintf("%s relevance %.3f", e.Kind, e.Relevance)
+	for _, r := range e.Reasons {
+		switch r {
+		case CompactReasonKeepTop:
+			s += fmt.Sprintf("; ranked %d of %d in its scope, keep-top is %d", e.Rank, e.ScopeTotal, p.KeepTop)
+		case CompactReasonMaxAge:
+			s += fmt.Sprintf("; idle %.1f days, max-age is %.1f days", e.IdleDays, p.MaxAge.Hours()/24)
+		}
+	}
+	return s
+}
+
+func compactKeySample(entries []MemoryCompactEntry, n int) []string {
+	keys := make([]string, 0, min(n, len(entries)))
+	for i, e := range entries {
+		if i == n {
+			break
+		}
+		keys = append(keys, e.Scope+":"+e.Key)
+	}
+	return keys
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCompaction_PreviewMatchesApply(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupMemoryTestDB(t)
+	t.Cleanup(cleanup)
+
+	require.NoError(t, SetMemory(context.Background(), db, "pinned", "v", "string", "global", "", nil, true, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "fresh", "v", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "stale", "v", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "project-note", "v", "string", "project", "p1", nil, false, "", nil))
+	_, err := db.Exec(`UPDATE memory SET updated_at = ? WHERE key IN ('stale', 'pinned')`,
+		time.Now().Add(-60*24*time.Hour).UTC().Format("2006-01-02 15:04:05"))
+	require.NoError(t, err)
+
+	params := MemoryCompactParams{KeepTop: 1, MaxAge: 30 * 24 * time.Hour, Explain: true}
+	preview, err := FindMemoryCompaction(context.Background(), db, params)
+	require.NoError(t, err)
+	assert.Equal(t, 4, preview.Scanned)
+	require.Len(t, preview.Entries, 2, "pinned holds the global keep-top slot; project-note is alone in its scope")
+	assert.Equal(t, "stale", preview.Entries[0].Key, "least relevant first")
+	assert.Equal(t, []string{CompactReasonKeepTop, CompactReasonMaxAge}, preview.Entries[0].Reasons)
+	assert.Contains(t, preview.Entries[0].Explanation, "keep-top is 1")
+	assert.Equal(t, "fresh", preview.Entries[1].Key)
+	assert.Equal(t, []string{CompactReasonKeepTop}, preview.Entries[1].Reasons)
+
+	mem, err := GetMemory(context.Background(), db, "stale", "global", "")
+	require.NoError(t, err)
+	require.NotNil(t, mem, "preview deletes nothing")
+
+	applied, err := CompactMemoryIdempotent(context.Background(), db, "agent1", "compact-1", params)
+	require.NoError(t, err)
+	assert.Equal(t, 2, applied.Removed)
+	assert.NotZero(t, applied.EventID)
+
+	mem, err = GetMemory(context.Background(), db, "stale", "global", "")
+	require.NoError(t, err)
+	assert.Nil(t, mem)
+	mem, err = GetMemory(context.Background(), db, "pinned", "global", "")
+	require.NoError(t, err)
+	assert.NotNil(t, mem, "pinned entries are never compacted")
+
+	history, err := ListMemoryHistory(context.Background(), db, "stale", "global", "", 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, history, "compaction is recorded in history for restore")
+	_, err = RestoreMemoryFromHistoryIdempotent(context.Background(), db, "agent1", "restore-1", history[0].ID)
+	require.NoError(t, err)
+	mem, err = GetMemory(context.Background(), db, "stale", "global", "")
+	require.NoError(t, err)
+	assert.NotNil(t, mem)
+
+	_, err = FindMemoryCompaction(context.Background(), db, MemoryCompactParams{})
+	assert.ErrorContains(t, err, "keep-top or --max-age")
+}
@@ -0,0 +1,260 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// memoryConflictBriefLimit caps the open memory conflicts a brief lists.
+const memoryConflictBriefLimit = 5
+
+// MemoryConflictResolution reports a resolved conflict: the value now stored,
+// how many other open conflicts on the key it settled, and the resolution event.
+type MemoryConflictResolution struct {
+	Conflict   *models.MemoryConflict `json:"conflict"`
+	Value      string                 `json:"value"`
+	Superseded int64                  `json:"superseded"`
+	EventID    int64                  `json:"event_id"`
+}
+
+// lastMemoryWriterTx returns the agent behind the latest recorded write of a
+// key, or "" when it is unknown.
+func lastMemoryWriterTx(tx *sql.Tx, key, scope, scopeID string) string {
+	var agent string
+	_ = tx.QueryRowContext(context.Background(), `
+		SELECT agent_name FROM memory_history
+		WHERE scope = ? AND scope_id = ? AND key = ?
+		ORDER BY id DESC LIMIT 1
+	`, scope, scopeID, key).Scan(&agent)
+	return agent
+}
+
+//nolint:revive // argument-limit: every column of the conflict row is distinct
+func insertMemoryConflictTx(tx *sql.Tx, key, scope, scopeID, valueA, agentA, valueB, agentB, valueType string) (int64, error) {
+	res, err := tx.ExecContext(context.Background(), `
+		INSERT INTO memory_conflicts (key, scope, scope_id, value_a, agent_a, value_b, agent_b, value_type)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, key, scope, scopeID, valueA, agentA, valueB, agentB, valueType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record memory conflict: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read memory conflict id: %w", err)
+	}
+	return id, nil
+}
+
+// ListMemoryConflicts returns conflicts with the given status (all when
+// empty), newest first.
+func ListMemoryConflicts(ctx context.Context, db *sql.DB, status string, limit int) (_ []*models.MemoryConflict, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	switch status {
+	case "", models.MemoryConflictOpen, models.MemoryConflictResolved, models.MemoryConflictSuperseded:
+	default:
+		return nil, fmt.Errorf("invalid status %q (valid: open, resolved, superseded)", status)
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	return queryMemoryConflicts(ctx, db, memoryConflictSelect+` WHERE (? = '' OR status = ?) ORDER BY id DESC LIMIT ?`,
+		status, status, limit)
+}
+
+// GetMemoryConflict returns one conflict by ID.
+func GetMemoryConflict(ctx context.Context, db *sql.DB, id int64) (*models.MemoryConflict, error) {
+	var c *models.MemoryConflict
+	err := RetryWithBackoff(ctx, func() error {
+		var err error
+		c, err = scanMemoryConflict(db.QueryRowContext(ctx, memoryConflictSelect+` WHERE id = ?`, id))
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("memory conflict %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory conflict: %w", err)
+	}
+	return c, nil
+}
+
+// listBriefMemoryConflicts returns the open conflicts on memory a brief for
+// this task, project, and agent would draw from, newest first.
+func listBriefMemoryConflicts(ctx context.Context, db *sql.DB, taskID, projectID, agentName string, limit int) ([]*models.MemoryConflict, error) {
+	return queryMemoryConflicts(ctx, db, memoryConflictSelect+`
+		WHERE status = 'open' AND (scope = 'global'
+			OR (scope = 'project' AND scope_id = ?)
+			OR (scope = 'task' AND scope_id = ?)
+			OR (scope = 'agent' AND scope_id = ?))
+		ORDER BY id DESC LIMIT ?`, projectID, taskID, agentName, limit)
+}
+
+func queryMemoryConflicts(ctx context.Context, db *sql.DB, query string, args ...any) ([]*models.MemoryConflict, error) {
+	var out []*models.MemoryConflict
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = []*models.MemoryConflict{}
+		for rows.Next() {
+			c, err := scanMemoryConflict(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memory conflicts: %w", err)
+	}
+	return out, nil
+}
+
+// ResolveMemoryConflictIdempotent settles an open conflict once per
+// (agentName, requestID): keep writes value_a back, keep b keeps value_b, and
+// merge writes mergedValue. The chosen value is written through the normal
+// upsert path (kind, pin, and expiry of the stored entry are kept), and every
+// other open conflict on the same key is marked superseded.
+//
+//nolint:revive // argument-limit: conflict id, choice, and merged value are all required
+func ResolveMemoryConflictIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, id int64, keep, mergedValue string) (*MemoryConflictResolution, error) {
+	switch keep {
+	case models.MemoryConflictKeepA, models.MemoryConflictKeepB:
+	case models.MemoryConflictMerge:
+		if mergedValue == "" {
+			return nil, errors.New("merge needs the merged value")
+		}
+	default:
+		return nil, fmt.Errorf("invalid choice %q (valid: a, b, merge)", keep)
+	}
+
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.resolve", func(tx *sql.Tx) (*MemoryConflictResolution, error) {
+		c, err := getMemoryConflict(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if c.Status != models.MemoryConflictOpen {
+			return nil, fmt.Errorf("memory conflict %d is already %s", id, c.Status)
+		}
+
+		value := mergedValue
+		switch keep {
+		case models.MemoryConflictKeepA:
+			value = c.ValueA
+		case models.MemoryConflictKeepB:
+			value = c.ValueB
+		}
+
+		kind := string(models.MemoryKindFact)
+		var pinned bool
+		var expiresAt sql.NullTime
+		err = tx.QueryRowContext(ctx, `SELECT kind, pinned, expires_at FROM memory WHERE scope = ? AND scope_id = ? AND key = ?`,
+			c.Scope, c.ScopeID, c.Key).Scan(&kind, &pinned, &expiresAt)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to read memory: %w", err)
+		}
+		var expires *time.Time
+		if expiresAt.Valid {
+			expires = &expiresAt.Time
+		}
+		if _, err := upsertMemoryTx(tx, agentName, c.Key, value, c.ValueType, string(c.Scope), c.ScopeID,
+			expires, pinned, kind, nil, nil, "", false); err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE memory_conflicts SET status = ?, resolution = ?, resolved_value = ?, resolved_by = ?, resolved_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, models.MemoryConflictResolved, keep, value, agentName, id); err != nil {
+			return nil, fmt.Errorf("failed to resolve memory conflict: %w", err)
+		}
+		res, err := tx.ExecContext(ctx, `
+			UPDATE memory_conflicts SET status = ?, resolved_by = ?, resolved_at = CURRENT_TIMESTAMP
+			WHERE status = ? AND scope = ? AND scope_id = ? AND key = ? AND id != ?
+		`, models.MemoryConflictSuperseded, agentName, models.MemoryConflictOpen, c.Scope, c.ScopeID, c.Key, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to supersede memory conflicts: %w", err)
+		}
+		superseded, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if c, err = getMemoryConflict(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		meta, err := json.Marshal(map[string]any{
+			"conflict_id": id,
+			"key":         c.Key,
+			"scope":       c.Scope,
+			"scope_id":    c.ScopeID,
+			"resolution":  keep,
+			"value":       truncateRunes(value, 512),
+			"superseded":  superseded,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event metadata: %w", err)
+		}
+		taskID := ""
+		if c.Scope == models.MemoryScopeTask {
+			taskID = c.ScopeID
+		}
+		outcome := "kept " + keep
+		if keep == models.MemoryConflictMerge {
+			outcome = "merged"
+		}
+		eventID, err := InsertEventTx(tx, models.EventKindMemoryResolved, agentName, taskID,
+			fmt.Sprintf("Memory conflict resolved: %s (%s)", c.Key, outcome), string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append event: %w", err)
+		}
+		return &MemoryConflictResolution{Conflict: c, Value: value, Superseded: superseded, EventID: eventID}, nil
+	})
+}
+
+const memoryConflictSelect = `
+	SELECT id, key, scope, scope_id, value_a, agent_a, value_b, agent_b, value_type,
+		status, resolution, resolved_value, resolved_by, created_at, resolved_at
+	FROM memory_conflicts`
+
+func getMemoryConflict(ctx context.Context, tx *sql.Tx, id int64) (*models.MemoryConflict, error) {
+	c, err := scanMemoryConflict(tx.QueryRowContext(ctx, memoryConflictSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("memory conflict %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory conflict: %w", err)
+	}
+	return c, nil
+}
+
+func scanMemoryConflict(row interface{ Scan(dest ...any) error }) (*models.MemoryConflict, error) {
+	var c models.MemoryConflict
+	var resolvedValue sql.NullString
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.Key, &c.Scope, &c.ScopeID, &c.ValueA, &c.AgentA, &c.ValueB, &c.AgentB, &c.ValueType,
+		&c.Status, &c.Resolution, &resolvedValue, &c.ResolvedBy, &c.CreatedAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+	if resolvedValue.Valid {
+		c.ResolvedValue = &resolvedValue.String
+	}
+	if resolvedAt.Valid {
+		t := resolvedAt.Time.UTC()
+		c.ResolvedAt = &t
+	}
+	return &c, nil
+}
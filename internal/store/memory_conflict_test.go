@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestUpsertMemory_CrossAgentOverwriteRecordsConflict(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_1", "test_cmd", "go test ./...", "", "project", "proj_a", nil, false, "", nil, "")
+	require.NoError(t, err)
+	// Same agent rewriting its own value is an update, not a conflict.
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_2", "test_cmd", "go test ./internal/...", "", "project", "proj_a", nil, false, "", nil, "")
+	require.NoError(t, err)
+	conflicts, err := ListMemoryConflicts(context.Background(), db, models.MemoryConflictOpen, 0)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent2", "set_3", "test_cmd", "make test", "", "project", "proj_a", nil, false, "", nil, "")
+	require.NoError(t, err)
+
+	conflicts, err = ListMemoryConflicts(context.Background(), db, models.MemoryConflictOpen, 0)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	c := conflicts[0]
+	assert.Equal(t, "test_cmd", c.Key)
+	assert.Equal(t, "go test ./internal/...", c.ValueA)
+	assert.Equal(t, "agent1", c.AgentA)
+	assert.Equal(t, "make test", c.ValueB)
+	assert.Equal(t, "agent2", c.AgentB)
+
+	// The overwrite still lands.
+	mem, err := GetMemory(context.Background(), db, "test_cmd", "project", "proj_a")
+	require.NoError(t, err)
+	assert.Equal(t, "make test", mem.Value)
+
+	brief, err := BuildBrief(context.Background(), db, "", "proj_a", "agent1")
+	require.NoError(t, err)
+	require.Len(t, brief.MemoryConflicts, 1)
+	assert.Equal(t, c.ID, brief.MemoryConflicts[0].ID)
+}
+
+func TestResolveMemoryConflict_KeepAndMerge(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	set := func(agent, req, value string) {
+		t.Helper()
+		_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, agent, req, "k", value, "", "global", "", nil, true, "", nil, "")
+		require.NoError(t, err)
+	}
+	set("agent1", "set_1", "v1")
+	set("agent2", "set_2", "v2")
+	set("agent1", "set_3", "v3")
+
+	conflicts, err := ListMemoryConflicts(context.Background(), db, models.MemoryConflictOpen, 0)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 2)
+	latest, older := conflicts[0], conflicts[1]
+
+	_, err = ResolveMemoryConflictIdempotent(context.Background(), db, "agent3", "resolve_bad", latest.ID, models.MemoryConflictMerge, "")
+	require.Error(t, err)
+
+	res, err := ResolveMemoryConflictIdempotent(context.Background(), db, "agent3", "resolve_1", latest.ID, models.MemoryConflictKeepA, "")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", res.Value)
+	assert.Equal(t, int64(1), res.Superseded)
+	assert.Equal(t, models.MemoryConflictResolved, res.Conflict.Status)
+	require.NotNil(t, res.Conflict.ResolvedAt)
+
+	mem, err := GetMemory(context.Background(), db, "k", "global", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", mem.Value)
+	assert.True(t, mem.Pinned, "resolution keeps the entry's pin")
+
+	// Resolving does not itself open a new conflict, and the older one is settled.
+	open, err := ListMemoryConflicts(context.Background(), db, models.MemoryConflictOpen, 0)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+	_, err = ResolveMemoryConflictIdempotent(context.Background(), db, "agent3", "resolve_2", older.ID, models.MemoryConflictKeepB, "")
+	require.ErrorContains(t, err, "superseded")
+
+	// Replaying the request returns the stored result.
+	replay, err := ResolveMemoryConflictIdempotent(context.Background(), db, "agent3", "resolve_1", latest.ID, models.MemoryConflictKeepA, "")
+	require.NoError(t, err)
+	assert.Equal(t, res.EventID, replay.EventID)
+
+	set("agent1", "set_4", "v4")
+	conflicts, err = ListMemoryConflicts(context.Background(), db, models.MemoryConflictOpen, 0)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	res, err = ResolveMemoryConflictIdempotent(context.Background(), db, "agent1", "resolve_3", conflicts[0].ID, models.MemoryConflictMerge, "v2+v4")
+	require.NoError(t, err)
+	assert.Equal(t, "v2+v4", res.Value)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM events WHERE kind = ?`, models.EventKindMemoryResolved).Scan(&count))
+	assert.Equal(t, 2, count)
+}
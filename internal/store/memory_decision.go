@@ -0,0 +1,133 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// decisionBriefLimit caps the recent decisions a brief carries.
+const decisionBriefLimit = 5
+
+// ParseDecisionRecord decodes and validates a decision record value: a JSON
+// object with a non-empty decision and optional context, alternatives (an
+// array of strings), and consequences. Unknown fields are rejected so a typo
+// does not silently drop part of the record.
+func ParseDecisionRecord(value string) (*models.DecisionRecord, error) {
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.DisallowUnknownFields()
+	var rec models.DecisionRecord
+	if err := dec.Decode(&rec); err != nil {
+		return nil, fmt.Errorf("invalid decision record (want a JSON object with context, decision, alternatives, consequences): %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("invalid decision record: trailing data after the JSON object")
+	}
+	rec.Context = strings.TrimSpace(rec.Context)
+	rec.Decision = strings.TrimSpace(rec.Decision)
+	rec.Consequences = strings.TrimSpace(rec.Consequences)
+	if rec.Decision == "" {
+		return nil, errors.New("invalid decision record: decision is required")
+	}
+	alternatives := rec.Alternatives[:0]
+	for _, a := range rec.Alternatives {
+		if a = strings.TrimSpace(a); a != "" {
+			alternatives = append(alternatives, a)
+		}
+	}
+	rec.Alternatives = alternatives
+	return &rec, nil
+}
+
+// normalizeDecisionValue validates a decision record value and returns it
+// re-encoded in canonical form.
+func normalizeDecisionValue(value string) (string, error) {
+	rec, err := ParseDecisionRecord(value)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(rec); err != nil {
+		return "", fmt.Errorf("failed to encode decision record: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// ListDecisions returns live decision records, most recently updated first.
+// With a project ID it lists global decisions plus that project's and its
+// tasks'; without one it lists every decision.
+func ListDecisions(ctx context.Context, db *sql.DB, projectID string, limit int) (_ []*models.Decision, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if limit <= 0 {
+		limit = 50
+	}
+	if projectID == "" {
+		return queryDecisions(ctx, db, `1 = 1`, []any{}, limit)
+	}
+	return queryDecisions(ctx, db, `scope = 'global'
+		OR (scope = 'project' AND scope_id = ?)
+		OR (scope = 'task' AND scope_id IN (SELECT id FROM tasks WHERE project_id = ?))`,
+		[]any{projectID, projectID}, limit)
+}
+
+// listBriefDecisions returns the recent decisions a brief for this task and
+// project draws on: global, the project's, and the task's.
+func listBriefDecisions(ctx context.Context, db *sql.DB, taskID, projectID string, limit int) ([]*models.Decision, error) {
+	return queryDecisions(ctx, db, `scope = 'global'
+		OR (scope = 'project' AND scope_id = ?)
+		OR (scope = 'task' AND scope_id = ?)`,
+		[]any{projectID, taskID}, limit)
+}
+
+func queryDecisions(ctx context.Context, db *sql.DB, scopes string, args []any, limit int) ([]*models.Decision, error) {
+	query := `
+		SELECT key, value, scope, scope_id, pinned, updated_at, created_at
+		FROM memory
+		WHERE value_type = ? AND (` + scopes + `)
+		AND (pinned = 1 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+		ORDER BY updated_at DESC, id DESC
+		LIMIT ?`
+	args = append(append([]any{models.MemoryValueTypeDecision}, args...), limit)
+
+	var out []*models.Decision
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = []*models.Decision{}
+		for rows.Next() {
+			var d models.Decision
+			var value string
+			if err := rows.Scan(&d.Key, &value, &d.Scope, &d.ScopeID, &d.Pinned, &d.UpdatedAt, &d.CreatedAt); err != nil {
+				return err
+			}
+			// Values are validated on write; a row that no longer parses
+			// (edited by hand) still lists with its raw value as the decision.
+			if rec, pErr := ParseDecisionRecord(value); pErr == nil {
+				d.DecisionRecord = *rec
+			} else {
+				d.Decision = value
+			}
+			out = append(out, &d)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+	return out, nil
+}
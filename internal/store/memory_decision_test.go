@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestParseDecisionRecord(t *testing.T) {
+	rec, err := ParseDecisionRecord(`{"decision":" Use SQLite ","alternatives":["Postgres"," "],"context":"CLI"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Use SQLite", rec.Decision)
+	assert.Equal(t, []string{"Postgres"}, rec.Alternatives)
+	assert.Equal(t, "CLI", rec.Context)
+
+	for name, value := range map[string]string{
+		"missing decision":    `{"context":"x"}`,
+		"unknown field":       `{"decision":"x","rationale":"y"}`,
+		"alternatives string": `{"decision":"x","alternatives":"y"}`,
+		"not json":            `use sqlite`,
+		"trailing data":       `{"decision":"x"} {}`,
+	} {
+		_, err := ParseDecisionRecord(value)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestDecisionMemory_ValidatedAndListed(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_bad", "decisions/db", `{"context":"no decision"}`, models.MemoryValueTypeDecision, "global", "", nil, false, "", nil, "")
+	require.ErrorContains(t, err, "decision is required")
+
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_1", "decisions/db", `{"decision": "Use SQLite", "alternatives": ["Postgres"]}`, models.MemoryValueTypeDecision, "global", "", nil, false, "", nil, "")
+	require.NoError(t, err)
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_2", "decisions/api", `{"decision":"REST over gRPC"}`, models.MemoryValueTypeDecision, "project", "proj_a", nil, false, "", nil, "")
+	require.NoError(t, err)
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_3", "decisions/ui", `{"decision":"HTMX"}`, models.MemoryValueTypeDecision, "project", "proj_b", nil, false, "", nil, "")
+	require.NoError(t, err)
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_4", "build/go", "1.26", "", "global", "", nil, false, "", nil, "")
+	require.NoError(t, err)
+
+	mem, err := GetMemory(context.Background(), db, "decisions/db", "global", "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"decision":"Use SQLite","alternatives":["Postgres"]}`, mem.Value)
+
+	all, err := ListDecisions(context.Background(), db, "", 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	projA, err := ListDecisions(context.Background(), db, "proj_a", 0)
+	require.NoError(t, err)
+	keys := make([]string, len(projA))
+	for i, d := range projA {
+		keys[i] = d.Key
+	}
+	assert.ElementsMatch(t, []string{"decisions/db", "decisions/api"}, keys)
+
+	brief, err := BuildBrief(context.Background(), db, "", "proj_a", "agent1")
+	require.NoError(t, err)
+	require.Len(t, brief.Decisions, 2)
+	for _, d := range brief.Decisions {
+		if d.Key == "decisions/db" {
+			assert.Equal(t, "Use SQLite", d.Decision)
+			assert.Equal(t, []string{"Postgres"}, d.Alternatives)
+		}
+	}
+}
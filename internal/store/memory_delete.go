@@ -6,25 +6,52 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
 )
 
+// GCOptions bounds a GC run. IdempotencyTTLDays > 0 also prunes idempotency
+// records older than that many days (see PruneIdempotencyTx); 0 leaves them.
+type GCOptions struct {
+	Limit              int
+	IdempotencyTTLDays int
+}
+
+// GCResult is the outcome of a GC run. IdempotencyDeleted counts pruned
+// idempotency records.
+type GCResult struct {
+	EventID            int64          `json:"event_id"`
+	Deleted            int            `json:"deleted"`
+	IdempotencyDeleted int64          `json:"idempotency_deleted"`
+	LeasesReclaimed    []ExpiredLease `json:"leases_reclaimed"`
+}
+
 // GCMemoryWithEventIdempotent removes expired memory entries, emitting a gc event.
 // Pinned entries are never deleted regardless of expires_at.
 // Idempotent per (agentName, requestID).
-func GCMemoryWithEventIdempotent(db *sql.DB, agentName, requestID string, limit int) (int64, int, error) {
-	if limit <= 0 {
-		limit = 100
+func GCMemoryWithEventIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, limit int) (int64, int, error) {
+	r, err := GCWithEventIdempotent(ctx, db, agentName, requestID, GCOptions{Limit: limit})
+	if err != nil {
+		return 0, 0, err
 	}
+	return r.EventID, r.Deleted, nil
+}
 
-	type idemResult struct {
-		EventID int64 `json:"event_id"`
-		Deleted int   `json:"deleted"`
+// GCWithEventIdempotent removes expired memory entries and, when
+// opts.IdempotencyTTLDays is set, stale idempotency records, emitting one
+// memory_gc event with both counts. It also returns tasks whose claim lease
+// ran out to pending (see ReclaimExpiredLeasesTx). Each table gives up at most
+// opts.Limit rows. Idempotent per (agentName, requestID).
+func GCWithEventIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, opts GCOptions) (*GCResult, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 100
 	}
 
-	r, err := RunIdempotent(context.Background(), db, agentName, requestID, "memory.gc", func(tx *sql.Tx) (idemResult, error) {
-		result, err := tx.ExecContext(context.Background(), `
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.gc", func(tx *sql.Tx) (*GCResult, error) {
+		result, err := tx.ExecContext(ctx, `
 			DELETE FROM memory WHERE id IN (
 				SELECT id FROM memory
 				WHERE pinned = 0
@@ -33,34 +60,58 @@ func GCMemoryWithEventIdempotent(db *sql.DB, agentName, requestID string, limit
 			)
 		`, limit)
 		if err != nil {
-			return idemResult{}, fmt.Errorf("failed to gc memory: %w", err)
+			return nil, fmt.Errorf("failed to gc memory: %w", err)
 		}
 		deleted, err := result.RowsAffected()
 		if err != nil {
-			return idemResult{}, fmt.Errorf("failed to check rows affected: %w", err)
+			return nil, fmt.Errorf("failed to check rows affected: %w", err)
+		}
+
+		metaObj := map[string]any{"deleted": deleted, "limit": limit}
+		msg := fmt.Sprintf("Memory GC deleted %d rows", deleted)
+		var idemDeleted int64
+		if opts.IdempotencyTTLDays > 0 {
+			idemDeleted, err = PruneIdempotencyTx(tx, opts.IdempotencyTTLDays, limit)
+			if err != nil {
+				return nil, err
+			}
+			metaObj["idempotency_deleted"] = idemDeleted
+			metaObj["idempotency_ttl_days"] = max(opts.IdempotencyTTLDays, app.MinIdempotencyTTLDays)
+			msg += fmt.Sprintf(", pruned %d idempotency records", idemDeleted)
 		}
 
-		meta, _ := json.Marshal(map[string]any{"deleted": deleted, "limit": limit})
-		eventID, err := InsertEventTx(tx, models.EventKindMemoryGC, agentName, "", fmt.Sprintf("Memory GC deleted %d rows", deleted), string(meta))
+		reclaimed, err := ReclaimExpiredLeasesTx(tx, agentName, time.Now(), limit)
 		if err != nil {
-			return idemResult{}, fmt.Errorf("failed to append memory_gc event: %w", err)
+			return nil, err
+		}
+		if len(reclaimed) > 0 {
+			metaObj["leases_reclaimed"] = len(reclaimed)
+			msg += fmt.Sprintf(", reclaimed %d expired task leases", len(reclaimed))
 		}
-		return idemResult{EventID: eventID, Deleted: int(deleted)}, nil
+
+		meta, _ := json.Marshal(metaObj)
+		eventID, err := InsertEventTx(tx, models.EventKindMemoryGC, agentName, "", msg, string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append memory_gc event: %w", err)
+		}
+		return &GCResult{EventID: eventID, Deleted: int(deleted), IdempotencyDeleted: idemDeleted, LeasesReclaimed: reclaimed}, nil
 	})
-	if err != nil {
-		return 0, 0, err
-	}
-	return r.EventID, r.Deleted, nil
 }
 
 // DeleteMemoryTx deletes a memory entry and appends an event within an existing transaction.
 // Returns (eventID, found, error). found is false when no row matched the key/scope/scopeID.
 func DeleteMemoryTx(ctx context.Context, tx *sql.Tx, agentName, key, scope, scopeID string) (int64, bool, error) {
+	key = canonicalMemoryPath(key)
 	taskID := ""
 	if scope == "task" {
 		taskID = scopeID
 	}
 
+	oldValue, valueType, _, err := memoryValueTx(ctx, tx, key, scope, scopeID)
+	if err != nil {
+		return 0, false, err
+	}
+
 	result, err := tx.ExecContext(ctx, `DELETE FROM memory WHERE key = ? AND scope = ? AND scope_id = ?`, key, scope, scopeID)
 	if err != nil {
 		return 0, false, fmt.Errorf("failed to delete memory: %w", err)
@@ -89,6 +140,10 @@ func DeleteMemoryTx(ctx context.Context, tx *sql.Tx, agentName, key, scope, scop
 	if err != nil {
 		return 0, false, fmt.Errorf("failed to append event: %w", err)
 	}
+	if err := recordMemoryHistoryTx(ctx, tx, agentName, key, scope, scopeID, models.MemoryChangeDeleted,
+		oldValue, sql.NullString{}, valueType, &eventID); err != nil {
+		return 0, false, err
+	}
 	return eventID, true, nil
 }
 
@@ -144,3 +199,63 @@ func DeleteMemoryWithEventIdempotent(ctx context.Context, db *sql.DB, agentName,
 	}
 	return r.EventID, nil
 }
+
+// DeleteMemoryMatchingResult reports the entries removed by a wildcard delete.
+type DeleteMemoryMatchingResult struct {
+	Keys     []string `json:"keys"`
+	EventIDs []int64  `json:"event_ids"`
+}
+
+// DeleteMemoryMatchingIdempotent deletes every entry in scope/scopeID whose key
+// matches pattern (see compileMemoryKeyPattern), appending one memory_delete
+// event per entry. Matching nothing is not an error.
+//
+//nolint:revive // argument-limit: all params (agent, req, pattern, scope, scope_id) are required
+func DeleteMemoryMatchingIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, pattern, scope, scopeID string) (*DeleteMemoryMatchingResult, error) {
+	if err := validateScope(scope, scopeID); err != nil {
+		return nil, err
+	}
+	pat, err := compileMemoryKeyPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.delete_matching", func(tx *sql.Tx) (*DeleteMemoryMatchingResult, error) {
+		prefixSQL, prefixArgs := memoryPrefixClause(pat.literalPrefix())
+		rows, err := tx.QueryContext(ctx, `
+			SELECT key FROM memory WHERE scope = ? AND scope_id = ? AND `+prefixSQL+`
+			ORDER BY key
+		`, append([]any{scope, scopeID}, prefixArgs...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list memory keys: %w", err)
+		}
+		var keys []string
+		for rows.Next() {
+			var k string
+			if err := rows.Scan(&k); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("failed to scan memory key: %w", err)
+			}
+			if pat.match(k) {
+				keys = append(keys, k)
+			}
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to list memory keys: %w", err)
+		}
+
+		out := &DeleteMemoryMatchingResult{Keys: []string{}, EventIDs: []int64{}}
+		for _, k := range keys {
+			eid, found, err := DeleteMemoryTx(ctx, tx, agentName, k, scope, scopeID)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				out.Keys = append(out.Keys, k)
+				out.EventIDs = append(out.EventIDs, eid)
+			}
+		}
+		return out, nil
+	})
+}
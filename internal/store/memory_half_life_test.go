@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"testing"
 
 	"github.com/dotcommander/vybe/internal/models"
@@ -19,11 +20,11 @@ func TestMemoryHalfLife_KindDefaults(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	t.Cleanup(cleanup)
 
-	require.NoError(t, SetMemory(db, "lesson-key", "insight", "string", "global", "", nil, false, "lesson", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "lesson-key", "insight", "string", "global", "", nil, false, "lesson", nil))
 	_, err := db.Exec(`UPDATE memory SET last_accessed_at = datetime('now', '-14 days') WHERE key = 'lesson-key'`)
 	require.NoError(t, err)
 
-	mems, err := fetchRelevantMemory(db, "", "")
+	mems, err := fetchRelevantMemory(context.Background(), db, "", "")
 	require.NoError(t, err)
 
 	var found *models.Memory
@@ -45,11 +46,11 @@ func TestMemoryHalfLife_DirectiveNoDecay(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	t.Cleanup(cleanup)
 
-	require.NoError(t, SetMemory(db, "dir-key", "never do X", "string", "global", "", nil, false, "directive", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "dir-key", "never do X", "string", "global", "", nil, false, "directive", nil))
 	_, err := db.Exec(`UPDATE memory SET last_accessed_at = datetime('now', '-365 days') WHERE key = 'dir-key'`)
 	require.NoError(t, err)
 
-	mems, err := fetchRelevantMemory(db, "", "")
+	mems, err := fetchRelevantMemory(context.Background(), db, "", "")
 	require.NoError(t, err)
 
 	var found *models.Memory
@@ -71,11 +72,11 @@ func TestMemoryHalfLife_ExplicitOverride(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	t.Cleanup(cleanup)
 
-	require.NoError(t, SetMemory(db, "fast-decay", "value", "string", "global", "", nil, false, "fact", ptr(1.0)))
+	require.NoError(t, SetMemory(context.Background(), db, "fast-decay", "value", "string", "global", "", nil, false, "fact", ptr(1.0)))
 	_, err := db.Exec(`UPDATE memory SET last_accessed_at = datetime('now', '-1 days') WHERE key = 'fast-decay'`)
 	require.NoError(t, err)
 
-	mems, err := fetchRelevantMemory(db, "", "")
+	mems, err := fetchRelevantMemory(context.Background(), db, "", "")
 	require.NoError(t, err)
 
 	var found *models.Memory
@@ -96,11 +97,11 @@ func TestMemoryHalfLife_StickyPreserve(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	t.Cleanup(cleanup)
 
-	_, err := UpsertMemoryWithEventIdempotent(db, "agent", "req-hl-sticky-1", "sticky-key", "v1", "string", "global", "", nil, false, "lesson", ptr(7.0), "")
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent", "req-hl-sticky-1", "sticky-key", "v1", "string", "global", "", nil, false, "lesson", ptr(7.0), "")
 	require.NoError(t, err)
 
 	// Second upsert without half_life — nil must not clobber stored value
-	_, err = UpsertMemoryWithEventIdempotent(db, "agent", "req-hl-sticky-2", "sticky-key", "v2", "string", "global", "", nil, false, "lesson", nil, "")
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent", "req-hl-sticky-2", "sticky-key", "v2", "string", "global", "", nil, false, "lesson", nil, "")
 	require.NoError(t, err)
 
 	var storedHL *float64
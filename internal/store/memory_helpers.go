@@ -73,6 +73,23 @@ func validateValueType(valueType string) error {
 	return nil
 }
 
+// normalizeMemoryValue validates a memory value type, inferring it when empty.
+// Decision records are memory-only: their value is checked and stored in
+// canonical JSON form.
+func normalizeMemoryValue(value, valueType string) (string, string, error) {
+	if valueType == models.MemoryValueTypeDecision {
+		value, err := normalizeDecisionValue(value)
+		return value, valueType, err
+	}
+	if valueType != "" && !isValidValueType(valueType) {
+		return "", "", fmt.Errorf("invalid value_type: %q (must be one of: string, number, boolean, json, array, decision)", valueType)
+	}
+	if valueType == "" {
+		valueType = inferValueType(value)
+	}
+	return value, valueType, nil
+}
+
 // truncateRunes truncates s to at most maxRunes runes, appending "…" if truncated.
 func truncateRunes(s string, maxRunes int) string {
 	runes := []rune(s)
@@ -0,0 +1,214 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// memoryValueTx reads the stored value of a key regardless of expiry, so the
+// history sees the value actually being replaced or removed.
+func memoryValueTx(ctx context.Context, tx *sql.Tx, key, scope, scopeID string) (value sql.NullString, valueType string, found bool, err error) {
+	err = tx.QueryRowContext(ctx,
+		`SELECT value, value_type FROM memory WHERE scope = ? AND scope_id = ? AND key = ?`,
+		scope, scopeID, key,
+	).Scan(&value, &valueType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sql.NullString{}, "", false, nil
+	}
+	if err != nil {
+		return sql.NullString{}, "", false, fmt.Errorf("failed to read memory value: %w", err)
+	}
+	return value, valueType, true, nil
+}
+
+// recordMemoryHistoryTx appends one row to the memory timeline. eventID is the
+// event that caused the change, or nil for writes that emit none.
+//
+//nolint:revive // argument-limit: every column of the history row is distinct
+func recordMemoryHistoryTx(ctx context.Context, tx *sql.Tx, agentName, key, scope, scopeID, change string, oldValue, newValue sql.NullString, valueType string, eventID *int64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO memory_history (key, scope, scope_id, change, old_value, new_value, value_type, agent_name, event_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, key, scope, scopeID, change, oldValue, newValue, valueType, agentName, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to record memory history: %w", err)
+	}
+	return nil
+}
+
+// recordMemoryWriteTx records a created or updated row for a write of value
+// over the previous state (old, found). Writes that leave the value unchanged
+// are not recorded.
+//
+//nolint:revive // argument-limit: every column of the history row is distinct
+func recordMemoryWriteTx(ctx context.Context, tx *sql.Tx, agentName, key, scope, scopeID, value, valueType string, old sql.NullString, found bool, eventID *int64) error {
+	change := models.MemoryChangeCreated
+	if found {
+		if old.Valid && old.String == value {
+			return nil
+		}
+		change = models.MemoryChangeUpdated
+	}
+	return recordMemoryHistoryTx(ctx, tx, agentName, key, scope, scopeID, change,
+		old, sql.NullString{String: value, Valid: true}, valueType, eventID)
+}
+
+// ListMemoryHistory returns the value timeline of one key, newest first,
+// including changes made before the key was deleted.
+func ListMemoryHistory(ctx context.Context, db *sql.DB, key, scope, scopeID string, limit int) ([]*models.MemoryHistoryEntry, error) {
+	if err := validateScope(scope, scopeID); err != nil {
+		return nil, err
+	}
+	key = canonicalMemoryPath(key)
+	if key == "" {
+		return nil, errors.New("memory key is required")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var entries []*models.MemoryHistoryEntry
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT id, key, scope, scope_id, change, old_value, new_value, value_type, agent_name, event_id, created_at
+			FROM memory_history
+			WHERE scope = ? AND scope_id = ? AND key = ?
+			ORDER BY id DESC
+			LIMIT ?
+		`, scope, scopeID, key, limit)
+		if err != nil {
+			return fmt.Errorf("failed to list memory history: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		entries = make([]*models.MemoryHistoryEntry, 0)
+		for rows.Next() {
+			e, err := scanMemoryHistory(rows)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, e)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetMemoryHistoryEntry returns one history row by ID, or nil if not found.
+func GetMemoryHistoryEntry(ctx context.Context, db *sql.DB, id int64) (*models.MemoryHistoryEntry, error) {
+	var entry *models.MemoryHistoryEntry
+	err := RetryWithBackoff(ctx, func() error {
+		var err error
+		entry, err = getMemoryHistoryEntry(ctx, db, id)
+		return err
+	})
+	return entry, err
+}
+
+type memoryHistoryQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func getMemoryHistoryEntry(ctx context.Context, q memoryHistoryQuerier, id int64) (*models.MemoryHistoryEntry, error) {
+	e, err := scanMemoryHistory(q.QueryRowContext(ctx, `
+		SELECT id, key, scope, scope_id, change, old_value, new_value, value_type, agent_name, event_id, created_at
+		FROM memory_history WHERE id = ?
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return e, err
+}
+
+func scanMemoryHistory(row interface{ Scan(dest ...any) error }) (*models.MemoryHistoryEntry, error) {
+	var (
+		e        models.MemoryHistoryEntry
+		oldValue sql.NullString
+		newValue sql.NullString
+		eventID  sql.NullInt64
+	)
+	if err := row.Scan(&e.ID, &e.Key, &e.Scope, &e.ScopeID, &e.Change, &oldValue, &newValue,
+		&e.ValueType, &e.AgentName, &eventID, &e.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan memory history: %w", err)
+	}
+	if oldValue.Valid {
+		e.OldValue = &oldValue.String
+	}
+	if newValue.Valid {
+		e.NewValue = &newValue.String
+	}
+	if eventID.Valid {
+		e.EventID = &eventID.Int64
+	}
+	return &e, nil
+}
+
+// MemoryRestoreResult reports a value restored from the memory timeline.
+type MemoryRestoreResult struct {
+	Key         string `json:"key"`
+	Scope       string `json:"scope"`
+	ScopeID     string `json:"scope_id,omitempty"`
+	Value       string `json:"value"`
+	FromHistory int64  `json:"from_history_id"`
+	EventID     int64  `json:"event_id"`
+}
+
+// RestoreMemoryFromHistoryIdempotent writes a past value back through the
+// normal upsert path, so the restore itself appears in events and history.
+// For created/updated entries the entry's new value is restored; for deleted
+// entries, the value that was deleted. The key's current kind and unexpired
+// expiry are preserved.
+func RestoreMemoryFromHistoryIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, historyID int64) (*MemoryRestoreResult, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.restore", func(tx *sql.Tx) (*MemoryRestoreResult, error) {
+		entry, err := getMemoryHistoryEntry(ctx, tx, historyID)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			return nil, fmt.Errorf("memory history entry %d not found", historyID)
+		}
+		value := entry.NewValue
+		if entry.Change == models.MemoryChangeDeleted {
+			value = entry.OldValue
+		}
+		if value == nil {
+			return nil, fmt.Errorf("memory history entry %d has no value to restore", historyID)
+		}
+
+		kind := string(models.MemoryKindFact)
+		var expiresAt *time.Time
+		err = tx.QueryRowContext(ctx, `
+			SELECT kind, expires_at FROM memory
+			WHERE scope = ? AND scope_id = ? AND key = ?
+			AND (pinned = 1 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+		`, entry.Scope, entry.ScopeID, entry.Key).Scan(&kind, &expiresAt)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to read current memory: %w", err)
+		}
+
+		eventID, err := UpsertMemoryTx(tx, agentName, entry.Key, *value, entry.ValueType, string(entry.Scope), entry.ScopeID,
+			expiresAt, false, kind, nil, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		return &MemoryRestoreResult{
+			Key:         entry.Key,
+			Scope:       string(entry.Scope),
+			ScopeID:     entry.ScopeID,
+			Value:       *value,
+			FromHistory: historyID,
+			EventID:     eventID,
+		}, nil
+	})
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestMemoryHistory_RecordsChangesAndRestores(t *testing.T) {
+	db, cleanup := setupMemoryTestDB(t)
+	defer cleanup()
+
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set-1", "go_version", "1.22", "string", "global", "", nil, false, "", nil, "")
+	require.NoError(t, err)
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set-2", "go_version", "1.22", "string", "global", "", nil, false, "", nil, "")
+	require.NoError(t, err)
+	wrongEventID, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent2", "set-3", "go_version", "1.9", "string", "global", "", nil, false, "", nil, "")
+	require.NoError(t, err)
+	_, err = DeleteMemoryWithEventIdempotent(context.Background(), db, "agent2", "del-1", "go_version", "global", "")
+	require.NoError(t, err)
+
+	history, err := ListMemoryHistory(context.Background(), db, "go_version", "global", "", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 3, "an unchanged rewrite is not a change")
+
+	assert.Equal(t, models.MemoryChangeDeleted, history[0].Change)
+	require.NotNil(t, history[0].OldValue)
+	assert.Equal(t, "1.9", *history[0].OldValue)
+	assert.Nil(t, history[0].NewValue)
+
+	assert.Equal(t, models.MemoryChangeUpdated, history[1].Change)
+	assert.Equal(t, "1.22", *history[1].OldValue)
+	assert.Equal(t, "1.9", *history[1].NewValue)
+	assert.Equal(t, "agent2", history[1].AgentName)
+	require.NotNil(t, history[1].EventID)
+	assert.Equal(t, wrongEventID, *history[1].EventID)
+
+	assert.Equal(t, models.MemoryChangeCreated, history[2].Change)
+	assert.Nil(t, history[2].OldValue)
+
+	// Recover the value that was overwritten.
+	res, err := RestoreMemoryFromHistoryIdempotent(context.Background(), db, "agent1", "restore-1", history[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "1.22", res.Value)
+
+	mem, err := GetMemory(context.Background(), db, "go_version", "global", "")
+	require.NoError(t, err)
+	require.NotNil(t, mem)
+	assert.Equal(t, "1.22", mem.Value)
+
+	history, err = ListMemoryHistory(context.Background(), db, "go_version", "global", "", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	assert.Equal(t, models.MemoryChangeCreated, history[0].Change, "restore after delete recreates the key")
+
+	_, err = RestoreMemoryFromHistoryIdempotent(context.Background(), db, "agent1", "restore-2", 9999)
+	require.Error(t, err)
+}
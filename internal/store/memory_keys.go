@@ -0,0 +1,121 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// MemoryKeySeparator separates the segments of a hierarchical memory key
+// (build/commands/test). Flat keys are single-segment keys.
+const MemoryKeySeparator = "/"
+
+// memoryKeyDeepWildcard, as the last segment of a delete pattern, matches any
+// number of descendant segments.
+const memoryKeyDeepWildcard = "**"
+
+// CanonicalMemoryKey normalizes a memory key while preserving its hierarchy:
+// surrounding whitespace is trimmed from the key and from every segment,
+// empty segments (leading, trailing, or doubled separators) are dropped.
+// Case is preserved. Wildcard characters are reserved for delete patterns.
+func CanonicalMemoryKey(key string) (string, error) {
+	canon := canonicalMemoryPath(key)
+	if canon == "" {
+		return "", errors.New("memory key is required")
+	}
+	if strings.ContainsAny(canon, "*?[") {
+		return "", fmt.Errorf("memory key %q contains wildcard characters (*, ?, [); these are reserved for delete patterns", key)
+	}
+	return canon, nil
+}
+
+// canonicalMemoryPath applies the segment rules of CanonicalMemoryKey without
+// rejecting wildcards, so lookups and patterns normalize the same way.
+func canonicalMemoryPath(key string) string {
+	parts := strings.Split(strings.TrimSpace(key), MemoryKeySeparator)
+	segs := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			segs = append(segs, p)
+		}
+	}
+	return strings.Join(segs, MemoryKeySeparator)
+}
+
+// IsMemoryKeyPattern reports whether key contains wildcard characters.
+func IsMemoryKeyPattern(key string) bool {
+	return strings.ContainsAny(key, "*?[")
+}
+
+// memoryKeyPattern matches hierarchical keys. Wildcards (*, ?, [...]) match
+// within a single segment; a final "**" segment matches every descendant.
+type memoryKeyPattern struct {
+	segs []string
+	deep bool
+}
+
+func compileMemoryKeyPattern(raw string) (memoryKeyPattern, error) {
+	canon := canonicalMemoryPath(raw)
+	if canon == "" {
+		return memoryKeyPattern{}, errors.New("memory key pattern is required")
+	}
+	p := memoryKeyPattern{segs: strings.Split(canon, MemoryKeySeparator)}
+	if last := p.segs[len(p.segs)-1]; last == memoryKeyDeepWildcard {
+		p.deep = true
+		p.segs = p.segs[:len(p.segs)-1]
+	}
+	if len(p.segs) == 0 || IsMemoryKeyPattern(p.segs[0]) {
+		return memoryKeyPattern{}, fmt.Errorf("memory key pattern %q must start with a literal segment (e.g. build/*)", raw)
+	}
+	for _, s := range p.segs {
+		if s == memoryKeyDeepWildcard {
+			return memoryKeyPattern{}, fmt.Errorf("memory key pattern %q: ** is only allowed as the last segment", raw)
+		}
+		if _, err := path.Match(s, ""); err != nil {
+			return memoryKeyPattern{}, fmt.Errorf("memory key pattern %q: %w", raw, err)
+		}
+	}
+	return p, nil
+}
+
+// literalPrefix returns the leading segments that contain no wildcards, used
+// to narrow the candidate rows before matching.
+func (p memoryKeyPattern) literalPrefix() string {
+	var lit []string
+	for _, s := range p.segs {
+		if IsMemoryKeyPattern(s) {
+			break
+		}
+		lit = append(lit, s)
+	}
+	return strings.Join(lit, MemoryKeySeparator)
+}
+
+func (p memoryKeyPattern) match(key string) bool {
+	segs := strings.Split(key, MemoryKeySeparator)
+	if len(segs) < len(p.segs) || (!p.deep && len(segs) != len(p.segs)) {
+		return false
+	}
+	if p.deep && len(segs) == len(p.segs) {
+		return false // ** needs at least one descendant segment
+	}
+	for i, ps := range p.segs {
+		if ok, _ := path.Match(ps, segs[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryPrefixClause returns a SQL condition (and args) selecting prefix and
+// its descendants. substr is used instead of LIKE so keys containing % or _
+// need no escaping.
+func memoryPrefixClause(prefix string) (string, []any) {
+	if prefix == "" {
+		return "1=1", nil
+	}
+	child := prefix + MemoryKeySeparator
+	return "(key = ? OR substr(key, 1, ?) = ?)", []any{prefix, utf8.RuneCountInString(child), child}
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalMemoryKey(t *testing.T) {
+	cases := map[string]string{
+		"build":                     "build",
+		" build / commands / test ": "build/commands/test",
+		"/build//commands/":         "build/commands",
+		"Build/Test":                "Build/Test",
+	}
+	for in, want := range cases {
+		got, err := CanonicalMemoryKey(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := CanonicalMemoryKey(" / ")
+	require.Error(t, err)
+	_, err = CanonicalMemoryKey("build/*")
+	require.Error(t, err)
+}
+
+func TestMemoryKeyPattern(t *testing.T) {
+	p, err := compileMemoryKeyPattern("build/*")
+	require.NoError(t, err)
+	assert.True(t, p.match("build/test"))
+	assert.False(t, p.match("build/commands/test"))
+	assert.False(t, p.match("build"))
+
+	p, err = compileMemoryKeyPattern("build/**")
+	require.NoError(t, err)
+	assert.True(t, p.match("build/test"))
+	assert.True(t, p.match("build/commands/test"))
+	assert.False(t, p.match("build"))
+	assert.False(t, p.match("builder/test"))
+
+	p, err = compileMemoryKeyPattern("build/*/test")
+	require.NoError(t, err)
+	assert.True(t, p.match("build/go/test"))
+	assert.False(t, p.match("build/go/lint"))
+
+	for _, bad := range []string{"*", "**", "*/test", "build/**/test", "build/[a"} {
+		_, err := compileMemoryKeyPattern(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestMemoryHierarchy_ListPrefixAndDeleteMatching(t *testing.T) {
+	db, cleanup := setupMemoryTestDB(t)
+	defer cleanup()
+
+	for _, k := range []string{"build", "build/test", " build / commands/test", "builder", "build_100%/x"} {
+		require.NoError(t, SetMemory(context.Background(), db, k, "v", "string", "global", "", nil, false, "", nil))
+	}
+
+	mem, err := GetMemory(context.Background(), db, "build/commands/test/", "global", "")
+	require.NoError(t, err)
+	require.NotNil(t, mem, "lookups are canonicalized like writes")
+	assert.Equal(t, "build/commands/test", mem.Key)
+
+	listed, err := ListMemoryWithPrefix(context.Background(), db, "global", "", "build/")
+	require.NoError(t, err)
+	keys := make([]string, 0, len(listed))
+	for _, m := range listed {
+		keys = append(keys, m.Key)
+	}
+	assert.ElementsMatch(t, []string{"build", "build/test", "build/commands/test"}, keys)
+
+	listed, err = ListMemoryWithPrefix(context.Background(), db, "global", "", "build_100%")
+	require.NoError(t, err)
+	require.Len(t, listed, 1, "prefix matching does not treat %% or _ as wildcards")
+
+	res, err := DeleteMemoryMatchingIdempotent(context.Background(), db, "agent1", "del-1", "build/*", "global", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build/test"}, res.Keys)
+	require.Len(t, res.EventIDs, 1)
+
+	res, err = DeleteMemoryMatchingIdempotent(context.Background(), db, "agent1", "del-2", "build/**", "global", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build/commands/test"}, res.Keys)
+
+	remaining, err := ListMemory(context.Background(), db, "global", "")
+	require.NoError(t, err)
+	assert.Len(t, remaining, 3)
+}
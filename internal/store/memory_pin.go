@@ -12,6 +12,7 @@ import (
 // PinMemoryTx sets or clears the pinned flag on an existing memory entry within a tx.
 // Returns (eventID, found, error). found is false when no row matched the key/scope/scopeID.
 func PinMemoryTx(ctx context.Context, tx *sql.Tx, agentName, key, scope, scopeID string, pin bool) (int64, bool, error) {
+	key = canonicalMemoryPath(key)
 	taskID := ""
 	if scope == "task" {
 		taskID = scopeID
@@ -17,10 +17,10 @@ func TestPinSurvivesTTL(t *testing.T) {
 
 	// Write with a past expires_at and pinned=true
 	past := time.Now().Add(-1 * time.Hour)
-	require.NoError(t, SetMemory(db, "arch", "monolith", "string", "global", "", &past, true, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "arch", "monolith", "string", "global", "", &past, true, "", nil))
 
 	// Should still be returned despite expired TTL
-	mem, err := GetMemory(db, "arch", "global", "")
+	mem, err := GetMemory(context.Background(), db, "arch", "global", "")
 	require.NoError(t, err)
 	require.NotNil(t, mem, "pinned memory must survive TTL expiry")
 	assert.Equal(t, "monolith", mem.Value)
@@ -35,15 +35,15 @@ func TestGCSkipsPinned(t *testing.T) {
 	past := time.Now().Add(-1 * time.Hour)
 
 	// One pinned-expired row, one unpinned-expired row
-	require.NoError(t, SetMemory(db, "pinned-expired", "v1", "string", "global", "", &past, true, "", nil))
-	require.NoError(t, SetMemory(db, "unpinned-expired", "v2", "string", "global", "", &past, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "pinned-expired", "v1", "string", "global", "", &past, true, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "unpinned-expired", "v2", "string", "global", "", &past, false, "", nil))
 
-	_, deleted, err := GCMemoryWithEventIdempotent(db, "agent-gc", "req-gc-skip-pinned", 100)
+	_, deleted, err := GCMemoryWithEventIdempotent(context.Background(), db, "agent-gc", "req-gc-skip-pinned", 100)
 	require.NoError(t, err)
 	assert.Equal(t, 1, deleted, "GC must delete unpinned expired rows only")
 
 	// Pinned row must still exist
-	mem, err := GetMemory(db, "pinned-expired", "global", "")
+	mem, err := GetMemory(context.Background(), db, "pinned-expired", "global", "")
 	require.NoError(t, err)
 	require.NotNil(t, mem, "pinned row must survive GC")
 }
@@ -68,7 +68,7 @@ func TestPinIdempotentReplay(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	t.Cleanup(cleanup)
 
-	require.NoError(t, SetMemory(db, "replay-key", "val", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "replay-key", "val", "string", "global", "", nil, false, "", nil))
 
 	eid1, err := PinMemoryIdempotent(context.Background(), db, "agent", "req-pin-replay-1", "replay-key", "global", "", true)
 	require.NoError(t, err)
@@ -0,0 +1,327 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// memoryScopeBreadth orders scopes from narrowest to widest; promotion only
+// moves an entry to a wider scope and demotion only to a narrower one.
+var memoryScopeBreadth = map[string]int{"task": 0, "agent": 0, "project": 1, "global": 2}
+
+// MemoryTransferOptions controls a promotion.
+type MemoryTransferOptions struct {
+	Move           bool // delete the source entry once the copy is written
+	RequiresReview bool // stage the copy until ReviewMemoryPromotionIdempotent approves it
+}
+
+// MemoryPromoteResult reports a promotion or demotion. Without review the entry
+// is copied at once and EventID is the target upsert's event; with review
+// Promotion is the staged request and EventID is the staging event. Moved is
+// set once the source entry has been deleted.
+type MemoryPromoteResult struct {
+	Promotion *models.MemoryPromotion `json:"promotion,omitempty"`
+	Applied   bool                    `json:"applied"`
+	Moved     bool                    `json:"moved,omitzero"`
+	EventID   int64                   `json:"event_id"`
+}
+
+// PromoteMemoryIdempotent copies the memory entry key from one scope into a
+// wider one, once per (agentName, requestID). The source entry is kept unless
+// opts.Move is set, in which case it is deleted in the same transaction. With
+// opts.RequiresReview the copy is only staged as a pending promotion, applied
+// later by ReviewMemoryPromotionIdempotent.
+//
+//nolint:revive // argument-limit: key and both scope pairs are all required
+func PromoteMemoryIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, key, fromScope, fromScopeID, toScope, toScopeID string, opts MemoryTransferOptions) (*MemoryPromoteResult, error) {
+	key, err := validateMemoryTransfer(key, fromScope, fromScopeID, toScope, toScopeID)
+	if err != nil {
+		return nil, err
+	}
+	if memoryScopeBreadth[toScope] <= memoryScopeBreadth[fromScope] {
+		return nil, fmt.Errorf("cannot promote from %s to %s: the target scope must be wider", fromScope, toScope)
+	}
+
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.promote", func(tx *sql.Tx) (*MemoryPromoteResult, error) {
+		p, err := readMemoryTransferSourceTx(ctx, tx, key, fromScope, fromScopeID, toScope, toScopeID)
+		if err != nil {
+			return nil, err
+		}
+		p.Move = opts.Move
+
+		if !opts.RequiresReview {
+			eventID, err := applyMemoryPromotionTx(tx, agentName, p)
+			if err != nil {
+				return nil, err
+			}
+			return &MemoryPromoteResult{Applied: true, Moved: p.Move, EventID: eventID}, nil
+		}
+
+		var pendingID int64
+		err = tx.QueryRowContext(ctx, `
+			SELECT id FROM memory_promotions
+			WHERE status = ? AND key = ? AND from_scope = ? AND from_scope_id = ? AND to_scope = ? AND to_scope_id = ?
+		`, models.MemoryPromotionPending, key, fromScope, fromScopeID, toScope, toScopeID).Scan(&pendingID)
+		if err == nil {
+			return nil, fmt.Errorf("promotion of %s is already pending review (promotion %d)", key, pendingID)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to check pending promotions: %w", err)
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO memory_promotions (key, from_scope, from_scope_id, to_scope, to_scope_id, value, value_type, kind, move, status, requested_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, key, fromScope, fromScopeID, toScope, toScopeID, p.Value, p.ValueType, p.Kind, p.Move, models.MemoryPromotionPending, agentName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stage promotion: %w", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read promotion id: %w", err)
+		}
+		if p, err = getMemoryPromotion(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		eventID, err := insertMemoryPromotionEventTx(tx, models.EventKindMemoryStaged, agentName, p,
+			fmt.Sprintf("Memory promotion staged for review: %s (%s -> %s)", key, fromScope, toScope))
+		if err != nil {
+			return nil, err
+		}
+		return &MemoryPromoteResult{Promotion: p, EventID: eventID}, nil
+	})
+}
+
+// ReviewMemoryPromotionIdempotent approves or rejects a pending promotion once
+// per (agentName, requestID). Approving writes the staged value into the
+// target scope through the normal upsert path.
+//
+//nolint:revive // argument-limit: promotion id, decision, and note are all required
+func ReviewMemoryPromotionIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, id int64, approve bool, note string) (*MemoryPromoteResult, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.promote_review", func(tx *sql.Tx) (*MemoryPromoteResult, error) {
+		p, err := getMemoryPromotion(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if p.Status != models.MemoryPromotionPending {
+			return nil, fmt.Errorf("promotion %d is already %s", id, p.Status)
+		}
+
+		status := models.MemoryPromotionRejected
+		if approve {
+			status = models.MemoryPromotionApproved
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE memory_promotions SET status = ?, reviewed_by = ?, review_note = ?, reviewed_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, status, agentName, note, id); err != nil {
+			return nil, fmt.Errorf("failed to update promotion: %w", err)
+		}
+		if p, err = getMemoryPromotion(ctx, tx, id); err != nil {
+			return nil, err
+		}
+
+		result := &MemoryPromoteResult{Promotion: p, Applied: approve, Moved: approve && p.Move}
+		if approve {
+			if result.EventID, err = applyMemoryPromotionTx(tx, agentName, p); err != nil {
+				return nil, err
+			}
+			return result, nil
+		}
+		result.EventID, err = insertMemoryPromotionEventTx(tx, models.EventKindMemoryReviewed, agentName, p,
+			fmt.Sprintf("Memory promotion rejected: %s", p.Key))
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	})
+}
+
+// ListMemoryPromotions returns promotions with the given status (all when
+// empty), newest first.
+func ListMemoryPromotions(ctx context.Context, db *sql.DB, status string, limit int) ([]*models.MemoryPromotion, error) {
+	switch status {
+	case "", models.MemoryPromotionPending, models.MemoryPromotionApproved, models.MemoryPromotionRejected:
+	default:
+		return nil, fmt.Errorf("invalid status %q (valid: pending, approved, rejected)", status)
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var out []*models.MemoryPromotion
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx,
+			memoryPromotionSelect+` WHERE (? = '' OR status = ?) ORDER BY id DESC LIMIT ?`, status, status, limit)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = []*models.MemoryPromotion{}
+		for rows.Next() {
+			p, err := scanMemoryPromotion(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, p)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memory promotions: %w", err)
+	}
+	return out, nil
+}
+
+// DemoteMemoryIdempotent copies the memory entry key from one scope into a
+// narrower one (global to project, project to task or agent), once per
+// (agentName, requestID), for facts that turned out to hold only there. With
+// move the source entry is deleted in the same transaction.
+//
+//nolint:revive // argument-limit: key and both scope pairs are all required
+func DemoteMemoryIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, key, fromScope, fromScopeID, toScope, toScopeID string, move bool) (*MemoryPromoteResult, error) {
+	key, err := validateMemoryTransfer(key, fromScope, fromScopeID, toScope, toScopeID)
+	if err != nil {
+		return nil, err
+	}
+	if memoryScopeBreadth[toScope] >= memoryScopeBreadth[fromScope] {
+		return nil, fmt.Errorf("cannot demote from %s to %s: the target scope must be narrower", fromScope, toScope)
+	}
+
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.demote", func(tx *sql.Tx) (*MemoryPromoteResult, error) {
+		p, err := readMemoryTransferSourceTx(ctx, tx, key, fromScope, fromScopeID, toScope, toScopeID)
+		if err != nil {
+			return nil, err
+		}
+		p.Move = move
+		eventID, err := applyMemoryTransferTx(tx, agentName, p, models.EventKindMemoryDemoted, "demoted")
+		if err != nil {
+			return nil, err
+		}
+		return &MemoryPromoteResult{Applied: true, Moved: move, EventID: eventID}, nil
+	})
+}
+
+func validateMemoryTransfer(key, fromScope, fromScopeID, toScope, toScopeID string) (string, error) {
+	key, err := CanonicalMemoryKey(key)
+	if err != nil {
+		return "", err
+	}
+	if err := validateScope(fromScope, fromScopeID); err != nil {
+		return "", fmt.Errorf("from: %w", err)
+	}
+	if err := validateScope(toScope, toScopeID); err != nil {
+		return "", fmt.Errorf("to: %w", err)
+	}
+	return key, nil
+}
+
+// readMemoryTransferSourceTx captures the live source entry as an unsaved
+// promotion from the source scope to the target scope.
+func readMemoryTransferSourceTx(ctx context.Context, tx *sql.Tx, key, fromScope, fromScopeID, toScope, toScopeID string) (*models.MemoryPromotion, error) {
+	p := &models.MemoryPromotion{Key: key, FromScope: fromScope, FromScopeID: fromScopeID, ToScope: toScope, ToScopeID: toScopeID}
+	err := tx.QueryRowContext(ctx, `
+		SELECT value, value_type, kind FROM memory
+		WHERE scope = ? AND scope_id = ? AND key = ?
+		AND (pinned = 1 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+	`, fromScope, fromScopeID, key).Scan(&p.Value, &p.ValueType, &p.Kind)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("memory key not found: %s (scope=%s, scope_id=%s)", key, fromScope, fromScopeID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory: %w", err)
+	}
+	return p, nil
+}
+
+// applyMemoryPromotionTx writes p's value into its target scope, then records
+// a memory_promoted event. It returns the target upsert's event ID.
+func applyMemoryPromotionTx(tx *sql.Tx, agentName string, p *models.MemoryPromotion) (int64, error) {
+	return applyMemoryTransferTx(tx, agentName, p, models.EventKindMemoryPromoted, "promoted")
+}
+
+// applyMemoryTransferTx writes p's value into its target scope, deletes the
+// source entry when p.Move is set (a source already gone is not an error),
+// then records an event of kind. It returns the target upsert's event ID.
+func applyMemoryTransferTx(tx *sql.Tx, agentName string, p *models.MemoryPromotion, kind, verb string) (int64, error) {
+	eventID, err := UpsertMemoryTx(tx, agentName, p.Key, p.Value, p.ValueType, p.ToScope, p.ToScopeID,
+		nil, false, p.Kind, nil, nil, "")
+	if err != nil {
+		return 0, err
+	}
+	if p.Move {
+		if _, _, err := DeleteMemoryTx(context.Background(), tx, agentName, p.Key, p.FromScope, p.FromScopeID); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := insertMemoryPromotionEventTx(tx, kind, agentName, p,
+		fmt.Sprintf("Memory %s: %s (%s -> %s)", verb, p.Key, p.FromScope, p.ToScope)); err != nil {
+		return 0, err
+	}
+	return eventID, nil
+}
+
+func insertMemoryPromotionEventTx(tx *sql.Tx, kind, agentName string, p *models.MemoryPromotion, msg string) (int64, error) {
+	meta := map[string]any{
+		"key":           p.Key,
+		"from_scope":    p.FromScope,
+		"from_scope_id": p.FromScopeID,
+		"to_scope":      p.ToScope,
+		"to_scope_id":   p.ToScopeID,
+		"move":          p.Move,
+	}
+	if p.ID != 0 {
+		meta["promotion_id"], meta["status"] = p.ID, p.Status
+	}
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event metadata: %w", err)
+	}
+	taskID := ""
+	if p.FromScope == string(models.MemoryScopeTask) {
+		taskID = p.FromScopeID
+	} else if p.ToScope == string(models.MemoryScopeTask) {
+		taskID = p.ToScopeID
+	}
+	eventID, err := InsertEventTx(tx, kind, agentName, taskID, msg, string(metaBytes))
+	if err != nil {
+		return 0, fmt.Errorf("failed to append event: %w", err)
+	}
+	return eventID, nil
+}
+
+const memoryPromotionSelect = `
+	SELECT id, key, from_scope, from_scope_id, to_scope, to_scope_id, value, value_type, kind, move,
+		status, requested_by, reviewed_by, review_note, created_at, reviewed_at
+	FROM memory_promotions`
+
+func getMemoryPromotion(ctx context.Context, tx *sql.Tx, id int64) (*models.MemoryPromotion, error) {
+	p, err := scanMemoryPromotion(tx.QueryRowContext(ctx, memoryPromotionSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("memory promotion %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory promotion: %w", err)
+	}
+	return p, nil
+}
+
+func scanMemoryPromotion(row interface{ Scan(dest ...any) error }) (*models.MemoryPromotion, error) {
+	var p models.MemoryPromotion
+	var reviewedAt sql.NullTime
+	if err := row.Scan(&p.ID, &p.Key, &p.FromScope, &p.FromScopeID, &p.ToScope, &p.ToScopeID, &p.Value, &p.ValueType, &p.Kind, &p.Move,
+		&p.Status, &p.RequestedBy, &p.ReviewedBy, &p.ReviewNote, &p.CreatedAt, &reviewedAt); err != nil {
+		return nil, err
+	}
+	if reviewedAt.Valid {
+		t := reviewedAt.Time.UTC()
+		p.ReviewedAt = &t
+	}
+	return &p, nil
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestPromoteMemory_DirectCopyKeepsSource(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_1", "build/go", "1.26", "", "project", "proj_a", nil, false, "fact", nil, "")
+	require.NoError(t, err)
+
+	res, err := PromoteMemoryIdempotent(context.Background(), db, "agent1", "promo_1", "build/go", "project", "proj_a", "global", "", MemoryTransferOptions{})
+	require.NoError(t, err)
+	assert.True(t, res.Applied)
+	assert.Nil(t, res.Promotion)
+
+	global, err := GetMemory(context.Background(), db, "build/go", "global", "")
+	require.NoError(t, err)
+	require.NotNil(t, global)
+	assert.Equal(t, "1.26", global.Value)
+	source, err := GetMemory(context.Background(), db, "build/go", "project", "proj_a")
+	require.NoError(t, err)
+	require.NotNil(t, source)
+
+	_, err = PromoteMemoryIdempotent(context.Background(), db, "agent1", "promo_2", "build/go", "global", "", "project", "proj_b", MemoryTransferOptions{})
+	require.Error(t, err, "promotion only widens scope")
+	_, err = PromoteMemoryIdempotent(context.Background(), db, "agent1", "promo_3", "missing", "project", "proj_a", "global", "", MemoryTransferOptions{})
+	require.Error(t, err)
+}
+
+func TestPromoteMemory_ReviewFlow(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_1", "lint/rule", "strict", "", "project", "proj_a", nil, false, "directive", nil, "")
+	require.NoError(t, err)
+
+	staged, err := PromoteMemoryIdempotent(context.Background(), db, "agent1", "promo_1", "lint/rule", "project", "proj_a", "global", "", MemoryTransferOptions{RequiresReview: true})
+	require.NoError(t, err)
+	require.NotNil(t, staged.Promotion)
+	assert.False(t, staged.Applied)
+	assert.Equal(t, models.MemoryPromotionPending, staged.Promotion.Status)
+	assert.Equal(t, "directive", staged.Promotion.Kind)
+
+	// Replay returns the same staged promotion; a second request is refused.
+	replay, err := PromoteMemoryIdempotent(context.Background(), db, "agent1", "promo_1", "lint/rule", "project", "proj_a", "global", "", MemoryTransferOptions{RequiresReview: true})
+	require.NoError(t, err)
+	assert.Equal(t, staged.Promotion.ID, replay.Promotion.ID)
+	_, err = PromoteMemoryIdempotent(context.Background(), db, "agent1", "promo_2", "lint/rule", "project", "proj_a", "global", "", MemoryTransferOptions{RequiresReview: true})
+	require.Error(t, err)
+
+	global, err := GetMemory(context.Background(), db, "lint/rule", "global", "")
+	require.NoError(t, err)
+	assert.Nil(t, global, "nothing is shared before review")
+
+	pending, err := ListMemoryPromotions(context.Background(), db, models.MemoryPromotionPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	approved, err := ReviewMemoryPromotionIdempotent(context.Background(), db, "reviewer", "review_1", staged.Promotion.ID, true, "applies everywhere")
+	require.NoError(t, err)
+	assert.True(t, approved.Applied)
+	assert.Equal(t, models.MemoryPromotionApproved, approved.Promotion.Status)
+	assert.Equal(t, "reviewer", approved.Promotion.ReviewedBy)
+	require.NotNil(t, approved.Promotion.ReviewedAt)
+
+	global, err = GetMemory(context.Background(), db, "lint/rule", "global", "")
+	require.NoError(t, err)
+	require.NotNil(t, global)
+	assert.Equal(t, "strict", global.Value)
+
+	_, err = ReviewMemoryPromotionIdempotent(context.Background(), db, "reviewer", "review_2", staged.Promotion.ID, false, "")
+	require.Error(t, err, "settled promotions cannot be reviewed again")
+
+	pending, err = ListMemoryPromotions(context.Background(), db, models.MemoryPromotionPending, 0)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestPromoteMemory_RejectLeavesTargetUntouched(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_1", "db/host", "localhost", "", "project", "proj_a", nil, false, "fact", nil, "")
+	require.NoError(t, err)
+	staged, err := PromoteMemoryIdempotent(context.Background(), db, "agent1", "promo_1", "db/host", "project", "proj_a", "global", "", MemoryTransferOptions{RequiresReview: true})
+	require.NoError(t, err)
+
+	rejected, err := ReviewMemoryPromotionIdempotent(context.Background(), db, "reviewer", "review_1", staged.Promotion.ID, false, "project-specific")
+	require.NoError(t, err)
+	assert.False(t, rejected.Applied)
+	assert.Equal(t, models.MemoryPromotionRejected, rejected.Promotion.Status)
+	assert.Equal(t, "project-specific", rejected.Promotion.ReviewNote)
+
+	global, err := GetMemory(context.Background(), db, "db/host", "global", "")
+	require.NoError(t, err)
+	assert.Nil(t, global)
+
+	_, err = ListMemoryPromotions(context.Background(), db, "bogus", 0)
+	require.Error(t, err)
+}
+
+func TestPromoteMemory_MoveFromTask(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Flaky tests", "", "proj_a", 0)
+	require.NoError(t, err)
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_1", "test/flags", "-race", "", "task", task.ID, nil, false, "fact", nil, "")
+	require.NoError(t, err)
+
+	res, err := PromoteMemoryIdempotent(context.Background(), db, "agent1", "promo_1", "test/flags", "task", task.ID, "project", "proj_a", MemoryTransferOptions{Move: true})
+	require.NoError(t, err)
+	assert.True(t, res.Applied)
+	assert.True(t, res.Moved)
+
+	project, err := GetMemory(context.Background(), db, "test/flags", "project", "proj_a")
+	require.NoError(t, err)
+	require.NotNil(t, project)
+	assert.Equal(t, "-race", project.Value)
+	source, err := GetMemory(context.Background(), db, "test/flags", "task", task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, source, "a move deletes the source")
+
+	var kind string
+	var move bool
+	require.NoError(t, db.QueryRow(`SELECT kind, json_extract(metadata, '$.move') FROM events
+		WHERE task_id = ? ORDER BY id DESC LIMIT 1`, task.ID).Scan(&kind, &move))
+	assert.Equal(t, models.EventKindMemoryPromoted, kind)
+	assert.True(t, move)
+
+	// A staged move deletes the source only once approved.
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_2", "lint/rule", "strict", "", "project", "proj_a", nil, false, "fact", nil, "")
+	require.NoError(t, err)
+	staged, err := PromoteMemoryIdempotent(context.Background(), db, "agent1", "promo_2", "lint/rule", "project", "proj_a", "global", "", MemoryTransferOptions{Move: true, RequiresReview: true})
+	require.NoError(t, err)
+	assert.True(t, staged.Promotion.Move)
+	assert.False(t, staged.Moved)
+	source, err = GetMemory(context.Background(), db, "lint/rule", "project", "proj_a")
+	require.NoError(t, err)
+	require.NotNil(t, source)
+
+	approved, err := ReviewMemoryPromotionIdempotent(context.Background(), db, "reviewer", "review_1", staged.Promotion.ID, true, "")
+	require.NoError(t, err)
+	assert.True(t, approved.Moved)
+	source, err = GetMemory(context.Background(), db, "lint/rule", "project", "proj_a")
+	require.NoError(t, err)
+	assert.Nil(t, source)
+}
+
+func TestDemoteMemory(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_1", "db/host", "10.0.0.5", "", "global", "", nil, false, "fact", nil, "")
+	require.NoError(t, err)
+
+	_, err = DemoteMemoryIdempotent(context.Background(), db, "agent1", "demote_0", "db/host", "global", "", "global", "", true)
+	require.Error(t, err, "demotion only narrows scope")
+
+	res, err := DemoteMemoryIdempotent(context.Background(), db, "agent1", "demote_1", "db/host", "global", "", "project", "proj_a", true)
+	require.NoError(t, err)
+	assert.True(t, res.Moved)
+
+	project, err := GetMemory(context.Background(), db, "db/host", "project", "proj_a")
+	require.NoError(t, err)
+	require.NotNil(t, project)
+	assert.Equal(t, "10.0.0.5", project.Value)
+	global, err := GetMemory(context.Background(), db, "db/host", "global", "")
+	require.NoError(t, err)
+	assert.Nil(t, global)
+
+	replay, err := DemoteMemoryIdempotent(context.Background(), db, "agent1", "demote_1", "db/host", "global", "", "project", "proj_a", true)
+	require.NoError(t, err)
+	assert.Equal(t, res.EventID, replay.EventID)
+
+	var kind string
+	require.NoError(t, db.QueryRow(`SELECT kind FROM events ORDER BY id DESC LIMIT 1`).Scan(&kind))
+	assert.Equal(t, models.EventKindMemoryDemoted, kind)
+
+	_, err = DemoteMemoryIdempotent(context.Background(), db, "agent1", "demote_2", "db/host", "global", "", "project", "proj_a", false)
+	require.Error(t, err, "the moved source is gone")
+}
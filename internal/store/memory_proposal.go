@@ -0,0 +1,247 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// MemoryProposalDraft is a memory fact found in an event, before it is staged.
+type MemoryProposalDraft struct {
+	Key           string
+	Value         string
+	ValueType     string
+	Rule          string
+	Excerpt       string
+	SourceEventID int64
+	SourceTaskID  string
+}
+
+// MemoryProposalStageResult reports one extraction run: the proposals it
+// staged, how many drafts it skipped because they were already proposed or
+// already stored, and the memory_proposed event (0 when nothing was staged).
+type MemoryProposalStageResult struct {
+	Scanned   int                      `json:"scanned"`
+	Proposals []*models.MemoryProposal `json:"proposals"`
+	Skipped   int                      `json:"skipped"`
+	EventID   int64                    `json:"event_id,omitzero"`
+}
+
+// MemoryProposalReview is the decision on a pending proposal. Key and Value,
+// when set, replace the proposed ones on accept.
+type MemoryProposalReview struct {
+	Accept bool
+	Key    string
+	Value  string
+	Note   string
+}
+
+// MemoryProposalReviewResult reports a reviewed proposal and its review event.
+type MemoryProposalReviewResult struct {
+	Proposal *models.MemoryProposal `json:"proposal"`
+	Applied  bool                   `json:"applied"`
+	EventID  int64                  `json:"event_id"`
+}
+
+// StageMemoryProposalsIdempotent stages drafts as pending project-scoped
+// proposals once per (agentName, requestID). A draft is skipped when its
+// source event already proposed that key or the project (or global scope)
+// already stores that value under it.
+func StageMemoryProposalsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, drafts []MemoryProposalDraft) (*MemoryProposalStageResult, error) {
+	if projectID == "" {
+		return nil, errors.New("project id is required")
+	}
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.propose", func(tx *sql.Tx) (*MemoryProposalStageResult, error) {
+		result := &MemoryProposalStageResult{Proposals: []*models.MemoryProposal{}}
+		for _, d := range drafts {
+			key, err := CanonicalMemoryKey(d.Key)
+			if err != nil {
+				result.Skipped++
+				continue
+			}
+			var stored int
+			if err := tx.QueryRowContext(ctx, `
+				SELECT COUNT(*) FROM memory
+				WHERE key = ? AND value = ? AND (scope = 'global' OR (scope = 'project' AND scope_id = ?))
+			`, key, d.Value, projectID).Scan(&stored); err != nil {
+				return nil, fmt.Errorf("failed to check memory: %w", err)
+			}
+			if stored > 0 {
+				result.Skipped++
+				continue
+			}
+			res, err := tx.ExecContext(ctx, `
+				INSERT OR IGNORE INTO memory_proposals
+					(key, value, value_type, scope, scope_id, rule, excerpt, source_event_id, source_task_id, proposed_by)
+				VALUES (?, ?, ?, 'project', ?, ?, ?, ?, ?, ?)
+			`, key, d.Value, d.ValueType, projectID, d.Rule, truncateRunes(d.Excerpt, 240), d.SourceEventID, d.SourceTaskID, agentName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to stage memory proposal: %w", err)
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				result.Skipped++
+				continue
+			}
+			id, err := res.LastInsertId()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read memory proposal id: %w", err)
+			}
+			p, err := getMemoryProposal(ctx, tx, id)
+			if err != nil {
+				return nil, err
+			}
+			result.Proposals = append(result.Proposals, p)
+		}
+		if len(result.Proposals) == 0 {
+			return result, nil
+		}
+
+		ids := make([]int64, len(result.Proposals))
+		for i, p := range result.Proposals {
+			ids[i] = p.ID
+		}
+		meta, err := json.Marshal(map[string]any{"proposal_ids": ids, "skipped": result.Skipped})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event metadata: %w", err)
+		}
+		result.EventID, err = InsertEventWithProjectTx(tx, models.EventKindMemoryProposed, agentName, projectID, "",
+			fmt.Sprintf("Proposed %d memory fact(s) for review", len(result.Proposals)), string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append event: %w", err)
+		}
+		return result, nil
+	})
+}
+
+// ReviewMemoryProposalIdempotent accepts or rejects a pending proposal once per
+// (agentName, requestID). Accepting writes the value through the normal upsert
+// path with the source event and task as provenance.
+func ReviewMemoryProposalIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, id int64, review MemoryProposalReview) (*MemoryProposalReviewResult, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "memory.proposal_review", func(tx *sql.Tx) (*MemoryProposalReviewResult, error) {
+		p, err := getMemoryProposal(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if p.Status != models.MemoryProposalPending {
+			return nil, fmt.Errorf("memory proposal %d is already %s", id, p.Status)
+		}
+
+		status := models.MemoryProposalRejected
+		verb := "rejected"
+		if review.Accept {
+			status, verb = models.MemoryProposalAccepted, "accepted"
+			if review.Key != "" {
+				if p.Key, err = CanonicalMemoryKey(review.Key); err != nil {
+					return nil, err
+				}
+			}
+			if review.Value != "" {
+				p.Value = review.Value
+			}
+			sourceEventID := p.SourceEventID
+			if _, err := UpsertMemoryTx(tx, agentName, p.Key, p.Value, p.ValueType, string(p.Scope), p.ScopeID,
+				nil, false, string(models.MemoryKindFact), nil, &sourceEventID, p.SourceTaskID); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE memory_proposals SET status = ?, key = ?, value = ?, reviewed_by = ?, review_note = ?, reviewed_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, status, p.Key, p.Value, agentName, review.Note, id); err != nil {
+			return nil, fmt.Errorf("failed to update memory proposal: %w", err)
+		}
+		if p, err = getMemoryProposal(ctx, tx, id); err != nil {
+			return nil, err
+		}
+
+		meta, err := json.Marshal(map[string]any{
+			"proposal_id": id,
+			"key":         p.Key,
+			"status":      p.Status,
+			"rule":        p.Rule,
+			"note":        review.Note,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event metadata: %w", err)
+		}
+		eventID, err := InsertEventWithProjectTx(tx, models.EventKindProposalReviewed, agentName, p.ScopeID, p.SourceTaskID,
+			fmt.Sprintf("Memory proposal %s: %s", verb, p.Key), string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append event: %w", err)
+		}
+		return &MemoryProposalReviewResult{Proposal: p, Applied: review.Accept, EventID: eventID}, nil
+	})
+}
+
+// ListMemoryProposals returns proposals with the given status (all when
+// empty), newest first, optionally for one project.
+func ListMemoryProposals(ctx context.Context, db *sql.DB, status, projectID string, limit int) ([]*models.MemoryProposal, error) {
+	switch status {
+	case "", models.MemoryProposalPending, models.MemoryProposalAccepted, models.MemoryProposalRejected:
+	default:
+		return nil, fmt.Errorf("invalid status %q (valid: pending, accepted, rejected)", status)
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var out []*models.MemoryProposal
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, memoryProposalSelect+`
+			WHERE (? = '' OR status = ?) AND (? = '' OR scope_id = ?)
+			ORDER BY id DESC LIMIT ?`, status, status, projectID, projectID, limit)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = []*models.MemoryProposal{}
+		for rows.Next() {
+			p, err := scanMemoryProposal(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, p)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memory proposals: %w", err)
+	}
+	return out, nil
+}
+
+const memoryProposalSelect = `
+	SELECT id, key, value, value_type, scope, scope_id, rule, excerpt, source_event_id, source_task_id,
+		status, proposed_by, reviewed_by, review_note, created_at, reviewed_at
+	FROM memory_proposals`
+
+func getMemoryProposal(ctx context.Context, tx *sql.Tx, id int64) (*models.MemoryProposal, error) {
+	p, err := scanMemoryProposal(tx.QueryRowContext(ctx, memoryProposalSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("memory proposal %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory proposal: %w", err)
+	}
+	return p, nil
+}
+
+func scanMemoryProposal(row interface{ Scan(dest ...any) error }) (*models.MemoryProposal, error) {
+	var p models.MemoryProposal
+	var reviewedAt sql.NullTime
+	if err := row.Scan(&p.ID, &p.Key, &p.Value, &p.ValueType, &p.Scope, &p.ScopeID, &p.Rule, &p.Excerpt,
+		&p.SourceEventID, &p.SourceTaskID, &p.Status, &p.ProposedBy, &p.ReviewedBy, &p.ReviewNote,
+		&p.CreatedAt, &reviewedAt); err != nil {
+		return nil, err
+	}
+	if reviewedAt.Valid {
+		t := reviewedAt.Time.UTC()
+		p.ReviewedAt = &t
+	}
+	return &p, nil
+}
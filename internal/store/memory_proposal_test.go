@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestStageMemoryProposals_SkipsKnownAndReviews(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	eventID, err := AppendEventWithProjectAndMetadataIdempotent(context.Background(), db, "agent1", "ev_1", models.EventKindProgress, "proj_a", "",
+		"uses Postgres for the queue; lint config is .golangci.yml", "")
+	require.NoError(t, err)
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "set_1", "config/lint", ".golangci.yml", "", "project", "proj_a", nil, false, "", nil, "")
+	require.NoError(t, err)
+
+	drafts := []MemoryProposalDraft{
+		{Key: "stack/queue", Value: "Postgres", Rule: "uses", Excerpt: "uses Postgres for the queue", SourceEventID: eventID},
+		{Key: "config/lint", Value: ".golangci.yml", Rule: "config", SourceEventID: eventID},
+	}
+	res, err := StageMemoryProposalsIdempotent(context.Background(), db, "agent1", "stage_1", "proj_a", drafts)
+	require.NoError(t, err)
+	require.Len(t, res.Proposals, 1, "config/lint is already stored")
+	assert.Equal(t, 1, res.Skipped)
+	assert.NotZero(t, res.EventID)
+	p := res.Proposals[0]
+	assert.Equal(t, models.MemoryProposalPending, p.Status)
+	assert.Equal(t, models.MemoryScopeProject, p.Scope)
+	assert.Equal(t, "proj_a", p.ScopeID)
+
+	// A second run over the same event proposes nothing new.
+	res, err = StageMemoryProposalsIdempotent(context.Background(), db, "agent1", "stage_2", "proj_a", drafts)
+	require.NoError(t, err)
+	assert.Empty(t, res.Proposals)
+	assert.Zero(t, res.EventID)
+
+	accepted, err := ReviewMemoryProposalIdempotent(context.Background(), db, "agent2", "accept_1", p.ID, MemoryProposalReview{Accept: true, Value: "Postgres 16"})
+	require.NoError(t, err)
+	assert.True(t, accepted.Applied)
+	assert.Equal(t, models.MemoryProposalAccepted, accepted.Proposal.Status)
+	assert.Equal(t, "Postgres 16", accepted.Proposal.Value)
+
+	mem, err := GetMemory(context.Background(), db, "stack/queue", "project", "proj_a")
+	require.NoError(t, err)
+	assert.Equal(t, "Postgres 16", mem.Value)
+	require.NotNil(t, mem.SourceEventID)
+	assert.Equal(t, eventID, *mem.SourceEventID)
+
+	_, err = ReviewMemoryProposalIdempotent(context.Background(), db, "agent2", "reject_1", p.ID, MemoryProposalReview{})
+	require.ErrorContains(t, err, "already accepted")
+
+	pending, err := ListMemoryProposals(context.Background(), db, models.MemoryProposalPending, "proj_a", 0)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	all, err := ListMemoryProposals(context.Background(), db, "", "", 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
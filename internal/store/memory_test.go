@@ -27,11 +27,11 @@ func TestSetMemory_GlobalScope(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	err := SetMemory(db, "api_key", "secret123", "string", "global", "", nil, false, "", nil)
+	err := SetMemory(context.Background(), db, "api_key", "secret123", "string", "global", "", nil, false, "", nil)
 	assert.NoError(t, err)
 
 	// Verify it was stored
-	mem, err := GetMemory(db, "api_key", "global", "")
+	mem, err := GetMemory(context.Background(), db, "api_key", "global", "")
 	require.NoError(t, err)
 	assert.NotNil(t, mem)
 	assert.Equal(t, "api_key", mem.Key)
@@ -45,10 +45,10 @@ func TestSetMemory_ProjectScope(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	err := SetMemory(db, "config", "value1", "string", "project", "proj-123", nil, false, "", nil)
+	err := SetMemory(context.Background(), db, "config", "value1", "string", "project", "proj-123", nil, false, "", nil)
 	assert.NoError(t, err)
 
-	mem, err := GetMemory(db, "config", "project", "proj-123")
+	mem, err := GetMemory(context.Background(), db, "config", "project", "proj-123")
 	require.NoError(t, err)
 	assert.NotNil(t, mem)
 	assert.Equal(t, "proj-123", mem.ScopeID)
@@ -58,10 +58,10 @@ func TestSetMemory_TaskScope(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	err := SetMemory(db, "status", "running", "string", "task", "task-456", nil, false, "", nil)
+	err := SetMemory(context.Background(), db, "status", "running", "string", "task", "task-456", nil, false, "", nil)
 	assert.NoError(t, err)
 
-	mem, err := GetMemory(db, "status", "task", "task-456")
+	mem, err := GetMemory(context.Background(), db, "status", "task", "task-456")
 	require.NoError(t, err)
 	assert.NotNil(t, mem)
 	assert.Equal(t, "task-456", mem.ScopeID)
@@ -71,10 +71,10 @@ func TestSetMemory_AgentScope(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	err := SetMemory(db, "last_action", "compile", "string", "agent", "poet-agent", nil, false, "", nil)
+	err := SetMemory(context.Background(), db, "last_action", "compile", "string", "agent", "poet-agent", nil, false, "", nil)
 	assert.NoError(t, err)
 
-	mem, err := GetMemory(db, "last_action", "agent", "poet-agent")
+	mem, err := GetMemory(context.Background(), db, "last_action", "agent", "poet-agent")
 	require.NoError(t, err)
 	assert.NotNil(t, mem)
 	assert.Equal(t, "poet-agent", mem.ScopeID)
@@ -85,15 +85,15 @@ func TestSetMemory_Upsert(t *testing.T) {
 	defer cleanup()
 
 	// Insert initial value
-	err := SetMemory(db, "counter", "1", "number", "global", "", nil, false, "", nil)
+	err := SetMemory(context.Background(), db, "counter", "1", "number", "global", "", nil, false, "", nil)
 	require.NoError(t, err)
 
 	// Update value
-	err = SetMemory(db, "counter", "2", "number", "global", "", nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "counter", "2", "number", "global", "", nil, false, "", nil)
 	require.NoError(t, err)
 
 	// Verify update
-	mem, err := GetMemory(db, "counter", "global", "")
+	mem, err := GetMemory(context.Background(), db, "counter", "global", "")
 	require.NoError(t, err)
 	assert.Equal(t, "2", mem.Value)
 }
@@ -103,12 +103,12 @@ func TestUpsertMemoryWithEventIdempotent_TaskScope(t *testing.T) {
 	defer cleanup()
 
 	expiresAt := time.Now().Add(24 * time.Hour)
-	eventID, err := UpsertMemoryWithEventIdempotent(db, "agent1", "req_task_scope_upsert", "checkpoint", "step_3", "", "task", "task-1", &expiresAt, false, "", nil, "")
+	eventID, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_task_scope_upsert", "checkpoint", "step_3", "", "task", "task-1", &expiresAt, false, "", nil, "")
 	require.NoError(t, err)
 	require.Greater(t, eventID, int64(0))
 
 	// Memory is present
-	mem, err := GetMemory(db, "checkpoint", "task", "task-1")
+	mem, err := GetMemory(context.Background(), db, "checkpoint", "task", "task-1")
 	require.NoError(t, err)
 	require.NotNil(t, mem)
 
@@ -130,14 +130,14 @@ func TestDeleteMemoryWithEvent_GlobalScope(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	require.NoError(t, SetMemory(db, "temp", "value", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "temp", "value", "string", "global", "", nil, false, "", nil))
 
 	eventID, err := DeleteMemoryWithEvent(context.Background(), db, "agent1", "temp", "global", "")
 	require.NoError(t, err)
 	require.Greater(t, eventID, int64(0))
 
 	// Memory is gone
-	mem, err := GetMemory(db, "temp", "global", "")
+	mem, err := GetMemory(context.Background(), db, "temp", "global", "")
 	require.NoError(t, err)
 	assert.Nil(t, mem)
 
@@ -151,7 +151,7 @@ func TestGetMemory_NotFound(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	mem, err := GetMemory(db, "nonexistent", "global", "")
+	mem, err := GetMemory(context.Background(), db, "nonexistent", "global", "")
 	assert.NoError(t, err)
 	assert.Nil(t, mem)
 }
@@ -161,11 +161,11 @@ func TestListMemory_GlobalScope(t *testing.T) {
 	defer cleanup()
 
 	// Insert multiple entries
-	require.NoError(t, SetMemory(db, "key1", "value1", "string", "global", "", nil, false, "", nil))
-	require.NoError(t, SetMemory(db, "key2", "value2", "string", "global", "", nil, false, "", nil))
-	require.NoError(t, SetMemory(db, "key3", "value3", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key1", "value1", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key2", "value2", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key3", "value3", "string", "global", "", nil, false, "", nil))
 
-	memories, err := ListMemory(db, "global", "")
+	memories, err := ListMemory(context.Background(), db, "global", "")
 	require.NoError(t, err)
 	assert.Len(t, memories, 3)
 }
@@ -175,17 +175,17 @@ func TestListMemory_ProjectScope_Isolated(t *testing.T) {
 	defer cleanup()
 
 	// Insert entries for different projects
-	require.NoError(t, SetMemory(db, "key1", "value1", "string", "project", "proj-a", nil, false, "", nil))
-	require.NoError(t, SetMemory(db, "key2", "value2", "string", "project", "proj-a", nil, false, "", nil))
-	require.NoError(t, SetMemory(db, "key3", "value3", "string", "project", "proj-b", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key1", "value1", "string", "project", "proj-a", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key2", "value2", "string", "project", "proj-a", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key3", "value3", "string", "project", "proj-b", nil, false, "", nil))
 
 	// List for proj-a
-	memories, err := ListMemory(db, "project", "proj-a")
+	memories, err := ListMemory(context.Background(), db, "project", "proj-a")
 	require.NoError(t, err)
 	assert.Len(t, memories, 2)
 
 	// List for proj-b
-	memories, err = ListMemory(db, "project", "proj-b")
+	memories, err = ListMemory(context.Background(), db, "project", "proj-b")
 	require.NoError(t, err)
 	assert.Len(t, memories, 1)
 }
@@ -195,10 +195,10 @@ func TestSetMemory_WithExpiration(t *testing.T) {
 	defer cleanup()
 
 	expiresAt := time.Now().UTC().Add(1 * time.Hour)
-	err := SetMemory(db, "temp_key", "temp_value", "string", "global", "", &expiresAt, false, "", nil)
+	err := SetMemory(context.Background(), db, "temp_key", "temp_value", "string", "global", "", &expiresAt, false, "", nil)
 	require.NoError(t, err)
 
-	mem, err := GetMemory(db, "temp_key", "global", "")
+	mem, err := GetMemory(context.Background(), db, "temp_key", "global", "")
 	require.NoError(t, err)
 	require.NotNil(t, mem, "GetMemory should return a record with valid expiration in future")
 	assert.NotNil(t, mem.ExpiresAt)
@@ -210,11 +210,11 @@ func TestGetMemory_FilterExpired(t *testing.T) {
 
 	// Insert expired entry
 	expiresAt := time.Now().UTC().Add(-1 * time.Hour)
-	err := SetMemory(db, "expired", "value", "string", "global", "", &expiresAt, false, "", nil)
+	err := SetMemory(context.Background(), db, "expired", "value", "string", "global", "", &expiresAt, false, "", nil)
 	require.NoError(t, err)
 
 	// Should not be returned
-	mem, err := GetMemory(db, "expired", "global", "")
+	mem, err := GetMemory(context.Background(), db, "expired", "global", "")
 	assert.NoError(t, err)
 	assert.Nil(t, mem)
 }
@@ -227,11 +227,11 @@ func TestListMemory_FilterExpired(t *testing.T) {
 	expired := time.Now().UTC().Add(-1 * time.Hour)
 	valid := time.Now().UTC().Add(1 * time.Hour)
 
-	require.NoError(t, SetMemory(db, "key1", "value1", "string", "global", "", &expired, false, "", nil))
-	require.NoError(t, SetMemory(db, "key2", "value2", "string", "global", "", &valid, false, "", nil))
-	require.NoError(t, SetMemory(db, "key3", "value3", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key1", "value1", "string", "global", "", &expired, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key2", "value2", "string", "global", "", &valid, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "key3", "value3", "string", "global", "", nil, false, "", nil))
 
-	memories, err := ListMemory(db, "global", "")
+	memories, err := ListMemory(context.Background(), db, "global", "")
 	require.NoError(t, err)
 	assert.Len(t, memories, 2) // Only valid and nil expiration
 }
@@ -325,10 +325,10 @@ func TestSetMemory_TypeInference(t *testing.T) {
 
 	for i, tt := range tests {
 		key := fmt.Sprintf("key%d", i)
-		err := SetMemory(db, key, tt.value, "", "global", "", nil, false, "", nil)
+		err := SetMemory(context.Background(), db, key, tt.value, "", "global", "", nil, false, "", nil)
 		require.NoError(t, err)
 
-		mem, err := GetMemory(db, key, "global", "")
+		mem, err := GetMemory(context.Background(), db, key, "global", "")
 		require.NoError(t, err)
 		assert.Equal(t, tt.expectedType, mem.ValueType)
 	}
@@ -339,18 +339,18 @@ func TestMemory_UniqueConstraint(t *testing.T) {
 	defer cleanup()
 
 	// Same key, different scopes - should not conflict
-	err := SetMemory(db, "config", "value1", "string", "global", "", nil, false, "", nil)
+	err := SetMemory(context.Background(), db, "config", "value1", "string", "global", "", nil, false, "", nil)
 	require.NoError(t, err)
 
-	err = SetMemory(db, "config", "value2", "string", "project", "proj-123", nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "config", "value2", "string", "project", "proj-123", nil, false, "", nil)
 	require.NoError(t, err)
 
 	// Verify both exist independently
-	mem1, err := GetMemory(db, "config", "global", "")
+	mem1, err := GetMemory(context.Background(), db, "config", "global", "")
 	require.NoError(t, err)
 	require.NotNil(t, mem1)
 
-	mem2, err := GetMemory(db, "config", "project", "proj-123")
+	mem2, err := GetMemory(context.Background(), db, "config", "project", "proj-123")
 	require.NoError(t, err)
 	require.NotNil(t, mem2)
 
@@ -362,7 +362,7 @@ func TestUpsertMemoryWithEventIdempotent_Replay(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	eventID1, err := UpsertMemoryWithEventIdempotent(
+	eventID1, err := UpsertMemoryWithEventIdempotent(context.Background(),
 		db,
 		"agent1",
 		"req_upsert_replay",
@@ -379,7 +379,7 @@ func TestUpsertMemoryWithEventIdempotent_Replay(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	eventID2, err := UpsertMemoryWithEventIdempotent(
+	eventID2, err := UpsertMemoryWithEventIdempotent(context.Background(),
 		db,
 		"agent1",
 		"req_upsert_replay",
@@ -408,11 +408,11 @@ func TestGCMemoryWithEventIdempotent(t *testing.T) {
 	defer cleanup()
 
 	expired := time.Now().UTC().Add(-1 * time.Hour)
-	require.NoError(t, SetMemory(db, "expired_1", "v", "string", "global", "", &expired, false, "", nil))
-	require.NoError(t, SetMemory(db, "expired_2", "v", "string", "global", "", &expired, false, "", nil))
-	require.NoError(t, SetMemory(db, "active", "v", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "expired_1", "v", "string", "global", "", &expired, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "expired_2", "v", "string", "global", "", &expired, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "active", "v", "string", "global", "", nil, false, "", nil))
 
-	eventID, deleted, err := GCMemoryWithEventIdempotent(db, "agent1", "req_gc_1", 10)
+	eventID, deleted, err := GCMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_gc_1", 10)
 	require.NoError(t, err)
 	require.Greater(t, eventID, int64(0))
 	assert.Equal(t, 2, deleted)
@@ -436,7 +436,7 @@ func TestSetMemory_RejectsInvalidValueType(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	err := SetMemory(db, "k", "v", "invalid_type", "global", "", nil, false, "", nil)
+	err := SetMemory(context.Background(), db, "k", "v", "invalid_type", "global", "", nil, false, "", nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid value_type")
 }
@@ -445,7 +445,7 @@ func TestUpsertMemory_RejectsInvalidValueType(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	_, err := UpsertMemoryWithEventIdempotent(db, "agent1", "req_vt_1", "k", "v", "invalid", "global", "", nil, false, "", nil, "")
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_vt_1", "k", "v", "invalid", "global", "", nil, false, "", nil, "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid value_type")
 }
@@ -462,7 +462,7 @@ func TestMemoryEventMetadata_MarshalProducesValidJSON(t *testing.T) {
 		{
 			name: "UpsertMemory produces valid metadata",
 			op: func() error {
-				_, err := UpsertMemoryWithEventIdempotent(
+				_, err := UpsertMemoryWithEventIdempotent(context.Background(),
 					db, "agent1", "req_meta_1",
 					"test-key", "test-value", "string",
 					"global", "", nil, false, "", nil, "",
@@ -474,7 +474,7 @@ func TestMemoryEventMetadata_MarshalProducesValidJSON(t *testing.T) {
 		{
 			name: "GCMemory produces valid metadata",
 			op: func() error {
-				_, _, err := GCMemoryWithEventIdempotent(
+				_, _, err := GCMemoryWithEventIdempotent(context.Background(),
 					db, "agent1", "req_meta_3", 10,
 				)
 				return err
@@ -485,7 +485,7 @@ func TestMemoryEventMetadata_MarshalProducesValidJSON(t *testing.T) {
 			name: "DeleteMemoryWithEvent produces valid metadata",
 			op: func() error {
 				// Ensure the key exists before deleting
-				_ = SetMemory(db, "to-delete", "val", "string", "global", "", nil, false, "", nil)
+				_ = SetMemory(context.Background(), db, "to-delete", "val", "string", "global", "", nil, false, "", nil)
 				_, err := DeleteMemoryWithEvent(context.Background(), db, "agent1", "to-delete", "global", "")
 				return err
 			},
@@ -494,7 +494,7 @@ func TestMemoryEventMetadata_MarshalProducesValidJSON(t *testing.T) {
 		{
 			name: "DeleteMemoryWithEventIdempotent produces valid metadata",
 			op: func() error {
-				_ = SetMemory(db, "to-delete-idem", "val", "string", "global", "", nil, false, "", nil)
+				_ = SetMemory(context.Background(), db, "to-delete-idem", "val", "string", "global", "", nil, false, "", nil)
 				_, err := DeleteMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_meta_5", "to-delete-idem", "global", "")
 				return err
 			},
@@ -508,7 +508,7 @@ func TestMemoryEventMetadata_MarshalProducesValidJSON(t *testing.T) {
 			require.NoError(t, err)
 
 			// Verify the event was created with valid metadata
-			events, err := ListEvents(db, ListEventsParams{Kind: tt.eventKind, Limit: 1, Desc: true})
+			events, err := ListEvents(context.Background(), db, ListEventsParams{Kind: tt.eventKind, Limit: 1, Desc: true})
 			require.NoError(t, err)
 			require.NotEmpty(t, events, "expected at least one %s event", tt.eventKind)
 
@@ -524,11 +524,11 @@ func TestUpsertMemoryTx_EmitsConflictEvent(t *testing.T) {
 	defer cleanup()
 
 	// First insert — no conflict
-	_, err := UpsertMemoryWithEventIdempotent(db, "agent1", "req_conflict_1", "key1", "value_old", "string", "global", "", nil, false, "", nil, "")
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_conflict_1", "key1", "value_old", "string", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 
 	// Overwrite with different value — should emit memory_conflict
-	_, err = UpsertMemoryWithEventIdempotent(db, "agent1", "req_conflict_2", "key1", "value_new", "string", "global", "", nil, false, "", nil, "")
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_conflict_2", "key1", "value_new", "string", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 
 	// Check that a memory_conflict event was emitted
@@ -549,10 +549,10 @@ func TestUpsertMemoryTx_NoConflictOnSameValue(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	_, err := UpsertMemoryWithEventIdempotent(db, "agent1", "req_same_1", "key1", "same_value", "string", "global", "", nil, false, "", nil, "")
+	_, err := UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_same_1", "key1", "same_value", "string", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 
-	_, err = UpsertMemoryWithEventIdempotent(db, "agent1", "req_same_2", "key1", "same_value", "string", "global", "", nil, false, "", nil, "")
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_same_2", "key1", "same_value", "string", "global", "", nil, false, "", nil, "")
 	require.NoError(t, err)
 
 	var count int
@@ -566,11 +566,11 @@ func TestGetMemory_TracksAccess(t *testing.T) {
 	db, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
 
-	require.NoError(t, SetMemory(db, "tracked", "value", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "tracked", "value", "string", "global", "", nil, false, "", nil))
 
 	// First access — returned struct has pre-update access_count (0),
 	// but DB is updated to 1 after the scan.
-	mem1, err := GetMemory(db, "tracked", "global", "")
+	mem1, err := GetMemory(context.Background(), db, "tracked", "global", "")
 	require.NoError(t, err)
 	require.NotNil(t, mem1)
 	assert.Equal(t, 0, mem1.AccessCount, "returned struct reflects pre-update value")
@@ -581,7 +581,7 @@ func TestGetMemory_TracksAccess(t *testing.T) {
 	assert.Equal(t, 1, accessCount, "DB should have access_count=1 after first GetMemory")
 
 	// Second access
-	mem2, err := GetMemory(db, "tracked", "global", "")
+	mem2, err := GetMemory(context.Background(), db, "tracked", "global", "")
 	require.NoError(t, err)
 	require.NotNil(t, mem2)
 	assert.Equal(t, 1, mem2.AccessCount, "second call scans the value from first update")
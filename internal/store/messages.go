@@ -0,0 +1,201 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+const (
+	maxMessageSubjectLength = 200
+	maxMessageBodyLength    = 10000
+)
+
+// SendMessageTx inserts a message into toAgent's inbox and emits a message_sent event.
+// toAgent is lowercased like every resolved agent name, so the recipient's inbox
+// finds it whatever case the sender typed. taskID is optional; when set it must
+// reference an existing task.
+//
+//nolint:revive // argument-limit: sender, recipient, subject, body, and task are all message fields
+func SendMessageTx(tx *sql.Tx, fromAgent, toAgent, subject, body, taskID string) (*models.Message, int64, error) {
+	toAgent = strings.ToLower(strings.TrimSpace(toAgent))
+	subject = strings.TrimSpace(subject)
+	if toAgent == "" {
+		return nil, 0, errors.New("recipient agent is required")
+	}
+	if len(toAgent) > MaxEventAgentNameLength {
+		return nil, 0, fmt.Errorf("recipient agent name exceeds maximum length (%d chars)", MaxEventAgentNameLength)
+	}
+	if subject == "" {
+		return nil, 0, errors.New("message subject is required")
+	}
+	if len(subject) > maxMessageSubjectLength {
+		return nil, 0, fmt.Errorf("message subject exceeds %d characters", maxMessageSubjectLength)
+	}
+	if len(body) > maxMessageBodyLength {
+		return nil, 0, fmt.Errorf("message body exceeds %d characters", maxMessageBodyLength)
+	}
+	if taskID != "" {
+		if _, err := GetTaskVersionTx(tx, taskID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	id := generatePrefixedID("msg")
+	if _, err := tx.ExecContext(context.Background(), `
+		INSERT INTO messages (id, from_agent, to_agent, subject, body, task_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, id, fromAgent, toAgent, subject, body, nullIfEmpty(taskID)); err != nil {
+		return nil, 0, fmt.Errorf("failed to insert message: %w", err)
+	}
+
+	meta, _ := json.Marshal(map[string]string{"message_id": id, "to": toAgent})
+	eventID, err := InsertEventTx(tx, models.EventKindMessageSent, fromAgent, taskID,
+		fmt.Sprintf("Message to %s: %s", toAgent, truncateRunes(subject, 120)), string(meta))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to append message event: %w", err)
+	}
+
+	m, err := getMessage(tx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return m, eventID, nil
+}
+
+// SendMessageIdempotent performs SendMessageTx once per (agent_name, request_id),
+// so a retried send never delivers a duplicate.
+//
+//nolint:revive // argument-limit: request id plus the SendMessageTx fields
+func SendMessageIdempotent(ctx context.Context, db *sql.DB, fromAgent, requestID, toAgent, subject, body, taskID string) (*models.Message, int64, error) {
+	type idemResult struct {
+		Message *models.Message `json:"message"`
+		EventID int64           `json:"event_id"`
+	}
+	r, err := RunIdempotent(ctx, db, fromAgent, requestID, "msg.send", func(tx *sql.Tx) (idemResult, error) {
+		m, eid, txErr := SendMessageTx(tx, fromAgent, toAgent, subject, body, taskID)
+		if txErr != nil {
+			return idemResult{}, txErr
+		}
+		return idemResult{Message: m, EventID: eid}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.Message, r.EventID, nil
+}
+
+// MarkMessagesReadIdempotent marks messages in agentName's inbox as read.
+// An empty ids slice marks every unread message. Messages addressed to other
+// agents are ignored. Returns the number of messages newly marked read.
+func MarkMessagesReadIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, ids []string) (int64, error) {
+	type idemResult struct {
+		Marked int64 `json:"marked"`
+	}
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "msg.read", func(tx *sql.Tx) (idemResult, error) {
+		query := `UPDATE messages SET read_at = CURRENT_TIMESTAMP WHERE to_agent = ? AND read_at IS NULL`
+		args := []any{agentName}
+		if len(ids) > 0 {
+			query += ` AND id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + `)` //nolint:gosec // G202: placeholders only; ids are bound
+			for _, id := range ids {
+				args = append(args, id)
+			}
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return idemResult{}, fmt.Errorf("failed to mark messages read: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return idemResult{}, fmt.Errorf("failed to check rows affected: %w", err)
+		}
+		return idemResult{Marked: n}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return r.Marked, nil
+}
+
+// ListInbox returns messages addressed to agentName, oldest first so agents
+// process hand-offs in the order they were sent.
+func ListInbox(ctx context.Context, db *sql.DB, agentName string, unreadOnly bool, limit int) ([]models.Message, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	query := messageSelect + ` WHERE to_agent = ?`
+	if unreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	query += ` ORDER BY created_at ASC, rowid ASC LIMIT ?`
+
+	var out []models.Message
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, agentName, limit)
+		if err != nil {
+			return fmt.Errorf("failed to query inbox: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		out = make([]models.Message, 0)
+		for rows.Next() {
+			m, err := scanMessage(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, *m)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CountUnreadMessages returns the number of unread messages for agentName.
+func CountUnreadMessages(ctx context.Context, db *sql.DB, agentName string) (int, error) {
+	var n int
+	err := RetryWithBackoff(ctx, func() error {
+		return db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM messages WHERE to_agent = ? AND read_at IS NULL`, agentName).Scan(&n)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return n, nil
+}
+
+const messageSelect = `SELECT id, from_agent, to_agent, subject, body, COALESCE(task_id, ''), read_at, created_at FROM messages`
+
+func getMessage(q Querier, id string) (*models.Message, error) {
+	rows, err := q.Query(messageSelect+` WHERE id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load message: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("message not found: %s", id)
+	}
+	return scanMessage(rows)
+}
+
+func scanMessage(rows *sql.Rows) (*models.Message, error) {
+	var m models.Message
+	var readAt sql.NullTime
+	if err := rows.Scan(&m.ID, &m.FromAgent, &m.ToAgent, &m.Subject, &m.Body, &m.TaskID, &readAt, &m.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan message: %w", err)
+	}
+	if readAt.Valid {
+		t := readAt.Time
+		m.ReadAt = &t
+	}
+	return &m, nil
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessages_SendInboxAndRead(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "handoff", "", "", 0)
+	require.NoError(t, err)
+
+	m1, eid, err := SendMessageIdempotent(context.Background(), db, "agent-a", "msg_1", "agent-b", "schema ready", "migrations landed", task.ID)
+	require.NoError(t, err)
+	assert.Positive(t, eid)
+	assert.Equal(t, task.ID, m1.TaskID)
+	assert.Nil(t, m1.ReadAt)
+
+	// Replaying the same request must not deliver a duplicate.
+	replay, _, err := SendMessageIdempotent(context.Background(), db, "agent-a", "msg_1", "agent-b", "schema ready", "migrations landed", task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, m1.ID, replay.ID)
+
+	m2, _, err := SendMessageIdempotent(context.Background(), db, "agent-a", "msg_2", "agent-b", "second", "", "")
+	require.NoError(t, err)
+	_, _, err = SendMessageIdempotent(context.Background(), db, "agent-a", "msg_3", "agent-c", "other inbox", "", "")
+	require.NoError(t, err)
+
+	inbox, err := ListInbox(context.Background(), db, "agent-b", true, 0)
+	require.NoError(t, err)
+	require.Len(t, inbox, 2)
+	assert.Equal(t, m1.ID, inbox[0].ID)
+
+	// agent-c cannot mark agent-b's messages read.
+	n, err := MarkMessagesReadIdempotent(context.Background(), db, "agent-c", "read_c", []string{m1.ID})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = MarkMessagesReadIdempotent(context.Background(), db, "agent-b", "read_1", []string{m1.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	unread, err := CountUnreadMessages(context.Background(), db, "agent-b")
+	require.NoError(t, err)
+	assert.Equal(t, 1, unread)
+
+	brief, err := BuildBrief(context.Background(), db, "", "", "agent-b")
+	require.NoError(t, err)
+	assert.Equal(t, 1, brief.UnreadMessages)
+
+	n, err = MarkMessagesReadIdempotent(context.Background(), db, "agent-b", "read_all", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	all, err := ListInbox(context.Background(), db, "agent-b", false, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, m2.ID, all[1].ID)
+	assert.NotNil(t, all[1].ReadAt)
+
+	_, _, err = SendMessageIdempotent(context.Background(), db, "agent-a", "msg_bad", "agent-b", "", "", "")
+	require.Error(t, err)
+}
+
+func TestMessages_RecipientIsCaseInsensitive(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	m, _, err := SendMessageIdempotent(context.Background(), db, "agent-a", "msg_1", " Agent-B ", "mixed case", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "agent-b", m.ToAgent)
+
+	inbox, err := ListInbox(context.Background(), db, "agent-b", true, 0)
+	require.NoError(t, err)
+	require.Len(t, inbox, 1)
+	assert.Equal(t, m.ID, inbox[0].ID)
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
@@ -9,6 +10,8 @@ import (
 	"sync"
 
 	"github.com/pressly/goose/v3"
+
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 //go:embed migrations/*.sql
@@ -35,9 +38,12 @@ func initGoose() error {
 
 // MigrateDB runs all pending migrations with a file lock to prevent concurrent
 // migration races. For in-memory databases (tests), the lock is skipped.
-func MigrateDB(db *sql.DB, dbPath string) error {
+func MigrateDB(ctx context.Context, db *sql.DB, dbPath string) (err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	// Fast path: skip lock + goose.Up when schema is already current.
-	current, latest, err := SchemaVersion(db)
+	current, latest, err := SchemaVersion(ctx, db)
 	if err == nil && current >= latest && latest > 0 {
 		return nil
 	}
@@ -49,13 +55,13 @@ func MigrateDB(db *sql.DB, dbPath string) error {
 		}
 		defer UnlockFile(lockF)
 	}
-	return RunMigrations(db)
+	return RunMigrations(ctx, db)
 }
 
 // SchemaVersion returns the current and latest migration versions.
 // current comes from goose_db_version; latest is the highest version
 // in the embedded migration files. Returns (0, latest, nil) for a fresh DB.
-func SchemaVersion(db *sql.DB) (current int64, latest int64, err error) {
+func SchemaVersion(ctx context.Context, db *sql.DB) (current int64, latest int64, err error) {
 	if err := initGoose(); err != nil {
 		return 0, 0, fmt.Errorf("set dialect: %w", err)
 	}
@@ -106,7 +112,7 @@ func latestMigrationVersion() (int64, error) {
 }
 
 // RunMigrations runs all pending migrations using goose.
-func RunMigrations(db *sql.DB) error {
+func RunMigrations(ctx context.Context, db *sql.DB) error {
 	if err := initGoose(); err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
@@ -12,7 +13,7 @@ import (
 func migrateToVersion(t *testing.T, version int64) *sql.DB {
 	t.Helper()
 	dbPath := t.TempDir() + "/migrate_test.db"
-	db, err := OpenDB(dbPath)
+	db, err := OpenDB(context.Background(), dbPath)
 	require.NoError(t, err, "OpenDB failed")
 	goose.SetBaseFS(embedMigrations)
 	goose.SetVerbose(false)
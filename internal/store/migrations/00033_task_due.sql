@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN due_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN overdue_notified_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_tasks_due_at ON tasks(due_at) WHERE due_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tasks_due_at;
+ALTER TABLE tasks DROP COLUMN overdue_notified_at;
+ALTER TABLE tasks DROP COLUMN due_at;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Content-addressed artifact bodies. Identical outputs across attempts share one row.
+CREATE TABLE IF NOT EXISTS artifact_blobs (
+    hash TEXT PRIMARY KEY,
+    size INTEGER NOT NULL,
+    content BLOB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE artifacts ADD COLUMN content_hash TEXT;
+CREATE INDEX idx_artifacts_content_hash ON artifacts(content_hash) WHERE content_hash IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_artifacts_content_hash;
+ALTER TABLE artifacts DROP COLUMN content_hash;
+DROP TABLE IF EXISTS artifact_blobs;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Per-session focus for agents that run several sessions under one name.
+-- agent_state keeps the cursor and the most recent focus for legacy readers.
+CREATE TABLE IF NOT EXISTS agent_session_state (
+    agent_name TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    focus_task_id TEXT,
+    focus_project_id TEXT,
+    last_active_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (agent_name, session_id)
+);
+
+CREATE INDEX idx_agent_session_state_active ON agent_session_state(agent_name, last_active_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS agent_session_state;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Hooks record the client session in events.metadata.session_id. Index it so
+-- session replay does not scan the whole log. The expression must match
+-- sessionIDExpr in session_replay.go exactly for the planner to use it.
+CREATE INDEX IF NOT EXISTS idx_events_session_id
+    ON events(CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.session_id') END);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_events_session_id;
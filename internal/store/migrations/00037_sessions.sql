@@ -0,0 +1,20 @@
+-- +goose Up
+-- Client sessions, keyed by the hook-provided session id.
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    agent_name TEXT NOT NULL,
+    project_id TEXT,
+    source TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ended_at TIMESTAMP,
+    end_reason TEXT,
+    outcome TEXT,
+    start_event_id INTEGER NOT NULL DEFAULT 0,
+    end_event_id INTEGER
+);
+
+CREATE INDEX idx_sessions_agent_started ON sessions(agent_name, started_at);
+CREATE INDEX idx_sessions_project_started ON sessions(project_id, started_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN size TEXT;
+
+-- +goose Down
+ALTER TABLE tasks DROP COLUMN size;
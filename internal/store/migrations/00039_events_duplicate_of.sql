@@ -0,0 +1,6 @@
+-- +goose Up
+-- events dedupe archives repeated hook events and points them at the kept copy.
+ALTER TABLE events ADD COLUMN duplicate_of INTEGER;
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN duplicate_of;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Append-only timeline of memory value changes, linked to the event that caused each.
+CREATE TABLE IF NOT EXISTS memory_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    scope_id TEXT NOT NULL DEFAULT '',
+    change TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    value_type TEXT NOT NULL DEFAULT '',
+    agent_name TEXT NOT NULL DEFAULT '',
+    event_id INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_memory_history_key ON memory_history(scope, scope_id, key, id);
+
+-- Seed the timeline with current values so existing keys have a baseline.
+INSERT INTO memory_history (key, scope, scope_id, change, new_value, value_type, created_at)
+SELECT key, scope, COALESCE(scope_id, ''), 'created', value, value_type, COALESCE(updated_at, created_at)
+FROM memory;
+
+-- +goose Down
+DROP TABLE IF EXISTS memory_history;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Archived projects stay queryable by ID but are hidden from project lists,
+-- resume focus selection, and task claims.
+ALTER TABLE projects ADD COLUMN archived_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN archived_at;
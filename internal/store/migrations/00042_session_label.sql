@@ -0,0 +1,7 @@
+-- +goose Up
+-- Human-friendly session name, derived from the first user prompt by the
+-- prompt hook or set with `session label`.
+ALTER TABLE sessions ADD COLUMN label TEXT;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN label;
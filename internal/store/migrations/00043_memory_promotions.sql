@@ -0,0 +1,24 @@
+-- +goose Up
+-- Staged copies of a memory entry into a wider scope, applied only once reviewed.
+CREATE TABLE IF NOT EXISTS memory_promotions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key TEXT NOT NULL,
+    from_scope TEXT NOT NULL,
+    from_scope_id TEXT NOT NULL DEFAULT '',
+    to_scope TEXT NOT NULL,
+    to_scope_id TEXT NOT NULL DEFAULT '',
+    value TEXT NOT NULL,
+    value_type TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL DEFAULT 'fact',
+    status TEXT NOT NULL DEFAULT 'pending',
+    requested_by TEXT NOT NULL DEFAULT '',
+    reviewed_by TEXT NOT NULL DEFAULT '',
+    review_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewed_at TIMESTAMP
+);
+
+CREATE INDEX idx_memory_promotions_status ON memory_promotions(status, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS memory_promotions;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Free-form task tags, used to route slices of the queue to dedicated loops.
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, tag),
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_tags_tag ON task_tags(tag);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_tags;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Daily counters behind workspace activity limits (vybe limits status).
+CREATE TABLE IF NOT EXISTS limit_usage (
+    counter TEXT NOT NULL,
+    day TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (counter, day)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS limit_usage;
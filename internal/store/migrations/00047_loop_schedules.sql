@@ -0,0 +1,19 @@
+-- +goose Up
+-- Scheduled loops (vybe loop --schedule/--window): one row per agent, holding
+-- the next run time so vybe loop schedule status can report it.
+CREATE TABLE IF NOT EXISTS loop_schedules (
+    agent_name TEXT PRIMARY KEY,
+    schedule TEXT NOT NULL DEFAULT '',
+    run_window TEXT NOT NULL DEFAULT '',
+    state TEXT NOT NULL DEFAULT 'waiting',
+    pid INTEGER NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP,
+    last_run_at TIMESTAMP,
+    last_completed INTEGER NOT NULL DEFAULT 0,
+    last_failed INTEGER NOT NULL DEFAULT 0,
+    runs INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS loop_schedules;
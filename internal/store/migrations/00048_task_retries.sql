@@ -0,0 +1,11 @@
+-- +goose Up
+-- Failed attempts per task, and when a failure-blocked task goes back to pending.
+ALTER TABLE tasks ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN retry_at TIMESTAMP;
+
+CREATE INDEX idx_tasks_retry_at ON tasks(retry_at) WHERE retry_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tasks_retry_at;
+ALTER TABLE tasks DROP COLUMN retry_at;
+ALTER TABLE tasks DROP COLUMN attempts;
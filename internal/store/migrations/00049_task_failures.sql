@@ -0,0 +1,19 @@
+-- +goose Up
+-- Structured failure history per task: why each failed attempt failed.
+CREATE TABLE IF NOT EXISTS task_failures (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id TEXT NOT NULL,
+    agent_name TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    error_class TEXT NOT NULL DEFAULT '',
+    attempt INTEGER NOT NULL DEFAULT 0,
+    event_id INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_failures_task ON task_failures(task_id, id);
+CREATE INDEX idx_task_failures_class ON task_failures(error_class);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_failures;
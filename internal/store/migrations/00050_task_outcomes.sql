@@ -0,0 +1,12 @@
+-- +goose Up
+-- How a completed task was resolved (done, partial, wontfix, duplicate,
+-- superseded) and, for superseded or duplicate tasks, the task that replaces it.
+ALTER TABLE tasks ADD COLUMN outcome TEXT;
+ALTER TABLE tasks ADD COLUMN superseded_by TEXT;
+
+CREATE INDEX idx_tasks_superseded_by ON tasks(superseded_by) WHERE superseded_by IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tasks_superseded_by;
+ALTER TABLE tasks DROP COLUMN superseded_by;
+ALTER TABLE tasks DROP COLUMN outcome;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- What each agent can do, and what each task needs, for skill-based routing.
+CREATE TABLE IF NOT EXISTS agent_capabilities (
+    agent_name TEXT NOT NULL,
+    capability TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (agent_name, capability)
+);
+
+CREATE TABLE IF NOT EXISTS task_requirements (
+    task_id TEXT NOT NULL,
+    capability TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, capability),
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_requirements_capability ON task_requirements(capability);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_requirements;
+DROP TABLE IF EXISTS agent_capabilities;
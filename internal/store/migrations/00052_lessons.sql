@@ -0,0 +1,21 @@
+-- +goose Up
+-- Lessons learned, ranked by confidence and by how often briefs surfaced them.
+-- project_id '' marks a global lesson.
+CREATE TABLE IF NOT EXISTS lessons (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT '',
+    confidence REAL NOT NULL DEFAULT 0.5 CHECK (confidence >= 0 AND confidence <= 1),
+    hit_count INTEGER NOT NULL DEFAULT 0,
+    last_hit_at TIMESTAMP,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (project_id, text)
+);
+
+CREATE INDEX idx_lessons_rank ON lessons(project_id, confidence DESC, hit_count DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS lessons;
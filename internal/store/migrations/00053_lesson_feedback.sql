@@ -0,0 +1,8 @@
+-- +goose Up
+-- Feedback tallies from `lesson feedback`; each verdict also moves confidence.
+ALTER TABLE lessons ADD COLUMN helpful_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE lessons ADD COLUMN wrong_count INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE lessons DROP COLUMN wrong_count;
+ALTER TABLE lessons DROP COLUMN helpful_count;
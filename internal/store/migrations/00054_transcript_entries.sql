@@ -0,0 +1,14 @@
+-- +goose Up
+-- Transcript entries already ingested as events, so re-running an ingest of the
+-- same session skips them. event_id is the event the entry became, or the
+-- hook-recorded event it duplicated.
+CREATE TABLE IF NOT EXISTS transcript_entries (
+    session_id TEXT NOT NULL,
+    entry_key TEXT NOT NULL,
+    event_id INTEGER NOT NULL,
+    ingested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, entry_key)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS transcript_entries;
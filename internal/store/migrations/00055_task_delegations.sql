@@ -0,0 +1,23 @@
+-- +goose Up
+-- Tasks an agent handed to a named sub-agent, and how far that sub-agent got.
+-- status: delegated (not started), running (SubagentStart seen), finished
+-- (SubagentStop seen). subagent_id is the hook's agent_id once started.
+CREATE TABLE IF NOT EXISTS task_delegations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id TEXT NOT NULL,
+    agent_name TEXT NOT NULL,
+    subagent TEXT NOT NULL,
+    subagent_id TEXT NOT NULL DEFAULT '',
+    session_id TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'delegated' CHECK (status IN ('delegated', 'running', 'finished')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_delegations_agent ON task_delegations(agent_name, subagent, status, id);
+CREATE INDEX idx_task_delegations_task ON task_delegations(task_id, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_delegations;
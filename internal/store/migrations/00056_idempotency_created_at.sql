@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Idempotency GC prunes completed records by age (idempotency_ttl).
+CREATE INDEX IF NOT EXISTS idx_idempotency_created_at ON idempotency(created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_idempotency_created_at;
+
+-- +goose StatementEnd
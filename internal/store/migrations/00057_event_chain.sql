@@ -0,0 +1,23 @@
+-- +goose Up
+-- Tamper-evident event chain (vybe events enable-chain). Once a row exists in
+-- event_chain, every new event stores the hash of the previous chained event
+-- (prev_hash) and its own hash over its immutable fields; hmac = 1 means the
+-- hashes are HMAC-SHA256 with a key held outside the database. head_* is the
+-- newest chained event, so truncating the log is detectable.
+ALTER TABLE events ADD COLUMN prev_hash TEXT;
+ALTER TABLE events ADD COLUMN hash TEXT;
+
+CREATE TABLE IF NOT EXISTS event_chain (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    hmac INTEGER NOT NULL DEFAULT 0,
+    started_event_id INTEGER NOT NULL DEFAULT 0,
+    head_event_id INTEGER NOT NULL DEFAULT 0,
+    head_hash TEXT NOT NULL DEFAULT '',
+    enabled_by TEXT NOT NULL,
+    enabled_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_chain;
+ALTER TABLE events DROP COLUMN hash;
+ALTER TABLE events DROP COLUMN prev_hash;
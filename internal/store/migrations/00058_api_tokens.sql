@@ -0,0 +1,18 @@
+-- +goose Up
+-- API tokens for a token-protected daemon (vybe daemon start --require-token).
+-- Only the SHA-256 of the secret is stored; the secret is shown once at
+-- creation. role: read (non-mutating commands), agent (plus mutations and
+-- hook handlers), admin (everything, including database and token admin).
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('read', 'agent', 'admin')),
+    token_hash TEXT NOT NULL UNIQUE,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_tokens;
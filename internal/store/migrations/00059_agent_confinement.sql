@@ -0,0 +1,8 @@
+-- +goose Up
+-- Project an agent is confined to (agent init --confine-to-project). NULL
+-- means unconfined. A confined agent's focus project stays on it, and its
+-- task, memory, and event operations are kept inside it.
+ALTER TABLE agent_state ADD COLUMN confined_project_id TEXT;
+
+-- +goose Down
+ALTER TABLE agent_state DROP COLUMN confined_project_id;
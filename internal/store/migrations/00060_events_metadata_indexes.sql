@@ -0,0 +1,17 @@
+-- +goose Up
+-- events list --meta filters on metadata keys through json_extract. Index the
+-- keys hooks write on nearly every event so the common filters (tool, hook
+-- event, exit code) do not scan the whole log. Each expression must match
+-- metaFieldExpr in event_meta_filter.go exactly for the planner to use it;
+-- session_id is already indexed by 00036.
+CREATE INDEX IF NOT EXISTS idx_events_meta_tool_name
+    ON events(CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.tool_name') END);
+CREATE INDEX IF NOT EXISTS idx_events_meta_hook_event
+    ON events(CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.hook_event') END);
+CREATE INDEX IF NOT EXISTS idx_events_meta_exit_code
+    ON events(CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.exit_code') END);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_events_meta_exit_code;
+DROP INDEX IF EXISTS idx_events_meta_hook_event;
+DROP INDEX IF EXISTS idx_events_meta_tool_name;
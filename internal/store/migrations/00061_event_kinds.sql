@@ -0,0 +1,13 @@
+-- +goose Up
+-- Custom event kinds registered with 'vybe events kinds add'. Together with
+-- the kinds vybe writes itself (models.BuiltinEventKinds) they form the
+-- vocabulary strict kinds mode accepts.
+CREATE TABLE IF NOT EXISTS event_kinds (
+    kind TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_kinds;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Claim leases. task claim records who holds an in_progress task and for how
+-- long; memory gc returns it to pending once the lease runs out with no
+-- activity on the task. default_lease_seconds overrides the lease length per
+-- task (known long jobs) and per agent.
+ALTER TABLE tasks ADD COLUMN lease_owner TEXT;
+ALTER TABLE tasks ADD COLUMN lease_seconds INTEGER;
+ALTER TABLE tasks ADD COLUMN lease_expires_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN default_lease_seconds INTEGER;
+ALTER TABLE agent_state ADD COLUMN default_lease_seconds INTEGER;
+CREATE INDEX idx_tasks_lease_expires_at ON tasks(lease_expires_at) WHERE lease_expires_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tasks_lease_expires_at;
+ALTER TABLE agent_state DROP COLUMN default_lease_seconds;
+ALTER TABLE tasks DROP COLUMN default_lease_seconds;
+ALTER TABLE tasks DROP COLUMN lease_expires_at;
+ALTER TABLE tasks DROP COLUMN lease_seconds;
+ALTER TABLE tasks DROP COLUMN lease_owner;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- A promotion staged with --move deletes its source entry when it is approved,
+-- so the fact lives only in the wider scope.
+ALTER TABLE memory_promotions ADD COLUMN move INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE memory_promotions DROP COLUMN move;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- A memory write by one agent over a different value another agent wrote.
+-- The newer value (b) stays live; the conflict stays open until 'memory
+-- resolve' keeps a, keeps b, or writes a merged value.
+CREATE TABLE IF NOT EXISTS memory_conflicts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    scope_id TEXT NOT NULL DEFAULT '',
+    value_a TEXT NOT NULL,
+    agent_a TEXT NOT NULL,
+    value_b TEXT NOT NULL,
+    agent_b TEXT NOT NULL,
+    value_type TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open',
+    resolution TEXT NOT NULL DEFAULT '',
+    resolved_value TEXT,
+    resolved_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX idx_memory_conflicts_open ON memory_conflicts(scope, scope_id, key) WHERE status = 'open';
+CREATE INDEX idx_memory_conflicts_status ON memory_conflicts(status, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS memory_conflicts;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Memory facts the rule-based extractor found in progress and tool events,
+-- staged until 'memory proposals accept' writes them or 'reject' drops them.
+-- One proposal per source event and key, so re-scanning an event is a no-op.
+CREATE TABLE IF NOT EXISTS memory_proposals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    value_type TEXT NOT NULL DEFAULT '',
+    scope TEXT NOT NULL,
+    scope_id TEXT NOT NULL DEFAULT '',
+    rule TEXT NOT NULL,
+    excerpt TEXT NOT NULL DEFAULT '',
+    source_event_id INTEGER NOT NULL,
+    source_task_id TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    proposed_by TEXT NOT NULL DEFAULT '',
+    reviewed_by TEXT NOT NULL DEFAULT '',
+    review_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_memory_proposals_source ON memory_proposals(source_event_id, key);
+CREATE INDEX idx_memory_proposals_status ON memory_proposals(status, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS memory_proposals;
@@ -0,0 +1,27 @@
+package store
+
+import (
+	"path/filepath"
+)
+
+// FilesystemInfo describes the filesystem that holds the database file.
+type FilesystemInfo struct {
+	Path    string `json:"path"`
+	Type    string `json:"type"`
+	Network bool   `json:"network"`
+	Mode    string `json:"mode,omitempty"`
+	Journal string `json:"journal_mode,omitempty"`
+}
+
+// DetectFilesystem reports the filesystem type of the directory holding path
+// and whether it is a network filesystem. SQLite's POSIX locks and WAL shared
+// memory are unreliable on NFS and SMB, so concurrent writers there can corrupt
+// the database silently. Type is "unknown" on platforms without detection.
+func DetectFilesystem(path string) (FilesystemInfo, error) {
+	dir := filepath.Dir(path)
+	fsType, network, err := statFilesystem(dir)
+	if err != nil {
+		return FilesystemInfo{Path: path}, err
+	}
+	return FilesystemInfo{Path: path, Type: fsType, Network: network}, nil
+}
@@ -0,0 +1,33 @@
+//go:build darwin
+
+package store
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// darwinNetworkFS lists f_fstypename values of network filesystems.
+var darwinNetworkFS = map[string]bool{
+	"nfs":    true,
+	"smbfs":  true,
+	"afpfs":  true,
+	"webdav": true,
+	"cifs":   true,
+}
+
+func statFilesystem(dir string) (string, bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return "", false, fmt.Errorf("statfs %s: %w", dir, err)
+	}
+	b := make([]byte, 0, len(st.Fstypename))
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	name := string(b)
+	return name, darwinNetworkFS[name], nil
+}
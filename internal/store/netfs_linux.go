@@ -0,0 +1,45 @@
+//go:build linux
+
+package store
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// linuxFSMagic maps statfs f_type magic numbers to names. Only types that
+// matter for the network check are listed.
+var linuxFSMagic = map[uint32]struct {
+	name    string
+	network bool
+}{
+	0x6969:     {"nfs", true},
+	0x517B:     {"smb", true},
+	0xFF534D42: {"cifs", true},
+	0xFE534D42: {"smb2", true},
+	0x73757245: {"coda", true},
+	0x5346414F: {"afs", true},
+	0x01021997: {"9p", true},
+	0x00C36400: {"ceph", true},
+	0x0BD00BD0: {"lustre", true},
+	0x01161970: {"gfs2", true},
+	0x65735546: {"fuse", false}, // sshfs is fuse too, but so are many local filesystems
+	0xEF53:     {"ext4", false},
+	0x58465342: {"xfs", false},
+	0x9123683E: {"btrfs", false},
+	0x01021994: {"tmpfs", false},
+	0x794C7630: {"overlayfs", false},
+	0x2FC12FC1: {"zfs", false},
+}
+
+func statFilesystem(dir string) (string, bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return "", false, fmt.Errorf("statfs %s: %w", dir, err)
+	}
+	magic := uint32(st.Type) //nolint:gosec // G115: f_type magics are 32-bit
+	if fs, ok := linuxFSMagic[magic]; ok {
+		return fs.name, fs.network, nil
+	}
+	return fmt.Sprintf("0x%x", magic), false, nil
+}
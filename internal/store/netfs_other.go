@@ -0,0 +1,7 @@
+//go:build !linux && !darwin
+
+package store
+
+func statFilesystem(string) (string, bool, error) {
+	return "unknown", false, nil
+}
@@ -0,0 +1,211 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Next action kinds, listed in the order they are suggested.
+const (
+	NextActionReadNotices   = "read_notices"   // vybe left the agent notices (e.g. focus released)
+	NextActionUnblock       = "unblock"        // finish a dependency, or clear a block, to free the focus task
+	NextActionBegin         = "begin"          // focus task is pending and ready
+	NextActionCheckCriteria = "check_criteria" // focus task has unchecked acceptance criteria
+	NextActionComplete      = "complete"       // focus task is in progress with nothing open
+	NextActionOverdue       = "overdue"        // another task is past its deadline
+	NextActionResolveMemory = "resolve_memory" // two agents wrote different values to one memory key
+	NextActionReviewStale   = "review_stale"   // another task sits in_progress without activity
+)
+
+// Next action limits: total suggestions per brief, dependencies and stale
+// tasks listed, and the idle time after which in_progress work counts as stale.
+const (
+	maxNextActions     = 8
+	maxUnblockActions  = 3
+	maxStaleActions    = 3
+	staleInProgressAge = 2 * time.Hour
+)
+
+// NextAction is one machine-generated suggestion in a brief. Command is a
+// ready-to-run CLI line; placeholders are UPPER_CASE, as in the resume prompt.
+type NextAction struct {
+	Kind    string `json:"kind"`
+	TaskID  string `json:"task_id,omitempty"`
+	Reason  string `json:"reason"`
+	Command string `json:"command,omitempty"`
+}
+
+// buildNextActions derives explicit guidance from a built brief: notices
+// first, then what to do with the focus task (or what blocks it), then
+// overdue work, memory conflicts, and stale work elsewhere in the project.
+func buildNextActions(ctx context.Context, db *sql.DB, brief *BriefPacket, agentName, focusProjectID string) []NextAction {
+	if agentName == "" {
+		agentName = "AGENT"
+	}
+	var out []NextAction
+
+	if len(brief.Notices) > 0 {
+		out = append(out, NextAction{
+			Kind:    NextActionReadNotices,
+			Reason:  fmt.Sprintf("%d unread notice(s) from vybe; a task may have been taken from you", len(brief.Notices)),
+			Command: "vybe msg inbox",
+		})
+	}
+
+	focusID := ""
+	if t := brief.Task; t != nil {
+		focusID = t.ID
+		out = append(out, focusTaskActions(brief, t, agentName)...)
+	}
+
+	for _, t := range brief.Overdue {
+		if t.ID == focusID {
+			continue
+		}
+		out = append(out, NextAction{
+			Kind:    NextActionOverdue,
+			TaskID:  t.ID,
+			Reason:  fmt.Sprintf("%q is past its deadline", t.Title),
+			Command: "vybe task get --id=" + t.ID,
+		})
+		break
+	}
+
+	if len(brief.MemoryConflicts) > 0 {
+		c := brief.MemoryConflicts[0]
+		out = append(out, NextAction{
+			Kind: NextActionResolveMemory,
+			Reason: fmt.Sprintf("%s and %s wrote different values to memory %q (%d open conflict(s))",
+				c.AgentA, c.AgentB, c.Key, len(brief.MemoryConflicts)),
+			Command: fmt.Sprintf("vybe memory resolve --agent=%s --request-id=resolve_$RANDOM --id=%d --keep=a|b", agentName, c.ID),
+		})
+	}
+
+	if stale, err := listStaleInProgress(ctx, db, focusProjectID, focusID, time.Now().Add(-staleInProgressAge)); err == nil {
+		for _, s := range stale {
+			out = append(out, NextAction{
+				Kind:    NextActionReviewStale,
+				TaskID:  s.id,
+				Reason:  fmt.Sprintf("%q has been in_progress with no activity since %s", s.title, s.lastActivity.UTC().Format(time.RFC3339)),
+				Command: "vybe task get --id=" + s.id,
+			})
+		}
+	}
+
+	if len(out) > maxNextActions {
+		out = out[:maxNextActions]
+	}
+	return out
+}
+
+func focusTaskActions(brief *BriefPacket, t *models.Task, agentName string) []NextAction {
+	if len(brief.Dependencies) > 0 {
+		var out []NextAction
+		for i, d := range brief.Dependencies {
+			if i == maxUnblockActions {
+				break
+			}
+			a := NextAction{
+				Kind:   NextActionUnblock,
+				TaskID: d.ID,
+				Reason: fmt.Sprintf("complete %q (%s) to unblock %q", d.Title, d.Status, t.Title),
+			}
+			if d.Status == models.TaskStatusPending {
+				a.Command = fmt.Sprintf("vybe task begin --agent=%s --request-id=begin_$RANDOM --id=%s", agentName, d.ID)
+			} else {
+				a.Command = "vybe task get --id=" + d.ID
+			}
+			out = append(out, a)
+		}
+		return out
+	}
+
+	switch t.Status {
+	case models.TaskStatusPending:
+		return []NextAction{{
+			Kind:    NextActionBegin,
+			TaskID:  t.ID,
+			Reason:  fmt.Sprintf("%q is ready to start", t.Title),
+			Command: fmt.Sprintf("vybe task begin --agent=%s --request-id=begin_$RANDOM --id=%s", agentName, t.ID),
+		}}
+	case models.TaskStatusBlocked:
+		reason := fmt.Sprintf("%q is blocked", t.Title)
+		if t.BlockedReason != "" {
+			reason += " (" + string(t.BlockedReason) + ")"
+		}
+		return []NextAction{{
+			Kind:    NextActionUnblock,
+			TaskID:  t.ID,
+			Reason:  reason + "; resolve it, then resume work",
+			Command: fmt.Sprintf("vybe task set-status --agent=%s --request-id=unblock_$RANDOM --id=%s --status=in_progress", agentName, t.ID),
+		}}
+	case models.TaskStatusInProgress:
+		for _, c := range brief.Criteria {
+			if c.Done {
+				continue
+			}
+			return []NextAction{{
+				Kind:    NextActionCheckCriteria,
+				TaskID:  t.ID,
+				Reason:  fmt.Sprintf("acceptance criterion #%d is unchecked: %s", c.ID, truncateRunes(c.Text, 120)),
+				Command: fmt.Sprintf("vybe task criteria check --agent=%s --request-id=check_$RANDOM --id=%s --criterion=%d", agentName, t.ID, c.ID),
+			}}
+		}
+		return []NextAction{{
+			Kind:    NextActionComplete,
+			TaskID:  t.ID,
+			Reason:  fmt.Sprintf("finish %q and mark it completed", t.Title),
+			Command: fmt.Sprintf("vybe task set-status --agent=%s --request-id=done_$RANDOM --id=%s --status=completed", agentName, t.ID),
+		}}
+	}
+	return nil
+}
+
+type staleTask struct {
+	id           string
+	title        string
+	lastActivity time.Time
+}
+
+// listStaleInProgress returns in_progress tasks other than excludeID whose
+// last update or event is older than cutoff, oldest first.
+func listStaleInProgress(ctx context.Context, db *sql.DB, projectID, excludeID string, cutoff time.Time) ([]staleTask, error) {
+	query := `
+		SELECT id, title, last_activity FROM (
+			SELECT t.id, t.title, CAST(strftime('%s', MAX(t.updated_at,
+				COALESCE((SELECT MAX(e.created_at) FROM events e WHERE e.task_id = t.id), t.updated_at))) AS INTEGER) AS last_activity
+			FROM tasks t
+			WHERE t.status = 'in_progress' AND t.id != ?`
+	args := []any{excludeID}
+	if projectID != "" {
+		query += ` AND t.project_id = ?`
+		args = append(args, projectID)
+	}
+	query += `) WHERE last_activity < ? ORDER BY last_activity ASC LIMIT ?`
+	args = append(args, cutoff.Unix(), maxStaleActions)
+
+	var out []staleTask
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query stale tasks: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		out = out[:0]
+		for rows.Next() {
+			var s staleTask
+			var unix int64
+			if err := rows.Scan(&s.id, &s.title, &unix); err != nil {
+				return fmt.Errorf("failed to scan stale task: %w", err)
+			}
+			s.lastActivity = time.Unix(unix, 0)
+			out = append(out, s)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
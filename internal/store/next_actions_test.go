@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBrief_NextActions(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Ship feature", "", "p1", 1)
+	require.NoError(t, err)
+
+	brief, err := BuildBrief(context.Background(), db, task.ID, "p1", "worker")
+	require.NoError(t, err)
+	require.NotEmpty(t, brief.NextActions)
+	assert.Equal(t, NextActionBegin, brief.NextActions[0].Kind)
+	assert.Contains(t, brief.NextActions[0].Command, "--agent=worker")
+	assert.Contains(t, brief.NextActions[0].Command, "--id="+task.ID)
+
+	dep, err := CreateTask(context.Background(), db, "Write migration", "", "p1", 0)
+	require.NoError(t, err)
+	_, err = AddTaskDependencyIdempotent(context.Background(), db, "worker", "dep-1", task.ID, dep.ID)
+	require.NoError(t, err)
+
+	brief, err = BuildBrief(context.Background(), db, task.ID, "p1", "worker")
+	require.NoError(t, err)
+	require.NotEmpty(t, brief.NextActions)
+	assert.Equal(t, NextActionUnblock, brief.NextActions[0].Kind)
+	assert.Equal(t, dep.ID, brief.NextActions[0].TaskID)
+	assert.Contains(t, brief.NextActions[0].Command, "task begin")
+
+	_, _, err = StartTaskAndFocus(context.Background(), db, "worker", dep.ID)
+	require.NoError(t, err)
+	_, _, err = AddTaskCriterionIdempotent(context.Background(), db, "worker", "crit-1", dep.ID, "migration is reversible")
+	require.NoError(t, err)
+
+	brief, err = BuildBrief(context.Background(), db, dep.ID, "p1", "worker")
+	require.NoError(t, err)
+	require.NotEmpty(t, brief.NextActions)
+	assert.Equal(t, NextActionCheckCriteria, brief.NextActions[0].Kind)
+	assert.Contains(t, brief.NextActions[0].Reason, "migration is reversible")
+}
+
+func TestBuildBrief_NextActionsFlagStaleInProgress(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	stale, err := CreateTask(context.Background(), db, "Abandoned", "", "p1", 0)
+	require.NoError(t, err)
+	_, _, err = StartTaskAndFocus(context.Background(), db, "ghost", stale.ID)
+	require.NoError(t, err)
+	fresh, err := CreateTask(context.Background(), db, "Active", "", "p1", 0)
+	require.NoError(t, err)
+	_, _, err = StartTaskAndFocus(context.Background(), db, "worker", fresh.ID)
+	require.NoError(t, err)
+
+	old := time.Now().Add(-3 * staleInProgressAge).UTC().Format("2006-01-02 15:04:05")
+	_, err = db.Exec(`UPDATE tasks SET updated_at = ? WHERE id = ?`, old, stale.ID)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE events SET created_at = ? WHERE task_id = ?`, old, stale.ID)
+	require.NoError(t, err)
+
+	brief, err := BuildBrief(context.Background(), db, fresh.ID, "p1", "worker")
+	require.NoError(t, err)
+
+	var staleIDs []string
+	for _, a := range brief.NextActions {
+		if a.Kind == NextActionReviewStale {
+			staleIDs = append(staleIDs, a.TaskID)
+		}
+	}
+	assert.Equal(t, []string{stale.ID}, staleIDs, "only the idle task is flagged, never the focus task")
+}
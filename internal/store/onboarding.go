@@ -0,0 +1,171 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// Onboarding section caps. The onboarding brief is sent once per agent per
+// project, so it is larger than the incremental brief but still bounded.
+const (
+	onboardingMemoryLimit = 30
+	onboardingLessonLimit = 10
+	onboardingReadyLimit  = 10
+)
+
+// OnboardingBrief is the expanded "read-me first" context attached to a brief
+// when an agent with a fresh cursor resumes into a project.
+type OnboardingBrief struct {
+	ProjectID     string             `json:"project_id"`
+	ProjectMemory []*models.Memory   `json:"project_memory"`
+	Lessons       []*models.Memory   `json:"lessons"`
+	TaskGraph     *TaskGraphOverview `json:"task_graph"`
+	Conventions   *ConventionsDoc    `json:"conventions,omitempty"`
+}
+
+// TaskGraphOverview summarizes a project's task graph without listing every task.
+type TaskGraphOverview struct {
+	Counts          *TaskStatusCounts `json:"counts"`
+	DependencyEdges int               `json:"dependency_edges"`
+	WaitingOnDeps   int               `json:"waiting_on_deps"`
+	Ready           []PipelineTask    `json:"ready"`
+	InProgress      []PipelineTask    `json:"in_progress"`
+}
+
+// ConventionsDoc is the project's conventions file, read from the workspace by
+// the caller (the store never touches the filesystem).
+type ConventionsDoc struct {
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// BuildOnboardingBrief gathers project memory, top lessons, and a task graph
+// overview for projectID. Conventions are left for the caller to fill.
+func BuildOnboardingBrief(ctx context.Context, db *sql.DB, projectID string) (_ *OnboardingBrief, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	ob := &OnboardingBrief{ProjectID: projectID}
+
+	memory, err := ListMemory(ctx, db, string(models.MemoryScopeProject), projectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(memory) > onboardingMemoryLimit {
+		memory = memory[:onboardingMemoryLimit]
+	}
+	ob.ProjectMemory = memory
+
+	if ob.Lessons, err = fetchTopLessons(ctx, db, projectID, onboardingLessonLimit); err != nil {
+		return nil, err
+	}
+	if ob.TaskGraph, err = buildTaskGraphOverview(ctx, db, projectID); err != nil {
+		return nil, err
+	}
+	return ob, nil
+}
+
+// fetchTopLessons returns lesson memories from global and project scope,
+// pinned and most-used first.
+func fetchTopLessons(ctx context.Context, db *sql.DB, projectID string, limit int) ([]*models.Memory, error) {
+	var lessons []*models.Memory
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT id, key, value, value_type, scope, scope_id, expires_at, updated_at, created_at, access_count, last_accessed_at, pinned, kind, half_life_days, source_event_id, source_task_id
+			FROM memory
+			WHERE kind = 'lesson'
+			  AND (scope = 'global' OR (scope = 'project' AND scope_id = ?))
+			  AND (pinned = 1 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			ORDER BY pinned DESC, access_count DESC, updated_at DESC
+			LIMIT ?
+		`, projectID, limit)
+		if err != nil {
+			return fmt.Errorf("failed to query lessons: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		lessons = make([]*models.Memory, 0)
+		for rows.Next() {
+			var mem models.Memory
+			var sourceTaskID sql.NullString
+			if err := rows.Scan(&mem.ID, &mem.Key, &mem.Value, &mem.ValueType, &mem.Scope, &mem.ScopeID, &mem.ExpiresAt, &mem.UpdatedAt, &mem.CreatedAt, &mem.AccessCount, &mem.LastAccessedAt, &mem.Pinned, &mem.Kind, &mem.HalfLifeDays, &mem.SourceEventID, &sourceTaskID); err != nil {
+				return fmt.Errorf("failed to scan lesson: %w", err)
+			}
+			mem.SourceTaskID = sourceTaskID.String
+			lessons = append(lessons, &mem)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lessons, nil
+}
+
+// unfinishedDepSQL matches a dependency of t that is not completed.
+const unfinishedDepSQL = `EXISTS (
+	SELECT 1 FROM task_dependencies d JOIN tasks dep ON dep.id = d.depends_on_task_id
+	WHERE d.task_id = t.id AND dep.status != 'completed')`
+
+func buildTaskGraphOverview(ctx context.Context, db *sql.DB, projectID string) (*TaskGraphOverview, error) {
+	counts, err := GetTaskStatusCounts(ctx, db, projectID)
+	if err != nil {
+		return nil, err
+	}
+	g := &TaskGraphOverview{Counts: counts}
+
+	err = RetryWithBackoff(ctx, func() error {
+		return db.QueryRowContext(ctx, `
+			SELECT
+				(SELECT COUNT(*) FROM task_dependencies d JOIN tasks t ON t.id = d.task_id WHERE t.project_id = ?),
+				(SELECT COUNT(*) FROM tasks t WHERE t.project_id = ? AND t.status = 'pending' AND `+unfinishedDepSQL+`)
+		`, projectID, projectID).Scan(&g.DependencyEdges, &g.WaitingOnDeps)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize task graph: %w", err)
+	}
+
+	if g.Ready, err = queryPipelineTasks(ctx, db, `
+		SELECT t.id, t.title, t.priority FROM tasks t
+		WHERE t.project_id = ? AND t.status = 'pending' AND NOT `+unfinishedDepSQL+`
+		ORDER BY t.priority DESC, t.created_at ASC LIMIT ?`, projectID, onboardingReadyLimit); err != nil {
+		return nil, err
+	}
+	if g.InProgress, err = queryPipelineTasks(ctx, db, `
+		SELECT t.id, t.title, t.priority FROM tasks t
+		WHERE t.project_id = ? AND t.status = 'in_progress'
+		ORDER BY t.priority DESC, t.updated_at DESC LIMIT ?`, projectID, onboardingReadyLimit); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func queryPipelineTasks(ctx context.Context, db *sql.DB, query string, args ...any) ([]PipelineTask, error) {
+	var out []PipelineTask
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query tasks: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = make([]PipelineTask, 0)
+		for rows.Next() {
+			var p PipelineTask
+			if err := rows.Scan(&p.ID, &p.Title, &p.Priority); err != nil {
+				return fmt.Errorf("failed to scan task: %w", err)
+			}
+			out = append(out, p)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // attemptResult holds the outcome of a single idempotent operation attempt.
@@ -87,6 +89,7 @@ func defaultRetryPredicate(err error) bool {
 
 // RunIdempotentWithRetry executes an idempotent operation with bounded retry on caller-defined retryable errors.
 // Returns replayed=true when the result is loaded from an existing idempotency record.
+// When tracing, the run is one span recording the replay and retry counts.
 //
 //nolint:revive // argument-limit: generics + idempotency params + retry config all required together
 func RunIdempotentWithRetry[T any](
@@ -101,7 +104,17 @@ func RunIdempotentWithRetry[T any](
 		maxAttempts = 1
 	}
 
+	ctx, span := telemetry.Start(ctx, "idempotent "+command)
+	retries := 0
+	defer func() {
+		span.Set("vybe.idempotency.command", command)
+		span.Set("vybe.idempotency.replayed", replayed)
+		span.Set("vybe.retries", retries)
+		span.End(err)
+	}()
+
 	for attempt := 0; attempt < maxAttempts; attempt++ {
+		retries = attempt
 		r := attemptIdempotent(ctx, db, agentName, requestID, command, operation)
 		if r.done {
 			return r.result, r.replayed, r.err
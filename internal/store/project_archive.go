@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// activeProjectTaskClause keeps tasks with no project or an unarchived one.
+// Applied wherever vybe picks work on the agent's behalf (resume focus
+// selection, task next) so archived projects never surface new work.
+const activeProjectTaskClause = `(project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))`
+
+// isProjectArchived reports whether projectID names an archived project.
+// Unknown and empty IDs are not archived.
+func isProjectArchived(q interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}, projectID string) (bool, error) {
+	if projectID == "" {
+		return false, nil
+	}
+	var archived bool
+	err := q.QueryRowContext(context.Background(), `
+		SELECT archived_at IS NOT NULL FROM projects WHERE id = ?
+	`, projectID).Scan(&archived)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check project archive state: %w", err)
+	}
+	return archived, nil
+}
+
+// ensureTaskClaimableTx rejects claims on tasks that belong to an archived project.
+func ensureTaskClaimableTx(tx *sql.Tx, taskID string) error {
+	var projectID sql.NullString
+	err := tx.QueryRowContext(context.Background(), `SELECT project_id FROM tasks WHERE id = ?`, taskID).Scan(&projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+	archived, err := isProjectArchived(tx, projectID.String)
+	if err != nil {
+		return err
+	}
+	if archived {
+		return fmt.Errorf("task %s belongs to archived project %s; unarchive the project to work on it", taskID, projectID.String)
+	}
+	return nil
+}
+
+// SetProjectArchivedIdempotent archives (archived=true) or unarchives a project
+// once per (agent_name, request_id) and returns the event ID. Archiving also
+// drops agent and session focus pointing at the project or its tasks, so the
+// next resume moves on to active work.
+func SetProjectArchivedIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, archived bool) (int64, error) {
+	if agentName == "" {
+		return 0, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return 0, errors.New("request id is required")
+	}
+	if projectID == "" {
+		return 0, errors.New("project ID is required")
+	}
+
+	command, kind, verb := "project.unarchive", models.EventKindProjectUnarchived, "unarchived"
+	if archived {
+		command, kind, verb = "project.archive", models.EventKindProjectArchived, "archived"
+	}
+
+	type idemResult struct {
+		EventID int64 `json:"event_id"`
+	}
+
+	r, err := RunIdempotent(ctx, db, agentName, requestID, command, func(tx *sql.Tx) (idemResult, error) {
+		current, err := isProjectArchived(tx, projectID)
+		if err != nil {
+			return idemResult{}, err
+		}
+		if err := validateProjectExistsTx(tx, projectID); err != nil {
+			return idemResult{}, err
+		}
+		if current == archived {
+			return idemResult{}, fmt.Errorf("project %s is already %s", projectID, verb)
+		}
+
+		update := `UPDATE projects SET archived_at = NULL WHERE id = ?`
+		if archived {
+			update = `UPDATE projects SET archived_at = CURRENT_TIMESTAMP WHERE id = ?`
+		}
+		if _, err := tx.ExecContext(ctx, update, projectID); err != nil {
+			return idemResult{}, fmt.Errorf("failed to update project: %w", err)
+		}
+
+		if archived {
+			if err := clearProjectFocusTx(tx, projectID, "project "+projectID+" was archived"); err != nil {
+				return idemResult{}, err
+			}
+		}
+
+		eventID, err := InsertEventTx(tx, kind, agentName, "", fmt.Sprintf("Project %s: %s", verb, projectID), "")
+		if err != nil {
+			return idemResult{}, fmt.Errorf("failed to append event: %w", err)
+		}
+		return idemResult{EventID: eventID}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return r.EventID, nil
+}
+
+// clearProjectFocusTx drops agent and session focus on projectID and on its
+// tasks, leaving each agent that lost a task an inbox notice naming reason.
+func clearProjectFocusTx(tx *sql.Tx, projectID, reason string) error {
+	holders, err := focusHoldersTx(tx, `t.project_id = ?`, projectID)
+	if err != nil {
+		return err
+	}
+	for _, table := range []string{"agent_state", "agent_session_state"} {
+		if _, err := tx.ExecContext(context.Background(), `
+			UPDATE `+table+` SET focus_project_id = NULL WHERE focus_project_id = ?
+		`, projectID); err != nil {
+			return fmt.Errorf("failed to clear %s project focus: %w", table, err)
+		}
+		if _, err := tx.ExecContext(context.Background(), `
+			UPDATE `+table+` SET focus_task_id = NULL
+			WHERE focus_task_id IN (SELECT id FROM tasks WHERE project_id = ?)
+		`, projectID); err != nil {
+			return fmt.Errorf("failed to clear %s task focus: %w", table, err)
+		}
+	}
+	return notifyFocusLostTx(tx, holders, reason)
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectArchive_HidesFromListsResumeAndClaims(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	archived, err := CreateProject(context.Background(), db, "Old", "")
+	require.NoError(t, err)
+	active, err := CreateProject(context.Background(), db, "Live", "")
+	require.NoError(t, err)
+
+	oldTask, err := CreateTask(context.Background(), db, "Old work", "", archived.ID, 10)
+	require.NoError(t, err)
+	liveTask, err := CreateTask(context.Background(), db, "Live work", "", active.ID, 1)
+	require.NoError(t, err)
+
+	_, err = SetAgentFocusProjectWithEventIdempotent(context.Background(), db, "agent1", "focus-1", archived.ID)
+	require.NoError(t, err)
+
+	_, err = SetProjectArchivedIdempotent(context.Background(), db, "agent1", "archive-1", archived.ID, true)
+	require.NoError(t, err)
+	_, err = SetProjectArchivedIdempotent(context.Background(), db, "agent1", "archive-2", archived.ID, true)
+	require.ErrorContains(t, err, "already archived")
+
+	projects, err := ListProjects(context.Background(), db)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, active.ID, projects[0].ID)
+
+	all, err := ListProjectsIncludingArchived(context.Background(), db)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	got, err := GetProject(context.Background(), db, archived.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got.ArchivedAt)
+
+	state, err := LoadOrCreateAgentState(context.Background(), db, "agent1")
+	require.NoError(t, err)
+	assert.Empty(t, state.FocusProjectID, "archiving clears focus on the project")
+
+	next, err := ListNextTasks(context.Background(), db, "", time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, liveTask.ID, next[0].ID)
+
+	focus, err := DetermineFocusTask(context.Background(), db, "agent1", "", nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, liveTask.ID, focus.TaskID, "higher-priority task in archived project is skipped")
+
+	_, _, err = StartTaskAndFocus(context.Background(), db, "agent1", oldTask.ID)
+	require.ErrorContains(t, err, "archived project")
+
+	_, err = SetProjectArchivedIdempotent(context.Background(), db, "agent1", "unarchive-1", archived.ID, false)
+	require.NoError(t, err)
+	_, _, err = StartTaskAndFocus(context.Background(), db, "agent1", oldTask.ID)
+	require.NoError(t, err)
+}
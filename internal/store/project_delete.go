@@ -9,7 +9,7 @@ import (
 
 // DeleteProjectTx deletes a project by ID inside an existing transaction.
 // Before deleting, it clears project_id references in tasks, events, agent_state, and artifacts,
-// and deletes project-scoped memory entries, to maintain referential integrity
+// and deletes project-scoped memory entries and stats snapshots, to maintain referential integrity
 // (no FK constraints on these columns).
 func DeleteProjectTx(tx *sql.Tx, projectID string) error {
 	if projectID == "" {
@@ -40,6 +40,20 @@ func DeleteProjectTx(tx *sql.Tx, projectID string) error {
 	if _, err := tx.ExecContext(context.Background(), `DELETE FROM memory WHERE scope = 'project' AND scope_id = ?`, projectID); err != nil {
 		return fmt.Errorf("failed to delete project-scoped memory: %w", err)
 	}
+	if _, err := tx.ExecContext(context.Background(), `DELETE FROM memory_history WHERE scope = 'project' AND scope_id = ?`, projectID); err != nil {
+		return fmt.Errorf("failed to delete project-scoped memory history: %w", err)
+	}
+	if _, err := tx.ExecContext(context.Background(), `DELETE FROM memory_conflicts WHERE scope = 'project' AND scope_id = ?`, projectID); err != nil {
+		return fmt.Errorf("failed to delete project-scoped memory conflicts: %w", err)
+	}
+	if _, err := tx.ExecContext(context.Background(), `DELETE FROM memory_proposals WHERE scope = 'project' AND scope_id = ?`, projectID); err != nil {
+		return fmt.Errorf("failed to delete project-scoped memory proposals: %w", err)
+	}
+
+	// Delete stats snapshots
+	if _, err := tx.ExecContext(context.Background(), `DELETE FROM stats_history WHERE project_id = ?`, projectID); err != nil {
+		return fmt.Errorf("failed to delete project stats history: %w", err)
+	}
 
 	// Delete the project
 	result, err := tx.ExecContext(context.Background(), `DELETE FROM projects WHERE id = ?`, projectID)
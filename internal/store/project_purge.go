@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// projectPurgeBatchSize bounds the rows deleted per transaction so purging a
+// large project never holds the write lock for long.
+const projectPurgeBatchSize = 500
+
+// projectTasksSQL selects the IDs of a project's tasks; takes one ? arg.
+const projectTasksSQL = `SELECT id FROM tasks WHERE project_id = ?`
+
+// purgeStep is one table a project purge empties. where takes the project ID
+// for every ? placeholder (args is the placeholder count). Steps run in order:
+// artifacts before events and tasks because artifacts reference both.
+type purgeStep struct {
+	name  string
+	table string
+	where string
+	args  int
+}
+
+var projectPurgeSteps = []purgeStep{
+	{"artifacts", "artifacts", `project_id = ? OR task_id IN (` + projectTasksSQL + `)`, 2},
+	{"events", "events", `project_id = ? OR task_id IN (` + projectTasksSQL + `)`, 2},
+	{"memory", "memory", `(scope = 'project' AND scope_id = ?) OR (scope = 'task' AND scope_id IN (` + projectTasksSQL + `))`, 2},
+	{"memory_history", "memory_history", `(scope = 'project' AND scope_id = ?) OR (scope = 'task' AND scope_id IN (` + projectTasksSQL + `))`, 2},
+	{"memory_conflicts", "memory_conflicts", `(scope = 'project' AND scope_id = ?) OR (scope = 'task' AND scope_id IN (` + projectTasksSQL + `))`, 2},
+	{"memory_proposals", "memory_proposals", `scope = 'project' AND scope_id = ?`, 1},
+	{"sessions", "sessions", `project_id = ?`, 1},
+	{"stats_history", "stats_history", `project_id = ?`, 1},
+	{"tasks", "tasks", `project_id = ?`, 1},
+}
+
+// ProjectPurgeReport counts the rows a project purge removes, per table.
+// DryRun reports are computed without deleting anything.
+type ProjectPurgeReport struct {
+	ProjectID string           `json:"project_id"`
+	DryRun    bool             `json:"dry_run"`
+	Counts    map[string]int64 `json:"counts"`
+	Total     int64            `json:"total"`
+	Batches   int              `json:"batches,omitempty"`
+	EventID   int64            `json:"event_id,omitempty"`
+}
+
+func newProjectPurgeReport(projectID string, dryRun bool) *ProjectPurgeReport {
+	r := &ProjectPurgeReport{ProjectID: projectID, DryRun: dryRun, Counts: make(map[string]int64, len(projectPurgeSteps))}
+	for _, step := range projectPurgeSteps {
+		r.Counts[step.name] = 0
+	}
+	return r
+}
+
+func (r *ProjectPurgeReport) add(name string, n int64) {
+	r.Counts[name] += n
+	r.Total += n
+}
+
+func purgeArgs(projectID string, n int) []any {
+	args := make([]any, n)
+	for i := range args {
+		args[i] = projectID
+	}
+	return args
+}
+
+// PreviewProjectPurge reports what PurgeProjectIdempotent would delete.
+func PreviewProjectPurge(ctx context.Context, db *sql.DB, projectID string) (*ProjectPurgeReport, error) {
+	if projectID == "" {
+		return nil, errors.New("project ID is required")
+	}
+	if _, err := GetProject(ctx, db, projectID); err != nil {
+		return nil, err
+	}
+
+	report := newProjectPurgeReport(projectID, true)
+	for _, step := range projectPurgeSteps {
+		var n int64
+		err := RetryWithBackoff(ctx, func() error {
+			return db.QueryRowContext(ctx,
+				`SELECT COUNT(*) FROM `+step.table+` WHERE `+step.where, purgeArgs(projectID, step.args)...,
+			).Scan(&n)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("count %s: %w", step.name, err)
+		}
+		report.add(step.name, n)
+	}
+	return report, nil
+}
+
+// PurgeProjectIdempotent deletes a project together with its tasks, events,
+// memory, artifacts, sessions, and stats. Rows are deleted in batches of
+// projectPurgeBatchSize, each in its own transaction, so an interrupted purge
+// leaves a smaller project that a rerun finishes. The final transaction
+// removes the project row and appends a project_purged event once per
+// (agent_name, request_id).
+func PurgeProjectIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) (*ProjectPurgeReport, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if requestID == "" {
+		return nil, errors.New("request id is required")
+	}
+	if projectID == "" {
+		return nil, errors.New("project ID is required")
+	}
+
+	var chained bool
+	if err := Transact(ctx, db, func(tx *sql.Tx) (err error) {
+		chained, err = eventChainEnabledTx(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if chained {
+		return nil, errors.New("the event chain is on; purging a project would delete chained events (retire it with 'vybe project archive' instead)")
+	}
+
+	report := newProjectPurgeReport(projectID, false)
+
+	// A retry after a completed purge finds no project; skip straight to the
+	// idempotent finish, which replays the recorded result.
+	if _, err := GetProject(ctx, db, projectID); err == nil {
+		if err := Transact(ctx, db, func(tx *sql.Tx) error {
+			return clearProjectFocusTx(tx, projectID, "project "+projectID+" is being purged")
+		}); err != nil {
+			return nil, err
+		}
+		for _, step := range projectPurgeSteps {
+			if err := purgeStepBatches(ctx, db, projectID, step, report); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	final, err := RunIdempotent(ctx, db, agentName, requestID, "project.purge", func(tx *sql.Tx) (ProjectPurgeReport, error) {
+		if err := DeleteProjectTx(tx, projectID); err != nil {
+			return ProjectPurgeReport{}, err
+		}
+		eventID, err := InsertEventTx(tx, models.EventKindProjectPurged, agentName, "",
+			fmt.Sprintf("Project purged: %s (%d rows)", projectID, report.Total), "")
+		if err != nil {
+			return ProjectPurgeReport{}, fmt.Errorf("failed to append event: %w", err)
+		}
+		out := *report
+		out.Batches++
+		out.EventID = eventID
+		return out, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &final, nil
+}
+
+// purgeStepBatches deletes step's rows batch by batch until none remain.
+func purgeStepBatches(ctx context.Context, db *sql.DB, projectID string, step purgeStep, report *ProjectPurgeReport) error {
+	args := append(purgeArgs(projectID, step.args), projectPurgeBatchSize)
+	for {
+		var n int64
+		err := Transact(ctx, db, func(tx *sql.Tx) error {
+			res, err := tx.ExecContext(ctx, `
+				DELETE FROM `+step.table+`
+				WHERE rowid IN (SELECT rowid FROM `+step.table+` WHERE `+step.where+` LIMIT ?)
+			`, args...)
+			if err != nil {
+				return err
+			}
+			n, err = res.RowsAffected()
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("purge %s: %w", step.name, err)
+		}
+		if n == 0 {
+			return nil
+		}
+		report.add(step.name, n)
+		report.Batches++
+		if n < projectPurgeBatchSize {
+			return nil
+		}
+	}
+}
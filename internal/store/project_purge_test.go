@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeProject_DryRunThenCascade(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, err := CreateProject(context.Background(), db, "Doomed", "")
+	require.NoError(t, err)
+	other, err := CreateProject(context.Background(), db, "Keeper", "")
+	require.NoError(t, err)
+
+	task, err := CreateTask(context.Background(), db, "Doomed task", "", project.ID, 0)
+	require.NoError(t, err)
+	keep, err := CreateTask(context.Background(), db, "Kept task", "", other.ID, 0)
+	require.NoError(t, err)
+
+	appendEventWithProject(t, db, "progress", "agent1", project.ID, task.ID, "work")
+	appendEventWithProject(t, db, "progress", "agent1", other.ID, keep.ID, "other work")
+	_, _, err = AddArtifact(context.Background(), db, "agent1", task.ID, "/tmp/out.txt", "text/plain")
+	require.NoError(t, err)
+	require.NoError(t, SetMemory(context.Background(), db, "k", "v", "string", "project", project.ID, nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "t", "v", "string", "task", task.ID, nil, false, "", nil))
+
+	preview, err := PreviewProjectPurge(context.Background(), db, project.ID)
+	require.NoError(t, err)
+	assert.True(t, preview.DryRun)
+	assert.Equal(t, int64(1), preview.Counts["tasks"])
+	assert.Equal(t, int64(1), preview.Counts["artifacts"])
+	assert.Equal(t, int64(2), preview.Counts["memory"])
+	assert.Positive(t, preview.Counts["events"])
+
+	_, err = GetTask(context.Background(), db, task.ID)
+	require.NoError(t, err, "dry run deletes nothing")
+
+	report, err := PurgeProjectIdempotent(context.Background(), db, "agent1", "purge-1", project.ID)
+	require.NoError(t, err)
+	assert.False(t, report.DryRun)
+	assert.Equal(t, preview.Total, report.Total)
+	assert.NotZero(t, report.EventID)
+
+	_, err = GetProject(context.Background(), db, project.ID)
+	require.Error(t, err)
+	_, err = GetTask(context.Background(), db, task.ID)
+	require.Error(t, err)
+	mem, err := GetMemory(context.Background(), db, "t", "task", task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, mem)
+
+	var remaining int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM events WHERE project_id = ? OR task_id = ?`, project.ID, task.ID).Scan(&remaining))
+	assert.Zero(t, remaining)
+
+	_, err = GetTask(context.Background(), db, keep.ID)
+	require.NoError(t, err, "other projects are untouched")
+
+	replay, err := PurgeProjectIdempotent(context.Background(), db, "agent1", "purge-1", project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, report.EventID, replay.EventID)
+}
@@ -15,10 +15,10 @@ func generateProjectID() string {
 }
 
 // CreateProject inserts a new project and returns the created record.
-func CreateProject(db *sql.DB, name, metadata string) (*models.Project, error) {
+func CreateProject(ctx context.Context, db *sql.DB, name, metadata string) (*models.Project, error) {
 	var project *models.Project
 
-	err := Transact(context.Background(), db, func(tx *sql.Tx) error {
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
 		createdProject, err := CreateProjectTx(tx, name, metadata)
 		if err != nil {
 			return err
@@ -58,26 +58,21 @@ func CreateProjectTx(tx *sql.Tx, name, metadata string) (*models.Project, error)
 		return nil, errors.New("failed to insert project: no rows affected")
 	}
 
-	var project models.Project
-	var metaCol sql.NullString
-	err = tx.QueryRowContext(context.Background(), `
-		SELECT id, name, metadata, created_at
+	project, err := scanProject(tx.QueryRowContext(context.Background(), `
+		SELECT `+projectColumns+`
 		FROM projects WHERE id = ?
-	`, projectID).Scan(&project.ID, &project.Name, &metaCol, &project.CreatedAt)
+	`, projectID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch created project: %w", err)
 	}
-	if metaCol.Valid {
-		project.Metadata = metaCol.String
-	}
 
-	return &project, nil
+	return project, nil
 }
 
 // EnsureProjectByID creates a project with the given ID if it doesn't exist,
 // then returns the (possibly pre-existing) row. Uses INSERT OR IGNORE for
 // idempotent creation.
-func EnsureProjectByID(db *sql.DB, id, name string) (*models.Project, error) {
+func EnsureProjectByID(ctx context.Context, db *sql.DB, id, name string) (*models.Project, error) {
 	if id == "" {
 		return nil, errors.New("project id is required")
 	}
@@ -85,43 +80,42 @@ func EnsureProjectByID(db *sql.DB, id, name string) (*models.Project, error) {
 		return nil, errors.New("project name is required")
 	}
 
-	var project models.Project
-	err := Transact(context.Background(), db, func(tx *sql.Tx) error {
-		if _, err := tx.ExecContext(context.Background(), `
+	var project *models.Project
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
 			INSERT OR IGNORE INTO projects (id, name, created_at)
 			VALUES (?, ?, CURRENT_TIMESTAMP)
 		`, id, name); err != nil {
 			return fmt.Errorf("failed to ensure project: %w", err)
 		}
 
-		var metaCol sql.NullString
-		if err := tx.QueryRowContext(context.Background(), `
-			SELECT id, name, metadata, created_at
+		p, err := scanProject(tx.QueryRowContext(ctx, `
+			SELECT `+projectColumns+`
 			FROM projects WHERE id = ?
-		`, id).Scan(&project.ID, &project.Name, &metaCol, &project.CreatedAt); err != nil {
+		`, id))
+		if err != nil {
 			return fmt.Errorf("failed to fetch project: %w", err)
 		}
-		if metaCol.Valid {
-			project.Metadata = metaCol.String
-		}
+		project = p
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &project, nil
+	return project, nil
 }
 
-// GetProject retrieves a project by ID.
-func GetProject(db *sql.DB, projectID string) (*models.Project, error) {
-	var project models.Project
-	var metadata sql.NullString
+// GetProject retrieves a project by ID. Archived projects are returned too.
+func GetProject(ctx context.Context, db *sql.DB, projectID string) (*models.Project, error) {
+	var project *models.Project
 
-	err := RetryWithBackoff(context.Background(), func() error {
-		return db.QueryRowContext(context.Background(), `
-			SELECT id, name, metadata, created_at
+	err := RetryWithBackoff(ctx, func() error {
+		p, err := scanProject(db.QueryRowContext(ctx, `
+			SELECT `+projectColumns+`
 			FROM projects WHERE id = ?
-		`, projectID).Scan(&project.ID, &project.Name, &metadata, &project.CreatedAt)
+		`, projectID))
+		project = p
+		return err
 	})
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -131,23 +125,30 @@ func GetProject(db *sql.DB, projectID string) (*models.Project, error) {
 		return nil, fmt.Errorf("failed to query project: %w", err)
 	}
 
-	if metadata.Valid {
-		project.Metadata = metadata.String
-	}
+	return project, nil
+}
 
-	return &project, nil
+// ListProjects retrieves active (not archived) projects ordered by creation time (newest first).
+func ListProjects(ctx context.Context, db *sql.DB) ([]*models.Project, error) {
+	return listProjects(ctx, db, false)
 }
 
-// ListProjects retrieves all projects ordered by creation time (newest first).
-func ListProjects(db *sql.DB) ([]*models.Project, error) {
+// ListProjectsIncludingArchived is ListProjects plus archived projects.
+func ListProjectsIncludingArchived(ctx context.Context, db *sql.DB) ([]*models.Project, error) {
+	return listProjects(ctx, db, true)
+}
+
+func listProjects(ctx context.Context, db *sql.DB, includeArchived bool) ([]*models.Project, error) {
 	var projects []*models.Project
 
-	err := RetryWithBackoff(context.Background(), func() error {
-		rows, err := db.QueryContext(context.Background(), `
-			SELECT id, name, metadata, created_at
-			FROM projects
-			ORDER BY created_at DESC
-		`)
+	query := `SELECT ` + projectColumns + ` FROM projects`
+	if !includeArchived {
+		query += ` WHERE archived_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC`
+
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query)
 		if err != nil {
 			return fmt.Errorf("failed to query projects: %w", err)
 		}
@@ -155,16 +156,11 @@ func ListProjects(db *sql.DB) ([]*models.Project, error) {
 
 		projects = make([]*models.Project, 0)
 		for rows.Next() {
-			var p models.Project
-			var metadata sql.NullString
-			err := rows.Scan(&p.ID, &p.Name, &metadata, &p.CreatedAt)
+			p, err := scanProject(rows)
 			if err != nil {
 				return fmt.Errorf("failed to scan project row: %w", err)
 			}
-			if metadata.Valid {
-				p.Metadata = metadata.String
-			}
-			projects = append(projects, &p)
+			projects = append(projects, p)
 		}
 
 		return rows.Err()
@@ -176,3 +172,21 @@ func ListProjects(db *sql.DB) ([]*models.Project, error) {
 
 	return projects, nil
 }
+
+const projectColumns = `id, name, metadata, created_at, archived_at`
+
+// scanProject scans one row selected with projectColumns.
+func scanProject(row interface{ Scan(dest ...any) error }) (*models.Project, error) {
+	var p models.Project
+	var metadata sql.NullString
+	var archivedAt sql.NullTime
+	if err := row.Scan(&p.ID, &p.Name, &metadata, &p.CreatedAt, &archivedAt); err != nil {
+		return nil, err
+	}
+	p.Metadata = scanNullString(metadata)
+	if archivedAt.Valid {
+		t := archivedAt.Time.UTC()
+		p.ArchivedAt = &t
+	}
+	return &p, nil
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"regexp"
 	"testing"
 
@@ -19,7 +20,7 @@ func TestCreateAndGetProject(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := CreateProject(db, "Test Project", `{"repo":"github.com/test/repo"}`)
+	project, err := CreateProject(context.Background(), db, "Test Project", `{"repo":"github.com/test/repo"}`)
 	require.NoError(t, err)
 	require.NotNil(t, project)
 
@@ -29,7 +30,7 @@ func TestCreateAndGetProject(t *testing.T) {
 	assert.False(t, project.CreatedAt.IsZero())
 
 	// Round-trip via GetProject
-	fetched, err := GetProject(db, project.ID)
+	fetched, err := GetProject(context.Background(), db, project.ID)
 	require.NoError(t, err)
 	assert.Equal(t, project.ID, fetched.ID)
 	assert.Equal(t, project.Name, fetched.Name)
@@ -40,7 +41,7 @@ func TestCreateProjectNoMetadata(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := CreateProject(db, "Bare Project", "")
+	project, err := CreateProject(context.Background(), db, "Bare Project", "")
 	require.NoError(t, err)
 	assert.Equal(t, "Bare Project", project.Name)
 	assert.Equal(t, "", project.Metadata)
@@ -50,7 +51,7 @@ func TestGetProjectNotFound(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := GetProject(db, "proj_nonexistent")
+	project, err := GetProject(context.Background(), db, "proj_nonexistent")
 	assert.Error(t, err)
 	assert.Nil(t, project)
 	assert.Contains(t, err.Error(), "project not found")
@@ -60,7 +61,7 @@ func TestListProjectsEmpty(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	projects, err := ListProjects(db)
+	projects, err := ListProjects(context.Background(), db)
 	require.NoError(t, err)
 	assert.Empty(t, projects)
 }
@@ -69,16 +70,16 @@ func TestListProjectsMultiple(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := CreateProject(db, "Alpha", "")
+	_, err := CreateProject(context.Background(), db, "Alpha", "")
 	require.NoError(t, err)
 
-	_, err = CreateProject(db, "Beta", `{"lang":"go"}`)
+	_, err = CreateProject(context.Background(), db, "Beta", `{"lang":"go"}`)
 	require.NoError(t, err)
 
-	_, err = CreateProject(db, "Gamma", "")
+	_, err = CreateProject(context.Background(), db, "Gamma", "")
 	require.NoError(t, err)
 
-	projects, err := ListProjects(db)
+	projects, err := ListProjects(context.Background(), db)
 	require.NoError(t, err)
 	assert.Len(t, projects, 3)
 
@@ -95,20 +96,20 @@ func TestGetProject_RetryOnBusy(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := CreateProject(db, "Retry Test", "")
+	project, err := CreateProject(context.Background(), db, "Retry Test", "")
 	require.NoError(t, err)
 
 	// Call GetProject multiple times to exercise the RetryWithBackoff wrapper.
 	// With single-connection SQLite, any leaked state would cause deadlock.
 	for i := 0; i < 10; i++ {
-		got, getErr := GetProject(db, project.ID)
+		got, getErr := GetProject(context.Background(), db, project.ID)
 		require.NoError(t, getErr)
 		assert.Equal(t, project.ID, got.ID)
 		assert.Equal(t, "Retry Test", got.Name)
 	}
 
 	// Not-found should still work correctly through retry
-	_, err = GetProject(db, "proj_nonexistent")
+	_, err = GetProject(context.Background(), db, "proj_nonexistent")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "project not found")
 }
@@ -117,7 +118,7 @@ func TestEnsureProjectByID_CreatesNew(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := EnsureProjectByID(db, "/home/user/myproject", "myproject")
+	project, err := EnsureProjectByID(context.Background(), db, "/home/user/myproject", "myproject")
 	require.NoError(t, err)
 	require.NotNil(t, project)
 	assert.Equal(t, "/home/user/myproject", project.ID)
@@ -129,10 +130,10 @@ func TestEnsureProjectByID_Idempotent(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	p1, err := EnsureProjectByID(db, "/home/user/myproject", "myproject")
+	p1, err := EnsureProjectByID(context.Background(), db, "/home/user/myproject", "myproject")
 	require.NoError(t, err)
 
-	p2, err := EnsureProjectByID(db, "/home/user/myproject", "different-name")
+	p2, err := EnsureProjectByID(context.Background(), db, "/home/user/myproject", "different-name")
 	require.NoError(t, err)
 
 	assert.Equal(t, p1.ID, p2.ID)
@@ -144,7 +145,7 @@ func TestEnsureProjectByID_EmptyID(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := EnsureProjectByID(db, "", "name")
+	project, err := EnsureProjectByID(context.Background(), db, "", "name")
 	assert.Error(t, err)
 	assert.Nil(t, project)
 	assert.Contains(t, err.Error(), "project id is required")
@@ -154,7 +155,7 @@ func TestEnsureProjectByID_EmptyName(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := EnsureProjectByID(db, "/home/user/proj", "")
+	project, err := EnsureProjectByID(context.Background(), db, "/home/user/proj", "")
 	assert.Error(t, err)
 	assert.Nil(t, project)
 	assert.Contains(t, err.Error(), "project name is required")
@@ -164,16 +165,16 @@ func TestSetAgentFocusProject(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := LoadOrCreateAgentState(db, "test-agent")
+	_, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	require.NoError(t, err)
 
-	project, err := CreateProject(db, "Test Project", "")
+	project, err := CreateProject(context.Background(), db, "Test Project", "")
 	require.NoError(t, err)
 
-	_, err = SetAgentFocusProjectWithEventIdempotent(db, "test-agent", "req-set-proj", project.ID)
+	_, err = SetAgentFocusProjectWithEventIdempotent(context.Background(), db, "test-agent", "req-set-proj", project.ID)
 	require.NoError(t, err)
 
-	state, err := LoadOrCreateAgentState(db, "test-agent")
+	state, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	require.NoError(t, err)
 	assert.Equal(t, project.ID, state.FocusProjectID)
 }
@@ -182,17 +183,17 @@ func TestSetAgentFocusProjectWithEvent(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := LoadOrCreateAgentState(db, "test-agent")
+	_, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	require.NoError(t, err)
 
-	project, err := CreateProject(db, "Test Project", "")
+	project, err := CreateProject(context.Background(), db, "Test Project", "")
 	require.NoError(t, err)
 
-	eventID, err := SetAgentFocusProjectWithEventIdempotent(db, "test-agent", "req-proj-evt", project.ID)
+	eventID, err := SetAgentFocusProjectWithEventIdempotent(context.Background(), db, "test-agent", "req-proj-evt", project.ID)
 	require.NoError(t, err)
 	assert.Greater(t, eventID, int64(0))
 
-	state, err := LoadOrCreateAgentState(db, "test-agent")
+	state, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	require.NoError(t, err)
 	assert.Equal(t, project.ID, state.FocusProjectID)
 }
@@ -201,20 +202,20 @@ func TestSetAgentFocusProjectClear(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := LoadOrCreateAgentState(db, "test-agent")
+	_, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	require.NoError(t, err)
 
-	project, err := CreateProject(db, "Test Project", "")
+	project, err := CreateProject(context.Background(), db, "Test Project", "")
 	require.NoError(t, err)
 
-	_, err = SetAgentFocusProjectWithEventIdempotent(db, "test-agent", "req-set-proj-clear", project.ID)
+	_, err = SetAgentFocusProjectWithEventIdempotent(context.Background(), db, "test-agent", "req-set-proj-clear", project.ID)
 	require.NoError(t, err)
 
 	// Clear
-	_, err = SetAgentFocusProjectWithEventIdempotent(db, "test-agent", "req-clear-proj", "")
+	_, err = SetAgentFocusProjectWithEventIdempotent(context.Background(), db, "test-agent", "req-clear-proj", "")
 	require.NoError(t, err)
 
-	state, err := LoadOrCreateAgentState(db, "test-agent")
+	state, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	require.NoError(t, err)
 	assert.Equal(t, "", state.FocusProjectID)
 }
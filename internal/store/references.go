@@ -0,0 +1,218 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Reference kinds checked in strict mode.
+const (
+	RefTask    = "task"
+	RefProject = "project"
+	RefAgent   = "agent"
+)
+
+// DanglingReferenceError reports a write that names a task, project, or agent
+// that does not exist while strict reference validation is enabled.
+type DanglingReferenceError struct {
+	Field string // CLI/JSON field that carried the reference, e.g. "task_id"
+	Kind  string // RefTask, RefProject, or RefAgent
+	ID    string
+}
+
+func (e *DanglingReferenceError) Error() string {
+	return fmt.Sprintf("%s references unknown %s %q", e.Field, e.Kind, e.ID)
+}
+func (e *DanglingReferenceError) ErrorCode() string { return "DANGLING_REFERENCE" }
+func (e *DanglingReferenceError) Context() map[string]string {
+	return map[string]string{"field": e.Field, "kind": e.Kind, "id": e.ID}
+}
+func (e *DanglingReferenceError) SuggestedAction() string {
+	return fmt.Sprintf("create the %s first or fix the ID; strict_references is enabled", e.Kind)
+}
+
+// Reference names one ID that must exist for a write to be accepted.
+type Reference struct {
+	Field string
+	Kind  string
+	ID    string
+}
+
+// MemoryScopeReference returns the reference implied by a memory scope, or
+// ok=false for scopes that do not point at a row (global).
+func MemoryScopeReference(scope, scopeID string) (Reference, bool) {
+	switch scope {
+	case string(models.MemoryScopeTask):
+		return Reference{Field: "scope_id", Kind: RefTask, ID: scopeID}, true
+	case string(models.MemoryScopeProject):
+		return Reference{Field: "scope_id", Kind: RefProject, ID: scopeID}, true
+	case string(models.MemoryScopeAgent):
+		return Reference{Field: "scope_id", Kind: RefAgent, ID: scopeID}, true
+	}
+	return Reference{}, false
+}
+
+// CheckReferencesTx returns a *DanglingReferenceError for the first reference
+// that does not resolve. It is a no-op unless strict references are enabled;
+// empty IDs are skipped so optional fields stay optional.
+func CheckReferencesTx(tx *sql.Tx, refs ...Reference) error {
+	if !app.StrictReferencesEnabled() {
+		return nil
+	}
+	for _, ref := range refs {
+		if ref.ID == "" {
+			continue
+		}
+		ok, err := referenceExists(tx, ref.Kind, ref.ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &DanglingReferenceError{Field: ref.Field, Kind: ref.Kind, ID: ref.ID}
+		}
+	}
+	return nil
+}
+
+// referenceExists resolves one reference. Projects are often identified by a
+// workspace path that was never registered in the projects table, so a project
+// also counts as known when any task or agent focus already uses its ID.
+func referenceExists(tx *sql.Tx, kind, id string) (bool, error) {
+	var query string
+	args := []any{id}
+	switch kind {
+	case RefTask:
+		query = `SELECT EXISTS(SELECT 1 FROM tasks WHERE id = ?)`
+	case RefAgent:
+		query = `SELECT EXISTS(SELECT 1 FROM agent_state WHERE agent_name = ?)`
+	case RefProject:
+		query = `SELECT EXISTS(SELECT 1 FROM projects WHERE id = ?)
+			OR EXISTS(SELECT 1 FROM tasks WHERE project_id = ?)
+			OR EXISTS(SELECT 1 FROM agent_state WHERE focus_project_id = ?)`
+		args = []any{id, id, id}
+	default:
+		return false, fmt.Errorf("unknown reference kind %q", kind)
+	}
+	var exists bool
+	if err := tx.QueryRowContext(context.Background(), query, args...).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check %s reference: %w", kind, err)
+	}
+	return exists, nil
+}
+
+// OrphanCategory counts dangling references of one shape, with a sample of the
+// referencing row IDs.
+type OrphanCategory struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Count       int      `json:"count"`
+	Samples     []string `json:"samples,omitempty"`
+}
+
+// OrphanReport lists every category checked, including empty ones, so callers
+// can tell "checked and clean" from "not checked".
+type OrphanReport struct {
+	Total      int              `json:"total"`
+	Categories []OrphanCategory `json:"categories"`
+}
+
+// knownProjectsSQL matches the project resolution used by referenceExists.
+const knownProjectsSQL = `SELECT id FROM projects
+	UNION SELECT project_id FROM tasks WHERE project_id IS NOT NULL
+	UNION SELECT focus_project_id FROM agent_state WHERE focus_project_id IS NOT NULL`
+
+// orphanChecks each select one identifying column per dangling row.
+// Events for tasks that were deleted through vybe are history, not orphans:
+// the task_deleted event records the deletion.
+var orphanChecks = []struct {
+	name, description, query string
+}{
+	{
+		"events.task_id", "events naming a task that never existed",
+		`SELECT CAST(e.id AS TEXT) FROM events e
+		 WHERE e.task_id IS NOT NULL AND e.task_id != ''
+		   AND NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = e.task_id)
+		   AND NOT EXISTS (SELECT 1 FROM events d WHERE d.kind = 'task_deleted' AND d.task_id = e.task_id)
+		 ORDER BY e.id`,
+	},
+	{
+		"memory.task_scope", "task-scoped memory whose task does not exist",
+		`SELECT scope_id || ':' || key FROM memory
+		 WHERE scope = 'task' AND NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = memory.scope_id)
+		 ORDER BY id`,
+	},
+	{
+		"memory.project_scope", "project-scoped memory whose project is unknown",
+		`SELECT scope_id || ':' || key FROM memory
+		 WHERE scope = 'project' AND scope_id NOT IN (` + knownProjectsSQL + `)
+		 ORDER BY id`,
+	},
+	{
+		"memory.agent_scope", "agent-scoped memory whose agent has no state",
+		`SELECT scope_id || ':' || key FROM memory
+		 WHERE scope = 'agent' AND NOT EXISTS (SELECT 1 FROM agent_state a WHERE a.agent_name = memory.scope_id)
+		 ORDER BY id`,
+	},
+	{
+		"memory.source_task_id", "memory provenance pointing at a missing task",
+		`SELECT scope || ':' || COALESCE(scope_id, '') || ':' || key FROM memory
+		 WHERE source_task_id IS NOT NULL AND source_task_id != ''
+		   AND NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = memory.source_task_id)
+		 ORDER BY id`,
+	},
+	{
+		"agent_state.focus_task_id", "agents focused on a missing task",
+		`SELECT agent_name FROM agent_state
+		 WHERE focus_task_id IS NOT NULL AND focus_task_id != ''
+		   AND NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = agent_state.focus_task_id)
+		 ORDER BY agent_name`,
+	},
+	{
+		"artifacts.task_id", "artifacts attached to a missing task",
+		`SELECT id FROM artifacts
+		 WHERE NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = artifacts.task_id)
+		 ORDER BY id`,
+	},
+}
+
+// FindOrphans reports stored rows whose task, project, or agent reference does
+// not resolve. At most sampleLimit IDs are returned per category.
+func FindOrphans(ctx context.Context, db *sql.DB, sampleLimit int) (*OrphanReport, error) {
+	if sampleLimit <= 0 {
+		sampleLimit = 10
+	}
+
+	report := &OrphanReport{Categories: make([]OrphanCategory, 0, len(orphanChecks))}
+	for _, check := range orphanChecks {
+		cat := OrphanCategory{Name: check.name, Description: check.description}
+		err := RetryWithBackoff(ctx, func() error {
+			cat.Count, cat.Samples = 0, nil
+			rows, err := db.QueryContext(ctx, check.query)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = rows.Close() }()
+			for rows.Next() {
+				var id string
+				if err := rows.Scan(&id); err != nil {
+					return err
+				}
+				cat.Count++
+				if len(cat.Samples) < sampleLimit {
+					cat.Samples = append(cat.Samples, id)
+				}
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", check.name, err)
+		}
+		report.Total += cat.Count
+		report.Categories = append(report.Categories, cat)
+	}
+	return report, nil
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestCheckReferencesTx_StrictModeOnly(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Real", "", "proj_a", 0)
+	require.NoError(t, err)
+
+	check := func(refs ...Reference) error {
+		return Transact(context.Background(), db, func(tx *sql.Tx) error { return CheckReferencesTx(tx, refs...) })
+	}
+	missing := Reference{Field: "task_id", Kind: RefTask, ID: "task_missing"}
+
+	t.Setenv(app.StrictReferencesEnv, "0")
+	require.NoError(t, check(missing))
+
+	t.Setenv(app.StrictReferencesEnv, "1")
+	err = check(missing)
+	var dangling *DanglingReferenceError
+	require.True(t, errors.As(err, &dangling))
+	assert.Equal(t, "task_missing", dangling.ID)
+
+	require.NoError(t, check(
+		Reference{Field: "task_id", Kind: RefTask, ID: task.ID},
+		Reference{Field: "scope_id", Kind: RefProject, ID: "proj_a"}, // known through the task
+		Reference{Field: "task_id", Kind: RefTask, ID: ""},
+	))
+	require.Error(t, check(Reference{Field: "scope_id", Kind: RefAgent, ID: "nobody"}))
+
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_mem_1", "k", "v", "", "task", "task_missing", nil, false, "", nil, "")
+	require.True(t, errors.As(err, &dangling))
+	_, err = UpsertMemoryWithEventIdempotent(context.Background(), db, "agent1", "req_mem_2", "k", "v", "", "agent", "agent1", nil, false, "", nil, "")
+	require.NoError(t, err, "an agent may write its own agent-scoped memory")
+}
+
+func TestFindOrphans(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Kept", "", "", 0)
+	require.NoError(t, err)
+	gone, err := CreateTask(context.Background(), db, "Deleted", "", "", 0)
+	require.NoError(t, err)
+
+	appendEvent(t, db, "progress", "agent1", task.ID, "fine")
+	appendEvent(t, db, "progress", "agent1", "task_never", "dangling")
+	appendEvent(t, db, "progress", "agent1", gone.ID, "history of a deleted task")
+	require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
+		if err := DeleteTaskTx(tx, "agent1", gone.ID); err != nil {
+			return err
+		}
+		_, err := InsertEventTx(tx, models.EventKindTaskDeleted, "agent1", gone.ID, "Task deleted", "")
+		return err
+	}))
+	require.NoError(t, SetMemory(context.Background(), db, "k", "v", "", "task", "task_never", nil, false, "", nil))
+
+	report, err := FindOrphans(context.Background(), db, 5)
+	require.NoError(t, err)
+	counts := map[string]int{}
+	for _, c := range report.Categories {
+		counts[c.Name] = c.Count
+	}
+	assert.Equal(t, 1, counts["events.task_id"])
+	assert.Equal(t, 1, counts["memory.task_scope"])
+	assert.Equal(t, 2, report.Total)
+}
@@ -36,13 +36,13 @@ func scanEventRows(rows *sql.Rows) ([]*models.Event, error) {
 }
 
 // FetchEventsSince retrieves events after a cursor position.
-func FetchEventsSince(db *sql.DB, cursorID int64, limit int, projectID string) ([]*models.Event, error) {
+func FetchEventsSince(ctx context.Context, db *sql.DB, cursorID int64, limit int, projectID string) ([]*models.Event, error) {
 	if limit <= 0 {
 		limit = 1000
 	}
 
 	var events []*models.Event
-	err := RetryWithBackoff(context.Background(), func() error {
+	err := RetryWithBackoff(ctx, func() error {
 		query := `
 			SELECT id, kind, agent_name, project_id, task_id, message, metadata, created_at
 			FROM events
@@ -56,7 +56,7 @@ func FetchEventsSince(db *sql.DB, cursorID int64, limit int, projectID string) (
 		query += " ORDER BY id ASC LIMIT ?"
 		args = append(args, limit)
 
-		rows, err := db.QueryContext(context.Background(), query, args...)
+		rows, err := db.QueryContext(ctx, query, args...)
 		if err != nil {
 			return fmt.Errorf("failed to fetch events: %w", err)
 		}
@@ -73,18 +73,18 @@ func FetchEventsSince(db *sql.DB, cursorID int64, limit int, projectID string) (
 }
 
 // FetchSessionEvents retrieves events useful for session retrospective extraction.
-func FetchSessionEvents(db *sql.DB, sinceID int64, projectID string, limit int) ([]*models.Event, error) {
+func FetchSessionEvents(ctx context.Context, db *sql.DB, sinceID int64, projectID string, limit int) ([]*models.Event, error) {
 	if limit <= 0 {
 		limit = 200
 	}
 
 	var events []*models.Event
-	err := RetryWithBackoff(context.Background(), func() error {
+	err := RetryWithBackoff(ctx, func() error {
 		query := `
 			SELECT id, kind, agent_name, project_id, task_id, message, metadata, created_at
 			FROM events
 			WHERE id > ? AND archived_at IS NULL
-			  AND kind IN ('user_prompt', 'reasoning', 'tool_failure', 'task_status', 'progress')
+			  AND kind IN ('user_prompt', 'reasoning', 'tool_failure', 'task_status', 'task_failed', 'progress')
 		`
 		args := []any{sinceID}
 		if projectID != "" {
@@ -94,7 +94,7 @@ func FetchSessionEvents(db *sql.DB, sinceID int64, projectID string, limit int)
 		query += " ORDER BY id ASC LIMIT ?"
 		args = append(args, limit)
 
-		rows, err := db.QueryContext(context.Background(), query, args...)
+		rows, err := db.QueryContext(ctx, query, args...)
 		if err != nil {
 			return fmt.Errorf("failed to fetch session events: %w", err)
 		}
@@ -138,6 +138,9 @@ func applyAgentStateAtomicTx(tx *sql.Tx, agentName string, newCursor int64, focu
 	projectMode := projectFocusPreserve
 	projectValue := ""
 	if focusProjectID != nil {
+		if err := checkConfinedFocusTx(tx, agentName, *focusProjectID); err != nil {
+			return err
+		}
 		if *focusProjectID == "" {
 			projectMode = projectFocusClear
 		} else {
@@ -186,15 +189,15 @@ func UpdateAgentStateAtomicWithProjectTx(tx *sql.Tx, agentName string, newCursor
 }
 
 // UpdateAgentStateAtomic atomically updates cursor and focus task.
-func UpdateAgentStateAtomic(db *sql.DB, agentName string, newCursor int64, focusTaskID string) error {
-	return Transact(context.Background(), db, func(tx *sql.Tx) error {
+func UpdateAgentStateAtomic(ctx context.Context, db *sql.DB, agentName string, newCursor int64, focusTaskID string) error {
+	return Transact(ctx, db, func(tx *sql.Tx) error {
 		return applyAgentStateAtomicTx(tx, agentName, newCursor, focusTaskID, nil)
 	})
 }
 
 // UpdateAgentStateAtomicWithProject atomically updates cursor, focus task, and project focus.
-func UpdateAgentStateAtomicWithProject(db *sql.DB, agentName string, newCursor int64, focusTaskID, projectID string) error {
-	return Transact(context.Background(), db, func(tx *sql.Tx) error {
+func UpdateAgentStateAtomicWithProject(ctx context.Context, db *sql.DB, agentName string, newCursor int64, focusTaskID, projectID string) error {
+	return Transact(ctx, db, func(tx *sql.Tx) error {
 		return applyAgentStateAtomicTx(tx, agentName, newCursor, focusTaskID, &projectID)
 	})
 }
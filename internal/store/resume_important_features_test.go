@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -10,11 +11,11 @@ func TestFetchRecentUserPrompts_ByProjectAndMetadata(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := CreateProject(db, "proj-a", "")
+	_, err := CreateProject(context.Background(), db, "proj-a", "")
 	require.NoError(t, err)
 
 	// Project via project_id column.
-	_, err = AppendEventWithProjectAndMetadataIdempotent(
+	_, err = AppendEventWithProjectAndMetadataIdempotent(context.Background(),
 		db,
 		"agent-a",
 		"req-prompt-1",
@@ -29,11 +30,11 @@ func TestFetchRecentUserPrompts_ByProjectAndMetadata(t *testing.T) {
 	// Project via metadata only.
 	appendEventWithMetadata(t, db, "user_prompt", "agent-a", "", "prompt 2", `{"project":"proj-a"}`)
 
-	filtered, err := FetchRecentUserPrompts(db, "proj-a", 10)
+	filtered, err := FetchRecentUserPrompts(context.Background(), db, "proj-a", 10)
 	require.NoError(t, err)
 	require.Len(t, filtered, 2)
 
-	all, err := FetchRecentUserPrompts(db, "", 1)
+	all, err := FetchRecentUserPrompts(context.Background(), db, "", 1)
 	require.NoError(t, err)
 	require.Len(t, all, 1)
 }
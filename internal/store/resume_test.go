@@ -1,10 +1,12 @@
 package store
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/dotcommander/vybe/internal/app"
 	"github.com/dotcommander/vybe/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,7 +22,7 @@ func TestFetchEventsSince(t *testing.T) {
 	eventID3 := appendEvent(t, db, "test.event", "agent3", "task3", "Event 3")
 
 	// Fetch events since cursor 0
-	events, err := FetchEventsSince(db, 0, 100, "")
+	events, err := FetchEventsSince(context.Background(), db, 0, 100, "")
 	if err != nil {
 		t.Fatalf("FetchEventsSince failed: %v", err)
 	}
@@ -30,7 +32,7 @@ func TestFetchEventsSince(t *testing.T) {
 	}
 
 	// Fetch events since cursor 1 (should get 2 events)
-	events, err = FetchEventsSince(db, 1, 100, "")
+	events, err = FetchEventsSince(context.Background(), db, 1, 100, "")
 	if err != nil {
 		t.Fatalf("FetchEventsSince failed: %v", err)
 	}
@@ -40,7 +42,7 @@ func TestFetchEventsSince(t *testing.T) {
 	}
 
 	// Fetch events since last event (should get 0 events)
-	events, err = FetchEventsSince(db, eventID3, 100, "")
+	events, err = FetchEventsSince(context.Background(), db, eventID3, 100, "")
 	if err != nil {
 		t.Fatalf("FetchEventsSince failed: %v", err)
 	}
@@ -60,7 +62,7 @@ func TestFetchEventsSinceRespectLimit(t *testing.T) {
 	}
 
 	// Fetch with limit 3
-	events, err := FetchEventsSince(db, 0, 3, "")
+	events, err := FetchEventsSince(context.Background(), db, 0, 3, "")
 	if err != nil {
 		t.Fatalf("FetchEventsSince failed: %v", err)
 	}
@@ -75,19 +77,19 @@ func TestDetermineFocusTask_KeepInProgress(t *testing.T) {
 	defer cleanup()
 
 	// Create a task with in_progress status
-	task, err := CreateTask(db, "Active Task", "Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "Active Task", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// Update status to in_progress
-	err = UpdateTaskStatus(db, task.ID, "in_progress", task.Version)
+	err = UpdateTaskStatus(context.Background(), db, task.ID, "in_progress", task.Version)
 	if err != nil {
 		t.Fatalf("Failed to update task status: %v", err)
 	}
 
 	// Determine focus (should keep in_progress task)
-	result, err := DetermineFocusTask(db, "agent1", task.ID, []*models.Event{}, "")
+	result, err := DetermineFocusTask(context.Background(), db, "agent1", task.ID, []*models.Event{}, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
@@ -105,20 +107,20 @@ func TestDetermineFocusTask_FailureBlockedSkips(t *testing.T) {
 	defer cleanup()
 
 	// Create two tasks: one failure-blocked, one pending
-	blockedTask, err := CreateTask(db, "Blocked Task", "Description", "", 0)
+	blockedTask, err := CreateTask(context.Background(), db, "Blocked Task", "Description", "", 0)
 	require.NoError(t, err)
 
-	pendingTask, err := CreateTask(db, "Pending Task", "Description", "", 0)
+	pendingTask, err := CreateTask(context.Background(), db, "Pending Task", "Description", "", 0)
 	require.NoError(t, err)
 
 	// Set to blocked with a failure reason — rule1.5 must skip failure-blocked tasks
-	err = UpdateTaskStatus(db, blockedTask.ID, "blocked", blockedTask.Version)
+	err = UpdateTaskStatus(context.Background(), db, blockedTask.ID, "blocked", blockedTask.Version)
 	require.NoError(t, err)
 	_, err = db.Exec(`UPDATE tasks SET blocked_reason = 'failure:build_error' WHERE id = ?`, blockedTask.ID)
 	require.NoError(t, err)
 
 	// Determine focus: should skip failure-blocked and pick pending task
-	result, err := DetermineFocusTask(db, "agent1", blockedTask.ID, []*models.Event{}, "")
+	result, err := DetermineFocusTask(context.Background(), db, "agent1", blockedTask.ID, []*models.Event{}, "")
 	require.NoError(t, err)
 	require.Equal(t, pendingTask.ID, result.TaskID, "Should skip failure-blocked focus and pick pending task")
 }
@@ -128,19 +130,19 @@ func TestDetermineFocusTask_TaskAssigned_SkipsInProgressByOther(t *testing.T) {
 	defer cleanup()
 
 	// Create a pending task and set it in_progress by another agent
-	task, err := CreateTask(db, "InProgress Task", "Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "InProgress Task", "Description", "", 0)
 	require.NoError(t, err)
-	require.NoError(t, UpdateTaskStatus(db, task.ID, "in_progress", 1))
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, task.ID, "in_progress", 1))
 
 	// Create a fallback pending task
-	fallback, err := CreateTask(db, "Fallback Task", "Description", "", 0)
+	fallback, err := CreateTask(context.Background(), db, "Fallback Task", "Description", "", 0)
 	require.NoError(t, err)
 
 	// task_assigned event points to the in_progress task
 	deltas := []*models.Event{{Kind: "task_assigned", TaskID: task.ID}}
 
 	// agent1 should skip the in_progress task (not pending) and fall through to Rule 4
-	result, err := DetermineFocusTask(db, "agent1", "", deltas, "")
+	result, err := DetermineFocusTask(context.Background(), db, "agent1", "", deltas, "")
 	require.NoError(t, err)
 	require.Equal(t, fallback.ID, result.TaskID, "Should skip non-pending task")
 }
@@ -150,12 +152,12 @@ func TestDetermineFocusTask_TaskAssignedEvent(t *testing.T) {
 	defer cleanup()
 
 	// Create two tasks
-	task1, err := CreateTask(db, "Task 1", "Description", "", 0)
+	task1, err := CreateTask(context.Background(), db, "Task 1", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task 1: %v", err)
 	}
 
-	task2, err := CreateTask(db, "Task 2", "Description", "", 0)
+	task2, err := CreateTask(context.Background(), db, "Task 2", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task 2: %v", err)
 	}
@@ -169,7 +171,7 @@ func TestDetermineFocusTask_TaskAssignedEvent(t *testing.T) {
 	}
 
 	// Determine focus (should select task2 from assignment event)
-	result, err := DetermineFocusTask(db, "agent1", task1.ID, deltas, "")
+	result, err := DetermineFocusTask(context.Background(), db, "agent1", task1.ID, deltas, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
@@ -184,18 +186,18 @@ func TestDetermineFocusTask_OldestPending(t *testing.T) {
 	defer cleanup()
 
 	// Create tasks (they'll be ordered by created_at)
-	task1, err := CreateTask(db, "Task 1", "Oldest", "", 0)
+	task1, err := CreateTask(context.Background(), db, "Task 1", "Oldest", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task 1: %v", err)
 	}
 
-	_, err = CreateTask(db, "Task 2", "Newer", "", 0)
+	_, err = CreateTask(context.Background(), db, "Task 2", "Newer", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task 2: %v", err)
 	}
 
 	// Determine focus with no current focus (should select oldest pending)
-	result, err := DetermineFocusTask(db, "agent1", "", []*models.Event{}, "")
+	result, err := DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
@@ -213,7 +215,7 @@ func TestDetermineFocusTask_NoWorkAvailable(t *testing.T) {
 	defer cleanup()
 
 	// Determine focus with no tasks
-	result, err := DetermineFocusTask(db, "agent1", "", []*models.Event{}, "")
+	result, err := DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
@@ -231,7 +233,7 @@ func TestBuildBrief_EmptyTask(t *testing.T) {
 	defer cleanup()
 
 	// Build brief with no focus task
-	brief, err := BuildBrief(db, "", "", "")
+	brief, err := BuildBrief(context.Background(), db, "", "", "")
 	if err != nil {
 		t.Fatalf("BuildBrief failed: %v", err)
 	}
@@ -255,18 +257,18 @@ func TestBuildBrief_WithTask(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	task, err := CreateTask(db, "Test Task", "Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "Test Task", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// Add some memory
-	err = SetMemory(db, "key1", "value1", "string", "global", "", nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "key1", "value1", "string", "global", "", nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set global memory: %v", err)
 	}
 
-	err = SetMemory(db, "key2", "value2", "string", "task", task.ID, nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "key2", "value2", "string", "task", task.ID, nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set task memory: %v", err)
 	}
@@ -275,7 +277,7 @@ func TestBuildBrief_WithTask(t *testing.T) {
 	appendEvent(t, db, "task.started", "agent1", task.ID, "Started work")
 
 	// Build brief
-	brief, err := BuildBrief(db, task.ID, "", "")
+	brief, err := BuildBrief(context.Background(), db, task.ID, "", "")
 	if err != nil {
 		t.Fatalf("BuildBrief failed: %v", err)
 	}
@@ -305,7 +307,7 @@ func TestBuildBrief_ApproxTokensFromEventMessages(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "Token Task", "Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "Token Task", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
@@ -313,7 +315,7 @@ func TestBuildBrief_ApproxTokensFromEventMessages(t *testing.T) {
 	appendEvent(t, db, "task.note", "agent1", task.ID, "abcd")
 	appendEvent(t, db, "task.note", "agent1", task.ID, "12345")
 
-	brief, err := BuildBrief(db, task.ID, "", "")
+	brief, err := BuildBrief(context.Background(), db, task.ID, "", "")
 	if err != nil {
 		t.Fatalf("BuildBrief failed: %v", err)
 	}
@@ -329,25 +331,25 @@ func TestUpdateAgentStateAtomic(t *testing.T) {
 	defer cleanup()
 
 	// Create agent state
-	_, err := LoadOrCreateAgentState(db, "test-agent")
+	_, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	if err != nil {
 		t.Fatalf("Failed to create agent state: %v", err)
 	}
 
 	// Create a task
-	task, err := CreateTask(db, "Test Task", "Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "Test Task", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// Update cursor and focus atomically
-	err = UpdateAgentStateAtomic(db, "test-agent", 42, task.ID)
+	err = UpdateAgentStateAtomic(context.Background(), db, "test-agent", 42, task.ID)
 	if err != nil {
 		t.Fatalf("UpdateAgentStateAtomic failed: %v", err)
 	}
 
 	// Verify update
-	state, err := LoadOrCreateAgentState(db, "test-agent")
+	state, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	if err != nil {
 		t.Fatalf("Failed to load agent state: %v", err)
 	}
@@ -366,25 +368,25 @@ func TestUpdateAgentStateAtomic_MonotonicCursor(t *testing.T) {
 	defer cleanup()
 
 	// Create agent state
-	_, err := LoadOrCreateAgentState(db, "test-agent")
+	_, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	if err != nil {
 		t.Fatalf("Failed to create agent state: %v", err)
 	}
 
 	// Advance cursor to 100
-	err = UpdateAgentStateAtomic(db, "test-agent", 100, "")
+	err = UpdateAgentStateAtomic(context.Background(), db, "test-agent", 100, "")
 	if err != nil {
 		t.Fatalf("UpdateAgentStateAtomic failed: %v", err)
 	}
 
 	// Try to move cursor backward to 50 (should stay at 100 due to MAX)
-	err = UpdateAgentStateAtomic(db, "test-agent", 50, "")
+	err = UpdateAgentStateAtomic(context.Background(), db, "test-agent", 50, "")
 	if err != nil {
 		t.Fatalf("UpdateAgentStateAtomic failed: %v", err)
 	}
 
 	// Verify cursor didn't go backward
-	state, err := LoadOrCreateAgentState(db, "test-agent")
+	state, err := LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	if err != nil {
 		t.Fatalf("Failed to load agent state: %v", err)
 	}
@@ -399,31 +401,31 @@ func TestFetchRelevantMemory(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	task, err := CreateTask(db, "Test Task", "Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "Test Task", "Description", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// Add global memory
-	err = SetMemory(db, "global_key", "global_value", "string", "global", "", nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "global_key", "global_value", "string", "global", "", nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set global memory: %v", err)
 	}
 
 	// Add task-specific memory
-	err = SetMemory(db, "task_key", "task_value", "string", "task", task.ID, nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "task_key", "task_value", "string", "task", task.ID, nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set task memory: %v", err)
 	}
 
 	// Add agent memory (should NOT be included)
-	err = SetMemory(db, "agent_key", "agent_value", "string", "agent", "agent1", nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "agent_key", "agent_value", "string", "agent", "agent1", nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set agent memory: %v", err)
 	}
 
 	// Fetch relevant memory
-	memories, err := fetchRelevantMemory(db, task.ID, "")
+	memories, err := fetchRelevantMemory(context.Background(), db, task.ID, "")
 	if err != nil {
 		t.Fatalf("fetchRelevantMemory failed: %v", err)
 	}
@@ -446,17 +448,17 @@ func TestDetermineFocusTask_Determinism(t *testing.T) {
 	defer cleanup()
 
 	// Create multiple pending tasks
-	task1, _ := CreateTask(db, "Task 1", "First", "", 0)
-	_, _ = CreateTask(db, "Task 2", "Second", "", 0)
-	_, _ = CreateTask(db, "Task 3", "Third", "", 0)
+	task1, _ := CreateTask(context.Background(), db, "Task 1", "First", "", 0)
+	_, _ = CreateTask(context.Background(), db, "Task 2", "Second", "", 0)
+	_, _ = CreateTask(context.Background(), db, "Task 3", "Third", "", 0)
 
 	// Run determination multiple times with same state
-	result1, err := DetermineFocusTask(db, "agent1", "", []*models.Event{}, "")
+	result1, err := DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
 
-	result2, err := DetermineFocusTask(db, "agent1", "", []*models.Event{}, "")
+	result2, err := DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
@@ -476,23 +478,23 @@ func TestBuildBrief_WithProject(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, err := CreateProject(db, "My Project", `{"key":"val"}`)
+	project, err := CreateProject(context.Background(), db, "My Project", `{"key":"val"}`)
 	if err != nil {
 		t.Fatalf("Failed to create project: %v", err)
 	}
 
-	task, err := CreateTask(db, "Task", "Desc", project.ID, 0)
+	task, err := CreateTask(context.Background(), db, "Task", "Desc", project.ID, 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// Add project-scoped memory
-	err = SetMemory(db, "pkey", "pval", "string", "project", project.ID, nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "pkey", "pval", "string", "project", project.ID, nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set project memory: %v", err)
 	}
 
-	brief, err := BuildBrief(db, task.ID, project.ID, "")
+	brief, err := BuildBrief(context.Background(), db, task.ID, project.ID, "")
 	if err != nil {
 		t.Fatalf("BuildBrief failed: %v", err)
 	}
@@ -522,23 +524,23 @@ func TestFetchRelevantMemory_ProjectFiltered(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "Task", "Desc", "", 0)
+	task, err := CreateTask(context.Background(), db, "Task", "Desc", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// Two projects
-	err = SetMemory(db, "p1key", "p1val", "string", "project", "proj_1", nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "p1key", "p1val", "string", "project", "proj_1", nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set project 1 memory: %v", err)
 	}
-	err = SetMemory(db, "p2key", "p2val", "string", "project", "proj_2", nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "p2key", "p2val", "string", "project", "proj_2", nil, false, "", nil)
 	if err != nil {
 		t.Fatalf("Failed to set project 2 memory: %v", err)
 	}
 
 	// Filtered: should only get proj_1
-	memories, err := fetchRelevantMemory(db, task.ID, "proj_1")
+	memories, err := fetchRelevantMemory(context.Background(), db, task.ID, "proj_1")
 	if err != nil {
 		t.Fatalf("fetchRelevantMemory failed: %v", err)
 	}
@@ -551,7 +553,7 @@ func TestFetchRelevantMemory_ProjectFiltered(t *testing.T) {
 	}
 
 	// Unfiltered: should get both
-	memories, err = fetchRelevantMemory(db, task.ID, "")
+	memories, err = fetchRelevantMemory(context.Background(), db, task.ID, "")
 	if err != nil {
 		t.Fatalf("fetchRelevantMemory failed: %v", err)
 	}
@@ -570,19 +572,19 @@ func TestFetchRelevantMemory_FiltersExpired(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "Task", "Desc", "", 0)
+	task, err := CreateTask(context.Background(), db, "Task", "Desc", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create task: %v", err)
 	}
 
 	// Active memory, should remain.
-	require.NoError(t, SetMemory(db, "active", "value", "string", "task", task.ID, nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "active", "value", "string", "task", task.ID, nil, false, "", nil))
 
 	// Expired memory, should be filtered out.
 	expired := time.Now().UTC().Add(-1 * time.Hour)
-	require.NoError(t, SetMemory(db, "expired", "value", "string", "task", task.ID, &expired, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "expired", "value", "string", "task", task.ID, &expired, false, "", nil))
 
-	memories, err := fetchRelevantMemory(db, task.ID, "")
+	memories, err := fetchRelevantMemory(context.Background(), db, task.ID, "")
 	if err != nil {
 		t.Fatalf("fetchRelevantMemory failed: %v", err)
 	}
@@ -605,19 +607,19 @@ func TestDetermineFocusTask_ProjectScoped(t *testing.T) {
 	defer cleanup()
 
 	// Task without project
-	_, err := CreateTask(db, "Global Task", "Desc", "", 0)
+	_, err := CreateTask(context.Background(), db, "Global Task", "Desc", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create global task: %v", err)
 	}
 
 	// Task with project
-	projTask, err := CreateTask(db, "Project Task", "Desc", "proj_x", 0)
+	projTask, err := CreateTask(context.Background(), db, "Project Task", "Desc", "proj_x", 0)
 	if err != nil {
 		t.Fatalf("Failed to create project task: %v", err)
 	}
 
 	// With project filter, should prefer project task
-	result, err := DetermineFocusTask(db, "agent1", "", []*models.Event{}, "proj_x")
+	result, err := DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "proj_x")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
@@ -626,12 +628,12 @@ func TestDetermineFocusTask_ProjectScoped(t *testing.T) {
 	}
 
 	// If no pending task exists in the active project, do not fall back to global.
-	err = UpdateTaskStatus(db, projTask.ID, "completed", projTask.Version)
+	err = UpdateTaskStatus(context.Background(), db, projTask.ID, "completed", projTask.Version)
 	if err != nil {
 		t.Fatalf("Failed to complete project task: %v", err)
 	}
 
-	result, err = DetermineFocusTask(db, "agent1", "", []*models.Event{}, "proj_x")
+	result, err = DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "proj_x")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed on strict project scope: %v", err)
 	}
@@ -644,15 +646,15 @@ func TestFetchEventsSince_ProjectIsolation(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	projectTask, err := CreateTask(db, "Project Task", "Desc", "proj_a", 0)
+	projectTask, err := CreateTask(context.Background(), db, "Project Task", "Desc", "proj_a", 0)
 	if err != nil {
 		t.Fatalf("Failed to create project task: %v", err)
 	}
-	globalTask, err := CreateTask(db, "Global Task", "Desc", "", 0)
+	globalTask, err := CreateTask(context.Background(), db, "Global Task", "Desc", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create global task: %v", err)
 	}
-	otherTask, err := CreateTask(db, "Other Task", "Desc", "proj_b", 0)
+	otherTask, err := CreateTask(context.Background(), db, "Other Task", "Desc", "proj_b", 0)
 	if err != nil {
 		t.Fatalf("Failed to create other project task: %v", err)
 	}
@@ -661,7 +663,7 @@ func TestFetchEventsSince_ProjectIsolation(t *testing.T) {
 	appendEvent(t, db, "task.note", "agent1", globalTask.ID, "global event")
 	appendEvent(t, db, "task.note", "agent1", otherTask.ID, "project B event")
 
-	events, err := FetchEventsSince(db, 0, 20, "proj_a")
+	events, err := FetchEventsSince(context.Background(), db, 0, 20, "proj_a")
 	if err != nil {
 		t.Fatalf("FetchEventsSince failed: %v", err)
 	}
@@ -682,23 +684,23 @@ func TestDetermineFocusTask_PriorityOrdering(t *testing.T) {
 	defer cleanup()
 
 	// Create tasks with different priorities
-	lowTask, err := CreateTask(db, "Low Priority", "Desc", "", 0)
+	lowTask, err := CreateTask(context.Background(), db, "Low Priority", "Desc", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create low priority task: %v", err)
 	}
 
-	_, err = CreateTask(db, "Normal Priority", "Desc", "", 5)
+	_, err = CreateTask(context.Background(), db, "Normal Priority", "Desc", "", 5)
 	if err != nil {
 		t.Fatalf("Failed to create normal priority task: %v", err)
 	}
 
-	highTask, err := CreateTask(db, "High Priority", "Desc", "", 10)
+	highTask, err := CreateTask(context.Background(), db, "High Priority", "Desc", "", 10)
 	if err != nil {
 		t.Fatalf("Failed to create high priority task: %v", err)
 	}
 
 	// Rule 4: Should select highest priority task
-	result, err := DetermineFocusTask(db, "agent1", "", []*models.Event{}, "")
+	result, err := DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
@@ -708,35 +710,35 @@ func TestDetermineFocusTask_PriorityOrdering(t *testing.T) {
 	}
 
 	// Mark high priority as completed
-	err = UpdateTaskStatus(db, highTask.ID, "completed", highTask.Version)
+	err = UpdateTaskStatus(context.Background(), db, highTask.ID, "completed", highTask.Version)
 	if err != nil {
 		t.Fatalf("Failed to complete high priority task: %v", err)
 	}
 
 	// Should now select normal priority (5)
-	result, err = DetermineFocusTask(db, "agent1", "", []*models.Event{}, "")
+	result, err = DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
 
 	// Create another task with same priority as lowTask (both priority 0)
-	lowTask2, err := CreateTask(db, "Another Low Priority", "Desc", "", 0)
+	lowTask2, err := CreateTask(context.Background(), db, "Another Low Priority", "Desc", "", 0)
 	if err != nil {
 		t.Fatalf("Failed to create another low priority task: %v", err)
 	}
 
 	// Mark normal priority as completed
-	normalTask, err := GetTask(db, result.TaskID)
+	normalTask, err := GetTask(context.Background(), db, result.TaskID)
 	if err != nil {
 		t.Fatalf("Failed to get normal task: %v", err)
 	}
-	err = UpdateTaskStatus(db, normalTask.ID, "completed", normalTask.Version)
+	err = UpdateTaskStatus(context.Background(), db, normalTask.ID, "completed", normalTask.Version)
 	if err != nil {
 		t.Fatalf("Failed to complete normal priority task: %v", err)
 	}
 
 	// Now with two tasks at priority 0, should select oldest (lowTask created first)
-	result, err = DetermineFocusTask(db, "agent1", "", []*models.Event{}, "")
+	result, err = DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
@@ -746,13 +748,13 @@ func TestDetermineFocusTask_PriorityOrdering(t *testing.T) {
 	}
 
 	// Mark oldest low priority as completed
-	err = UpdateTaskStatus(db, lowTask.ID, "completed", lowTask.Version)
+	err = UpdateTaskStatus(context.Background(), db, lowTask.ID, "completed", lowTask.Version)
 	if err != nil {
 		t.Fatalf("Failed to complete oldest low priority task: %v", err)
 	}
 
 	// Should now select the newer low priority task
-	result, err = DetermineFocusTask(db, "agent1", "", []*models.Event{}, "")
+	result, err = DetermineFocusTask(context.Background(), db, "agent1", "", []*models.Event{}, "")
 	if err != nil {
 		t.Fatalf("DetermineFocusTask failed: %v", err)
 	}
@@ -766,7 +768,7 @@ func TestFetchPriorReasoning_Empty(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	events, err := FetchPriorReasoning(db, "", 10)
+	events, err := FetchPriorReasoning(context.Background(), db, "", 10)
 	require.NoError(t, err)
 	require.Empty(t, events)
 }
@@ -779,7 +781,7 @@ func TestFetchPriorReasoning_WithEvents(t *testing.T) {
 	appendEvent(t, db, "user_prompt", "agent1", "", "not reasoning")
 	appendEvent(t, db, "reasoning", "agent1", "", "intent: fix bug Y")
 
-	events, err := FetchPriorReasoning(db, "", 10)
+	events, err := FetchPriorReasoning(context.Background(), db, "", 10)
 	require.NoError(t, err)
 	require.Len(t, events, 2)
 	// Newest first
@@ -791,18 +793,18 @@ func TestFetchPriorReasoning_ProjectScoped(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := AppendEventWithProjectAndMetadataIdempotent(
+	_, err := AppendEventWithProjectAndMetadataIdempotent(context.Background(),
 		db, "agent1", "req1", "reasoning", "proj_a", "", "reasoning for A", "",
 	)
 	require.NoError(t, err)
-	_, err = AppendEventWithProjectAndMetadataIdempotent(
+	_, err = AppendEventWithProjectAndMetadataIdempotent(context.Background(),
 		db, "agent1", "req2", "reasoning", "proj_b", "", "reasoning for B", "",
 	)
 	require.NoError(t, err)
 	appendEvent(t, db, "reasoning", "agent1", "", "global reasoning")
 
 	// Scoped to proj_a: should get proj_a + global
-	events, err := FetchPriorReasoning(db, "proj_a", 10)
+	events, err := FetchPriorReasoning(context.Background(), db, "proj_a", 10)
 	require.NoError(t, err)
 	require.Len(t, events, 2)
 	for _, e := range events {
@@ -821,7 +823,7 @@ func TestFetchPriorReasoning_ExcludesArchived(t *testing.T) {
 	require.NoError(t, err)
 	appendEvent(t, db, "reasoning", "agent1", "", "active reasoning")
 
-	events, err := FetchPriorReasoning(db, "", 10)
+	events, err := FetchPriorReasoning(context.Background(), db, "", 10)
 	require.NoError(t, err)
 	require.Len(t, events, 1)
 	require.Equal(t, "active reasoning", events[0].Message)
@@ -831,7 +833,7 @@ func TestFetchSessionEvents_Empty(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	events, err := FetchSessionEvents(db, 0, "", 200)
+	events, err := FetchSessionEvents(context.Background(), db, 0, "", 200)
 	require.NoError(t, err)
 	require.Empty(t, events)
 }
@@ -848,7 +850,7 @@ func TestFetchSessionEvents_FiltersByKind(t *testing.T) {
 	// This kind should be excluded
 	appendEvent(t, db, "task.note", "agent1", "", "random note")
 
-	events, err := FetchSessionEvents(db, 0, "", 200)
+	events, err := FetchSessionEvents(context.Background(), db, 0, "", 200)
 	require.NoError(t, err)
 	require.Len(t, events, 5)
 	// Chronological order
@@ -860,17 +862,17 @@ func TestFetchSessionEvents_ProjectScoped(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := AppendEventWithProjectAndMetadataIdempotent(
+	_, err := AppendEventWithProjectAndMetadataIdempotent(context.Background(),
 		db, "agent1", "req1", "user_prompt", "proj_a", "", "prompt for A", "",
 	)
 	require.NoError(t, err)
-	_, err = AppendEventWithProjectAndMetadataIdempotent(
+	_, err = AppendEventWithProjectAndMetadataIdempotent(context.Background(),
 		db, "agent1", "req2", "user_prompt", "proj_b", "", "prompt for B", "",
 	)
 	require.NoError(t, err)
 	appendEvent(t, db, "progress", "agent1", "", "global progress")
 
-	events, err := FetchSessionEvents(db, 0, "proj_a", 200)
+	events, err := FetchSessionEvents(context.Background(), db, 0, "proj_a", 200)
 	require.NoError(t, err)
 	require.Len(t, events, 2) // proj_a + global
 	for _, e := range events {
@@ -886,23 +888,23 @@ func TestGetTaskStatusCounts_Global(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	t1, err := CreateTask(db, "Pending 1", "", "", 0)
+	t1, err := CreateTask(context.Background(), db, "Pending 1", "", "", 0)
 	require.NoError(t, err)
-	_, err = CreateTask(db, "Pending 2", "", "", 0)
+	_, err = CreateTask(context.Background(), db, "Pending 2", "", "", 0)
 	require.NoError(t, err)
 
-	t3, err := CreateTask(db, "In Progress", "", "", 0)
+	t3, err := CreateTask(context.Background(), db, "In Progress", "", "", 0)
 	require.NoError(t, err)
-	require.NoError(t, UpdateTaskStatus(db, t3.ID, "in_progress", t3.Version))
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, t3.ID, "in_progress", t3.Version))
 
-	t4, err := CreateTask(db, "Completed", "", "", 0)
+	t4, err := CreateTask(context.Background(), db, "Completed", "", "", 0)
 	require.NoError(t, err)
-	require.NoError(t, UpdateTaskStatus(db, t4.ID, "completed", t4.Version))
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, t4.ID, "completed", t4.Version))
 
 	// blocked task
-	require.NoError(t, UpdateTaskStatus(db, t1.ID, "blocked", t1.Version))
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, t1.ID, "blocked", t1.Version))
 
-	counts, err := GetTaskStatusCounts(db, "")
+	counts, err := GetTaskStatusCounts(context.Background(), db, "")
 	require.NoError(t, err)
 	require.Equal(t, 1, counts.Pending)    // Pending 2
 	require.Equal(t, 1, counts.InProgress) // In Progress
@@ -914,15 +916,15 @@ func TestGetTaskStatusCounts_ProjectScoped(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := CreateTask(db, "Global Pending", "", "", 0)
+	_, err := CreateTask(context.Background(), db, "Global Pending", "", "", 0)
 	require.NoError(t, err)
-	_, err = CreateTask(db, "Proj A Pending", "", "proj_a", 0)
+	_, err = CreateTask(context.Background(), db, "Proj A Pending", "", "proj_a", 0)
 	require.NoError(t, err)
-	tB, err := CreateTask(db, "Proj B Done", "", "proj_b", 0)
+	tB, err := CreateTask(context.Background(), db, "Proj B Done", "", "proj_b", 0)
 	require.NoError(t, err)
-	require.NoError(t, UpdateTaskStatus(db, tB.ID, "completed", tB.Version))
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, tB.ID, "completed", tB.Version))
 
-	counts, err := GetTaskStatusCounts(db, "proj_a")
+	counts, err := GetTaskStatusCounts(context.Background(), db, "proj_a")
 	require.NoError(t, err)
 	require.Equal(t, 1, counts.Pending)
 	require.Equal(t, 0, counts.InProgress)
@@ -934,14 +936,14 @@ func TestFetchPipelineTasks_Ordering(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	low, err := CreateTask(db, "Low Priority", "", "", 1)
+	low, err := CreateTask(context.Background(), db, "Low Priority", "", "", 1)
 	require.NoError(t, err)
-	high, err := CreateTask(db, "High Priority", "", "", 10)
+	high, err := CreateTask(context.Background(), db, "High Priority", "", "", 10)
 	require.NoError(t, err)
-	med, err := CreateTask(db, "Med Priority", "", "", 5)
+	med, err := CreateTask(context.Background(), db, "Med Priority", "", "", 5)
 	require.NoError(t, err)
 
-	tasks, err := FetchPipelineTasks(db, "", "agent1", "", 5)
+	tasks, err := FetchPipelineTasks(context.Background(), db, "", "agent1", "", 5)
 	require.NoError(t, err)
 	require.Len(t, tasks, 3)
 	require.Equal(t, high.ID, tasks[0].ID)
@@ -954,20 +956,20 @@ func TestFetchPipelineTasks_ExcludesInProgressAndBlocked(t *testing.T) {
 	defer cleanup()
 
 	// Available task
-	avail, err := CreateTask(db, "Available", "", "", 0)
+	avail, err := CreateTask(context.Background(), db, "Available", "", "", 0)
 	require.NoError(t, err)
 
 	// In_progress task — not in pipeline (not pending)
-	inprog, err := CreateTask(db, "InProgress", "", "", 0)
+	inprog, err := CreateTask(context.Background(), db, "InProgress", "", "", 0)
 	require.NoError(t, err)
-	require.NoError(t, UpdateTaskStatus(db, inprog.ID, "in_progress", 1))
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, inprog.ID, "in_progress", 1))
 
 	// Manually blocked task
-	blocked, err := CreateTask(db, "Blocked", "", "", 0)
+	blocked, err := CreateTask(context.Background(), db, "Blocked", "", "", 0)
 	require.NoError(t, err)
-	require.NoError(t, UpdateTaskStatus(db, blocked.ID, "blocked", blocked.Version))
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, blocked.ID, "blocked", blocked.Version))
 
-	tasks, err := FetchPipelineTasks(db, "", "agent1", "", 10)
+	tasks, err := FetchPipelineTasks(context.Background(), db, "", "agent1", "", 10)
 	require.NoError(t, err)
 
 	ids := make(map[string]bool)
@@ -983,14 +985,14 @@ func TestFetchRelevantMemory_ACTRScoring(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	require.NoError(t, SetMemory(db, "rarely_used", "val1", "string", "global", "", nil, false, "", nil))
-	require.NoError(t, SetMemory(db, "frequently_used", "val2", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "rarely_used", "val1", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "frequently_used", "val2", "string", "global", "", nil, false, "", nil))
 
 	// Simulate high access for frequently_used
 	_, err := db.Exec(`UPDATE memory SET access_count = 10, last_accessed_at = CURRENT_TIMESTAMP WHERE key = 'frequently_used'`)
 	require.NoError(t, err)
 
-	memories, err := fetchRelevantMemory(db, "", "")
+	memories, err := fetchRelevantMemory(context.Background(), db, "", "")
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, len(memories), 2)
 
@@ -1017,16 +1019,62 @@ func TestFetchRelevantMemory_AccessCountIncrement(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	require.NoError(t, SetMemory(db, "counter_test", "val", "string", "global", "", nil, false, "", nil))
+	require.NoError(t, SetMemory(context.Background(), db, "counter_test", "val", "string", "global", "", nil, false, "", nil))
 
 	// Fetch twice
-	_, err := fetchRelevantMemory(db, "", "")
+	_, err := fetchRelevantMemory(context.Background(), db, "", "")
 	require.NoError(t, err)
-	_, err = fetchRelevantMemory(db, "", "")
+	_, err = fetchRelevantMemory(context.Background(), db, "", "")
 	require.NoError(t, err)
 
-	mem, err := GetMemory(db, "counter_test", "global", "")
+	mem, err := GetMemory(context.Background(), db, "counter_test", "global", "")
 	require.NoError(t, err)
 	assert.Equal(t, 2, mem.AccessCount, "access_count should be 2 after two fetches")
 	assert.NotNil(t, mem.LastAccessedAt, "last_accessed_at should be set")
 }
+
+func TestDetermineFocusTaskWithPolicy(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// proj_a holds the globally highest-priority task.
+	aTop, err := CreateTask(context.Background(), db, "A top", "", "proj_a", 10)
+	require.NoError(t, err)
+	bTask, err := CreateTask(context.Background(), db, "B task", "", "proj_b", 1)
+	require.NoError(t, err)
+	cTask, err := CreateTask(context.Background(), db, "C task", "", "proj_c", 5)
+	require.NoError(t, err)
+
+	result, err := DetermineFocusTaskWithPolicy(context.Background(), db, "agent1", "", nil, "", app.FocusPriorityFirst)
+	require.NoError(t, err)
+	assert.Equal(t, aTop.ID, result.TaskID)
+
+	// Without deadlines, deadline-first ties everywhere and ranks like priority-first.
+	result, err = DetermineFocusTaskWithPolicy(context.Background(), db, "agent1", "", nil, "", app.FocusDeadlineFirst)
+	require.NoError(t, err)
+	assert.Equal(t, aTop.ID, result.TaskID)
+
+	// The agent last worked in proj_b: affinity keeps it there despite lower priority.
+	appendEventWithProject(t, db, "progress", "agent1", "proj_b", bTask.ID, "worked on b")
+	result, err = DetermineFocusTaskWithPolicy(context.Background(), db, "agent1", "", nil, "", app.FocusProjectAffinity)
+	require.NoError(t, err)
+	assert.Equal(t, bTask.ID, result.TaskID)
+	assert.Contains(t, result.Rule, "project-affinity")
+
+	// Round-robin moves on from proj_b to the next project with pending work.
+	result, err = DetermineFocusTaskWithPolicy(context.Background(), db, "agent1", "", nil, "", app.FocusRoundRobin)
+	require.NoError(t, err)
+	assert.Equal(t, cTask.ID, result.TaskID)
+	assert.Contains(t, result.Rule, "round-robin")
+
+	// ...and wraps around after the last project.
+	appendEventWithProject(t, db, "progress", "agent1", "proj_c", cTask.ID, "worked on c")
+	result, err = DetermineFocusTaskWithPolicy(context.Background(), db, "agent1", "", nil, "", app.FocusRoundRobin)
+	require.NoError(t, err)
+	assert.Equal(t, aTop.ID, result.TaskID)
+
+	// A project-scoped resume ignores affinity and rotation.
+	result, err = DetermineFocusTaskWithPolicy(context.Background(), db, "agent1", "", nil, "proj_b", app.FocusRoundRobin)
+	require.NoError(t, err)
+	assert.Equal(t, bTask.ID, result.TaskID)
+}
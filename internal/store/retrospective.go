@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// RetrospectiveSummaryHalfLifeDays is the decay half-life of session summary
+// memory, so old summaries fade from briefs while lessons carry what lasts.
+const RetrospectiveSummaryHalfLifeDays = 7.0
+
+// LessonDraft is a lesson extracted by a retrospective, before it is stored.
+type LessonDraft struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+}
+
+// RetrospectiveRecord is what a session retrospective persists.
+type RetrospectiveRecord struct {
+	SessionID string
+	ProjectID string // "" stores the summary globally and the lessons as global
+	Mode      string // "rules" or "llm"
+	Provider  string // LLM provider name when Mode is "llm"
+	Summary   string
+	Lessons   []LessonDraft
+}
+
+// RetrospectiveResult reports a stored retrospective. Lessons lists every
+// extracted lesson; ones the project already had are returned unchanged.
+type RetrospectiveResult struct {
+	SessionID  string    `json:"session_id"`
+	ProjectID  string    `json:"project_id,omitempty"`
+	Mode       string    `json:"mode"`
+	Provider   string    `json:"provider,omitempty"`
+	Summary    string    `json:"summary"`
+	SummaryKey string    `json:"summary_key"`
+	Lessons    []*Lesson `json:"lessons"`
+	EventID    int64     `json:"event_id"`
+}
+
+// RetrospectiveSummaryKey is the memory key a session's summary is stored under.
+func RetrospectiveSummaryKey(sessionID string) string {
+	return "session" + MemoryKeySeparator + sessionID + MemoryKeySeparator + "summary"
+}
+
+// RecordRetrospectiveTx stores a session retrospective: the summary as
+// project (or global) memory under RetrospectiveSummaryKey, each lesson in the
+// lessons table with source "retrospective:<session>", and a
+// session_retrospective event.
+func RecordRetrospectiveTx(tx *sql.Tx, agentName string, r RetrospectiveRecord) (*RetrospectiveResult, error) {
+	if r.SessionID == "" {
+		return nil, errors.New("session id is required")
+	}
+	if r.Summary == "" {
+		return nil, errors.New("retrospective summary is required")
+	}
+
+	scope, scopeID := string(models.MemoryScopeGlobal), ""
+	if r.ProjectID != "" {
+		scope, scopeID = string(models.MemoryScopeProject), r.ProjectID
+	}
+	key := RetrospectiveSummaryKey(r.SessionID)
+	halfLife := RetrospectiveSummaryHalfLifeDays
+	if _, err := UpsertMemoryTx(tx, agentName, key, r.Summary, "string", scope, scopeID,
+		nil, false, string(models.MemoryKindFact), &halfLife, nil, ""); err != nil {
+		return nil, fmt.Errorf("failed to store session summary: %w", err)
+	}
+
+	res := &RetrospectiveResult{
+		SessionID:  r.SessionID,
+		ProjectID:  r.ProjectID,
+		Mode:       r.Mode,
+		Provider:   r.Provider,
+		Summary:    r.Summary,
+		SummaryKey: key,
+		Lessons:    []*Lesson{},
+	}
+	lessonIDs := []int64{}
+	for _, d := range r.Lessons {
+		confidence := d.Confidence
+		change, err := AddLessonTx(tx, agentName, r.ProjectID, d.Text, "retrospective:"+r.SessionID, &confidence)
+		if err != nil {
+			return nil, err
+		}
+		res.Lessons = append(res.Lessons, change.Lesson)
+		lessonIDs = append(lessonIDs, change.Lesson.ID)
+	}
+
+	meta, _ := json.Marshal(map[string]any{
+		"session_id":  r.SessionID,
+		"project_id":  r.ProjectID,
+		"mode":        r.Mode,
+		"provider":    r.Provider,
+		"summary_key": key,
+		"lesson_ids":  lessonIDs,
+	})
+	eventID, err := InsertEventTx(tx, models.EventKindRetrospective, agentName, "",
+		fmt.Sprintf("Session retrospective: %s (%d lessons)", r.SessionID, len(lessonIDs)), string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append retrospective event: %w", err)
+	}
+	res.EventID = eventID
+	return res, nil
+}
+
+// RecordRetrospectiveIdempotent performs RecordRetrospectiveTx once per (agent_name, request_id).
+func RecordRetrospectiveIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, r RetrospectiveRecord) (*RetrospectiveResult, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "hook.retrospective", func(tx *sql.Tx) (*RetrospectiveResult, error) {
+		return RecordRetrospectiveTx(tx, agentName, r)
+	})
+}
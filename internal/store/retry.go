@@ -9,12 +9,32 @@ import (
 	"github.com/cenkalti/backoff/v4"
 	sqlite "modernc.org/sqlite"
 	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // RetryWithBackoff wraps an operation with exponential backoff retry logic.
 // Retries on transient SQLite errors (SQLITE_BUSY, "database is locked").
 // Does not retry on version conflicts or constraint violations.
+//
+// When ctx carries a trace, each call is a span named after the calling store
+// function, carrying the number of retries it took.
 func RetryWithBackoff(ctx context.Context, operation func() error) error {
+	return retryWithBackoff(ctx, 1, operation)
+}
+
+// retryWithBackoff is RetryWithBackoff with its span named after the function
+// skip frames above the caller, so wrappers such as Transact name the store
+// function that called them.
+func retryWithBackoff(ctx context.Context, skip int, operation func() error) (err error) {
+	ctx, span := telemetry.StartCaller(ctx, skip+1)
+	attempts := 0
+	defer func() {
+		span.Set("db.system.name", "sqlite")
+		span.Set("vybe.retries", max(attempts-1, 0))
+		span.End(err)
+	}()
+
 	b := backoff.NewExponentialBackOff()
 	b.InitialInterval = 50 * time.Millisecond
 	b.MaxInterval = 2 * time.Second
@@ -26,6 +46,7 @@ func RetryWithBackoff(ctx context.Context, operation func() error) error {
 			return backoff.Permanent(err)
 		}
 
+		attempts++
 		err := operation()
 		if err == nil {
 			return nil
@@ -131,14 +131,14 @@ func TestConcurrentCASUpdates(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "CAS Test", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "CAS Test", "", "", 0)
 	require.NoError(t, err)
 
 	const numRounds = 5
 	const numContenders = 4
 
 	for round := 0; round < numRounds; round++ {
-		current, err := GetTask(db, task.ID)
+		current, err := GetTask(context.Background(), db, task.ID)
 		require.NoError(t, err)
 
 		var wg sync.WaitGroup
@@ -152,7 +152,7 @@ func TestConcurrentCASUpdates(t *testing.T) {
 				if current.Status == "in_progress" {
 					newStatus = "pending"
 				}
-				updateErr := UpdateTaskStatus(db, task.ID, newStatus, current.Version)
+				updateErr := UpdateTaskStatus(context.Background(), db, task.ID, newStatus, current.Version)
 				if updateErr != nil {
 					if IsVersionConflict(updateErr) {
 						atomic.AddInt32(&conflicts, 1)
@@ -171,7 +171,7 @@ func TestConcurrentCASUpdates(t *testing.T) {
 		assert.Equal(t, int32(numContenders-1), conflicts, "round %d: rest should get version conflict", round)
 	}
 
-	final, err := GetTask(db, task.ID)
+	final, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, 1+numRounds, final.Version, "version should have incremented once per round")
 }
@@ -19,6 +19,11 @@ type taskRowScanner struct {
 	task          models.Task
 	projID        sql.NullString
 	blockedReason sql.NullString
+	dueAt         sql.NullTime
+	size          sql.NullString
+	retryAt       sql.NullTime
+	outcome       sql.NullString
+	supersededBy  sql.NullString
 }
 
 func (s *taskRowScanner) scan(row interface {
@@ -32,6 +37,12 @@ func (s *taskRowScanner) scan(row interface {
 		&s.task.Priority,
 		&s.projID,
 		&s.blockedReason,
+		&s.dueAt,
+		&s.size,
+		&s.task.Attempts,
+		&s.retryAt,
+		&s.outcome,
+		&s.supersededBy,
 		&s.task.Version,
 		&s.task.CreatedAt,
 		&s.task.UpdatedAt,
@@ -43,6 +54,17 @@ func (s *taskRowScanner) hydrate() {
 	if s.blockedReason.Valid {
 		s.task.BlockedReason = models.BlockedReason(s.blockedReason.String)
 	}
+	s.task.Size = models.TaskSize(s.size.String)
+	if s.dueAt.Valid {
+		due := s.dueAt.Time.UTC()
+		s.task.DueAt = &due
+	}
+	s.task.Outcome = models.TaskOutcome(s.outcome.String)
+	s.task.SupersededBy = s.supersededBy.String
+	if s.retryAt.Valid {
+		retry := s.retryAt.Time.UTC()
+		s.task.RetryAt = &retry
+	}
 }
 
 func (s *taskRowScanner) getTask() *models.Task {
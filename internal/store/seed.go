@@ -0,0 +1,379 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Seed limits keep an accidental extra zero from filling the disk.
+const (
+	maxSeedTasks  = 100000
+	maxSeedEvents = 5000000
+	maxSeedAgents = 100
+)
+
+const seedTimeLayout = "2006-01-02 15:04:05"
+
+// SeedOptions controls synthetic data generation.
+type SeedOptions struct {
+	Tasks  int    // Tasks to create in the seeded project
+	Events int    // Activity events (prompts, reasoning, progress, failures) to spread across tasks
+	Agents int    // Agents named seed-agent-1..N
+	Days   int    // Width of the history window ending now; default 14
+	Seed   uint64 // PRNG seed; the same seed produces the same graph, statuses, and event mix
+
+	// AllowExisting permits seeding a database that already holds tasks.
+	// Without it, seeding refuses so synthetic data never mixes into real work.
+	AllowExisting bool
+}
+
+// SeedResult summarizes what SeedSyntheticData inserted.
+type SeedResult struct {
+	ProjectID       string         `json:"project_id"`
+	Agents          []string       `json:"agents"`
+	Tasks           int            `json:"tasks"`
+	Dependencies    int            `json:"dependencies"`
+	TaskStatuses    map[string]int `json:"task_statuses"`
+	Events          int            `json:"events"`
+	LifecycleEvents int            `json:"lifecycle_events"`
+	EventKinds      map[string]int `json:"event_kinds"`
+	Seed            uint64         `json:"seed"`
+}
+
+// seedEventKinds weights activity events roughly as hooks produce them in real
+// sessions: mostly progress, with prompts and reasoning interleaved and a thin
+// tail of failures and checkpoints.
+var seedEventKinds = []struct {
+	kind   string
+	weight int
+}{
+	{models.EventKindProgress, 40},
+	{models.EventKindUserPrompt, 15},
+	{models.EventKindReasoning, 15},
+	{models.EventKindToolFailure, 8},
+	{models.EventKindCheckpoint, 4},
+}
+
+var seedTools = []string{"Bash", "Edit", "Read", "Write", "Grep"}
+
+type seedTask struct {
+	id        string
+	status    string
+	reason    string
+	deps      []int
+	createdAt time.Time
+}
+
+// SeedSyntheticData populates db with a fresh project, agents, a task DAG and an
+// event history for benchmarking and reproducing scale issues. Everything is
+// written in one transaction; nothing is inserted if any step fails.
+//
+// The dependency graph mixes chains, fan-in, and hub fan-out. Edges only point
+// to earlier tasks, so the graph is acyclic. Earlier tasks are more likely to be
+// completed, and a task whose dependency is unfinished stays pending.
+func SeedSyntheticData(ctx context.Context, db *sql.DB, opts SeedOptions) (*SeedResult, error) {
+	switch {
+	case opts.Tasks < 0 || opts.Tasks > maxSeedTasks:
+		return nil, fmt.Errorf("tasks must be between 0 and %d", maxSeedTasks)
+	case opts.Events < 0 || opts.Events > maxSeedEvents:
+		return nil, fmt.Errorf("events must be between 0 and %d", maxSeedEvents)
+	case opts.Agents < 1 || opts.Agents > maxSeedAgents:
+		return nil, fmt.Errorf("agents must be between 1 and %d", maxSeedAgents)
+	}
+	if opts.Days <= 0 {
+		opts.Days = 14
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15)) //nolint:gosec // G404: synthetic data, reproducibility over unpredictability
+	now := time.Now().UTC().Truncate(time.Second)
+	start := now.Add(-time.Duration(opts.Days) * 24 * time.Hour)
+
+	agents := make([]string, opts.Agents)
+	for i := range agents {
+		agents[i] = fmt.Sprintf("seed-agent-%d", i+1)
+	}
+	tasks := planSeedTasks(rng, opts.Tasks, start, now)
+
+	res := &SeedResult{
+		Agents:       agents,
+		Tasks:        len(tasks),
+		TaskStatuses: map[string]int{},
+		EventKinds:   map[string]int{},
+		Seed:         opts.Seed,
+	}
+
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
+		if !opts.AllowExisting {
+			var existing bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM tasks)`).Scan(&existing); err != nil {
+				return fmt.Errorf("failed to check for existing tasks: %w", err)
+			}
+			if existing {
+				return errors.New("database already contains tasks; seed a scratch database (--db-path) or pass --force")
+			}
+		}
+
+		project, err := CreateProjectTx(tx, "seed", `{"seeded":true}`)
+		if err != nil {
+			return err
+		}
+		res.ProjectID = project.ID
+
+		if err := insertSeedTasks(tx, project.ID, tasks, res); err != nil {
+			return err
+		}
+
+		insertEvent, err := tx.PrepareContext(ctx, `
+			INSERT INTO events (kind, agent_name, project_id, task_id, message, metadata, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare event insert: %w", err)
+		}
+		defer func() { _ = insertEvent.Close() }()
+
+		for _, ev := range planSeedEvents(rng, tasks, agents, opts.Events, start, now) {
+			var meta any
+			if ev.metadata != "" {
+				meta = ev.metadata
+			}
+			r, err := insertEvent.ExecContext(ctx, ev.kind, ev.agent, project.ID,
+				nullIfEmpty(ev.taskID), ev.message, meta, ev.at.Format(seedTimeLayout))
+			if err != nil {
+				return fmt.Errorf("failed to insert seed event: %w", err)
+			}
+			eventID, err := r.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("failed to insert seed event: %w", err)
+			}
+			if err := chainEventTx(tx, eventID); err != nil {
+				return err
+			}
+			if ev.lifecycle {
+				res.LifecycleEvents++
+			} else {
+				res.Events++
+				res.EventKinds[ev.kind]++
+			}
+		}
+
+		return insertSeedAgents(tx, project.ID, agents, tasks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func planSeedTasks(rng *rand.Rand, n int, start, now time.Time) []seedTask {
+	tasks := make([]seedTask, n)
+	if n == 0 {
+		return tasks
+	}
+	hubs := max(1, n/10)
+	// Tasks are created over the first two thirds of the window so that the
+	// remaining third carries activity on an already-populated backlog.
+	span := now.Sub(start) * 2 / 3
+	for i := range tasks {
+		t := &tasks[i]
+		t.id = generateTaskID()
+		t.createdAt = start.Add(span * time.Duration(i) / time.Duration(n))
+
+		if i > 0 {
+			switch r := rng.Float64(); {
+			case r < 0.30: // chain
+				t.deps = []int{i - 1}
+			case r < 0.45: // fan-in from two earlier tasks
+				a, b := rng.IntN(i), rng.IntN(i)
+				t.deps = []int{a}
+				if b != a {
+					t.deps = append(t.deps, b)
+				}
+			case r < 0.60: // fan-out from a hub near the start of the backlog
+				t.deps = []int{rng.IntN(min(hubs, i))}
+			}
+		}
+
+		depsDone := true
+		for _, d := range t.deps {
+			if tasks[d].status != string(models.TaskStatusCompleted) {
+				depsDone = false
+			}
+		}
+		doneChance := 0.85 * (1 - float64(i)/float64(n))
+		switch r := rng.Float64(); {
+		case !depsDone:
+			t.status = string(models.TaskStatusPending)
+		case r < doneChance:
+			t.status = string(models.TaskStatusCompleted)
+		case r < doneChance+0.10:
+			t.status = string(models.TaskStatusInProgress)
+		case r < doneChance+0.14:
+			t.status = string(models.TaskStatusBlocked)
+			t.reason = models.BlockedReasonFailurePrefix + "seeded failure"
+		default:
+			t.status = string(models.TaskStatusPending)
+		}
+	}
+	return tasks
+}
+
+func insertSeedTasks(tx *sql.Tx, projectID string, tasks []seedTask, res *SeedResult) error {
+	insertTask, err := tx.PrepareContext(context.Background(), `
+		INSERT INTO tasks (id, title, description, status, priority, project_id, blocked_reason, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare task insert: %w", err)
+	}
+	defer func() { _ = insertTask.Close() }()
+
+	insertDep, err := tx.PrepareContext(context.Background(), `
+		INSERT OR IGNORE INTO task_dependencies (task_id, depends_on_task_id, created_at) VALUES (?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare dependency insert: %w", err)
+	}
+	defer func() { _ = insertDep.Close() }()
+
+	for i, t := range tasks {
+		ts := t.createdAt.Format(seedTimeLayout)
+		if _, err := insertTask.ExecContext(context.Background(), t.id,
+			fmt.Sprintf("Seed task %d", i+1), "Synthetic task generated by vybe dev seed",
+			t.status, i%4, projectID, nullIfEmpty(t.reason), ts, ts); err != nil {
+			return fmt.Errorf("failed to insert seed task: %w", err)
+		}
+		res.TaskStatuses[t.status]++
+		for _, d := range t.deps {
+			r, err := insertDep.ExecContext(context.Background(), t.id, tasks[d].id, ts)
+			if err != nil {
+				return fmt.Errorf("failed to insert seed dependency: %w", err)
+			}
+			if n, _ := r.RowsAffected(); n > 0 {
+				res.Dependencies++
+			}
+		}
+	}
+	return nil
+}
+
+type seedEvent struct {
+	at        time.Time
+	kind      string
+	agent     string
+	taskID    string
+	message   string
+	metadata  string
+	lifecycle bool
+}
+
+// planSeedEvents returns task lifecycle events plus n activity events, sorted by
+// time so event IDs increase with created_at as they do in real databases.
+// Activity is Zipf-distributed over tasks: a few hot tasks get most of it.
+func planSeedEvents(rng *rand.Rand, tasks []seedTask, agents []string, n int, start, now time.Time) []seedEvent {
+	events := make([]seedEvent, 0, n+2*len(tasks))
+	pickAgent := func() string { return agents[rng.IntN(len(agents))] }
+
+	for _, t := range tasks {
+		events = append(events, seedEvent{
+			at: t.createdAt, kind: models.EventKindTaskCreated, agent: pickAgent(),
+			taskID: t.id, message: "Task created", lifecycle: true,
+		})
+		if t.status != string(models.TaskStatusPending) {
+			events = append(events, seedEvent{
+				at:   t.createdAt.Add(time.Duration(rng.Int64N(int64(now.Sub(t.createdAt)) + 1))),
+				kind: models.EventKindTaskStatus, agent: pickAgent(), taskID: t.id,
+				message: "Status changed to " + t.status, lifecycle: true,
+			})
+		}
+	}
+
+	totalWeight := 0
+	for _, k := range seedEventKinds {
+		totalWeight += k.weight
+	}
+	var zipf *rand.Zipf
+	if len(tasks) > 1 {
+		zipf = rand.NewZipf(rng, 1.2, 1, uint64(len(tasks)-1))
+	}
+	window := int64(now.Sub(start))
+
+	for range n {
+		w := rng.IntN(totalWeight)
+		kind := seedEventKinds[0].kind
+		for _, k := range seedEventKinds {
+			if w < k.weight {
+				kind = k.kind
+				break
+			}
+			w -= k.weight
+		}
+
+		ev := seedEvent{kind: kind, agent: pickAgent()}
+		earliest := start
+		if len(tasks) > 0 {
+			idx := 0
+			if zipf != nil {
+				// Zipf favors low indexes; map them onto the recent end of the backlog.
+				idx = len(tasks) - 1 - int(zipf.Uint64()) //nolint:gosec // G115: bounded by len(tasks)-1
+			}
+			ev.taskID = tasks[idx].id
+			earliest = tasks[idx].createdAt
+		}
+		ev.at = earliest.Add(time.Duration(rng.Int64N(max(1, window-int64(earliest.Sub(start))))))
+
+		switch kind {
+		case models.EventKindUserPrompt:
+			ev.message = "Synthetic user prompt"
+		case models.EventKindReasoning:
+			ev.message = "Synthetic reasoning step"
+		case models.EventKindToolFailure:
+			tool := seedTools[rng.IntN(len(seedTools))]
+			ev.message = tool + " failed"
+			ev.metadata = fmt.Sprintf(`{"tool_name":%q,"exit_code":%d}`, tool, 1+rng.IntN(2))
+		case models.EventKindCheckpoint:
+			ev.message = "Checkpoint"
+		default:
+			tool := seedTools[rng.IntN(len(seedTools))]
+			ev.message = "Ran " + tool
+			ev.metadata = fmt.Sprintf(`{"tool_name":%q}`, tool)
+		}
+		events = append(events, ev)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+	return events
+}
+
+// insertSeedAgents creates agent state rows focused on the seeded project, each
+// holding one in-progress task when there are enough to go around.
+func insertSeedAgents(tx *sql.Tx, projectID string, agents []string, tasks []seedTask) error {
+	var inProgress []string
+	for _, t := range tasks {
+		if t.status == string(models.TaskStatusInProgress) {
+			inProgress = append(inProgress, t.id)
+		}
+	}
+	for i, name := range agents {
+		if err := ensureAgentStateTx(tx, name); err != nil {
+			return err
+		}
+		var focus any
+		if i < len(inProgress) {
+			focus = inProgress[i]
+		}
+		if _, err := tx.ExecContext(context.Background(), `
+			UPDATE agent_state SET focus_task_id = ?, focus_project_id = ?, version = version + 1 WHERE agent_name = ?
+		`, focus, projectID, name); err != nil {
+			return fmt.Errorf("failed to seed agent state: %w", err)
+		}
+	}
+	return nil
+}
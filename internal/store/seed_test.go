@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedSyntheticData_PopulatesAcyclicGraphAndEvents(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	res, err := SeedSyntheticData(context.Background(), db, SeedOptions{Tasks: 30, Events: 400, Agents: 2, Seed: 7})
+	require.NoError(t, err)
+	assert.Equal(t, 30, res.Tasks)
+	assert.Equal(t, 400, res.Events)
+	assert.Equal(t, []string{"seed-agent-1", "seed-agent-2"}, res.Agents)
+
+	var tasks, events, deps, backwards int
+	require.NoError(t, db.QueryRowContext(context.Background(),
+		`SELECT COUNT(*) FROM tasks WHERE project_id = ?`, res.ProjectID).Scan(&tasks))
+	require.NoError(t, db.QueryRowContext(context.Background(),
+		`SELECT COUNT(*) FROM events WHERE project_id = ?`, res.ProjectID).Scan(&events))
+	require.NoError(t, db.QueryRowContext(context.Background(),
+		`SELECT COUNT(*) FROM task_dependencies`).Scan(&deps))
+	// Every edge points at an earlier task, which is what keeps the graph acyclic.
+	require.NoError(t, db.QueryRowContext(context.Background(), `
+		SELECT COUNT(*) FROM task_dependencies d
+		JOIN tasks a ON a.id = d.task_id JOIN tasks b ON b.id = d.depends_on_task_id
+		WHERE b.created_at > a.created_at`).Scan(&backwards))
+	assert.Equal(t, 30, tasks)
+	assert.Equal(t, res.Events+res.LifecycleEvents, events)
+	assert.Equal(t, res.Dependencies, deps)
+	assert.Positive(t, deps)
+	assert.Zero(t, backwards)
+
+	state, err := GetAgentState(context.Background(), db, "seed-agent-2")
+	require.NoError(t, err)
+	assert.Equal(t, res.ProjectID, state.FocusProjectID)
+
+	_, err = SeedSyntheticData(context.Background(), db, SeedOptions{Tasks: 1, Agents: 1})
+	require.Error(t, err, "refuses a database that already has tasks")
+	_, err = SeedSyntheticData(context.Background(), db, SeedOptions{Tasks: 1, Agents: 1, AllowExisting: true})
+	require.NoError(t, err)
+}
+
+func TestSeedSyntheticData_SameSeedSameShape(t *testing.T) {
+	db1, cleanup1 := setupTestDB(t)
+	defer cleanup1()
+	db2, cleanup2 := setupTestDB(t)
+	defer cleanup2()
+
+	a, err := SeedSyntheticData(context.Background(), db1, SeedOptions{Tasks: 40, Events: 300, Agents: 3, Seed: 42})
+	require.NoError(t, err)
+	b, err := SeedSyntheticData(context.Background(), db2, SeedOptions{Tasks: 40, Events: 300, Agents: 3, Seed: 42})
+	require.NoError(t, err)
+
+	assert.Equal(t, a.TaskStatuses, b.TaskStatuses)
+	assert.Equal(t, a.Dependencies, b.Dependencies)
+	assert.Equal(t, a.EventKinds, b.EventKinds)
+}
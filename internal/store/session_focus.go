@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SessionFocusActiveWindow is how recently a sibling session must have resumed
+// for its focus task to be withheld from other sessions of the same agent.
+const SessionFocusActiveWindow = 6 * time.Hour
+
+// SessionFocus is the focus pointer owned by one session of an agent.
+type SessionFocus struct {
+	AgentName      string    `json:"agent_name"`
+	SessionID      string    `json:"session_id"`
+	FocusTaskID    string    `json:"focus_task_id,omitempty"`
+	FocusProjectID string    `json:"focus_project_id,omitempty"`
+	LastActiveAt   time.Time `json:"last_active_at"`
+}
+
+// GetSessionFocus returns the session's focus, or found=false for a session
+// that has not resumed yet.
+func GetSessionFocus(ctx context.Context, db *sql.DB, agentName, sessionID string) (SessionFocus, bool, error) {
+	sf := SessionFocus{AgentName: agentName, SessionID: sessionID}
+	var taskID, projectID sql.NullString
+	err := RetryWithBackoff(ctx, func() error {
+		return db.QueryRowContext(ctx, `
+			SELECT focus_task_id, focus_project_id, last_active_at
+			FROM agent_session_state
+			WHERE agent_name = ? AND session_id = ?
+		`, agentName, sessionID).Scan(&taskID, &projectID, &sf.LastActiveAt)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return sf, false, nil
+	}
+	if err != nil {
+		return SessionFocus{}, false, fmt.Errorf("failed to load session focus: %w", err)
+	}
+	sf.FocusTaskID = taskID.String
+	sf.FocusProjectID = projectID.String
+	return sf, true, nil
+}
+
+// SetSessionFocusTx upserts the session's focus and marks it active.
+func SetSessionFocusTx(tx *sql.Tx, agentName, sessionID, taskID, projectID string) error {
+	if agentName == "" || sessionID == "" {
+		return errors.New("agent name and session id are required")
+	}
+	if projectID != "" {
+		if err := checkConfinedFocusTx(tx, agentName, projectID); err != nil {
+			return err
+		}
+	}
+	_, err := tx.ExecContext(context.Background(), `
+		INSERT INTO agent_session_state (agent_name, session_id, focus_task_id, focus_project_id, last_active_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(agent_name, session_id) DO UPDATE SET
+			focus_task_id = excluded.focus_task_id,
+			focus_project_id = excluded.focus_project_id,
+			last_active_at = excluded.last_active_at
+	`, agentName, sessionID, nullIfEmpty(taskID), nullIfEmpty(projectID), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save session focus: %w", err)
+	}
+	return nil
+}
+
+// TouchSessionFocus marks the session active now without changing its focus,
+// so a long-running holder keeps its task withheld from sibling sessions.
+func TouchSessionFocus(ctx context.Context, db *sql.DB, agentName, sessionID string) error {
+	err := RetryWithBackoff(ctx, func() error {
+		_, err := db.ExecContext(ctx, `
+			UPDATE agent_session_state SET last_active_at = ?
+			WHERE agent_name = ? AND session_id = ?
+		`, time.Now().UTC(), agentName, sessionID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to touch session focus: %w", err)
+	}
+	return nil
+}
+
+// SiblingSessionFocusTasks returns task IDs held by the agent's other sessions
+// that were active within SessionFocusActiveWindow of now.
+func SiblingSessionFocusTasks(ctx context.Context, db *sql.DB, agentName, sessionID string, now time.Time) ([]string, error) {
+	var ids []string
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT focus_task_id FROM agent_session_state
+			WHERE agent_name = ? AND session_id != ?
+			  AND focus_task_id IS NOT NULL AND last_active_at >= ?
+			ORDER BY last_active_at DESC
+		`, agentName, sessionID, now.Add(-SessionFocusActiveWindow).UTC())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+
+		ids = ids[:0]
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sibling session focus: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// sessionIDExpr extracts metadata.session_id. It must stay identical to the
+// expression indexed by idx_events_session_id.
+const sessionIDExpr = `(CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.session_id') END)`
+
+// SessionEvents is the event log of one client session: events tagged with the
+// session id, plus untagged events written by the same agents between the
+// session's first and last tagged event (CLI pushes and status changes carry no
+// session id). When the session has a sessions row, the window runs from its
+// start event to its end event, or to the latest event while it is active.
+type SessionEvents struct {
+	SessionID string
+	Session   *models.Session
+	Agents    []string
+	Events    []*models.Event
+	Tagged    map[int64]bool
+}
+
+// SessionNotFoundError reports a session id with no sessions row and no
+// tagged events.
+type SessionNotFoundError struct{ SessionID string }
+
+func (e *SessionNotFoundError) Error() string {
+	return fmt.Sprintf("session %q not found", e.SessionID)
+}
+func (e *SessionNotFoundError) ErrorCode() string { return "SESSION_NOT_FOUND" }
+func (e *SessionNotFoundError) Context() map[string]string {
+	return map[string]string{"session_id": e.SessionID}
+}
+func (e *SessionNotFoundError) SuggestedAction() string {
+	return "list recorded sessions with: vybe session list"
+}
+
+// FetchSessionEventsByID returns the chronological events of sessionID,
+// including archived ones, capped at limit.
+func FetchSessionEventsByID(ctx context.Context, db *sql.DB, sessionID string, limit int) (*SessionEvents, error) {
+	if limit <= 0 {
+		limit = 2000
+	}
+
+	out := &SessionEvents{SessionID: sessionID, Tagged: map[int64]bool{}}
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT id, kind, agent_name, project_id, task_id, message, metadata, created_at
+			FROM events
+			WHERE `+sessionIDExpr+` = ?
+			ORDER BY id ASC
+			LIMIT ?
+		`, sessionID, limit)
+		if err != nil {
+			return fmt.Errorf("failed to fetch session events: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		out.Events, err = scanEventRows(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Events) == 0 {
+		return nil, &SessionNotFoundError{SessionID: sessionID}
+	}
+
+	seen := map[string]bool{}
+	for _, e := range out.Events {
+		out.Tagged[e.ID] = true
+		if !seen[e.AgentName] {
+			seen[e.AgentName] = true
+			out.Agents = append(out.Agents, e.AgentName)
+		}
+	}
+
+	firstID, lastID := out.Events[0].ID, out.Events[len(out.Events)-1].ID
+	sess, err := GetSession(ctx, db, sessionID)
+	var nf *SessionNotFoundError
+	switch {
+	case err == nil:
+		out.Session = sess
+		firstID = min(firstID, sess.StartEventID)
+		if sess.EndEventID > 0 {
+			lastID = max(lastID, sess.EndEventID)
+		} else {
+			lastID = math.MaxInt64
+		}
+	case !errors.As(err, &nf):
+		return nil, err
+	}
+	args := []any{firstID, lastID}
+	for _, a := range out.Agents {
+		args = append(args, a)
+	}
+	args = append(args, limit)
+
+	var untagged []*models.Event
+	err = RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT id, kind, agent_name, project_id, task_id, message, metadata, created_at
+			FROM events
+			WHERE id > ? AND id < ?
+			  AND agent_name IN (?`+strings.Repeat(", ?", len(out.Agents)-1)+`)
+			  AND COALESCE(`+sessionIDExpr+`, '') = ''
+			ORDER BY id ASC
+			LIMIT ?
+		`, args...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch session window events: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		untagged, err = scanEventRows(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out.Events = mergeEventsByID(out.Events, untagged, limit)
+	return out, nil
+}
+
+// mergeEventsByID merges two id-ascending slices, keeping at most limit events.
+func mergeEventsByID(a, b []*models.Event, limit int) []*models.Event {
+	merged := make([]*models.Event, 0, min(len(a)+len(b), limit))
+	i, j := 0, 0
+	for len(merged) < limit && (i < len(a) || j < len(b)) {
+		if j == len(b) || (i < len(a) && a[i].ID < b[j].ID) {
+			merged = append(merged, a[i])
+			i++
+		} else {
+			merged = append(merged, b[j])
+			j++
+		}
+	}
+	return merged
+}
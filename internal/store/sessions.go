@@ -0,0 +1,278 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// sessionCompletedStatusMsg is the task_status message written when a task
+// moves to completed; sessions count these to derive tasks_completed.
+const sessionCompletedStatusMsg = "Status changed to: completed"
+
+// sessionSelectSQL selects a session row with its derived counters: events
+// tagged with the session id, and distinct tasks the agent completed between
+// the session's start event and its end event (or now, while active).
+const sessionSelectSQL = `
+	SELECT s.id, s.agent_name, s.label, s.project_id, s.source, s.started_at, s.ended_at,
+	       s.end_reason, s.outcome, s.start_event_id, s.end_event_id,
+	       (SELECT COUNT(*) FROM events e WHERE ` + sessionIDExprAlias + ` = s.id),
+	       (SELECT COUNT(DISTINCT c.task_id) FROM events c
+	         WHERE c.agent_name = s.agent_name
+	           AND c.kind = 'task_status' AND c.message = '` + sessionCompletedStatusMsg + `'
+	           AND c.id > s.start_event_id
+	           AND (s.end_event_id IS NULL OR c.id <= s.end_event_id))
+	FROM sessions s
+`
+
+// sessionIDExprAlias is sessionIDExpr qualified for the events alias "e".
+const sessionIDExprAlias = `(CASE WHEN json_valid(e.metadata) THEN json_extract(e.metadata, '$.session_id') END)`
+
+// ListSessionsParams filters ListSessions.
+type ListSessionsParams struct {
+	AgentName  string
+	ProjectID  string
+	ActiveOnly bool
+	Limit      int
+}
+
+// StartSessionTx records the start of sessionID and appends a session_started
+// event tagged with the session id. Starting a known session (resume, clear)
+// keeps its original start time and reopens it if it had ended.
+func StartSessionTx(tx *sql.Tx, agentName, sessionID, projectID, source string) (*models.Session, error) {
+	if agentName == "" || sessionID == "" {
+		return nil, errors.New("agent name and session id are required")
+	}
+
+	meta, err := json.Marshal(map[string]string{"session_id": sessionID, "source": source})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session metadata: %w", err)
+	}
+	eventID, err := InsertEventWithProjectTx(tx, models.EventKindSessionStarted, agentName, projectID, "",
+		"Session started", string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append session event: %w", err)
+	}
+
+	_, err = tx.ExecContext(context.Background(), `
+		INSERT INTO sessions (id, agent_name, project_id, source, started_at, start_event_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			project_id = COALESCE(excluded.project_id, sessions.project_id),
+			source = COALESCE(excluded.source, sessions.source),
+			ended_at = NULL,
+			end_reason = NULL,
+			outcome = NULL,
+			end_event_id = NULL
+	`, sessionID, agentName, nullIfEmpty(projectID), nullIfEmpty(source), time.Now().UTC(), eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record session start: %w", err)
+	}
+	return getSessionTx(tx, sessionID)
+}
+
+// StartSessionIdempotent wraps StartSessionTx in an idempotent transaction.
+//
+//nolint:revive // argument-limit: agent, request, session, project, source are all required
+func StartSessionIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, sessionID, projectID, source string) (*models.Session, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "session.start", func(tx *sql.Tx) (*models.Session, error) {
+		return StartSessionTx(tx, agentName, sessionID, projectID, source)
+	})
+}
+
+// EndSessionTx closes sessionID. An empty outcome is derived: "completed" when
+// the agent completed at least one task during the session, "incomplete"
+// otherwise. Ending an already-ended session returns it unchanged.
+//
+//nolint:revive // argument-limit: agent, session, reason, outcome are all required
+func EndSessionTx(tx *sql.Tx, agentName, sessionID, reason, outcome string) (*models.Session, error) {
+	s, err := getSessionTx(tx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if s.EndedAt != nil {
+		return s, nil
+	}
+	if outcome == "" {
+		outcome = models.SessionOutcomeIncomplete
+		if s.TasksCompleted > 0 {
+			outcome = models.SessionOutcomeCompleted
+		}
+	}
+
+	meta, err := json.Marshal(map[string]any{
+		"session_id":      sessionID,
+		"reason":          reason,
+		"outcome":         outcome,
+		"tasks_completed": s.TasksCompleted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session metadata: %w", err)
+	}
+	eventID, err := InsertEventWithProjectTx(tx, models.EventKindSessionEnded, agentName, s.ProjectID, "",
+		fmt.Sprintf("Session ended: %s", outcome), string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append session event: %w", err)
+	}
+
+	_, err = tx.ExecContext(context.Background(), `
+		UPDATE sessions
+		SET ended_at = ?, end_reason = ?, outcome = ?, end_event_id = ?
+		WHERE id = ?
+	`, time.Now().UTC(), nullIfEmpty(reason), outcome, eventID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record session end: %w", err)
+	}
+	return getSessionTx(tx, sessionID)
+}
+
+// EndSessionIdempotent wraps EndSessionTx in an idempotent transaction.
+//
+//nolint:revive // argument-limit: agent, request, session, reason, outcome are all required
+func EndSessionIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, sessionID, reason, outcome string) (*models.Session, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "session.end", func(tx *sql.Tx) (*models.Session, error) {
+		return EndSessionTx(tx, agentName, sessionID, reason, outcome)
+	})
+}
+
+// SetSessionLabelTx names sessionID and appends a session_labeled event. With
+// onlyIfUnset, a session that already has a label is returned unchanged, so the
+// first prompt names the session and later prompts do not rename it.
+//
+//nolint:revive // argument-limit: agent, session, label, onlyIfUnset are all required
+func SetSessionLabelTx(tx *sql.Tx, agentName, sessionID, label string, onlyIfUnset bool) (*models.Session, error) {
+	if label == "" {
+		return nil, errors.New("session label is required")
+	}
+	s, err := getSessionTx(tx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if s.Label == label || (onlyIfUnset && s.Label != "") {
+		return s, nil
+	}
+
+	meta, err := json.Marshal(map[string]any{
+		"session_id": sessionID,
+		"label":      label,
+		"previous":   s.Label,
+		"auto":       onlyIfUnset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session metadata: %w", err)
+	}
+	if _, err := InsertEventWithProjectTx(tx, models.EventKindSessionLabeled, agentName, s.ProjectID, "",
+		fmt.Sprintf("Session labeled: %s", label), string(meta)); err != nil {
+		return nil, fmt.Errorf("failed to append session event: %w", err)
+	}
+	if _, err := tx.ExecContext(context.Background(), `UPDATE sessions SET label = ? WHERE id = ?`, label, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to record session label: %w", err)
+	}
+	return getSessionTx(tx, sessionID)
+}
+
+// SetSessionLabelIdempotent wraps SetSessionLabelTx in an idempotent transaction.
+//
+//nolint:revive // argument-limit: agent, request, session, label, onlyIfUnset are all required
+func SetSessionLabelIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, sessionID, label string, onlyIfUnset bool) (*models.Session, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "session.label", func(tx *sql.Tx) (*models.Session, error) {
+		return SetSessionLabelTx(tx, agentName, sessionID, label, onlyIfUnset)
+	})
+}
+
+// GetSession returns the session row for sessionID.
+func GetSession(ctx context.Context, db *sql.DB, sessionID string) (*models.Session, error) {
+	var s *models.Session
+	err := RetryWithBackoff(ctx, func() error {
+		var err error
+		s, err = scanSession(db.QueryRowContext(ctx, sessionSelectSQL+` WHERE s.id = ?`, sessionID), sessionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func getSessionTx(tx *sql.Tx, sessionID string) (*models.Session, error) {
+	return scanSession(tx.QueryRowContext(context.Background(), sessionSelectSQL+` WHERE s.id = ?`, sessionID), sessionID)
+}
+
+// ListSessions returns sessions newest first.
+func ListSessions(ctx context.Context, db *sql.DB, p ListSessionsParams) ([]*models.Session, error) {
+	if p.Limit <= 0 {
+		p.Limit = 50
+	}
+	query := sessionSelectSQL + ` WHERE 1=1`
+	var args []any
+	if p.AgentName != "" {
+		query += ` AND s.agent_name = ?`
+		args = append(args, p.AgentName)
+	}
+	if p.ProjectID != "" {
+		query += ` AND s.project_id = ?`
+		args = append(args, p.ProjectID)
+	}
+	if p.ActiveOnly {
+		query += ` AND s.ended_at IS NULL`
+	}
+	query += ` ORDER BY s.started_at DESC, s.rowid DESC LIMIT ?`
+	args = append(args, p.Limit)
+
+	var out []*models.Session
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to list sessions: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = out[:0]
+		for rows.Next() {
+			s, err := scanSession(rows, "")
+			if err != nil {
+				return err
+			}
+			out = append(out, s)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func scanSession(row interface{ Scan(dest ...any) error }, sessionID string) (*models.Session, error) {
+	var (
+		s                                models.Session
+		label, projectID, source, reason sql.NullString
+		outcome                          sql.NullString
+		endedAt                          sql.NullTime
+		endEventID                       sql.NullInt64
+	)
+	err := row.Scan(&s.ID, &s.AgentName, &label, &projectID, &source, &s.StartedAt, &endedAt,
+		&reason, &outcome, &s.StartEventID, &endEventID, &s.EventCount, &s.TasksCompleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &SessionNotFoundError{SessionID: sessionID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan session: %w", err)
+	}
+	s.Label = label.String
+	s.ProjectID = projectID.String
+	s.Source = source.String
+	s.EndReason = reason.String
+	s.Outcome = outcome.String
+	s.EndEventID = endEventID.Int64
+	if endedAt.Valid {
+		t := endedAt.Time
+		s.EndedAt = &t
+	}
+	return &s, nil
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestSessionLifecycle(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Ship it", "", "", 0)
+	require.NoError(t, err)
+
+	s, err := StartSessionIdempotent(context.Background(), db, "agent1", "start-1", "sess-1", "/repo", "startup")
+	require.NoError(t, err)
+	assert.Equal(t, "agent1", s.AgentName)
+	assert.Equal(t, "/repo", s.ProjectID)
+	assert.Nil(t, s.EndedAt)
+	assert.Equal(t, 1, s.EventCount, "session_started is tagged with the session id")
+
+	_, err = StartSessionIdempotent(context.Background(), db, "agent2", "start-2", "sess-2", "/other", "startup")
+	require.NoError(t, err)
+
+	require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
+		_, err := UpdateTaskStatusWithEventTx(tx, "agent1", task.ID, "completed", task.Version)
+		return err
+	}))
+
+	active, err := ListSessions(context.Background(), db, ListSessionsParams{ActiveOnly: true})
+	require.NoError(t, err)
+	assert.Len(t, active, 2)
+
+	ended, err := EndSessionIdempotent(context.Background(), db, "agent1", "end-1", "sess-1", "logout", "")
+	require.NoError(t, err)
+	require.NotNil(t, ended.EndedAt)
+	assert.Equal(t, models.SessionOutcomeCompleted, ended.Outcome)
+	assert.Equal(t, "logout", ended.EndReason)
+	assert.Equal(t, 1, ended.TasksCompleted)
+	assert.Equal(t, 2, ended.EventCount)
+
+	// A second end keeps the recorded outcome.
+	again, err := EndSessionIdempotent(context.Background(), db, "agent1", "end-2", "sess-1", "other", models.SessionOutcomeFailed)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionOutcomeCompleted, again.Outcome)
+
+	other, err := EndSessionIdempotent(context.Background(), db, "agent2", "end-3", "sess-2", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionOutcomeIncomplete, other.Outcome)
+
+	mine, err := ListSessions(context.Background(), db, ListSessionsParams{AgentName: "agent1"})
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, "sess-1", mine[0].ID)
+
+	_, err = GetSession(context.Background(), db, "missing")
+	var nf *SessionNotFoundError
+	require.ErrorAs(t, err, &nf)
+
+	// Restarting (resume) reopens the session and keeps its start time.
+	reopened, err := StartSessionIdempotent(context.Background(), db, "agent1", "start-3", "sess-1", "/repo", "resume")
+	require.NoError(t, err)
+	assert.Nil(t, reopened.EndedAt)
+	assert.Empty(t, reopened.Outcome)
+	assert.Equal(t, s.StartedAt.Unix(), reopened.StartedAt.Unix())
+}
+
+func TestSetSessionLabel(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := StartSessionIdempotent(context.Background(), db, "agent1", "start-1", "sess-1", "/repo", "startup")
+	require.NoError(t, err)
+
+	s, err := SetSessionLabelIdempotent(context.Background(), db, "agent1", "label-1", "sess-1", "Fix the flaky login test", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Fix the flaky login test", s.Label)
+
+	// An automatic label never replaces an existing one.
+	s, err = SetSessionLabelIdempotent(context.Background(), db, "agent1", "label-2", "sess-1", "Something else", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Fix the flaky login test", s.Label)
+
+	s, err = SetSessionLabelIdempotent(context.Background(), db, "agent1", "label-3", "sess-1", "Login test cleanup", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Login test cleanup", s.Label)
+
+	var labeled int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM events WHERE kind = ?`, models.EventKindSessionLabeled).Scan(&labeled))
+	assert.Equal(t, 2, labeled)
+
+	list, err := ListSessions(context.Background(), db, ListSessionsParams{})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Login test cleanup", list[0].Label)
+
+	_, err = SetSessionLabelIdempotent(context.Background(), db, "agent1", "label-4", "sess-missing", "x", false)
+	var notFound *SessionNotFoundError
+	require.ErrorAs(t, err, &notFound)
+}
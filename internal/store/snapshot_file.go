@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// snapshotCountTables are the tables whose row counts describe a snapshot file.
+var snapshotCountTables = []string{"tasks", "events", "memory", "projects", "artifacts", "sessions"}
+
+// SnapshotFileInfo describes a database file written by VacuumInto.
+type SnapshotFileInfo struct {
+	Path          string           `json:"path"`
+	SizeBytes     int64            `json:"size_bytes"`
+	ModifiedAt    time.Time        `json:"modified_at"`
+	SchemaVersion int64            `json:"schema_version"`
+	LatestVersion int64            `json:"latest_version"`
+	IntegrityOK   bool             `json:"integrity_ok"`
+	Tables        map[string]int64 `json:"tables"`
+	LastEventID   int64            `json:"last_event_id"`
+	LastEventAt   *time.Time       `json:"last_event_at,omitempty"`
+}
+
+// VacuumInto writes a compact, consistent copy of the whole database to path.
+// The copy is taken inside one read transaction, so concurrent writers are not
+// blocked and the file reflects a single point in time. path must not exist.
+func VacuumInto(ctx context.Context, db *sql.DB, path string) (err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if path == "" {
+		return errors.New("snapshot file path is required")
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("snapshot file already exists: %s", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check snapshot file: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create snapshot directory: %w", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	return nil
+}
+
+// OpenSnapshotReadOnly opens a snapshot file for inspection. The connection is
+// read-only and immutable: it takes no locks and never runs migrations.
+func OpenSnapshotReadOnly(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve snapshot path: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+abs+"?mode=ro&immutable=1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := db.PingContext(context.Background()); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	return db, nil
+}
+
+// InspectSnapshotFile opens path read-only and reports its schema version,
+// integrity, and row counts.
+func InspectSnapshotFile(ctx context.Context, path string) (*SnapshotFileInfo, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat snapshot file: %w", err)
+	}
+	db, err := OpenSnapshotReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	info := &SnapshotFileInfo{
+		Path:       path,
+		SizeBytes:  st.Size(),
+		ModifiedAt: st.ModTime().UTC(),
+		Tables:     map[string]int64{},
+	}
+
+	info.SchemaVersion, info.LatestVersion, err = SchemaVersion(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("not a vybe database: %w", err)
+	}
+
+	var check string
+	if err := db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&check); err != nil {
+		return nil, fmt.Errorf("failed to check snapshot integrity: %w", err)
+	}
+	info.IntegrityOK = check == "ok"
+
+	for _, table := range snapshotCountTables {
+		var n int64
+		// Table names come from the fixed snapshotCountTables list.
+		err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n) //nolint:gosec // G202: fixed table names
+		if err != nil {
+			if strings.Contains(err.Error(), "no such table") {
+				continue // older schema
+			}
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		info.Tables[table] = n
+	}
+
+	var lastID sql.NullInt64
+	var lastAt sql.NullTime
+	err = db.QueryRowContext(ctx, `
+		SELECT id, created_at FROM events ORDER BY id DESC LIMIT 1
+	`).Scan(&lastID, &lastAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to read last event: %w", err)
+	}
+	info.LastEventID = lastID.Int64
+	if lastAt.Valid {
+		t := lastAt.Time
+		info.LastEventAt = &t
+	}
+	return info, nil
+}
+
+// snapshotQueryMaxRows caps rows returned by QuerySnapshotFile.
+const snapshotQueryMaxRows = 1000
+
+// QuerySnapshotFile runs a read-only SQL query against a snapshot file and
+// returns up to snapshotQueryMaxRows rows as column maps. The connection is
+// opened read-only, so statements that write fail.
+func QuerySnapshotFile(path, query string) ([]map[string]any, error) {
+	db, err := OpenSnapshotReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	rows, err := db.QueryContext(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot query failed: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("snapshot query failed: %w", err)
+	}
+	out := []map[string]any{}
+	for rows.Next() && len(out) < snapshotQueryMaxRows {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("snapshot query failed: %w", err)
+		}
+		row := make(map[string]any, len(cols))
+		for i, c := range cols {
+			if b, ok := vals[i].([]byte); ok {
+				row[c] = string(b)
+			} else {
+				row[c] = vals[i]
+			}
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("snapshot query failed: %w", err)
+	}
+	return out, nil
+}
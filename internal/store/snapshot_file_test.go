@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVacuumInto_WritesReadOnlySnapshot(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := CreateTask(context.Background(), db, "Snapshot me", "", "", 0)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "snap.db")
+	require.NoError(t, VacuumInto(context.Background(), db, path))
+	require.Error(t, VacuumInto(context.Background(), db, path), "existing file is never overwritten")
+
+	info, err := InspectSnapshotFile(context.Background(), path)
+	require.NoError(t, err)
+	assert.True(t, info.IntegrityOK)
+	assert.Equal(t, info.LatestVersion, info.SchemaVersion)
+	assert.Equal(t, int64(1), info.Tables["tasks"])
+
+	// Later writes to the live DB do not reach the snapshot.
+	_, err = CreateTask(context.Background(), db, "After", "", "", 0)
+	require.NoError(t, err)
+
+	rows, err := QuerySnapshotFile(path, "SELECT title FROM tasks")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Snapshot me", rows[0]["title"])
+
+	_, err = QuerySnapshotFile(path, "DELETE FROM tasks")
+	require.Error(t, err)
+}
@@ -0,0 +1,432 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// Restore scopes for RestoreNamedSnapshotIdempotent.
+const (
+	SnapshotScopeTasks  = "tasks"
+	SnapshotScopeMemory = "memory"
+	SnapshotScopeAll    = "all"
+)
+
+// snapshotNameMaxLen bounds snapshot names; they are labels, not descriptions.
+const snapshotNameMaxLen = 128
+
+// snapshotTaskChildTables hang off tasks and are replaced wholesale when the
+// tasks scope is restored. Artifacts are included: they reference tasks
+// without a cascade, and their events are still in the (append-only) log.
+var snapshotTaskChildTables = []string{
+	"task_dependencies", "task_criteria", "task_metadata", "task_tags", "task_requirements", "task_failures", "task_delegations", "artifacts",
+}
+
+// NamedSnapshot is a registered snapshot file. Missing is set when the file
+// has been removed from disk since it was taken.
+type NamedSnapshot struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Path          string    `json:"path"`
+	SizeBytes     int64     `json:"size_bytes"`
+	SchemaVersion int64     `json:"schema_version"`
+	LastEventID   int64     `json:"last_event_id"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	Missing       bool      `json:"missing,omitempty"`
+}
+
+// NamedSnapshotResult reports a created snapshot and its snapshot_created event.
+type NamedSnapshotResult struct {
+	Snapshot *NamedSnapshot `json:"snapshot"`
+	EventID  int64          `json:"event_id"`
+}
+
+// SnapshotRestoreResult reports a scoped restore: the rows now in each
+// restored table, the live tasks that did not exist in the snapshot and were
+// removed, and the snapshot_restored event.
+type SnapshotRestoreResult struct {
+	Snapshot     *NamedSnapshot   `json:"snapshot"`
+	Scope        string           `json:"scope"`
+	Rows         map[string]int64 `json:"rows"`
+	TasksRemoved int64            `json:"tasks_removed"`
+	EventID      int64            `json:"event_id"`
+}
+
+// CreateNamedSnapshotIdempotent writes a VACUUM INTO copy of the database to
+// dir and registers it under name, once per (agentName, requestID). Names are
+// unique. On replay the registered snapshot is returned and no new file is
+// kept.
+func CreateNamedSnapshotIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, dir, name string) (*NamedSnapshotResult, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("snapshot name is required")
+	}
+	if len(name) > snapshotNameMaxLen {
+		return nil, fmt.Errorf("snapshot name is longer than %d characters", snapshotNameMaxLen)
+	}
+	if dir == "" {
+		return nil, errors.New("snapshot directory is required")
+	}
+
+	id := generatePrefixedID("snap")
+	path, err := filepath.Abs(filepath.Join(dir, id+".db"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve snapshot path: %w", err)
+	}
+	if err := VacuumInto(ctx, db, path); err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+	info, err := InspectSnapshotFile(ctx, path)
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+
+	res, err := RunIdempotent(ctx, db, agentName, requestID, "snapshot.create", func(tx *sql.Tx) (*NamedSnapshotResult, error) {
+		var existing string
+		err := tx.QueryRowContext(ctx, `SELECT id FROM snapshots WHERE name = ?`, name).Scan(&existing)
+		if err == nil {
+			return nil, fmt.Errorf("snapshot name %q is already taken by %s", name, existing)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to check snapshot name: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO snapshots (id, name, path, size_bytes, schema_version, last_event_id, created_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, id, name, path, info.SizeBytes, info.SchemaVersion, info.LastEventID, agentName); err != nil {
+			return nil, fmt.Errorf("failed to register snapshot: %w", err)
+		}
+		s, err := getNamedSnapshot(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		meta, err := json.Marshal(map[string]any{
+			"snapshot_id":   s.ID,
+			"name":          s.Name,
+			"path":          s.Path,
+			"last_event_id": s.LastEventID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event metadata: %w", err)
+		}
+		eventID, err := InsertEventTx(tx, models.EventKindSnapshotCreated, agentName, "", "Snapshot taken: "+name, string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append event: %w", err)
+		}
+		return &NamedSnapshotResult{Snapshot: s, EventID: eventID}, nil
+	})
+	if err != nil || res.Snapshot.ID != id {
+		// Failed, or replayed: the file written above is not registered.
+		_ = os.Remove(path)
+	}
+	return res, err
+}
+
+// ListNamedSnapshots returns the registered snapshots, newest first.
+func ListNamedSnapshots(ctx context.Context, db *sql.DB) (_ []*NamedSnapshot, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, name, path, size_bytes, schema_version, last_event_id, created_by, created_at
+		FROM snapshots ORDER BY created_at DESC, rowid DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []*NamedSnapshot{}
+	for rows.Next() {
+		s, err := scanNamedSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	return out, nil
+}
+
+// RestoreNamedSnapshotIdempotent rolls task and/or memory state back to the
+// snapshot idOrName (an id or a name), once per (agentName, requestID).
+//
+// The tasks scope restores tasks, their dependencies, criteria, metadata, and
+// artifacts; tasks created after the snapshot are removed. Restored tasks get
+// a version above both their snapshot and live versions, so an agent holding
+// a pre-restore version fails its next compare-and-swap instead of
+// overwriting the restore. The memory scope replaces the memory table. The
+// event log is never rewritten: the restore is recorded as one more event.
+//
+//nolint:revive // argument-limit: snapshot and scope are both required
+func RestoreNamedSnapshotIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, idOrName, scope string) (*SnapshotRestoreResult, error) {
+	switch scope {
+	case SnapshotScopeTasks, SnapshotScopeMemory, SnapshotScopeAll:
+	default:
+		return nil, fmt.Errorf("invalid restore scope %q (use tasks, memory, or all)", scope)
+	}
+
+	return RunIdempotent(ctx, db, agentName, requestID, "snapshot.restore", func(tx *sql.Tx) (*SnapshotRestoreResult, error) {
+		s, err := findNamedSnapshot(ctx, tx, idOrName)
+		if err != nil {
+			return nil, err
+		}
+		if s.Missing {
+			return nil, fmt.Errorf("snapshot file is missing: %s", s.Path)
+		}
+		info, err := InspectSnapshotFile(ctx, s.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IntegrityOK {
+			return nil, fmt.Errorf("snapshot %s fails its integrity check", s.ID)
+		}
+		if info.SchemaVersion > info.LatestVersion {
+			return nil, fmt.Errorf("snapshot schema version %d is newer than this vybe (%d)", info.SchemaVersion, info.LatestVersion)
+		}
+
+		snap, err := OpenSnapshotReadOnly(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = snap.Close() }()
+
+		res := &SnapshotRestoreResult{Snapshot: s, Scope: scope, Rows: map[string]int64{}}
+		if scope == SnapshotScopeTasks || scope == SnapshotScopeAll {
+			if err := restoreSnapshotTasksTx(ctx, tx, snap, res); err != nil {
+				return nil, err
+			}
+		}
+		if scope == SnapshotScopeMemory || scope == SnapshotScopeAll {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM memory`); err != nil {
+				return nil, fmt.Errorf("failed to clear memory: %w", err)
+			}
+			if res.Rows["memory"], err = copySnapshotTableTx(ctx, tx, snap, "memory", ""); err != nil {
+				return nil, err
+			}
+		}
+
+		meta, err := json.Marshal(map[string]any{
+			"snapshot_id":   s.ID,
+			"name":          s.Name,
+			"scope":         scope,
+			"rows":          res.Rows,
+			"tasks_removed": res.TasksRemoved,
+			"last_event_id": s.LastEventID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event metadata: %w", err)
+		}
+		res.EventID, err = InsertEventTx(tx, models.EventKindSnapshotRestored, agentName, "",
+			fmt.Sprintf("Snapshot restored: %s (%s)", s.Name, scope), string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append event: %w", err)
+		}
+		return res, nil
+	})
+}
+
+// restoreSnapshotTasksTx upserts the snapshot's tasks (keeping rows that point
+// at surviving tasks, such as messages, attached), removes tasks the snapshot
+// does not have, and replaces the task child tables.
+func restoreSnapshotTasksTx(ctx context.Context, tx *sql.Tx, snap *sql.DB, res *SnapshotRestoreResult) error {
+	for _, table := range snapshotTaskChildTables {
+		// Table names come from the fixed snapshotTaskChildTables list.
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil { //nolint:gosec // G202: fixed table names
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+
+	n, err := copySnapshotTableTx(ctx, tx, snap, "tasks",
+		"ON CONFLICT(id) DO UPDATE SET %s, version = MAX(tasks.version, excluded.version) + 1")
+	if err != nil {
+		return err
+	}
+	res.Rows["tasks"] = n
+
+	ids, err := snapshotTaskIDs(ctx, snap)
+	if err != nil {
+		return err
+	}
+	removed, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE id NOT IN (SELECT value FROM json_each(?))`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to remove tasks created after the snapshot: %w", err)
+	}
+	if res.TasksRemoved, err = removed.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to count removed tasks: %w", err)
+	}
+
+	for _, table := range snapshotTaskChildTables {
+		if res.Rows[table], err = copySnapshotTableTx(ctx, tx, snap, table, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotTaskIDs returns the snapshot's task ids as a JSON array.
+func snapshotTaskIDs(ctx context.Context, snap *sql.DB) (string, error) {
+	var ids sql.NullString
+	if err := snap.QueryRowContext(ctx, `SELECT json_group_array(id) FROM tasks`).Scan(&ids); err != nil {
+		return "", fmt.Errorf("failed to read snapshot task ids: %w", err)
+	}
+	if !ids.Valid {
+		return "[]", nil
+	}
+	return ids.String, nil
+}
+
+// copySnapshotTableTx inserts every row of table from snap into the live
+// table and returns the row count. Only columns present in both schemas are
+// copied, so an older snapshot fills newer columns with their defaults; a
+// table the snapshot lacks copies nothing. conflict, when set, is appended
+// to the INSERT with %s replaced by "col = excluded.col" for every column.
+func copySnapshotTableTx(ctx context.Context, tx *sql.Tx, snap *sql.DB, table, conflict string) (int64, error) {
+	liveCols, err := tableColumns(ctx, tx, table)
+	if err != nil {
+		return 0, err
+	}
+	snapCols, err := tableColumns(ctx, snap, table)
+	if err != nil {
+		return 0, err
+	}
+	inSnap := make(map[string]bool, len(snapCols))
+	for _, c := range snapCols {
+		inSnap[c] = true
+	}
+	var cols, quoted, selects, sets []string
+	for _, c := range liveCols {
+		if !inSnap[c] {
+			continue
+		}
+		q := `"` + c + `"`
+		cols = append(cols, c)
+		quoted = append(quoted, q)
+		// Unary + makes each result column an expression with no declared
+		// type, so the driver returns the stored value as-is instead of
+		// parsing TIMESTAMP text into time.Time and re-formatting it.
+		selects = append(selects, "+"+q)
+		sets = append(sets, q+" = excluded."+q)
+	}
+	if len(cols) == 0 {
+		return 0, nil
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(quoted, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", "))
+	if conflict != "" {
+		insert += " " + fmt.Sprintf(conflict, strings.Join(sets, ", "))
+	}
+	stmt, err := tx.PrepareContext(ctx, insert)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare %s restore: %w", table, err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	// Column and table names come from PRAGMA table_info and fixed lists.
+	rows, err := snap.QueryContext(ctx, "SELECT "+strings.Join(selects, ", ")+" FROM "+table) //nolint:gosec // G202: names from schema
+	if err != nil {
+		return 0, fmt.Errorf("failed to read snapshot %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var n int64
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return 0, fmt.Errorf("failed to read snapshot %s: %w", table, err)
+		}
+		if _, err := stmt.ExecContext(ctx, vals...); err != nil {
+			return 0, fmt.Errorf("failed to restore %s: %w", table, err)
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read snapshot %s: %w", table, err)
+	}
+	return n, nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// tableColumns lists the columns of table, or none when it does not exist.
+func tableColumns(ctx context.Context, q queryer, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+	var cols []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+		}
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+const namedSnapshotColumns = `id, name, path, size_bytes, schema_version, last_event_id, created_by, created_at`
+
+func getNamedSnapshot(ctx context.Context, tx *sql.Tx, id string) (*NamedSnapshot, error) {
+	s, err := scanNamedSnapshot(tx.QueryRowContext(ctx, `SELECT `+namedSnapshotColumns+` FROM snapshots WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("snapshot not found: %s", id)
+	}
+	return s, err
+}
+
+// findNamedSnapshot looks a snapshot up by id, then by name.
+func findNamedSnapshot(ctx context.Context, tx *sql.Tx, idOrName string) (*NamedSnapshot, error) {
+	s, err := scanNamedSnapshot(tx.QueryRowContext(ctx, `
+		SELECT `+namedSnapshotColumns+` FROM snapshots
+		WHERE id = ? OR name = ? ORDER BY id = ? DESC LIMIT 1
+	`, idOrName, idOrName, idOrName))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("snapshot not found: %s", idOrName)
+	}
+	return s, err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanNamedSnapshot(row rowScanner) (*NamedSnapshot, error) {
+	s := &NamedSnapshot{}
+	err := row.Scan(&s.ID, &s.Name, &s.Path, &s.SizeBytes, &s.SchemaVersion, &s.LastEventID, &s.CreatedBy, &s.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if _, statErr := os.Stat(s.Path); statErr != nil {
+		s.Missing = true
+	}
+	return s, nil
+}
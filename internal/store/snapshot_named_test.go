@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestNamedSnapshot_CreateListRestore(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "snapshots")
+
+	kept, err := CreateTask(ctx, db, "Kept task", "", "", 0)
+	require.NoError(t, err)
+	dep, err := CreateTask(ctx, db, "Dependency", "", "", 0)
+	require.NoError(t, err)
+	_, err = AddTaskDependencyIdempotent(ctx, db, "agent1", "dep_1", kept.ID, dep.ID)
+	require.NoError(t, err)
+	_, _, err = AddTaskCriterionIdempotent(ctx, db, "agent1", "crit_1", kept.ID, "tests pass")
+	require.NoError(t, err)
+	require.NoError(t, SetMemory(ctx, db, "deploy_target", "staging", "string", "global", "", nil, false, "fact", nil))
+
+	created, err := CreateNamedSnapshotIdempotent(ctx, db, "agent1", "snap_1", dir, " before-run ")
+	require.NoError(t, err)
+	assert.Equal(t, "before-run", created.Snapshot.Name)
+	assert.FileExists(t, created.Snapshot.Path)
+
+	replay, err := CreateNamedSnapshotIdempotent(ctx, db, "agent1", "snap_1", dir, "before-run")
+	require.NoError(t, err)
+	assert.Equal(t, created.Snapshot.ID, replay.Snapshot.ID)
+	_, err = CreateNamedSnapshotIdempotent(ctx, db, "agent1", "snap_2", dir, "before-run")
+	require.Error(t, err, "names are unique")
+	files, err := filepath.Glob(filepath.Join(dir, "*.db"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "replayed and failed creates leave no file behind")
+
+	// A rogue run: complete a task, add one, drop the dependency, rewrite memory.
+	require.NoError(t, UpdateTaskStatus(ctx, db, kept.ID, string(models.TaskStatusCompleted), kept.Version))
+	rogue, err := CreateTask(ctx, db, "Rogue task", "", "", 0)
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM task_dependencies`)
+	require.NoError(t, err)
+	require.NoError(t, SetMemory(ctx, db, "deploy_target", "production", "string", "global", "", nil, false, "fact", nil))
+	var eventsBefore int64
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM events`).Scan(&eventsBefore))
+
+	res, err := RestoreNamedSnapshotIdempotent(ctx, db, "agent1", "restore_1", "before-run", SnapshotScopeTasks)
+	require.NoError(t, err)
+	assert.Equal(t, created.Snapshot.ID, res.Snapshot.ID)
+	assert.Equal(t, int64(2), res.Rows["tasks"])
+	assert.Equal(t, int64(1), res.Rows["task_dependencies"])
+	assert.Equal(t, int64(1), res.Rows["task_criteria"])
+	assert.Equal(t, int64(1), res.TasksRemoved)
+
+	got, err := GetTask(ctx, db, kept.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusPending, got.Status)
+	assert.Greater(t, got.Version, kept.Version+1, "restored tasks move past their live version")
+	_, err = GetTask(ctx, db, rogue.ID)
+	require.Error(t, err)
+	var createdAt string
+	require.NoError(t, db.QueryRow(`SELECT created_at || '' FROM tasks WHERE id = ?`, kept.ID).Scan(&createdAt))
+	assert.NotContains(t, createdAt, "UTC", "timestamps keep their stored text form")
+
+	m, err := GetMemory(ctx, db, "deploy_target", "global", "")
+	require.NoError(t, err)
+	assert.Equal(t, "production", m.Value, "the tasks scope leaves memory alone")
+
+	_, err = RestoreNamedSnapshotIdempotent(ctx, db, "agent1", "restore_2", created.Snapshot.ID, SnapshotScopeMemory)
+	require.NoError(t, err)
+	m, err = GetMemory(ctx, db, "deploy_target", "global", "")
+	require.NoError(t, err)
+	assert.Equal(t, "staging", m.Value)
+
+	// The event log only grows: both restores are recorded, nothing is removed.
+	var eventsAfter, restored int64
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM events`).Scan(&eventsAfter))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM events WHERE kind = ?`, models.EventKindSnapshotRestored).Scan(&restored))
+	assert.Equal(t, eventsBefore+2, eventsAfter)
+	assert.Equal(t, int64(2), restored)
+
+	list, err := ListNamedSnapshots(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.False(t, list[0].Missing)
+
+	_, err = RestoreNamedSnapshotIdempotent(ctx, db, "agent1", "restore_3", "before-run", "events")
+	require.Error(t, err)
+	_, err = RestoreNamedSnapshotIdempotent(ctx, db, "agent1", "restore_4", "nope", SnapshotScopeAll)
+	require.Error(t, err)
+}
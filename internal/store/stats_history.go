@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ProjectStatsSnapshot is one day's recorded counters for a project.
+type ProjectStatsSnapshot struct {
+	ProjectID       string    `json:"project_id"`
+	Day             string    `json:"day"` // YYYY-MM-DD, UTC
+	TasksPending    int       `json:"tasks_pending"`
+	TasksInProgress int       `json:"tasks_in_progress"`
+	TasksCompleted  int       `json:"tasks_completed"`
+	TasksBlocked    int       `json:"tasks_blocked"`
+	EventsCount     int       `json:"events_count"`
+	MemoryCount     int       `json:"memory_count"`
+	DBSizeBytes     int64     `json:"db_size_bytes"`
+	RecordedAt      time.Time `json:"recorded_at"`
+}
+
+// Backlog is the number of tasks not yet completed.
+func (s ProjectStatsSnapshot) Backlog() int {
+	return s.TasksPending + s.TasksInProgress + s.TasksBlocked
+}
+
+// RecordProjectStatsSnapshot upserts today's snapshot for projectID. Repeated
+// calls on the same UTC day overwrite that day's row, so the stored value is
+// the last one observed that day.
+func RecordProjectStatsSnapshot(ctx context.Context, db *sql.DB, projectID string) (*ProjectStatsSnapshot, error) {
+	if projectID == "" {
+		return nil, errors.New("project ID is required")
+	}
+
+	var snap *ProjectStatsSnapshot
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
+		s := ProjectStatsSnapshot{ProjectID: projectID}
+		err := tx.QueryRowContext(ctx, `
+			SELECT
+				COALESCE(SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END), 0),
+				COALESCE(SUM(CASE WHEN status = 'in_progress' THEN 1 ELSE 0 END), 0),
+				COALESCE(SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END), 0),
+				COALESCE(SUM(CASE WHEN status = 'blocked' THEN 1 ELSE 0 END), 0)
+			FROM tasks WHERE project_id = ?
+		`, projectID).Scan(&s.TasksPending, &s.TasksInProgress, &s.TasksCompleted, &s.TasksBlocked)
+		if err != nil {
+			return fmt.Errorf("failed to count project tasks: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM events WHERE project_id = ?`, projectID).Scan(&s.EventsCount); err != nil {
+			return fmt.Errorf("failed to count project events: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM memory WHERE scope = 'project' AND scope_id = ?`, projectID).Scan(&s.MemoryCount); err != nil {
+			return fmt.Errorf("failed to count project memory: %w", err)
+		}
+		// Database size is shared by every project; it is recorded per snapshot
+		// so a single trends query shows storage growth alongside the backlog.
+		if err := tx.QueryRowContext(ctx,
+			`SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`).Scan(&s.DBSizeBytes); err != nil {
+			return fmt.Errorf("failed to read database size: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stats_history (project_id, day, tasks_pending, tasks_in_progress, tasks_completed,
+				tasks_blocked, events_count, memory_count, db_size_bytes, recorded_at)
+			VALUES (?, date('now'), ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(project_id, day) DO UPDATE SET
+				tasks_pending = excluded.tasks_pending,
+				tasks_in_progress = excluded.tasks_in_progress,
+				tasks_completed = excluded.tasks_completed,
+				tasks_blocked = excluded.tasks_blocked,
+				events_count = excluded.events_count,
+				memory_count = excluded.memory_count,
+				db_size_bytes = excluded.db_size_bytes,
+				recorded_at = CURRENT_TIMESTAMP
+		`, projectID, s.TasksPending, s.TasksInProgress, s.TasksCompleted, s.TasksBlocked,
+			s.EventsCount, s.MemoryCount, s.DBSizeBytes)
+		if err != nil {
+			return fmt.Errorf("failed to record stats snapshot: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx,
+			`SELECT day, recorded_at FROM stats_history WHERE project_id = ? AND day = date('now')`,
+			projectID).Scan(&s.Day, &s.RecordedAt); err != nil {
+			return fmt.Errorf("failed to load stats snapshot: %w", err)
+		}
+		snap = &s
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// ProjectTrendDelta is the change between the first and last snapshot in a window.
+type ProjectTrendDelta struct {
+	Backlog        int   `json:"backlog"`
+	TasksCompleted int   `json:"tasks_completed"`
+	EventsCount    int   `json:"events_count"`
+	MemoryCount    int   `json:"memory_count"`
+	DBSizeBytes    int64 `json:"db_size_bytes"`
+}
+
+// ProjectTrends is the snapshot history for a project over a window of days.
+type ProjectTrends struct {
+	ProjectID string                 `json:"project_id"`
+	Days      int                    `json:"days"`
+	Snapshots []ProjectStatsSnapshot `json:"snapshots"`
+	Delta     *ProjectTrendDelta     `json:"delta,omitempty"`
+}
+
+// ListProjectTrends returns snapshots recorded in the last days days, oldest first,
+// with the first-to-last delta when at least two snapshots exist.
+func ListProjectTrends(ctx context.Context, db *sql.DB, projectID string, days int) (*ProjectTrends, error) {
+	if projectID == "" {
+		return nil, errors.New("project ID is required")
+	}
+	if days <= 0 {
+		days = 30
+	}
+
+	out := &ProjectTrends{ProjectID: projectID, Days: days}
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT project_id, day, tasks_pending, tasks_in_progress, tasks_completed, tasks_blocked,
+				events_count, memory_count, db_size_bytes, recorded_at
+			FROM stats_history
+			WHERE project_id = ? AND day >= date('now', ?)
+			ORDER BY day ASC
+		`, projectID, fmt.Sprintf("-%d days", days))
+		if err != nil {
+			return fmt.Errorf("failed to query stats history: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out.Snapshots = make([]ProjectStatsSnapshot, 0)
+		for rows.Next() {
+			var s ProjectStatsSnapshot
+			if err := rows.Scan(&s.ProjectID, &s.Day, &s.TasksPending, &s.TasksInProgress, &s.TasksCompleted,
+				&s.TasksBlocked, &s.EventsCount, &s.MemoryCount, &s.DBSizeBytes, &s.RecordedAt); err != nil {
+				return fmt.Errorf("failed to scan stats snapshot: %w", err)
+			}
+			out.Snapshots = append(out.Snapshots, s)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if n := len(out.Snapshots); n >= 2 {
+		first, last := out.Snapshots[0], out.Snapshots[n-1]
+		out.Delta = &ProjectTrendDelta{
+			Backlog:        last.Backlog() - first.Backlog(),
+			TasksCompleted: last.TasksCompleted - first.TasksCompleted,
+			EventsCount:    last.EventsCount - first.EventsCount,
+			MemoryCount:    last.MemoryCount - first.MemoryCount,
+			DBSizeBytes:    last.DBSizeBytes - first.DBSizeBytes,
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectStatsSnapshot_UpsertsDailyAndReportsDelta(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	proj, err := CreateProject(context.Background(), db, "trends", "")
+	require.NoError(t, err)
+	_, err = CreateTask(context.Background(), db, "one", "", proj.ID, 0)
+	require.NoError(t, err)
+
+	snap, err := RecordProjectStatsSnapshot(context.Background(), db, proj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, snap.TasksPending)
+	assert.Positive(t, snap.DBSizeBytes)
+
+	// A second snapshot on the same day replaces the first.
+	_, err = CreateTask(context.Background(), db, "two", "", proj.ID, 0)
+	require.NoError(t, err)
+	snap, err = RecordProjectStatsSnapshot(context.Background(), db, proj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, snap.TasksPending)
+
+	trends, err := ListProjectTrends(context.Background(), db, proj.ID, 7)
+	require.NoError(t, err)
+	require.Len(t, trends.Snapshots, 1)
+	assert.Nil(t, trends.Delta)
+
+	// Backdate a snapshot to simulate history from earlier in the window.
+	require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(context.Background(), `
+			INSERT INTO stats_history (project_id, day, tasks_pending, events_count)
+			VALUES (?, date('now', '-3 days'), 5, 1)
+		`, proj.ID)
+		return err
+	}))
+
+	trends, err = ListProjectTrends(context.Background(), db, proj.ID, 7)
+	require.NoError(t, err)
+	require.Len(t, trends.Snapshots, 2)
+	require.NotNil(t, trends.Delta)
+	assert.Equal(t, -3, trends.Delta.Backlog)
+
+	trends, err = ListProjectTrends(context.Background(), db, proj.ID, 1)
+	require.NoError(t, err)
+	assert.Len(t, trends.Snapshots, 1)
+}
@@ -51,15 +51,15 @@ type TasksDetail struct {
 }
 
 // GetStatusCounts retrieves all status counts in a single atomic query with retry.
-func GetStatusCounts(db *sql.DB) (*StatusCounts, error) {
+func GetStatusCounts(ctx context.Context, db *sql.DB) (*StatusCounts, error) {
 	counts := &StatusCounts{}
 	var evActive, evArchived int
 	var memActive, memExpired int
 	var agActive7d int
 	var taskTotal, taskUnknown int
 
-	err := RetryWithBackoff(context.Background(), func() error {
-		return db.QueryRowContext(context.Background(), `
+	err := RetryWithBackoff(ctx, func() error {
+		return db.QueryRowContext(ctx, `
 			SELECT
 				COALESCE((SELECT SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END) FROM tasks), 0),
 				COALESCE((SELECT SUM(CASE WHEN status = 'in_progress' THEN 1 ELSE 0 END) FROM tasks), 0),
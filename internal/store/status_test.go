@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,7 +13,7 @@ func TestGetStatusCounts_SingleAtomicQuery(t *testing.T) {
 	defer cleanup()
 
 	// Empty DB should return all zeros
-	counts, err := GetStatusCounts(db)
+	counts, err := GetStatusCounts(context.Background(), db)
 	require.NoError(t, err)
 	assert.Equal(t, 0, counts.Tasks.Pending)
 	assert.Equal(t, 0, counts.Tasks.InProgress)
@@ -24,35 +25,35 @@ func TestGetStatusCounts_SingleAtomicQuery(t *testing.T) {
 	assert.Equal(t, 0, counts.Projects)
 
 	// Create some data
-	task1, err := CreateTask(db, "Task 1", "desc", "", 0)
+	task1, err := CreateTask(context.Background(), db, "Task 1", "desc", "", 0)
 	require.NoError(t, err)
 
-	task2, err := CreateTask(db, "Task 2", "desc", "", 0)
+	task2, err := CreateTask(context.Background(), db, "Task 2", "desc", "", 0)
 	require.NoError(t, err)
 
-	err = UpdateTaskStatus(db, task1.ID, "completed", task1.Version)
+	err = UpdateTaskStatus(context.Background(), db, task1.ID, "completed", task1.Version)
 	require.NoError(t, err)
 
-	err = UpdateTaskStatus(db, task2.ID, "in_progress", task2.Version)
+	err = UpdateTaskStatus(context.Background(), db, task2.ID, "in_progress", task2.Version)
 	require.NoError(t, err)
 
 	// Create an agent
-	_, err = LoadOrCreateAgentState(db, "test-agent")
+	_, err = LoadOrCreateAgentState(context.Background(), db, "test-agent")
 	require.NoError(t, err)
 
 	// Create a project
-	_, err = CreateProject(db, "Test Project", "")
+	_, err = CreateProject(context.Background(), db, "Test Project", "")
 	require.NoError(t, err)
 
 	// Create memory
-	err = SetMemory(db, "key1", "val1", "string", "global", "", nil, false, "", nil)
+	err = SetMemory(context.Background(), db, "key1", "val1", "string", "global", "", nil, false, "", nil)
 	require.NoError(t, err)
 
 	// Log an event
 	appendEvent(t, db, "test_event", "test-agent", "", "test message")
 
 	// Verify all counts are correct in a single read
-	counts, err = GetStatusCounts(db)
+	counts, err = GetStatusCounts(context.Background(), db)
 	require.NoError(t, err)
 	assert.Equal(t, 0, counts.Tasks.Pending, "pending tasks")
 	assert.Equal(t, 1, counts.Tasks.InProgress, "in_progress tasks")
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/app"
+)
+
+// ClaimOptions tunes ClaimNextTaskIdempotent.
+type ClaimOptions struct {
+	// Lease, when positive, overrides the task's, the agent's, and the
+	// configured claim lease.
+	Lease time.Duration
+	// StealStale takes over an in_progress task whose lease expired when no
+	// pending task passes the filter.
+	StealStale bool
+}
+
+// ClaimResult is the outcome of ClaimNextTaskIdempotent. TaskID is empty when
+// no pending task passed the filter. StolenFrom names the prior owner when the
+// task was taken over under ClaimOptions.StealStale.
+type ClaimResult struct {
+	TaskID        string     `json:"task_id"`
+	StatusEventID int64      `json:"status_event_id,omitempty"`
+	FocusEventID  int64      `json:"focus_event_id,omitempty"`
+	Lease         *TaskLease `json:"lease,omitempty"`
+	StolenFrom    string     `json:"stolen_from,omitempty"`
+	StolenEventID int64      `json:"stolen_event_id,omitempty"`
+}
+
+// ClaimNextTaskIdempotent picks the first pending task passing filter, in
+// resume's rule 4 order (deadline-first under that policy, otherwise
+// priority-first), then sets it in_progress and focuses it, once per
+// (agent_name, request_id). A non-empty projectID restricts the pick to that
+// project. Selection and start share one transaction, so two claimers never
+// get the same task. Each claim counts toward the tasks_per_day limit.
+//
+// The claim is leased for opts.Lease when positive, else for the task's or the
+// agent's default lease, else for claim_lease; memory gc returns the task to
+// pending once the lease runs out (see ReclaimExpiredLeasesTx). With
+// opts.StealStale and nothing pending, the in_progress task passing the filter
+// whose lease expired longest ago is taken over instead (see stealTaskTx).
+func ClaimNextTaskIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, policy app.FocusPolicy, filter FocusFilter, opts ClaimOptions) (*ClaimResult, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+
+	orderBy := priorityFirstOrder
+	if policy == app.FocusDeadlineFirst {
+		orderBy = deadlineFirstOrder
+	}
+	query, args := pendingTaskQuery(projectID, projectID != "", orderBy, filter)
+
+	var picked string
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "task.claim", func(tx *sql.Tx) (ClaimResult, error) {
+		var result ClaimResult
+		var taskID string
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&taskID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if !opts.StealStale {
+				return ClaimResult{}, nil
+			}
+			expired, err := expiredLeasesTx(tx, time.Now(), 1, projectID, projectID != "", filter)
+			if err != nil {
+				return ClaimResult{}, err
+			}
+			if len(expired) == 0 {
+				return ClaimResult{}, nil
+			}
+			taskID = expired[0].TaskID
+			picked = taskID
+			result = ClaimResult{TaskID: taskID, StolenFrom: expired[0].Owner}
+			if result.FocusEventID, result.StolenEventID, err = stealTaskTx(tx, agentName, expired[0]); err != nil {
+				return ClaimResult{}, err
+			}
+		case err != nil:
+			return ClaimResult{}, fmt.Errorf("failed to select task: %w", err)
+		default:
+			picked = taskID
+			statusEventID, focusEventID, err := startTaskAndFocusTx(tx, agentName, taskID)
+			if err != nil {
+				return ClaimResult{}, err
+			}
+			result = ClaimResult{TaskID: taskID, StatusEventID: statusEventID, FocusEventID: focusEventID}
+		}
+
+		if err := IncrementLimitUsageTx(tx, LimitTasksPerDay, time.Now()); err != nil {
+			return ClaimResult{}, err
+		}
+		ttl, err := resolveClaimLeaseTx(tx, agentName, taskID, opts.Lease)
+		if err != nil {
+			return ClaimResult{}, err
+		}
+		if ttl > 0 {
+			if result.Lease, err = setTaskLeaseTx(tx, agentName, taskID, ttl); err != nil {
+				return ClaimResult{}, err
+			}
+		}
+		return result, nil
+	})
+	if err != nil {
+		if IsVersionConflict(err) && picked != "" {
+			RecordClaimConflict(ctx, db, agentName, picked)
+		}
+		return nil, err
+	}
+	return &r, nil
+}
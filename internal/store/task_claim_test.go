@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestDetermineFocusTaskFiltered_TagsAndPriorityFloor(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	urgent, err := CreateTask(context.Background(), db, "Urgent feature", "", "", 9)
+	require.NoError(t, err)
+	bug, err := CreateTask(context.Background(), db, "Minor bug", "", "", 1)
+	require.NoError(t, err)
+	bigBug, err := CreateTask(context.Background(), db, "Major bug", "", "", 5)
+	require.NoError(t, err)
+	for _, id := range []string{bug.ID, bigBug.ID} {
+		_, err = db.Exec(`INSERT INTO task_tags (task_id, tag) VALUES (?, 'bugfix')`, id)
+		require.NoError(t, err)
+	}
+
+	res, err := DetermineFocusTaskFiltered(context.Background(), db, "agent1", "", nil, "", app.FocusPriorityFirst, FocusFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, urgent.ID, res.TaskID, "no filter picks the top task")
+
+	res, err = DetermineFocusTaskFiltered(context.Background(), db, "agent1", "", nil, "", app.FocusPriorityFirst, FocusFilter{Tags: []string{" BugFix "}})
+	require.NoError(t, err)
+	assert.Equal(t, bigBug.ID, res.TaskID, "tags match case-insensitively")
+
+	floor := 6
+	res, err = DetermineFocusTaskFiltered(context.Background(), db, "agent1", "", nil, "", app.FocusPriorityFirst, FocusFilter{Tags: []string{"bugfix"}, MinPriority: &floor})
+	require.NoError(t, err)
+	assert.Empty(t, res.TaskID)
+	assert.Contains(t, res.Rule, "match the filter")
+
+	// A current focus outside the filter is set aside, not kept.
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, urgent.ID, string(models.TaskStatusInProgress), urgent.Version))
+	res, err = DetermineFocusTaskFiltered(context.Background(), db, "agent1", urgent.ID, nil, "", app.FocusPriorityFirst, FocusFilter{Tags: []string{"bugfix"}})
+	require.NoError(t, err)
+	assert.Equal(t, bigBug.ID, res.TaskID)
+	assert.True(t, strings.HasPrefix(res.Rule, "rule4"), res.Rule)
+}
+
+func TestDetermineFocusTaskFiltered_FloorUsesEffectivePriority(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	blocked, err := CreateTask(context.Background(), db, "Release", "", "", 8)
+	require.NoError(t, err)
+	blocker, err := CreateTask(context.Background(), db, "Fix build", "", "", 0)
+	require.NoError(t, err)
+	_, err = AddTaskDependencyIdempotent(context.Background(), db, "agent1", "dep_1", blocked.ID, blocker.ID)
+	require.NoError(t, err)
+
+	floor := 5
+	res, err := DetermineFocusTaskFiltered(context.Background(), db, "agent1", "", nil, "", app.FocusPriorityFirst, FocusFilter{MinPriority: &floor})
+	require.NoError(t, err)
+	assert.Equal(t, blocker.ID, res.TaskID, "a blocker inherits the priority it holds up")
+}
+
+func TestClaimNextTaskIdempotent(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := CreateTask(context.Background(), db, "Other project", "", "proj-b", 9)
+	require.NoError(t, err)
+	low, err := CreateTask(context.Background(), db, "Low", "", "proj-a", 1)
+	require.NoError(t, err)
+	high, err := CreateTask(context.Background(), db, "High", "", "proj-a", 4)
+	require.NoError(t, err)
+
+	first, err := ClaimNextTaskIdempotent(context.Background(), db, "agent1", "claim_1", "proj-a", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, high.ID, first.TaskID)
+	assert.NotZero(t, first.StatusEventID)
+	assert.NotZero(t, first.FocusEventID)
+
+	got, err := GetTask(context.Background(), db, high.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusInProgress, got.Status)
+	state, err := LoadOrCreateAgentState(context.Background(), db, "agent1")
+	require.NoError(t, err)
+	assert.Equal(t, high.ID, state.FocusTaskID)
+
+	replay, err := ClaimNextTaskIdempotent(context.Background(), db, "agent1", "claim_1", "proj-a", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, *first, *replay)
+
+	second, err := ClaimNextTaskIdempotent(context.Background(), db, "agent2", "claim_2", "proj-a", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, low.ID, second.TaskID, "a claimed task is not handed out twice")
+
+	none, err := ClaimNextTaskIdempotent(context.Background(), db, "agent3", "claim_3", "proj-a", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, none.TaskID)
+}
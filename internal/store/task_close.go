@@ -26,12 +26,15 @@ type CloseTaskParams struct {
 	TaskID        string
 	Status        string // "completed" or "blocked"
 	Summary       string
-	Label         string // optional, stored in event metadata only
-	BlockedReason string // optional, only used when Status is "blocked"
+	Label         string             // optional, stored in event metadata only
+	BlockedReason string             // optional, only used when Status is "blocked"
+	Outcome       models.TaskOutcome // optional, only used when Status is "completed"; empty means done
+	SupersededBy  string             // optional, the replacing task for superseded or duplicate outcomes
 }
 
 // CloseTaskTx atomically closes a task: CAS status update,
-// set blocked_reason (if blocked), emit task_status + task_closed events.
+// set blocked_reason (if blocked) or the outcome (if completed), emit
+// task_status + task_closed events.
 //
 // Status must be "completed" or "blocked".
 func CloseTaskTx(tx *sql.Tx, p CloseTaskParams) (*CloseTaskResult, error) {
@@ -47,6 +50,9 @@ func CloseTaskTx(tx *sql.Tx, p CloseTaskParams) (*CloseTaskResult, error) {
 	if p.Summary == "" {
 		return nil, errors.New("summary is required")
 	}
+	if p.Status == taskStatusBlocked && (p.Outcome != "" || p.SupersededBy != "") {
+		return nil, errors.New("outcome and superseded_by apply only to completed tasks")
+	}
 
 	// CAS status update.
 	version, err := GetTaskVersionTx(tx, p.TaskID)
@@ -66,11 +72,26 @@ func CloseTaskTx(tx *sql.Tx, p CloseTaskParams) (*CloseTaskResult, error) {
 		}
 	}
 
+	if p.Status == taskStatusCompleted {
+		if p.Outcome == "" {
+			p.Outcome = models.TaskOutcomeDone
+		}
+		if err := SetTaskOutcomeTx(tx, p.TaskID, p.Outcome, p.SupersededBy); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build close event metadata.
 	metaMap := map[string]any{
 		"outcome": p.Status,
 		"summary": p.Summary,
 	}
+	if p.Status == taskStatusCompleted {
+		metaMap["task_outcome"] = p.Outcome
+	}
+	if p.SupersededBy != "" {
+		metaMap["superseded_by"] = p.SupersededBy
+	}
 	if p.Label != "" {
 		metaMap["label"] = p.Label
 	}
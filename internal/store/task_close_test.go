@@ -16,11 +16,11 @@ func TestCloseTaskTx_Done(t *testing.T) {
 	defer cleanup()
 	var err error
 
-	task, err := CreateTask(db, "closable", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "closable", "", "", 0)
 	require.NoError(t, err)
 
 	// Claim and start the task first.
-	_, _, err = StartTaskAndFocus(db, "agent1", task.ID)
+	_, _, err = StartTaskAndFocus(context.Background(), db, "agent1", task.ID)
 	require.NoError(t, err)
 
 	var result *CloseTaskResult
@@ -40,7 +40,7 @@ func TestCloseTaskTx_Done(t *testing.T) {
 	assert.NotZero(t, result.CloseEventID)
 
 	// Verify task is completed.
-	updated, err := GetTask(db, task.ID)
+	updated, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, models.TaskStatusCompleted, updated.Status)
 }
@@ -50,10 +50,10 @@ func TestCloseTaskTx_Blocked(t *testing.T) {
 	defer cleanup()
 	var err error
 
-	task, err := CreateTask(db, "will-block", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "will-block", "", "", 0)
 	require.NoError(t, err)
 
-	_, _, err = StartTaskAndFocus(db, "agent1", task.ID)
+	_, _, err = StartTaskAndFocus(context.Background(), db, "agent1", task.ID)
 	require.NoError(t, err)
 
 	var result *CloseTaskResult
@@ -73,7 +73,7 @@ func TestCloseTaskTx_Blocked(t *testing.T) {
 	assert.NotZero(t, result.StatusEventID)
 	assert.NotZero(t, result.CloseEventID)
 
-	updated, err := GetTask(db, task.ID)
+	updated, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, models.TaskStatusBlocked, updated.Status)
 	assert.Equal(t, newBlockedReasonFailure("api_timeout"), updated.BlockedReason)
@@ -84,11 +84,11 @@ func TestCloseTaskTx_BlockedClearsStaleReason(t *testing.T) {
 	defer cleanup()
 	var err error
 
-	task, err := CreateTask(db, "stale-reason", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "stale-reason", "", "", 0)
 	require.NoError(t, err)
 
 	// First: start, close as blocked with a reason.
-	_, _, err = StartTaskAndFocus(db, "agent1", task.ID)
+	_, _, err = StartTaskAndFocus(context.Background(), db, "agent1", task.ID)
 	require.NoError(t, err)
 
 	require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
@@ -100,12 +100,12 @@ func TestCloseTaskTx_BlockedClearsStaleReason(t *testing.T) {
 		return txErr
 	}))
 
-	updated, err := GetTask(db, task.ID)
+	updated, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, newBlockedReasonFailure("old_reason"), updated.BlockedReason)
 
 	// Re-open the task so we can close it again.
-	err = UpdateTaskStatus(db, task.ID, "in_progress", updated.Version)
+	err = UpdateTaskStatus(context.Background(), db, task.ID, "in_progress", updated.Version)
 	require.NoError(t, err)
 
 	// Second: close as blocked WITHOUT a reason — stale value must be cleared.
@@ -117,7 +117,7 @@ func TestCloseTaskTx_BlockedClearsStaleReason(t *testing.T) {
 		return txErr
 	}))
 
-	updated, err = GetTask(db, task.ID)
+	updated, err = GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, models.TaskStatusBlocked, updated.Status)
 	assert.Empty(t, updated.BlockedReason, "stale blocked_reason should be cleared")
@@ -128,10 +128,10 @@ func TestCloseTaskTx_EventMetadataShape(t *testing.T) {
 	defer cleanup()
 	var err error
 
-	task, err := CreateTask(db, "meta-test", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "meta-test", "", "", 0)
 	require.NoError(t, err)
 
-	_, _, err = StartTaskAndFocus(db, "agent1", task.ID)
+	_, _, err = StartTaskAndFocus(context.Background(), db, "agent1", task.ID)
 	require.NoError(t, err)
 
 	var result *CloseTaskResult
@@ -167,7 +167,7 @@ func TestCloseTaskTx_InvalidStatus(t *testing.T) {
 	defer cleanup()
 	var err error
 
-	task, err := CreateTask(db, "invalid", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "invalid", "", "", 0)
 	require.NoError(t, err)
 
 	err = Transact(context.Background(), db, func(tx *sql.Tx) error {
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Contention kinds recorded in task_contention event metadata.
+const (
+	ContentionOverlap     = "overlap"      // another agent already had the task as its focus
+	ContentionCASConflict = "cas_conflict" // the claim lost an optimistic-concurrency race
+)
+
+const (
+	contentionBaseBackoffMs = 250
+	contentionMaxBackoffMs  = 8000
+)
+
+type contentionMeta struct {
+	Kind    string   `json:"kind"`
+	Holders []string `json:"holders,omitempty"`
+}
+
+// recordFocusOverlapTx emits a task_contention event when other agents already focus taskID.
+// Called inside the claim transaction so the overlap is recorded exactly once per claim.
+func recordFocusOverlapTx(tx *sql.Tx, agentName, taskID string) error {
+	rows, err := tx.QueryContext(context.Background(), `
+		SELECT agent_name FROM agent_state
+		WHERE focus_task_id = ? AND agent_name != ?
+		ORDER BY agent_name
+	`, taskID, agentName)
+	if err != nil {
+		return fmt.Errorf("failed to query focus holders: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var holders []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("failed to scan focus holder: %w", err)
+		}
+		holders = append(holders, name)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(holders) == 0 {
+		return nil
+	}
+
+	meta, _ := json.Marshal(contentionMeta{Kind: ContentionOverlap, Holders: holders})
+	_, err = InsertEventTx(tx, models.EventKindTaskContention, agentName, taskID,
+		fmt.Sprintf("Claim overlaps focus of %s", strings.Join(holders, ", ")), string(meta))
+	return err
+}
+
+// RecordClaimConflict records a lost CAS race on a task claim. Best effort: the
+// claim already failed, so errors here are swallowed.
+func RecordClaimConflict(ctx context.Context, db *sql.DB, agentName, taskID string) {
+	meta, _ := json.Marshal(contentionMeta{Kind: ContentionCASConflict})
+	_ = Transact(ctx, db, func(tx *sql.Tx) error {
+		_, err := InsertEventTx(tx, models.EventKindTaskContention, agentName, taskID, "Claim lost version race", string(meta))
+		return err
+	})
+}
+
+// AgentContention summarizes one agent's claim activity within the report window.
+type AgentContention struct {
+	AgentName          string  `json:"agent_name"`
+	Claims             int     `json:"claims"`
+	Overlaps           int     `json:"overlaps"`
+	CASConflicts       int     `json:"cas_conflicts"`
+	Stolen             int     `json:"stolen"`
+	LeaseExpired       int     `json:"lease_expired"`
+	AvgWaitSec         float64 `json:"avg_wait_sec"`
+	SuggestedBackoffMs int     `json:"suggested_backoff_ms"`
+}
+
+// ContentionReport aggregates task claim contention across agents.
+type ContentionReport struct {
+	SinceDays    int               `json:"since_days"`
+	Claims       int               `json:"claims"`
+	Overlaps     int               `json:"overlaps"`
+	CASConflicts int               `json:"cas_conflicts"`
+	Stolen       int               `json:"stolen"`
+	LeaseExpired int               `json:"lease_expired"`
+	Agents       []AgentContention `json:"agents"`
+	Guidance     []string          `json:"guidance"`
+}
+
+// TaskContentionReport builds a contention report from agent_focus,
+// task_contention, task_stolen, and task_lease_expired events created within
+// the last sinceDays days. Wait is measured from task creation to the agent's
+// claim. Stolen and expired leases count against the agent that lost them.
+func TaskContentionReport(ctx context.Context, db *sql.DB, sinceDays int) (*ContentionReport, error) {
+	if sinceDays <= 0 {
+		sinceDays = 7
+	}
+	window := fmt.Sprintf("-%d days", sinceDays)
+	byAgent := map[string]*AgentContention{}
+	get := func(name string) *AgentContention {
+		a, ok := byAgent[name]
+		if !ok {
+			a = &AgentContention{AgentName: name}
+			byAgent[name] = a
+		}
+		return a
+	}
+
+	err := RetryWithBackoff(ctx, func() error {
+		clear(byAgent)
+
+		rows, err := db.QueryContext(ctx, `
+			SELECT e.agent_name, COUNT(*),
+				COALESCE(AVG(MAX(0, (julianday(e.created_at) - julianday(t.created_at)) * 86400.0)), 0)
+			FROM events e
+			JOIN tasks t ON t.id = e.task_id
+			WHERE e.kind = ? AND e.created_at >= datetime('now', ?)
+			GROUP BY e.agent_name
+		`, models.EventKindAgentFocus, window)
+		if err != nil {
+			return fmt.Errorf("failed to query claims: %w", err)
+		}
+		for rows.Next() {
+			var name string
+			var claims int
+			var wait float64
+			if err := rows.Scan(&name, &claims, &wait); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("failed to scan claims: %w", err)
+			}
+			a := get(name)
+			a.Claims, a.AvgWaitSec = claims, math.Round(wait*10)/10
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		rows, err = db.QueryContext(ctx, `
+			SELECT agent_name, COALESCE(json_extract(metadata, '$.kind'), ''), COUNT(*)
+			FROM events
+			WHERE kind = ? AND created_at >= datetime('now', ?)
+			GROUP BY agent_name, 2
+		`, models.EventKindTaskContention, window)
+		if err != nil {
+			return fmt.Errorf("failed to query contention: %w", err)
+		}
+		for rows.Next() {
+			var name, kind string
+			var n int
+			if err := rows.Scan(&name, &kind, &n); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("failed to scan contention: %w", err)
+			}
+			a := get(name)
+			switch kind {
+			case ContentionOverlap:
+				a.Overlaps += n
+			case ContentionCASConflict:
+				a.CASConflicts += n
+			}
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		rows, err = db.QueryContext(ctx, `
+			SELECT kind, COALESCE(json_extract(metadata, '$.prior_owner'), json_extract(metadata, '$.owner'), ''), COUNT(*)
+			FROM events
+			WHERE kind IN (?, ?) AND created_at >= datetime('now', ?)
+			GROUP BY kind, 2
+		`, models.EventKindTaskStolen, models.EventKindTaskLeaseExpired, window)
+		if err != nil {
+			return fmt.Errorf("failed to query lease losses: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var kind, owner string
+			var n int
+			if err := rows.Scan(&kind, &owner, &n); err != nil {
+				return fmt.Errorf("failed to scan lease losses: %w", err)
+			}
+			if owner == "" {
+				continue
+			}
+			a := get(owner)
+			if kind == models.EventKindTaskStolen {
+				a.Stolen += n
+			} else {
+				a.LeaseExpired += n
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ContentionReport{SinceDays: sinceDays, Agents: make([]AgentContention, 0, len(byAgent)), Guidance: []string{}}
+	for _, a := range byAgent {
+		a.SuggestedBackoffMs = suggestedBackoffMs(a.Claims, a.Overlaps+a.CASConflicts)
+		report.Claims += a.Claims
+		report.Overlaps += a.Overlaps
+		report.CASConflicts += a.CASConflicts
+		report.Stolen += a.Stolen
+		report.LeaseExpired += a.LeaseExpired
+		report.Agents = append(report.Agents, *a)
+	}
+	sort.Slice(report.Agents, func(i, j int) bool { return report.Agents[i].AgentName < report.Agents[j].AgentName })
+	report.Guidance = contentionGuidance(report)
+	return report, nil
+}
+
+// suggestedBackoffMs doubles the base retry delay for every 10% of claims that
+// were contended, capped at contentionMaxBackoffMs. Zero means no backoff needed.
+func suggestedBackoffMs(claims, contended int) int {
+	if contended == 0 {
+		return 0
+	}
+	attempts := max(claims, contended)
+	steps := int(math.Ceil(float64(contended) / float64(attempts) * 10))
+	return min(contentionBaseBackoffMs<<min(steps, 6), contentionMaxBackoffMs)
+}
+
+func contentionGuidance(r *ContentionReport) []string {
+	var out []string
+	if r.Claims == 0 && r.Stolen+r.LeaseExpired == 0 {
+		return []string{"no claims recorded in window"}
+	}
+	if r.Overlaps > 0 && r.Claims > 0 {
+		pct := float64(r.Overlaps) / float64(r.Claims) * 100
+		out = append(out, fmt.Sprintf("%.0f%% of claims overlapped another agent's focus; partition agents by --project-dir or lengthen loop --cooldown", pct))
+	}
+	if r.CASConflicts > 0 {
+		out = append(out, "claims lost version races; retry task begin with jittered exponential backoff starting at each agent's suggested_backoff_ms")
+	}
+	if r.Stolen > 0 {
+		out = append(out, fmt.Sprintf("%d claim(s) were stolen after their leases ran out; have long-running agents log progress on the task (any task event renews the lease) or raise task claim --lease", r.Stolen))
+	}
+	if r.LeaseExpired > 0 {
+		out = append(out, fmt.Sprintf("%d lease(s) expired before the holder finished; raise task claim --lease or set a per-task default with task due --lease", r.LeaseExpired))
+	}
+	if len(out) == 0 {
+		out = append(out, "no contention detected; current pacing is fine")
+	}
+	return out
+}
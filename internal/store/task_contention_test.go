@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/dotcommander/vybe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskContentionReport_CountsOverlapsAndConflicts(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "shared work", "", "", 0)
+	require.NoError(t, err)
+
+	_, _, err = StartTaskAndFocus(context.Background(), db, "agent1", task.ID)
+	require.NoError(t, err)
+	_, _, err = StartTaskAndFocus(context.Background(), db, "agent2", task.ID)
+	require.NoError(t, err)
+	RecordClaimConflict(context.Background(), db, "agent2", task.ID)
+
+	report, err := TaskContentionReport(context.Background(), db, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Claims)
+	assert.Equal(t, 1, report.Overlaps)
+	assert.Equal(t, 1, report.CASConflicts)
+	require.Len(t, report.Agents, 2)
+
+	a1, a2 := report.Agents[0], report.Agents[1]
+	assert.Equal(t, "agent1", a1.AgentName)
+	assert.Zero(t, a1.Overlaps)
+	assert.Zero(t, a1.SuggestedBackoffMs)
+	assert.Equal(t, "agent2", a2.AgentName)
+	assert.Equal(t, 1, a2.Overlaps)
+	assert.Equal(t, 1, a2.CASConflicts)
+	assert.Positive(t, a2.SuggestedBackoffMs)
+	assert.Len(t, report.Guidance, 2)
+}
+
+func TestTaskContentionReport_CountsLostLeasesAgainstPriorOwner(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "long work", "", "", 0)
+	require.NoError(t, err)
+	require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
+		if _, err := InsertEventTx(tx, models.EventKindTaskStolen, "agent2", task.ID, "stolen", `{"prior_owner":"agent1"}`); err != nil {
+			return err
+		}
+		_, err := InsertEventTx(tx, models.EventKindTaskLeaseExpired, "gc", task.ID, "expired", `{"owner":"agent1"}`)
+		return err
+	}))
+
+	report, err := TaskContentionReport(context.Background(), db, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Stolen)
+	assert.Equal(t, 1, report.LeaseExpired)
+	require.Len(t, report.Agents, 1, "the thief and the reclaimer are not charged")
+	assert.Equal(t, "agent1", report.Agents[0].AgentName)
+	assert.Equal(t, 1, report.Agents[0].Stolen)
+	assert.Equal(t, 1, report.Agents[0].LeaseExpired)
+	assert.Len(t, report.Guidance, 2)
+	assert.Contains(t, report.Guidance[0], "stolen")
+	assert.Contains(t, report.Guidance[1], "expired")
+}
+
+func TestSuggestedBackoffMs_ScalesWithContentionRate(t *testing.T) {
+	assert.Zero(t, suggestedBackoffMs(10, 0))
+	low := suggestedBackoffMs(10, 1)
+	high := suggestedBackoffMs(10, 8)
+	assert.Less(t, low, high)
+	assert.LessOrEqual(t, suggestedBackoffMs(1, 50), contentionMaxBackoffMs)
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskCriteria_AddCheckAndList(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "with criteria", "", "", 0)
+	require.NoError(t, err)
+
+	c1, eid, err := AddTaskCriterionIdempotent(context.Background(), db, "agent1", "crit_add_1", task.ID, "tests pass")
+	require.NoError(t, err)
+	assert.Positive(t, eid)
+	assert.False(t, c1.Done)
+
+	replay, replayEID, err := AddTaskCriterionIdempotent(context.Background(), db, "agent1", "crit_add_1", task.ID, "tests pass")
+	require.NoError(t, err)
+	assert.Equal(t, c1.ID, replay.ID)
+	assert.Equal(t, eid, replayEID)
+
+	_, _, err = AddTaskCriterionIdempotent(context.Background(), db, "agent1", "crit_add_2", task.ID, "docs updated")
+	require.NoError(t, err)
+
+	checked, _, err := CheckTaskCriterionIdempotent(context.Background(), db, "agent1", "crit_check_1", task.ID, c1.ID, true)
+	require.NoError(t, err)
+	assert.True(t, checked.Done)
+	assert.Equal(t, "agent1", checked.CheckedBy)
+	assert.NotNil(t, checked.CheckedAt)
+
+	list, err := ListTaskCriteria(context.Background(), db, task.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "tests pass", list[0].Text)
+	assert.False(t, list[1].Done)
+
+	_, _, err = CheckTaskCriterionIdempotent(context.Background(), db, "agent1", "crit_check_missing", task.ID, 9999, true)
+	require.Error(t, err)
+}
+
+func TestRequireCriteriaDoneTx_ReportsOpenItems(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "strict", "", "", 0)
+	require.NoError(t, err)
+	c, _, err := AddTaskCriterionIdempotent(context.Background(), db, "agent1", "crit_strict_add", task.ID, "reviewed")
+	require.NoError(t, err)
+
+	err = Transact(context.Background(), db, func(tx *sql.Tx) error { return RequireCriteriaDoneTx(tx, task.ID) })
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrOpenCriteria))
+
+	_, _, err = CheckTaskCriterionIdempotent(context.Background(), db, "agent1", "crit_strict_check", task.ID, c.ID, true)
+	require.NoError(t, err)
+	require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error { return RequireCriteriaDoneTx(tx, task.ID) }))
+}
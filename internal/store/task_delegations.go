@@ -0,0 +1,279 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Delegation statuses, in the order a delegation moves through them.
+const (
+	DelegationDelegated = "delegated" // recorded by task delegate; no sub-agent started yet
+	DelegationRunning   = "running"   // a SubagentStart hook picked it up
+	DelegationFinished  = "finished"  // the sub-agent's SubagentStop hook fired
+)
+
+// maxSubagentNameLength bounds sub-agent names, which are agent type labels.
+const maxSubagentNameLength = 128
+
+// TaskDelegation is a task an agent handed to a named sub-agent. TaskTitle and
+// TaskStatus are read from the task when delegations are listed.
+type TaskDelegation struct {
+	ID         int64             `json:"id"`
+	TaskID     string            `json:"task_id"`
+	TaskTitle  string            `json:"task_title,omitempty"`
+	TaskStatus models.TaskStatus `json:"task_status,omitempty"`
+	AgentName  string            `json:"agent_name"`
+	Subagent   string            `json:"subagent"`
+	SubagentID string            `json:"subagent_id,omitempty"`
+	SessionID  string            `json:"session_id,omitempty"`
+	Status     string            `json:"status"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+const taskDelegationColumns = `d.id, d.task_id, COALESCE(t.title, ''), COALESCE(t.status, ''), d.agent_name, d.subagent,
+	d.subagent_id, d.session_id, d.status, d.created_at, d.started_at, d.finished_at`
+
+func scanTaskDelegation(row interface{ Scan(dest ...any) error }) (*TaskDelegation, error) {
+	var d TaskDelegation
+	var started, finished sql.NullTime
+	if err := row.Scan(&d.ID, &d.TaskID, &d.TaskTitle, &d.TaskStatus, &d.AgentName, &d.Subagent,
+		&d.SubagentID, &d.SessionID, &d.Status, &d.CreatedAt, &started, &finished); err != nil {
+		return nil, err
+	}
+	if started.Valid {
+		d.StartedAt = &started.Time
+	}
+	if finished.Valid {
+		d.FinishedAt = &finished.Time
+	}
+	return &d, nil
+}
+
+func getTaskDelegationTx(tx *sql.Tx, id int64) (*TaskDelegation, error) {
+	d, err := scanTaskDelegation(tx.QueryRowContext(context.Background(), `
+		SELECT `+taskDelegationColumns+`
+		FROM task_delegations d LEFT JOIN tasks t ON t.id = d.task_id
+		WHERE d.id = ?
+	`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load delegation: %w", err)
+	}
+	return d, nil
+}
+
+// DelegateTaskTx records that agentName handed taskID to the sub-agent named
+// subagent and emits a task_delegated event on the task.
+func DelegateTaskTx(tx *sql.Tx, agentName, taskID, subagent string) (*TaskDelegation, int64, error) {
+	subagent = strings.TrimSpace(subagent)
+	if subagent == "" {
+		return nil, 0, errors.New("sub-agent name is required")
+	}
+	if len(subagent) > maxSubagentNameLength {
+		return nil, 0, fmt.Errorf("sub-agent name exceeds %d characters", maxSubagentNameLength)
+	}
+	if _, err := GetTaskVersionTx(tx, taskID); err != nil {
+		return nil, 0, err
+	}
+
+	res, err := tx.ExecContext(context.Background(), `
+		INSERT INTO task_delegations (task_id, agent_name, subagent) VALUES (?, ?, ?)
+	`, taskID, agentName, subagent)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to record delegation: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read delegation id: %w", err)
+	}
+
+	meta, _ := json.Marshal(map[string]any{"delegation_id": id, "subagent": subagent})
+	eventID, err := InsertEventTx(tx, models.EventKindTaskDelegated, agentName, taskID,
+		"Task delegated to sub-agent: "+subagent, string(meta))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to append delegation event: %w", err)
+	}
+
+	d, err := getTaskDelegationTx(tx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return d, eventID, nil
+}
+
+// DelegateTaskIdempotent performs DelegateTaskTx once per (agent_name, request_id).
+func DelegateTaskIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, subagent string) (*TaskDelegation, int64, error) {
+	type idemResult struct {
+		Delegation *TaskDelegation `json:"delegation"`
+		EventID    int64           `json:"event_id"`
+	}
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "task.delegate", func(tx *sql.Tx) (idemResult, error) {
+		d, eid, txErr := DelegateTaskTx(tx, agentName, taskID, subagent)
+		if txErr != nil {
+			return idemResult{}, txErr
+		}
+		return idemResult{Delegation: d, EventID: eid}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.Delegation, r.EventID, nil
+}
+
+// StartDelegationTx marks agentName's oldest not-yet-started delegation to
+// subagent as running under subagentID and emits a subagent_started event on
+// its task. It returns a nil delegation when none is waiting.
+func StartDelegationTx(tx *sql.Tx, agentName, subagent, subagentID, sessionID string) (*TaskDelegation, int64, error) {
+	var id int64
+	err := tx.QueryRowContext(context.Background(), `
+		SELECT id FROM task_delegations
+		WHERE agent_name = ? AND subagent = ? AND status = ?
+		ORDER BY id LIMIT 1
+	`, agentName, subagent, DelegationDelegated).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find delegation: %w", err)
+	}
+
+	if _, err := tx.ExecContext(context.Background(), `
+		UPDATE task_delegations
+		SET status = ?, subagent_id = ?, session_id = ?, started_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, DelegationRunning, subagentID, sessionID, id); err != nil {
+		return nil, 0, fmt.Errorf("failed to start delegation: %w", err)
+	}
+	d, err := getTaskDelegationTx(tx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	meta, _ := json.Marshal(map[string]any{"delegation_id": id, "subagent": subagent, "subagent_id": subagentID, "session_id": sessionID})
+	eventID, err := InsertEventTx(tx, models.EventKindSubagentStarted, agentName, d.TaskID,
+		"Sub-agent started: "+subagent, string(meta))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to append sub-agent event: %w", err)
+	}
+	return d, eventID, nil
+}
+
+// FinishDelegationTx marks the running delegation of subagentID (or, when the
+// hook carries no id, agentName's oldest running delegation to subagent) as
+// finished and emits a subagent_stopped event on its task. It returns a nil
+// delegation when none is running.
+func FinishDelegationTx(tx *sql.Tx, agentName, subagent, subagentID string) (*TaskDelegation, int64, error) {
+	var id int64
+	err := tx.QueryRowContext(context.Background(), `
+		SELECT id FROM task_delegations
+		WHERE agent_name = ? AND status = ?
+		  AND ((? != '' AND subagent_id = ?) OR (? = '' AND subagent = ?))
+		ORDER BY id LIMIT 1
+	`, agentName, DelegationRunning, subagentID, subagentID, subagentID, subagent).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find delegation: %w", err)
+	}
+
+	if _, err := tx.ExecContext(context.Background(), `
+		UPDATE task_delegations SET status = ?, finished_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, DelegationFinished, id); err != nil {
+		return nil, 0, fmt.Errorf("failed to finish delegation: %w", err)
+	}
+	d, err := getTaskDelegationTx(tx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	meta, _ := json.Marshal(map[string]any{"delegation_id": id, "subagent": d.Subagent, "subagent_id": d.SubagentID})
+	eventID, err := InsertEventTx(tx, models.EventKindSubagentStopped, agentName, d.TaskID,
+		"Sub-agent finished: "+d.Subagent, string(meta))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to append sub-agent event: %w", err)
+	}
+	return d, eventID, nil
+}
+
+// StartDelegationIdempotent performs StartDelegationTx once per (agent_name, request_id).
+//
+//nolint:revive // argument-limit: agent, request, sub-agent, its id, and session are all required
+func StartDelegationIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, subagent, subagentID, sessionID string) (*TaskDelegation, int64, error) {
+	return runDelegationIdempotent(ctx, db, agentName, requestID, "task.delegation_start", func(tx *sql.Tx) (*TaskDelegation, int64, error) {
+		return StartDelegationTx(tx, agentName, subagent, subagentID, sessionID)
+	})
+}
+
+// FinishDelegationIdempotent performs FinishDelegationTx once per (agent_name, request_id).
+func FinishDelegationIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, subagent, subagentID string) (*TaskDelegation, int64, error) {
+	return runDelegationIdempotent(ctx, db, agentName, requestID, "task.delegation_finish", func(tx *sql.Tx) (*TaskDelegation, int64, error) {
+		return FinishDelegationTx(tx, agentName, subagent, subagentID)
+	})
+}
+
+func runDelegationIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, command string, op func(tx *sql.Tx) (*TaskDelegation, int64, error)) (*TaskDelegation, int64, error) {
+	type idemResult struct {
+		Delegation *TaskDelegation `json:"delegation"`
+		EventID    int64           `json:"event_id"`
+	}
+	r, err := RunIdempotent(ctx, db, agentName, requestID, command, func(tx *sql.Tx) (idemResult, error) {
+		d, eid, txErr := op(tx)
+		if txErr != nil {
+			return idemResult{}, txErr
+		}
+		return idemResult{Delegation: d, EventID: eid}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.Delegation, r.EventID, nil
+}
+
+// ListAgentDelegations returns agentName's delegations, unfinished ones first,
+// then the most recently finished, up to limit.
+func ListAgentDelegations(ctx context.Context, db *sql.DB, agentName string, limit int) ([]TaskDelegation, error) {
+	if agentName == "" {
+		return nil, errors.New("agent name is required")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var out []TaskDelegation
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT `+taskDelegationColumns+`
+			FROM task_delegations d LEFT JOIN tasks t ON t.id = d.task_id
+			WHERE d.agent_name = ?
+			ORDER BY d.status = ?, d.id DESC
+			LIMIT ?
+		`, agentName, DelegationFinished, limit)
+		if err != nil {
+			return fmt.Errorf("failed to query delegations: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = make([]TaskDelegation, 0)
+		for rows.Next() {
+			d, err := scanTaskDelegation(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan delegation: %w", err)
+			}
+			out = append(out, *d)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestTaskDelegations_FollowSubagentLifecycle(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	lint, err := CreateTask(context.Background(), db, "Lint", "", "", 0)
+	require.NoError(t, err)
+	review, err := CreateTask(context.Background(), db, "Review", "", "", 0)
+	require.NoError(t, err)
+
+	d1, eventID, err := DelegateTaskIdempotent(context.Background(), db, "parent", "delegate_1", lint.ID, " quality-agent ")
+	require.NoError(t, err)
+	assert.NotZero(t, eventID)
+	assert.Equal(t, "quality-agent", d1.Subagent)
+	assert.Equal(t, DelegationDelegated, d1.Status)
+	_, _, err = DelegateTaskIdempotent(context.Background(), db, "parent", "delegate_2", review.ID, "quality-agent")
+	require.NoError(t, err)
+
+	// The oldest waiting delegation to that sub-agent type is picked up first.
+	started, _, err := StartDelegationIdempotent(context.Background(), db, "parent", "start_1", "quality-agent", "sa_1", "sess_1")
+	require.NoError(t, err)
+	require.NotNil(t, started)
+	assert.Equal(t, lint.ID, started.TaskID)
+	assert.Equal(t, DelegationRunning, started.Status)
+	assert.NotNil(t, started.StartedAt)
+
+	none, _, err := StartDelegationIdempotent(context.Background(), db, "parent", "start_other", "docs-agent", "sa_2", "sess_1")
+	require.NoError(t, err)
+	assert.Nil(t, none, "nothing was delegated to docs-agent")
+
+	finished, _, err := FinishDelegationIdempotent(context.Background(), db, "parent", "stop_1", "quality-agent", "sa_1")
+	require.NoError(t, err)
+	require.NotNil(t, finished)
+	assert.Equal(t, lint.ID, finished.TaskID)
+	assert.Equal(t, DelegationFinished, finished.Status)
+
+	events, err := ListEvents(context.Background(), db, ListEventsParams{TaskID: lint.ID})
+	require.NoError(t, err)
+	kinds := make([]string, 0, len(events))
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	assert.Subset(t, kinds, []string{models.EventKindTaskDelegated, models.EventKindSubagentStarted, models.EventKindSubagentStopped})
+
+	list, err := ListAgentDelegations(context.Background(), db, "parent", 10)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, review.ID, list[0].TaskID, "unfinished delegations come first")
+	assert.Equal(t, "Review", list[0].TaskTitle)
+	assert.Equal(t, models.TaskStatusPending, list[0].TaskStatus)
+	assert.Equal(t, DelegationFinished, list[1].Status)
+
+	_, _, err = DelegateTaskIdempotent(context.Background(), db, "parent", "delegate_missing", "task_missing", "quality-agent")
+	require.Error(t, err)
+}
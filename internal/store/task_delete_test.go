@@ -13,7 +13,7 @@ func TestDeleteTask(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "Delete me", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "Delete me", "", "", 0)
 	require.NoError(t, err)
 
 	err = Transact(context.Background(), db, func(tx *sql.Tx) error {
@@ -22,7 +22,7 @@ func TestDeleteTask(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify task is gone
-	_, err = GetTask(db, task.ID)
+	_, err = GetTask(context.Background(), db, task.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
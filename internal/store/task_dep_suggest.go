@@ -0,0 +1,303 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Suggestion directions: which task of the pair should wait.
+const (
+	// DepSuggestDependsOn proposes that the task waits on the candidate.
+	DepSuggestDependsOn = "depends_on"
+	// DepSuggestBlocks proposes that the candidate waits on the task.
+	DepSuggestBlocks = "blocks"
+)
+
+// DepSuggestOptions tunes SuggestTaskDependencies.
+type DepSuggestOptions struct {
+	// MinConfidence drops candidates scoring below it (0..1).
+	MinConfidence float64
+	// Limit caps the number of suggestions; <= 0 means 10.
+	Limit int
+}
+
+// DepSuggestion is one proposed dependency edge with its evidence.
+type DepSuggestion struct {
+	TaskID          string            `json:"task_id"`
+	Title           string            `json:"title"`
+	Status          models.TaskStatus `json:"status"`
+	Direction       string            `json:"direction"`
+	Confidence      float64           `json:"confidence"`
+	TitleSimilarity float64           `json:"title_similarity"`
+	FileOverlap     float64           `json:"file_overlap"`
+	SharedTerms     []string          `json:"shared_terms,omitempty"`
+	SharedFiles     []string          `json:"shared_files,omitempty"`
+}
+
+// DepSuggestReport lists the suggestions for one task.
+type DepSuggestReport struct {
+	TaskID      string          `json:"task_id"`
+	Files       []string        `json:"files"`
+	Considered  int             `json:"considered"`
+	Suggestions []DepSuggestion `json:"suggestions"`
+}
+
+// depSuggestTask is a task with the terms and files it is compared on.
+type depSuggestTask struct {
+	id, title string
+	status    models.TaskStatus
+	createdAt time.Time
+	seq       int64 // rowid: creation order when created_at ties
+	terms     map[string]bool
+	files     map[string]bool
+}
+
+// SuggestTaskDependencies proposes dependency edges between taskID and the
+// other open tasks (pending, in progress, or blocked) of its project. Each
+// candidate is scored on title similarity (Jaccard over significant words) and
+// file overlap (artifact paths plus file paths named in descriptions). Pairs
+// already linked, directly or through a chain, are skipped. The older or
+// already started task of a pair is proposed as the one to finish first.
+func SuggestTaskDependencies(ctx context.Context, db *sql.DB, taskID string, opts DepSuggestOptions) (*DepSuggestReport, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+
+	var target *depSuggestTask
+	var candidates []*depSuggestTask
+	var adj map[string][]string
+	err := RetryWithBackoff(ctx, func() error {
+		var err error
+		target, candidates, adj, err = loadDepSuggestInputs(ctx, db, taskID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DepSuggestReport{TaskID: taskID, Files: sortedKeys(target.files), Considered: len(candidates), Suggestions: []DepSuggestion{}}
+	for _, c := range candidates {
+		if dependencyPath(adj, taskID, c.id) != nil || dependencyPath(adj, c.id, taskID) != nil {
+			continue
+		}
+		sharedTerms := sharedKeys(target.terms, c.terms)
+		titleSim := ratio(len(sharedTerms), len(target.terms)+len(c.terms)-len(sharedTerms))
+		sharedFiles := sharedFilePaths(target.files, c.files)
+		fileOverlap := ratio(len(sharedFiles), min(len(target.files), len(c.files)))
+
+		confidence := titleSim
+		if len(target.files) > 0 && len(c.files) > 0 {
+			confidence = 0.6*fileOverlap + 0.4*titleSim
+		}
+		confidence = math.Round(confidence*100) / 100
+		if confidence <= 0 || confidence < opts.MinConfidence {
+			continue
+		}
+
+		direction := DepSuggestBlocks
+		if c.status == models.TaskStatusInProgress && target.status != models.TaskStatusInProgress ||
+			c.status == target.status && c.olderThan(target) {
+			direction = DepSuggestDependsOn
+		}
+		report.Suggestions = append(report.Suggestions, DepSuggestion{
+			TaskID: c.id, Title: c.title, Status: c.status, Direction: direction,
+			Confidence:      confidence,
+			TitleSimilarity: math.Round(titleSim*100) / 100,
+			FileOverlap:     math.Round(fileOverlap*100) / 100,
+			SharedTerms:     sharedTerms,
+			SharedFiles:     sharedFiles,
+		})
+	}
+
+	sort.SliceStable(report.Suggestions, func(i, j int) bool {
+		return report.Suggestions[i].Confidence > report.Suggestions[j].Confidence
+	})
+	if len(report.Suggestions) > opts.Limit {
+		report.Suggestions = report.Suggestions[:opts.Limit]
+	}
+	return report, nil
+}
+
+func (t *depSuggestTask) olderThan(o *depSuggestTask) bool {
+	if !t.createdAt.Equal(o.createdAt) {
+		return t.createdAt.Before(o.createdAt)
+	}
+	return t.seq < o.seq
+}
+
+func loadDepSuggestInputs(ctx context.Context, db *sql.DB, taskID string) (*depSuggestTask, []*depSuggestTask, map[string][]string, error) {
+	var projectID, description string
+	target := &depSuggestTask{id: taskID}
+	err := db.QueryRowContext(ctx,
+		`SELECT rowid, title, COALESCE(description, ''), status, COALESCE(project_id, ''), created_at FROM tasks WHERE id = ?`, taskID,
+	).Scan(&target.seq, &target.title, &description, &target.status, &projectID, &target.createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil, fmt.Errorf("task not found: %s", taskID)
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load task: %w", err)
+	}
+	target.terms = titleTerms(target.title)
+	target.files = descriptionFiles(description)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT rowid, id, title, COALESCE(description, ''), status, created_at FROM tasks
+		WHERE id != ? AND status IN (?, ?, ?) AND COALESCE(project_id, '') = ?
+		ORDER BY created_at ASC, rowid ASC
+	`, taskID, models.TaskStatusPending, models.TaskStatusInProgress, models.TaskStatusBlocked, projectID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load candidate tasks: %w", err)
+	}
+	byID := map[string]*depSuggestTask{taskID: target}
+	var candidates []*depSuggestTask
+	for rows.Next() {
+		c := &depSuggestTask{}
+		if err := rows.Scan(&c.seq, &c.id, &c.title, &description, &c.status, &c.createdAt); err != nil {
+			_ = rows.Close()
+			return nil, nil, nil, fmt.Errorf("failed to scan candidate task: %w", err)
+		}
+		c.terms = titleTerms(c.title)
+		c.files = descriptionFiles(description)
+		byID[c.id] = c
+		candidates = append(candidates, c)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT task_id, file_path FROM artifacts
+		WHERE task_id IN (SELECT id FROM tasks WHERE COALESCE(project_id, '') = ?)
+	`, projectID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load artifacts: %w", err)
+	}
+	for rows.Next() {
+		var id, path string
+		if err := rows.Scan(&id, &path); err != nil {
+			_ = rows.Close()
+			return nil, nil, nil, fmt.Errorf("failed to scan artifact: %w", err)
+		}
+		if t := byID[id]; t != nil {
+			t.files[filepath.ToSlash(filepath.Clean(path))] = true
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT task_id, depends_on_task_id FROM task_dependencies`)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to query dependencies: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var edges []dependencyEdge
+	for rows.Next() {
+		var e dependencyEdge
+		if err := rows.Scan(&e.taskID, &e.dependsOn); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to scan dependency: %w", err)
+		}
+		edges = append(edges, e)
+	}
+	return target, candidates, dependencyAdjacency(edges), rows.Err()
+}
+
+// depSuggestStopwords are words too common in task titles to signal a link.
+var depSuggestStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"add": true, "fix": true, "update": true, "make": true, "use": true, "support": true,
+	"task": true, "new": true, "when": true, "that": true, "this": true, "not": true,
+}
+
+// titleTerms returns the significant lowercase words of a title.
+func titleTerms(title string) map[string]bool {
+	terms := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(w)) < 3 || depSuggestStopwords[w] {
+			continue
+		}
+		terms[strings.TrimSuffix(w, "s")] = true
+	}
+	return terms
+}
+
+// descriptionFiles picks out file paths named in a description: words with a
+// short alphabetic extension ("main.go", "docs/guide.md"), excluding URLs.
+// One-letter extensions only count inside a path, so "e.g." is not a file.
+func descriptionFiles(description string) map[string]bool {
+	files := map[string]bool{}
+	for _, w := range strings.Fields(description) {
+		w = strings.Trim(w, "`'\"()[]{}<>,;:!?")
+		w = strings.TrimSuffix(w, ".")
+		if w == "" || strings.Contains(w, "://") {
+			continue
+		}
+		ext := filepath.Ext(w)
+		minExt := 3
+		if strings.Contains(w, "/") {
+			minExt = 2
+		}
+		if len(ext) < minExt || len(ext) > 6 || strings.IndexFunc(ext[1:], func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+			continue
+		}
+		files[filepath.ToSlash(filepath.Clean(w))] = true
+	}
+	return files
+}
+
+// sharedFilePaths matches paths that are equal or where one is a path suffix
+// of the other (an absolute artifact path against a relative mention).
+func sharedFilePaths(a, b map[string]bool) []string {
+	var shared []string
+	for pa := range a {
+		for pb := range b {
+			if pa == pb || strings.HasSuffix(pa, "/"+pb) || strings.HasSuffix(pb, "/"+pa) {
+				shared = append(shared, pa)
+				break
+			}
+		}
+	}
+	sort.Strings(shared)
+	return shared
+}
+
+func sharedKeys(a, b map[string]bool) []string {
+	var shared []string
+	for k := range a {
+		if b[k] {
+			shared = append(shared, k)
+		}
+	}
+	sort.Strings(shared)
+	return shared
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func ratio(n, d int) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestSuggestTaskDependencies_TitleAndFiles(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	schema, err := CreateTask(context.Background(), db, "Design billing schema", "Tables in internal/billing/schema.sql", "proj", 0)
+	require.NoError(t, err)
+	api, err := CreateTask(context.Background(), db, "Billing invoice API", "Handlers read schema.sql and write api.go", "proj", 0)
+	require.NoError(t, err)
+	unrelated, err := CreateTask(context.Background(), db, "Refresh onboarding docs", "", "proj", 0)
+	require.NoError(t, err)
+	other, err := CreateTask(context.Background(), db, "Billing invoice API", "", "elsewhere", 0)
+	require.NoError(t, err)
+	done, err := CreateTask(context.Background(), db, "Billing invoice emails", "", "proj", 0)
+	require.NoError(t, err)
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, done.ID, string(models.TaskStatusCompleted), done.Version))
+	_, _, err = AddArtifact(context.Background(), db, "agent1", schema.ID, "/repo/internal/billing/api.go", "text/x-go")
+	require.NoError(t, err)
+
+	report, err := SuggestTaskDependencies(context.Background(), db, api.ID, DepSuggestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Considered, "other projects and closed tasks are not candidates")
+	require.Len(t, report.Suggestions, 1)
+
+	s := report.Suggestions[0]
+	assert.Equal(t, schema.ID, s.TaskID)
+	assert.Equal(t, DepSuggestDependsOn, s.Direction, "the older task finishes first")
+	assert.Equal(t, []string{"billing"}, s.SharedTerms)
+	assert.Equal(t, []string{"api.go", "schema.sql"}, s.SharedFiles)
+	assert.InDelta(t, 1.0, s.FileOverlap, 0.001)
+	assert.Greater(t, s.Confidence, s.TitleSimilarity)
+	assert.NotContains(t, []string{unrelated.ID, other.ID, done.ID}, s.TaskID)
+
+	// The reverse view proposes the same pair the other way round.
+	report, err = SuggestTaskDependencies(context.Background(), db, schema.ID, DepSuggestOptions{})
+	require.NoError(t, err)
+	require.Len(t, report.Suggestions, 1)
+	assert.Equal(t, DepSuggestBlocks, report.Suggestions[0].Direction)
+
+	// Linked pairs are not suggested again.
+	_, err = AddTaskDependencyIdempotent(context.Background(), db, "agent1", "dep_1", api.ID, schema.ID)
+	require.NoError(t, err)
+	report, err = SuggestTaskDependencies(context.Background(), db, api.ID, DepSuggestOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, report.Suggestions)
+
+	_, err = SuggestTaskDependencies(context.Background(), db, "missing", DepSuggestOptions{})
+	require.Error(t, err)
+}
+
+func TestSuggestTaskDependencies_MinConfidence(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	a, err := CreateTask(context.Background(), db, "Parser error recovery", "", "", 0)
+	require.NoError(t, err)
+	_, err = CreateTask(context.Background(), db, "Parser error messages and recovery hints", "", "", 0)
+	require.NoError(t, err)
+
+	report, err := SuggestTaskDependencies(context.Background(), db, a.ID, DepSuggestOptions{})
+	require.NoError(t, err)
+	require.Len(t, report.Suggestions, 1)
+	assert.Equal(t, DepSuggestBlocks, report.Suggestions[0].Direction)
+
+	report, err = SuggestTaskDependencies(context.Background(), db, a.ID, DepSuggestOptions{MinConfidence: 0.9})
+	require.NoError(t, err)
+	assert.Empty(t, report.Suggestions)
+}
+
+func TestDescriptionFiles(t *testing.T) {
+	files := descriptionFiles("Edit `cmd/main.go`, README.md and pkg/x.c; see https://example.com/a.html, e.g. v1.2")
+	assert.Equal(t, map[string]bool{"cmd/main.go": true, "README.md": true, "pkg/x.c": true}, files)
+}
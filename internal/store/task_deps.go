@@ -0,0 +1,404 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// ErrDependencyCycle is returned when a dependency edge would close a loop.
+var ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+// DependencyCycleError reports the existing chain a rejected edge would close.
+// Path runs from DependsOn to TaskID along existing dependencies.
+type DependencyCycleError struct {
+	TaskID    string
+	DependsOn string
+	Path      []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	if e.TaskID == e.DependsOn {
+		return fmt.Sprintf("task %s cannot depend on itself", e.TaskID)
+	}
+	return fmt.Sprintf("task %s cannot depend on %s: %s already depends on it (cycle: %s -> %s)",
+		e.TaskID, e.DependsOn, e.DependsOn, e.TaskID, strings.Join(e.Path, " -> "))
+}
+func (e *DependencyCycleError) ErrorCode() string { return "DEPENDENCY_CYCLE" }
+func (e *DependencyCycleError) Context() map[string]string {
+	return map[string]string{"task_id": e.TaskID, "depends_on": e.DependsOn, "cycle": strings.Join(e.Path, ",")}
+}
+func (e *DependencyCycleError) SuggestedAction() string {
+	return "drop an edge on the existing chain first, or depend on a different task"
+}
+func (e *DependencyCycleError) Unwrap() error { return ErrDependencyCycle }
+
+// AddDependencyResult is the outcome of AddTaskDependencyTx. Added is false
+// when the edge already existed; no event is emitted then.
+type AddDependencyResult struct {
+	TaskID    string `json:"task_id"`
+	DependsOn string `json:"depends_on"`
+	Added     bool   `json:"added"`
+	EventID   int64  `json:"event_id,omitempty"`
+}
+
+// AddTaskDependencyTx records that taskID waits on dependsOn. Both tasks must
+// exist; self-edges and edges that would close a cycle through existing
+// dependencies are rejected with a *DependencyCycleError.
+func AddTaskDependencyTx(tx *sql.Tx, agentName, taskID, dependsOn string) (*AddDependencyResult, error) {
+	for _, id := range []string{taskID, dependsOn} {
+		if _, err := GetTaskVersionTx(tx, id); err != nil {
+			return nil, err
+		}
+	}
+	if taskID == dependsOn {
+		return nil, &DependencyCycleError{TaskID: taskID, DependsOn: dependsOn, Path: []string{taskID}}
+	}
+
+	edges, err := loadDependencyEdgesTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	if path := dependencyPath(dependencyAdjacency(edges), dependsOn, taskID); path != nil {
+		return nil, &DependencyCycleError{TaskID: taskID, DependsOn: dependsOn, Path: path}
+	}
+
+	result := &AddDependencyResult{TaskID: taskID, DependsOn: dependsOn}
+	res, err := tx.ExecContext(context.Background(),
+		`INSERT OR IGNORE INTO task_dependencies (task_id, depends_on_task_id) VALUES (?, ?)`, taskID, dependsOn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert dependency: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return result, nil
+	}
+
+	meta, _ := json.Marshal(map[string]any{"depends_on": dependsOn})
+	eventID, err := InsertEventTx(tx, models.EventKindDependencyAdded, agentName, taskID,
+		fmt.Sprintf("Dependency added: waits on %s", dependsOn), string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append dependency event: %w", err)
+	}
+	result.Added = true
+	result.EventID = eventID
+	return result, nil
+}
+
+// AddTaskDependencyIdempotent performs AddTaskDependencyTx once per (agent_name, request_id).
+func AddTaskDependencyIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, dependsOn string) (*AddDependencyResult, error) {
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "task.add_dep", func(tx *sql.Tx) (AddDependencyResult, error) {
+		res, txErr := AddTaskDependencyTx(tx, agentName, taskID, dependsOn)
+		if txErr != nil {
+			return AddDependencyResult{}, txErr
+		}
+		return *res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Dependency issue kinds reported by ValidateTaskGraph.
+const (
+	DependencyIssueSelfEdge = "self_edge"
+	DependencyIssueOrphan   = "orphan"
+	DependencyIssueCycle    = "cycle"
+)
+
+// DependencyIssue is one problem edge. For cycles, Cycle lists the loop's
+// tasks in dependency order and TaskID/DependsOn name the edge that --fix
+// removes to break it: the most recently added edge on the loop.
+type DependencyIssue struct {
+	Kind      string   `json:"kind"`
+	TaskID    string   `json:"task_id"`
+	DependsOn string   `json:"depends_on"`
+	Cycle     []string `json:"cycle,omitempty"`
+}
+
+// TaskGraphValidation is the result of checking the dependency graph. Fixed
+// is set when the issue edges were deleted.
+type TaskGraphValidation struct {
+	ProjectID string            `json:"project_id,omitempty"`
+	Valid     bool              `json:"valid"`
+	Issues    []DependencyIssue `json:"issues"`
+	Fixed     bool              `json:"fixed,omitempty"`
+	EventID   int64             `json:"event_id,omitempty"`
+}
+
+// ValidateTaskGraph finds self-edges, edges whose task no longer exists, and
+// cycles. With projectID set, only issues touching that project's tasks are
+// reported.
+func ValidateTaskGraph(ctx context.Context, db *sql.DB, projectID string) (*TaskGraphValidation, error) {
+	var v *TaskGraphValidation
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
+		var txErr error
+		v, txErr = validateTaskGraphTx(tx, projectID)
+		return txErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// RepairTaskGraphIdempotent deletes every edge ValidateTaskGraph reports and
+// appends one task_graph_repaired event, once per (agent_name, request_id).
+// A graph without issues is left untouched and emits no event.
+func RepairTaskGraphIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) (*TaskGraphValidation, error) {
+	v, err := RunIdempotent(ctx, db, agentName, requestID, "task.graph_fix", func(tx *sql.Tx) (TaskGraphValidation, error) {
+		v, txErr := validateTaskGraphTx(tx, projectID)
+		if txErr != nil {
+			return TaskGraphValidation{}, txErr
+		}
+		if v.Valid {
+			return *v, nil
+		}
+		for _, issue := range v.Issues {
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM task_dependencies WHERE task_id = ? AND depends_on_task_id = ?`,
+				issue.TaskID, issue.DependsOn); err != nil {
+				return TaskGraphValidation{}, fmt.Errorf("failed to delete dependency: %w", err)
+			}
+		}
+		meta, _ := json.Marshal(map[string]any{"issues": v.Issues})
+		eventID, err := InsertEventWithProjectTx(tx, models.EventKindTaskGraphRepaired, agentName, projectID, "",
+			fmt.Sprintf("Task graph repaired: %d dependency edges removed", len(v.Issues)), string(meta))
+		if err != nil {
+			return TaskGraphValidation{}, fmt.Errorf("failed to append event: %w", err)
+		}
+		v.Fixed = true
+		v.EventID = eventID
+		return *v, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+type dependencyEdge struct {
+	taskID    string
+	dependsOn string
+	createdAt time.Time
+}
+
+func loadDependencyEdgesTx(tx *sql.Tx) ([]dependencyEdge, error) {
+	rows, err := tx.QueryContext(context.Background(),
+		`SELECT task_id, depends_on_task_id, created_at FROM task_dependencies ORDER BY task_id, depends_on_task_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dependencies: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var edges []dependencyEdge
+	for rows.Next() {
+		var e dependencyEdge
+		if err := rows.Scan(&e.taskID, &e.dependsOn, &e.createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dependency: %w", err)
+		}
+		edges = append(edges, e)
+	}
+	return edges, rows.Err()
+}
+
+// dependencyAdjacency maps each task to the tasks it depends on, in the
+// (sorted) order of edges.
+func dependencyAdjacency(edges []dependencyEdge) map[string][]string {
+	adj := make(map[string][]string)
+	for _, e := range edges {
+		adj[e.taskID] = append(adj[e.taskID], e.dependsOn)
+	}
+	return adj
+}
+
+// dependencyPath returns the tasks on a dependency chain from "from" to "to"
+// (both included), or nil when to is unreachable.
+func dependencyPath(adj map[string][]string, from, to string) []string {
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == to {
+			var path []string
+			for n := to; n != ""; n = prev[n] {
+				path = append(path, n)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		for _, next := range adj[cur] {
+			if _, seen := prev[next]; !seen {
+				prev[next] = cur
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+func validateTaskGraphTx(tx *sql.Tx, projectID string) (*TaskGraphValidation, error) {
+	edges, err := loadDependencyEdgesTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	taskProject := map[string]string{}
+	rows, err := tx.QueryContext(context.Background(), `SELECT id, COALESCE(project_id, '') FROM tasks`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	for rows.Next() {
+		var id, pid string
+		if err := rows.Scan(&id, &pid); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		taskProject[id] = pid
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	inScope := func(ids ...string) bool {
+		if projectID == "" {
+			return true
+		}
+		for _, id := range ids {
+			if pid, ok := taskProject[id]; ok && pid == projectID {
+				return true
+			}
+		}
+		return false
+	}
+
+	v := &TaskGraphValidation{ProjectID: projectID, Issues: []DependencyIssue{}}
+	var sound []dependencyEdge
+	for _, e := range edges {
+		_, taskOK := taskProject[e.taskID]
+		_, depOK := taskProject[e.dependsOn]
+		switch {
+		case e.taskID == e.dependsOn:
+			if inScope(e.taskID) {
+				v.Issues = append(v.Issues, DependencyIssue{Kind: DependencyIssueSelfEdge, TaskID: e.taskID, DependsOn: e.dependsOn})
+			}
+		case !taskOK || !depOK:
+			if inScope(e.taskID, e.dependsOn) {
+				v.Issues = append(v.Issues, DependencyIssue{Kind: DependencyIssueOrphan, TaskID: e.taskID, DependsOn: e.dependsOn})
+			}
+		default:
+			sound = append(sound, e)
+		}
+	}
+
+	for _, cycle := range breakDependencyCycles(sound) {
+		if inScope(cycle.Cycle...) {
+			v.Issues = append(v.Issues, cycle)
+		}
+	}
+	v.Valid = len(v.Issues) == 0
+	return v, nil
+}
+
+// breakDependencyCycles repeatedly finds a cycle and drops its most recently
+// added edge (ties broken by task ID) until the graph is acyclic, returning
+// one issue per dropped edge. Search order is deterministic.
+func breakDependencyCycles(edges []dependencyEdge) []DependencyIssue {
+	created := make(map[[2]string]time.Time, len(edges))
+	for _, e := range edges {
+		created[[2]string{e.taskID, e.dependsOn}] = e.createdAt
+	}
+	adj := dependencyAdjacency(edges)
+	nodes := make([]string, 0, len(adj))
+	for id := range adj {
+		nodes = append(nodes, id)
+	}
+	sort.Strings(nodes)
+
+	var issues []DependencyIssue
+	for {
+		cycle := findDependencyCycle(adj, nodes)
+		if cycle == nil {
+			return issues
+		}
+		// cycle[i] depends on cycle[i+1], wrapping around.
+		drop := -1
+		for i := range cycle {
+			if drop < 0 {
+				drop = i
+				continue
+			}
+			a := created[[2]string{cycle[i], cycle[(i+1)%len(cycle)]}]
+			b := created[[2]string{cycle[drop], cycle[(drop+1)%len(cycle)]}]
+			if a.After(b) || (a.Equal(b) && cycle[i] < cycle[drop]) {
+				drop = i
+			}
+		}
+		from, to := cycle[drop], cycle[(drop+1)%len(cycle)]
+		issues = append(issues, DependencyIssue{Kind: DependencyIssueCycle, TaskID: from, DependsOn: to, Cycle: cycle})
+		deps := adj[from]
+		for i, d := range deps {
+			if d == to {
+				adj[from] = append(deps[:i:i], deps[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// findDependencyCycle returns one cycle as the tasks on it in dependency order,
+// or nil when adj is acyclic.
+func findDependencyCycle(adj map[string][]string, nodes []string) []string {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := map[string]int{}
+	var stack []string
+	var cycle []string
+	var visit func(n string) bool
+	visit = func(n string) bool {
+		state[n] = onStack
+		stack = append(stack, n)
+		for _, next := range adj[n] {
+			switch state[next] {
+			case onStack:
+				for i, s := range stack {
+					if s == next {
+						cycle = append([]string(nil), stack[i:]...)
+						return true
+					}
+				}
+			case unvisited:
+				if visit(next) {
+					return true
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[n] = done
+		return false
+	}
+	for _, n := range nodes {
+		if state[n] == unvisited && visit(n) {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddTaskDependency_RejectsCycles(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	a, err := CreateTask(context.Background(), db, "A", "", "p1", 0)
+	require.NoError(t, err)
+	b, err := CreateTask(context.Background(), db, "B", "", "p1", 0)
+	require.NoError(t, err)
+	c, err := CreateTask(context.Background(), db, "C", "", "p1", 0)
+	require.NoError(t, err)
+
+	res, err := AddTaskDependencyIdempotent(context.Background(), db, "agent1", "dep-1", a.ID, b.ID)
+	require.NoError(t, err)
+	assert.True(t, res.Added)
+	assert.Positive(t, res.EventID)
+
+	replay, err := AddTaskDependencyIdempotent(context.Background(), db, "agent1", "dep-1", a.ID, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, res, replay)
+
+	again, err := AddTaskDependencyIdempotent(context.Background(), db, "agent1", "dep-1b", a.ID, b.ID)
+	require.NoError(t, err)
+	assert.False(t, again.Added, "existing edge is a no-op")
+
+	_, err = AddTaskDependencyIdempotent(context.Background(), db, "agent1", "dep-2", b.ID, c.ID)
+	require.NoError(t, err)
+
+	// c -> a would close a -> b -> c -> a.
+	_, err = AddTaskDependencyIdempotent(context.Background(), db, "agent1", "dep-3", c.ID, a.ID)
+	var cycleErr *DependencyCycleError
+	require.ErrorAs(t, err, &cycleErr)
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	assert.Equal(t, []string{a.ID, b.ID, c.ID}, cycleErr.Path)
+
+	_, err = AddTaskDependencyIdempotent(context.Background(), db, "agent1", "dep-4", a.ID, a.ID)
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+
+	_, err = AddTaskDependencyIdempotent(context.Background(), db, "agent1", "dep-5", a.ID, "task_missing")
+	assert.Error(t, err)
+}
+
+func TestValidateTaskGraph_FindsAndFixesIssues(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	a, err := CreateTask(context.Background(), db, "A", "", "p1", 0)
+	require.NoError(t, err)
+	b, err := CreateTask(context.Background(), db, "B", "", "p1", 0)
+	require.NoError(t, err)
+	c, err := CreateTask(context.Background(), db, "C", "", "p1", 0)
+	require.NoError(t, err)
+	other, err := CreateTask(context.Background(), db, "Other", "", "p2", 0)
+	require.NoError(t, err)
+
+	v, err := ValidateTaskGraph(context.Background(), db, "")
+	require.NoError(t, err)
+	assert.True(t, v.Valid)
+	assert.Empty(t, v.Issues)
+
+	// Legacy rows written around the checks: a cycle whose newest edge is
+	// c -> a, a self-edge, and an edge to a deleted task.
+	_, err = db.Exec(`PRAGMA foreign_keys=OFF`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO task_dependencies (task_id, depends_on_task_id, created_at) VALUES
+		(?, ?, '2026-01-01 00:00:00'), (?, ?, '2026-01-02 00:00:00'), (?, ?, '2026-01-03 00:00:00'),
+		(?, ?, '2026-01-01 00:00:00'), (?, 'task_deleted', '2026-01-01 00:00:00')`,
+		a.ID, b.ID, b.ID, c.ID, c.ID, a.ID, other.ID, other.ID, b.ID)
+	require.NoError(t, err)
+	_, err = db.Exec(`PRAGMA foreign_keys=ON`)
+	require.NoError(t, err)
+
+	v, err = ValidateTaskGraph(context.Background(), db, "")
+	require.NoError(t, err)
+	assert.False(t, v.Valid)
+	kinds := map[string]DependencyIssue{}
+	for _, issue := range v.Issues {
+		kinds[issue.Kind] = issue
+	}
+	require.Len(t, v.Issues, 3)
+	assert.Equal(t, other.ID, kinds[DependencyIssueSelfEdge].TaskID)
+	assert.Equal(t, "task_deleted", kinds[DependencyIssueOrphan].DependsOn)
+	cycle := kinds[DependencyIssueCycle]
+	assert.Equal(t, c.ID, cycle.TaskID, "newest edge on the loop is the one to drop")
+	assert.Equal(t, a.ID, cycle.DependsOn)
+	assert.ElementsMatch(t, []string{a.ID, b.ID, c.ID}, cycle.Cycle)
+
+	scoped, err := ValidateTaskGraph(context.Background(), db, "p2")
+	require.NoError(t, err)
+	require.Len(t, scoped.Issues, 1)
+	assert.Equal(t, DependencyIssueSelfEdge, scoped.Issues[0].Kind)
+
+	fixed, err := RepairTaskGraphIdempotent(context.Background(), db, "agent1", "fix-1", "")
+	require.NoError(t, err)
+	assert.True(t, fixed.Fixed)
+	assert.Positive(t, fixed.EventID)
+	assert.Len(t, fixed.Issues, 3)
+
+	var remaining int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM task_dependencies`).Scan(&remaining))
+	assert.Equal(t, 2, remaining, "a -> b and b -> c survive")
+
+	v, err = ValidateTaskGraph(context.Background(), db, "")
+	require.NoError(t, err)
+	assert.True(t, v.Valid)
+
+	replay, err := RepairTaskGraphIdempotent(context.Background(), db, "agent1", "fix-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, fixed.EventID, replay.EventID)
+}
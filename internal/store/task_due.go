@@ -0,0 +1,202 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// dueTimeLayout matches SQLite's CURRENT_TIMESTAMP so due_at compares correctly
+// as text against it and against formatted "now" values.
+const dueTimeLayout = "2006-01-02 15:04:05"
+
+func formatDue(t time.Time) string {
+	return t.UTC().Format(dueTimeLayout)
+}
+
+// SetTaskDueTx sets or clears (due == nil) a task deadline with a CAS version
+// bump and a task_due_set event. Changing the deadline re-arms the overdue
+// notification so the next sweep reports the new deadline once.
+func SetTaskDueTx(tx *sql.Tx, agentName, taskID string, due *time.Time) (int64, error) {
+	version, err := GetTaskVersionTx(tx, taskID)
+	if err != nil {
+		return 0, err
+	}
+
+	var dueVal any
+	message := "Deadline cleared"
+	if due != nil {
+		dueVal = formatDue(*due)
+		message = "Deadline set: " + due.UTC().Format(time.RFC3339)
+	}
+
+	return casUpdateTaskWithEvent(tx, agentName, taskID, version,
+		`UPDATE tasks
+		SET due_at = ?, overdue_notified_at = NULL,
+		    version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND version = ?`,
+		[]any{dueVal, taskID, version},
+		models.EventKindTaskDueSet,
+		message,
+	)
+}
+
+// ListOverdueTasks returns unfinished tasks whose deadline is before now, most
+// overdue first. projectID, when non-empty, restricts to that project.
+func ListOverdueTasks(ctx context.Context, db *sql.DB, projectID string, now time.Time, limit int) (_ []*models.Task, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if limit <= 0 {
+		limit = 50
+	}
+	query := `SELECT id, title, description, status, priority, project_id, blocked_reason, due_at, size, attempts, retry_at, outcome, superseded_by, version, created_at, updated_at
+		FROM tasks WHERE status != 'completed' AND due_at IS NOT NULL AND due_at < ?`
+	args := []any{formatDue(now)}
+	if projectID != "" {
+		query += ` AND ` + ProjectScopeClause
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY due_at ASC, priority DESC LIMIT ?`
+	args = append(args, limit)
+	return queryTasks(ctx, db, query, args...)
+}
+
+// ListNextTasks returns pending tasks in pick order: overdue first (earliest
+// deadline first), then the remaining tasks by effective priority (inherited
+// through dependencies, see task_priority.go), with upcoming deadlines
+// breaking priority ties.
+func ListNextTasks(ctx context.Context, db *sql.DB, projectID string, now time.Time, limit int) ([]*models.Task, error) {
+	return ListNextTasksFiltered(ctx, db, projectID, now, limit, FocusFilter{})
+}
+
+// ListNextTasksFiltered is ListNextTasks restricted to tasks passing filter's
+// tags and priority floor.
+func ListNextTasksFiltered(ctx context.Context, db *sql.DB, projectID string, now time.Time, limit int, filter FocusFilter) (_ []*models.Task, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if limit <= 0 {
+		limit = 5
+	}
+	nowStr := formatDue(now)
+	query := effectivePriorityCTE + `SELECT id, title, description, status, priority, project_id, blocked_reason, due_at, size, attempts, retry_at, outcome, superseded_by, version, created_at, updated_at
+		FROM tasks` + effectivePriorityJoin + ` WHERE status = 'pending' AND ` + activeProjectTaskClause
+	args := []any{}
+	if projectID != "" {
+		query += ` AND ` + ProjectScopeClause
+		args = append(args, projectID)
+	}
+	cond, condArgs := filter.clause()
+	query += cond
+	args = append(args, condArgs...)
+	query += ` ORDER BY (due_at IS NOT NULL AND due_at < ?) DESC,
+		CASE WHEN due_at IS NOT NULL AND due_at < ? THEN due_at END ASC,
+		` + effectivePriorityOrder + `, (due_at IS NULL) ASC, due_at ASC, created_at ASC
+		LIMIT ?`
+	args = append(args, nowStr, nowStr, limit)
+	return queryTasks(ctx, db, query, args...)
+}
+
+func queryTasks(ctx context.Context, db *sql.DB, query string, args ...any) ([]*models.Task, error) {
+	var tasks []*models.Task
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query tasks: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		tasks = make([]*models.Task, 0)
+		for rows.Next() {
+			task, err := scanTaskRow(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan task: %w", err)
+			}
+			tasks = append(tasks, task)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// OverdueNotice is one task_overdue event emitted by a sweep.
+type OverdueNotice struct {
+	TaskID       string    `json:"task_id"`
+	Title        string    `json:"title"`
+	DueAt        time.Time `json:"due_at"`
+	OverdueHours float64   `json:"overdue_hours"`
+	EventID      int64     `json:"event_id"`
+}
+
+// SweepOverdueTx emits one task_overdue event per unfinished task that passed
+// its deadline and has not been reported since the deadline was last set.
+func SweepOverdueTx(tx *sql.Tx, agentName, projectID string, now time.Time) ([]OverdueNotice, error) {
+	query := `SELECT id, title, due_at FROM tasks
+		WHERE status != 'completed' AND due_at IS NOT NULL AND due_at < ? AND overdue_notified_at IS NULL`
+	args := []any{formatDue(now)}
+	if projectID != "" {
+		query += ` AND ` + ProjectScopeClause
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY due_at ASC`
+
+	rows, err := tx.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue tasks: %w", err)
+	}
+	notices := make([]OverdueNotice, 0)
+	for rows.Next() {
+		var n OverdueNotice
+		if err := rows.Scan(&n.TaskID, &n.Title, &n.DueAt); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan overdue task: %w", err)
+		}
+		n.DueAt = n.DueAt.UTC()
+		n.OverdueHours = math.Round(now.Sub(n.DueAt).Hours()*10) / 10
+		notices = append(notices, n)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range notices {
+		n := &notices[i]
+		meta, err := json.Marshal(map[string]any{
+			"due_at":        n.DueAt.Format(time.RFC3339),
+			"overdue_hours": n.OverdueHours,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode overdue metadata: %w", err)
+		}
+		n.EventID, err = InsertEventTx(tx, models.EventKindTaskOverdue, agentName, n.TaskID,
+			fmt.Sprintf("Task overdue: %s", n.Title), string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append overdue event: %w", err)
+		}
+		if _, err := tx.ExecContext(context.Background(),
+			`UPDATE tasks SET overdue_notified_at = CURRENT_TIMESTAMP WHERE id = ?`, n.TaskID); err != nil {
+			return nil, fmt.Errorf("failed to mark overdue notified: %w", err)
+		}
+	}
+	return notices, nil
+}
+
+// SweepOverdueIdempotent runs SweepOverdueTx once per (agent, request id).
+func SweepOverdueIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) ([]OverdueNotice, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "task.sweep", func(tx *sql.Tx) ([]OverdueNotice, error) {
+		return SweepOverdueTx(tx, agentName, projectID, time.Now())
+	})
+}
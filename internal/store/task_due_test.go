@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func setDue(t *testing.T, db *sql.DB, taskID string, due *time.Time) {
+	t.Helper()
+	err := Transact(context.Background(), db, func(tx *sql.Tx) error {
+		_, err := SetTaskDueTx(tx, "agent1", taskID, due)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestSetTaskDue_RoundTripAndClear(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Ship", "", "", 0)
+	require.NoError(t, err)
+
+	due := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	setDue(t, db, task.ID, &due)
+
+	got, err := GetTask(context.Background(), db, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.DueAt)
+	assert.True(t, due.Equal(*got.DueAt))
+	assert.Equal(t, task.Version+1, got.Version)
+	assert.True(t, got.IsOverdue(due.Add(time.Minute)))
+	assert.False(t, got.IsOverdue(due.Add(-time.Minute)))
+
+	setDue(t, db, task.ID, nil)
+	got, err = GetTask(context.Background(), db, task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.DueAt)
+
+	var n int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM events WHERE kind = ? AND task_id = ?`,
+		models.EventKindTaskDueSet, task.ID).Scan(&n))
+	assert.Equal(t, 2, n)
+}
+
+func TestListNextTasks_OverdueFirst(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	high, err := CreateTask(context.Background(), db, "High", "", "p1", 10)
+	require.NoError(t, err)
+	late, err := CreateTask(context.Background(), db, "Late", "", "p1", 0)
+	require.NoError(t, err)
+	soon, err := CreateTask(context.Background(), db, "Soon", "", "p1", 10)
+	require.NoError(t, err)
+	_, err = CreateTask(context.Background(), db, "Elsewhere", "", "p2", 0)
+	require.NoError(t, err)
+
+	past := now.Add(-48 * time.Hour)
+	future := now.Add(24 * time.Hour)
+	setDue(t, db, late.ID, &past)
+	setDue(t, db, soon.ID, &future)
+
+	next, err := ListNextTasks(context.Background(), db, "p1", now, 10)
+	require.NoError(t, err)
+	require.Len(t, next, 3)
+	assert.Equal(t, late.ID, next[0].ID, "overdue beats priority")
+	assert.Equal(t, soon.ID, next[1].ID, "a deadline breaks the priority tie")
+	assert.Equal(t, high.ID, next[2].ID)
+
+	overdue, err := ListOverdueTasks(context.Background(), db, "", now, 0)
+	require.NoError(t, err)
+	require.Len(t, overdue, 1)
+	assert.Equal(t, late.ID, overdue[0].ID)
+
+	result, err := DetermineFocusTaskWithPolicy(context.Background(), db, "agent1", "", nil, "p1", app.FocusDeadlineFirst)
+	require.NoError(t, err)
+	assert.Equal(t, late.ID, result.TaskID)
+	assert.Contains(t, result.Rule, "deadline-first")
+}
+
+func TestSweepOverdue_OncePerDeadline(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Late", "", "", 0)
+	require.NoError(t, err)
+	past := time.Now().Add(-3 * time.Hour)
+	setDue(t, db, task.ID, &past)
+
+	notices, err := SweepOverdueIdempotent(context.Background(), db, "sweeper", "sweep-1", "")
+	require.NoError(t, err)
+	require.Len(t, notices, 1)
+	assert.Equal(t, task.ID, notices[0].TaskID)
+	assert.InDelta(t, 3.0, notices[0].OverdueHours, 0.2)
+	assert.Positive(t, notices[0].EventID)
+
+	// Already reported: a second sweep is quiet.
+	notices, err = SweepOverdueIdempotent(context.Background(), db, "sweeper", "sweep-2", "")
+	require.NoError(t, err)
+	assert.Empty(t, notices)
+
+	// Moving the deadline re-arms the notification.
+	earlier := past.Add(-time.Hour)
+	setDue(t, db, task.ID, &earlier)
+	notices, err = SweepOverdueIdempotent(context.Background(), db, "sweeper", "sweep-3", "")
+	require.NoError(t, err)
+	require.Len(t, notices, 1)
+
+	brief, err := BuildBrief(context.Background(), db, "", "", "agent1")
+	require.NoError(t, err)
+	require.Len(t, brief.Overdue, 1)
+	assert.Equal(t, task.ID, brief.Overdue[0].ID)
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Error classes the loop records for tasks it blocks.
+const (
+	ErrorClassTimeout    = "timeout"    // the spawned command ran past --task-timeout
+	ErrorClassIncomplete = "incomplete" // the spawned command exited without completing the task
+)
+
+// maxFailureReasonLength bounds a failure reason; longer reasons are truncated.
+const maxFailureReasonLength = 2000
+
+// TaskFailure is one recorded failed attempt at a task.
+type TaskFailure struct {
+	ID         int64     `json:"id"`
+	TaskID     string    `json:"task_id"`
+	AgentName  string    `json:"agent_name"`
+	Reason     string    `json:"reason"`
+	ErrorClass string    `json:"error_class,omitempty"`
+	Attempt    int       `json:"attempt"`
+	EventID    int64     `json:"event_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NormalizeErrorClass lowercases an error class and checks it uses tag
+// characters, so classes group cleanly (e.g. test_failure, env:network).
+func NormalizeErrorClass(class string) (string, error) {
+	class = strings.ToLower(strings.TrimSpace(class))
+	if class == "" {
+		return "", nil
+	}
+	if len(class) > maxTaskTagLength {
+		return "", fmt.Errorf("error class exceeds %d characters: %s", maxTaskTagLength, class)
+	}
+	if !taskTagPattern.MatchString(class) {
+		return "", fmt.Errorf("invalid error class %q (use letters, digits, '.', '_', '-', ':', '/')", class)
+	}
+	return class, nil
+}
+
+// RecordTaskFailureTx counts a failed attempt at taskID, stores its reason and
+// error class in the failure history, and emits a task_failed event. It does
+// not change the task's status.
+func RecordTaskFailureTx(tx *sql.Tx, agentName, taskID, reason, errorClass string) (*TaskFailure, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("reason is required")
+	}
+	if runes := []rune(reason); len(runes) > maxFailureReasonLength {
+		reason = string(runes[:maxFailureReasonLength])
+	}
+	errorClass, err := NormalizeErrorClass(errorClass)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := RecordTaskAttemptTx(tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &TaskFailure{
+		TaskID:     taskID,
+		AgentName:  agentName,
+		Reason:     reason,
+		ErrorClass: errorClass,
+		Attempt:    attempt,
+		CreatedAt:  time.Now().UTC(),
+	}
+	meta, err := json.Marshal(map[string]any{
+		"reason":      reason,
+		"error_class": errorClass,
+		"attempt":     attempt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode failure metadata: %w", err)
+	}
+	msg := "Task failed: " + reason
+	if errorClass != "" {
+		msg = fmt.Sprintf("Task failed (%s): %s", errorClass, reason)
+	}
+	f.EventID, err = InsertEventTx(tx, models.EventKindTaskFailed, agentName, taskID, msg, string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append failure event: %w", err)
+	}
+
+	res, err := tx.ExecContext(context.Background(), `
+		INSERT INTO task_failures (task_id, agent_name, reason, error_class, attempt, event_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, taskID, agentName, reason, errorClass, attempt, f.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record task failure: %w", err)
+	}
+	if f.ID, err = res.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("failed to read failure id: %w", err)
+	}
+	return f, nil
+}
+
+// ListTaskFailures returns a task's failure history, newest first.
+func ListTaskFailures(ctx context.Context, db *sql.DB, taskID string, limit int) ([]TaskFailure, error) {
+	if taskID == "" {
+		return nil, errors.New("task ID is required")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var out []TaskFailure
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT id, task_id, agent_name, reason, error_class, attempt, COALESCE(event_id, 0), created_at
+			FROM task_failures
+			WHERE task_id = ?
+			ORDER BY id DESC LIMIT ?
+		`, taskID, limit)
+		if err != nil {
+			return fmt.Errorf("failed to query task failures: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = make([]TaskFailure, 0)
+		for rows.Next() {
+			var f TaskFailure
+			if err := rows.Scan(&f.ID, &f.TaskID, &f.AgentName, &f.Reason, &f.ErrorClass, &f.Attempt, &f.EventID, &f.CreatedAt); err != nil {
+				return fmt.Errorf("failed to scan task failure: %w", err)
+			}
+			out = append(out, f)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordTaskFailure_HistoryAndAttempts(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Deploy", "", "", 0)
+	require.NoError(t, err)
+
+	record := func(reason, class string) (*TaskFailure, error) {
+		return RunIdempotent(t.Context(), db, "agent1", "fail_"+reason, "task.fail", func(tx *sql.Tx) (*TaskFailure, error) {
+			return RecordTaskFailureTx(tx, "agent1", task.ID, reason, class)
+		})
+	}
+
+	first, err := record("tests red", " Test_Failure ")
+	require.NoError(t, err)
+	assert.Equal(t, "test_failure", first.ErrorClass)
+	assert.Equal(t, 1, first.Attempt)
+	assert.NotZero(t, first.EventID)
+
+	second, err := record("no network", "env:network")
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Attempt)
+
+	failures, err := ListTaskFailures(context.Background(), db, task.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, failures, 2)
+	assert.Equal(t, "no network", failures[0].Reason, "newest first")
+	assert.Equal(t, "env:network", failures[0].ErrorClass)
+	assert.Equal(t, "agent1", failures[1].AgentName)
+
+	got, err := GetTask(context.Background(), db, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Attempts)
+	assert.Equal(t, "pending", string(got.Status), "recording a failure leaves status to the caller")
+
+	_, err = record("bad class", "has space")
+	require.Error(t, err)
+	_, err = record("", "")
+	require.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// TaskGraphNode is one task in a dependency graph. External nodes belong to
+// another project but are referenced by an edge from the requested one.
+// HeldBy lists the agents whose focus is the task while it is in progress.
+type TaskGraphNode struct {
+	ID        string            `json:"id"`
+	Title     string            `json:"title"`
+	Status    models.TaskStatus `json:"status"`
+	Priority  int               `json:"priority"`
+	ProjectID string            `json:"project_id,omitempty"`
+	External  bool              `json:"external,omitempty"`
+	HeldBy    []string          `json:"held_by,omitempty"`
+}
+
+// TaskGraphEdge says TaskID depends on DependsOn (DependsOn must finish first).
+type TaskGraphEdge struct {
+	TaskID    string `json:"task_id"`
+	DependsOn string `json:"depends_on"`
+}
+
+// TaskGraph is a project's tasks and the dependency edges touching them.
+type TaskGraph struct {
+	ProjectID string          `json:"project_id,omitempty"`
+	Nodes     []TaskGraphNode `json:"nodes"`
+	Edges     []TaskGraphEdge `json:"edges"`
+}
+
+// LoadTaskGraph returns the tasks of projectID (every task when empty), the
+// dependency edges that touch them, and the agents holding in-progress tasks.
+// Nodes are ordered by creation, edges by (task_id, depends_on).
+func LoadTaskGraph(ctx context.Context, db *sql.DB, projectID string) (*TaskGraph, error) {
+	g := &TaskGraph{ProjectID: projectID}
+	err := RetryWithBackoff(ctx, func() error {
+		g.Nodes, g.Edges = nil, nil
+		return loadTaskGraph(ctx, db, projectID, g)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if g.Nodes == nil {
+		g.Nodes = []TaskGraphNode{}
+	}
+	if g.Edges == nil {
+		g.Edges = []TaskGraphEdge{}
+	}
+	return g, nil
+}
+
+func loadTaskGraph(ctx context.Context, db *sql.DB, projectID string, g *TaskGraph) (err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	nodeSQL := `SELECT id, title, status, priority, COALESCE(project_id, '') FROM tasks`
+	var args []any
+	if projectID != "" {
+		nodeSQL += ` WHERE ` + ProjectScopeClause
+		args = append(args, projectID)
+	}
+	nodeSQL += ` ORDER BY created_at ASC, id ASC`
+
+	index := map[string]int{}
+	if err := scanGraphNodes(ctx, db, nodeSQL, args, g, index, false); err != nil {
+		return err
+	}
+
+	edgeSQL := `SELECT task_id, depends_on_task_id FROM task_dependencies`
+	if projectID != "" {
+		edgeSQL += ` WHERE task_id IN (SELECT id FROM tasks WHERE project_id = ?)
+			OR depends_on_task_id IN (SELECT id FROM tasks WHERE project_id = ?)`
+		args = []any{projectID, projectID}
+	}
+	edgeSQL += ` ORDER BY task_id, depends_on_task_id`
+	rows, err := db.QueryContext(ctx, edgeSQL, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query dependencies: %w", err)
+	}
+	var external []any
+	for rows.Next() {
+		var e TaskGraphEdge
+		if err := rows.Scan(&e.TaskID, &e.DependsOn); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan dependency: %w", err)
+		}
+		g.Edges = append(g.Edges, e)
+		for _, id := range []string{e.TaskID, e.DependsOn} {
+			if _, ok := index[id]; !ok {
+				index[id] = -1
+				external = append(external, id)
+			}
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(external) > 0 {
+		extSQL := `SELECT id, title, status, priority, COALESCE(project_id, '') FROM tasks WHERE id IN (?` +
+			strings.Repeat(", ?", len(external)-1) + `) ORDER BY created_at ASC, id ASC`
+		if err := scanGraphNodes(ctx, db, extSQL, external, g, index, true); err != nil {
+			return err
+		}
+	}
+
+	return attachGraphHolders(ctx, db, g, index)
+}
+
+func scanGraphNodes(ctx context.Context, db *sql.DB, query string, args []any, g *TaskGraph, index map[string]int, external bool) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query graph tasks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var n TaskGraphNode
+		if err := rows.Scan(&n.ID, &n.Title, &n.Status, &n.Priority, &n.ProjectID); err != nil {
+			return fmt.Errorf("failed to scan graph task: %w", err)
+		}
+		n.External = external
+		index[n.ID] = len(g.Nodes)
+		g.Nodes = append(g.Nodes, n)
+	}
+	return rows.Err()
+}
+
+// attachGraphHolders fills HeldBy from agent-wide and per-session focus.
+func attachGraphHolders(ctx context.Context, db *sql.DB, g *TaskGraph, index map[string]int) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT agent_name, focus_task_id FROM agent_state WHERE focus_task_id IS NOT NULL
+		UNION
+		SELECT agent_name, focus_task_id FROM agent_session_state WHERE focus_task_id IS NOT NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query task holders: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var agent, taskID string
+		if err := rows.Scan(&agent, &taskID); err != nil {
+			return fmt.Errorf("failed to scan task holder: %w", err)
+		}
+		i, ok := index[taskID]
+		if !ok || i < 0 || g.Nodes[i].Status != models.TaskStatusInProgress {
+			continue
+		}
+		g.Nodes[i].HeldBy = append(g.Nodes[i].HeldBy, agent)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range g.Nodes {
+		sort.Strings(g.Nodes[i].HeldBy)
+	}
+	return nil
+}
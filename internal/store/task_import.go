@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// Task metadata keys written by ImportPlanIdempotent. Together they identify
+// the plan item a task came from; `task list --meta plan_hash=<hash>` lists an
+// import.
+const (
+	PlanHashMetaKey = "plan_hash"
+	PlanKeyMetaKey  = "plan_key"
+)
+
+// maxPlanItems bounds one import so a runaway generator cannot flood the queue.
+const maxPlanItems = 1000
+
+// PlanItem is one task in an imported plan. Key is unique within the plan;
+// DependsOn names the keys of items that must complete first.
+type PlanItem struct {
+	Key         string   `json:"key"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Priority    int      `json:"priority,omitempty"`
+	Done        bool     `json:"done,omitempty"`
+	DependsOn   []string `json:"depends_on,omitempty"`
+}
+
+// PlanImportTask maps a plan item to its task. Created is false when an
+// earlier import of the same plan already made it.
+type PlanImportTask struct {
+	Key     string `json:"key"`
+	TaskID  string `json:"task_id"`
+	Title   string `json:"title"`
+	Created bool   `json:"created"`
+}
+
+// PlanImportResult summarizes ImportPlanIdempotent.
+type PlanImportResult struct {
+	PlanHash     string           `json:"plan_hash"`
+	ProjectID    string           `json:"project_id,omitempty"`
+	Created      int              `json:"created"`
+	Existing     int              `json:"existing"`
+	Dependencies int              `json:"dependencies"`
+	Tasks        []PlanImportTask `json:"tasks"`
+}
+
+// ValidatePlan checks that items is non-empty and within maxPlanItems, that
+// every item has a title and a unique key, and that dependencies name known keys.
+func ValidatePlan(items []PlanItem) error {
+	if len(items) == 0 {
+		return errors.New("plan has no tasks")
+	}
+	if len(items) > maxPlanItems {
+		return fmt.Errorf("plan has %d tasks (max %d)", len(items), maxPlanItems)
+	}
+	keys := make(map[string]bool, len(items))
+	for _, it := range items {
+		if it.Key == "" {
+			return fmt.Errorf("plan task %q has no key", it.Title)
+		}
+		if it.Title == "" {
+			return fmt.Errorf("plan task %q has no title", it.Key)
+		}
+		if keys[it.Key] {
+			return fmt.Errorf("duplicate plan key %q", it.Key)
+		}
+		keys[it.Key] = true
+	}
+	for _, it := range items {
+		for _, dep := range it.DependsOn {
+			if !keys[dep] {
+				return fmt.Errorf("plan task %q depends on unknown key %q", it.Key, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// ImportPlanIdempotent creates a task per plan item in projectID, tagging each
+// with plan_hash and plan_key metadata, then adds the dependency edges (cycles
+// are rejected as in task add-dep). Items already imported under planHash in
+// the same project are reused rather than duplicated, so re-importing a plan
+// only fills in what is missing. Items marked Done are created completed.
+// Everything runs in one transaction, once per (agent_name, request_id).
+//
+//nolint:revive // argument-limit: agent, request, project, items, and hash are all required
+func ImportPlanIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, items []PlanItem, planHash string) (*PlanImportResult, error) {
+	if err := ValidatePlan(items); err != nil {
+		return nil, err
+	}
+	if planHash == "" {
+		return nil, errors.New("plan hash is required")
+	}
+
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "task.import", func(tx *sql.Tx) (PlanImportResult, error) {
+		return importPlanTx(tx, agentName, projectID, items, planHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func importPlanTx(tx *sql.Tx, agentName, projectID string, items []PlanItem, planHash string) (PlanImportResult, error) {
+	res := PlanImportResult{PlanHash: planHash, ProjectID: projectID, Tasks: make([]PlanImportTask, 0, len(items))}
+
+	existing, err := importedPlanTasksTx(tx, projectID, planHash)
+	if err != nil {
+		return res, err
+	}
+
+	ids := make(map[string]string, len(items))
+	for _, it := range items {
+		if id, ok := existing[it.Key]; ok {
+			ids[it.Key] = id
+			res.Existing++
+			res.Tasks = append(res.Tasks, PlanImportTask{Key: it.Key, TaskID: id, Title: it.Title})
+			continue
+		}
+
+		task, err := CreateTaskTx(tx, it.Title, it.Description, projectID, it.Priority)
+		if err != nil {
+			return res, err
+		}
+		if _, err := InsertEventTx(tx, models.EventKindTaskCreated, agentName, task.ID,
+			fmt.Sprintf("Task created: %s", it.Title), ""); err != nil {
+			return res, fmt.Errorf("failed to append event: %w", err)
+		}
+		for _, kv := range [][2]string{{PlanHashMetaKey, planHash}, {PlanKeyMetaKey, it.Key}} {
+			if _, _, err := SetTaskMetaTx(tx, agentName, task.ID, kv[0], kv[1], "string"); err != nil {
+				return res, err
+			}
+		}
+		if it.Done {
+			version, err := GetTaskVersionTx(tx, task.ID)
+			if err != nil {
+				return res, err
+			}
+			if _, err := UpdateTaskStatusWithEventTx(tx, agentName, task.ID, string(models.TaskStatusCompleted), version); err != nil {
+				return res, err
+			}
+		}
+		ids[it.Key] = task.ID
+		res.Created++
+		res.Tasks = append(res.Tasks, PlanImportTask{Key: it.Key, TaskID: task.ID, Title: it.Title, Created: true})
+	}
+
+	for _, it := range items {
+		for _, dep := range it.DependsOn {
+			added, err := AddTaskDependencyTx(tx, agentName, ids[it.Key], ids[dep])
+			if err != nil {
+				return res, fmt.Errorf("plan task %q -> %q: %w", it.Key, dep, err)
+			}
+			if added.Added {
+				res.Dependencies++
+			}
+		}
+	}
+	return res, nil
+}
+
+// importedPlanTasksTx maps plan_key to task ID for tasks an earlier import of
+// planHash created in projectID.
+func importedPlanTasksTx(tx *sql.Tx, projectID, planHash string) (map[string]string, error) {
+	rows, err := tx.QueryContext(context.Background(), `
+		SELECT k.value, h.task_id
+		FROM task_metadata h
+		JOIN task_metadata k ON k.task_id = h.task_id AND k.key = ?
+		JOIN tasks t ON t.id = h.task_id
+		WHERE h.key = ? AND h.value = ? AND COALESCE(t.project_id, '') = ?
+		ORDER BY t.created_at ASC
+	`, PlanKeyMetaKey, PlanHashMetaKey, planHash, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query imported tasks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	out := map[string]string{}
+	for rows.Next() {
+		var key, taskID string
+		if err := rows.Scan(&key, &taskID); err != nil {
+			return nil, fmt.Errorf("failed to scan imported task: %w", err)
+		}
+		if _, dup := out[key]; !dup {
+			out[key] = taskID
+		}
+	}
+	return out, rows.Err()
+}
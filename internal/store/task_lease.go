@@ -0,0 +1,327 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// TaskLease is a claim's hold on an in_progress task. ExpiresAt is as of the
+// claim; activity on the task pushes the expiry out (see leaseExpiryExpr).
+type TaskLease struct {
+	TaskID    string    `json:"task_id"`
+	Owner     string    `json:"owner"`
+	Seconds   int64     `json:"seconds"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ExpiredLease is an in_progress task whose claim lease ran out.
+type ExpiredLease struct {
+	TaskID    string    `json:"task_id"`
+	Title     string    `json:"title"`
+	Owner     string    `json:"owner"`
+	Seconds   int64     `json:"lease_seconds"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+// AgentLeaseSetting is an agent's default claim lease. Seconds is 0 when the
+// agent has none and claims fall back to claim_lease.
+type AgentLeaseSetting struct {
+	AgentName string `json:"agent_name"`
+	Seconds   int64  `json:"lease_seconds"`
+	EventID   int64  `json:"event_id,omitempty"`
+}
+
+// leaseExpiryExpr is when the lease on a task runs out: lease_seconds after the
+// later of the claim and the task's latest activity (its last update or event,
+// stale and lease-expiry notices aside). Binds models.EventKindTaskStale and
+// models.EventKindTaskLeaseExpired.
+const leaseExpiryExpr = `MAX(tasks.lease_expires_at, datetime(MAX(tasks.updated_at, COALESCE(
+	(SELECT MAX(e.created_at) FROM events e WHERE e.task_id = tasks.id AND e.kind NOT IN (?, ?)),
+	tasks.updated_at)), '+' || tasks.lease_seconds || ' seconds'))`
+
+// resolveClaimLeaseTx picks the lease for agentName's claim of taskID: lease
+// when positive, else the task's default, else the agent's, else claim_lease.
+// Zero means the claim is unleased.
+func resolveClaimLeaseTx(tx *sql.Tx, agentName, taskID string, lease time.Duration) (time.Duration, error) {
+	if lease > 0 {
+		return lease, nil
+	}
+	var secs sql.NullInt64
+	if err := tx.QueryRowContext(context.Background(),
+		`SELECT default_lease_seconds FROM tasks WHERE id = ?`, taskID).Scan(&secs); err != nil {
+		return 0, fmt.Errorf("failed to load task lease: %w", err)
+	}
+	if secs.Valid && secs.Int64 > 0 {
+		return time.Duration(secs.Int64) * time.Second, nil
+	}
+	err := tx.QueryRowContext(context.Background(),
+		`SELECT default_lease_seconds FROM agent_state WHERE agent_name = ?`, agentName).Scan(&secs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to load agent lease: %w", err)
+	}
+	if secs.Valid && secs.Int64 > 0 {
+		return time.Duration(secs.Int64) * time.Second, nil
+	}
+	return app.ClaimLeaseTTL(), nil
+}
+
+// setTaskLeaseTx records owner's lease of ttl on taskID, starting now.
+func setTaskLeaseTx(tx *sql.Tx, owner, taskID string, ttl time.Duration) (*TaskLease, error) {
+	lease := &TaskLease{
+		TaskID:    taskID,
+		Owner:     owner,
+		Seconds:   int64(ttl / time.Second),
+		ExpiresAt: time.Now().UTC().Truncate(time.Second).Add(ttl),
+	}
+	if _, err := tx.ExecContext(context.Background(), `
+		UPDATE tasks SET lease_owner = ?, lease_seconds = ?, lease_expires_at = ? WHERE id = ?
+	`, owner, lease.Seconds, lease.ExpiresAt.Format(time.DateTime), taskID); err != nil {
+		return nil, fmt.Errorf("failed to record task lease: %w", err)
+	}
+	return lease, nil
+}
+
+// expiredLeasesTx returns up to limit leased in_progress tasks whose lease ran
+// out by now, longest expired first. A non-empty projectID (or scoped) and
+// filter narrow the tasks as they narrow a claim.
+func expiredLeasesTx(tx *sql.Tx, now time.Time, limit int, projectID string, scoped bool, filter FocusFilter) ([]ExpiredLease, error) {
+	args := []any{models.EventKindTaskStale, models.EventKindTaskLeaseExpired}
+	scope := ""
+	if scoped {
+		scope = ` AND COALESCE(tasks.project_id, '') = ?`
+		args = append(args, projectID)
+	}
+	cond, condArgs := filter.clause()
+	args = append(append(args, condArgs...), now.UTC().Format(time.DateTime), limit)
+
+	rows, err := tx.QueryContext(context.Background(), effectivePriorityCTE+`
+		SELECT id, title, lease_owner, lease_seconds, strftime('%Y-%m-%dT%H:%M:%SZ', expires_at) FROM (
+			SELECT tasks.id, tasks.title, COALESCE(tasks.lease_owner, '') AS lease_owner, tasks.lease_seconds,
+				`+leaseExpiryExpr+` AS expires_at
+			FROM tasks`+effectivePriorityJoin+`
+			WHERE tasks.status = 'in_progress' AND tasks.lease_seconds IS NOT NULL AND `+activeProjectTaskClause+scope+cond+`
+		)
+		WHERE expires_at <= ?
+		ORDER BY expires_at ASC, id ASC
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired leases: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]ExpiredLease, 0)
+	for rows.Next() {
+		var l ExpiredLease
+		var expiredAt string
+		if err := rows.Scan(&l.TaskID, &l.Title, &l.Owner, &l.Seconds, &expiredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan expired lease: %w", err)
+		}
+		l.ExpiredAt, _ = time.Parse(time.RFC3339, expiredAt)
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// ReclaimExpiredLeasesTx returns up to limit tasks whose claim lease ran out by
+// now to pending, as agentName. Each loses every focus on it, its holders get
+// an inbox notice, and a task_lease_expired event names the prior owner.
+func ReclaimExpiredLeasesTx(tx *sql.Tx, agentName string, now time.Time, limit int) ([]ExpiredLease, error) {
+	expired, err := expiredLeasesTx(tx, now, limit, "", false, FocusFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range expired {
+		if err := releaseTaskFocusTx(tx, l.TaskID, "its claim lease expired"); err != nil {
+			return nil, err
+		}
+		version, err := GetTaskVersionTx(tx, l.TaskID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := UpdateTaskStatusWithEventTx(tx, agentName, l.TaskID, string(models.TaskStatusPending), version); err != nil {
+			return nil, fmt.Errorf("failed to requeue task %s: %w", l.TaskID, err)
+		}
+		meta, _ := json.Marshal(map[string]any{
+			"owner":         l.Owner,
+			"lease_seconds": l.Seconds,
+			"expired_at":    l.ExpiredAt.Format(time.RFC3339),
+		})
+		if _, err := InsertEventTx(tx, models.EventKindTaskLeaseExpired, agentName, l.TaskID,
+			fmt.Sprintf("Lease expired: %s (held by %s)", l.Title, l.Owner), string(meta)); err != nil {
+			return nil, fmt.Errorf("failed to append lease expired event: %w", err)
+		}
+	}
+	return expired, nil
+}
+
+// stealTaskTx hands the expired lease l to agentName without leaving
+// in_progress: the prior holders lose their focus (with an inbox notice),
+// agentName takes focus, and a task_stolen event names the prior owner. The
+// expired lease is cleared (the claim then leases the task to agentName when a
+// lease applies), and the task's version is bumped so a concurrent steal of
+// the same task conflicts.
+func stealTaskTx(tx *sql.Tx, agentName string, l ExpiredLease) (focusEventID, stolenEventID int64, err error) {
+	version, err := GetTaskVersionTx(tx, l.TaskID)
+	if err != nil {
+		return 0, 0, err
+	}
+	res, err := tx.ExecContext(context.Background(), `
+		UPDATE tasks
+		SET lease_owner = NULL, lease_seconds = NULL, lease_expires_at = NULL,
+		    version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND version = ? AND status = 'in_progress'
+	`, l.TaskID, version)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to take over task: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, 0, fmt.Errorf("failed to check rows affected: %w", err)
+	} else if n == 0 {
+		return 0, 0, &VersionConflictError{Entity: "task", ID: l.TaskID, Version: version}
+	}
+
+	if err := releaseTaskFocusTx(tx, l.TaskID, "its lease expired and "+agentName+" took it over"); err != nil {
+		return 0, 0, err
+	}
+	focusEventID, err = setAgentFocusTx(tx, agentName, l.TaskID)
+	if err != nil {
+		return 0, 0, err
+	}
+	meta, _ := json.Marshal(map[string]any{
+		"prior_owner":   l.Owner,
+		"lease_seconds": l.Seconds,
+		"expired_at":    l.ExpiredAt.Format(time.RFC3339),
+	})
+	stolenEventID, err = InsertEventTx(tx, models.EventKindTaskStolen, agentName, l.TaskID,
+		fmt.Sprintf("Task stolen from %s: %s (lease expired)", l.Owner, l.Title), string(meta))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to append task stolen event: %w", err)
+	}
+	return focusEventID, stolenEventID, nil
+}
+
+// releaseTaskFocusTx drops every agent and session focus on taskID, leaving
+// each holder an inbox notice naming reason.
+func releaseTaskFocusTx(tx *sql.Tx, taskID, reason string) error {
+	holders, err := focusHoldersTx(tx, `t.id = ?`, taskID)
+	if err != nil {
+		return err
+	}
+	for _, table := range []string{"agent_state", "agent_session_state"} {
+		if _, err := tx.ExecContext(context.Background(),
+			`UPDATE `+table+` SET focus_task_id = NULL WHERE focus_task_id = ?`, taskID); err != nil {
+			return fmt.Errorf("failed to clear %s task focus: %w", table, err)
+		}
+	}
+	return notifyFocusLostTx(tx, holders, reason)
+}
+
+// GetTaskLease returns the lease on taskID, or nil when it has none.
+func GetTaskLease(ctx context.Context, db *sql.DB, taskID string) (*TaskLease, error) {
+	var lease *TaskLease
+	err := RetryWithBackoff(ctx, func() error {
+		var owner sql.NullString
+		var secs sql.NullInt64
+		var expiresAt sql.NullString
+		err := db.QueryRowContext(ctx,
+			`SELECT lease_owner, lease_seconds, strftime('%Y-%m-%dT%H:%M:%SZ', lease_expires_at) FROM tasks WHERE id = ?`, taskID).
+			Scan(&owner, &secs, &expiresAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("task not found: %s", taskID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query task lease: %w", err)
+		}
+		lease = nil
+		if secs.Valid {
+			lease = &TaskLease{TaskID: taskID, Owner: owner.String, Seconds: secs.Int64}
+			lease.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt.String)
+		}
+		return nil
+	})
+	return lease, err
+}
+
+// SetTaskDefaultLeaseTx sets the lease claims of taskID take regardless of the
+// claiming agent's default; ttl 0 clears it.
+func SetTaskDefaultLeaseTx(tx *sql.Tx, agentName, taskID string, ttl time.Duration) (int64, error) {
+	if ttl < 0 {
+		return 0, errors.New("lease must not be negative")
+	}
+	version, err := GetTaskVersionTx(tx, taskID)
+	if err != nil {
+		return 0, err
+	}
+
+	var secs any
+	message := "Default lease cleared"
+	if ttl > 0 {
+		secs = int64(ttl / time.Second)
+		message = "Default lease set: " + ttl.String()
+	}
+	return casUpdateTaskWithEvent(tx, agentName, taskID, version,
+		`UPDATE tasks
+		SET default_lease_seconds = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND version = ?`,
+		[]any{secs, taskID, version},
+		models.EventKindTaskLeaseSet,
+		message,
+	)
+}
+
+// SetAgentDefaultLeaseIdempotent sets the lease agentName's claims take when
+// neither the claim nor the task sets one, once per (agent_name, request_id);
+// ttl 0 clears it.
+func SetAgentDefaultLeaseIdempotent(ctx context.Context, db *sql.DB, agentName, requestID string, ttl time.Duration) (*AgentLeaseSetting, error) {
+	if ttl < 0 {
+		return nil, errors.New("lease must not be negative")
+	}
+	return RunIdempotent(ctx, db, agentName, requestID, "agent.lease", func(tx *sql.Tx) (*AgentLeaseSetting, error) {
+		if err := ensureAgentStateTx(tx, agentName); err != nil {
+			return nil, err
+		}
+		setting := &AgentLeaseSetting{AgentName: agentName, Seconds: int64(ttl / time.Second)}
+		var secs any
+		message := "Agent default lease cleared"
+		if setting.Seconds > 0 {
+			secs = setting.Seconds
+			message = "Agent default lease set: " + ttl.String()
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE agent_state SET default_lease_seconds = ? WHERE agent_name = ?`, secs, agentName); err != nil {
+			return nil, fmt.Errorf("failed to set agent lease: %w", err)
+		}
+		meta, _ := json.Marshal(map[string]any{"lease_seconds": setting.Seconds})
+		eventID, err := InsertEventTx(tx, models.EventKindAgentLeaseSet, agentName, "", message, string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append agent lease event: %w", err)
+		}
+		setting.EventID = eventID
+		return setting, nil
+	})
+}
+
+// GetAgentDefaultLease returns agentName's default claim lease, 0 when unset.
+func GetAgentDefaultLease(ctx context.Context, db *sql.DB, agentName string) (time.Duration, error) {
+	var secs sql.NullInt64
+	err := RetryWithBackoff(ctx, func() error {
+		err := db.QueryRowContext(ctx,
+			`SELECT default_lease_seconds FROM agent_state WHERE agent_name = ?`, agentName).Scan(&secs)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query agent lease: %w", err)
+	}
+	return time.Duration(secs.Int64) * time.Second, nil
+}
@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/app"
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestClaimLeasePrecedence(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	long, err := CreateTask(context.Background(), db, "Long job", "", "", 9)
+	require.NoError(t, err)
+	plain, err := CreateTask(context.Background(), db, "Plain", "", "", 5)
+	require.NoError(t, err)
+	other, err := CreateTask(context.Background(), db, "Other", "", "", 1)
+	require.NoError(t, err)
+
+	require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
+		_, err := SetTaskDefaultLeaseTx(tx, "ops", long.ID, 6*time.Hour)
+		return err
+	}))
+	_, err = SetAgentDefaultLeaseIdempotent(context.Background(), db, "agent1", "lease_1", 45*time.Minute)
+	require.NoError(t, err)
+
+	r, err := ClaimNextTaskIdempotent(context.Background(), db, "agent1", "claim_1", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{})
+	require.NoError(t, err)
+	require.Equal(t, long.ID, r.TaskID)
+	require.NotNil(t, r.Lease)
+	assert.Equal(t, int64(6*3600), r.Lease.Seconds, "the task's lease beats the agent's")
+	assert.Equal(t, "agent1", r.Lease.Owner)
+
+	r, err = ClaimNextTaskIdempotent(context.Background(), db, "agent1", "claim_2", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{})
+	require.NoError(t, err)
+	require.Equal(t, plain.ID, r.TaskID)
+	assert.Equal(t, int64(45*60), r.Lease.Seconds, "the agent's lease applies without a task lease")
+
+	r, err = ClaimNextTaskIdempotent(context.Background(), db, "agent2", "claim_3", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{Lease: 10 * time.Minute})
+	require.NoError(t, err)
+	require.Equal(t, other.ID, r.TaskID)
+	assert.Equal(t, int64(600), r.Lease.Seconds, "an explicit lease wins")
+
+	stored, err := GetTaskLease(context.Background(), db, other.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, r.Lease.ExpiresAt.Unix(), stored.ExpiresAt.Unix())
+
+	// Leaving in_progress drops the lease.
+	require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
+		version, err := GetTaskVersionTx(tx, other.ID)
+		if err != nil {
+			return err
+		}
+		_, err = UpdateTaskStatusWithEventTx(tx, "agent2", other.ID, string(models.TaskStatusCompleted), version)
+		return err
+	}))
+	stored, err = GetTaskLease(context.Background(), db, other.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestReclaimExpiredLeases(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	idle, err := CreateTask(context.Background(), db, "Idle", "", "", 9)
+	require.NoError(t, err)
+	busy, err := CreateTask(context.Background(), db, "Busy", "", "", 5)
+	require.NoError(t, err)
+
+	_, err = ClaimNextTaskIdempotent(context.Background(), db, "agent1", "claim_1", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{Lease: 30 * time.Minute})
+	require.NoError(t, err)
+	_, err = ClaimNextTaskIdempotent(context.Background(), db, "agent2", "claim_2", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{Lease: 30 * time.Minute})
+	require.NoError(t, err)
+
+	// Activity on busy 25 minutes in renews its lease past the check below.
+	_, err = db.Exec(`INSERT INTO events (kind, agent_name, task_id, message, created_at)
+		VALUES ('progress', 'agent2', ?, 'still going', datetime('now', '+25 minutes'))`, busy.ID)
+	require.NoError(t, err)
+
+	var reclaimed []ExpiredLease
+	require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
+		reclaimed, err = ReclaimExpiredLeasesTx(tx, "gc", time.Now().Add(40*time.Minute), 10)
+		return err
+	}))
+	require.Len(t, reclaimed, 1)
+	assert.Equal(t, idle.ID, reclaimed[0].TaskID)
+	assert.Equal(t, "agent1", reclaimed[0].Owner)
+	assert.Equal(t, int64(1800), reclaimed[0].Seconds)
+
+	got, err := GetTask(context.Background(), db, idle.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusPending, got.Status)
+	state, err := LoadOrCreateAgentState(context.Background(), db, "agent1")
+	require.NoError(t, err)
+	assert.Empty(t, state.FocusTaskID, "the owner loses its focus")
+
+	var kind, owner string
+	require.NoError(t, db.QueryRow(`SELECT kind, json_extract(metadata, '$.owner') FROM events
+		WHERE task_id = ? ORDER BY id DESC LIMIT 1`, idle.ID).Scan(&kind, &owner))
+	assert.Equal(t, models.EventKindTaskLeaseExpired, kind)
+	assert.Equal(t, "agent1", owner)
+
+	got, err = GetTask(context.Background(), db, busy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusInProgress, got.Status)
+
+	// The GC reclaims through the same path.
+	r, err := GCWithEventIdempotent(context.Background(), db, "gc", "gc_1", GCOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, r.LeasesReclaimed, "nothing has expired as of now")
+}
+
+func TestClaimStealStale(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Crashed work", "", "proj-a", 5)
+	require.NoError(t, err)
+	_, err = ClaimNextTaskIdempotent(context.Background(), db, "agent1", "claim_1", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{Lease: 10 * time.Minute})
+	require.NoError(t, err)
+
+	r, err := ClaimNextTaskIdempotent(context.Background(), db, "agent2", "claim_2", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{StealStale: true})
+	require.NoError(t, err)
+	assert.Empty(t, r.TaskID, "a live lease is not stolen")
+
+	_, err = db.Exec(`UPDATE tasks SET lease_expires_at = datetime('now', '-1 hour'), updated_at = datetime('now', '-1 hour') WHERE id = ?`, task.ID)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE events SET created_at = datetime('now', '-1 hour') WHERE task_id = ?`, task.ID)
+	require.NoError(t, err)
+
+	r, err = ClaimNextTaskIdempotent(context.Background(), db, "agent2", "claim_3", "proj-b", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{StealStale: true})
+	require.NoError(t, err)
+	assert.Empty(t, r.TaskID, "stealing honors the project filter")
+
+	r, err = ClaimNextTaskIdempotent(context.Background(), db, "agent2", "claim_4", "proj-a", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{StealStale: true, Lease: 20 * time.Minute})
+	require.NoError(t, err)
+	require.Equal(t, task.ID, r.TaskID)
+	assert.Equal(t, "agent1", r.StolenFrom)
+	assert.NotZero(t, r.StolenEventID)
+	require.NotNil(t, r.Lease)
+	assert.Equal(t, "agent2", r.Lease.Owner)
+	assert.Equal(t, int64(1200), r.Lease.Seconds)
+
+	got, err := GetTask(context.Background(), db, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusInProgress, got.Status)
+	prior, err := LoadOrCreateAgentState(context.Background(), db, "agent1")
+	require.NoError(t, err)
+	assert.Empty(t, prior.FocusTaskID)
+	thief, err := LoadOrCreateAgentState(context.Background(), db, "agent2")
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, thief.FocusTaskID)
+
+	var kind, priorOwner string
+	require.NoError(t, db.QueryRow(`SELECT kind, json_extract(metadata, '$.prior_owner') FROM events WHERE id = ?`,
+		r.StolenEventID).Scan(&kind, &priorOwner))
+	assert.Equal(t, models.EventKindTaskStolen, kind)
+	assert.Equal(t, "agent1", priorOwner)
+}
+
+// With claim_lease off the thief takes no lease, so the prior owner's expired
+// one must not survive the steal.
+func TestClaimStealStaleWithLeasesOff(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".config", "vybe")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("claim_lease: \"off\"\n"), 0o600))
+	_, err := app.ReloadSettings()
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = app.ReloadSettings() })
+	require.Zero(t, app.ClaimLeaseTTL())
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Crashed work", "", "", 5)
+	require.NoError(t, err)
+	_, err = ClaimNextTaskIdempotent(context.Background(), db, "agent1", "claim_1", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{Lease: 10 * time.Minute})
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE tasks SET lease_expires_at = datetime('now', '-1 hour'), updated_at = datetime('now', '-1 hour') WHERE id = ?`, task.ID)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE events SET created_at = datetime('now', '-1 hour') WHERE task_id = ?`, task.ID)
+	require.NoError(t, err)
+
+	r, err := ClaimNextTaskIdempotent(context.Background(), db, "agent2", "claim_2", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{StealStale: true})
+	require.NoError(t, err)
+	require.Equal(t, task.ID, r.TaskID)
+	assert.Nil(t, r.Lease)
+
+	var owner, expires sql.NullString
+	var seconds sql.NullInt64
+	require.NoError(t, db.QueryRow(`SELECT lease_owner, lease_seconds, lease_expires_at FROM tasks WHERE id = ?`, task.ID).
+		Scan(&owner, &seconds, &expires))
+	assert.False(t, owner.Valid, "the prior owner's lease is cleared")
+	assert.False(t, seconds.Valid)
+	assert.False(t, expires.Valid)
+
+	r, err = ClaimNextTaskIdempotent(context.Background(), db, "agent3", "claim_3", "", app.FocusPriorityFirst, FocusFilter{}, ClaimOptions{StealStale: true})
+	require.NoError(t, err)
+	assert.Empty(t, r.TaskID, "a stolen task cannot be stolen again at once")
+
+	gc, err := GCWithEventIdempotent(context.Background(), db, "gc", "gc_1", GCOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, gc.LeasesReclaimed, "gc does not requeue the stolen task")
+}
@@ -0,0 +1,313 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+const (
+	maxTaskMetaKeyLength   = 64
+	maxTaskMetaValueLength = 4096
+)
+
+// taskMetaKeyPattern keeps keys usable as --meta key=value filters and JSON object keys.
+var taskMetaKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+func validateTaskMetaKey(key string) error {
+	if key == "" {
+		return errors.New("metadata key is required")
+	}
+	if len(key) > maxTaskMetaKeyLength {
+		return fmt.Errorf("metadata key exceeds %d characters", maxTaskMetaKeyLength)
+	}
+	if !taskMetaKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid metadata key %q (use letters, digits, '.', '_', '-')", key)
+	}
+	return nil
+}
+
+// normalizeTaskMetaValue infers or validates the value type and checks that the
+// value parses as that type, so reads can decode it without guessing.
+func normalizeTaskMetaValue(value, valueType string) (string, string, error) {
+	if len(value) > maxTaskMetaValueLength {
+		return "", "", fmt.Errorf("metadata value exceeds %d characters", maxTaskMetaValueLength)
+	}
+	if err := validateValueType(valueType); err != nil {
+		return "", "", err
+	}
+	if valueType == "" {
+		valueType = inferValueType(value)
+	}
+	if valueType != "string" {
+		value = strings.TrimSpace(value)
+		if _, err := decodeTaskMetaValue(value, valueType); err != nil {
+			return "", "", fmt.Errorf("value %q is not a valid %s: %w", value, valueType, err)
+		}
+	}
+	return value, valueType, nil
+}
+
+// decodeTaskMetaValue converts a stored metadata value into its typed Go form.
+func decodeTaskMetaValue(value, valueType string) (any, error) {
+	switch valueType {
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "json", "array":
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// SetTaskMetaTx upserts one metadata entry on a task and emits a task_meta_set event.
+// An empty valueType infers the type from the value.
+//
+//nolint:revive // argument-limit: agent, task, key, value, and type are all required
+func SetTaskMetaTx(tx *sql.Tx, agentName, taskID, key, value, valueType string) (*models.TaskMeta, int64, error) {
+	key = strings.TrimSpace(key)
+	if err := validateTaskMetaKey(key); err != nil {
+		return nil, 0, err
+	}
+	value, valueType, err := normalizeTaskMetaValue(value, valueType)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := GetTaskVersionTx(tx, taskID); err != nil {
+		return nil, 0, err
+	}
+
+	_, err = tx.ExecContext(context.Background(), `
+		INSERT INTO task_metadata (task_id, key, value, value_type, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(task_id, key) DO UPDATE SET
+			value = excluded.value,
+			value_type = excluded.value_type,
+			updated_by = excluded.updated_by,
+			updated_at = CURRENT_TIMESTAMP
+	`, taskID, key, value, valueType, agentName)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to upsert task metadata: %w", err)
+	}
+
+	meta, _ := json.Marshal(map[string]any{"key": key, "value": value, "value_type": valueType})
+	eventID, err := InsertEventTx(tx, models.EventKindTaskMetaSet, agentName, taskID,
+		fmt.Sprintf("Task metadata set: %s=%s", key, truncateRunes(value, 80)), string(meta))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to append task metadata event: %w", err)
+	}
+
+	m := &models.TaskMeta{TaskID: taskID, Key: key}
+	err = tx.QueryRowContext(context.Background(), `
+		SELECT value, value_type, updated_by, updated_at FROM task_metadata WHERE task_id = ? AND key = ?
+	`, taskID, key).Scan(&m.Value, &m.ValueType, &m.UpdatedBy, &m.UpdatedAt)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load task metadata: %w", err)
+	}
+	return m, eventID, nil
+}
+
+// UnsetTaskMetaTx removes one metadata entry. Returns eventID 0 when the key was absent.
+func UnsetTaskMetaTx(tx *sql.Tx, agentName, taskID, key string) (int64, error) {
+	key = strings.TrimSpace(key)
+	if err := validateTaskMetaKey(key); err != nil {
+		return 0, err
+	}
+	if _, err := GetTaskVersionTx(tx, taskID); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(context.Background(),
+		`DELETE FROM task_metadata WHERE task_id = ? AND key = ?`, taskID, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete task metadata: %w", err)
+	}
+	ra, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if ra == 0 {
+		return 0, nil
+	}
+
+	meta, _ := json.Marshal(map[string]any{"key": key})
+	eventID, err := InsertEventTx(tx, models.EventKindTaskMetaUnset, agentName, taskID,
+		fmt.Sprintf("Task metadata unset: %s", key), string(meta))
+	if err != nil {
+		return 0, fmt.Errorf("failed to append task metadata event: %w", err)
+	}
+	return eventID, nil
+}
+
+// SetTaskMetaIdempotent performs SetTaskMetaTx once per (agent_name, request_id).
+//
+//nolint:revive // argument-limit: agent, request, task, key, value, and type are all required
+func SetTaskMetaIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, key, value, valueType string) (*models.TaskMeta, int64, error) {
+	type idemResult struct {
+		Meta    *models.TaskMeta `json:"meta"`
+		EventID int64            `json:"event_id"`
+	}
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "task.meta_set", func(tx *sql.Tx) (idemResult, error) {
+		m, eid, txErr := SetTaskMetaTx(tx, agentName, taskID, key, value, valueType)
+		if txErr != nil {
+			return idemResult{}, txErr
+		}
+		return idemResult{Meta: m, EventID: eid}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.Meta, r.EventID, nil
+}
+
+// UnsetTaskMetaIdempotent performs UnsetTaskMetaTx once per (agent_name, request_id).
+func UnsetTaskMetaIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID, key string) (int64, error) {
+	type idemResult struct {
+		EventID int64 `json:"event_id"`
+	}
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "task.meta_unset", func(tx *sql.Tx) (idemResult, error) {
+		eid, txErr := UnsetTaskMetaTx(tx, agentName, taskID, key)
+		if txErr != nil {
+			return idemResult{}, txErr
+		}
+		return idemResult{EventID: eid}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return r.EventID, nil
+}
+
+// ListTaskMeta returns a task's metadata entries ordered by key.
+func ListTaskMeta(ctx context.Context, db *sql.DB, taskID string) ([]models.TaskMeta, error) {
+	var out []models.TaskMeta
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT task_id, key, value, value_type, updated_by, updated_at
+			FROM task_metadata WHERE task_id = ? ORDER BY key
+		`, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to query task metadata: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = make([]models.TaskMeta, 0)
+		for rows.Next() {
+			var m models.TaskMeta
+			if err := rows.Scan(&m.TaskID, &m.Key, &m.Value, &m.ValueType, &m.UpdatedBy, &m.UpdatedAt); err != nil {
+				return fmt.Errorf("failed to scan task metadata: %w", err)
+			}
+			out = append(out, m)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TaskMetaMap decodes entries into a key -> typed value map for JSON output.
+// Values that no longer decode (e.g. rows written by hand) fall back to the raw string.
+func TaskMetaMap(entries []models.TaskMeta) map[string]any {
+	if len(entries) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(entries))
+	for _, e := range entries {
+		v, err := decodeTaskMetaValue(e.Value, e.ValueType)
+		if err != nil {
+			v = e.Value
+		}
+		out[e.Key] = v
+	}
+	return out
+}
+
+// ParseTaskMetaFilters parses "key=value" pairs into a filter map.
+func ParseTaskMetaFilters(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		key, value, ok := strings.Cut(p, "=")
+		key = strings.TrimSpace(key)
+		if !ok {
+			return nil, fmt.Errorf("invalid metadata filter %q (want key=value)", p)
+		}
+		if err := validateTaskMetaKey(key); err != nil {
+			return nil, err
+		}
+		out[key] = strings.TrimSpace(value)
+	}
+	return out, nil
+}
+
+// FilterTasksByMeta keeps tasks whose metadata matches every key=value filter.
+// Values compare against the stored text form, so reviewer=alice and attempts=3 both work.
+func FilterTasksByMeta(ctx context.Context, db *sql.DB, tasks []*models.Task, filters map[string]string) ([]*models.Task, error) {
+	if len(filters) == 0 || len(tasks) == 0 {
+		return tasks, nil
+	}
+
+	keys := make([]string, 0, len(filters))
+	for k := range filters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	clauses := make([]string, len(keys))
+	args := make([]any, 0, len(keys)*2+1)
+	for i, k := range keys {
+		clauses[i] = "(key = ? AND value = ?)"
+		args = append(args, k, filters[k])
+	}
+	args = append(args, len(keys))
+	//nolint:gosec // G202: clauses are fixed placeholders; values are bound
+	query := `SELECT task_id FROM task_metadata WHERE ` + strings.Join(clauses, " OR ") +
+		` GROUP BY task_id HAVING COUNT(*) = ?`
+
+	matched := map[string]bool{}
+	err := RetryWithBackoff(ctx, func() error {
+		clear(matched)
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query task metadata: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return fmt.Errorf("failed to scan task metadata: %w", err)
+			}
+			matched[id] = true
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*models.Task, 0, len(matched))
+	for _, t := range tasks {
+		if matched[t.ID] {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskMeta_SetTypedValuesAndUnset(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "with metadata", "", "", 0)
+	require.NoError(t, err)
+
+	m, eid, err := SetTaskMetaIdempotent(context.Background(), db, "agent1", "meta_set_1", task.ID, "reviewer", "alice", "")
+	require.NoError(t, err)
+	assert.Positive(t, eid)
+	assert.Equal(t, "string", m.ValueType)
+	assert.Equal(t, "agent1", m.UpdatedBy)
+
+	_, _, err = SetTaskMetaIdempotent(context.Background(), db, "agent1", "meta_set_2", task.ID, "attempts", "3", "")
+	require.NoError(t, err)
+	_, _, err = SetTaskMetaIdempotent(context.Background(), db, "agent1", "meta_set_3", task.ID, "labels", `["api","db"]`, "")
+	require.NoError(t, err)
+
+	_, _, err = SetTaskMetaIdempotent(context.Background(), db, "agent1", "meta_set_bad", task.ID, "ready", "maybe", "boolean")
+	require.Error(t, err)
+	_, _, err = SetTaskMetaIdempotent(context.Background(), db, "agent1", "meta_set_badkey", task.ID, "has space", "x", "")
+	require.Error(t, err)
+
+	entries, err := ListTaskMeta(context.Background(), db, task.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	decoded := TaskMetaMap(entries)
+	assert.Equal(t, "alice", decoded["reviewer"])
+	assert.InDelta(t, 3.0, decoded["attempts"], 0)
+	assert.Equal(t, []any{"api", "db"}, decoded["labels"])
+
+	eid, err = UnsetTaskMetaIdempotent(context.Background(), db, "agent1", "meta_unset_1", task.ID, "attempts")
+	require.NoError(t, err)
+	assert.Positive(t, eid)
+	eid, err = UnsetTaskMetaIdempotent(context.Background(), db, "agent1", "meta_unset_2", task.ID, "attempts")
+	require.NoError(t, err)
+	assert.Zero(t, eid)
+}
+
+func TestFilterTasksByMeta_RequiresAllPairs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	a, err := CreateTask(context.Background(), db, "a", "", "", 0)
+	require.NoError(t, err)
+	b, err := CreateTask(context.Background(), db, "b", "", "", 0)
+	require.NoError(t, err)
+	_, err = CreateTask(context.Background(), db, "c", "", "", 0)
+	require.NoError(t, err)
+
+	_, _, err = SetTaskMetaIdempotent(context.Background(), db, "agent1", "f1", a.ID, "reviewer", "alice", "")
+	require.NoError(t, err)
+	_, _, err = SetTaskMetaIdempotent(context.Background(), db, "agent1", "f2", a.ID, "env", "prod", "")
+	require.NoError(t, err)
+	_, _, err = SetTaskMetaIdempotent(context.Background(), db, "agent1", "f3", b.ID, "reviewer", "alice", "")
+	require.NoError(t, err)
+
+	all, err := ListTasks(context.Background(), db, "", "", -1)
+	require.NoError(t, err)
+
+	filters, err := ParseTaskMetaFilters([]string{"reviewer=alice"})
+	require.NoError(t, err)
+	got, err := FilterTasksByMeta(context.Background(), db, all, filters)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	filters, err = ParseTaskMetaFilters([]string{"reviewer=alice", "env=prod"})
+	require.NoError(t, err)
+	got, err = FilterTasksByMeta(context.Background(), db, all, filters)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, a.ID, got[0].ID)
+
+	_, err = ParseTaskMetaFilters([]string{"reviewer"})
+	require.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// ValidateTaskOutcome checks outcome and the superseded_by link that goes with
+// it: superseded needs one, duplicate may name the task it duplicates, and the
+// other outcomes take none. An empty outcome is done.
+func ValidateTaskOutcome(outcome models.TaskOutcome, supersededBy string) error {
+	if outcome == "" {
+		outcome = models.TaskOutcomeDone
+	}
+	if !outcome.IsValid() {
+		valid := make([]string, len(models.TaskOutcomes))
+		for i, o := range models.TaskOutcomes {
+			valid[i] = string(o)
+		}
+		return fmt.Errorf("invalid outcome %q (valid: %s)", outcome, strings.Join(valid, ", "))
+	}
+	switch {
+	case outcome == models.TaskOutcomeSuperseded && supersededBy == "":
+		return errors.New("outcome superseded requires superseded_by (the replacing task)")
+	case supersededBy != "" && outcome != models.TaskOutcomeSuperseded && outcome != models.TaskOutcomeDuplicate:
+		return fmt.Errorf("superseded_by applies only to superseded or duplicate outcomes, not %s", outcome)
+	}
+	return nil
+}
+
+// SetTaskOutcomeTx records how a completed task was resolved. supersededBy, when
+// set, must name another existing task.
+func SetTaskOutcomeTx(tx *sql.Tx, taskID string, outcome models.TaskOutcome, supersededBy string) error {
+	if err := ValidateTaskOutcome(outcome, supersededBy); err != nil {
+		return err
+	}
+	if supersededBy != "" {
+		if supersededBy == taskID {
+			return errors.New("a task cannot supersede itself")
+		}
+		if _, err := GetTaskVersionTx(tx, supersededBy); err != nil {
+			return fmt.Errorf("superseded_by task: %w", err)
+		}
+	}
+	var link any
+	if supersededBy != "" {
+		link = supersededBy
+	}
+	if _, err := tx.ExecContext(context.Background(),
+		`UPDATE tasks SET outcome = ?, superseded_by = ? WHERE id = ?`, string(outcome), link, taskID); err != nil {
+		return fmt.Errorf("failed to set task outcome: %w", err)
+	}
+	return nil
+}
+
+// TaskStats is queue health for a project (or all tasks): how many tasks are
+// in each status and how the completed ones were resolved.
+type TaskStats struct {
+	ProjectID string                     `json:"project_id,omitempty"`
+	Total     int                        `json:"total"`
+	Statuses  TaskStatusCounts           `json:"statuses"`
+	Outcomes  map[models.TaskOutcome]int `json:"outcomes"`
+	// DoneRatio is the share of completed tasks whose outcome is done.
+	DoneRatio float64 `json:"done_ratio"`
+}
+
+// GetTaskStats rolls tasks up by status and completed tasks by outcome, optionally
+// scoped to a project. Tasks completed without an outcome count as done.
+func GetTaskStats(ctx context.Context, db *sql.DB, projectID string) (*TaskStats, error) {
+	counts, err := GetTaskStatusCounts(ctx, db, projectID)
+	if err != nil {
+		return nil, err
+	}
+	stats := &TaskStats{
+		ProjectID: projectID,
+		Statuses:  *counts,
+		Total:     counts.Pending + counts.InProgress + counts.Completed + counts.Blocked,
+		Outcomes:  make(map[models.TaskOutcome]int, len(models.TaskOutcomes)),
+	}
+	for _, o := range models.TaskOutcomes {
+		stats.Outcomes[o] = 0
+	}
+
+	query := `SELECT COALESCE(outcome, 'done'), COUNT(*) FROM tasks WHERE status = 'completed'`
+	var args []any
+	if projectID != "" {
+		query += ` AND project_id = ?`
+		args = append(args, projectID)
+	}
+	query += ` GROUP BY 1`
+
+	err = RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query task outcomes: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var outcome string
+			var n int
+			if err := rows.Scan(&outcome, &n); err != nil {
+				return fmt.Errorf("failed to scan task outcome: %w", err)
+			}
+			stats.Outcomes[models.TaskOutcome(outcome)] = n
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if counts.Completed > 0 {
+		stats.DoneRatio = float64(stats.Outcomes[models.TaskOutcomeDone]) / float64(counts.Completed)
+	}
+	return stats, nil
+}
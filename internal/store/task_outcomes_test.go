@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestCloseTask_OutcomesAndStats(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	newTask := func(title, project string) *models.Task {
+		task, err := CreateTask(context.Background(), db, title, "", project, 0)
+		require.NoError(t, err)
+		return task
+	}
+	closeTask := func(taskID string, p CloseTaskParams) error {
+		p.AgentName, p.TaskID, p.Status, p.Summary = "agent1", taskID, "completed", "closed"
+		_, err := RunIdempotent(t.Context(), db, "agent1", "close_"+taskID+string(p.Outcome), "task.close", func(tx *sql.Tx) (*CloseTaskResult, error) {
+			return CloseTaskTx(tx, p)
+		})
+		return err
+	}
+
+	done := newTask("Done", "p1")
+	partial := newTask("Partial", "p1")
+	old := newTask("Old", "p1")
+	replacement := newTask("Replacement", "p1")
+	newTask("Elsewhere", "p2")
+
+	require.NoError(t, closeTask(done.ID, CloseTaskParams{}))
+	require.NoError(t, closeTask(partial.ID, CloseTaskParams{Outcome: models.TaskOutcomePartial}))
+	require.Error(t, closeTask(old.ID, CloseTaskParams{Outcome: models.TaskOutcomeSuperseded}), "superseded needs a link")
+	require.Error(t, closeTask(old.ID, CloseTaskParams{Outcome: models.TaskOutcomeSuperseded, SupersededBy: old.ID}))
+	require.Error(t, closeTask(old.ID, CloseTaskParams{Outcome: models.TaskOutcomeWontFix, SupersededBy: replacement.ID}))
+	require.NoError(t, closeTask(old.ID, CloseTaskParams{Outcome: models.TaskOutcomeSuperseded, SupersededBy: replacement.ID}))
+
+	got, err := GetTask(context.Background(), db, old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskOutcomeSuperseded, got.Outcome)
+	assert.Equal(t, replacement.ID, got.SupersededBy)
+	got, err = GetTask(context.Background(), db, done.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskOutcomeDone, got.Outcome, "no outcome means done")
+
+	stats, err := GetTaskStats(context.Background(), db, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.Total)
+	assert.Equal(t, 3, stats.Statuses.Completed)
+	assert.Equal(t, 1, stats.Outcomes[models.TaskOutcomeDone])
+	assert.Equal(t, 1, stats.Outcomes[models.TaskOutcomePartial])
+	assert.Equal(t, 1, stats.Outcomes[models.TaskOutcomeSuperseded])
+	assert.Equal(t, 0, stats.Outcomes[models.TaskOutcomeWontFix])
+	assert.InDelta(t, 1.0/3, stats.DoneRatio, 0.001)
+
+	// Reopening a task clears its outcome.
+	current, err := GetTask(context.Background(), db, old.ID)
+	require.NoError(t, err)
+	require.NoError(t, Transact(t.Context(), db, func(tx *sql.Tx) error {
+		_, err := UpdateTaskStatusWithEventTx(tx, "agent1", old.ID, "pending", current.Version)
+		return err
+	}))
+	got, err = GetTask(context.Background(), db, old.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Outcome)
+	assert.Empty(t, got.SupersededBy)
+}
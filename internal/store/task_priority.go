@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Priority inheritance: an unfinished task's effective priority is the highest
+// priority among itself and every unfinished task that transitively depends on
+// it, so a low-priority blocker of urgent work is picked before unrelated tasks
+// of middling priority. Completed tasks neither inherit nor pass priority on.
+//
+// inheritedPriorityCTE yields inherited(ep_task_id, ep_priority, ep_source):
+// one row per (task, waiting task) pair, including each task as its own source.
+// UNION (not UNION ALL) keeps it finite on cyclic graphs.
+const inheritedPriorityCTE = `WITH RECURSIVE inherited(ep_task_id, ep_priority, ep_source) AS (
+		SELECT id, priority, id FROM tasks WHERE status != 'completed'
+		UNION
+		SELECT d.depends_on_task_id, i.ep_priority, i.ep_source
+		FROM inherited i
+		JOIN task_dependencies d ON d.task_id = i.ep_task_id
+		JOIN tasks dep ON dep.id = d.depends_on_task_id AND dep.status != 'completed'
+	)`
+
+// effectivePriorityCTE extends inheritedPriorityCTE with
+// effective_priority(ep_task_id, ep_priority). Prefix a tasks query with it,
+// add effectivePriorityJoin after FROM tasks, and order by effectivePriorityOrder.
+const effectivePriorityCTE = inheritedPriorityCTE + `, effective_priority(ep_task_id, ep_priority) AS (
+		SELECT ep_task_id, MAX(ep_priority) FROM inherited GROUP BY ep_task_id
+	)
+`
+
+// effectivePriorityOrder ranks by effective priority; on a tie, a task still
+// waiting on unfinished dependencies sorts after its blockers, which share its
+// inherited priority.
+const (
+	effectivePriorityJoin  = ` LEFT JOIN effective_priority ON effective_priority.ep_task_id = tasks.id`
+	effectivePriorityExpr  = `COALESCE(effective_priority.ep_priority, tasks.priority)`
+	effectivePriorityOrder = effectivePriorityExpr + ` DESC, EXISTS (
+		SELECT 1 FROM task_dependencies wd JOIN tasks wdt ON wdt.id = wd.depends_on_task_id
+		WHERE wd.task_id = tasks.id AND wdt.status != 'completed'
+	) ASC`
+)
+
+// InheritedPriority explains a raised effective priority: Priority is the
+// task's own value, Effective the inherited one, and From the waiting task it
+// came from.
+type InheritedPriority struct {
+	Priority  int    `json:"priority"`
+	Effective int    `json:"effective_priority"`
+	From      string `json:"inherited_from"`
+}
+
+// LoadInheritedPriorities returns, for each of taskIDs whose effective priority
+// exceeds its own, where the higher value came from. Tasks at their own
+// priority are absent from the map.
+func LoadInheritedPriorities(ctx context.Context, db *sql.DB, taskIDs []string) (map[string]InheritedPriority, error) {
+	out := map[string]InheritedPriority{}
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+
+	args := make([]any, len(taskIDs))
+	for i, id := range taskIDs {
+		args[i] = id
+	}
+	query := inheritedPriorityCTE + `
+		SELECT i.ep_task_id, t.priority, i.ep_priority, i.ep_source
+		FROM inherited i JOIN tasks t ON t.id = i.ep_task_id
+		WHERE i.ep_task_id IN (?` + strings.Repeat(", ?", len(taskIDs)-1) + `)
+		  AND i.ep_priority > t.priority
+		ORDER BY i.ep_task_id, i.ep_priority DESC, i.ep_source ASC`
+
+	err := RetryWithBackoff(ctx, func() error {
+		clear(out)
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query inherited priorities: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var taskID string
+			var ip InheritedPriority
+			if err := rows.Scan(&taskID, &ip.Priority, &ip.Effective, &ip.From); err != nil {
+				return fmt.Errorf("failed to scan inherited priority: %w", err)
+			}
+			if _, seen := out[taskID]; !seen {
+				out[taskID] = ip
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// inheritedPriorityNote is the focus-rule suffix explaining a task picked on an
+// inherited priority, or "" when the task ranks on its own priority. Lookup
+// errors yield "" since the note is informational only.
+func inheritedPriorityNote(ctx context.Context, db *sql.DB, taskID string) string {
+	inherited, err := LoadInheritedPriorities(ctx, db, []string{taskID})
+	if err != nil {
+		return ""
+	}
+	ip, ok := inherited[taskID]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" (effective priority %d inherited from %s, own priority %d)", ip.Effective, ip.From, ip.Priority)
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectivePriority_InheritedThroughDependencies(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	urgent, err := CreateTask(context.Background(), db, "Urgent", "", "p1", 9)
+	require.NoError(t, err)
+	middle, err := CreateTask(context.Background(), db, "Middle", "", "p1", 5)
+	require.NoError(t, err)
+	blocker, err := CreateTask(context.Background(), db, "Blocker", "", "p1", 1)
+	require.NoError(t, err)
+	root, err := CreateTask(context.Background(), db, "Root blocker", "", "p1", 0)
+	require.NoError(t, err)
+
+	// urgent waits on blocker, which waits on root; the cycle back to urgent
+	// must not loop forever.
+	_, err = db.Exec(`INSERT INTO task_dependencies (task_id, depends_on_task_id) VALUES (?, ?), (?, ?), (?, ?)`,
+		urgent.ID, blocker.ID, blocker.ID, root.ID, root.ID, urgent.ID)
+	require.NoError(t, err)
+
+	inherited, err := LoadInheritedPriorities(context.Background(), db, []string{urgent.ID, middle.ID, blocker.ID, root.ID})
+	require.NoError(t, err)
+	assert.Equal(t, InheritedPriority{Priority: 1, Effective: 9, From: urgent.ID}, inherited[blocker.ID])
+	assert.Equal(t, InheritedPriority{Priority: 0, Effective: 9, From: urgent.ID}, inherited[root.ID])
+	assert.NotContains(t, inherited, urgent.ID)
+	assert.NotContains(t, inherited, middle.ID)
+
+	// Break the cycle: root now only blocks blocker, which blocks urgent.
+	_, err = db.Exec(`DELETE FROM task_dependencies WHERE task_id = ?`, root.ID)
+	require.NoError(t, err)
+
+	next, err := ListNextTasks(context.Background(), db, "p1", time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, next, 4)
+	assert.Equal(t, root.ID, next[0].ID, "the unblocked root inherits urgent's priority")
+	assert.Equal(t, middle.ID, next[3].ID)
+
+	result, err := DetermineFocusTask(context.Background(), db, "agent1", "", nil, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, result.TaskID)
+	assert.Contains(t, result.Rule, "effective priority 9 inherited from "+urgent.ID)
+
+	// Completed tasks pass nothing on.
+	_, err = db.Exec(`UPDATE tasks SET status = 'completed' WHERE id = ?`, urgent.ID)
+	require.NoError(t, err)
+	inherited, err = LoadInheritedPriorities(context.Background(), db, []string{blocker.ID, root.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]InheritedPriority{
+		root.ID: {Priority: 0, Effective: 1, From: blocker.ID},
+	}, inherited)
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// RecordTaskAttemptTx counts one failed run of taskID and returns the new
+// attempt count.
+func RecordTaskAttemptTx(tx *sql.Tx, taskID string) (int, error) {
+	var attempts int
+	err := tx.QueryRowContext(context.Background(),
+		`UPDATE tasks SET attempts = attempts + 1 WHERE id = ? RETURNING attempts`, taskID).Scan(&attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("task not found: %s", taskID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to record task attempt: %w", err)
+	}
+	return attempts, nil
+}
+
+// SetTaskRetryTx schedules when a failure-blocked task returns to pending, or
+// cancels the retry when retryAt is nil.
+func SetTaskRetryTx(tx *sql.Tx, taskID string, retryAt *time.Time) error {
+	if _, err := tx.ExecContext(context.Background(),
+		`UPDATE tasks SET retry_at = ? WHERE id = ?`, nullableTime(retryAt), taskID); err != nil {
+		return fmt.Errorf("failed to set task retry: %w", err)
+	}
+	return nil
+}
+
+// RequeuedTask is one failure-blocked task a retry sweep returned to pending.
+type RequeuedTask struct {
+	TaskID   string `json:"task_id"`
+	Title    string `json:"title"`
+	Attempts int    `json:"attempts"`
+	EventID  int64  `json:"event_id"`
+}
+
+// RequeueDueRetriesTx returns failure-blocked tasks whose retry_at has passed
+// to pending, emitting a task_status and a task_requeued event for each.
+// projectID, when non-empty, restricts the sweep to that project.
+func RequeueDueRetriesTx(tx *sql.Tx, agentName, projectID string, now time.Time) ([]RequeuedTask, error) {
+	query := `SELECT id, title, attempts, version FROM tasks
+		WHERE status = 'blocked' AND retry_at IS NOT NULL AND retry_at <= ?
+		  AND blocked_reason LIKE ?`
+	args := []any{formatDue(now), models.BlockedReasonFailurePrefix + "%"}
+	if projectID != "" {
+		query += ` AND ` + ProjectScopeClause
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY retry_at ASC`
+
+	rows, err := tx.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due retries: %w", err)
+	}
+	type due struct {
+		RequeuedTask
+		version int
+	}
+	var dues []due
+	for rows.Next() {
+		var d due
+		if err := rows.Scan(&d.TaskID, &d.Title, &d.Attempts, &d.version); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan due retry: %w", err)
+		}
+		dues = append(dues, d)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]RequeuedTask, 0, len(dues))
+	for _, d := range dues {
+		if _, err := UpdateTaskStatusWithEventTx(tx, agentName, d.TaskID, string(models.TaskStatusPending), d.version); err != nil {
+			return nil, err
+		}
+		meta, err := json.Marshal(map[string]any{"attempts": d.Attempts})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode requeue metadata: %w", err)
+		}
+		d.EventID, err = InsertEventTx(tx, models.EventKindTaskRequeued, agentName, d.TaskID,
+			fmt.Sprintf("Task requeued for retry after %d failed attempt(s): %s", d.Attempts, d.Title), string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append requeue event: %w", err)
+		}
+		out = append(out, d.RequeuedTask)
+	}
+	return out, nil
+}
+
+// RequeueDueRetriesIdempotent runs RequeueDueRetriesTx once per (agent, request id).
+func RequeueDueRetriesIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string) ([]RequeuedTask, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "task.requeue_retries", func(tx *sql.Tx) ([]RequeuedTask, error) {
+		return RequeueDueRetriesTx(tx, agentName, projectID, time.Now())
+	})
+}
@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
+)
+
+// SetTaskSizeTx sets or clears (size == "") a task's effort size with a CAS
+// version bump and a task_size_set event.
+func SetTaskSizeTx(tx *sql.Tx, agentName, taskID string, size models.TaskSize) (int64, error) {
+	if size != "" && !size.IsValid() {
+		return 0, fmt.Errorf("invalid task size %q (valid: xs, s, m, l, xl)", size)
+	}
+	version, err := GetTaskVersionTx(tx, taskID)
+	if err != nil {
+		return 0, err
+	}
+
+	message := "Size cleared"
+	if size != "" {
+		message = "Size set: " + string(size)
+	}
+	return casUpdateTaskWithEvent(tx, agentName, taskID, version,
+		`UPDATE tasks
+		SET size = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND version = ?`,
+		[]any{nullIfEmpty(string(size)), taskID, version},
+		models.EventKindTaskSizeSet,
+		message,
+	)
+}
+
+// Capacity plan skip reasons.
+const (
+	PlanSkipUnsized      = "unsized"
+	PlanSkipOverCapacity = "exceeds_remaining_capacity"
+	PlanSkipWaitingOnDep = "waiting_on_dependencies"
+)
+
+// PlannedTask is one pending task considered by PlanCapacity.
+type PlannedTask struct {
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Priority  int             `json:"priority"`
+	ProjectID string          `json:"project_id,omitempty"`
+	Size      models.TaskSize `json:"size,omitempty"`
+	Points    int             `json:"points"`
+	// SkipReason is set on tasks left out of the plan.
+	SkipReason string `json:"skip_reason,omitempty"`
+}
+
+// CapacityPlan is the set of pending tasks that fit a capacity budget.
+type CapacityPlan struct {
+	Capacity  int           `json:"capacity"`
+	Used      int           `json:"used"`
+	Remaining int           `json:"remaining"`
+	Selected  []PlannedTask `json:"selected"`
+	Skipped   []PlannedTask `json:"skipped"`
+}
+
+// PlanCapacity fills capacity points with pending tasks in priority order
+// (effective priority DESC, oldest first). A task that does not fit is skipped and
+// smaller lower-priority tasks may still fill the remainder. Tasks whose
+// unfinished dependencies are not themselves in the plan are skipped.
+// Unsized tasks count as defaultSize, or are skipped when defaultSize is "".
+func PlanCapacity(ctx context.Context, db *sql.DB, projectID string, capacity int, defaultSize models.TaskSize) (_ *CapacityPlan, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	if capacity <= 0 {
+		return nil, fmt.Errorf("capacity must be > 0, got %d", capacity)
+	}
+	if defaultSize != "" && !defaultSize.IsValid() {
+		return nil, fmt.Errorf("invalid default size %q (valid: xs, s, m, l, xl)", defaultSize)
+	}
+
+	query := effectivePriorityCTE + `SELECT id, title, description, status, priority, project_id, blocked_reason, due_at, size, attempts, retry_at, outcome, superseded_by, version, created_at, updated_at
+		FROM tasks` + effectivePriorityJoin + ` WHERE status = 'pending'`
+	var args []any
+	if projectID != "" {
+		query += ` AND ` + ProjectScopeClause
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY ` + priorityFirstOrder
+	tasks, err := queryTasks(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	deps, err := unfinishedDependencies(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &CapacityPlan{Capacity: capacity, Selected: []PlannedTask{}, Skipped: []PlannedTask{}}
+	selected := map[string]bool{}
+	pending := make([]PlannedTask, 0, len(tasks))
+	for _, t := range tasks {
+		pt := PlannedTask{ID: t.ID, Title: t.Title, Priority: t.Priority, ProjectID: t.ProjectID, Size: t.Size}
+		if pt.Size == "" {
+			pt.Size = defaultSize
+		}
+		pt.Points = pt.Size.Points()
+		if pt.Points == 0 {
+			pt.SkipReason = PlanSkipUnsized
+			plan.Skipped = append(plan.Skipped, pt)
+			continue
+		}
+		pending = append(pending, pt)
+	}
+
+	// Repeat passes so a dependent ranked above its dependency can still be
+	// planned once the dependency is selected.
+	for progress := true; progress; {
+		progress = false
+		rest := pending[:0]
+		for _, pt := range pending {
+			if pt.Points <= capacity-plan.Used && depsSatisfied(deps[pt.ID], selected) {
+				plan.Used += pt.Points
+				selected[pt.ID] = true
+				plan.Selected = append(plan.Selected, pt)
+				progress = true
+				continue
+			}
+			rest = append(rest, pt)
+		}
+		pending = rest
+	}
+	for _, pt := range pending {
+		pt.SkipReason = PlanSkipOverCapacity
+		if !depsSatisfied(deps[pt.ID], selected) {
+			pt.SkipReason = PlanSkipWaitingOnDep
+		}
+		plan.Skipped = append(plan.Skipped, pt)
+	}
+	plan.Remaining = capacity - plan.Used
+	return plan, nil
+}
+
+func depsSatisfied(deps []string, selected map[string]bool) bool {
+	for _, d := range deps {
+		if !selected[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// unfinishedDependencies maps task ID to the IDs of its dependencies that are
+// not completed.
+func unfinishedDependencies(ctx context.Context, db *sql.DB) (map[string][]string, error) {
+	out := map[string][]string{}
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT d.task_id, d.depends_on_task_id
+			FROM task_dependencies d JOIN tasks dep ON dep.id = d.depends_on_task_id
+			WHERE dep.status != 'completed'
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to load dependencies: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		clear(out)
+		for rows.Next() {
+			var taskID, depID string
+			if err := rows.Scan(&taskID, &depID); err != nil {
+				return fmt.Errorf("failed to scan dependency: %w", err)
+			}
+			out[taskID] = append(out[taskID], depID)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
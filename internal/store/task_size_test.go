@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestPlanCapacity_GreedyByPriority(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mk := func(title string, prio int, size models.TaskSize) *models.Task {
+		t.Helper()
+		task, err := CreateTask(context.Background(), db, title, "", "", prio)
+		require.NoError(t, err)
+		if size != "" {
+			require.NoError(t, Transact(context.Background(), db, func(tx *sql.Tx) error {
+				_, err := SetTaskSizeTx(tx, "agent1", task.ID, size)
+				return err
+			}))
+		}
+		return task
+	}
+
+	big := mk("Big", 9, models.TaskSizeXL)      // 8
+	medium := mk("Medium", 8, models.TaskSizeM) // 3: does not fit after Big
+	small := mk("Small", 5, models.TaskSizeS)   // 2: fills the rest
+	unsized := mk("Unsized", 7, "")
+	dep := mk("Needs Medium", 6, models.TaskSizeXS)
+	_, err := db.Exec(`INSERT INTO task_dependencies (task_id, depends_on_task_id) VALUES (?, ?)`, dep.ID, medium.ID)
+	require.NoError(t, err)
+
+	plan, err := PlanCapacity(context.Background(), db, "", 10, "")
+	require.NoError(t, err)
+	ids := func(ps []PlannedTask) []string {
+		out := make([]string, 0, len(ps))
+		for _, p := range ps {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []string{big.ID, small.ID}, ids(plan.Selected))
+	assert.Equal(t, 10, plan.Used)
+	assert.Equal(t, 0, plan.Remaining)
+
+	reasons := map[string]string{}
+	for _, p := range plan.Skipped {
+		reasons[p.ID] = p.SkipReason
+	}
+	assert.Equal(t, PlanSkipOverCapacity, reasons[medium.ID])
+	assert.Equal(t, PlanSkipUnsized, reasons[unsized.ID])
+	assert.Equal(t, PlanSkipWaitingOnDep, reasons[dep.ID])
+
+	// With room for Medium, its dependent follows it into the plan.
+	plan, err = PlanCapacity(context.Background(), db, "", 20, models.TaskSizeXS)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{big.ID, medium.ID, small.ID, unsized.ID, dep.ID}, ids(plan.Selected))
+	assert.Equal(t, 15, plan.Used)
+
+	got, err := GetTask(context.Background(), db, big.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskSizeXL, got.Size)
+
+	_, err = PlanCapacity(context.Background(), db, "", 0, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// StaleTask is an in_progress task with no activity for longer than a
+// threshold. Activity is the task's last update or its latest event other
+// than a task_stale notice. Agents lists the agents whose focus it is.
+type StaleTask struct {
+	TaskID         string    `json:"task_id"`
+	Title          string    `json:"title"`
+	ProjectID      string    `json:"project_id,omitempty"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	IdleHours      float64   `json:"idle_hours"`
+	Agents         []string  `json:"agents"`
+	Notified       bool      `json:"notified"`           // a task_stale notice already covers this idle stretch
+	EventID        int64     `json:"event_id,omitempty"` // the notice NotifyStaleTasks emitted
+}
+
+type staleQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// findStaleTasks returns in_progress tasks idle for longer than threshold,
+// longest idle first.
+//
+//nolint:funlen // one query plus the agent lookup that decorates its rows
+func findStaleTasks(ctx context.Context, q staleQuerier, projectID string, threshold time.Duration) ([]StaleTask, error) {
+	scope, args := "", []any{models.EventKindTaskStale, models.EventKindTaskStale}
+	if projectID != "" {
+		scope = ` AND t.project_id = ?`
+		args = append(args, projectID)
+	}
+	args = append(args, threshold.Seconds())
+
+	rows, err := q.QueryContext(ctx, `
+		WITH activity AS (
+			SELECT t.id, t.title, COALESCE(t.project_id, '') AS project_id,
+				MAX(t.updated_at, COALESCE(
+					(SELECT MAX(e.created_at) FROM events e WHERE e.task_id = t.id AND e.kind != ?),
+					t.updated_at)) AS last_at,
+				(SELECT MAX(e.created_at) FROM events e WHERE e.task_id = t.id AND e.kind = ?) AS noticed_at
+			FROM tasks t
+			WHERE t.status = 'in_progress'`+scope+`
+		)
+		SELECT id, title, project_id, strftime('%Y-%m-%dT%H:%M:%SZ', last_at),
+			(julianday('now') - julianday(last_at)) * 86400.0,
+			COALESCE(noticed_at >= last_at, 0)
+		FROM activity
+		WHERE (julianday('now') - julianday(last_at)) * 86400.0 > ?
+		ORDER BY last_at ASC, id ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale tasks: %w", err)
+	}
+	stale := make([]StaleTask, 0)
+	byID := map[string]int{}
+	for rows.Next() {
+		var s StaleTask
+		var lastAt string
+		var idleSecs float64
+		if err := rows.Scan(&s.TaskID, &s.Title, &s.ProjectID, &lastAt, &idleSecs, &s.Notified); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan stale task: %w", err)
+		}
+		s.LastActivityAt, _ = time.Parse(time.RFC3339, lastAt)
+		s.IdleHours = math.Round(idleSecs/3600*10) / 10
+		s.Agents = []string{}
+		byID[s.TaskID] = len(stale)
+		stale = append(stale, s)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if len(stale) == 0 {
+		return stale, nil
+	}
+
+	ids := make([]any, 0, len(stale))
+	for _, s := range stale {
+		ids = append(ids, s.TaskID)
+	}
+	//nolint:gosec // G202: only placeholders are concatenated
+	rows, err = q.QueryContext(ctx, `SELECT agent_name, focus_task_id FROM agent_state
+		WHERE focus_task_id IN (?`+strings.Repeat(", ?", len(ids)-1)+`) ORDER BY agent_name`, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale task agents: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var agent, taskID string
+		if err := rows.Scan(&agent, &taskID); err != nil {
+			return nil, fmt.Errorf("failed to scan stale task agent: %w", err)
+		}
+		s := &stale[byID[taskID]]
+		s.Agents = append(s.Agents, agent)
+	}
+	return stale, rows.Err()
+}
+
+// ListStaleTasks returns in_progress tasks (in projectID when set) with no
+// activity for longer than threshold, longest idle first.
+func ListStaleTasks(ctx context.Context, db *sql.DB, projectID string, threshold time.Duration) ([]StaleTask, error) {
+	var stale []StaleTask
+	err := RetryWithBackoff(ctx, func() error {
+		var err error
+		stale, err = findStaleTasks(ctx, db, projectID, threshold)
+		return err
+	})
+	return stale, err
+}
+
+// NotifyStaleTasksIdempotent emits one task_stale event per stale task not yet
+// notified for its current idle stretch; any new activity on the task re-arms
+// it. Returns the tasks it notified.
+func NotifyStaleTasksIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, projectID string, threshold time.Duration) ([]StaleTask, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "task.stale_notify", func(tx *sql.Tx) ([]StaleTask, error) {
+		stale, err := findStaleTasks(ctx, tx, projectID, threshold)
+		if err != nil {
+			return nil, err
+		}
+		notified := make([]StaleTask, 0)
+		for _, s := range stale {
+			if s.Notified {
+				continue
+			}
+			meta, err := json.Marshal(map[string]any{
+				"last_activity_at": s.LastActivityAt.Format(time.RFC3339),
+				"idle_hours":       s.IdleHours,
+				"threshold":        threshold.String(),
+				"agents":           s.Agents,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode stale metadata: %w", err)
+			}
+			s.EventID, err = InsertEventTx(tx, models.EventKindTaskStale, agentName, s.TaskID,
+				fmt.Sprintf("Task stale: %s (no activity for %.1fh)", s.Title, s.IdleHours), string(meta))
+			if err != nil {
+				return nil, fmt.Errorf("failed to append stale event: %w", err)
+			}
+			s.Notified = true
+			notified = append(notified, s)
+		}
+		return notified, nil
+	})
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+func TestStaleTasks_ListAndNotifyOncePerIdleStretch(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	idle, err := CreateTask(context.Background(), db, "idle", "", "", 0)
+	require.NoError(t, err)
+	busy, err := CreateTask(context.Background(), db, "busy", "", "", 0)
+	require.NoError(t, err)
+	pending, err := CreateTask(context.Background(), db, "pending", "", "", 0)
+	require.NoError(t, err)
+
+	// idle and pending were last touched three hours ago; busy has a recent event.
+	_, err = db.Exec(`UPDATE tasks SET status = CASE WHEN id = ? THEN 'pending' ELSE 'in_progress' END,
+		updated_at = datetime('now', '-3 hours')`, pending.ID)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE events SET created_at = datetime('now', '-3 hours')`)
+	require.NoError(t, err)
+	appendEvent(t, db, models.EventKindProgress, "worker", busy.ID, "still going")
+	_, err = LoadOrCreateAgentState(context.Background(), db, "worker")
+	require.NoError(t, err)
+	_, err = SetAgentFocusTaskWithEventIdempotent(context.Background(), db, "worker", "focus", idle.ID)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE events SET created_at = datetime('now', '-3 hours') WHERE task_id = ?`, idle.ID)
+	require.NoError(t, err)
+
+	stale, err := ListStaleTasks(context.Background(), db, "", 2*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	assert.Equal(t, idle.ID, stale[0].TaskID)
+	assert.Equal(t, []string{"worker"}, stale[0].Agents)
+	assert.InDelta(t, 3.0, stale[0].IdleHours, 0.1)
+	assert.False(t, stale[0].Notified)
+
+	notified, err := NotifyStaleTasksIdempotent(context.Background(), db, "watcher", "n1", "", 2*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, notified, 1)
+	assert.Positive(t, notified[0].EventID)
+
+	// The notice is not activity: the task stays stale, but is not reported twice.
+	stale, err = ListStaleTasks(context.Background(), db, "", 2*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	assert.True(t, stale[0].Notified)
+	notified, err = NotifyStaleTasksIdempotent(context.Background(), db, "watcher", "n2", "", 2*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, notified)
+
+	// New activity clears it; going idle again re-arms the notice.
+	appendEvent(t, db, models.EventKindProgress, "worker", idle.ID, "back")
+	stale, err = ListStaleTasks(context.Background(), db, "", 2*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+	_, err = db.Exec(`UPDATE events SET created_at = datetime('now', '-1 hours') WHERE task_id = ? AND kind = ?`,
+		idle.ID, models.EventKindTaskStale)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE events SET created_at = datetime('now', '-50 minutes') WHERE task_id = ? AND kind = ?`,
+		idle.ID, models.EventKindProgress)
+	require.NoError(t, err)
+	notified, err = NotifyStaleTasksIdempotent(context.Background(), db, "watcher", "n3", "", 30*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, notified, 1, "re-armed by the activity after the first notice")
+	assert.Equal(t, idle.ID, notified[0].TaskID)
+}
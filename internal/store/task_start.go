@@ -20,6 +20,10 @@ func setAgentFocusTx(tx *sql.Tx, agentName, taskID string) (focusEventID int64,
 		return 0, fmt.Errorf("failed to ensure agent state: %w", execErr)
 	}
 
+	if err := recordFocusOverlapTx(tx, agentName, taskID); err != nil {
+		return 0, err
+	}
+
 	// Update focus with optimistic concurrency.
 	var agentVersion int
 	if scanErr := tx.QueryRowContext(context.Background(), `SELECT version FROM agent_state WHERE agent_name = ?`, agentName).Scan(&agentVersion); scanErr != nil {
@@ -53,6 +57,10 @@ func setAgentFocusTx(tx *sql.Tx, agentName, taskID string) (focusEventID int64,
 }
 
 func startTaskAndFocusTx(tx *sql.Tx, agentName, taskID string) (statusEventID int64, focusEventID int64, runErr error) {
+	if err := ensureTaskClaimableTx(tx, taskID); err != nil {
+		return 0, 0, err
+	}
+
 	// Transition to in_progress (if not already), emitting a status event.
 	statusEvent, err := markTaskInProgressTx(tx, agentName, taskID)
 	if err != nil {
@@ -87,7 +95,8 @@ func markTaskInProgressTx(tx *sql.Tx, agentName, taskID string) (int64, error) {
 
 	res, err := tx.ExecContext(context.Background(), `
 		UPDATE tasks
-		SET status = 'in_progress', version = version + 1, updated_at = CURRENT_TIMESTAMP
+		SET status = 'in_progress', lease_owner = NULL, lease_seconds = NULL, lease_expires_at = NULL,
+		    version = version + 1, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND version = ?
 	`, taskID, version)
 	if err != nil {
@@ -111,7 +120,7 @@ func markTaskInProgressTx(tx *sql.Tx, agentName, taskID string) (int64, error) {
 // StartTaskAndFocus sets a task to in_progress (if needed), sets agent focus to it,
 // and appends corresponding events. Returns (statusEventID, focusEventID).
 // statusEventID may be 0 if status was already in_progress.
-func StartTaskAndFocus(db *sql.DB, agentName, taskID string) (statusEventID int64, focusEventID int64, runErr error) {
+func StartTaskAndFocus(ctx context.Context, db *sql.DB, agentName, taskID string) (statusEventID int64, focusEventID int64, runErr error) {
 	if agentName == "" {
 		return 0, 0, errors.New("agent name is required")
 	}
@@ -122,7 +131,7 @@ func StartTaskAndFocus(db *sql.DB, agentName, taskID string) (statusEventID int6
 	var statusEvent int64
 	var focusEvent int64
 
-	runErr = Transact(context.Background(), db, func(tx *sql.Tx) error {
+	runErr = Transact(ctx, db, func(tx *sql.Tx) error {
 		se, fe, txErr := startTaskAndFocusTx(tx, agentName, taskID)
 		if txErr != nil {
 			return txErr
@@ -132,6 +141,9 @@ func StartTaskAndFocus(db *sql.DB, agentName, taskID string) (statusEventID int6
 		return nil
 	})
 	if runErr != nil {
+		if IsVersionConflict(runErr) {
+			RecordClaimConflict(ctx, db, agentName, taskID)
+		}
 		return 0, 0, runErr
 	}
 
@@ -140,7 +152,7 @@ func StartTaskAndFocus(db *sql.DB, agentName, taskID string) (statusEventID int6
 
 // StartTaskAndFocusIdempotent performs StartTaskAndFocus once per (agent_name, request_id).
 // On retries with the same request id, returns the originally created event ids.
-func StartTaskAndFocusIdempotent(db *sql.DB, agentName, requestID, taskID string) (statusEventID int64, focusEventID int64, runErr error) {
+func StartTaskAndFocusIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string) (statusEventID int64, focusEventID int64, runErr error) {
 	if agentName == "" {
 		return 0, 0, errors.New("agent name is required")
 	}
@@ -153,7 +165,7 @@ func StartTaskAndFocusIdempotent(db *sql.DB, agentName, requestID, taskID string
 		FocusEventID  int64 `json:"focus_event_id"`
 	}
 
-	r, err := RunIdempotent(context.Background(), db, agentName, requestID, "task.start", func(tx *sql.Tx) (idemResult, error) {
+	r, err := RunIdempotent(ctx, db, agentName, requestID, "task.start", func(tx *sql.Tx) (idemResult, error) {
 		statusEventID, focusEventID, txErr := startTaskAndFocusTx(tx, agentName, taskID)
 		if txErr != nil {
 			return idemResult{}, txErr
@@ -161,6 +173,9 @@ func StartTaskAndFocusIdempotent(db *sql.DB, agentName, requestID, taskID string
 		return idemResult{StatusEventID: statusEventID, FocusEventID: focusEventID}, nil
 	})
 	if err != nil {
+		if IsVersionConflict(err) {
+			RecordClaimConflict(ctx, db, agentName, taskID)
+		}
 		return 0, 0, err
 	}
 
@@ -0,0 +1,304 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+const maxTaskTagLength = 64
+
+// taskTagPattern keeps tags usable as --tag filters: lowercase letters, digits,
+// and '.', '_', '-', ':', '/' after the first character (e.g. area:frontend).
+var taskTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:/-]*$`)
+
+// normalizeTags trims and lowercases tags, dropping empties and duplicates.
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// NormalizeTaskTags normalizes tags and rejects any that are not valid tag
+// names, so tags written and tags filtered on compare equal.
+func NormalizeTaskTags(tags []string) ([]string, error) {
+	out := normalizeTags(tags)
+	for _, tag := range out {
+		if len(tag) > maxTaskTagLength {
+			return nil, fmt.Errorf("tag exceeds %d characters: %s", maxTaskTagLength, tag)
+		}
+		if !taskTagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q (use letters, digits, '.', '_', '-', ':', '/')", tag)
+		}
+	}
+	return out, nil
+}
+
+// TaskTagChange is the result of adding or removing tags on a task.
+type TaskTagChange struct {
+	TaskID  string   `json:"task_id"`
+	Changed []string `json:"changed"` // tags actually added or removed
+	Tags    []string `json:"tags"`    // the task's tags afterwards
+	EventID int64    `json:"event_id,omitempty"`
+}
+
+// AddTaskTagsTx tags a task and emits one task_tagged event listing the tags
+// that were new. Tags the task already carries are left alone; when none are
+// new no event is written.
+func AddTaskTagsTx(tx *sql.Tx, agentName, taskID string, tags []string) (*TaskTagChange, error) {
+	tags, err := NormalizeTaskTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, errors.New("at least one tag is required")
+	}
+	if _, err := GetTaskVersionTx(tx, taskID); err != nil {
+		return nil, err
+	}
+
+	change := &TaskTagChange{TaskID: taskID, Changed: []string{}}
+	for _, tag := range tags {
+		res, err := tx.ExecContext(context.Background(),
+			`INSERT OR IGNORE INTO task_tags (task_id, tag, created_by) VALUES (?, ?, ?)`, taskID, tag, agentName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert task tag: %w", err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			change.Changed = append(change.Changed, tag)
+		}
+	}
+	return finishTaskTagChange(tx, agentName, change, models.EventKindTaskTagged, "Task tagged")
+}
+
+// RemoveTaskTagsTx removes tags from a task and emits one task_untagged event
+// listing the tags that were present. Absent tags are ignored.
+func RemoveTaskTagsTx(tx *sql.Tx, agentName, taskID string, tags []string) (*TaskTagChange, error) {
+	tags, err := NormalizeTaskTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, errors.New("at least one tag is required")
+	}
+	if _, err := GetTaskVersionTx(tx, taskID); err != nil {
+		return nil, err
+	}
+
+	change := &TaskTagChange{TaskID: taskID, Changed: []string{}}
+	for _, tag := range tags {
+		res, err := tx.ExecContext(context.Background(),
+			`DELETE FROM task_tags WHERE task_id = ? AND tag = ?`, taskID, tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete task tag: %w", err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			change.Changed = append(change.Changed, tag)
+		}
+	}
+	return finishTaskTagChange(tx, agentName, change, models.EventKindTaskUntagged, "Task untagged")
+}
+
+func finishTaskTagChange(tx *sql.Tx, agentName string, change *TaskTagChange, kind, verb string) (*TaskTagChange, error) {
+	if len(change.Changed) > 0 {
+		meta, _ := json.Marshal(map[string]any{"tags": change.Changed})
+		eventID, err := InsertEventTx(tx, kind, agentName, change.TaskID,
+			fmt.Sprintf("%s: %s", verb, strings.Join(change.Changed, ", ")), string(meta))
+		if err != nil {
+			return nil, fmt.Errorf("failed to append task tag event: %w", err)
+		}
+		change.EventID = eventID
+	}
+
+	tags, err := queryTaskTags(context.Background(), tx, change.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	change.Tags = tags
+	return change, nil
+}
+
+// AddTaskTagsIdempotent performs AddTaskTagsTx once per (agent_name, request_id).
+func AddTaskTagsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string, tags []string) (*TaskTagChange, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "task.tag_add", func(tx *sql.Tx) (*TaskTagChange, error) {
+		return AddTaskTagsTx(tx, agentName, taskID, tags)
+	})
+}
+
+// RemoveTaskTagsIdempotent performs RemoveTaskTagsTx once per (agent_name, request_id).
+func RemoveTaskTagsIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, taskID string, tags []string) (*TaskTagChange, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "task.tag_remove", func(tx *sql.Tx) (*TaskTagChange, error) {
+		return RemoveTaskTagsTx(tx, agentName, taskID, tags)
+	})
+}
+
+func queryTaskTags(ctx context.Context, q queryer, taskID string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT tag FROM task_tags WHERE task_id = ? ORDER BY tag`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task tags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	tags := make([]string, 0)
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan task tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// ListTaskTags returns a task's tags in alphabetical order.
+func ListTaskTags(ctx context.Context, db *sql.DB, taskID string) ([]string, error) {
+	var tags []string
+	err := RetryWithBackoff(ctx, func() error {
+		t, err := queryTaskTags(ctx, db, taskID)
+		tags = t
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// tagQueryBatch bounds the task ids bound into one LoadTaskTags query.
+const tagQueryBatch = 500
+
+// AttachTaskTags fills Tags on each task.
+func AttachTaskTags(ctx context.Context, db *sql.DB, tasks []*models.Task) error {
+	ids := make([]string, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	tags, err := LoadTaskTags(ctx, db, ids)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		t.Tags = tags[t.ID]
+	}
+	return nil
+}
+
+// LoadTaskTags returns the tags of each of taskIDs that has any.
+func LoadTaskTags(ctx context.Context, db *sql.DB, taskIDs []string) (map[string][]string, error) {
+	out := map[string][]string{}
+	for batch := range slices.Chunk(taskIDs, tagQueryBatch) {
+		args := make([]any, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		//nolint:gosec // G202: only placeholders are concatenated; ids are bound
+		query := `SELECT task_id, tag FROM task_tags WHERE task_id IN (?` + strings.Repeat(", ?", len(batch)-1) + `) ORDER BY task_id, tag`
+		err := RetryWithBackoff(ctx, func() error {
+			for _, id := range batch {
+				delete(out, id)
+			}
+			rows, err := db.QueryContext(ctx, query, args...)
+			if err != nil {
+				return fmt.Errorf("failed to query task tags: %w", err)
+			}
+			defer func() { _ = rows.Close() }()
+			for rows.Next() {
+				var id, tag string
+				if err := rows.Scan(&id, &tag); err != nil {
+					return fmt.Errorf("failed to scan task tag: %w", err)
+				}
+				out[id] = append(out[id], tag)
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// FilterTasksByTags keeps tasks carrying every tag in tags. Tasks must already
+// have Tags attached.
+func FilterTasksByTags(tasks []*models.Task, tags []string) []*models.Task {
+	tags = normalizeTags(tags)
+	if len(tags) == 0 {
+		return tasks
+	}
+	out := make([]*models.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if hasAllTags(t.Tags, tags) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func hasAllTags(have, want []string) bool {
+	for _, tag := range want {
+		if !slices.Contains(have, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// TagCount is how many tasks carry a tag, broken down by status.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Total int    `json:"total"`
+	TaskStatusCounts
+}
+
+// ListTagCounts returns every tag in use with per-status task counts, most
+// used first, optionally scoped to a project.
+func ListTagCounts(ctx context.Context, db *sql.DB, projectID string) ([]TagCount, error) {
+	query := `
+		SELECT task_tags.tag, COUNT(*),
+			COALESCE(SUM(CASE WHEN tasks.status = 'pending' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN tasks.status = 'in_progress' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN tasks.status = 'completed' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN tasks.status = 'blocked' THEN 1 ELSE 0 END), 0)
+		FROM task_tags JOIN tasks ON tasks.id = task_tags.task_id`
+	var args []any
+	if projectID != "" {
+		query += ` WHERE tasks.project_id = ?`
+		args = append(args, projectID)
+	}
+	query += ` GROUP BY task_tags.tag ORDER BY COUNT(*) DESC, task_tags.tag ASC`
+
+	var out []TagCount
+	err := RetryWithBackoff(ctx, func() error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query tag counts: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		out = make([]TagCount, 0)
+		for rows.Next() {
+			var c TagCount
+			if err := rows.Scan(&c.Tag, &c.Total, &c.Pending, &c.InProgress, &c.Completed, &c.Blocked); err != nil {
+				return fmt.Errorf("failed to scan tag count: %w", err)
+			}
+			out = append(out, c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
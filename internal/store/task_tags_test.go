@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskTags_AddRemoveAndEvents(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task, err := CreateTask(context.Background(), db, "Fix login", "", "", 0)
+	require.NoError(t, err)
+
+	change, err := AddTaskTagsIdempotent(context.Background(), db, "agent1", "tag_1", task.ID, []string{"Bugfix", " area:api ", "bugfix"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bugfix", "area:api"}, change.Changed)
+	assert.Equal(t, []string{"area:api", "bugfix"}, change.Tags)
+	assert.NotZero(t, change.EventID)
+
+	again, err := AddTaskTagsIdempotent(context.Background(), db, "agent1", "tag_1", task.ID, []string{"other"})
+	require.NoError(t, err)
+	assert.Equal(t, change, again, "same request id replays the first result")
+
+	dup, err := AddTaskTagsIdempotent(context.Background(), db, "agent1", "tag_2", task.ID, []string{"bugfix"})
+	require.NoError(t, err)
+	assert.Empty(t, dup.Changed)
+	assert.Zero(t, dup.EventID, "no event when nothing changed")
+
+	removed, err := RemoveTaskTagsIdempotent(context.Background(), db, "agent1", "tag_3", task.ID, []string{"bugfix", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bugfix"}, removed.Changed)
+	assert.Equal(t, []string{"area:api"}, removed.Tags)
+
+	var kinds []string
+	rows, err := db.Query(`SELECT kind FROM events WHERE task_id = ? AND kind LIKE 'task_%tagged' ORDER BY id`, task.ID)
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var k string
+		require.NoError(t, rows.Scan(&k))
+		kinds = append(kinds, k)
+	}
+	assert.Equal(t, []string{"task_tagged", "task_untagged"}, kinds)
+
+	_, err = AddTaskTagsIdempotent(context.Background(), db, "agent1", "tag_4", task.ID, []string{"no spaces"})
+	require.Error(t, err)
+	_, err = AddTaskTagsIdempotent(context.Background(), db, "agent1", "tag_5", "task_missing", []string{"ok"})
+	require.Error(t, err)
+}
+
+func TestTaskTags_NextFilterAttachAndCounts(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docs, err := CreateTask(context.Background(), db, "Write docs", "", "p1", 3)
+	require.NoError(t, err)
+	bug, err := CreateTask(context.Background(), db, "Fix crash", "", "p1", 1)
+	require.NoError(t, err)
+	done, err := CreateTask(context.Background(), db, "Old bug", "", "p1", 0)
+	require.NoError(t, err)
+	_, err = AddTaskTagsIdempotent(context.Background(), db, "agent1", "t1", docs.ID, []string{"docs"})
+	require.NoError(t, err)
+	_, err = AddTaskTagsIdempotent(context.Background(), db, "agent1", "t2", bug.ID, []string{"bugfix", "area:ui"})
+	require.NoError(t, err)
+	_, err = AddTaskTagsIdempotent(context.Background(), db, "agent1", "t3", done.ID, []string{"bugfix"})
+	require.NoError(t, err)
+	require.NoError(t, UpdateTaskStatus(context.Background(), db, done.ID, "completed", done.Version))
+
+	next, err := ListNextTasksFiltered(context.Background(), db, "p1", time.Now(), 10, FocusFilter{Tags: []string{"bugfix"}})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, bug.ID, next[0].ID)
+
+	require.NoError(t, AttachTaskTags(context.Background(), db, next))
+	assert.Equal(t, []string{"area:ui", "bugfix"}, next[0].Tags)
+
+	all, err := ListTasks(context.Background(), db, "", "p1", -1)
+	require.NoError(t, err)
+	require.NoError(t, AttachTaskTags(context.Background(), db, all))
+	filtered := FilterTasksByTags(all, []string{"BUGFIX", "area:ui"})
+	require.Len(t, filtered, 1)
+	assert.Equal(t, bug.ID, filtered[0].ID)
+
+	counts, err := ListTagCounts(context.Background(), db, "p1")
+	require.NoError(t, err)
+	require.Len(t, counts, 3)
+	assert.Equal(t, "bugfix", counts[0].Tag)
+	assert.Equal(t, 2, counts[0].Total)
+	assert.Equal(t, 1, counts[0].Pending)
+	assert.Equal(t, 1, counts[0].Completed)
+
+	brief, err := BuildBrief(context.Background(), db, docs.ID, "p1", "agent1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs"}, brief.Task.Tags)
+	require.NotEmpty(t, brief.Pipeline)
+	assert.Equal(t, bug.ID, brief.Pipeline[0].ID)
+	assert.Equal(t, []string{"area:ui", "bugfix"}, brief.Pipeline[0].Tags)
+}
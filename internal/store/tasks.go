@@ -7,16 +7,17 @@ import (
 	"fmt"
 
 	"github.com/dotcommander/vybe/internal/models"
+	"github.com/dotcommander/vybe/internal/telemetry"
 )
 
 // CreateTask creates a new task with the given title and description.
 // Task ID is generated using pattern: task_<unix_timestamp>_<random_suffix>
 // Initial status is "pending", version starts at 1.
 // If projectID is non-empty, the task is associated with that project.
-func CreateTask(db *sql.DB, title, description, projectID string, priority int) (*models.Task, error) {
+func CreateTask(ctx context.Context, db *sql.DB, title, description, projectID string, priority int) (*models.Task, error) {
 	var task *models.Task
 
-	err := Transact(context.Background(), db, func(tx *sql.Tx) error {
+	err := Transact(ctx, db, func(tx *sql.Tx) error {
 		createdTask, err := CreateTaskTx(tx, title, description, projectID, priority)
 		if err != nil {
 			return err
@@ -57,7 +58,7 @@ func CreateTaskTx(tx *sql.Tx, title, description, projectID string, priority int
 	}
 
 	row := tx.QueryRowContext(context.Background(), `
-		SELECT id, title, description, status, priority, project_id, blocked_reason, version, created_at, updated_at
+		SELECT id, title, description, status, priority, project_id, blocked_reason, due_at, size, attempts, retry_at, outcome, superseded_by, version, created_at, updated_at
 		FROM tasks WHERE id = ?
 	`, taskID)
 
@@ -83,6 +84,19 @@ func GetTaskVersionTx(tx *sql.Tx, taskID string) (int, error) {
 	return version, nil
 }
 
+// CheckTaskVersionTx fails with a *StaleVersionError unless the task is at
+// the expected version.
+func CheckTaskVersionTx(tx *sql.Tx, taskID string, expected int) error {
+	version, err := GetTaskVersionTx(tx, taskID)
+	if err != nil {
+		return err
+	}
+	if version != expected {
+		return &StaleVersionError{Entity: "task", ID: taskID, Expected: expected, Current: version}
+	}
+	return nil
+}
+
 // casUpdateTaskWithEvent executes a CAS UPDATE on the tasks table and appends an event in the
 // same transaction. The caller provides the full SQL and its args (the final two args must be
 // the task ID and the expected version for the WHERE clause). On zero rows affected the helper
@@ -115,6 +129,10 @@ func casUpdateTaskWithEvent(tx *sql.Tx, agentName, taskID string, version int, u
 //   - status != "blocked": blocked_reason is cleared to NULL
 //   - status == "blocked": blocked_reason is PRESERVED (not set)
 //
+// Any status other than blocked also cancels a scheduled retry (retry_at), and
+// any status other than completed clears the outcome and superseded_by. Leaving
+// in_progress drops the claim lease.
+//
 // Callers that need to SET blocked_reason must follow with SetBlockedReasonTx
 // within the same transaction. See CloseTaskTx and TaskSetStatusIdempotent.
 func UpdateTaskStatusWithEventTx(tx *sql.Tx, agentName, taskID, status string, version int) (int64, error) {
@@ -122,9 +140,15 @@ func UpdateTaskStatusWithEventTx(tx *sql.Tx, agentName, taskID, status string, v
 		`UPDATE tasks
 		SET status = ?,
 		    blocked_reason = CASE WHEN ? = 'blocked' THEN blocked_reason ELSE NULL END,
+		    retry_at = CASE WHEN ? = 'blocked' THEN retry_at ELSE NULL END,
+		    outcome = CASE WHEN ? = 'completed' THEN outcome ELSE NULL END,
+		    superseded_by = CASE WHEN ? = 'completed' THEN superseded_by ELSE NULL END,
+		    lease_owner = CASE WHEN ? = 'in_progress' THEN lease_owner ELSE NULL END,
+		    lease_seconds = CASE WHEN ? = 'in_progress' THEN lease_seconds ELSE NULL END,
+		    lease_expires_at = CASE WHEN ? = 'in_progress' THEN lease_expires_at ELSE NULL END,
 		    version = version + 1, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND version = ?`,
-		[]any{status, status, taskID, version},
+		[]any{status, status, status, status, status, status, status, status, taskID, version},
 		models.EventKindTaskStatus,
 		fmt.Sprintf("Status changed to: %s", status),
 	)
@@ -132,9 +156,9 @@ func UpdateTaskStatusWithEventTx(tx *sql.Tx, agentName, taskID, status string, v
 
 // UpdateTaskStatus updates a task's status using optimistic concurrency control.
 // Returns ErrVersionConflict if the version has changed since read.
-func UpdateTaskStatus(db *sql.DB, taskID, status string, version int) error {
-	return RetryWithBackoff(context.Background(), func() error {
-		result, err := db.ExecContext(context.Background(), `
+func UpdateTaskStatus(ctx context.Context, db *sql.DB, taskID, status string, version int) error {
+	return RetryWithBackoff(ctx, func() error {
+		result, err := db.ExecContext(ctx, `
 			UPDATE tasks
 			SET status = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
 			WHERE id = ? AND version = ?
@@ -157,18 +181,21 @@ func UpdateTaskStatus(db *sql.DB, taskID, status string, version int) error {
 }
 
 // GetTask retrieves a task by ID
-func GetTask(db *sql.DB, taskID string) (*models.Task, error) {
+func GetTask(ctx context.Context, db *sql.DB, taskID string) (_ *models.Task, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
 	return getTaskByQuerier(db, taskID)
 }
 
-// getTaskTx retrieves a task by ID in an existing transaction.
-func getTaskTx(tx *sql.Tx, taskID string) (*models.Task, error) {
+// GetTaskTx retrieves a task by ID in an existing transaction.
+func GetTaskTx(tx *sql.Tx, taskID string) (*models.Task, error) {
 	return getTaskByQuerier(tx, taskID)
 }
 
 func getTaskByQuerier(q Querier, taskID string) (*models.Task, error) {
 	row := q.QueryRow(`
-		SELECT id, title, description, status, priority, project_id, blocked_reason, version, created_at, updated_at
+		SELECT id, title, description, status, priority, project_id, blocked_reason, due_at, size, attempts, retry_at, outcome, superseded_by, version, created_at, updated_at
 		FROM tasks WHERE id = ?
 	`, taskID)
 
@@ -185,8 +212,11 @@ func getTaskByQuerier(q Querier, taskID string) (*models.Task, error) {
 
 // ListTasks retrieves all tasks, optionally filtered by status, project, and/or priority.
 // Empty/negative filters are ignored.
-func ListTasks(db *sql.DB, statusFilter, projectFilter string, priorityFilter int) ([]*models.Task, error) {
-	query := `SELECT id, title, description, status, priority, project_id, blocked_reason, version, created_at, updated_at FROM tasks WHERE 1=1`
+func ListTasks(ctx context.Context, db *sql.DB, statusFilter, projectFilter string, priorityFilter int) (_ []*models.Task, err error) {
+	ctx, span := telemetry.StartCaller(ctx, 0)
+	defer func() { span.End(err) }()
+
+	query := `SELECT id, title, description, status, priority, project_id, blocked_reason, due_at, size, attempts, retry_at, outcome, superseded_by, version, created_at, updated_at FROM tasks WHERE 1=1`
 	var args []any
 
 	if statusFilter != "" {
@@ -204,7 +234,7 @@ func ListTasks(db *sql.DB, statusFilter, projectFilter string, priorityFilter in
 
 	query += ` ORDER BY priority DESC, created_at DESC`
 
-	rows, err := db.QueryContext(context.Background(), query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tasks: %w", err)
 	}
@@ -17,7 +17,7 @@ func TestCreateTask(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "Test Task", "Test Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "Test Task", "Test Description", "", 0)
 	require.NoError(t, err)
 	require.NotNil(t, task)
 
@@ -35,11 +35,11 @@ func TestGetTask(t *testing.T) {
 	defer cleanup()
 
 	// Create a task first
-	created, err := CreateTask(db, "Get Test", "Description", "", 0)
+	created, err := CreateTask(context.Background(), db, "Get Test", "Description", "", 0)
 	require.NoError(t, err)
 
 	// Get the task
-	task, err := GetTask(db, created.ID)
+	task, err := GetTask(context.Background(), db, created.ID)
 	require.NoError(t, err)
 	require.NotNil(t, task)
 
@@ -54,7 +54,7 @@ func TestGetTaskNotFound(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := GetTask(db, "nonexistent")
+	task, err := GetTask(context.Background(), db, "nonexistent")
 	assert.Error(t, err)
 	assert.Nil(t, task)
 	assert.Contains(t, err.Error(), "task not found")
@@ -65,15 +65,15 @@ func TestUpdateTaskStatus(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	task, err := CreateTask(db, "Update Test", "Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "Update Test", "Description", "", 0)
 	require.NoError(t, err)
 
 	// Update status
-	err = UpdateTaskStatus(db, task.ID, "in_progress", task.Version)
+	err = UpdateTaskStatus(context.Background(), db, task.ID, "in_progress", task.Version)
 	require.NoError(t, err)
 
 	// Verify update
-	updated, err := GetTask(db, task.ID)
+	updated, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, models.TaskStatusInProgress, updated.Status)
 	assert.Equal(t, 2, updated.Version)
@@ -86,15 +86,15 @@ func TestUpdateTaskStatusVersionConflict(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	task, err := CreateTask(db, "Conflict Test", "Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "Conflict Test", "Description", "", 0)
 	require.NoError(t, err)
 
 	// Update with correct version
-	err = UpdateTaskStatus(db, task.ID, "in_progress", task.Version)
+	err = UpdateTaskStatus(context.Background(), db, task.ID, "in_progress", task.Version)
 	require.NoError(t, err)
 
 	// Try to update with old version (should fail)
-	err = UpdateTaskStatus(db, task.ID, "completed", task.Version)
+	err = UpdateTaskStatus(context.Background(), db, task.ID, "completed", task.Version)
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, ErrVersionConflict)
 }
@@ -104,41 +104,41 @@ func TestListTasks(t *testing.T) {
 	defer cleanup()
 
 	// Create multiple tasks
-	_, err := CreateTask(db, "Task 1", "Desc 1", "", 0)
+	_, err := CreateTask(context.Background(), db, "Task 1", "Desc 1", "", 0)
 	require.NoError(t, err)
 
-	task2, err := CreateTask(db, "Task 2", "Desc 2", "", 0)
+	task2, err := CreateTask(context.Background(), db, "Task 2", "Desc 2", "", 0)
 	require.NoError(t, err)
 
-	task3, err := CreateTask(db, "Task 3", "Desc 3", "", 0)
+	task3, err := CreateTask(context.Background(), db, "Task 3", "Desc 3", "", 0)
 	require.NoError(t, err)
 
 	// Update some statuses
-	err = UpdateTaskStatus(db, task2.ID, "in_progress", task2.Version)
+	err = UpdateTaskStatus(context.Background(), db, task2.ID, "in_progress", task2.Version)
 	require.NoError(t, err)
 
-	err = UpdateTaskStatus(db, task3.ID, "completed", task3.Version)
+	err = UpdateTaskStatus(context.Background(), db, task3.ID, "completed", task3.Version)
 	require.NoError(t, err)
 
 	// List all tasks
-	allTasks, err := ListTasks(db, "", "", -1)
+	allTasks, err := ListTasks(context.Background(), db, "", "", -1)
 	require.NoError(t, err)
 	assert.Len(t, allTasks, 3)
 
 	// List pending tasks
-	pendingTasks, err := ListTasks(db, "pending", "", -1)
+	pendingTasks, err := ListTasks(context.Background(), db, "pending", "", -1)
 	require.NoError(t, err)
 	assert.Len(t, pendingTasks, 1)
 	assert.Equal(t, models.TaskStatusPending, pendingTasks[0].Status)
 
 	// List in_progress tasks
-	inProgressTasks, err := ListTasks(db, "in_progress", "", -1)
+	inProgressTasks, err := ListTasks(context.Background(), db, "in_progress", "", -1)
 	require.NoError(t, err)
 	assert.Len(t, inProgressTasks, 1)
 	assert.Equal(t, models.TaskStatusInProgress, inProgressTasks[0].Status)
 
 	// List completed tasks
-	completedTasks, err := ListTasks(db, "completed", "", -1)
+	completedTasks, err := ListTasks(context.Background(), db, "completed", "", -1)
 	require.NoError(t, err)
 	assert.Len(t, completedTasks, 1)
 	assert.Equal(t, models.TaskStatusCompleted, completedTasks[0].Status)
@@ -148,7 +148,7 @@ func TestListTasksEmpty(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	tasks, err := ListTasks(db, "", "", -1)
+	tasks, err := ListTasks(context.Background(), db, "", "", -1)
 	require.NoError(t, err)
 	assert.Empty(t, tasks)
 }
@@ -158,21 +158,21 @@ func TestConcurrentTaskUpdates(t *testing.T) {
 	defer cleanup()
 
 	// Create a task
-	task, err := CreateTask(db, "Concurrent Test", "Description", "", 0)
+	task, err := CreateTask(context.Background(), db, "Concurrent Test", "Description", "", 0)
 	require.NoError(t, err)
 
 	// Simulate concurrent updates
 	// First update succeeds
-	err = UpdateTaskStatus(db, task.ID, "in_progress", task.Version)
+	err = UpdateTaskStatus(context.Background(), db, task.ID, "in_progress", task.Version)
 	require.NoError(t, err)
 
 	// Second update with old version fails
-	err = UpdateTaskStatus(db, task.ID, "completed", task.Version)
+	err = UpdateTaskStatus(context.Background(), db, task.ID, "completed", task.Version)
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, ErrVersionConflict)
 
 	// Verify final state
-	final, err := GetTask(db, task.ID)
+	final, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, models.TaskStatusInProgress, final.Status)
 	assert.Equal(t, 2, final.Version)
@@ -187,11 +187,11 @@ func TestCreateTaskWithProject(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "Project Task", "Desc", "proj_123", 0)
+	task, err := CreateTask(context.Background(), db, "Project Task", "Desc", "proj_123", 0)
 	require.NoError(t, err)
 	assert.Equal(t, "proj_123", task.ProjectID)
 
-	fetched, err := GetTask(db, task.ID)
+	fetched, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "proj_123", fetched.ProjectID)
 }
@@ -200,23 +200,23 @@ func TestListTasksByProject(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := CreateTask(db, "Task A", "", "proj_a", 0)
+	_, err := CreateTask(context.Background(), db, "Task A", "", "proj_a", 0)
 	require.NoError(t, err)
-	_, err = CreateTask(db, "Task B", "", "proj_b", 0)
+	_, err = CreateTask(context.Background(), db, "Task B", "", "proj_b", 0)
 	require.NoError(t, err)
-	_, err = CreateTask(db, "Task C", "", "proj_a", 0)
+	_, err = CreateTask(context.Background(), db, "Task C", "", "proj_a", 0)
 	require.NoError(t, err)
 
-	tasks, err := ListTasks(db, "", "proj_a", -1)
+	tasks, err := ListTasks(context.Background(), db, "", "proj_a", -1)
 	require.NoError(t, err)
 	assert.Len(t, tasks, 2)
 
-	tasks, err = ListTasks(db, "", "proj_b", -1)
+	tasks, err = ListTasks(context.Background(), db, "", "proj_b", -1)
 	require.NoError(t, err)
 	assert.Len(t, tasks, 1)
 
 	// No filter returns all
-	tasks, err = ListTasks(db, "", "", -1)
+	tasks, err = ListTasks(context.Background(), db, "", "", -1)
 	require.NoError(t, err)
 	assert.Len(t, tasks, 3)
 }
@@ -225,28 +225,28 @@ func TestListTasks_PriorityFilter(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	_, err := CreateTask(db, "Low", "", "", 0)
+	_, err := CreateTask(context.Background(), db, "Low", "", "", 0)
 	require.NoError(t, err)
-	_, err = CreateTask(db, "Medium", "", "", 5)
+	_, err = CreateTask(context.Background(), db, "Medium", "", "", 5)
 	require.NoError(t, err)
-	_, err = CreateTask(db, "High", "", "", 10)
+	_, err = CreateTask(context.Background(), db, "High", "", "", 10)
 	require.NoError(t, err)
 
 	// Filter by priority 5
-	tasks, err := ListTasks(db, "", "", 5)
+	tasks, err := ListTasks(context.Background(), db, "", "", 5)
 	require.NoError(t, err)
 	require.Len(t, tasks, 1)
 	assert.Equal(t, "Medium", tasks[0].Title)
 	assert.Equal(t, 5, tasks[0].Priority)
 
 	// Filter by priority 10
-	tasks, err = ListTasks(db, "", "", 10)
+	tasks, err = ListTasks(context.Background(), db, "", "", 10)
 	require.NoError(t, err)
 	require.Len(t, tasks, 1)
 	assert.Equal(t, "High", tasks[0].Title)
 
 	// No filter (-1) returns all, ordered by priority DESC
-	tasks, err = ListTasks(db, "", "", -1)
+	tasks, err = ListTasks(context.Background(), db, "", "", -1)
 	require.NoError(t, err)
 	require.Len(t, tasks, 3)
 	assert.Equal(t, "High", tasks[0].Title)
@@ -254,7 +254,7 @@ func TestListTasks_PriorityFilter(t *testing.T) {
 	assert.Equal(t, "Low", tasks[2].Title)
 
 	// Filter by nonexistent priority returns empty
-	tasks, err = ListTasks(db, "", "", 99)
+	tasks, err = ListTasks(context.Background(), db, "", "", 99)
 	require.NoError(t, err)
 	assert.Empty(t, tasks)
 }
@@ -267,7 +267,7 @@ func TestUpdateTaskStatusWithEventTx_BlockedReasonTransitions(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	task, err := CreateTask(db, "Blocked Reason Test", "", "", 0)
+	task, err := CreateTask(context.Background(), db, "Blocked Reason Test", "", "", 0)
 	require.NoError(t, err)
 	assert.Empty(t, task.BlockedReason)
 
@@ -278,7 +278,7 @@ func TestUpdateTaskStatusWithEventTx_BlockedReasonTransitions(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	after1, err := GetTask(db, task.ID)
+	after1, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "blocked", string(after1.Status))
 	assert.Empty(t, after1.BlockedReason, "blocked_reason must be preserved (NULL) when transitioning to blocked")
@@ -289,7 +289,7 @@ func TestUpdateTaskStatusWithEventTx_BlockedReasonTransitions(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	after2, err := GetTask(db, task.ID)
+	after2, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, models.BlockedReasonDependency, after2.BlockedReason)
 
@@ -299,7 +299,7 @@ func TestUpdateTaskStatusWithEventTx_BlockedReasonTransitions(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	after3, err := GetTask(db, task.ID)
+	after3, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "blocked", string(after3.Status))
 	assert.Equal(t, models.BlockedReasonDependency, after3.BlockedReason, "blocked_reason must be preserved when staying blocked")
@@ -311,7 +311,7 @@ func TestUpdateTaskStatusWithEventTx_BlockedReasonTransitions(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	after4, err := GetTask(db, task.ID)
+	after4, err := GetTask(context.Background(), db, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "pending", string(after4.Status))
 	assert.Empty(t, after4.BlockedReason, "blocked_reason must be cleared when transitioning out of blocked")
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dotcommander/vybe/internal/models"
+)
+
+// TranscriptEntry is one transcript record mapped onto an event. Key is unique
+// within the session. Signature, when set, identifies a hook-recorded event
+// carrying the same step (see IngestTranscriptTx); entries without one are
+// never matched against hook events.
+type TranscriptEntry struct {
+	Key       string `json:"key"`
+	Kind      string `json:"kind"`
+	Message   string `json:"message"`
+	Metadata  string `json:"metadata,omitempty"`
+	Signature string `json:"-"`
+}
+
+// TranscriptIngestResult reports an ingested transcript. Duplicates counts
+// entries a hook had already recorded; Skipped counts entries ingested by an
+// earlier run.
+type TranscriptIngestResult struct {
+	SessionID  string         `json:"session_id"`
+	ProjectID  string         `json:"project_id,omitempty"`
+	Entries    int            `json:"entries"`
+	Ingested   int            `json:"ingested"`
+	Duplicates int            `json:"duplicates"`
+	Skipped    int            `json:"skipped"`
+	ByKind     map[string]int `json:"by_kind"`
+	EventID    int64          `json:"event_id"`
+}
+
+// TranscriptSignature keys a step for matching transcript entries against
+// hook-recorded events of the same session: the prompt text for user prompts,
+// the tool name for tool results.
+func TranscriptSignature(kind, value string) string {
+	return kind + "\x00" + value
+}
+
+// hookEventSignaturesTx returns, per TranscriptSignature, the hook-recorded
+// event ids of sessionID not themselves written by a transcript ingest.
+func hookEventSignaturesTx(tx *sql.Tx, sessionID string) (map[string][]int64, error) {
+	rows, err := tx.QueryContext(context.Background(), `
+		SELECT id, kind, message, COALESCE(json_extract(metadata, '$.tool_name'), '')
+		FROM events
+		WHERE `+sessionIDExpr+` = ?
+		  AND kind IN (?, ?, ?)
+		  AND COALESCE(json_extract(metadata, '$.source'), '') != 'transcript'
+		ORDER BY id ASC
+	`, sessionID, models.EventKindUserPrompt, models.EventKindToolFailure, models.EventKindToolSuccess)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	sigs := map[string][]int64{}
+	for rows.Next() {
+		var id int64
+		var kind, message, tool string
+		if err := rows.Scan(&id, &kind, &message, &tool); err != nil {
+			return nil, err
+		}
+		value := tool
+		if kind == models.EventKindUserPrompt {
+			value = message
+		}
+		sig := TranscriptSignature(kind, value)
+		sigs[sig] = append(sigs[sig], id)
+	}
+	return sigs, rows.Err()
+}
+
+// IngestTranscriptTx writes each entry as an event in projectID and records
+// its key in transcript_entries, then writes one transcript_ingested event.
+// Entries already recorded for the session are skipped, so re-running an
+// ingest is safe. An entry whose Signature matches a hook-recorded event of
+// the session is recorded against that event instead of duplicating it; the
+// n-th matching entry pairs with the n-th hook event.
+func IngestTranscriptTx(tx *sql.Tx, agentName, sessionID, projectID string, entries []TranscriptEntry) (*TranscriptIngestResult, error) {
+	if sessionID == "" {
+		return nil, errors.New("session id is required")
+	}
+	if projectID != "" {
+		if err := CheckReferencesTx(tx, Reference{Field: "project_id", Kind: RefProject, ID: projectID}); err != nil {
+			return nil, err
+		}
+	}
+
+	hookEvents, err := hookEventSignaturesTx(tx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &TranscriptIngestResult{SessionID: sessionID, ProjectID: projectID, Entries: len(entries), ByKind: map[string]int{}}
+	for _, e := range entries {
+		var known int64
+		err := tx.QueryRowContext(context.Background(),
+			`SELECT event_id FROM transcript_entries WHERE session_id = ? AND entry_key = ?`, sessionID, e.Key).Scan(&known)
+		if err == nil {
+			res.Skipped++
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to check transcript entry: %w", err)
+		}
+
+		var eventID int64
+		if ids := hookEvents[e.Signature]; e.Signature != "" && len(ids) > 0 {
+			eventID, hookEvents[e.Signature] = ids[0], ids[1:]
+			res.Duplicates++
+		} else {
+			eventID, err = InsertEventWithProjectTx(tx, e.Kind, agentName, projectID, "", e.Message, e.Metadata)
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert %s event: %w", e.Kind, err)
+			}
+			res.Ingested++
+			res.ByKind[e.Kind]++
+		}
+		if _, err := tx.ExecContext(context.Background(),
+			`INSERT INTO transcript_entries (session_id, entry_key, event_id) VALUES (?, ?, ?)`,
+			sessionID, e.Key, eventID); err != nil {
+			return nil, fmt.Errorf("failed to record transcript entry: %w", err)
+		}
+	}
+
+	meta, _ := json.Marshal(map[string]any{
+		"session_id": sessionID,
+		"source":     "transcript",
+		"ingested":   res.Ingested,
+		"duplicates": res.Duplicates,
+		"skipped":    res.Skipped,
+		"by_kind":    res.ByKind,
+	})
+	res.EventID, err = InsertEventWithProjectTx(tx, models.EventKindTranscriptIngest, agentName, projectID, "",
+		fmt.Sprintf("Ingested transcript %s: %d events (%d duplicates, %d already ingested)",
+			sessionID, res.Ingested, res.Duplicates, res.Skipped), string(meta))
+	if err != nil {
+		return nil, fmt.Errorf("failed to append transcript ingest event: %w", err)
+	}
+	return res, nil
+}
+
+// IngestTranscriptIdempotent performs IngestTranscriptTx once per (agent_name, request_id).
+func IngestTranscriptIdempotent(ctx context.Context, db *sql.DB, agentName, requestID, sessionID, projectID string, entries []TranscriptEntry) (*TranscriptIngestResult, error) {
+	return RunIdempotent(ctx, db, agentName, requestID, "ingest.transcript", func(tx *sql.Tx) (*TranscriptIngestResult, error) {
+		return IngestTranscriptTx(tx, agentName, sessionID, projectID, entries)
+	})
+}
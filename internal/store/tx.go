@@ -13,9 +13,10 @@ type Querier interface {
 	QueryRow(query string, args ...any) *sql.Row
 }
 
-// Transact runs fn in a transaction wrapped with RetryWithBackoff.
+// Transact runs fn in a transaction wrapped with RetryWithBackoff. Its span is
+// named after the function that called Transact.
 func Transact(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
-	return RetryWithBackoff(ctx, func() error {
+	return retryWithBackoff(ctx, 1, func() error {
 		tx, err := db.BeginTx(ctx, nil)
 		if err != nil {
 			return fmt.Errorf("failed to begin transaction: %w", err)
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Single-writer lock used in network_fs: lock mode. flock and POSIX locks are
+// not trustworthy on network filesystems, but O_EXCL create is atomic on NFSv3+
+// and SMB, so the lock is the existence of a file next to the database.
+const (
+	// writerLockWait is how long OpenDB waits for another process to release the lock.
+	writerLockWait = 30 * time.Second
+	// writerLockStaleAfter is how old an untouched lock file must be before it is
+	// treated as left behind by a crashed process and broken.
+	writerLockStaleAfter = 2 * time.Minute
+	// writerLockRefresh is how often a holder touches the lock file so
+	// long-running commands are not mistaken for crashed ones.
+	writerLockRefresh = 30 * time.Second
+)
+
+// writerLocks maps an open *sql.DB to the release func of its writer lock.
+var writerLocks sync.Map
+
+// WriterLockPath returns the lock file used for dbPath in network_fs: lock mode.
+func WriterLockPath(dbPath string) string {
+	return dbPath + ".writer.lock"
+}
+
+// acquireWriterLock creates the lock file for dbPath, waiting up to wait for a
+// current holder and breaking locks older than writerLockStaleAfter. The
+// returned func stops the refresher and removes the file.
+func acquireWriterLock(dbPath string, wait time.Duration) (func(), error) {
+	path := WriterLockPath(dbPath)
+	host, _ := os.Hostname()
+	deadline := time.Now().Add(wait)
+	delay := 25 * time.Millisecond
+
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644) //nolint:gosec // G304: path derived from the trusted DB path
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "host=%s pid=%d acquired=%s\n", host, os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+			_ = f.Close()
+			return startWriterLockRefresh(path), nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create writer lock %s: %w", path, err)
+		}
+
+		if st, statErr := os.Stat(path); statErr == nil && time.Since(st.ModTime()) > writerLockStaleAfter {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			holder, _ := os.ReadFile(path) //nolint:gosec // G304: path derived from the trusted DB path
+			return nil, fmt.Errorf("database is locked by another vybe process (%s); network_fs: lock allows one process at a time", trimLockHolder(holder))
+		}
+		time.Sleep(delay)
+		delay = min(delay*2, 500*time.Millisecond)
+	}
+}
+
+func startWriterLockRefresh(path string) func() {
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(writerLockRefresh)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-t.C:
+				_ = os.Chtimes(path, now, now)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			_ = os.Remove(path)
+		})
+	}
+}
+
+func trimLockHolder(b []byte) string {
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return "holder unknown"
+	}
+	return s
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterLock_SingleHolderAndStaleBreak(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "vybe.db")
+
+	release, err := acquireWriterLock(dbPath, time.Second)
+	require.NoError(t, err)
+	require.FileExists(t, WriterLockPath(dbPath))
+
+	_, err = acquireWriterLock(dbPath, 50*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "locked by another vybe process")
+
+	release()
+	assert.NoFileExists(t, WriterLockPath(dbPath))
+
+	// A lock left by a crashed process is broken once stale.
+	require.NoError(t, os.WriteFile(WriterLockPath(dbPath), []byte("host=x pid=1\n"), 0o600))
+	old := time.Now().Add(-2 * writerLockStaleAfter)
+	require.NoError(t, os.Chtimes(WriterLockPath(dbPath), old, old))
+	release, err = acquireWriterLock(dbPath, 50*time.Millisecond)
+	require.NoError(t, err)
+	release()
+}
+
+func TestDetectFilesystem_LocalTempDir(t *testing.T) {
+	fs, err := DetectFilesystem(filepath.Join(t.TempDir(), "vybe.db"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, fs.Type)
+	assert.False(t, fs.Network)
+}
@@ -0,0 +1,243 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config describes where and how traces are exported.
+type Config struct {
+	// Endpoint is the full OTLP/HTTP traces URL, e.g. http://localhost:4318/v1/traces.
+	Endpoint string
+	// Headers are sent with every export request (e.g. collector auth).
+	Headers map[string]string
+	// ServiceName and ServiceVersion become the service.name and
+	// service.version resource attributes.
+	ServiceName    string
+	ServiceVersion string
+}
+
+// otlpTracesPath is the OTLP/HTTP path for trace exports.
+const otlpTracesPath = "/v1/traces"
+
+// ConfigFromEnv resolves the exporter config. endpoint (the --otel-endpoint
+// flag) wins when set; otherwise the standard OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+// and OTEL_EXPORTER_OTLP_ENDPOINT variables apply. A bare host endpoint gets
+// /v1/traces appended. ok is false when tracing is off: no endpoint, or
+// OTEL_SDK_DISABLED=true or OTEL_TRACES_EXPORTER=none.
+func ConfigFromEnv(endpoint string) (cfg Config, ok bool) {
+	if disabled, _ := strconv.ParseBool(os.Getenv("OTEL_SDK_DISABLED")); disabled {
+		return Config{}, false
+	}
+	if strings.EqualFold(os.Getenv("OTEL_TRACES_EXPORTER"), "none") {
+		return Config{}, false
+	}
+
+	switch {
+	case endpoint != "":
+		cfg.Endpoint = withTracesPath(endpoint)
+	case os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "":
+		cfg.Endpoint = withTracesPath(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"))
+	case os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "":
+		// The generic endpoint is a base URL: signal paths always go under it.
+		cfg.Endpoint = strings.TrimRight(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "/") + otlpTracesPath
+	default:
+		return Config{}, false
+	}
+
+	cfg.Headers = parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	for k, v := range parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS")) {
+		cfg.Headers[k] = v
+	}
+	cfg.ServiceName = os.Getenv("OTEL_SERVICE_NAME")
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "vybe"
+	}
+	return cfg, true
+}
+
+// withTracesPath appends /v1/traces to an endpoint with no path of its own.
+func withTracesPath(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Path != "" && u.Path != "/") {
+		return endpoint
+	}
+	u.Path = otlpTracesPath
+	return u.String()
+}
+
+// parseHeaders reads the OTEL_EXPORTER_OTLP_HEADERS format: comma-separated
+// key=value pairs with URL-encoded values.
+func parseHeaders(raw string) map[string]string {
+	headers := map[string]string{}
+	for pair := range strings.SplitSeq(raw, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(strings.TrimSpace(v)); err == nil {
+			v = unescaped
+		}
+		headers[k] = strings.TrimSpace(v)
+	}
+	return headers
+}
+
+// OTLP/JSON request shapes (opentelemetry-proto, trace/v1). IDs are hex and
+// 64-bit integers are decimal strings, as the JSON mapping requires.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// OTLP span kind and status codes.
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusError      = 2
+)
+
+const instrumentationScope = "github.com/dotcommander/vybe"
+
+func otlpAttr(key string, value any) otlpKeyValue {
+	var v otlpValue
+	switch x := value.(type) {
+	case string:
+		v.StringValue = &x
+	case bool:
+		v.BoolValue = &x
+	case int:
+		s := strconv.Itoa(x)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(x, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &x
+	default:
+		s := fmt.Sprint(x)
+		v.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: v}
+}
+
+func encodeTrace(t *trace) otlpRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	resource := []otlpKeyValue{otlpAttr("service.name", t.cfg.ServiceName)}
+	if t.cfg.ServiceVersion != "" {
+		resource = append(resource, otlpAttr("service.version", t.cfg.ServiceVersion))
+	}
+	traceID := hex.EncodeToString(t.id[:])
+	spans := make([]otlpSpan, 0, len(t.finished))
+	for _, s := range t.finished {
+		span := otlpSpan{
+			TraceID:           traceID,
+			SpanID:            hex.EncodeToString(s.id[:]),
+			Name:              s.name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		}
+		if s.parent != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parent[:])
+		}
+		for _, a := range s.attrs {
+			span.Attributes = append(span.Attributes, otlpAttr(a.key, a.value))
+		}
+		if s.err != "" {
+			span.Status = otlpStatus{Code: otlpStatusError, Message: s.err}
+		}
+		spans = append(spans, span)
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: resource},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: instrumentationScope, Version: t.cfg.ServiceVersion},
+			Spans: spans,
+		}},
+	}}}
+}
+
+// export posts the trace's finished spans to the configured endpoint.
+func export(ctx context.Context, t *trace) error {
+	body, err := json.Marshal(encodeTrace(t))
+	if err != nil {
+		return fmt.Errorf("failed to encode trace: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build trace export request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export trace: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("trace export to %s failed: %s: %s", t.cfg.Endpoint, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
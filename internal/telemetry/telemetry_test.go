@@ -0,0 +1,193 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clearOTelEnv(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{
+		"OTEL_SDK_DISABLED", "OTEL_TRACES_EXPORTER", "OTEL_SERVICE_NAME",
+		"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS",
+	} {
+		t.Setenv(k, "")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	clearOTelEnv(t)
+
+	_, ok := ConfigFromEnv("")
+	assert.False(t, ok, "no endpoint means no tracing")
+
+	cfg, ok := ConfigFromEnv("http://localhost:4318")
+	require.True(t, ok)
+	assert.Equal(t, "http://localhost:4318/v1/traces", cfg.Endpoint)
+	assert.Equal(t, "vybe", cfg.ServiceName)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/otlp/")
+	cfg, ok = ConfigFromEnv("")
+	require.True(t, ok)
+	assert.Equal(t, "http://collector:4318/otlp/v1/traces", cfg.Endpoint)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://collector:4318/custom")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=abc,x-team=core")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "api-key=a%20b")
+	t.Setenv("OTEL_SERVICE_NAME", "vybe-ci")
+	cfg, ok = ConfigFromEnv("")
+	require.True(t, ok)
+	assert.Equal(t, "http://collector:4318/custom", cfg.Endpoint, "the traces endpoint is used as given")
+	assert.Equal(t, map[string]string{"api-key": "a b", "x-team": "core"}, cfg.Headers)
+	assert.Equal(t, "vybe-ci", cfg.ServiceName)
+
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	_, ok = ConfigFromEnv("http://localhost:4318")
+	assert.False(t, ok)
+}
+
+func TestSpansAreNoopsWithoutTrace(t *testing.T) {
+	ctx := context.Background()
+	require.False(t, Enabled(ctx))
+	spanCtx, span := StartCaller(ctx, 0)
+	assert.Nil(t, span)
+	assert.Equal(t, ctx, spanCtx)
+	span.Set("k", "v")
+	span.End(errors.New("ignored"))
+	require.NoError(t, Finish(ctx, nil))
+}
+
+func TestFuncSpanName(t *testing.T) {
+	assert.Equal(t, "store.GetMemory", funcSpanName("github.com/dotcommander/vybe/internal/store.GetMemory"))
+	assert.Equal(t, "store.ListEvents", funcSpanName("github.com/dotcommander/vybe/internal/store.ListEvents.func1.2"))
+	assert.Equal(t, "store.RunIdempotent[...]", funcSpanName("github.com/dotcommander/vybe/internal/store.RunIdempotent[...]"))
+	assert.Equal(t, "store.LoadTaskTags", funcSpanName("github.com/dotcommander/vybe/internal/store.LoadTaskTags-range1"))
+	assert.Equal(t, "store.OpenDB", funcSpanName("github.com/dotcommander/vybe/internal/store.OpenDB.func2-range1.1"))
+}
+
+func TestStartCallerDoesNotRepeatItsOwnSpan(t *testing.T) {
+	ctx, root := StartTrace(context.Background(), Config{}, "root")
+	outer, span := StartCaller(ctx, 0)
+	require.NotNil(t, span)
+	inner, nested := StartCaller(outer, 0)
+	assert.Nil(t, nested)
+	assert.Equal(t, outer, inner)
+	span.End(nil)
+	root.End(nil)
+}
+
+func TestFinishExportsSpanTree(t *testing.T) {
+	var got otlpRequest
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		auth = r.Header.Get("Authorization")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	ctx, root := StartTrace(context.Background(), Config{
+		Endpoint:       srv.URL + "/v1/traces",
+		Headers:        map[string]string{"Authorization": "Bearer t"},
+		ServiceName:    "vybe",
+		ServiceVersion: "1.2.3",
+	}, "vybe resume")
+	require.True(t, Enabled(ctx))
+	root.Set("vybe.command", "resume")
+	childCtx, child := StartCaller(ctx, 0)
+	_, grandchild := Start(childCtx, "query")
+	grandchild.Set("vybe.retries", 2)
+	grandchild.End(errors.New("database is locked"))
+	child.End(nil)
+	Start(ctx, "left open")
+
+	require.NoError(t, Finish(ctx, nil))
+	require.NoError(t, Finish(ctx, nil), "a finished trace is not exported again")
+	assert.Equal(t, "Bearer t", auth)
+
+	require.Len(t, got.ResourceSpans, 1)
+	rs := got.ResourceSpans[0]
+	assert.Contains(t, rs.Resource.Attributes, otlpAttr("service.version", "1.2.3"))
+	require.Len(t, rs.ScopeSpans, 1)
+	spans := rs.ScopeSpans[0].Spans
+	require.Len(t, spans, 3, "spans still open at Finish are not exported")
+
+	byName := map[string]otlpSpan{}
+	for _, s := range spans {
+		assert.Len(t, s.TraceID, 32)
+		assert.Equal(t, spans[0].TraceID, s.TraceID)
+		byName[s.Name] = s
+	}
+	rootSpan := byName["vybe resume"]
+	childSpan := byName["telemetry.TestFinishExportsSpanTree"]
+	querySpan := byName["query"]
+	assert.Empty(t, rootSpan.ParentSpanID)
+	assert.Equal(t, rootSpan.SpanID, childSpan.ParentSpanID)
+	assert.Equal(t, childSpan.SpanID, querySpan.ParentSpanID)
+	assert.Equal(t, otlpStatus{Code: otlpStatusError, Message: "database is locked"}, querySpan.Status)
+	assert.Contains(t, querySpan.Attributes, otlpAttr("vybe.retries", 2))
+	assert.Contains(t, rootSpan.Attributes, otlpAttr("vybe.command", "resume"))
+}
+
+func TestConcurrentSpansTakeParentsFromTheirContext(t *testing.T) {
+	var got otlpRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	ctx, _ := StartTrace(context.Background(), Config{Endpoint: srv.URL, ServiceName: "vybe"}, "vybe loop")
+	const workers = 8
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workerCtx, worker := Start(ctx, fmt.Sprintf("worker %d", w))
+			for range 10 {
+				_, op := Start(workerCtx, fmt.Sprintf("op %d", w))
+				op.End(nil)
+			}
+			worker.End(nil)
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, Finish(ctx, nil))
+
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 1+workers+workers*10)
+	idByName := map[string]string{}
+	for _, s := range spans {
+		if strings.HasPrefix(s.Name, "worker ") {
+			idByName[s.Name] = s.SpanID
+		}
+	}
+	for _, s := range spans {
+		if n, ok := strings.CutPrefix(s.Name, "op "); ok {
+			assert.Equal(t, idByName["worker "+n], s.ParentSpanID, "an op's parent is its own worker's span")
+		}
+	}
+}
+
+func TestFinishReportsCollectorErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	ctx, _ := StartTrace(context.Background(), Config{Endpoint: srv.URL, ServiceName: "vybe"}, "vybe status")
+	err := Finish(ctx, nil)
+	require.ErrorContains(t, err, "bad payload")
+}
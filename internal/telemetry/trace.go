@@ -0,0 +1,198 @@
+// Package telemetry records per-command trace spans and exports them to an
+// OpenTelemetry collector over OTLP/HTTP with JSON encoding.
+//
+// Spans travel in a context.Context: StartTrace puts a trace's root span in
+// the context it returns, and Start opens a child of whatever span the given
+// context carries. Without a trace in the context every function here is a
+// cheap no-op and returns a nil *Span, whose methods are safe to call. Because
+// parents come from the context, goroutines that share a trace (loop
+// --workers) each get their own branch of it.
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSpans bounds the spans one trace keeps, so a long-running command such
+// as loop cannot grow memory without limit. Spans past it are dropped and
+// counted on the root span.
+const maxSpans = 4096
+
+type trace struct {
+	mu       sync.Mutex
+	cfg      Config
+	id       [16]byte
+	root     *Span
+	finished []*Span
+	dropped  int
+}
+
+// Span is one timed operation within a trace.
+type Span struct {
+	t      *trace
+	id     [8]byte
+	parent [8]byte
+	name   string
+	start  time.Time
+	end    time.Time
+	attrs  []attr
+	err    string
+}
+
+type attr struct {
+	key   string
+	value any
+}
+
+type spanKey struct{}
+
+// fromContext returns the span ctx carries, or nil.
+func fromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// Enabled reports whether ctx carries a trace being recorded.
+func Enabled(ctx context.Context) bool {
+	return fromContext(ctx) != nil
+}
+
+// StartTrace begins recording a new trace and returns its root span and a
+// context carrying it.
+func StartTrace(ctx context.Context, cfg Config, name string) (context.Context, *Span) {
+	t := &trace{cfg: cfg}
+	_, _ = rand.Read(t.id[:])
+	t.root = t.start(name, [8]byte{})
+	return context.WithValue(ctx, spanKey{}, t.root), t.root
+}
+
+// Start opens a span as a child of the span ctx carries and returns a context
+// carrying the new span. It returns ctx and nil when ctx carries no trace.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := fromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	s := parent.t.start(name, parent.id)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// StartCaller is Start named after a calling function: skip 0 names the
+// function that calls StartCaller, 1 its caller, and so on. Names are
+// package-qualified ("store.GetMemory"); closures take the name of the
+// function that encloses them. When the span ctx carries already has that
+// name, StartCaller returns ctx and nil so a function that spans itself is not
+// repeated under its own span by helpers it calls (such as RetryWithBackoff).
+func StartCaller(ctx context.Context, skip int) (context.Context, *Span) {
+	parent := fromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	name := "unknown"
+	if pc, _, _, ok := runtime.Caller(skip + 1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			name = funcSpanName(fn.Name())
+		}
+	}
+	if name == parent.name {
+		return ctx, nil
+	}
+	return Start(ctx, name)
+}
+
+// funcSpanName trims a runtime function name to its package and function,
+// dropping the module path and closure suffixes (".func1", ".2", "-range1").
+func funcSpanName(full string) string {
+	name := full[strings.LastIndexByte(full, '/')+1:]
+	for {
+		if i := strings.LastIndex(name, "-range"); i >= 0 && isDigits(name[i+len("-range"):]) {
+			name = name[:i]
+			continue
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 || !isDigits(strings.TrimPrefix(name[i+1:], "func")) {
+			return name
+		}
+		name = name[:i]
+	}
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *trace) start(name string, parent [8]byte) *Span {
+	s := &Span{t: t, parent: parent, name: name, start: time.Now()}
+	_, _ = rand.Read(s.id[:])
+	return s
+}
+
+// Set records an attribute on the span. Values are strings, bools, ints,
+// int64s, or float64s; anything else is recorded as its string form.
+func (s *Span) Set(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	s.attrs = append(s.attrs, attr{key: key, value: value})
+}
+
+// End closes the span, marking it failed when err is non-nil. Ending a span
+// twice keeps the first end.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	t := s.t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !s.end.IsZero() {
+		return
+	}
+	s.end = time.Now()
+	if err != nil {
+		s.err = err.Error()
+	}
+	if len(t.finished) >= maxSpans && s != t.root {
+		t.dropped++
+		return
+	}
+	t.finished = append(t.finished, s)
+}
+
+// Finish ends the root span of the trace ctx carries with err and exports
+// every finished span, using ctx for the export request. It is a no-op when
+// ctx carries no trace, or when its root span has already ended. Spans still
+// open are not exported.
+func Finish(ctx context.Context, err error) error {
+	s := fromContext(ctx)
+	if s == nil {
+		return nil
+	}
+	t := s.t
+	t.mu.Lock()
+	ended, dropped := !t.root.end.IsZero(), t.dropped
+	t.mu.Unlock()
+	if ended {
+		return nil
+	}
+	if dropped > 0 {
+		t.root.Set("vybe.spans_dropped", dropped)
+	}
+	t.root.End(err)
+	return export(ctx, t)
+}